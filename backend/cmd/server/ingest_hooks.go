@@ -0,0 +1,13 @@
+package main
+
+import "gist/backend/internal/service"
+
+// customIngestHooks returns the hooks every ingested entry passes through,
+// in the order they run. Add custom hooks here, for example:
+//
+//	service.NewIngestHook("drop-sponsored", func(ctx context.Context, feed model.Feed, entry *model.Entry) (bool, error) {
+//		return entry.Title == nil || !strings.HasPrefix(*entry.Title, "Sponsored:"), nil
+//	}),
+func customIngestHooks() []service.IngestHook {
+	return nil
+}
@@ -8,15 +8,21 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
+	// Feed time zones load on images without a zoneinfo database, like alpine
+	_ "time/tzdata"
 
 	"gist/backend/internal/config"
 	"gist/backend/internal/db"
+	"gist/backend/internal/events"
 	"gist/backend/internal/handler"
 	transport "gist/backend/internal/http"
 	"gist/backend/internal/repository"
 	"gist/backend/internal/scheduler"
+	"gist/backend/internal/secret"
 	"gist/backend/internal/service"
 	"gist/backend/internal/service/ai"
 	"gist/backend/internal/service/anubis"
@@ -27,9 +33,46 @@ import (
 // @version 1.0
 // @description This is a modern RSS reader API.
 // @BasePath /api
+// @securityDefinitions.apikey ApiToken
+// @in header
+// @name X-Gist-Token
 func main() {
 	cfg := config.Load()
 
+	firstRun, err := config.Bootstrap(&cfg)
+	if err != nil {
+		log.Fatalf("bootstrap data dir: %v", err)
+	}
+	if firstRun {
+		// Printed once to the console, never through the logger
+		log.Printf("first run: data directory initialized at %s", cfg.DataDir)
+		fmt.Printf("\n  Gist is ready: %s\n  API token: %s\n  (saved in %s)\n\n",
+			config.FirstRunURL(cfg.Addr), cfg.APIToken, filepath.Join(cfg.DataDir, config.ConfigFileName))
+	}
+
+	// Move icons, media and backups that were kept elsewhere before
+	relocations, err := config.Relocate(cfg)
+	if err != nil {
+		log.Fatalf("relocate data: %v", err)
+	}
+	for _, r := range relocations {
+		log.Printf("relocate data: moved %d files from %s to %s, left %d in place", r.Moved, r.From, r.To, r.Skipped)
+	}
+
+	if cfg.Mode != config.ModeNormal {
+		log.Printf("running in %s mode", cfg.Mode)
+	}
+
+	secretKeyPath := filepath.Join(cfg.DataDir, secret.KeyFileName)
+	secretKey, err := secret.LoadOrCreateKey(secretKeyPath)
+	if err != nil {
+		log.Fatalf("load secret key: %v", err)
+	}
+	secretBox, err := secret.NewBox(secretKey)
+	if err != nil {
+		log.Fatalf("init secret box: %v", err)
+	}
+
 	if err := snowflake.Init(1); err != nil {
 		log.Fatalf("init snowflake: %v", err)
 	}
@@ -47,6 +90,15 @@ func main() {
 	aiSummaryRepo := repository.NewAISummaryRepository(dbConn)
 	aiTranslationRepo := repository.NewAITranslationRepository(dbConn)
 	aiListTranslationRepo := repository.NewAIListTranslationRepository(dbConn)
+	importItemRepo := repository.NewImportItemRepository(dbConn)
+	txManager := repository.NewTxManager(dbConn)
+	outboxRepo := repository.NewOutboxRepository(dbConn)
+	linkCheckRepo := repository.NewLinkCheckRepository(dbConn)
+	bandwidthRepo := repository.NewBandwidthRepository(dbConn)
+	filterRepo := repository.NewFilterRepository(dbConn)
+	notificationRepo := repository.NewNotificationRepository(dbConn)
+	feedFetchRepo := repository.NewFeedFetchRepository(dbConn)
+	webSubRepo := repository.NewWebSubRepository(dbConn)
 
 	// Initialize rate limiter with stored setting
 	initialRateLimit := ai.DefaultRateLimit
@@ -65,40 +117,146 @@ func main() {
 	anubisStore := anubis.NewStore(settingsRepo)
 	anubisSolver := anubis.NewSolver(nil, anubisStore)
 
-	iconService := service.NewIconService(cfg.DataDir, feedRepo, anubisSolver)
+	iconService := service.NewIconService(cfg.IconsDir, feedRepo, settingsRepo, anubisSolver)
+	// Background work reports to clients of GET /api/events through the hub
+	eventHub := events.NewHub()
+	// Scheduled and startup tasks, and imports every replica would resume,
+	// take a lease in the database, so replicas sharing it do not run them twice
+	taskRunner := service.NewLeasedTaskRunner(eventHub, service.NewLeases(repository.NewLeaseRepository(dbConn)),
+		service.TaskRefresh, service.TaskLinkCheck, service.TaskPrune, service.TaskSync, service.TaskBackup,
+		service.TaskIconBackfill, service.TaskSnippetBackfill, service.TaskImport)
+	// Finished imports and failed tasks are kept in the notification inbox
+	notificationService := service.NewNotificationService(notificationRepo, eventHub)
+	eventNotifier := service.NewEventNotifier(notificationService, eventHub)
+	eventNotifier.Start()
 
-	// Backfill icons for existing feeds (run in background)
-	go func() {
-		if err := iconService.BackfillIcons(context.Background()); err != nil {
-			log.Printf("backfill icons: %v", err)
-		}
-	}()
+	// Backfill icons for existing feeds; in low-data mode, wait for a later start
+	if settingsService.LowDataActive(context.Background()) {
+		log.Println("backfill icons: skipped in low-data mode")
+	} else if _, err := taskRunner.Start(service.TaskIconBackfill, 0, service.BackfillIconsTask(iconService)); err != nil {
+		log.Printf("backfill icons: %v", err)
+	}
 
+	outboxDispatcher := service.NewOutboxDispatcher(outboxRepo)
+
+	// Feed fetches keep the cookies of the hosts allowed in the settings,
+	// use the browser fingerprints set for some hosts, and count the bytes
+	// each feed downloads
+	cookieJar := service.NewCookieJar(settingsRepo)
+	fetchTransport := service.NewFingerprintTransport(settingsRepo)
+	bandwidthMeter := service.NewBandwidthMeter(bandwidthRepo)
+	meteredTransport := service.NewMeteredTransport(fetchTransport)
+
+	feedCredentials := service.NewFeedCredentials(feedRepo, secretBox)
+	ingestHooks, err := service.NewIngestHooks(customIngestHooks()...)
+	if err != nil {
+		log.Fatalf("register ingest hooks: %v", err)
+	}
 	folderService := service.NewFolderService(folderRepo, feedRepo)
-	feedService := service.NewFeedService(feedRepo, folderRepo, entryRepo, iconService, settingsService, nil, anubisSolver)
-	entryService := service.NewEntryService(entryRepo, feedRepo, folderRepo)
-	readabilityService := service.NewReadabilityService(entryRepo, anubisSolver)
-	opmlService := service.NewOPMLService(folderService, feedService, folderRepo, feedRepo)
-	refreshService := service.NewRefreshService(feedRepo, entryRepo, settingsService, nil, anubisSolver)
+	filterService := service.NewFilterService(filterRepo, feedRepo, folderRepo)
+	feedService := service.NewFeedService(txManager, feedRepo, folderRepo, outboxDispatcher, settingsService, &http.Client{Timeout: 20 * time.Second, Jar: cookieJar, Transport: meteredTransport}, anubisSolver, feedCredentials, ingestHooks)
+	entryService := service.NewEntryService(txManager, entryRepo, feedRepo, folderRepo)
+
+	// Backfill snippets for entries stored before they were computed at ingest
+	if _, err := taskRunner.Start(service.TaskSnippetBackfill, 0, service.BackfillSnippetsTask(entryService)); err != nil {
+		log.Printf("backfill snippets: %v", err)
+	}
+	readabilityService := service.NewReadabilityService(entryRepo, feedRepo, settingsService, anubisSolver, service.NewPageCache(cfg.PageCacheSize, cfg.PageCacheTTL))
+	opmlService := service.NewOPMLService(folderService, feedService, folderRepo, feedRepo, importItemRepo)
+	fetchMetrics := service.NewFetchMetrics()
+	feedHealthService := service.NewFeedHealthService(feedFetchRepo, feedRepo)
+	proxyService := service.NewProxyService(anubisSolver, filepath.Join(cfg.MediaDir, "images"), cfg.ImageCacheSize)
+	thumbnailService := service.NewThumbnailService(cfg.MediaDir, proxyService, entryRepo)
+	aiService := service.NewAIService(aiSummaryRepo, aiTranslationRepo, aiListTranslationRepo, settingsRepo, entryRepo, feedRepo, rateLimiter)
+	nsfwCheckService := service.NewNSFWCheckService(entryRepo, settingsService, thumbnailService, aiService)
+	// Feeds whose content names a WebSub hub are subscribed to it when the
+	// instance is reachable at cfg.PublicURL, and get new entries pushed
+	webSubService := service.NewWebSubService(webSubRepo, &http.Client{Timeout: 20 * time.Second, Transport: meteredTransport}, cfg.PublicURL)
+	refreshService := service.NewRefreshService(feedRepo, entryRepo, settingsService, filterService, &http.Client{Timeout: 30 * time.Second, Jar: cookieJar, Transport: meteredTransport}, anubisSolver, fetchMetrics, bandwidthMeter, eventHub, thumbnailService, nsfwCheckService, readabilityService, feedCredentials, feedHealthService, webSubService, ingestHooks, cfg.RefreshMode)
+	if hooks := ingestHooks.Names(); len(hooks) > 0 {
+		log.Printf("ingest hooks: %s", strings.Join(hooks, ", "))
+	}
+
+	outboxDispatcher.Register(service.OutboxFeedIcon, service.NewFeedIconHandler(iconService, feedRepo))
+	outboxDispatcher.Register(service.OutboxFeedRefresh, service.NewFeedRefreshHandler(refreshService))
+
+	syncService := service.NewSyncService(folderRepo, feedRepo, entryRepo, settingsRepo, folderService, feedService, service.SyncPeer{
+		URL:   cfg.SyncPrimaryURL,
+		Token: cfg.SyncToken,
+	})
 
-	proxyService := service.NewProxyService(anubisSolver)
-	aiService := service.NewAIService(aiSummaryRepo, aiTranslationRepo, aiListTranslationRepo, settingsRepo, rateLimiter)
+	linkCheckService := service.NewLinkCheckService(linkCheckRepo, nil)
 
 	folderHandler := handler.NewFolderHandler(folderService)
-	feedHandler := handler.NewFeedHandler(feedService, refreshService)
-	entryHandler := handler.NewEntryHandler(entryService, readabilityService)
-	importTaskService := service.NewImportTaskService()
-	opmlHandler := handler.NewOPMLHandler(opmlService, importTaskService)
-	iconHandler := handler.NewIconHandler(iconService)
-	proxyHandler := handler.NewProxyHandler(proxyService)
+	filterHandler := handler.NewFilterHandler(filterService)
+	feedHandler := handler.NewFeedHandler(feedService, refreshService, readabilityService, taskRunner, feedHealthService)
+	exportService := service.NewExportService(entryRepo, feedRepo, aiSummaryRepo)
+	backupService := service.NewBackupService(cfg.BackupsDir, settingsService, opmlService, exportService)
+	entryHandler := handler.NewEntryHandler(entryService, readabilityService, exportService)
+	importTaskService := service.NewImportTaskService(opmlService, taskRunner, repository.NewImportTaskRepository(dbConn))
+	// Pick up an import the last run stopped in the middle of
+	if err := importTaskService.Resume(context.Background()); err != nil {
+		log.Printf("resume import: %v", err)
+	}
+	opmlHandler := handler.NewOPMLHandler(opmlService, importTaskService, taskRunner, cfg.MaxUploadSize)
+	iconHandler := handler.NewIconHandler(iconService, taskRunner)
+	proxyHandler := handler.NewProxyHandler(proxyService, thumbnailService)
 	settingsHandler := handler.NewSettingsHandler(settingsService)
 	aiHandler := handler.NewAIHandler(aiService)
+	taskHandler := handler.NewTaskHandler(taskRunner)
+	syncHandler := handler.NewSyncHandler(syncService, taskRunner)
+	metricsHandler := handler.NewMetricsHandler(fetchMetrics, cfg.MetricsFeedLimit)
+	statsHandler := handler.NewStatsHandler(bandwidthMeter)
+	eventHandler := handler.NewEventHandler(eventHub)
+	linkCheckHandler := handler.NewLinkCheckHandler(linkCheckService, taskRunner)
+	opdsHandler := handler.NewOPDSHandler(service.NewOPDSService(entryRepo, feedRepo))
+	readerHandler := handler.NewReaderHandler(service.NewReaderService(entryService, feedRepo))
+	plainHandler := handler.NewPlainHandler(service.NewPlainService(entryService, feedRepo))
+	readLaterService := service.NewReadLaterService(entryRepo, feedRepo, readabilityService, iconService)
+	wallabagHandler := handler.NewWallabagHandler(readLaterService, entryService, cfg.APIToken)
+	captureHandler := handler.NewCaptureHandler(readLaterService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	webSubHandler := handler.NewWebSubHandler(webSubService, refreshService)
+	wipeService, err := service.NewWipeService(repository.NewWipeRepository(dbConn), secretBox, secretKeyPath, cfg.DBPath, filepath.Join(cfg.DataDir, config.ConfigFileName), cfg.IconsDir, cfg.MediaDir, cfg.BackupsDir)
+	if err != nil {
+		log.Fatalf("init wipe: %v", err)
+	}
+	maintenanceService := service.NewMaintenanceService(repository.NewMaintenanceRepository(dbConn), cfg.DataDir)
+	adminHandler := handler.NewAdminHandler(wipeService, maintenanceService, cfg.MaxRestoreSize)
+	integrationsHandler := handler.NewIntegrationsHandler(service.NewIntegrationsService(settingsRepo, entryRepo, &http.Client{Timeout: 30 * time.Second, Transport: meteredTransport}))
+	backupHandler := handler.NewBackupHandler(backupService)
+	preferencesHandler := handler.NewPreferencesHandler(service.NewPreferencesService(settingsRepo))
+	playbackHandler := handler.NewPlaybackHandler(service.NewPlaybackService(repository.NewPlaybackRepository(dbConn)))
+	tagHandler := handler.NewTagHandler(service.NewTagService(repository.NewTagRepository(dbConn), entryRepo))
 
-	router := transport.NewRouter(folderHandler, feedHandler, entryHandler, opmlHandler, iconHandler, proxyHandler, settingsHandler, aiHandler, cfg.StaticDir)
+	// Background scheduler: refresh the feeds that are due (each on its own
+	// interval, see cfg.RefreshMode), check starred links daily, and pull
+	// from the primary when this instance is a sync secondary. All hold off
+	// in low-data mode. Entries past the retention settings are pruned daily
+	// and backups written when due regardless, as neither fetches anything.
+	lowData := func(ctx context.Context) string {
+		if settingsService.LowDataActive(ctx) {
+			return "low-data mode"
+		}
+		return ""
+	}
+	jobs := []scheduler.Job{
+		{Kind: service.TaskRefresh, Interval: service.RefreshCheckInterval, Task: service.RefreshDueTask(refreshService), Skip: lowData},
+		{Kind: service.TaskLinkCheck, Interval: 24 * time.Hour, Task: service.LinkCheckTask(linkCheckService), Skip: lowData},
+		{Kind: service.TaskPrune, Interval: 24 * time.Hour, Task: service.PruneTask(settingsService, entryRepo)},
+		{Kind: service.TaskBackup, Interval: service.BackupCheckInterval, Task: service.BackupDueTask(backupService)},
+	}
+	if syncService.Configured() {
+		jobs = append(jobs, scheduler.Job{Kind: service.TaskSync, Interval: cfg.SyncInterval, Task: service.SyncTask(syncService), Skip: lowData})
+		log.Printf("sync: mirroring %s every %v", cfg.SyncPrimaryURL, cfg.SyncInterval)
+	}
+	sched := scheduler.New(taskRunner, jobs...)
+	schedulerHandler := handler.NewSchedulerHandler(sched)
+
+	router := transport.NewRouter(folderHandler, filterHandler, feedHandler, entryHandler, opmlHandler, iconHandler, proxyHandler, settingsHandler, aiHandler, taskHandler, syncHandler, metricsHandler, statsHandler, eventHandler, schedulerHandler, linkCheckHandler, opdsHandler, readerHandler, plainHandler, wallabagHandler, captureHandler, notificationHandler, webSubHandler, adminHandler, integrationsHandler, backupHandler, preferencesHandler, playbackHandler, tagHandler, service.NewDataVersionService(repository.NewDataVersionRepository(dbConn)), cfg)
 
-	// Start background scheduler (15 minutes interval)
-	sched := scheduler.New(refreshService, 15*time.Minute)
 	sched.Start()
+	outboxDispatcher.Start()
 
 	// Handle graceful shutdown
 	go func() {
@@ -112,8 +270,14 @@ func main() {
 		defer cancel()
 
 		sched.Stop()
+		if err := taskRunner.Shutdown(ctx); err != nil {
+			log.Printf("stop background tasks: %v", err)
+		}
+		eventNotifier.Stop()
+		outboxDispatcher.Stop()
 		readabilityService.Close()
 		proxyService.Close()
+		fetchTransport.Close()
 
 		// Gracefully shutdown the HTTP server
 		if err := router.Shutdown(ctx); err != nil {
@@ -15,6 +15,182 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/admin/backup": {
+            "post": {
+                "security": [
+                    {
+                        "ApiToken": []
+                    }
+                ],
+                "description": "Take a consistent, compacted copy of the database (SQLite VACUUM INTO, while the instance keeps running) and download it as gist-YYYYMMDD-HHMMSS.db. The secret key sealing stored credentials (secret.key in the data directory) is not included; copy it along to keep them usable on another host. Requires the API token as a Bearer token or X-Gist-Token header.",
+                "produces": [
+                    "application/vnd.sqlite3"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Back up the database",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiToken": []
+                    }
+                ],
+                "description": "Replace every folder, feed, entry, setting and cache with those of a database from POST /admin/backup, uploaded as the multipart field \"file\" or as the raw body. The file is checked for integrity and brought up to the current schema first, so backups of older versions can be restored; a file that is not an intact Gist database is rejected with 400 and the data is kept. The data is replaced in one transaction. Cached icons and media are kept. Requires the API token as a Bearer token or X-Gist-Token header.",
+                "consumes": [
+                    "multipart/form-data",
+                    "application/vnd.sqlite3"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Restore the database",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Database file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.RestoreResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/wipe": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiToken": []
+                    }
+                ],
+                "description": "Delete every folder, feed, entry, setting and cache, the cached icons, media and backups, and replace the secret key, leaving the instance as on first run. The config file and its API token are kept. Cannot be undone. Requires the API token as a Bearer token or X-Gist-Token header, and a token from POST /admin/wipe/token as confirm.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Wipe all data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Confirmation token from POST /admin/wipe/token",
+                        "name": "confirm",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.WipeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/wipe/token": {
+            "post": {
+                "security": [
+                    {
+                        "ApiToken": []
+                    }
+                ],
+                "description": "Issue the single-use token DELETE /admin/wipe must carry. It expires after 5 minutes; requesting another replaces it. Requires the API token as a Bearer token or X-Gist-Token header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Request a wipe confirmation token",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.WipeConfirmation"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/ai/cache": {
             "delete": {
                 "description": "Delete all AI-generated summaries and translations cache.",
@@ -43,7 +219,7 @@ const docTemplate = `{
         },
         "/ai/summarize": {
             "post": {
-                "description": "Generate an AI summary of the article content. Returns cached result if available, otherwise streams the response.",
+                "description": "Generate an AI summary of the article content. Returns cached result if available, otherwise streams the response.\nWhile the AI rate limit holds the request back, \"event: queue\" events carrying a JSON {\"position\",\"waitMs\"} are sent before the text, about every second, so the stream does not look hung; closing the request leaves the queue at once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -90,7 +266,7 @@ const docTemplate = `{
         },
         "/ai/translate": {
             "post": {
-                "description": "Translate article content. Returns cached result if available, otherwise streams block translations via SSE.",
+                "description": "Translate article content. Returns cached result if available, otherwise streams block translations via SSE.\nWhile the AI rate limit holds blocks back, {\"queued\":{\"position\",\"waitMs\"}} events are sent about every second; closing the request leaves the queue at once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -181,27 +357,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/proxy/image/{encoded}": {
+        "/api/proxy/image": {
             "get": {
-                "description": "Proxies external images to avoid triggering anti-crawling mechanisms",
+                "description": "Same as /api/proxy/image/{encoded} with plain query parameters. Entry content asked for with proxyImages=true points its images here.",
                 "produces": [
                     "application/octet-stream"
                 ],
                 "tags": [
                     "proxy"
                 ],
-                "summary": "Proxy external image",
+                "summary": "Proxy external image by URL",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Base64 URL-safe encoded image URL",
-                        "name": "encoded",
-                        "in": "path",
+                        "description": "Image URL",
+                        "name": "url",
+                        "in": "query",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "Base64 URL-safe encoded article URL (used as Referer for CDN anti-hotlinking)",
+                        "description": "Article URL (used as Referer for CDN anti-hotlinking)",
                         "name": "ref",
                         "in": "query"
                     }
@@ -234,57 +410,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/entries": {
+        "/api/proxy/image/{encoded}": {
             "get": {
-                "description": "Get a list of entries with optional filters and pagination",
+                "description": "Proxies external images to avoid triggering anti-crawling mechanisms. Images are cached on disk (media/images, GIST_IMAGE_CACHE_MB) and fetched through the network proxy set by HTTP_PROXY/HTTPS_PROXY.",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "entries"
+                    "proxy"
                 ],
-                "summary": "List entries",
+                "summary": "Proxy external image",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Filter by feed ID",
-                        "name": "feedId",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Filter by folder ID",
-                        "name": "folderId",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "Filter by content type (article, picture, notification)",
-                        "name": "contentType",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Only return unread entries",
-                        "name": "unreadOnly",
-                        "in": "query"
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Only return starred entries",
-                        "name": "starredOnly",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Limit the number of entries (default 50)",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "Base64 URL-safe encoded image URL",
+                        "name": "encoded",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "Offset for pagination",
-                        "name": "offset",
+                        "type": "string",
+                        "description": "Base64 URL-safe encoded article URL (used as Referer for CDN anti-hotlinking)",
+                        "name": "ref",
                         "in": "query"
                     }
                 ],
@@ -292,7 +439,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.entryListResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -300,40 +447,15 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/entries/mark-read": {
-            "post": {
-                "description": "Mark all entries as read, optionally filtered by feed, folder, or content type",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "entries"
-                ],
-                "summary": "Mark all as read",
-                "parameters": [
-                    {
-                        "description": "Filter criteria",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.markAllReadRequest"
+                            "$ref": "#/definitions/internal_handler.errorResponse"
                         }
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "504": {
+                        "description": "Gateway Timeout",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
@@ -341,30 +463,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/entries/{id}": {
+        "/api/proxy/thumbnail/{encoded}": {
             "get": {
-                "description": "Get a single entry by its ID",
+                "description": "Proxies an external image scaled down for the picture grid. Thumbnails are cached on disk, and pre-generated after refresh for picture folders that enable it.",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "entries"
+                    "proxy"
                 ],
-                "summary": "Get entry",
+                "summary": "Proxy external image as a grid thumbnail",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Entry ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Base64 URL-safe encoded image URL",
+                        "name": "encoded",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Base64 URL-safe encoded article URL (used as Referer for CDN anti-hotlinking)",
+                        "name": "ref",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.entryResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -373,39 +501,51 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/entries/{id}/fetch-readable": {
-            "post": {
-                "description": "Extract readable content from the entry's original URL using readability",
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/thumbnails/{entryId}": {
+            "get": {
+                "description": "Returns the thumbnail image of an entry scaled down to w pixels wide (320 or 640, default 320), generated from thumbnailUrl on first use and cached on disk (media/thumbnails). Large JPEG and PNG originals are scaled to JPEG, or PNG when transparent; narrower images and other formats are served as they are. Entry lists link it as thumbnailProxyUrl.",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "entries"
+                    "proxy"
                 ],
-                "summary": "Fetch readable content",
+                "summary": "Entry thumbnail",
                 "parameters": [
                     {
                         "type": "integer",
                         "description": "Entry ID",
-                        "name": "id",
+                        "name": "entryId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Width: 320 or 640",
+                        "name": "w",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.readableContentResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -419,53 +559,44 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "504": {
+                        "description": "Gateway Timeout",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
                     }
                 }
             }
         },
-        "/entries/{id}/read": {
-            "patch": {
-                "description": "Mark an entry as read or unread",
-                "consumes": [
-                    "application/json"
-                ],
+        "/backups": {
+            "get": {
+                "description": "Get the backups written by the backup job (every backupInterval hours of the general settings, keeping the newest backupKeep), newest first. Each is a zip of subscriptions.opml and starred.jsonl.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "entries"
-                ],
-                "summary": "Update read status",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Entry ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Read status",
-                        "name": "read",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.updateReadRequest"
-                        }
-                    }
+                    "backups"
                 ],
+                "summary": "List backups",
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.errorResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gist_backend_internal_service.BackupFile"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
@@ -473,45 +604,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/entries/{id}/starred": {
-            "patch": {
-                "description": "Mark an entry as starred or unstarred",
-                "consumes": [
-                    "application/json"
-                ],
+        "/backups/{name}": {
+            "get": {
+                "description": "Download a backup as a zip holding the OPML export of the subscriptions (subscriptions.opml) and the starred entries as JSON Lines (starred.jsonl).",
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
-                    "entries"
+                    "backups"
                 ],
-                "summary": "Update starred status",
+                "summary": "Download a backup",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Entry ID",
-                        "name": "id",
+                        "type": "string",
+                        "description": "Backup name",
+                        "name": "name",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Starred status",
-                        "name": "starred",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.updateStarredRequest"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.errorResponse"
+                            "type": "file"
                         }
                     },
                     "404": {
@@ -523,56 +639,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/feeds": {
-            "get": {
-                "description": "Get a list of all subscribed feeds",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "feeds"
-                ],
-                "summary": "List feeds",
-                "parameters": [
+        "/capture": {
+            "post": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Filter by folder ID",
-                        "name": "folderId",
-                        "in": "query"
+                        "ApiToken": []
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/internal_handler.feedResponse"
-                            }
-                        }
-                    }
-                }
-            },
-            "post": {
-                "description": "Subscribe to a new RSS/Atom feed",
+                "description": "Save a web page into the Saved pages feed, extracting its readable content and site icon. Send the page HTML to save what the browser shows, e.g. behind a login; otherwise the page is fetched. A page saved before is returned as it is. Requires the API token as a Bearer token or X-Gist-Token header; cross-origin requests are allowed.",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-www-form-urlencoded"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "feeds"
+                    "entries"
                 ],
-                "summary": "Create a feed",
+                "summary": "Save a page",
                 "parameters": [
                     {
-                        "description": "Feed creation request",
-                        "name": "feed",
+                        "description": "Page to save",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.createFeedRequest"
+                            "$ref": "#/definitions/internal_handler.captureRequest"
                         }
                     }
                 ],
@@ -580,7 +673,7 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.feedResponse"
+                            "$ref": "#/definitions/internal_handler.entryResponse"
                         }
                     },
                     "400": {
@@ -589,40 +682,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     },
-                    "409": {
-                        "description": "Feed URL already exists",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.feedConflictResponse"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Unsubscribe from multiple feeds at once",
-                "consumes": [
-                    "application/json"
-                ],
-                "tags": [
-                    "feeds"
-                ],
-                "summary": "Delete multiple feeds",
-                "parameters": [
-                    {
-                        "description": "Feed IDs to delete",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.deleteFeedsRequest"
+                            "$ref": "#/definitions/internal_handler.errorResponse"
                         }
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
@@ -630,30 +697,95 @@ const docTemplate = `{
                 }
             }
         },
-        "/feeds/preview": {
+        "/entries": {
             "get": {
-                "description": "Fetch information about a feed from its URL",
+                "description": "Get a page of entry summaries with optional filters, newest published first. Content is left out; each entry carries a plain-text snippet and the full entry comes from /entries/{id}. Page with after=nextCursor rather than offset to not skip or repeat entries when new ones arrive between pages; offset still works. With compact=true or the header Prefer: return=minimal, for e-ink devices and terminal clients, entries carry only id, feedId, title, url, a snippet cut to 140 characters, publishedAt, and read and starred when true (compactEntryListResponse).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "feeds"
+                    "entries"
                 ],
-                "summary": "Preview a feed",
+                "summary": "List entries",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by feed ID",
+                        "name": "feedId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by folder ID",
+                        "name": "folderId",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "Feed URL",
-                        "name": "url",
-                        "in": "query",
-                        "required": true
+                        "description": "Filter by content type (article, picture, notification, podcast)",
+                        "name": "contentType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return unread entries",
+                        "name": "unreadOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return starred entries",
+                        "name": "starredOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return entries published in this UTC year (YYYY) or month (YYYY-MM)",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave out entries flagged as not safe for work",
+                        "name": "excludeNsfw",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tag ID",
+                        "name": "tagId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit the number of entries (1-100, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (\u003e= 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue after this cursor (publishedAt,id), the nextCursor of the previous page; not combined with offset",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compact response, also asked for with the header Prefer: return=minimal",
+                        "name": "compact",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.feedPreviewResponse"
+                            "$ref": "#/definitions/internal_handler.entryListResponse"
                         }
                     },
                     "400": {
@@ -665,96 +797,78 @@ const docTemplate = `{
                 }
             }
         },
-        "/feeds/refresh": {
-            "post": {
-                "description": "Trigger an immediate refresh of all subscribed feeds",
-                "tags": [
-                    "feeds"
-                ],
-                "summary": "Refresh all feeds",
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "409": {
-                        "description": "Refresh already in progress",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.errorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/feeds/{id}": {
-            "put": {
-                "description": "Update the title or folder of an existing feed",
-                "consumes": [
-                    "application/json"
-                ],
+        "/entries/archive": {
+            "get": {
+                "description": "Count entries per UTC year or month of publication, newest first, for archive browsing. Entries without a publish date are left out. Takes the same filters as the entry list; pass a period back to /entries?period= to list its entries.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "feeds"
+                    "entries"
                 ],
-                "summary": "Update a feed",
+                "summary": "Entry archive",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Period length (month, year; default month)",
+                        "name": "groupBy",
+                        "in": "query"
+                    },
                     {
                         "type": "integer",
-                        "description": "Feed ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by feed ID",
+                        "name": "feedId",
+                        "in": "query"
                     },
                     {
-                        "description": "Feed update request",
-                        "name": "feed",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.updateFeedRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.feedResponse"
-                        }
+                        "type": "integer",
+                        "description": "Filter by folder ID",
+                        "name": "folderId",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.errorResponse"
-                        }
+                    {
+                        "type": "string",
+                        "description": "Filter by content type (article, picture, notification, podcast)",
+                        "name": "contentType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only count unread entries",
+                        "name": "unreadOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only count starred entries",
+                        "name": "starredOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only count entries published in this UTC year (YYYY) or month (YYYY-MM)",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave out entries flagged as not safe for work",
+                        "name": "excludeNsfw",
+                        "in": "query"
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.errorResponse"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Unsubscribe from a feed",
-                "tags": [
-                    "feeds"
-                ],
-                "summary": "Delete a feed",
-                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Feed ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by tag ID",
+                        "name": "tagId",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.archiveResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -771,46 +885,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/feeds/{id}/type": {
-            "patch": {
-                "description": "Change the content type of a feed (article/picture/notification)",
+        "/entries/batch": {
+            "post": {
+                "description": "Mark up to 1000 entries read or unread, star or unstar them, or delete them, in a single statement, e.g. to replay changes made offline. Unknown IDs are skipped. Deleted entries still in their feed come back on its next refresh.",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "feeds"
+                    "entries"
                 ],
-                "summary": "Update feed type",
+                "summary": "Batch entry operation",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Feed ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Type update request",
+                        "description": "Entry IDs and operation",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.updateTypeRequest"
+                            "$ref": "#/definitions/internal_handler.entryBatchRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.errorResponse"
+                            "$ref": "#/definitions/internal_handler.entryBatchResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
@@ -818,56 +925,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/folders": {
+        "/entries/export": {
             "get": {
-                "description": "Get a list of all folders",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "folders"
-                ],
-                "summary": "List folders",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/internal_handler.folderResponse"
-                            }
-                        }
-                    }
-                }
-            },
-            "post": {
-                "description": "Create a new folder to organize feeds",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Download all starred entries, newest first, with their readable content (or feed content) and cached AI summary, as JSON Lines (an entry per line) or a Markdown digest. The export streams; an error after it started cuts it short.",
                 "produces": [
-                    "application/json"
+                    "text/plain"
                 ],
                 "tags": [
-                    "folders"
+                    "entries"
                 ],
-                "summary": "Create a folder",
+                "summary": "Export starred entries",
                 "parameters": [
                     {
-                        "description": "Folder creation request",
-                        "name": "folder",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.folderRequest"
-                        }
+                        "type": "boolean",
+                        "description": "Only starred entries can be exported; must be true when given",
+                        "name": "starred",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Export format (json, md; default json)",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "Exported entries",
                         "schema": {
-                            "type": "Created"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -877,24 +963,29 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete multiple folders at once (also deletes feeds in them)",
+            }
+        },
+        "/entries/mark-read": {
+            "post": {
+                "description": "Mark all entries as read, optionally filtered by feed, folder, or content type",
                 "consumes": [
                     "application/json"
                 ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "folders"
+                    "entries"
                 ],
-                "summary": "Delete multiple folders",
+                "summary": "Mark all as read",
                 "parameters": [
                     {
-                        "description": "Folder IDs to delete",
+                        "description": "Filter criteria",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.deleteFoldersRequest"
+                            "$ref": "#/definitions/internal_handler.markAllReadRequest"
                         }
                     }
                 ],
@@ -911,76 +1002,103 @@ const docTemplate = `{
                 }
             }
         },
-        "/folders/{id}": {
-            "put": {
-                "description": "Update the name or parent ID of an existing folder",
-                "consumes": [
-                    "application/json"
-                ],
+        "/entries/search": {
+            "get": {
+                "description": "Full-text search over the title, text, author and URL of entries, best match first. All words must match; \"quoted words\" match as a phrase, word* matches a prefix, -word leaves out entries containing it, and title:word or author:word searches one field. feed:ID, folder:ID, is:unread and is:starred in q narrow the search like the query parameters. Results are summaries, as in the entry list, and compact as there when asked.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "folders"
+                    "entries"
                 ],
-                "summary": "Update a folder",
+                "summary": "Search entries",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Folder ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "string",
+                        "description": "Search text",
+                        "name": "q",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "Folder update request",
-                        "name": "folder",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.folderRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.folderResponse"
-                        }
+                        "type": "string",
+                        "description": "Result order (relevance, newest; default relevance)",
+                        "name": "sort",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.errorResponse"
-                        }
+                    {
+                        "type": "integer",
+                        "description": "Filter by feed ID",
+                        "name": "feedId",
+                        "in": "query"
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.errorResponse"
-                        }
-                    }
-                }
-            },
-            "delete": {
-                "description": "Delete an existing folder",
-                "tags": [
-                    "folders"
-                ],
-                "summary": "Delete a folder",
-                "parameters": [
                     {
                         "type": "integer",
-                        "description": "Folder ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter by folder ID",
+                        "name": "folderId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by content type (article, picture, notification, podcast)",
+                        "name": "contentType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return unread entries",
+                        "name": "unreadOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only return starred entries",
+                        "name": "starredOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return entries published in this UTC year (YYYY) or month (YYYY-MM)",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave out entries flagged as not safe for work",
+                        "name": "excludeNsfw",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tag ID",
+                        "name": "tagId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit the number of entries (1-100, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset for pagination (\u003e= 0)",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compact response, also asked for with the header Prefer: return=minimal",
+                        "name": "compact",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.entryListResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -997,37 +1115,49 @@ const docTemplate = `{
                 }
             }
         },
-        "/folders/{id}/type": {
-            "patch": {
-                "description": "Change the content type of a folder (article/picture/notification)",
-                "consumes": [
+        "/entries/{id}": {
+            "get": {
+                "description": "Get a single entry by its ID. revision summarizes what the publisher changed in their latest update of the title or text, if they ever changed it. format selects how content and readableContent are returned: html (default) is the sanitized HTML, text is plain text with a line per paragraph, and clean is simplified HTML without media, embeds, classes or styles, with absolute links, for widgets, text-to-speech and e-ink readers. The text and clean versions of content are made at ingest. externalUrl is the page a linkblog entry is about (JSON Feed external_url) and attachments are the files attached to it (JSON Feed attachments, RSS and Atom enclosures). With compact=true or the header Prefer: return=minimal the entry carries only id, feedId, title, url, author, publishedAt, content, readableContent, and read and starred when true (compactEntryResponse). With proxyImages=true the images in content and readableContent load through /api/proxy/image, with relative sources resolved against the entry URL.",
+                "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "folders"
+                    "entries"
                 ],
-                "summary": "Update folder type",
+                "summary": "Get entry",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Folder ID",
+                        "description": "Entry ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Type update request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.updateFolderTypeRequest"
-                        }
+                        "type": "string",
+                        "description": "Content format: html, text or clean",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Point images at the image proxy",
+                        "name": "proxyImages",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compact response, also asked for with the header Prefer: return=minimal",
+                        "name": "compact",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.entryResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -1044,53 +1174,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/opml/export": {
-            "get": {
-                "description": "Export all feeds and folders to an OPML file",
-                "produces": [
-                    "text/xml"
-                ],
-                "tags": [
-                    "opml"
-                ],
-                "summary": "Export OPML",
-                "responses": {
-                    "200": {
-                        "description": "OPML file content",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/opml/import": {
+        "/entries/{id}/fetch-readable": {
             "post": {
-                "description": "Start importing feeds and folders from an OPML file",
-                "consumes": [
-                    "multipart/form-data",
-                    "text/xml"
-                ],
+                "description": "Extract readable content from the entry's original URL using readability. format returns it as html (default), text or clean HTML, like GET /entries/{id}.\nContent extracted before is returned as it is; force extracts it again, from the page fetched within the last minutes when there is one.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "opml"
+                    "entries"
                 ],
-                "summary": "Import OPML",
+                "summary": "Fetch readable content",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "OPML file to import",
-                        "name": "file",
-                        "in": "formData"
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Content format: html, text or clean",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Extract again even when readable content is stored",
+                        "name": "force",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Point images at the image proxy, as for GET /entries/{id}",
+                        "name": "proxyImages",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.importStartedResponse"
+                            "$ref": "#/definitions/internal_handler.readableContentResponse"
                         }
                     },
                     "400": {
@@ -1099,72 +1224,49 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     },
-                    "413": {
-                        "description": "Request Entity Too Large",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Cancel the current import task",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "opml"
-                ],
-                "summary": "Cancel Import",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.importCancelledResponse"
-                        }
-                    }
-                }
             }
         },
-        "/opml/import/status": {
+        "/entries/{id}/playback": {
             "get": {
-                "description": "Get current import task status via SSE stream",
+                "description": "Get where playback of the entry's attachment (such as a podcast episode's audio) stopped, in seconds from the start; 0 when it was never played.",
                 "produces": [
-                    "text/event-stream"
+                    "application/json"
                 ],
                 "tags": [
-                    "opml"
+                    "entries"
                 ],
-                "summary": "Import Status",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/gist_backend_internal_service.ImportTask"
-                        }
+                "summary": "Get playback position",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
-                }
-            }
-        },
-        "/settings/ai": {
-            "get": {
-                "description": "Get the AI provider configuration with masked API keys",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "settings"
                 ],
-                "summary": "Get AI settings",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.aiSettingsResponse"
+                            "$ref": "#/definitions/internal_handler.playbackResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
@@ -1172,7 +1274,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update the AI provider configuration. Empty apiKey keeps existing key.",
+                "description": "Store where playback of the entry's attachment stopped, in seconds from the start, so it resumes there on any device. Players save it periodically while playing and on pause.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1180,17 +1282,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "settings"
+                    "entries"
                 ],
-                "summary": "Update AI settings",
+                "summary": "Update playback position",
                 "parameters": [
                     {
-                        "description": "AI settings",
-                        "name": "settings",
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Playback position",
+                        "name": "playback",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.aiSettingsRequest"
+                            "$ref": "#/definitions/internal_handler.playbackRequest"
                         }
                     }
                 ],
@@ -1198,7 +1307,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.aiSettingsResponse"
+                            "$ref": "#/definitions/internal_handler.playbackResponse"
                         }
                     },
                     "400": {
@@ -1207,8 +1316,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
@@ -1216,35 +1325,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/settings/ai/test": {
-            "post": {
-                "description": "Test the AI provider connection with a \"Hello world\" message",
-                "consumes": [
-                    "application/json"
-                ],
+        "/entries/{id}/print": {
+            "get": {
+                "description": "Render an entry as a self-contained HTML document for printing or saving: its readable content when extracted and the feed content otherwise, with the title, byline and a link to the source. Scripts and embedded media are left out; image and link URLs are made absolute.",
                 "produces": [
-                    "application/json"
+                    "text/html"
                 ],
                 "tags": [
-                    "settings"
+                    "entries"
                 ],
-                "summary": "Test AI connection",
+                "summary": "Print view of an entry",
                 "parameters": [
                     {
-                        "description": "AI test configuration",
-                        "name": "config",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/internal_handler.aiTestRequest"
-                        }
-                    }
-                ],
-                "responses": {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "HTML document",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.aiTestResponse"
+                            "type": "string"
                         }
                     },
                     "400": {
@@ -1252,37 +1356,135 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
                     }
                 }
             }
         },
-        "/settings/general": {
-            "get": {
-                "description": "Get general application settings including fallback user agent and auto readability",
+        "/entries/{id}/read": {
+            "patch": {
+                "description": "Mark an entry as read or unread",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "settings"
+                    "entries"
+                ],
+                "summary": "Update read status",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Read status",
+                        "name": "read",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.updateReadRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/entries/{id}/save-to/{provider}": {
+            "post": {
+                "description": "Push the URL and title of an entry to Wallabag, Pocket or Linkding. With withContent=true its readable content is sent too, for services that store content (Wallabag), so the page is not fetched again; Pocket and Linkding always fetch the page themselves.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Save an entry to a read-later service",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Read-later service (wallabag, pocket, linkding)",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Send the readable content too",
+                        "name": "withContent",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get general settings",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.generalSettingsResponse"
+                            "$ref": "#/definitions/gist_backend_internal_service.IntegrationSaveResult"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Update general application settings",
+            }
+        },
+        "/entries/{id}/starred": {
+            "patch": {
+                "description": "Mark an entry as starred or unstarred",
                 "consumes": [
                     "application/json"
                 ],
@@ -1290,25 +1492,73 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "settings"
+                    "entries"
                 ],
-                "summary": "Update general settings",
+                "summary": "Update starred status",
                 "parameters": [
                     {
-                        "description": "General settings",
-                        "name": "settings",
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Starred status",
+                        "name": "starred",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.generalSettingsRequest"
+                            "$ref": "#/definitions/internal_handler.updateStarredRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     }
+                }
+            }
+        },
+        "/entries/{id}/tags": {
+            "get": {
+                "description": "Get the tags of an entry, by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "List entry tags",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.generalSettingsResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handler.tagResponse"
+                            }
                         }
                     },
                     "400": {
@@ -1317,336 +1567,5259 @@ const docTemplate = `{
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     }
                 }
-            }
-        },
-        "/starred-count": {
-            "get": {
-                "description": "Get the total count of starred entries",
+            },
+            "post": {
+                "description": "Add a tag to an entry: an existing one by tagId, or one by name, created when no tag has that name ignoring case. Tagging an entry again with the same tag does nothing. Returns the entry's tags.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "entries"
+                    "tags"
+                ],
+                "summary": "Tag an entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag to add",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.assignTagRequest"
+                        }
+                    }
                 ],
-                "summary": "Get starred count",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.starredCountResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handler.tagResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     }
                 }
             }
         },
-        "/unread-counts": {
-            "get": {
-                "description": "Get a map of feed IDs to their respective unread entry counts",
+        "/entries/{id}/tags/{tagId}": {
+            "delete": {
+                "description": "Remove a tag from an entry. Returns the entry's tags.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "entries"
+                    "tags"
+                ],
+                "summary": "Untag an entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "tagId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get unread counts",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/internal_handler.unreadCountsResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handler.tagResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "gist_backend_internal_service.BatchTranslateResult": {
+        },
+        "/events": {
+            "get": {
+                "description": "Server-sent events about background work, each named by its type: feed_refreshed (feedId, title, newEntries) after a feed was fetched, unread_delta (feedId, delta) when a feed's unread count changed, and task (a task snapshot) when a task such as a refresh, OPML import or icon backfill starts, makes progress or ends, and notification (id, kind, title, body) when a notification was added to the inbox.\nA client that falls too far behind is disconnected; it should reconnect and reload what it shows.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Stream events",
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds": {
+            "get": {
+                "description": "Get a list of all subscribed feeds",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "List feeds",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by folder ID",
+                        "name": "folderId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handler.feedResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Subscribe to a new RSS, Atom or JSON Feed (1.0 and 1.1) feed. auth holds credentials for feeds behind HTTP Basic auth (username, password) or a token header (headers); they are stored encrypted and sent on every fetch of the feed.\nrequestOverrides is for sites that only answer certain clients: userAgent replaces the User-Agent (and the fallback User-Agent is not tried) and headers are added, on every request for the feed, its sitemaps, the readable content of its entries and its icon. Unlike auth they are stored as they are and returned; credentials are applied after them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Create a feed",
+                "parameters": [
+                    {
+                        "description": "Feed creation request",
+                        "name": "feed",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.createFeedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.feedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Feed URL already exists",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_handler.errorResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/internal_handler.feedConflictDetails"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Unsubscribe from multiple feeds at once",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Delete multiple feeds",
+                "parameters": [
+                    {
+                        "description": "Feed IDs to delete",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.deleteFeedsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/bulk-add": {
+            "post": {
+                "description": "Subscribe to each URL of a newline-separated list (up to 200, e.g. pasted from a blogroll), fetching several at once. Blank lines and repeats are skipped. Each URL gets a result in list order: added (with the new feed), exists (with the subscribed feed), invalid (not an http(s) URL) or failed (with the error; unlike POST /feeds, a URL that cannot be fetched as a feed is not subscribed to).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Add feeds from a URL list",
+                "parameters": [
+                    {
+                        "description": "URL list, folder and content type for the new feeds",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.bulkAddFeedsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.bulkAddFeedsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/discover": {
+            "get": {
+                "description": "Find the feeds of a website from any of its URLs. When the URL is a feed itself it is the only result (source direct). Otherwise the page's \u003clink rel=\"alternate\"\u003e feeds are checked (source link), and when it links none, the common paths /feed, /rss.xml, /atom.xml, /feed.xml, /index.xml and /rss of the site (source path).\nOnly candidates that fetch as feeds are returned, in page order with comment feeds last. The list is empty when none was found.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Discover feeds",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Website or feed URL",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handler.feedCandidateResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Bad Gateway",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/health": {
+            "get": {
+                "description": "Get the health of every feed from its last 50 refresh attempts: status (healthy, failing when the latest attempt failed, unknown before any attempt), consecutive failures, failed and total attempts, average fetch time, and the last attempt, success and error. Feeds failing the longest come first. cooldownUntil is set while the feed's host is left alone after answering 429 Too Many Requests: every feed on the host is skipped until then, for as long as its Retry-After asked (10 minutes without one, at most a day).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "List feed health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gist_backend_internal_service.FeedHealth"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/preview": {
+            "get": {
+                "description": "Fetch information about a feed from its URL",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Preview a feed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feed URL",
+                        "name": "url",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.feedPreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Fetch information about a feed behind HTTP Basic auth or a token header, or one that needs its own requestOverrides (see POST /feeds). Credentials go in the body rather than the query string, so they stay out of access logs; they are not stored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Preview a feed with credentials",
+                "parameters": [
+                    {
+                        "description": "Feed preview request",
+                        "name": "feed",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.previewFeedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.feedPreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/refresh": {
+            "post": {
+                "description": "Start a background refresh of all subscribed feeds. Poll the returned task via /tasks/{id}.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Refresh all feeds",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.Task"
+                        }
+                    },
+                    "409": {
+                        "description": "Refresh already in progress",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/scraped": {
+            "post": {
+                "description": "Subscribe to a web page without a feed by CSS selectors: items selects each entry on the page, and title, link and date select within an item. Without title an entry is titled by the item's text, without link it takes the item's first link, and without date it is dated when first seen; a date is read from the datetime attribute of a time element when there is one, else from the text. Links are resolved against the page URL, and at most 100 items are taken, in page order. Every refresh scrapes the page again, and new entries have their content fetched from their pages. Selectors that pick nothing out of the page are rejected with 400; when the page cannot be fetched the feed is created with the error and retried in the background. dateQuirks corrects how the dates picked out of the page are read, as for PUT /feeds/{id}, and requestOverrides replaces the User-Agent and headers the page is requested with, as for POST /feeds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Create a scraped feed",
+                "parameters": [
+                    {
+                        "description": "Scraped feed creation request",
+                        "name": "feed",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.createScrapedFeedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.feedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Page URL already subscribed",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/internal_handler.errorResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "details": {
+                                            "$ref": "#/definitions/internal_handler.feedConflictDetails"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/scraped/preview": {
+            "post": {
+                "description": "Fetch a page and return the entries the selectors pick out of it, as POST /feeds/scraped would create them, to try selectors before subscribing. publishedAt is unset for items without a date, and otherwise the date the first refresh would store under dateQuirks (see PUT /feeds/{id}) and the future dates setting. The page is requested with requestOverrides (see POST /feeds). Nothing is stored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Preview a scraped feed",
+                "parameters": [
+                    {
+                        "description": "Scraped feed preview request",
+                        "name": "feed",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.previewScrapedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.scrapePreviewResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/{id}": {
+            "put": {
+                "description": "Update the title, folder, refresh interval, full-text service, summary language, date corrections, scraper selectors, request overrides or credentials of an existing feed.\nrefreshInterval is in minutes (5 to 10080); 0 lets the scheduler decide, and omitting it keeps the current one.\nfullTextUrl is an external full-text service (Morss, FiveFilters) that new entries are extracted with during refresh and on demand, stored as their readable content, instead of the built-in readability.\n{url} in it stands for the escaped entry URL (e.g. https://ftr.example.com/extract.php?url={url}); without it the entry URL is appended (e.g. https://morss.it/:proxy/).\nThe service may answer with JSON carrying content, a feed whose first item holds the text, or the page itself. An empty string goes back to readability, and omitting it keeps the current one.\nsummaryInSourceLanguage has AI summaries of the feed's entries written in the article's own language (e.g. for language learning) instead of the global summary language; omitting it keeps the current choice.\nprefetchReadability has each refresh fetch the readable content of the feed's new entries in the background, so articles of truncated feeds are complete and available offline when opened; omitting it keeps the current choice.\ndateQuirks fixes feeds whose entries sort wrong because of their dates: timezone (IANA, e.g. Asia/Shanghai) is the zone of dates written without an offset, which are otherwise read as UTC; dayFirst reads numeric dates such as 03/04/2025 as 3 April; ignoreFuture never trusts a date in the future, using the entry's updated date when it is not, else the time it was first seen, whatever the future dates setting. An empty object removes the corrections, and omitting it keeps the current ones. They apply from the next refresh.\nscraper replaces the CSS selectors of a feed scraped from a web page (see POST /feeds/scraped); an empty object makes it an ordinary feed again, and omitting it keeps the current ones. Setting it on an ordinary feed has its URL scraped as a page from the next refresh.\nrequestOverrides replaces the User-Agent and headers the feed is requested with (see POST /feeds); an empty object goes back to the defaults, and omitting it keeps the current ones.\nauth replaces the credentials the feed is fetched with (HTTP Basic username and password, extra headers); an empty object removes them, and omitting it keeps the current ones. Stored credentials are never returned, only hasAuth.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Update a feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feed ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Feed update request",
+                        "name": "feed",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.updateFeedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.feedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Unsubscribe from a feed. mode decides what happens to its entries:\ndeleteAll (default) removes them, keepStarred keeps starred entries, keepAll keeps every entry.\nWhen entries are kept the feed is archived instead of removed; resubscribing restores it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Delete a feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feed ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "deleteAll, keepStarred or keepAll",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.deleteFeedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/{id}/enable": {
+            "post": {
+                "description": "Enable a feed that was disabled after failing too many refreshes in a row (see feedDisableThreshold in the general settings): its failure count is reset and it is refreshed in the next scheduled run. Enabling a feed that is not disabled just makes it due.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Enable a feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feed ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.feedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/{id}/fetch-readable": {
+            "post": {
+                "description": "Start extracting the readable content of every entry of a feed that has none, or of its unread entries only, for feeds that publish excerpts. Pages are fetched one at a time, through the feed's full-text service when it has one; entries that fail are skipped. Returns the task to poll via /tasks/{id}; its progress counts entries and its result is a ReadableBatchResult. One such task runs at a time.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Fetch readable content of a feed's entries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feed ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only unread entries",
+                        "name": "unreadOnly",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Extraction already in progress",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/{id}/health": {
+            "get": {
+                "description": "Get the health of a feed as in /feeds/health, with its last 20 refresh attempts (time, outcome, HTTP status, duration, new entries, error), newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Get feed health",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feed ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.FeedHealth"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/{id}/refresh": {
+            "post": {
+                "description": "Fetch a feed now and wait for the result, for debugging a broken subscription: the outcome (ok, not_modified, http_error or error), HTTP status, duration, new entries and the error, such as a parse error. A failed fetch is still a 200 response. The attempt is recorded in the feed's health as a scheduled one would be; the request times out after a minute.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Refresh a feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feed ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.FeedFetchAttempt"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id, or a feed that is not fetched, such as Saved pages",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Timed out, or host_cooling_down with a Retry-After header while the feed's host is left alone after answering 429 Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/feeds/{id}/type": {
+            "patch": {
+                "description": "Change the content type of a feed (article/picture/notification/podcast)",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Update feed type",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feed ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Type update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.updateTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/filters": {
+            "get": {
+                "description": "Get the filter rules applied to new entries during refresh, in the order they run",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "filters"
+                ],
+                "summary": "List filters",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handler.filterResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a rule that refresh applies to new entries of one feed (feedId) or of every feed.\nfield is what is matched (title, content, author or url) and match how: contains (case-insensitive) or regex (Go syntax).\nmatch expr makes pattern an expression (expr-lang syntax) over title, content (HTML), text (content as plain text), author and url, with no field;\nit must return a bool, except for action rewrite, which returns a map of title, content or author to replace, or nil.\naction is mark_read, star, skip (the entry is not stored), move, which files the entry under folderId in folder views, or rewrite.\nEntries already stored are not changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "filters"
+                ],
+                "summary": "Create a filter",
+                "parameters": [
+                    {
+                        "description": "Filter rule",
+                        "name": "filter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.filterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.filterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The feed or folder does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/filters/test": {
+            "post": {
+                "description": "Run a rule, without saving it, on a sample entry and return the entry as refresh would store it.\nThe rule is validated as for creating one; an expression that fails on the entry reports why in error.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "filters"
+                ],
+                "summary": "Test a filter",
+                "parameters": [
+                    {
+                        "description": "Filter rule and sample entry",
+                        "name": "test",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.filterTestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.filterTestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The feed or folder does not exist",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/filters/{id}": {
+            "put": {
+                "description": "Replace the rule of a filter. Fields are as for creating one.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "filters"
+                ],
+                "summary": "Update a filter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Filter rule",
+                        "name": "filter",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.filterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.filterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a filter rule",
+                "tags": [
+                    "filters"
+                ],
+                "summary": "Delete a filter",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/folders": {
+            "get": {
+                "description": "Get a list of all folders",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "List folders",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handler.folderResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new folder to organize feeds",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Create a folder",
+                "parameters": [
+                    {
+                        "description": "Folder creation request",
+                        "name": "folder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.folderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "Created"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete multiple folders at once (also deletes feeds in them)",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Delete multiple folders",
+                "parameters": [
+                    {
+                        "description": "Folder IDs to delete",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.deleteFoldersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/folders/{id}": {
+            "put": {
+                "description": "Update the name or parent ID of an existing folder",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Update a folder",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Folder update request",
+                        "name": "folder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.folderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.folderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete an existing folder",
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Delete a folder",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/folders/{id}/thumbnails": {
+            "patch": {
+                "description": "Cache scaled-down grid thumbnails of a picture folder's newest images right after each refresh, so the picture grid loads without fetching full images",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Toggle thumbnail pre-generation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Toggle request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.folderThumbnailsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Validation failed, or the folder is not a picture folder",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/folders/{id}/type": {
+            "patch": {
+                "description": "Change the content type of a folder (article/picture/notification/podcast)",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folders"
+                ],
+                "summary": "Update folder type",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Type update request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.updateFolderTypeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/icons/backfill": {
+            "post": {
+                "description": "Start fetching icons for feeds without one and restoring missing or month-old icon files, a few feeds at a time and one per site. Also runs at startup.\nReturns the task to poll via /tasks/{id}: current and total count the feeds done, and the result is an IconBackfillResult listing the feeds whose icon could not be found.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "feeds"
+                ],
+                "summary": "Backfill feed icons",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.Task"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/link-checks/broken": {
+            "get": {
+                "description": "Get starred entries whose URL failed its last health check, dead links (404, 410, unknown host) first, then ones failing for other reasons (5xx, timeouts). Their content stays readable in Gist.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "link-checks"
+                ],
+                "summary": "List broken starred links",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handler.brokenLinkResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/link-checks/run": {
+            "post": {
+                "description": "Start checking the links of starred entries not checked in the last week. Also runs daily. Returns the task to poll via /tasks/{id}; its result is a LinkCheckResult.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "link-checks"
+                ],
+                "summary": "Check starred links",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.Task"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "description": "Get the inbox of background work outcomes, newest first: OPML imports that finished and tasks that failed. The newest 100 are kept. New ones are also sent as notification events on /events.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "List notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only return unread notifications",
+                        "name": "unreadOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Limit the number of notifications (1-100, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.notificationListResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/mark-read": {
+            "post": {
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark all notifications read",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}": {
+            "delete": {
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Dismiss a notification",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/read": {
+            "post": {
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Mark a notification read",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/opds": {
+            "get": {
+                "description": "OPDS 1.2 acquisition feed of starred entries, newest first, 50 per page with next/previous links. Add this URL to an e-reader app such as KOReader to browse saved articles and download them as EPUB books.",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "OPDS catalog of starred entries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number, from 1",
+                        "name": "page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OPDS acquisition feed",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/opds/entries/{file}": {
+            "get": {
+                "description": "Convert an entry to an EPUB 3 book on the fly, using its readable content when extracted and the feed content otherwise. Images and embedded media are left out.",
+                "produces": [
+                    "application/epub+zip"
+                ],
+                "tags": [
+                    "opds"
+                ],
+                "summary": "Download an entry as EPUB",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Entry ID followed by .epub",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "EPUB book",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/opml/export": {
+            "get": {
+                "description": "Export all feeds and folders to an OPML file",
+                "produces": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "opml"
+                ],
+                "summary": "Export OPML",
+                "responses": {
+                    "200": {
+                        "description": "OPML file content",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/opml/import": {
+            "post": {
+                "description": "Validate an OPML file and start importing its feeds and folders in the background.\nThe file is parsed while it streams in; malformed documents are rejected before the task starts.\nWith dryRun=true the folders and feeds that would be created, skipped duplicates and type conflicts are returned instead.\nThe returned taskId follows the import with GET /opml/import/{taskId} and cancels it with DELETE /opml/import/{taskId}. Progress is saved after every feed, and an import the server stopped in the middle of resumes from there on the next start.",
+                "consumes": [
+                    "multipart/form-data",
+                    "text/xml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "opml"
+                ],
+                "summary": "Import OPML",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "OPML file to import",
+                        "name": "file",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only report what would be imported, without writing anything",
+                        "name": "dryRun",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "When dryRun is true",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.ImportPreview"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Another import is running",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancel the current import task",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "opml"
+                ],
+                "summary": "Cancel Import",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.importCancelledResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/opml/import/status": {
+            "get": {
+                "description": "Get current import task status via SSE stream",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "opml"
+                ],
+                "summary": "Import Status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.Task"
+                        }
+                    }
+                }
+            }
+        },
+        "/opml/import/{taskId}": {
+            "get": {
+                "description": "Get an import task by the ID the import returned: live progress while it runs, and its saved state once it has ended, also after a restart. current counts the feeds processed; result holds the counts of a finished import.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "opml"
+                ],
+                "summary": "Get import",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Import task ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.Task"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop an import task by ID. Feeds and folders it created so far are kept and can be removed with POST /opml/imports/{taskId}/undo. cancelled is false when the task had already ended.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "opml"
+                ],
+                "summary": "Cancel import task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Import task ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.importCancelledResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/opml/imports/{taskId}/undo": {
+            "post": {
+                "description": "Delete the feeds and folders created by an OPML import task.\nFolders that now contain feeds or subfolders from elsewhere are kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "opml"
+                ],
+                "summary": "Undo import",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Import task ID",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.ImportUndoResult"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plain/entries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiToken": []
+                    }
+                ],
+                "description": "List entries as plain text for terminals and scripts, newest first, e.g. curl -H \"X-Gist-Token: …\" \u003csite\u003e/api/plain/entries?unreadOnly=true. Each entry is a block of lines: \"[id] title\", then feed, date (UTC), unread and starred, then the link; blocks are separated by a blank line. When more entries follow, the last line is \"Next: \" and the path of the next page. Takes the same filters as the entry list. Requires the API token as a Bearer token or X-Gist-Token header.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Plain-text entry list",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Filter by feed ID",
+                        "name": "feedId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by folder ID",
+                        "name": "folderId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by content type (article, picture, notification, podcast)",
+                        "name": "contentType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only unread entries",
+                        "name": "unreadOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only starred entries",
+                        "name": "starredOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only entries published in this UTC year (YYYY) or month (YYYY-MM)",
+                        "name": "period",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Leave out entries flagged as not safe for work",
+                        "name": "excludeNsfw",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by tag ID",
+                        "name": "tagId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of entries (1-100, default 50)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Continue after this cursor (publishedAt,id), from the Next line",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entry list",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/plain/entries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiToken": []
+                    }
+                ],
+                "description": "Get an entry as plain text for terminals and scripts: the title, a line with feed, author, date (UTC), unread and starred, the link, then the text of its readable content when extracted and the feed content otherwise, a paragraph per block wrapped at width columns. Requires the API token as a Bearer token or X-Gist-Token header.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Plain-text entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Column to wrap text at (0 for none, default 80)",
+                        "name": "width",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Entry text",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/preferences": {
+            "get": {
+                "description": "Get the user preferences stored by the frontend, such as shortcut remaps (shortcuts) and gestures (gestures), as an object of JSON values by key.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get preferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Store the given preferences, leaving those not listed as they are; null deletes one. Keys are dot-separated lowercase segments of at most 64 characters. A value is at most 16 KiB of JSON; shortcuts and gestures must be objects of strings. All preferences together are at most 200 keys and 256 KiB.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update preferences",
+                "parameters": [
+                    {
+                        "description": "Preferences to store",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reader": {
+            "get": {
+                "description": "Server-rendered HTML page of unread entries, newest first, 30 per page with a link to older ones. It needs no scripts, for e-ink devices and text browsers such as w3m or lynx; each entry links to its article page.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "reader"
+                ],
+                "summary": "Reader list of unread entries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Continue after this cursor (publishedAt,id), from the page's link to older entries",
+                        "name": "after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML document",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reader/{id}": {
+            "get": {
+                "description": "Server-rendered HTML page of an entry: its readable content when extracted and the feed content otherwise, reduced as in the print view, with a form to mark it read that needs no scripts.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "reader"
+                ],
+                "summary": "Reader article page",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "HTML document",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reader/{id}/read": {
+            "post": {
+                "description": "Form post of the reader article page. Marks the entry as read, or unread with read=false, and redirects to the reader list.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "tags": [
+                    "reader"
+                ],
+                "summary": "Mark an entry read from the reader",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Read status (default true)",
+                        "name": "read",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "303": {
+                        "description": "Redirect to the reader list"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduler": {
+            "get": {
+                "description": "Get each background job (refresh, link_check, and sync on a secondary) with its interval, pause state, skip reason (e.g. low-data mode), next run and the outcome and duration of its latest run",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "List scheduled jobs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gist_backend_internal_scheduler.JobStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduler/{kind}/pause": {
+            "post": {
+                "description": "Stop starting the job on its schedule, e.g. to avoid refreshing on a metered connection. A run in progress is not cancelled and manual runs still work. Pauses last until resumed or the server restarts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Pause a scheduled job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job kind (refresh, link_check, sync)",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_scheduler.JobStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/scheduler/{kind}/resume": {
+            "post": {
+                "description": "Resume a paused job. It next runs at its regular interval.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "scheduler"
+                ],
+                "summary": "Resume a scheduled job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job kind (refresh, link_check, sync)",
+                        "name": "kind",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_scheduler.JobStatus"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/ai": {
+            "get": {
+                "description": "Get the AI provider configuration with masked API keys",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get AI settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.aiSettingsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the AI provider configuration. Empty apiKey keeps existing key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update AI settings",
+                "parameters": [
+                    {
+                        "description": "AI settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.aiSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.aiSettingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/ai/test": {
+            "post": {
+                "description": "Test the AI provider connection with a \"Hello world\" message",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Test AI connection",
+                "parameters": [
+                    {
+                        "description": "AI test configuration",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.aiTestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.aiTestResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/appearance": {
+            "get": {
+                "description": "Get how clients show Gist, kept on the server so every device looks the same: theme (light, dark or auto to follow the device), accentColor (#rrggbb, empty for the default), fontSize of entry content in pixels (default 16) and the view entries of each content type are listed in (list or masonry; default masonry for picture, list otherwise).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get appearance settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.AppearanceSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the appearance settings. Omitted fields, and content types missing from defaultViews, keep their current values; an empty accentColor restores the default. fontSize is 12 to 24.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update appearance settings",
+                "parameters": [
+                    {
+                        "description": "Appearance settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.appearanceSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.AppearanceSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/general": {
+            "get": {
+                "description": "Get general application settings including fallback user agent, auto readability, low-data mode, NSFW filtering, the cookie allowlist, per-host TLS fingerprints and entry retention. lowDataActive reports whether low-data mode is in effect now, by switch or by schedule. nsfwMode (show/blur/hide) tells clients how to present flagged entries.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get general settings",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.generalSettingsResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update general application settings. lowData, lowDataSchedule, the nsfw fields, cookieHosts, tlsFingerprints, iconSources, the retention fields, feedDisableThreshold, categoryFolders, the duplicate title fields, embedHosts, futureDates, contentRefetchWindow and the backup fields keep their current values when omitted; an empty schedule removes it. nsfwKeywords holds comma- or line-separated keywords matched as whole words against titles and categories of new entries. cookieHosts lists the comma- or line-separated hosts (subdomains included) whose cookies are kept across fetches; the stored cookies of hosts taken off the list are deleted. tlsFingerprints holds comma- or line-separated host=browser pairs (browser: chrome, firefox, safari; subdomains included) for hosts whose feeds must be fetched with a browser's TLS and HTTP/2 fingerprint. iconSources is the comma-separated order feed icons are looked up in, from feed (the feed's image), site (the site's /favicon.ico), google and duckduckgo; sources left out are never used, and an empty list restores the default feed,site,google,duckduckgo. retentionDays and retentionMaxPerFeed (both kept when omitted, 0 turns the rule off) have the daily prune job delete unstarred entries fetched more than that many days ago or past the newest that many of their feed. feedDisableThreshold (default 10, 0 never disables) is the number of failed refreshes in a row after which a feed is disabled until re-enabled. categoryFolders files feeds added without a folder (one by one, in bulk or by OPML import) into a top-level folder named after the first category the feed declares, created when missing. duplicateTitles (off, exact or fuzzy; default off) has refresh drop new entries whose title repeats one of the same feed from the last duplicateTitleWindow hours (1 to 720, default 48): exact ignores case and spacing, fuzzy also punctuation and small differences. embedHosts lists the comma- or line-separated hosts (subdomains included, default youtube.com,youtube-nocookie.com,vimeo.com) whose iframes are kept when new entry content and readable content are sanitized before being stored; an empty list keeps none. futureDates (clamp, hide or keep; default clamp) is how new entries dated in the future are stored: clamp dates them when first seen and keeps the feed's date as originalPublishedAt, hide stores them only at a refresh after their date, keep stores the date as given. contentRefetchWindow (0 to 168 hours, default 0 for off) has refreshes of a feed fetch the page of its entries found without content within that many hours, a few per refresh, filling the content in place. backupInterval (0 to 720 hours, default 0 for off) has the backup job write a zip of the OPML export and the starred entries to the backups directory that many hours after the latest, keeping the newest backupKeep (1 to 100, default 7).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update general settings",
+                "parameters": [
+                    {
+                        "description": "General settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.generalSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.generalSettingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/integrations": {
+            "get": {
+                "description": "Get the Wallabag, Pocket and Linkding configuration entries can be pushed with. Secrets (clientSecret, password, accessToken, token) are masked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get read-later integrations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.IntegrationSettings"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Save the configuration of every read-later service. A masked or empty secret keeps the stored one; an empty url (for Pocket, consumerKey) removes the service with its secrets.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update read-later integrations",
+                "parameters": [
+                    {
+                        "description": "Integration settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.IntegrationSettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.IntegrationSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/settings/low-data": {
+            "put": {
+                "description": "Turn low-data mode on or off without touching other settings, e.g. from an automation when roaming starts. While it is active, scheduled refreshes and syncs and the startup icon backfill are skipped and clients stop automatic AI summaries and translations. A configured schedule still applies when the switch is off.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Switch low-data mode",
+                "parameters": [
+                    {
+                        "description": "Low-data switch",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.lowDataRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.generalSettingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/share": {
+            "post": {
+                "description": "Target of the PWA manifest's share_target. Saves the shared page into the Saved pages feed and redirects to it in the app. The page URL is taken from url, or else from the first link in text, since many Android apps share the link as text.",
+                "consumes": [
+                    "application/x-www-form-urlencoded",
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Web Share Target",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Shared title",
+                        "name": "title",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Shared text",
+                        "name": "text",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Shared URL",
+                        "name": "url",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "303": {
+                        "description": "Redirect to the saved entry"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/starred-count": {
+            "get": {
+                "description": "Get the total count of starred entries",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Get starred count",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.starredCountResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/starred-counts": {
+            "get": {
+                "description": "Get maps of feed IDs (counts) and folder IDs (folders) to their starred entry counts, in the shape of the unread counts. An entry a filter moved counts under its own folder rather than its feed's; a folder's count leaves out its subfolders. Feeds and folders without starred entries are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Get starred counts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.starredCountsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats/bandwidth": {
+            "get": {
+                "description": "Get the bytes downloaded for each feed over the last days (today included), most first, with a daily breakdown by server local date.\nCounts the feed and sitemap fetches of refreshes and the pages and thumbnails fetched for entries in the background; images loaded through the proxy while reading are not counted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get bandwidth per feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 30,
+                        "description": "Days to cover, 1 to 90",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.BandwidthUsage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sync/changes": {
+            "get": {
+                "security": [
+                    {
+                        "ApiToken": []
+                    }
+                ],
+                "description": "Used by secondary instances to mirror this one. Returns all folders and feeds, plus the entry read/starred changes after cursor in log order. Pass the returned cursor to the next call until hasMore is false. Requires the API token (the pairing token) as a Bearer token or X-Gist-Token header.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Sync change feed",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Change-log position already applied (default 0)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum entry changes to return (1-1000, default 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.SyncChanges"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sync/run": {
+            "post": {
+                "description": "Start pulling subscriptions and read state from the configured primary instance. Returns the task to poll via /tasks/{id}; its result is a SyncResult.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sync"
+                ],
+                "summary": "Sync from primary",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.Task"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags": {
+            "get": {
+                "description": "Get every tag with the number of entries that have it, by name. List a tag's entries with /entries?tagId=.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "List tags",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/internal_handler.tagResponse"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a tag to organize entries with. Names are 1 to 64 characters and unique ignoring case.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Create a tag",
+                "parameters": [
+                    {
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.tagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.tagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tags/{id}": {
+            "put": {
+                "description": "Rename a tag, keeping its entries",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Rename a tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tag",
+                        "name": "tag",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.tagRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.tagResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a tag, removing it from its entries; the entries are kept",
+                "tags": [
+                    "tags"
+                ],
+                "summary": "Delete a tag",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Tag ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks": {
+            "get": {
+                "description": "Get the most recent background task of each kind (import, refresh, icon_backfill), newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "List tasks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gist_backend_internal_service.Task"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/tasks/{id}": {
+            "get": {
+                "description": "Get the status and progress of a background task",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Get a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gist_backend_internal_service.Task"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancel a running background task. cancelled is false if it had already finished.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "Cancel a task",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Task ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.cancelTaskResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/unread-counts": {
+            "get": {
+                "description": "Get maps of feed IDs (counts) and folder IDs (folders) to their unread entry counts, the total of unread entries (total) and of starred entries (starredTotal), so sidebar badges render from one call. With contentType only feeds of that type are counted; with folderId only entries in the folder and its subfolders are, counting an entry a filter moved under its folder rather than its feed's. As in /starred-counts, a folder's count leaves out its subfolders and counts the entries filters moved to it. Feeds and folders without unread entries in scope are left out; starredTotal ignores the scope.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "entries"
+                ],
+                "summary": "Get unread counts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Count only feeds of this content type (article, picture, notification, podcast)",
+                        "name": "contentType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Count only entries in this folder subtree",
+                        "name": "folderId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.unreadCountsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/websub/callback/{feedId}": {
+            "get": {
+                "description": "Called by a WebSub hub to verify the subscription of a feed that this instance requested (see GIST_PUBLIC_URL). For hub.mode=subscribe with the subscribed topic, the subscription is activated for hub.lease_seconds and hub.challenge is echoed as text/plain; hub.mode=denied records that the hub refused it. Requests for subscriptions this instance did not make, including unsubscriptions, get 404.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "websub"
+                ],
+                "summary": "Verify a WebSub subscription",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feed ID",
+                        "name": "feedId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "subscribe, unsubscribe or denied",
+                        "name": "hub.mode",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Topic URL of the subscription",
+                        "name": "hub.topic",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Challenge to echo (subscribe)",
+                        "name": "hub.challenge",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Lease granted by the hub (subscribe)",
+                        "name": "hub.lease_seconds",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The challenge",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Called by a WebSub hub with new content of a subscribed feed, signed in X-Hub-Signature (sha1, sha256, sha384 or sha512 HMAC with the subscription's secret). The entries are stored at once, as a refresh would. Content with a missing or wrong signature is acknowledged but ignored, as the WebSub specification asks; content for a feed without an active subscription gets 404.",
+                "consumes": [
+                    "text/xml"
+                ],
+                "tags": [
+                    "websub"
+                ],
+                "summary": "Receive WebSub content",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Feed ID",
+                        "name": "feedId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "method=hex HMAC of the body",
+                        "name": "X-Hub-Signature",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/internal_handler.errorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "gist_backend_internal_scheduler.JobStatus": {
+            "type": "object",
+            "properties": {
+                "intervalSeconds": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "lastRun": {
+                    "$ref": "#/definitions/gist_backend_internal_scheduler.LastRun"
+                },
+                "nextRunAt": {
+                    "description": "unset while paused",
+                    "type": "string"
+                },
+                "paused": {
+                    "type": "boolean"
+                },
+                "skipReason": {
+                    "description": "why scheduled runs are being skipped, e.g. low-data mode",
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_scheduler.LastRun": {
+            "type": "object",
+            "properties": {
+                "durationMs": {
+                    "description": "unset while running",
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "taskId": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.AppearanceSettings": {
+            "type": "object",
+            "properties": {
+                "accentColor": {
+                    "description": "AccentColor is a #rrggbb color, or empty for the default.",
+                    "type": "string"
+                },
+                "defaultViews": {
+                    "description": "DefaultViews maps each content type to the view its entries are\nlisted in, ViewList or ViewMasonry.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "fontSize": {
+                    "description": "FontSize is the size of entry content in CSS pixels.",
+                    "type": "integer"
+                },
+                "theme": {
+                    "description": "Theme is ThemeLight, ThemeDark or ThemeAuto (the default).",
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.BackupFile": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gist_backend_internal_service.BandwidthUsage": {
+            "type": "object",
+            "properties": {
+                "feeds": {
+                    "description": "most bytes first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.FeedBandwidthUsage"
+                    }
+                },
+                "since": {
+                    "type": "string"
+                },
+                "totalBytes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gist_backend_internal_service.BatchTranslateResult": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.DayBandwidthUsage": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "day": {
+                    "description": "YYYY-MM-DD, server local time",
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.FeedBandwidthUsage": {
+            "type": "object",
+            "properties": {
+                "bytes": {
+                    "type": "integer"
+                },
+                "days": {
+                    "description": "oldest first, days without downloads left out",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.DayBandwidthUsage"
+                    }
+                },
+                "feedId": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.FeedFetchAttempt": {
+            "type": "object",
+            "properties": {
+                "durationMs": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fetchedAt": {
+                    "type": "string"
+                },
+                "httpStatus": {
+                    "type": "integer"
+                },
+                "newEntries": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "one of FetchOutcomes",
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.FeedHealth": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "avgDurationMs": {
+                    "type": "integer"
+                },
+                "consecutiveFailures": {
+                    "description": "ConsecutiveFailures counts the failed attempts since the last success.",
+                    "type": "integer"
+                },
+                "cooldownUntil": {
+                    "description": "CooldownUntil is set while the feed's host is left alone after it\nanswered 429 Too Many Requests; refreshes skip the feed until then.",
+                    "type": "string"
+                },
+                "failures": {
+                    "type": "integer"
+                },
+                "feedId": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "history": {
+                    "description": "History lists the latest attempts, newest first; only the health of a\nsingle feed has it.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.FeedFetchAttempt"
+                    }
+                },
+                "lastAttemptAt": {
+                    "type": "string"
+                },
+                "lastError": {
+                    "description": "LastError is the error of the latest failed attempt.",
+                    "type": "string"
+                },
+                "lastSuccessAt": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.ImportPreview": {
+            "type": "object",
+            "properties": {
+                "duplicates": {
+                    "description": "already subscribed or repeated in the file",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.ImportPreviewFeed"
+                    }
+                },
+                "feeds": {
+                    "description": "feeds that would be created",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.ImportPreviewFeed"
+                    }
+                },
+                "folders": {
+                    "description": "folders that would be created",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.ImportPreviewFolder"
+                    }
+                },
+                "invalid": {
+                    "description": "missing or malformed feed URLs",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.ImportPreviewFeed"
+                    }
+                },
+                "summary": {
+                    "$ref": "#/definitions/gist_backend_internal_service.ImportResult"
+                },
+                "typeConflicts": {
+                    "description": "duplicates whose existing type differs from the target folder",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.ImportTypeConflict"
+                    }
+                }
+            }
+        },
+        "gist_backend_internal_service.ImportPreviewFeed": {
+            "type": "object",
+            "properties": {
+                "folder": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.ImportPreviewFolder": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.ImportResult": {
+            "type": "object",
+            "properties": {
+                "feedsCreated": {
+                    "type": "integer"
+                },
+                "feedsSkipped": {
+                    "type": "integer"
+                },
+                "foldersCreated": {
+                    "type": "integer"
+                },
+                "foldersSkipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gist_backend_internal_service.ImportTypeConflict": {
+            "type": "object",
+            "properties": {
+                "existingFolder": {
+                    "type": "string"
+                },
+                "existingType": {
+                    "type": "string"
+                },
+                "folder": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.ImportUndoResult": {
+            "type": "object",
+            "properties": {
+                "feedsDeleted": {
+                    "type": "integer"
+                },
+                "foldersDeleted": {
+                    "type": "integer"
+                },
+                "foldersKept": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gist_backend_internal_service.IntegrationSaveResult": {
+            "type": "object",
+            "properties": {
+                "provider": {
+                    "type": "string"
+                },
+                "remoteId": {
+                    "description": "RemoteID is the ID the service gave the saved page, when it returns one.",
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.IntegrationSettings": {
+            "type": "object",
+            "properties": {
+                "linkding": {
+                    "$ref": "#/definitions/gist_backend_internal_service.LinkdingIntegration"
+                },
+                "pocket": {
+                    "$ref": "#/definitions/gist_backend_internal_service.PocketIntegration"
+                },
+                "wallabag": {
+                    "$ref": "#/definitions/gist_backend_internal_service.WallabagIntegration"
+                }
+            }
+        },
+        "gist_backend_internal_service.LinkdingIntegration": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.PocketIntegration": {
+            "type": "object",
+            "properties": {
+                "accessToken": {
+                    "type": "string"
+                },
+                "consumerKey": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.RestoreResult": {
+            "type": "object",
+            "properties": {
+                "rowsRestored": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gist_backend_internal_service.SyncChanges": {
+            "type": "object",
+            "properties": {
+                "cursor": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.SyncEntryState"
+                    }
+                },
+                "feeds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.SyncFeed"
+                    }
+                },
+                "folders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gist_backend_internal_service.SyncFolder"
+                    }
+                },
+                "hasMore": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "gist_backend_internal_service.SyncEntryState": {
+            "type": "object",
+            "properties": {
+                "feedUrl": {
+                    "type": "string"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "starred": {
+                    "type": "boolean"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.SyncFeed": {
+            "type": "object",
+            "properties": {
+                "folder": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.SyncFolder": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.Task": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "integer"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "result": {},
+                "status": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "gist_backend_internal_service.WallabagIntegration": {
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "type": "string"
+                },
+                "clientSecret": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.WipeConfirmation": {
+            "type": "object",
+            "properties": {
+                "expiresAt": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "gist_backend_internal_service.WipeResult": {
+            "type": "object",
+            "properties": {
+                "filesDeleted": {
+                    "type": "integer"
+                },
+                "rowsDeleted": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handler.ErrorCode": {
+            "type": "string",
+            "enum": [
+                "invalid_request",
+                "validation_failed",
+                "invalid_id",
+                "missing_field",
+                "invalid_url",
+                "batch_too_large",
+                "missing_file",
+                "file_too_large",
+                "preferences_too_large",
+                "unsupported_media_type",
+                "not_found",
+                "method_not_allowed",
+                "conflict",
+                "feed_exists",
+                "refresh_in_progress",
+                "import_in_progress",
+                "sync_in_progress",
+                "sync_not_configured",
+                "link_check_in_progress",
+                "icon_backfill_in_progress",
+                "fetch_readable_in_progress",
+                "idempotency_key_in_use",
+                "unauthorized",
+                "demo_mode",
+                "read_only",
+                "feed_fetch_failed",
+                "host_cooling_down",
+                "content_fetch_failed",
+                "upstream_timeout",
+                "request_timeout",
+                "image_fetch_failed",
+                "ai_request_failed",
+                "integration_not_configured",
+                "integration_failed",
+                "internal_error"
+            ],
+            "x-enum-varnames": [
+                "CodeInvalidRequest",
+                "CodeValidationFailed",
+                "CodeInvalidID",
+                "CodeMissingField",
+                "CodeInvalidURL",
+                "CodeBatchTooLarge",
+                "CodeMissingFile",
+                "CodeFileTooLarge",
+                "CodePreferencesTooLarge",
+                "CodeUnsupportedMediaType",
+                "CodeNotFound",
+                "CodeMethodNotAllowed",
+                "CodeConflict",
+                "CodeFeedExists",
+                "CodeRefreshInProgress",
+                "CodeImportInProgress",
+                "CodeSyncInProgress",
+                "CodeSyncNotConfigured",
+                "CodeLinkCheckInProgress",
+                "CodeIconBackfillInProgress",
+                "CodeFetchReadableInProgress",
+                "CodeIdempotencyKeyInUse",
+                "CodeUnauthorized",
+                "CodeDemoMode",
+                "CodeReadOnly",
+                "CodeFeedFetchFailed",
+                "CodeHostCoolingDown",
+                "CodeContentFetchFailed",
+                "CodeUpstreamTimeout",
+                "CodeRequestTimeout",
+                "CodeImageFetchFailed",
+                "CodeAIRequestFailed",
+                "CodeIntegrationNotConfigured",
+                "CodeIntegrationFailed",
+                "CodeInternal"
+            ]
+        },
+        "internal_handler.FieldError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "invalid_url"
+                },
+                "field": {
+                    "type": "string",
+                    "example": "url"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "must be an http or https URL"
+                }
+            }
+        },
+        "internal_handler.aiSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "type": "string"
+                },
+                "autoSummary": {
+                    "type": "boolean"
+                },
+                "autoTranslate": {
+                    "type": "boolean"
+                },
+                "baseUrl": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "rateLimit": {
+                    "type": "integer"
+                },
+                "reasoningEffort": {
+                    "type": "string"
+                },
+                "summaryLanguage": {
+                    "type": "string"
+                },
+                "thinking": {
+                    "type": "boolean"
+                },
+                "thinkingBudget": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handler.aiSettingsResponse": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "type": "string"
+                },
+                "autoSummary": {
+                    "type": "boolean"
+                },
+                "autoTranslate": {
+                    "type": "boolean"
+                },
+                "baseUrl": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "rateLimit": {
+                    "type": "integer"
+                },
+                "reasoningEffort": {
+                    "type": "string"
+                },
+                "summaryLanguage": {
+                    "type": "string"
+                },
+                "thinking": {
+                    "type": "boolean"
+                },
+                "thinkingBudget": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handler.aiTestRequest": {
+            "type": "object",
+            "properties": {
+                "apiKey": {
+                    "type": "string"
+                },
+                "baseUrl": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "reasoningEffort": {
+                    "type": "string"
+                },
+                "thinking": {
+                    "type": "boolean"
+                },
+                "thinkingBudget": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handler.aiTestResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_handler.appearanceSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "accentColor": {
+                    "type": "string"
+                },
+                "defaultViews": {
+                    "description": "Content types left out keep their view",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "fontSize": {
+                    "type": "integer"
+                },
+                "theme": {
+                    "description": "Fields are kept as they are when omitted",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.archivePeriodResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "period": {
+                    "type": "string",
+                    "example": "2025-03"
+                },
+                "unreadCount": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handler.archiveResponse": {
+            "type": "object",
+            "properties": {
+                "groupBy": {
+                    "type": "string",
+                    "example": "month"
+                },
+                "periods": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handler.archivePeriodResponse"
+                    }
+                }
+            }
+        },
+        "internal_handler.assignTagRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "tagId": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.batchTranslateRequest": {
+            "type": "object",
+            "properties": {
+                "articles": {
+                    "type": "array",
+                    "items": {
+                        "type": "object",
+                        "properties": {
+                            "id": {
+                                "type": "string"
+                            },
+                            "summary": {
+                                "type": "string"
+                            },
+                            "title": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "internal_handler.brokenLinkResponse": {
+            "type": "object",
+            "properties": {
+                "checkedAt": {
+                    "type": "string"
+                },
+                "entryId": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failingSince": {
+                    "type": "string"
+                },
+                "feedId": {
+                    "type": "string"
+                },
+                "feedTitle": {
+                    "type": "string"
+                },
+                "httpStatus": {
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "dead or error",
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.bulkAddFeedsRequest": {
+            "type": "object",
+            "properties": {
+                "folderId": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "urls": {
+                    "description": "URLs is a newline-separated list of feed URLs.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.bulkAddFeedsResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handler.bulkAddResultResponse"
+                    }
+                }
+            }
+        },
+        "internal_handler.bulkAddResultResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "feed": {
+                    "$ref": "#/definitions/internal_handler.feedResponse"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.cancelTaskResponse": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_handler.captureRequest": {
+            "type": "object",
+            "properties": {
+                "html": {
+                    "description": "HTML is the page as the browser rendered it, e.g. document.documentElement.outerHTML.\nWithout it the page is fetched.",
+                    "type": "string"
+                },
+                "title": {
+                    "description": "Title overrides the page title",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.clearCacheResponse": {
+            "type": "object",
+            "properties": {
+                "listTranslations": {
+                    "type": "integer"
+                },
+                "summaries": {
+                    "type": "integer"
+                },
+                "translations": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handler.createFeedRequest": {
+            "type": "object",
+            "properties": {
+                "auth": {
+                    "$ref": "#/definitions/internal_handler.feedAuthRequest"
+                },
+                "folderId": {
+                    "type": "string"
+                },
+                "requestOverrides": {
+                    "$ref": "#/definitions/internal_handler.feedRequestOverrides"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.createScrapedFeedRequest": {
+            "type": "object",
+            "properties": {
+                "auth": {
+                    "$ref": "#/definitions/internal_handler.feedAuthRequest"
+                },
+                "dateQuirks": {
+                    "$ref": "#/definitions/internal_handler.feedDateQuirks"
+                },
+                "folderId": {
+                    "type": "string"
+                },
+                "requestOverrides": {
+                    "$ref": "#/definitions/internal_handler.feedRequestOverrides"
+                },
+                "scraper": {
+                    "$ref": "#/definitions/internal_handler.feedScraper"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the page to scrape.",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.deleteFeedResponse": {
+            "type": "object",
+            "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
+                "entriesDeleted": {
+                    "type": "integer"
+                },
+                "entriesKept": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handler.deleteFeedsRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_handler.deleteFoldersRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "internal_handler.entryAttachmentResponse": {
+            "type": "object",
+            "properties": {
+                "durationSeconds": {
+                    "type": "integer"
+                },
+                "mimeType": {
+                    "type": "string",
+                    "example": "audio/mpeg"
+                },
+                "sizeBytes": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.entryBatchRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "operation": {
+                    "description": "Operation is read, unread, star, unstar or delete",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.entryBatchResponse": {
+            "type": "object",
+            "properties": {
+                "updated": {
+                    "description": "Updated counts the entries that changed; those already in the requested\nstate and unknown IDs are not counted",
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handler.entryListResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handler.entrySummaryResponse"
+                    }
+                },
+                "hasMore": {
+                    "type": "boolean"
+                },
+                "nextCursor": {
+                    "description": "NextCursor continues the list with ?after=, set by the list when hasMore",
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.entryResponse": {
+            "type": "object",
+            "properties": {
+                "attachments": {
+                    "description": "Attachments are the files attached to the entry, like podcast audio",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handler.entryAttachmentResponse"
+                    }
+                },
+                "author": {
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "externalUrl": {
+                    "description": "ExternalURL is the page a linkblog entry is about, apart from url",
+                    "type": "string"
+                },
+                "feedId": {
+                    "type": "string"
+                },
+                "iconPath": {
+                    "description": "IconPath is the site icon of a saved page, served under /icons; absent for feed entries",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "images": {
+                    "description": "Images is the ordered gallery of an entry of a picture feed",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "linkDead": {
+                    "description": "LinkDead means the original URL is gone and this is the only copy",
+                    "type": "boolean"
+                },
+                "nsfwReason": {
+                    "description": "NSFWReason is why the entry is flagged as not safe for work: category, explicit, keyword or ai",
+                    "type": "string"
+                },
+                "originalPublishedAt": {
+                    "description": "OriginalPublishedAt is the feed's publish date when it was in the\nfuture and publishedAt is when the entry was first seen instead",
+                    "type": "string"
+                },
+                "publishedAt": {
+                    "type": "string"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "readableByline": {
+                    "type": "string"
+                },
+                "readableContent": {
+                    "type": "string"
+                },
+                "readableDir": {
+                    "description": "ReadableDir is the text direction of the readable content: rtl or ltr",
+                    "type": "string"
+                },
+                "readableLang": {
+                    "type": "string"
+                },
+                "readableSiteName": {
+                    "type": "string"
+                },
+                "revision": {
+                    "description": "Revision is what the publisher changed in their latest update",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_handler.entryRevisionResponse"
+                        }
+                    ]
+                },
+                "starred": {
+                    "type": "boolean"
+                },
+                "thumbnailUrl": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.entryRevisionResponse": {
+            "type": "object",
+            "properties": {
+                "changedAt": {
+                    "type": "string"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handler.textChangeResponse"
+                    }
+                },
+                "titleBefore": {
+                    "type": "string"
+                },
+                "wordsAdded": {
+                    "type": "integer"
+                },
+                "wordsRemoved": {
+                    "type": "integer"
+                }
+            }
+        },
+        "internal_handler.entrySummaryResponse": {
+            "type": "object",
+            "properties": {
+                "author": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "feedId": {
+                    "type": "string"
+                },
+                "iconPath": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "nsfwReason": {
+                    "type": "string"
+                },
+                "publishedAt": {
+                    "type": "string"
+                },
+                "read": {
+                    "type": "boolean"
+                },
+                "snippet": {
+                    "type": "string"
+                },
+                "starred": {
+                    "type": "boolean"
+                },
+                "thumbnailProxyUrl": {
+                    "description": "ThumbnailProxyURL serves the thumbnail scaled down, with ?w=320 or ?w=640",
+                    "type": "string"
+                },
+                "thumbnailUrl": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.errorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_handler.ErrorCode"
+                        }
+                    ],
+                    "example": "not_found"
+                },
+                "details": {},
+                "fieldErrors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handler.FieldError"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "resource not found"
+                }
+            }
+        },
+        "internal_handler.feedAuthRequest": {
+            "type": "object",
+            "properties": {
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.feedCandidateResponse": {
+            "type": "object",
+            "properties": {
+                "itemCount": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.feedConflictDetails": {
+            "type": "object",
+            "properties": {
+                "existingFeed": {
+                    "$ref": "#/definitions/internal_handler.feedResponse"
+                }
+            }
+        },
+        "internal_handler.feedDateQuirks": {
+            "type": "object",
+            "properties": {
+                "dayFirst": {
+                    "description": "DayFirst reads numeric dates such as 03/04/2025 as day, month, year",
+                    "type": "boolean"
+                },
+                "ignoreFuture": {
+                    "description": "IgnoreFuture replaces future dates by the updated date or the time the entry was first seen",
+                    "type": "boolean"
+                },
+                "timezone": {
+                    "description": "Timezone is the IANA zone of dates written without an offset",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                }
+            }
+        },
+        "internal_handler.feedPreviewResponse": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "imageUrl": {
+                    "type": "string"
+                },
+                "itemCount": {
+                    "type": "integer"
+                },
+                "lastUpdated": {
+                    "type": "string"
+                },
+                "siteUrl": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.feedRequestOverrides": {
+            "type": "object",
+            "properties": {
+                "headers": {
+                    "description": "Headers are added to every request, after the defaults",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "userAgent": {
+                    "description": "UserAgent replaces the User-Agent, and the fallback one is not tried",
+                    "type": "string",
+                    "example": "Mozilla/5.0 (compatible; Feedly/1.0)"
+                }
+            }
+        },
+        "internal_handler.feedResponse": {
+            "type": "object",
+            "properties": {
+                "consecutiveFailures": {
+                    "description": "ConsecutiveFailures counts the refreshes in a row that failed; each\ndoubles the time until the next one, up to a day.",
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "dateQuirks": {
+                    "description": "DateQuirks corrects how the dates of the feed's entries are read;\nunset when they are taken as the feed gives them.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_handler.feedDateQuirks"
+                        }
+                    ]
+                },
+                "description": {
+                    "type": "string"
+                },
+                "disabledAt": {
+                    "description": "DisabledAt is set while the feed is disabled for failing too often;\nscheduled refreshes skip it until it is enabled again.",
+                    "type": "string"
+                },
+                "errorMessage": {
+                    "type": "string"
+                },
+                "etag": {
+                    "type": "string"
+                },
+                "folderId": {
+                    "type": "string"
+                },
+                "fullTextUrl": {
+                    "description": "unset when readability extracts entries",
+                    "type": "string"
+                },
+                "hasAuth": {
+                    "description": "HasAuth is set when credentials are stored for the feed. They are\nnever sent back.",
+                    "type": "boolean"
+                },
+                "iconColor": {
+                    "description": "#rrggbb, for placeholders while the icon loads",
+                    "type": "string"
+                },
+                "iconPath": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "lastModified": {
+                    "type": "string"
+                },
+                "nextRefreshAt": {
+                    "description": "unset until first refreshed",
+                    "type": "string"
+                },
+                "prefetchReadability": {
+                    "description": "PrefetchReadability is set when refresh fetches the readable content\nof the feed's new entries.",
+                    "type": "boolean"
+                },
+                "refreshInterval": {
+                    "description": "RefreshInterval is the feed's own minutes between refreshes, unset when\nthe scheduler decides.",
+                    "type": "integer"
+                },
+                "requestOverrides": {
+                    "description": "RequestOverrides is set when the feed is requested with its own\nUser-Agent or headers.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_handler.feedRequestOverrides"
+                        }
+                    ]
+                },
+                "scraper": {
+                    "description": "Scraper is set when the feed is a web page scraped for entries.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_handler.feedScraper"
+                        }
+                    ]
+                },
+                "siteUrl": {
+                    "type": "string"
+                },
+                "summaryInSourceLanguage": {
+                    "description": "SummaryInSourceLanguage is set when AI summaries of the feed's entries\nare written in the article's language.",
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.feedScraper": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "Date selects the date within an item; entries are dated when first seen when empty",
+                    "type": "string",
+                    "example": "time"
+                },
+                "items": {
+                    "description": "Items selects each entry on the page",
+                    "type": "string",
+                    "example": "article.post"
+                },
+                "link": {
+                    "description": "Link selects the link within an item; its first link when empty",
+                    "type": "string",
+                    "example": "h2 a"
+                },
+                "title": {
+                    "description": "Title selects the title within an item; the item's text when empty",
+                    "type": "string",
+                    "example": "h2"
+                }
+            }
+        },
+        "internal_handler.filterRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "enabled": {
+                    "description": "Enabled defaults to true when omitted.",
+                    "type": "boolean"
+                },
+                "feedId": {
+                    "description": "FeedID limits the filter to one feed; omitted applies it to every feed.",
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "folderId": {
+                    "type": "string"
+                },
+                "match": {
+                    "type": "string"
+                },
+                "pattern": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.filterResponse": {
             "type": "object",
             "properties": {
-                "cached": {
+                "action": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "CreatedAt also orders the filters: they run oldest first.",
+                    "type": "string"
+                },
+                "enabled": {
                     "type": "boolean"
                 },
+                "feedId": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "folderId": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
-                "summary": {
+                "match": {
                     "type": "string"
                 },
-                "title": {
+                "pattern": {
+                    "type": "string"
+                },
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "gist_backend_internal_service.ImportResult": {
+        "internal_handler.filterSampleEntry": {
             "type": "object",
             "properties": {
-                "feedsCreated": {
-                    "type": "integer"
+                "author": {
+                    "type": "string"
                 },
-                "feedsSkipped": {
-                    "type": "integer"
+                "content": {
+                    "type": "string"
                 },
-                "foldersCreated": {
-                    "type": "integer"
+                "title": {
+                    "type": "string"
                 },
-                "foldersSkipped": {
-                    "type": "integer"
+                "url": {
+                    "type": "string"
                 }
             }
         },
-        "gist_backend_internal_service.ImportTask": {
+        "internal_handler.filterTestRequest": {
             "type": "object",
             "properties": {
-                "createdAt": {
+                "action": {
                     "type": "string"
                 },
-                "current": {
-                    "type": "integer"
+                "enabled": {
+                    "description": "Enabled defaults to true when omitted.",
+                    "type": "boolean"
                 },
-                "error": {
-                    "type": "string"
+                "entry": {
+                    "$ref": "#/definitions/internal_handler.filterSampleEntry"
                 },
-                "feed": {
+                "feedId": {
+                    "description": "FeedID limits the filter to one feed; omitted applies it to every feed.",
                     "type": "string"
                 },
-                "id": {
+                "field": {
                     "type": "string"
                 },
-                "result": {
-                    "$ref": "#/definitions/gist_backend_internal_service.ImportResult"
+                "folderId": {
+                    "type": "string"
                 },
-                "status": {
-                    "description": "\"running\", \"done\", \"error\", \"cancelled\"",
+                "match": {
                     "type": "string"
                 },
-                "total": {
-                    "type": "integer"
+                "pattern": {
+                    "type": "string"
                 }
             }
         },
-        "internal_handler.aiSettingsRequest": {
+        "internal_handler.filterTestResponse": {
             "type": "object",
             "properties": {
-                "apiKey": {
+                "author": {
                     "type": "string"
                 },
-                "autoSummary": {
+                "content": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is why the expression failed on the entry, which refresh would\nlog and then ignore the rule.",
+                    "type": "string"
+                },
+                "folderId": {
+                    "type": "string"
+                },
+                "matched": {
                     "type": "boolean"
                 },
-                "autoTranslate": {
+                "read": {
                     "type": "boolean"
                 },
-                "baseUrl": {
+                "skip": {
+                    "description": "Skip means the entry would not be stored.",
+                    "type": "boolean"
+                },
+                "starred": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.folderRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
                     "type": "string"
                 },
-                "model": {
+                "parentId": {
                     "type": "string"
                 },
-                "provider": {
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.folderResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
                     "type": "string"
                 },
-                "rateLimit": {
-                    "type": "integer"
+                "id": {
+                    "type": "string"
                 },
-                "reasoningEffort": {
+                "name": {
                     "type": "string"
                 },
-                "summaryLanguage": {
+                "parentId": {
                     "type": "string"
                 },
-                "thinking": {
+                "pregenerateThumbnails": {
+                    "description": "PregenerateThumbnails is set when grid thumbnails are cached after each refresh.",
                     "type": "boolean"
                 },
-                "thinkingBudget": {
-                    "type": "integer"
+                "type": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "internal_handler.aiSettingsResponse": {
+        "internal_handler.folderThumbnailsRequest": {
             "type": "object",
             "properties": {
-                "apiKey": {
-                    "type": "string"
-                },
-                "autoSummary": {
+                "enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "internal_handler.generalSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "autoReadability": {
                     "type": "boolean"
                 },
-                "autoTranslate": {
+                "backupInterval": {
+                    "description": "Backup fields are kept as they are when omitted; an interval of 0\nturns scheduled backups off",
+                    "type": "integer"
+                },
+                "backupKeep": {
+                    "type": "integer"
+                },
+                "categoryFolders": {
+                    "description": "Files feeds added without a folder by their first category; kept as\nit is when omitted.",
                     "type": "boolean"
                 },
-                "baseUrl": {
+                "contentRefetchWindow": {
+                    "description": "Hours to re-fetch missing content for, kept when omitted; 0 turns it off",
+                    "type": "integer"
+                },
+                "cookieHosts": {
+                    "description": "CookieHosts is kept as it is when omitted",
                     "type": "string"
                 },
-                "model": {
+                "duplicateTitleWindow": {
+                    "type": "integer"
+                },
+                "duplicateTitles": {
+                    "description": "Duplicate title fields are kept as they are when omitted",
                     "type": "string"
                 },
-                "provider": {
+                "embedHosts": {
+                    "description": "EmbedHosts and FutureDates are kept as they are when omitted",
                     "type": "string"
                 },
-                "rateLimit": {
+                "fallbackUserAgent": {
+                    "type": "string"
+                },
+                "feedDisableThreshold": {
+                    "description": "Failed refreshes in a row before a feed is disabled; 0 never disables.",
                     "type": "integer"
                 },
-                "reasoningEffort": {
+                "futureDates": {
                     "type": "string"
                 },
-                "summaryLanguage": {
+                "iconSources": {
+                    "description": "IconSources is kept as it is when omitted",
                     "type": "string"
                 },
-                "thinking": {
+                "lowData": {
+                    "description": "Low-data fields are kept as they are when omitted",
                     "type": "boolean"
                 },
-                "thinkingBudget": {
+                "lowDataSchedule": {
+                    "type": "string"
+                },
+                "nsfwKeywords": {
+                    "type": "string"
+                },
+                "nsfwMode": {
+                    "description": "NSFW fields are kept as they are when omitted",
+                    "type": "string"
+                },
+                "nsfwVisionCheck": {
+                    "type": "boolean"
+                },
+                "retentionDays": {
+                    "description": "Retention fields are kept as they are when omitted; 0 turns a rule off",
                     "type": "integer"
+                },
+                "retentionMaxPerFeed": {
+                    "type": "integer"
+                },
+                "tlsFingerprints": {
+                    "description": "TLSFingerprints is kept as it is when omitted",
+                    "type": "string"
                 }
             }
         },
-        "internal_handler.aiTestRequest": {
+        "internal_handler.generalSettingsResponse": {
             "type": "object",
             "properties": {
-                "apiKey": {
+                "autoReadability": {
+                    "type": "boolean"
+                },
+                "backupInterval": {
+                    "type": "integer"
+                },
+                "backupKeep": {
+                    "type": "integer"
+                },
+                "categoryFolders": {
+                    "type": "boolean"
+                },
+                "contentRefetchWindow": {
+                    "type": "integer"
+                },
+                "cookieHosts": {
                     "type": "string"
                 },
-                "baseUrl": {
+                "duplicateTitleWindow": {
+                    "type": "integer"
+                },
+                "duplicateTitles": {
                     "type": "string"
                 },
-                "model": {
+                "embedHosts": {
                     "type": "string"
                 },
-                "provider": {
+                "fallbackUserAgent": {
                     "type": "string"
                 },
-                "reasoningEffort": {
+                "feedDisableThreshold": {
+                    "type": "integer"
+                },
+                "futureDates": {
                     "type": "string"
                 },
-                "thinking": {
+                "iconSources": {
+                    "type": "string"
+                },
+                "lowData": {
                     "type": "boolean"
                 },
-                "thinkingBudget": {
-                    "type": "integer"
-                }
-            }
-        },
-        "internal_handler.aiTestResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
+                "lowDataActive": {
+                    "type": "boolean"
+                },
+                "lowDataSchedule": {
                     "type": "string"
                 },
-                "message": {
+                "nsfwKeywords": {
                     "type": "string"
                 },
-                "success": {
+                "nsfwMode": {
+                    "type": "string"
+                },
+                "nsfwVisionCheck": {
                     "type": "boolean"
-                }
-            }
-        },
-        "internal_handler.batchTranslateRequest": {
-            "type": "object",
-            "properties": {
-                "articles": {
-                    "type": "array",
-                    "items": {
-                        "type": "object",
-                        "properties": {
-                            "id": {
-                                "type": "string"
-                            },
-                            "summary": {
-                                "type": "string"
-                            },
-                            "title": {
-                                "type": "string"
-                            }
-                        }
-                    }
-                }
-            }
-        },
-        "internal_handler.clearCacheResponse": {
-            "type": "object",
-            "properties": {
-                "listTranslations": {
-                    "type": "integer"
                 },
-                "summaries": {
+                "retentionDays": {
                     "type": "integer"
                 },
-                "translations": {
+                "retentionMaxPerFeed": {
                     "type": "integer"
+                },
+                "tlsFingerprints": {
+                    "type": "string"
+                }
+            }
+        },
+        "internal_handler.importCancelledResponse": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "boolean"
                 }
             }
         },
-        "internal_handler.createFeedRequest": {
+        "internal_handler.importStartedResponse": {
             "type": "object",
             "properties": {
-                "folderId": {
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "type": {
+                "status": {
                     "type": "string"
                 },
-                "url": {
+                "taskId": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.deleteFeedsRequest": {
+        "internal_handler.lowDataRequest": {
             "type": "object",
             "properties": {
-                "ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "enabled": {
+                    "type": "boolean"
                 }
             }
         },
-        "internal_handler.deleteFoldersRequest": {
+        "internal_handler.markAllReadRequest": {
             "type": "object",
             "properties": {
-                "ids": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "contentType": {
+                    "type": "string"
+                },
+                "feedId": {
+                    "type": "string"
+                },
+                "folderId": {
+                    "type": "string"
                 }
             }
         },
-        "internal_handler.entryListResponse": {
+        "internal_handler.notificationListResponse": {
             "type": "object",
             "properties": {
-                "entries": {
+                "notifications": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/internal_handler.entryResponse"
+                        "$ref": "#/definitions/internal_handler.notificationResponse"
                     }
                 },
-                "hasMore": {
-                    "type": "boolean"
+                "unread": {
+                    "type": "integer"
                 }
             }
         },
-        "internal_handler.entryResponse": {
+        "internal_handler.notificationResponse": {
             "type": "object",
             "properties": {
-                "author": {
-                    "type": "string"
-                },
-                "content": {
+                "body": {
                     "type": "string"
                 },
                 "createdAt": {
+                    "description": "CreatedAt is RFC 3339 in UTC.",
                     "type": "string"
                 },
                 "feedId": {
@@ -1655,251 +6828,218 @@ const docTemplate = `{
                 "id": {
                     "type": "string"
                 },
-                "publishedAt": {
+                "kind": {
+                    "description": "import_done, task_failed or feed_disabled",
                     "type": "string"
                 },
                 "read": {
                     "type": "boolean"
                 },
-                "readableContent": {
-                    "type": "string"
-                },
-                "starred": {
-                    "type": "boolean"
-                },
-                "thumbnailUrl": {
+                "taskKind": {
                     "type": "string"
                 },
                 "title": {
                     "type": "string"
-                },
-                "updatedAt": {
-                    "type": "string"
-                },
-                "url": {
-                    "type": "string"
-                }
-            }
-        },
-        "internal_handler.errorResponse": {
-            "type": "object",
-            "properties": {
-                "error": {
-                    "type": "string"
                 }
             }
         },
-        "internal_handler.feedConflictResponse": {
+        "internal_handler.playbackRequest": {
             "type": "object",
             "properties": {
-                "error": {
-                    "type": "string",
-                    "example": "feed_exists"
-                },
-                "existingFeed": {
-                    "$ref": "#/definitions/internal_handler.feedResponse"
+                "positionSeconds": {
+                    "type": "integer"
                 }
             }
         },
-        "internal_handler.feedPreviewResponse": {
+        "internal_handler.playbackResponse": {
             "type": "object",
             "properties": {
-                "description": {
-                    "type": "string"
-                },
-                "imageUrl": {
+                "entryId": {
                     "type": "string"
                 },
-                "itemCount": {
+                "positionSeconds": {
                     "type": "integer"
                 },
-                "lastUpdated": {
-                    "type": "string"
-                },
-                "siteUrl": {
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "url": {
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.feedResponse": {
+        "internal_handler.previewFeedRequest": {
             "type": "object",
             "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "errorMessage": {
-                    "type": "string"
-                },
-                "etag": {
-                    "type": "string"
-                },
-                "folderId": {
-                    "type": "string"
-                },
-                "iconPath": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "lastModified": {
-                    "type": "string"
-                },
-                "siteUrl": {
-                    "type": "string"
-                },
-                "title": {
-                    "type": "string"
-                },
-                "type": {
-                    "type": "string"
+                "auth": {
+                    "$ref": "#/definitions/internal_handler.feedAuthRequest"
                 },
-                "updatedAt": {
-                    "type": "string"
+                "requestOverrides": {
+                    "$ref": "#/definitions/internal_handler.feedRequestOverrides"
                 },
                 "url": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.folderRequest": {
+        "internal_handler.previewScrapedRequest": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string"
+                "auth": {
+                    "$ref": "#/definitions/internal_handler.feedAuthRequest"
                 },
-                "parentId": {
-                    "type": "string"
+                "dateQuirks": {
+                    "$ref": "#/definitions/internal_handler.feedDateQuirks"
                 },
-                "type": {
+                "requestOverrides": {
+                    "$ref": "#/definitions/internal_handler.feedRequestOverrides"
+                },
+                "scraper": {
+                    "$ref": "#/definitions/internal_handler.feedScraper"
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.folderResponse": {
+        "internal_handler.readableContentResponse": {
             "type": "object",
             "properties": {
-                "createdAt": {
-                    "type": "string"
-                },
-                "id": {
+                "readableByline": {
                     "type": "string"
                 },
-                "name": {
+                "readableContent": {
                     "type": "string"
                 },
-                "parentId": {
+                "readableDir": {
+                    "description": "ReadableDir is the text direction of the readable content: rtl or ltr",
                     "type": "string"
                 },
-                "type": {
+                "readableLang": {
                     "type": "string"
                 },
-                "updatedAt": {
+                "readableSiteName": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.generalSettingsRequest": {
+        "internal_handler.scrapePreviewResponse": {
             "type": "object",
             "properties": {
-                "autoReadability": {
-                    "type": "boolean"
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/internal_handler.scrapedItemResponse"
+                    }
                 },
-                "fallbackUserAgent": {
+                "title": {
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.generalSettingsResponse": {
+        "internal_handler.scrapedItemResponse": {
             "type": "object",
             "properties": {
-                "autoReadability": {
-                    "type": "boolean"
+                "publishedAt": {
+                    "type": "string"
                 },
-                "fallbackUserAgent": {
+                "title": {
+                    "type": "string"
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.importCancelledResponse": {
+        "internal_handler.starredCountResponse": {
             "type": "object",
             "properties": {
-                "cancelled": {
-                    "type": "boolean"
+                "count": {
+                    "type": "integer"
                 }
             }
         },
-        "internal_handler.importStartedResponse": {
+        "internal_handler.starredCountsResponse": {
             "type": "object",
             "properties": {
-                "status": {
-                    "type": "string"
+                "counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "folders": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "internal_handler.markAllReadRequest": {
+        "internal_handler.summarizeRequest": {
             "type": "object",
             "properties": {
-                "contentType": {
+                "content": {
                     "type": "string"
                 },
-                "feedId": {
+                "entryId": {
                     "type": "string"
                 },
-                "folderId": {
+                "isReadability": {
+                    "type": "boolean"
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.readableContentResponse": {
+        "internal_handler.summarizeResponse": {
             "type": "object",
             "properties": {
-                "readableContent": {
+                "cached": {
+                    "type": "boolean"
+                },
+                "summary": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.starredCountResponse": {
+        "internal_handler.tagRequest": {
             "type": "object",
             "properties": {
-                "count": {
-                    "type": "integer"
+                "name": {
+                    "type": "string"
                 }
             }
         },
-        "internal_handler.summarizeRequest": {
+        "internal_handler.tagResponse": {
             "type": "object",
             "properties": {
-                "content": {
+                "createdAt": {
                     "type": "string"
                 },
-                "entryId": {
+                "entryCount": {
+                    "description": "EntryCount is how many entries have the tag; only in the tag list.",
+                    "type": "integer"
+                },
+                "id": {
                     "type": "string"
                 },
-                "isReadability": {
-                    "type": "boolean"
+                "name": {
+                    "type": "string"
                 },
-                "title": {
+                "updatedAt": {
                     "type": "string"
                 }
             }
         },
-        "internal_handler.summarizeResponse": {
+        "internal_handler.textChangeResponse": {
             "type": "object",
             "properties": {
-                "cached": {
-                    "type": "boolean"
+                "added": {
+                    "type": "string"
                 },
-                "summary": {
+                "removed": {
                     "type": "string"
                 }
             }
@@ -1936,19 +7076,82 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "counts": {
+                    "description": "by feed ID",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "folders": {
+                    "description": "by folder ID, without subfolders",
                     "type": "object",
                     "additionalProperties": {
                         "type": "integer"
                     }
+                },
+                "starredTotal": {
+                    "description": "StarredTotal counts every starred entry, whatever the scope.",
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
         "internal_handler.updateFeedRequest": {
             "type": "object",
             "properties": {
+                "auth": {
+                    "description": "Auth replaces the credentials the feed is fetched with, and an empty\nobject removes them; kept as they are when omitted.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_handler.feedAuthRequest"
+                        }
+                    ]
+                },
+                "dateQuirks": {
+                    "description": "DateQuirks replaces how the dates of entries are read, and an empty\nobject removes the corrections; kept as they are when omitted.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_handler.feedDateQuirks"
+                        }
+                    ]
+                },
                 "folderId": {
                     "type": "string"
                 },
+                "fullTextUrl": {
+                    "description": "FullTextURL is the feed's full-text service, \"\" for the built-in\nreadability; kept as it is when omitted.",
+                    "type": "string"
+                },
+                "prefetchReadability": {
+                    "description": "PrefetchReadability has refresh fetch the readable content of new\nentries; kept as it is when omitted.",
+                    "type": "boolean"
+                },
+                "refreshInterval": {
+                    "description": "RefreshInterval is the minutes between refreshes, 0 for the scheduler's\nchoice; kept as it is when omitted.",
+                    "type": "integer"
+                },
+                "requestOverrides": {
+                    "description": "RequestOverrides replaces the User-Agent and headers the feed is\nrequested with, and an empty object sends the defaults; kept as they\nare when omitted.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_handler.feedRequestOverrides"
+                        }
+                    ]
+                },
+                "scraper": {
+                    "description": "Scraper replaces the selectors the feed's page is scraped with, and\nan empty object makes it an ordinary feed; kept as they are when omitted.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/internal_handler.feedScraper"
+                        }
+                    ]
+                },
+                "summaryInSourceLanguage": {
+                    "description": "SummaryInSourceLanguage has AI summaries written in the article's\nlanguage instead of the global one; kept as it is when omitted.",
+                    "type": "boolean"
+                },
                 "title": {
                     "type": "string"
                 }
@@ -1986,6 +7189,13 @@ const docTemplate = `{
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "ApiToken": {
+            "type": "apiKey",
+            "name": "X-Gist-Token",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigFileName is the config file generated inside the data directory on first run.
+const ConfigFileName = "gist.conf"
+
+// Data directory layout
+const (
+	IconsDirName   = "icons"
+	BackupsDirName = "backups"
+	MediaDirName   = "media"
+)
+
+const (
+	dataDirPerm    = 0o750
+	configFilePerm = 0o600 // holds the API token
+)
+
+// Bootstrap prepares the data directory layout and writes a default config file
+// if none exists. Returns true on first run, in which case cfg.APIToken is
+// populated with a freshly generated token.
+func Bootstrap(cfg *Config) (bool, error) {
+	dirs := []string{
+		cfg.DataDir,
+		filepath.Dir(cfg.DBPath),
+		cfg.IconsDir,
+		cfg.BackupsDir,
+		cfg.MediaDir,
+	}
+	for _, dir := range dirs {
+		if err := os.MkdirAll(dir, dataDirPerm); err != nil {
+			return false, fmt.Errorf("create %s: %w", dir, err)
+		}
+	}
+
+	configPath := filepath.Join(cfg.DataDir, ConfigFileName)
+	if _, err := os.Stat(configPath); err == nil {
+		return false, nil
+	} else if !os.IsNotExist(err) {
+		return false, fmt.Errorf("stat config file: %w", err)
+	}
+
+	if cfg.APIToken == "" {
+		token, err := generateToken()
+		if err != nil {
+			return false, fmt.Errorf("generate api token: %w", err)
+		}
+		cfg.APIToken = token
+	}
+
+	if err := os.WriteFile(configPath, []byte(defaultConfigFile(*cfg)), configFilePerm); err != nil {
+		return false, fmt.Errorf("write config file: %w", err)
+	}
+	return true, nil
+}
+
+// FirstRunURL returns the URL users should open after the first start.
+func FirstRunURL(addr string) string {
+	host := addr
+	if strings.HasPrefix(host, ":") {
+		host = "localhost" + host
+	} else if strings.HasPrefix(host, "0.0.0.0:") {
+		host = "localhost" + strings.TrimPrefix(host, "0.0.0.0")
+	}
+	return "http://" + host + "/"
+}
+
+func defaultConfigFile(cfg Config) string {
+	var b strings.Builder
+	b.WriteString("# Gist configuration, generated on first run.\n")
+	b.WriteString("# Environment variables with the same name take precedence.\n\n")
+	fmt.Fprintf(&b, "GIST_ADDR=%s\n", cfg.Addr)
+	fmt.Fprintf(&b, "GIST_DB_PATH=%s\n", cfg.DBPath)
+	b.WriteString("# GIST_STATIC_DIR=\n\n")
+	b.WriteString("# Data kept outside of the data directory, e.g. media on a large disk.\n")
+	b.WriteString("# Existing files are moved when a path changes.\n")
+	b.WriteString("# GIST_ICONS_DIR=\n# GIST_MEDIA_DIR=\n# GIST_BACKUPS_DIR=\n\n")
+	b.WriteString("# Token for scripted clients; keep it secret.\n")
+	fmt.Fprintf(&b, "GIST_API_TOKEN=%s\n", cfg.APIToken)
+	return b.String()
+}
+
+// readConfigFile parses KEY=VALUE lines, ignoring blanks and # comments.
+func readConfigFile(path string) (map[string]string, error) {
+	values := make(map[string]string)
+	file, err := os.Open(path)
+	if err != nil {
+		return values, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+	return values, scanner.Err()
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
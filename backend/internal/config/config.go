@@ -3,6 +3,9 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -23,40 +26,198 @@ const (
 // DefaultUserAgent for RSS fetching
 var DefaultUserAgent = GistUserAgent
 
+// DefaultMaxUploadSize caps uploaded files (OPML) when GIST_MAX_UPLOAD_MB is unset.
+const DefaultMaxUploadSize int64 = 5 << 20
+
+// DefaultMaxRestoreSize caps an uploaded database restore when
+// GIST_MAX_RESTORE_MB is unset.
+const DefaultMaxRestoreSize int64 = 1 << 30
+
+// DefaultSyncInterval is how often a secondary pulls from its primary when
+// GIST_SYNC_INTERVAL_MIN is unset.
+const DefaultSyncInterval = 5 * time.Minute
+
+// DefaultMetricsFeedLimit caps the feeds with their own metrics series when
+// GIST_METRICS_FEED_LIMIT is unset.
+const DefaultMetricsFeedLimit = 50
+
+// DefaultPageCacheSize and DefaultPageCacheTTL bound the cache of fetched
+// page HTML when GIST_PAGE_CACHE_MB and GIST_PAGE_CACHE_TTL_MIN are unset.
+const (
+	DefaultPageCacheSize int64 = 32 << 20
+	DefaultPageCacheTTL        = 10 * time.Minute
+)
+
+// DefaultImageCacheSize caps the disk cache of proxied images when
+// GIST_IMAGE_CACHE_MB is unset.
+const DefaultImageCacheSize int64 = 512 << 20
+
+// Server modes controlling which mutations are allowed.
+const (
+	ModeNormal   = "normal"
+	ModeDemo     = "demo"     // only read/star toggles are allowed
+	ModeReadOnly = "readonly" // all mutations are rejected
+)
+
+// Refresh modes deciding how often feeds without their own interval are
+// refreshed.
+const (
+	RefreshFixed    = "fixed"    // every feed on the default interval
+	RefreshAdaptive = "adaptive" // more often for feeds that publish often, less for quiet ones
+)
+
 type Config struct {
 	Addr      string
 	DBPath    string
 	DataDir   string
 	StaticDir string
+	// IconsDir, MediaDir and BackupsDir default to subdirectories of DataDir
+	// and can be put on other volumes, e.g. media on a large disk.
+	IconsDir   string
+	MediaDir   string
+	BackupsDir string
+	// APIToken authenticates scripted clients (bookmarklets, CLI, admin calls).
+	// Generated on first run and stored in the config file.
+	APIToken string
+	// Mode is one of ModeNormal, ModeDemo or ModeReadOnly.
+	Mode string
+	// MaxUploadSize is the largest accepted upload body in bytes.
+	MaxUploadSize int64
+	// MaxRestoreSize is the largest accepted database restore in bytes.
+	MaxRestoreSize int64
+	// SyncPrimaryURL makes this instance a secondary that mirrors the
+	// subscriptions and read state of the Gist instance at that URL.
+	SyncPrimaryURL string
+	// SyncToken is the pairing token: the primary's API token.
+	SyncToken string
+	// SyncInterval is how often a secondary pulls from its primary.
+	SyncInterval time.Duration
+	// MetricsFeedLimit is how many feeds get their own series on /metrics;
+	// the rest are aggregated as feed="other".
+	MetricsFeedLimit int
+	// RefreshMode is RefreshFixed or RefreshAdaptive.
+	RefreshMode string
+	// PublicURL is where WebSub hubs reach this instance, e.g.
+	// https://gist.example.com. Feeds are only subscribed to their hubs
+	// when it is set.
+	PublicURL string
+	// PageCacheSize and PageCacheTTL bound the in-memory cache of fetched
+	// page HTML that extractions share; a size of 0 turns it off.
+	PageCacheSize int64
+	PageCacheTTL  time.Duration
+	// ImageCacheSize bounds the images the image proxy keeps in MediaDir;
+	// 0 turns the cache off.
+	ImageCacheSize int64
 }
 
+// Load reads configuration from GIST_* environment variables, falling back to
+// the config file in the data directory. Environment variables always win.
 func Load() Config {
-	addr := os.Getenv("GIST_ADDR")
-	if addr == "" {
-		addr = ":8080"
-	}
 	dataDir := os.Getenv("GIST_DATA_DIR")
 	if dataDir == "" {
 		dataDir = "./data"
 	}
-	path := os.Getenv("GIST_DB_PATH")
+	dataDir = filepath.Clean(dataDir)
+
+	fileValues, _ := readConfigFile(filepath.Join(dataDir, ConfigFileName))
+	lookup := func(key string) string {
+		if val := os.Getenv(key); val != "" {
+			return val
+		}
+		return fileValues[key]
+	}
+
+	addr := lookup("GIST_ADDR")
+	if addr == "" {
+		addr = ":8080"
+	}
+	path := lookup("GIST_DB_PATH")
 	if path == "" {
 		path = filepath.Join(dataDir, "gist.db")
 	}
-	staticDir := os.Getenv("GIST_STATIC_DIR")
-	if staticDir == "" {
-		staticDir = detectStaticDir()
+	dataPath := func(key, name string) string {
+		if dir := lookup(key); dir != "" {
+			return filepath.Clean(dir)
+		}
+		return filepath.Join(dataDir, name)
+	}
+	// Empty means "use the embedded frontend, or a local dist when none is embedded"
+	staticDir := lookup("GIST_STATIC_DIR")
+	if staticDir != "" {
+		staticDir = filepath.Clean(staticDir)
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(lookup("GIST_MODE")))
+	if mode != ModeDemo && mode != ModeReadOnly {
+		mode = ModeNormal
+	}
+
+	refreshMode := strings.ToLower(strings.TrimSpace(lookup("GIST_REFRESH_MODE")))
+	if refreshMode != RefreshAdaptive {
+		refreshMode = RefreshFixed
+	}
+
+	maxUploadSize := DefaultMaxUploadSize
+	if mb, err := strconv.ParseInt(strings.TrimSpace(lookup("GIST_MAX_UPLOAD_MB")), 10, 64); err == nil && mb > 0 {
+		maxUploadSize = mb << 20
+	}
+
+	maxRestoreSize := DefaultMaxRestoreSize
+	if mb, err := strconv.ParseInt(strings.TrimSpace(lookup("GIST_MAX_RESTORE_MB")), 10, 64); err == nil && mb > 0 {
+		maxRestoreSize = mb << 20
+	}
+
+	syncInterval := DefaultSyncInterval
+	if min, err := strconv.Atoi(strings.TrimSpace(lookup("GIST_SYNC_INTERVAL_MIN"))); err == nil && min > 0 {
+		syncInterval = time.Duration(min) * time.Minute
+	}
+
+	metricsFeedLimit := DefaultMetricsFeedLimit
+	if n, err := strconv.Atoi(strings.TrimSpace(lookup("GIST_METRICS_FEED_LIMIT"))); err == nil && n >= 0 {
+		metricsFeedLimit = n
+	}
+
+	pageCacheSize := DefaultPageCacheSize
+	if mb, err := strconv.ParseInt(strings.TrimSpace(lookup("GIST_PAGE_CACHE_MB")), 10, 64); err == nil && mb >= 0 {
+		pageCacheSize = mb << 20
+	}
+	pageCacheTTL := DefaultPageCacheTTL
+	if min, err := strconv.Atoi(strings.TrimSpace(lookup("GIST_PAGE_CACHE_TTL_MIN"))); err == nil && min > 0 {
+		pageCacheTTL = time.Duration(min) * time.Minute
+	}
+
+	imageCacheSize := DefaultImageCacheSize
+	if mb, err := strconv.ParseInt(strings.TrimSpace(lookup("GIST_IMAGE_CACHE_MB")), 10, 64); err == nil && mb >= 0 {
+		imageCacheSize = mb << 20
 	}
 
 	return Config{
-		Addr:      addr,
-		DBPath:    filepath.Clean(path),
-		DataDir:   filepath.Clean(dataDir),
-		StaticDir: filepath.Clean(staticDir),
+		Addr:             addr,
+		DBPath:           filepath.Clean(path),
+		DataDir:          dataDir,
+		StaticDir:        staticDir,
+		IconsDir:         dataPath("GIST_ICONS_DIR", IconsDirName),
+		MediaDir:         dataPath("GIST_MEDIA_DIR", MediaDirName),
+		BackupsDir:       dataPath("GIST_BACKUPS_DIR", BackupsDirName),
+		APIToken:         lookup("GIST_API_TOKEN"),
+		Mode:             mode,
+		MaxUploadSize:    maxUploadSize,
+		MaxRestoreSize:   maxRestoreSize,
+		SyncPrimaryURL:   strings.TrimRight(strings.TrimSpace(lookup("GIST_SYNC_PRIMARY_URL")), "/"),
+		SyncToken:        lookup("GIST_SYNC_TOKEN"),
+		SyncInterval:     syncInterval,
+		MetricsFeedLimit: metricsFeedLimit,
+		RefreshMode:      refreshMode,
+		PublicURL:        strings.TrimRight(strings.TrimSpace(lookup("GIST_PUBLIC_URL")), "/"),
+		PageCacheSize:    pageCacheSize,
+		PageCacheTTL:     pageCacheTTL,
+		ImageCacheSize:   imageCacheSize,
 	}
 }
 
-func detectStaticDir() string {
+// DetectStaticDir looks for a frontend build next to the working directory.
+// Used during development when the binary has no embedded frontend.
+func DetectStaticDir() string {
 	candidates := []string{
 		"./frontend/dist",
 		"../frontend/dist",
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LayoutFileName records, inside the data directory, where the relocatable
+// directories were on the last start, so their files can follow a changed path.
+const LayoutFileName = "layout.conf"
+
+// Relocation reports the files moved from a directory's previous path.
+type Relocation struct {
+	From  string
+	To    string
+	Moved int
+	// Skipped counts files left at the old path because the new path already
+	// had a file of the same name, or because they are not regular files.
+	Skipped int
+}
+
+type layoutDir struct {
+	key  string // the environment variable setting the path
+	name string // the default subdirectory of the data directory
+	path string
+}
+
+func layoutDirs(cfg Config) []layoutDir {
+	return []layoutDir{
+		{key: "GIST_ICONS_DIR", name: IconsDirName, path: cfg.IconsDir},
+		{key: "GIST_MEDIA_DIR", name: MediaDirName, path: cfg.MediaDir},
+		{key: "GIST_BACKUPS_DIR", name: BackupsDirName, path: cfg.BackupsDir},
+	}
+}
+
+// Relocate moves the files of every directory whose path changed since the
+// last start to its new path, then records the current layout. Before a
+// layout was first recorded, the previous paths are the defaults inside the
+// data directory.
+//
+// Files already at the new path are never overwritten. Moves across volumes
+// copy the file and remove the original only once the copy is complete. A
+// failed move returns an error without recording the layout, so the next
+// start picks up where it stopped.
+func Relocate(cfg Config) ([]Relocation, error) {
+	layoutPath := filepath.Join(cfg.DataDir, LayoutFileName)
+	previous, err := readConfigFile(layoutPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read layout file: %w", err)
+	}
+
+	var relocations []Relocation
+	var b strings.Builder
+	b.WriteString("# Where Gist last kept these directories; generated, do not edit.\n")
+	for _, dir := range layoutDirs(cfg) {
+		to, err := filepath.Abs(dir.path)
+		if err != nil {
+			return relocations, fmt.Errorf("resolve %s: %w", dir.path, err)
+		}
+		from := previous[dir.key]
+		if from == "" {
+			if from, err = filepath.Abs(filepath.Join(cfg.DataDir, dir.name)); err != nil {
+				return relocations, fmt.Errorf("resolve %s: %w", dir.name, err)
+			}
+		}
+		fmt.Fprintf(&b, "%s=%s\n", dir.key, to)
+		if from == to {
+			continue
+		}
+		if within(to, from) || within(from, to) {
+			return relocations, fmt.Errorf("cannot move %s to %s: one is inside the other", from, to)
+		}
+
+		relocation, err := moveDir(from, to)
+		if err != nil {
+			return relocations, fmt.Errorf("move %s to %s: %w", from, to, err)
+		}
+		if relocation.Moved > 0 || relocation.Skipped > 0 {
+			relocations = append(relocations, relocation)
+		}
+	}
+
+	if err := os.WriteFile(layoutPath, []byte(b.String()), configFilePerm); err != nil {
+		return relocations, fmt.Errorf("write layout file: %w", err)
+	}
+	return relocations, nil
+}
+
+// within reports whether path is dir or inside it.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// moveDir moves the files under from to the same relative paths under to,
+// then removes the directories left empty. A missing from moves nothing.
+func moveDir(from, to string) (Relocation, error) {
+	relocation := Relocation{From: from, To: to}
+	if _, err := os.Stat(from); os.IsNotExist(err) {
+		return relocation, nil
+	}
+
+	var dirs []string
+	err := filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			relocation.Skipped++
+			return nil
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(to, rel)
+		if _, err := os.Lstat(dst); err == nil {
+			relocation.Skipped++
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(dst), dataDirPerm); err != nil {
+			return err
+		}
+		if err := moveFile(path, dst); err != nil {
+			return err
+		}
+		relocation.Moved++
+		return nil
+	})
+	if err != nil {
+		return relocation, err
+	}
+
+	// Deepest first; directories still holding skipped files stay
+	for i := len(dirs) - 1; i >= 0; i-- {
+		_ = os.Remove(dirs[i])
+	}
+	return relocation, nil
+}
+
+// moveFile renames src to dst, falling back to a copy when they are on
+// different volumes.
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// copyFile copies src to dst through a temporary file, so dst never holds a
+// partial copy, keeping its mode and modification time.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".relocate-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+	if err := os.Chtimes(tmp.Name(), info.ModTime(), info.ModTime()); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
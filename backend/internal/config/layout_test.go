@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), dataDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRelocate(t *testing.T) {
+	dataDir := t.TempDir()
+	cfg := Config{
+		DataDir:    dataDir,
+		IconsDir:   filepath.Join(dataDir, IconsDirName),
+		MediaDir:   filepath.Join(dataDir, MediaDirName),
+		BackupsDir: filepath.Join(dataDir, BackupsDirName),
+	}
+	writeFile(t, filepath.Join(cfg.IconsDir, "example.com.png"), "icon")
+	writeFile(t, filepath.Join(cfg.MediaDir, "thumbnails", "a.jpg"), "a")
+	writeFile(t, filepath.Join(cfg.MediaDir, "thumbnails", "b.jpg"), "old b")
+	old := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	if err := os.Chtimes(filepath.Join(cfg.MediaDir, "thumbnails", "a.jpg"), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default layout moves nothing
+	if relocations, err := Relocate(cfg); err != nil || len(relocations) != 0 {
+		t.Fatalf("expected no relocation, got %+v, %v", relocations, err)
+	}
+
+	mediaDir := filepath.Join(t.TempDir(), "media")
+	writeFile(t, filepath.Join(mediaDir, "thumbnails", "b.jpg"), "new b")
+	cfg.MediaDir = mediaDir
+	relocations, err := Relocate(cfg)
+	if err != nil {
+		t.Fatalf("relocate: %v", err)
+	}
+	if len(relocations) != 1 || relocations[0].Moved != 1 || relocations[0].Skipped != 1 {
+		t.Fatalf("expected one file moved and one skipped, got %+v", relocations)
+	}
+	moved := filepath.Join(mediaDir, "thumbnails", "a.jpg")
+	if info, err := os.Stat(moved); err != nil || !info.ModTime().Equal(old) {
+		t.Errorf("expected the moved file to keep its time, got %v, %v", info, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(mediaDir, "thumbnails", "b.jpg")); string(data) != "new b" {
+		t.Errorf("expected the existing file to be kept, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, MediaDirName, "thumbnails", "b.jpg")); err != nil {
+		t.Errorf("expected the skipped file to stay at the old path: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, IconsDirName, "example.com.png")); err != nil {
+		t.Errorf("expected icons to stay: %v", err)
+	}
+
+	// The recorded layout makes the next start a no-op
+	if relocations, err := Relocate(cfg); err != nil || len(relocations) != 0 {
+		t.Errorf("expected no relocation on the next start, got %+v, %v", relocations, err)
+	}
+
+	cfg.IconsDir = filepath.Join(dataDir, IconsDirName, "nested")
+	if _, err := Relocate(cfg); err == nil {
+		t.Error("expected an error moving a directory into itself")
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	writeFile(t, src, "data")
+	dst := filepath.Join(dir, "dst")
+	if err := copyFile(src, dst); err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "data" {
+		t.Errorf("expected the copy to hold data, got %q, %v", data, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("expected no temporary file left, got %v", entries)
+	}
+}
@@ -297,5 +297,638 @@ func runMigrations(db *sql.DB) error {
 		return fmt.Errorf("create entries_ad trigger: %w", err)
 	}
 
+	// Migration 16: Record folders and feeds created by each OPML import so it can be undone
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS import_items (
+			task_id TEXT NOT NULL,
+			item_type TEXT NOT NULL,
+			item_id INTEGER NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (task_id, item_type, item_id)
+		)
+	`); err != nil {
+		return fmt.Errorf("create import_items table: %w", err)
+	}
+
+	// Migration 17: Add archived_at to feeds so unsubscribing can keep the feed's entries
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = 'archived_at'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check feeds archived_at column: %w", err)
+	}
+
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN archived_at TEXT`); err != nil {
+			return fmt.Errorf("add feeds archived_at column: %w", err)
+		}
+	}
+
+	// Migration 18: Create outbox table for durable background side effects
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS outbox (
+			id INTEGER PRIMARY KEY,
+			kind TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT,
+			next_attempt_at TEXT NOT NULL,
+			created_at TEXT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create outbox table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_outbox_next_attempt ON outbox(next_attempt_at)`); err != nil {
+		return fmt.Errorf("create outbox index: %w", err)
+	}
+
+	// Migration 19: Add snippet column with a plain-text preview of the content.
+	// The full-text index covers the snippet rather than raw HTML; existing rows
+	// are filled in by the snippet backfill task at startup.
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('entries') WHERE name = 'snippet'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check entries snippet column: %w", err)
+	}
+
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE entries ADD COLUMN snippet TEXT`); err != nil {
+			return fmt.Errorf("add entries snippet column: %w", err)
+		}
+	}
+	if _, err := db.Exec(`DROP TRIGGER IF EXISTS entries_ai`); err != nil {
+		return fmt.Errorf("drop entries_ai trigger: %w", err)
+	}
+	if _, err := db.Exec(`CREATE TRIGGER IF NOT EXISTS entries_ai AFTER INSERT ON entries BEGIN
+		INSERT INTO entries_fts(rowid, title, content, author, url)
+		VALUES (new.id, new.title, new.snippet, new.author, new.url);
+	END`); err != nil {
+		return fmt.Errorf("create entries_ai trigger: %w", err)
+	}
+
+	// Migration 20: Indexes backing the entry list ordering and its filters.
+	// The covering index for unread counts is made by migration 48.
+	indexes := []struct{ name, def string }{
+		{"idx_entries_published", "entries(published_at DESC, id DESC)"},
+		{"idx_entries_feed_published", "entries(feed_id, published_at DESC, id DESC)"},
+		{"idx_entries_starred_published", "entries(starred, published_at DESC, id DESC)"},
+		{"idx_entries_read_published", "entries(read, published_at DESC, id DESC)"},
+		{"idx_feeds_type", "feeds(type)"},
+	}
+	for _, idx := range indexes {
+		if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS ` + idx.name + ` ON ` + idx.def); err != nil {
+			return fmt.Errorf("create %s: %w", idx.name, err)
+		}
+	}
+
+	// Migration 21: Change log of entry read/starred state for instance sync.
+	// One row per entry; a change replaces the row, so seq always grows.
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_state_changes (
+			seq INTEGER PRIMARY KEY AUTOINCREMENT,
+			entry_id INTEGER NOT NULL UNIQUE,
+			FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create entry_state_changes table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE TRIGGER IF NOT EXISTS entries_state_au AFTER UPDATE OF read, starred ON entries
+		WHEN old.read != new.read OR old.starred != new.starred BEGIN
+		INSERT OR REPLACE INTO entry_state_changes(entry_id) VALUES (new.id);
+	END`); err != nil {
+		return fmt.Errorf("create entries_state_au trigger: %w", err)
+	}
+
+	// Migration 22: Last URL health check of each starred entry
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_link_checks (
+			entry_id INTEGER PRIMARY KEY,
+			status TEXT NOT NULL,
+			http_status INTEGER,
+			error TEXT,
+			checked_at TEXT NOT NULL,
+			failing_since TEXT,
+			FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create entry_link_checks table: %w", err)
+	}
+
+	// Migration 23: Diff summary of the publisher's latest change to each entry.
+	// changes holds the changed passages as a JSON array.
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_revisions (
+			entry_id INTEGER PRIMARY KEY,
+			title_before TEXT,
+			words_added INTEGER NOT NULL,
+			words_removed INTEGER NOT NULL,
+			changes TEXT NOT NULL,
+			changed_at TEXT NOT NULL,
+			FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create entry_revisions table: %w", err)
+	}
+
+	// Migration 24: Ordered image gallery of entries of picture feeds
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_media (
+			entry_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			url TEXT NOT NULL,
+			PRIMARY KEY (entry_id, position),
+			FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create entry_media table: %w", err)
+	}
+
+	// Migration 25: Per-folder toggle for pre-generating thumbnails of picture folders
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('folders') WHERE name = 'pregenerate_thumbnails'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check folders pregenerate_thumbnails column: %w", err)
+	}
+
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE folders ADD COLUMN pregenerate_thumbnails INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("add folders pregenerate_thumbnails column: %w", err)
+		}
+	}
+
+	// Migration 26: NSFW flag of entries, and which entries the AI image check has seen
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('entries') WHERE name = 'nsfw_reason'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check entries nsfw_reason column: %w", err)
+	}
+
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE entries ADD COLUMN nsfw_reason TEXT`); err != nil {
+			return fmt.Errorf("add entries nsfw_reason column: %w", err)
+		}
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_nsfw_checks (
+			entry_id INTEGER PRIMARY KEY,
+			checked_at TEXT NOT NULL,
+			FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create entry_nsfw_checks table: %w", err)
+	}
+
+	// Migration 27: site icon of pages saved outside of any feed
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('entries') WHERE name = 'icon_path'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check entries icon_path column: %w", err)
+	}
+
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE entries ADD COLUMN icon_path TEXT`); err != nil {
+			return fmt.Errorf("add entries icon_path column: %w", err)
+		}
+	}
+
+	// Migration 28: Keep the full-text index in step with updated entries.
+	// The trigger only fires for the indexed columns, so read and starred
+	// changes leave the index alone (see Migration 3). Rows updated before it
+	// existed are reindexed once.
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = 'entries_fts_au'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check entries_fts_au trigger: %w", err)
+	}
+
+	if count == 0 {
+		if _, err := db.Exec(`DELETE FROM entries_fts`); err != nil {
+			return fmt.Errorf("clear entries_fts: %w", err)
+		}
+		if _, err := db.Exec(`INSERT INTO entries_fts(rowid, title, content, author, url)
+			SELECT id, title, snippet, author, url FROM entries`); err != nil {
+			return fmt.Errorf("rebuild entries_fts: %w", err)
+		}
+		if _, err := db.Exec(`CREATE TRIGGER entries_fts_au AFTER UPDATE OF title, snippet, author, url ON entries BEGIN
+			UPDATE entries_fts SET title = new.title, content = new.snippet, author = new.author, url = new.url
+			WHERE rowid = new.id;
+		END`); err != nil {
+			return fmt.Errorf("create entries_fts_au trigger: %w", err)
+		}
+	}
+
+	// Migration 29: Per-feed refresh interval and the scheduled next refresh
+	for _, column := range []struct{ name, def string }{
+		{"refresh_interval", "INTEGER"},
+		{"next_refresh_at", "TEXT"},
+	} {
+		err = db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = ?
+		`, column.name).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("check feeds %s column: %w", column.name, err)
+		}
+		if count == 0 {
+			if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN ` + column.name + ` ` + column.def); err != nil {
+				return fmt.Errorf("add feeds %s column: %w", column.name, err)
+			}
+		}
+	}
+
+	// Migration 30: Bytes downloaded per feed and day
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS feed_bandwidth (
+			feed_id INTEGER NOT NULL,
+			day TEXT NOT NULL,
+			bytes INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (feed_id, day),
+			FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create feed_bandwidth table: %w", err)
+	}
+
+	// Migration 31: Dominant color of feed icons
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = 'icon_color'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check icon_color column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN icon_color TEXT`); err != nil {
+			return fmt.Errorf("add icon_color column: %w", err)
+		}
+	}
+
+	// Migration 32: Filter rules applied to new entries during refresh, and
+	// the folder a rule moved an entry to
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS filters (
+			id INTEGER PRIMARY KEY,
+			feed_id INTEGER,
+			field TEXT NOT NULL,
+			match_type TEXT NOT NULL,
+			pattern TEXT NOT NULL,
+			action TEXT NOT NULL,
+			folder_id INTEGER,
+			enabled INTEGER NOT NULL DEFAULT 1,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL,
+			FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE,
+			FOREIGN KEY (folder_id) REFERENCES folders(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create filters table: %w", err)
+	}
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('entries') WHERE name = 'folder_id'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check entries folder_id column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE entries ADD COLUMN folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL`); err != nil {
+			return fmt.Errorf("add entries folder_id column: %w", err)
+		}
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_entries_folder_id ON entries(folder_id) WHERE folder_id IS NOT NULL`); err != nil {
+		return fmt.Errorf("create idx_entries_folder_id: %w", err)
+	}
+
+	// Migration 33: Per-feed external full-text service
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = 'full_text_url'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check feeds full_text_url column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN full_text_url TEXT`); err != nil {
+			return fmt.Errorf("add feeds full_text_url column: %w", err)
+		}
+	}
+
+	// Migration 34: Per-feed option to summarize in the article's language
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = 'summary_source_language'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check feeds summary_source_language column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN summary_source_language INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("add feeds summary_source_language column: %w", err)
+		}
+	}
+
+	// Migration 35: Encrypted per-feed credentials
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = 'auth'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check feeds auth column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN auth TEXT`); err != nil {
+			return fmt.Errorf("add feeds auth column: %w", err)
+		}
+	}
+
+	// Migration 36: Inbox of background task outcomes
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS notifications (
+			id INTEGER PRIMARY KEY,
+			kind TEXT NOT NULL,
+			task_kind TEXT,
+			title TEXT NOT NULL,
+			body TEXT NOT NULL DEFAULT '',
+			read INTEGER NOT NULL DEFAULT 0,
+			created_at TEXT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create notifications table: %w", err)
+	}
+
+	// Migration 37: Log of recent refresh attempts per feed
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS feed_fetch_log (
+			id INTEGER PRIMARY KEY,
+			feed_id INTEGER NOT NULL,
+			fetched_at TEXT NOT NULL,
+			status TEXT NOT NULL,
+			http_status INTEGER,
+			duration_ms INTEGER NOT NULL,
+			new_entries INTEGER NOT NULL DEFAULT 0,
+			error TEXT,
+			FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create feed_fetch_log table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_feed_fetch_log_feed ON feed_fetch_log(feed_id, fetched_at DESC)`); err != nil {
+		return fmt.Errorf("create idx_feed_fetch_log_feed: %w", err)
+	}
+
+	// Migration 38: Failed refreshes in a row and automatic disabling of
+	// feeds, and the feed a notification is about
+	for _, column := range []struct{ table, name, definition string }{
+		{"feeds", "consecutive_failures", "INTEGER NOT NULL DEFAULT 0"},
+		{"feeds", "disabled_at", "TEXT"},
+		{"notifications", "feed_id", "INTEGER REFERENCES feeds(id) ON DELETE CASCADE"},
+	} {
+		err = db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?
+		`, column.table, column.name).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("check %s %s column: %w", column.table, column.name, err)
+		}
+		if count == 0 {
+			if _, err := db.Exec(`ALTER TABLE ` + column.table + ` ADD COLUMN ` + column.name + ` ` + column.definition); err != nil {
+				return fmt.Errorf("add %s %s column: %w", column.table, column.name, err)
+			}
+		}
+	}
+
+	// Migration 39: WebSub subscriptions of feeds to their hubs
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS websub_subscriptions (
+			feed_id INTEGER PRIMARY KEY,
+			hub TEXT NOT NULL,
+			topic TEXT NOT NULL,
+			secret TEXT NOT NULL,
+			state TEXT NOT NULL,
+			lease_expires_at TEXT,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL,
+			FOREIGN KEY (feed_id) REFERENCES feeds(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create websub_subscriptions table: %w", err)
+	}
+
+	// Migration 40: What readability extraction learned about an entry's
+	// page: its language, text direction, byline and site name
+	for _, column := range []string{"readable_lang", "readable_dir", "readable_byline", "readable_site_name"} {
+		err = db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info('entries') WHERE name = ?
+		`, column).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("check entries %s column: %w", column, err)
+		}
+		if count == 0 {
+			if _, err := db.Exec(`ALTER TABLE entries ADD COLUMN ` + column + ` TEXT NOT NULL DEFAULT ''`); err != nil {
+				return fmt.Errorf("add entries %s column: %w", column, err)
+			}
+		}
+	}
+
+	// Migration 41: The publish date a feed gave an entry when ingest moved
+	// it, as it was in the future
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('entries') WHERE name = 'original_published_at'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check entries original_published_at column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE entries ADD COLUMN original_published_at TEXT`); err != nil {
+			return fmt.Errorf("add entries original_published_at column: %w", err)
+		}
+	}
+
+	// Migration 42: Plain text and simplified HTML versions of entry
+	// content, derived at ingest for clients that ask for them
+	for _, column := range []string{"content_text", "content_clean"} {
+		err = db.QueryRow(`
+			SELECT COUNT(*) FROM pragma_table_info('entries') WHERE name = ?
+		`, column).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("check entries %s column: %w", column, err)
+		}
+		if count == 0 {
+			if _, err := db.Exec(`ALTER TABLE entries ADD COLUMN ` + column + ` TEXT`); err != nil {
+				return fmt.Errorf("add entries %s column: %w", column, err)
+			}
+		}
+	}
+
+	// Migration 43: The page a linkblog entry points to (JSON Feed
+	// external_url), and the files attached to entries: JSON Feed
+	// attachments and RSS enclosures
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('entries') WHERE name = 'external_url'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check entries external_url column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE entries ADD COLUMN external_url TEXT`); err != nil {
+			return fmt.Errorf("add entries external_url column: %w", err)
+		}
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_attachments (
+			entry_id INTEGER NOT NULL,
+			position INTEGER NOT NULL,
+			url TEXT NOT NULL,
+			mime_type TEXT NOT NULL DEFAULT '',
+			title TEXT NOT NULL DEFAULT '',
+			size_bytes INTEGER NOT NULL DEFAULT 0,
+			duration_seconds INTEGER NOT NULL DEFAULT 0,
+			PRIMARY KEY (entry_id, position),
+			FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create entry_attachments table: %w", err)
+	}
+
+	// Migration 44: Leases on background tasks, so instances sharing the
+	// database do not run the same task at once. expires_at is in Unix
+	// milliseconds so expiry compares as a number.
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS leases (
+			name TEXT PRIMARY KEY,
+			holder TEXT NOT NULL,
+			expires_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create leases table: %w", err)
+	}
+
+	// Migration 45: Where playback of an entry's audio or video stopped, so
+	// a podcast episode resumes on any device
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS playback_positions (
+			entry_id INTEGER PRIMARY KEY,
+			position_seconds INTEGER NOT NULL,
+			updated_at TEXT NOT NULL,
+			FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create playback_positions table: %w", err)
+	}
+
+	// Migration 46: User-defined tags and the entries tagged with them.
+	// Names are unique ignoring case.
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS tags (
+			id INTEGER PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("create tags table: %w", err)
+	}
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS entry_tags (
+			entry_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			created_at TEXT NOT NULL,
+			PRIMARY KEY (entry_id, tag_id),
+			FOREIGN KEY (entry_id) REFERENCES entries(id) ON DELETE CASCADE,
+			FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+		)
+	`); err != nil {
+		return fmt.Errorf("create entry_tags table: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_entry_tags_tag_id ON entry_tags(tag_id)`); err != nil {
+		return fmt.Errorf("create entry_tags tag index: %w", err)
+	}
+
+	// Migration 47: Per-feed option to fetch readable content of new entries
+	// during refresh
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = 'prefetch_readability'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check feeds prefetch_readability column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN prefetch_readability INTEGER NOT NULL DEFAULT 0`); err != nil {
+			return fmt.Errorf("add feeds prefetch_readability column: %w", err)
+		}
+	}
+
+	// Migration 48: Covering index for the unread counts per feed and
+	// folder, replacing the one per feed only
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_entries_read_feed_folder ON entries(read, feed_id, folder_id)`); err != nil {
+		return fmt.Errorf("create idx_entries_read_feed_folder: %w", err)
+	}
+	if _, err := db.Exec(`DROP INDEX IF EXISTS idx_entries_read_feed`); err != nil {
+		return fmt.Errorf("drop idx_entries_read_feed: %w", err)
+	}
+
+	// Migration 49: Index for the latest entry change, which API responses
+	// are revalidated against
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_entries_updated_at ON entries(updated_at)`); err != nil {
+		return fmt.Errorf("create idx_entries_updated_at: %w", err)
+	}
+
+	// Migration 50: Per-feed corrections of how entry dates are read
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = 'date_quirks'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check feeds date_quirks column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN date_quirks TEXT`); err != nil {
+			return fmt.Errorf("add feeds date_quirks column: %w", err)
+		}
+	}
+
+	// Migration 51: Saved state of OPML imports, so an import cut short by a
+	// restart resumes from its last feed
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS import_tasks (
+			id TEXT PRIMARY KEY,
+			status TEXT NOT NULL,
+			document TEXT NOT NULL DEFAULT '',
+			total INTEGER NOT NULL,
+			processed INTEGER NOT NULL DEFAULT 0,
+			result TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			created_at TEXT NOT NULL,
+			updated_at TEXT NOT NULL,
+			finished_at TEXT
+		)
+	`); err != nil {
+		return fmt.Errorf("create import_tasks table: %w", err)
+	}
+
+	// Migration 52: CSS selectors of feeds scraped from pages without a feed
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = 'scraper'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check feeds scraper column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN scraper TEXT`); err != nil {
+			return fmt.Errorf("add feeds scraper column: %w", err)
+		}
+	}
+
+	// Migration 53: User-Agent and headers feeds are requested with
+	err = db.QueryRow(`
+		SELECT COUNT(*) FROM pragma_table_info('feeds') WHERE name = 'request_overrides'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("check feeds request_overrides column: %w", err)
+	}
+	if count == 0 {
+		if _, err := db.Exec(`ALTER TABLE feeds ADD COLUMN request_overrides TEXT`); err != nil {
+			return fmt.Errorf("add feeds request_overrides column: %w", err)
+		}
+	}
+
 	return nil
 }
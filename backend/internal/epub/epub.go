@@ -0,0 +1,216 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gist/backend/internal/htmlclean"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Book is a single article to package as an EPUB 3 book.
+type Book struct {
+	// ID identifies the book across downloads, e.g. "urn:gist:entry:42".
+	ID        string
+	Title     string
+	Author    string
+	Publisher string // the feed the article came from
+	SourceURL string
+	Published *time.Time
+	Modified  time.Time
+	// Content is the article HTML. It is reduced to a safe subset of XHTML;
+	// images and media are dropped, since e-readers cannot load them offline.
+	Content string
+}
+
+// Write packages the book as an EPUB file.
+func Write(w io.Writer, book Book) error {
+	body, err := toXHTML(book.Content)
+	if err != nil {
+		return fmt.Errorf("convert content: %w", err)
+	}
+
+	zw := zip.NewWriter(w)
+	// The mimetype file must come first and stay uncompressed
+	mimetype, err := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store, Modified: book.Modified})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return err
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"META-INF/container.xml", containerXML},
+		{"OEBPS/content.opf", packageDocument(book)},
+		{"OEBPS/nav.xhtml", navDocument(book)},
+		{"OEBPS/article.xhtml", articleDocument(book, body)},
+	}
+	for _, file := range files {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: book.Modified})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, file.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+func packageDocument(book Book) string {
+	var meta strings.Builder
+	fmt.Fprintf(&meta, "    <dc:identifier id=\"book-id\">%s</dc:identifier>\n", escape(book.ID))
+	fmt.Fprintf(&meta, "    <dc:title>%s</dc:title>\n", escape(title(book)))
+	meta.WriteString("    <dc:language>und</dc:language>\n")
+	if book.Author != "" {
+		fmt.Fprintf(&meta, "    <dc:creator>%s</dc:creator>\n", escape(book.Author))
+	}
+	if book.Publisher != "" {
+		fmt.Fprintf(&meta, "    <dc:publisher>%s</dc:publisher>\n", escape(book.Publisher))
+	}
+	if book.SourceURL != "" {
+		fmt.Fprintf(&meta, "    <dc:source>%s</dc:source>\n", escape(book.SourceURL))
+	}
+	if book.Published != nil {
+		fmt.Fprintf(&meta, "    <dc:date>%s</dc:date>\n", book.Published.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintf(&meta, "    <meta property=\"dcterms:modified\">%s</meta>\n", book.Modified.UTC().Format("2006-01-02T15:04:05Z"))
+
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+` + meta.String() + `  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="article" href="article.xhtml" media-type="application/xhtml+xml"/>
+  </manifest>
+  <spine>
+    <itemref idref="article"/>
+  </spine>
+</package>
+`
+}
+
+func navDocument(book Book) string {
+	t := escape(title(book))
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops">
+<head><title>` + t + `</title></head>
+<body>
+  <nav epub:type="toc"><ol><li><a href="article.xhtml">` + t + `</a></li></ol></nav>
+</body>
+</html>
+`
+}
+
+func articleDocument(book Book, body string) string {
+	t := escape(title(book))
+	var byline []string
+	if book.Author != "" {
+		byline = append(byline, escape(book.Author))
+	}
+	if book.Publisher != "" {
+		byline = append(byline, escape(book.Publisher))
+	}
+	if book.Published != nil {
+		byline = append(byline, book.Published.UTC().Format("2006-01-02"))
+	}
+
+	var header strings.Builder
+	header.WriteString("<h1>" + t + "</h1>\n")
+	if len(byline) > 0 {
+		header.WriteString("<p><em>" + strings.Join(byline, " · ") + "</em></p>\n")
+	}
+	if book.SourceURL != "" {
+		u := escape(book.SourceURL)
+		header.WriteString(`<p><a href="` + u + `">` + u + "</a></p>\n")
+	}
+
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title>` + t + `</title></head>
+<body>
+` + header.String() + "<hr/>\n" + body + `
+</body>
+</html>
+`
+}
+
+func title(book Book) string {
+	if strings.TrimSpace(book.Title) == "" {
+		return "Untitled"
+	}
+	return book.Title
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// keptElements are the HTML elements copied into the book. Other elements
+// are unwrapped, keeping their text, unless listed in droppedElements.
+var keptElements = htmlclean.TextElements()
+
+// droppedElements are removed together with their content.
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Img: true,
+	atom.Picture: true, atom.Video: true, atom.Audio: true, atom.Svg: true, atom.Math: true,
+	atom.Form: true, atom.Input: true, atom.Button: true, atom.Select: true,
+	atom.Textarea: true, atom.Head: true,
+}
+
+// keptAttributes are the attributes copied onto kept elements.
+var keptAttributes = map[string]bool{
+	"href": true, "title": true, "colspan": true, "rowspan": true, "datetime": true,
+}
+
+// toXHTML reduces an HTML fragment to well-formed XHTML body content.
+// Relative links would point into the book, which has only the one page, so
+// only links to web pages are kept.
+func toXHTML(content string) (string, error) {
+	policy := htmlclean.Policy{
+		Elements:   keptElements,
+		Dropped:    droppedElements,
+		Attributes: keptAttributes,
+		URL: func(_ *html.Node, _, val string) string {
+			if !htmlclean.IsWebURL(val) {
+				return ""
+			}
+			return val
+		},
+		Text: func(text string) string { return strings.Map(xmlChar, text) },
+	}
+	return policy.Clean(content)
+}
+
+// xmlChar drops the control characters XML does not allow.
+func xmlChar(r rune) rune {
+	if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+		return -1
+	}
+	return r
+}
@@ -0,0 +1,94 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWrite(t *testing.T) {
+	published := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	book := Book{
+		ID:        "urn:gist:entry:42",
+		Title:     "Fish & Chips <tonight>",
+		Author:    "Ann",
+		Publisher: "Food Blog",
+		SourceURL: "https://example.com/fish?a=1&b=2",
+		Published: &published,
+		Modified:  published,
+		Content: `<p>Hello&nbsp;<b>world</b><br><img src="x.jpg"></p>` +
+			`<script>alert(1)</script><o:p>Word</o:p><p onclick="x()">Tea` + "\x01" + `</p>` +
+			`<a href="/local">local</a> <a href="https://example.com">web</a>` +
+			`<table><tr><td colspan=2>cell</td></tr></table><noscript><img src=y></noscript>`,
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, book); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open zip: %v", err)
+	}
+
+	if first := zr.File[0]; first.Name != "mimetype" || first.Method != zip.Store {
+		t.Fatalf("expected an uncompressed mimetype first, got %s (method %d)", first.Name, first.Method)
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(r)
+		r.Close()
+		files[f.Name] = string(data)
+		if strings.HasSuffix(f.Name, ".xml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".xhtml") {
+			assertWellFormed(t, f.Name, data)
+		}
+	}
+	if files["mimetype"] != "application/epub+zip" {
+		t.Errorf("unexpected mimetype %q", files["mimetype"])
+	}
+
+	article := files["OEBPS/article.xhtml"]
+	for _, want := range []string{
+		"<h1>Fish &amp; Chips &lt;tonight&gt;</h1>",
+		"Hello\u00a0<b>world</b><br/>",
+		"Word",
+		"<p>Tea</p>",
+		"<a>local</a>",
+		`<a href="https://example.com">web</a>`,
+		`<td colspan="2">cell</td>`,
+	} {
+		if !strings.Contains(article, want) {
+			t.Errorf("expected article to contain %q:\n%s", want, article)
+		}
+	}
+	for _, unwanted := range []string{"<img", "<script", "alert", "<o:p", "onclick", "<noscript"} {
+		if strings.Contains(article, unwanted) {
+			t.Errorf("expected article without %q:\n%s", unwanted, article)
+		}
+	}
+	if !strings.Contains(files["OEBPS/content.opf"], "<dc:creator>Ann</dc:creator>") {
+		t.Errorf("expected the author in the package document:\n%s", files["OEBPS/content.opf"])
+	}
+}
+
+func assertWellFormed(t *testing.T, name string, data []byte) {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			t.Fatalf("%s is not well-formed XML: %v\n%s", name, err, data)
+		}
+	}
+}
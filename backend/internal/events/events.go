@@ -0,0 +1,113 @@
+// Package events fans out notifications about background work, such as
+// refreshed feeds and task progress, to connected clients.
+package events
+
+import "sync"
+
+// Event types
+const (
+	// TypeFeedRefreshed is sent when a feed was fetched; Data is FeedRefreshed.
+	TypeFeedRefreshed = "feed_refreshed"
+	// TypeUnreadDelta is sent when a feed's unread count changed; Data is UnreadDelta.
+	TypeUnreadDelta = "unread_delta"
+	// TypeTask is sent when a task starts, reports progress or ends; Data is
+	// the task snapshot.
+	TypeTask = "task"
+	// TypeNotification is sent when a notification was added to the inbox;
+	// Data is Notification.
+	TypeNotification = "notification"
+	// TypeFeedDisabled is sent when a feed was disabled after failing too
+	// many refreshes in a row; Data is FeedDisabled.
+	TypeFeedDisabled = "feed_disabled"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind by before
+// it is dropped.
+const subscriberBuffer = 64
+
+// Event is a notification of one of the types above.
+type Event struct {
+	Type string
+	Data interface{}
+}
+
+// FeedRefreshed reports a fetched feed and how many entries it added.
+type FeedRefreshed struct {
+	FeedID     int64  `json:"feedId,string"`
+	Title      string `json:"title"`
+	NewEntries int    `json:"newEntries"`
+}
+
+// UnreadDelta reports a change to a feed's unread count.
+type UnreadDelta struct {
+	FeedID int64 `json:"feedId,string"`
+	Delta  int   `json:"delta"`
+}
+
+// FeedDisabled reports a feed disabled after failing Failures refreshes in a
+// row, the last with Error.
+type FeedDisabled struct {
+	FeedID   int64  `json:"feedId,string"`
+	Title    string `json:"title"`
+	Failures int    `json:"failures"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Notification reports a new inbox notification.
+type Notification struct {
+	ID    int64  `json:"id,string"`
+	Kind  string `json:"kind"`
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+}
+
+// Hub delivers published events to every current subscriber. Publishing
+// never blocks: a subscriber that falls subscriberBuffer events behind has
+// its channel closed, so it can reconnect and reload instead of silently
+// missing events. A nil *Hub discards everything.
+type Hub struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: make(map[chan Event]struct{})}
+}
+
+// Subscribe returns a channel of the events published from now on and a
+// function that ends the subscription.
+func (h *Hub) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() { h.drop(ch) }
+}
+
+// Publish sends an event to all subscribers.
+func (h *Hub) Publish(eventType string, data interface{}) {
+	if h == nil {
+		return
+	}
+	ev := Event{Type: eventType, Data: data}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- ev:
+		default:
+			delete(h.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// drop ends a subscription, if it has not been dropped already.
+func (h *Hub) drop(ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[ch]; ok {
+		delete(h.subs, ch)
+		close(ch)
+	}
+}
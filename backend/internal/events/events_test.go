@@ -0,0 +1,34 @@
+package events
+
+import "testing"
+
+func TestHub_PublishDropsSlowSubscribers(t *testing.T) {
+	hub := NewHub()
+	fast, stopFast := hub.Subscribe()
+	defer stopFast()
+	slow, stopSlow := hub.Subscribe()
+	defer stopSlow()
+
+	for i := 0; i < subscriberBuffer; i++ {
+		hub.Publish(TypeTask, i)
+		<-fast
+	}
+	// slow has not read anything and its buffer is full now
+	hub.Publish(TypeTask, "one too many")
+	if ev := <-fast; ev.Data != "one too many" {
+		t.Errorf("expected the fast subscriber to get the event, got %+v", ev)
+	}
+
+	count := 0
+	for range slow {
+		count++
+	}
+	if count != subscriberBuffer {
+		t.Errorf("expected %d buffered events before the slow subscriber was dropped, got %d", subscriberBuffer, count)
+	}
+}
+
+func TestHub_NilDiscards(t *testing.T) {
+	var hub *Hub
+	hub.Publish(TypeTask, nil) // must not panic
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+// databaseMediaTypes are the accepted types for an uploaded database.
+// Browsers rarely know .db, so generic types are accepted and the restore
+// checks the file.
+var databaseMediaTypes = map[string]bool{
+	"application/vnd.sqlite3":  true,
+	"application/x-sqlite3":    true,
+	"application/octet-stream": true,
+}
+
+type AdminHandler struct {
+	wipe           service.WipeService
+	maintenance    service.MaintenanceService
+	maxRestoreSize int64
+}
+
+func NewAdminHandler(wipe service.WipeService, maintenance service.MaintenanceService, maxRestoreSize int64) *AdminHandler {
+	return &AdminHandler{wipe: wipe, maintenance: maintenance, maxRestoreSize: maxRestoreSize}
+}
+
+// RegisterRoutes registers the admin routes. They erase, replace or hand
+// out all data of the instance, so they are guarded by auth.
+func (h *AdminHandler) RegisterRoutes(g *echo.Group, auth echo.MiddlewareFunc) {
+	g.POST("/admin/wipe/token", h.WipeToken, auth)
+	g.DELETE("/admin/wipe", h.Wipe, auth)
+	g.POST("/admin/backup", h.Backup, auth)
+	g.POST("/admin/restore", h.Restore, auth)
+}
+
+// WipeToken issues the confirmation token of a wipe.
+// @Summary Request a wipe confirmation token
+// @Description Issue the single-use token DELETE /admin/wipe must carry. It expires after 5 minutes; requesting another replaces it. Requires the API token as a Bearer token or X-Gist-Token header.
+// @Tags admin
+// @Produce json
+// @Security ApiToken
+// @Success 200 {object} service.WipeConfirmation
+// @Failure 401 {object} errorResponse
+// @Router /admin/wipe/token [post]
+func (h *AdminHandler) WipeToken(c echo.Context) error {
+	confirmation, err := h.wipe.Confirm()
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, confirmation)
+}
+
+// Wipe erases all data of the instance.
+// @Summary Wipe all data
+// @Description Delete every folder, feed, entry, setting and cache, the cached icons, media and backups, and replace the secret key, leaving the instance as on first run. The config file and its API token are kept. Cannot be undone. Requires the API token as a Bearer token or X-Gist-Token header, and a token from POST /admin/wipe/token as confirm.
+// @Tags admin
+// @Produce json
+// @Security ApiToken
+// @Param confirm query string true "Confirmation token from POST /admin/wipe/token"
+// @Success 200 {object} service.WipeResult
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Router /admin/wipe [delete]
+func (h *AdminHandler) Wipe(c echo.Context) error {
+	var v validator
+	confirm := c.QueryParam("confirm")
+	if confirm == "" {
+		v.fail("confirm", fieldRequired, "is required")
+		return v.write(c)
+	}
+
+	result, err := h.wipe.Wipe(c.Request().Context(), confirm)
+	if errors.Is(err, service.ErrInvalid) {
+		v.fail("confirm", fieldInvalidFormat, "must be an unused confirmation token issued in the last 5 minutes")
+		return v.write(c)
+	}
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// Backup downloads a copy of the whole database.
+// @Summary Back up the database
+// @Description Take a consistent, compacted copy of the database (SQLite VACUUM INTO, while the instance keeps running) and download it as gist-YYYYMMDD-HHMMSS.db. The secret key sealing stored credentials (secret.key in the data directory) is not included; copy it along to keep them usable on another host. Requires the API token as a Bearer token or X-Gist-Token header.
+// @Tags admin
+// @Produce application/vnd.sqlite3
+// @Security ApiToken
+// @Success 200 {file} binary
+// @Failure 401 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /admin/backup [post]
+func (h *AdminHandler) Backup(c echo.Context) error {
+	snapshot, err := h.maintenance.Backup(c.Request().Context())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	defer snapshot.Close()
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+snapshot.Name+`"`)
+	res.Header().Set(echo.HeaderContentLength, strconv.FormatInt(snapshot.Size, 10))
+	return c.Stream(http.StatusOK, "application/vnd.sqlite3", snapshot)
+}
+
+// Restore replaces all data with an uploaded database.
+// @Summary Restore the database
+// @Description Replace every folder, feed, entry, setting and cache with those of a database from POST /admin/backup, uploaded as the multipart field "file" or as the raw body. The file is checked for integrity and brought up to the current schema first, so backups of older versions can be restored; a file that is not an intact Gist database is rejected with 400 and the data is kept. The data is replaced in one transaction. Cached icons and media are kept. Requires the API token as a Bearer token or X-Gist-Token header.
+// @Tags admin
+// @Accept multipart/form-data
+// @Accept application/vnd.sqlite3
+// @Produce json
+// @Security ApiToken
+// @Param file formData file true "Database file"
+// @Success 200 {object} service.RestoreResult
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 413 {object} errorResponse
+// @Failure 415 {object} errorResponse
+// @Router /admin/restore [post]
+func (h *AdminHandler) Restore(c echo.Context) error {
+	reader, err := openUpload(c, h.maxRestoreSize, databaseMediaTypes)
+	if err != nil {
+		return writeUploadError(c, err)
+	}
+
+	result, err := h.maintenance.Restore(c.Request().Context(), reader)
+	if errors.Is(err, service.ErrInvalid) {
+		return Error(c, CodeInvalidRequest, "not a valid Gist database")
+	}
+	if err != nil {
+		return writeUploadError(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
@@ -1,15 +1,16 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
 
 	"gist/backend/internal/service"
+	"gist/backend/internal/service/ai"
 )
 
 type AIHandler struct {
@@ -42,6 +43,23 @@ type translateResponse struct {
 	Cached  bool   `json:"cached"`
 }
 
+// withQueueEvents returns a context whose AI requests report on the returned
+// channel when the rate limit holds them back. Only the latest unread report
+// is kept.
+func withQueueEvents(ctx context.Context) (context.Context, <-chan ai.QueueStatus) {
+	queueCh := make(chan ai.QueueStatus, 1)
+	return ai.WithQueueNotifier(ctx, func(status ai.QueueStatus) {
+		select {
+		case <-queueCh:
+		default:
+		}
+		select {
+		case queueCh <- status:
+		default:
+		}
+	}), queueCh
+}
+
 func NewAIHandler(service service.AIService) *AIHandler {
 	return &AIHandler{service: service}
 }
@@ -56,6 +74,7 @@ func (h *AIHandler) RegisterRoutes(g *echo.Group) {
 // Summarize generates an AI summary of the content.
 // @Summary Generate AI summary
 // @Description Generate an AI summary of the article content. Returns cached result if available, otherwise streams the response.
+// @Description While the AI rate limit holds the request back, "event: queue" events carrying a JSON {"position","waitMs"} are sent before the text, about every second, so the stream does not look hung; closing the request leaves the queue at once.
 // @Tags ai
 // @Accept json
 // @Produce json
@@ -68,20 +87,17 @@ func (h *AIHandler) RegisterRoutes(g *echo.Group) {
 func (h *AIHandler) Summarize(c echo.Context) error {
 	var req summarizeRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 
-	if req.Content == "" {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "content is required"})
+	var v validator
+	entryID := v.id("entryId", req.EntryID)
+	v.required("content", req.Content)
+	if v.failed() {
+		return v.write(c)
 	}
 
-	// Parse entry ID
-	entryID, err := strconv.ParseInt(req.EntryID, 10, 64)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid entry ID"})
-	}
-
-	ctx := c.Request().Context()
+	ctx, queueCh := withQueueEvents(c.Request().Context())
 
 	// Check cache first
 	cached, err := h.service.GetCachedSummary(ctx, entryID, req.IsReadability)
@@ -98,7 +114,7 @@ func (h *AIHandler) Summarize(c echo.Context) error {
 	// Generate summary with streaming
 	textCh, errCh, err := h.service.Summarize(ctx, entryID, req.Content, req.Title, req.IsReadability)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return Error(c, CodeAIRequestFailed, err.Error())
 	}
 
 	// Set headers for SSE
@@ -145,6 +161,11 @@ func (h *AIHandler) Summarize(c echo.Context) error {
 			}
 			c.Response().Flush()
 
+		case status := <-queueCh:
+			data, _ := json.Marshal(status)
+			fmt.Fprintf(c.Response(), "event: queue\ndata: %s\n\n", data)
+			c.Response().Flush()
+
 		case <-ctx.Done():
 			return nil
 		}
@@ -178,9 +199,15 @@ type translateErrorEvent struct {
 	Error string `json:"error"`
 }
 
+// translateQueueEvent reports blocks held back by the AI rate limit.
+type translateQueueEvent struct {
+	Queued ai.QueueStatus `json:"queued"`
+}
+
 // Translate generates an AI translation of the content.
 // @Summary Generate AI translation
 // @Description Translate article content. Returns cached result if available, otherwise streams block translations via SSE.
+// @Description While the AI rate limit holds blocks back, {"queued":{"position","waitMs"}} events are sent about every second; closing the request leaves the queue at once.
 // @Tags ai
 // @Accept json
 // @Produce json
@@ -193,20 +220,17 @@ type translateErrorEvent struct {
 func (h *AIHandler) Translate(c echo.Context) error {
 	var req translateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
-	}
-
-	if req.Content == "" {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "content is required"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 
-	// Parse entry ID
-	entryID, err := strconv.ParseInt(req.EntryID, 10, 64)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid entry ID"})
+	var v validator
+	entryID := v.id("entryId", req.EntryID)
+	v.required("content", req.Content)
+	if v.failed() {
+		return v.write(c)
 	}
 
-	ctx := c.Request().Context()
+	ctx, queueCh := withQueueEvents(c.Request().Context())
 
 	// Check cache first
 	cached, err := h.service.GetCachedTranslation(ctx, entryID, req.IsReadability)
@@ -223,7 +247,7 @@ func (h *AIHandler) Translate(c echo.Context) error {
 	// Start block translation
 	blockInfos, resultCh, errCh, err := h.service.TranslateBlocks(ctx, entryID, req.Content, req.Title, req.IsReadability)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return Error(c, CodeAIRequestFailed, err.Error())
 	}
 
 	// Set headers for SSE
@@ -275,6 +299,11 @@ func (h *AIHandler) Translate(c echo.Context) error {
 				// Continue to receive remaining results
 			}
 
+		case status := <-queueCh:
+			data, _ := json.Marshal(translateQueueEvent{Queued: status})
+			fmt.Fprintf(c.Response(), "data: %s\n\n", data)
+			c.Response().Flush()
+
 		case <-ctx.Done():
 			return nil
 		}
@@ -304,16 +333,18 @@ type batchTranslateRequest struct {
 func (h *AIHandler) TranslateBatch(c echo.Context) error {
 	var req batchTranslateRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 
 	if len(req.Articles) == 0 {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "articles is required"})
+		var v validator
+		v.fail("articles", fieldRequired, "must contain at least one article")
+		return v.write(c)
 	}
 
 	// Limit batch size
 	if len(req.Articles) > 100 {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "maximum 100 articles per batch"})
+		return Error(c, CodeBatchTooLarge, "maximum 100 articles per batch")
 	}
 
 	ctx := c.Request().Context()
@@ -331,7 +362,7 @@ func (h *AIHandler) TranslateBatch(c echo.Context) error {
 	// Start batch translation
 	resultCh, errCh, err := h.service.TranslateBatch(ctx, articles)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return Error(c, CodeAIRequestFailed, err.Error())
 	}
 
 	// Set headers for NDJSON streaming
@@ -386,7 +417,7 @@ func (h *AIHandler) ClearCache(c echo.Context) error {
 
 	summaries, translations, listTranslations, err := h.service.ClearAllCache(ctx)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return Error(c, CodeAIRequestFailed, err.Error())
 	}
 
 	return c.JSON(http.StatusOK, clearCacheResponse{
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+type BackupHandler struct {
+	service service.BackupService
+}
+
+func NewBackupHandler(backups service.BackupService) *BackupHandler {
+	return &BackupHandler{service: backups}
+}
+
+func (h *BackupHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/backups", h.List)
+	g.GET("/backups/:name", h.Download)
+}
+
+// List returns the stored backups.
+// @Summary List backups
+// @Description Get the backups written by the backup job (every backupInterval hours of the general settings, keeping the newest backupKeep), newest first. Each is a zip of subscriptions.opml and starred.jsonl.
+// @Tags backups
+// @Produce json
+// @Success 200 {array} service.BackupFile
+// @Failure 500 {object} errorResponse
+// @Router /backups [get]
+func (h *BackupHandler) List(c echo.Context) error {
+	backups, err := h.service.List(c.Request().Context())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, backups)
+}
+
+// Download sends a backup.
+// @Summary Download a backup
+// @Description Download a backup as a zip holding the OPML export of the subscriptions (subscriptions.opml) and the starred entries as JSON Lines (starred.jsonl).
+// @Tags backups
+// @Produce application/zip
+// @Param name path string true "Backup name"
+// @Success 200 {file} binary
+// @Failure 404 {object} errorResponse
+// @Router /backups/{name} [get]
+func (h *BackupHandler) Download(c echo.Context) error {
+	name := c.Param("name")
+	path, err := h.service.Path(name)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Attachment(path, name)
+}
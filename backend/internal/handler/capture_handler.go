@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+
+	"gist/backend/internal/service"
+)
+
+// maxCaptureSize caps a capture request, which may carry a whole page.
+const maxCaptureSize = 10 << 20
+
+type CaptureHandler struct {
+	readLater service.ReadLaterService
+}
+
+func NewCaptureHandler(readLater service.ReadLaterService) *CaptureHandler {
+	return &CaptureHandler{readLater: readLater}
+}
+
+// RegisterRoutes registers the capture and share routes. Bookmarklets call
+// capture from the page being saved, so it answers cross-origin requests; the
+// token it requires is sent as a header, never as a cookie.
+func (h *CaptureHandler) RegisterRoutes(g *echo.Group, auth echo.MiddlewareFunc) {
+	cors := middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{http.MethodPost},
+		AllowHeaders: []string{echo.HeaderAuthorization, echo.HeaderContentType, "X-Gist-Token"},
+	})
+	g.Match([]string{http.MethodPost, http.MethodOptions}, "/capture", h.Capture, cors, auth)
+	// The share sheet posts a plain form and cannot send the token, so, like
+	// the app's own routes, sharing is open
+	g.POST("/share", h.Share)
+}
+
+type captureRequest struct {
+	URL string `json:"url" form:"url"`
+	// Title overrides the page title
+	Title string `json:"title,omitempty" form:"title"`
+	// HTML is the page as the browser rendered it, e.g. document.documentElement.outerHTML.
+	// Without it the page is fetched.
+	HTML string `json:"html,omitempty" form:"html"`
+}
+
+// Capture saves a web page from a bookmarklet or browser extension.
+// @Summary Save a page
+// @Description Save a web page into the Saved pages feed, extracting its readable content and site icon. Send the page HTML to save what the browser shows, e.g. behind a login; otherwise the page is fetched. A page saved before is returned as it is. Requires the API token as a Bearer token or X-Gist-Token header; cross-origin requests are allowed.
+// @Tags entries
+// @Accept json,x-www-form-urlencoded
+// @Produce json
+// @Security ApiToken
+// @Param request body captureRequest true "Page to save"
+// @Success 201 {object} entryResponse
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 413 {object} errorResponse
+// @Router /capture [post]
+func (h *CaptureHandler) Capture(c echo.Context) error {
+	c.Request().Body = http.MaxBytesReader(c.Response().Writer, c.Request().Body, maxCaptureSize)
+	var req captureRequest
+	if err := c.Bind(&req); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return Error(c, CodeFileTooLarge, "page too large")
+		}
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if v.required("url", req.URL) {
+		v.httpURL("url", req.URL)
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	entry, err := h.readLater.Save(c.Request().Context(), service.SavePageParams{URL: req.URL, Title: req.Title, HTML: req.HTML})
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusCreated, toEntryResponse(entry))
+}
+
+// sharedURLPattern finds a link in shared text; apps often share "Title https://…".
+var sharedURLPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// Share saves a page shared to the installed app from the system share sheet.
+// @Summary Web Share Target
+// @Description Target of the PWA manifest's share_target. Saves the shared page into the Saved pages feed and redirects to it in the app. The page URL is taken from url, or else from the first link in text, since many Android apps share the link as text.
+// @Tags entries
+// @Accept x-www-form-urlencoded,mpfd
+// @Param title formData string false "Shared title"
+// @Param text formData string false "Shared text"
+// @Param url formData string false "Shared URL"
+// @Success 303 "Redirect to the saved entry"
+// @Failure 400 {object} errorResponse
+// @Router /share [post]
+func (h *CaptureHandler) Share(c echo.Context) error {
+	pageURL := strings.TrimSpace(c.FormValue("url"))
+	if pageURL == "" {
+		// Trailing punctuation is more likely prose than part of the link
+		pageURL = strings.TrimRight(sharedURLPattern.FindString(c.FormValue("text")), ".,;:!?)]}'")
+	}
+	var v validator
+	if v.required("url", pageURL) {
+		v.httpURL("url", pageURL)
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	entry, err := h.readLater.Save(c.Request().Context(), service.SavePageParams{URL: pageURL, Title: c.FormValue("title")})
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Redirect(http.StatusSeeOther, fmt.Sprintf("/feed/%d/%d", entry.FeedID, entry.ID))
+}
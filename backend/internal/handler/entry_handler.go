@@ -2,8 +2,9 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
-	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -15,23 +16,35 @@ import (
 type EntryHandler struct {
 	service            service.EntryService
 	readabilityService service.ReadabilityService
+	exportService      service.ExportService
 }
 
-func NewEntryHandler(service service.EntryService, readabilityService service.ReadabilityService) *EntryHandler {
-	return &EntryHandler{service: service, readabilityService: readabilityService}
+func NewEntryHandler(service service.EntryService, readabilityService service.ReadabilityService, exportService service.ExportService) *EntryHandler {
+	return &EntryHandler{service: service, readabilityService: readabilityService, exportService: exportService}
 }
 
 func (h *EntryHandler) RegisterRoutes(g *echo.Group) {
 	g.GET("/entries", h.List)
+	g.GET("/entries/archive", h.Archive)
+	g.GET("/entries/search", h.Search)
+	g.GET("/entries/export", h.Export)
 	g.GET("/entries/:id", h.GetByID)
+	g.GET("/entries/:id/print", h.Print)
 	g.PATCH("/entries/:id/read", h.UpdateReadStatus)
 	g.PATCH("/entries/:id/starred", h.UpdateStarredStatus)
 	g.POST("/entries/:id/fetch-readable", h.FetchReadable)
 	g.POST("/entries/mark-read", h.MarkAllAsRead)
+	g.POST("/entries/batch", h.ApplyBatch)
 	g.GET("/unread-counts", h.GetUnreadCounts)
 	g.GET("/starred-count", h.GetStarredCount)
+	g.GET("/starred-counts", h.GetStarredCounts)
 }
 
+const (
+	defaultEntryLimit = 50
+	maxEntryLimit     = 100
+)
+
 type entryResponse struct {
 	ID              string  `json:"id"`
 	FeedID          string  `json:"feedId"`
@@ -46,15 +59,133 @@ type entryResponse struct {
 	Starred         bool    `json:"starred"`
 	CreatedAt       string  `json:"createdAt"`
 	UpdatedAt       string  `json:"updatedAt"`
+	// OriginalPublishedAt is the feed's publish date when it was in the
+	// future and publishedAt is when the entry was first seen instead
+	OriginalPublishedAt *string `json:"originalPublishedAt,omitempty"`
+	// LinkDead means the original URL is gone and this is the only copy
+	LinkDead bool `json:"linkDead,omitempty"`
+	// Revision is what the publisher changed in their latest update
+	Revision *entryRevisionResponse `json:"revision,omitempty"`
+	// Images is the ordered gallery of an entry of a picture feed
+	Images []string `json:"images,omitempty"`
+	// ExternalURL is the page a linkblog entry is about, apart from url
+	ExternalURL *string `json:"externalUrl,omitempty"`
+	// Attachments are the files attached to the entry, like podcast audio
+	Attachments []entryAttachmentResponse `json:"attachments,omitempty"`
+	// NSFWReason is why the entry is flagged as not safe for work: category, explicit, keyword or ai
+	NSFWReason *string `json:"nsfwReason,omitempty"`
+	// IconPath is the site icon of a saved page, served under /icons; absent for feed entries
+	IconPath *string `json:"iconPath,omitempty"`
+	readableMetaResponse
+}
+
+// readableMetaResponse describes the page the readable content was extracted from.
+type readableMetaResponse struct {
+	ReadableLang string `json:"readableLang,omitempty"`
+	// ReadableDir is the text direction of the readable content: rtl or ltr
+	ReadableDir      string `json:"readableDir,omitempty"`
+	ReadableByline   string `json:"readableByline,omitempty"`
+	ReadableSiteName string `json:"readableSiteName,omitempty"`
+}
+
+// entryAttachmentResponse is a file attached to an entry. Size and duration
+// are left out when the feed does not give them.
+type entryAttachmentResponse struct {
+	URL             string `json:"url"`
+	MimeType        string `json:"mimeType,omitempty" example:"audio/mpeg"`
+	Title           string `json:"title,omitempty"`
+	SizeBytes       int64  `json:"sizeBytes,omitempty"`
+	DurationSeconds int64  `json:"durationSeconds,omitempty"`
+}
+
+type entryRevisionResponse struct {
+	TitleBefore  *string              `json:"titleBefore,omitempty"`
+	WordsAdded   int                  `json:"wordsAdded"`
+	WordsRemoved int                  `json:"wordsRemoved"`
+	Changes      []textChangeResponse `json:"changes"`
+	ChangedAt    string               `json:"changedAt"`
+}
+
+type textChangeResponse struct {
+	Removed string `json:"removed,omitempty"`
+	Added   string `json:"added,omitempty"`
+}
+
+// entrySummaryResponse is an entry as listed: a plain-text snippet instead of content.
+type entrySummaryResponse struct {
+	ID           string  `json:"id"`
+	FeedID       string  `json:"feedId"`
+	Title        *string `json:"title,omitempty"`
+	URL          *string `json:"url,omitempty"`
+	Snippet      *string `json:"snippet,omitempty"`
+	ThumbnailURL *string `json:"thumbnailUrl,omitempty"`
+	// ThumbnailProxyURL serves the thumbnail scaled down, with ?w=320 or ?w=640
+	ThumbnailProxyURL *string `json:"thumbnailProxyUrl,omitempty"`
+	Author            *string `json:"author,omitempty"`
+	PublishedAt       *string `json:"publishedAt,omitempty"`
+	Read              bool    `json:"read"`
+	Starred           bool    `json:"starred"`
+	CreatedAt         string  `json:"createdAt"`
+	UpdatedAt         string  `json:"updatedAt"`
+	NSFWReason        *string `json:"nsfwReason,omitempty"`
+	IconPath          *string `json:"iconPath,omitempty"`
+}
+
+type archivePeriodResponse struct {
+	Period      string `json:"period" example:"2025-03"`
+	Count       int    `json:"count"`
+	UnreadCount int    `json:"unreadCount"`
+}
+
+type archiveResponse struct {
+	GroupBy string                  `json:"groupBy" example:"month"`
+	Periods []archivePeriodResponse `json:"periods"`
 }
 
 type readableContentResponse struct {
 	ReadableContent string `json:"readableContent"`
+	readableMetaResponse
 }
 
 type entryListResponse struct {
-	Entries []entryResponse `json:"entries"`
-	HasMore bool            `json:"hasMore"`
+	Entries []entrySummaryResponse `json:"entries"`
+	HasMore bool                   `json:"hasMore"`
+	// NextCursor continues the list with ?after=, set by the list when hasMore
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// compactEntrySummaryResponse is an entry as listed in compact mode: no
+// thumbnail, author or timestamps but the publish date, a short snippet, and
+// read and starred only when true.
+type compactEntrySummaryResponse struct {
+	ID          string  `json:"id"`
+	FeedID      string  `json:"feedId"`
+	Title       *string `json:"title,omitempty"`
+	URL         *string `json:"url,omitempty"`
+	Snippet     *string `json:"snippet,omitempty"`
+	PublishedAt *string `json:"publishedAt,omitempty"`
+	Read        bool    `json:"read,omitempty"`
+	Starred     bool    `json:"starred,omitempty"`
+}
+
+type compactEntryListResponse struct {
+	Entries    []compactEntrySummaryResponse `json:"entries"`
+	HasMore    bool                          `json:"hasMore"`
+	NextCursor string                        `json:"nextCursor,omitempty"`
+}
+
+// compactEntryResponse is an entry in compact mode: its text and little else.
+type compactEntryResponse struct {
+	ID              string  `json:"id"`
+	FeedID          string  `json:"feedId"`
+	Title           *string `json:"title,omitempty"`
+	URL             *string `json:"url,omitempty"`
+	Author          *string `json:"author,omitempty"`
+	PublishedAt     *string `json:"publishedAt,omitempty"`
+	Read            bool    `json:"read,omitempty"`
+	Starred         bool    `json:"starred,omitempty"`
+	Content         *string `json:"content,omitempty"`
+	ReadableContent *string `json:"readableContent,omitempty"`
 }
 
 type updateReadRequest struct {
@@ -69,118 +200,335 @@ type starredCountResponse struct {
 	Count int `json:"count"`
 }
 
+type starredCountsResponse struct {
+	Counts  map[string]int `json:"counts"`
+	Folders map[string]int `json:"folders"`
+}
+
 type markAllReadRequest struct {
 	FeedID      *string `json:"feedId,omitempty"`
 	FolderID    *string `json:"folderId,omitempty"`
 	ContentType *string `json:"contentType,omitempty"`
 }
 
+type entryBatchRequest struct {
+	IDs []string `json:"ids"`
+	// Operation is read, unread, star, unstar or delete
+	Operation string `json:"operation"`
+}
+
+type entryBatchResponse struct {
+	// Updated counts the entries that changed; those already in the requested
+	// state and unknown IDs are not counted
+	Updated int64 `json:"updated"`
+}
+
 type unreadCountsResponse struct {
-	Counts map[string]int `json:"counts"`
+	Counts  map[string]int `json:"counts"`  // by feed ID
+	Folders map[string]int `json:"folders"` // by folder ID, without subfolders
+	Total   int            `json:"total"`
+	// StarredTotal counts every starred entry, whatever the scope.
+	StarredTotal int `json:"starredTotal"`
 }
 
 // List returns a list of entries.
 // @Summary List entries
-// @Description Get a list of entries with optional filters and pagination
+// @Description Get a page of entry summaries with optional filters, newest published first. Content is left out; each entry carries a plain-text snippet and the full entry comes from /entries/{id}. Page with after=nextCursor rather than offset to not skip or repeat entries when new ones arrive between pages; offset still works. With compact=true or the header Prefer: return=minimal, for e-ink devices and terminal clients, entries carry only id, feedId, title, url, a snippet cut to 140 characters, publishedAt, and read and starred when true (compactEntryListResponse).
 // @Tags entries
 // @Produce json
 // @Param feedId query int false "Filter by feed ID"
 // @Param folderId query int false "Filter by folder ID"
-// @Param contentType query string false "Filter by content type (article, picture, notification)"
+// @Param contentType query string false "Filter by content type (article, picture, notification, podcast)"
 // @Param unreadOnly query bool false "Only return unread entries"
 // @Param starredOnly query bool false "Only return starred entries"
-// @Param limit query int false "Limit the number of entries (default 50)"
-// @Param offset query int false "Offset for pagination"
+// @Param period query string false "Only return entries published in this UTC year (YYYY) or month (YYYY-MM)"
+// @Param excludeNsfw query bool false "Leave out entries flagged as not safe for work"
+// @Param tagId query int false "Filter by tag ID"
+// @Param limit query int false "Limit the number of entries (1-100, default 50)"
+// @Param offset query int false "Offset for pagination (>= 0)"
+// @Param after query string false "Continue after this cursor (publishedAt,id), the nextCursor of the previous page; not combined with offset"
+// @Param compact query bool false "Compact response, also asked for with the header Prefer: return=minimal"
 // @Success 200 {object} entryListResponse
 // @Failure 400 {object} errorResponse
 // @Router /entries [get]
 func (h *EntryHandler) List(c echo.Context) error {
-	params := service.EntryListParams{
-		Limit:  50,
-		Offset: 0,
+	var v validator
+	params := parseEntryScope(c, &v)
+	params.Limit = v.queryInt(c, "limit", defaultEntryLimit)
+	params.Offset = v.queryInt(c, "offset", 0)
+	params.After = c.QueryParam("after")
+	v.intRange("limit", params.Limit, 1, maxEntryLimit)
+	v.minInt("offset", params.Offset, 0)
+	if params.After != "" {
+		if _, err := service.ParseEntryCursor(params.After); err != nil {
+			v.fail("after", fieldInvalidFormat, "must be a publishedAt,id cursor")
+		} else if params.Offset > 0 {
+			v.fail("offset", fieldOutOfRange, "cannot be combined with after")
+		}
+	}
+	if v.failed() {
+		return v.write(c)
 	}
 
-	if raw := c.QueryParam("feedId"); raw != "" {
-		id, err := strconv.ParseInt(raw, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid feedId"})
-		}
-		params.FeedID = &id
+	// Request one extra to determine if there are more results
+	queryParams := params
+	queryParams.Limit = params.Limit + 1
+
+	entries, err := h.service.List(c.Request().Context(), queryParams)
+	if err != nil {
+		return writeServiceError(c, err)
 	}
 
-	if raw := c.QueryParam("folderId"); raw != "" {
-		id, err := strconv.ParseInt(raw, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid folderId"})
-		}
-		params.FolderID = &id
+	// Determine hasMore by checking if we got more than requested
+	hasMore := len(entries) > params.Limit
+	if hasMore {
+		entries = entries[:params.Limit] // Trim to requested limit
 	}
 
-	if raw := c.QueryParam("contentType"); raw != "" {
-		if raw != "article" && raw != "picture" && raw != "notification" {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid contentType"})
-		}
-		params.ContentType = &raw
+	nextCursor := ""
+	if hasMore {
+		nextCursor = service.EntryCursorOf(entries[len(entries)-1])
 	}
 
-	if c.QueryParam("unreadOnly") == "true" {
-		params.UnreadOnly = true
+	return writeEntryList(c, entries, hasMore, nextCursor)
+}
+
+// Archive returns entry counts per publish period.
+// @Summary Entry archive
+// @Description Count entries per UTC year or month of publication, newest first, for archive browsing. Entries without a publish date are left out. Takes the same filters as the entry list; pass a period back to /entries?period= to list its entries.
+// @Tags entries
+// @Produce json
+// @Param groupBy query string false "Period length (month, year; default month)"
+// @Param feedId query int false "Filter by feed ID"
+// @Param folderId query int false "Filter by folder ID"
+// @Param contentType query string false "Filter by content type (article, picture, notification, podcast)"
+// @Param unreadOnly query bool false "Only count unread entries"
+// @Param starredOnly query bool false "Only count starred entries"
+// @Param period query string false "Only count entries published in this UTC year (YYYY) or month (YYYY-MM)"
+// @Param excludeNsfw query bool false "Leave out entries flagged as not safe for work"
+// @Param tagId query int false "Filter by tag ID"
+// @Success 200 {object} archiveResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /entries/archive [get]
+func (h *EntryHandler) Archive(c echo.Context) error {
+	var v validator
+	params := parseEntryScope(c, &v)
+	groupBy := c.QueryParam("groupBy")
+	if groupBy == "" {
+		groupBy = service.ArchiveByMonth
+	}
+	v.oneOf("groupBy", groupBy, service.ArchiveByMonth, service.ArchiveByYear)
+	if v.failed() {
+		return v.write(c)
 	}
 
-	if c.QueryParam("starredOnly") == "true" {
-		params.StarredOnly = true
+	periods, err := h.service.Archive(c.Request().Context(), params, groupBy)
+	if err != nil {
+		return writeServiceError(c, err)
 	}
 
-	if c.QueryParam("hasThumbnail") == "true" {
-		params.HasThumbnail = true
+	response := archiveResponse{
+		GroupBy: groupBy,
+		Periods: make([]archivePeriodResponse, len(periods)),
+	}
+	for i, p := range periods {
+		response.Periods[i] = archivePeriodResponse{Period: p.Period, Count: p.Count, UnreadCount: p.UnreadCount}
 	}
 
-	if raw := c.QueryParam("limit"); raw != "" {
-		limit, err := strconv.Atoi(raw)
-		if err == nil && limit > 0 && limit <= 100 {
-			params.Limit = limit
-		}
+	return c.JSON(http.StatusOK, response)
+}
+
+// exportContentTypes and exportFileNames describe the download of an export
+// in each format.
+var (
+	exportContentTypes = map[string]string{
+		service.ExportJSON:     "application/x-ndjson; charset=utf-8",
+		service.ExportMarkdown: "text/markdown; charset=utf-8",
 	}
+	exportFileNames = map[string]string{
+		service.ExportJSON:     "gist-starred.jsonl",
+		service.ExportMarkdown: "gist-starred.md",
+	}
+)
+
+// Export downloads the starred entries.
+// @Summary Export starred entries
+// @Description Download all starred entries, newest first, with their readable content (or feed content) and cached AI summary, as JSON Lines (an entry per line) or a Markdown digest. The export streams; an error after it started cuts it short.
+// @Tags entries
+// @Produce plain
+// @Param starred query bool false "Only starred entries can be exported; must be true when given"
+// @Param format query string false "Export format (json, md; default json)"
+// @Success 200 {string} string "Exported entries"
+// @Failure 400 {object} errorResponse
+// @Router /entries/export [get]
+func (h *EntryHandler) Export(c echo.Context) error {
+	var v validator
+	if starred := c.QueryParam("starred"); starred != "" && starred != "true" {
+		v.fail("starred", fieldInvalidFormat, "only starred entries can be exported")
+	}
+	format := c.QueryParam("format")
+	if format == "" {
+		format = service.ExportJSON
+	}
+	v.oneOf("format", format, service.ExportFormats...)
+	if v.failed() {
+		return v.write(c)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, exportContentTypes[format])
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, exportFileNames[format]))
+	res.WriteHeader(http.StatusOK)
+	if _, err := h.exportService.ExportStarred(c.Request().Context(), res, format); err != nil {
+		// The status is sent; the download ends short
+		c.Logger().Errorf("export starred entries: %v", err)
+	}
+	return nil
+}
 
-	if raw := c.QueryParam("offset"); raw != "" {
-		offset, err := strconv.Atoi(raw)
-		if err == nil && offset >= 0 {
-			params.Offset = offset
+// Search finds entries by their text.
+// @Summary Search entries
+// @Description Full-text search over the title, text, author and URL of entries, best match first. All words must match; "quoted words" match as a phrase, word* matches a prefix, -word leaves out entries containing it, and title:word or author:word searches one field. feed:ID, folder:ID, is:unread and is:starred in q narrow the search like the query parameters. Results are summaries, as in the entry list, and compact as there when asked.
+// @Tags entries
+// @Produce json
+// @Param q query string true "Search text"
+// @Param sort query string false "Result order (relevance, newest; default relevance)"
+// @Param feedId query int false "Filter by feed ID"
+// @Param folderId query int false "Filter by folder ID"
+// @Param contentType query string false "Filter by content type (article, picture, notification, podcast)"
+// @Param unreadOnly query bool false "Only return unread entries"
+// @Param starredOnly query bool false "Only return starred entries"
+// @Param period query string false "Only return entries published in this UTC year (YYYY) or month (YYYY-MM)"
+// @Param excludeNsfw query bool false "Leave out entries flagged as not safe for work"
+// @Param tagId query int false "Filter by tag ID"
+// @Param limit query int false "Limit the number of entries (1-100, default 50)"
+// @Param offset query int false "Offset for pagination (>= 0)"
+// @Param compact query bool false "Compact response, also asked for with the header Prefer: return=minimal"
+// @Success 200 {object} entryListResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /entries/search [get]
+func (h *EntryHandler) Search(c echo.Context) error {
+	var v validator
+	params := service.EntrySearchParams{
+		EntryListParams: parseEntryScope(c, &v),
+		Query:           c.QueryParam("q"),
+		SortBy:          c.QueryParam("sort"),
+	}
+	if v.required("q", params.Query) {
+		if _, err := service.ParseSearchQuery(params.Query); err != nil {
+			v.fail("q", fieldInvalidFormat, "needs a word to search for; filters are feed:ID, folder:ID, is:unread and is:starred")
 		}
 	}
+	if params.SortBy == "" {
+		params.SortBy = service.SearchByRelevance
+	}
+	v.oneOf("sort", params.SortBy, service.SearchByRelevance, service.SearchByNewest)
+	params.Limit = v.queryInt(c, "limit", defaultEntryLimit)
+	params.Offset = v.queryInt(c, "offset", 0)
+	v.intRange("limit", params.Limit, 1, maxEntryLimit)
+	v.minInt("offset", params.Offset, 0)
+	if v.failed() {
+		return v.write(c)
+	}
 
 	// Request one extra to determine if there are more results
 	queryParams := params
 	queryParams.Limit = params.Limit + 1
 
-	entries, err := h.service.List(c.Request().Context(), queryParams)
+	entries, err := h.service.Search(c.Request().Context(), queryParams)
 	if err != nil {
 		return writeServiceError(c, err)
 	}
 
-	// Determine hasMore by checking if we got more than requested
 	hasMore := len(entries) > params.Limit
 	if hasMore {
-		entries = entries[:params.Limit] // Trim to requested limit
+		entries = entries[:params.Limit]
+	}
+
+	return writeEntryList(c, entries, hasMore, "")
+}
+
+// writeEntryList writes a page of entry summaries, in compact form when the
+// client asks for it.
+func writeEntryList(c echo.Context, entries []model.EntrySummary, hasMore bool, nextCursor string) error {
+	if compactRequested(c) {
+		response := compactEntryListResponse{
+			Entries:    make([]compactEntrySummaryResponse, len(entries)),
+			HasMore:    hasMore,
+			NextCursor: nextCursor,
+		}
+		for i, e := range entries {
+			response.Entries[i] = toCompactEntrySummaryResponse(e)
+		}
+		return c.JSON(http.StatusOK, response)
 	}
 
 	response := entryListResponse{
-		Entries: make([]entryResponse, len(entries)),
-		HasMore: hasMore,
+		Entries:    make([]entrySummaryResponse, len(entries)),
+		HasMore:    hasMore,
+		NextCursor: nextCursor,
 	}
 	for i, e := range entries {
-		response.Entries[i] = toEntryResponse(e)
+		response.Entries[i] = toEntrySummaryResponse(e)
 	}
-
 	return c.JSON(http.StatusOK, response)
 }
 
+// compactRequested reports whether the client asks for compact responses,
+// with ?compact=true or a "Prefer: return=minimal" header (RFC 7240), for
+// e-ink devices and terminal clients on slow links.
+func compactRequested(c echo.Context) bool {
+	c.Response().Header().Add(echo.HeaderVary, "Prefer")
+	if c.QueryParam("compact") == "true" {
+		return true
+	}
+	for _, header := range c.Request().Header.Values("Prefer") {
+		for _, pref := range strings.Split(header, ",") {
+			if strings.EqualFold(strings.TrimSpace(pref), "return=minimal") {
+				c.Response().Header().Set("Preference-Applied", "return=minimal")
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseEntryScope reads the query parameters that select entries, shared by
+// the list, the archive and search.
+func parseEntryScope(c echo.Context, v *validator) service.EntryListParams {
+	params := service.EntryListParams{
+		FeedID:       v.queryID(c, "feedId"),
+		FolderID:     v.queryID(c, "folderId"),
+		TagID:        v.queryID(c, "tagId"),
+		UnreadOnly:   c.QueryParam("unreadOnly") == "true",
+		StarredOnly:  c.QueryParam("starredOnly") == "true",
+		HasThumbnail: c.QueryParam("hasThumbnail") == "true",
+		ExcludeNSFW:  c.QueryParam("excludeNsfw") == "true",
+		Period:       c.QueryParam("period"),
+	}
+	if raw := c.QueryParam("contentType"); raw != "" {
+		v.oneOf("contentType", raw, service.ContentTypes...)
+		params.ContentType = &raw
+	}
+	if params.Period != "" {
+		if _, _, err := service.PeriodBounds(params.Period); err != nil {
+			v.fail("period", fieldInvalidFormat, "must be YYYY or YYYY-MM")
+		}
+	}
+	return params
+}
+
 // GetByID returns an entry by its ID.
 // @Summary Get entry
-// @Description Get a single entry by its ID
+// @Description Get a single entry by its ID. revision summarizes what the publisher changed in their latest update of the title or text, if they ever changed it. format selects how content and readableContent are returned: html (default) is the sanitized HTML, text is plain text with a line per paragraph, and clean is simplified HTML without media, embeds, classes or styles, with absolute links, for widgets, text-to-speech and e-ink readers. The text and clean versions of content are made at ingest. externalUrl is the page a linkblog entry is about (JSON Feed external_url) and attachments are the files attached to it (JSON Feed attachments, RSS and Atom enclosures). With compact=true or the header Prefer: return=minimal the entry carries only id, feedId, title, url, author, publishedAt, content, readableContent, and read and starred when true (compactEntryResponse). With proxyImages=true the images in content and readableContent load through /api/proxy/image, with relative sources resolved against the entry URL.
 // @Tags entries
 // @Produce json
 // @Param id path int true "Entry ID"
+// @Param format query string false "Content format: html, text or clean"
+// @Param proxyImages query bool false "Point images at the image proxy"
+// @Param compact query bool false "Compact response, also asked for with the header Prefer: return=minimal"
 // @Success 200 {object} entryResponse
 // @Failure 400 {object} errorResponse
 // @Failure 404 {object} errorResponse
@@ -188,17 +536,68 @@ func (h *EntryHandler) List(c echo.Context) error {
 func (h *EntryHandler) GetByID(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid id"})
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+
+	var v validator
+	format := contentFormat(c, &v)
+	if v.failed() {
+		return v.write(c)
 	}
 
 	entry, err := h.service.GetByID(c.Request().Context(), id)
 	if err != nil {
 		return writeServiceError(c, err)
 	}
+	service.FormatEntry(&entry, format)
+	if c.QueryParam("proxyImages") == "true" {
+		service.ProxyEntryImages(&entry)
+	}
 
+	if compactRequested(c) {
+		return c.JSON(http.StatusOK, toCompactEntryResponse(entry))
+	}
 	return c.JSON(http.StatusOK, toEntryResponse(entry))
 }
 
+// contentFormat reads the format an entry endpoint returns content in.
+func contentFormat(c echo.Context, v *validator) string {
+	format := c.QueryParam("format")
+	if format == "" {
+		return service.ContentFormatHTML
+	}
+	v.oneOf("format", format, service.ContentFormats...)
+	return format
+}
+
+// printPolicy lets a print view load its images and inline styles only, as
+// it is served from the app's origin.
+const printPolicy = "default-src 'none'; img-src http: https: data:; style-src 'unsafe-inline'"
+
+// Print renders an entry as a standalone HTML page.
+// @Summary Print view of an entry
+// @Description Render an entry as a self-contained HTML document for printing or saving: its readable content when extracted and the feed content otherwise, with the title, byline and a link to the source. Scripts and embedded media are left out; image and link URLs are made absolute.
+// @Tags entries
+// @Produce html
+// @Param id path int true "Entry ID"
+// @Success 200 {string} string "HTML document"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /entries/{id}/print [get]
+func (h *EntryHandler) Print(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+
+	page, err := h.service.Print(c.Request().Context(), id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	c.Response().Header().Set("Content-Security-Policy", printPolicy)
+	return c.HTMLBlob(http.StatusOK, page)
+}
+
 // UpdateReadStatus updates the read status of an entry.
 // @Summary Update read status
 // @Description Mark an entry as read or unread
@@ -214,12 +613,12 @@ func (h *EntryHandler) GetByID(c echo.Context) error {
 func (h *EntryHandler) UpdateReadStatus(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid id"})
+		return Error(c, CodeInvalidID, "invalid id")
 	}
 
 	var req updateReadRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 
 	if err := h.service.MarkAsRead(c.Request().Context(), id, req.Read); err != nil {
@@ -231,10 +630,14 @@ func (h *EntryHandler) UpdateReadStatus(c echo.Context) error {
 
 // FetchReadable fetches the readable content from the original URL.
 // @Summary Fetch readable content
-// @Description Extract readable content from the entry's original URL using readability
+// @Description Extract readable content from the entry's original URL using readability. format returns it as html (default), text or clean HTML, like GET /entries/{id}.
+// @Description Content extracted before is returned as it is; force extracts it again, from the page fetched within the last minutes when there is one.
 // @Tags entries
 // @Produce json
 // @Param id path int true "Entry ID"
+// @Param format query string false "Content format: html, text or clean"
+// @Param force query bool false "Extract again even when readable content is stored"
+// @Param proxyImages query bool false "Point images at the image proxy, as for GET /entries/{id}"
 // @Success 200 {object} readableContentResponse
 // @Failure 400 {object} errorResponse
 // @Failure 404 {object} errorResponse
@@ -242,22 +645,32 @@ func (h *EntryHandler) UpdateReadStatus(c echo.Context) error {
 func (h *EntryHandler) FetchReadable(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid id"})
+		return Error(c, CodeInvalidID, "invalid id")
 	}
 
-	content, err := h.readabilityService.FetchReadableContent(c.Request().Context(), id)
+	var v validator
+	format := contentFormat(c, &v)
+	if v.failed() {
+		return v.write(c)
+	}
+
+	content, meta, err := h.readabilityService.FetchReadableContent(c.Request().Context(), id, c.QueryParam("force") == "true")
 	if err != nil {
 		if errors.Is(err, service.ErrNotFound) {
-			return c.JSON(http.StatusNotFound, errorResponse{Error: "entry not found"})
+			return Error(c, CodeNotFound, "entry not found")
 		}
 		if errors.Is(err, service.ErrInvalid) {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "no URL or empty content"})
+			return Error(c, CodeInvalidRequest, "no URL or empty content")
 		}
 		// Return the actual error message
-		return c.JSON(http.StatusBadGateway, errorResponse{Error: err.Error()})
+		return Error(c, CodeContentFetchFailed, err.Error())
 	}
 
-	return c.JSON(http.StatusOK, readableContentResponse{ReadableContent: content})
+	content = service.FormatContent(content, "", format)
+	if c.QueryParam("proxyImages") == "true" {
+		content = service.ProxyContentImages(content, "")
+	}
+	return c.JSON(http.StatusOK, readableContentResponse{ReadableContent: content, readableMetaResponse: toReadableMetaResponse(meta)})
 }
 
 // MarkAllAsRead marks all entries as read for a feed or folder.
@@ -273,62 +686,105 @@ func (h *EntryHandler) FetchReadable(c echo.Context) error {
 func (h *EntryHandler) MarkAllAsRead(c echo.Context) error {
 	var req markAllReadRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
-	}
-
-	var feedID, folderID *int64
-	if req.FeedID != nil {
-		id, err := strconv.ParseInt(*req.FeedID, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid feed ID"})
-		}
-		feedID = &id
-	}
-	if req.FolderID != nil {
-		id, err := strconv.ParseInt(*req.FolderID, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid folder ID"})
-		}
-		folderID = &id
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 
-	// Validate contentType if provided
-	var contentType *string
+	var v validator
+	feedID := v.optionalID("feedId", req.FeedID)
+	folderID := v.optionalID("folderId", req.FolderID)
 	if req.ContentType != nil {
-		ct := *req.ContentType
-		if ct != "article" && ct != "picture" && ct != "notification" {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid contentType"})
+		if v.required("contentType", *req.ContentType) {
+			v.oneOf("contentType", *req.ContentType, service.ContentTypes...)
 		}
-		contentType = &ct
+	}
+	if v.failed() {
+		return v.write(c)
 	}
 
-	if err := h.service.MarkAllAsRead(c.Request().Context(), feedID, folderID, contentType); err != nil {
+	if err := h.service.MarkAllAsRead(c.Request().Context(), feedID, folderID, req.ContentType); err != nil {
 		return writeServiceError(c, err)
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
-// GetUnreadCounts returns unread counts for all feeds.
+// ApplyBatch applies one operation to many entries at once.
+// @Summary Batch entry operation
+// @Description Mark up to 1000 entries read or unread, star or unstar them, or delete them, in a single statement, e.g. to replay changes made offline. Unknown IDs are skipped. Deleted entries still in their feed come back on its next refresh.
+// @Tags entries
+// @Accept json
+// @Produce json
+// @Param request body entryBatchRequest true "Entry IDs and operation"
+// @Success 200 {object} entryBatchResponse
+// @Failure 400 {object} errorResponse
+// @Router /entries/batch [post]
+func (h *EntryHandler) ApplyBatch(c echo.Context) error {
+	var req entryBatchRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+
+	var v validator
+	ids := v.ids("ids", req.IDs)
+	if len(req.IDs) > service.MaxEntryBatch {
+		v.fail("ids", fieldOutOfRange, fmt.Sprintf("must contain at most %d IDs", service.MaxEntryBatch))
+	}
+	if v.required("operation", req.Operation) {
+		v.oneOf("operation", req.Operation, service.EntryOps...)
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	updated, err := h.service.ApplyBatch(c.Request().Context(), ids, req.Operation)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, entryBatchResponse{Updated: updated})
+}
+
+// GetUnreadCounts returns unread counts per feed and folder.
 // @Summary Get unread counts
-// @Description Get a map of feed IDs to their respective unread entry counts
+// @Description Get maps of feed IDs (counts) and folder IDs (folders) to their unread entry counts, the total of unread entries (total) and of starred entries (starredTotal), so sidebar badges render from one call. With contentType only feeds of that type are counted; with folderId only entries in the folder and its subfolders are, counting an entry a filter moved under its folder rather than its feed's. As in /starred-counts, a folder's count leaves out its subfolders and counts the entries filters moved to it. Feeds and folders without unread entries in scope are left out; starredTotal ignores the scope.
 // @Tags entries
 // @Produce json
+// @Param contentType query string false "Count only feeds of this content type (article, picture, notification, podcast)"
+// @Param folderId query int false "Count only entries in this folder subtree"
 // @Success 200 {object} unreadCountsResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
 // @Router /unread-counts [get]
 func (h *EntryHandler) GetUnreadCounts(c echo.Context) error {
-	counts, err := h.service.GetUnreadCounts(c.Request().Context())
+	var v validator
+	folderID := v.queryID(c, "folderId")
+	var contentType *string
+	if raw := c.QueryParam("contentType"); raw != "" {
+		v.oneOf("contentType", raw, service.ContentTypes...)
+		contentType = &raw
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	counts, err := h.service.GetUnreadCounts(c.Request().Context(), contentType, folderID)
 	if err != nil {
 		return writeServiceError(c, err)
 	}
 
-	// Convert int64 keys to string keys for JSON
-	stringCounts := make(map[string]int)
-	for feedID, count := range counts {
-		stringCounts[strconv.FormatInt(feedID, 10)] = count
+	response := unreadCountsResponse{
+		Counts:       make(map[string]int, len(counts.Feeds)),
+		Folders:      make(map[string]int, len(counts.Folders)),
+		Total:        counts.Total,
+		StarredTotal: counts.StarredTotal,
+	}
+	for feedID, count := range counts.Feeds {
+		response.Counts[idToString(feedID)] = count
+	}
+	for folderID, count := range counts.Folders {
+		response.Folders[idToString(folderID)] = count
 	}
 
-	return c.JSON(http.StatusOK, unreadCountsResponse{Counts: stringCounts})
+	return c.JSON(http.StatusOK, response)
 }
 
 // UpdateStarredStatus updates the starred status of an entry.
@@ -346,12 +802,12 @@ func (h *EntryHandler) GetUnreadCounts(c echo.Context) error {
 func (h *EntryHandler) UpdateStarredStatus(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid id"})
+		return Error(c, CodeInvalidID, "invalid id")
 	}
 
 	var req updateStarredRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 
 	if err := h.service.MarkAsStarred(c.Request().Context(), id, req.Starred); err != nil {
@@ -377,6 +833,33 @@ func (h *EntryHandler) GetStarredCount(c echo.Context) error {
 	return c.JSON(http.StatusOK, starredCountResponse{Count: count})
 }
 
+// GetStarredCounts returns starred counts per feed and folder.
+// @Summary Get starred counts
+// @Description Get maps of feed IDs (counts) and folder IDs (folders) to their starred entry counts, in the shape of the unread counts. An entry a filter moved counts under its own folder rather than its feed's; a folder's count leaves out its subfolders. Feeds and folders without starred entries are left out.
+// @Tags entries
+// @Produce json
+// @Success 200 {object} starredCountsResponse
+// @Router /starred-counts [get]
+func (h *EntryHandler) GetStarredCounts(c echo.Context) error {
+	counts, err := h.service.GetStarredCounts(c.Request().Context())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+
+	response := starredCountsResponse{
+		Counts:  make(map[string]int, len(counts.Feeds)),
+		Folders: make(map[string]int, len(counts.Folders)),
+	}
+	for feedID, count := range counts.Feeds {
+		response.Counts[idToString(feedID)] = count
+	}
+	for folderID, count := range counts.Folders {
+		response.Folders[idToString(folderID)] = count
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
 func toEntryResponse(e model.Entry) entryResponse {
 	resp := entryResponse{
 		ID:              idToString(e.ID),
@@ -391,12 +874,127 @@ func toEntryResponse(e model.Entry) entryResponse {
 		Starred:         e.Starred,
 		CreatedAt:       e.CreatedAt.UTC().Format(time.RFC3339),
 		UpdatedAt:       e.UpdatedAt.UTC().Format(time.RFC3339),
+		LinkDead:        e.LinkDead,
+		Images:          e.Images,
+		ExternalURL:     e.ExternalURL,
+		NSFWReason:      e.NSFWReason,
+		IconPath:        e.IconPath,
+	}
+	if e.ReadableContent != nil {
+		resp.readableMetaResponse = toReadableMetaResponse(e.ReadableMeta)
+	}
+
+	if e.PublishedAt != nil {
+		formatted := e.PublishedAt.UTC().Format(time.RFC3339)
+		resp.PublishedAt = &formatted
+	}
+	if e.OriginalPublishedAt != nil {
+		formatted := e.OriginalPublishedAt.UTC().Format(time.RFC3339)
+		resp.OriginalPublishedAt = &formatted
+	}
+
+	for _, a := range e.Attachments {
+		resp.Attachments = append(resp.Attachments, entryAttachmentResponse{
+			URL:             a.URL,
+			MimeType:        a.MimeType,
+			Title:           a.Title,
+			SizeBytes:       a.SizeBytes,
+			DurationSeconds: a.DurationSeconds,
+		})
+	}
+
+	if e.Revision != nil {
+		changes := make([]textChangeResponse, 0, len(e.Revision.Changes))
+		for _, change := range e.Revision.Changes {
+			changes = append(changes, textChangeResponse{Removed: change.Removed, Added: change.Added})
+		}
+		resp.Revision = &entryRevisionResponse{
+			TitleBefore:  e.Revision.TitleBefore,
+			WordsAdded:   e.Revision.WordsAdded,
+			WordsRemoved: e.Revision.WordsRemoved,
+			Changes:      changes,
+			ChangedAt:    e.Revision.ChangedAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	return resp
+}
+
+func toReadableMetaResponse(m model.ReadableMeta) readableMetaResponse {
+	return readableMetaResponse{
+		ReadableLang:     m.Language,
+		ReadableDir:      m.Direction,
+		ReadableByline:   m.Byline,
+		ReadableSiteName: m.SiteName,
+	}
+}
+
+func toCompactEntrySummaryResponse(e model.EntrySummary) compactEntrySummaryResponse {
+	resp := compactEntrySummaryResponse{
+		ID:          idToString(e.ID),
+		FeedID:      idToString(e.FeedID),
+		Title:       e.Title,
+		URL:         e.URL,
+		PublishedAt: formatOptionalTime(e.PublishedAt),
+		Read:        e.Read,
+		Starred:     e.Starred,
+	}
+	if e.Snippet != nil && *e.Snippet != "" {
+		snippet := service.ShortSnippet(*e.Snippet)
+		resp.Snippet = &snippet
+	}
+	return resp
+}
+
+func toCompactEntryResponse(e model.Entry) compactEntryResponse {
+	return compactEntryResponse{
+		ID:              idToString(e.ID),
+		FeedID:          idToString(e.FeedID),
+		Title:           e.Title,
+		URL:             e.URL,
+		Author:          e.Author,
+		PublishedAt:     formatOptionalTime(e.PublishedAt),
+		Read:            e.Read,
+		Starred:         e.Starred,
+		Content:         e.Content,
+		ReadableContent: e.ReadableContent,
+	}
+}
+
+// formatOptionalTime formats t as RFC 3339 in UTC, or returns nil without it.
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.UTC().Format(time.RFC3339)
+	return &formatted
+}
+
+func toEntrySummaryResponse(e model.EntrySummary) entrySummaryResponse {
+	resp := entrySummaryResponse{
+		ID:           idToString(e.ID),
+		FeedID:       idToString(e.FeedID),
+		Title:        e.Title,
+		URL:          e.URL,
+		Snippet:      e.Snippet,
+		ThumbnailURL: e.ThumbnailURL,
+		Author:       e.Author,
+		Read:         e.Read,
+		Starred:      e.Starred,
+		CreatedAt:    e.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:    e.UpdatedAt.UTC().Format(time.RFC3339),
+		NSFWReason:   e.NSFWReason,
+		IconPath:     e.IconPath,
 	}
 
 	if e.PublishedAt != nil {
 		formatted := e.PublishedAt.UTC().Format(time.RFC3339)
 		resp.PublishedAt = &formatted
 	}
+	if e.ThumbnailURL != nil && *e.ThumbnailURL != "" {
+		proxied := "/api/thumbnails/" + resp.ID
+		resp.ThumbnailProxyURL = &proxied
+	}
 
 	return resp
 }
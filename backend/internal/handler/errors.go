@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorCode is a stable, machine-readable error identifier. Clients branch on
+// and localize by the code; the message is for humans and may change.
+type ErrorCode string
+
+const (
+	CodeInvalidRequest           ErrorCode = "invalid_request"
+	CodeValidationFailed         ErrorCode = "validation_failed"
+	CodeInvalidID                ErrorCode = "invalid_id"
+	CodeMissingField             ErrorCode = "missing_field"
+	CodeInvalidURL               ErrorCode = "invalid_url"
+	CodeBatchTooLarge            ErrorCode = "batch_too_large"
+	CodeMissingFile              ErrorCode = "missing_file"
+	CodeFileTooLarge             ErrorCode = "file_too_large"
+	CodePreferencesTooLarge      ErrorCode = "preferences_too_large"
+	CodeUnsupportedMediaType     ErrorCode = "unsupported_media_type"
+	CodeNotFound                 ErrorCode = "not_found"
+	CodeMethodNotAllowed         ErrorCode = "method_not_allowed"
+	CodeConflict                 ErrorCode = "conflict"
+	CodeFeedExists               ErrorCode = "feed_exists"
+	CodeRefreshInProgress        ErrorCode = "refresh_in_progress"
+	CodeImportInProgress         ErrorCode = "import_in_progress"
+	CodeSyncInProgress           ErrorCode = "sync_in_progress"
+	CodeSyncNotConfigured        ErrorCode = "sync_not_configured"
+	CodeLinkCheckInProgress      ErrorCode = "link_check_in_progress"
+	CodeIconBackfillInProgress   ErrorCode = "icon_backfill_in_progress"
+	CodeFetchReadableInProgress  ErrorCode = "fetch_readable_in_progress"
+	CodeIdempotencyKeyInUse      ErrorCode = "idempotency_key_in_use"
+	CodeUnauthorized             ErrorCode = "unauthorized"
+	CodeDemoMode                 ErrorCode = "demo_mode"
+	CodeReadOnly                 ErrorCode = "read_only"
+	CodeFeedFetchFailed          ErrorCode = "feed_fetch_failed"
+	CodeHostCoolingDown          ErrorCode = "host_cooling_down"
+	CodeContentFetchFailed       ErrorCode = "content_fetch_failed"
+	CodeUpstreamTimeout          ErrorCode = "upstream_timeout"
+	CodeRequestTimeout           ErrorCode = "request_timeout"
+	CodeImageFetchFailed         ErrorCode = "image_fetch_failed"
+	CodeAIRequestFailed          ErrorCode = "ai_request_failed"
+	CodeIntegrationNotConfigured ErrorCode = "integration_not_configured"
+	CodeIntegrationFailed        ErrorCode = "integration_failed"
+	CodeInternal                 ErrorCode = "internal_error"
+)
+
+// errorCodeStatus is the error-code registry: every code a handler may return
+// and the HTTP status it is sent with.
+var errorCodeStatus = map[ErrorCode]int{
+	CodeInvalidRequest:           http.StatusBadRequest,
+	CodeValidationFailed:         http.StatusBadRequest,
+	CodeInvalidID:                http.StatusBadRequest,
+	CodeMissingField:             http.StatusBadRequest,
+	CodeInvalidURL:               http.StatusBadRequest,
+	CodeBatchTooLarge:            http.StatusBadRequest,
+	CodeMissingFile:              http.StatusBadRequest,
+	CodeFileTooLarge:             http.StatusRequestEntityTooLarge,
+	CodePreferencesTooLarge:      http.StatusRequestEntityTooLarge,
+	CodeUnsupportedMediaType:     http.StatusUnsupportedMediaType,
+	CodeNotFound:                 http.StatusNotFound,
+	CodeMethodNotAllowed:         http.StatusMethodNotAllowed,
+	CodeConflict:                 http.StatusConflict,
+	CodeFeedExists:               http.StatusConflict,
+	CodeRefreshInProgress:        http.StatusConflict,
+	CodeImportInProgress:         http.StatusConflict,
+	CodeSyncInProgress:           http.StatusConflict,
+	CodeSyncNotConfigured:        http.StatusConflict,
+	CodeLinkCheckInProgress:      http.StatusConflict,
+	CodeIconBackfillInProgress:   http.StatusConflict,
+	CodeFetchReadableInProgress:  http.StatusConflict,
+	CodeIdempotencyKeyInUse:      http.StatusConflict,
+	CodeUnauthorized:             http.StatusUnauthorized,
+	CodeDemoMode:                 http.StatusForbidden,
+	CodeReadOnly:                 http.StatusForbidden,
+	CodeFeedFetchFailed:          http.StatusBadGateway,
+	CodeHostCoolingDown:          http.StatusServiceUnavailable,
+	CodeContentFetchFailed:       http.StatusBadGateway,
+	CodeUpstreamTimeout:          http.StatusGatewayTimeout,
+	CodeRequestTimeout:           http.StatusServiceUnavailable,
+	CodeImageFetchFailed:         http.StatusInternalServerError,
+	CodeAIRequestFailed:          http.StatusInternalServerError,
+	CodeIntegrationNotConfigured: http.StatusConflict,
+	CodeIntegrationFailed:        http.StatusBadGateway,
+	CodeInternal:                 http.StatusInternalServerError,
+}
+
+// errorResponse is the envelope for every error returned by the API.
+type errorResponse struct {
+	Code        ErrorCode    `json:"code" example:"not_found"`
+	Message     string       `json:"message" example:"resource not found"`
+	Details     interface{}  `json:"details,omitempty"`
+	FieldErrors []FieldError `json:"fieldErrors,omitempty"`
+}
+
+// FieldError describes why a single request field was rejected.
+type FieldError struct {
+	Field   string `json:"field" example:"url"`
+	Code    string `json:"code" example:"invalid_url"`
+	Message string `json:"message" example:"must be an http or https URL"`
+}
+
+// Error writes an error envelope with the status registered for code.
+func Error(c echo.Context, code ErrorCode, message string) error {
+	return c.JSON(statusForCode(code), errorResponse{Code: code, Message: message})
+}
+
+// errorWithDetails writes an error envelope carrying extra structured data.
+func errorWithDetails(c echo.Context, code ErrorCode, message string, details interface{}) error {
+	return c.JSON(statusForCode(code), errorResponse{Code: code, Message: message, Details: details})
+}
+
+// HTTPErrorHandler renders errors raised by echo itself (unknown routes,
+// wrong methods, errors returned from handlers) in the same envelope.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status := http.StatusInternalServerError
+	message := "internal error"
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		status = httpErr.Code
+		if msg, ok := httpErr.Message.(string); ok {
+			message = msg
+		}
+	}
+	code := genericCodeForStatus(status)
+	if code == CodeInternal {
+		c.Logger().Error(err)
+	}
+
+	if c.Request().Method == http.MethodHead {
+		_ = c.NoContent(status)
+		return
+	}
+	_ = c.JSON(status, errorResponse{Code: code, Message: message})
+}
+
+// genericCodeForStatus is the catch-all code for errors that only carry a status.
+func genericCodeForStatus(status int) ErrorCode {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeFileTooLarge
+	case http.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	default:
+		return CodeInternal
+	}
+}
+
+func statusForCode(code ErrorCode) int {
+	if status, ok := errorCodeStatus[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
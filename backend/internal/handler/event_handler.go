@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/events"
+)
+
+// eventKeepAlive is how often an idle event stream gets a comment line, so
+// proxies do not close it.
+const eventKeepAlive = 30 * time.Second
+
+type EventHandler struct {
+	hub *events.Hub
+}
+
+func NewEventHandler(hub *events.Hub) *EventHandler {
+	return &EventHandler{hub: hub}
+}
+
+func (h *EventHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/events", h.Stream)
+}
+
+// Stream sends background work notifications as they happen.
+// @Summary Stream events
+// @Description Server-sent events about background work, each named by its type: feed_refreshed (feedId, title, newEntries) after a feed was fetched, unread_delta (feedId, delta) when a feed's unread count changed, and task (a task snapshot) when a task such as a refresh, OPML import or icon backfill starts, makes progress or ends, and notification (id, kind, title, body) when a notification was added to the inbox.
+// @Description A client that falls too far behind is disconnected; it should reconnect and reload what it shows.
+// @Tags events
+// @Produce text/event-stream
+// @Success 200 {string} string "Event stream"
+// @Router /events [get]
+func (h *EventHandler) Stream(c echo.Context) error {
+	ch, unsubscribe := h.hub.Subscribe()
+	defer unsubscribe()
+
+	res := c.Response()
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.WriteHeader(http.StatusOK)
+	fmt.Fprint(res, ": connected\n\n")
+	res.Flush()
+
+	ctx := c.Request().Context()
+	ticker := time.NewTicker(eventKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			fmt.Fprint(res, ": keep-alive\n\n")
+			res.Flush()
+		case ev, ok := <-ch:
+			if !ok {
+				return nil // fell behind
+			}
+			data, err := json.Marshal(ev.Data)
+			if err != nil {
+				c.Logger().Errorf("encode %s event: %v", ev.Type, err)
+				continue
+			}
+			fmt.Fprintf(res, "event: %s\ndata: %s\n\n", ev.Type, data)
+			res.Flush()
+		}
+	}
+}
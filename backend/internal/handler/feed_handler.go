@@ -3,7 +3,6 @@ package handler
 import (
 	"errors"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
@@ -16,27 +15,152 @@ import (
 type FeedHandler struct {
 	service        service.FeedService
 	refreshService service.RefreshService
+	readability    service.ReadabilityService
+	tasks          service.TaskRunner
+	health         service.FeedHealthService
 }
 
 type createFeedRequest struct {
-	URL      string  `json:"url"`
+	URL              string                `json:"url"`
+	FolderID         *string               `json:"folderId"`
+	Title            string                `json:"title"`
+	Type             string                `json:"type"`
+	RequestOverrides *feedRequestOverrides `json:"requestOverrides"`
+	Auth             *feedAuthRequest      `json:"auth"`
+}
+
+// feedAuthRequest holds the credentials a feed is fetched with: HTTP Basic
+// auth and extra headers such as an API token.
+type feedAuthRequest struct {
+	Username string            `json:"username"`
+	Password string            `json:"password"`
+	Headers  map[string]string `json:"headers"`
+}
+
+// feedRequestOverrides replaces what a feed and its pages and icon are
+// requested with, for sites that only answer certain clients.
+type feedRequestOverrides struct {
+	// UserAgent replaces the User-Agent, and the fallback one is not tried
+	UserAgent string `json:"userAgent,omitempty" example:"Mozilla/5.0 (compatible; Feedly/1.0)"`
+	// Headers are added to every request, after the defaults
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// feedScraper holds the CSS selectors that turn a web page into entries.
+type feedScraper struct {
+	// Items selects each entry on the page
+	Items string `json:"items" example:"article.post"`
+	// Title selects the title within an item; the item's text when empty
+	Title string `json:"title,omitempty" example:"h2"`
+	// Link selects the link within an item; its first link when empty
+	Link string `json:"link,omitempty" example:"h2 a"`
+	// Date selects the date within an item; entries are dated when first seen when empty
+	Date string `json:"date,omitempty" example:"time"`
+}
+
+type createScrapedFeedRequest struct {
+	// URL is the page to scrape.
+	URL              string                `json:"url"`
+	FolderID         *string               `json:"folderId"`
+	Title            string                `json:"title"`
+	Type             string                `json:"type"`
+	Scraper          feedScraper           `json:"scraper"`
+	DateQuirks       *feedDateQuirks       `json:"dateQuirks"`
+	RequestOverrides *feedRequestOverrides `json:"requestOverrides"`
+	Auth             *feedAuthRequest      `json:"auth"`
+}
+
+type previewScrapedRequest struct {
+	URL              string                `json:"url"`
+	Scraper          feedScraper           `json:"scraper"`
+	DateQuirks       *feedDateQuirks       `json:"dateQuirks"`
+	RequestOverrides *feedRequestOverrides `json:"requestOverrides"`
+	Auth             *feedAuthRequest      `json:"auth"`
+}
+
+type scrapedItemResponse struct {
+	Title       string  `json:"title"`
+	URL         string  `json:"url"`
+	PublishedAt *string `json:"publishedAt,omitempty"`
+}
+
+type scrapePreviewResponse struct {
+	URL   string                `json:"url"`
+	Title string                `json:"title"`
+	Items []scrapedItemResponse `json:"items"`
+}
+
+// feedDateQuirks corrects how the dates of a feed's entries are read.
+type feedDateQuirks struct {
+	// Timezone is the IANA zone of dates written without an offset
+	Timezone string `json:"timezone,omitempty" example:"Asia/Shanghai"`
+	// DayFirst reads numeric dates such as 03/04/2025 as day, month, year
+	DayFirst bool `json:"dayFirst,omitempty"`
+	// IgnoreFuture replaces future dates by the updated date or the time the entry was first seen
+	IgnoreFuture bool `json:"ignoreFuture,omitempty"`
+}
+
+type bulkAddFeedsRequest struct {
+	// URLs is a newline-separated list of feed URLs.
+	URLs     string  `json:"urls"`
 	FolderID *string `json:"folderId"`
-	Title    string  `json:"title"`
 	Type     string  `json:"type"`
 }
 
+type bulkAddResultResponse struct {
+	URL    string        `json:"url"`
+	Status string        `json:"status"`
+	Feed   *feedResponse `json:"feed,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+type bulkAddFeedsResponse struct {
+	Results []bulkAddResultResponse `json:"results"`
+}
+
+type previewFeedRequest struct {
+	URL              string                `json:"url"`
+	RequestOverrides *feedRequestOverrides `json:"requestOverrides"`
+	Auth             *feedAuthRequest      `json:"auth"`
+}
+
 type updateTypeRequest struct {
 	Type string `json:"type"`
 }
 
-type feedConflictResponse struct {
-	Error        string       `json:"error" example:"feed_exists"`
+// feedConflictDetails is sent as the details of a feed_exists error.
+type feedConflictDetails struct {
 	ExistingFeed feedResponse `json:"existingFeed"`
 }
 
 type updateFeedRequest struct {
 	Title    string  `json:"title"`
 	FolderID *string `json:"folderId"`
+	// RefreshInterval is the minutes between refreshes, 0 for the scheduler's
+	// choice; kept as it is when omitted.
+	RefreshInterval *int `json:"refreshInterval"`
+	// FullTextURL is the feed's full-text service, "" for the built-in
+	// readability; kept as it is when omitted.
+	FullTextURL *string `json:"fullTextUrl"`
+	// SummaryInSourceLanguage has AI summaries written in the article's
+	// language instead of the global one; kept as it is when omitted.
+	SummaryInSourceLanguage *bool `json:"summaryInSourceLanguage"`
+	// PrefetchReadability has refresh fetch the readable content of new
+	// entries; kept as it is when omitted.
+	PrefetchReadability *bool `json:"prefetchReadability"`
+	// DateQuirks replaces how the dates of entries are read, and an empty
+	// object removes the corrections; kept as they are when omitted.
+	DateQuirks *feedDateQuirks `json:"dateQuirks"`
+	// Scraper replaces the selectors the feed's page is scraped with, and
+	// an empty object makes it an ordinary feed; kept as they are when omitted.
+	Scraper *feedScraper `json:"scraper"`
+	// RequestOverrides replaces the User-Agent and headers the feed is
+	// requested with, and an empty object sends the defaults; kept as they
+	// are when omitted.
+	RequestOverrides *feedRequestOverrides `json:"requestOverrides"`
+	// Auth replaces the credentials the feed is fetched with, and an empty
+	// object removes them; kept as they are when omitted.
+	Auth *feedAuthRequest `json:"auth"`
 }
 
 type deleteFeedsRequest struct {
@@ -51,12 +175,47 @@ type feedResponse struct {
 	SiteURL      *string `json:"siteUrl,omitempty"`
 	Description  *string `json:"description,omitempty"`
 	IconPath     *string `json:"iconPath,omitempty"`
+	IconColor    *string `json:"iconColor,omitempty"` // #rrggbb, for placeholders while the icon loads
 	Type         string  `json:"type"`
 	ETag         *string `json:"etag,omitempty"`
 	LastModified *string `json:"lastModified,omitempty"`
 	ErrorMessage *string `json:"errorMessage,omitempty"`
-	CreatedAt    string  `json:"createdAt"`
-	UpdatedAt    string  `json:"updatedAt"`
+	// RefreshInterval is the feed's own minutes between refreshes, unset when
+	// the scheduler decides.
+	RefreshInterval *int    `json:"refreshInterval,omitempty"`
+	NextRefreshAt   *string `json:"nextRefreshAt,omitempty"` // unset until first refreshed
+	FullTextURL     *string `json:"fullTextUrl,omitempty"`   // unset when readability extracts entries
+	// SummaryInSourceLanguage is set when AI summaries of the feed's entries
+	// are written in the article's language.
+	SummaryInSourceLanguage bool `json:"summaryInSourceLanguage"`
+	// PrefetchReadability is set when refresh fetches the readable content
+	// of the feed's new entries.
+	PrefetchReadability bool `json:"prefetchReadability"`
+	// DateQuirks corrects how the dates of the feed's entries are read;
+	// unset when they are taken as the feed gives them.
+	DateQuirks *feedDateQuirks `json:"dateQuirks,omitempty"`
+	// Scraper is set when the feed is a web page scraped for entries.
+	Scraper *feedScraper `json:"scraper,omitempty"`
+	// RequestOverrides is set when the feed is requested with its own
+	// User-Agent or headers.
+	RequestOverrides *feedRequestOverrides `json:"requestOverrides,omitempty"`
+	// HasAuth is set when credentials are stored for the feed. They are
+	// never sent back.
+	HasAuth bool `json:"hasAuth"`
+	// ConsecutiveFailures counts the refreshes in a row that failed; each
+	// doubles the time until the next one, up to a day.
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// DisabledAt is set while the feed is disabled for failing too often;
+	// scheduled refreshes skip it until it is enabled again.
+	DisabledAt *string `json:"disabledAt,omitempty"`
+	CreatedAt  string  `json:"createdAt"`
+	UpdatedAt  string  `json:"updatedAt"`
+}
+
+type deleteFeedResponse struct {
+	EntriesDeleted int64 `json:"entriesDeleted"`
+	EntriesKept    int64 `json:"entriesKept"`
+	Archived       bool  `json:"archived"`
 }
 
 type feedPreviewResponse struct {
@@ -69,57 +228,159 @@ type feedPreviewResponse struct {
 	LastUpdated *string `json:"lastUpdated,omitempty"`
 }
 
-func NewFeedHandler(service service.FeedService, refreshService service.RefreshService) *FeedHandler {
-	return &FeedHandler{service: service, refreshService: refreshService}
+type feedCandidateResponse struct {
+	URL       string `json:"url"`
+	Title     string `json:"title"`
+	Source    string `json:"source"`
+	ItemCount *int   `json:"itemCount,omitempty"`
+}
+
+func NewFeedHandler(service service.FeedService, refreshService service.RefreshService, readability service.ReadabilityService, tasks service.TaskRunner, health service.FeedHealthService) *FeedHandler {
+	return &FeedHandler{service: service, refreshService: refreshService, readability: readability, tasks: tasks, health: health}
 }
 
 func (h *FeedHandler) RegisterRoutes(g *echo.Group) {
 	g.POST("/feeds", h.Create)
+	g.POST("/feeds/bulk-add", h.BulkAdd)
 	g.POST("/feeds/refresh", h.RefreshAll)
 	g.GET("/feeds/preview", h.Preview)
+	g.GET("/feeds/discover", h.Discover)
+	g.POST("/feeds/preview", h.PreviewWithAuth)
+	g.POST("/feeds/scraped", h.CreateScraped)
+	g.POST("/feeds/scraped/preview", h.PreviewScraped)
 	g.GET("/feeds", h.List)
+	g.GET("/feeds/health", h.HealthAll)
+	g.GET("/feeds/:id/health", h.Health)
 	g.PUT("/feeds/:id", h.Update)
 	g.PATCH("/feeds/:id/type", h.UpdateType)
+	g.POST("/feeds/:id/fetch-readable", h.FetchReadable)
+	g.POST("/feeds/:id/enable", h.Enable)
+	g.POST("/feeds/:id/refresh", h.Refresh)
 	g.DELETE("/feeds/:id", h.Delete)
 	g.DELETE("/feeds", h.DeleteBatch)
 }
 
+// HealthAll reports how the refreshes of every feed went lately.
+// @Summary List feed health
+// @Description Get the health of every feed from its last 50 refresh attempts: status (healthy, failing when the latest attempt failed, unknown before any attempt), consecutive failures, failed and total attempts, average fetch time, and the last attempt, success and error. Feeds failing the longest come first. cooldownUntil is set while the feed's host is left alone after answering 429 Too Many Requests: every feed on the host is skipped until then, for as long as its Retry-After asked (10 minutes without one, at most a day).
+// @Tags feeds
+// @Produce json
+// @Success 200 {array} service.FeedHealth
+// @Failure 500 {object} errorResponse
+// @Router /feeds/health [get]
+func (h *FeedHandler) HealthAll(c echo.Context) error {
+	health, err := h.health.List(c.Request().Context())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, health)
+}
+
+// Health reports how the refreshes of a feed went lately.
+// @Summary Get feed health
+// @Description Get the health of a feed as in /feeds/health, with its last 20 refresh attempts (time, outcome, HTTP status, duration, new entries, error), newest first.
+// @Tags feeds
+// @Produce json
+// @Param id path int true "Feed ID"
+// @Success 200 {object} service.FeedHealth
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /feeds/{id}/health [get]
+func (h *FeedHandler) Health(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	health, err := h.health.Feed(c.Request().Context(), id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, health)
+}
+
 // Create creates a new feed.
 // @Summary Create a feed
-// @Description Subscribe to a new RSS/Atom feed
+// @Description Subscribe to a new RSS, Atom or JSON Feed (1.0 and 1.1) feed. auth holds credentials for feeds behind HTTP Basic auth (username, password) or a token header (headers); they are stored encrypted and sent on every fetch of the feed.
+// @Description requestOverrides is for sites that only answer certain clients: userAgent replaces the User-Agent (and the fallback User-Agent is not tried) and headers are added, on every request for the feed, its sitemaps, the readable content of its entries and its icon. Unlike auth they are stored as they are and returned; credentials are applied after them.
 // @Tags feeds
 // @Accept json
 // @Produce json
 // @Param feed body createFeedRequest true "Feed creation request"
 // @Success 201 {object} feedResponse
 // @Failure 400 {object} errorResponse
-// @Failure 409 {object} feedConflictResponse "Feed URL already exists"
+// @Failure 409 {object} errorResponse{details=feedConflictDetails} "Feed URL already exists"
 // @Router /feeds [post]
 func (h *FeedHandler) Create(c echo.Context) error {
 	var req createFeedRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if v.required("url", req.URL) {
+		v.httpURL("url", req.URL)
+	}
+	folderID := v.optionalID("folderId", req.FolderID)
+	v.oneOf("type", req.Type, service.ContentTypes...)
+	overrides := v.requestOverrides("requestOverrides", req.RequestOverrides)
+	auth := v.feedAuth("auth", req.Auth)
+	if v.failed() {
+		return v.write(c)
+	}
+	feedType := req.Type
+	if feedType == "" {
+		feedType = "article"
 	}
-	var folderID *int64
-	if req.FolderID != nil {
-		id, err := strconv.ParseInt(*req.FolderID, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid folder ID"})
+	feed, err := h.service.Add(c.Request().Context(), req.URL, folderID, req.Title, feedType, overrides, auth)
+	if err != nil {
+		var conflictErr *service.FeedConflictError
+		if errors.As(err, &conflictErr) {
+			return errorWithDetails(c, CodeFeedExists, "feed already exists", feedConflictDetails{
+				ExistingFeed: toFeedResponse(conflictErr.ExistingFeed),
+			})
 		}
-		folderID = &id
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusCreated, toFeedResponse(feed))
+}
+
+// CreateScraped subscribes to a web page that has no feed.
+// @Summary Create a scraped feed
+// @Description Subscribe to a web page without a feed by CSS selectors: items selects each entry on the page, and title, link and date select within an item. Without title an entry is titled by the item's text, without link it takes the item's first link, and without date it is dated when first seen; a date is read from the datetime attribute of a time element when there is one, else from the text. Links are resolved against the page URL, and at most 100 items are taken, in page order. Every refresh scrapes the page again, and new entries have their content fetched from their pages. Selectors that pick nothing out of the page are rejected with 400; when the page cannot be fetched the feed is created with the error and retried in the background. dateQuirks corrects how the dates picked out of the page are read, as for PUT /feeds/{id}, and requestOverrides replaces the User-Agent and headers the page is requested with, as for POST /feeds.
+// @Tags feeds
+// @Accept json
+// @Produce json
+// @Param feed body createScrapedFeedRequest true "Scraped feed creation request"
+// @Success 201 {object} feedResponse
+// @Failure 400 {object} errorResponse
+// @Failure 409 {object} errorResponse{details=feedConflictDetails} "Page URL already subscribed"
+// @Router /feeds/scraped [post]
+func (h *FeedHandler) CreateScraped(c echo.Context) error {
+	var req createScrapedFeedRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if v.required("url", req.URL) {
+		v.httpURL("url", req.URL)
+	}
+	folderID := v.optionalID("folderId", req.FolderID)
+	v.oneOf("type", req.Type, service.ContentTypes...)
+	scraper := v.feedScraper("scraper", req.Scraper)
+	dateQuirks := v.dateQuirks("dateQuirks", req.DateQuirks)
+	overrides := v.requestOverrides("requestOverrides", req.RequestOverrides)
+	auth := v.feedAuth("auth", req.Auth)
+	if v.failed() {
+		return v.write(c)
 	}
 	feedType := req.Type
 	if feedType == "" {
 		feedType = "article"
-	} else if !isValidContentType(feedType) {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "type must be article, picture, or notification"})
 	}
-	feed, err := h.service.Add(c.Request().Context(), req.URL, folderID, req.Title, feedType)
+	feed, err := h.service.AddScraped(c.Request().Context(), req.URL, folderID, req.Title, feedType, scraper, dateQuirks, overrides, auth)
 	if err != nil {
 		var conflictErr *service.FeedConflictError
 		if errors.As(err, &conflictErr) {
-			return c.JSON(http.StatusConflict, feedConflictResponse{
-				Error:        "feed_exists",
+			return errorWithDetails(c, CodeFeedExists, "feed already exists", feedConflictDetails{
 				ExistingFeed: toFeedResponse(conflictErr.ExistingFeed),
 			})
 		}
@@ -128,6 +389,90 @@ func (h *FeedHandler) Create(c echo.Context) error {
 	return c.JSON(http.StatusCreated, toFeedResponse(feed))
 }
 
+// PreviewScraped tries scraper selectors on a page.
+// @Summary Preview a scraped feed
+// @Description Fetch a page and return the entries the selectors pick out of it, as POST /feeds/scraped would create them, to try selectors before subscribing. publishedAt is unset for items without a date, and otherwise the date the first refresh would store under dateQuirks (see PUT /feeds/{id}) and the future dates setting. The page is requested with requestOverrides (see POST /feeds). Nothing is stored.
+// @Tags feeds
+// @Accept json
+// @Produce json
+// @Param feed body previewScrapedRequest true "Scraped feed preview request"
+// @Success 200 {object} scrapePreviewResponse
+// @Failure 400 {object} errorResponse
+// @Router /feeds/scraped/preview [post]
+func (h *FeedHandler) PreviewScraped(c echo.Context) error {
+	var req previewScrapedRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	rawURL := strings.TrimSpace(req.URL)
+	var v validator
+	if v.required("url", rawURL) {
+		v.httpURL("url", rawURL)
+	}
+	scraper := v.feedScraper("scraper", req.Scraper)
+	dateQuirks := v.dateQuirks("dateQuirks", req.DateQuirks)
+	overrides := v.requestOverrides("requestOverrides", req.RequestOverrides)
+	auth := v.feedAuth("auth", req.Auth)
+	if v.failed() {
+		return v.write(c)
+	}
+	preview, err := h.service.PreviewScraped(c.Request().Context(), rawURL, scraper, dateQuirks, overrides, auth)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	response := scrapePreviewResponse{URL: preview.URL, Title: preview.Title, Items: make([]scrapedItemResponse, len(preview.Items))}
+	for i, item := range preview.Items {
+		response.Items[i] = scrapedItemResponse{Title: item.Title, URL: item.URL}
+		if item.PublishedAt != nil {
+			published := item.PublishedAt.UTC().Format(time.RFC3339)
+			response.Items[i].PublishedAt = &published
+		}
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// BulkAdd subscribes to a list of feed URLs.
+// @Summary Add feeds from a URL list
+// @Description Subscribe to each URL of a newline-separated list (up to 200, e.g. pasted from a blogroll), fetching several at once. Blank lines and repeats are skipped. Each URL gets a result in list order: added (with the new feed), exists (with the subscribed feed), invalid (not an http(s) URL) or failed (with the error; unlike POST /feeds, a URL that cannot be fetched as a feed is not subscribed to).
+// @Tags feeds
+// @Accept json
+// @Produce json
+// @Param request body bulkAddFeedsRequest true "URL list, folder and content type for the new feeds"
+// @Success 200 {object} bulkAddFeedsResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /feeds/bulk-add [post]
+func (h *FeedHandler) BulkAdd(c echo.Context) error {
+	var req bulkAddFeedsRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	v.required("urls", req.URLs)
+	folderID := v.optionalID("folderId", req.FolderID)
+	v.oneOf("type", req.Type, service.ContentTypes...)
+	if v.failed() {
+		return v.write(c)
+	}
+	feedType := req.Type
+	if feedType == "" {
+		feedType = "article"
+	}
+	results, err := h.service.BulkAdd(c.Request().Context(), req.URLs, folderID, feedType)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	response := bulkAddFeedsResponse{Results: make([]bulkAddResultResponse, len(results))}
+	for i, result := range results {
+		response.Results[i] = bulkAddResultResponse{URL: result.URL, Status: result.Status, Error: result.Error}
+		if result.Feed != nil {
+			feed := toFeedResponse(*result.Feed)
+			response.Results[i].Feed = &feed
+		}
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
 // List returns all feeds, optionally filtered by folder.
 // @Summary List feeds
 // @Description Get a list of all subscribed feeds
@@ -137,13 +482,10 @@ func (h *FeedHandler) Create(c echo.Context) error {
 // @Success 200 {array} feedResponse
 // @Router /feeds [get]
 func (h *FeedHandler) List(c echo.Context) error {
-	var folderID *int64
-	if raw := c.QueryParam("folderId"); raw != "" {
-		parsed, err := strconv.ParseInt(raw, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
-		}
-		folderID = &parsed
+	var v validator
+	folderID := v.queryID(c, "folderId")
+	if v.failed() {
+		return v.write(c)
 	}
 
 	feeds, err := h.service.List(c.Request().Context(), folderID)
@@ -168,19 +510,101 @@ func (h *FeedHandler) List(c echo.Context) error {
 // @Router /feeds/preview [get]
 func (h *FeedHandler) Preview(c echo.Context) error {
 	rawURL := strings.TrimSpace(c.QueryParam("url"))
-	if rawURL == "" {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+	var v validator
+	if v.required("url", rawURL) {
+		v.httpURL("url", rawURL)
+	}
+	if v.failed() {
+		return v.write(c)
 	}
-	preview, err := h.service.Preview(c.Request().Context(), rawURL)
+	preview, err := h.service.Preview(c.Request().Context(), rawURL, nil, nil)
 	if err != nil {
 		return writeServiceError(c, err)
 	}
 	return c.JSON(http.StatusOK, toFeedPreviewResponse(preview))
 }
 
+// PreviewWithAuth fetches a feed's information with credentials.
+// @Summary Preview a feed with credentials
+// @Description Fetch information about a feed behind HTTP Basic auth or a token header, or one that needs its own requestOverrides (see POST /feeds). Credentials go in the body rather than the query string, so they stay out of access logs; they are not stored.
+// @Tags feeds
+// @Accept json
+// @Produce json
+// @Param feed body previewFeedRequest true "Feed preview request"
+// @Success 200 {object} feedPreviewResponse
+// @Failure 400 {object} errorResponse
+// @Router /feeds/preview [post]
+func (h *FeedHandler) PreviewWithAuth(c echo.Context) error {
+	var req previewFeedRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	rawURL := strings.TrimSpace(req.URL)
+	var v validator
+	if v.required("url", rawURL) {
+		v.httpURL("url", rawURL)
+	}
+	overrides := v.requestOverrides("requestOverrides", req.RequestOverrides)
+	auth := v.feedAuth("auth", req.Auth)
+	if v.failed() {
+		return v.write(c)
+	}
+	preview, err := h.service.Preview(c.Request().Context(), rawURL, overrides, auth)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toFeedPreviewResponse(preview))
+}
+
+// Discover finds the feeds of a website.
+// @Summary Discover feeds
+// @Description Find the feeds of a website from any of its URLs. When the URL is a feed itself it is the only result (source direct). Otherwise the page's <link rel="alternate"> feeds are checked (source link), and when it links none, the common paths /feed, /rss.xml, /atom.xml, /feed.xml, /index.xml and /rss of the site (source path).
+// @Description Only candidates that fetch as feeds are returned, in page order with comment feeds last. The list is empty when none was found.
+// @Tags feeds
+// @Produce json
+// @Param url query string true "Website or feed URL"
+// @Success 200 {array} feedCandidateResponse
+// @Failure 400 {object} errorResponse
+// @Failure 502 {object} errorResponse
+// @Router /feeds/discover [get]
+func (h *FeedHandler) Discover(c echo.Context) error {
+	rawURL := strings.TrimSpace(c.QueryParam("url"))
+	var v validator
+	if v.required("url", rawURL) {
+		v.httpURL("url", rawURL)
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+	candidates, err := h.service.Discover(c.Request().Context(), rawURL)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	response := make([]feedCandidateResponse, len(candidates))
+	for i, candidate := range candidates {
+		response[i] = feedCandidateResponse{
+			URL:       candidate.URL,
+			Title:     candidate.Title,
+			Source:    candidate.Source,
+			ItemCount: candidate.ItemCount,
+		}
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
 // Update updates an existing feed.
 // @Summary Update a feed
-// @Description Update the title or folder of an existing feed
+// @Description Update the title, folder, refresh interval, full-text service, summary language, date corrections, scraper selectors, request overrides or credentials of an existing feed.
+// @Description refreshInterval is in minutes (5 to 10080); 0 lets the scheduler decide, and omitting it keeps the current one.
+// @Description fullTextUrl is an external full-text service (Morss, FiveFilters) that new entries are extracted with during refresh and on demand, stored as their readable content, instead of the built-in readability.
+// @Description {url} in it stands for the escaped entry URL (e.g. https://ftr.example.com/extract.php?url={url}); without it the entry URL is appended (e.g. https://morss.it/:proxy/).
+// @Description The service may answer with JSON carrying content, a feed whose first item holds the text, or the page itself. An empty string goes back to readability, and omitting it keeps the current one.
+// @Description summaryInSourceLanguage has AI summaries of the feed's entries written in the article's own language (e.g. for language learning) instead of the global summary language; omitting it keeps the current choice.
+// @Description prefetchReadability has each refresh fetch the readable content of the feed's new entries in the background, so articles of truncated feeds are complete and available offline when opened; omitting it keeps the current choice.
+// @Description dateQuirks fixes feeds whose entries sort wrong because of their dates: timezone (IANA, e.g. Asia/Shanghai) is the zone of dates written without an offset, which are otherwise read as UTC; dayFirst reads numeric dates such as 03/04/2025 as 3 April; ignoreFuture never trusts a date in the future, using the entry's updated date when it is not, else the time it was first seen, whatever the future dates setting. An empty object removes the corrections, and omitting it keeps the current ones. They apply from the next refresh.
+// @Description scraper replaces the CSS selectors of a feed scraped from a web page (see POST /feeds/scraped); an empty object makes it an ordinary feed again, and omitting it keeps the current ones. Setting it on an ordinary feed has its URL scraped as a page from the next refresh.
+// @Description requestOverrides replaces the User-Agent and headers the feed is requested with (see POST /feeds); an empty object goes back to the defaults, and omitting it keeps the current ones.
+// @Description auth replaces the credentials the feed is fetched with (HTTP Basic username and password, extra headers); an empty object removes them, and omitting it keeps the current ones. Stored credentials are never returned, only hasAuth.
 // @Tags feeds
 // @Accept json
 // @Produce json
@@ -193,21 +617,38 @@ func (h *FeedHandler) Preview(c echo.Context) error {
 func (h *FeedHandler) Update(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 	var req updateFeedRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
-	var folderID *int64
-	if req.FolderID != nil {
-		fid, err := strconv.ParseInt(*req.FolderID, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid folder ID"})
+	var v validator
+	folderID := v.optionalID("folderId", req.FolderID)
+	if req.RefreshInterval != nil && *req.RefreshInterval != 0 {
+		v.intRange("refreshInterval", *req.RefreshInterval, service.MinRefreshIntervalMinutes, service.MaxRefreshIntervalMinutes)
+	}
+	if req.FullTextURL != nil && strings.TrimSpace(*req.FullTextURL) != "" {
+		if err := service.ValidateFullTextURL(strings.TrimSpace(*req.FullTextURL)); err != nil {
+			v.fail("fullTextUrl", fieldInvalidURL, "must be an http(s) URL")
+		}
+	}
+	overrides := v.requestOverrides("requestOverrides", req.RequestOverrides)
+	auth := v.feedAuth("auth", req.Auth)
+	dateQuirks := v.dateQuirks("dateQuirks", req.DateQuirks)
+	var scraper *model.FeedScraper
+	if req.Scraper != nil {
+		if model.FeedScraper(*req.Scraper).IsEmpty() {
+			scraper = &model.FeedScraper{}
+		} else {
+			selectors := v.feedScraper("scraper", *req.Scraper)
+			scraper = &selectors
 		}
-		folderID = &fid
 	}
-	feed, err := h.service.Update(c.Request().Context(), id, req.Title, folderID)
+	if v.failed() {
+		return v.write(c)
+	}
+	feed, err := h.service.Update(c.Request().Context(), id, req.Title, folderID, req.RefreshInterval, req.FullTextURL, req.SummaryInSourceLanguage, req.PrefetchReadability, dateQuirks, scraper, overrides, auth)
 	if err != nil {
 		return writeServiceError(c, err)
 	}
@@ -216,7 +657,7 @@ func (h *FeedHandler) Update(c echo.Context) error {
 
 // UpdateType updates the content type of a feed.
 // @Summary Update feed type
-// @Description Change the content type of a feed (article/picture/notification)
+// @Description Change the content type of a feed (article/picture/notification/podcast)
 // @Tags feeds
 // @Accept json
 // @Param id path int true "Feed ID"
@@ -228,14 +669,18 @@ func (h *FeedHandler) Update(c echo.Context) error {
 func (h *FeedHandler) UpdateType(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 	var req updateTypeRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
-	if !isValidContentType(req.Type) {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "type must be article, picture, or notification"})
+	var v validator
+	if v.required("type", req.Type) {
+		v.oneOf("type", req.Type, service.ContentTypes...)
+	}
+	if v.failed() {
+		return v.write(c)
 	}
 	if err := h.service.UpdateType(c.Request().Context(), id, req.Type); err != nil {
 		return writeServiceError(c, err)
@@ -243,24 +688,61 @@ func (h *FeedHandler) UpdateType(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// Enable enables a feed disabled for failing too often.
+// @Summary Enable a feed
+// @Description Enable a feed that was disabled after failing too many refreshes in a row (see feedDisableThreshold in the general settings): its failure count is reset and it is refreshed in the next scheduled run. Enabling a feed that is not disabled just makes it due.
+// @Tags feeds
+// @Produce json
+// @Param id path int true "Feed ID"
+// @Success 200 {object} feedResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /feeds/{id}/enable [post]
+func (h *FeedHandler) Enable(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	feed, err := h.service.Enable(c.Request().Context(), id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toFeedResponse(feed))
+}
+
 // Delete deletes a feed.
 // @Summary Delete a feed
-// @Description Unsubscribe from a feed
+// @Description Unsubscribe from a feed. mode decides what happens to its entries:
+// @Description deleteAll (default) removes them, keepStarred keeps starred entries, keepAll keeps every entry.
+// @Description When entries are kept the feed is archived instead of removed; resubscribing restores it.
 // @Tags feeds
+// @Produce json
 // @Param id path int true "Feed ID"
-// @Success 204 "No Content"
+// @Param mode query string false "deleteAll, keepStarred or keepAll"
+// @Success 200 {object} deleteFeedResponse
 // @Failure 400 {object} errorResponse
 // @Failure 404 {object} errorResponse
 // @Router /feeds/{id} [delete]
 func (h *FeedHandler) Delete(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	mode := c.QueryParam("mode")
+	var v validator
+	v.oneOf("mode", mode, service.FeedDeleteAll, service.FeedDeleteKeepStarred, service.FeedDeleteKeepAll)
+	if v.failed() {
+		return v.write(c)
 	}
-	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+	result, err := h.service.Delete(c.Request().Context(), id, mode)
+	if err != nil {
 		return writeServiceError(c, err)
 	}
-	return c.NoContent(http.StatusNoContent)
+	return c.JSON(http.StatusOK, deleteFeedResponse{
+		EntriesDeleted: result.EntriesDeleted,
+		EntriesKept:    result.EntriesKept,
+		Archived:       result.Archived,
+	})
 }
 
 // DeleteBatch deletes multiple feeds.
@@ -275,20 +757,12 @@ func (h *FeedHandler) Delete(c echo.Context) error {
 func (h *FeedHandler) DeleteBatch(c echo.Context) error {
 	var req deleteFeedsRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
-	if len(req.IDs) == 0 {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "no feed IDs provided"})
-	}
-
-	// Parse all IDs first
-	ids := make([]int64, 0, len(req.IDs))
-	for _, idStr := range req.IDs {
-		id, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid feed ID"})
-		}
-		ids = append(ids, id)
+	var v validator
+	ids := v.ids("ids", req.IDs)
+	if v.failed() {
+		return v.write(c)
 	}
 
 	// Delete all at once
@@ -299,38 +773,119 @@ func (h *FeedHandler) DeleteBatch(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
-// RefreshAll triggers a refresh of all feeds.
+// RefreshAll starts a refresh of all feeds.
 // @Summary Refresh all feeds
-// @Description Trigger an immediate refresh of all subscribed feeds
+// @Description Start a background refresh of all subscribed feeds. Poll the returned task via /tasks/{id}.
 // @Tags feeds
-// @Success 204 "No Content"
+// @Produce json
+// @Success 202 {object} service.Task
 // @Failure 409 {object} errorResponse "Refresh already in progress"
 // @Router /feeds/refresh [post]
 func (h *FeedHandler) RefreshAll(c echo.Context) error {
-	if err := h.refreshService.RefreshAll(c.Request().Context()); err != nil {
-		if errors.Is(err, service.ErrAlreadyRefreshing) {
-			return c.JSON(http.StatusConflict, errorResponse{Error: "refresh already in progress"})
+	task, err := h.tasks.Start(service.TaskRefresh, 0, service.RefreshAllTask(h.refreshService))
+	if err != nil {
+		if errors.Is(err, service.ErrTaskRunning) {
+			// A scheduled refresh still spreading its fetches finishes them now
+			h.refreshService.Hurry()
+			return Error(c, CodeRefreshInProgress, "refresh already in progress")
 		}
 		return writeServiceError(c, err)
 	}
-	return c.NoContent(http.StatusNoContent)
+	return c.JSON(http.StatusAccepted, task)
+}
+
+// Refresh refreshes a feed now.
+// @Summary Refresh a feed
+// @Description Fetch a feed now and wait for the result, for debugging a broken subscription: the outcome (ok, not_modified, http_error or error), HTTP status, duration, new entries and the error, such as a parse error. A failed fetch is still a 200 response. The attempt is recorded in the feed's health as a scheduled one would be; the request times out after a minute.
+// @Tags feeds
+// @Produce json
+// @Param id path int true "Feed ID"
+// @Success 200 {object} service.FeedFetchAttempt
+// @Failure 400 {object} errorResponse "Invalid id, or a feed that is not fetched, such as Saved pages"
+// @Failure 404 {object} errorResponse
+// @Failure 503 {object} errorResponse "Timed out, or host_cooling_down with a Retry-After header while the feed's host is left alone after answering 429 Too Many Requests"
+// @Router /feeds/{id}/refresh [post]
+func (h *FeedHandler) Refresh(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	attempt, err := h.refreshService.RefreshNow(c.Request().Context(), id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, attempt)
+}
+
+// FetchReadable extracts the readable content of a feed's entries.
+// @Summary Fetch readable content of a feed's entries
+// @Description Start extracting the readable content of every entry of a feed that has none, or of its unread entries only, for feeds that publish excerpts. Pages are fetched one at a time, through the feed's full-text service when it has one; entries that fail are skipped. Returns the task to poll via /tasks/{id}; its progress counts entries and its result is a ReadableBatchResult. One such task runs at a time.
+// @Tags feeds
+// @Produce json
+// @Param id path int true "Feed ID"
+// @Param unreadOnly query bool false "Only unread entries"
+// @Success 202 {object} service.Task
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse "Extraction already in progress"
+// @Router /feeds/{id}/fetch-readable [post]
+func (h *FeedHandler) FetchReadable(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	entryIDs, err := h.readability.PendingReadable(c.Request().Context(), id, c.QueryParam("unreadOnly") == "true")
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+
+	task, err := h.tasks.Start(service.TaskFetchReadable, len(entryIDs), service.FetchReadableTask(h.readability, entryIDs))
+	if errors.Is(err, service.ErrTaskRunning) {
+		return Error(c, CodeFetchReadableInProgress, "readable content extraction already in progress")
+	}
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, task)
 }
 
 func toFeedResponse(feed model.Feed) feedResponse {
+	var nextRefreshAt *string
+	if feed.NextRefreshAt != nil {
+		next := feed.NextRefreshAt.UTC().Format(time.RFC3339)
+		nextRefreshAt = &next
+	}
+	var disabledAt *string
+	if feed.DisabledAt != nil {
+		disabled := feed.DisabledAt.UTC().Format(time.RFC3339)
+		disabledAt = &disabled
+	}
 	return feedResponse{
-		ID:           idToString(feed.ID),
-		FolderID:     idPtrToString(feed.FolderID),
-		Title:        feed.Title,
-		URL:          feed.URL,
-		SiteURL:      feed.SiteURL,
-		Description:  feed.Description,
-		IconPath:     feed.IconPath,
-		Type:         feed.Type,
-		ETag:         feed.ETag,
-		LastModified: feed.LastModified,
-		ErrorMessage: feed.ErrorMessage,
-		CreatedAt:    feed.CreatedAt.UTC().Format(time.RFC3339),
-		UpdatedAt:    feed.UpdatedAt.UTC().Format(time.RFC3339),
+		ID:                      idToString(feed.ID),
+		FolderID:                idPtrToString(feed.FolderID),
+		Title:                   feed.Title,
+		URL:                     feed.URL,
+		SiteURL:                 feed.SiteURL,
+		Description:             feed.Description,
+		IconPath:                feed.IconPath,
+		IconColor:               feed.IconColor,
+		Type:                    feed.Type,
+		ETag:                    feed.ETag,
+		LastModified:            feed.LastModified,
+		ErrorMessage:            feed.ErrorMessage,
+		RefreshInterval:         feed.RefreshInterval,
+		NextRefreshAt:           nextRefreshAt,
+		FullTextURL:             feed.FullTextURL,
+		SummaryInSourceLanguage: feed.SummaryInSourceLanguage,
+		PrefetchReadability:     feed.PrefetchReadability,
+		DateQuirks:              (*feedDateQuirks)(feed.DateQuirks),
+		Scraper:                 (*feedScraper)(feed.Scraper),
+		RequestOverrides:        (*feedRequestOverrides)(feed.RequestOverrides),
+		HasAuth:                 feed.HasAuth,
+		ConsecutiveFailures:     feed.ConsecutiveFailures,
+		DisabledAt:              disabledAt,
+		CreatedAt:               feed.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:               feed.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 }
 
@@ -345,3 +900,62 @@ func toFeedPreviewResponse(preview service.FeedPreview) feedPreviewResponse {
 		LastUpdated: preview.LastUpdated,
 	}
 }
+
+// feedAuth checks and converts the credentials of a feed request; nil when
+// none were sent.
+func (v *validator) feedAuth(field string, raw *feedAuthRequest) *model.FeedAuth {
+	if raw == nil {
+		return nil
+	}
+	auth, err := service.NormalizeFeedAuth(model.FeedAuth{
+		Username: raw.Username,
+		Password: raw.Password,
+		Headers:  raw.Headers,
+	})
+	if err != nil {
+		v.fail(field, fieldInvalidFormat, strings.TrimPrefix(err.Error(), service.ErrInvalid.Error()+": "))
+		return nil
+	}
+	return &auth
+}
+
+// dateQuirks checks the time zone of a feed's date corrections.
+func (v *validator) dateQuirks(field string, raw *feedDateQuirks) *model.FeedDateQuirks {
+	if raw == nil {
+		return nil
+	}
+	quirks, err := service.NormalizeDateQuirks(model.FeedDateQuirks(*raw))
+	if err != nil {
+		v.fail(field+".timezone", fieldInvalidFormat, "must be an IANA time zone such as Europe/Paris")
+		return nil
+	}
+	return &quirks
+}
+
+// requestOverrides checks the User-Agent and headers a feed is requested
+// with.
+func (v *validator) requestOverrides(field string, raw *feedRequestOverrides) *model.FeedRequestOverrides {
+	if raw == nil {
+		return nil
+	}
+	overrides, err := service.NormalizeRequestOverrides(model.FeedRequestOverrides(*raw))
+	if err != nil {
+		v.fail(field, fieldInvalidFormat, strings.TrimPrefix(err.Error(), service.ErrInvalid.Error()+": "))
+		return nil
+	}
+	return &overrides
+}
+
+// feedScraper checks the selectors of a scraped feed, which need an items
+// selector.
+func (v *validator) feedScraper(field string, raw feedScraper) model.FeedScraper {
+	if strings.TrimSpace(raw.Items) == "" {
+		v.fail(field+".items", fieldRequired, "is required")
+		return model.FeedScraper{}
+	}
+	scraper, err := service.NormalizeScraper(model.FeedScraper(raw))
+	if err != nil {
+		v.fail(field, fieldInvalidFormat, strings.TrimPrefix(err.Error(), service.ErrInvalid.Error()+": "))
+	}
+	return scraper
+}
@@ -0,0 +1,309 @@
+package handler
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service"
+)
+
+type FilterHandler struct {
+	service service.FilterService
+}
+
+type filterRequest struct {
+	// FeedID limits the filter to one feed; omitted applies it to every feed.
+	FeedID   *string `json:"feedId"`
+	Field    string  `json:"field"`
+	Match    string  `json:"match"`
+	Pattern  string  `json:"pattern"`
+	Action   string  `json:"action"`
+	FolderID *string `json:"folderId"`
+	// Enabled defaults to true when omitted.
+	Enabled *bool `json:"enabled"`
+}
+
+// filterTestRequest is a rule to try and the entry to try it on.
+type filterTestRequest struct {
+	filterRequest
+	Entry filterSampleEntry `json:"entry"`
+}
+
+type filterSampleEntry struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Author  string `json:"author"`
+	URL     string `json:"url"`
+}
+
+// filterTestResponse is what the rule did to the sample entry.
+type filterTestResponse struct {
+	Matched bool `json:"matched"`
+	// Skip means the entry would not be stored.
+	Skip     bool    `json:"skip"`
+	Read     bool    `json:"read"`
+	Starred  bool    `json:"starred"`
+	FolderID *string `json:"folderId,omitempty"`
+	Title    string  `json:"title"`
+	Content  string  `json:"content"`
+	Author   string  `json:"author"`
+	// Error is why the expression failed on the entry, which refresh would
+	// log and then ignore the rule.
+	Error string `json:"error,omitempty"`
+}
+
+type filterResponse struct {
+	ID       string  `json:"id"`
+	FeedID   *string `json:"feedId,omitempty"`
+	Field    string  `json:"field"`
+	Match    string  `json:"match"`
+	Pattern  string  `json:"pattern"`
+	Action   string  `json:"action"`
+	FolderID *string `json:"folderId,omitempty"`
+	Enabled  bool    `json:"enabled"`
+	// CreatedAt also orders the filters: they run oldest first.
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+var (
+	filterFields  = []string{model.FilterFieldTitle, model.FilterFieldContent, model.FilterFieldAuthor, model.FilterFieldURL}
+	filterMatches = []string{model.FilterContains, model.FilterRegex, model.FilterExpr}
+	filterActions = []string{model.FilterActionRead, model.FilterActionStar, model.FilterActionSkip, model.FilterActionMove, model.FilterActionRewrite}
+)
+
+func NewFilterHandler(service service.FilterService) *FilterHandler {
+	return &FilterHandler{service: service}
+}
+
+func (h *FilterHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/filters", h.List)
+	g.POST("/filters", h.Create)
+	g.POST("/filters/test", h.Test)
+	g.PUT("/filters/:id", h.Update)
+	g.DELETE("/filters/:id", h.Delete)
+}
+
+// List returns all filters.
+// @Summary List filters
+// @Description Get the filter rules applied to new entries during refresh, in the order they run
+// @Tags filters
+// @Produce json
+// @Success 200 {array} filterResponse
+// @Router /filters [get]
+func (h *FilterHandler) List(c echo.Context) error {
+	filters, err := h.service.List(c.Request().Context())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	response := make([]filterResponse, 0, len(filters))
+	for _, filter := range filters {
+		response = append(response, toFilterResponse(filter))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// Create creates a filter.
+// @Summary Create a filter
+// @Description Add a rule that refresh applies to new entries of one feed (feedId) or of every feed.
+// @Description field is what is matched (title, content, author or url) and match how: contains (case-insensitive) or regex (Go syntax).
+// @Description match expr makes pattern an expression (expr-lang syntax) over title, content (HTML), text (content as plain text), author and url, with no field;
+// @Description it must return a bool, except for action rewrite, which returns a map of title, content or author to replace, or nil.
+// @Description action is mark_read, star, skip (the entry is not stored), move, which files the entry under folderId in folder views, or rewrite.
+// @Description Entries already stored are not changed.
+// @Tags filters
+// @Accept json
+// @Produce json
+// @Param filter body filterRequest true "Filter rule"
+// @Success 201 {object} filterResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse "The feed or folder does not exist"
+// @Router /filters [post]
+func (h *FilterHandler) Create(c echo.Context) error {
+	var req filterRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	params := req.params(&v)
+	if v.failed() {
+		return v.write(c)
+	}
+	filter, err := h.service.Create(c.Request().Context(), params)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusCreated, toFilterResponse(filter))
+}
+
+// Test runs a rule on a sample entry.
+// @Summary Test a filter
+// @Description Run a rule, without saving it, on a sample entry and return the entry as refresh would store it.
+// @Description The rule is validated as for creating one; an expression that fails on the entry reports why in error.
+// @Tags filters
+// @Accept json
+// @Produce json
+// @Param test body filterTestRequest true "Filter rule and sample entry"
+// @Success 200 {object} filterTestResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse "The feed or folder does not exist"
+// @Router /filters/test [post]
+func (h *FilterHandler) Test(c echo.Context) error {
+	var req filterTestRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	params := req.params(&v)
+	if v.failed() {
+		return v.write(c)
+	}
+	text := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+	sample := model.Entry{
+		Title:   text(req.Entry.Title),
+		Content: text(req.Entry.Content),
+		Author:  text(req.Entry.Author),
+		URL:     text(req.Entry.URL),
+	}
+	result, err := h.service.Test(c.Request().Context(), params, sample)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	entry := result.Entry
+	return c.JSON(http.StatusOK, filterTestResponse{
+		Matched:  result.Matched,
+		Skip:     result.Skip,
+		Read:     entry.Read,
+		Starred:  entry.Starred,
+		FolderID: idPtrToString(entry.FolderID),
+		Title:    stringValue(entry.Title),
+		Content:  stringValue(entry.Content),
+		Author:   stringValue(entry.Author),
+		Error:    result.Error,
+	})
+}
+
+// Update replaces the rule of a filter.
+// @Summary Update a filter
+// @Description Replace the rule of a filter. Fields are as for creating one.
+// @Tags filters
+// @Accept json
+// @Produce json
+// @Param id path int true "Filter ID"
+// @Param filter body filterRequest true "Filter rule"
+// @Success 200 {object} filterResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /filters/{id} [put]
+func (h *FilterHandler) Update(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var req filterRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	params := req.params(&v)
+	if v.failed() {
+		return v.write(c)
+	}
+	filter, err := h.service.Update(c.Request().Context(), id, params)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toFilterResponse(filter))
+}
+
+// Delete deletes a filter.
+// @Summary Delete a filter
+// @Description Delete a filter rule
+// @Tags filters
+// @Param id path int true "Filter ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /filters/{id} [delete]
+func (h *FilterHandler) Delete(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// params validates the request into v, returning the rule it describes.
+func (req filterRequest) params(v *validator) service.FilterParams {
+	feedID := v.optionalID("feedId", req.FeedID)
+	// An expression reads the entry itself
+	if req.Match != model.FilterExpr && v.required("field", req.Field) {
+		v.oneOf("field", req.Field, filterFields...)
+	}
+	if v.required("match", req.Match) {
+		v.oneOf("match", req.Match, filterMatches...)
+	}
+	if v.required("pattern", req.Pattern) {
+		switch req.Match {
+		case model.FilterRegex:
+			if _, err := regexp.Compile(req.Pattern); err != nil {
+				v.fail("pattern", fieldInvalidFormat, "must be a valid regular expression")
+			}
+		case model.FilterExpr:
+			if err := service.ValidateFilterExpr(req.Pattern, req.Action); err != nil {
+				v.fail("pattern", fieldInvalidFormat, "must be a valid expression: "+err.Error())
+			}
+		}
+	}
+	if v.required("action", req.Action) {
+		v.oneOf("action", req.Action, filterActions...)
+	}
+	if req.Action == model.FilterActionRewrite && req.Match != model.FilterExpr {
+		v.fail("action", fieldInvalidEnum, "rewrite needs an expression")
+	}
+	folderID := v.optionalID("folderId", req.FolderID)
+	if req.Action == model.FilterActionMove && req.FolderID == nil {
+		v.fail("folderId", fieldRequired, "is required to move entries")
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return service.FilterParams{
+		FeedID:   feedID,
+		Field:    req.Field,
+		Match:    req.Match,
+		Pattern:  req.Pattern,
+		Action:   req.Action,
+		FolderID: folderID,
+		Enabled:  enabled,
+	}
+}
+
+func toFilterResponse(filter model.Filter) filterResponse {
+	return filterResponse{
+		ID:        idToString(filter.ID),
+		FeedID:    idPtrToString(filter.FeedID),
+		Field:     filter.Field,
+		Match:     filter.Match,
+		Pattern:   filter.Pattern,
+		Action:    filter.Action,
+		FolderID:  idPtrToString(filter.FolderID),
+		Enabled:   filter.Enabled,
+		CreatedAt: filter.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: filter.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
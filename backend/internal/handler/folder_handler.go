@@ -2,7 +2,6 @@ package handler
 
 import (
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -25,17 +24,23 @@ type updateFolderTypeRequest struct {
 	Type string `json:"type"`
 }
 
+type folderThumbnailsRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
 type deleteFoldersRequest struct {
 	IDs []string `json:"ids"`
 }
 
 type folderResponse struct {
-	ID        string  `json:"id"`
-	Name      string  `json:"name"`
-	ParentID  *string `json:"parentId,omitempty"`
-	Type      string  `json:"type"`
-	CreatedAt string  `json:"createdAt"`
-	UpdatedAt string  `json:"updatedAt"`
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	ParentID *string `json:"parentId,omitempty"`
+	Type     string  `json:"type"`
+	// PregenerateThumbnails is set when grid thumbnails are cached after each refresh.
+	PregenerateThumbnails bool   `json:"pregenerateThumbnails"`
+	CreatedAt             string `json:"createdAt"`
+	UpdatedAt             string `json:"updatedAt"`
 }
 
 func NewFolderHandler(service service.FolderService) *FolderHandler {
@@ -47,6 +52,7 @@ func (h *FolderHandler) RegisterRoutes(g *echo.Group) {
 	g.GET("/folders", h.List)
 	g.PUT("/folders/:id", h.Update)
 	g.PATCH("/folders/:id/type", h.UpdateType)
+	g.PATCH("/folders/:id/thumbnails", h.SetThumbnails)
 	g.DELETE("/folders/:id", h.Delete)
 	g.DELETE("/folders", h.DeleteBatch)
 }
@@ -64,21 +70,18 @@ func (h *FolderHandler) RegisterRoutes(g *echo.Group) {
 func (h *FolderHandler) Create(c echo.Context) error {
 	var req folderRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
-	var parentID *int64
-	if req.ParentID != nil {
-		id, err := strconv.ParseInt(*req.ParentID, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid parent ID"})
-		}
-		parentID = &id
+	var v validator
+	v.required("name", req.Name)
+	parentID := v.optionalID("parentId", req.ParentID)
+	v.oneOf("type", req.Type, service.ContentTypes...)
+	if v.failed() {
+		return v.write(c)
 	}
 	folderType := req.Type
 	if folderType == "" {
 		folderType = "article"
-	} else if !isValidContentType(folderType) {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "type must be article, picture, or notification"})
 	}
 	folder, err := h.service.Create(c.Request().Context(), req.Name, parentID, folderType)
 	if err != nil {
@@ -121,19 +124,17 @@ func (h *FolderHandler) List(c echo.Context) error {
 func (h *FolderHandler) Update(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 	var req folderRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
-	var parentID *int64
-	if req.ParentID != nil {
-		pid, err := strconv.ParseInt(*req.ParentID, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid parent ID"})
-		}
-		parentID = &pid
+	var v validator
+	v.required("name", req.Name)
+	parentID := v.optionalID("parentId", req.ParentID)
+	if v.failed() {
+		return v.write(c)
 	}
 	folder, err := h.service.Update(c.Request().Context(), id, req.Name, parentID)
 	if err != nil {
@@ -144,7 +145,7 @@ func (h *FolderHandler) Update(c echo.Context) error {
 
 // UpdateType updates the content type of a folder.
 // @Summary Update folder type
-// @Description Change the content type of a folder (article/picture/notification)
+// @Description Change the content type of a folder (article/picture/notification/podcast)
 // @Tags folders
 // @Accept json
 // @Param id path int true "Folder ID"
@@ -156,14 +157,18 @@ func (h *FolderHandler) Update(c echo.Context) error {
 func (h *FolderHandler) UpdateType(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 	var req updateFolderTypeRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if v.required("type", req.Type) {
+		v.oneOf("type", req.Type, service.ContentTypes...)
 	}
-	if !isValidContentType(req.Type) {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "type must be article, picture, or notification"})
+	if v.failed() {
+		return v.write(c)
 	}
 	if err := h.service.UpdateType(c.Request().Context(), id, req.Type); err != nil {
 		return writeServiceError(c, err)
@@ -171,6 +176,39 @@ func (h *FolderHandler) UpdateType(c echo.Context) error {
 	return c.NoContent(http.StatusNoContent)
 }
 
+// SetThumbnails turns thumbnail pre-generation on or off for a folder.
+// @Summary Toggle thumbnail pre-generation
+// @Description Cache scaled-down grid thumbnails of a picture folder's newest images right after each refresh, so the picture grid loads without fetching full images
+// @Tags folders
+// @Accept json
+// @Param id path int true "Folder ID"
+// @Param request body folderThumbnailsRequest true "Toggle request"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorResponse "Validation failed, or the folder is not a picture folder"
+// @Failure 404 {object} errorResponse
+// @Router /folders/{id}/thumbnails [patch]
+func (h *FolderHandler) SetThumbnails(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var req folderThumbnailsRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if req.Enabled == nil {
+		v.fail("enabled", fieldRequired, "is required")
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+	if err := h.service.SetPregenerateThumbnails(c.Request().Context(), id, *req.Enabled); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
 // Delete deletes a folder.
 // @Summary Delete a folder
 // @Description Delete an existing folder
@@ -183,7 +221,7 @@ func (h *FolderHandler) UpdateType(c echo.Context) error {
 func (h *FolderHandler) Delete(c echo.Context) error {
 	id, err := parseIDParam(c, "id")
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 	if err := h.service.Delete(c.Request().Context(), id); err != nil {
 		return writeServiceError(c, err)
@@ -203,17 +241,15 @@ func (h *FolderHandler) Delete(c echo.Context) error {
 func (h *FolderHandler) DeleteBatch(c echo.Context) error {
 	var req deleteFoldersRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
-	if len(req.IDs) == 0 {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "no folder IDs provided"})
+	var v validator
+	ids := v.ids("ids", req.IDs)
+	if v.failed() {
+		return v.write(c)
 	}
 
-	for _, idStr := range req.IDs {
-		id, err := strconv.ParseInt(idStr, 10, 64)
-		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid folder ID"})
-		}
+	for _, id := range ids {
 		if err := h.service.Delete(c.Request().Context(), id); err != nil {
 			return writeServiceError(c, err)
 		}
@@ -224,11 +260,12 @@ func (h *FolderHandler) DeleteBatch(c echo.Context) error {
 
 func toFolderResponse(folder model.Folder) folderResponse {
 	return folderResponse{
-		ID:        idToString(folder.ID),
-		Name:      folder.Name,
-		ParentID:  idPtrToString(folder.ParentID),
-		Type:      folder.Type,
-		CreatedAt: folder.CreatedAt.UTC().Format(time.RFC3339),
-		UpdatedAt: folder.UpdatedAt.UTC().Format(time.RFC3339),
+		ID:                    idToString(folder.ID),
+		Name:                  folder.Name,
+		ParentID:              idPtrToString(folder.ParentID),
+		Type:                  folder.Type,
+		PregenerateThumbnails: folder.PregenerateThumbnails,
+		CreatedAt:             folder.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt:             folder.UpdatedAt.UTC().Format(time.RFC3339),
 	}
 }
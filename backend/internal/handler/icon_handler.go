@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -12,11 +13,13 @@ import (
 
 type IconHandler struct {
 	iconService service.IconService
+	tasks       service.TaskRunner
 }
 
-func NewIconHandler(iconService service.IconService) *IconHandler {
+func NewIconHandler(iconService service.IconService, tasks service.TaskRunner) *IconHandler {
 	return &IconHandler{
 		iconService: iconService,
+		tasks:       tasks,
 	}
 }
 
@@ -24,6 +27,30 @@ func (h *IconHandler) RegisterRoutes(e *echo.Echo) {
 	e.GET("/icons/:filename", h.GetIcon)
 }
 
+func (h *IconHandler) RegisterAPIRoutes(g *echo.Group) {
+	g.POST("/icons/backfill", h.Backfill)
+}
+
+// Backfill fetches missing icons now.
+// @Summary Backfill feed icons
+// @Description Start fetching icons for feeds without one and restoring missing or month-old icon files, a few feeds at a time and one per site. Also runs at startup.
+// @Description Returns the task to poll via /tasks/{id}: current and total count the feeds done, and the result is an IconBackfillResult listing the feeds whose icon could not be found.
+// @Tags feeds
+// @Produce json
+// @Success 202 {object} service.Task
+// @Failure 409 {object} errorResponse
+// @Router /icons/backfill [post]
+func (h *IconHandler) Backfill(c echo.Context) error {
+	task, err := h.tasks.Start(service.TaskIconBackfill, 0, service.BackfillIconsTask(h.iconService))
+	if errors.Is(err, service.ErrTaskRunning) {
+		return Error(c, CodeIconBackfillInProgress, "icon backfill already in progress")
+	}
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, task)
+}
+
 // GetIcon serves icon files.
 // Icons are named by domain (e.g., "example.com.png"), not by feed ID.
 func (h *IconHandler) GetIcon(c echo.Context) error {
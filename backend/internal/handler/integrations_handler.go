@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+type IntegrationsHandler struct {
+	service service.IntegrationsService
+}
+
+func NewIntegrationsHandler(integrations service.IntegrationsService) *IntegrationsHandler {
+	return &IntegrationsHandler{service: integrations}
+}
+
+func (h *IntegrationsHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/settings/integrations", h.GetSettings)
+	g.PUT("/settings/integrations", h.UpdateSettings)
+	g.POST("/entries/:id/save-to/:provider", h.SaveTo)
+}
+
+// GetSettings returns the read-later service configuration.
+// @Summary Get read-later integrations
+// @Description Get the Wallabag, Pocket and Linkding configuration entries can be pushed with. Secrets (clientSecret, password, accessToken, token) are masked.
+// @Tags settings
+// @Produce json
+// @Success 200 {object} service.IntegrationSettings
+// @Failure 500 {object} errorResponse
+// @Router /settings/integrations [get]
+func (h *IntegrationsHandler) GetSettings(c echo.Context) error {
+	settings, err := h.service.GetSettings(c.Request().Context())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings saves the read-later service configuration.
+// @Summary Update read-later integrations
+// @Description Save the configuration of every read-later service. A masked or empty secret keeps the stored one; an empty url (for Pocket, consumerKey) removes the service with its secrets.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Param settings body service.IntegrationSettings true "Integration settings"
+// @Success 200 {object} service.IntegrationSettings
+// @Failure 400 {object} errorResponse
+// @Router /settings/integrations [put]
+func (h *IntegrationsHandler) UpdateSettings(c echo.Context) error {
+	var req service.IntegrationSettings
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if req.Wallabag.URL != "" {
+		v.httpURL("wallabag.url", req.Wallabag.URL)
+	}
+	if req.Linkding.URL != "" {
+		v.httpURL("linkding.url", req.Linkding.URL)
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	if err := h.service.SetSettings(c.Request().Context(), &req); err != nil {
+		return writeServiceError(c, err)
+	}
+	return h.GetSettings(c)
+}
+
+// SaveTo pushes an entry to a read-later service.
+// @Summary Save an entry to a read-later service
+// @Description Push the URL and title of an entry to Wallabag, Pocket or Linkding. With withContent=true its readable content is sent too, for services that store content (Wallabag), so the page is not fetched again; Pocket and Linkding always fetch the page themselves.
+// @Tags entries
+// @Produce json
+// @Param id path int true "Entry ID"
+// @Param provider path string true "Read-later service (wallabag, pocket, linkding)"
+// @Param withContent query bool false "Send the readable content too"
+// @Success 200 {object} service.IntegrationSaveResult
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Failure 502 {object} errorResponse
+// @Router /entries/{id}/save-to/{provider} [post]
+func (h *IntegrationsHandler) SaveTo(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	provider := c.Param("provider")
+	var v validator
+	v.oneOf("provider", provider, service.IntegrationProviders...)
+	if v.failed() {
+		return v.write(c)
+	}
+
+	result, err := h.service.SaveTo(c.Request().Context(), id, provider, c.QueryParam("withContent") == "true")
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, result)
+}
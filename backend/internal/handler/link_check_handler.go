@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service"
+)
+
+type LinkCheckHandler struct {
+	checks service.LinkCheckService
+	tasks  service.TaskRunner
+}
+
+type brokenLinkResponse struct {
+	EntryID      string  `json:"entryId"`
+	FeedID       string  `json:"feedId"`
+	FeedTitle    string  `json:"feedTitle"`
+	Title        *string `json:"title,omitempty"`
+	URL          string  `json:"url"`
+	Status       string  `json:"status"` // dead or error
+	HTTPStatus   *int    `json:"httpStatus,omitempty"`
+	Error        *string `json:"error,omitempty"`
+	CheckedAt    string  `json:"checkedAt"`
+	FailingSince *string `json:"failingSince,omitempty"`
+}
+
+func NewLinkCheckHandler(checks service.LinkCheckService, tasks service.TaskRunner) *LinkCheckHandler {
+	return &LinkCheckHandler{checks: checks, tasks: tasks}
+}
+
+func (h *LinkCheckHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/link-checks/broken", h.Broken)
+	g.POST("/link-checks/run", h.Run)
+}
+
+// Broken reports starred entries whose links no longer work.
+// @Summary List broken starred links
+// @Description Get starred entries whose URL failed its last health check, dead links (404, 410, unknown host) first, then ones failing for other reasons (5xx, timeouts). Their content stays readable in Gist.
+// @Tags link-checks
+// @Produce json
+// @Success 200 {array} brokenLinkResponse
+// @Failure 500 {object} errorResponse
+// @Router /link-checks/broken [get]
+func (h *LinkCheckHandler) Broken(c echo.Context) error {
+	links, err := h.checks.Broken(c.Request().Context())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+
+	resp := make([]brokenLinkResponse, 0, len(links))
+	for _, link := range links {
+		resp = append(resp, toBrokenLinkResponse(link))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Run checks the due starred links now.
+// @Summary Check starred links
+// @Description Start checking the links of starred entries not checked in the last week. Also runs daily. Returns the task to poll via /tasks/{id}; its result is a LinkCheckResult.
+// @Tags link-checks
+// @Produce json
+// @Success 202 {object} service.Task
+// @Failure 409 {object} errorResponse
+// @Router /link-checks/run [post]
+func (h *LinkCheckHandler) Run(c echo.Context) error {
+	task, err := h.tasks.Start(service.TaskLinkCheck, 0, service.LinkCheckTask(h.checks))
+	if errors.Is(err, service.ErrTaskRunning) {
+		return Error(c, CodeLinkCheckInProgress, "link check already in progress")
+	}
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, task)
+}
+
+func toBrokenLinkResponse(link model.BrokenLink) brokenLinkResponse {
+	resp := brokenLinkResponse{
+		EntryID:    idToString(link.EntryID),
+		FeedID:     idToString(link.FeedID),
+		FeedTitle:  link.FeedTitle,
+		Title:      link.Title,
+		URL:        link.URL,
+		Status:     link.Status,
+		HTTPStatus: link.HTTPStatus,
+		Error:      link.Error,
+		CheckedAt:  link.CheckedAt.UTC().Format(time.RFC3339),
+	}
+	if link.FailingSince != nil {
+		formatted := link.FailingSince.UTC().Format(time.RFC3339)
+		resp.FailingSince = &formatted
+	}
+	return resp
+}
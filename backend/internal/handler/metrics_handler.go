@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+// otherFeedLabel names the series that aggregates feeds beyond the cap.
+const otherFeedLabel = "other"
+
+type MetricsHandler struct {
+	fetches   *service.FetchMetrics
+	feedLimit int
+}
+
+// NewMetricsHandler exposes fetch metrics with per-feed series for at most
+// feedLimit feeds; the rest are reported as feed="other".
+func NewMetricsHandler(fetches *service.FetchMetrics, feedLimit int) *MetricsHandler {
+	return &MetricsHandler{fetches: fetches, feedLimit: feedLimit}
+}
+
+// RegisterRoutes registers /metrics at the root, where Prometheus looks by
+// default. Feed titles are private, so it is guarded by auth.
+func (h *MetricsHandler) RegisterRoutes(e *echo.Echo, auth echo.MiddlewareFunc) {
+	e.GET("/metrics", h.Metrics, auth)
+}
+
+// Metrics serves the metrics in the Prometheus text exposition format.
+func (h *MetricsHandler) Metrics(c echo.Context) error {
+	snapshot := h.fetches.Snapshot(h.feedLimit)
+
+	var b strings.Builder
+	writeFetchMetrics(&b, snapshot)
+	return c.Blob(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+}
+
+func writeFetchMetrics(w io.Writer, snapshot service.FetchMetricsSnapshot) {
+	// The other series is always present so dashboards need no special case
+	series := make([]service.FeedFetchStats, 0, len(snapshot.Feeds)+1)
+	series = append(series, snapshot.Feeds...)
+	series = append(series, snapshot.Other)
+	feedLabel := func(stats service.FeedFetchStats) string {
+		if stats.FeedID == 0 {
+			return otherFeedLabel
+		}
+		return strconv.FormatInt(stats.FeedID, 10)
+	}
+
+	fmt.Fprintln(w, "# HELP gist_feed_fetches_total Feed refresh fetches since startup, by outcome.")
+	fmt.Fprintln(w, "# TYPE gist_feed_fetches_total counter")
+	for _, stats := range series {
+		for _, outcome := range service.FetchOutcomes {
+			fmt.Fprintf(w, "gist_feed_fetches_total{feed=%q,outcome=%q} %d\n", feedLabel(stats), outcome, stats.Outcomes[outcome])
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP gist_feed_fetch_duration_seconds Time spent on feed refresh fetches since startup.")
+	fmt.Fprintln(w, "# TYPE gist_feed_fetch_duration_seconds summary")
+	for _, stats := range series {
+		label := feedLabel(stats)
+		fmt.Fprintf(w, "gist_feed_fetch_duration_seconds_sum{feed=%q} %s\n", label, strconv.FormatFloat(stats.Seconds, 'g', -1, 64))
+		fmt.Fprintf(w, "gist_feed_fetch_duration_seconds_count{feed=%q} %d\n", label, stats.Fetches())
+	}
+
+	fmt.Fprintln(w, "# HELP gist_feed_entries_collapsed_total New entries dropped since startup as repeats of a recent title of their feed.")
+	fmt.Fprintln(w, "# TYPE gist_feed_entries_collapsed_total counter")
+	for _, stats := range series {
+		fmt.Fprintf(w, "gist_feed_entries_collapsed_total{feed=%q} %d\n", feedLabel(stats), stats.Collapsed)
+	}
+
+	fmt.Fprintln(w, "# HELP gist_feed_info Titles of the feeds that have their own series.")
+	fmt.Fprintln(w, "# TYPE gist_feed_info gauge")
+	for _, stats := range snapshot.Feeds {
+		fmt.Fprintf(w, "gist_feed_info{feed=%q,title=\"%s\"} 1\n", feedLabel(stats), escapeLabelValue(stats.Title))
+	}
+
+	fmt.Fprintln(w, "# HELP gist_feed_fetch_other_feeds Feeds aggregated into the feed=\"other\" series.")
+	fmt.Fprintln(w, "# TYPE gist_feed_fetch_other_feeds gauge")
+	fmt.Fprintf(w, "gist_feed_fetch_other_feeds %d\n", snapshot.OtherFeeds)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabelValue escapes a label value per the text exposition format,
+// which only knows \\, \" and \n. Go's %q escapes more than that.
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
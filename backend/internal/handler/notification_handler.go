@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service"
+)
+
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 100
+)
+
+type NotificationHandler struct {
+	service service.NotificationService
+}
+
+type notificationResponse struct {
+	ID       string  `json:"id"`
+	Kind     string  `json:"kind"` // import_done, task_failed or feed_disabled
+	TaskKind *string `json:"taskKind,omitempty"`
+	FeedID   *string `json:"feedId,omitempty"`
+	Title    string  `json:"title"`
+	Body     string  `json:"body,omitempty"`
+	Read     bool    `json:"read"`
+	// CreatedAt is RFC 3339 in UTC.
+	CreatedAt string `json:"createdAt"`
+}
+
+type notificationListResponse struct {
+	Notifications []notificationResponse `json:"notifications"`
+	Unread        int                    `json:"unread"`
+}
+
+func NewNotificationHandler(service service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{service: service}
+}
+
+func (h *NotificationHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/notifications", h.List)
+	g.POST("/notifications/:id/read", h.MarkRead)
+	g.POST("/notifications/mark-read", h.MarkAllRead)
+	g.DELETE("/notifications/:id", h.Dismiss)
+}
+
+// List returns the notification inbox.
+// @Summary List notifications
+// @Description Get the inbox of background work outcomes, newest first: OPML imports that finished and tasks that failed. The newest 100 are kept. New ones are also sent as notification events on /events.
+// @Tags notifications
+// @Produce json
+// @Param unreadOnly query bool false "Only return unread notifications"
+// @Param limit query int false "Limit the number of notifications (1-100, default 50)"
+// @Success 200 {object} notificationListResponse
+// @Failure 400 {object} errorResponse
+// @Router /notifications [get]
+func (h *NotificationHandler) List(c echo.Context) error {
+	var v validator
+	limit := v.queryInt(c, "limit", defaultNotificationLimit)
+	v.intRange("limit", limit, 1, maxNotificationLimit)
+	if v.failed() {
+		return v.write(c)
+	}
+
+	ctx := c.Request().Context()
+	notifications, err := h.service.List(ctx, c.QueryParam("unreadOnly") == "true", limit)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	unread, err := h.service.UnreadCount(ctx)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+
+	resp := notificationListResponse{
+		Notifications: make([]notificationResponse, 0, len(notifications)),
+		Unread:        unread,
+	}
+	for _, notification := range notifications {
+		resp.Notifications = append(resp.Notifications, toNotificationResponse(notification))
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// MarkRead marks a notification as read.
+// @Summary Mark a notification read
+// @Tags notifications
+// @Param id path int true "Notification ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	if err := h.service.MarkRead(c.Request().Context(), id); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MarkAllRead marks every notification as read.
+// @Summary Mark all notifications read
+// @Tags notifications
+// @Success 204 "No Content"
+// @Failure 500 {object} errorResponse
+// @Router /notifications/mark-read [post]
+func (h *NotificationHandler) MarkAllRead(c echo.Context) error {
+	if err := h.service.MarkAllRead(c.Request().Context()); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Dismiss removes a notification from the inbox.
+// @Summary Dismiss a notification
+// @Tags notifications
+// @Param id path int true "Notification ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /notifications/{id} [delete]
+func (h *NotificationHandler) Dismiss(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	if err := h.service.Dismiss(c.Request().Context(), id); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func toNotificationResponse(notification model.Notification) notificationResponse {
+	return notificationResponse{
+		ID:        idToString(notification.ID),
+		Kind:      notification.Kind,
+		TaskKind:  notification.TaskKind,
+		FeedID:    idPtrToString(notification.FeedID),
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Read:      notification.Read,
+		CreatedAt: notification.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/opds"
+	"gist/backend/internal/service"
+)
+
+type OPDSHandler struct {
+	service service.OPDSService
+}
+
+func NewOPDSHandler(opdsService service.OPDSService) *OPDSHandler {
+	return &OPDSHandler{service: opdsService}
+}
+
+func (h *OPDSHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/opds", h.Starred)
+	g.GET("/opds/entries/:file", h.EPUB)
+}
+
+// Starred serves the OPDS catalog of starred entries.
+// @Summary OPDS catalog of starred entries
+// @Description OPDS 1.2 acquisition feed of starred entries, newest first, 50 per page with next/previous links. Add this URL to an e-reader app such as KOReader to browse saved articles and download them as EPUB books.
+// @Tags opds
+// @Produce xml
+// @Param page query int false "Page number, from 1"
+// @Success 200 {string} string "OPDS acquisition feed"
+// @Failure 400 {object} errorResponse
+// @Router /opds [get]
+func (h *OPDSHandler) Starred(c echo.Context) error {
+	var v validator
+	page := v.queryInt(c, "page", 1)
+	v.minInt("page", page, 1)
+	if v.failed() {
+		return v.write(c)
+	}
+
+	payload, err := h.service.Starred(c.Request().Context(), page)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Blob(http.StatusOK, opds.AcquisitionType, payload)
+}
+
+// EPUB converts an entry to an EPUB book.
+// @Summary Download an entry as EPUB
+// @Description Convert an entry to an EPUB 3 book on the fly, using its readable content when extracted and the feed content otherwise. Images and embedded media are left out.
+// @Tags opds
+// @Produce application/epub+zip
+// @Param file path string true "Entry ID followed by .epub"
+// @Success 200 {file} file "EPUB book"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /opds/entries/{file} [get]
+func (h *OPDSHandler) EPUB(c echo.Context) error {
+	id, err := strconv.ParseInt(strings.TrimSuffix(c.Param("file"), ".epub"), 10, 64)
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+
+	book, name, err := h.service.EPUB(c.Request().Context(), id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	c.Response().Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	return c.Blob(http.StatusOK, opds.EPUBType, book)
+}
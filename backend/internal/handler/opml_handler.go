@@ -1,12 +1,10 @@
 package handler
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -14,17 +12,30 @@ import (
 	"gist/backend/internal/service"
 )
 
-const maxOPMLSize = 5 << 20
+// opmlMediaTypes are the accepted types for an uploaded OPML file. Browsers
+// rarely know .opml, so generic types are accepted and the parser decides.
+var opmlMediaTypes = map[string]bool{
+	"application/xml":          true,
+	"text/xml":                 true,
+	"text/x-opml":              true,
+	"application/x-opml":       true,
+	"text/plain":               true,
+	"application/octet-stream": true,
+}
 
 type OPMLHandler struct {
-	service     service.OPMLService
-	taskManager service.ImportTaskService
+	service       service.OPMLService
+	imports       service.ImportTaskService
+	tasks         service.TaskRunner
+	maxUploadSize int64
 }
 
-func NewOPMLHandler(opmlService service.OPMLService, taskManager service.ImportTaskService) *OPMLHandler {
+func NewOPMLHandler(opmlService service.OPMLService, imports service.ImportTaskService, tasks service.TaskRunner, maxUploadSize int64) *OPMLHandler {
 	return &OPMLHandler{
-		service:     opmlService,
-		taskManager: taskManager,
+		service:       opmlService,
+		imports:       imports,
+		tasks:         tasks,
+		maxUploadSize: maxUploadSize,
 	}
 }
 
@@ -32,99 +43,117 @@ func (h *OPMLHandler) RegisterRoutes(g *echo.Group) {
 	g.POST("/opml/import", h.Import)
 	g.DELETE("/opml/import", h.CancelImport)
 	g.GET("/opml/import/status", h.ImportStatus)
+	g.GET("/opml/import/:taskId", h.GetImport)
+	g.DELETE("/opml/import/:taskId", h.CancelImportTask)
 	g.GET("/opml/export", h.Export)
+	g.POST("/opml/imports/:taskId/undo", h.UndoImport)
 }
 
 // Import imports subscriptions from an OPML file.
 // @Summary Import OPML
-// @Description Start importing feeds and folders from an OPML file
+// @Description Validate an OPML file and start importing its feeds and folders in the background.
+// @Description The file is parsed while it streams in; malformed documents are rejected before the task starts.
+// @Description With dryRun=true the folders and feeds that would be created, skipped duplicates and type conflicts are returned instead.
+// @Description The returned taskId follows the import with GET /opml/import/{taskId} and cancels it with DELETE /opml/import/{taskId}. Progress is saved after every feed, and an import the server stopped in the middle of resumes from there on the next start.
 // @Tags opml
 // @Accept multipart/form-data
 // @Accept xml
 // @Produce json
 // @Param file formData file false "OPML file to import"
+// @Param dryRun query bool false "Only report what would be imported, without writing anything"
 // @Success 200 {object} importStartedResponse
+// @Success 200 {object} service.ImportPreview "When dryRun is true"
 // @Failure 400 {object} errorResponse
 // @Failure 413 {object} errorResponse
+// @Failure 415 {object} errorResponse
+// @Failure 409 {object} errorResponse "Another import is running"
 // @Router /opml/import [post]
 func (h *OPMLHandler) Import(c echo.Context) error {
-	req := c.Request()
-	req.Body = http.MaxBytesReader(c.Response().Writer, req.Body, maxOPMLSize)
+	reader, err := openUpload(c, h.maxUploadSize, opmlMediaTypes)
+	if err != nil {
+		return writeUploadError(c, err)
+	}
 
-	var reader io.Reader
-	contentType := req.Header.Get("Content-Type")
-	if strings.HasPrefix(contentType, "multipart/") {
-		file, err := c.FormFile("file")
-		if err != nil {
-			if err == http.ErrMissingFile {
-				return c.JSON(http.StatusBadRequest, errorResponse{Error: "missing file"})
-			}
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
-		}
-		if file.Size > maxOPMLSize {
-			return c.JSON(http.StatusRequestEntityTooLarge, errorResponse{Error: "file too large"})
-		}
-		src, err := file.Open()
+	doc, err := h.service.Parse(reader)
+	if err != nil {
+		return writeUploadError(c, err)
+	}
+
+	if c.QueryParam("dryRun") == "true" {
+		preview, err := h.service.Preview(c.Request().Context(), doc)
 		if err != nil {
-			return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+			return writeServiceError(c, err)
 		}
-		defer src.Close()
-		reader = io.LimitReader(src, maxOPMLSize)
-	} else {
-		reader = io.LimitReader(req.Body, maxOPMLSize)
+		return c.JSON(http.StatusOK, preview)
 	}
 
-	// Read file content into memory for background processing
-	content, err := io.ReadAll(reader)
+	task, err := h.imports.Start(c.Request().Context(), doc)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "read file failed"})
+		if errors.Is(err, service.ErrTaskRunning) {
+			return Error(c, CodeImportInProgress, "an import is already running")
+		}
+		return writeServiceError(c, err)
 	}
 
-	// Start background import
-	go h.runImport(content)
-
-	return c.JSON(http.StatusOK, importStartedResponse{Status: "started"})
+	return c.JSON(http.StatusOK, importStartedResponse{Status: "started", TaskID: task.ID})
 }
 
-func (h *OPMLHandler) runImport(content []byte) {
-	reader := bytes.NewReader(content)
-
-	// Pre-count total feeds for progress
-	preReader := bytes.NewReader(content)
-	total := h.countFeedsInOPML(preReader)
-
-	// Start task and get cancellable context
-	_, ctx := h.taskManager.Start(total)
-
-	onProgress := func(p service.ImportProgress) {
-		h.taskManager.Update(p.Current, p.Feed)
+// GetImport returns the progress of an import task.
+// @Summary Get import
+// @Description Get an import task by the ID the import returned: live progress while it runs, and its saved state once it has ended, also after a restart. current counts the feeds processed; result holds the counts of a finished import.
+// @Tags opml
+// @Produce json
+// @Param taskId path string true "Import task ID"
+// @Success 200 {object} service.Task
+// @Failure 404 {object} errorResponse
+// @Router /opml/import/{taskId} [get]
+func (h *OPMLHandler) GetImport(c echo.Context) error {
+	task, err := h.imports.Get(c.Request().Context(), c.Param("taskId"))
+	if err != nil {
+		return writeServiceError(c, err)
 	}
+	return c.JSON(http.StatusOK, task)
+}
 
-	result, err := h.service.Import(ctx, reader, onProgress)
+// CancelImportTask cancels an import task.
+// @Summary Cancel import task
+// @Description Stop an import task by ID. Feeds and folders it created so far are kept and can be removed with POST /opml/imports/{taskId}/undo. cancelled is false when the task had already ended.
+// @Tags opml
+// @Produce json
+// @Param taskId path string true "Import task ID"
+// @Success 200 {object} importCancelledResponse
+// @Failure 404 {object} errorResponse
+// @Router /opml/import/{taskId} [delete]
+func (h *OPMLHandler) CancelImportTask(c echo.Context) error {
+	cancelled, err := h.imports.Cancel(c.Request().Context(), c.Param("taskId"))
 	if err != nil {
-		// Check if cancelled
-		if ctx.Err() != nil {
-			return // Already marked as cancelled
-		}
-		h.taskManager.Fail(err)
-		return
+		return writeServiceError(c, err)
 	}
+	return c.JSON(http.StatusOK, importCancelledResponse{Cancelled: cancelled})
+}
 
-	// Check if cancelled before marking complete
-	if ctx.Err() != nil {
-		return
+// UndoImport removes everything an import task created.
+// @Summary Undo import
+// @Description Delete the feeds and folders created by an OPML import task.
+// @Description Folders that now contain feeds or subfolders from elsewhere are kept.
+// @Tags opml
+// @Produce json
+// @Param taskId path string true "Import task ID"
+// @Success 200 {object} service.ImportUndoResult
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Router /opml/imports/{taskId}/undo [post]
+func (h *OPMLHandler) UndoImport(c echo.Context) error {
+	taskID := c.Param("taskId")
+	if task, ok := h.tasks.Get(taskID); ok && task.Status == service.TaskRunning {
+		return Error(c, CodeImportInProgress, "import still running")
 	}
-	h.taskManager.Complete(result)
-}
 
-func (h *OPMLHandler) countFeedsInOPML(reader io.Reader) int {
-	// Simple count by parsing - if fails, return 0
-	content, err := io.ReadAll(reader)
+	result, err := h.service.Undo(c.Request().Context(), taskID)
 	if err != nil {
-		return 0
+		return writeServiceError(c, err)
 	}
-	// Count occurrences of xmlUrl attribute (rough estimate)
-	return bytes.Count(bytes.ToLower(content), []byte("xmlurl"))
+	return c.JSON(http.StatusOK, result)
 }
 
 // CancelImport cancels the current import task.
@@ -135,7 +164,13 @@ func (h *OPMLHandler) countFeedsInOPML(reader io.Reader) int {
 // @Success 200 {object} importCancelledResponse
 // @Router /opml/import [delete]
 func (h *OPMLHandler) CancelImport(c echo.Context) error {
-	cancelled := h.taskManager.Cancel()
+	cancelled := false
+	if task, ok := h.tasks.Latest(service.TaskImport); ok {
+		var err error
+		if cancelled, err = h.imports.Cancel(c.Request().Context(), task.ID); err != nil {
+			return writeServiceError(c, err)
+		}
+	}
 	return c.JSON(http.StatusOK, importCancelledResponse{Cancelled: cancelled})
 }
 
@@ -144,7 +179,7 @@ func (h *OPMLHandler) CancelImport(c echo.Context) error {
 // @Description Get current import task status via SSE stream
 // @Tags opml
 // @Produce text/event-stream
-// @Success 200 {object} service.ImportTask
+// @Success 200 {object} service.Task
 // @Router /opml/import/status [get]
 func (h *OPMLHandler) ImportStatus(c echo.Context) error {
 	res := c.Response()
@@ -165,11 +200,11 @@ func (h *OPMLHandler) ImportStatus(c echo.Context) error {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			task := h.taskManager.Get()
+			task, ok := h.tasks.Latest(service.TaskImport)
 			h.sendTaskStatus(res)
 
-			// Stop streaming if task is done, error, or cancelled
-			if task != nil && (task.Status == "done" || task.Status == "error" || task.Status == "cancelled") {
+			// Stop streaming once the task has finished
+			if ok && task.Status != service.TaskRunning {
 				return nil
 			}
 		}
@@ -177,8 +212,8 @@ func (h *OPMLHandler) ImportStatus(c echo.Context) error {
 }
 
 func (h *OPMLHandler) sendTaskStatus(res *echo.Response) {
-	task := h.taskManager.Get()
-	if task == nil {
+	task, ok := h.tasks.Latest(service.TaskImport)
+	if !ok {
 		data, _ := json.Marshal(importIdleResponse{Status: "idle"})
 		fmt.Fprintf(res, "data: %s\n\n", data)
 	} else {
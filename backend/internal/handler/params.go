@@ -9,7 +9,3 @@ import (
 func parseIDParam(c echo.Context, name string) (int64, error) {
 	return strconv.ParseInt(c.Param(name), 10, 64)
 }
-
-func isValidContentType(t string) bool {
-	return t == "article" || t == "picture" || t == "notification"
-}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/plainview"
+	"gist/backend/internal/service"
+)
+
+// maxPlainWidth caps the column article text wraps at.
+const maxPlainWidth = 1000
+
+type PlainHandler struct {
+	service service.PlainService
+}
+
+func NewPlainHandler(plainService service.PlainService) *PlainHandler {
+	return &PlainHandler{service: plainService}
+}
+
+// RegisterRoutes registers the plain-text routes. They are meant for shell
+// scripts rather than the app, so they require the API token.
+func (h *PlainHandler) RegisterRoutes(g *echo.Group, auth echo.MiddlewareFunc) {
+	g.GET("/plain/entries", h.Entries, auth)
+	g.GET("/plain/entries/:id", h.Entry, auth)
+}
+
+// Entries lists entries as plain text.
+// @Summary Plain-text entry list
+// @Description List entries as plain text for terminals and scripts, newest first, e.g. curl -H "X-Gist-Token: …" <site>/api/plain/entries?unreadOnly=true. Each entry is a block of lines: "[id] title", then feed, date (UTC), unread and starred, then the link; blocks are separated by a blank line. When more entries follow, the last line is "Next: " and the path of the next page. Takes the same filters as the entry list. Requires the API token as a Bearer token or X-Gist-Token header.
+// @Tags entries
+// @Produce plain
+// @Security ApiToken
+// @Param feedId query int false "Filter by feed ID"
+// @Param folderId query int false "Filter by folder ID"
+// @Param contentType query string false "Filter by content type (article, picture, notification, podcast)"
+// @Param unreadOnly query bool false "Only unread entries"
+// @Param starredOnly query bool false "Only starred entries"
+// @Param period query string false "Only entries published in this UTC year (YYYY) or month (YYYY-MM)"
+// @Param excludeNsfw query bool false "Leave out entries flagged as not safe for work"
+// @Param tagId query int false "Filter by tag ID"
+// @Param limit query int false "Number of entries (1-100, default 50)"
+// @Param after query string false "Continue after this cursor (publishedAt,id), from the Next line"
+// @Success 200 {string} string "Entry list"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /plain/entries [get]
+func (h *PlainHandler) Entries(c echo.Context) error {
+	var v validator
+	params := parseEntryScope(c, &v)
+	params.Limit = v.queryInt(c, "limit", defaultEntryLimit)
+	params.After = c.QueryParam("after")
+	v.intRange("limit", params.Limit, 1, maxEntryLimit)
+	if params.After != "" {
+		if _, err := service.ParseEntryCursor(params.After); err != nil {
+			v.fail("after", fieldInvalidFormat, "must be a publishedAt,id cursor")
+		}
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	text, err := h.service.Entries(c.Request().Context(), params, c.QueryParams())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, text)
+}
+
+// Entry returns an entry as plain text.
+// @Summary Plain-text entry
+// @Description Get an entry as plain text for terminals and scripts: the title, a line with feed, author, date (UTC), unread and starred, the link, then the text of its readable content when extracted and the feed content otherwise, a paragraph per block wrapped at width columns. Requires the API token as a Bearer token or X-Gist-Token header.
+// @Tags entries
+// @Produce plain
+// @Security ApiToken
+// @Param id path int true "Entry ID"
+// @Param width query int false "Column to wrap text at (0 for none, default 80)"
+// @Success 200 {string} string "Entry text"
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /plain/entries/{id} [get]
+func (h *PlainHandler) Entry(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	var v validator
+	width := v.queryInt(c, "width", plainview.DefaultWidth)
+	v.intRange("width", width, 0, maxPlainWidth)
+	if v.failed() {
+		return v.write(c)
+	}
+
+	text, err := h.service.Entry(c.Request().Context(), id, width)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Blob(http.StatusOK, echo.MIMETextPlainCharsetUTF8, text)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service"
+)
+
+type PlaybackHandler struct {
+	service service.PlaybackService
+}
+
+type playbackRequest struct {
+	PositionSeconds int64 `json:"positionSeconds"`
+}
+
+// playbackResponse is where playback of an entry stopped. updatedAt is
+// omitted when the entry was never played.
+type playbackResponse struct {
+	EntryID         string     `json:"entryId"`
+	PositionSeconds int64      `json:"positionSeconds"`
+	UpdatedAt       *time.Time `json:"updatedAt,omitempty"`
+}
+
+func NewPlaybackHandler(playback service.PlaybackService) *PlaybackHandler {
+	return &PlaybackHandler{service: playback}
+}
+
+func (h *PlaybackHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/entries/:id/playback", h.Get)
+	g.PUT("/entries/:id/playback", h.Update)
+}
+
+// Get returns where playback of an entry stopped.
+// @Summary Get playback position
+// @Description Get where playback of the entry's attachment (such as a podcast episode's audio) stopped, in seconds from the start; 0 when it was never played.
+// @Tags entries
+// @Produce json
+// @Param id path int true "Entry ID"
+// @Success 200 {object} playbackResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /entries/{id}/playback [get]
+func (h *PlaybackHandler) Get(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	position, err := h.service.Get(c.Request().Context(), id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toPlaybackResponse(position))
+}
+
+// Update stores where playback of an entry stopped.
+// @Summary Update playback position
+// @Description Store where playback of the entry's attachment stopped, in seconds from the start, so it resumes there on any device. Players save it periodically while playing and on pause.
+// @Tags entries
+// @Accept json
+// @Produce json
+// @Param id path int true "Entry ID"
+// @Param playback body playbackRequest true "Playback position"
+// @Success 200 {object} playbackResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /entries/{id}/playback [put]
+func (h *PlaybackHandler) Update(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	var req playbackRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if req.PositionSeconds < 0 {
+		v.fail("positionSeconds", fieldOutOfRange, "must be at least 0")
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	position, err := h.service.Set(c.Request().Context(), id, req.PositionSeconds)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toPlaybackResponse(position))
+}
+
+func toPlaybackResponse(position model.PlaybackPosition) playbackResponse {
+	resp := playbackResponse{
+		EntryID:         idToString(position.EntryID),
+		PositionSeconds: position.PositionSeconds,
+	}
+	if !position.UpdatedAt.IsZero() {
+		resp.UpdatedAt = &position.UpdatedAt
+	}
+	return resp
+}
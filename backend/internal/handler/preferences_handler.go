@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+type PreferencesHandler struct {
+	service service.PreferencesService
+}
+
+func NewPreferencesHandler(preferences service.PreferencesService) *PreferencesHandler {
+	return &PreferencesHandler{service: preferences}
+}
+
+func (h *PreferencesHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/preferences", h.Get)
+	g.PUT("/preferences", h.Update)
+}
+
+// Get returns the stored preferences.
+// @Summary Get preferences
+// @Description Get the user preferences stored by the frontend, such as shortcut remaps (shortcuts) and gestures (gestures), as an object of JSON values by key.
+// @Tags settings
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} errorResponse
+// @Router /preferences [get]
+func (h *PreferencesHandler) Get(c echo.Context) error {
+	prefs, err := h.service.Get(c.Request().Context())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// Update stores preferences.
+// @Summary Update preferences
+// @Description Store the given preferences, leaving those not listed as they are; null deletes one. Keys are dot-separated lowercase segments of at most 64 characters. A value is at most 16 KiB of JSON; shortcuts and gestures must be objects of strings. All preferences together are at most 200 keys and 256 KiB.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Param preferences body map[string]interface{} true "Preferences to store"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} errorResponse
+// @Failure 413 {object} errorResponse
+// @Router /preferences [put]
+func (h *PreferencesHandler) Update(c echo.Context) error {
+	body := http.MaxBytesReader(c.Response(), c.Request().Body, service.MaxPreferencesSize)
+	var changes map[string]json.RawMessage
+	if err := json.NewDecoder(body).Decode(&changes); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return Error(c, CodePreferencesTooLarge, "preferences too large")
+		}
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	for _, key := range slices.Sorted(maps.Keys(changes)) {
+		if err := service.CheckPreference(key, changes[key]); err != nil {
+			v.fail(key, fieldInvalidFormat, err.Error())
+		}
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	prefs, err := h.service.Update(c.Request().Context(), changes)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 
 	"github.com/labstack/echo/v4"
 
@@ -14,22 +15,27 @@ import (
 const cacheMaxAge = 86400 // 1 day
 
 type ProxyHandler struct {
-	proxyService service.ProxyService
+	proxyService     service.ProxyService
+	thumbnailService service.ThumbnailService
 }
 
-func NewProxyHandler(proxyService service.ProxyService) *ProxyHandler {
+func NewProxyHandler(proxyService service.ProxyService, thumbnailService service.ThumbnailService) *ProxyHandler {
 	return &ProxyHandler{
-		proxyService: proxyService,
+		proxyService:     proxyService,
+		thumbnailService: thumbnailService,
 	}
 }
 
 func (h *ProxyHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/proxy/image", h.ProxyImageByURL)
 	g.GET("/proxy/image/:encoded", h.ProxyImage)
+	g.GET("/proxy/thumbnail/:encoded", h.ProxyThumbnail)
+	g.GET("/thumbnails/:entryId", h.EntryThumbnail)
 }
 
 // ProxyImage godoc
 // @Summary Proxy external image
-// @Description Proxies external images to avoid triggering anti-crawling mechanisms
+// @Description Proxies external images to avoid triggering anti-crawling mechanisms. Images are cached on disk (media/images, GIST_IMAGE_CACHE_MB) and fetched through the network proxy set by HTTP_PROXY/HTTPS_PROXY.
 // @Tags proxy
 // @Produce octet-stream
 // @Param encoded path string true "Base64 URL-safe encoded image URL"
@@ -40,31 +46,130 @@ func (h *ProxyHandler) RegisterRoutes(g *echo.Group) {
 // @Failure 504 {object} errorResponse
 // @Router /api/proxy/image/{encoded} [get]
 func (h *ProxyHandler) ProxyImage(c echo.Context) error {
+	imageURL, refererURL, ok, err := decodeImageParams(c)
+	if !ok {
+		return err
+	}
+
+	return h.proxyImage(c, imageURL, refererURL)
+}
+
+// ProxyImageByURL godoc
+// @Summary Proxy external image by URL
+// @Description Same as /api/proxy/image/{encoded} with plain query parameters. Entry content asked for with proxyImages=true points its images here.
+// @Tags proxy
+// @Produce octet-stream
+// @Param url query string true "Image URL"
+// @Param ref query string false "Article URL (used as Referer for CDN anti-hotlinking)"
+// @Success 200 {file} binary
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure 504 {object} errorResponse
+// @Router /api/proxy/image [get]
+func (h *ProxyHandler) ProxyImageByURL(c echo.Context) error {
+	imageURL := c.QueryParam("url")
+	if imageURL == "" {
+		return Error(c, CodeMissingField, "URL is required")
+	}
+	return h.proxyImage(c, imageURL, c.QueryParam("ref"))
+}
+
+func (h *ProxyHandler) proxyImage(c echo.Context, imageURL, refererURL string) error {
+	result, err := h.proxyService.CachedImage(c.Request().Context(), imageURL, refererURL)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+	return writeImage(c, result)
+}
+
+// ProxyThumbnail godoc
+// @Summary Proxy external image as a grid thumbnail
+// @Description Proxies an external image scaled down for the picture grid. Thumbnails are cached on disk, and pre-generated after refresh for picture folders that enable it.
+// @Tags proxy
+// @Produce octet-stream
+// @Param encoded path string true "Base64 URL-safe encoded image URL"
+// @Param ref query string false "Base64 URL-safe encoded article URL (used as Referer for CDN anti-hotlinking)"
+// @Success 200 {file} binary
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure 504 {object} errorResponse
+// @Router /api/proxy/thumbnail/{encoded} [get]
+func (h *ProxyHandler) ProxyThumbnail(c echo.Context) error {
+	imageURL, refererURL, ok, err := decodeImageParams(c)
+	if !ok {
+		return err
+	}
+
+	result, err := h.thumbnailService.Get(c.Request().Context(), imageURL, refererURL)
+	if err != nil {
+		return h.handleServiceError(c, err)
+	}
+	return writeImage(c, result)
+}
+
+// EntryThumbnail godoc
+// @Summary Entry thumbnail
+// @Description Returns the thumbnail image of an entry scaled down to w pixels wide (320 or 640, default 320), generated from thumbnailUrl on first use and cached on disk (media/thumbnails). Large JPEG and PNG originals are scaled to JPEG, or PNG when transparent; narrower images and other formats are served as they are. Entry lists link it as thumbnailProxyUrl.
+// @Tags proxy
+// @Produce octet-stream
+// @Param entryId path int true "Entry ID"
+// @Param w query int false "Width: 320 or 640"
+// @Success 200 {file} binary
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure 504 {object} errorResponse
+// @Router /api/thumbnails/{entryId} [get]
+func (h *ProxyHandler) EntryThumbnail(c echo.Context) error {
+	entryID, err := parseIDParam(c, "entryId")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+
+	var v validator
+	width := v.queryInt(c, "w", service.DefaultThumbnailWidth)
+	if !v.failed() && !slices.Contains(service.ThumbnailWidths, width) {
+		v.fail("w", fieldInvalidEnum, fmt.Sprintf("must be one of %v", service.ThumbnailWidths))
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	result, err := h.thumbnailService.ForEntry(c.Request().Context(), entryID, width)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) || errors.Is(err, service.ErrInvalid) {
+			return writeServiceError(c, err)
+		}
+		return h.handleServiceError(c, err)
+	}
+	return writeImage(c, result)
+}
+
+// decodeImageParams reads the encoded image URL and optional referer. When
+// ok is false, the error response has been written and err is its result.
+func decodeImageParams(c echo.Context) (imageURL, refererURL string, ok bool, err error) {
 	encoded := c.Param("encoded")
 	if encoded == "" {
-		return Error(c, http.StatusBadRequest, "URL is required")
+		return "", "", false, Error(c, CodeMissingField, "URL is required")
 	}
 
 	// Decode Base64 URL-safe
-	decoded, err := base64.URLEncoding.DecodeString(encoded)
-	if err != nil {
-		return Error(c, http.StatusBadRequest, "Invalid encoding")
+	decoded, decodeErr := base64.URLEncoding.DecodeString(encoded)
+	if decodeErr != nil {
+		return "", "", false, Error(c, CodeInvalidURL, "Invalid encoding")
 	}
-	imageURL := string(decoded)
+	imageURL = string(decoded)
 
 	// Decode referer URL if provided (for CDN anti-hotlinking)
-	var refererURL string
 	if refEncoded := c.QueryParam("ref"); refEncoded != "" {
 		if refDecoded, err := base64.URLEncoding.DecodeString(refEncoded); err == nil {
 			refererURL = string(refDecoded)
 		}
 	}
+	return imageURL, refererURL, true, nil
+}
 
-	result, err := h.proxyService.FetchImage(c.Request().Context(), imageURL, refererURL)
-	if err != nil {
-		return h.handleServiceError(c, err)
-	}
-
+func writeImage(c echo.Context, result *service.ProxyResult) error {
 	c.Response().Header().Set("Content-Type", result.ContentType)
 	c.Response().Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", cacheMaxAge))
 	c.Response().Header().Set("X-Content-Type-Options", "nosniff")
@@ -75,12 +180,12 @@ func (h *ProxyHandler) ProxyImage(c echo.Context) error {
 func (h *ProxyHandler) handleServiceError(c echo.Context, err error) error {
 	switch {
 	case errors.Is(err, service.ErrInvalidURL):
-		return Error(c, http.StatusBadRequest, "Invalid URL")
+		return Error(c, CodeInvalidURL, "Invalid URL")
 	case errors.Is(err, service.ErrInvalidProtocol):
-		return Error(c, http.StatusBadRequest, "Invalid protocol")
+		return Error(c, CodeInvalidURL, "Invalid protocol")
 	case errors.Is(err, service.ErrRequestTimeout):
-		return Error(c, http.StatusGatewayTimeout, "Request timeout")
+		return Error(c, CodeUpstreamTimeout, "Request timeout")
 	default:
-		return Error(c, http.StatusInternalServerError, "Failed to fetch image")
+		return Error(c, CodeImageFetchFailed, "Failed to fetch image")
 	}
 }
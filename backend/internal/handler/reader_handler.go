@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+// readerPolicy lets reader pages load their images, inline styles and post
+// their read form, as they are served from the app's origin.
+const readerPolicy = "default-src 'none'; img-src http: https: data:; style-src 'unsafe-inline'; form-action 'self'"
+
+type ReaderHandler struct {
+	service service.ReaderService
+}
+
+func NewReaderHandler(readerService service.ReaderService) *ReaderHandler {
+	return &ReaderHandler{service: readerService}
+}
+
+func (h *ReaderHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/reader", h.Unread)
+	g.GET("/reader/:id", h.Article)
+	g.POST("/reader/:id/read", h.MarkAsRead)
+}
+
+// Unread renders the unread entries as a plain HTML page.
+// @Summary Reader list of unread entries
+// @Description Server-rendered HTML page of unread entries, newest first, 30 per page with a link to older ones. It needs no scripts, for e-ink devices and text browsers such as w3m or lynx; each entry links to its article page.
+// @Tags reader
+// @Produce html
+// @Param after query string false "Continue after this cursor (publishedAt,id), from the page's link to older entries"
+// @Success 200 {string} string "HTML document"
+// @Failure 400 {object} errorResponse
+// @Router /reader [get]
+func (h *ReaderHandler) Unread(c echo.Context) error {
+	after := c.QueryParam("after")
+	if after != "" {
+		if _, err := service.ParseEntryCursor(after); err != nil {
+			var v validator
+			v.fail("after", fieldInvalidFormat, "must be a publishedAt,id cursor")
+			return v.write(c)
+		}
+	}
+
+	page, err := h.service.Unread(c.Request().Context(), after)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	c.Response().Header().Set("Content-Security-Policy", readerPolicy)
+	return c.HTMLBlob(http.StatusOK, page)
+}
+
+// Article renders an entry as a plain HTML page.
+// @Summary Reader article page
+// @Description Server-rendered HTML page of an entry: its readable content when extracted and the feed content otherwise, reduced as in the print view, with a form to mark it read that needs no scripts.
+// @Tags reader
+// @Produce html
+// @Param id path int true "Entry ID"
+// @Success 200 {string} string "HTML document"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /reader/{id} [get]
+func (h *ReaderHandler) Article(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+
+	page, err := h.service.Article(c.Request().Context(), id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	c.Response().Header().Set("Content-Security-Policy", readerPolicy)
+	return c.HTMLBlob(http.StatusOK, page)
+}
+
+// MarkAsRead marks an entry read from the reader's form.
+// @Summary Mark an entry read from the reader
+// @Description Form post of the reader article page. Marks the entry as read, or unread with read=false, and redirects to the reader list.
+// @Tags reader
+// @Accept x-www-form-urlencoded
+// @Param id path int true "Entry ID"
+// @Param read formData bool false "Read status (default true)"
+// @Success 303 "Redirect to the reader list"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /reader/{id}/read [post]
+func (h *ReaderHandler) MarkAsRead(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+
+	read := c.FormValue("read") != "false"
+	if err := h.service.MarkAsRead(c.Request().Context(), id, read); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.Redirect(http.StatusSeeOther, service.ReaderPath)
+}
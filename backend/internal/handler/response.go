@@ -1,9 +1,11 @@
 package handler
 
 import (
+	"context"
 	"errors"
-	"net/http"
+	"math"
 	"strconv"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
@@ -26,12 +28,17 @@ func idPtrToString(id *int64) *string {
 	return &s
 }
 
-type errorResponse struct {
-	Error string `json:"error"`
+// stringValue returns the string s points to, or "" for nil.
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
 }
 
 type importStartedResponse struct {
 	Status string `json:"status"`
+	TaskID string `json:"taskId"`
 }
 
 type importCancelledResponse struct {
@@ -43,22 +50,34 @@ type importIdleResponse struct {
 }
 
 func writeServiceError(c echo.Context, err error) error {
+	var cooling *service.HostCooldownError
 	switch {
 	case errors.Is(err, service.ErrInvalid):
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	case errors.Is(err, service.ErrNotFound):
-		return c.JSON(http.StatusNotFound, errorResponse{Error: "resource not found"})
+		return Error(c, CodeNotFound, "resource not found")
 	case errors.Is(err, service.ErrConflict):
-		return c.JSON(http.StatusConflict, errorResponse{Error: "conflict"})
+		return Error(c, CodeConflict, "conflict")
 	case errors.Is(err, service.ErrFeedFetch):
-		return c.JSON(http.StatusBadGateway, errorResponse{Error: "feed fetch failed"})
+		return Error(c, CodeFeedFetchFailed, "feed fetch failed")
+	case errors.As(err, &cooling):
+		wait := max(int(math.Ceil(time.Until(cooling.Until).Seconds())), 1)
+		c.Response().Header().Set("Retry-After", strconv.Itoa(wait))
+		return Error(c, CodeHostCoolingDown, cooling.Error())
+	case errors.Is(err, service.ErrIntegrationNotConfigured):
+		return Error(c, CodeIntegrationNotConfigured, "read-later service is not configured")
+	case errors.Is(err, service.ErrIntegrationFailed):
+		c.Logger().Warn(err)
+		return Error(c, CodeIntegrationFailed, "read-later service request failed")
+	case errors.Is(err, service.ErrPreferencesFull):
+		return Error(c, CodePreferencesTooLarge, "preferences too large")
+	case errors.Is(err, context.DeadlineExceeded):
+		return Error(c, CodeRequestTimeout, "request timed out")
+	case errors.Is(err, context.Canceled):
+		// The client disconnected; nobody is left to read a response
+		return nil
 	default:
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, errorResponse{Error: "internal error"})
+		return Error(c, CodeInternal, "internal error")
 	}
 }
-
-// Error returns a JSON error response with the given status and message
-func Error(c echo.Context, status int, message string) error {
-	return c.JSON(status, errorResponse{Error: message})
-}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/scheduler"
+)
+
+type SchedulerHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+func NewSchedulerHandler(sched *scheduler.Scheduler) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: sched}
+}
+
+func (h *SchedulerHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/scheduler", h.List)
+	g.POST("/scheduler/:kind/pause", h.Pause)
+	g.POST("/scheduler/:kind/resume", h.Resume)
+}
+
+// List returns the scheduled jobs.
+// @Summary List scheduled jobs
+// @Description Get each background job (refresh, link_check, and sync on a secondary) with its interval, pause state, skip reason (e.g. low-data mode), next run and the outcome and duration of its latest run
+// @Tags scheduler
+// @Produce json
+// @Success 200 {array} scheduler.JobStatus
+// @Router /scheduler [get]
+func (h *SchedulerHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.scheduler.Jobs(c.Request().Context()))
+}
+
+// Pause stops a job from running on schedule.
+// @Summary Pause a scheduled job
+// @Description Stop starting the job on its schedule, e.g. to avoid refreshing on a metered connection. A run in progress is not cancelled and manual runs still work. Pauses last until resumed or the server restarts.
+// @Tags scheduler
+// @Produce json
+// @Param kind path string true "Job kind (refresh, link_check, sync)"
+// @Success 200 {object} scheduler.JobStatus
+// @Failure 404 {object} errorResponse
+// @Router /scheduler/{kind}/pause [post]
+func (h *SchedulerHandler) Pause(c echo.Context) error {
+	status, ok := h.scheduler.Pause(c.Request().Context(), c.Param("kind"))
+	if !ok {
+		return Error(c, CodeNotFound, "scheduled job not found")
+	}
+	return c.JSON(http.StatusOK, status)
+}
+
+// Resume puts a paused job back on its schedule.
+// @Summary Resume a scheduled job
+// @Description Resume a paused job. It next runs at its regular interval.
+// @Tags scheduler
+// @Produce json
+// @Param kind path string true "Job kind (refresh, link_check, sync)"
+// @Success 200 {object} scheduler.JobStatus
+// @Failure 404 {object} errorResponse
+// @Router /scheduler/{kind}/resume [post]
+func (h *SchedulerHandler) Resume(c echo.Context) error {
+	status, ok := h.scheduler.Resume(c.Request().Context(), c.Param("kind"))
+	if !ok {
+		return Error(c, CodeNotFound, "scheduled job not found")
+	}
+	return c.JSON(http.StatusOK, status)
+}
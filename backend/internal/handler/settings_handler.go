@@ -6,6 +6,7 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"gist/backend/internal/service"
+	"gist/backend/internal/service/ai"
 )
 
 type SettingsHandler struct {
@@ -59,13 +60,79 @@ type aiTestResponse struct {
 }
 
 type generalSettingsResponse struct {
-	FallbackUserAgent string `json:"fallbackUserAgent"`
-	AutoReadability   bool   `json:"autoReadability"`
+	FallbackUserAgent    string `json:"fallbackUserAgent"`
+	AutoReadability      bool   `json:"autoReadability"`
+	LowData              bool   `json:"lowData"`
+	LowDataSchedule      string `json:"lowDataSchedule"`
+	LowDataActive        bool   `json:"lowDataActive"`
+	NSFWMode             string `json:"nsfwMode"`
+	NSFWKeywords         string `json:"nsfwKeywords"`
+	NSFWVisionCheck      bool   `json:"nsfwVisionCheck"`
+	CookieHosts          string `json:"cookieHosts"`
+	TLSFingerprints      string `json:"tlsFingerprints"`
+	IconSources          string `json:"iconSources"`
+	RetentionDays        int    `json:"retentionDays"`
+	RetentionMaxPerFeed  int    `json:"retentionMaxPerFeed"`
+	FeedDisableThreshold int    `json:"feedDisableThreshold"`
+	CategoryFolders      bool   `json:"categoryFolders"`
+	DuplicateTitles      string `json:"duplicateTitles"`
+	DuplicateTitleWindow int    `json:"duplicateTitleWindow"`
+	EmbedHosts           string `json:"embedHosts"`
+	FutureDates          string `json:"futureDates"`
+	ContentRefetchWindow int    `json:"contentRefetchWindow"`
+	BackupInterval       int    `json:"backupInterval"`
+	BackupKeep           int    `json:"backupKeep"`
 }
 
 type generalSettingsRequest struct {
 	FallbackUserAgent string `json:"fallbackUserAgent"`
 	AutoReadability   bool   `json:"autoReadability"`
+	// Low-data fields are kept as they are when omitted
+	LowData         *bool   `json:"lowData,omitempty"`
+	LowDataSchedule *string `json:"lowDataSchedule,omitempty"`
+	// NSFW fields are kept as they are when omitted
+	NSFWMode        *string `json:"nsfwMode,omitempty"`
+	NSFWKeywords    *string `json:"nsfwKeywords,omitempty"`
+	NSFWVisionCheck *bool   `json:"nsfwVisionCheck,omitempty"`
+	// CookieHosts is kept as it is when omitted
+	CookieHosts *string `json:"cookieHosts,omitempty"`
+	// TLSFingerprints is kept as it is when omitted
+	TLSFingerprints *string `json:"tlsFingerprints,omitempty"`
+	// IconSources is kept as it is when omitted
+	IconSources *string `json:"iconSources,omitempty"`
+	// Retention fields are kept as they are when omitted; 0 turns a rule off
+	RetentionDays       *int `json:"retentionDays,omitempty"`
+	RetentionMaxPerFeed *int `json:"retentionMaxPerFeed,omitempty"`
+	// Failed refreshes in a row before a feed is disabled; 0 never disables.
+	FeedDisableThreshold *int `json:"feedDisableThreshold,omitempty"`
+	// Files feeds added without a folder by their first category; kept as
+	// it is when omitted.
+	CategoryFolders *bool `json:"categoryFolders,omitempty"`
+	// Duplicate title fields are kept as they are when omitted
+	DuplicateTitles      *string `json:"duplicateTitles,omitempty"`
+	DuplicateTitleWindow *int    `json:"duplicateTitleWindow,omitempty"`
+	// EmbedHosts and FutureDates are kept as they are when omitted
+	EmbedHosts  *string `json:"embedHosts,omitempty"`
+	FutureDates *string `json:"futureDates,omitempty"`
+	// Hours to re-fetch missing content for, kept when omitted; 0 turns it off
+	ContentRefetchWindow *int `json:"contentRefetchWindow,omitempty"`
+	// Backup fields are kept as they are when omitted; an interval of 0
+	// turns scheduled backups off
+	BackupInterval *int `json:"backupInterval,omitempty"`
+	BackupKeep     *int `json:"backupKeep,omitempty"`
+}
+
+type appearanceSettingsRequest struct {
+	// Fields are kept as they are when omitted
+	Theme       *string `json:"theme,omitempty"`
+	AccentColor *string `json:"accentColor,omitempty"`
+	FontSize    *int    `json:"fontSize,omitempty"`
+	// Content types left out keep their view
+	DefaultViews map[string]string `json:"defaultViews,omitempty"`
+}
+
+type lowDataRequest struct {
+	Enabled *bool `json:"enabled"`
 }
 
 func NewSettingsHandler(service service.SettingsService) *SettingsHandler {
@@ -78,6 +145,9 @@ func (h *SettingsHandler) RegisterRoutes(g *echo.Group) {
 	g.POST("/settings/ai/test", h.TestAI)
 	g.GET("/settings/general", h.GetGeneralSettings)
 	g.PUT("/settings/general", h.UpdateGeneralSettings)
+	g.PUT("/settings/low-data", h.SetLowData)
+	g.GET("/settings/appearance", h.GetAppearanceSettings)
+	g.PUT("/settings/appearance", h.UpdateAppearanceSettings)
 }
 
 // GetAISettings returns the AI configuration.
@@ -92,7 +162,7 @@ func (h *SettingsHandler) GetAISettings(c echo.Context) error {
 	settings, err := h.service.GetAISettings(c.Request().Context())
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, errorResponse{Error: "failed to get settings"})
+		return Error(c, CodeInternal, "failed to get settings")
 	}
 
 	return c.JSON(http.StatusOK, aiSettingsResponse{
@@ -124,7 +194,13 @@ func (h *SettingsHandler) GetAISettings(c echo.Context) error {
 func (h *SettingsHandler) UpdateAISettings(c echo.Context) error {
 	var req aiSettingsRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	validateAIConfig(&v, req.Provider, req.BaseURL, req.ThinkingBudget, req.ReasoningEffort)
+	v.minInt("rateLimit", req.RateLimit, 0)
+	if v.failed() {
+		return v.write(c)
 	}
 
 	settings := &service.AISettings{
@@ -143,7 +219,7 @@ func (h *SettingsHandler) UpdateAISettings(c echo.Context) error {
 
 	if err := h.service.SetAISettings(c.Request().Context(), settings); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, errorResponse{Error: "failed to save settings"})
+		return Error(c, CodeInternal, "failed to save settings")
 	}
 
 	// Return updated settings (with masked keys)
@@ -163,14 +239,15 @@ func (h *SettingsHandler) UpdateAISettings(c echo.Context) error {
 func (h *SettingsHandler) TestAI(c echo.Context) error {
 	var req aiTestRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
 	}
 
-	if req.Provider == "" {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "provider is required"})
-	}
-	if req.Model == "" {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "model is required"})
+	var v validator
+	v.required("provider", req.Provider)
+	v.required("model", req.Model)
+	validateAIConfig(&v, req.Provider, req.BaseURL, req.ThinkingBudget, req.ReasoningEffort)
+	if v.failed() {
+		return v.write(c)
 	}
 
 	response, err := h.service.TestAI(c.Request().Context(), req.Provider, req.APIKey, req.BaseURL, req.Model, req.Thinking, req.ThinkingBudget, req.ReasoningEffort)
@@ -189,7 +266,7 @@ func (h *SettingsHandler) TestAI(c echo.Context) error {
 
 // GetGeneralSettings returns the general settings.
 // @Summary Get general settings
-// @Description Get general application settings including fallback user agent and auto readability
+// @Description Get general application settings including fallback user agent, auto readability, low-data mode, NSFW filtering, the cookie allowlist, per-host TLS fingerprints and entry retention. lowDataActive reports whether low-data mode is in effect now, by switch or by schedule. nsfwMode (show/blur/hide) tells clients how to present flagged entries.
 // @Tags settings
 // @Produce json
 // @Success 200 {object} generalSettingsResponse
@@ -199,18 +276,38 @@ func (h *SettingsHandler) GetGeneralSettings(c echo.Context) error {
 	settings, err := h.service.GetGeneralSettings(c.Request().Context())
 	if err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, errorResponse{Error: "failed to get settings"})
+		return Error(c, CodeInternal, "failed to get settings")
 	}
 
 	return c.JSON(http.StatusOK, generalSettingsResponse{
-		FallbackUserAgent: settings.FallbackUserAgent,
-		AutoReadability:   settings.AutoReadability,
+		FallbackUserAgent:    settings.FallbackUserAgent,
+		AutoReadability:      settings.AutoReadability,
+		LowData:              settings.LowData,
+		LowDataSchedule:      settings.LowDataSchedule,
+		LowDataActive:        settings.LowDataActive,
+		NSFWMode:             settings.NSFWMode,
+		NSFWKeywords:         settings.NSFWKeywords,
+		NSFWVisionCheck:      settings.NSFWVisionCheck,
+		CookieHosts:          settings.CookieHosts,
+		TLSFingerprints:      settings.TLSFingerprints,
+		IconSources:          settings.IconSources,
+		RetentionDays:        settings.RetentionDays,
+		RetentionMaxPerFeed:  settings.RetentionMaxPerFeed,
+		FeedDisableThreshold: settings.FeedDisableThreshold,
+		CategoryFolders:      settings.CategoryFolders,
+		DuplicateTitles:      settings.DuplicateTitles,
+		DuplicateTitleWindow: settings.DuplicateTitleWindow,
+		EmbedHosts:           settings.EmbedHosts,
+		FutureDates:          settings.FutureDates,
+		ContentRefetchWindow: settings.ContentRefetchWindow,
+		BackupInterval:       settings.BackupInterval,
+		BackupKeep:           settings.BackupKeep,
 	})
 }
 
 // UpdateGeneralSettings updates the general settings.
 // @Summary Update general settings
-// @Description Update general application settings
+// @Description Update general application settings. lowData, lowDataSchedule, the nsfw fields, cookieHosts, tlsFingerprints, iconSources, the retention fields, feedDisableThreshold, categoryFolders, the duplicate title fields, embedHosts, futureDates, contentRefetchWindow and the backup fields keep their current values when omitted; an empty schedule removes it. nsfwKeywords holds comma- or line-separated keywords matched as whole words against titles and categories of new entries. cookieHosts lists the comma- or line-separated hosts (subdomains included) whose cookies are kept across fetches; the stored cookies of hosts taken off the list are deleted. tlsFingerprints holds comma- or line-separated host=browser pairs (browser: chrome, firefox, safari; subdomains included) for hosts whose feeds must be fetched with a browser's TLS and HTTP/2 fingerprint. iconSources is the comma-separated order feed icons are looked up in, from feed (the feed's image), site (the site's /favicon.ico), google and duckduckgo; sources left out are never used, and an empty list restores the default feed,site,google,duckduckgo. retentionDays and retentionMaxPerFeed (both kept when omitted, 0 turns the rule off) have the daily prune job delete unstarred entries fetched more than that many days ago or past the newest that many of their feed. feedDisableThreshold (default 10, 0 never disables) is the number of failed refreshes in a row after which a feed is disabled until re-enabled. categoryFolders files feeds added without a folder (one by one, in bulk or by OPML import) into a top-level folder named after the first category the feed declares, created when missing. duplicateTitles (off, exact or fuzzy; default off) has refresh drop new entries whose title repeats one of the same feed from the last duplicateTitleWindow hours (1 to 720, default 48): exact ignores case and spacing, fuzzy also punctuation and small differences. embedHosts lists the comma- or line-separated hosts (subdomains included, default youtube.com,youtube-nocookie.com,vimeo.com) whose iframes are kept when new entry content and readable content are sanitized before being stored; an empty list keeps none. futureDates (clamp, hide or keep; default clamp) is how new entries dated in the future are stored: clamp dates them when first seen and keeps the feed's date as originalPublishedAt, hide stores them only at a refresh after their date, keep stores the date as given. contentRefetchWindow (0 to 168 hours, default 0 for off) has refreshes of a feed fetch the page of its entries found without content within that many hours, a few per refresh, filling the content in place. backupInterval (0 to 720 hours, default 0 for off) has the backup job write a zip of the OPML export and the starred entries to the backups directory that many hours after the latest, keeping the newest backupKeep (1 to 100, default 7).
 // @Tags settings
 // @Accept json
 // @Produce json
@@ -222,18 +319,249 @@ func (h *SettingsHandler) GetGeneralSettings(c echo.Context) error {
 func (h *SettingsHandler) UpdateGeneralSettings(c echo.Context) error {
 	var req generalSettingsRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, errorResponse{Error: "invalid request"})
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if req.LowDataSchedule != nil && *req.LowDataSchedule != "" {
+		if _, _, err := service.ParseLowDataSchedule(*req.LowDataSchedule); err != nil {
+			v.fail("lowDataSchedule", fieldInvalidFormat, "must be HH:MM-HH:MM")
+		}
+	}
+	if req.NSFWMode != nil {
+		v.oneOf("nsfwMode", *req.NSFWMode, service.NSFWShow, service.NSFWBlur, service.NSFWHide)
+	}
+	if req.TLSFingerprints != nil {
+		if _, err := service.ParseTLSFingerprints(*req.TLSFingerprints); err != nil {
+			v.fail("tlsFingerprints", fieldInvalidFormat, "must be host=browser pairs, browser one of chrome, firefox, safari")
+		}
+	}
+	if req.IconSources != nil {
+		if _, err := service.ParseIconSources(*req.IconSources); err != nil {
+			v.fail("iconSources", fieldInvalidFormat, "must list each of feed, site, google, duckduckgo at most once")
+		}
+	}
+	if req.RetentionDays != nil {
+		v.minInt("retentionDays", *req.RetentionDays, 0)
+	}
+	if req.RetentionMaxPerFeed != nil {
+		v.minInt("retentionMaxPerFeed", *req.RetentionMaxPerFeed, 0)
+	}
+	if req.FeedDisableThreshold != nil {
+		v.minInt("feedDisableThreshold", *req.FeedDisableThreshold, 0)
+	}
+	if req.DuplicateTitles != nil {
+		v.oneOf("duplicateTitles", *req.DuplicateTitles, service.DuplicateTitlesOff, service.DuplicateTitlesExact, service.DuplicateTitlesFuzzy)
+	}
+	if req.ContentRefetchWindow != nil {
+		v.intRange("contentRefetchWindow", *req.ContentRefetchWindow, 0, service.MaxContentRefetchWindow)
+	}
+	if req.FutureDates != nil {
+		v.oneOf("futureDates", *req.FutureDates, service.FutureDatesClamp, service.FutureDatesHide, service.FutureDatesKeep)
+	}
+	if req.EmbedHosts != nil {
+		if _, err := service.ParseEmbedHosts(*req.EmbedHosts); err != nil {
+			v.fail("embedHosts", fieldInvalidFormat, "must be host names")
+		}
+	}
+	if req.DuplicateTitleWindow != nil {
+		v.intRange("duplicateTitleWindow", *req.DuplicateTitleWindow, 1, service.MaxDuplicateTitleWindow)
+	}
+	if req.BackupInterval != nil {
+		v.intRange("backupInterval", *req.BackupInterval, 0, service.MaxBackupInterval)
+	}
+	if req.BackupKeep != nil {
+		v.intRange("backupKeep", *req.BackupKeep, 1, service.MaxBackupKeep)
+	}
+	if v.failed() {
+		return v.write(c)
 	}
 
-	settings := &service.GeneralSettings{
-		FallbackUserAgent: req.FallbackUserAgent,
-		AutoReadability:   req.AutoReadability,
+	settings, err := h.service.GetGeneralSettings(c.Request().Context())
+	if err != nil {
+		c.Logger().Error(err)
+		return Error(c, CodeInternal, "failed to save settings")
+	}
+	settings.FallbackUserAgent = req.FallbackUserAgent
+	settings.AutoReadability = req.AutoReadability
+	if req.LowData != nil {
+		settings.LowData = *req.LowData
+	}
+	if req.LowDataSchedule != nil {
+		settings.LowDataSchedule = *req.LowDataSchedule
+	}
+	if req.NSFWMode != nil {
+		settings.NSFWMode = *req.NSFWMode
+	}
+	if req.NSFWKeywords != nil {
+		settings.NSFWKeywords = *req.NSFWKeywords
+	}
+	if req.NSFWVisionCheck != nil {
+		settings.NSFWVisionCheck = *req.NSFWVisionCheck
+	}
+	if req.CookieHosts != nil {
+		settings.CookieHosts = *req.CookieHosts
+	}
+	if req.TLSFingerprints != nil {
+		settings.TLSFingerprints = *req.TLSFingerprints
+	}
+	if req.IconSources != nil {
+		settings.IconSources = *req.IconSources
+	}
+	if req.RetentionDays != nil {
+		settings.RetentionDays = *req.RetentionDays
+	}
+	if req.RetentionMaxPerFeed != nil {
+		settings.RetentionMaxPerFeed = *req.RetentionMaxPerFeed
+	}
+	if req.FeedDisableThreshold != nil {
+		settings.FeedDisableThreshold = *req.FeedDisableThreshold
+	}
+	if req.CategoryFolders != nil {
+		settings.CategoryFolders = *req.CategoryFolders
+	}
+	if req.DuplicateTitles != nil {
+		settings.DuplicateTitles = *req.DuplicateTitles
+	}
+	if req.DuplicateTitleWindow != nil {
+		settings.DuplicateTitleWindow = *req.DuplicateTitleWindow
+	}
+	if req.EmbedHosts != nil {
+		settings.EmbedHosts = *req.EmbedHosts
+	}
+	if req.FutureDates != nil {
+		settings.FutureDates = *req.FutureDates
+	}
+	if req.ContentRefetchWindow != nil {
+		settings.ContentRefetchWindow = *req.ContentRefetchWindow
+	}
+	if req.BackupInterval != nil {
+		settings.BackupInterval = *req.BackupInterval
+	}
+	if req.BackupKeep != nil {
+		settings.BackupKeep = *req.BackupKeep
 	}
 
 	if err := h.service.SetGeneralSettings(c.Request().Context(), settings); err != nil {
 		c.Logger().Error(err)
-		return c.JSON(http.StatusInternalServerError, errorResponse{Error: "failed to save settings"})
+		return Error(c, CodeInternal, "failed to save settings")
 	}
 
 	return h.GetGeneralSettings(c)
 }
+
+// SetLowData switches low-data mode on or off.
+// @Summary Switch low-data mode
+// @Description Turn low-data mode on or off without touching other settings, e.g. from an automation when roaming starts. While it is active, scheduled refreshes and syncs and the startup icon backfill are skipped and clients stop automatic AI summaries and translations. A configured schedule still applies when the switch is off.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Param body body lowDataRequest true "Low-data switch"
+// @Success 200 {object} generalSettingsResponse
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /settings/low-data [put]
+func (h *SettingsHandler) SetLowData(c echo.Context) error {
+	var req lowDataRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if req.Enabled == nil {
+		v.fail("enabled", fieldRequired, "is required")
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	if err := h.service.SetLowData(c.Request().Context(), *req.Enabled); err != nil {
+		c.Logger().Error(err)
+		return Error(c, CodeInternal, "failed to save settings")
+	}
+	return h.GetGeneralSettings(c)
+}
+
+// validateAIConfig checks the provider fields shared by the save and test requests.
+func validateAIConfig(v *validator, provider, baseURL string, thinkingBudget int, reasoningEffort string) {
+	v.oneOf("provider", provider, ai.ProviderOpenAI, ai.ProviderAnthropic, ai.ProviderCompatible)
+	if baseURL != "" {
+		v.httpURL("baseUrl", baseURL)
+	}
+	v.minInt("thinkingBudget", thinkingBudget, 0)
+	v.oneOf("reasoningEffort", reasoningEffort, "none", "minimal", "low", "medium", "high", "xhigh")
+}
+
+// GetAppearanceSettings returns the appearance settings.
+// @Summary Get appearance settings
+// @Description Get how clients show Gist, kept on the server so every device looks the same: theme (light, dark or auto to follow the device), accentColor (#rrggbb, empty for the default), fontSize of entry content in pixels (default 16) and the view entries of each content type are listed in (list or masonry; default masonry for picture, list otherwise).
+// @Tags settings
+// @Produce json
+// @Success 200 {object} service.AppearanceSettings
+// @Failure 500 {object} errorResponse
+// @Router /settings/appearance [get]
+func (h *SettingsHandler) GetAppearanceSettings(c echo.Context) error {
+	settings, err := h.service.GetAppearanceSettings(c.Request().Context())
+	if err != nil {
+		c.Logger().Error(err)
+		return Error(c, CodeInternal, "failed to get settings")
+	}
+	return c.JSON(http.StatusOK, settings)
+}
+
+// UpdateAppearanceSettings updates the appearance settings.
+// @Summary Update appearance settings
+// @Description Update the appearance settings. Omitted fields, and content types missing from defaultViews, keep their current values; an empty accentColor restores the default. fontSize is 12 to 24.
+// @Tags settings
+// @Accept json
+// @Produce json
+// @Param settings body appearanceSettingsRequest true "Appearance settings"
+// @Success 200 {object} service.AppearanceSettings
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Router /settings/appearance [put]
+func (h *SettingsHandler) UpdateAppearanceSettings(c echo.Context) error {
+	var req appearanceSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	if req.Theme != nil {
+		v.oneOf("theme", *req.Theme, service.ThemeLight, service.ThemeDark, service.ThemeAuto)
+	}
+	if req.AccentColor != nil && *req.AccentColor != "" && !service.IsAccentColor(*req.AccentColor) {
+		v.fail("accentColor", fieldInvalidFormat, "must be #rrggbb")
+	}
+	if req.FontSize != nil {
+		v.intRange("fontSize", *req.FontSize, service.MinFontSize, service.MaxFontSize)
+	}
+	for contentType, view := range req.DefaultViews {
+		v.oneOf("defaultViews", contentType, service.ContentTypes...)
+		v.oneOf("defaultViews."+contentType, view, service.ViewList, service.ViewMasonry)
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	settings, err := h.service.GetAppearanceSettings(c.Request().Context())
+	if err != nil {
+		c.Logger().Error(err)
+		return Error(c, CodeInternal, "failed to save settings")
+	}
+	if req.Theme != nil {
+		settings.Theme = *req.Theme
+	}
+	if req.AccentColor != nil {
+		settings.AccentColor = *req.AccentColor
+	}
+	if req.FontSize != nil {
+		settings.FontSize = *req.FontSize
+	}
+	for contentType, view := range req.DefaultViews {
+		settings.DefaultViews[contentType] = view
+	}
+
+	if err := h.service.SetAppearanceSettings(c.Request().Context(), settings); err != nil {
+		c.Logger().Error(err)
+		return Error(c, CodeInternal, "failed to save settings")
+	}
+	return h.GetAppearanceSettings(c)
+}
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+type StatsHandler struct {
+	bandwidth *service.BandwidthMeter
+}
+
+func NewStatsHandler(bandwidth *service.BandwidthMeter) *StatsHandler {
+	return &StatsHandler{bandwidth: bandwidth}
+}
+
+func (h *StatsHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/stats/bandwidth", h.Bandwidth)
+}
+
+// Bandwidth returns what each feed downloaded per day.
+// @Summary Get bandwidth per feed
+// @Description Get the bytes downloaded for each feed over the last days (today included), most first, with a daily breakdown by server local date.
+// @Description Counts the feed and sitemap fetches of refreshes and the pages and thumbnails fetched for entries in the background; images loaded through the proxy while reading are not counted.
+// @Tags stats
+// @Produce json
+// @Param days query int false "Days to cover, 1 to 90" default(30)
+// @Success 200 {object} service.BandwidthUsage
+// @Failure 400 {object} errorResponse
+// @Router /stats/bandwidth [get]
+func (h *StatsHandler) Bandwidth(c echo.Context) error {
+	var v validator
+	days := v.queryInt(c, "days", 30)
+	if !v.failed() {
+		v.intRange("days", days, 1, service.BandwidthRetentionDays)
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+	usage, err := h.bandwidth.Usage(c.Request().Context(), days)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, usage)
+}
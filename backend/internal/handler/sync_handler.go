@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+type SyncHandler struct {
+	sync  service.SyncService
+	tasks service.TaskRunner
+}
+
+func NewSyncHandler(sync service.SyncService, tasks service.TaskRunner) *SyncHandler {
+	return &SyncHandler{sync: sync, tasks: tasks}
+}
+
+// RegisterRoutes registers the sync routes. The change feed exposes every
+// subscription and read state, so it is guarded by auth.
+func (h *SyncHandler) RegisterRoutes(g *echo.Group, auth echo.MiddlewareFunc) {
+	g.GET("/sync/changes", h.Changes, auth)
+	g.POST("/sync/run", h.Run)
+}
+
+// Changes returns a page of this instance's change feed.
+// @Summary Sync change feed
+// @Description Used by secondary instances to mirror this one. Returns all folders and feeds, plus the entry read/starred changes after cursor in log order. Pass the returned cursor to the next call until hasMore is false. Requires the API token (the pairing token) as a Bearer token or X-Gist-Token header.
+// @Tags sync
+// @Produce json
+// @Security ApiToken
+// @Param cursor query int false "Change-log position already applied (default 0)"
+// @Param limit query int false "Maximum entry changes to return (1-1000, default 1000)"
+// @Success 200 {object} service.SyncChanges
+// @Failure 400 {object} errorResponse
+// @Failure 401 {object} errorResponse
+// @Router /sync/changes [get]
+func (h *SyncHandler) Changes(c echo.Context) error {
+	var v validator
+	cursor := v.queryInt(c, "cursor", 0)
+	limit := v.queryInt(c, "limit", service.MaxSyncPageSize)
+	v.minInt("cursor", cursor, 0)
+	v.intRange("limit", limit, 1, service.MaxSyncPageSize)
+	if v.failed() {
+		return v.write(c)
+	}
+
+	changes, err := h.sync.Changes(c.Request().Context(), int64(cursor), limit)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, changes)
+}
+
+// Run pulls from the primary now.
+// @Summary Sync from primary
+// @Description Start pulling subscriptions and read state from the configured primary instance. Returns the task to poll via /tasks/{id}; its result is a SyncResult.
+// @Tags sync
+// @Produce json
+// @Success 202 {object} service.Task
+// @Failure 409 {object} errorResponse
+// @Router /sync/run [post]
+func (h *SyncHandler) Run(c echo.Context) error {
+	if !h.sync.Configured() {
+		return Error(c, CodeSyncNotConfigured, "no sync primary is configured")
+	}
+	task, err := h.tasks.Start(service.TaskSync, 0, service.SyncTask(h.sync))
+	if errors.Is(err, service.ErrTaskRunning) {
+		return Error(c, CodeSyncInProgress, "sync already in progress")
+	}
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusAccepted, task)
+}
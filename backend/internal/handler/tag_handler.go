@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service"
+)
+
+type TagHandler struct {
+	service service.TagService
+}
+
+type tagRequest struct {
+	Name string `json:"name"`
+}
+
+// assignTagRequest names the tag to add to an entry: tagId for an existing
+// tag, or name for one that is created when missing.
+type assignTagRequest struct {
+	TagID *string `json:"tagId"`
+	Name  string  `json:"name"`
+}
+
+type tagResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// EntryCount is how many entries have the tag; only in the tag list.
+	EntryCount *int   `json:"entryCount,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+	UpdatedAt  string `json:"updatedAt"`
+}
+
+func NewTagHandler(tags service.TagService) *TagHandler {
+	return &TagHandler{service: tags}
+}
+
+func (h *TagHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/tags", h.List)
+	g.POST("/tags", h.Create)
+	g.PUT("/tags/:id", h.Rename)
+	g.DELETE("/tags/:id", h.Delete)
+	g.GET("/entries/:id/tags", h.ListForEntry)
+	g.POST("/entries/:id/tags", h.Assign)
+	g.DELETE("/entries/:id/tags/:tagId", h.Unassign)
+}
+
+// List returns all tags.
+// @Summary List tags
+// @Description Get every tag with the number of entries that have it, by name. List a tag's entries with /entries?tagId=.
+// @Tags tags
+// @Produce json
+// @Success 200 {array} tagResponse
+// @Router /tags [get]
+func (h *TagHandler) List(c echo.Context) error {
+	tags, err := h.service.List(c.Request().Context())
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	response := make([]tagResponse, 0, len(tags))
+	for _, tag := range tags {
+		resp := toTagResponse(tag)
+		count := tag.EntryCount
+		resp.EntryCount = &count
+		response = append(response, resp)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// Create creates a tag.
+// @Summary Create a tag
+// @Description Create a tag to organize entries with. Names are 1 to 64 characters and unique ignoring case.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param tag body tagRequest true "Tag"
+// @Success 201 {object} tagResponse
+// @Failure 400 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Router /tags [post]
+func (h *TagHandler) Create(c echo.Context) error {
+	var req tagRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	v.tagName("name", req.Name)
+	if v.failed() {
+		return v.write(c)
+	}
+	tag, err := h.service.Create(c.Request().Context(), req.Name)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusCreated, toTagResponse(tag))
+}
+
+// Rename renames a tag.
+// @Summary Rename a tag
+// @Description Rename a tag, keeping its entries
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Tag ID"
+// @Param tag body tagRequest true "Tag"
+// @Success 200 {object} tagResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 409 {object} errorResponse
+// @Router /tags/{id} [put]
+func (h *TagHandler) Rename(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	var req tagRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	v.tagName("name", req.Name)
+	if v.failed() {
+		return v.write(c)
+	}
+	tag, err := h.service.Rename(c.Request().Context(), id, req.Name)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toTagResponse(tag))
+}
+
+// Delete deletes a tag.
+// @Summary Delete a tag
+// @Description Delete a tag, removing it from its entries; the entries are kept
+// @Tags tags
+// @Param id path int true "Tag ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /tags/{id} [delete]
+func (h *TagHandler) Delete(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListForEntry returns the tags of an entry.
+// @Summary List entry tags
+// @Description Get the tags of an entry, by name
+// @Tags tags
+// @Produce json
+// @Param id path int true "Entry ID"
+// @Success 200 {array} tagResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /entries/{id}/tags [get]
+func (h *TagHandler) ListForEntry(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	tags, err := h.service.ListForEntry(c.Request().Context(), id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toTagResponses(tags))
+}
+
+// Assign tags an entry.
+// @Summary Tag an entry
+// @Description Add a tag to an entry: an existing one by tagId, or one by name, created when no tag has that name ignoring case. Tagging an entry again with the same tag does nothing. Returns the entry's tags.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path int true "Entry ID"
+// @Param tag body assignTagRequest true "Tag to add"
+// @Success 200 {array} tagResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /entries/{id}/tags [post]
+func (h *TagHandler) Assign(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	var req assignTagRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	tagID := v.optionalID("tagId", req.TagID)
+	if req.TagID == nil {
+		v.tagName("name", req.Name)
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+	tags, err := h.service.Assign(c.Request().Context(), id, tagID, req.Name)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toTagResponses(tags))
+}
+
+// Unassign removes a tag from an entry.
+// @Summary Untag an entry
+// @Description Remove a tag from an entry. Returns the entry's tags.
+// @Tags tags
+// @Produce json
+// @Param id path int true "Entry ID"
+// @Param tagId path int true "Tag ID"
+// @Success 200 {array} tagResponse
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /entries/{id}/tags/{tagId} [delete]
+func (h *TagHandler) Unassign(c echo.Context) error {
+	id, err := parseIDParam(c, "id")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	tagID, err := parseIDParam(c, "tagId")
+	if err != nil {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	tags, err := h.service.Unassign(c.Request().Context(), id, tagID)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toTagResponses(tags))
+}
+
+// tagName checks that a tag name is given and not too long.
+func (v *validator) tagName(field, name string) {
+	if v.required(field, name) && utf8.RuneCountInString(strings.TrimSpace(name)) > service.MaxTagNameLength {
+		v.fail(field, fieldOutOfRange, fmt.Sprintf("must be at most %d characters", service.MaxTagNameLength))
+	}
+}
+
+func toTagResponse(tag model.Tag) tagResponse {
+	return tagResponse{
+		ID:        idToString(tag.ID),
+		Name:      tag.Name,
+		CreatedAt: tag.CreatedAt.UTC().Format(time.RFC3339),
+		UpdatedAt: tag.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func toTagResponses(tags []model.Tag) []tagResponse {
+	response := make([]tagResponse, 0, len(tags))
+	for _, tag := range tags {
+		response = append(response, toTagResponse(tag))
+	}
+	return response
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+type TaskHandler struct {
+	tasks service.TaskRunner
+}
+
+type cancelTaskResponse struct {
+	Cancelled bool `json:"cancelled"`
+}
+
+func NewTaskHandler(tasks service.TaskRunner) *TaskHandler {
+	return &TaskHandler{tasks: tasks}
+}
+
+func (h *TaskHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/tasks", h.List)
+	g.GET("/tasks/:id", h.Get)
+	g.DELETE("/tasks/:id", h.Cancel)
+}
+
+// List returns the latest task of each kind.
+// @Summary List tasks
+// @Description Get the most recent background task of each kind (import, refresh, icon_backfill), newest first
+// @Tags tasks
+// @Produce json
+// @Success 200 {array} service.Task
+// @Router /tasks [get]
+func (h *TaskHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.tasks.List())
+}
+
+// Get returns a single task.
+// @Summary Get a task
+// @Description Get the status and progress of a background task
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} service.Task
+// @Failure 404 {object} errorResponse
+// @Router /tasks/{id} [get]
+func (h *TaskHandler) Get(c echo.Context) error {
+	task, ok := h.tasks.Get(c.Param("id"))
+	if !ok {
+		return Error(c, CodeNotFound, "task not found")
+	}
+	return c.JSON(http.StatusOK, task)
+}
+
+// Cancel stops a running task.
+// @Summary Cancel a task
+// @Description Cancel a running background task. cancelled is false if it had already finished.
+// @Tags tasks
+// @Produce json
+// @Param id path string true "Task ID"
+// @Success 200 {object} cancelTaskResponse
+// @Failure 404 {object} errorResponse
+// @Router /tasks/{id} [delete]
+func (h *TaskHandler) Cancel(c echo.Context) error {
+	id := c.Param("id")
+	if _, ok := h.tasks.Get(id); !ok {
+		return Error(c, CodeNotFound, "task not found")
+	}
+	return c.JSON(http.StatusOK, cancelTaskResponse{Cancelled: h.tasks.Cancel(id)})
+}
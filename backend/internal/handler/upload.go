@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// uploadField is the multipart form field carrying the uploaded file.
+const uploadField = "file"
+
+var (
+	errMissingUpload     = errors.New("missing file")
+	errInvalidUpload     = errors.New("invalid upload")
+	errUnsupportedUpload = errors.New("unsupported media type")
+)
+
+// openUpload returns a stream over an uploaded file, sent either as the "file"
+// field of a multipart form or as the raw request body. Nothing is buffered:
+// the body is capped at maxSize and reads past it fail with *http.MaxBytesError.
+// allowed lists the accepted media types of the file itself.
+func openUpload(c echo.Context, maxSize int64, allowed map[string]bool) (io.Reader, error) {
+	req := c.Request()
+	if req.ContentLength > maxSize {
+		return nil, &http.MaxBytesError{Limit: maxSize}
+	}
+	req.Body = http.MaxBytesReader(c.Response().Writer, req.Body, maxSize)
+
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, errUnsupportedUpload
+	}
+	if mediaType != "multipart/form-data" {
+		if !allowed[mediaType] {
+			return nil, errUnsupportedUpload
+		}
+		return req.Body, nil
+	}
+
+	mr, err := req.MultipartReader()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidUpload, err)
+	}
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return nil, errMissingUpload
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errInvalidUpload, err)
+		}
+		if part.FormName() != uploadField {
+			continue
+		}
+		// Parts without a Content-Type default to application/octet-stream (RFC 7578)
+		partType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil {
+			partType = "application/octet-stream"
+		}
+		if !allowed[partType] {
+			return nil, errUnsupportedUpload
+		}
+		return part, nil
+	}
+}
+
+// writeUploadError maps upload and size-limit errors, deferring the rest to writeServiceError.
+func writeUploadError(c echo.Context, err error) error {
+	var maxErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxErr):
+		return Error(c, CodeFileTooLarge, "file too large")
+	case errors.Is(err, errUnsupportedUpload):
+		return Error(c, CodeUnsupportedMediaType, "unsupported media type")
+	case errors.Is(err, errMissingUpload):
+		return Error(c, CodeMissingFile, "missing file")
+	case errors.Is(err, errInvalidUpload):
+		return Error(c, CodeInvalidRequest, "invalid request")
+	default:
+		return writeServiceError(c, err)
+	}
+}
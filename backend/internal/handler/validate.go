@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Field error codes reported in FieldError.Code.
+const (
+	fieldRequired      = "required"
+	fieldInvalidID     = "invalid_id"
+	fieldInvalidURL    = "invalid_url"
+	fieldInvalidEnum   = "invalid_enum"
+	fieldOutOfRange    = "out_of_range"
+	fieldNotInteger    = "not_integer"
+	fieldInvalidFormat = "invalid_format"
+)
+
+// validator collects per-field errors so a request reports every problem at
+// once. Checks record a FieldError and keep going; parsing helpers return a
+// zero value for invalid input, which is never used because the handler
+// bails out on failed().
+type validator struct {
+	errs []FieldError
+}
+
+func (v *validator) fail(field, code, message string) {
+	v.errs = append(v.errs, FieldError{Field: field, Code: code, Message: message})
+}
+
+// failed reports whether any check has failed.
+func (v *validator) failed() bool {
+	return len(v.errs) > 0
+}
+
+// write sends the collected errors as a validation_failed envelope.
+func (v *validator) write(c echo.Context) error {
+	return c.JSON(statusForCode(CodeValidationFailed), errorResponse{
+		Code:        CodeValidationFailed,
+		Message:     "request validation failed",
+		FieldErrors: v.errs,
+	})
+}
+
+func (v *validator) required(field, value string) bool {
+	if strings.TrimSpace(value) == "" {
+		v.fail(field, fieldRequired, "is required")
+		return false
+	}
+	return true
+}
+
+// httpURL checks that value is an absolute http or https URL.
+func (v *validator) httpURL(field, value string) {
+	parsed, err := url.ParseRequestURI(strings.TrimSpace(value))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		v.fail(field, fieldInvalidURL, "must be an http or https URL")
+	}
+}
+
+// oneOf checks that value is one of allowed. Empty values pass; pair with
+// required when the field is mandatory.
+func (v *validator) oneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.fail(field, fieldInvalidEnum, "must be one of "+strings.Join(allowed, ", "))
+}
+
+func (v *validator) intRange(field string, value, min, max int) {
+	if value < min || value > max {
+		v.fail(field, fieldOutOfRange, fmt.Sprintf("must be between %d and %d", min, max))
+	}
+}
+
+func (v *validator) minInt(field string, value, min int) {
+	if value < min {
+		v.fail(field, fieldOutOfRange, fmt.Sprintf("must be at least %d", min))
+	}
+}
+
+// id parses a string ID.
+func (v *validator) id(field, raw string) int64 {
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		v.fail(field, fieldInvalidID, "must be a numeric ID")
+	}
+	return id
+}
+
+// optionalID parses an ID that may be absent.
+func (v *validator) optionalID(field string, raw *string) *int64 {
+	if raw == nil {
+		return nil
+	}
+	id := v.id(field, *raw)
+	return &id
+}
+
+// ids parses a non-empty list of IDs, reporting bad entries as field[i].
+func (v *validator) ids(field string, raw []string) []int64 {
+	if len(raw) == 0 {
+		v.fail(field, fieldRequired, "must contain at least one ID")
+		return nil
+	}
+	ids := make([]int64, 0, len(raw))
+	for i, r := range raw {
+		ids = append(ids, v.id(fmt.Sprintf("%s[%d]", field, i), r))
+	}
+	return ids
+}
+
+// queryID parses an optional ID query parameter.
+func (v *validator) queryID(c echo.Context, name string) *int64 {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return nil
+	}
+	return v.optionalID(name, &raw)
+}
+
+// queryInt parses an optional integer query parameter, returning def when absent.
+func (v *validator) queryInt(c echo.Context, name string, def int) int {
+	raw := c.QueryParam(name)
+	if raw == "" {
+		return def
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		v.fail(name, fieldNotInteger, "must be an integer")
+		return def
+	}
+	return n
+}
@@ -0,0 +1,566 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service"
+)
+
+const (
+	// wallabagVersion is the Wallabag server version reported to clients;
+	// the apps check it before using the 2.x API.
+	wallabagVersion = "2.6.0"
+	// wallabagTimeLayout is how Wallabag formats dates.
+	wallabagTimeLayout = "2006-01-02T15:04:05-0700"
+	// wallabagAccessLifetime is how long an access token is accepted.
+	wallabagAccessLifetime = time.Hour
+	// wallabagRefreshLifetime is how long a refresh token can be exchanged
+	// for a new access token.
+	wallabagRefreshLifetime = 60 * 24 * time.Hour
+
+	defaultWallabagPageSize = 30
+	maxWallabagPageSize     = 100
+)
+
+// WallabagHandler serves the subset of the Wallabag API that read-it-later
+// apps (the Wallabag apps, KOReader, browser extensions) use to save pages
+// and sync the reading list. Any client ID and secret are accepted; the
+// password is the API token, which clients exchange for expiring tokens.
+type WallabagHandler struct {
+	readLater service.ReadLaterService
+	entries   service.EntryService
+	apiToken  string
+}
+
+func NewWallabagHandler(readLater service.ReadLaterService, entries service.EntryService, apiToken string) *WallabagHandler {
+	return &WallabagHandler{readLater: readLater, entries: entries, apiToken: apiToken}
+}
+
+// RegisterRoutes registers the Wallabag routes under /wallabag, where clients
+// are pointed as the server URL. Everything but the token exchange and
+// version probes needs an access token, or whatever auth accepts.
+func (h *WallabagHandler) RegisterRoutes(e *echo.Echo, auth echo.MiddlewareFunc) {
+	auth = h.acceptAccessTokens(auth)
+	g := e.Group("/wallabag")
+	g.POST("/oauth/v2/token", h.Token)
+	// Clients call the API with and without the .json suffix
+	for _, suffix := range []string{"", ".json"} {
+		g.GET("/api/version"+suffix, h.Version)
+		g.GET("/api/info"+suffix, h.Info)
+		g.GET("/api/entries"+suffix, h.List, auth)
+		g.POST("/api/entries"+suffix, h.Create, auth)
+		g.GET("/api/entries/exists"+suffix, h.Exists, auth)
+		g.GET("/api/tags"+suffix, h.Tags, auth)
+	}
+	g.GET("/api/entries/:id", h.Get, auth)
+	g.PATCH("/api/entries/:id", h.Update, auth)
+}
+
+type wallabagTokenRequest struct {
+	GrantType    string `json:"grant_type" form:"grant_type"`
+	ClientID     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+	Username     string `json:"username" form:"username"`
+	Password     string `json:"password" form:"password"`
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
+}
+
+type wallabagTokenResponse struct {
+	AccessToken  string  `json:"access_token"`
+	ExpiresIn    int     `json:"expires_in"`
+	RefreshToken string  `json:"refresh_token"`
+	Scope        *string `json:"scope"`
+	TokenType    string  `json:"token_type"`
+}
+
+// wallabagOAuthError is the OAuth 2 error body token clients expect.
+type wallabagOAuthError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Token exchanges the API token, or a refresh token, for an access token
+// and a new refresh token. Neither is the API token itself.
+func (h *WallabagHandler) Token(c echo.Context) error {
+	var req wallabagTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, wallabagOAuthError{Error: "invalid_request", Description: "invalid request body"})
+	}
+
+	now := time.Now()
+	switch req.GrantType {
+	case "password":
+		if h.apiToken == "" || subtle.ConstantTimeCompare([]byte(req.Password), []byte(h.apiToken)) != 1 {
+			return c.JSON(http.StatusBadRequest, wallabagOAuthError{Error: "invalid_grant", Description: "the password must be the API token"})
+		}
+	case "refresh_token":
+		if !h.validToken(req.RefreshToken, wallabagRefreshToken, now) {
+			return c.JSON(http.StatusBadRequest, wallabagOAuthError{Error: "invalid_grant", Description: "the refresh token is invalid or expired"})
+		}
+	default:
+		return c.JSON(http.StatusBadRequest, wallabagOAuthError{Error: "unsupported_grant_type", Description: "grant_type must be password or refresh_token"})
+	}
+	return c.JSON(http.StatusOK, wallabagTokenResponse{
+		AccessToken:  h.token(wallabagAccessToken, now.Add(wallabagAccessLifetime)),
+		ExpiresIn:    int(wallabagAccessLifetime / time.Second),
+		RefreshToken: h.token(wallabagRefreshToken, now.Add(wallabagRefreshLifetime)),
+		TokenType:    "bearer",
+	})
+}
+
+// Kinds of token handed out by Token.
+const (
+	wallabagAccessToken  = "access"
+	wallabagRefreshToken = "refresh"
+)
+
+// token derives a token of kind that expires at expires. It is signed with
+// the API token, so changing that revokes every token handed out.
+func (h *WallabagHandler) token(kind string, expires time.Time) string {
+	payload := kind + "." + strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + h.sign(payload)
+}
+
+func (h *WallabagHandler) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(h.apiToken))
+	mac.Write([]byte("wallabag." + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// validToken reports whether token is a token of kind handed out by Token
+// that has not expired by now.
+func (h *WallabagHandler) validToken(token, kind string, now time.Time) bool {
+	if h.apiToken == "" {
+		return false
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != kind {
+		return false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() >= expires {
+		return false
+	}
+	return hmac.Equal([]byte(parts[2]), []byte(h.sign(parts[0]+"."+parts[1])))
+}
+
+// acceptAccessTokens lets requests with a valid access token through and
+// leaves the rest to auth.
+func (h *WallabagHandler) acceptAccessTokens(auth echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		guarded := auth(next)
+		return func(c echo.Context) error {
+			bearer, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			if ok && h.validToken(strings.TrimSpace(bearer), wallabagAccessToken, time.Now()) {
+				return next(c)
+			}
+			return guarded(c)
+		}
+	}
+}
+
+// Version reports the Wallabag API version.
+func (h *WallabagHandler) Version(c echo.Context) error {
+	return c.JSON(http.StatusOK, wallabagVersion)
+}
+
+type wallabagInfo struct {
+	AppName             string `json:"appname"`
+	Version             string `json:"version"`
+	AllowedRegistration bool   `json:"allowed_registration"`
+}
+
+// Info describes the server to Wallabag clients.
+func (h *WallabagHandler) Info(c echo.Context) error {
+	return c.JSON(http.StatusOK, wallabagInfo{AppName: "wallabag", Version: wallabagVersion})
+}
+
+// wallabagEntry is an entry in the shape of the Wallabag API. IDs are sent
+// as strings, like the rest of the API: snowflake IDs do not fit the
+// numbers of JavaScript and Lua clients, which read them back as strings.
+type wallabagEntry struct {
+	ID             string     `json:"id"`
+	Title          string     `json:"title"`
+	URL            string     `json:"url"`
+	GivenURL       string     `json:"given_url"`
+	Content        *string    `json:"content,omitempty"`
+	IsArchived     int        `json:"is_archived"`
+	IsStarred      int        `json:"is_starred"`
+	IsPublic       bool       `json:"is_public"`
+	CreatedAt      string     `json:"created_at"`
+	UpdatedAt      string     `json:"updated_at"`
+	PublishedAt    *string    `json:"published_at"`
+	PublishedBy    []string   `json:"published_by"`
+	ReadingTime    int        `json:"reading_time"`
+	DomainName     string     `json:"domain_name"`
+	PreviewPicture *string    `json:"preview_picture"`
+	MimeType       string     `json:"mimetype"`
+	Language       *string    `json:"language"`
+	Tags           []struct{} `json:"tags"`
+	Annotations    []struct{} `json:"annotations"`
+	UserID         int        `json:"user_id"`
+	UserName       string     `json:"user_name"`
+}
+
+type wallabagLink struct {
+	Href string `json:"href"`
+}
+
+type wallabagEntryPage struct {
+	Page     int                     `json:"page"`
+	Limit    int                     `json:"limit"`
+	Pages    int                     `json:"pages"`
+	Total    int                     `json:"total"`
+	Links    map[string]wallabagLink `json:"_links"`
+	Embedded struct {
+		Items []wallabagEntry `json:"items"`
+	} `json:"_embedded"`
+}
+
+// List returns a page of the read-later list. Summaries carry no content,
+// so unless detail=metadata each entry is loaded in full.
+func (h *WallabagHandler) List(c echo.Context) error {
+	var v validator
+	archive := c.QueryParam("archive")
+	starred := c.QueryParam("starred")
+	sortBy := c.QueryParam("sort")
+	order := c.QueryParam("order")
+	detail := c.QueryParam("detail")
+	v.oneOf("archive", archive, "0", "1")
+	v.oneOf("starred", starred, "0", "1")
+	v.oneOf("sort", sortBy, service.ReadLaterSortCreated, service.ReadLaterSortUpdated)
+	v.oneOf("order", order, "asc", "desc")
+	v.oneOf("detail", detail, "full", "metadata")
+	page := v.queryInt(c, "page", 1)
+	perPage := v.queryInt(c, "perPage", defaultWallabagPageSize)
+	since := v.queryInt(c, "since", 0)
+	v.minInt("page", page, 1)
+	v.intRange("perPage", perPage, 1, maxWallabagPageSize)
+	v.minInt("since", since, 0)
+	if v.failed() {
+		return v.write(c)
+	}
+
+	q := service.ReadLaterQuery{
+		Read:      wallabagBool(archive),
+		Starred:   wallabagBool(starred),
+		SortBy:    sortBy,
+		Ascending: order == "asc",
+		Limit:     perPage,
+		Offset:    (page - 1) * perPage,
+	}
+	if since > 0 {
+		q.Since = time.Unix(int64(since), 0)
+	}
+	ctx := c.Request().Context()
+	summaries, total, err := h.readLater.List(ctx, q)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+
+	resp := wallabagEntryPage{
+		Page:  page,
+		Limit: perPage,
+		Pages: (total + perPage - 1) / perPage,
+		Total: total,
+	}
+	resp.Embedded.Items = make([]wallabagEntry, 0, len(summaries))
+	for _, summary := range summaries {
+		entry := model.Entry{
+			ID:           summary.ID,
+			FeedID:       summary.FeedID,
+			Title:        summary.Title,
+			URL:          summary.URL,
+			ThumbnailURL: summary.ThumbnailURL,
+			Author:       summary.Author,
+			PublishedAt:  summary.PublishedAt,
+			Read:         summary.Read,
+			Starred:      summary.Starred,
+			CreatedAt:    summary.CreatedAt,
+			UpdatedAt:    summary.UpdatedAt,
+		}
+		if detail != "metadata" {
+			if entry, err = h.entries.GetByID(ctx, summary.ID); err != nil {
+				return writeServiceError(c, err)
+			}
+		}
+		resp.Embedded.Items = append(resp.Embedded.Items, toWallabagEntry(entry, detail != "metadata"))
+	}
+	resp.Links = wallabagPageLinks(c, page, resp.Pages)
+	return c.JSON(http.StatusOK, resp)
+}
+
+// wallabagFlag is a 0/1 request field, which clients send as a number, a
+// string or a boolean.
+type wallabagFlag struct {
+	set   bool
+	value bool
+}
+
+func (f *wallabagFlag) UnmarshalJSON(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	switch v := raw.(type) {
+	case nil:
+		*f = wallabagFlag{}
+		return nil
+	case bool:
+		*f = wallabagFlag{set: true, value: v}
+		return nil
+	case float64:
+		*f = wallabagFlag{set: true, value: v != 0}
+		return nil
+	case string:
+		return f.UnmarshalParam(v)
+	}
+	return fmt.Errorf("invalid flag %s", data)
+}
+
+// UnmarshalParam parses the flag from a form value.
+func (f *wallabagFlag) UnmarshalParam(param string) error {
+	switch strings.TrimSpace(param) {
+	case "":
+		*f = wallabagFlag{}
+	case "1", "true":
+		*f = wallabagFlag{set: true, value: true}
+	case "0", "false":
+		*f = wallabagFlag{set: true, value: false}
+	default:
+		return fmt.Errorf("invalid flag %q", param)
+	}
+	return nil
+}
+
+type wallabagCreateRequest struct {
+	URL     string       `json:"url" form:"url"`
+	Title   string       `json:"title" form:"title"`
+	Content string       `json:"content" form:"content"`
+	Archive wallabagFlag `json:"archive" form:"archive"`
+	Starred wallabagFlag `json:"starred" form:"starred"`
+}
+
+// Create saves a web page to read later. With content, that HTML is saved
+// instead of fetching the page.
+func (h *WallabagHandler) Create(c echo.Context) error {
+	var req wallabagCreateRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request body")
+	}
+	var v validator
+	if v.required("url", req.URL) {
+		v.httpURL("url", req.URL)
+	}
+	if v.failed() {
+		return v.write(c)
+	}
+
+	ctx := c.Request().Context()
+	entry, err := h.readLater.Save(ctx, service.SavePageParams{URL: req.URL, Title: req.Title, HTML: req.Content})
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return h.applyFlags(c, entry.ID, req.Archive, req.Starred)
+}
+
+// Get returns one entry.
+func (h *WallabagHandler) Get(c echo.Context) error {
+	id, ok := wallabagEntryID(c)
+	if !ok {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	entry, err := h.entries.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toWallabagEntry(entry, true))
+}
+
+type wallabagUpdateRequest struct {
+	Archive wallabagFlag `json:"archive" form:"archive"`
+	Starred wallabagFlag `json:"starred" form:"starred"`
+}
+
+// Update archives or stars an entry.
+func (h *WallabagHandler) Update(c echo.Context) error {
+	id, ok := wallabagEntryID(c)
+	if !ok {
+		return Error(c, CodeInvalidID, "invalid id")
+	}
+	var req wallabagUpdateRequest
+	if err := c.Bind(&req); err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request body")
+	}
+	return h.applyFlags(c, id, req.Archive, req.Starred)
+}
+
+// applyFlags sets the flags that were sent and responds with the entry.
+func (h *WallabagHandler) applyFlags(c echo.Context, id int64, archive, starred wallabagFlag) error {
+	ctx := c.Request().Context()
+	if archive.set {
+		if err := h.entries.MarkAsRead(ctx, id, archive.value); err != nil {
+			return writeServiceError(c, err)
+		}
+	}
+	if starred.set {
+		if err := h.entries.MarkAsStarred(ctx, id, starred.value); err != nil {
+			return writeServiceError(c, err)
+		}
+	}
+	entry, err := h.entries.GetByID(ctx, id)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.JSON(http.StatusOK, toWallabagEntry(entry, true))
+}
+
+// Exists reports whether pages are saved. With url it returns
+// {"exists": bool}, or {"id": id} (null if not saved) with return_id=1; with
+// urls[] it returns an object keyed by URL.
+func (h *WallabagHandler) Exists(c echo.Context) error {
+	returnID := c.QueryParam("return_id") == "1"
+	ctx := c.Request().Context()
+	lookup := func(pageURL string) (any, error) {
+		entry, err := h.readLater.FindByURL(ctx, pageURL)
+		if errors.Is(err, service.ErrNotFound) {
+			if returnID {
+				return nil, nil
+			}
+			return false, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if returnID {
+			return strconv.FormatInt(entry.ID, 10), nil
+		}
+		return true, nil
+	}
+
+	if urls := c.QueryParams()["urls[]"]; len(urls) > 0 {
+		result := make(map[string]any, len(urls))
+		for _, pageURL := range urls {
+			found, err := lookup(pageURL)
+			if err != nil {
+				return writeServiceError(c, err)
+			}
+			result[pageURL] = found
+		}
+		return c.JSON(http.StatusOK, result)
+	}
+
+	var v validator
+	pageURL := c.QueryParam("url")
+	v.required("url", pageURL)
+	if v.failed() {
+		return v.write(c)
+	}
+	found, err := lookup(pageURL)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	if returnID {
+		return c.JSON(http.StatusOK, map[string]any{"id": found})
+	}
+	return c.JSON(http.StatusOK, map[string]any{"exists": found})
+}
+
+// Tags lists tags. Gist has none; clients still ask during sync.
+func (h *WallabagHandler) Tags(c echo.Context) error {
+	return c.JSON(http.StatusOK, []struct{}{})
+}
+
+func toWallabagEntry(entry model.Entry, withContent bool) wallabagEntry {
+	out := wallabagEntry{
+		ID:          strconv.FormatInt(entry.ID, 10),
+		Title:       stringValue(entry.Title),
+		URL:         stringValue(entry.URL),
+		GivenURL:    stringValue(entry.URL),
+		CreatedAt:   entry.CreatedAt.Format(wallabagTimeLayout),
+		UpdatedAt:   entry.UpdatedAt.Format(wallabagTimeLayout),
+		PublishedBy: []string{},
+		MimeType:    "text/html",
+		Tags:        []struct{}{},
+		Annotations: []struct{}{},
+		UserID:      1,
+		UserName:    "gist",
+	}
+	if entry.Read {
+		out.IsArchived = 1
+	}
+	if entry.Starred {
+		out.IsStarred = 1
+	}
+	if entry.PublishedAt != nil {
+		published := entry.PublishedAt.Format(wallabagTimeLayout)
+		out.PublishedAt = &published
+	}
+	if entry.Author != nil && *entry.Author != "" {
+		out.PublishedBy = []string{*entry.Author}
+	}
+	if parsed, err := url.Parse(out.URL); err == nil {
+		out.DomainName = parsed.Hostname()
+	}
+	if entry.ThumbnailURL != nil && *entry.ThumbnailURL != "" {
+		out.PreviewPicture = entry.ThumbnailURL
+	}
+
+	content := stringValue(entry.ReadableContent)
+	if content == "" {
+		content = stringValue(entry.Content)
+	}
+	if withContent {
+		out.Content = &content
+		out.ReadingTime = service.ReadingMinutes(content)
+	}
+	return out
+}
+
+// wallabagPageLinks builds the HAL links of a list page from the request URL.
+func wallabagPageLinks(c echo.Context, page, pages int) map[string]wallabagLink {
+	base := c.Scheme() + "://" + c.Request().Host + c.Request().URL.Path
+	href := func(p int) wallabagLink {
+		query := c.Request().URL.Query()
+		query.Set("page", strconv.Itoa(p))
+		return wallabagLink{Href: base + "?" + query.Encode()}
+	}
+	links := map[string]wallabagLink{"self": href(page), "first": href(1)}
+	if pages > 0 {
+		links["last"] = href(pages)
+	}
+	if page < pages {
+		links["next"] = href(page + 1)
+	}
+	return links
+}
+
+// wallabagEntryID parses the :id path parameter, which clients may suffix
+// with .json.
+func wallabagEntryID(c echo.Context) (int64, bool) {
+	id, err := strconv.ParseInt(strings.TrimSuffix(c.Param("id"), ".json"), 10, 64)
+	return id, err == nil && id > 0
+}
+
+// wallabagBool parses a 0/1 query filter; empty means no filter.
+func wallabagBool(value string) *bool {
+	if value == "" {
+		return nil
+	}
+	b := value == "1"
+	return &b
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/service"
+)
+
+// maxWebSubPushSize caps the feed content a hub may push at once.
+const maxWebSubPushSize = 10 << 20
+
+// WebSubHandler is the callback WebSub hubs verify subscriptions with and
+// push new feed content to. Hubs cannot authenticate as a user, so its
+// routes are open; pushed content is checked against the signature secret
+// of the feed's subscription instead.
+type WebSubHandler struct {
+	websub  service.WebSubService
+	refresh service.RefreshService
+}
+
+func NewWebSubHandler(websub service.WebSubService, refresh service.RefreshService) *WebSubHandler {
+	return &WebSubHandler{websub: websub, refresh: refresh}
+}
+
+func (h *WebSubHandler) RegisterRoutes(g *echo.Group) {
+	g.GET("/websub/callback/:feedId", h.Verify)
+	g.POST("/websub/callback/:feedId", h.Receive)
+}
+
+// Verify answers a hub checking a subscription.
+// @Summary Verify a WebSub subscription
+// @Description Called by a WebSub hub to verify the subscription of a feed that this instance requested (see GIST_PUBLIC_URL). For hub.mode=subscribe with the subscribed topic, the subscription is activated for hub.lease_seconds and hub.challenge is echoed as text/plain; hub.mode=denied records that the hub refused it. Requests for subscriptions this instance did not make, including unsubscriptions, get 404.
+// @Tags websub
+// @Produce plain
+// @Param feedId path int true "Feed ID"
+// @Param hub.mode query string true "subscribe, unsubscribe or denied"
+// @Param hub.topic query string true "Topic URL of the subscription"
+// @Param hub.challenge query string false "Challenge to echo (subscribe)"
+// @Param hub.lease_seconds query int false "Lease granted by the hub (subscribe)"
+// @Success 200 {string} string "The challenge"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /websub/callback/{feedId} [get]
+func (h *WebSubHandler) Verify(c echo.Context) error {
+	feedID, err := parseIDParam(c, "feedId")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	var v validator
+	leaseSeconds := v.queryInt(c, "hub.lease_seconds", 0)
+	if v.failed() {
+		return v.write(c)
+	}
+	challenge, err := h.websub.Verify(c.Request().Context(), feedID, c.QueryParam("hub.mode"), c.QueryParam("hub.topic"), c.QueryParam("hub.challenge"), leaseSeconds)
+	if err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.String(http.StatusOK, challenge)
+}
+
+// Receive ingests feed content pushed by a hub.
+// @Summary Receive WebSub content
+// @Description Called by a WebSub hub with new content of a subscribed feed, signed in X-Hub-Signature (sha1, sha256, sha384 or sha512 HMAC with the subscription's secret). The entries are stored at once, as a refresh would. Content with a missing or wrong signature is acknowledged but ignored, as the WebSub specification asks; content for a feed without an active subscription gets 404.
+// @Tags websub
+// @Accept xml
+// @Param feedId path int true "Feed ID"
+// @Param X-Hub-Signature header string true "method=hex HMAC of the body"
+// @Success 204 "No Content"
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Router /websub/callback/{feedId} [post]
+func (h *WebSubHandler) Receive(c echo.Context) error {
+	feedID, err := parseIDParam(c, "feedId")
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, maxWebSubPushSize))
+	if err != nil {
+		return Error(c, CodeInvalidRequest, "invalid request")
+	}
+	ctx := c.Request().Context()
+	if err := h.websub.Authenticate(ctx, feedID, body, c.Request().Header.Get("X-Hub-Signature")); err != nil {
+		if errors.Is(err, service.ErrInvalid) {
+			c.Logger().Warnf("websub: ignored content pushed for feed %d: %v", feedID, err)
+			return c.NoContent(http.StatusNoContent)
+		}
+		return writeServiceError(c, err)
+	}
+	if err := h.refresh.Ingest(ctx, feedID, body); err != nil {
+		return writeServiceError(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,134 @@
+package htmlclean
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// TextElements returns the elements of article text, such as paragraphs,
+// headings, links, lists and tables, together with extra.
+func TextElements(extra ...atom.Atom) map[atom.Atom]bool {
+	elements := map[atom.Atom]bool{
+		atom.P: true, atom.Br: true, atom.Hr: true, atom.Div: true, atom.Span: true,
+		atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+		atom.A: true, atom.Em: true, atom.Strong: true, atom.B: true, atom.I: true, atom.U: true,
+		atom.S: true, atom.Sub: true, atom.Sup: true, atom.Small: true, atom.Mark: true,
+		atom.Del: true, atom.Ins: true, atom.Abbr: true, atom.Cite: true, atom.Q: true,
+		atom.Kbd: true, atom.Time: true, atom.Blockquote: true, atom.Pre: true, atom.Code: true,
+		atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+		atom.Table: true, atom.Caption: true, atom.Thead: true, atom.Tbody: true, atom.Tfoot: true,
+		atom.Tr: true, atom.Th: true, atom.Td: true, atom.Figure: true, atom.Figcaption: true,
+		atom.Section: true, atom.Article: true,
+	}
+	for _, a := range extra {
+		elements[a] = true
+	}
+	return elements
+}
+
+// Policy is what a cleaned HTML fragment keeps.
+type Policy struct {
+	// Elements are copied with their allowed attributes. Other elements are
+	// unwrapped, keeping their text, unless listed in Dropped.
+	Elements map[atom.Atom]bool
+	// Dropped elements are removed together with their content.
+	Dropped map[atom.Atom]bool
+	// Attributes are the attributes copied onto kept elements.
+	Attributes map[string]bool
+	// Required names the attribute a kept element is dropped without, such
+	// as an img without a usable src.
+	Required map[atom.Atom]string
+	// URL returns the value to keep for the href or src attribute of n, or
+	// "" to drop it. Nil keeps the values as they are.
+	URL func(n *html.Node, key, val string) string
+	// Text returns the text to keep for a text node. Nil keeps it as it is.
+	Text func(text string) string
+}
+
+// Clean reduces an HTML fragment to the elements and attributes p keeps.
+func (p Policy) Clean(content string) (string, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		for _, kept := range p.clean(node) {
+			if err := html.Render(&buf, kept); err != nil {
+				return "", err
+			}
+		}
+	}
+	return buf.String(), nil
+}
+
+// clean returns the nodes that replace n: n itself with allowed attributes
+// and cleaned children, its cleaned children if n is unwrapped, or nothing.
+func (p Policy) clean(n *html.Node) []*html.Node {
+	switch n.Type {
+	case html.TextNode:
+		text := n.Data
+		if p.Text != nil {
+			text = p.Text(text)
+		}
+		return []*html.Node{{Type: html.TextNode, Data: text}}
+	case html.ElementNode:
+	default:
+		return nil
+	}
+	if p.Dropped[n.DataAtom] {
+		return nil
+	}
+
+	var children []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		children = append(children, p.clean(c)...)
+	}
+	if !p.Elements[n.DataAtom] || n.Namespace != "" {
+		return children
+	}
+
+	out := &html.Node{Type: html.ElementNode, Data: n.DataAtom.String(), DataAtom: n.DataAtom}
+	for _, attr := range n.Attr {
+		if attr.Namespace != "" || !p.Attributes[attr.Key] {
+			continue
+		}
+		val := attr.Val
+		if (attr.Key == "href" || attr.Key == "src") && p.URL != nil {
+			if val = p.URL(n, attr.Key, val); val == "" {
+				continue
+			}
+		}
+		out.Attr = append(out.Attr, html.Attribute{Key: attr.Key, Val: val})
+	}
+	if required, ok := p.Required[n.DataAtom]; ok && !hasAttr(out, required) {
+		return nil
+	}
+	for _, c := range children {
+		out.AppendChild(c)
+	}
+	return []*html.Node{out}
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// IsWebURL reports whether href is an http(s) URL.
+func IsWebURL(href string) bool {
+	href = strings.ToLower(strings.TrimSpace(href))
+	return strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://")
+}
@@ -0,0 +1,35 @@
+package htmlclean
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+func TestPolicy_Clean(t *testing.T) {
+	policy := Policy{
+		Elements:   TextElements(atom.Img),
+		Dropped:    map[atom.Atom]bool{atom.Script: true},
+		Attributes: map[string]bool{"href": true, "src": true},
+		Required:   map[atom.Atom]string{atom.Img: "src"},
+		URL: func(_ *html.Node, _, val string) string {
+			if !IsWebURL(val) {
+				return ""
+			}
+			return val
+		},
+		Text: strings.ToUpper,
+	}
+
+	got, err := policy.Clean(`<p class="x">hi <font>there</font><script>alert(1)</script></p>` +
+		`<a href="javascript:x()">a</a><a href="https://example.com">b</a><img src="/rel.png"><img src="https://example.com/i.png">`)
+	if err != nil {
+		t.Fatalf("clean: %v", err)
+	}
+	want := `<p>HI THERE</p><a>A</a><a href="https://example.com">B</a><img src="https://example.com/i.png"/>`
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/handler"
+)
+
+// tokenHeader is an alternative to "Authorization: Bearer" for simple clients.
+const tokenHeader = "X-Gist-Token"
+
+// requestToken extracts the API token from the request headers.
+func requestToken(c echo.Context) string {
+	if token := c.Request().Header.Get(tokenHeader); token != "" {
+		return token
+	}
+	auth := c.Request().Header.Get(echo.HeaderAuthorization)
+	if strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	return ""
+}
+
+// hasValidToken reports whether the request carries the configured API token.
+func hasValidToken(c echo.Context, apiToken string) bool {
+	if apiToken == "" {
+		return false
+	}
+	token := requestToken(c)
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(apiToken)) == 1
+}
+
+// requireToken rejects requests that don't carry the API token. With no
+// token configured, guarded routes are closed.
+func requireToken(apiToken string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !hasValidToken(c, apiToken) {
+				return handler.Error(c, handler.CodeUnauthorized, "a valid API token is required")
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	nethttp "net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/service"
+)
+
+// conditionalRoutes opts read-heavy routes that clients poll into ETag
+// revalidation, keyed by method and route pattern, with the tables their
+// responses are built from.
+var conditionalRoutes = map[string][]string{
+	nethttp.MethodGet + " /api/entries":       {service.VersionEntries, service.VersionFeeds, service.VersionFolders, service.VersionEntryTags},
+	nethttp.MethodGet + " /api/feeds":         {service.VersionFeeds},
+	nethttp.MethodGet + " /api/unread-counts": {service.VersionEntries, service.VersionFeeds, service.VersionFolders},
+	nethttp.MethodGet + " /api/plain/entries": {service.VersionEntries, service.VersionFeeds, service.VersionFolders, service.VersionEntryTags},
+}
+
+// conditionalGet gives the responses of conditionalRoutes a weak ETag
+// derived from the version of their tables, and answers 304 Not Modified
+// without running the handler when If-None-Match still matches. The version
+// is read before the handler runs, so a change racing the request only
+// costs the client one more full response.
+func conditionalGet(versions service.DataVersionService) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			tables, ok := conditionalRoutes[req.Method+" "+c.Path()]
+			if !ok {
+				return next(c)
+			}
+			version, err := versions.Version(req.Context(), tables...)
+			if err != nil {
+				log.Printf("etag %s: %v", c.Path(), err)
+				return next(c)
+			}
+
+			etag := weakETag(version, req.URL.RawQuery, req.Header.Get("Prefer"))
+			res := c.Response()
+			res.Before(func() {
+				// Errors are not worth revalidating
+				if res.Status == nethttp.StatusOK || res.Status == nethttp.StatusNotModified {
+					res.Header().Set("ETag", etag)
+					res.Header().Set("Cache-Control", "no-cache")
+				}
+			})
+			if etagMatches(req.Header.Get("If-None-Match"), etag) {
+				return c.NoContent(nethttp.StatusNotModified)
+			}
+			return next(c)
+		}
+	}
+}
+
+// weakETag hashes the data version together with what else shapes the
+// response: the query, the Prefer header and the server version.
+func weakETag(version, query, prefer string) string {
+	sum := sha256.Sum256([]byte(config.AppVersion + "\n" + version + "\n" + query + "\n" + prefer))
+	return `W/"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 asks for If-None-Match.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
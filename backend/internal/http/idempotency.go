@@ -0,0 +1,166 @@
+package http
+
+import (
+	"bytes"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/handler"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	idempotencyTTL            = 24 * time.Hour
+	maxIdempotencyKeyLength   = 255
+	maxIdempotencyEntries     = 10000
+	// Larger responses are passed through but not cached.
+	maxIdempotentResponseSize = 1 << 20
+)
+
+type idempotencyEntry struct {
+	done        bool
+	status      int
+	contentType string
+	body        []byte
+	expiresAt   time.Time
+}
+
+// idempotencyStore keeps recent POST responses keyed by route and Idempotency-Key.
+type idempotencyStore struct {
+	mu      sync.Mutex
+	entries map[string]*idempotencyEntry
+}
+
+func newIdempotencyStore() *idempotencyStore {
+	return &idempotencyStore{entries: make(map[string]*idempotencyEntry)}
+}
+
+// reserve returns a copy of the cached entry for key, or claims the key for a
+// new request. The copy is taken under the lock because complete may still be
+// filling in the entry.
+func (s *idempotencyStore) reserve(key string, now time.Time) (idempotencyEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		return *entry, false
+	}
+	if len(s.entries) >= maxIdempotencyEntries {
+		s.evict(now)
+	}
+	s.entries[key] = &idempotencyEntry{expiresAt: now.Add(idempotencyTTL)}
+	return idempotencyEntry{}, true
+}
+
+func (s *idempotencyStore) complete(key string, status int, contentType string, body []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry, ok := s.entries[key]; ok {
+		entry.done = true
+		entry.status = status
+		entry.contentType = contentType
+		entry.body = body
+	}
+}
+
+func (s *idempotencyStore) release(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+}
+
+// evict drops expired entries, then arbitrary finished ones if still full. Caller holds mu.
+func (s *idempotencyStore) evict(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+	for key, entry := range s.entries {
+		if len(s.entries) < maxIdempotencyEntries {
+			return
+		}
+		if entry.done {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// captureWriter mirrors the response body so it can be replayed.
+type captureWriter struct {
+	nethttp.ResponseWriter
+	buf      bytes.Buffer
+	overflow bool
+}
+
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if !w.overflow {
+		if w.buf.Len()+len(b) > maxIdempotentResponseSize {
+			w.overflow = true
+			w.buf.Reset()
+		} else {
+			w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *captureWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(nethttp.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *captureWriter) Unwrap() nethttp.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// idempotency replays the stored response when a POST to the API is retried
+// with the same Idempotency-Key, so retries on flaky networks cannot create
+// duplicate feeds or start a second import. A retry that arrives while the
+// first request is still running gets 409. Server errors and streamed
+// responses are not stored, so those requests can be retried for real.
+func idempotency(store *idempotencyStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			key := strings.TrimSpace(req.Header.Get(idempotencyKeyHeader))
+			if key == "" || req.Method != nethttp.MethodPost || !strings.HasPrefix(req.URL.Path, "/api/") {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return handler.Error(c, handler.CodeInvalidRequest, "Idempotency-Key is too long")
+			}
+
+			storeKey := req.URL.RequestURI() + " " + key
+			if entry, claimed := store.reserve(storeKey, time.Now()); !claimed {
+				if !entry.done {
+					return handler.Error(c, handler.CodeIdempotencyKeyInUse, "a request with this Idempotency-Key is still in progress")
+				}
+				c.Response().Header().Set(idempotencyReplayedHeader, "true")
+				return c.Blob(entry.status, entry.contentType, entry.body)
+			}
+
+			res := c.Response()
+			writer := &captureWriter{ResponseWriter: res.Writer}
+			res.Writer = writer
+			err := next(c)
+			res.Writer = writer.ResponseWriter
+
+			contentType := res.Header().Get(echo.HeaderContentType)
+			if err != nil || !res.Committed || res.Status >= nethttp.StatusInternalServerError ||
+				writer.overflow || strings.HasPrefix(contentType, "text/event-stream") {
+				store.release(storeKey)
+				return err
+			}
+			store.complete(storeKey, res.Status, contentType, writer.buf.Bytes())
+			return nil
+		}
+	}
+}
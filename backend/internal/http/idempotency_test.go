@@ -0,0 +1,138 @@
+package http
+
+import (
+	"fmt"
+	nethttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/handler"
+)
+
+func newIdempotentServer(h echo.HandlerFunc) *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = handler.HTTPErrorHandler
+	e.Use(idempotency(newIdempotencyStore()))
+	e.POST("/api/feeds", h)
+	return e
+}
+
+func postWithKey(e *echo.Echo, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(nethttp.MethodPost, "/api/feeds", nil)
+	req.Header.Set(idempotencyKeyHeader, key)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	var calls int32
+	e := newIdempotentServer(func(c echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.JSON(nethttp.StatusCreated, map[string]int32{"call": n})
+	})
+
+	first := postWithKey(e, "abc")
+	second := postWithKey(e, "abc")
+
+	if calls != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", calls)
+	}
+	if second.Code != nethttp.StatusCreated || second.Body.String() != first.Body.String() {
+		t.Errorf("expected the first response to be replayed, got %d %q", second.Code, second.Body.String())
+	}
+	if second.Header().Get(idempotencyReplayedHeader) != "true" {
+		t.Error("expected the replayed response to be marked")
+	}
+	if first.Header().Get(idempotencyReplayedHeader) != "" {
+		t.Error("expected the original response not to be marked")
+	}
+}
+
+func TestIdempotency_RejectsRetryWhileInProgress(t *testing.T) {
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	e := newIdempotentServer(func(c echo.Context) error {
+		close(started)
+		<-finish
+		return c.NoContent(nethttp.StatusNoContent)
+	})
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postWithKey(e, "abc") }()
+	<-started
+
+	retry := postWithKey(e, "abc")
+	if retry.Code != nethttp.StatusConflict || !strings.Contains(retry.Body.String(), string(handler.CodeIdempotencyKeyInUse)) {
+		t.Errorf("expected %s, got %d %q", handler.CodeIdempotencyKeyInUse, retry.Code, retry.Body.String())
+	}
+
+	close(finish)
+	if first := <-done; first.Code != nethttp.StatusNoContent {
+		t.Errorf("expected the first request to finish, got %d", first.Code)
+	}
+}
+
+func TestIdempotency_ReleasesKeyOnError(t *testing.T) {
+	var calls int32
+	e := newIdempotentServer(func(c echo.Context) error {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			return c.NoContent(nethttp.StatusBadGateway)
+		case 2:
+			return echo.NewHTTPError(nethttp.StatusBadRequest, "bad")
+		default:
+			return c.NoContent(nethttp.StatusCreated)
+		}
+	})
+
+	for i, want := range []int{nethttp.StatusBadGateway, nethttp.StatusBadRequest, nethttp.StatusCreated} {
+		rec := postWithKey(e, "abc")
+		if rec.Code != want {
+			t.Errorf("request %d: got %d, want %d", i+1, rec.Code, want)
+		}
+		if rec.Header().Get(idempotencyReplayedHeader) != "" {
+			t.Errorf("request %d: expected a real response, got a replay", i+1)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("expected every failed request to be retried for real, handler ran %d times", calls)
+	}
+}
+
+func TestIdempotencyStore_Evict(t *testing.T) {
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	store := newIdempotencyStore()
+	for i := 0; i < maxIdempotencyEntries; i++ {
+		entry := &idempotencyEntry{expiresAt: now.Add(idempotencyTTL)}
+		switch {
+		case i < 10:
+			entry.expiresAt = now
+		case i%2 == 0:
+			entry.done = true
+		}
+		store.entries[fmt.Sprintf("key-%d", i)] = entry
+	}
+
+	if _, claimed := store.reserve("new", now); !claimed {
+		t.Fatal("expected the new key to be claimed")
+	}
+	if len(store.entries) > maxIdempotencyEntries {
+		t.Errorf("expected the store to stay within %d entries, has %d", maxIdempotencyEntries, len(store.entries))
+	}
+	for i := 0; i < 10; i++ {
+		if _, ok := store.entries[fmt.Sprintf("key-%d", i)]; ok {
+			t.Errorf("expected expired key-%d to be evicted", i)
+		}
+	}
+	for i := 11; i < maxIdempotencyEntries; i += 2 {
+		if _, ok := store.entries[fmt.Sprintf("key-%d", i)]; !ok {
+			t.Fatalf("expected in-progress key-%d to be kept", i)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	nethttp "net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/handler"
+)
+
+// demoAllowedRoutes are the mutations still permitted in demo mode,
+// keyed by method and route pattern.
+var demoAllowedRoutes = map[string]bool{
+	nethttp.MethodPatch + " /api/entries/:id/read":    true,
+	nethttp.MethodPatch + " /api/entries/:id/starred": true,
+	nethttp.MethodPost + " /api/entries/mark-read":    true,
+	nethttp.MethodPost + " /api/reader/:id/read":      true,
+}
+
+// pushRoutes receive content from outside, like refreshes fetch it, so they
+// stay open in every mode.
+var pushRoutes = map[string]bool{
+	nethttp.MethodPost + " /api/websub/callback/:feedId": true,
+}
+
+// modeGuard rejects mutating API requests in demo and read-only modes.
+// Requests carrying the API token bypass the guard so operators can still
+// manage a public instance.
+func modeGuard(mode, apiToken string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set("X-App-Mode", mode)
+			if mode == config.ModeNormal || !isMutation(c.Request().Method) {
+				return next(c)
+			}
+			if !strings.HasPrefix(c.Request().URL.Path, "/api/") {
+				return next(c)
+			}
+			if pushRoutes[c.Request().Method+" "+c.Path()] {
+				return next(c)
+			}
+			if mode == config.ModeDemo && demoAllowedRoutes[c.Request().Method+" "+c.Path()] {
+				return next(c)
+			}
+			if hasValidToken(c, apiToken) {
+				return next(c)
+			}
+			if mode == config.ModeDemo {
+				return handler.Error(c, handler.CodeDemoMode, "This is a demo instance: only marking entries as read or starred is allowed")
+			}
+			return handler.Error(c, handler.CodeReadOnly, "This instance is read-only")
+		}
+	}
+}
+
+func isMutation(method string) bool {
+	switch method {
+	case nethttp.MethodGet, nethttp.MethodHead, nethttp.MethodOptions:
+		return false
+	default:
+		return true
+	}
+}
@@ -6,11 +6,14 @@ import (
 	echoSwagger "github.com/swaggo/echo-swagger"
 
 	_ "gist/backend/docs"
+	"gist/backend/internal/config"
 	"gist/backend/internal/handler"
+	"gist/backend/internal/service"
 )
 
 func NewRouter(
 	folderHandler *handler.FolderHandler,
+	filterHandler *handler.FilterHandler,
 	feedHandler *handler.FeedHandler,
 	entryHandler *handler.EntryHandler,
 	opmlHandler *handler.OPMLHandler,
@@ -18,28 +21,87 @@ func NewRouter(
 	proxyHandler *handler.ProxyHandler,
 	settingsHandler *handler.SettingsHandler,
 	aiHandler *handler.AIHandler,
-	staticDir string,
+	taskHandler *handler.TaskHandler,
+	syncHandler *handler.SyncHandler,
+	metricsHandler *handler.MetricsHandler,
+	statsHandler *handler.StatsHandler,
+	eventHandler *handler.EventHandler,
+	schedulerHandler *handler.SchedulerHandler,
+	linkCheckHandler *handler.LinkCheckHandler,
+	opdsHandler *handler.OPDSHandler,
+	readerHandler *handler.ReaderHandler,
+	plainHandler *handler.PlainHandler,
+	wallabagHandler *handler.WallabagHandler,
+	captureHandler *handler.CaptureHandler,
+	notificationHandler *handler.NotificationHandler,
+	webSubHandler *handler.WebSubHandler,
+	adminHandler *handler.AdminHandler,
+	integrationsHandler *handler.IntegrationsHandler,
+	backupHandler *handler.BackupHandler,
+	preferencesHandler *handler.PreferencesHandler,
+	playbackHandler *handler.PlaybackHandler,
+	tagHandler *handler.TagHandler,
+	dataVersions service.DataVersionService,
+	cfg config.Config,
 ) *echo.Echo {
 	e := echo.New()
 	e.HideBanner = true
+	e.HTTPErrorHandler = handler.HTTPErrorHandler
 	e.Use(middleware.Recover())
 	e.Use(middleware.Logger())
+	e.Use(appVersionHeader())
+	e.Use(modeGuard(cfg.Mode, cfg.APIToken))
+	e.Use(routeTimeout())
+	e.Use(conditionalGet(dataVersions))
+	e.Use(idempotency(newIdempotencyStore()))
 
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	metricsHandler.RegisterRoutes(e, requireToken(cfg.APIToken))
+	wallabagHandler.RegisterRoutes(e, requireToken(cfg.APIToken))
 
 	api := e.Group("/api")
 	folderHandler.RegisterRoutes(api)
+	filterHandler.RegisterRoutes(api)
 	feedHandler.RegisterRoutes(api)
 	entryHandler.RegisterRoutes(api)
 	opmlHandler.RegisterRoutes(api)
 	proxyHandler.RegisterRoutes(api)
 	settingsHandler.RegisterRoutes(api)
+	integrationsHandler.RegisterRoutes(api)
+	backupHandler.RegisterRoutes(api)
+	preferencesHandler.RegisterRoutes(api)
+	playbackHandler.RegisterRoutes(api)
+	tagHandler.RegisterRoutes(api)
 	aiHandler.RegisterRoutes(api)
+	taskHandler.RegisterRoutes(api)
+	schedulerHandler.RegisterRoutes(api)
+	statsHandler.RegisterRoutes(api)
+	eventHandler.RegisterRoutes(api)
+	linkCheckHandler.RegisterRoutes(api)
+	notificationHandler.RegisterRoutes(api)
+	webSubHandler.RegisterRoutes(api)
+	opdsHandler.RegisterRoutes(api)
+	readerHandler.RegisterRoutes(api)
+	syncHandler.RegisterRoutes(api, requireToken(cfg.APIToken))
+	captureHandler.RegisterRoutes(api, requireToken(cfg.APIToken))
+	plainHandler.RegisterRoutes(api, requireToken(cfg.APIToken))
+	adminHandler.RegisterRoutes(api, requireToken(cfg.APIToken))
+	iconHandler.RegisterAPIRoutes(api)
 
 	// Icon routes with cache recovery
 	iconHandler.RegisterRoutes(e)
 
-	registerStatic(e, staticDir)
+	registerStatic(e, cfg.StaticDir)
 
 	return e
 }
+
+// appVersionHeader exposes the running version so clients can detect upgrades.
+func appVersionHeader() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Response().Header().Set("X-App-Version", config.AppVersion)
+			return next(c)
+		}
+	}
+}
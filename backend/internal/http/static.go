@@ -1,49 +1,82 @@
 package http
 
 import (
+	"io/fs"
 	nethttp "net/http"
 	"os"
 	"path"
-	"path/filepath"
 	"strings"
 
 	"github.com/labstack/echo/v4"
+
+	"gist/backend/internal/config"
+	"gist/backend/web"
 )
 
-func registerStatic(e *echo.Echo, dir string) {
-	if dir == "" {
-		return
+const (
+	// Vite emits content-hashed files under assets/, so they never change in place.
+	immutableCacheControl = "public, max-age=31536000, immutable"
+	// index.html, sw.js and manifest must be revalidated to pick up new releases.
+	revalidateCacheControl = "no-cache"
+)
+
+// resolveStaticFS picks where the frontend is served from:
+// an explicit directory wins, then the embedded build, then a local dist for development.
+func resolveStaticFS(dir string) (fs.FS, string) {
+	if dir != "" {
+		return os.DirFS(dir), dir
+	}
+	if embedded := web.Dist(); embedded != nil {
+		return embedded, "embedded"
 	}
-	indexPath := filepath.Join(dir, "index.html")
-	info, err := os.Stat(indexPath)
-	if err != nil || info.IsDir() {
-		e.Logger.Warnf("static index not found at %s", indexPath)
+	detected := config.DetectStaticDir()
+	return os.DirFS(detected), detected
+}
+
+func registerStatic(e *echo.Echo, dir string) {
+	fsys, source := resolveStaticFS(dir)
+	index, err := fs.ReadFile(fsys, "index.html")
+	if err != nil {
+		e.Logger.Warnf("static index not found in %s", source)
 		return
 	}
 
-	fileServer := nethttp.FileServer(nethttp.Dir(dir))
+	fileServer := nethttp.FileServer(nethttp.FS(fsys))
+
+	serveIndex := func(c echo.Context) error {
+		c.Response().Header().Set("Cache-Control", revalidateCacheControl)
+		return c.HTMLBlob(nethttp.StatusOK, index)
+	}
 
 	e.GET("/*", func(c echo.Context) error {
 		requestPath := c.Request().URL.Path
 		if requestPath == "/api" || strings.HasPrefix(requestPath, "/api/") {
 			return echo.ErrNotFound
 		}
-		if requestPath == "/" {
-			return c.File(indexPath)
-		}
 
 		cleanPath := strings.TrimPrefix(path.Clean(requestPath), "/")
-		if cleanPath == "." || cleanPath == "" {
-			return c.File(indexPath)
+		if cleanPath == "." || cleanPath == "" || cleanPath == "index.html" {
+			return serveIndex(c)
 		}
 
-		candidate := filepath.Join(dir, cleanPath)
-		fileInfo, err := os.Stat(candidate)
+		fileInfo, err := fs.Stat(fsys, cleanPath)
 		if err == nil && !fileInfo.IsDir() {
+			if strings.HasPrefix(cleanPath, "assets/") {
+				c.Response().Header().Set("Cache-Control", immutableCacheControl)
+			} else {
+				c.Response().Header().Set("Cache-Control", revalidateCacheControl)
+			}
 			fileServer.ServeHTTP(c.Response(), c.Request())
 			return nil
 		}
 
-		return c.File(indexPath)
+		// A missing hashed asset means a stale client; serving index.html would
+		// only surface as a confusing MIME error in the browser.
+		if strings.HasPrefix(cleanPath, "assets/") {
+			return echo.ErrNotFound
+		}
+
+		// SPA history fallback
+		return serveIndex(c)
 	})
 }
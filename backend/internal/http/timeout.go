@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	nethttp "net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultRouteTimeout bounds ordinary CRUD requests.
+const defaultRouteTimeout = 15 * time.Second
+
+// routeTimeouts overrides the default for routes that legitimately run long,
+// keyed by method and route pattern. Zero disables the deadline: the import
+// status and event streams live as long as the client watches them.
+var routeTimeouts = map[string]time.Duration{
+	nethttp.MethodPost + " /api/feeds":                         time.Minute,
+	nethttp.MethodGet + " /api/feeds/preview":                  time.Minute,
+	nethttp.MethodGet + " /api/feeds/discover":                 time.Minute,
+	nethttp.MethodPost + " /api/feeds/scraped":                 time.Minute,
+	nethttp.MethodPost + " /api/feeds/scraped/preview":         time.Minute,
+	nethttp.MethodPost + " /api/entries/:id/fetch-readable":    time.Minute,
+	nethttp.MethodGet + " /api/entries/export":                 5 * time.Minute,
+	nethttp.MethodGet + " /api/backups/:name":                  5 * time.Minute,
+	nethttp.MethodPost + " /api/opml/import":                   5 * time.Minute,
+	nethttp.MethodGet + " /api/opml/import/status":             0,
+	nethttp.MethodGet + " /api/events":                         0,
+	nethttp.MethodPost + " /api/admin/backup":                  10 * time.Minute,
+	nethttp.MethodPost + " /api/admin/restore":                 10 * time.Minute,
+	nethttp.MethodPost + " /api/feeds/:id/refresh":             time.Minute,
+	nethttp.MethodPost + " /api/settings/ai/test":              time.Minute,
+	nethttp.MethodPost + " /api/ai/summarize":                  10 * time.Minute,
+	nethttp.MethodPost + " /api/ai/translate":                  10 * time.Minute,
+	nethttp.MethodPost + " /api/ai/translate/batch":            10 * time.Minute,
+	nethttp.MethodPost + " /api/capture":                       time.Minute,
+	nethttp.MethodPost + " /api/entries/:id/save-to/:provider": time.Minute,
+	nethttp.MethodPost + " /api/share":                         time.Minute,
+	nethttp.MethodPost + " /wallabag/api/entries":              time.Minute,
+	nethttp.MethodPost + " /wallabag/api/entries.json":         time.Minute,
+}
+
+// routeTimeout puts a deadline on the request context. Handlers pass that
+// context down, so a request that overruns its budget, or whose client
+// disconnects, stops its database queries and upstream fetches.
+func routeTimeout() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			timeout := defaultRouteTimeout
+			if d, ok := routeTimeouts[c.Request().Method+" "+c.Path()]; ok {
+				timeout = d
+			}
+			if timeout <= 0 {
+				return next(c)
+			}
+			ctx, cancel := context.WithTimeout(c.Request().Context(), timeout)
+			defer cancel()
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package model
+
+// FeedBandwidth is what was downloaded for a feed on one day.
+type FeedBandwidth struct {
+	FeedID    int64
+	FeedTitle string
+	Day       string // local date, YYYY-MM-DD
+	Bytes     int64
+}
@@ -9,11 +9,160 @@ type Entry struct {
 	URL             *string
 	Content         *string
 	ReadableContent *string
-	ThumbnailURL    *string
-	Author          *string
-	PublishedAt     *time.Time
-	Read            bool
-	Starred         bool
-	CreatedAt       time.Time
+	// ReadableMeta describes the page ReadableContent was extracted from.
+	// Only loaded for a single entry.
+	ReadableMeta ReadableMeta
+	// Snippet is a plain-text preview of Content, computed at ingest.
+	Snippet *string
+	// ContentText and ContentClean are Content as plain text and as
+	// simplified HTML, computed at ingest; nil for entries stored before.
+	// Only loaded for a single entry.
+	ContentText  *string
+	ContentClean *string
+	// ExternalURL is the page a linkblog entry is about, when the feed
+	// links it apart from the entry's own URL.
+	ExternalURL  *string
+	ThumbnailURL *string
+	Author       *string
+	PublishedAt  *time.Time
+	// OriginalPublishedAt is the date the feed gave when it was in the
+	// future and ingest clamped PublishedAt to the time it was seen. Saving
+	// with nil keeps it.
+	OriginalPublishedAt *time.Time
+	// Read and Starred are only saved with a new entry; saving a known one
+	// keeps its state.
+	Read      bool
+	Starred   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// LinkDead is set when the last link check found URL gone; the stored
+	// content is then the only copy. Only loaded for a single entry.
+	LinkDead bool
+	// Revision summarizes the publisher's latest change to the title or text,
+	// nil if the entry never changed. Only loaded for a single entry.
+	Revision *EntryRevision
+	// Images is the ordered gallery of an entry of a picture feed. Only
+	// loaded for a single entry; saving with nil Images keeps the stored ones.
+	Images []string
+	// Attachments are the files the feed attached to the entry, in feed
+	// order. Only loaded for a single entry; saving with nil keeps the
+	// stored ones.
+	Attachments []EntryAttachment
+	// NSFWReason is set when the entry is flagged as not safe for work, to
+	// one of the NSFW constants. Saving with nil keeps an earlier flag.
+	NSFWReason *string
+	// IconPath is the site icon of a saved page, relative to the icons
+	// directory; nil shows the feed's icon. Saving with nil keeps it.
+	IconPath *string
+	// FolderID files the entry under a folder other than its feed's, when a
+	// filter moved it there. Only stored for a new entry.
+	FolderID *int64
+}
+
+// ReadableMeta is what readability extraction learned about an entry's page.
+// Fields are empty when the page did not say.
+type ReadableMeta struct {
+	// Language is the page's language as it declares it, e.g. "ar" or "he-IL".
+	Language string
+	// Direction is the text direction, "rtl" or "ltr".
+	Direction string
+	Byline    string
+	SiteName  string
+}
+
+// PlaybackPosition is where playback of an entry's attachment stopped.
+// UpdatedAt is zero when it was never played.
+type PlaybackPosition struct {
+	EntryID         int64
+	PositionSeconds int64
 	UpdatedAt       time.Time
 }
+
+// EntryAttachment is a file attached to an entry, like a podcast episode's
+// audio. Size and duration are 0 when the feed does not give them.
+type EntryAttachment struct {
+	URL             string
+	MimeType        string
+	Title           string
+	SizeBytes       int64
+	DurationSeconds int64
+}
+
+// Text directions of readable content
+const (
+	DirectionLTR = "ltr"
+	DirectionRTL = "rtl"
+)
+
+// Operations of a batch change to entries
+const (
+	EntryOpRead   = "read"
+	EntryOpUnread = "unread"
+	EntryOpStar   = "star"
+	EntryOpUnstar = "unstar"
+	EntryOpDelete = "delete"
+)
+
+// Why an entry is flagged as not safe for work
+const (
+	NSFWCategory = "category" // the item or feed has an adult category
+	NSFWExplicit = "explicit" // itunes:explicit is set
+	NSFWKeyword  = "keyword"  // the title or a category matches a keyword rule
+	NSFWVision   = "ai"       // the AI image check flagged the thumbnail
+)
+
+// EntryRevision summarizes how an entry changed when the publisher updated it.
+type EntryRevision struct {
+	// TitleBefore is the previous title, set only when the title changed.
+	TitleBefore  *string
+	WordsAdded   int
+	WordsRemoved int
+	// Changes are the first few changed passages of the text, in order.
+	Changes   []TextChange
+	ChangedAt time.Time
+}
+
+// TextChange is a passage of an entry's text that was replaced. Removed or
+// Added is empty for a pure insertion or deletion.
+type TextChange struct {
+	Removed string
+	Added   string
+}
+
+// EntrySummary is the list projection of an entry. It leaves out the content
+// blobs, which are only loaded for a single entry, in favour of the stored snippet.
+type EntrySummary struct {
+	ID           int64
+	FeedID       int64
+	Title        *string
+	URL          *string
+	Snippet      *string
+	ThumbnailURL *string
+	Author       *string
+	PublishedAt  *time.Time
+	Read         bool
+	Starred      bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	NSFWReason   *string
+	IconPath     *string
+}
+
+// ArchivePeriod is the number of entries published in a year ("2025") or
+// month ("2025-03"), in UTC.
+type ArchivePeriod struct {
+	Period      string
+	Count       int
+	UnreadCount int
+}
+
+// EntryStateChange is an entry's current read and starred state, recorded in
+// the change log at position Seq. Entries are identified by feed and entry
+// URL, which stay the same across instances.
+type EntryStateChange struct {
+	Seq     int64
+	FeedURL string
+	URL     string
+	Read    bool
+	Starred bool
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Feed struct {
 	ID           int64
@@ -10,10 +13,116 @@ type Feed struct {
 	SiteURL      *string
 	Description  *string
 	IconPath     *string
-	Type         string // article, picture, notification
+	IconColor    *string // dominant color of the icon, as #rrggbb
+	Type         string  // article, picture, notification, podcast
 	ETag         *string
 	LastModified *string
 	ErrorMessage *string
-	CreatedAt    time.Time
-	UpdatedAt    time.Time
+	ArchivedAt   *time.Time // set when unsubscribed but entries were kept
+	// RefreshInterval is the minutes between scheduled refreshes; nil leaves
+	// it to the scheduler (see service.RefreshInterval).
+	RefreshInterval *int
+	NextRefreshAt   *time.Time // unset until the feed is first refreshed
+	// FullTextURL is an external full-text service (Morss, FiveFilters) to
+	// extract entries with instead of the built-in readability. "{url}"
+	// stands for the escaped entry URL; without it the URL is appended.
+	FullTextURL *string
+	// SummaryInSourceLanguage has AI summaries of the feed's entries written
+	// in the article's own language rather than the global summary language.
+	SummaryInSourceLanguage bool
+	// PrefetchReadability has refresh fetch the readable content of the
+	// feed's new entries, so truncated articles are complete when opened.
+	PrefetchReadability bool
+	// DateQuirks corrects how the dates of the feed's entries are read; nil
+	// when they are taken as the feed gives them.
+	DateQuirks *FeedDateQuirks
+	// Scraper is set on a feed followed by scraping its URL, a web page
+	// without a feed, for the entries its selectors pick out.
+	Scraper *FeedScraper
+	// RequestOverrides replaces what the feed's requests are sent with, for
+	// sites that only answer certain clients; nil sends the defaults.
+	RequestOverrides *FeedRequestOverrides
+	// HasAuth is set when credentials are stored for the feed. They are
+	// stored encrypted and only loaded into Auth where the feed is fetched.
+	HasAuth bool
+	Auth    *FeedAuth
+	// ConsecutiveFailures counts the refreshes that failed since the last
+	// one that did not; each stretches the time to the next refresh.
+	ConsecutiveFailures int
+	// DisabledAt is set when the feed was taken out of scheduled refreshes
+	// after failing too often in a row.
+	DisabledAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// FeedAuth holds the credentials a feed is fetched with: HTTP Basic auth
+// when Username or Password is set, and headers such as an API token.
+type FeedAuth struct {
+	Username string            `json:"username,omitempty"`
+	Password string            `json:"password,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+}
+
+// IsEmpty reports whether a carries no credentials.
+func (a FeedAuth) IsEmpty() bool {
+	return a.Username == "" && a.Password == "" && len(a.Headers) == 0
+}
+
+// FeedDateQuirks corrects the entry dates of a feed whose publisher writes
+// them wrong: without a time zone, day before month, or in the future.
+type FeedDateQuirks struct {
+	// Timezone is the IANA zone dates without an offset are local to, such
+	// as Asia/Shanghai; "" reads them as UTC.
+	Timezone string `json:"timezone,omitempty"`
+	// DayFirst reads numeric dates such as 03/04/2025 as day, month, year.
+	DayFirst bool `json:"dayFirst,omitempty"`
+	// IgnoreFuture never trusts a date in the future: the updated date is
+	// used instead when it is not, otherwise the entry is dated when first
+	// seen.
+	IgnoreFuture bool `json:"ignoreFuture,omitempty"`
+}
+
+// IsEmpty reports whether q corrects nothing.
+func (q FeedDateQuirks) IsEmpty() bool {
+	return q == FeedDateQuirks{}
+}
+
+// FeedScraper holds the CSS selectors that turn a web page into entries.
+// Title, Link and Date select within each item; an empty Title takes the
+// item's text, an empty Link its first link, and an empty Date leaves
+// entries dated when first seen.
+type FeedScraper struct {
+	Items string `json:"items"`
+	Title string `json:"title,omitempty"`
+	Link  string `json:"link,omitempty"`
+	Date  string `json:"date,omitempty"`
+}
+
+// IsEmpty reports whether s selects nothing.
+func (s FeedScraper) IsEmpty() bool {
+	return s == FeedScraper{}
+}
+
+// FeedRequestOverrides replaces the User-Agent and adds headers to every
+// request for a feed and its pages and icon. Unlike FeedAuth it is not
+// secret, and is stored and shown as it is.
+type FeedRequestOverrides struct {
+	UserAgent string            `json:"userAgent,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+// IsEmpty reports whether o overrides nothing.
+func (o FeedRequestOverrides) IsEmpty() bool {
+	return o.UserAgent == "" && len(o.Headers) == 0
+}
+
+// SavedPagesURL is the URL of the feed that holds web pages saved outside of
+// any subscription, such as through the Wallabag API.
+const SavedPagesURL = "gist:saved-pages"
+
+// IsVirtual reports whether the feed only holds entries Gist created itself
+// and has no URL to fetch.
+func (f Feed) IsVirtual() bool {
+	return strings.HasPrefix(f.URL, "gist:")
 }
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// FeedFetch is one refresh attempt of a feed.
+type FeedFetch struct {
+	ID        int64
+	FeedID    int64
+	FetchedAt time.Time
+	// Status is one of the fetch outcomes: ok, not_modified, http_error or error.
+	Status     string
+	HTTPStatus *int
+	Duration   time.Duration
+	NewEntries int
+	Error      *string
+}
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// Filter is a rule that refresh applies to new entries before storing them.
+type Filter struct {
+	ID int64
+	// FeedID limits the rule to one feed; nil applies it to every feed.
+	FeedID *int64
+	// Field is the part of the entry that is matched, one of the FilterField
+	// constants. It is empty for FilterExpr, whose pattern reads any field.
+	Field string
+	// Match is FilterContains (case-insensitive), FilterRegex or FilterExpr.
+	Match   string
+	Pattern string
+	// Action is what happens to a matching entry, one of the FilterAction constants.
+	Action string
+	// FolderID is the folder FilterActionMove files matching entries under.
+	FolderID  *int64
+	Enabled   bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Parts of an entry a filter can match
+const (
+	FilterFieldTitle   = "title"
+	FilterFieldContent = "content"
+	FilterFieldAuthor  = "author"
+	FilterFieldURL     = "url"
+)
+
+// How a filter's pattern is matched
+const (
+	FilterContains = "contains"
+	FilterRegex    = "regex"
+	FilterExpr     = "expr" // the pattern is an expression over the entry
+)
+
+// What a filter does to a matching entry
+const (
+	FilterActionRead = "mark_read"
+	FilterActionStar = "star"
+	FilterActionSkip = "skip" // the entry is not stored at all
+	FilterActionMove = "move"
+	// FilterActionRewrite replaces fields of the entry with those its
+	// expression returns; only FilterExpr rules rewrite.
+	FilterActionRewrite = "rewrite"
+)
@@ -3,10 +3,13 @@ package model
 import "time"
 
 type Folder struct {
-	ID        int64
-	Name      string
-	ParentID  *int64
-	Type      string // article, picture, notification
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID       int64
+	Name     string
+	ParentID *int64
+	Type     string // article, picture, notification, podcast
+	// PregenerateThumbnails caches grid thumbnails of a picture folder's
+	// images right after each refresh.
+	PregenerateThumbnails bool
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
 }
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Import item types
+const (
+	ImportItemFolder = "folder"
+	ImportItemFeed   = "feed"
+)
+
+// ImportItem records a folder or feed created by an OPML import task.
+type ImportItem struct {
+	TaskID    string
+	ItemType  string // folder, feed
+	ItemID    int64
+	CreatedAt time.Time
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// ImportTask is the saved state of an OPML import, kept so an import cut
+// short by a restart can resume and finished ones can still be looked up.
+type ImportTask struct {
+	ID     string
+	Status string // running, done, error, cancelled
+	// Document is the OPML document as JSON, dropped once the task ends.
+	Document  string
+	Total     int
+	Processed int    // feeds finished, in document order
+	Result    string // the import's counts as JSON
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	// FinishedAt is nil while the task runs.
+	FinishedAt *time.Time
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Link check statuses
+const (
+	LinkOK    = "ok"
+	LinkDead  = "dead"  // the page is gone: 404, 410 or an unknown host
+	LinkError = "error" // the check failed in a way that may pass later
+)
+
+// LinkCheck is the latest URL health check of an entry.
+type LinkCheck struct {
+	EntryID    int64
+	Status     string
+	HTTPStatus *int
+	Error      *string
+	CheckedAt  time.Time
+	// FailingSince is when the current run of failed checks began.
+	FailingSince *time.Time
+}
+
+// LinkToCheck is a starred entry whose URL is due for a check.
+type LinkToCheck struct {
+	EntryID int64
+	URL     string
+	// FailingSince carries over from the previous check.
+	FailingSince *time.Time
+}
+
+// BrokenLink is a starred entry whose last check failed.
+type BrokenLink struct {
+	LinkCheck
+	FeedID    int64
+	FeedTitle string
+	Title     *string
+	URL       string
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Notification kinds
+const (
+	NotificationImportDone   = "import_done"
+	NotificationTaskFailed   = "task_failed"
+	NotificationFeedDisabled = "feed_disabled"
+)
+
+// Notification is an entry of the inbox of background work outcomes.
+type Notification struct {
+	ID   int64
+	Kind string
+	// TaskKind is the kind of the task the notification reports on, if any.
+	TaskKind *string
+	// FeedID is the feed the notification reports on, if any.
+	FeedID    *int64
+	Title     string
+	Body      string
+	Read      bool
+	CreatedAt time.Time
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// OutboxMessage is a pending background side effect, written in the same
+// transaction as the change that caused it.
+type OutboxMessage struct {
+	ID            int64
+	Kind          string
+	Payload       string // JSON
+	Attempts      int
+	LastError     *string
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
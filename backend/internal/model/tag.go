@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Tag is a user-defined label entries are organized with.
+type Tag struct {
+	ID   int64
+	Name string
+	// EntryCount is how many entries have the tag. Only set by listing.
+	EntryCount int
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// WebSub subscription states
+const (
+	WebSubPending = "pending" // requested, waiting for the hub to verify it
+	WebSubActive  = "active"  // verified; the hub pushes new content
+	WebSubDenied  = "denied"  // refused by the hub
+)
+
+// WebSubSubscription is a feed's subscription to the WebSub hub its content
+// is pushed from.
+type WebSubSubscription struct {
+	FeedID int64
+	Hub    string
+	// Topic is the URL the hub knows the feed by, its self link.
+	Topic string
+	// Secret signs pushed content, so it can be told from forged requests.
+	Secret string
+	State  string
+	// LeaseExpiresAt is when an active subscription runs out unless renewed.
+	LeaseExpiresAt *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
@@ -0,0 +1,73 @@
+package opds
+
+import (
+	"bytes"
+	"encoding/xml"
+)
+
+const (
+	// AcquisitionType is the media type of a catalog feed listing books.
+	AcquisitionType = "application/atom+xml;profile=opds-catalog;kind=acquisition"
+	// RelAcquisition marks the link that downloads a book.
+	RelAcquisition = "http://opds-spec.org/acquisition"
+	// EPUBType is the media type of an EPUB book.
+	EPUBType = "application/epub+zip"
+
+	atomNamespace = "http://www.w3.org/2005/Atom"
+	dcNamespace   = "http://purl.org/dc/terms/"
+)
+
+// Feed is an OPDS catalog feed: an Atom feed whose entries are books.
+type Feed struct {
+	XMLName xml.Name `xml:"feed"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	XmlnsDC string   `xml:"xmlns:dc,attr"`
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Links   []Link   `xml:"link"`
+	Entries []Entry  `xml:"entry"`
+}
+
+type Link struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type Entry struct {
+	ID      string `xml:"id"`
+	Title   string `xml:"title"`
+	Updated string `xml:"updated"`
+	Authors []Name `xml:"author,omitempty"`
+	Issued  string `xml:"dc:issued,omitempty"`
+	Summary *Text  `xml:"summary,omitempty"`
+	Links   []Link `xml:"link"`
+}
+
+type Name struct {
+	Name string `xml:"name"`
+}
+
+type Text struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// Encode writes the feed as an XML document, declaring the Atom and Dublin
+// Core namespaces.
+func Encode(feed Feed) ([]byte, error) {
+	feed.Xmlns = atomNamespace
+	feed.XmlnsDC = dcNamespace
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(feed); err != nil {
+		return nil, err
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -4,13 +4,20 @@ import (
 	"bytes"
 	"encoding/xml"
 	"io"
+	"strings"
 )
 
+// Namespace is the XML namespace of Gist's own outline attributes, bound to
+// the "gist" prefix on export.
+const Namespace = "https://github.com/dddepg/Gist"
+
 type Document struct {
 	XMLName xml.Name `xml:"opml"`
 	Version string   `xml:"version,attr"`
-	Head    Head     `xml:"head"`
-	Body    Body     `xml:"body"`
+	// GistNS declares the gist prefix; set it to Namespace when outlines carry GistType.
+	GistNS string `xml:"xmlns:gist,attr,omitempty"`
+	Head   Head   `xml:"head"`
+	Body   Body   `xml:"body"`
 }
 
 type Head struct {
@@ -24,14 +31,58 @@ type Body struct {
 }
 
 type Outline struct {
-	Text     string    `xml:"text,attr,omitempty"`
-	Title    string    `xml:"title,attr,omitempty"`
-	Type     string    `xml:"type,attr,omitempty"`
-	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
-	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Text    string `xml:"text,attr,omitempty"`
+	Title   string `xml:"title,attr,omitempty"`
+	Type    string `xml:"type,attr,omitempty"`
+	XMLURL  string `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL string `xml:"htmlUrl,attr,omitempty"`
+	// Category is the OPML 2.0 comma-separated list of slash-delimited categories.
+	Category string `xml:"category,attr,omitempty"`
+	// GistType is the gist:type attribute: the content type of a folder or feed.
+	GistType string    `xml:"https://github.com/dddepg/Gist type,attr,omitempty"`
 	Outlines []Outline `xml:"outline,omitempty"`
 }
 
+// MarshalXML writes GistType under the gist prefix declared on the document.
+// Left to itself encoding/xml would invent a prefix and redeclare it on every
+// outline.
+func (o Outline) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type plain Outline
+	p := plain(o)
+	p.GistType = ""
+	if o.GistType != "" {
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "gist:type"}, Value: o.GistType})
+	}
+	return e.EncodeElement(p, start)
+}
+
+// IsFeed reports whether the outline is a subscription rather than a folder.
+func (o Outline) IsFeed() bool {
+	if strings.TrimSpace(o.XMLURL) != "" {
+		return true
+	}
+	feedType := strings.ToLower(strings.TrimSpace(o.Type))
+	return feedType == "rss" || feedType == "atom" || feedType == "feed"
+}
+
+// CountFeeds returns the number of feed outlines in the document.
+func (d Document) CountFeeds() int {
+	return countFeeds(d.Body.Outlines)
+}
+
+func countFeeds(outlines []Outline) int {
+	count := 0
+	for _, outline := range outlines {
+		if outline.IsFeed() {
+			count++
+		} else {
+			count += countFeeds(outline.Outlines)
+		}
+	}
+	return count
+}
+
+// Parse decodes an OPML document incrementally from r.
 func Parse(r io.Reader) (Document, error) {
 	decoder := xml.NewDecoder(r)
 	var doc Document
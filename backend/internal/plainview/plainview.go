@@ -0,0 +1,152 @@
+package plainview
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gist/backend/internal/service/ai"
+)
+
+// DefaultWidth is the column article text wraps at unless asked otherwise.
+const DefaultWidth = 80
+
+// Item is an entry as listed.
+type Item struct {
+	ID        int64
+	Title     string
+	FeedTitle string
+	Published *time.Time
+	URL       string
+	Read      bool
+	Starred   bool
+}
+
+// List is a page of entries.
+type List struct {
+	Items []Item
+	// Next is the path of the following page, empty on the last one.
+	Next string
+}
+
+// Article is an entry to read.
+type Article struct {
+	ID        int64
+	Title     string
+	Author    string
+	FeedTitle string
+	URL       string
+	Published *time.Time
+	// Content is the article HTML; only its text is written.
+	Content string
+	Read    bool
+	Starred bool
+}
+
+// RenderList writes a list of entries, a block of three lines each: the ID
+// and title, the feed, date and state, and the link. The ID is what the
+// article path takes, so the list is easy to cut or grep.
+func RenderList(w io.Writer, list List) error {
+	var b strings.Builder
+	if len(list.Items) == 0 {
+		b.WriteString("No entries.\n")
+	}
+	for i, item := range list.Items {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[%d] %s\n", item.ID, untitled(item.Title))
+		fmt.Fprintf(&b, "    %s\n", joinMeta(item.FeedTitle, "", item.Published, item.Read, item.Starred))
+		if url := strings.TrimSpace(item.URL); url != "" {
+			fmt.Fprintf(&b, "    %s\n", url)
+		}
+	}
+	if list.Next != "" {
+		fmt.Fprintf(&b, "\nNext: %s\n", list.Next)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RenderArticle writes an entry's header and its text, a paragraph per
+// block wrapped at width columns. A width of 0 leaves paragraphs unwrapped.
+func RenderArticle(w io.Writer, article Article, width int) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n", untitled(article.Title))
+	fmt.Fprintf(&b, "%s\n", joinMeta(article.FeedTitle, article.Author, article.Published, article.Read, article.Starred))
+	if url := strings.TrimSpace(article.URL); url != "" {
+		fmt.Fprintf(&b, "%s\n", url)
+	}
+	for _, paragraph := range paragraphs(ai.HTMLToText(article.Content)) {
+		b.WriteString("\n")
+		b.WriteString(wrap(paragraph, width))
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func untitled(title string) string {
+	if title = strings.TrimSpace(title); title != "" {
+		return title
+	}
+	return "Untitled"
+}
+
+// joinMeta is the line under a title: feed, author, date and state.
+func joinMeta(feed, author string, published *time.Time, read, starred bool) string {
+	var parts []string
+	for _, part := range []string{feed, author} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if published != nil {
+		parts = append(parts, published.UTC().Format("2006-01-02 15:04"))
+	}
+	if !read {
+		parts = append(parts, "unread")
+	}
+	if starred {
+		parts = append(parts, "starred")
+	}
+	return strings.Join(parts, " · ")
+}
+
+// paragraphs splits text into its non-empty lines, each a paragraph, with
+// runs of spaces collapsed.
+func paragraphs(text string) []string {
+	var out []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// wrap breaks a paragraph between words so lines fit in width characters.
+// Words longer than a line, such as links, get a line of their own.
+func wrap(paragraph string, width int) string {
+	if width <= 0 {
+		return paragraph
+	}
+	var b strings.Builder
+	lineLen := 0
+	for _, word := range strings.Fields(paragraph) {
+		n := utf8.RuneCountInString(word)
+		if lineLen > 0 && lineLen+1+n > width {
+			b.WriteString("\n")
+			lineLen = 0
+		}
+		if lineLen > 0 {
+			b.WriteString(" ")
+			lineLen++
+		}
+		b.WriteString(word)
+		lineLen += n
+	}
+	return b.String()
+}
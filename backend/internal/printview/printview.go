@@ -0,0 +1,187 @@
+package printview
+
+import (
+	"html/template"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"gist/backend/internal/htmlclean"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Page is an article to render as a standalone HTML document.
+type Page struct {
+	Title     string
+	Author    string
+	FeedTitle string // the feed the article came from
+	SourceURL string
+	Published *time.Time
+	// Content is the article HTML. It is reduced to a safe subset of HTML
+	// with its links and images resolved against SourceURL.
+	Content string
+}
+
+// Render writes the page as an HTML document that needs nothing but the
+// images it links to: styles are inline and no scripts are kept.
+func Render(w io.Writer, page Page) error {
+	body, err := Clean(page.Content, page.SourceURL)
+	if err != nil {
+		return err
+	}
+
+	title := strings.TrimSpace(page.Title)
+	if title == "" {
+		title = "Untitled"
+	}
+	var byline []string
+	if author := strings.TrimSpace(page.Author); author != "" {
+		byline = append(byline, author)
+	}
+	if feed := strings.TrimSpace(page.FeedTitle); feed != "" {
+		byline = append(byline, feed)
+	}
+	if page.Published != nil {
+		byline = append(byline, page.Published.UTC().Format("2006-01-02"))
+	}
+	source := ""
+	if htmlclean.IsWebURL(page.SourceURL) {
+		source = page.SourceURL
+	}
+
+	return document.Execute(w, struct {
+		Title  string
+		Byline string
+		Source string
+		Body   template.HTML
+	}{title, strings.Join(byline, " · "), source, template.HTML(body)})
+}
+
+var document = template.Must(template.New("print").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="referrer" content="no-referrer">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0 auto; max-width: 42rem; padding: 2rem 1.25rem; font: 17px/1.7 Georgia, "Times New Roman", "Songti SC", serif; color: #111; background: #fff; }
+h1 { font-size: 1.9rem; line-height: 1.25; margin: 0 0 .5rem; }
+header { border-bottom: 1px solid #ddd; margin-bottom: 1.5rem; padding-bottom: 1rem; }
+.byline { color: #555; font-style: italic; margin: 0; }
+.source { font-size: .85rem; margin: .25rem 0 0; word-break: break-all; }
+a { color: #1a4f9c; }
+img, video { max-width: 100%; height: auto; }
+figure { margin: 1.5rem 0; }
+figcaption { color: #555; font-size: .85rem; }
+blockquote { border-left: 3px solid #ccc; color: #333; margin: 1rem 0; padding-left: 1rem; }
+pre { background: #f5f5f5; overflow-x: auto; padding: .75rem; white-space: pre-wrap; }
+code { font-family: Menlo, Consolas, monospace; font-size: .9em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: .25rem .5rem; }
+@media print {
+  body { max-width: none; padding: 0; font-size: 12pt; }
+  a { color: inherit; }
+  img, figure, pre, blockquote, tr { break-inside: avoid; }
+  h1, h2, h3, h4 { break-after: avoid; }
+}
+</style>
+</head>
+<body>
+<article>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Byline}}<p class="byline">{{.Byline}}</p>
+{{end}}{{if .Source}}<p class="source"><a href="{{.Source}}">{{.Source}}</a></p>
+{{end}}</header>
+{{.Body}}
+</article>
+</body>
+</html>
+`))
+
+// keptElements are the HTML elements copied into the page. Other elements
+// are unwrapped, keeping their text, unless listed in droppedElements.
+var keptElements = htmlclean.TextElements(atom.Img)
+
+// droppedElements are removed together with their content. Pictures are
+// unwrapped to their fallback img rather than dropped.
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Object: true, atom.Embed: true, atom.Source: true,
+	atom.Video: true, atom.Audio: true, atom.Svg: true, atom.Math: true,
+	atom.Form: true, atom.Input: true, atom.Button: true, atom.Select: true,
+	atom.Textarea: true, atom.Head: true, atom.Link: true, atom.Meta: true,
+}
+
+// keptAttributes are the attributes copied onto kept elements.
+var keptAttributes = map[string]bool{
+	"href": true, "src": true, "alt": true, "title": true, "width": true, "height": true,
+	"colspan": true, "rowspan": true, "datetime": true,
+}
+
+// lazySrcAttributes hold the real image URL of lazy-loaded images, whose
+// src is a placeholder until a script swaps it in.
+var lazySrcAttributes = []string{"data-src", "data-lazy-src", "data-original"}
+
+// Clean reduces an HTML fragment to the kept elements, resolving links and
+// image sources against base. It is the simplified HTML of the print view,
+// also served to clients that ask for clean entry content.
+func Clean(content, base string) (string, error) {
+	baseURL, _ := url.Parse(base)
+	policy := htmlclean.Policy{
+		Elements:   keptElements,
+		Dropped:    droppedElements,
+		Attributes: keptAttributes,
+		Required:   map[atom.Atom]string{atom.Img: "src"},
+		URL: func(n *html.Node, key, val string) string {
+			if key == "src" {
+				val = imageSource(n)
+			}
+			return resolve(baseURL, val)
+		},
+	}
+	return policy.Clean(content)
+}
+
+// imageSource is the URL an img shows once loaded: its lazy-loading source
+// if it has one, its src otherwise.
+func imageSource(n *html.Node) string {
+	src := ""
+	for _, attr := range n.Attr {
+		if attr.Key == "src" {
+			src = attr.Val
+		}
+	}
+	for _, key := range lazySrcAttributes {
+		for _, attr := range n.Attr {
+			if attr.Key == key && strings.TrimSpace(attr.Val) != "" {
+				return attr.Val
+			}
+		}
+	}
+	return src
+}
+
+// resolve makes ref absolute against base, returning "" unless the result
+// is an http(s) URL.
+func resolve(base *url.URL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if !htmlclean.IsWebURL(u.String()) {
+		return ""
+	}
+	return u.String()
+}
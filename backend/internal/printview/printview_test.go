@@ -0,0 +1,68 @@
+package printview
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	published := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	page := Page{
+		Title:     "Fish & Chips <tonight>",
+		Author:    "Ann",
+		FeedTitle: "Food Blog",
+		SourceURL: "https://example.com/posts/fish?a=1&b=2",
+		Published: &published,
+		Content: `<p onclick="x()">Hello <b>world</b><img src="img/fish.jpg" alt="Fish"></p>` +
+			`<script>alert(1)</script><iframe src="https://video.example.com"></iframe>` +
+			`<img src="data:image/gif;base64,R0lGOD" data-src="/lazy.png">` +
+			`<a href="/about">about</a> <a href="javascript:alert(1)">bad</a>` +
+			`<picture><source srcset="a.webp"><img src="//cdn.example.com/b.jpg"></picture>` +
+			`<table><tr><td colspan=2 style="color:red">cell</td></tr></table>`,
+	}
+
+	var buf bytes.Buffer
+	if err := Render(&buf, page); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	doc := buf.String()
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"<title>Fish &amp; Chips &lt;tonight&gt;</title>",
+		"<h1>Fish &amp; Chips &lt;tonight&gt;</h1>",
+		"Ann · Food Blog · 2025-03-01",
+		`<a href="https://example.com/posts/fish?a=1&amp;b=2">`,
+		"@media print",
+		`<p>Hello <b>world</b><img src="https://example.com/posts/img/fish.jpg" alt="Fish"/></p>`,
+		`<img src="https://example.com/lazy.png"/>`,
+		`<a href="https://example.com/about">about</a> <a>bad</a>`,
+		`<img src="https://cdn.example.com/b.jpg"/>`,
+		`<td colspan="2">cell</td>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %q in:\n%s", want, doc)
+		}
+	}
+	for _, unwanted := range []string{"<script", "alert(1)", "<iframe", "onclick", "javascript:", "srcset", "color:red"} {
+		if strings.Contains(doc, unwanted) {
+			t.Errorf("unexpected %q in:\n%s", unwanted, doc)
+		}
+	}
+}
+
+func TestRender_Untitled(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Render(&buf, Page{SourceURL: "file:///etc/passwd", Content: "<p>Text</p>"}); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	doc := buf.String()
+	if !strings.Contains(doc, "<h1>Untitled</h1>") {
+		t.Errorf("expected an untitled heading in:\n%s", doc)
+	}
+	if strings.Contains(doc, `class="byline"`) || strings.Contains(doc, `class="source"`) {
+		t.Errorf("expected no byline or source link in:\n%s", doc)
+	}
+}
@@ -0,0 +1,188 @@
+package readerview
+
+import (
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"gist/backend/internal/printview"
+)
+
+// Item is an entry as listed on the list page.
+type Item struct {
+	Title     string
+	FeedTitle string
+	Published *time.Time
+	Snippet   string
+	Href      string // the article page
+}
+
+// List is a page of entries.
+type List struct {
+	Title string
+	Items []Item
+	// Next is the link to the following page, empty on the last one.
+	Next string
+	// Start is the link to the first page, empty on the first one.
+	Start string
+}
+
+// Article is an entry to read.
+type Article struct {
+	Title     string
+	Author    string
+	FeedTitle string
+	SourceURL string
+	Published *time.Time
+	// Content is the article HTML, reduced as in the print view.
+	Content string
+	Read    bool
+	// Back is the link to the list; MarkRead is where the read form posts.
+	Back     string
+	MarkRead string
+}
+
+// RenderList writes a list page. Pages are plain HTML without scripts or
+// images, for e-ink readers and text browsers.
+func RenderList(w io.Writer, list List) error {
+	items := make([]listItem, len(list.Items))
+	for i, item := range list.Items {
+		items[i] = listItem{
+			Title:  untitled(item.Title),
+			Href:   item.Href,
+			Meta:   joinMeta(item.FeedTitle, "", item.Published),
+			Detail: strings.TrimSpace(item.Snippet),
+		}
+	}
+	return listPage.Execute(w, struct {
+		Title string
+		Items []listItem
+		Next  string
+		Start string
+	}{untitled(list.Title), items, list.Next, list.Start})
+}
+
+// RenderArticle writes an article page with a form to mark it read.
+func RenderArticle(w io.Writer, article Article) error {
+	body, err := printview.Clean(article.Content, article.SourceURL)
+	if err != nil {
+		return err
+	}
+	source := ""
+	if strings.HasPrefix(article.SourceURL, "http://") || strings.HasPrefix(article.SourceURL, "https://") {
+		source = article.SourceURL
+	}
+	return articlePage.Execute(w, struct {
+		Title    string
+		Meta     string
+		Source   string
+		Body     template.HTML
+		Read     bool
+		Back     string
+		MarkRead string
+	}{
+		untitled(article.Title),
+		joinMeta(article.FeedTitle, article.Author, article.Published),
+		source,
+		template.HTML(body),
+		article.Read,
+		article.Back,
+		article.MarkRead,
+	})
+}
+
+type listItem struct {
+	Title  string
+	Href   string
+	Meta   string
+	Detail string
+}
+
+func untitled(title string) string {
+	if title = strings.TrimSpace(title); title != "" {
+		return title
+	}
+	return "Untitled"
+}
+
+// joinMeta is the line under a title: feed, author and date.
+func joinMeta(feed, author string, published *time.Time) string {
+	var parts []string
+	for _, part := range []string{feed, author} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if published != nil {
+		parts = append(parts, published.UTC().Format("2006-01-02 15:04"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// style is shared by both pages: black on white, large serif text and no
+// animations, which e-ink screens redraw slowly.
+const style = `<style>
+body { margin: 0 auto; max-width: 40rem; padding: 1rem; font: 20px/1.6 Georgia, "Times New Roman", "Songti SC", serif; color: #000; background: #fff; }
+a { color: #000; }
+h1 { font-size: 1.5rem; line-height: 1.3; margin: 0 0 .25rem; }
+ol { list-style: none; margin: 0; padding: 0; }
+li { border-bottom: 1px solid #000; padding: .75rem 0; }
+.meta { font-size: .8rem; margin: .25rem 0 0; }
+.detail { font-size: .9rem; margin: .25rem 0 0; }
+nav { display: flex; justify-content: space-between; margin: 1rem 0; }
+header { border-bottom: 2px solid #000; margin-bottom: 1rem; padding-bottom: .5rem; }
+img { max-width: 100%; height: auto; }
+pre { overflow-x: auto; white-space: pre-wrap; }
+blockquote { border-left: 3px solid #000; margin: 1rem 0; padding-left: 1rem; }
+button { font: inherit; padding: .5rem 1rem; border: 2px solid #000; background: #fff; color: #000; }
+</style>`
+
+var listPage = template.Must(template.New("list").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="referrer" content="no-referrer">
+<title>{{.Title}}</title>
+` + style + `
+</head>
+<body>
+<header><h1>{{.Title}}</h1></header>
+{{if .Items}}<ol>
+{{range .Items}}<li><a href="{{.Href}}">{{.Title}}</a>
+{{if .Meta}}<p class="meta">{{.Meta}}</p>
+{{end}}{{if .Detail}}<p class="detail">{{.Detail}}</p>
+{{end}}</li>
+{{end}}</ol>
+{{else}}<p>Nothing to read.</p>
+{{end}}<nav>{{if .Start}}<a href="{{.Start}}">Newest</a>{{else}}<span></span>{{end}}{{if .Next}}<a href="{{.Next}}">Older</a>{{end}}</nav>
+</body>
+</html>
+`))
+
+var articlePage = template.Must(template.New("article").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="referrer" content="no-referrer">
+<title>{{.Title}}</title>
+` + style + `
+</head>
+<body>
+<nav><a href="{{.Back}}">Back</a>{{if .Source}}<a href="{{.Source}}">Source</a>{{end}}</nav>
+<article>
+<header>
+<h1>{{.Title}}</h1>
+{{if .Meta}}<p class="meta">{{.Meta}}</p>
+{{end}}</header>
+{{.Body}}
+</article>
+<form method="post" action="{{.MarkRead}}">
+<input type="hidden" name="read" value="{{if .Read}}false{{else}}true{{end}}">
+<button type="submit">{{if .Read}}Mark as unread{{else}}Mark as read{{end}}</button>
+</form>
+</body>
+</html>
+`))
@@ -11,6 +11,9 @@ import (
 
 type AISummaryRepository interface {
 	Get(ctx context.Context, entryID int64, isReadability bool, language string) (*model.AISummary, error)
+	// GetLatest returns the most recent summary of an entry in any language,
+	// or nil when it has none.
+	GetLatest(ctx context.Context, entryID int64) (*model.AISummary, error)
 	Save(ctx context.Context, entryID int64, isReadability bool, language, summary string) error
 	DeleteByEntryID(ctx context.Context, entryID int64) error
 	DeleteAll(ctx context.Context) (int64, error)
@@ -55,6 +58,33 @@ func (r *aiSummaryRepository) Get(ctx context.Context, entryID int64, isReadabil
 	return &s, nil
 }
 
+func (r *aiSummaryRepository) GetLatest(ctx context.Context, entryID int64) (*model.AISummary, error) {
+	row := r.db.QueryRowContext(
+		ctx,
+		`SELECT id, entry_id, is_readability, language, summary, created_at
+		 FROM ai_summaries WHERE entry_id = ?
+		 ORDER BY created_at DESC, id DESC LIMIT 1`,
+		entryID,
+	)
+
+	var s model.AISummary
+	var isReadabilityDB int
+	var createdAt string
+
+	err := row.Scan(&s.ID, &s.EntryID, &isReadabilityDB, &s.Language, &s.Summary, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.IsReadability = isReadabilityDB == 1
+	s.CreatedAt, _ = parseTime(createdAt)
+
+	return &s, nil
+}
+
 func (r *aiSummaryRepository) Save(ctx context.Context, entryID int64, isReadability bool, language, summary string) error {
 	id := snowflake.NextID()
 	now := formatTime(time.Now())
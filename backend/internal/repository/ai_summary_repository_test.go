@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestAISummaryRepository_GetLatest(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewAISummaryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	entryID := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+
+	summary, err := repo.GetLatest(ctx, entryID)
+	if err != nil {
+		t.Fatalf("get latest: %v", err)
+	}
+	if summary != nil {
+		t.Fatalf("expected no summary, got %+v", summary)
+	}
+
+	if err := repo.Save(ctx, entryID, false, "en", "First"); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := repo.Save(ctx, entryID, true, "zh-CN", "Second"); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	summary, err = repo.GetLatest(ctx, entryID)
+	if err != nil {
+		t.Fatalf("get latest: %v", err)
+	}
+	if summary == nil || summary.Summary != "Second" || summary.Language != "zh-CN" || !summary.IsReadability {
+		t.Errorf("expected the newest summary, got %+v", summary)
+	}
+}
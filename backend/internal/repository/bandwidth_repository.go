@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"gist/backend/internal/model"
+)
+
+type BandwidthRepository interface {
+	// Add counts bytes downloaded for a feed on day (YYYY-MM-DD).
+	Add(ctx context.Context, feedID int64, day string, bytes int64) error
+	// ListSince returns the daily totals of every feed from day on, oldest
+	// day first.
+	ListSince(ctx context.Context, day string) ([]model.FeedBandwidth, error)
+	// DeleteBefore drops the totals of days before day.
+	DeleteBefore(ctx context.Context, day string) error
+}
+
+type bandwidthRepository struct {
+	db dbtx
+}
+
+func NewBandwidthRepository(db dbtx) BandwidthRepository {
+	return &bandwidthRepository{db: db}
+}
+
+func (r *bandwidthRepository) Add(ctx context.Context, feedID int64, day string, bytes int64) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO feed_bandwidth (feed_id, day, bytes) VALUES (?, ?, ?)
+		 ON CONFLICT(feed_id, day) DO UPDATE SET bytes = bytes + excluded.bytes`,
+		feedID, day, bytes,
+	)
+	if err != nil {
+		return fmt.Errorf("add feed bandwidth: %w", err)
+	}
+	return nil
+}
+
+func (r *bandwidthRepository) ListSince(ctx context.Context, day string) ([]model.FeedBandwidth, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT b.feed_id, f.title, b.day, b.bytes
+		 FROM feed_bandwidth b
+		 JOIN feeds f ON f.id = b.feed_id
+		 WHERE b.day >= ?
+		 ORDER BY b.day, b.feed_id`,
+		day,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list feed bandwidth: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []model.FeedBandwidth
+	for rows.Next() {
+		var u model.FeedBandwidth
+		if err := rows.Scan(&u.FeedID, &u.FeedTitle, &u.Day, &u.Bytes); err != nil {
+			return nil, fmt.Errorf("scan feed bandwidth: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate feed bandwidth: %w", err)
+	}
+	return usage, nil
+}
+
+func (r *bandwidthRepository) DeleteBefore(ctx context.Context, day string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM feed_bandwidth WHERE day < ?`, day); err != nil {
+		return fmt.Errorf("delete feed bandwidth: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestBandwidthRepository(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewBandwidthRepository(db)
+	feeds := NewFeedRepository(db)
+	ctx := context.Background()
+
+	blogID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	photosID := testutil.SeedFeed(t, db, model.Feed{Title: "Photos", URL: "https://example.org/feed"})
+	for _, add := range []struct {
+		feedID int64
+		day    string
+		bytes  int64
+	}{
+		{blogID, "2026-02-27", 100},
+		{blogID, "2026-03-01", 200},
+		{blogID, "2026-03-01", 50}, // adds up
+		{photosID, "2026-03-01", 5000},
+	} {
+		if err := repo.Add(ctx, add.feedID, add.day, add.bytes); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	usage, err := repo.ListSince(ctx, "2026-02-28")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []model.FeedBandwidth{
+		{FeedID: blogID, FeedTitle: "Blog", Day: "2026-03-01", Bytes: 250},
+		{FeedID: photosID, FeedTitle: "Photos", Day: "2026-03-01", Bytes: 5000},
+	}
+	if blogID > photosID {
+		want[0], want[1] = want[1], want[0]
+	}
+	if !reflect.DeepEqual(usage, want) {
+		t.Errorf("unexpected usage %+v", usage)
+	}
+
+	// Old days are pruned, and a deleted feed takes its totals along
+	if err := repo.DeleteBefore(ctx, "2026-03-01"); err != nil {
+		t.Fatalf("delete before: %v", err)
+	}
+	if err := feeds.Delete(ctx, photosID); err != nil {
+		t.Fatalf("delete feed: %v", err)
+	}
+	usage, err = repo.ListSince(ctx, "2000-01-01")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(usage) != 1 || usage[0].FeedID != blogID || usage[0].Day != "2026-03-01" {
+		t.Errorf("expected only the blog's latest day, got %+v", usage)
+	}
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// dataVersionQueries fingerprint each table that API responses are cached
+// against. Every row change moves at least one of the values: inserts raise
+// the count and max id, deletes lower the count, and updates set a newer
+// updated_at (nanosecond precision). Read and starred changes also take a
+// new entry_state_changes seq, which stays exact where updated_at strings
+// of the same second do not sort by time. Feeds also count the scheduling
+// columns refresh writes without touching updated_at.
+var dataVersionQueries = map[string]string{
+	// Separate subqueries, so that each max is a lookup in its index
+	"entries": `SELECT (SELECT COUNT(*) FROM entries) || ':' || COALESCE((SELECT MAX(id) FROM entries), 0) || ':' ||
+		COALESCE((SELECT MAX(updated_at) FROM entries), '') || ':' || COALESCE((SELECT MAX(seq) FROM entry_state_changes), 0)`,
+	"feeds": `SELECT COUNT(*) || ':' || COALESCE(MAX(id), 0) || ':' || COALESCE(MAX(updated_at), '') || ':' ||
+		COALESCE(MAX(next_refresh_at), '') || ':' || COALESCE(SUM(consecutive_failures), 0) || ':' ||
+		COUNT(disabled_at) || ':' || COALESCE(MAX(disabled_at), '') FROM feeds`,
+	"folders":    `SELECT COUNT(*) || ':' || COALESCE(MAX(id), 0) || ':' || COALESCE(MAX(updated_at), '') FROM folders`,
+	"entry_tags": `SELECT COUNT(*) || ':' || COALESCE(MAX(rowid), 0) || ':' || COALESCE(MAX(created_at), '') FROM entry_tags`,
+}
+
+// DataVersionRepository fingerprints tables so that responses built from
+// them can be revalidated without building them again.
+type DataVersionRepository interface {
+	// Version returns a value that changes whenever a row of one of the
+	// tables is inserted, updated or deleted. Tables without a fingerprint
+	// are an error.
+	Version(ctx context.Context, tables ...string) (string, error)
+}
+
+type dataVersionRepository struct {
+	db *sql.DB
+}
+
+func NewDataVersionRepository(db *sql.DB) DataVersionRepository {
+	return &dataVersionRepository{db: db}
+}
+
+func (r *dataVersionRepository) Version(ctx context.Context, tables ...string) (string, error) {
+	columns := make([]string, 0, len(tables))
+	for _, table := range tables {
+		query, ok := dataVersionQueries[table]
+		if !ok {
+			return "", fmt.Errorf("no data version for table %q", table)
+		}
+		columns = append(columns, "("+query+")")
+	}
+	if len(columns) == 0 {
+		return "", nil
+	}
+
+	var version string
+	if err := r.db.QueryRowContext(ctx, `SELECT `+strings.Join(columns, ` || '|' || `)).Scan(&version); err != nil {
+		return "", fmt.Errorf("query data version: %w", err)
+	}
+	return version, nil
+}
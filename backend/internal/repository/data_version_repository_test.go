@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestDataVersionRepository_Version(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	versions := NewDataVersionRepository(db)
+	entries := NewEntryRepository(db)
+	ctx := context.Background()
+
+	version := func() string {
+		t.Helper()
+		v, err := versions.Version(ctx, "entries", "feeds")
+		if err != nil {
+			t.Fatalf("version: %v", err)
+		}
+		return v
+	}
+
+	empty := version()
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	withFeed := version()
+	if withFeed == empty {
+		t.Error("expected a new feed to change the version")
+	}
+	if again := version(); again != withFeed {
+		t.Errorf("version changed without a write: %q then %q", withFeed, again)
+	}
+
+	first := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+	second := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+	seeded := version()
+	if seeded == withFeed {
+		t.Error("expected new entries to change the version")
+	}
+
+	if _, err := entries.UpdateReadStatus(ctx, []int64{first}, true); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	read := version()
+	if read == seeded {
+		t.Error("expected marking an entry read to change the version")
+	}
+
+	if _, err := db.Exec(`DELETE FROM entries WHERE id = ?`, second); err != nil {
+		t.Fatalf("delete entry: %v", err)
+	}
+	deleted := version()
+	if deleted == read {
+		t.Error("expected deleting an entry to change the version")
+	}
+
+	if _, err := db.Exec(`UPDATE feeds SET consecutive_failures = 1 WHERE id = ?`, feedID); err != nil {
+		t.Fatalf("count failure: %v", err)
+	}
+	failing := version()
+	if _, err := db.Exec(`UPDATE feeds SET consecutive_failures = 0 WHERE id = ?`, feedID); err != nil {
+		t.Fatalf("reset failures: %v", err)
+	}
+	if failing == deleted || version() == failing {
+		t.Error("expected failure counts to change the version")
+	}
+
+	if _, err := versions.Version(ctx, "settings"); err == nil {
+		t.Error("expected a table without a fingerprint to be refused")
+	}
+}
+
+func TestDataVersionRepository_QueryPlan(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+
+	// The latest entry change must come from an index, not a scan of every
+	// entry on each polled request
+	plan := queryPlan(t, db, dataVersionQueries["entries"])
+	if !strings.Contains(strings.Join(plan, "\n"), "USING COVERING INDEX idx_entries_updated_at") {
+		t.Errorf("expected idx_entries_updated_at in plan %q", plan)
+	}
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"strings"
 	"time"
 
@@ -17,26 +19,144 @@ type EntryListFilter struct {
 	UnreadOnly   bool
 	StarredOnly  bool
 	HasThumbnail bool
-	Limit        int
-	Offset       int
+	// HasPublishedAt leaves out entries without a publish date.
+	HasPublishedAt bool
+	// PublishedFrom and PublishedBefore bound published_at to [from, before).
+	// Both are compared as strings, so a date prefix like "2025-03" works.
+	PublishedFrom   string
+	PublishedBefore string
+	// ExcludeNSFW leaves out entries flagged as not safe for work.
+	ExcludeNSFW bool
+	// TagID selects the entries with this tag.
+	TagID *int64
+	// StarredOrFeedID selects the starred entries together with all entries
+	// of this feed, whether starred or not.
+	StarredOrFeedID *int64
+	// Match is an FTS5 query the entry's title, snippet, author or URL must match.
+	Match string
+	// After selects the entries behind this one in list order, for keyset
+	// pagination. Only meaningful with List.
+	After  *EntryCursor
+	Limit  int
+	Offset int
 }
 
+// EntryCursor is an entry's position in list order: newest published first,
+// then highest ID, with entries without a publish date (nil PublishedAt) last.
+type EntryCursor struct {
+	PublishedAt *time.Time
+	ID          int64
+}
+
+// UnreadCount is the number of unread entries of a feed filed under a
+// folder: the entry's own folder when a filter moved it, else its feed's.
 type UnreadCount struct {
-	FeedID int64
-	Count  int
+	FeedID   int64
+	FolderID *int64
+	Count    int
+}
+
+// StarredCount is the number of starred entries of a feed filed under a
+// folder: the entry's own folder when a filter moved it, else its feed's.
+type StarredCount struct {
+	FeedID   int64
+	FolderID *int64
+	Count    int
 }
 
 type EntryRepository interface {
 	GetByID(ctx context.Context, id int64) (model.Entry, error)
-	List(ctx context.Context, filter EntryListFilter) ([]model.Entry, error)
-	UpdateReadStatus(ctx context.Context, id int64, read bool) error
+	List(ctx context.Context, filter EntryListFilter) ([]model.EntrySummary, error)
+	// Search is List ordered by relevance to filter.Match, best match first.
+	Search(ctx context.Context, filter EntryListFilter) ([]model.EntrySummary, error)
+	// Archive counts the entries matching filter per period, where a period
+	// is the first bucketLen characters of published_at (4 for year, 7 for month).
+	Archive(ctx context.Context, filter EntryListFilter, bucketLen int) ([]model.ArchivePeriod, error)
+	// UpdateReadStatus sets the read status of several entries in one
+	// statement and returns the IDs of those that exist.
+	UpdateReadStatus(ctx context.Context, ids []int64, read bool) ([]int64, error)
 	UpdateStarredStatus(ctx context.Context, id int64, starred bool) error
-	UpdateReadableContent(ctx context.Context, id int64, content string) error
+	// ApplyBatch applies op, one of the model.EntryOp constants, to the
+	// entries with ids in a single statement and returns how many changed.
+	// Entries already in the state op sets and unknown IDs are skipped.
+	ApplyBatch(ctx context.Context, ids []int64, op string) (int64, error)
+	UpdateReadableContent(ctx context.Context, id int64, content string, meta model.ReadableMeta) error
+	// ListWithoutSnippet returns up to limit entries that have content but no snippet yet.
+	ListWithoutSnippet(ctx context.Context, limit int) ([]model.Entry, error)
+	// ListThumbnailSources returns the feed, thumbnail and URL of up to limit of the
+	// newest entries in picture folders that pre-generate grid thumbnails.
+	ListThumbnailSources(ctx context.Context, limit int) ([]model.Entry, error)
+	// ListUncheckedImages returns the ID, URL and thumbnail of up to limit of
+	// the newest unflagged entries of picture feeds the AI image check has not seen.
+	ListUncheckedImages(ctx context.Context, limit int) ([]model.Entry, error)
+	// SaveNSFWCheck records that the AI image check has seen an entry, flagging
+	// it when the check found it not safe for work.
+	SaveNSFWCheck(ctx context.Context, entryID int64, flagged bool, checkedAt time.Time) error
+	// UpdateSnippet stores an entry's snippet and reindexes it for search.
+	UpdateSnippet(ctx context.Context, id int64, snippet string) error
 	MarkAllAsRead(ctx context.Context, feedID *int64, folderID *int64, contentType *string) error
-	GetAllUnreadCounts(ctx context.Context) ([]UnreadCount, error)
+	// GetUnreadCounts counts the unread entries within filter per feed and
+	// folder. A feed may come back more than once for a folder.
+	GetUnreadCounts(ctx context.Context, filter UnreadCountFilter) ([]UnreadCount, error)
 	GetStarredCount(ctx context.Context) (int, error)
+	// GetStarredCounts counts the starred entries per feed and folder.
+	GetStarredCounts(ctx context.Context) ([]StarredCount, error)
+	// ListStateChanges returns up to limit read/starred changes logged after seq, oldest first.
+	ListStateChanges(ctx context.Context, afterSeq int64, limit int) ([]model.EntryStateChange, error)
+	// SetStateByURL sets the read and starred state of the entry with url in the
+	// feed with feedURL. Returns false if there is no such entry.
+	SetStateByURL(ctx context.Context, feedURL, url string, read, starred bool) (bool, error)
+	// GetByURL returns the entry with url in a feed, or sql.ErrNoRows.
+	GetByURL(ctx context.Context, feedID int64, url string) (model.Entry, error)
+	// GetByTitlePublished returns the oldest entry of a feed with title and
+	// publish time, or sql.ErrNoRows.
+	GetByTitlePublished(ctx context.Context, feedID int64, title string, publishedAt time.Time) (model.Entry, error)
 	CreateOrUpdate(ctx context.Context, entry model.Entry) error
+	// SaveRevision records the latest publisher change to an entry, replacing the previous one.
+	SaveRevision(ctx context.Context, entryID int64, rev model.EntryRevision) error
 	ExistsByURL(ctx context.Context, feedID int64, url string) (bool, error)
+	CountByFeed(ctx context.Context, feedID int64) (int64, error)
+	// CountPublishedSince counts a feed's entries published (or, without a
+	// date, found) since the given time.
+	CountPublishedSince(ctx context.Context, feedID int64, since time.Time) (int64, error)
+	// ListTitlesSince returns the titles of a feed's entries published (or,
+	// without a date, found) since the given time, newest first.
+	ListTitlesSince(ctx context.Context, feedID int64, since time.Time) ([]string, error)
+	// ListMissingContent returns the ID and URL of up to limit of a feed's
+	// entries found since the given time that have no content, newest first.
+	ListMissingContent(ctx context.Context, feedID int64, since time.Time, limit int) ([]model.Entry, error)
+	// ListWithoutReadable returns the IDs of a feed's entries that have a URL
+	// but no readable content, unread ones only when unreadOnly is set,
+	// newest first.
+	ListWithoutReadable(ctx context.Context, feedID int64, unreadOnly bool) ([]int64, error)
+	// FillContent stores the content fetched for an entry that has none, with
+	// its snippet, text and clean versions and, when it has none either,
+	// thumbnail. It reports whether the entry was still missing content.
+	FillContent(ctx context.Context, entry model.Entry) (bool, error)
+	// DeleteByFeed removes a feed's entries, sparing starred ones when keepStarred is set.
+	DeleteByFeed(ctx context.Context, feedID int64, keepStarred bool) (int64, error)
+	// PruneOld deletes up to limit entries, neither starred nor tagged, fetched before before or
+	// ranked past the newest keepPerFeed of their feed, by fetch time. A zero
+	// before or keepPerFeed leaves that rule out. Returns how many were deleted.
+	PruneOld(ctx context.Context, before time.Time, keepPerFeed int, limit int) (int64, error)
+}
+
+// unreadCountsQuery counts unread entries per feed and folder, in the order
+// of a covering index. Grouping by the entry's own folder rather than the
+// one it is filed under keeps that order, so a feed may come back twice for
+// the same folder; callers add the counts up.
+const unreadCountsQuery = `SELECT e.feed_id, COALESCE(e.folder_id, f.folder_id), COUNT(*)
+	FROM entries e INNER JOIN feeds f ON e.feed_id = f.id
+	WHERE e.read = 0 GROUP BY e.feed_id, e.folder_id`
+
+// UnreadCountFilter limits unread counts to feeds of a content type or to a
+// folder subtree. The zero value counts every feed.
+type UnreadCountFilter struct {
+	ContentType *string
+	// FolderID counts the entries in the folder and the folders below it.
+	// Like entry lists, an entry a filter moved counts under its folder
+	// instead of its feed's.
+	FolderID *int64
 }
 
 type entryRepository struct {
@@ -47,34 +167,165 @@ func NewEntryRepository(db dbtx) EntryRepository {
 	return &entryRepository{db: db}
 }
 
+// entrySelect loads a single entry with its link check state and latest revision.
+const entrySelect = `SELECT e.id, e.feed_id, e.title, e.url, e.content, e.readable_content, e.thumbnail_url, e.author,
+        e.published_at, e.read, e.starred, e.created_at, e.updated_at, e.nsfw_reason, e.icon_path,
+        EXISTS(SELECT 1 FROM entry_link_checks c WHERE c.entry_id = e.id AND c.status = ?),
+        r.title_before, r.words_added, r.words_removed, r.changes, r.changed_at,
+        e.readable_lang, e.readable_dir, e.readable_byline, e.readable_site_name, e.original_published_at,
+        e.content_text, e.content_clean, e.external_url
+ FROM entries e
+ LEFT JOIN entry_revisions r ON r.entry_id = e.id`
+
 func (r *entryRepository) GetByID(ctx context.Context, id int64) (model.Entry, error) {
-	row := r.db.QueryRowContext(
-		ctx,
-		`SELECT id, feed_id, title, url, content, readable_content, thumbnail_url, author, published_at, read, starred, created_at, updated_at
-		 FROM entries WHERE id = ?`,
-		id,
-	)
+	row := r.db.QueryRowContext(ctx, entrySelect+` WHERE e.id = ?`, model.LinkDead, id)
+	entry, err := scanEntry(row)
+	if err != nil {
+		return model.Entry{}, err
+	}
+	entry.Images, err = r.listImages(ctx, id)
+	if err != nil {
+		return model.Entry{}, err
+	}
+	entry.Attachments, err = r.listAttachments(ctx, id)
+	if err != nil {
+		return model.Entry{}, err
+	}
+	return entry, nil
+}
+
+func (r *entryRepository) GetByURL(ctx context.Context, feedID int64, url string) (model.Entry, error) {
+	row := r.db.QueryRowContext(ctx, entrySelect+` WHERE e.feed_id = ? AND e.url = ?`, model.LinkDead, feedID, url)
 	return scanEntry(row)
 }
 
-func (r *entryRepository) List(ctx context.Context, filter EntryListFilter) ([]model.Entry, error) {
-	var args []interface{}
+// sameItemWhere matches an entry of a feed by title and publish time.
+const sameItemWhere = ` WHERE e.feed_id = ? AND e.published_at = ? AND e.title = ? ORDER BY e.id LIMIT 1`
+
+func (r *entryRepository) GetByTitlePublished(ctx context.Context, feedID int64, title string, publishedAt time.Time) (model.Entry, error) {
+	row := r.db.QueryRowContext(ctx, entrySelect+sameItemWhere, model.LinkDead, feedID, formatTime(publishedAt), title)
+	return scanEntry(row)
+}
+
+func (r *entryRepository) List(ctx context.Context, filter EntryListFilter) ([]model.EntrySummary, error) {
+	query, args := buildListQuery(filter, listOrder)
+	return r.listSummaries(ctx, query, args)
+}
+
+func (r *entryRepository) Search(ctx context.Context, filter EntryListFilter) ([]model.EntrySummary, error) {
+	query, args := buildListQuery(filter, searchOrder)
+	return r.listSummaries(ctx, query, args)
+}
+
+func (r *entryRepository) listSummaries(ctx context.Context, query string, args []interface{}) ([]model.EntrySummary, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.EntrySummary
+	for rows.Next() {
+		entry, err := scanEntrySummaryRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *entryRepository) Archive(ctx context.Context, filter EntryListFilter, bucketLen int) ([]model.ArchivePeriod, error) {
+	query, args := buildArchiveQuery(filter, bucketLen)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var periods []model.ArchivePeriod
+	for rows.Next() {
+		var p model.ArchivePeriod
+		if err := rows.Scan(&p.Period, &p.Count, &p.UnreadCount); err != nil {
+			return nil, err
+		}
+		periods = append(periods, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return periods, nil
+}
+
+const (
+	listOrder = " ORDER BY e.published_at DESC, e.id DESC"
+	// searchOrder ranks matches with BM25, which is lower for better
+	// matches; a hit in the title counts most, then the author.
+	searchOrder = " ORDER BY bm25(entries_fts, 10.0, 1.0, 5.0, 1.0), e.published_at DESC, e.id DESC"
+)
+
+// buildListQuery assembles the entry list query for filter, sorted by order.
+func buildListQuery(filter EntryListFilter, order string) (string, []interface{}) {
+	from, args := entryScope(filter)
 	query := `
-		SELECT e.id, e.feed_id, e.title, e.url, e.content, e.readable_content, e.thumbnail_url, e.author,
-		       e.published_at, e.read, e.starred, e.created_at, e.updated_at
-		FROM entries e
-	`
+		SELECT e.id, e.feed_id, e.title, e.url, e.snippet, e.thumbnail_url, e.author,
+		       e.published_at, e.read, e.starred, e.created_at, e.updated_at, e.nsfw_reason, e.icon_path
+	` + from + order
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query += " OFFSET ?"
+		args = append(args, filter.Offset)
+	}
+
+	return query, args
+}
+
+// buildArchiveQuery counts the entries matching filter per published_at
+// prefix of bucketLen characters, newest period first.
+func buildArchiveQuery(filter EntryListFilter, bucketLen int) (string, []interface{}) {
+	filter.HasPublishedAt = true
+	from, scopeArgs := entryScope(filter)
+	query := `
+		SELECT substr(e.published_at, 1, ?) AS period, COUNT(*), SUM(CASE WHEN e.read = 0 THEN 1 ELSE 0 END)
+	` + from + " GROUP BY period ORDER BY period DESC"
+
+	return query, append([]interface{}{bucketLen}, scopeArgs...)
+}
+
+// entryScope builds the FROM and WHERE clauses selecting the entries that
+// match filter.
+func entryScope(filter EntryListFilter) (string, []interface{}) {
+	var args []interface{}
+	query := "FROM entries e"
 
 	var conditions []string
-	needFeedsJoin := filter.FolderID != nil || filter.ContentType != nil
+	needFeedsJoin := filter.ContentType != nil
 
 	if needFeedsJoin {
 		query += " INNER JOIN feeds f ON e.feed_id = f.id"
 	}
 
+	if filter.Match != "" {
+		query += " INNER JOIN entries_fts ON entries_fts.rowid = e.id"
+		conditions = append(conditions, "entries_fts MATCH ?")
+		args = append(args, filter.Match)
+	}
+
 	if filter.FolderID != nil {
-		conditions = append(conditions, "f.folder_id = ?")
-		args = append(args, *filter.FolderID)
+		// An entry a filter moved counts under its folder instead of its feed's
+		conditions = append(conditions, "((e.feed_id IN (SELECT id FROM feeds WHERE folder_id = ?) AND e.folder_id IS NULL) OR e.folder_id = ?)")
+		args = append(args, *filter.FolderID, *filter.FolderID)
 	}
 
 	if filter.ContentType != nil {
@@ -99,57 +350,85 @@ func (r *entryRepository) List(ctx context.Context, filter EntryListFilter) ([]m
 		conditions = append(conditions, "e.thumbnail_url IS NOT NULL AND e.thumbnail_url != ''")
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	if filter.HasPublishedAt {
+		conditions = append(conditions, "e.published_at IS NOT NULL")
 	}
 
-	query += " ORDER BY e.published_at DESC, e.id DESC"
+	if filter.PublishedFrom != "" {
+		conditions = append(conditions, "e.published_at >= ?")
+		args = append(args, filter.PublishedFrom)
+	}
 
-	if filter.Limit > 0 {
-		query += " LIMIT ?"
-		args = append(args, filter.Limit)
+	if filter.PublishedBefore != "" {
+		conditions = append(conditions, "e.published_at < ?")
+		args = append(args, filter.PublishedBefore)
 	}
-	if filter.Offset > 0 {
-		query += " OFFSET ?"
-		args = append(args, filter.Offset)
+
+	if filter.ExcludeNSFW {
+		conditions = append(conditions, "e.nsfw_reason IS NULL")
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, err
+	if filter.TagID != nil {
+		conditions = append(conditions, "e.id IN (SELECT entry_id FROM entry_tags WHERE tag_id = ?)")
+		args = append(args, *filter.TagID)
 	}
-	defer rows.Close()
 
-	var entries []model.Entry
-	for rows.Next() {
-		entry, err := scanEntryRows(rows)
-		if err != nil {
-			return nil, err
+	if filter.StarredOrFeedID != nil {
+		conditions = append(conditions, "(e.starred = 1 OR e.feed_id = ?)")
+		args = append(args, *filter.StarredOrFeedID)
+	}
+
+	if filter.After != nil {
+		if filter.After.PublishedAt != nil {
+			conditions = append(conditions, "((e.published_at, e.id) < (?, ?) OR e.published_at IS NULL)")
+			args = append(args, formatTime(*filter.After.PublishedAt), filter.After.ID)
+		} else {
+			conditions = append(conditions, "e.published_at IS NULL AND e.id < ?")
+			args = append(args, filter.After.ID)
 		}
-		entries = append(entries, entry)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	return entries, nil
+	return query, args
 }
 
-func (r *entryRepository) UpdateReadStatus(ctx context.Context, id int64, read bool) error {
+func (r *entryRepository) UpdateReadStatus(ctx context.Context, ids []int64, read bool) ([]int64, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
 	readInt := 0
 	if read {
 		readInt = 1
 	}
 
-	_, err := r.db.ExecContext(
+	placeholders := strings.Repeat("?,", len(ids)-1) + "?"
+	args := make([]interface{}, 0, len(ids)+2)
+	args = append(args, readInt, formatTime(time.Now()))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	rows, err := r.db.QueryContext(
 		ctx,
-		`UPDATE entries SET read = ?, updated_at = ? WHERE id = ?`,
-		readInt,
-		formatTime(time.Now()),
-		id,
+		`UPDATE entries SET read = ?, updated_at = ? WHERE id IN (`+placeholders+`) RETURNING id`,
+		args...,
 	)
-	return err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	updated := make([]int64, 0, len(ids))
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		updated = append(updated, id)
+	}
+	return updated, rows.Err()
 }
 
 func (r *entryRepository) MarkAllAsRead(ctx context.Context, feedID *int64, folderID *int64, contentType *string) error {
@@ -159,9 +438,10 @@ func (r *entryRepository) MarkAllAsRead(ctx context.Context, feedID *int64, fold
 		_, err := r.db.ExecContext(
 			ctx,
 			`UPDATE entries SET read = 1, updated_at = ?
-			 WHERE feed_id IN (SELECT id FROM feeds WHERE folder_id = ?) AND read = 0`,
+			 WHERE read = 0 AND ((feed_id IN (SELECT id FROM feeds WHERE folder_id = ?) AND folder_id IS NULL) OR folder_id = ?)`,
 			now,
 			*folderID,
+			*folderID,
 		)
 		return err
 	}
@@ -197,11 +477,9 @@ func (r *entryRepository) MarkAllAsRead(ctx context.Context, feedID *int64, fold
 	return err
 }
 
-func (r *entryRepository) GetAllUnreadCounts(ctx context.Context) ([]UnreadCount, error) {
-	rows, err := r.db.QueryContext(
-		ctx,
-		`SELECT feed_id, COUNT(*) as count FROM entries WHERE read = 0 GROUP BY feed_id`,
-	)
+func (r *entryRepository) GetUnreadCounts(ctx context.Context, filter UnreadCountFilter) ([]UnreadCount, error) {
+	query, args := buildUnreadCountsQuery(filter)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -210,9 +488,13 @@ func (r *entryRepository) GetAllUnreadCounts(ctx context.Context) ([]UnreadCount
 	var counts []UnreadCount
 	for rows.Next() {
 		var uc UnreadCount
-		if err := rows.Scan(&uc.FeedID, &uc.Count); err != nil {
+		var folderID sql.NullInt64
+		if err := rows.Scan(&uc.FeedID, &folderID, &uc.Count); err != nil {
 			return nil, err
 		}
+		if folderID.Valid {
+			uc.FolderID = &folderID.Int64
+		}
 		counts = append(counts, uc)
 	}
 
@@ -223,43 +505,96 @@ func (r *entryRepository) GetAllUnreadCounts(ctx context.Context) ([]UnreadCount
 	return counts, nil
 }
 
+// buildUnreadCountsQuery counts the unread entries per feed and folder within
+// filter in a single query, walking the folder tree with a recursive CTE.
+func buildUnreadCountsQuery(filter UnreadCountFilter) (string, []interface{}) {
+	if filter.ContentType == nil && filter.FolderID == nil {
+		return unreadCountsQuery, nil
+	}
+
+	var args []interface{}
+	query := ""
+	from := "FROM entries e INNER JOIN feeds f ON e.feed_id = f.id"
+	conditions := []string{"e.read = 0"}
+
+	if filter.FolderID != nil {
+		query = `WITH RECURSIVE subtree(id) AS (
+			SELECT id FROM folders WHERE id = ?
+			UNION SELECT folders.id FROM folders INNER JOIN subtree ON folders.parent_id = subtree.id
+		) `
+		args = append(args, *filter.FolderID)
+		conditions = append(conditions, "((e.feed_id IN (SELECT id FROM feeds WHERE folder_id IN subtree) AND e.folder_id IS NULL) OR e.folder_id IN subtree)")
+	}
+
+	if filter.ContentType != nil {
+		conditions = append(conditions, "f.type = ?")
+		args = append(args, *filter.ContentType)
+	}
+
+	query += "SELECT e.feed_id, COALESCE(e.folder_id, f.folder_id), COUNT(*) " + from + " WHERE " + strings.Join(conditions, " AND ") + " GROUP BY e.feed_id, e.folder_id"
+	return query, args
+}
+
 func scanEntry(row *sql.Row) (model.Entry, error) {
 	var e model.Entry
-	var publishedAt sql.NullString
+	var publishedAt, originalPublishedAt sql.NullString
 	var createdAt, updatedAt string
 	var readInt, starredInt int
+	var titleBefore, changes, changedAt sql.NullString
+	var wordsAdded, wordsRemoved sql.NullInt64
 
 	err := row.Scan(
 		&e.ID, &e.FeedID, &e.Title, &e.URL, &e.Content, &e.ReadableContent, &e.ThumbnailURL, &e.Author,
-		&publishedAt, &readInt, &starredInt, &createdAt, &updatedAt,
+		&publishedAt, &readInt, &starredInt, &createdAt, &updatedAt, &e.NSFWReason, &e.IconPath, &e.LinkDead,
+		&titleBefore, &wordsAdded, &wordsRemoved, &changes, &changedAt,
+		&e.ReadableMeta.Language, &e.ReadableMeta.Direction, &e.ReadableMeta.Byline, &e.ReadableMeta.SiteName,
+		&originalPublishedAt, &e.ContentText, &e.ContentClean, &e.ExternalURL,
 	)
 	if err != nil {
 		return model.Entry{}, err
 	}
+	if changedAt.Valid {
+		rev := &model.EntryRevision{
+			WordsAdded:   int(wordsAdded.Int64),
+			WordsRemoved: int(wordsRemoved.Int64),
+		}
+		if titleBefore.Valid {
+			rev.TitleBefore = &titleBefore.String
+		}
+		rev.Changes, err = decodeTextChanges(changes.String)
+		if err != nil {
+			return model.Entry{}, err
+		}
+		rev.ChangedAt, _ = parseTime(changedAt.String)
+		e.Revision = rev
+	}
 
 	e.Read = readInt == 1
 	e.Starred = starredInt == 1
 	if publishedAt.Valid {
 		e.PublishedAt = parseTimePtr(publishedAt.String)
 	}
+	if originalPublishedAt.Valid {
+		e.OriginalPublishedAt = parseTimePtr(originalPublishedAt.String)
+	}
 	e.CreatedAt, _ = parseTime(createdAt)
 	e.UpdatedAt, _ = parseTime(updatedAt)
 
 	return e, nil
 }
 
-func scanEntryRows(rows *sql.Rows) (model.Entry, error) {
-	var e model.Entry
+func scanEntrySummaryRows(rows *sql.Rows) (model.EntrySummary, error) {
+	var e model.EntrySummary
 	var publishedAt sql.NullString
 	var createdAt, updatedAt string
 	var readInt, starredInt int
 
 	err := rows.Scan(
-		&e.ID, &e.FeedID, &e.Title, &e.URL, &e.Content, &e.ReadableContent, &e.ThumbnailURL, &e.Author,
-		&publishedAt, &readInt, &starredInt, &createdAt, &updatedAt,
+		&e.ID, &e.FeedID, &e.Title, &e.URL, &e.Snippet, &e.ThumbnailURL, &e.Author,
+		&publishedAt, &readInt, &starredInt, &createdAt, &updatedAt, &e.NSFWReason, &e.IconPath,
 	)
 	if err != nil {
-		return model.Entry{}, err
+		return model.EntrySummary{}, err
 	}
 
 	e.Read = readInt == 1
@@ -285,36 +620,201 @@ func (r *entryRepository) CreateOrUpdate(ctx context.Context, entry model.Entry)
 	id := snowflake.NextID()
 	now := formatTime(time.Now())
 
-	var publishedAt interface{}
+	var publishedAt, originalPublishedAt interface{}
 	if entry.PublishedAt != nil {
 		publishedAt = formatTime(*entry.PublishedAt)
 	}
+	if entry.OriginalPublishedAt != nil {
+		originalPublishedAt = formatTime(*entry.OriginalPublishedAt)
+	}
 
-	_, err := r.db.ExecContext(
+	err := r.db.QueryRowContext(
 		ctx,
-		`INSERT INTO entries (id, feed_id, title, url, content, thumbnail_url, author, published_at, read, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, 0, ?, ?)
+		`INSERT INTO entries (id, feed_id, title, url, content, snippet, thumbnail_url, author, published_at, original_published_at, read, starred, folder_id, nsfw_reason, icon_path, content_text, content_clean, external_url, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		 ON CONFLICT(feed_id, url) DO UPDATE SET
 		   title = excluded.title,
 		   content = excluded.content,
+		   snippet = excluded.snippet,
+		   content_text = excluded.content_text,
+		   content_clean = excluded.content_clean,
+		   external_url = excluded.external_url,
 		   thumbnail_url = excluded.thumbnail_url,
 		   author = excluded.author,
 		   published_at = excluded.published_at,
-		   updated_at = excluded.updated_at`,
+		   original_published_at = COALESCE(excluded.original_published_at, entries.original_published_at),
+		   nsfw_reason = COALESCE(excluded.nsfw_reason, entries.nsfw_reason),
+		   icon_path = COALESCE(excluded.icon_path, entries.icon_path),
+		   updated_at = excluded.updated_at
+		 RETURNING id`,
 		id,
 		entry.FeedID,
 		entry.Title,
 		entry.URL,
 		entry.Content,
+		entry.Snippet,
 		entry.ThumbnailURL,
 		entry.Author,
 		publishedAt,
+		originalPublishedAt,
+		entry.Read,
+		entry.Starred,
+		nullableInt64(entry.FolderID),
+		entry.NSFWReason,
+		entry.IconPath,
+		entry.ContentText,
+		entry.ContentClean,
+		entry.ExternalURL,
 		now,
 		now,
+	).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	if entry.Images != nil {
+		if err := r.replaceImages(ctx, id, entry.Images); err != nil {
+			return err
+		}
+	}
+	if entry.Attachments != nil {
+		return r.replaceAttachments(ctx, id, entry.Attachments)
+	}
+	return nil
+}
+
+// replaceImages stores images as the gallery of an entry.
+func (r *entryRepository) replaceImages(ctx context.Context, entryID int64, images []string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM entry_media WHERE entry_id = ?`, entryID); err != nil {
+		return err
+	}
+	if len(images) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(images))
+	args := make([]interface{}, 0, len(images)*3)
+	for i, url := range images {
+		placeholders[i] = "(?, ?, ?)"
+		args = append(args, entryID, i, url)
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO entry_media (entry_id, position, url) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return err
+}
+
+func (r *entryRepository) listImages(ctx context.Context, entryID int64) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT url FROM entry_media WHERE entry_id = ? ORDER BY position`, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var images []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		images = append(images, url)
+	}
+	return images, rows.Err()
+}
+
+// replaceAttachments stores attachments as the files attached to an entry.
+func (r *entryRepository) replaceAttachments(ctx context.Context, entryID int64, attachments []model.EntryAttachment) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM entry_attachments WHERE entry_id = ?`, entryID); err != nil {
+		return err
+	}
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(attachments))
+	args := make([]interface{}, 0, len(attachments)*7)
+	for i, a := range attachments {
+		placeholders[i] = "(?, ?, ?, ?, ?, ?, ?)"
+		args = append(args, entryID, i, a.URL, a.MimeType, a.Title, a.SizeBytes, a.DurationSeconds)
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO entry_attachments (entry_id, position, url, mime_type, title, size_bytes, duration_seconds) VALUES `+strings.Join(placeholders, ", "),
+		args...,
+	)
+	return err
+}
+
+func (r *entryRepository) listAttachments(ctx context.Context, entryID int64) ([]model.EntryAttachment, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT url, mime_type, title, size_bytes, duration_seconds FROM entry_attachments WHERE entry_id = ? ORDER BY position`, entryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []model.EntryAttachment
+	for rows.Next() {
+		var a model.EntryAttachment
+		if err := rows.Scan(&a.URL, &a.MimeType, &a.Title, &a.SizeBytes, &a.DurationSeconds); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, rows.Err()
+}
+
+func (r *entryRepository) SaveRevision(ctx context.Context, entryID int64, rev model.EntryRevision) error {
+	changes, err := encodeTextChanges(rev.Changes)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.ExecContext(
+		ctx,
+		`INSERT INTO entry_revisions (entry_id, title_before, words_added, words_removed, changes, changed_at)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(entry_id) DO UPDATE SET
+		   title_before = excluded.title_before,
+		   words_added = excluded.words_added,
+		   words_removed = excluded.words_removed,
+		   changes = excluded.changes,
+		   changed_at = excluded.changed_at`,
+		entryID, nullableString(rev.TitleBefore), rev.WordsAdded, rev.WordsRemoved, changes, formatTime(rev.ChangedAt),
 	)
 	return err
 }
 
+// textChangeJSON is how a TextChange is stored in entry_revisions.changes.
+type textChangeJSON struct {
+	Removed string `json:"removed,omitempty"`
+	Added   string `json:"added,omitempty"`
+}
+
+func encodeTextChanges(changes []model.TextChange) (string, error) {
+	rows := make([]textChangeJSON, len(changes))
+	for i, c := range changes {
+		rows[i] = textChangeJSON{Removed: c.Removed, Added: c.Added}
+	}
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return "", fmt.Errorf("encode revision changes: %w", err)
+	}
+	return string(data), nil
+}
+
+func decodeTextChanges(data string) ([]model.TextChange, error) {
+	var rows []textChangeJSON
+	if err := json.Unmarshal([]byte(data), &rows); err != nil {
+		return nil, fmt.Errorf("decode revision changes: %w", err)
+	}
+	changes := make([]model.TextChange, len(rows))
+	for i, row := range rows {
+		changes[i] = model.TextChange{Removed: row.Removed, Added: row.Added}
+	}
+	return changes, nil
+}
+
 func (r *entryRepository) ExistsByURL(ctx context.Context, feedID int64, url string) (bool, error) {
 	var count int
 	err := r.db.QueryRowContext(
@@ -329,17 +829,276 @@ func (r *entryRepository) ExistsByURL(ctx context.Context, feedID int64, url str
 	return count > 0, nil
 }
 
-func (r *entryRepository) UpdateReadableContent(ctx context.Context, id int64, content string) error {
+func (r *entryRepository) CountByFeed(ctx context.Context, feedID int64) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM entries WHERE feed_id = ?`, feedID).Scan(&count)
+	return count, err
+}
+
+func (r *entryRepository) CountPublishedSince(ctx context.Context, feedID int64, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM entries WHERE feed_id = ? AND COALESCE(published_at, created_at) >= ?`,
+		feedID, formatTime(since)).Scan(&count)
+	return count, err
+}
+
+func (r *entryRepository) ListTitlesSince(ctx context.Context, feedID int64, since time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT title FROM entries WHERE feed_id = ? AND COALESCE(published_at, created_at) >= ? AND title IS NOT NULL AND title != ''
+		 ORDER BY COALESCE(published_at, created_at) DESC`,
+		feedID, formatTime(since))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, err
+		}
+		titles = append(titles, title)
+	}
+	return titles, rows.Err()
+}
+
+func (r *entryRepository) ListMissingContent(ctx context.Context, feedID int64, since time.Time, limit int) ([]model.Entry, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, url FROM entries
+		 WHERE feed_id = ? AND created_at >= ? AND COALESCE(content, '') = '' AND COALESCE(url, '') != ''
+		 ORDER BY created_at DESC, id DESC LIMIT ?`,
+		feedID, formatTime(since), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.Entry
+	for rows.Next() {
+		e := model.Entry{FeedID: feedID}
+		if err := rows.Scan(&e.ID, &e.URL); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *entryRepository) ListWithoutReadable(ctx context.Context, feedID int64, unreadOnly bool) ([]int64, error) {
+	query := `SELECT id FROM entries
+		 WHERE feed_id = ? AND COALESCE(readable_content, '') = '' AND COALESCE(url, '') != ''`
+	if unreadOnly {
+		query += ` AND read = 0`
+	}
+	query += ` ORDER BY published_at IS NULL, published_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query, feedID)
+	if err != nil {
+		return nil, fmt.Errorf("list entries without readable content: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *entryRepository) FillContent(ctx context.Context, entry model.Entry) (bool, error) {
+	// The entries_fts_au trigger reindexes the row
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE entries SET content = ?, snippet = ?, content_text = ?, content_clean = ?,
+		        thumbnail_url = COALESCE(thumbnail_url, ?), updated_at = ?
+		 WHERE id = ? AND COALESCE(content, '') = ''`,
+		entry.Content, entry.Snippet, entry.ContentText, entry.ContentClean, entry.ThumbnailURL, formatTime(time.Now()), entry.ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *entryRepository) DeleteByFeed(ctx context.Context, feedID int64, keepStarred bool) (int64, error) {
+	query := `DELETE FROM entries WHERE feed_id = ?`
+	if keepStarred {
+		query += ` AND starred = 0`
+	}
+	result, err := r.db.ExecContext(ctx, query, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *entryRepository) PruneOld(ctx context.Context, before time.Time, keepPerFeed int, limit int) (int64, error) {
+	var conditions []string
+	var args []interface{}
+	if !before.IsZero() {
+		conditions = append(conditions, `created_at < ?`)
+		args = append(args, formatTime(before))
+	}
+	if keepPerFeed > 0 {
+		conditions = append(conditions, `rank > ?`)
+		args = append(args, keepPerFeed)
+	}
+	if len(conditions) == 0 {
+		return 0, nil
+	}
+
+	// Starred and tagged entries count towards keepPerFeed but are never deleted
+	ranked := `SELECT id, starred, created_at, 0 AS rank FROM entries`
+	if keepPerFeed > 0 {
+		ranked = `SELECT id, starred, created_at,
+			ROW_NUMBER() OVER (PARTITION BY feed_id ORDER BY created_at DESC, id DESC) AS rank
+			FROM entries`
+	}
+	args = append(args, limit)
+	result, err := r.db.ExecContext(ctx,
+		`DELETE FROM entries WHERE id IN (
+			SELECT id FROM (`+ranked+`)
+			WHERE starred = 0 AND id NOT IN (SELECT entry_id FROM entry_tags) AND (`+strings.Join(conditions, " OR ")+`)
+			LIMIT ?
+		)`,
+		args...,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *entryRepository) UpdateReadableContent(ctx context.Context, id int64, content string, meta model.ReadableMeta) error {
 	_, err := r.db.ExecContext(
 		ctx,
-		`UPDATE entries SET readable_content = ?, updated_at = ? WHERE id = ?`,
+		`UPDATE entries SET readable_content = ?, readable_lang = ?, readable_dir = ?, readable_byline = ?,
+		        readable_site_name = ?, updated_at = ? WHERE id = ?`,
 		content,
+		meta.Language,
+		meta.Direction,
+		meta.Byline,
+		meta.SiteName,
 		formatTime(time.Now()),
 		id,
 	)
 	return err
 }
 
+func (r *entryRepository) ListWithoutSnippet(ctx context.Context, limit int) ([]model.Entry, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, content FROM entries
+		 WHERE snippet IS NULL AND content IS NOT NULL
+		 ORDER BY id LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.Entry
+	for rows.Next() {
+		var e model.Entry
+		if err := rows.Scan(&e.ID, &e.Content); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *entryRepository) ListThumbnailSources(ctx context.Context, limit int) ([]model.Entry, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT e.id, e.feed_id, e.url, e.thumbnail_url FROM entries e
+		 JOIN feeds f ON f.id = e.feed_id
+		 JOIN folders d ON d.id = f.folder_id
+		 WHERE d.type = 'picture' AND d.pregenerate_thumbnails = 1
+		   AND f.archived_at IS NULL AND e.thumbnail_url IS NOT NULL
+		 ORDER BY e.published_at DESC, e.id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.Entry
+	for rows.Next() {
+		var e model.Entry
+		if err := rows.Scan(&e.ID, &e.FeedID, &e.URL, &e.ThumbnailURL); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *entryRepository) ListUncheckedImages(ctx context.Context, limit int) ([]model.Entry, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT e.id, e.url, e.thumbnail_url FROM entries e
+		 JOIN feeds f ON f.id = e.feed_id
+		 WHERE f.type = 'picture' AND e.thumbnail_url IS NOT NULL AND e.nsfw_reason IS NULL
+		   AND NOT EXISTS (SELECT 1 FROM entry_nsfw_checks c WHERE c.entry_id = e.id)
+		 ORDER BY e.published_at DESC, e.id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []model.Entry
+	for rows.Next() {
+		var e model.Entry
+		if err := rows.Scan(&e.ID, &e.URL, &e.ThumbnailURL); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func (r *entryRepository) SaveNSFWCheck(ctx context.Context, entryID int64, flagged bool, checkedAt time.Time) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO entry_nsfw_checks (entry_id, checked_at) VALUES (?, ?)
+		 ON CONFLICT(entry_id) DO UPDATE SET checked_at = excluded.checked_at`,
+		entryID,
+		formatTime(checkedAt),
+	); err != nil {
+		return err
+	}
+	if !flagged {
+		return nil
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE entries SET nsfw_reason = ? WHERE id = ? AND nsfw_reason IS NULL`,
+		model.NSFWVision,
+		entryID,
+	)
+	return err
+}
+
+func (r *entryRepository) UpdateSnippet(ctx context.Context, id int64, snippet string) error {
+	// The entries_fts_au trigger reindexes the row
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE entries SET snippet = ? WHERE id = ?`,
+		snippet,
+		id,
+	)
+	return err
+}
+
 func (r *entryRepository) UpdateStarredStatus(ctx context.Context, id int64, starred bool) error {
 	starredInt := 0
 	if starred {
@@ -356,8 +1115,143 @@ func (r *entryRepository) UpdateStarredStatus(ctx context.Context, id int64, sta
 	return err
 }
 
+func (r *entryRepository) ApplyBatch(ctx context.Context, ids []int64, op string) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	placeholders := strings.Repeat("?,", len(ids)-1) + "?"
+	idArgs := make([]interface{}, len(ids))
+	for i, id := range ids {
+		idArgs[i] = id
+	}
+
+	var column string
+	value := 0
+	switch op {
+	case model.EntryOpRead:
+		column, value = "read", 1
+	case model.EntryOpUnread:
+		column = "read"
+	case model.EntryOpStar:
+		column, value = "starred", 1
+	case model.EntryOpUnstar:
+		column = "starred"
+	case model.EntryOpDelete:
+		result, err := r.db.ExecContext(ctx, `DELETE FROM entries WHERE id IN (`+placeholders+`)`, idArgs...)
+		if err != nil {
+			return 0, fmt.Errorf("delete entries batch: %w", err)
+		}
+		return result.RowsAffected()
+	default:
+		return 0, fmt.Errorf("unknown entry operation %q", op)
+	}
+
+	args := append([]interface{}{value, formatTime(time.Now()), value}, idArgs...)
+	result, err := r.db.ExecContext(
+		ctx,
+		`UPDATE entries SET `+column+` = ?, updated_at = ? WHERE `+column+` != ? AND id IN (`+placeholders+`)`,
+		args...,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("update entries batch: %w", err)
+	}
+	return result.RowsAffected()
+}
+
 func (r *entryRepository) GetStarredCount(ctx context.Context) (int, error) {
 	var count int
 	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM entries WHERE starred = 1`).Scan(&count)
 	return count, err
 }
+
+func (r *entryRepository) GetStarredCounts(ctx context.Context) ([]StarredCount, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT e.feed_id, COALESCE(e.folder_id, f.folder_id) AS folder, COUNT(*)
+		 FROM entries e
+		 INNER JOIN feeds f ON e.feed_id = f.id
+		 WHERE e.starred = 1
+		 GROUP BY e.feed_id, folder`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var counts []StarredCount
+	for rows.Next() {
+		var sc StarredCount
+		var folderID sql.NullInt64
+		if err := rows.Scan(&sc.FeedID, &folderID, &sc.Count); err != nil {
+			return nil, err
+		}
+		if folderID.Valid {
+			sc.FolderID = &folderID.Int64
+		}
+		counts = append(counts, sc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+func (r *entryRepository) ListStateChanges(ctx context.Context, afterSeq int64, limit int) ([]model.EntryStateChange, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT c.seq, f.url, e.url, e.read, e.starred
+		 FROM entry_state_changes c
+		 INNER JOIN entries e ON e.id = c.entry_id
+		 INNER JOIN feeds f ON f.id = e.feed_id
+		 WHERE c.seq > ? AND e.url IS NOT NULL
+		 ORDER BY c.seq
+		 LIMIT ?`,
+		afterSeq,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []model.EntryStateChange
+	for rows.Next() {
+		var c model.EntryStateChange
+		var readInt, starredInt int
+		if err := rows.Scan(&c.Seq, &c.FeedURL, &c.URL, &readInt, &starredInt); err != nil {
+			return nil, err
+		}
+		c.Read = readInt == 1
+		c.Starred = starredInt == 1
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func (r *entryRepository) SetStateByURL(ctx context.Context, feedURL, url string, read, starred bool) (bool, error) {
+	readInt, starredInt := 0, 0
+	if read {
+		readInt = 1
+	}
+	if starred {
+		starredInt = 1
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		`UPDATE entries SET read = ?, starred = ?, updated_at = ?
+		 WHERE url = ? AND feed_id = (SELECT id FROM feeds WHERE url = ?)`,
+		readInt,
+		starredInt,
+		formatTime(time.Now()),
+		url,
+		feedURL,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,1246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+// queryPlan returns the detail lines of EXPLAIN QUERY PLAN for query.
+func queryPlan(t *testing.T, db *sql.DB, query string, args ...interface{}) []string {
+	t.Helper()
+	rows, err := db.Query("EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		t.Fatalf("explain query: %v", err)
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			t.Fatalf("scan plan: %v", err)
+		}
+		plan = append(plan, detail)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("read plan: %v", err)
+	}
+	return plan
+}
+
+// assertIndexed fails if the plan reads a table without an index (rowid
+// lookups count as indexed), or sorts in a temp B-tree when sorted is set.
+func assertIndexed(t *testing.T, plan []string, sorted bool) {
+	t.Helper()
+	for _, step := range plan {
+		indexed := strings.Contains(step, " INDEX ") || strings.Contains(step, " INTEGER PRIMARY KEY ")
+		if (strings.HasPrefix(step, "SCAN ") || strings.HasPrefix(step, "SEARCH ")) && !indexed {
+			t.Errorf("unindexed table access %q in plan %q", step, plan)
+		}
+		if sorted && strings.Contains(step, "TEMP B-TREE") {
+			t.Errorf("sort not served by an index in plan %q", plan)
+		}
+	}
+}
+
+func TestEntryRepository_ListQueryPlans(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+
+	id := int64(1)
+	contentType := "picture"
+	published := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		filter EntryListFilter
+		// sorted reports whether an index should also yield the list order.
+		// Folder and content type filters merge several feeds and sort the
+		// (already index-narrowed) result.
+		sorted bool
+	}{
+		{"all", EntryListFilter{Limit: 50}, true},
+		{"feed", EntryListFilter{FeedID: &id, Limit: 50}, true},
+		{"feed unread", EntryListFilter{FeedID: &id, UnreadOnly: true, Limit: 50}, true},
+		{"unread", EntryListFilter{UnreadOnly: true, Limit: 50}, true},
+		{"starred", EntryListFilter{StarredOnly: true, Limit: 50}, true},
+		{"folder", EntryListFilter{FolderID: &id, Limit: 50}, false},
+		{"content type", EntryListFilter{ContentType: &contentType, HasThumbnail: true, Limit: 50}, false},
+		{"after", EntryListFilter{After: &EntryCursor{PublishedAt: &published, ID: id}, Limit: 50}, true},
+		{"feed after", EntryListFilter{FeedID: &id, After: &EntryCursor{PublishedAt: &published, ID: id}, Limit: 50}, true},
+		{"after undated", EntryListFilter{After: &EntryCursor{ID: id}, Limit: 50}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := buildListQuery(tt.filter, listOrder)
+			assertIndexed(t, queryPlan(t, db, query, args...), tt.sorted)
+		})
+	}
+}
+
+func TestEntryRepository_ListAfterCursor(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(hours int) *time.Time {
+		t := base.Add(time.Duration(hours) * time.Hour)
+		return &t
+	}
+	// Newest first, the two entries published together by ID, undated last
+	var want []int64
+	for _, published := range []*time.Time{at(3), at(2), at(2), at(1), nil, nil} {
+		want = append(want, testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, PublishedAt: published}))
+	}
+	sort.Slice(want[1:3], func(i, j int) bool { return want[1+i] > want[1+j] })
+	sort.Slice(want[4:], func(i, j int) bool { return want[4+i] > want[4+j] })
+
+	var got []int64
+	filter := EntryListFilter{FeedID: &feedID, Limit: 2}
+	for page := 0; page < 5; page++ {
+		entries, err := repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("list page %d: %v", page, err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+		for _, e := range entries {
+			got = append(got, e.ID)
+		}
+		last := entries[len(entries)-1]
+		filter.After = &EntryCursor{PublishedAt: last.PublishedAt, ID: last.ID}
+		// A new entry arriving between pages does not shift the next one
+		testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, PublishedAt: at(10 + page)})
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected every entry once in list order %v, got %v", want, got)
+	}
+}
+
+func TestEntryRepository_ArchiveQueryPlan(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+
+	id := int64(1)
+	for _, filter := range []EntryListFilter{{}, {FeedID: &id}, {StarredOnly: true}, {FolderID: &id}} {
+		query, args := buildArchiveQuery(filter, 7)
+		assertIndexed(t, queryPlan(t, db, query, args...), false)
+	}
+}
+
+func TestEntryRepository_UnreadCountsQueryPlan(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+
+	plan := queryPlan(t, db, unreadCountsQuery)
+	assertIndexed(t, plan, true)
+	if len(plan) == 0 || !strings.Contains(plan[0], "COVERING INDEX") {
+		t.Errorf("expected unread counts to use a covering index, got %q", plan)
+	}
+}
+
+func TestEntryRepository_GetUnreadCounts(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	root := testutil.SeedFolder(t, db, "Root", nil, "article")
+	child := testutil.SeedFolder(t, db, "Child", &root, "article")
+	other := testutil.SeedFolder(t, db, "Other", nil, "article")
+	rootFeed := testutil.SeedFeed(t, db, model.Feed{Title: "Root", URL: "https://example.com/root", FolderID: &root})
+	childFeed := testutil.SeedFeed(t, db, model.Feed{Title: "Child", URL: "https://example.com/child", FolderID: &child, Type: "picture"})
+	otherFeed := testutil.SeedFeed(t, db, model.Feed{Title: "Other", URL: "https://example.com/other", FolderID: &other})
+
+	testutil.SeedEntry(t, db, model.Entry{FeedID: rootFeed})
+	testutil.SeedEntry(t, db, model.Entry{FeedID: rootFeed, Read: true})
+	testutil.SeedEntry(t, db, model.Entry{FeedID: childFeed})
+	testutil.SeedEntry(t, db, model.Entry{FeedID: otherFeed})
+	// Moved by a filter from the root feed to the other folder
+	moved := testutil.SeedEntry(t, db, model.Entry{FeedID: rootFeed})
+	if _, err := db.Exec(`UPDATE entries SET folder_id = ? WHERE id = ?`, other, moved); err != nil {
+		t.Fatal(err)
+	}
+
+	picture := "picture"
+	article := "article"
+	cases := []struct {
+		name   string
+		filter UnreadCountFilter
+		want   map[int64]int
+	}{
+		{"all", UnreadCountFilter{}, map[int64]int{rootFeed: 2, childFeed: 1, otherFeed: 1}},
+		{"content type", UnreadCountFilter{ContentType: &picture}, map[int64]int{childFeed: 1}},
+		{"folder subtree", UnreadCountFilter{FolderID: &root}, map[int64]int{rootFeed: 1, childFeed: 1}},
+		{"moved entries", UnreadCountFilter{FolderID: &other}, map[int64]int{rootFeed: 1, otherFeed: 1}},
+		{"folder and type", UnreadCountFilter{FolderID: &root, ContentType: &article}, map[int64]int{rootFeed: 1}},
+	}
+	for _, tc := range cases {
+		counts, err := repo.GetUnreadCounts(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		got := make(map[int64]int)
+		for _, uc := range counts {
+			got[uc.FeedID] += uc.Count
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for feedID, count := range tc.want {
+			if got[feedID] != count {
+				t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+				break
+			}
+		}
+	}
+
+	// Entries count under their feed's folder, or the one a filter moved them to
+	counts, err := repo.GetUnreadCounts(ctx, UnreadCountFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	folders := make(map[int64]int)
+	for _, uc := range counts {
+		if uc.FolderID != nil {
+			folders[*uc.FolderID] += uc.Count
+		}
+	}
+	if len(folders) != 3 || folders[root] != 1 || folders[child] != 1 || folders[other] != 2 {
+		t.Errorf("unexpected folder counts %v", folders)
+	}
+}
+
+func TestEntryRepository_GetStarredCounts(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	folder := testutil.SeedFolder(t, db, "Folder", nil, "article")
+	other := testutil.SeedFolder(t, db, "Other", nil, "article")
+	filed := testutil.SeedFeed(t, db, model.Feed{Title: "Filed", URL: "https://example.com/filed", FolderID: &folder})
+	loose := testutil.SeedFeed(t, db, model.Feed{Title: "Loose", URL: "https://example.com/loose"})
+
+	testutil.SeedEntry(t, db, model.Entry{FeedID: filed, Starred: true})
+	testutil.SeedEntry(t, db, model.Entry{FeedID: filed, Starred: true})
+	testutil.SeedEntry(t, db, model.Entry{FeedID: filed})
+	testutil.SeedEntry(t, db, model.Entry{FeedID: loose, Starred: true})
+	moved := testutil.SeedEntry(t, db, model.Entry{FeedID: loose, Starred: true})
+	if _, err := db.Exec(`UPDATE entries SET folder_id = ? WHERE id = ?`, other, moved); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := repo.GetStarredCounts(ctx)
+	if err != nil {
+		t.Fatalf("GetStarredCounts: %v", err)
+	}
+	got := make(map[string]int)
+	for _, sc := range counts {
+		key := fmt.Sprintf("%d/", sc.FeedID)
+		if sc.FolderID != nil {
+			key += fmt.Sprint(*sc.FolderID)
+		}
+		got[key] = sc.Count
+	}
+	want := map[string]int{
+		fmt.Sprintf("%d/%d", filed, folder): 2,
+		fmt.Sprintf("%d/", loose):           1,
+		fmt.Sprintf("%d/%d", loose, other):  1,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for key, count := range want {
+		if got[key] != count {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestEntryRepository_Archive(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	seed := func(published string, read bool) {
+		entry := model.Entry{FeedID: feedID, Read: read}
+		if published != "" {
+			at, err := time.Parse(time.RFC3339, published)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entry.PublishedAt = &at
+		}
+		testutil.SeedEntry(t, db, entry)
+	}
+	seed("2024-12-31T23:59:59Z", false)
+	seed("2025-03-01T00:00:00Z", true)
+	seed("2025-03-31T12:00:00Z", false)
+	seed("2025-04-02T08:00:00Z", false)
+	seed("", false)
+
+	months, err := repo.Archive(ctx, EntryListFilter{}, 7)
+	if err != nil {
+		t.Fatalf("archive by month: %v", err)
+	}
+	wantMonths := []model.ArchivePeriod{
+		{Period: "2025-04", Count: 1, UnreadCount: 1},
+		{Period: "2025-03", Count: 2, UnreadCount: 1},
+		{Period: "2024-12", Count: 1, UnreadCount: 1},
+	}
+	if !reflect.DeepEqual(months, wantMonths) {
+		t.Errorf("by month: got %+v, want %+v", months, wantMonths)
+	}
+
+	years, err := repo.Archive(ctx, EntryListFilter{}, 4)
+	if err != nil {
+		t.Fatalf("archive by year: %v", err)
+	}
+	wantYears := []model.ArchivePeriod{
+		{Period: "2025", Count: 3, UnreadCount: 2},
+		{Period: "2024", Count: 1, UnreadCount: 1},
+	}
+	if !reflect.DeepEqual(years, wantYears) {
+		t.Errorf("by year: got %+v, want %+v", years, wantYears)
+	}
+
+	entries, err := repo.List(ctx, EntryListFilter{PublishedFrom: "2025-03", PublishedBefore: "2025-04"})
+	if err != nil {
+		t.Fatalf("list period: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected 2 entries in 2025-03, got %d", len(entries))
+	}
+}
+
+func TestEntryRepository_StateChanges(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedURL := "https://example.com/feed"
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: feedURL})
+	urlA, urlB := "https://example.com/a", "https://example.com/b"
+	idA := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlA})
+	idB := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlB})
+
+	if _, err := repo.UpdateReadStatus(ctx, []int64{idA}, true); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	if err := repo.UpdateStarredStatus(ctx, idB, true); err != nil {
+		t.Fatalf("star: %v", err)
+	}
+	// A second change to the same entry moves it to the end of the log
+	if err := repo.UpdateStarredStatus(ctx, idA, true); err != nil {
+		t.Fatalf("star: %v", err)
+	}
+	// Writing the same state again is not a change
+	if err := repo.UpdateStarredStatus(ctx, idB, true); err != nil {
+		t.Fatalf("star again: %v", err)
+	}
+
+	changes, err := repo.ListStateChanges(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("list changes: %v", err)
+	}
+	if len(changes) != 2 || changes[0].URL != urlB || changes[1].URL != urlA {
+		t.Fatalf("expected changes for b then a, got %+v", changes)
+	}
+	if !changes[1].Read || !changes[1].Starred || changes[1].FeedURL != feedURL {
+		t.Errorf("unexpected state for a: %+v", changes[1])
+	}
+
+	after, err := repo.ListStateChanges(ctx, changes[0].Seq, 10)
+	if err != nil {
+		t.Fatalf("list changes after cursor: %v", err)
+	}
+	if len(after) != 1 || after[0].URL != urlA {
+		t.Errorf("expected only a after cursor, got %+v", after)
+	}
+
+	found, err := repo.SetStateByURL(ctx, feedURL, urlB, true, false)
+	if err != nil || !found {
+		t.Fatalf("set state by url: found=%v err=%v", found, err)
+	}
+	entry, err := repo.GetByID(ctx, idB)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if !entry.Read || entry.Starred {
+		t.Errorf("expected b read and unstarred, got read=%v starred=%v", entry.Read, entry.Starred)
+	}
+
+	found, err = repo.SetStateByURL(ctx, feedURL, "https://example.com/missing", true, false)
+	if err != nil || found {
+		t.Errorf("expected missing entry to report not found, got found=%v err=%v", found, err)
+	}
+}
+
+func TestEntryRepository_ApplyBatch(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	urlA, urlB, urlC := "https://example.com/a", "https://example.com/b", "https://example.com/c"
+	idA := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlA})
+	idB := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlB, Read: true})
+	idC := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlC})
+	missing := int64(999999)
+
+	apply := func(op string, ids ...int64) int64 {
+		t.Helper()
+		updated, err := repo.ApplyBatch(ctx, ids, op)
+		if err != nil {
+			t.Fatalf("%s: %v", op, err)
+		}
+		return updated
+	}
+
+	// Entries already read and unknown IDs are not counted
+	if got := apply(model.EntryOpRead, idA, idB, missing); got != 1 {
+		t.Errorf("read: expected 1 updated, got %d", got)
+	}
+	if got := apply(model.EntryOpStar, idA, idC); got != 2 {
+		t.Errorf("star: expected 2 updated, got %d", got)
+	}
+	if got := apply(model.EntryOpUnread, idA, idB, idC); got != 2 {
+		t.Errorf("unread: expected 2 updated, got %d", got)
+	}
+	if got := apply(model.EntryOpUnstar, idC); got != 1 {
+		t.Errorf("unstar: expected 1 updated, got %d", got)
+	}
+	entry, err := repo.GetByID(ctx, idA)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if entry.Read || !entry.Starred {
+		t.Errorf("expected a unread and starred, got read=%v starred=%v", entry.Read, entry.Starred)
+	}
+
+	changes, err := repo.ListStateChanges(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("list changes: %v", err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("expected a state change per changed entry, got %+v", changes)
+	}
+
+	if got := apply(model.EntryOpDelete, idB, idC, missing); got != 2 {
+		t.Errorf("delete: expected 2 deleted, got %d", got)
+	}
+	if _, err := repo.GetByID(ctx, idB); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected b to be deleted, got %v", err)
+	}
+	if _, err := repo.ApplyBatch(ctx, []int64{idA}, "archive"); err == nil {
+		t.Error("expected an unknown operation to fail")
+	}
+}
+
+func TestEntryRepository_Revision(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	url := "https://example.com/a"
+	id := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &url})
+
+	entry, err := repo.GetByURL(ctx, feedID, url)
+	if err != nil {
+		t.Fatalf("get by url: %v", err)
+	}
+	if entry.ID != id || entry.Revision != nil {
+		t.Fatalf("expected entry %d without revision, got %d %+v", id, entry.ID, entry.Revision)
+	}
+	if _, err := repo.GetByURL(ctx, feedID, "https://example.com/missing"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+
+	titleBefore := "Old title"
+	changedAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	first := model.EntryRevision{
+		TitleBefore:  &titleBefore,
+		WordsAdded:   1,
+		WordsRemoved: 1,
+		Changes:      []model.TextChange{{Removed: "Monday", Added: "Tuesday"}},
+		ChangedAt:    changedAt,
+	}
+	if err := repo.SaveRevision(ctx, id, first); err != nil {
+		t.Fatalf("save revision: %v", err)
+	}
+	// A later revision replaces the earlier one
+	second := model.EntryRevision{
+		WordsAdded: 2,
+		Changes:    []model.TextChange{{Added: "new words"}},
+		ChangedAt:  changedAt.Add(time.Hour),
+	}
+	if err := repo.SaveRevision(ctx, id, second); err != nil {
+		t.Fatalf("save second revision: %v", err)
+	}
+
+	entry, err = repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	rev := entry.Revision
+	if rev == nil {
+		t.Fatal("expected a revision")
+	}
+	if rev.TitleBefore != nil || rev.WordsAdded != 2 || rev.WordsRemoved != 0 || !rev.ChangedAt.Equal(second.ChangedAt) {
+		t.Errorf("unexpected revision: %+v", rev)
+	}
+	if len(rev.Changes) != 1 || rev.Changes[0] != second.Changes[0] {
+		t.Errorf("unexpected changes: %+v", rev.Changes)
+	}
+}
+
+func TestEntryRepository_GetByTitlePublished(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	otherFeedID := testutil.SeedFeed(t, db, model.Feed{Title: "Other", URL: "https://example.com/other"})
+	title := "Weekly notes"
+	published := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	urlA, urlB := "https://example.com/post?sid=aaa", "https://example.com/post?sid=bbb"
+	id := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, Title: &title, URL: &urlA, PublishedAt: &published})
+	testutil.SeedEntry(t, db, model.Entry{FeedID: otherFeedID, Title: &title, URL: &urlB, PublishedAt: &published})
+
+	entry, err := repo.GetByTitlePublished(ctx, feedID, title, published)
+	if err != nil {
+		t.Fatalf("get by title and publish time: %v", err)
+	}
+	if entry.ID != id || entry.URL == nil || *entry.URL != urlA {
+		t.Errorf("expected entry %d, got %d (%v)", id, entry.ID, entry.URL)
+	}
+
+	if _, err := repo.GetByTitlePublished(ctx, feedID, title, published.Add(time.Minute)); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for another publish time, got %v", err)
+	}
+	if _, err := repo.GetByTitlePublished(ctx, feedID, "Other title", published); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for another title, got %v", err)
+	}
+
+	assertIndexed(t, queryPlan(t, db, entrySelect+sameItemWhere, model.LinkDead, feedID, "2025-03-01T09:30:00Z", title), false)
+}
+
+func TestEntryRepository_Images(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Photos", URL: "https://example.com/feed", Type: "picture"})
+	url := "https://example.com/set"
+	save := func(images []string) model.Entry {
+		t.Helper()
+		if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &url, Images: images}); err != nil {
+			t.Fatalf("save entry: %v", err)
+		}
+		stored, err := repo.GetByURL(ctx, feedID, url)
+		if err != nil {
+			t.Fatalf("get by url: %v", err)
+		}
+		entry, err := repo.GetByID(ctx, stored.ID)
+		if err != nil {
+			t.Fatalf("get entry: %v", err)
+		}
+		return entry
+	}
+
+	gallery := []string{"https://cdn.example.com/b.jpg", "https://cdn.example.com/a.jpg", "https://cdn.example.com/c.jpg"}
+	if entry := save(gallery); !reflect.DeepEqual(entry.Images, gallery) {
+		t.Errorf("expected gallery %v, got %v", gallery, entry.Images)
+	}
+
+	// Saving without images keeps the gallery
+	if entry := save(nil); !reflect.DeepEqual(entry.Images, gallery) {
+		t.Errorf("expected gallery to be kept, got %v", entry.Images)
+	}
+
+	// A new gallery replaces the old one
+	if entry := save([]string{"https://cdn.example.com/a.jpg"}); !reflect.DeepEqual(entry.Images, []string{"https://cdn.example.com/a.jpg"}) {
+		t.Errorf("expected the gallery to be replaced, got %v", entry.Images)
+	}
+	if entry := save([]string{}); len(entry.Images) != 0 {
+		t.Errorf("expected an empty gallery, got %v", entry.Images)
+	}
+}
+
+func TestEntryRepository_UpdateReadableContent(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	entryID := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+
+	meta := model.ReadableMeta{Language: "ar", Direction: model.DirectionRTL, Byline: "Author", SiteName: "Site"}
+	if err := repo.UpdateReadableContent(ctx, entryID, "<p>نص</p>", meta); err != nil {
+		t.Fatalf("update readable content: %v", err)
+	}
+	entry, err := repo.GetByID(ctx, entryID)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if entry.ReadableContent == nil || *entry.ReadableContent != "<p>نص</p>" || entry.ReadableMeta != meta {
+		t.Errorf("unexpected readable content %v, %+v", entry.ReadableContent, entry.ReadableMeta)
+	}
+}
+
+func TestEntryRepository_ListThumbnailSources(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	folders := NewFolderRepository(db)
+	ctx := context.Background()
+
+	enabled := testutil.SeedFolder(t, db, "Photos", nil, "picture")
+	disabled := testutil.SeedFolder(t, db, "More photos", nil, "picture")
+	if err := folders.UpdatePregenerateThumbnails(ctx, enabled, true); err != nil {
+		t.Fatalf("enable thumbnails: %v", err)
+	}
+	photos := testutil.SeedFeed(t, db, model.Feed{Title: "Photos", URL: "https://example.com/photos", Type: "picture", FolderID: &enabled})
+	others := testutil.SeedFeed(t, db, model.Feed{Title: "Others", URL: "https://example.com/others", Type: "picture", FolderID: &disabled})
+
+	older, newer := time.Now().Add(-2*time.Hour), time.Now().Add(-time.Hour)
+	thumbA, thumbB, thumbC := "https://cdn.example.com/a.jpg", "https://cdn.example.com/b.jpg", "https://cdn.example.com/c.jpg"
+	urlA, urlB, urlC, urlD := "https://example.com/a", "https://example.com/b", "https://example.com/c", "https://example.com/d"
+	idA := testutil.SeedEntry(t, db, model.Entry{FeedID: photos, URL: &urlA, ThumbnailURL: &thumbA, PublishedAt: &older})
+	idB := testutil.SeedEntry(t, db, model.Entry{FeedID: photos, URL: &urlB, ThumbnailURL: &thumbB, PublishedAt: &newer})
+	testutil.SeedEntry(t, db, model.Entry{FeedID: photos, URL: &urlC, PublishedAt: &newer})
+	testutil.SeedEntry(t, db, model.Entry{FeedID: others, URL: &urlD, ThumbnailURL: &thumbC, PublishedAt: &newer})
+
+	sources, err := repo.ListThumbnailSources(ctx, 10)
+	if err != nil {
+		t.Fatalf("list thumbnail sources: %v", err)
+	}
+	if len(sources) != 2 || sources[0].ID != idB || sources[1].ID != idA {
+		t.Fatalf("expected entries %d and %d newest first, got %+v", idB, idA, sources)
+	}
+	if *sources[0].ThumbnailURL != thumbB || *sources[0].URL != urlB {
+		t.Errorf("expected thumbnail and URL of the entry, got %+v", sources[0])
+	}
+
+	if sources, err := repo.ListThumbnailSources(ctx, 1); err != nil || len(sources) != 1 {
+		t.Errorf("expected the limit to apply, got %d (%v)", len(sources), err)
+	}
+}
+
+func TestEntryRepository_NSFW(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	folder := testutil.SeedFolder(t, db, "Photos", nil, "picture")
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Photos", URL: "https://example.com/photos", Type: "picture", FolderID: &folder})
+	reason := model.NSFWCategory
+	flaggedURL, cleanURL := "https://example.com/flagged", "https://example.com/clean"
+	thumbFlagged, thumbClean := "https://cdn.example.com/flagged.jpg", "https://cdn.example.com/clean.jpg"
+	if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &flaggedURL, ThumbnailURL: &thumbFlagged, NSFWReason: &reason}); err != nil {
+		t.Fatalf("create flagged entry: %v", err)
+	}
+	if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &cleanURL, ThumbnailURL: &thumbClean}); err != nil {
+		t.Fatalf("create clean entry: %v", err)
+	}
+
+	// A later refresh without the marker keeps the flag
+	if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &flaggedURL, ThumbnailURL: &thumbFlagged}); err != nil {
+		t.Fatalf("update flagged entry: %v", err)
+	}
+	flagged, err := repo.GetByURL(ctx, feedID, flaggedURL)
+	if err != nil {
+		t.Fatalf("get flagged entry: %v", err)
+	}
+	if flagged.NSFWReason == nil || *flagged.NSFWReason != model.NSFWCategory {
+		t.Fatalf("expected the category flag to stick, got %v", flagged.NSFWReason)
+	}
+
+	all, err := repo.List(ctx, EntryListFilter{FeedID: &feedID})
+	if err != nil {
+		t.Fatalf("list entries: %v", err)
+	}
+	safe, err := repo.List(ctx, EntryListFilter{FeedID: &feedID, ExcludeNSFW: true})
+	if err != nil {
+		t.Fatalf("list safe entries: %v", err)
+	}
+	if len(all) != 2 || len(safe) != 1 || *safe[0].URL != cleanURL {
+		t.Fatalf("expected 2 entries and 1 safe one, got %d and %+v", len(all), safe)
+	}
+
+	// Only the unflagged image still needs a vision check
+	unchecked, err := repo.ListUncheckedImages(ctx, 10)
+	if err != nil {
+		t.Fatalf("list unchecked images: %v", err)
+	}
+	if len(unchecked) != 1 || *unchecked[0].ThumbnailURL != thumbClean {
+		t.Fatalf("expected the clean entry unchecked, got %+v", unchecked)
+	}
+	if err := repo.SaveNSFWCheck(ctx, unchecked[0].ID, true, time.Now()); err != nil {
+		t.Fatalf("save check: %v", err)
+	}
+	if unchecked, err := repo.ListUncheckedImages(ctx, 10); err != nil || len(unchecked) != 0 {
+		t.Errorf("expected no unchecked images, got %d (%v)", len(unchecked), err)
+	}
+	clean, err := repo.GetByID(ctx, unchecked[0].ID)
+	if err != nil {
+		t.Fatalf("get checked entry: %v", err)
+	}
+	if clean.NSFWReason == nil || *clean.NSFWReason != model.NSFWVision {
+		t.Errorf("expected the vision flag, got %v", clean.NSFWReason)
+	}
+}
+
+func TestEntryRepository_StarredOrFeed(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	saved := testutil.SeedFeed(t, db, model.Feed{Title: "Saved pages", URL: model.SavedPagesURL, Type: "article"})
+	blog := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	pageURL, starredURL, otherURL := "https://example.com/page", "https://example.com/starred", "https://example.com/other"
+	icon := "example.com.png"
+	for _, entry := range []model.Entry{
+		{FeedID: saved, URL: &pageURL, IconPath: &icon},
+		{FeedID: blog, URL: &starredURL},
+		{FeedID: blog, URL: &otherURL},
+	} {
+		if err := repo.CreateOrUpdate(ctx, entry); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+	}
+	starred, err := repo.GetByURL(ctx, blog, starredURL)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if err := repo.UpdateStarredStatus(ctx, starred.ID, true); err != nil {
+		t.Fatalf("star entry: %v", err)
+	}
+
+	list, err := repo.List(ctx, EntryListFilter{StarredOrFeedID: &saved})
+	if err != nil {
+		t.Fatalf("list entries: %v", err)
+	}
+	urls := make(map[string]bool)
+	for _, entry := range list {
+		urls[*entry.URL] = true
+		if *entry.URL == pageURL && (entry.IconPath == nil || *entry.IconPath != icon) {
+			t.Errorf("expected the saved page's icon, got %v", entry.IconPath)
+		}
+	}
+	if len(list) != 2 || !urls[pageURL] || !urls[starredURL] {
+		t.Errorf("expected the saved page and the starred entry, got %v", urls)
+	}
+}
+
+func TestEntryRepository_FilteredState(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	news := testutil.SeedFolder(t, db, "News", nil, "article")
+	muted := testutil.SeedFolder(t, db, "Muted", nil, "article")
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: "https://example.com/feed", FolderID: &news})
+	keptURL, movedURL := "https://example.com/kept", "https://example.com/moved"
+	if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &keptURL}); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &movedURL, Read: true, Starred: true, FolderID: &muted}); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	// Saving a known entry again keeps its state
+	if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &movedURL}); err != nil {
+		t.Fatalf("update entry: %v", err)
+	}
+	moved, err := repo.GetByURL(ctx, feedID, movedURL)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if !moved.Read || !moved.Starred {
+		t.Errorf("expected the new entry's state to be kept, got read=%v starred=%v", moved.Read, moved.Starred)
+	}
+
+	folderURLs := func(folderID int64) []string {
+		t.Helper()
+		list, err := repo.List(ctx, EntryListFilter{FolderID: &folderID})
+		if err != nil {
+			t.Fatalf("list entries: %v", err)
+		}
+		var urls []string
+		for _, entry := range list {
+			urls = append(urls, *entry.URL)
+		}
+		return urls
+	}
+	if urls := folderURLs(news); len(urls) != 1 || urls[0] != keptURL {
+		t.Errorf("expected only the kept entry in its feed's folder, got %v", urls)
+	}
+	if urls := folderURLs(muted); len(urls) != 1 || urls[0] != movedURL {
+		t.Errorf("expected the moved entry in its folder, got %v", urls)
+	}
+
+	if _, err := repo.UpdateReadStatus(ctx, []int64{moved.ID}, false); err != nil {
+		t.Fatalf("mark unread: %v", err)
+	}
+	if err := repo.MarkAllAsRead(ctx, nil, &news, nil); err != nil {
+		t.Fatalf("mark folder read: %v", err)
+	}
+	if moved, err = repo.GetByID(ctx, moved.ID); err != nil || moved.Read {
+		t.Errorf("expected marking the feed's folder read to leave the moved entry, got read=%v err=%v", moved.Read, err)
+	}
+
+	// Deleting the folder puts the entry back under its feed's
+	if _, err := db.ExecContext(ctx, `DELETE FROM folders WHERE id = ?`, muted); err != nil {
+		t.Fatalf("delete folder: %v", err)
+	}
+	if urls := folderURLs(news); len(urls) != 2 {
+		t.Errorf("expected both entries in the feed's folder, got %v", urls)
+	}
+}
+
+func TestEntryRepository_PruneOld(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	blog := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	news := testutil.SeedFeed(t, db, model.Feed{Title: "News", URL: "https://news.example.com/feed"})
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	seed := func(feedID int64, name string, age int, starred bool) {
+		t.Helper()
+		url := "https://example.com/" + name
+		id := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &url, Starred: starred})
+		fetched := now.AddDate(0, 0, -age).Format(time.RFC3339)
+		if _, err := db.ExecContext(ctx, `UPDATE entries SET created_at = ? WHERE id = ?`, fetched, id); err != nil {
+			t.Fatalf("age entry: %v", err)
+		}
+	}
+	seed(blog, "blog-1", 1, false)
+	seed(blog, "blog-2", 2, true)
+	seed(blog, "blog-3", 3, false)
+	seed(blog, "blog-40", 40, false)
+	seed(blog, "blog-50", 50, true)
+	seed(news, "news-1", 1, false)
+	seed(news, "news-40", 40, false)
+	remaining := func() []string {
+		t.Helper()
+		rows, err := db.QueryContext(ctx, `SELECT url FROM entries ORDER BY url`)
+		if err != nil {
+			t.Fatalf("list entries: %v", err)
+		}
+		defer rows.Close()
+		var urls []string
+		for rows.Next() {
+			var url string
+			if err := rows.Scan(&url); err != nil {
+				t.Fatalf("scan entry: %v", err)
+			}
+			urls = append(urls, strings.TrimPrefix(url, "https://example.com/"))
+		}
+		return urls
+	}
+
+	if deleted, err := repo.PruneOld(ctx, time.Time{}, 0, 100); err != nil || deleted != 0 {
+		t.Fatalf("expected nothing pruned without a rule, got %d, %v", deleted, err)
+	}
+
+	// One at a time, older than 30 days: the starred blog-50 stays
+	for _, want := range []int64{1, 1, 0} {
+		deleted, err := repo.PruneOld(ctx, now.AddDate(0, 0, -30), 0, 1)
+		if err != nil {
+			t.Fatalf("prune by age: %v", err)
+		}
+		if deleted != want {
+			t.Errorf("expected %d pruned, got %d", want, deleted)
+		}
+	}
+	if got := strings.Join(remaining(), ","); got != "blog-1,blog-2,blog-3,blog-50,news-1" {
+		t.Errorf("unexpected entries after pruning by age: %s", got)
+	}
+
+	// Two per feed: the starred blog-2 counts towards them
+	deleted, err := repo.PruneOld(ctx, time.Time{}, 2, 100)
+	if err != nil {
+		t.Fatalf("prune by count: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 pruned, got %d", deleted)
+	}
+	if got := strings.Join(remaining(), ","); got != "blog-1,blog-2,blog-50,news-1" {
+		t.Errorf("unexpected entries after pruning by count: %s", got)
+	}
+}
+
+func TestEntryRepository_Search(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	entry := func(url, title, snippet string, published time.Time) model.Entry {
+		return model.Entry{FeedID: feedID, URL: &url, Title: &title, Snippet: &snippet, PublishedAt: &published}
+	}
+	for _, e := range []model.Entry{
+		entry("https://example.com/1", "Gardening notes", "Tomatoes need sun", day),
+		entry("https://example.com/2", "Weekly links", "A note on tomatoes and basil", day.Add(time.Hour)),
+		entry("https://example.com/3", "Release 2.0", "Faster startup", day.Add(2*time.Hour)),
+	} {
+		if err := repo.CreateOrUpdate(ctx, e); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+	}
+
+	titles := func(list []model.EntrySummary) []string {
+		var got []string
+		for _, e := range list {
+			got = append(got, *e.Title)
+		}
+		return got
+	}
+
+	// A title hit ranks above a newer snippet hit
+	found, err := repo.Search(ctx, EntryListFilter{Match: `"tomatoes" OR "gardening"`})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if got := titles(found); !reflect.DeepEqual(got, []string{"Gardening notes", "Weekly links"}) {
+		t.Errorf("unexpected search results %v", got)
+	}
+
+	// Updates are reindexed, and read changes do not disturb the index
+	if err := repo.CreateOrUpdate(ctx, entry("https://example.com/3", "Release 2.0", "Tomatoes in the logo", day.Add(2*time.Hour))); err != nil {
+		t.Fatalf("update entry: %v", err)
+	}
+	release, err := repo.GetByURL(ctx, feedID, "https://example.com/3")
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if _, err := repo.UpdateReadStatus(ctx, []int64{release.ID}, true); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	found, err = repo.List(ctx, EntryListFilter{Match: `"tomatoes"`})
+	if err != nil {
+		t.Fatalf("list matches: %v", err)
+	}
+	if got := titles(found); !reflect.DeepEqual(got, []string{"Release 2.0", "Weekly links", "Gardening notes"}) {
+		t.Errorf("expected matches newest first, got %v", got)
+	}
+	found, err = repo.Search(ctx, EntryListFilter{Match: `"tomatoes"`, UnreadOnly: true})
+	if err != nil {
+		t.Fatalf("search unread: %v", err)
+	}
+	if len(found) != 2 {
+		t.Errorf("expected the two unread matches, got %v", titles(found))
+	}
+
+	// A deleted entry leaves the index
+	if _, err := repo.DeleteByFeed(ctx, feedID, false); err != nil {
+		t.Fatalf("delete entries: %v", err)
+	}
+	var indexed int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM entries_fts WHERE entries_fts MATCH 'tomatoes'`).Scan(&indexed); err != nil {
+		t.Fatalf("count index: %v", err)
+	}
+	if indexed != 0 {
+		t.Errorf("expected deleted entries to leave the index, %d left", indexed)
+	}
+}
+
+func TestEntryRepository_CountPublishedSince(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	otherID := testutil.SeedFeed(t, db, model.Feed{Title: "Other", URL: "https://example.org/feed", Type: "article"})
+	now := time.Now()
+	hourAgo, twoDaysAgo := now.Add(-time.Hour), now.Add(-48*time.Hour)
+	for i, e := range []struct {
+		feedID    int64
+		published *time.Time
+	}{
+		{feedID, &hourAgo},
+		{feedID, &twoDaysAgo},
+		{feedID, nil}, // undated, found just now
+		{otherID, &hourAgo},
+	} {
+		url := "https://example.com/" + strconv.Itoa(i)
+		if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: e.feedID, URL: &url, PublishedAt: e.published}); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+	}
+
+	count, err := repo.CountPublishedSince(ctx, feedID, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 entries in the last day, got %d", count)
+	}
+}
+
+func TestEntryRepository_ListTitlesSince(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	otherID := testutil.SeedFeed(t, db, model.Feed{Title: "Other", URL: "https://example.org/feed", Type: "article"})
+	now := time.Now()
+	hourAgo, twoHoursAgo, twoDaysAgo := now.Add(-time.Hour), now.Add(-2*time.Hour), now.Add(-48*time.Hour)
+	for i, e := range []struct {
+		feedID    int64
+		title     string
+		published *time.Time
+	}{
+		{feedID, "Older", &twoHoursAgo},
+		{feedID, "Newer", &hourAgo},
+		{feedID, "Stale", &twoDaysAgo},
+		{feedID, "", &hourAgo},
+		{otherID, "Elsewhere", &hourAgo},
+	} {
+		url := "https://example.com/" + strconv.Itoa(i)
+		title := e.title
+		if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: e.feedID, URL: &url, Title: &title, PublishedAt: e.published}); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+	}
+
+	titles, err := repo.ListTitlesSince(ctx, feedID, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("list titles: %v", err)
+	}
+	if strings.Join(titles, ",") != "Newer,Older" {
+		t.Errorf("expected Newer,Older, got %v", titles)
+	}
+}
+
+func TestEntryRepository_OriginalPublishedAt(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	url := "https://example.com/launch"
+	seen := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	original := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &url, PublishedAt: &seen, OriginalPublishedAt: &original}); err != nil {
+		t.Fatalf("create entry: %v", err)
+	}
+	// Saving without it keeps the stored date
+	if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &url, PublishedAt: &seen}); err != nil {
+		t.Fatalf("update entry: %v", err)
+	}
+
+	entry, err := repo.GetByURL(ctx, feedID, url)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if entry.OriginalPublishedAt == nil || !entry.OriginalPublishedAt.Equal(original) {
+		t.Errorf("expected original date %v, got %v", original, entry.OriginalPublishedAt)
+	}
+	if entry.PublishedAt == nil || !entry.PublishedAt.Equal(seen) {
+		t.Errorf("expected publish date %v, got %v", seen, entry.PublishedAt)
+	}
+}
+
+func TestEntryRepository_MissingContent(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	body := "<p>Written</p>"
+	empty := ""
+	for i, content := range []*string{nil, &empty, &body} {
+		url := "https://example.com/" + strconv.Itoa(i)
+		if err := repo.CreateOrUpdate(ctx, model.Entry{FeedID: feedID, URL: &url, Content: content}); err != nil {
+			t.Fatalf("create entry: %v", err)
+		}
+	}
+
+	missing, err := repo.ListMissingContent(ctx, feedID, time.Now().Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatalf("list missing content: %v", err)
+	}
+	if len(missing) != 2 {
+		t.Fatalf("expected 2 entries missing content, got %d", len(missing))
+	}
+	if later, err := repo.ListMissingContent(ctx, feedID, time.Now().Add(time.Hour), 10); err != nil || len(later) != 0 {
+		t.Errorf("expected none found since the future, got %v, %v", later, err)
+	}
+
+	image, snippet, again := "https://example.com/image.png", "Written", "<p>Again</p>"
+	filled, err := repo.FillContent(ctx, model.Entry{ID: missing[0].ID, Content: &body, Snippet: &snippet, ContentText: &snippet, ThumbnailURL: &image})
+	if err != nil || !filled {
+		t.Fatalf("fill content: %v, %v", filled, err)
+	}
+	if filled, err := repo.FillContent(ctx, model.Entry{ID: missing[0].ID, Content: &again}); err != nil || filled {
+		t.Errorf("expected an entry with content left alone, got %v, %v", filled, err)
+	}
+	entry, err := repo.GetByID(ctx, missing[0].ID)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if entry.Content == nil || *entry.Content != body || entry.ThumbnailURL == nil || *entry.ThumbnailURL != image {
+		t.Errorf("expected the fetched content and thumbnail, got %v, %v", entry.Content, entry.ThumbnailURL)
+	}
+	if entry.ContentText == nil || *entry.ContentText != snippet {
+		t.Errorf("expected the text version stored, got %v", entry.ContentText)
+	}
+}
+
+func TestEntryRepository_ListWithoutReadable(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	otherID := testutil.SeedFeed(t, db, model.Feed{Title: "Other", URL: "https://example.org/feed"})
+	ids := make([]int64, 4)
+	for i := range ids {
+		url := "https://example.com/" + strconv.Itoa(i)
+		published := time.Date(2025, 3, i+1, 0, 0, 0, 0, time.UTC)
+		ids[i] = testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &url, PublishedAt: &published})
+	}
+	testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+	otherURL := "https://example.org/1"
+	testutil.SeedEntry(t, db, model.Entry{FeedID: otherID, URL: &otherURL})
+	if err := repo.UpdateReadableContent(ctx, ids[0], "<p>Readable</p>", model.ReadableMeta{}); err != nil {
+		t.Fatalf("update readable content: %v", err)
+	}
+	if _, err := repo.UpdateReadStatus(ctx, []int64{ids[1]}, true); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+
+	all, err := repo.ListWithoutReadable(ctx, feedID, false)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if want := []int64{ids[3], ids[2], ids[1]}; !reflect.DeepEqual(all, want) {
+		t.Errorf("expected %v, got %v", want, all)
+	}
+	unread, err := repo.ListWithoutReadable(ctx, feedID, true)
+	if err != nil {
+		t.Fatalf("list unread: %v", err)
+	}
+	if want := []int64{ids[3], ids[2]}; !reflect.DeepEqual(unread, want) {
+		t.Errorf("expected %v, got %v", want, unread)
+	}
+}
+
+func TestEntryRepository_AttachmentsAndExternalURL(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Podcast", URL: "https://example.com/feed.json", Type: "article"})
+	url, external := "https://example.com/episodes/1", "https://news.example.org/story"
+	save := func(entry model.Entry) model.Entry {
+		t.Helper()
+		entry.FeedID, entry.URL = feedID, &url
+		if err := repo.CreateOrUpdate(ctx, entry); err != nil {
+			t.Fatalf("save entry: %v", err)
+		}
+		stored, err := repo.GetByURL(ctx, feedID, url)
+		if err != nil {
+			t.Fatalf("get by url: %v", err)
+		}
+		got, err := repo.GetByID(ctx, stored.ID)
+		if err != nil {
+			t.Fatalf("get entry: %v", err)
+		}
+		return got
+	}
+
+	attachments := []model.EntryAttachment{
+		{URL: "https://cdn.example.com/1.mp3", MimeType: "audio/mpeg", Title: "Episode 1", SizeBytes: 1234, DurationSeconds: 61},
+		{URL: "https://cdn.example.com/1.m4a", MimeType: "audio/mp4"},
+	}
+	entry := save(model.Entry{ExternalURL: &external, Attachments: attachments})
+	if !reflect.DeepEqual(entry.Attachments, attachments) {
+		t.Errorf("expected attachments %+v, got %+v", attachments, entry.Attachments)
+	}
+	if entry.ExternalURL == nil || *entry.ExternalURL != external {
+		t.Errorf("expected external url %q, got %v", external, entry.ExternalURL)
+	}
+
+	// Saving without attachments keeps them; an empty list removes them
+	if entry := save(model.Entry{}); !reflect.DeepEqual(entry.Attachments, attachments) || entry.ExternalURL != nil {
+		t.Errorf("expected attachments kept and external url cleared, got %+v, %v", entry.Attachments, entry.ExternalURL)
+	}
+	if entry := save(model.Entry{Attachments: []model.EntryAttachment{}}); len(entry.Attachments) != 0 {
+		t.Errorf("expected no attachments, got %+v", entry.Attachments)
+	}
+}
+
+func TestEntryRepository_DeleteByFeed(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	otherID := testutil.SeedFeed(t, db, model.Feed{Title: "Other", URL: "https://example.org/feed"})
+	for i, starred := range []bool{true, false, false} {
+		url := fmt.Sprintf("https://example.com/%d", i)
+		testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &url, Starred: starred})
+	}
+	otherURL := "https://example.org/1"
+	testutil.SeedEntry(t, db, model.Entry{FeedID: otherID, URL: &otherURL})
+
+	deleted, err := repo.DeleteByFeed(ctx, feedID, true)
+	if err != nil || deleted != 2 {
+		t.Fatalf("expected the 2 unstarred entries deleted, got %d, %v", deleted, err)
+	}
+	if kept, err := repo.CountByFeed(ctx, feedID); err != nil || kept != 1 {
+		t.Errorf("expected the starred entry kept, got %d, %v", kept, err)
+	}
+
+	if deleted, err = repo.DeleteByFeed(ctx, feedID, false); err != nil || deleted != 1 {
+		t.Errorf("expected the starred entry deleted, got %d, %v", deleted, err)
+	}
+	if kept, err := repo.CountByFeed(ctx, otherID); err != nil || kept != 1 {
+		t.Errorf("expected other feeds untouched, got %d, %v", kept, err)
+	}
+}
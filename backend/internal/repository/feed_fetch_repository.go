@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/snowflake"
+)
+
+type FeedFetchRepository interface {
+	// Add records a refresh attempt and drops the feed's attempts beyond
+	// the newest keep.
+	Add(ctx context.Context, fetch model.FeedFetch, keep int) error
+	// ListByFeed returns up to limit attempts of a feed, newest first.
+	ListByFeed(ctx context.Context, feedID int64, limit int) ([]model.FeedFetch, error)
+	// ListAll returns every recorded attempt, grouped by feed, newest first
+	// within each feed.
+	ListAll(ctx context.Context) ([]model.FeedFetch, error)
+}
+
+type feedFetchRepository struct {
+	db dbtx
+}
+
+func NewFeedFetchRepository(db dbtx) FeedFetchRepository {
+	return &feedFetchRepository{db: db}
+}
+
+const feedFetchColumns = `id, feed_id, fetched_at, status, http_status, duration_ms, new_entries, error`
+
+func (r *feedFetchRepository) Add(ctx context.Context, fetch model.FeedFetch, keep int) error {
+	var httpStatus interface{}
+	if fetch.HTTPStatus != nil {
+		httpStatus = *fetch.HTTPStatus
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO feed_fetch_log (id, feed_id, fetched_at, status, http_status, duration_ms, new_entries, error)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		snowflake.NextID(),
+		fetch.FeedID,
+		formatTime(fetch.FetchedAt),
+		fetch.Status,
+		httpStatus,
+		fetch.Duration.Milliseconds(),
+		fetch.NewEntries,
+		nullableString(fetch.Error),
+	)
+	if err != nil {
+		return fmt.Errorf("add feed fetch: %w", err)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		`DELETE FROM feed_fetch_log WHERE feed_id = ? AND id NOT IN (
+			SELECT id FROM feed_fetch_log WHERE feed_id = ? ORDER BY fetched_at DESC, id DESC LIMIT ?
+		)`,
+		fetch.FeedID, fetch.FeedID, keep,
+	)
+	if err != nil {
+		return fmt.Errorf("trim feed fetches: %w", err)
+	}
+	return nil
+}
+
+func (r *feedFetchRepository) ListByFeed(ctx context.Context, feedID int64, limit int) ([]model.FeedFetch, error) {
+	return r.query(
+		ctx,
+		`SELECT `+feedFetchColumns+` FROM feed_fetch_log
+		 WHERE feed_id = ?
+		 ORDER BY fetched_at DESC, id DESC
+		 LIMIT ?`,
+		feedID, limit,
+	)
+}
+
+func (r *feedFetchRepository) ListAll(ctx context.Context) ([]model.FeedFetch, error) {
+	return r.query(ctx, `SELECT `+feedFetchColumns+` FROM feed_fetch_log ORDER BY feed_id, fetched_at DESC, id DESC`)
+}
+
+func (r *feedFetchRepository) query(ctx context.Context, query string, args ...interface{}) ([]model.FeedFetch, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list feed fetches: %w", err)
+	}
+	defer rows.Close()
+
+	var fetches []model.FeedFetch
+	for rows.Next() {
+		var fetch model.FeedFetch
+		var fetchedAt string
+		var httpStatus sql.NullInt64
+		var durationMs int64
+		if err := rows.Scan(
+			&fetch.ID, &fetch.FeedID, &fetchedAt, &fetch.Status, &httpStatus, &durationMs,
+			&fetch.NewEntries, &fetch.Error,
+		); err != nil {
+			return nil, fmt.Errorf("scan feed fetch: %w", err)
+		}
+		fetch.FetchedAt, _ = parseTime(fetchedAt)
+		if httpStatus.Valid {
+			status := int(httpStatus.Int64)
+			fetch.HTTPStatus = &status
+		}
+		fetch.Duration = time.Duration(durationMs) * time.Millisecond
+		fetches = append(fetches, fetch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate feed fetches: %w", err)
+	}
+	return fetches, nil
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestFeedFetchRepository(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedFetchRepository(db)
+	ctx := context.Background()
+
+	blogID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	photosID := testutil.SeedFeed(t, db, model.Feed{Title: "Photos", URL: "https://example.org/feed"})
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	notFound := 404
+	message := "HTTP 404"
+	for i := 0; i < 4; i++ {
+		fetch := model.FeedFetch{FeedID: blogID, FetchedAt: start.Add(time.Duration(i) * time.Hour), Status: "ok", Duration: 250 * time.Millisecond, NewEntries: i}
+		if i == 3 {
+			fetch.Status, fetch.HTTPStatus, fetch.Error = "http_error", &notFound, &message
+		}
+		if err := repo.Add(ctx, fetch, 3); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+	if err := repo.Add(ctx, model.FeedFetch{FeedID: photosID, FetchedAt: start, Status: "not_modified"}, 3); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	fetches, err := repo.ListByFeed(ctx, blogID, 10)
+	if err != nil {
+		t.Fatalf("list by feed: %v", err)
+	}
+	if len(fetches) != 3 {
+		t.Fatalf("expected the newest 3 attempts kept, got %+v", fetches)
+	}
+	latest := fetches[0]
+	if latest.Status != "http_error" || latest.HTTPStatus == nil || *latest.HTTPStatus != 404 || latest.Error == nil || *latest.Error != message ||
+		!latest.FetchedAt.Equal(start.Add(3*time.Hour)) || latest.Duration != 250*time.Millisecond {
+		t.Errorf("unexpected latest attempt %+v", latest)
+	}
+	if fetches[2].NewEntries != 1 || fetches[2].HTTPStatus != nil {
+		t.Errorf("unexpected oldest kept attempt %+v", fetches[2])
+	}
+
+	all, err := repo.ListAll(ctx)
+	if err != nil {
+		t.Fatalf("list all: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("expected 4 attempts across feeds, got %d", len(all))
+	}
+}
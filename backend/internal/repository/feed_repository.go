@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -19,9 +20,47 @@ type FeedRepository interface {
 	List(ctx context.Context, folderID *int64) ([]model.Feed, error)
 	ListWithoutIcon(ctx context.Context) ([]model.Feed, error)
 	Update(ctx context.Context, feed model.Feed) (model.Feed, error)
-	UpdateIconPath(ctx context.Context, id int64, iconPath string) error
+	// UpdateIcon sets the feed's icon file and its dominant color, if known.
+	UpdateIcon(ctx context.Context, id int64, iconPath string, iconColor *string) error
 	UpdateErrorMessage(ctx context.Context, id int64, errorMessage *string) error
 	UpdateType(ctx context.Context, id int64, feedType string) error
+	// SetArchived hides a feed from listings and refresh while keeping its entries.
+	// Archiving also detaches the feed from its folder.
+	SetArchived(ctx context.Context, id int64, archived bool) error
+	// UpdateRefreshInterval sets the minutes between refreshes; nil leaves it
+	// to the scheduler.
+	UpdateRefreshInterval(ctx context.Context, id int64, minutes *int) error
+	// UpdateFullTextURL sets the feed's external full-text service; nil
+	// returns it to the built-in readability.
+	UpdateFullTextURL(ctx context.Context, id int64, fullTextURL *string) error
+	// UpdateSummaryInSourceLanguage sets whether the feed's entries are
+	// summarized in their own language.
+	UpdateSummaryInSourceLanguage(ctx context.Context, id int64, enabled bool) error
+	// UpdatePrefetchReadability sets whether refresh fetches the readable
+	// content of the feed's new entries.
+	UpdatePrefetchReadability(ctx context.Context, id int64, enabled bool) error
+	// UpdateDateQuirks sets how the dates of the feed's entries are read;
+	// nil removes the corrections.
+	UpdateDateQuirks(ctx context.Context, id int64, quirks *model.FeedDateQuirks) error
+	// UpdateScraper sets the selectors the feed's page is scraped with; nil
+	// makes it an ordinary feed again.
+	UpdateScraper(ctx context.Context, id int64, scraper *model.FeedScraper) error
+	// UpdateRequestOverrides sets the User-Agent and headers the feed is
+	// requested with; nil sends the defaults.
+	UpdateRequestOverrides(ctx context.Context, id int64, overrides *model.FeedRequestOverrides) error
+	// GetAuth returns the feed's sealed credentials, nil when it has none.
+	GetAuth(ctx context.Context, id int64) (*string, error)
+	// UpdateAuth stores the feed's sealed credentials; nil removes them.
+	UpdateAuth(ctx context.Context, id int64, sealed *string) error
+	// ScheduleRefresh sets when the scheduler next refreshes the feed.
+	ScheduleRefresh(ctx context.Context, id int64, at time.Time) error
+	// IncrementFailures counts another failed refresh of the feed in a row,
+	// returning the count.
+	IncrementFailures(ctx context.Context, id int64) (int, error)
+	// ClearFailures resets the feed's failed refreshes and enables it again.
+	ClearFailures(ctx context.Context, id int64) error
+	// Disable takes the feed out of scheduled refreshes.
+	Disable(ctx context.Context, id int64, at time.Time) error
 	Delete(ctx context.Context, id int64) error
 	DeleteBatch(ctx context.Context, ids []int64) (int64, error)
 }
@@ -66,12 +105,12 @@ func (r *feedRepository) Create(ctx context.Context, feed model.Feed) (model.Fee
 }
 
 func (r *feedRepository) GetByID(ctx context.Context, id int64) (model.Feed, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, folder_id, title, url, site_url, description, icon_path, type, etag, last_modified, error_message, created_at, updated_at FROM feeds WHERE id = ?`, id)
+	row := r.db.QueryRowContext(ctx, `SELECT id, folder_id, title, url, site_url, description, icon_path, icon_color, type, etag, last_modified, error_message, archived_at, refresh_interval, next_refresh_at, full_text_url, summary_source_language, prefetch_readability, date_quirks, scraper, request_overrides, auth IS NOT NULL, consecutive_failures, disabled_at, created_at, updated_at FROM feeds WHERE id = ?`, id)
 	return scanFeed(row)
 }
 
 func (r *feedRepository) FindByURL(ctx context.Context, url string) (*model.Feed, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, folder_id, title, url, site_url, description, icon_path, type, etag, last_modified, error_message, created_at, updated_at FROM feeds WHERE url = ?`, url)
+	row := r.db.QueryRowContext(ctx, `SELECT id, folder_id, title, url, site_url, description, icon_path, icon_color, type, etag, last_modified, error_message, archived_at, refresh_interval, next_refresh_at, full_text_url, summary_source_language, prefetch_readability, date_quirks, scraper, request_overrides, auth IS NOT NULL, consecutive_failures, disabled_at, created_at, updated_at FROM feeds WHERE url = ?`, url)
 	feed, err := scanFeed(row)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -83,10 +122,10 @@ func (r *feedRepository) FindByURL(ctx context.Context, url string) (*model.Feed
 }
 
 func (r *feedRepository) List(ctx context.Context, folderID *int64) ([]model.Feed, error) {
-	query := `SELECT id, folder_id, title, url, site_url, description, icon_path, type, etag, last_modified, error_message, created_at, updated_at FROM feeds ORDER BY title`
+	query := `SELECT id, folder_id, title, url, site_url, description, icon_path, icon_color, type, etag, last_modified, error_message, archived_at, refresh_interval, next_refresh_at, full_text_url, summary_source_language, prefetch_readability, date_quirks, scraper, request_overrides, auth IS NOT NULL, consecutive_failures, disabled_at, created_at, updated_at FROM feeds WHERE archived_at IS NULL ORDER BY title`
 	args := []interface{}{}
 	if folderID != nil {
-		query = `SELECT id, folder_id, title, url, site_url, description, icon_path, type, etag, last_modified, error_message, created_at, updated_at FROM feeds WHERE folder_id = ? ORDER BY title`
+		query = `SELECT id, folder_id, title, url, site_url, description, icon_path, icon_color, type, etag, last_modified, error_message, archived_at, refresh_interval, next_refresh_at, full_text_url, summary_source_language, prefetch_readability, date_quirks, scraper, request_overrides, auth IS NOT NULL, consecutive_failures, disabled_at, created_at, updated_at FROM feeds WHERE folder_id = ? AND archived_at IS NULL ORDER BY title`
 		args = append(args, *folderID)
 	}
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -111,7 +150,7 @@ func (r *feedRepository) List(ctx context.Context, folderID *int64) ([]model.Fee
 }
 
 func (r *feedRepository) ListWithoutIcon(ctx context.Context) ([]model.Feed, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id, folder_id, title, url, site_url, description, icon_path, type, etag, last_modified, error_message, created_at, updated_at FROM feeds WHERE icon_path IS NULL OR icon_path = ''`)
+	rows, err := r.db.QueryContext(ctx, `SELECT id, folder_id, title, url, site_url, description, icon_path, icon_color, type, etag, last_modified, error_message, archived_at, refresh_interval, next_refresh_at, full_text_url, summary_source_language, prefetch_readability, date_quirks, scraper, request_overrides, auth IS NOT NULL, consecutive_failures, disabled_at, created_at, updated_at FROM feeds WHERE (icon_path IS NULL OR icon_path = '') AND archived_at IS NULL`)
 	if err != nil {
 		return nil, fmt.Errorf("list feeds without icon: %w", err)
 	}
@@ -155,11 +194,12 @@ func (r *feedRepository) Update(ctx context.Context, feed model.Feed) (model.Fee
 	return feed, nil
 }
 
-func (r *feedRepository) UpdateIconPath(ctx context.Context, id int64, iconPath string) error {
+func (r *feedRepository) UpdateIcon(ctx context.Context, id int64, iconPath string, iconColor *string) error {
 	_, err := r.db.ExecContext(
 		ctx,
-		`UPDATE feeds SET icon_path = ?, updated_at = ? WHERE id = ?`,
+		`UPDATE feeds SET icon_path = ?, icon_color = ?, updated_at = ? WHERE id = ?`,
 		iconPath,
+		iconColor,
 		formatTime(time.Now()),
 		id,
 	)
@@ -188,6 +228,185 @@ func (r *feedRepository) UpdateType(ctx context.Context, id int64, feedType stri
 	return err
 }
 
+func (r *feedRepository) SetArchived(ctx context.Context, id int64, archived bool) error {
+	now := formatTime(time.Now())
+	query := `UPDATE feeds SET archived_at = NULL, updated_at = ? WHERE id = ?`
+	args := []interface{}{now, id}
+	if archived {
+		query = `UPDATE feeds SET archived_at = ?, folder_id = NULL, updated_at = ? WHERE id = ?`
+		args = []interface{}{now, now, id}
+	}
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("set feed archived: %w", err)
+	}
+	return nil
+}
+
+func (r *feedRepository) UpdateRefreshInterval(ctx context.Context, id int64, minutes *int) error {
+	var interval interface{}
+	if minutes != nil {
+		interval = *minutes
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE feeds SET refresh_interval = ?, updated_at = ? WHERE id = ?`,
+		interval,
+		formatTime(time.Now()),
+		id,
+	)
+	return err
+}
+
+func (r *feedRepository) UpdateFullTextURL(ctx context.Context, id int64, fullTextURL *string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE feeds SET full_text_url = ?, updated_at = ? WHERE id = ?`,
+		nullableString(fullTextURL),
+		formatTime(time.Now()),
+		id,
+	)
+	return err
+}
+
+func (r *feedRepository) UpdateSummaryInSourceLanguage(ctx context.Context, id int64, enabled bool) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE feeds SET summary_source_language = ?, updated_at = ? WHERE id = ?`,
+		enabled,
+		formatTime(time.Now()),
+		id,
+	)
+	return err
+}
+
+func (r *feedRepository) UpdatePrefetchReadability(ctx context.Context, id int64, enabled bool) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE feeds SET prefetch_readability = ?, updated_at = ? WHERE id = ?`,
+		enabled,
+		formatTime(time.Now()),
+		id,
+	)
+	return err
+}
+
+func (r *feedRepository) UpdateDateQuirks(ctx context.Context, id int64, quirks *model.FeedDateQuirks) error {
+	var value interface{}
+	if quirks != nil {
+		encoded, err := json.Marshal(quirks)
+		if err != nil {
+			return fmt.Errorf("encode date quirks: %w", err)
+		}
+		value = string(encoded)
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE feeds SET date_quirks = ?, updated_at = ? WHERE id = ?`,
+		value,
+		formatTime(time.Now()),
+		id,
+	)
+	return err
+}
+
+func (r *feedRepository) UpdateScraper(ctx context.Context, id int64, scraper *model.FeedScraper) error {
+	var value interface{}
+	if scraper != nil {
+		encoded, err := json.Marshal(scraper)
+		if err != nil {
+			return fmt.Errorf("encode scraper: %w", err)
+		}
+		value = string(encoded)
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE feeds SET scraper = ?, updated_at = ? WHERE id = ?`,
+		value,
+		formatTime(time.Now()),
+		id,
+	)
+	return err
+}
+
+func (r *feedRepository) UpdateRequestOverrides(ctx context.Context, id int64, overrides *model.FeedRequestOverrides) error {
+	var value interface{}
+	if overrides != nil {
+		encoded, err := json.Marshal(overrides)
+		if err != nil {
+			return fmt.Errorf("encode request overrides: %w", err)
+		}
+		value = string(encoded)
+	}
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE feeds SET request_overrides = ?, updated_at = ? WHERE id = ?`,
+		value,
+		formatTime(time.Now()),
+		id,
+	)
+	return err
+}
+
+func (r *feedRepository) GetAuth(ctx context.Context, id int64) (*string, error) {
+	var sealed sql.NullString
+	if err := r.db.QueryRowContext(ctx, `SELECT auth FROM feeds WHERE id = ?`, id).Scan(&sealed); err != nil {
+		return nil, err
+	}
+	if !sealed.Valid {
+		return nil, nil
+	}
+	return &sealed.String, nil
+}
+
+func (r *feedRepository) UpdateAuth(ctx context.Context, id int64, sealed *string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE feeds SET auth = ?, updated_at = ? WHERE id = ?`,
+		nullableString(sealed),
+		formatTime(time.Now()),
+		id,
+	)
+	return err
+}
+
+// ScheduleRefresh leaves updated_at alone: the schedule moves on every
+// refresh and is not a change to the feed.
+func (r *feedRepository) ScheduleRefresh(ctx context.Context, id int64, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE feeds SET next_refresh_at = ? WHERE id = ?`, formatTime(at), id)
+	return err
+}
+
+func (r *feedRepository) IncrementFailures(ctx context.Context, id int64) (int, error) {
+	var failures int
+	err := r.db.QueryRowContext(
+		ctx,
+		`UPDATE feeds SET consecutive_failures = consecutive_failures + 1 WHERE id = ? RETURNING consecutive_failures`,
+		id,
+	).Scan(&failures)
+	if err != nil {
+		return 0, fmt.Errorf("increment feed failures: %w", err)
+	}
+	return failures, nil
+}
+
+func (r *feedRepository) ClearFailures(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE feeds SET consecutive_failures = 0, disabled_at = NULL WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("clear feed failures: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("clear feed failures: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *feedRepository) Disable(ctx context.Context, id int64, at time.Time) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE feeds SET disabled_at = ? WHERE id = ?`, formatTime(at), id); err != nil {
+		return fmt.Errorf("disable feed: %w", err)
+	}
+	return nil
+}
+
 func (r *feedRepository) Delete(ctx context.Context, id int64) error {
 	if _, err := r.db.ExecContext(ctx, `DELETE FROM feeds WHERE id = ?`, id); err != nil {
 		return fmt.Errorf("delete feed: %w", err)
@@ -220,10 +439,19 @@ func scanFeed(scanner interface {
 	var siteURL sql.NullString
 	var description sql.NullString
 	var iconPath sql.NullString
+	var iconColor sql.NullString
 	var feedType sql.NullString
 	var etag sql.NullString
 	var lastModified sql.NullString
 	var errorMessage sql.NullString
+	var archivedAt sql.NullString
+	var refreshInterval sql.NullInt64
+	var nextRefreshAt sql.NullString
+	var fullTextURL sql.NullString
+	var dateQuirks sql.NullString
+	var scraper sql.NullString
+	var requestOverrides sql.NullString
+	var disabledAt sql.NullString
 	var createdAt string
 	var updatedAt string
 	if err := scanner.Scan(
@@ -234,10 +462,23 @@ func scanFeed(scanner interface {
 		&siteURL,
 		&description,
 		&iconPath,
+		&iconColor,
 		&feedType,
 		&etag,
 		&lastModified,
 		&errorMessage,
+		&archivedAt,
+		&refreshInterval,
+		&nextRefreshAt,
+		&fullTextURL,
+		&feed.SummaryInSourceLanguage,
+		&feed.PrefetchReadability,
+		&dateQuirks,
+		&scraper,
+		&requestOverrides,
+		&feed.HasAuth,
+		&feed.ConsecutiveFailures,
+		&disabledAt,
 		&createdAt,
 		&updatedAt,
 	); err != nil {
@@ -255,6 +496,9 @@ func scanFeed(scanner interface {
 	if iconPath.Valid {
 		feed.IconPath = &iconPath.String
 	}
+	if iconColor.Valid {
+		feed.IconColor = &iconColor.String
+	}
 	if feedType.Valid {
 		feed.Type = feedType.String
 	} else {
@@ -270,6 +514,55 @@ func scanFeed(scanner interface {
 		feed.ErrorMessage = &errorMessage.String
 	}
 	var err error
+	if archivedAt.Valid {
+		archived, err := parseTime(archivedAt.String)
+		if err != nil {
+			return model.Feed{}, fmt.Errorf("parse feed archived_at: %w", err)
+		}
+		feed.ArchivedAt = &archived
+	}
+	if refreshInterval.Valid {
+		minutes := int(refreshInterval.Int64)
+		feed.RefreshInterval = &minutes
+	}
+	if nextRefreshAt.Valid {
+		next, err := parseTime(nextRefreshAt.String)
+		if err != nil {
+			return model.Feed{}, fmt.Errorf("parse feed next_refresh_at: %w", err)
+		}
+		feed.NextRefreshAt = &next
+	}
+	if fullTextURL.Valid {
+		feed.FullTextURL = &fullTextURL.String
+	}
+	if dateQuirks.Valid {
+		var quirks model.FeedDateQuirks
+		if err := json.Unmarshal([]byte(dateQuirks.String), &quirks); err != nil {
+			return model.Feed{}, fmt.Errorf("parse feed date_quirks: %w", err)
+		}
+		feed.DateQuirks = &quirks
+	}
+	if scraper.Valid {
+		var selectors model.FeedScraper
+		if err := json.Unmarshal([]byte(scraper.String), &selectors); err != nil {
+			return model.Feed{}, fmt.Errorf("parse feed scraper: %w", err)
+		}
+		feed.Scraper = &selectors
+	}
+	if requestOverrides.Valid {
+		var overrides model.FeedRequestOverrides
+		if err := json.Unmarshal([]byte(requestOverrides.String), &overrides); err != nil {
+			return model.Feed{}, fmt.Errorf("parse feed request_overrides: %w", err)
+		}
+		feed.RequestOverrides = &overrides
+	}
+	if disabledAt.Valid {
+		disabled, err := parseTime(disabledAt.String)
+		if err != nil {
+			return model.Feed{}, fmt.Errorf("parse feed disabled_at: %w", err)
+		}
+		feed.DisabledAt = &disabled
+	}
 	feed.CreatedAt, err = parseTime(createdAt)
 	if err != nil {
 		return model.Feed{}, fmt.Errorf("parse feed created_at: %w", err)
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestFeedRepository_RefreshSchedule(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	feed, err := repo.GetByID(ctx, feedID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if feed.RefreshInterval != nil || feed.NextRefreshAt != nil {
+		t.Fatalf("new feed should have no schedule, got %v %v", feed.RefreshInterval, feed.NextRefreshAt)
+	}
+
+	minutes := 60
+	next := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := repo.UpdateRefreshInterval(ctx, feedID, &minutes); err != nil {
+		t.Fatalf("update refresh interval: %v", err)
+	}
+	if err := repo.ScheduleRefresh(ctx, feedID, next); err != nil {
+		t.Fatalf("schedule refresh: %v", err)
+	}
+	feeds, err := repo.List(ctx, nil)
+	if err != nil || len(feeds) != 1 {
+		t.Fatalf("list feeds: %v %v", feeds, err)
+	}
+	if got := feeds[0]; got.RefreshInterval == nil || *got.RefreshInterval != 60 || got.NextRefreshAt == nil || !got.NextRefreshAt.Equal(next) {
+		t.Errorf("unexpected schedule %v %v", got.RefreshInterval, got.NextRefreshAt)
+	}
+
+	// Saving the feed keeps its schedule; clearing the interval hands it back
+	if _, err := repo.Update(ctx, feeds[0]); err != nil {
+		t.Fatalf("update feed: %v", err)
+	}
+	if err := repo.UpdateRefreshInterval(ctx, feedID, nil); err != nil {
+		t.Fatalf("clear refresh interval: %v", err)
+	}
+	if feed, _ = repo.GetByID(ctx, feedID); feed.RefreshInterval != nil || feed.NextRefreshAt == nil {
+		t.Errorf("expected cleared interval and kept next refresh, got %v %v", feed.RefreshInterval, feed.NextRefreshAt)
+	}
+}
+
+func TestFeedRepository_Failures(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Flaky", URL: "https://example.com/feed", Type: "article"})
+	for want := 1; want <= 3; want++ {
+		if got, err := repo.IncrementFailures(ctx, feedID); err != nil || got != want {
+			t.Fatalf("increment failures: got %d %v, want %d", got, err, want)
+		}
+	}
+	disabledAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := repo.Disable(ctx, feedID, disabledAt); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	feed, err := repo.GetByID(ctx, feedID)
+	if err != nil || feed.ConsecutiveFailures != 3 || feed.DisabledAt == nil || !feed.DisabledAt.Equal(disabledAt) {
+		t.Fatalf("unexpected failures %d %v %v", feed.ConsecutiveFailures, feed.DisabledAt, err)
+	}
+
+	if err := repo.ClearFailures(ctx, feedID); err != nil {
+		t.Fatalf("clear failures: %v", err)
+	}
+	if feed, _ = repo.GetByID(ctx, feedID); feed.ConsecutiveFailures != 0 || feed.DisabledAt != nil {
+		t.Errorf("expected cleared failures, got %d %v", feed.ConsecutiveFailures, feed.DisabledAt)
+	}
+	if err := repo.ClearFailures(ctx, feedID+1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing feed, got %v", err)
+	}
+}
+
+func TestFeedRepository_UpdateFullTextURL(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	service := "https://morss.it/:proxy/"
+	if err := repo.UpdateFullTextURL(ctx, feedID, &service); err != nil {
+		t.Fatalf("update full-text service: %v", err)
+	}
+	feed, err := repo.GetByID(ctx, feedID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if feed.FullTextURL == nil || *feed.FullTextURL != service {
+		t.Errorf("expected the full-text service to be stored, got %v", feed.FullTextURL)
+	}
+
+	if err := repo.UpdateFullTextURL(ctx, feedID, nil); err != nil {
+		t.Fatalf("clear full-text service: %v", err)
+	}
+	if feed, _ = repo.GetByID(ctx, feedID); feed.FullTextURL != nil {
+		t.Errorf("expected the full-text service to be cleared, got %v", *feed.FullTextURL)
+	}
+}
+
+func TestFeedRepository_UpdateSummaryInSourceLanguage(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	if feed, _ := repo.GetByID(ctx, feedID); feed.SummaryInSourceLanguage {
+		t.Fatal("expected summaries in the global language by default")
+	}
+	if err := repo.UpdateSummaryInSourceLanguage(ctx, feedID, true); err != nil {
+		t.Fatalf("update summary language: %v", err)
+	}
+	feeds, err := repo.List(ctx, nil)
+	if err != nil {
+		t.Fatalf("list feeds: %v", err)
+	}
+	if len(feeds) != 1 || !feeds[0].SummaryInSourceLanguage {
+		t.Errorf("expected summaries in the source language, got %+v", feeds)
+	}
+}
+
+func TestFeedRepository_UpdatePrefetchReadability(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	if feed, _ := repo.GetByID(ctx, feedID); feed.PrefetchReadability {
+		t.Fatal("expected no prefetch by default")
+	}
+	if err := repo.UpdatePrefetchReadability(ctx, feedID, true); err != nil {
+		t.Fatalf("update prefetch: %v", err)
+	}
+	if feed, err := repo.GetByID(ctx, feedID); err != nil || !feed.PrefetchReadability {
+		t.Errorf("expected prefetch enabled, got %+v, %v", feed, err)
+	}
+}
+
+func TestFeedRepository_UpdateDateQuirks(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	if feed, _ := repo.GetByID(ctx, feedID); feed.DateQuirks != nil {
+		t.Fatalf("expected no date quirks by default, got %+v", feed.DateQuirks)
+	}
+	quirks := model.FeedDateQuirks{Timezone: "Asia/Shanghai", DayFirst: true}
+	if err := repo.UpdateDateQuirks(ctx, feedID, &quirks); err != nil {
+		t.Fatalf("update date quirks: %v", err)
+	}
+	if feed, err := repo.GetByID(ctx, feedID); err != nil || feed.DateQuirks == nil || *feed.DateQuirks != quirks {
+		t.Errorf("expected %+v, got %+v, %v", quirks, feed.DateQuirks, err)
+	}
+	if err := repo.UpdateDateQuirks(ctx, feedID, nil); err != nil {
+		t.Fatalf("remove date quirks: %v", err)
+	}
+	if feed, _ := repo.GetByID(ctx, feedID); feed.DateQuirks != nil {
+		t.Errorf("expected date quirks removed, got %+v", feed.DateQuirks)
+	}
+}
+
+func TestFeedRepository_UpdateScraper(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "News", URL: "https://example.com/news", Type: "article"})
+	scraper := model.FeedScraper{Items: "article", Link: "h2 a", Date: "time"}
+	if err := repo.UpdateScraper(ctx, feedID, &scraper); err != nil {
+		t.Fatalf("update scraper: %v", err)
+	}
+	if feed, err := repo.GetByID(ctx, feedID); err != nil || feed.Scraper == nil || *feed.Scraper != scraper {
+		t.Errorf("expected %+v, got %+v, %v", scraper, feed.Scraper, err)
+	}
+	if err := repo.UpdateScraper(ctx, feedID, nil); err != nil {
+		t.Fatalf("remove scraper: %v", err)
+	}
+	if feed, _ := repo.GetByID(ctx, feedID); feed.Scraper != nil {
+		t.Errorf("expected scraper removed, got %+v", feed.Scraper)
+	}
+}
+
+func TestFeedRepository_UpdateRequestOverrides(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Picky", URL: "https://example.com/feed", Type: "article"})
+	overrides := model.FeedRequestOverrides{UserAgent: "Feedly/1.0", Headers: map[string]string{"Referer": "https://example.com/"}}
+	if err := repo.UpdateRequestOverrides(ctx, feedID, &overrides); err != nil {
+		t.Fatalf("update request overrides: %v", err)
+	}
+	feed, err := repo.GetByID(ctx, feedID)
+	if err != nil || feed.RequestOverrides == nil || feed.RequestOverrides.UserAgent != "Feedly/1.0" || feed.RequestOverrides.Headers["Referer"] != "https://example.com/" {
+		t.Errorf("expected %+v, got %+v, %v", overrides, feed.RequestOverrides, err)
+	}
+	if err := repo.UpdateRequestOverrides(ctx, feedID, nil); err != nil {
+		t.Fatalf("remove request overrides: %v", err)
+	}
+	if feed, _ := repo.GetByID(ctx, feedID); feed.RequestOverrides != nil {
+		t.Errorf("expected request overrides removed, got %+v", feed.RequestOverrides)
+	}
+}
+
+func TestFeedRepository_UpdateAuth(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Private", URL: "https://example.com/feed", Type: "article"})
+	sealed := "c2VhbGVk"
+	if err := repo.UpdateAuth(ctx, feedID, &sealed); err != nil {
+		t.Fatalf("update auth: %v", err)
+	}
+	got, err := repo.GetAuth(ctx, feedID)
+	if err != nil || got == nil || *got != sealed {
+		t.Fatalf("expected the sealed credentials back, got %v, %v", got, err)
+	}
+	if feed, _ := repo.GetByID(ctx, feedID); !feed.HasAuth || feed.Auth != nil {
+		t.Errorf("expected HasAuth without loaded credentials, got %v, %+v", feed.HasAuth, feed.Auth)
+	}
+
+	if err := repo.UpdateAuth(ctx, feedID, nil); err != nil {
+		t.Fatalf("clear auth: %v", err)
+	}
+	if got, err := repo.GetAuth(ctx, feedID); err != nil || got != nil {
+		t.Errorf("expected no credentials, got %v, %v", got, err)
+	}
+	if feed, _ := repo.GetByID(ctx, feedID); feed.HasAuth {
+		t.Error("expected HasAuth to be cleared")
+	}
+}
+
+func TestFeedRepository_UpdateIcon(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	color := "#2040d0"
+	if err := repo.UpdateIcon(ctx, feedID, "example.com.png", &color); err != nil {
+		t.Fatalf("update icon: %v", err)
+	}
+	feed, err := repo.GetByID(ctx, feedID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if feed.IconPath == nil || *feed.IconPath != "example.com.png" || feed.IconColor == nil || *feed.IconColor != color {
+		t.Errorf("unexpected icon %v %v", feed.IconPath, feed.IconColor)
+	}
+
+	// A new icon drops the color of the old one
+	if err := repo.UpdateIcon(ctx, feedID, "", nil); err != nil {
+		t.Fatalf("clear icon: %v", err)
+	}
+	if feed, _ = repo.GetByID(ctx, feedID); feed.IconColor != nil {
+		t.Errorf("expected no color, got %v", *feed.IconColor)
+	}
+}
+
+func TestFeedRepository_SetArchived(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFeedRepository(db)
+	ctx := context.Background()
+
+	folderID := testutil.SeedFolder(t, db, "News", nil, "article")
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", FolderID: &folderID})
+	if err := repo.SetArchived(ctx, feedID, true); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+
+	for _, folder := range []*int64{nil, &folderID} {
+		if feeds, err := repo.List(ctx, folder); err != nil || len(feeds) != 0 {
+			t.Errorf("expected the archived feed to be hidden, got %v, %v", feeds, err)
+		}
+	}
+	feed, err := repo.GetByID(ctx, feedID)
+	if err != nil {
+		t.Fatalf("get feed: %v", err)
+	}
+	if feed.ArchivedAt == nil || feed.FolderID != nil {
+		t.Errorf("expected an archived feed outside its folder, got %v, %v", feed.ArchivedAt, feed.FolderID)
+	}
+
+	if err := repo.SetArchived(ctx, feedID, false); err != nil {
+		t.Fatalf("unarchive: %v", err)
+	}
+	if feeds, err := repo.List(ctx, nil); err != nil || len(feeds) != 1 || feeds[0].ArchivedAt != nil {
+		t.Errorf("expected the feed to be listed again, got %v, %v", feeds, err)
+	}
+}
@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/snowflake"
+)
+
+type FilterRepository interface {
+	List(ctx context.Context) ([]model.Filter, error)
+	// ListForFeed returns the enabled filters of a feed together with the
+	// global ones, oldest first.
+	ListForFeed(ctx context.Context, feedID int64) ([]model.Filter, error)
+	GetByID(ctx context.Context, id int64) (model.Filter, error)
+	Create(ctx context.Context, filter model.Filter) (model.Filter, error)
+	// Update replaces the rule of a filter, returning sql.ErrNoRows if it does not exist.
+	Update(ctx context.Context, filter model.Filter) (model.Filter, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type filterRepository struct {
+	db dbtx
+}
+
+func NewFilterRepository(db dbtx) FilterRepository {
+	return &filterRepository{db: db}
+}
+
+const filterColumns = `id, feed_id, field, match_type, pattern, action, folder_id, enabled, created_at, updated_at`
+
+func (r *filterRepository) List(ctx context.Context) ([]model.Filter, error) {
+	return r.query(ctx, `SELECT `+filterColumns+` FROM filters ORDER BY created_at, id`)
+}
+
+func (r *filterRepository) ListForFeed(ctx context.Context, feedID int64) ([]model.Filter, error) {
+	return r.query(
+		ctx,
+		`SELECT `+filterColumns+` FROM filters
+		 WHERE enabled = 1 AND (feed_id IS NULL OR feed_id = ?)
+		 ORDER BY created_at, id`,
+		feedID,
+	)
+}
+
+func (r *filterRepository) GetByID(ctx context.Context, id int64) (model.Filter, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+filterColumns+` FROM filters WHERE id = ?`, id)
+	filter, err := scanFilter(row)
+	if err != nil {
+		return model.Filter{}, fmt.Errorf("get filter: %w", err)
+	}
+	return filter, nil
+}
+
+func (r *filterRepository) Create(ctx context.Context, filter model.Filter) (model.Filter, error) {
+	filter.ID = snowflake.NextID()
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO filters (id, feed_id, field, match_type, pattern, action, folder_id, enabled, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		filter.ID,
+		nullableInt64(filter.FeedID),
+		filter.Field,
+		filter.Match,
+		filter.Pattern,
+		filter.Action,
+		nullableInt64(filter.FolderID),
+		filter.Enabled,
+		formatTime(now),
+		formatTime(now),
+	)
+	if err != nil {
+		return model.Filter{}, fmt.Errorf("create filter: %w", err)
+	}
+	filter.CreatedAt = now
+	filter.UpdatedAt = now
+	return filter, nil
+}
+
+func (r *filterRepository) Update(ctx context.Context, filter model.Filter) (model.Filter, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE filters SET feed_id = ?, field = ?, match_type = ?, pattern = ?, action = ?, folder_id = ?, enabled = ?, updated_at = ?
+		 WHERE id = ?`,
+		nullableInt64(filter.FeedID),
+		filter.Field,
+		filter.Match,
+		filter.Pattern,
+		filter.Action,
+		nullableInt64(filter.FolderID),
+		filter.Enabled,
+		formatTime(time.Now().UTC()),
+		filter.ID,
+	)
+	if err != nil {
+		return model.Filter{}, fmt.Errorf("update filter: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return model.Filter{}, fmt.Errorf("update filter: %w", sql.ErrNoRows)
+	}
+	return r.GetByID(ctx, filter.ID)
+}
+
+func (r *filterRepository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM filters WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete filter: %w", err)
+	}
+	return nil
+}
+
+func (r *filterRepository) query(ctx context.Context, query string, args ...interface{}) ([]model.Filter, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list filters: %w", err)
+	}
+	defer rows.Close()
+
+	var filters []model.Filter
+	for rows.Next() {
+		filter, err := scanFilter(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan filter: %w", err)
+		}
+		filters = append(filters, filter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate filters: %w", err)
+	}
+	return filters, nil
+}
+
+func scanFilter(scanner interface {
+	Scan(dest ...interface{}) error
+}) (model.Filter, error) {
+	var filter model.Filter
+	var feedID, folderID sql.NullInt64
+	var createdAt, updatedAt string
+	if err := scanner.Scan(
+		&filter.ID, &feedID, &filter.Field, &filter.Match, &filter.Pattern, &filter.Action,
+		&folderID, &filter.Enabled, &createdAt, &updatedAt,
+	); err != nil {
+		return model.Filter{}, err
+	}
+	if feedID.Valid {
+		filter.FeedID = &feedID.Int64
+	}
+	if folderID.Valid {
+		filter.FolderID = &folderID.Int64
+	}
+	filter.CreatedAt, _ = parseTime(createdAt)
+	filter.UpdatedAt, _ = parseTime(updatedAt)
+	return filter, nil
+}
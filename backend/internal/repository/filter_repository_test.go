@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestFilterRepository_CRUD(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFilterRepository(db)
+	ctx := context.Background()
+
+	folder := testutil.SeedFolder(t, db, "Muted", nil, "article")
+	created, err := repo.Create(ctx, model.Filter{
+		Field: model.FilterFieldTitle, Match: model.FilterContains, Pattern: "sponsored",
+		Action: model.FilterActionMove, FolderID: &folder, Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("create filter: %v", err)
+	}
+	if created.ID == 0 || created.CreatedAt.IsZero() {
+		t.Errorf("expected an ID and creation time, got %+v", created)
+	}
+
+	got, err := repo.GetByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("get filter: %v", err)
+	}
+	if got.Pattern != "sponsored" || got.FeedID != nil || got.FolderID == nil || *got.FolderID != folder || !got.Enabled {
+		t.Errorf("unexpected filter %+v", got)
+	}
+
+	got.Action = model.FilterActionSkip
+	got.FolderID = nil
+	got.Enabled = false
+	updated, err := repo.Update(ctx, got)
+	if err != nil {
+		t.Fatalf("update filter: %v", err)
+	}
+	if updated.Action != model.FilterActionSkip || updated.FolderID != nil || updated.Enabled {
+		t.Errorf("unexpected updated filter %+v", updated)
+	}
+	if _, err := repo.Update(ctx, model.Filter{ID: created.ID + 1}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing filter, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, created.ID); err != nil {
+		t.Fatalf("delete filter: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the filter to be gone, got %v", err)
+	}
+}
+
+func TestFilterRepository_ListForFeed(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewFilterRepository(db)
+	ctx := context.Background()
+
+	blog := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	news := testutil.SeedFeed(t, db, model.Feed{Title: "News", URL: "https://news.example.com/feed"})
+	create := func(feedID *int64, pattern string, enabled bool) {
+		t.Helper()
+		if _, err := repo.Create(ctx, model.Filter{
+			FeedID: feedID, Field: model.FilterFieldTitle, Match: model.FilterContains,
+			Pattern: pattern, Action: model.FilterActionRead, Enabled: enabled,
+		}); err != nil {
+			t.Fatalf("create filter: %v", err)
+		}
+	}
+	create(nil, "global", true)
+	create(&blog, "blog", true)
+	create(&blog, "disabled", false)
+	create(&news, "news", true)
+
+	filters, err := repo.ListForFeed(ctx, blog)
+	if err != nil {
+		t.Fatalf("list filters: %v", err)
+	}
+	var patterns []string
+	for _, filter := range filters {
+		patterns = append(patterns, filter.Pattern)
+	}
+	if len(patterns) != 2 || patterns[0] != "global" || patterns[1] != "blog" {
+		t.Errorf("expected the global and the blog's enabled filter, got %v", patterns)
+	}
+
+	// A feed's filters go with it
+	if _, err := db.ExecContext(ctx, `DELETE FROM feeds WHERE id = ?`, news); err != nil {
+		t.Fatalf("delete feed: %v", err)
+	}
+	all, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("list filters: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("expected 3 filters after deleting the feed, got %d", len(all))
+	}
+}
@@ -18,6 +18,7 @@ type FolderRepository interface {
 	List(ctx context.Context) ([]model.Folder, error)
 	Update(ctx context.Context, id int64, name string, parentID *int64) (model.Folder, error)
 	UpdateType(ctx context.Context, id int64, folderType string) error
+	UpdatePregenerateThumbnails(ctx context.Context, id int64, enabled bool) error
 	Delete(ctx context.Context, id int64) error
 }
 
@@ -60,14 +61,14 @@ func (r *folderRepository) Create(ctx context.Context, name string, parentID *in
 }
 
 func (r *folderRepository) GetByID(ctx context.Context, id int64) (model.Folder, error) {
-	row := r.db.QueryRowContext(ctx, `SELECT id, name, parent_id, type, created_at, updated_at FROM folders WHERE id = ?`, id)
+	row := r.db.QueryRowContext(ctx, `SELECT id, name, parent_id, type, pregenerate_thumbnails, created_at, updated_at FROM folders WHERE id = ?`, id)
 
 	var folder model.Folder
 	var parentID sql.NullInt64
 	var folderType sql.NullString
 	var createdAt string
 	var updatedAt string
-	if err := row.Scan(&folder.ID, &folder.Name, &parentID, &folderType, &createdAt, &updatedAt); err != nil {
+	if err := row.Scan(&folder.ID, &folder.Name, &parentID, &folderType, &folder.PregenerateThumbnails, &createdAt, &updatedAt); err != nil {
 		return model.Folder{}, fmt.Errorf("get folder: %w", err)
 	}
 	if parentID.Valid {
@@ -92,10 +93,10 @@ func (r *folderRepository) GetByID(ctx context.Context, id int64) (model.Folder,
 }
 
 func (r *folderRepository) FindByName(ctx context.Context, name string, parentID *int64) (*model.Folder, error) {
-	query := `SELECT id, name, parent_id, type, created_at, updated_at FROM folders WHERE name = ? AND parent_id IS NULL`
+	query := `SELECT id, name, parent_id, type, pregenerate_thumbnails, created_at, updated_at FROM folders WHERE name = ? AND parent_id IS NULL`
 	args := []interface{}{name}
 	if parentID != nil {
-		query = `SELECT id, name, parent_id, type, created_at, updated_at FROM folders WHERE name = ? AND parent_id = ?`
+		query = `SELECT id, name, parent_id, type, pregenerate_thumbnails, created_at, updated_at FROM folders WHERE name = ? AND parent_id = ?`
 		args = []interface{}{name, *parentID}
 	}
 
@@ -105,7 +106,7 @@ func (r *folderRepository) FindByName(ctx context.Context, name string, parentID
 	var folderType sql.NullString
 	var createdAt string
 	var updatedAt string
-	if err := row.Scan(&folder.ID, &folder.Name, &parent, &folderType, &createdAt, &updatedAt); err != nil {
+	if err := row.Scan(&folder.ID, &folder.Name, &parent, &folderType, &folder.PregenerateThumbnails, &createdAt, &updatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
@@ -133,7 +134,7 @@ func (r *folderRepository) FindByName(ctx context.Context, name string, parentID
 }
 
 func (r *folderRepository) List(ctx context.Context) ([]model.Folder, error) {
-	rows, err := r.db.QueryContext(ctx, `SELECT id, name, parent_id, type, created_at, updated_at FROM folders ORDER BY name`)
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, parent_id, type, pregenerate_thumbnails, created_at, updated_at FROM folders ORDER BY name`)
 	if err != nil {
 		return nil, fmt.Errorf("list folders: %w", err)
 	}
@@ -146,7 +147,7 @@ func (r *folderRepository) List(ctx context.Context) ([]model.Folder, error) {
 		var folderType sql.NullString
 		var createdAt string
 		var updatedAt string
-		if err := rows.Scan(&folder.ID, &folder.Name, &parentID, &folderType, &createdAt, &updatedAt); err != nil {
+		if err := rows.Scan(&folder.ID, &folder.Name, &parentID, &folderType, &folder.PregenerateThumbnails, &createdAt, &updatedAt); err != nil {
 			return nil, fmt.Errorf("scan folder: %w", err)
 		}
 		if parentID.Valid {
@@ -202,6 +203,22 @@ func (r *folderRepository) UpdateType(ctx context.Context, id int64, folderType
 	return err
 }
 
+func (r *folderRepository) UpdatePregenerateThumbnails(ctx context.Context, id int64, enabled bool) error {
+	enabledInt := 0
+	if enabled {
+		enabledInt = 1
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE folders SET pregenerate_thumbnails = ?, updated_at = ? WHERE id = ?`,
+		enabledInt,
+		formatTime(time.Now()),
+		id,
+	)
+	return err
+}
+
 func (r *folderRepository) Delete(ctx context.Context, id int64) error {
 	if _, err := r.db.ExecContext(ctx, `DELETE FROM folders WHERE id = ?`, id); err != nil {
 		return fmt.Errorf("delete folder: %w", err)
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+type ImportItemRepository interface {
+	Add(ctx context.Context, taskID, itemType string, itemID int64) error
+	ListByTask(ctx context.Context, taskID string) ([]model.ImportItem, error)
+	DeleteByTask(ctx context.Context, taskID string) error
+}
+
+type importItemRepository struct {
+	db dbtx
+}
+
+func NewImportItemRepository(db dbtx) ImportItemRepository {
+	return &importItemRepository{db: db}
+}
+
+func (r *importItemRepository) Add(ctx context.Context, taskID, itemType string, itemID int64) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT OR IGNORE INTO import_items (task_id, item_type, item_id, created_at) VALUES (?, ?, ?, ?)`,
+		taskID, itemType, itemID, formatTime(time.Now()),
+	)
+	if err != nil {
+		return fmt.Errorf("add import item: %w", err)
+	}
+	return nil
+}
+
+func (r *importItemRepository) ListByTask(ctx context.Context, taskID string) ([]model.ImportItem, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT task_id, item_type, item_id, created_at FROM import_items WHERE task_id = ? ORDER BY item_id`,
+		taskID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list import items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.ImportItem
+	for rows.Next() {
+		var item model.ImportItem
+		var createdAt string
+		if err := rows.Scan(&item.TaskID, &item.ItemType, &item.ItemID, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan import item: %w", err)
+		}
+		item.CreatedAt, err = parseTime(createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse import item created_at: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate import items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *importItemRepository) DeleteByTask(ctx context.Context, taskID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM import_items WHERE task_id = ?`, taskID); err != nil {
+		return fmt.Errorf("delete import items: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+// ImportTaskRepository keeps the state of OPML import tasks.
+type ImportTaskRepository interface {
+	Create(ctx context.Context, task model.ImportTask) error
+	// Get returns the task with id, or nil if there is none.
+	Get(ctx context.Context, id string) (*model.ImportTask, error)
+	// UpdateProgress records the feeds a running task has finished.
+	UpdateProgress(ctx context.Context, id string, processed int, result string) error
+	// Finish ends a running task with status and drops its document.
+	// Returns false if the task was not running, so the first end wins.
+	Finish(ctx context.Context, id, status string, processed int, result, errMsg string) (bool, error)
+	// ListRunning returns the tasks that have not ended, oldest first.
+	ListRunning(ctx context.Context) ([]model.ImportTask, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type importTaskRepository struct {
+	db dbtx
+}
+
+func NewImportTaskRepository(db dbtx) ImportTaskRepository {
+	return &importTaskRepository{db: db}
+}
+
+const importTaskColumns = `id, status, document, total, processed, result, error, created_at, updated_at, finished_at`
+
+func (r *importTaskRepository) Create(ctx context.Context, task model.ImportTask) error {
+	now := formatTime(time.Now())
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO import_tasks (`+importTaskColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NULL)`,
+		task.ID, task.Status, task.Document, task.Total, task.Processed, task.Result, task.Error, now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("create import task: %w", err)
+	}
+	return nil
+}
+
+func (r *importTaskRepository) Get(ctx context.Context, id string) (*model.ImportTask, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+importTaskColumns+` FROM import_tasks WHERE id = ?`, id)
+	task, err := scanImportTask(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get import task: %w", err)
+	}
+	return &task, nil
+}
+
+func (r *importTaskRepository) UpdateProgress(ctx context.Context, id string, processed int, result string) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE import_tasks SET processed = ?, result = ?, updated_at = ? WHERE id = ? AND status = 'running'`,
+		processed, result, formatTime(time.Now()), id,
+	)
+	if err != nil {
+		return fmt.Errorf("update import task progress: %w", err)
+	}
+	return nil
+}
+
+func (r *importTaskRepository) Finish(ctx context.Context, id, status string, processed int, result, errMsg string) (bool, error) {
+	now := formatTime(time.Now())
+	res, err := r.db.ExecContext(
+		ctx,
+		`UPDATE import_tasks SET status = ?, document = '', processed = ?, result = ?, error = ?, updated_at = ?, finished_at = ?
+		 WHERE id = ? AND status = 'running'`,
+		status, processed, result, errMsg, now, now, id,
+	)
+	if err != nil {
+		return false, fmt.Errorf("finish import task: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r *importTaskRepository) ListRunning(ctx context.Context) ([]model.ImportTask, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+importTaskColumns+` FROM import_tasks WHERE status = 'running' ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("list running import tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []model.ImportTask
+	for rows.Next() {
+		task, err := scanImportTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan import task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate import tasks: %w", err)
+	}
+	return tasks, nil
+}
+
+func (r *importTaskRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM import_tasks WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete import task: %w", err)
+	}
+	return nil
+}
+
+func scanImportTask(scanner interface {
+	Scan(dest ...interface{}) error
+}) (model.ImportTask, error) {
+	var task model.ImportTask
+	var createdAt, updatedAt string
+	var finishedAt sql.NullString
+	if err := scanner.Scan(&task.ID, &task.Status, &task.Document, &task.Total, &task.Processed, &task.Result, &task.Error, &createdAt, &updatedAt, &finishedAt); err != nil {
+		return model.ImportTask{}, err
+	}
+	task.CreatedAt, _ = parseTime(createdAt)
+	task.UpdatedAt, _ = parseTime(updatedAt)
+	if finishedAt.Valid {
+		task.FinishedAt = parseTimePtr(finishedAt.String)
+	}
+	return task, nil
+}
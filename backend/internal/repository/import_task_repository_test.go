@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestImportTaskRepository_Lifecycle(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewImportTaskRepository(db)
+	ctx := context.Background()
+
+	if task, err := repo.Get(ctx, "missing"); err != nil || task != nil {
+		t.Fatalf("expected no task, got %+v, %v", task, err)
+	}
+
+	for _, id := range []string{"first", "second"} {
+		if err := repo.Create(ctx, model.ImportTask{ID: id, Status: "running", Document: `{"body":{}}`, Total: 3}); err != nil {
+			t.Fatalf("create %s: %v", id, err)
+		}
+	}
+	if err := repo.UpdateProgress(ctx, "first", 2, `{"feedsCreated":2}`); err != nil {
+		t.Fatalf("update progress: %v", err)
+	}
+	task, err := repo.Get(ctx, "first")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if task == nil || task.Processed != 2 || task.Result != `{"feedsCreated":2}` || task.Document == "" || task.FinishedAt != nil {
+		t.Fatalf("expected the saved progress, got %+v", task)
+	}
+
+	ended, err := repo.Finish(ctx, "first", "cancelled", 2, task.Result, "")
+	if err != nil || !ended {
+		t.Fatalf("finish: %v, %v", ended, err)
+	}
+	// The first end wins
+	if ended, err := repo.Finish(ctx, "first", "done", 3, "", ""); err != nil || ended {
+		t.Fatalf("expected a finished task to stay, got %v, %v", ended, err)
+	}
+	if err := repo.UpdateProgress(ctx, "first", 3, ""); err != nil {
+		t.Fatalf("update progress: %v", err)
+	}
+	task, _ = repo.Get(ctx, "first")
+	if task.Status != "cancelled" || task.Processed != 2 || task.Document != "" || task.FinishedAt == nil {
+		t.Errorf("expected the cancelled task without its document, got %+v", task)
+	}
+
+	running, err := repo.ListRunning(ctx)
+	if err != nil {
+		t.Fatalf("list running: %v", err)
+	}
+	if len(running) != 1 || running[0].ID != "second" {
+		t.Errorf("expected only the second task running, got %+v", running)
+	}
+
+	if err := repo.Delete(ctx, "second"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if task, _ := repo.Get(ctx, "second"); task != nil {
+		t.Errorf("expected the task to be deleted, got %+v", task)
+	}
+}
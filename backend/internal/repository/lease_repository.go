@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// LeaseRepository hands out named leases, each held by one holder at a time
+// until it expires or is released.
+type LeaseRepository interface {
+	// Acquire takes the lease of name for holder until expires, or extends it
+	// when holder already has it. Returns false while another holder has a
+	// lease that has not expired by now.
+	Acquire(ctx context.Context, name, holder string, now, expires time.Time) (bool, error)
+	// Release gives up the lease of name if holder has it.
+	Release(ctx context.Context, name, holder string) error
+}
+
+type leaseRepository struct {
+	db *sql.DB
+}
+
+func NewLeaseRepository(db *sql.DB) LeaseRepository {
+	return &leaseRepository{db: db}
+}
+
+func (r *leaseRepository) Acquire(ctx context.Context, name, holder string, now, expires time.Time) (bool, error) {
+	res, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO leases (name, holder, expires_at) VALUES (?, ?, ?)
+		 ON CONFLICT(name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+		 WHERE leases.holder = excluded.holder OR leases.expires_at <= ?`,
+		name, holder, expires.UnixMilli(), now.UnixMilli(),
+	)
+	if err != nil {
+		return false, fmt.Errorf("acquire lease %s: %w", name, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("acquire lease %s: %w", name, err)
+	}
+	return n > 0, nil
+}
+
+func (r *leaseRepository) Release(ctx context.Context, name, holder string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM leases WHERE name = ? AND holder = ?`, name, holder); err != nil {
+		return fmt.Errorf("release lease %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestLeaseRepository_AcquireAndRelease(t *testing.T) {
+	t.Parallel()
+	repo := NewLeaseRepository(testutil.NewTestDB(t))
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	acquire := func(holder string, at time.Time) bool {
+		t.Helper()
+		ok, err := repo.Acquire(ctx, "task:refresh", holder, at, at.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("acquire: %v", err)
+		}
+		return ok
+	}
+
+	if !acquire("a", now) {
+		t.Fatal("expected a free lease to be acquired")
+	}
+	if acquire("b", now.Add(30*time.Second)) {
+		t.Error("expected a held lease not to be acquired by another holder")
+	}
+	// The holder extends its own lease
+	if !acquire("a", now.Add(50*time.Second)) {
+		t.Error("expected the holder to extend its lease")
+	}
+	if acquire("b", now.Add(70*time.Second)) {
+		t.Error("expected the extended lease to be held still")
+	}
+	// An expired lease is taken over
+	if !acquire("b", now.Add(2*time.Minute)) {
+		t.Error("expected an expired lease to be taken over")
+	}
+
+	// Only the holder releases a lease
+	if err := repo.Release(ctx, "task:refresh", "a"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if acquire("a", now.Add(2*time.Minute)) {
+		t.Error("expected a release by another holder to keep the lease")
+	}
+	if err := repo.Release(ctx, "task:refresh", "b"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if !acquire("a", now.Add(2*time.Minute)) {
+		t.Error("expected a released lease to be acquired")
+	}
+}
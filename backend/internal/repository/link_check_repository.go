@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+type LinkCheckRepository interface {
+	// ListDue returns up to limit starred entries never checked or last
+	// checked before checkedBefore, never-checked first.
+	ListDue(ctx context.Context, checkedBefore time.Time, limit int) ([]model.LinkToCheck, error)
+	// Save records the latest check of an entry, replacing the previous one.
+	Save(ctx context.Context, check model.LinkCheck) error
+	// ListBroken returns the starred entries whose last check failed, dead links first.
+	ListBroken(ctx context.Context) ([]model.BrokenLink, error)
+}
+
+type linkCheckRepository struct {
+	db dbtx
+}
+
+func NewLinkCheckRepository(db dbtx) LinkCheckRepository {
+	return &linkCheckRepository{db: db}
+}
+
+func (r *linkCheckRepository) ListDue(ctx context.Context, checkedBefore time.Time, limit int) ([]model.LinkToCheck, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT e.id, e.url, c.failing_since
+		 FROM entries e
+		 LEFT JOIN entry_link_checks c ON c.entry_id = e.id
+		 WHERE e.starred = 1 AND e.url IS NOT NULL AND e.url != ''
+		   AND (c.checked_at IS NULL OR c.checked_at < ?)
+		 ORDER BY c.checked_at IS NOT NULL, c.checked_at, e.id
+		 LIMIT ?`,
+		formatTime(checkedBefore), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list due link checks: %w", err)
+	}
+	defer rows.Close()
+
+	var links []model.LinkToCheck
+	for rows.Next() {
+		var link model.LinkToCheck
+		var failingSince sql.NullString
+		if err := rows.Scan(&link.EntryID, &link.URL, &failingSince); err != nil {
+			return nil, fmt.Errorf("scan due link check: %w", err)
+		}
+		if failingSince.Valid {
+			link.FailingSince = parseTimePtr(failingSince.String)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate due link checks: %w", err)
+	}
+	return links, nil
+}
+
+func (r *linkCheckRepository) Save(ctx context.Context, check model.LinkCheck) error {
+	var failingSince interface{}
+	if check.FailingSince != nil {
+		failingSince = formatTime(*check.FailingSince)
+	}
+	var httpStatus interface{}
+	if check.HTTPStatus != nil {
+		httpStatus = *check.HTTPStatus
+	}
+
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO entry_link_checks (entry_id, status, http_status, error, checked_at, failing_since)
+		 VALUES (?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(entry_id) DO UPDATE SET
+		   status = excluded.status,
+		   http_status = excluded.http_status,
+		   error = excluded.error,
+		   checked_at = excluded.checked_at,
+		   failing_since = excluded.failing_since`,
+		check.EntryID, check.Status, httpStatus, nullableString(check.Error), formatTime(check.CheckedAt), failingSince,
+	)
+	if err != nil {
+		return fmt.Errorf("save link check: %w", err)
+	}
+	return nil
+}
+
+func (r *linkCheckRepository) ListBroken(ctx context.Context) ([]model.BrokenLink, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT c.entry_id, c.status, c.http_status, c.error, c.checked_at, c.failing_since,
+		        e.feed_id, f.title, e.title, e.url
+		 FROM entry_link_checks c
+		 INNER JOIN entries e ON e.id = c.entry_id
+		 INNER JOIN feeds f ON f.id = e.feed_id
+		 WHERE c.status != ? AND e.starred = 1
+		 ORDER BY c.status = ? DESC, c.failing_since, c.entry_id`,
+		model.LinkOK, model.LinkDead,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list broken links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []model.BrokenLink
+	for rows.Next() {
+		var link model.BrokenLink
+		var httpStatus sql.NullInt64
+		var checkedAt string
+		var failingSince sql.NullString
+		if err := rows.Scan(
+			&link.EntryID, &link.Status, &httpStatus, &link.Error, &checkedAt, &failingSince,
+			&link.FeedID, &link.FeedTitle, &link.Title, &link.URL,
+		); err != nil {
+			return nil, fmt.Errorf("scan broken link: %w", err)
+		}
+		if httpStatus.Valid {
+			status := int(httpStatus.Int64)
+			link.HTTPStatus = &status
+		}
+		link.CheckedAt, _ = parseTime(checkedAt)
+		if failingSince.Valid {
+			link.FailingSince = parseTimePtr(failingSince.String)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate broken links: %w", err)
+	}
+	return links, nil
+}
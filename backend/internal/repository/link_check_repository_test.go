@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestLinkCheckRepository_ListDue(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewLinkCheckRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	urlA, urlB, urlC := "https://example.com/a", "https://example.com/b", "https://example.com/c"
+	idA := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlA, Starred: true})
+	idB := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlB, Starred: true})
+	idC := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlC, Starred: true})
+	urlD := "https://example.com/d"
+	testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlD})    // not starred
+	testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, Starred: true}) // no URL
+
+	now := time.Now().UTC().Truncate(time.Second)
+	failingSince := now.Add(-72 * time.Hour)
+	if err := repo.Save(ctx, model.LinkCheck{EntryID: idA, Status: model.LinkDead, CheckedAt: now.Add(-10 * 24 * time.Hour), FailingSince: &failingSince}); err != nil {
+		t.Fatalf("save stale check: %v", err)
+	}
+	if err := repo.Save(ctx, model.LinkCheck{EntryID: idB, Status: model.LinkOK, CheckedAt: now}); err != nil {
+		t.Fatalf("save fresh check: %v", err)
+	}
+
+	due, err := repo.ListDue(ctx, now.Add(-7*24*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("list due: %v", err)
+	}
+	// Never-checked first, then the stale check; the fresh one is not due
+	if len(due) != 2 || due[0].EntryID != idC || due[1].EntryID != idA {
+		t.Fatalf("expected c then a, got %+v", due)
+	}
+	if due[1].FailingSince == nil || !due[1].FailingSince.Equal(failingSince) {
+		t.Errorf("expected failingSince %v, got %v", failingSince, due[1].FailingSince)
+	}
+
+	limited, err := repo.ListDue(ctx, now.Add(-7*24*time.Hour), 1)
+	if err != nil {
+		t.Fatalf("list due with limit: %v", err)
+	}
+	if len(limited) != 1 || limited[0].EntryID != idC {
+		t.Errorf("expected only c, got %+v", limited)
+	}
+}
+
+func TestLinkCheckRepository_ListBroken(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewLinkCheckRepository(db)
+	entries := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Feed", URL: "https://example.com/feed"})
+	urlA, urlB, urlC := "https://example.com/a", "https://example.com/b", "https://example.com/c"
+	idA := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlA, Starred: true})
+	idB := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlB, Starred: true})
+	idC := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, URL: &urlC, Starred: true})
+
+	now := time.Now().UTC().Truncate(time.Second)
+	status := 503
+	if err := repo.Save(ctx, model.LinkCheck{EntryID: idA, Status: model.LinkError, HTTPStatus: &status, CheckedAt: now, FailingSince: &now}); err != nil {
+		t.Fatalf("save error check: %v", err)
+	}
+	// Saving again replaces the previous check
+	if err := repo.Save(ctx, model.LinkCheck{EntryID: idB, Status: model.LinkOK, CheckedAt: now}); err != nil {
+		t.Fatalf("save ok check: %v", err)
+	}
+	gone := 404
+	if err := repo.Save(ctx, model.LinkCheck{EntryID: idB, Status: model.LinkDead, HTTPStatus: &gone, CheckedAt: now, FailingSince: &now}); err != nil {
+		t.Fatalf("save dead check: %v", err)
+	}
+	if err := repo.Save(ctx, model.LinkCheck{EntryID: idC, Status: model.LinkOK, CheckedAt: now}); err != nil {
+		t.Fatalf("save ok check: %v", err)
+	}
+
+	broken, err := repo.ListBroken(ctx)
+	if err != nil {
+		t.Fatalf("list broken: %v", err)
+	}
+	if len(broken) != 2 || broken[0].EntryID != idB || broken[1].EntryID != idA {
+		t.Fatalf("expected b (dead) then a (error), got %+v", broken)
+	}
+	if broken[0].HTTPStatus == nil || *broken[0].HTTPStatus != gone || broken[0].FeedTitle != "Feed" || broken[0].URL != urlB {
+		t.Errorf("unexpected broken link: %+v", broken[0])
+	}
+
+	entry, err := entries.GetByID(ctx, idB)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if !entry.LinkDead {
+		t.Error("expected entry b to be marked linkDead")
+	}
+	entry, err = entries.GetByID(ctx, idA)
+	if err != nil {
+		t.Fatalf("get entry: %v", err)
+	}
+	if entry.LinkDead {
+		t.Error("an erroring link should not be marked dead")
+	}
+
+	// Unstarring drops the entry from the report
+	if err := entries.UpdateStarredStatus(ctx, idB, false); err != nil {
+		t.Fatalf("unstar: %v", err)
+	}
+	broken, err = repo.ListBroken(ctx)
+	if err != nil {
+		t.Fatalf("list broken: %v", err)
+	}
+	if len(broken) != 1 || broken[0].EntryID != idA {
+		t.Errorf("expected only a, got %+v", broken)
+	}
+}
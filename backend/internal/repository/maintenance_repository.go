@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gist/backend/internal/db"
+)
+
+// ErrInvalidDatabase is returned by Restore when the file is not an intact
+// Gist database.
+var ErrInvalidDatabase = errors.New("not a valid Gist database")
+
+// sqliteHeader starts every SQLite database file.
+const sqliteHeader = "SQLite format 3\x00"
+
+// requiredTables are the tables a file must have to be taken for a Gist
+// database.
+var requiredTables = []string{"folders", "feeds", "entries", "settings"}
+
+// MaintenanceRepository copies the whole database out and back in.
+type MaintenanceRepository interface {
+	// Snapshot writes a consistent, compacted copy of the database to path,
+	// which must not exist yet. Writes go on while it is taken.
+	Snapshot(ctx context.Context, path string) error
+	// Restore replaces every row of the database with those of the database
+	// file at path, in one transaction. The file is checked for integrity
+	// and migrated to the current schema first, which modifies it; it
+	// returns ErrInvalidDatabase when the file is not a Gist database.
+	// Returns the number of rows restored.
+	Restore(ctx context.Context, path string) (int64, error)
+}
+
+type maintenanceRepository struct {
+	db *sql.DB
+}
+
+func NewMaintenanceRepository(db *sql.DB) MaintenanceRepository {
+	return &maintenanceRepository{db: db}
+}
+
+func (r *maintenanceRepository) Snapshot(ctx context.Context, path string) error {
+	if _, err := r.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("vacuum into: %w", err)
+	}
+	return nil
+}
+
+func (r *maintenanceRepository) Restore(ctx context.Context, path string) (int64, error) {
+	if err := prepareRestore(ctx, path); err != nil {
+		return 0, err
+	}
+
+	// The restored database is attached to a single connection, which the
+	// transaction must then run on
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS restored`, path); err != nil {
+		return 0, fmt.Errorf("attach restored database: %w", err)
+	}
+	defer func() { _, _ = conn.ExecContext(context.Background(), `DETACH DATABASE restored`) }()
+
+	tables, err := dataTables(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Foreign keys are checked at commit, when every table is filled again
+	if _, err := tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return 0, fmt.Errorf("defer foreign keys: %w", err)
+	}
+	for _, table := range tables {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM main."`+table.name+`"`); err != nil {
+			return 0, fmt.Errorf("empty %s: %w", table.name, err)
+		}
+	}
+	// The full-text index is filled by the triggers of the tables it
+	// indexes, and AUTOINCREMENT counters as rows are inserted
+	var restored int64
+	for _, table := range tables {
+		if table.virtual || table.name == "sqlite_sequence" {
+			continue
+		}
+		columns, err := restoredColumns(ctx, tx, table.name)
+		if err != nil {
+			return 0, err
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		list := `"` + strings.Join(columns, `", "`) + `"`
+		res, err := tx.ExecContext(ctx, `INSERT INTO main."`+table.name+`" (`+list+`) SELECT `+list+` FROM restored."`+table.name+`"`)
+		if err != nil {
+			return 0, fmt.Errorf("restore %s: %w", table.name, err)
+		}
+		n, _ := res.RowsAffected()
+		restored += n
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+	return restored, nil
+}
+
+// restoredColumns returns the columns of a table that the restored database
+// has too, so a table it lacks, as one added by a later version, is left
+// empty.
+func restoredColumns(ctx context.Context, tx *sql.Tx, table string) ([]string, error) {
+	rows, err := tx.QueryContext(
+		ctx,
+		`SELECT m.name FROM pragma_table_info(?, 'main') m
+		 JOIN pragma_table_info(?, 'restored') r ON r.name = m.name
+		 ORDER BY m.cid`,
+		table, table,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("scan column: %w", err)
+		}
+		columns = append(columns, name)
+	}
+	return columns, rows.Err()
+}
+
+// prepareRestore checks that the file at path is an intact Gist database
+// and brings it to the current schema, so a backup of an older version can
+// be restored.
+func prepareRestore(ctx context.Context, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open restored database: %w", err)
+	}
+	header := make([]byte, len(sqliteHeader))
+	_, err = io.ReadFull(file, header)
+	file.Close()
+	if err != nil || string(header) != sqliteHeader {
+		return ErrInvalidDatabase
+	}
+
+	conn, err := sql.Open("sqlite", "file:"+path+"?mode=rw")
+	if err != nil {
+		return fmt.Errorf("open restored database: %w", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	var result string
+	if err := conn.QueryRowContext(ctx, `PRAGMA integrity_check(1)`).Scan(&result); err != nil || result != "ok" {
+		return fmt.Errorf("%w: integrity check failed", ErrInvalidDatabase)
+	}
+	for _, table := range requiredTables {
+		var count int
+		if err := conn.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&count); err != nil {
+			return fmt.Errorf("check restored tables: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: no %s table", ErrInvalidDatabase, table)
+		}
+	}
+	if err := db.Migrate(conn); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestMaintenanceRepository_SnapshotAndRestore(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewMaintenanceRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	title := "Searchable entry"
+	entryID := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, Title: &title})
+	testutil.SeedSetting(t, db, "ai.provider", "openai")
+
+	path := filepath.Join(t.TempDir(), "snapshot.db")
+	if err := repo.Snapshot(ctx, path); err != nil {
+		t.Fatalf("snapshot: %v", err)
+	}
+
+	// Changes after the snapshot are undone by restoring it
+	if _, err := db.ExecContext(ctx, `DELETE FROM entries WHERE id = ?`, entryID); err != nil {
+		t.Fatalf("delete entry: %v", err)
+	}
+	testutil.SeedFeed(t, db, model.Feed{Title: "Later", URL: "https://example.com/later"})
+	if _, err := db.ExecContext(ctx, `UPDATE settings SET value = 'anthropic' WHERE key = 'ai.provider'`); err != nil {
+		t.Fatalf("update setting: %v", err)
+	}
+
+	restored, err := repo.Restore(ctx, path)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored < 3 {
+		t.Errorf("expected at least 3 rows restored, got %d", restored)
+	}
+
+	var feeds int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM feeds`).Scan(&feeds); err != nil {
+		t.Fatalf("count feeds: %v", err)
+	}
+	if feeds != 1 {
+		t.Errorf("expected the snapshot's single feed, got %d", feeds)
+	}
+	var provider string
+	if err := db.QueryRowContext(ctx, `SELECT value FROM settings WHERE key = 'ai.provider'`).Scan(&provider); err != nil {
+		t.Fatalf("get setting: %v", err)
+	}
+	if provider != "openai" {
+		t.Errorf("expected the snapshot's setting, got %q", provider)
+	}
+	// The full-text index is rebuilt with the entries
+	var matches int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM entries_fts WHERE entries_fts MATCH 'searchable'`).Scan(&matches); err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if matches != 1 {
+		t.Errorf("expected the restored entry to be searchable, got %d matches", matches)
+	}
+}
+
+func TestMaintenanceRepository_RestoreRejectsOtherFiles(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewMaintenanceRepository(db)
+	ctx := context.Background()
+	testutil.SeedSetting(t, db, "ai.provider", "openai")
+
+	notSQLite := filepath.Join(t.TempDir(), "notes.db")
+	if err := os.WriteFile(notSQLite, []byte("not a database"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := repo.Restore(ctx, notSQLite); !errors.Is(err, ErrInvalidDatabase) {
+		t.Errorf("expected ErrInvalidDatabase for a non-SQLite file, got %v", err)
+	}
+
+	// A SQLite database of another application
+	other := filepath.Join(t.TempDir(), "other.db")
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("get connection: %v", err)
+	}
+	for _, stmt := range []string{`ATTACH DATABASE '` + other + `' AS other`, `CREATE TABLE other.notes (id INTEGER PRIMARY KEY)`, `DETACH DATABASE other`} {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			t.Fatalf("create other database: %v", err)
+		}
+	}
+	conn.Close()
+	if _, err := repo.Restore(ctx, other); !errors.Is(err, ErrInvalidDatabase) {
+		t.Errorf("expected ErrInvalidDatabase for another database, got %v", err)
+	}
+
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM settings`).Scan(&n); err != nil {
+		t.Fatalf("count settings: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected the data to be kept, got %d settings", n)
+	}
+}
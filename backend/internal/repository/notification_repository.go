@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/snowflake"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification model.Notification) (model.Notification, error)
+	// List returns up to limit notifications, newest first.
+	List(ctx context.Context, unreadOnly bool, limit int) ([]model.Notification, error)
+	CountUnread(ctx context.Context) (int, error)
+	// MarkRead returns sql.ErrNoRows if the notification does not exist.
+	MarkRead(ctx context.Context, id int64) error
+	MarkAllRead(ctx context.Context) error
+	// Delete returns sql.ErrNoRows if the notification does not exist.
+	Delete(ctx context.Context, id int64) error
+	// Trim deletes all but the newest keep notifications.
+	Trim(ctx context.Context, keep int) error
+}
+
+type notificationRepository struct {
+	db dbtx
+}
+
+func NewNotificationRepository(db dbtx) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+const notificationColumns = `id, kind, task_kind, feed_id, title, body, read, created_at`
+
+func (r *notificationRepository) Create(ctx context.Context, notification model.Notification) (model.Notification, error) {
+	notification.ID = snowflake.NextID()
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO notifications (id, kind, task_kind, feed_id, title, body, read, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, 0, ?)`,
+		notification.ID,
+		notification.Kind,
+		nullableString(notification.TaskKind),
+		nullableInt64(notification.FeedID),
+		notification.Title,
+		notification.Body,
+		formatTime(now),
+	)
+	if err != nil {
+		return model.Notification{}, fmt.Errorf("create notification: %w", err)
+	}
+	notification.Read = false
+	notification.CreatedAt = now
+	return notification, nil
+}
+
+func (r *notificationRepository) List(ctx context.Context, unreadOnly bool, limit int) ([]model.Notification, error) {
+	query := `SELECT ` + notificationColumns + ` FROM notifications`
+	if unreadOnly {
+		query += ` WHERE read = 0`
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []model.Notification
+	for rows.Next() {
+		notification, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM notifications WHERE read = 0`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE notifications SET read = 1 WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("mark notification read: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("mark notification read: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context) error {
+	if _, err := r.db.ExecContext(ctx, `UPDATE notifications SET read = 1 WHERE read = 0`); err != nil {
+		return fmt.Errorf("mark all notifications read: %w", err)
+	}
+	return nil
+}
+
+func (r *notificationRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM notifications WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete notification: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("delete notification: %w", sql.ErrNoRows)
+	}
+	return nil
+}
+
+func (r *notificationRepository) Trim(ctx context.Context, keep int) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`DELETE FROM notifications WHERE id NOT IN (
+			SELECT id FROM notifications ORDER BY created_at DESC, id DESC LIMIT ?
+		)`,
+		keep,
+	)
+	if err != nil {
+		return fmt.Errorf("trim notifications: %w", err)
+	}
+	return nil
+}
+
+func scanNotification(scanner interface {
+	Scan(dest ...interface{}) error
+}) (model.Notification, error) {
+	var notification model.Notification
+	var taskKind sql.NullString
+	var feedID sql.NullInt64
+	var createdAt string
+	if err := scanner.Scan(
+		&notification.ID, &notification.Kind, &taskKind, &feedID, &notification.Title, &notification.Body,
+		&notification.Read, &createdAt,
+	); err != nil {
+		return model.Notification{}, err
+	}
+	if taskKind.Valid {
+		notification.TaskKind = &taskKind.String
+	}
+	if feedID.Valid {
+		notification.FeedID = &feedID.Int64
+	}
+	notification.CreatedAt, _ = parseTime(createdAt)
+	return notification, nil
+}
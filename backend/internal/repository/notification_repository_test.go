@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestNotificationRepository(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewNotificationRepository(db)
+	ctx := context.Background()
+
+	kind := "import"
+	first, err := repo.Create(ctx, model.Notification{Kind: model.NotificationImportDone, TaskKind: &kind, Title: "Import finished", Body: "3 feeds added"})
+	if err != nil {
+		t.Fatalf("create notification: %v", err)
+	}
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed", Type: "article"})
+	second, err := repo.Create(ctx, model.Notification{Kind: model.NotificationFeedDisabled, FeedID: &feedID, Title: "Blog disabled"})
+	if err != nil {
+		t.Fatalf("create notification: %v", err)
+	}
+
+	list, err := repo.List(ctx, false, 10)
+	if err != nil {
+		t.Fatalf("list notifications: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != second.ID || list[0].FeedID == nil || *list[0].FeedID != feedID || list[1].FeedID != nil || list[1].TaskKind == nil || *list[1].TaskKind != "import" || list[1].Body != "3 feeds added" {
+		t.Fatalf("expected both notifications newest first, got %+v", list)
+	}
+
+	if err := repo.MarkRead(ctx, first.ID); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	if err := repo.MarkRead(ctx, first.ID+second.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a missing notification, got %v", err)
+	}
+	unread, err := repo.List(ctx, true, 10)
+	if err != nil || len(unread) != 1 || unread[0].ID != second.ID {
+		t.Errorf("expected only the second notification unread, got %+v, %v", unread, err)
+	}
+
+	if err := repo.MarkAllRead(ctx); err != nil {
+		t.Fatalf("mark all read: %v", err)
+	}
+	if count, err := repo.CountUnread(ctx); err != nil || count != 0 {
+		t.Errorf("expected no unread notifications, got %d, %v", count, err)
+	}
+
+	if err := repo.Trim(ctx, 1); err != nil {
+		t.Fatalf("trim: %v", err)
+	}
+	if list, _ := repo.List(ctx, false, 10); len(list) != 1 || list[0].ID != second.ID {
+		t.Errorf("expected only the newest notification kept, got %+v", list)
+	}
+
+	if err := repo.Delete(ctx, second.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := repo.Delete(ctx, second.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a deleted notification, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/snowflake"
+)
+
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, kind, payload string) error
+	ListDue(ctx context.Context, now time.Time, limit int) ([]model.OutboxMessage, error)
+	Reschedule(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type outboxRepository struct {
+	db dbtx
+}
+
+func NewOutboxRepository(db dbtx) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, kind, payload string) error {
+	now := formatTime(time.Now())
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO outbox (id, kind, payload, next_attempt_at, created_at) VALUES (?, ?, ?, ?, ?)`,
+		snowflake.NextID(), kind, payload, now, now,
+	)
+	if err != nil {
+		return fmt.Errorf("enqueue outbox message: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]model.OutboxMessage, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT id, kind, payload, attempts, last_error, next_attempt_at, created_at
+		 FROM outbox WHERE next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?`,
+		formatTime(now), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []model.OutboxMessage
+	for rows.Next() {
+		var msg model.OutboxMessage
+		var lastError sql.NullString
+		var nextAttemptAt string
+		var createdAt string
+		if err := rows.Scan(&msg.ID, &msg.Kind, &msg.Payload, &msg.Attempts, &lastError, &nextAttemptAt, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan outbox message: %w", err)
+		}
+		if lastError.Valid {
+			msg.LastError = &lastError.String
+		}
+		msg.NextAttemptAt, err = parseTime(nextAttemptAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse outbox next_attempt_at: %w", err)
+		}
+		msg.CreatedAt, err = parseTime(createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("parse outbox created_at: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate outbox messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *outboxRepository) Reschedule(ctx context.Context, id int64, lastError string, nextAttemptAt time.Time) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		`UPDATE outbox SET attempts = attempts + 1, last_error = ?, next_attempt_at = ? WHERE id = ?`,
+		lastError, formatTime(nextAttemptAt), id,
+	)
+	if err != nil {
+		return fmt.Errorf("reschedule outbox message: %w", err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM outbox WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete outbox message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+// PlaybackRepository stores where playback of each entry stopped.
+type PlaybackRepository interface {
+	// Get returns the playback position of an entry, zero when it was never
+	// played, or sql.ErrNoRows when the entry does not exist.
+	Get(ctx context.Context, entryID int64) (model.PlaybackPosition, error)
+	// Set stores the playback position of an entry. Returns sql.ErrNoRows
+	// when the entry does not exist.
+	Set(ctx context.Context, position model.PlaybackPosition) error
+}
+
+type playbackRepository struct {
+	db *sql.DB
+}
+
+func NewPlaybackRepository(db *sql.DB) PlaybackRepository {
+	return &playbackRepository{db: db}
+}
+
+func (r *playbackRepository) Get(ctx context.Context, entryID int64) (model.PlaybackPosition, error) {
+	var position sql.NullInt64
+	var updatedAt sql.NullString
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT p.position_seconds, p.updated_at FROM entries e
+		 LEFT JOIN playback_positions p ON p.entry_id = e.id
+		 WHERE e.id = ?`,
+		entryID,
+	).Scan(&position, &updatedAt)
+	if err != nil {
+		return model.PlaybackPosition{}, err
+	}
+	playback := model.PlaybackPosition{EntryID: entryID, PositionSeconds: position.Int64}
+	if updatedAt.Valid {
+		playback.UpdatedAt, _ = time.Parse(time.RFC3339, updatedAt.String)
+	}
+	return playback, nil
+}
+
+func (r *playbackRepository) Set(ctx context.Context, position model.PlaybackPosition) error {
+	// Selecting from entries stores nothing for an unknown entry
+	res, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO playback_positions (entry_id, position_seconds, updated_at)
+		 SELECT id, ?, ? FROM entries WHERE id = ?
+		 ON CONFLICT(entry_id) DO UPDATE SET position_seconds = excluded.position_seconds, updated_at = excluded.updated_at`,
+		position.PositionSeconds, position.UpdatedAt.UTC().Format(time.RFC3339), position.EntryID,
+	)
+	if err != nil {
+		return fmt.Errorf("set playback position: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("set playback position: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
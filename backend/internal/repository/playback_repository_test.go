@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestPlaybackRepository_SetAndGet(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewPlaybackRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Podcast", URL: "https://example.com/podcast.xml", Type: "podcast"})
+	entryID := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+
+	got, err := repo.Get(ctx, entryID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.PositionSeconds != 0 || !got.UpdatedAt.IsZero() {
+		t.Errorf("expected no position before playback, got %+v", got)
+	}
+
+	for _, seconds := range []int64{90, 1200} {
+		at := time.Date(2026, 5, 1, 12, 0, int(seconds), 0, time.UTC)
+		if err := repo.Set(ctx, model.PlaybackPosition{EntryID: entryID, PositionSeconds: seconds, UpdatedAt: at}); err != nil {
+			t.Fatalf("set: %v", err)
+		}
+		got, err := repo.Get(ctx, entryID)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if got.PositionSeconds != seconds || !got.UpdatedAt.Equal(at) {
+			t.Errorf("got %+v, want %d at %v", got, seconds, at)
+		}
+	}
+
+	if _, err := repo.Get(ctx, entryID+1); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("get unknown entry: got %v, want sql.ErrNoRows", err)
+	}
+	if err := repo.Set(ctx, model.PlaybackPosition{EntryID: entryID + 1, PositionSeconds: 1, UpdatedAt: time.Now()}); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("set unknown entry: got %v, want sql.ErrNoRows", err)
+	}
+}
@@ -26,6 +26,13 @@ func nullableString(value *string) interface{} {
 	return *value
 }
 
+func nullableTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return formatTime(*value)
+}
+
 func formatTime(value time.Time) string {
 	return value.UTC().Format(time.RFC3339Nano)
 }
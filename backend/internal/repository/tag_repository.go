@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/snowflake"
+)
+
+// TagRepository stores tags and which entries have them. Tag names are
+// unique ignoring case.
+type TagRepository interface {
+	Create(ctx context.Context, name string) (model.Tag, error)
+	GetByID(ctx context.Context, id int64) (model.Tag, error)
+	// FindByName returns the tag named name ignoring case, or nil.
+	FindByName(ctx context.Context, name string) (*model.Tag, error)
+	// List returns every tag with its entry count, by name.
+	List(ctx context.Context) ([]model.Tag, error)
+	Rename(ctx context.Context, id int64, name string) (model.Tag, error)
+	// Delete deletes a tag, untagging its entries.
+	Delete(ctx context.Context, id int64) error
+	// ListByEntry returns the tags of an entry, by name.
+	ListByEntry(ctx context.Context, entryID int64) ([]model.Tag, error)
+	// Assign tags an entry; tagging it again does nothing.
+	Assign(ctx context.Context, entryID, tagID int64) error
+	Unassign(ctx context.Context, entryID, tagID int64) error
+}
+
+type tagRepository struct {
+	db dbtx
+}
+
+func NewTagRepository(db dbtx) TagRepository {
+	return &tagRepository{db: db}
+}
+
+func (r *tagRepository) Create(ctx context.Context, name string) (model.Tag, error) {
+	id := snowflake.NextID()
+	now := time.Now().UTC()
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO tags (id, name, created_at, updated_at) VALUES (?, ?, ?, ?)`,
+		id, name, formatTime(now), formatTime(now),
+	)
+	if err != nil {
+		return model.Tag{}, fmt.Errorf("create tag: %w", err)
+	}
+	return model.Tag{ID: id, Name: name, CreatedAt: now, UpdatedAt: now}, nil
+}
+
+func (r *tagRepository) GetByID(ctx context.Context, id int64) (model.Tag, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT id, name, created_at, updated_at FROM tags WHERE id = ?`, id)
+	tag, err := scanTag(row)
+	if err != nil {
+		return model.Tag{}, fmt.Errorf("get tag: %w", err)
+	}
+	return tag, nil
+}
+
+func (r *tagRepository) FindByName(ctx context.Context, name string) (*model.Tag, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT id, name, created_at, updated_at FROM tags WHERE name = ?`, name)
+	tag, err := scanTag(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("find tag: %w", err)
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) List(ctx context.Context) ([]model.Tag, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT t.id, t.name, t.created_at, t.updated_at, COUNT(et.entry_id)
+		 FROM tags t LEFT JOIN entry_tags et ON et.tag_id = t.id
+		 GROUP BY t.id ORDER BY t.name COLLATE NOCASE`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]model.Tag, 0)
+	for rows.Next() {
+		var count int
+		tag, err := scanTag(rows, &count)
+		if err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tag.EntryCount = count
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) Rename(ctx context.Context, id int64, name string) (model.Tag, error) {
+	if _, err := r.db.ExecContext(
+		ctx,
+		`UPDATE tags SET name = ?, updated_at = ? WHERE id = ?`,
+		name, formatTime(time.Now()), id,
+	); err != nil {
+		return model.Tag{}, fmt.Errorf("rename tag: %w", err)
+	}
+	return r.GetByID(ctx, id)
+}
+
+func (r *tagRepository) Delete(ctx context.Context, id int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("delete tag: %w", err)
+	}
+	return nil
+}
+
+func (r *tagRepository) ListByEntry(ctx context.Context, entryID int64) ([]model.Tag, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		`SELECT t.id, t.name, t.created_at, t.updated_at
+		 FROM tags t INNER JOIN entry_tags et ON et.tag_id = t.id
+		 WHERE et.entry_id = ? ORDER BY t.name COLLATE NOCASE`,
+		entryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list entry tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := make([]model.Tag, 0)
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate entry tags: %w", err)
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) Assign(ctx context.Context, entryID, tagID int64) error {
+	if _, err := r.db.ExecContext(
+		ctx,
+		`INSERT OR IGNORE INTO entry_tags (entry_id, tag_id, created_at) VALUES (?, ?, ?)`,
+		entryID, tagID, formatTime(time.Now()),
+	); err != nil {
+		return fmt.Errorf("tag entry: %w", err)
+	}
+	return nil
+}
+
+func (r *tagRepository) Unassign(ctx context.Context, entryID, tagID int64) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM entry_tags WHERE entry_id = ? AND tag_id = ?`, entryID, tagID); err != nil {
+		return fmt.Errorf("untag entry: %w", err)
+	}
+	return nil
+}
+
+// scanTag scans id, name, created_at and updated_at, then extra.
+func scanTag(scanner interface {
+	Scan(dest ...interface{}) error
+}, extra ...interface{}) (model.Tag, error) {
+	var tag model.Tag
+	var createdAt, updatedAt string
+	if err := scanner.Scan(append([]interface{}{&tag.ID, &tag.Name, &createdAt, &updatedAt}, extra...)...); err != nil {
+		return model.Tag{}, err
+	}
+	var err error
+	if tag.CreatedAt, err = parseTime(createdAt); err != nil {
+		return model.Tag{}, fmt.Errorf("parse tag created_at: %w", err)
+	}
+	if tag.UpdatedAt, err = parseTime(updatedAt); err != nil {
+		return model.Tag{}, fmt.Errorf("parse tag updated_at: %w", err)
+	}
+	return tag, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestTagRepository_TagEntries(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	tags := NewTagRepository(db)
+	entries := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	first := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+	second := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+
+	research, err := tags.Create(ctx, "Research")
+	if err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	later, err := tags.Create(ctx, "later")
+	if err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	if _, err := tags.Create(ctx, "RESEARCH"); err == nil {
+		t.Error("expected a name differing only in case to be refused")
+	}
+	if found, err := tags.FindByName(ctx, "research"); err != nil || found == nil || found.ID != research.ID {
+		t.Errorf("find ignoring case: got %+v, %v", found, err)
+	}
+
+	for _, assign := range [][2]int64{{first, research.ID}, {first, research.ID}, {second, research.ID}, {first, later.ID}} {
+		if err := tags.Assign(ctx, assign[0], assign[1]); err != nil {
+			t.Fatalf("assign: %v", err)
+		}
+	}
+	list, err := tags.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "later" || list[0].EntryCount != 1 || list[1].Name != "Research" || list[1].EntryCount != 2 {
+		t.Errorf("unexpected tags: %+v", list)
+	}
+	of, err := tags.ListByEntry(ctx, first)
+	if err != nil {
+		t.Fatalf("list by entry: %v", err)
+	}
+	if len(of) != 2 || of[0].ID != later.ID || of[1].ID != research.ID {
+		t.Errorf("unexpected tags of entry: %+v", of)
+	}
+
+	tagged, err := entries.List(ctx, EntryListFilter{TagID: &later.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("list entries by tag: %v", err)
+	}
+	if len(tagged) != 1 || tagged[0].ID != first {
+		t.Errorf("unexpected entries of tag: %+v", tagged)
+	}
+
+	if err := tags.Unassign(ctx, first, research.ID); err != nil {
+		t.Fatalf("unassign: %v", err)
+	}
+	if err := tags.Delete(ctx, later.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := tags.GetByID(ctx, later.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("get deleted tag: got %v, want sql.ErrNoRows", err)
+	}
+	if of, err := tags.ListByEntry(ctx, first); err != nil || len(of) != 0 {
+		t.Errorf("expected the entry untagged, got %+v, %v", of, err)
+	}
+	renamed, err := tags.Rename(ctx, research.ID, "Papers")
+	if err != nil || renamed.Name != "Papers" {
+		t.Errorf("rename: got %+v, %v", renamed, err)
+	}
+}
+
+func TestTagRepository_PruneKeepsTagged(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	tags := NewTagRepository(db)
+	entries := NewEntryRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	old := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+	kept := testutil.SeedEntry(t, db, model.Entry{FeedID: feedID})
+	if _, err := db.ExecContext(ctx, `UPDATE entries SET created_at = ?`, "2020-01-01T00:00:00Z"); err != nil {
+		t.Fatalf("age entries: %v", err)
+	}
+	tag, err := tags.Create(ctx, "keep")
+	if err != nil {
+		t.Fatalf("create tag: %v", err)
+	}
+	if err := tags.Assign(ctx, kept, tag.ID); err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+
+	deleted, err := entries.PruneOld(ctx, time.Now(), 0, 100)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("expected 1 pruned, got %d", deleted)
+	}
+	if _, err := entries.GetByID(ctx, old); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected the untagged entry pruned, got %v", err)
+	}
+	if _, err := entries.GetByID(ctx, kept); err != nil {
+		t.Errorf("expected the tagged entry kept, got %v", err)
+	}
+}
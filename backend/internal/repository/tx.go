@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// TxRepositories are repositories bound to a single transaction.
+type TxRepositories struct {
+	Folders FolderRepository
+	Feeds   FeedRepository
+	Entries EntryRepository
+	Outbox  OutboxRepository
+}
+
+// TxManager runs work that must commit or roll back as a unit.
+type TxManager interface {
+	WithTx(ctx context.Context, fn func(repos TxRepositories) error) error
+}
+
+type txManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) WithTx(ctx context.Context, fn func(repos TxRepositories) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	repos := TxRepositories{
+		Folders: NewFolderRepository(tx),
+		Feeds:   NewFeedRepository(tx),
+		Entries: NewEntryRepository(tx),
+		Outbox:  NewOutboxRepository(tx),
+	}
+	if err := fn(repos); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
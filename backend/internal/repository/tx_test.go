@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func countRows(t *testing.T, db dbtx, table string) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRowContext(context.Background(), `SELECT COUNT(*) FROM `+table).Scan(&n); err != nil {
+		t.Fatalf("count %s: %v", table, err)
+	}
+	return n
+}
+
+func TestTxManager_WithTx(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	tx := NewTxManager(db)
+	ctx := context.Background()
+
+	createFeed := func(repos TxRepositories, url string) error {
+		feed, err := repos.Feeds.Create(ctx, model.Feed{Title: "Blog", URL: url})
+		if err != nil {
+			return err
+		}
+		entryURL := url + "/1"
+		if err := repos.Entries.CreateOrUpdate(ctx, model.Entry{FeedID: feed.ID, URL: &entryURL}); err != nil {
+			return err
+		}
+		return repos.Outbox.Enqueue(ctx, "feed.icon", "{}")
+	}
+
+	failed := errors.New("side effect failed")
+	err := tx.WithTx(ctx, func(repos TxRepositories) error {
+		if err := createFeed(repos, "https://example.com/rolled-back"); err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected the work's error, got %v", err)
+	}
+	for _, table := range []string{"feeds", "entries", "outbox"} {
+		if n := countRows(t, db, table); n != 0 {
+			t.Errorf("expected %s to be rolled back, has %d rows", table, n)
+		}
+	}
+
+	if err := tx.WithTx(ctx, func(repos TxRepositories) error {
+		return createFeed(repos, "https://example.com/committed")
+	}); err != nil {
+		t.Fatalf("with tx: %v", err)
+	}
+	for _, table := range []string{"feeds", "entries", "outbox"} {
+		if n := countRows(t, db, table); n != 1 {
+			t.Errorf("expected %s to be committed, has %d rows", table, n)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+type WebSubRepository interface {
+	// Get returns the subscription of a feed, or sql.ErrNoRows.
+	Get(ctx context.Context, feedID int64) (model.WebSubSubscription, error)
+	// Save stores the subscription of a feed, replacing any previous one.
+	Save(ctx context.Context, sub model.WebSubSubscription) error
+	// UpdateState sets the state and lease of a feed's subscription, or
+	// returns sql.ErrNoRows when it has none.
+	UpdateState(ctx context.Context, feedID int64, state string, leaseExpiresAt *time.Time) error
+}
+
+type webSubRepository struct {
+	db dbtx
+}
+
+func NewWebSubRepository(db dbtx) WebSubRepository {
+	return &webSubRepository{db: db}
+}
+
+func (r *webSubRepository) Get(ctx context.Context, feedID int64) (model.WebSubSubscription, error) {
+	var sub model.WebSubSubscription
+	var leaseExpiresAt sql.NullString
+	var createdAt, updatedAt string
+	err := r.db.QueryRowContext(
+		ctx,
+		`SELECT feed_id, hub, topic, secret, state, lease_expires_at, created_at, updated_at
+		 FROM websub_subscriptions WHERE feed_id = ?`,
+		feedID,
+	).Scan(&sub.FeedID, &sub.Hub, &sub.Topic, &sub.Secret, &sub.State, &leaseExpiresAt, &createdAt, &updatedAt)
+	if err != nil {
+		return model.WebSubSubscription{}, err
+	}
+	if leaseExpiresAt.Valid {
+		sub.LeaseExpiresAt = parseTimePtr(leaseExpiresAt.String)
+	}
+	sub.CreatedAt, _ = parseTime(createdAt)
+	sub.UpdatedAt, _ = parseTime(updatedAt)
+	return sub, nil
+}
+
+func (r *webSubRepository) Save(ctx context.Context, sub model.WebSubSubscription) error {
+	now := formatTime(time.Now())
+	_, err := r.db.ExecContext(
+		ctx,
+		`INSERT INTO websub_subscriptions (feed_id, hub, topic, secret, state, lease_expires_at, created_at, updated_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT(feed_id) DO UPDATE SET
+			hub = excluded.hub,
+			topic = excluded.topic,
+			secret = excluded.secret,
+			state = excluded.state,
+			lease_expires_at = excluded.lease_expires_at,
+			updated_at = excluded.updated_at`,
+		sub.FeedID,
+		sub.Hub,
+		sub.Topic,
+		sub.Secret,
+		sub.State,
+		nullableTime(sub.LeaseExpiresAt),
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("save websub subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *webSubRepository) UpdateState(ctx context.Context, feedID int64, state string, leaseExpiresAt *time.Time) error {
+	result, err := r.db.ExecContext(
+		ctx,
+		`UPDATE websub_subscriptions SET state = ?, lease_expires_at = ?, updated_at = ? WHERE feed_id = ?`,
+		state, nullableTime(leaseExpiresAt), formatTime(time.Now()), feedID,
+	)
+	if err != nil {
+		return fmt.Errorf("update websub subscription: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update websub subscription: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("update websub subscription: %w", sql.ErrNoRows)
+	}
+	return nil
+}
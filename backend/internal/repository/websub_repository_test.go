@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestWebSubRepository(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewWebSubRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Channel", URL: "https://example.com/feed", Type: "article"})
+	if _, err := repo.Get(ctx, feedID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows before subscribing, got %v", err)
+	}
+
+	sub := model.WebSubSubscription{FeedID: feedID, Hub: "https://hub.example.com/", Topic: "https://example.com/feed", Secret: "secret", State: model.WebSubPending}
+	if err := repo.Save(ctx, sub); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	expires := time.Date(2025, 3, 11, 12, 0, 0, 0, time.UTC)
+	if err := repo.UpdateState(ctx, feedID, model.WebSubActive, &expires); err != nil {
+		t.Fatalf("update state: %v", err)
+	}
+	got, err := repo.Get(ctx, feedID)
+	if err != nil || got.Hub != sub.Hub || got.Topic != sub.Topic || got.Secret != "secret" || got.State != model.WebSubActive ||
+		got.LeaseExpiresAt == nil || !got.LeaseExpiresAt.Equal(expires) || got.CreatedAt.IsZero() {
+		t.Fatalf("unexpected subscription %+v, %v", got, err)
+	}
+
+	// Another hub replaces the subscription
+	sub.Hub, sub.Secret = "https://other.example.com/", "other"
+	if err := repo.Save(ctx, sub); err != nil {
+		t.Fatalf("save again: %v", err)
+	}
+	if got, _ = repo.Get(ctx, feedID); got.Hub != sub.Hub || got.Secret != "other" || got.State != model.WebSubPending || got.LeaseExpiresAt != nil {
+		t.Errorf("expected the replaced subscription, got %+v", got)
+	}
+
+	if err := repo.UpdateState(ctx, feedID+1, model.WebSubDenied, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for a feed without subscription, got %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// WipeRepository empties the whole database.
+type WipeRepository interface {
+	// WipeAll deletes every row of every table, keeping the schema, and
+	// compacts the database file so no deleted data stays in it. It returns
+	// the number of rows deleted.
+	WipeAll(ctx context.Context) (int64, error)
+}
+
+type wipeRepository struct {
+	db *sql.DB
+}
+
+func NewWipeRepository(db *sql.DB) WipeRepository {
+	return &wipeRepository{db: db}
+}
+
+func (r *wipeRepository) WipeAll(ctx context.Context) (int64, error) {
+	tables, err := dataTables(ctx, r.db)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Foreign keys are checked at commit, when every table is empty
+	if _, err := tx.ExecContext(ctx, `PRAGMA defer_foreign_keys = ON`); err != nil {
+		return 0, fmt.Errorf("defer foreign keys: %w", err)
+	}
+	var deleted int64
+	for _, table := range tables {
+		res, err := tx.ExecContext(ctx, `DELETE FROM "`+table.name+`"`)
+		if err != nil {
+			return 0, fmt.Errorf("wipe %s: %w", table.name, err)
+		}
+		if !table.virtual && table.name != "sqlite_sequence" {
+			n, _ := res.RowsAffected()
+			deleted += n
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+
+	// Deleted rows stay in free pages and the WAL until overwritten
+	if _, err := r.db.ExecContext(ctx, `VACUUM`); err != nil {
+		return 0, fmt.Errorf("vacuum: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `PRAGMA wal_checkpoint(TRUNCATE)`); err != nil {
+		return 0, fmt.Errorf("checkpoint wal: %w", err)
+	}
+	return deleted, nil
+}
+
+type wipeTable struct {
+	name    string
+	virtual bool
+}
+
+// dataTables lists the tables holding data, virtual ones first so the
+// full-text index is emptied before the triggers of the tables it indexes
+// run. The shadow tables of virtual tables are left to them; sqlite_sequence
+// is included so AUTOINCREMENT counters restart.
+func dataTables(ctx context.Context, q dbtx) ([]wipeTable, error) {
+	rows, err := q.QueryContext(
+		ctx,
+		`SELECT name, COALESCE(sql, '') FROM sqlite_master
+		 WHERE type = 'table' AND (name NOT LIKE 'sqlite\_%' ESCAPE '\' OR name = 'sqlite_sequence')
+		 ORDER BY name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	defer rows.Close()
+
+	var virtual, ordinary []wipeTable
+	for rows.Next() {
+		var name, ddl string
+		if err := rows.Scan(&name, &ddl); err != nil {
+			return nil, fmt.Errorf("scan table: %w", err)
+		}
+		if strings.HasPrefix(strings.ToUpper(ddl), "CREATE VIRTUAL TABLE") {
+			virtual = append(virtual, wipeTable{name: name, virtual: true})
+		} else {
+			ordinary = append(ordinary, wipeTable{name: name})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate tables: %w", err)
+	}
+
+	tables := virtual
+	for _, table := range ordinary {
+		if !isShadowTable(table.name, virtual) {
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
+
+func isShadowTable(name string, virtual []wipeTable) bool {
+	for _, table := range virtual {
+		if strings.HasPrefix(name, table.name+"_") {
+			return true
+		}
+	}
+	return false
+}
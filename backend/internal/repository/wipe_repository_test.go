@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository/testutil"
+)
+
+func TestWipeRepository_WipeAll(t *testing.T) {
+	t.Parallel()
+	db := testutil.NewTestDB(t)
+	repo := NewWipeRepository(db)
+	ctx := context.Background()
+
+	feedID := testutil.SeedFeed(t, db, model.Feed{Title: "Blog", URL: "https://example.com/feed"})
+	title := "Searchable entry"
+	testutil.SeedEntry(t, db, model.Entry{FeedID: feedID, Title: &title})
+	testutil.SeedSetting(t, db, "ai.provider", "openai")
+
+	deleted, err := repo.WipeAll(ctx)
+	if err != nil {
+		t.Fatalf("wipe: %v", err)
+	}
+	if deleted < 3 {
+		t.Errorf("expected at least 3 rows deleted, got %d", deleted)
+	}
+
+	for _, table := range []string{"feeds", "entries", "entries_fts", "settings"} {
+		var n int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM `+table).Scan(&n); err != nil {
+			t.Fatalf("count %s: %v", table, err)
+		}
+		if n != 0 {
+			t.Errorf("expected %s to be empty, got %d rows", table, n)
+		}
+	}
+
+	// The schema is kept and usable
+	testutil.SeedFeed(t, db, model.Feed{Title: "Again", URL: "https://example.com/feed"})
+}
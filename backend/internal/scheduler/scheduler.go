@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"log"
 	"sync"
 	"time"
@@ -9,25 +10,72 @@ import (
 	"gist/backend/internal/service"
 )
 
+// Job is a task started once at startup and then every Interval.
+type Job struct {
+	Kind     string
+	Interval time.Duration
+	Task     service.TaskFunc
+	// Skip, if set, is asked before each scheduled run; a non-empty reason
+	// skips that run.
+	Skip func(ctx context.Context) string
+}
+
+// JobStatus describes a registered job. The last run is the latest task of
+// the job's kind, whether the scheduler or a user started it.
+type JobStatus struct {
+	Kind            string     `json:"kind"`
+	IntervalSeconds int64      `json:"intervalSeconds"`
+	Paused          bool       `json:"paused"`
+	SkipReason      string     `json:"skipReason,omitempty"` // why scheduled runs are being skipped, e.g. low-data mode
+	NextRunAt       *time.Time `json:"nextRunAt,omitempty"`  // unset while paused
+	LastRun         *LastRun   `json:"lastRun,omitempty"`
+}
+
+// LastRun summarizes the latest task of a job's kind.
+type LastRun struct {
+	TaskID     string     `json:"taskId"`
+	Status     string     `json:"status"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	DurationMs *int64     `json:"durationMs,omitempty"` // unset while running
+	Error      string     `json:"error,omitempty"`
+}
+
+// jobState is the mutable schedule of a job.
+type jobState struct {
+	paused  bool
+	nextRun time.Time
+}
+
 type Scheduler struct {
-	refreshService service.RefreshService
-	interval       time.Duration
-	stopCh         chan struct{}
-	wg             sync.WaitGroup
+	tasks  service.TaskRunner
+	jobs   []Job
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	state map[string]*jobState
 }
 
-func New(refreshService service.RefreshService, interval time.Duration) *Scheduler {
+func New(tasks service.TaskRunner, jobs ...Job) *Scheduler {
+	state := make(map[string]*jobState, len(jobs))
+	for _, job := range jobs {
+		state[job.Kind] = &jobState{}
+	}
 	return &Scheduler{
-		refreshService: refreshService,
-		interval:       interval,
-		stopCh:         make(chan struct{}),
+		tasks:  tasks,
+		jobs:   jobs,
+		stopCh: make(chan struct{}),
+		state:  state,
 	}
 }
 
 func (s *Scheduler) Start() {
-	s.wg.Add(1)
-	go s.run()
-	log.Printf("scheduler started with interval %v", s.interval)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.run(job)
+		log.Printf("scheduler started %s with interval %v", job.Kind, job.Interval)
+	}
 }
 
 func (s *Scheduler) Stop() {
@@ -36,32 +84,125 @@ func (s *Scheduler) Stop() {
 	log.Println("scheduler stopped")
 }
 
-func (s *Scheduler) run() {
+// Jobs returns the status of every registered job, in registration order.
+func (s *Scheduler) Jobs(ctx context.Context) []JobStatus {
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, s.status(ctx, job))
+	}
+	return statuses
+}
+
+// Pause stops starting the job until Resume. A run already in progress is
+// not cancelled. Returns false if no job of kind is registered.
+func (s *Scheduler) Pause(ctx context.Context, kind string) (JobStatus, bool) {
+	return s.setPaused(ctx, kind, true)
+}
+
+// Resume restarts a paused job on its regular schedule. Pauses only last
+// until the server restarts.
+func (s *Scheduler) Resume(ctx context.Context, kind string) (JobStatus, bool) {
+	return s.setPaused(ctx, kind, false)
+}
+
+func (s *Scheduler) setPaused(ctx context.Context, kind string, paused bool) (JobStatus, bool) {
+	for _, job := range s.jobs {
+		if job.Kind != kind {
+			continue
+		}
+		s.mu.Lock()
+		if s.state[kind].paused != paused {
+			if paused {
+				log.Printf("scheduler paused %s", kind)
+			} else {
+				log.Printf("scheduler resumed %s", kind)
+			}
+		}
+		s.state[kind].paused = paused
+		s.mu.Unlock()
+		return s.status(ctx, job), true
+	}
+	return JobStatus{}, false
+}
+
+func (s *Scheduler) status(ctx context.Context, job Job) JobStatus {
+	status := JobStatus{Kind: job.Kind, IntervalSeconds: int64(job.Interval / time.Second)}
+	if job.Skip != nil {
+		status.SkipReason = job.Skip(ctx)
+	}
+
+	s.mu.Lock()
+	state := s.state[job.Kind]
+	status.Paused = state.paused
+	if !state.paused && !state.nextRun.IsZero() {
+		next := state.nextRun
+		status.NextRunAt = &next
+	}
+	s.mu.Unlock()
+
+	if task, ok := s.tasks.Latest(job.Kind); ok {
+		last := &LastRun{
+			TaskID:     task.ID,
+			Status:     task.Status,
+			StartedAt:  task.CreatedAt,
+			FinishedAt: task.FinishedAt,
+			Error:      task.Error,
+		}
+		if task.FinishedAt != nil {
+			ms := task.FinishedAt.Sub(task.CreatedAt).Milliseconds()
+			last.DurationMs = &ms
+		}
+		status.LastRun = last
+	}
+	return status
+}
+
+func (s *Scheduler) run(job Job) {
 	defer s.wg.Done()
 
 	// Run immediately on start
-	s.refresh()
+	s.start(job)
 
-	ticker := time.NewTicker(s.interval)
+	ticker := time.NewTicker(job.Interval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			s.refresh()
+			s.start(job)
 		case <-s.stopCh:
 			return
 		}
 	}
 }
 
-func (s *Scheduler) refresh() {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+// start runs the job as a task unless it is paused, its Skip gives a reason,
+// or one of its kind is already running, e.g. one triggered from the UI.
+func (s *Scheduler) start(job Job) {
+	s.mu.Lock()
+	state := s.state[job.Kind]
+	state.nextRun = time.Now().Add(job.Interval)
+	paused := state.paused
+	s.mu.Unlock()
 
-	log.Println("starting scheduled feed refresh")
-	if err := s.refreshService.RefreshAll(ctx); err != nil {
-		log.Printf("scheduled refresh error: %v", err)
+	if paused {
+		log.Printf("skipping scheduled %s: paused", job.Kind)
+		return
+	}
+	if job.Skip != nil {
+		if reason := job.Skip(context.Background()); reason != "" {
+			log.Printf("skipping scheduled %s: %s", job.Kind, reason)
+			return
+		}
+	}
+	task, err := s.tasks.Start(job.Kind, 0, job.Task)
+	if errors.Is(err, service.ErrTaskRunning) {
+		log.Printf("skipping scheduled %s: already running", job.Kind)
+		return
+	}
+	if err != nil {
+		log.Printf("scheduled %s error: %v", job.Kind, err)
+		return
 	}
-	log.Println("scheduled feed refresh completed")
+	log.Printf("started scheduled %s (task %s)", job.Kind, task.ID)
 }
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gist/backend/internal/service"
+)
+
+func TestScheduler_PauseSkipsRuns(t *testing.T) {
+	runner := service.NewTaskRunner(nil)
+	runs := 0
+	sched := New(runner, Job{Kind: service.TaskRefresh, Interval: time.Hour, Task: func(ctx context.Context, _ service.TaskHandle) (interface{}, error) {
+		runs++
+		return nil, nil
+	}})
+
+	if _, ok := sched.Pause(context.Background(), "unknown"); ok {
+		t.Error("pausing an unregistered job should fail")
+	}
+	status, ok := sched.Pause(context.Background(), service.TaskRefresh)
+	if !ok || !status.Paused {
+		t.Fatalf("expected paused job, got %+v", status)
+	}
+
+	sched.start(sched.jobs[0])
+	if _, ok := runner.Latest(service.TaskRefresh); ok {
+		t.Fatal("paused job should not start a task")
+	}
+	if jobs := sched.Jobs(context.Background()); len(jobs) != 1 || jobs[0].NextRunAt != nil {
+		t.Errorf("paused job should have no next run, got %+v", jobs)
+	}
+
+	if status, _ := sched.Resume(context.Background(), service.TaskRefresh); status.Paused || status.NextRunAt == nil {
+		t.Errorf("expected resumed job with a next run, got %+v", status)
+	}
+	sched.start(sched.jobs[0])
+
+	deadline := time.Now().Add(2 * time.Second)
+	for status = sched.Jobs(context.Background())[0]; status.LastRun == nil || status.LastRun.Status == service.TaskRunning; status = sched.Jobs(context.Background())[0] {
+		if time.Now().After(deadline) {
+			t.Fatal("scheduled task did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if runs != 1 || status.LastRun == nil || status.LastRun.Status != service.TaskDone || status.LastRun.DurationMs == nil {
+		t.Errorf("expected one finished run, got runs=%d status=%+v", runs, status.LastRun)
+	}
+}
+
+func TestScheduler_SkipReason(t *testing.T) {
+	runner := service.NewTaskRunner(nil)
+	reason := "low-data mode"
+	sched := New(runner, Job{
+		Kind:     service.TaskSync,
+		Interval: time.Hour,
+		Task: func(ctx context.Context, _ service.TaskHandle) (interface{}, error) {
+			return nil, nil
+		},
+		Skip: func(ctx context.Context) string { return reason },
+	})
+
+	sched.start(sched.jobs[0])
+	if _, ok := runner.Latest(service.TaskSync); ok {
+		t.Fatal("skipped job should not start a task")
+	}
+	if status := sched.Jobs(context.Background())[0]; status.SkipReason != reason {
+		t.Errorf("expected skip reason %q, got %+v", reason, status)
+	}
+
+	reason = ""
+	sched.start(sched.jobs[0])
+	if _, ok := runner.Latest(service.TaskSync); !ok {
+		t.Error("job should start once nothing holds it back")
+	}
+}
@@ -0,0 +1,125 @@
+// Package secret encrypts the small secrets Gist stores in its database, such
+// as feed credentials, with a key kept outside of it.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// KeyFileName is the key file generated inside the data directory on first
+// use. Database backups do not include it.
+const KeyFileName = "secret.key"
+
+const (
+	keySize     = 32 // AES-256
+	keyFilePerm = 0o600
+)
+
+// ErrInvalid is returned when a sealed value cannot be opened, as when it was
+// sealed with another key.
+var ErrInvalid = errors.New("secret: invalid sealed value")
+
+// Box seals and opens secrets with AES-256-GCM.
+type Box struct {
+	mu   sync.RWMutex
+	aead cipher.AEAD
+}
+
+// NewBox creates a box for a 32-byte key.
+func NewBox(key []byte) (*Box, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Rekey switches the box to a new key. Values sealed before can no longer
+// be opened.
+func (b *Box) Rekey(key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	b.aead = aead
+	b.mu.Unlock()
+	return nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != keySize {
+		return nil, fmt.Errorf("secret: key must be %d bytes, got %d", keySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// LoadOrCreateKey reads the hex-encoded key at path, generating and writing
+// a new one when the file does not exist.
+func LoadOrCreateKey(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("secret: %s does not hold a %d-byte hex key", path, keySize)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read secret key: %w", err)
+	}
+	return CreateKey(path)
+}
+
+// CreateKey generates a new key and writes it to path, replacing any key
+// already there.
+func CreateKey(path string) ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate secret key: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(hex.EncodeToString(key)+"\n"), keyFilePerm); err != nil {
+		return nil, fmt.Errorf("write secret key: %w", err)
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext, returning the nonce and ciphertext as base64.
+func (b *Box) Seal(plaintext []byte) (string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b.aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Open decrypts a value returned by Seal.
+func (b *Box) Open(sealed string) ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < b.aead.NonceSize() {
+		return nil, ErrInvalid
+	}
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	return plaintext, nil
+}
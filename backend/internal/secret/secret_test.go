@@ -0,0 +1,82 @@
+package secret
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBox(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KeyFileName)
+	key, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != keyFilePerm {
+		t.Fatalf("expected a %o key file, got %v, %v", keyFilePerm, info, err)
+	}
+	again, err := LoadOrCreateKey(path)
+	if err != nil || !bytes.Equal(key, again) {
+		t.Fatalf("expected the stored key to be read back, got %x, %v", again, err)
+	}
+
+	box, err := NewBox(key)
+	if err != nil {
+		t.Fatalf("new box: %v", err)
+	}
+	sealed, err := box.Seal([]byte("hunter2"))
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if other, _ := box.Seal([]byte("hunter2")); other == sealed {
+		t.Error("expected a fresh nonce per seal")
+	}
+	opened, err := box.Open(sealed)
+	if err != nil || string(opened) != "hunter2" {
+		t.Errorf("expected hunter2, got %q, %v", opened, err)
+	}
+
+	otherBox, _ := NewBox(bytes.Repeat([]byte{1}, keySize))
+	if _, err := otherBox.Open(sealed); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid with another key, got %v", err)
+	}
+	if _, err := box.Open("not base64!"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for garbage, got %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte("short"), keyFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadOrCreateKey(path); err == nil {
+		t.Error("expected an error for a malformed key file")
+	}
+}
+
+func TestBox_Rekey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), KeyFileName)
+	key, err := LoadOrCreateKey(path)
+	if err != nil {
+		t.Fatalf("create key: %v", err)
+	}
+	box, _ := NewBox(key)
+	sealed, _ := box.Seal([]byte("hunter2"))
+
+	newKey, err := CreateKey(path)
+	if err != nil || bytes.Equal(key, newKey) {
+		t.Fatalf("expected a new key, got %x, %v", newKey, err)
+	}
+	if stored, _ := LoadOrCreateKey(path); !bytes.Equal(stored, newKey) {
+		t.Error("expected the new key to replace the stored one")
+	}
+	if err := box.Rekey(newKey); err != nil {
+		t.Fatalf("rekey: %v", err)
+	}
+	if _, err := box.Open(sealed); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected values sealed with the old key to be unreadable, got %v", err)
+	}
+	if err := box.Rekey([]byte("short")); err == nil {
+		t.Error("expected an error for a short key")
+	}
+}
@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"encoding/base64"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
@@ -142,10 +143,22 @@ func (p *AnthropicProvider) SummarizeStream(ctx context.Context, systemPrompt, c
 
 // Complete generates a response without streaming.
 func (p *AnthropicProvider) Complete(ctx context.Context, systemPrompt, content string) (string, error) {
+	return p.complete(ctx, systemPrompt, anthropic.NewTextBlock(content))
+}
+
+// CompleteImage generates a response to a prompt about an image, sent inline as base64.
+func (p *AnthropicProvider) CompleteImage(ctx context.Context, systemPrompt, prompt string, image []byte, mediaType string) (string, error) {
+	return p.complete(ctx, systemPrompt,
+		anthropic.NewImageBlockBase64(mediaType, base64.StdEncoding.EncodeToString(image)),
+		anthropic.NewTextBlock(prompt),
+	)
+}
+
+func (p *AnthropicProvider) complete(ctx context.Context, systemPrompt string, blocks ...anthropic.ContentBlockParamUnion) (string, error) {
 	params := anthropic.MessageNewParams{
 		Model: anthropic.Model(p.model),
 		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(content)),
+			anthropic.NewUserMessage(blocks...),
 		},
 	}
 
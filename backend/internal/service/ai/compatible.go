@@ -150,11 +150,26 @@ func (p *CompatibleProvider) SummarizeStream(ctx context.Context, systemPrompt,
 
 // Complete generates a response without streaming.
 func (p *CompatibleProvider) Complete(ctx context.Context, systemPrompt, content string) (string, error) {
+	return p.complete(ctx, systemPrompt, openai.UserMessage(content))
+}
+
+// CompleteImage generates a response to a prompt about an image, sent inline as a data URL.
+func (p *CompatibleProvider) CompleteImage(ctx context.Context, systemPrompt, prompt string, image []byte, mediaType string) (string, error) {
+	return p.complete(ctx, systemPrompt, openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+		openai.TextContentPart(prompt),
+		openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+			URL:    imageDataURL(image, mediaType),
+			Detail: "low",
+		}),
+	}))
+}
+
+func (p *CompatibleProvider) complete(ctx context.Context, systemPrompt string, user openai.ChatCompletionMessageParamUnion) (string, error) {
 	messages := []openai.ChatCompletionMessageParamUnion{}
 	if systemPrompt != "" {
 		messages = append(messages, openai.SystemMessage(systemPrompt))
 	}
-	messages = append(messages, openai.UserMessage(content))
+	messages = append(messages, user)
 
 	params := openai.ChatCompletionNewParams{
 		Model:    openai.ChatModel(p.model),
@@ -131,11 +131,26 @@ func (p *OpenAIProvider) SummarizeStream(ctx context.Context, systemPrompt, cont
 
 // Complete generates a response without streaming.
 func (p *OpenAIProvider) Complete(ctx context.Context, systemPrompt, content string) (string, error) {
+	return p.complete(ctx, systemPrompt, openai.UserMessage(content))
+}
+
+// CompleteImage generates a response to a prompt about an image, sent inline as a data URL.
+func (p *OpenAIProvider) CompleteImage(ctx context.Context, systemPrompt, prompt string, image []byte, mediaType string) (string, error) {
+	return p.complete(ctx, systemPrompt, openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+		openai.TextContentPart(prompt),
+		openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+			URL:    imageDataURL(image, mediaType),
+			Detail: "low",
+		}),
+	}))
+}
+
+func (p *OpenAIProvider) complete(ctx context.Context, systemPrompt string, user openai.ChatCompletionMessageParamUnion) (string, error) {
 	messages := []openai.ChatCompletionMessageParamUnion{}
 	if systemPrompt != "" {
 		messages = append(messages, openai.SystemMessage(systemPrompt))
 	}
-	messages = append(messages, openai.UserMessage(content))
+	messages = append(messages, user)
 
 	params := openai.ChatCompletionNewParams{
 		Model:    openai.ChatModel(p.model),
@@ -2,6 +2,10 @@ package ai
 
 import "fmt"
 
+// SourceLanguage stands for the language of the article itself, for
+// summaries that are not translated.
+const SourceLanguage = "source"
+
 // languageNames maps language codes to human-readable names.
 var languageNames = map[string]string{
 	"zh-CN": "简体中文",
@@ -17,6 +21,8 @@ var languageNames = map[string]string{
 	"ru":    "Русский",
 	"ar":    "العربية",
 	"it":    "Italiano",
+
+	SourceLanguage: "the same language as the article",
 }
 
 // getLanguageName converts a language code to its human-readable name.
@@ -153,3 +159,22 @@ CRITICAL: You MUST translate into %s.
 This is MANDATORY. Any response not in %s will be rejected.
 </language_constraint>`, textType, textType, langName, langName, langName)
 }
+
+// NSFWImagePrompt is the system prompt for checking whether an image is not
+// safe for work. The answer starts with YES or NO.
+const NSFWImagePrompt = `<role>
+You are a content moderator. Your task is to decide whether an image is not safe for work.
+</role>
+
+<rules>
+- Answer YES for nudity, sexual content, or graphic violence or gore
+- Answer NO for everything else, including swimwear, art without nudity, and medical or news imagery without gore
+</rules>
+
+<output_format>
+- Output ONLY the word YES or NO
+- NO explanations or notes
+</output_format>`
+
+// NSFWImageQuestion is the user prompt sent with the image to check.
+const NSFWImageQuestion = "Is this image not safe for work?"
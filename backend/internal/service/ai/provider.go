@@ -2,6 +2,7 @@ package ai
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 )
 
@@ -17,6 +18,9 @@ type Provider interface {
 	SummarizeStream(ctx context.Context, systemPrompt, content string) (<-chan string, <-chan error)
 	// Complete generates a response without streaming.
 	Complete(ctx context.Context, systemPrompt, content string) (string, error)
+	// CompleteImage generates a response to a prompt about an image, which
+	// needs a model with vision support.
+	CompleteImage(ctx context.Context, systemPrompt, prompt string, image []byte, mediaType string) (string, error)
 }
 
 // Config holds the configuration for an AI provider.
@@ -67,3 +71,8 @@ func NewProvider(cfg Config) (Provider, error) {
 		return nil, ErrInvalidProvider
 	}
 }
+
+// imageDataURL encodes an image as a data URL for OpenAI-style image parts.
+func imageDataURL(image []byte, mediaType string) string {
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(image)
+}
@@ -2,7 +2,10 @@ package ai
 
 import (
 	"context"
+	"errors"
+	"math"
 	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
 )
@@ -10,6 +13,27 @@ import (
 // DefaultRateLimit is the default QPS limit.
 const DefaultRateLimit = 10
 
+// queueNotifyInterval is how often a waiting request reports its place in
+// the queue.
+const queueNotifyInterval = time.Second
+
+// QueueStatus is the place of a request held back by the rate limit.
+type QueueStatus struct {
+	// Position is how many requests go before this one, itself included.
+	Position int `json:"position"`
+	// WaitMs is the estimated wait in milliseconds.
+	WaitMs int64 `json:"waitMs"`
+}
+
+type queueNotifierKey struct{}
+
+// WithQueueNotifier returns a context whose requests call notify when the
+// rate limit holds them back, then about every second until they go. notify
+// must not block.
+func WithQueueNotifier(ctx context.Context, notify func(QueueStatus)) context.Context {
+	return context.WithValue(ctx, queueNotifierKey{}, notify)
+}
+
 // RateLimiter provides global rate limiting for AI API calls.
 type RateLimiter struct {
 	limiter *rate.Limiter
@@ -26,12 +50,62 @@ func NewRateLimiter(qps int) *RateLimiter {
 	}
 }
 
-// Wait blocks until a token is available or context is cancelled.
+// Wait blocks until a token is available or context is cancelled. A
+// cancelled wait gives its token back at once, moving those behind it up.
+// While it waits, the notifier of ctx (see WithQueueNotifier) is told the
+// request's place in the queue.
 func (r *RateLimiter) Wait(ctx context.Context) error {
 	r.mu.RLock()
 	limiter := r.limiter
 	r.mu.RUnlock()
-	return limiter.Wait(ctx)
+
+	reservation := limiter.Reserve()
+	if !reservation.OK() {
+		return limiter.Wait(ctx)
+	}
+	delay := reservation.Delay()
+	if delay <= 0 {
+		return nil
+	}
+	ready := time.Now().Add(delay)
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(ready) {
+		reservation.Cancel()
+		return errors.New("rate limit wait would exceed context deadline")
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	var tick <-chan time.Time
+	notify, _ := ctx.Value(queueNotifierKey{}).(func(QueueStatus))
+	if notify != nil {
+		notify(queueStatus(delay, limiter.Limit()))
+		ticker := time.NewTicker(queueNotifyInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-timer.C:
+			return nil
+		case <-tick:
+			if left := time.Until(ready); left > 0 {
+				notify(queueStatus(left, limiter.Limit()))
+			}
+		case <-ctx.Done():
+			reservation.Cancel()
+			return ctx.Err()
+		}
+	}
+}
+
+// queueStatus estimates the place of a request that can go after wait. Each
+// request before it needs a token, and tokens come at limit per second.
+func queueStatus(wait time.Duration, limit rate.Limit) QueueStatus {
+	position := int(math.Ceil(wait.Seconds() * float64(limit)))
+	if position < 1 {
+		position = 1
+	}
+	return QueueStatus{Position: position, WaitMs: wait.Milliseconds()}
 }
 
 // SetLimit updates the rate limit dynamically.
@@ -0,0 +1,59 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_WaitReportsQueue(t *testing.T) {
+	limiter := NewRateLimiter(2)
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("wait within burst: %v", err)
+		}
+	}
+
+	var reports []QueueStatus
+	start := time.Now()
+	err := limiter.Wait(WithQueueNotifier(ctx, func(status QueueStatus) {
+		reports = append(reports, status)
+	}))
+	if err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if len(reports) == 0 {
+		t.Fatal("expected the wait to be reported")
+	}
+	if reports[0].Position != 1 || reports[0].WaitMs <= 0 || reports[0].WaitMs > 500 {
+		t.Errorf("unexpected queue status %+v", reports[0])
+	}
+	if waited := time.Since(start); waited < 300*time.Millisecond {
+		t.Errorf("expected to wait for a token, waited %v", waited)
+	}
+}
+
+func TestRateLimiter_CancelFreesSlot(t *testing.T) {
+	limiter := NewRateLimiter(1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("wait within burst: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := limiter.Wait(WithQueueNotifier(ctx, func(QueueStatus) { cancel() }))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the cancelled wait to end, got %v", err)
+	}
+
+	// The cancelled request's token goes to the next one, which would
+	// otherwise wait two seconds
+	start := time.Now()
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("wait: %v", err)
+	}
+	if waited := time.Since(start); waited > 1500*time.Millisecond {
+		t.Errorf("expected the freed slot to be used, waited %v", waited)
+	}
+}
@@ -46,7 +46,8 @@ type AIService interface {
 	// GetCachedSummary returns a cached summary if available.
 	GetCachedSummary(ctx context.Context, entryID int64, isReadability bool) (*model.AISummary, error)
 	// Summarize generates a summary using AI streaming.
-	// Returns channels for text chunks and errors.
+	// Returns channels for text chunks and errors. The wait for the rate
+	// limit happens in the stream, so the call returns at once.
 	Summarize(ctx context.Context, entryID int64, content, title string, isReadability bool) (<-chan string, <-chan error, error)
 	// SaveSummary saves a summary to cache.
 	SaveSummary(ctx context.Context, entryID int64, isReadability bool, summary string) error
@@ -63,6 +64,9 @@ type AIService interface {
 	// TranslateBatch translates multiple articles' titles and summaries.
 	// Returns a channel of results and an error channel.
 	TranslateBatch(ctx context.Context, articles []BatchArticleInput) (<-chan BatchTranslateResult, <-chan error, error)
+	// IsImageNSFW asks the AI provider whether an image is not safe for work.
+	// The configured model must support images.
+	IsImageNSFW(ctx context.Context, image []byte, mediaType string) (bool, error)
 	// ClearAllCache deletes all AI cache data (summaries, translations, list translations).
 	// Returns the number of deleted records for each type.
 	ClearAllCache(ctx context.Context) (summaries, translations, listTranslations int64, err error)
@@ -73,6 +77,8 @@ type aiService struct {
 	translationRepo     repository.AITranslationRepository
 	listTranslationRepo repository.AIListTranslationRepository
 	settingsRepo        repository.SettingsRepository
+	entries             repository.EntryRepository
+	feeds               repository.FeedRepository
 	rateLimiter         *ai.RateLimiter
 }
 
@@ -82,6 +88,8 @@ func NewAIService(
 	translationRepo repository.AITranslationRepository,
 	listTranslationRepo repository.AIListTranslationRepository,
 	settingsRepo repository.SettingsRepository,
+	entries repository.EntryRepository,
+	feeds repository.FeedRepository,
 	rateLimiter *ai.RateLimiter,
 ) AIService {
 	return &aiService{
@@ -89,12 +97,14 @@ func NewAIService(
 		translationRepo:     translationRepo,
 		listTranslationRepo: listTranslationRepo,
 		settingsRepo:        settingsRepo,
+		entries:             entries,
+		feeds:               feeds,
 		rateLimiter:         rateLimiter,
 	}
 }
 
 func (s *aiService) GetCachedSummary(ctx context.Context, entryID int64, isReadability bool) (*model.AISummary, error) {
-	language := s.GetSummaryLanguage(ctx)
+	language := s.entrySummaryLanguage(ctx, entryID)
 	return s.summaryRepo.Get(ctx, entryID, isReadability, language)
 }
 
@@ -111,13 +121,8 @@ func (s *aiService) Summarize(ctx context.Context, entryID int64, content, title
 		return nil, nil, fmt.Errorf("create provider: %w", err)
 	}
 
-	// Wait for rate limiter
-	if err := s.rateLimiter.Wait(ctx); err != nil {
-		return nil, nil, fmt.Errorf("rate limit: %w", err)
-	}
-
 	// Get language setting
-	language := s.GetSummaryLanguage(ctx)
+	language := s.entrySummaryLanguage(ctx, entryID)
 
 	// Build system prompt
 	systemPrompt := ai.GetSummarizePrompt(title, language)
@@ -125,14 +130,36 @@ func (s *aiService) Summarize(ctx context.Context, entryID int64, content, title
 	// Convert HTML to plain text to save tokens
 	plainText := ai.HTMLToText(content)
 
-	// Start streaming
-	textCh, errCh := provider.SummarizeStream(ctx, systemPrompt, plainText)
+	// Wait for the rate limiter in the stream, so the caller can report the
+	// request's place in the queue meanwhile
+	textOut := make(chan string)
+	errOut := make(chan error, 1)
+	go func() {
+		defer close(textOut)
+		defer close(errOut)
 
-	return textCh, errCh, nil
+		if err := s.rateLimiter.Wait(ctx); err != nil {
+			errOut <- fmt.Errorf("rate limit: %w", err)
+			return
+		}
+		textCh, errCh := provider.SummarizeStream(ctx, systemPrompt, plainText)
+		for text := range textCh {
+			select {
+			case textOut <- text:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := <-errCh; err != nil {
+			errOut <- err
+		}
+	}()
+
+	return textOut, errOut, nil
 }
 
 func (s *aiService) SaveSummary(ctx context.Context, entryID int64, isReadability bool, summary string) error {
-	language := s.GetSummaryLanguage(ctx)
+	language := s.entrySummaryLanguage(ctx, entryID)
 	return s.summaryRepo.Save(ctx, entryID, isReadability, language, summary)
 }
 
@@ -144,6 +171,20 @@ func (s *aiService) GetSummaryLanguage(ctx context.Context) string {
 	return setting.Value
 }
 
+// entrySummaryLanguage is the language to summarize an entry in: the
+// article's own when its feed asks for that, the configured one otherwise.
+// Summaries are cached per language, so the two never mix.
+func (s *aiService) entrySummaryLanguage(ctx context.Context, entryID int64) string {
+	if s.entries != nil && s.feeds != nil {
+		if entry, err := s.entries.GetByID(ctx, entryID); err == nil {
+			if feed, err := s.feeds.GetByID(ctx, entry.FeedID); err == nil && feed.SummaryInSourceLanguage {
+				return ai.SourceLanguage
+			}
+		}
+	}
+	return s.GetSummaryLanguage(ctx)
+}
+
 func (s *aiService) getAIConfig(ctx context.Context) (ai.Config, error) {
 	var cfg ai.Config
 
@@ -539,6 +580,34 @@ func (s *aiService) TranslateBatch(ctx context.Context, articles []BatchArticleI
 	return resultCh, errCh, nil
 }
 
+func (s *aiService) IsImageNSFW(ctx context.Context, image []byte, mediaType string) (bool, error) {
+	cfg, err := s.getAIConfig(ctx)
+	if err != nil {
+		return false, err
+	}
+	provider, err := ai.NewProvider(cfg)
+	if err != nil {
+		return false, fmt.Errorf("create provider: %w", err)
+	}
+	if err := s.rateLimiter.Wait(ctx); err != nil {
+		return false, fmt.Errorf("rate limit: %w", err)
+	}
+
+	answer, err := provider.CompleteImage(ctx, ai.NSFWImagePrompt, ai.NSFWImageQuestion, image, mediaType)
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToUpper(strings.TrimSpace(answer))
+	switch {
+	case strings.HasPrefix(answer, "YES"):
+		return true, nil
+	case strings.HasPrefix(answer, "NO"):
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected answer %q", answer)
+	}
+}
+
 func parseEntryID(id string) (int64, error) {
 	var entryID int64
 	_, err := fmt.Sscanf(id, "%d", &entryID)
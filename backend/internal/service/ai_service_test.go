@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/ai"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestAIService_SummaryLanguagePerFeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	settings := &memorySettings{values: map[string]string{keyAISummaryLanguage: "en-US"}}
+	service := NewAIService(nil, nil, nil, settings, mockEntries, mockFeeds, nil).(*aiService)
+	ctx := context.Background()
+
+	mockEntries.EXPECT().GetByID(ctx, int64(1)).Return(model.Entry{ID: 1, FeedID: 10}, nil)
+	mockFeeds.EXPECT().GetByID(ctx, int64(10)).Return(model.Feed{ID: 10, SummaryInSourceLanguage: true}, nil)
+	if got := service.entrySummaryLanguage(ctx, 1); got != ai.SourceLanguage {
+		t.Errorf("expected the source language, got %q", got)
+	}
+
+	mockEntries.EXPECT().GetByID(ctx, int64(2)).Return(model.Entry{ID: 2, FeedID: 20}, nil)
+	mockFeeds.EXPECT().GetByID(ctx, int64(20)).Return(model.Feed{ID: 20}, nil)
+	if got := service.entrySummaryLanguage(ctx, 2); got != "en-US" {
+		t.Errorf("expected the global language, got %q", got)
+	}
+
+	if prompt := ai.GetSummarizePrompt("", ai.SourceLanguage); !strings.Contains(prompt, "in the same language as the article") {
+		t.Errorf("expected the prompt to ask for the article's language, got:\n%s", prompt)
+	}
+}
@@ -121,7 +121,10 @@ func (s *Solver) SolveFromBody(ctx context.Context, body []byte, originalURL str
 	log.Printf("anubis: detected challenge for %s (difficulty=%d)", originalURL, challenge.Rules.Difficulty)
 
 	// Solve the challenge
-	result := solveChallenge(challenge)
+	result, err := solveChallenge(ctx, challenge)
+	if err != nil {
+		return "", err
+	}
 
 	// Submit the solution (pass initial cookies for session)
 	cookie, expiresAt, err := s.submit(ctx, originalURL, challenge.Challenge.ID, result, initialCookies)
@@ -164,16 +167,21 @@ func parseChallenge(body []byte) (*Challenge, error) {
 }
 
 // solveChallenge computes the solution for the Anubis challenge
-func solveChallenge(challenge *Challenge) string {
+func solveChallenge(ctx context.Context, challenge *Challenge) (string, error) {
 	// 1. Compute sha256 of randomData
 	hash := sha256.Sum256([]byte(challenge.Challenge.RandomData))
 	result := hex.EncodeToString(hash[:])
 
 	// 2. Wait for the required time (add 100ms buffer for safety)
 	waitTime := time.Duration(challenge.Rules.Difficulty)*125*time.Millisecond + 100*time.Millisecond
-	time.Sleep(waitTime)
-
-	return result
+	timer := time.NewTimer(waitTime)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return result, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
 }
 
 // submit sends the solution to Anubis and retrieves the cookie
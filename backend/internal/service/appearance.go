@@ -0,0 +1,69 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Themes of the appearance settings
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+	ThemeAuto  = "auto" // follows the system of each device
+)
+
+// Views entry lists can be shown in
+const (
+	ViewList    = "list"
+	ViewMasonry = "masonry" // a grid of pictures
+)
+
+// Font sizes of entry content, in CSS pixels
+const (
+	DefaultFontSize = 16
+	MinFontSize     = 12
+	MaxFontSize     = 24
+)
+
+// ContentTypes are the content types of feeds and folders.
+var ContentTypes = []string{"article", "picture", "notification", "podcast"}
+
+// defaultViews is the view of each content type when none was chosen.
+var defaultViews = map[string]string{
+	"article":      ViewList,
+	"picture":      ViewMasonry,
+	"notification": ViewList,
+	"podcast":      ViewList,
+}
+
+// accentColor matches a hex color such as #3b82f6.
+var accentColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// IsAccentColor reports whether value is a #rrggbb color.
+func IsAccentColor(value string) bool {
+	return accentColor.MatchString(value)
+}
+
+// validateAppearance checks settings before they are stored.
+func validateAppearance(settings *AppearanceSettings) error {
+	switch settings.Theme {
+	case ThemeLight, ThemeDark, ThemeAuto:
+	default:
+		return fmt.Errorf("%w: unknown theme %q", ErrInvalid, settings.Theme)
+	}
+	if settings.AccentColor != "" && !IsAccentColor(settings.AccentColor) {
+		return fmt.Errorf("%w: accent color must be #rrggbb", ErrInvalid)
+	}
+	if settings.FontSize < MinFontSize || settings.FontSize > MaxFontSize {
+		return fmt.Errorf("%w: font size must be %d to %d", ErrInvalid, MinFontSize, MaxFontSize)
+	}
+	for contentType, view := range settings.DefaultViews {
+		if _, ok := defaultViews[contentType]; !ok {
+			return fmt.Errorf("%w: unknown content type %q", ErrInvalid, contentType)
+		}
+		if view != ViewList && view != ViewMasonry {
+			return fmt.Errorf("%w: unknown view %q", ErrInvalid, view)
+		}
+	}
+	return nil
+}
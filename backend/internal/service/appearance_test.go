@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAppearanceSettings(t *testing.T) {
+	ctx := context.Background()
+	settings := NewSettingsService(&memorySettings{values: map[string]string{}}, nil)
+
+	appearance, err := settings.GetAppearanceSettings(ctx)
+	if err != nil {
+		t.Fatalf("get appearance: %v", err)
+	}
+	if appearance.Theme != ThemeAuto || appearance.FontSize != DefaultFontSize || appearance.DefaultViews["picture"] != ViewMasonry || appearance.DefaultViews["article"] != ViewList {
+		t.Fatalf("unexpected defaults: %+v", appearance)
+	}
+
+	// Views not given are kept
+	appearance.Theme = ThemeDark
+	appearance.AccentColor = "#3b82f6"
+	appearance.FontSize = 18
+	appearance.DefaultViews = map[string]string{"article": ViewMasonry}
+	if err := settings.SetAppearanceSettings(ctx, appearance); err != nil {
+		t.Fatalf("set appearance: %v", err)
+	}
+	stored, err := settings.GetAppearanceSettings(ctx)
+	if err != nil {
+		t.Fatalf("get appearance: %v", err)
+	}
+	if stored.Theme != ThemeDark || stored.AccentColor != "#3b82f6" || stored.FontSize != 18 {
+		t.Errorf("unexpected settings: %+v", stored)
+	}
+	if stored.DefaultViews["article"] != ViewMasonry || stored.DefaultViews["picture"] != ViewMasonry || stored.DefaultViews["notification"] != ViewList {
+		t.Errorf("unexpected views: %v", stored.DefaultViews)
+	}
+
+	for _, invalid := range []AppearanceSettings{
+		{Theme: "sepia", FontSize: 16},
+		{Theme: ThemeLight, AccentColor: "blue", FontSize: 16},
+		{Theme: ThemeLight, FontSize: 40},
+		{Theme: ThemeLight, FontSize: 16, DefaultViews: map[string]string{"video": ViewList}},
+		{Theme: ThemeLight, FontSize: 16, DefaultViews: map[string]string{"article": "cards"}},
+	} {
+		if err := settings.SetAppearanceSettings(ctx, &invalid); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected ErrInvalid for %+v, got %v", invalid, err)
+		}
+	}
+}
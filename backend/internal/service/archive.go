@@ -0,0 +1,31 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// Archive groupings
+const (
+	ArchiveByMonth = "month"
+	ArchiveByYear  = "year"
+)
+
+// archiveBucketLen is the length of the published_at prefix naming a period.
+var archiveBucketLen = map[string]int{
+	ArchiveByYear:  len("2006"),
+	ArchiveByMonth: len("2006-01"),
+}
+
+// PeriodBounds returns the half-open published_at range [from, before) of a
+// year ("2025") or month ("2025-03") period. Bounds are date prefixes, which
+// order correctly against the stored UTC timestamps.
+func PeriodBounds(period string) (from, before string, err error) {
+	if t, err := time.Parse("2006-01", period); err == nil {
+		return period, t.AddDate(0, 1, 0).Format("2006-01"), nil
+	}
+	if t, err := time.Parse("2006", period); err == nil {
+		return period, t.AddDate(1, 0, 0).Format("2006"), nil
+	}
+	return "", "", fmt.Errorf("%w: period must be YYYY or YYYY-MM", ErrInvalid)
+}
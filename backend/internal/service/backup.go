@@ -0,0 +1,244 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+const (
+	// BackupCheckInterval is how often the backup job checks whether a
+	// backup is due.
+	BackupCheckInterval = time.Hour
+	// DefaultBackupKeep is how many backups are kept when not set.
+	DefaultBackupKeep = 7
+	// MaxBackupKeep bounds how many backups can be kept.
+	MaxBackupKeep = 100
+	// MaxBackupInterval bounds the hours between scheduled backups.
+	MaxBackupInterval = 30 * 24
+)
+
+// backupTimeLayout names backups by when they were written, in UTC, so
+// their names sort by age.
+const backupTimeLayout = "20060102-150405"
+
+// backupName matches the names of backups, and only those, so no other
+// file of the directory is listed, rotated or served.
+var backupName = regexp.MustCompile(`^gist-backup-(\d{8}-\d{6})\.zip$`)
+
+// Files inside a backup
+const (
+	backupOPMLFile    = "subscriptions.opml"
+	backupStarredFile = "starred.jsonl"
+)
+
+// BackupFile is a backup in the backups directory.
+type BackupFile struct {
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// BackupService writes backups of the subscriptions and starred entries:
+// a zip of the OPML export and the JSON Lines export of starred entries.
+// Backups are written on the interval set in GeneralSettings, keeping the
+// newest BackupKeep.
+type BackupService interface {
+	// Create writes a backup now, then deletes the oldest past BackupKeep.
+	Create(ctx context.Context) (BackupFile, error)
+	// CreateIfDue writes a backup when backups are on and the latest is at
+	// least BackupInterval old. Returns nil when none was due.
+	CreateIfDue(ctx context.Context) (*BackupFile, error)
+	// List returns the backups, newest first.
+	List(ctx context.Context) ([]BackupFile, error)
+	// Path returns where the named backup is stored, or ErrNotFound.
+	Path(name string) (string, error)
+}
+
+type backupService struct {
+	dir      string
+	settings SettingsService
+	opml     OPMLService
+	export   ExportService
+	now      func() time.Time
+}
+
+func NewBackupService(dir string, settings SettingsService, opml OPMLService, export ExportService) BackupService {
+	return &backupService{dir: dir, settings: settings, opml: opml, export: export, now: time.Now}
+}
+
+func (s *backupService) Create(ctx context.Context) (BackupFile, error) {
+	general, err := s.settings.GetGeneralSettings(ctx)
+	if err != nil {
+		return BackupFile{}, fmt.Errorf("get backup settings: %w", err)
+	}
+	backup, err := s.write(ctx)
+	if err != nil {
+		return BackupFile{}, err
+	}
+	if err := s.rotate(ctx, general.BackupKeep); err != nil {
+		return backup, err
+	}
+	log.Printf("backup: wrote %s (%d bytes)", backup.Name, backup.Size)
+	return backup, nil
+}
+
+func (s *backupService) CreateIfDue(ctx context.Context) (*BackupFile, error) {
+	general, err := s.settings.GetGeneralSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get backup settings: %w", err)
+	}
+	if general.BackupInterval == 0 {
+		return nil, nil
+	}
+	backups, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	interval := time.Duration(general.BackupInterval) * time.Hour
+	if len(backups) > 0 && s.now().Sub(backups[0].CreatedAt) < interval {
+		return nil, nil
+	}
+	backup, err := s.Create(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+func (s *backupService) List(ctx context.Context) ([]BackupFile, error) {
+	files, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []BackupFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read backups directory: %w", err)
+	}
+	backups := make([]BackupFile, 0, len(files))
+	for _, file := range files {
+		match := backupName.FindStringSubmatch(file.Name())
+		if match == nil || !file.Type().IsRegular() {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimeLayout, match[1])
+		if err != nil {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, BackupFile{Name: file.Name(), Size: info.Size(), CreatedAt: createdAt})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name > backups[j].Name })
+	return backups, nil
+}
+
+func (s *backupService) Path(name string) (string, error) {
+	if !backupName.MatchString(name) {
+		return "", ErrNotFound
+	}
+	path := filepath.Join(s.dir, name)
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return "", ErrNotFound
+	}
+	return path, nil
+}
+
+// write writes a backup under a temporary name and renames it into place
+// once complete, so a failed or interrupted backup is never listed.
+func (s *backupService) write(ctx context.Context) (BackupFile, error) {
+	createdAt := s.now().UTC().Truncate(time.Second)
+	name := "gist-backup-" + createdAt.Format(backupTimeLayout) + ".zip"
+
+	subscriptions, err := s.opml.Export(ctx)
+	if err != nil {
+		return BackupFile{}, fmt.Errorf("export subscriptions: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return BackupFile{}, fmt.Errorf("create backups directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, ".backup-*")
+	if err != nil {
+		return BackupFile{}, fmt.Errorf("create backup: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	zw := zip.NewWriter(tmp)
+	if err := writeBackupFile(zw, backupOPMLFile, createdAt, func(w io.Writer) error {
+		_, err := w.Write(subscriptions)
+		return err
+	}); err != nil {
+		return BackupFile{}, err
+	}
+	if err := writeBackupFile(zw, backupStarredFile, createdAt, func(w io.Writer) error {
+		_, err := s.export.ExportStarred(ctx, w, ExportJSON)
+		return err
+	}); err != nil {
+		return BackupFile{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return BackupFile{}, fmt.Errorf("write backup: %w", err)
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return BackupFile{}, fmt.Errorf("write backup: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return BackupFile{}, fmt.Errorf("write backup: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		return BackupFile{}, fmt.Errorf("store backup: %w", err)
+	}
+	return BackupFile{Name: name, Size: size, CreatedAt: createdAt}, nil
+}
+
+func writeBackupFile(zw *zip.Writer, name string, modified time.Time, write func(io.Writer) error) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return fmt.Errorf("add %s to backup: %w", name, err)
+	}
+	if err := write(w); err != nil {
+		return fmt.Errorf("write %s to backup: %w", name, err)
+	}
+	return nil
+}
+
+// rotate deletes the oldest backups past the newest keep.
+func (s *backupService) rotate(ctx context.Context, keep int) error {
+	backups, err := s.List(ctx)
+	if err != nil {
+		return err
+	}
+	if keep <= 0 {
+		keep = DefaultBackupKeep
+	}
+	for _, old := range backups[min(keep, len(backups)):] {
+		if err := os.Remove(filepath.Join(s.dir, old.Name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("delete old backup %s: %w", old.Name, err)
+		}
+	}
+	return nil
+}
+
+// BackupDueTask runs CreateIfDue as a task.
+func BackupDueTask(backups BackupService) TaskFunc {
+	return func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		backup, err := backups.CreateIfDue(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return backup, nil
+	}
+}
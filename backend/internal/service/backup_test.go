@@ -0,0 +1,134 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type fakeOPML struct {
+	OPMLService
+}
+
+func (fakeOPML) Export(context.Context) ([]byte, error) {
+	return []byte("<opml/>"), nil
+}
+
+type fakeExport struct {
+	ExportService
+}
+
+func (fakeExport) ExportStarred(_ context.Context, w io.Writer, format string) (int, error) {
+	_, err := io.WriteString(w, `{"id":"1","title":"Starred"}`+"\n")
+	return 1, err
+}
+
+func TestBackupService_CreateAndRotate(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "backups")
+	settings := NewSettingsService(&memorySettings{values: map[string]string{}}, nil)
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	general.BackupKeep = 2
+	if err := settings.SetGeneralSettings(ctx, general); err != nil {
+		t.Fatalf("set settings: %v", err)
+	}
+
+	svc := NewBackupService(dir, settings, fakeOPML{}, fakeExport{}).(*backupService)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	// Backups are off until an interval is set
+	if backup, err := svc.CreateIfDue(ctx); err != nil || backup != nil {
+		t.Fatalf("expected no backup while off, got %+v, %v", backup, err)
+	}
+
+	var names []string
+	for i := 0; i < 3; i++ {
+		backup, err := svc.Create(ctx)
+		if err != nil {
+			t.Fatalf("create backup: %v", err)
+		}
+		names = append(names, backup.Name)
+		now = now.Add(time.Hour)
+	}
+
+	backups, err := svc.List(ctx)
+	if err != nil {
+		t.Fatalf("list backups: %v", err)
+	}
+	if len(backups) != 2 || backups[0].Name != names[2] || backups[1].Name != names[1] {
+		t.Fatalf("expected the newest two backups, got %+v", backups)
+	}
+	if _, err := svc.Path(names[0]); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected the oldest backup to be rotated out, got %v", err)
+	}
+	if _, err := svc.Path("../gist.db"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a name outside the backups, got %v", err)
+	}
+
+	path, err := svc.Path(names[2])
+	if err != nil {
+		t.Fatalf("path: %v", err)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open backup: %v", err)
+	}
+	defer zr.Close()
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		contents[f.Name] = string(data)
+	}
+	if contents[backupOPMLFile] != "<opml/>" || contents[backupStarredFile] != `{"id":"1","title":"Starred"}`+"\n" {
+		t.Errorf("unexpected backup contents: %v", contents)
+	}
+
+	// No temporary files are left behind
+	files, _ := os.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("expected only the two backups in the directory, got %d files", len(files))
+	}
+}
+
+func TestBackupService_CreateIfDue(t *testing.T) {
+	ctx := context.Background()
+	settings := NewSettingsService(&memorySettings{values: map[string]string{}}, nil)
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	general.BackupInterval = 24
+	if err := settings.SetGeneralSettings(ctx, general); err != nil {
+		t.Fatalf("set settings: %v", err)
+	}
+
+	svc := NewBackupService(t.TempDir(), settings, fakeOPML{}, fakeExport{}).(*backupService)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	if backup, err := svc.CreateIfDue(ctx); err != nil || backup == nil {
+		t.Fatalf("expected a first backup, got %+v, %v", backup, err)
+	}
+	now = now.Add(23 * time.Hour)
+	if backup, err := svc.CreateIfDue(ctx); err != nil || backup != nil {
+		t.Fatalf("expected no backup before the interval passed, got %+v, %v", backup, err)
+	}
+	now = now.Add(time.Hour)
+	if backup, err := svc.CreateIfDue(ctx); err != nil || backup == nil {
+		t.Fatalf("expected a backup once the interval passed, got %+v, %v", backup, err)
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"gist/backend/internal/repository"
+)
+
+const (
+	// BandwidthRetentionDays is how many days of totals are kept.
+	BandwidthRetentionDays = 90
+	// bandwidthDayFormat is the local date the totals are kept by.
+	bandwidthDayFormat = "2006-01-02"
+)
+
+// BandwidthMeter adds up the bytes downloaded for each feed per day: the
+// feed itself, its sitemap pages, and the images and pages fetched for its
+// entries in the background, so users on metered connections can see which
+// subscriptions cost the most. Fetches learn their feed from the context, see
+// withBandwidth; images the browser loads through the proxy belong to no feed
+// and are not counted.
+type BandwidthMeter struct {
+	repo repository.BandwidthRepository
+	mu   sync.Mutex
+	// prunedDay is the day old totals were last dropped on
+	prunedDay string
+}
+
+// FeedBandwidthUsage is what a feed downloaded over a range of days.
+type FeedBandwidthUsage struct {
+	FeedID int64               `json:"feedId,string"`
+	Title  string              `json:"title"`
+	Bytes  int64               `json:"bytes"`
+	Days   []DayBandwidthUsage `json:"days"` // oldest first, days without downloads left out
+}
+
+type DayBandwidthUsage struct {
+	Day   string `json:"day"` // YYYY-MM-DD, server local time
+	Bytes int64  `json:"bytes"`
+}
+
+// BandwidthUsage reports downloads from Since (a local date) through today.
+type BandwidthUsage struct {
+	Since      string               `json:"since"`
+	TotalBytes int64                `json:"totalBytes"`
+	Feeds      []FeedBandwidthUsage `json:"feeds"` // most bytes first
+}
+
+func NewBandwidthMeter(repo repository.BandwidthRepository) *BandwidthMeter {
+	return &BandwidthMeter{repo: repo}
+}
+
+// Record counts bytes downloaded for a feed today. Failures are only logged:
+// accounting never fails a fetch.
+func (m *BandwidthMeter) Record(ctx context.Context, feedID int64, bytes int64) {
+	if m == nil || feedID == 0 || bytes <= 0 {
+		return
+	}
+	// A fetch cut short by its context still used the bandwidth
+	ctx = context.WithoutCancel(ctx)
+	now := time.Now()
+	day := now.Format(bandwidthDayFormat)
+	if err := m.repo.Add(ctx, feedID, day, bytes); err != nil {
+		log.Printf("record bandwidth of feed %d: %v", feedID, err)
+	}
+
+	m.mu.Lock()
+	prune := m.prunedDay != day
+	m.prunedDay = day
+	m.mu.Unlock()
+	if prune {
+		if err := m.repo.DeleteBefore(ctx, now.AddDate(0, 0, -BandwidthRetentionDays).Format(bandwidthDayFormat)); err != nil {
+			log.Printf("prune bandwidth: %v", err)
+		}
+	}
+}
+
+// Usage returns what each feed downloaded over the last days, today included.
+func (m *BandwidthMeter) Usage(ctx context.Context, days int) (BandwidthUsage, error) {
+	since := time.Now().AddDate(0, 0, 1-days).Format(bandwidthDayFormat)
+	rows, err := m.repo.ListSince(ctx, since)
+	if err != nil {
+		return BandwidthUsage{}, err
+	}
+
+	usage := BandwidthUsage{Since: since, Feeds: []FeedBandwidthUsage{}}
+	index := make(map[int64]int)
+	for _, row := range rows {
+		i, ok := index[row.FeedID]
+		if !ok {
+			i = len(usage.Feeds)
+			index[row.FeedID] = i
+			usage.Feeds = append(usage.Feeds, FeedBandwidthUsage{FeedID: row.FeedID, Title: row.FeedTitle})
+		}
+		feed := &usage.Feeds[i]
+		feed.Bytes += row.Bytes
+		feed.Days = append(feed.Days, DayBandwidthUsage{Day: row.Day, Bytes: row.Bytes})
+		usage.TotalBytes += row.Bytes
+	}
+	sort.SliceStable(usage.Feeds, func(i, j int) bool { return usage.Feeds[i].Bytes > usage.Feeds[j].Bytes })
+	return usage, nil
+}
+
+type bandwidthKey struct{}
+
+// bandwidthScope is who the downloads made with a context are counted for.
+type bandwidthScope struct {
+	meter  *BandwidthMeter
+	feedID int64
+}
+
+// withBandwidth counts the downloads made with ctx for the feed; a zero
+// feedID only carries the meter, for withBandwidthFeed to pick a feed later.
+func withBandwidth(ctx context.Context, meter *BandwidthMeter, feedID int64) context.Context {
+	if meter == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, bandwidthKey{}, bandwidthScope{meter: meter, feedID: feedID})
+}
+
+// withBandwidthFeed moves the downloads made with ctx over to another feed,
+// if ctx carries a meter.
+func withBandwidthFeed(ctx context.Context, feedID int64) context.Context {
+	scope, ok := ctx.Value(bandwidthKey{}).(bandwidthScope)
+	if !ok {
+		return ctx
+	}
+	return withBandwidth(ctx, scope.meter, feedID)
+}
+
+// recordBandwidth counts bytes downloaded with ctx, if it names a feed.
+func recordBandwidth(ctx context.Context, bytes int64) {
+	if scope, ok := ctx.Value(bandwidthKey{}).(bandwidthScope); ok {
+		scope.meter.Record(ctx, scope.feedID, bytes)
+	}
+}
+
+// MeteredTransport counts the response bodies read through it towards the
+// feed the request's context names.
+type MeteredTransport struct {
+	base http.RoundTripper
+}
+
+func NewMeteredTransport(base http.RoundTripper) *MeteredTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &MeteredTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *MeteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.Body == nil {
+		return resp, err
+	}
+	if _, ok := req.Context().Value(bandwidthKey{}).(bandwidthScope); ok {
+		resp.Body = &meteredBody{ReadCloser: resp.Body, ctx: req.Context()}
+	}
+	return resp, nil
+}
+
+// meteredBody records the bytes read once the body is closed.
+type meteredBody struct {
+	io.ReadCloser
+	ctx  context.Context
+	read int64
+	once sync.Once
+}
+
+func (b *meteredBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	return n, err
+}
+
+func (b *meteredBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { recordBandwidth(b.ctx, b.read) })
+	return err
+}
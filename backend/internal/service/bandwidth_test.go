@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+)
+
+func TestMeteredTransport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	body := strings.Repeat("x", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	repo := testutil.NewMockBandwidthRepository(ctrl)
+	meter := NewBandwidthMeter(repo)
+	client := &http.Client{Transport: NewMeteredTransport(nil)}
+	today := time.Now().Format(bandwidthDayFormat)
+
+	repo.EXPECT().Add(gomock.Any(), int64(7), today, int64(len(body))).Return(nil)
+	repo.EXPECT().DeleteBefore(gomock.Any(), gomock.Any()).Return(nil)
+
+	fetch := func(ctx context.Context) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("fetch: %v", err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	// Counted only once a feed is named
+	fetch(context.Background())
+	fetch(withBandwidth(context.Background(), meter, 0))
+	fetch(withBandwidthFeed(withBandwidth(context.Background(), meter, 0), 7))
+}
+
+func TestBandwidthMeter_Usage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := testutil.NewMockBandwidthRepository(ctrl)
+	meter := NewBandwidthMeter(repo)
+	since := time.Now().AddDate(0, 0, -6).Format(bandwidthDayFormat)
+
+	repo.EXPECT().ListSince(gomock.Any(), since).Return([]model.FeedBandwidth{
+		{FeedID: 1, FeedTitle: "Blog", Day: "2026-03-01", Bytes: 100},
+		{FeedID: 2, FeedTitle: "Photos", Day: "2026-03-01", Bytes: 3000},
+		{FeedID: 1, FeedTitle: "Blog", Day: "2026-03-02", Bytes: 200},
+	}, nil)
+
+	usage, err := meter.Usage(context.Background(), 7)
+	if err != nil {
+		t.Fatalf("usage: %v", err)
+	}
+	if usage.Since != since || usage.TotalBytes != 3300 || len(usage.Feeds) != 2 {
+		t.Fatalf("unexpected usage %+v", usage)
+	}
+	if photos := usage.Feeds[0]; photos.FeedID != 2 || photos.Bytes != 3000 {
+		t.Errorf("expected the heaviest feed first, got %+v", photos)
+	}
+	if blog := usage.Feeds[1]; blog.Bytes != 300 || len(blog.Days) != 2 || blog.Days[1].Day != "2026-03-02" {
+		t.Errorf("unexpected daily breakdown %+v", blog)
+	}
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"strings"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/printview"
+	"gist/backend/internal/service/ai"
+)
+
+// Formats entry content can be served in
+const (
+	ContentFormatHTML  = "html"  // the sanitized HTML as stored
+	ContentFormatText  = "text"  // plain text, a line per paragraph
+	ContentFormatClean = "clean" // simplified HTML without media, embeds or styles
+)
+
+// ContentFormats are the formats entry endpoints accept.
+var ContentFormats = []string{ContentFormatHTML, ContentFormatText, ContentFormatClean}
+
+// setContentFormats derives the text and clean versions of an entry's
+// content, after it was sanitized.
+func setContentFormats(entry *model.Entry) {
+	entry.ContentText, entry.ContentClean = nil, nil
+	if trimmedValue(entry.Content) == "" {
+		return
+	}
+	text := FormatContent(*entry.Content, trimmedValue(entry.URL), ContentFormatText)
+	clean := FormatContent(*entry.Content, trimmedValue(entry.URL), ContentFormatClean)
+	entry.ContentText, entry.ContentClean = &text, &clean
+}
+
+// FormatContent converts sanitized HTML to format, resolving links of clean
+// HTML against baseURL. Clean HTML that cannot be parsed falls back to text.
+func FormatContent(content, baseURL, format string) string {
+	switch format {
+	case ContentFormatText:
+		return ai.HTMLToText(content)
+	case ContentFormatClean:
+		clean, err := printview.Clean(content, baseURL)
+		if err != nil {
+			return ai.HTMLToText(content)
+		}
+		return strings.TrimSpace(clean)
+	default:
+		return content
+	}
+}
+
+// FormatEntry replaces the content and readable content of an entry with
+// their versions in format. The content uses the versions derived at ingest
+// when it has them; readable content, extracted on demand, is converted now.
+func FormatEntry(entry *model.Entry, format string) {
+	if format != ContentFormatText && format != ContentFormatClean {
+		return
+	}
+	base := trimmedValue(entry.URL)
+	if entry.Content != nil {
+		stored := entry.ContentText
+		if format == ContentFormatClean {
+			stored = entry.ContentClean
+		}
+		if stored == nil {
+			converted := FormatContent(*entry.Content, base, format)
+			stored = &converted
+		}
+		entry.Content = stored
+	}
+	if entry.ReadableContent != nil {
+		converted := FormatContent(*entry.ReadableContent, base, format)
+		entry.ReadableContent = &converted
+	}
+}
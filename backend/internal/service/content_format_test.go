@@ -0,0 +1,59 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"gist/backend/internal/model"
+)
+
+func TestFormatContent(t *testing.T) {
+	content := `<h2>Heading</h2><p class="lead">First <a href="/more">paragraph</a>.</p>` +
+		`<iframe src="https://www.youtube.com/embed/abc"></iframe><p><img src="/a.png" alt="A"></p>`
+
+	if got := FormatContent(content, "https://example.com/post", ContentFormatHTML); got != content {
+		t.Errorf("html format changed the content: %q", got)
+	}
+
+	if text := FormatContent(content, "https://example.com/post", ContentFormatText); text != "Heading\n\nFirst paragraph ." {
+		t.Errorf("expected plain text with a line per block, got %q", text)
+	}
+
+	clean := FormatContent(content, "https://example.com/post", ContentFormatClean)
+	for _, want := range []string{`<h2>Heading</h2>`, `href="https://example.com/more"`, `src="https://example.com/a.png"`} {
+		if !strings.Contains(clean, want) {
+			t.Errorf("expected %q in clean HTML %q", want, clean)
+		}
+	}
+	for _, unwanted := range []string{"iframe", "class"} {
+		if strings.Contains(clean, unwanted) {
+			t.Errorf("expected %q removed from clean HTML %q", unwanted, clean)
+		}
+	}
+}
+
+func TestFormatEntry(t *testing.T) {
+	url := "https://example.com/post"
+	content := "<p>Feed <b>text</b></p>"
+	entry := model.Entry{URL: &url, Content: &content}
+	setContentFormats(&entry)
+	if entry.ContentText == nil || *entry.ContentText != "Feed text" {
+		t.Fatalf("expected the text version derived, got %v", entry.ContentText)
+	}
+
+	// The versions made at ingest are served as they are
+	stored := "Stored text"
+	readable := "<p>Readable</p>"
+	entry.ContentText, entry.ReadableContent = &stored, &readable
+	FormatEntry(&entry, ContentFormatText)
+	if *entry.Content != stored || *entry.ReadableContent != "Readable" {
+		t.Errorf("expected the stored text and converted readable content, got %q, %q", *entry.Content, *entry.ReadableContent)
+	}
+
+	// Entries stored before have theirs converted on request
+	old := model.Entry{URL: &url, Content: &content}
+	FormatEntry(&old, ContentFormatClean)
+	if *old.Content != "<p>Feed <b>text</b></p>" {
+		t.Errorf("expected clean HTML converted on request, got %q", *old.Content)
+	}
+}
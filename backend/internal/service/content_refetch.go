@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+// MaxContentRefetchWindow bounds the content refetch window of the general
+// settings, in hours.
+const MaxContentRefetchWindow = 7 * 24
+
+// contentRefetchPerRefresh is how many pages one refresh of a feed fetches
+// for entries missing content. Pages that never yield any are tried again on
+// every refresh until the window passes, so this keeps the cost bounded.
+const contentRefetchPerRefresh = 3
+
+// refetchMissingContent fills the content of a feed's recent entries that
+// have none from their pages, for feeds whose items carry only a title until
+// the article is written. Failures are only logged; the entry is tried again
+// on the next refresh while it is within the window.
+func (s *refreshService) refetchMissingContent(ctx context.Context, feed model.Feed) {
+	if s.readability == nil || s.settings == nil || feed.Type == pictureFeedType {
+		return
+	}
+	general, err := s.settings.GetGeneralSettings(ctx)
+	if err != nil || general.ContentRefetchWindow <= 0 {
+		return
+	}
+	since := time.Now().Add(-time.Duration(general.ContentRefetchWindow) * time.Hour)
+	entries, err := s.entries.ListMissingContent(ctx, feed.ID, since, contentRefetchPerRefresh)
+	if err != nil {
+		log.Printf("list entries of feed %d missing content: %v", feed.ID, err)
+		return
+	}
+
+	filled := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		page, err := s.readability.ExtractPage(ctx, *entry.URL)
+		if err != nil {
+			log.Printf("refetch content of %s: %v", *entry.URL, err)
+			continue
+		}
+		if strings.TrimSpace(page.Content) == "" {
+			continue
+		}
+		snippet := makeSnippet(page.Content)
+		entry.Content, entry.Snippet = &page.Content, &snippet
+		if page.ImageURL != "" {
+			entry.ThumbnailURL = &page.ImageURL
+		}
+		setContentFormats(&entry)
+		ok, err := s.entries.FillContent(ctx, entry)
+		if err != nil {
+			log.Printf("refetch content of %s: %v", *entry.URL, err)
+			continue
+		}
+		if ok {
+			filled++
+		}
+	}
+	if filled > 0 {
+		log.Printf("feed %d (%s): content of %d entries refetched", feed.ID, feed.Title, filled)
+	}
+}
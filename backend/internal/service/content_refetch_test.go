@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestRefreshService_RefetchMissingContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	settings := NewSettingsService(&memorySettings{values: map[string]string{}}, nil)
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	general.ContentRefetchWindow = 24
+	if err := settings.SetGeneralSettings(ctx, general); err != nil {
+		t.Fatalf("set settings: %v", err)
+	}
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	extractor := &fakeExtractor{page: &ReadablePage{Content: "<p>The article</p>", ImageURL: "https://example.com/a.png"}}
+	service := NewRefreshService(nil, mockEntries, settings, nil, nil, nil, nil, nil, nil, nil, nil, extractor, nil, nil, nil, nil, config.RefreshFixed).(*refreshService)
+
+	url := "https://example.com/a"
+	mockEntries.EXPECT().ListMissingContent(ctx, int64(1), gomock.Any(), contentRefetchPerRefresh).
+		Return([]model.Entry{{ID: 5, FeedID: 1, URL: &url}}, nil)
+	mockEntries.EXPECT().FillContent(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) (bool, error) {
+		if entry.ID != 5 || entry.Content == nil || *entry.Content != "<p>The article</p>" {
+			t.Errorf("expected the page content for entry 5, got %d %v", entry.ID, entry.Content)
+		}
+		if entry.Snippet == nil || *entry.Snippet != "The article" || entry.ContentText == nil || *entry.ContentText != "The article" {
+			t.Errorf("expected the snippet and text version, got %v %v", entry.Snippet, entry.ContentText)
+		}
+		if entry.ThumbnailURL == nil || *entry.ThumbnailURL != "https://example.com/a.png" {
+			t.Errorf("expected the page image as thumbnail, got %v", entry.ThumbnailURL)
+		}
+		return true, nil
+	})
+
+	service.refetchMissingContent(ctx, model.Feed{ID: 1, Title: "Blog", Type: "article"})
+	if !extractor.fetched {
+		t.Error("expected the page fetched")
+	}
+}
+
+func TestRefreshService_RefetchMissingContentOff(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// No window and picture feeds fetch nothing; the mock fails on any call
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	extractor := &fakeExtractor{err: errors.New("unexpected fetch")}
+	settings := NewSettingsService(&memorySettings{values: map[string]string{}}, nil)
+	service := NewRefreshService(nil, mockEntries, settings, nil, nil, nil, nil, nil, nil, nil, nil, extractor, nil, nil, nil, nil, config.RefreshFixed).(*refreshService)
+
+	service.refetchMissingContent(context.Background(), model.Feed{ID: 1, Type: "article"})
+	if extractor.fetched {
+		t.Error("expected no fetch with re-fetching off")
+	}
+}
+
+func TestRefreshService_SaveEntry_KeepsFetchedContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewRefreshService(nil, mockEntries, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed).(*refreshService)
+	ctx := context.Background()
+
+	title, url, content := "Launch", "https://example.com/launch", "<p>Fetched from the page</p>"
+	stored := model.Entry{ID: 3, FeedID: 1, Title: &title, URL: &url, Content: &content}
+	mockEntries.EXPECT().GetByURL(ctx, int64(1), url).Return(stored, nil)
+	mockEntries.EXPECT().CreateOrUpdate(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		if entry.Content == nil || *entry.Content != content {
+			t.Errorf("expected the fetched content kept, got %v", entry.Content)
+		}
+		if entry.Snippet == nil || *entry.Snippet != "Fetched from the page" {
+			t.Errorf("expected its snippet, got %v", entry.Snippet)
+		}
+		return nil
+	})
+
+	if _, err := service.saveEntry(ctx, model.Entry{FeedID: 1, Title: &title, URL: &url}, futureDates{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
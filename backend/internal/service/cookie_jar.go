@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gist/backend/internal/repository"
+)
+
+// cookieKeyPrefix prefixes the settings keys holding the cookies stored for
+// each host, e.g. "cookies.example.com".
+const cookieKeyPrefix = "cookies."
+
+// CookieJar keeps the cookies feed hosts set and sends them back on later
+// fetches, across redirects and restarts, for the hosts allowed in
+// GeneralSettings.CookieHosts. Other hosts get no cookies at all, as with no
+// jar. Cookies are persisted per host in settings.
+type CookieJar struct {
+	settings repository.SettingsRepository
+	mu       sync.Mutex
+	jar      *cookiejar.Jar
+	// loaded holds the hosts whose stored cookies are in jar
+	loaded map[string]bool
+}
+
+// storedCookie is a cookie as persisted; Expires is zero for a session cookie.
+type storedCookie struct {
+	Name    string    `json:"name"`
+	Value   string    `json:"value"`
+	Domain  string    `json:"domain,omitempty"`
+	Path    string    `json:"path"`
+	Expires time.Time `json:"expires,omitempty"`
+	Secure  bool      `json:"secure,omitempty"`
+}
+
+func NewCookieJar(settings repository.SettingsRepository) *CookieJar {
+	// cookiejar.New only fails on options it is not given
+	jar, _ := cookiejar.New(nil)
+	return &CookieJar{settings: settings, jar: jar, loaded: make(map[string]bool)}
+}
+
+// Cookies implements http.CookieJar.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	ctx := context.Background()
+	host := u.Hostname()
+	if !j.allowed(ctx, host) {
+		return nil
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.load(ctx, host)
+	return j.jar.Cookies(u)
+}
+
+// SetCookies implements http.CookieJar.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	ctx := context.Background()
+	host := u.Hostname()
+	if len(cookies) == 0 || !j.allowed(ctx, host) {
+		return
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.load(ctx, host)
+	j.jar.SetCookies(u, cookies)
+
+	stored := j.stored(ctx, host)
+	now := time.Now()
+	for _, c := range cookies {
+		next := storedCookie{Name: c.Name, Value: c.Value, Domain: c.Domain, Path: c.Path, Expires: c.Expires, Secure: c.Secure}
+		if next.Path == "" || !strings.HasPrefix(next.Path, "/") {
+			next.Path = defaultCookiePath(u.Path)
+		}
+		if c.MaxAge > 0 {
+			next.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		}
+		removed := c.MaxAge < 0 || (!next.Expires.IsZero() && !next.Expires.After(now))
+
+		kept := stored[:0]
+		for _, old := range stored {
+			if old.Name != next.Name || old.Domain != next.Domain || old.Path != next.Path {
+				kept = append(kept, old)
+			}
+		}
+		stored = kept
+		if !removed {
+			stored = append(stored, next)
+		}
+	}
+	j.save(ctx, host, stored)
+}
+
+// allowed reports whether host or one of its parent domains is in the
+// allowlist.
+func (j *CookieJar) allowed(ctx context.Context, host string) bool {
+	if j.settings == nil || host == "" {
+		return false
+	}
+	setting, err := j.settings.Get(ctx, keyCookieHosts)
+	if err != nil || setting == nil {
+		return false
+	}
+	return cookieHostAllowed(parseCookieHosts(setting.Value), host)
+}
+
+// load adds the stored cookies of host to the jar once. Callers hold mu.
+func (j *CookieJar) load(ctx context.Context, host string) {
+	if j.loaded[host] {
+		return
+	}
+	j.loaded[host] = true
+	now := time.Now()
+	for _, c := range j.stored(ctx, host) {
+		if !c.Expires.IsZero() && !c.Expires.After(now) {
+			continue
+		}
+		scheme := "http"
+		if c.Secure {
+			scheme = "https"
+		}
+		j.jar.SetCookies(&url.URL{Scheme: scheme, Host: host, Path: c.Path}, []*http.Cookie{{
+			Name:    c.Name,
+			Value:   c.Value,
+			Domain:  c.Domain,
+			Path:    c.Path,
+			Expires: c.Expires,
+			Secure:  c.Secure,
+		}})
+	}
+}
+
+func (j *CookieJar) stored(ctx context.Context, host string) []storedCookie {
+	setting, err := j.settings.Get(ctx, cookieKeyPrefix+host)
+	if err != nil || setting == nil {
+		return nil
+	}
+	var cookies []storedCookie
+	if err := json.Unmarshal([]byte(setting.Value), &cookies); err != nil {
+		log.Printf("cookies of %s: %v", host, err)
+		return nil
+	}
+	return cookies
+}
+
+func (j *CookieJar) save(ctx context.Context, host string, cookies []storedCookie) {
+	var err error
+	if len(cookies) == 0 {
+		err = j.settings.Delete(ctx, cookieKeyPrefix+host)
+	} else {
+		var data []byte
+		if data, err = json.Marshal(cookies); err == nil {
+			err = j.settings.Set(ctx, cookieKeyPrefix+host, string(data))
+		}
+	}
+	if err != nil {
+		log.Printf("store cookies of %s: %v", host, err)
+	}
+}
+
+// defaultCookiePath is the path of a cookie set without one (RFC 6265 5.1.4).
+func defaultCookiePath(requestPath string) string {
+	i := strings.LastIndex(requestPath, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return requestPath[:i]
+}
+
+// parseCookieHosts splits the allowlist on commas and line breaks.
+func parseCookieHosts(raw string) []string {
+	var hosts []string
+	for _, field := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '，' || r == '\n' || r == ' ' }) {
+		if host := strings.Trim(strings.ToLower(strings.TrimSpace(field)), "."); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// cookieHostAllowed reports whether host is one of hosts or a subdomain of one.
+func cookieHostAllowed(hosts []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range hosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
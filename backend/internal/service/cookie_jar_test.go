@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+// memorySettings is a settings repository kept in a map.
+type memorySettings struct {
+	repository.SettingsRepository
+	values map[string]string
+}
+
+func (m *memorySettings) Get(_ context.Context, key string) (*model.Setting, error) {
+	value, ok := m.values[key]
+	if !ok {
+		return nil, nil
+	}
+	return &model.Setting{Key: key, Value: value}, nil
+}
+
+func (m *memorySettings) Set(_ context.Context, key, value string) error {
+	m.values[key] = value
+	return nil
+}
+
+func (m *memorySettings) GetByPrefix(_ context.Context, prefix string) ([]model.Setting, error) {
+	var settings []model.Setting
+	for key, value := range m.values {
+		if strings.HasPrefix(key, prefix) {
+			settings = append(settings, model.Setting{Key: key, Value: value})
+		}
+	}
+	return settings, nil
+}
+
+func (m *memorySettings) Delete(_ context.Context, key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func TestCookieJar_SessionAfterRedirect(t *testing.T) {
+	// The feed sets a session cookie and redirects back to itself
+	redirects := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			redirects++
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/", MaxAge: 3600})
+			http.Redirect(w, r, "/feed", http.StatusFound)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	settings := &memorySettings{values: map[string]string{keyCookieHosts: "example.com\n127.0.0.1"}}
+	client := &http.Client{Jar: NewCookieJar(settings)}
+	resp, err := client.Get(server.URL + "/feed")
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || redirects != 1 {
+		t.Fatalf("expected the feed after one redirect, got HTTP %d after %d", resp.StatusCode, redirects)
+	}
+	if !strings.Contains(settings.values[cookieKeyPrefix+"127.0.0.1"], `"abc"`) {
+		t.Fatalf("expected the session to be stored, got %v", settings.values)
+	}
+
+	// After a restart the stored session is sent straight away
+	client = &http.Client{Jar: NewCookieJar(settings)}
+	resp, err = client.Get(server.URL + "/feed")
+	if err != nil {
+		t.Fatalf("fetch again: %v", err)
+	}
+	resp.Body.Close()
+	if redirects != 1 {
+		t.Errorf("expected the stored session to be reused, got %d redirects", redirects)
+	}
+
+	// Hosts off the list keep no cookies, and taking a host off forgets them
+	service := NewSettingsService(settings, nil)
+	general, err := service.GetGeneralSettings(context.Background())
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	general.CookieHosts = "example.com"
+	if err := service.SetGeneralSettings(context.Background(), general); err != nil {
+		t.Fatalf("set settings: %v", err)
+	}
+	if _, ok := settings.values[cookieKeyPrefix+"127.0.0.1"]; ok {
+		t.Error("expected the stored cookies of a host taken off the list to be deleted")
+	}
+	client = &http.Client{Jar: NewCookieJar(settings)}
+	if _, err := client.Get(server.URL + "/feed"); err == nil {
+		t.Error("expected the redirect loop of a host off the list to fail")
+	}
+	if _, ok := settings.values[cookieKeyPrefix+"127.0.0.1"]; ok {
+		t.Error("expected no cookies stored for a host off the list")
+	}
+}
+
+func TestCookieHostAllowed(t *testing.T) {
+	hosts := parseCookieHosts(" Example.com, .news.org\nfoo.net ")
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"example.com", true},
+		{"feeds.example.com", true},
+		{"news.org", true},
+		{"FOO.net", true},
+		{"badexample.com", false},
+		{"example.com.evil.net", false},
+	}
+	for _, tt := range tests {
+		if got := cookieHostAllowed(hosts, tt.host); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
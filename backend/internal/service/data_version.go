@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+
+	"gist/backend/internal/repository"
+)
+
+// Tables that read-heavy responses are built from, for DataVersionService.
+const (
+	VersionEntries   = "entries"
+	VersionFeeds     = "feeds"
+	VersionFolders   = "folders"
+	VersionEntryTags = "entry_tags"
+)
+
+// DataVersionService tells whether the data behind a response changed, so
+// polling clients can be answered with 304 Not Modified.
+type DataVersionService interface {
+	// Version returns a value that changes whenever a row of one of the
+	// tables changes. It is cheap next to building the response: a few
+	// index lookups and counts.
+	Version(ctx context.Context, tables ...string) (string, error)
+}
+
+type dataVersionService struct {
+	repo repository.DataVersionRepository
+}
+
+func NewDataVersionService(repo repository.DataVersionRepository) DataVersionService {
+	return &dataVersionService{repo: repo}
+}
+
+func (s *dataVersionService) Version(ctx context.Context, tables ...string) (string, error) {
+	return s.repo.Version(ctx, tables...)
+}
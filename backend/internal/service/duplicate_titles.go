@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"gist/backend/internal/repository"
+)
+
+// Duplicate title modes of the general settings
+const (
+	DuplicateTitlesOff   = "off"
+	DuplicateTitlesExact = "exact" // same title, ignoring case and spacing
+	DuplicateTitlesFuzzy = "fuzzy" // nearly the same letters and digits
+)
+
+// Duplicate title window bounds, in hours
+const (
+	DefaultDuplicateTitleWindow = 48
+	MaxDuplicateTitleWindow     = 30 * 24
+)
+
+// fuzzyTitleSimilarity is how alike two titles must be, by the Dice
+// coefficient of their character bigrams, for fuzzy mode to collapse them.
+const fuzzyTitleSimilarity = 0.8
+
+// titleCollapser tells whether a new entry of a feed repeats the title of
+// one already seen within the window. A nil collapser collapses nothing.
+type titleCollapser struct {
+	fuzzy bool
+	exact map[string]bool
+	grams []titleBigrams
+}
+
+type titleBigrams struct {
+	counts map[string]int
+	total  int
+}
+
+// duplicateTitles loads the collapser of a feed for a refresh, seeded with
+// the titles of its entries within the window. Failures are logged and leave
+// the feed without one, like unreadable filters.
+func duplicateTitles(ctx context.Context, settings SettingsService, entries repository.EntryRepository, feedID int64) *titleCollapser {
+	if settings == nil {
+		return nil
+	}
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil || (general.DuplicateTitles != DuplicateTitlesExact && general.DuplicateTitles != DuplicateTitlesFuzzy) {
+		return nil
+	}
+	since := time.Now().Add(-time.Duration(general.DuplicateTitleWindow) * time.Hour)
+	titles, err := entries.ListTitlesSince(ctx, feedID, since)
+	if err != nil {
+		log.Printf("load recent titles of feed %d: %v", feedID, err)
+		return nil
+	}
+	c := newTitleCollapser(general.DuplicateTitles)
+	for _, title := range titles {
+		c.collapse(title)
+	}
+	return c
+}
+
+func newTitleCollapser(mode string) *titleCollapser {
+	return &titleCollapser{fuzzy: mode == DuplicateTitlesFuzzy, exact: make(map[string]bool)}
+}
+
+// collapse reports whether title repeats one seen before, remembering it
+// when it does not. Empty titles are never collapsed.
+func (c *titleCollapser) collapse(title string) bool {
+	if c == nil {
+		return false
+	}
+	key := strings.Join(strings.Fields(strings.ToLower(title)), " ")
+	if c.fuzzy {
+		key = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, key)
+	}
+	if key == "" {
+		return false
+	}
+	if c.exact[key] {
+		return true
+	}
+	c.exact[key] = true
+	if !c.fuzzy {
+		return false
+	}
+
+	grams := bigramsOf(key)
+	for _, seen := range c.grams {
+		if grams.similarity(seen) >= fuzzyTitleSimilarity {
+			return true
+		}
+	}
+	c.grams = append(c.grams, grams)
+	return false
+}
+
+func bigramsOf(s string) titleBigrams {
+	runes := []rune(s)
+	grams := titleBigrams{counts: make(map[string]int)}
+	for i := 0; i+1 < len(runes); i++ {
+		grams.counts[string(runes[i:i+2])]++
+		grams.total++
+	}
+	return grams
+}
+
+// similarity is the Dice coefficient of two bigram multisets, from 0 for
+// nothing shared to 1 for the same.
+func (a titleBigrams) similarity(b titleBigrams) float64 {
+	if a.total == 0 || b.total == 0 {
+		return 0
+	}
+	shared := 0
+	for gram, count := range a.counts {
+		shared += min(count, b.counts[gram])
+	}
+	return 2 * float64(shared) / float64(a.total+b.total)
+}
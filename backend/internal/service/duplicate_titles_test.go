@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestTitleCollapser(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		titles []string
+		want   []bool
+	}{
+		{"exact ignores case and spacing", DuplicateTitlesExact,
+			[]string{"Weekly  Digest", "weekly digest", "Weekly digest!"}, []bool{false, true, false}},
+		{"fuzzy ignores punctuation", DuplicateTitlesFuzzy,
+			[]string{"Weekly digest", "Weekly digest!"}, []bool{false, true}},
+		{"fuzzy small edit", DuplicateTitlesFuzzy,
+			[]string{"Go 1.25 released with new garbage collector", "Go 1.25 released, with a new garbage collector"}, []bool{false, true}},
+		{"fuzzy different", DuplicateTitlesFuzzy,
+			[]string{"Go 1.25 released", "Rust 1.90 released"}, []bool{false, false}},
+		{"fuzzy without spaces", DuplicateTitlesFuzzy,
+			[]string{"苹果发布新款笔记本电脑", "苹果发布新款笔记本电脑！"}, []bool{false, true}},
+		{"empty never collapses", DuplicateTitlesExact,
+			[]string{"", " "}, []bool{false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTitleCollapser(tt.mode)
+			for i, title := range tt.titles {
+				if got := c.collapse(title); got != tt.want[i] {
+					t.Errorf("collapse(%q) = %v, want %v", title, got, tt.want[i])
+				}
+			}
+		})
+	}
+
+	var off *titleCollapser
+	if off.collapse("anything") {
+		t.Error("a nil collapser must not collapse")
+	}
+}
+
+func TestRefreshService_CollapsesDuplicateTitles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>News</title>
+<item><title>Storm hits the coast</title><link>https://example.com/1</link></item>
+<item><title>Storm hits the coast!</title><link>https://example.com/2</link></item>
+<item><title>Markets close higher</title><link>https://example.com/3</link></item>
+<item><title>Markets close higher</title><link>https://example.com/4</link></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	settings := NewSettingsService(&memorySettings{values: map[string]string{}}, nil)
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	general.DuplicateTitles = DuplicateTitlesFuzzy
+	if err := settings.SetGeneralSettings(ctx, general); err != nil {
+		t.Fatalf("set settings: %v", err)
+	}
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	metrics := NewFetchMetrics()
+	service := NewRefreshService(mockFeeds, mockEntries, settings, nil, server.Client(), nil, metrics, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed)
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "News", URL: server.URL}, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	mockEntries.EXPECT().ListTitlesSince(gomock.Any(), int64(1), gomock.Any()).Return([]string{"Markets close higher"}, nil)
+	// The already stored item is updated; of the new ones only the first
+	// storm story is kept
+	knownURL, knownTitle := "https://example.com/3", "Markets close higher"
+	known := model.Entry{ID: 3, URL: &knownURL, Title: &knownTitle}
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), knownURL).Return(known, nil).Times(2)
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), gomock.Any()).Return(model.Entry{}, sql.ErrNoRows).AnyTimes()
+	var stored []string
+	mockEntries.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		stored = append(stored, *entry.URL)
+		return nil
+	}).Times(2)
+
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if len(stored) != 2 || stored[0] != "https://example.com/1" || stored[1] != "https://example.com/3" {
+		t.Errorf("expected entries 1 and 3 stored, got %v", stored)
+	}
+	if snapshot := metrics.Snapshot(10); len(snapshot.Feeds) != 1 || snapshot.Feeds[0].Collapsed != 2 {
+		t.Errorf("expected 2 collapsed entries in the metrics, got %+v", snapshot.Feeds)
+	}
+}
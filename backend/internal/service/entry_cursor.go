@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+// ParseEntryCursor reads a list cursor of the form "publishedAt,id", where
+// publishedAt is RFC 3339 and left empty for an entry without a publish date.
+func ParseEntryCursor(raw string) (repository.EntryCursor, error) {
+	i := strings.LastIndexByte(raw, ',')
+	if i < 0 {
+		return repository.EntryCursor{}, fmt.Errorf("%w: cursor must be publishedAt,id", ErrInvalid)
+	}
+	id, err := strconv.ParseInt(raw[i+1:], 10, 64)
+	if err != nil {
+		return repository.EntryCursor{}, fmt.Errorf("%w: cursor must end with an entry ID", ErrInvalid)
+	}
+	cursor := repository.EntryCursor{ID: id}
+	if published := raw[:i]; published != "" {
+		t, err := time.Parse(time.RFC3339Nano, published)
+		if err != nil {
+			return repository.EntryCursor{}, fmt.Errorf("%w: cursor must start with an RFC 3339 time", ErrInvalid)
+		}
+		cursor.PublishedAt = &t
+	}
+	return cursor, nil
+}
+
+// EntryCursorOf is the cursor listing continues from after entry.
+func EntryCursorOf(entry model.EntrySummary) string {
+	id := strconv.FormatInt(entry.ID, 10)
+	if entry.PublishedAt == nil {
+		return "," + id
+	}
+	return entry.PublishedAt.UTC().Format(time.RFC3339Nano) + "," + id
+}
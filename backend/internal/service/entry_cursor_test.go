@@ -0,0 +1,33 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+func TestEntryCursor(t *testing.T) {
+	published := time.Date(2025, 3, 1, 12, 30, 0, 500, time.FixedZone("CET", 3600))
+	for _, entry := range []model.EntrySummary{{ID: 42, PublishedAt: &published}, {ID: 7}} {
+		raw := EntryCursorOf(entry)
+		cursor, err := ParseEntryCursor(raw)
+		if err != nil {
+			t.Fatalf("parse %q: %v", raw, err)
+		}
+		if cursor.ID != entry.ID || (cursor.PublishedAt == nil) != (entry.PublishedAt == nil) ||
+			(cursor.PublishedAt != nil && !cursor.PublishedAt.Equal(*entry.PublishedAt)) {
+			t.Errorf("cursor %q parsed to %+v, want entry %d at %v", raw, cursor, entry.ID, entry.PublishedAt)
+		}
+	}
+	if got := EntryCursorOf(model.EntrySummary{ID: 42, PublishedAt: &published}); got != "2025-03-01T11:30:00.0000005Z,42" {
+		t.Errorf("unexpected cursor %q", got)
+	}
+
+	for _, raw := range []string{"", "42", "2025-03-01,42", "2025-03-01T12:00:00Z,x"} {
+		if _, err := ParseEntryCursor(raw); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: expected ErrInvalid, got %v", raw, err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/ai"
+)
+
+const (
+	// maxRevisionChanges is how many changed passages a revision keeps.
+	maxRevisionChanges = 5
+	// maxChangeWords truncates each side of a kept passage.
+	maxChangeWords = 40
+	// maxDiffCells bounds the word-by-word comparison of the part between the
+	// common prefix and suffix; beyond it that part counts as one replacement.
+	maxDiffCells = 1 << 20
+)
+
+// diffEntry summarizes how the publisher changed an entry from before to
+// after. It reports false when the title and text are unchanged, so markup
+// churn alone does not count as an update.
+func diffEntry(before, after model.Entry, now time.Time) (model.EntryRevision, bool) {
+	rev := model.EntryRevision{ChangedAt: now}
+
+	oldTitle, newTitle := trimmedValue(before.Title), trimmedValue(after.Title)
+	titleChanged := oldTitle != newTitle
+	if titleChanged {
+		rev.TitleBefore = &oldTitle
+	}
+
+	oldWords := textWords(before.Content)
+	newWords := textWords(after.Content)
+	for _, h := range diffWords(oldWords, newWords) {
+		rev.WordsRemoved += h.oldTo - h.oldFrom
+		rev.WordsAdded += h.newTo - h.newFrom
+		if len(rev.Changes) < maxRevisionChanges {
+			rev.Changes = append(rev.Changes, model.TextChange{
+				Removed: joinWords(oldWords[h.oldFrom:h.oldTo]),
+				Added:   joinWords(newWords[h.newFrom:h.newTo]),
+			})
+		}
+	}
+
+	return rev, titleChanged || rev.WordsAdded > 0 || rev.WordsRemoved > 0
+}
+
+func trimmedValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return strings.TrimSpace(*s)
+}
+
+// textWords splits the text of HTML content into words. CJK text has no
+// spaces, so each of its characters is a word of its own.
+func textWords(content *string) []string {
+	if content == nil {
+		return nil
+	}
+	var words []string
+	for _, field := range strings.Fields(ai.HTMLToText(*content)) {
+		start := 0
+		for i, r := range field {
+			if !isCJK(r) {
+				continue
+			}
+			if start < i {
+				words = append(words, field[start:i])
+			}
+			words = append(words, string(r))
+			start = i + len(string(r))
+		}
+		if start < len(field) {
+			words = append(words, field[start:])
+		}
+	}
+	return words
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// joinWords rejoins words for display, truncated to maxChangeWords. CJK
+// characters are joined without spaces.
+func joinWords(words []string) string {
+	truncated := len(words) > maxChangeWords
+	if truncated {
+		words = words[:maxChangeWords]
+	}
+	var b strings.Builder
+	for i, w := range words {
+		if i > 0 && !isCJK([]rune(words[i-1])[0]) && !isCJK([]rune(w)[0]) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(w)
+	}
+	if truncated {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// diffHunk is a run of words replaced between two texts:
+// before[oldFrom:oldTo] became after[newFrom:newTo].
+type diffHunk struct {
+	oldFrom, oldTo int
+	newFrom, newTo int
+}
+
+// diffWords returns the hunks where before and after differ, in order,
+// using the longest common subsequence of words.
+func diffWords(before, after []string) []diffHunk {
+	prefix := 0
+	for prefix < len(before) && prefix < len(after) && before[prefix] == after[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(before)-prefix && suffix < len(after)-prefix && before[len(before)-1-suffix] == after[len(after)-1-suffix] {
+		suffix++
+	}
+	a, b := before[prefix:len(before)-suffix], after[prefix:len(after)-suffix]
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	if len(a)*len(b) > maxDiffCells || len(a) == 0 || len(b) == 0 {
+		return []diffHunk{{prefix, prefix + len(a), prefix, prefix + len(b)}}
+	}
+
+	// lcs[i][j] is the length of the common subsequence of a[i:] and b[j:]
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var hunks []diffHunk
+	open := false
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && a[i] == b[j] {
+			open = false
+			i++
+			j++
+			continue
+		}
+		if !open {
+			hunks = append(hunks, diffHunk{prefix + i, prefix + i, prefix + j, prefix + j})
+			open = true
+		}
+		h := &hunks[len(hunks)-1]
+		if j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]) {
+			i++
+			h.oldTo = prefix + i
+		} else {
+			j++
+			h.newTo = prefix + j
+		}
+	}
+	return hunks
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+func diffTestEntry(title, content string) model.Entry {
+	return model.Entry{Title: &title, Content: &content}
+}
+
+func TestDiffEntry(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		before       model.Entry
+		after        model.Entry
+		changed      bool
+		titleBefore  string
+		wordsAdded   int
+		wordsRemoved int
+		changes      []model.TextChange
+	}{
+		{
+			name:    "markup only",
+			before:  diffTestEntry("Title", "<p>The quick brown fox</p>"),
+			after:   diffTestEntry("Title", "<div><b>The quick</b> brown fox</div>"),
+			changed: false,
+		},
+		{
+			name:         "correction",
+			before:       diffTestEntry("Title", "<p>The fire started on Monday and spread quickly.</p>"),
+			after:        diffTestEntry("Title", "<p>The fire started on Tuesday and spread quickly.</p>"),
+			changed:      true,
+			wordsAdded:   1,
+			wordsRemoved: 1,
+			changes:      []model.TextChange{{Removed: "Monday", Added: "Tuesday"}},
+		},
+		{
+			name:         "separate insertion and deletion",
+			before:       diffTestEntry("Title", "<p>one two three four five</p>"),
+			after:        diffTestEntry("Title", "<p>one two extra three four</p>"),
+			changed:      true,
+			wordsAdded:   1,
+			wordsRemoved: 1,
+			changes:      []model.TextChange{{Added: "extra"}, {Removed: "five"}},
+		},
+		{
+			name:        "title",
+			before:      diffTestEntry(" Old title ", "<p>Body</p>"),
+			after:       diffTestEntry("New title", "<p>Body</p>"),
+			changed:     true,
+			titleBefore: "Old title",
+			changes:     nil,
+		},
+		{
+			name:         "CJK",
+			before:       diffTestEntry("标题", "<p>今天下雨了</p>"),
+			after:        diffTestEntry("标题", "<p>今天晴天了</p>"),
+			changed:      true,
+			wordsAdded:   2,
+			wordsRemoved: 2,
+			changes:      []model.TextChange{{Removed: "下雨", Added: "晴天"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rev, changed := diffEntry(tt.before, tt.after, now)
+			if changed != tt.changed {
+				t.Fatalf("expected changed=%v, got %v (%+v)", tt.changed, changed, rev)
+			}
+			if !changed {
+				return
+			}
+			if tt.titleBefore == "" && rev.TitleBefore != nil {
+				t.Errorf("expected no titleBefore, got %q", *rev.TitleBefore)
+			}
+			if tt.titleBefore != "" && (rev.TitleBefore == nil || *rev.TitleBefore != tt.titleBefore) {
+				t.Errorf("expected titleBefore %q, got %v", tt.titleBefore, rev.TitleBefore)
+			}
+			if rev.WordsAdded != tt.wordsAdded || rev.WordsRemoved != tt.wordsRemoved {
+				t.Errorf("expected +%d -%d words, got +%d -%d", tt.wordsAdded, tt.wordsRemoved, rev.WordsAdded, rev.WordsRemoved)
+			}
+			if len(rev.Changes) != len(tt.changes) {
+				t.Fatalf("expected changes %+v, got %+v", tt.changes, rev.Changes)
+			}
+			for i := range tt.changes {
+				if rev.Changes[i] != tt.changes[i] {
+					t.Errorf("change %d: expected %+v, got %+v", i, tt.changes[i], rev.Changes[i])
+				}
+			}
+			if !rev.ChangedAt.Equal(now) {
+				t.Errorf("expected changedAt %v, got %v", now, rev.ChangedAt)
+			}
+		})
+	}
+}
+
+func TestDiffEntry_LimitsChanges(t *testing.T) {
+	var before, after []string
+	for i := 0; i < 20; i++ {
+		before = append(before, "keep", "old")
+		after = append(after, "keep", "new")
+	}
+	long := strings.Repeat("word ", maxChangeWords+10)
+
+	rev, changed := diffEntry(
+		diffTestEntry("Title", strings.Join(before, " ")),
+		diffTestEntry("Title", strings.Join(after, " ")+" end "+long),
+		time.Now(),
+	)
+	if !changed {
+		t.Fatal("expected a change")
+	}
+	if len(rev.Changes) != maxRevisionChanges {
+		t.Errorf("expected %d changes, got %d", maxRevisionChanges, len(rev.Changes))
+	}
+	if rev.WordsRemoved != 20 || rev.WordsAdded != 20+1+maxChangeWords+10 {
+		t.Errorf("expected counts over all changes, got +%d -%d", rev.WordsAdded, rev.WordsRemoved)
+	}
+
+	rev, _ = diffEntry(diffTestEntry("Title", "start"), diffTestEntry("Title", "start "+long), time.Now())
+	if len(rev.Changes) != 1 || !strings.HasSuffix(rev.Changes[0].Added, "…") {
+		t.Errorf("expected a truncated passage, got %+v", rev.Changes)
+	}
+}
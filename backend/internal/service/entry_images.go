@@ -0,0 +1,126 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	"golang.org/x/net/html"
+
+	"gist/backend/internal/model"
+)
+
+// maxEntryImages caps the gallery of a single entry.
+const maxEntryImages = 50
+
+// pictureFeedType is the feed type whose entries get an image gallery.
+const pictureFeedType = "picture"
+
+// addGallery sets the image gallery of an entry of a picture feed. An entry
+// without a thumbnail gets its first image as one, so it shows in the grid.
+func addGallery(entry *model.Entry, item *gofeed.Item) {
+	entry.Images = entryImages(item, *entry)
+	if entry.ThumbnailURL == nil && len(entry.Images) > 0 {
+		thumbnail := entry.Images[0]
+		entry.ThumbnailURL = &thumbnail
+	}
+}
+
+// entryImages collects the images of a feed item in order: the thumbnail,
+// images attached to the item, then images in its content. Relative URLs are
+// resolved against the entry URL; anything but http(s) is dropped.
+func entryImages(item *gofeed.Item, entry model.Entry) []string {
+	var base *url.URL
+	if entry.URL != nil {
+		base, _ = url.Parse(*entry.URL)
+	}
+
+	images := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(raw string) {
+		resolved, ok := resolveImageURL(base, raw)
+		if !ok || seen[resolved] || len(images) >= maxEntryImages {
+			return
+		}
+		seen[resolved] = true
+		images = append(images, resolved)
+	}
+
+	if entry.ThumbnailURL != nil {
+		add(*entry.ThumbnailURL)
+	}
+	if item.Image != nil {
+		add(item.Image.URL)
+	}
+	for _, enc := range item.Enclosures {
+		if strings.HasPrefix(enc.Type, "image/") {
+			add(enc.URL)
+		}
+	}
+	if media, ok := item.Extensions["media"]; ok {
+		contents := media["content"]
+		for _, group := range media["group"] {
+			contents = append(contents, group.Children["content"]...)
+		}
+		for _, c := range contents {
+			if strings.HasPrefix(c.Attrs["type"], "image/") || c.Attrs["medium"] == "image" {
+				add(c.Attrs["url"])
+			}
+		}
+	}
+	if entry.Content != nil {
+		for _, src := range contentImages(*entry.Content) {
+			add(src)
+		}
+	}
+	return images
+}
+
+// contentImages returns the sources of the img elements in HTML content,
+// including lazy-loaded ones.
+func contentImages(content string) []string {
+	doc, err := html.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil
+	}
+
+	var sources []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "img" {
+			attrs := make(map[string]string, len(n.Attr))
+			for _, attr := range n.Attr {
+				attrs[attr.Key] = attr.Val
+			}
+			for _, key := range []string{"src", "data-src", "data-lazy-src"} {
+				if src := strings.TrimSpace(attrs[key]); src != "" && !strings.HasPrefix(src, "data:") {
+					sources = append(sources, src)
+					break
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return sources
+}
+
+func resolveImageURL(base *url.URL, raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	ref, err := url.Parse(raw)
+	if err != nil {
+		return "", false
+	}
+	if base != nil {
+		ref = base.ResolveReference(ref)
+	}
+	if ref.Scheme != "http" && ref.Scheme != "https" {
+		return "", false
+	}
+	return ref.String(), true
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/extensions"
+
+	"gist/backend/internal/model"
+)
+
+func TestEntryImages(t *testing.T) {
+	url := "https://example.com/posts/1"
+	thumbnail := "https://cdn.example.com/cover.jpg"
+	content := `<p>Set</p>
+		<img src="https://cdn.example.com/cover.jpg">
+		<img src="/images/2.jpg">
+		<img src="data:image/gif;base64,R0lGOD" data-src="https://cdn.example.com/3.jpg">
+		<img src="javascript:alert(1)">`
+	item := &gofeed.Item{
+		Enclosures: []*gofeed.Enclosure{
+			{URL: "https://cdn.example.com/enclosure.jpg", Type: "image/jpeg"},
+			{URL: "https://cdn.example.com/podcast.mp3", Type: "audio/mpeg"},
+		},
+		Extensions: ext.Extensions{
+			"media": {
+				"group": {{Children: map[string][]ext.Extension{
+					"content": {{Attrs: map[string]string{"url": "https://cdn.example.com/group.jpg", "medium": "image"}}},
+				}}},
+			},
+		},
+	}
+
+	images := entryImages(item, model.Entry{URL: &url, ThumbnailURL: &thumbnail, Content: &content})
+	expected := []string{
+		"https://cdn.example.com/cover.jpg",
+		"https://cdn.example.com/enclosure.jpg",
+		"https://cdn.example.com/group.jpg",
+		"https://example.com/images/2.jpg",
+		"https://cdn.example.com/3.jpg",
+	}
+	if !reflect.DeepEqual(images, expected) {
+		t.Errorf("expected %v, got %v", expected, images)
+	}
+
+	// An entry without images still gets an empty, not nil, gallery so a
+	// save clears images the publisher removed
+	if images := entryImages(&gofeed.Item{}, model.Entry{}); images == nil || len(images) != 0 {
+		t.Errorf("expected an empty gallery, got %#v", images)
+	}
+}
+
+func TestAddGallery_ThumbnailFallback(t *testing.T) {
+	content := `<img src="https://cdn.example.com/1.jpg"><img src="https://cdn.example.com/2.jpg">`
+	entry := model.Entry{Content: &content}
+
+	addGallery(&entry, &gofeed.Item{})
+	if len(entry.Images) != 2 {
+		t.Fatalf("expected 2 images, got %v", entry.Images)
+	}
+	if entry.ThumbnailURL == nil || *entry.ThumbnailURL != "https://cdn.example.com/1.jpg" {
+		t.Errorf("expected the first image as thumbnail, got %v", entry.ThumbnailURL)
+	}
+}
@@ -1,14 +1,24 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
 
 	"gist/backend/internal/model"
+	"gist/backend/internal/printview"
 	"gist/backend/internal/repository"
 )
 
+// MaxEntryBatch caps the entries of one batch operation.
+const MaxEntryBatch = 1000
+
+// EntryOps are the operations ApplyBatch accepts.
+var EntryOps = []string{model.EntryOpRead, model.EntryOpUnread, model.EntryOpStar, model.EntryOpUnstar, model.EntryOpDelete}
+
 type EntryListParams struct {
 	FeedID       *int64
 	FolderID     *int64
@@ -16,47 +26,178 @@ type EntryListParams struct {
 	UnreadOnly   bool
 	StarredOnly  bool
 	HasThumbnail bool
-	Limit        int
-	Offset       int
+	// ExcludeNSFW leaves out entries flagged as not safe for work.
+	ExcludeNSFW bool
+	// TagID limits entries to those with this tag; an unknown tag selects none.
+	TagID *int64
+	// Period limits entries to a year ("2025") or month ("2025-03") by publish date.
+	Period string
+	// After continues a list from a cursor EntryCursorOf returned, instead
+	// of Offset. Search ignores it.
+	After  string
+	Limit  int
+	Offset int
+}
+
+// StarredCounts holds the starred entries per feed and per folder. An entry
+// a filter moved counts under its own folder rather than its feed's; folders
+// count only their own entries, not their subfolders'.
+type StarredCounts struct {
+	Feeds   map[int64]int
+	Folders map[int64]int
+}
+
+// UnreadCounts holds the unread entries per feed and per folder, counted as
+// StarredCounts are, and their total. StarredTotal is every starred entry,
+// whatever the scope of the unread counts, so one call fills the sidebar.
+type UnreadCounts struct {
+	Feeds        map[int64]int
+	Folders      map[int64]int
+	Total        int
+	StarredTotal int
 }
 
 type EntryService interface {
-	List(ctx context.Context, params EntryListParams) ([]model.Entry, error)
+	// List returns entry summaries; content is only loaded by GetByID.
+	List(ctx context.Context, params EntryListParams) ([]model.EntrySummary, error)
+	// Search returns the summaries of entries matching params.Query within
+	// the entries params selects.
+	Search(ctx context.Context, params EntrySearchParams) ([]model.EntrySummary, error)
+	// Archive counts the entries selected by params per year or month
+	// (ArchiveByYear, ArchiveByMonth), newest first. Limit and Offset are ignored.
+	Archive(ctx context.Context, params EntryListParams, groupBy string) ([]model.ArchivePeriod, error)
 	GetByID(ctx context.Context, id int64) (model.Entry, error)
+	// Print renders an entry as a standalone HTML document for printing or
+	// saving, preferring its readable content.
+	Print(ctx context.Context, id int64) ([]byte, error)
 	MarkAsRead(ctx context.Context, id int64, read bool) error
 	MarkAsStarred(ctx context.Context, id int64, starred bool) error
 	MarkAllAsRead(ctx context.Context, feedID *int64, folderID *int64, contentType *string) error
-	GetUnreadCounts(ctx context.Context) (map[int64]int, error)
+	// ApplyBatch applies op, one of the model.EntryOp constants, to up to
+	// MaxEntryBatch entries at once and returns how many changed. Unknown IDs
+	// are skipped, so changes made offline still apply after some of their
+	// entries were pruned.
+	ApplyBatch(ctx context.Context, ids []int64, op string) (int64, error)
+	// GetUnreadCounts returns the unread count of each feed and folder and
+	// their total, limited to a content type and a folder subtree when
+	// given, with the total of starred entries.
+	GetUnreadCounts(ctx context.Context, contentType *string, folderID *int64) (UnreadCounts, error)
 	GetStarredCount(ctx context.Context) (int, error)
+	// GetStarredCounts returns the starred count of each feed and folder.
+	GetStarredCounts(ctx context.Context) (StarredCounts, error)
+	// BackfillSnippets computes snippets for entries stored before they existed.
+	BackfillSnippets(ctx context.Context) (int, error)
 }
 
 type entryService struct {
-	entries repository.EntryRepository
-	feeds   repository.FeedRepository
-	folders repository.FolderRepository
+	entries    repository.EntryRepository
+	feeds      repository.FeedRepository
+	folders    repository.FolderRepository
+	readStatus *readStatusBatcher
 }
 
 func NewEntryService(
+	tx repository.TxManager,
 	entries repository.EntryRepository,
 	feeds repository.FeedRepository,
 	folders repository.FolderRepository,
 ) EntryService {
 	return &entryService{
-		entries: entries,
-		feeds:   feeds,
-		folders: folders,
+		entries:    entries,
+		feeds:      feeds,
+		folders:    folders,
+		readStatus: newReadStatusBatcher(tx, readBatchWindow),
 	}
 }
 
-func (s *entryService) List(ctx context.Context, params EntryListParams) ([]model.Entry, error) {
+func (s *entryService) List(ctx context.Context, params EntryListParams) ([]model.EntrySummary, error) {
+	filter, err := s.scopeFilter(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.After != "" {
+		cursor, err := ParseEntryCursor(params.After)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = &cursor
+	}
+	filter.Limit = listLimit(params.Limit)
+	filter.Offset = params.Offset
+
+	return s.entries.List(ctx, filter)
+}
+
+func (s *entryService) Search(ctx context.Context, params EntrySearchParams) ([]model.EntrySummary, error) {
+	query, err := ParseSearchQuery(params.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Filters in the query text add to those of the request
+	scope := params.EntryListParams
+	if query.FeedID != nil {
+		scope.FeedID = query.FeedID
+	}
+	if query.FolderID != nil {
+		scope.FolderID = query.FolderID
+	}
+	scope.UnreadOnly = scope.UnreadOnly || query.UnreadOnly
+	scope.StarredOnly = scope.StarredOnly || query.StarredOnly
+	filter, err := s.scopeFilter(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	filter.Match = query.Match
+	filter.Limit = listLimit(params.Limit)
+	filter.Offset = params.Offset
+
+	switch params.SortBy {
+	case "", SearchByRelevance:
+		return s.entries.Search(ctx, filter)
+	case SearchByNewest:
+		return s.entries.List(ctx, filter)
+	default:
+		return nil, fmt.Errorf("%w: unknown search order %q", ErrInvalid, params.SortBy)
+	}
+}
+
+// listLimit applies the default page size, allowing up to 101 for the
+// handler's hasMore check (it requests limit+1).
+func listLimit(limit int) int {
+	if limit <= 0 {
+		return 50
+	}
+	if limit > 101 {
+		return 101
+	}
+	return limit
+}
+
+func (s *entryService) Archive(ctx context.Context, params EntryListParams, groupBy string) ([]model.ArchivePeriod, error) {
+	bucketLen, ok := archiveBucketLen[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown archive grouping %q", ErrInvalid, groupBy)
+	}
+	filter, err := s.scopeFilter(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return s.entries.Archive(ctx, filter, bucketLen)
+}
+
+// scopeFilter turns the selection part of params into a repository filter,
+// checking that the feed and folder it names exist.
+func (s *entryService) scopeFilter(ctx context.Context, params EntryListParams) (repository.EntryListFilter, error) {
 	// Validate feedID exists if provided
 	if params.FeedID != nil {
 		_, err := s.feeds.GetByID(ctx, *params.FeedID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				return nil, ErrNotFound
+				return repository.EntryListFilter{}, ErrNotFound
 			}
-			return nil, err
+			return repository.EntryListFilter{}, err
 		}
 	}
 
@@ -65,22 +206,12 @@ func (s *entryService) List(ctx context.Context, params EntryListParams) ([]mode
 		_, err := s.folders.GetByID(ctx, *params.FolderID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				return nil, ErrNotFound
+				return repository.EntryListFilter{}, ErrNotFound
 			}
-			return nil, err
+			return repository.EntryListFilter{}, err
 		}
 	}
 
-	// Set default limit
-	// Allow up to 101 for internal hasMore check (handler requests limit+1)
-	limit := params.Limit
-	if limit <= 0 {
-		limit = 50
-	}
-	if limit > 101 {
-		limit = 101
-	}
-
 	filter := repository.EntryListFilter{
 		FeedID:       params.FeedID,
 		FolderID:     params.FolderID,
@@ -88,11 +219,18 @@ func (s *entryService) List(ctx context.Context, params EntryListParams) ([]mode
 		UnreadOnly:   params.UnreadOnly,
 		StarredOnly:  params.StarredOnly,
 		HasThumbnail: params.HasThumbnail,
-		Limit:        limit,
-		Offset:       params.Offset,
+		ExcludeNSFW:  params.ExcludeNSFW,
+		TagID:        params.TagID,
 	}
-
-	return s.entries.List(ctx, filter)
+	if params.Period != "" {
+		from, before, err := PeriodBounds(params.Period)
+		if err != nil {
+			return repository.EntryListFilter{}, err
+		}
+		filter.PublishedFrom = from
+		filter.PublishedBefore = before
+	}
+	return filter, nil
 }
 
 func (s *entryService) GetByID(ctx context.Context, id int64) (model.Entry, error) {
@@ -106,17 +244,39 @@ func (s *entryService) GetByID(ctx context.Context, id int64) (model.Entry, erro
 	return entry, nil
 }
 
-func (s *entryService) MarkAsRead(ctx context.Context, id int64, read bool) error {
-	// Check entry exists
-	_, err := s.entries.GetByID(ctx, id)
+func (s *entryService) Print(ctx context.Context, id int64) ([]byte, error) {
+	entry, err := s.GetByID(ctx, id)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrNotFound
-		}
-		return err
+		return nil, err
+	}
+	feedTitle := ""
+	if feed, err := s.feeds.GetByID(ctx, entry.FeedID); err == nil {
+		feedTitle = feed.Title
+	}
+
+	content := ""
+	if entry.ReadableContent != nil && *entry.ReadableContent != "" {
+		content = *entry.ReadableContent
+	} else if entry.Content != nil {
+		content = *entry.Content
 	}
+	var buf bytes.Buffer
+	if err := printview.Render(&buf, printview.Page{
+		Title:     trimmedValue(entry.Title),
+		Author:    trimmedValue(entry.Author),
+		FeedTitle: feedTitle,
+		SourceURL: trimmedValue(entry.URL),
+		Published: entry.PublishedAt,
+		Content:   content,
+	}); err != nil {
+		return nil, fmt.Errorf("render print view: %w", err)
+	}
+	return buf.Bytes(), nil
+}
 
-	return s.entries.UpdateReadStatus(ctx, id, read)
+func (s *entryService) MarkAsRead(ctx context.Context, id int64, read bool) error {
+	// The batch reports entries that do not exist
+	return s.readStatus.Submit(ctx, id, read)
 }
 
 func (s *entryService) MarkAllAsRead(ctx context.Context, feedID *int64, folderID *int64, contentType *string) error {
@@ -145,15 +305,32 @@ func (s *entryService) MarkAllAsRead(ctx context.Context, feedID *int64, folderI
 	return s.entries.MarkAllAsRead(ctx, feedID, folderID, contentType)
 }
 
-func (s *entryService) GetUnreadCounts(ctx context.Context) (map[int64]int, error) {
-	counts, err := s.entries.GetAllUnreadCounts(ctx)
+func (s *entryService) GetUnreadCounts(ctx context.Context, contentType *string, folderID *int64) (UnreadCounts, error) {
+	if folderID != nil {
+		if _, err := s.folders.GetByID(ctx, *folderID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return UnreadCounts{}, ErrNotFound
+			}
+			return UnreadCounts{}, err
+		}
+	}
+
+	counts, err := s.entries.GetUnreadCounts(ctx, repository.UnreadCountFilter{ContentType: contentType, FolderID: folderID})
 	if err != nil {
-		return nil, err
+		return UnreadCounts{}, err
+	}
+	starred, err := s.entries.GetStarredCount(ctx)
+	if err != nil {
+		return UnreadCounts{}, err
 	}
 
-	result := make(map[int64]int)
+	result := UnreadCounts{Feeds: make(map[int64]int), Folders: make(map[int64]int), StarredTotal: starred}
 	for _, uc := range counts {
-		result[uc.FeedID] = uc.Count
+		result.Feeds[uc.FeedID] += uc.Count
+		if uc.FolderID != nil {
+			result.Folders[*uc.FolderID] += uc.Count
+		}
+		result.Total += uc.Count
 	}
 
 	return result, nil
@@ -172,6 +349,64 @@ func (s *entryService) MarkAsStarred(ctx context.Context, id int64, starred bool
 	return s.entries.UpdateStarredStatus(ctx, id, starred)
 }
 
+func (s *entryService) ApplyBatch(ctx context.Context, ids []int64, op string) (int64, error) {
+	if len(ids) == 0 || len(ids) > MaxEntryBatch || !slices.Contains(EntryOps, op) {
+		return 0, ErrInvalid
+	}
+	return s.entries.ApplyBatch(ctx, ids, op)
+}
+
 func (s *entryService) GetStarredCount(ctx context.Context) (int, error) {
 	return s.entries.GetStarredCount(ctx)
 }
+
+func (s *entryService) GetStarredCounts(ctx context.Context) (StarredCounts, error) {
+	counts, err := s.entries.GetStarredCounts(ctx)
+	if err != nil {
+		return StarredCounts{}, err
+	}
+
+	result := StarredCounts{Feeds: make(map[int64]int), Folders: make(map[int64]int)}
+	for _, sc := range counts {
+		result.Feeds[sc.FeedID] += sc.Count
+		if sc.FolderID != nil {
+			result.Folders[*sc.FolderID] += sc.Count
+		}
+	}
+
+	return result, nil
+}
+
+// snippetBackfillBatch is how many entries BackfillSnippets loads at a time.
+const snippetBackfillBatch = 200
+
+func (s *entryService) BackfillSnippets(ctx context.Context) (int, error) {
+	filled := 0
+	for {
+		entries, err := s.entries.ListWithoutSnippet(ctx, snippetBackfillBatch)
+		if err != nil {
+			return filled, fmt.Errorf("list entries without snippet: %w", err)
+		}
+		if len(entries) == 0 {
+			return filled, nil
+		}
+		for _, entry := range entries {
+			if ctx.Err() != nil {
+				return filled, ctx.Err()
+			}
+			// An empty snippet still marks the entry as done
+			if err := s.entries.UpdateSnippet(ctx, entry.ID, makeSnippet(*entry.Content)); err != nil {
+				return filled, fmt.Errorf("update snippet for entry %d: %w", entry.ID, err)
+			}
+			filled++
+		}
+	}
+}
+
+// BackfillSnippetsTask runs BackfillSnippets as a task.
+func BackfillSnippetsTask(entries EntryService) TaskFunc {
+	return func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		_, err := entries.BackfillSnippets(ctx)
+		return nil, err
+	}
+}
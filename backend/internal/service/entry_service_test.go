@@ -4,7 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"gist/backend/internal/model"
 	"gist/backend/internal/repository"
@@ -20,11 +24,11 @@ func TestEntryService_List_Success(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
-	expectedEntries := []model.Entry{
-		{ID: 1, FeedID: 100, Title: stringPtr("Entry 1")},
+	expectedEntries := []model.EntrySummary{
+		{ID: 1, FeedID: 100, Title: stringPtr("Entry 1"), Snippet: stringPtr("Hello world")},
 		{ID: 2, FeedID: 100, Title: stringPtr("Entry 2")},
 	}
 
@@ -47,7 +51,10 @@ func TestEntryService_List_Success(t *testing.T) {
 	}
 
 	if len(entries) != 2 {
-		t.Errorf("expected 2 entries, got %d", len(entries))
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Snippet == nil || *entries[0].Snippet != "Hello world" {
+		t.Errorf("expected stored snippet, got %v", entries[0].Snippet)
 	}
 }
 
@@ -58,7 +65,7 @@ func TestEntryService_List_WithFeedID(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	feedID := int64(100)
@@ -78,7 +85,7 @@ func TestEntryService_List_WithFeedID(t *testing.T) {
 			Limit:        50,
 			Offset:       0,
 		}).
-		Return([]model.Entry{}, nil)
+		Return([]model.EntrySummary{}, nil)
 
 	_, err := service.List(ctx, EntryListParams{FeedID: &feedID})
 	if err != nil {
@@ -93,7 +100,7 @@ func TestEntryService_List_FeedNotFound(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	feedID := int64(999)
@@ -115,7 +122,7 @@ func TestEntryService_List_FolderNotFound(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	folderID := int64(999)
@@ -137,7 +144,7 @@ func TestEntryService_List_LimitClamp(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	// Limit > 101 should be clamped to 101
@@ -146,7 +153,7 @@ func TestEntryService_List_LimitClamp(t *testing.T) {
 			Limit:  101,
 			Offset: 0,
 		}).
-		Return([]model.Entry{}, nil)
+		Return([]model.EntrySummary{}, nil)
 
 	_, err := service.List(ctx, EntryListParams{Limit: 200})
 	if err != nil {
@@ -161,7 +168,7 @@ func TestEntryService_List_DefaultLimit(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	// Limit <= 0 should default to 50
@@ -170,7 +177,7 @@ func TestEntryService_List_DefaultLimit(t *testing.T) {
 			Limit:  50,
 			Offset: 0,
 		}).
-		Return([]model.Entry{}, nil)
+		Return([]model.EntrySummary{}, nil)
 
 	_, err := service.List(ctx, EntryListParams{Limit: 0})
 	if err != nil {
@@ -185,7 +192,7 @@ func TestEntryService_GetByID_Success(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	expectedEntry := model.Entry{
@@ -215,7 +222,7 @@ func TestEntryService_GetByID_NotFound(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	mockEntries.EXPECT().
@@ -228,23 +235,59 @@ func TestEntryService_GetByID_NotFound(t *testing.T) {
 	}
 }
 
-func TestEntryService_MarkAsRead_Success(t *testing.T) {
+func TestEntryService_Print(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
-	mockEntries.EXPECT().
-		GetByID(ctx, int64(123)).
-		Return(model.Entry{ID: 123}, nil)
+	mockEntries.EXPECT().GetByID(ctx, int64(123)).Return(model.Entry{
+		ID:              123,
+		FeedID:          100,
+		Title:           stringPtr("Printed"),
+		URL:             stringPtr("https://example.com/post"),
+		Content:         stringPtr("<p>Summary</p>"),
+		ReadableContent: stringPtr(`<p>Full text</p><img src="/a.png">`),
+	}, nil)
+	mockFeeds.EXPECT().GetByID(ctx, int64(100)).Return(model.Feed{ID: 100, Title: "Blog"}, nil)
+
+	page, err := service.Print(ctx, 123)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc := string(page)
+	for _, want := range []string{"<h1>Printed</h1>", "Blog", "<p>Full text</p>", `src="https://example.com/a.png"`} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %q in:\n%s", want, doc)
+		}
+	}
+	if strings.Contains(doc, "Summary") {
+		t.Errorf("expected readable content to be preferred, got:\n%s", doc)
+	}
+
+	mockEntries.EXPECT().GetByID(ctx, int64(999)).Return(model.Entry{}, sql.ErrNoRows)
+	if _, err := service.Print(ctx, 999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestEntryService_MarkAsRead_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	service := NewEntryService(mockTx(ctrl, repository.TxRepositories{Entries: mockEntries}), mockEntries, mockFeeds, mockFolders)
+	ctx := context.Background()
 
 	mockEntries.EXPECT().
-		UpdateReadStatus(ctx, int64(123), true).
-		Return(nil)
+		UpdateReadStatus(gomock.Any(), []int64{123}, true).
+		Return([]int64{123}, nil)
 
 	err := service.MarkAsRead(ctx, 123, true)
 	if err != nil {
@@ -259,12 +302,12 @@ func TestEntryService_MarkAsRead_NotFound(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(mockTx(ctrl, repository.TxRepositories{Entries: mockEntries}), mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	mockEntries.EXPECT().
-		GetByID(ctx, int64(999)).
-		Return(model.Entry{}, sql.ErrNoRows)
+		UpdateReadStatus(gomock.Any(), []int64{999}, true).
+		Return([]int64{}, nil)
 
 	err := service.MarkAsRead(ctx, 999, true)
 	if !errors.Is(err, ErrNotFound) {
@@ -279,7 +322,7 @@ func TestEntryService_MarkAsStarred_Success(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	mockEntries.EXPECT().
@@ -303,7 +346,7 @@ func TestEntryService_MarkAllAsRead_ByFeed(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	feedID := int64(100)
@@ -329,7 +372,7 @@ func TestEntryService_MarkAllAsRead_ByFolder(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	folderID := int64(200)
@@ -355,7 +398,7 @@ func TestEntryService_MarkAllAsRead_All(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	mockEntries.EXPECT().
@@ -375,7 +418,7 @@ func TestEntryService_MarkAllAsRead_FeedNotFound(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	feedID := int64(999)
@@ -397,34 +440,45 @@ func TestEntryService_GetUnreadCounts_Success(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
+	folderA, folderB := int64(10), int64(20)
 	expectedCounts := []repository.UnreadCount{
-		{FeedID: 1, Count: 5},
-		{FeedID: 2, Count: 10},
+		{FeedID: 1, FolderID: &folderA, Count: 5},
+		{FeedID: 1, FolderID: &folderB, Count: 1}, // moved by a filter
+		{FeedID: 2, FolderID: &folderB, Count: 10},
 		{FeedID: 3, Count: 3},
 	}
 
 	mockEntries.EXPECT().
-		GetAllUnreadCounts(ctx).
+		GetUnreadCounts(ctx, repository.UnreadCountFilter{}).
 		Return(expectedCounts, nil)
+	mockEntries.EXPECT().GetStarredCount(ctx).Return(7, nil)
 
-	counts, err := service.GetUnreadCounts(ctx)
+	counts, err := service.GetUnreadCounts(ctx, nil, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if len(counts) != 3 {
-		t.Errorf("expected 3 feed counts, got %d", len(counts))
+	if len(counts.Feeds) != 3 {
+		t.Errorf("expected 3 feed counts, got %d", len(counts.Feeds))
+	}
+
+	if counts.Feeds[1] != 6 {
+		t.Errorf("expected feed 1 to have 6 unread, got %d", counts.Feeds[1])
 	}
 
-	if counts[1] != 5 {
-		t.Errorf("expected feed 1 to have 5 unread, got %d", counts[1])
+	if counts.Feeds[2] != 10 {
+		t.Errorf("expected feed 2 to have 10 unread, got %d", counts.Feeds[2])
 	}
 
-	if counts[2] != 10 {
-		t.Errorf("expected feed 2 to have 10 unread, got %d", counts[2])
+	if len(counts.Folders) != 2 || counts.Folders[folderA] != 5 || counts.Folders[folderB] != 11 {
+		t.Errorf("unexpected folder counts %v", counts.Folders)
+	}
+
+	if counts.Total != 19 || counts.StarredTotal != 7 {
+		t.Errorf("expected totals 19 unread and 7 starred, got %d and %d", counts.Total, counts.StarredTotal)
 	}
 }
 
@@ -435,7 +489,7 @@ func TestEntryService_GetStarredCount_Success(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	mockEntries.EXPECT().
@@ -459,7 +513,7 @@ func TestEntryService_List_WithFilters(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	contentType := "picture"
@@ -475,7 +529,7 @@ func TestEntryService_List_WithFilters(t *testing.T) {
 			Limit:        20,
 			Offset:       10,
 		}).
-		Return([]model.Entry{}, nil)
+		Return([]model.EntrySummary{}, nil)
 
 	_, err := service.List(ctx, EntryListParams{
 		ContentType:  &contentType,
@@ -498,7 +552,7 @@ func TestEntryService_List_RepositoryError(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	dbError := errors.New("database connection lost")
@@ -524,7 +578,7 @@ func TestEntryService_List_FeedValidationError(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	feedID := int64(100)
@@ -551,7 +605,7 @@ func TestEntryService_List_FolderValidationError(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	folderID := int64(100)
@@ -578,7 +632,7 @@ func TestEntryService_GetByID_RepositoryError(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	dbError := errors.New("database error")
@@ -604,18 +658,14 @@ func TestEntryService_MarkAsRead_UpdateError(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(mockTx(ctrl, repository.TxRepositories{Entries: mockEntries}), mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	dbError := errors.New("update failed")
 
 	mockEntries.EXPECT().
-		GetByID(ctx, int64(123)).
-		Return(model.Entry{ID: 123}, nil)
-
-	mockEntries.EXPECT().
-		UpdateReadStatus(ctx, int64(123), true).
-		Return(dbError)
+		UpdateReadStatus(gomock.Any(), []int64{123}, true).
+		Return(nil, dbError)
 
 	err := service.MarkAsRead(ctx, 123, true)
 	if err == nil {
@@ -634,7 +684,7 @@ func TestEntryService_MarkAsStarred_UpdateError(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	dbError := errors.New("update failed")
@@ -664,7 +714,7 @@ func TestEntryService_MarkAllAsRead_RepositoryError(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	dbError := errors.New("mark all failed")
@@ -690,7 +740,7 @@ func TestEntryService_MarkAllAsRead_FolderNotFound(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	folderID := int64(999)
@@ -705,6 +755,39 @@ func TestEntryService_MarkAllAsRead_FolderNotFound(t *testing.T) {
 	}
 }
 
+func TestEntryService_GetUnreadCounts_Scoped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
+	ctx := context.Background()
+
+	contentType := "picture"
+	folderID := int64(4)
+	mockFolders.EXPECT().GetByID(ctx, folderID).Return(model.Folder{ID: folderID}, nil)
+	mockEntries.EXPECT().
+		GetUnreadCounts(ctx, repository.UnreadCountFilter{ContentType: &contentType, FolderID: &folderID}).
+		Return([]repository.UnreadCount{{FeedID: 1, FolderID: &folderID, Count: 2}}, nil)
+	mockEntries.EXPECT().GetStarredCount(ctx).Return(0, nil)
+
+	counts, err := service.GetUnreadCounts(ctx, &contentType, &folderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(counts.Feeds) != 1 || counts.Feeds[1] != 2 || counts.Folders[folderID] != 2 || counts.Total != 2 {
+		t.Errorf("unexpected counts %+v", counts)
+	}
+
+	missing := int64(5)
+	mockFolders.EXPECT().GetByID(ctx, missing).Return(model.Folder{}, sql.ErrNoRows)
+	if _, err := service.GetUnreadCounts(ctx, nil, &missing); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown folder, got %v", err)
+	}
+}
+
 func TestEntryService_GetUnreadCounts_RepositoryError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -712,16 +795,16 @@ func TestEntryService_GetUnreadCounts_RepositoryError(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	dbError := errors.New("count query failed")
 
 	mockEntries.EXPECT().
-		GetAllUnreadCounts(ctx).
+		GetUnreadCounts(ctx, repository.UnreadCountFilter{}).
 		Return(nil, dbError)
 
-	_, err := service.GetUnreadCounts(ctx)
+	_, err := service.GetUnreadCounts(ctx, nil, nil)
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -731,6 +814,36 @@ func TestEntryService_GetUnreadCounts_RepositoryError(t *testing.T) {
 	}
 }
 
+func TestEntryService_GetStarredCounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
+	ctx := context.Background()
+
+	folder, other := int64(10), int64(11)
+	mockEntries.EXPECT().GetStarredCounts(ctx).Return([]repository.StarredCount{
+		{FeedID: 1, FolderID: &folder, Count: 2},
+		{FeedID: 2, Count: 1},
+		{FeedID: 2, FolderID: &other, Count: 3},
+		{FeedID: 3, FolderID: &folder, Count: 4},
+	}, nil)
+
+	counts, err := service.GetStarredCounts(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(counts.Feeds) != 3 || counts.Feeds[1] != 2 || counts.Feeds[2] != 4 || counts.Feeds[3] != 4 {
+		t.Errorf("unexpected feed counts %v", counts.Feeds)
+	}
+	if len(counts.Folders) != 2 || counts.Folders[folder] != 6 || counts.Folders[other] != 3 {
+		t.Errorf("unexpected folder counts %v", counts.Folders)
+	}
+}
+
 func TestEntryService_GetStarredCount_RepositoryError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -738,7 +851,7 @@ func TestEntryService_GetStarredCount_RepositoryError(t *testing.T) {
 	mockEntries := testutil.NewMockEntryRepository(ctrl)
 	mockFeeds := testutil.NewMockFeedRepository(ctrl)
 	mockFolders := testutil.NewMockFolderRepository(ctrl)
-	service := NewEntryService(mockEntries, mockFeeds, mockFolders)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
 	ctx := context.Background()
 
 	dbError := errors.New("count query failed")
@@ -761,3 +874,281 @@ func TestEntryService_GetStarredCount_RepositoryError(t *testing.T) {
 func stringPtr(s string) *string {
 	return &s
 }
+
+func TestEntryService_BackfillSnippets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
+	ctx := context.Background()
+
+	gomock.InOrder(
+		mockEntries.EXPECT().
+			ListWithoutSnippet(ctx, snippetBackfillBatch).
+			Return([]model.Entry{
+				{ID: 1, Content: stringPtr("<p>Hello\n<b>world</b></p><script>x()</script>")},
+				{ID: 2, Content: stringPtr(`<img src="a.png">`)},
+			}, nil),
+		mockEntries.EXPECT().UpdateSnippet(ctx, int64(1), "Hello world").Return(nil),
+		mockEntries.EXPECT().UpdateSnippet(ctx, int64(2), "").Return(nil),
+		mockEntries.EXPECT().
+			ListWithoutSnippet(ctx, snippetBackfillBatch).
+			Return(nil, nil),
+	)
+
+	filled, err := service.BackfillSnippets(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filled != 2 {
+		t.Errorf("expected 2 snippets filled, got %d", filled)
+	}
+}
+
+func TestEntryService_MarkAsRead_CoalescesWrites(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	tx := testutil.NewMockTxManager(ctrl)
+	service := NewEntryService(tx, mockEntries, mockFeeds, mockFolders)
+	ctx := context.Background()
+
+	// Both statements of the batch run in one transaction
+	tx.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, fn func(repository.TxRepositories) error) error {
+			return fn(repository.TxRepositories{Entries: mockEntries})
+		}).
+		Times(1)
+	mockEntries.EXPECT().
+		UpdateReadStatus(gomock.Any(), []int64{1, 2}, true).
+		Return([]int64{1, 2}, nil).
+		Times(1)
+	mockEntries.EXPECT().
+		UpdateReadStatus(gomock.Any(), []int64{3}, false).
+		Return([]int64{3}, nil).
+		Times(1)
+
+	// Entry 3 is read then unread within the window; the last change wins
+	type change struct {
+		id   int64
+		read bool
+	}
+	changes := []change{{1, true}, {2, true}, {3, true}, {3, false}}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(changes))
+	for i, c := range changes {
+		wg.Add(1)
+		go func(c change) {
+			defer wg.Done()
+			errs <- service.MarkAsRead(ctx, c.id, c.read)
+		}(c)
+		if i == 2 {
+			time.Sleep(5 * time.Millisecond) // order the two changes to entry 3
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestEntryService_MarkAsRead_ReportsMissingInBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	service := NewEntryService(mockTx(ctrl, repository.TxRepositories{Entries: mockEntries}), mockEntries, mockFeeds, mockFolders)
+	ctx := context.Background()
+
+	// Entry 2 was deleted; only entry 1 comes back from the update
+	mockEntries.EXPECT().
+		UpdateReadStatus(gomock.Any(), gomock.Any(), true).
+		Return([]int64{1}, nil).
+		Times(1)
+
+	var wg sync.WaitGroup
+	errs := make(map[int64]error)
+	var mu sync.Mutex
+	for _, id := range []int64{1, 2} {
+		wg.Add(1)
+		go func(id int64) {
+			defer wg.Done()
+			err := service.MarkAsRead(ctx, id, true)
+			mu.Lock()
+			errs[id] = err
+			mu.Unlock()
+		}(id)
+	}
+	wg.Wait()
+
+	if errs[1] != nil {
+		t.Errorf("expected entry 1 to be updated, got %v", errs[1])
+	}
+	if !errors.Is(errs[2], ErrNotFound) {
+		t.Errorf("expected ErrNotFound for entry 2, got %v", errs[2])
+	}
+}
+
+func TestEntryService_Archive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
+	ctx := context.Background()
+
+	periods := []model.ArchivePeriod{{Period: "2025-03", Count: 4, UnreadCount: 1}}
+	mockEntries.EXPECT().
+		Archive(ctx, repository.EntryListFilter{
+			StarredOnly:     true,
+			PublishedFrom:   "2025",
+			PublishedBefore: "2026",
+		}, 7).
+		Return(periods, nil)
+
+	got, err := service.Archive(ctx, EntryListParams{StarredOnly: true, Period: "2025"}, ArchiveByMonth)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0] != periods[0] {
+		t.Errorf("unexpected periods: %+v", got)
+	}
+
+	if _, err := service.Archive(ctx, EntryListParams{}, "week"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for unknown grouping, got %v", err)
+	}
+}
+
+func TestPeriodBounds(t *testing.T) {
+	tests := []struct {
+		period, from, before string
+		wantErr              bool
+	}{
+		{period: "2025-03", from: "2025-03", before: "2025-04"},
+		{period: "2025-12", from: "2025-12", before: "2026-01"},
+		{period: "2025", from: "2025", before: "2026"},
+		{period: "2025-13", wantErr: true},
+		{period: "March", wantErr: true},
+	}
+	for _, tt := range tests {
+		from, before, err := PeriodBounds(tt.period)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("%s: expected ErrInvalid, got %v", tt.period, err)
+			}
+			continue
+		}
+		if err != nil || from != tt.from || before != tt.before {
+			t.Errorf("%s: got [%s, %s) %v, want [%s, %s)", tt.period, from, before, err, tt.from, tt.before)
+		}
+	}
+}
+
+func TestEntryService_Search(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	service := NewEntryService(nil, mockEntries, mockFeeds, mockFolders)
+	ctx := context.Background()
+
+	feedID := int64(4)
+	mockFeeds.EXPECT().GetByID(ctx, feedID).Return(model.Feed{ID: feedID}, nil).Times(2)
+	filter := repository.EntryListFilter{FeedID: &feedID, UnreadOnly: true, Match: `"rust" "async"*`, Limit: 50}
+	mockEntries.EXPECT().Search(ctx, filter).Return([]model.EntrySummary{{ID: 1}}, nil)
+	mockEntries.EXPECT().List(ctx, filter).Return(nil, nil)
+
+	got, err := service.Search(ctx, EntrySearchParams{Query: "rust async* feed:4", EntryListParams: EntryListParams{UnreadOnly: true}})
+	if err != nil || len(got) != 1 {
+		t.Fatalf("expected the match, got %v %v", got, err)
+	}
+	if _, err := service.Search(ctx, EntrySearchParams{Query: "rust async* is:unread feed:4", SortBy: SearchByNewest}); err != nil {
+		t.Fatalf("search newest first: %v", err)
+	}
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(5)).Return(model.Feed{}, sql.ErrNoRows)
+	if _, err := service.Search(ctx, EntrySearchParams{Query: "rust feed:5"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown feed, got %v", err)
+	}
+	if _, err := service.Search(ctx, EntrySearchParams{Query: "is:unread"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid without words, got %v", err)
+	}
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+	tests := []struct {
+		text    string
+		want    SearchQuery
+		wantErr bool
+	}{
+		{text: "rust", want: SearchQuery{Match: `"rust"`}},
+		{text: `"state machine" gen*`, want: SearchQuery{Match: `"state machine" "gen"*`}},
+		{text: "title:release -beta -author:bot", want: SearchQuery{Match: `(title : "release") NOT "beta" NOT author : "bot"`}},
+		{text: "go feed:3 folder:7 is:unread is:starred", want: SearchQuery{Match: `"go"`, FeedID: id(3), FolderID: id(7), UnreadOnly: true, StarredOnly: true}},
+		// Operators and stray punctuation are searched as words, never as FTS5 syntax
+		{text: `AND OR NEAR( * - "unclosed`, want: SearchQuery{Match: `"AND" "OR" "NEAR(" "unclosed"`}},
+		{text: "https://example.com/post", want: SearchQuery{Match: `"https://example.com/post"`}},
+		{text: "中文 搜索", want: SearchQuery{Match: `"中文" "搜索"`}},
+		{text: "", wantErr: true},
+		{text: "-spam", wantErr: true},
+		{text: "rust feed:abc", wantErr: true},
+		{text: "rust is:read", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSearchQuery(tt.text)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("%q: expected ErrInvalid, got %+v %v", tt.text, got, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v %v, want %+v", tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestEntryService_ApplyBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewEntryService(nil, mockEntries, nil, nil)
+	ctx := context.Background()
+
+	mockEntries.EXPECT().ApplyBatch(ctx, []int64{1, 2, 3}, model.EntryOpStar).Return(int64(2), nil)
+	updated, err := service.ApplyBatch(ctx, []int64{1, 2, 3}, model.EntryOpStar)
+	if err != nil || updated != 2 {
+		t.Errorf("expected 2 updated, got %d, %v", updated, err)
+	}
+
+	tooMany := make([]int64, MaxEntryBatch+1)
+	for name, call := range map[string]func() (int64, error){
+		"no ids":         func() (int64, error) { return service.ApplyBatch(ctx, nil, model.EntryOpRead) },
+		"too many ids":   func() (int64, error) { return service.ApplyBatch(ctx, tooMany, model.EntryOpRead) },
+		"unknown action": func() (int64, error) { return service.ApplyBatch(ctx, []int64{1}, "archive") },
+	} {
+		if _, err := call(); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: expected ErrInvalid, got %v", name, err)
+		}
+	}
+}
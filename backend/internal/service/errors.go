@@ -11,6 +11,12 @@ var (
 	ErrConflict  = errors.New("conflict")
 	ErrInvalid   = errors.New("invalid")
 	ErrFeedFetch = errors.New("feed fetch failed")
+	// ErrIntegrationNotConfigured is returned when pushing to a read-later
+	// service that is not set up.
+	ErrIntegrationNotConfigured = errors.New("integration not configured")
+	// ErrIntegrationFailed is returned when a read-later service could not
+	// be reached or refused a push.
+	ErrIntegrationFailed = errors.New("integration request failed")
 )
 
 // FeedConflictError is returned when a feed URL already exists.
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gist/backend/internal/repository"
+)
+
+// Formats starred entries can be exported in
+const (
+	ExportJSON     = "json" // JSON Lines, an entry per line
+	ExportMarkdown = "md"   // a Markdown digest
+)
+
+// ExportFormats are the formats the export endpoint accepts.
+var ExportFormats = []string{ExportJSON, ExportMarkdown}
+
+// exportPageSize is how many entries are read from the database at a time.
+const exportPageSize = 100
+
+// ExportedEntry is an entry as written to a JSON Lines export.
+type ExportedEntry struct {
+	ID          int64      `json:"id,string"`
+	FeedTitle   string     `json:"feedTitle"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url,omitempty"`
+	Author      string     `json:"author,omitempty"`
+	PublishedAt *time.Time `json:"publishedAt,omitempty"`
+	// Content is the readable content when it was extracted, otherwise the
+	// feed content, as sanitized HTML.
+	Content         string `json:"content,omitempty"`
+	Summary         string `json:"summary,omitempty"`
+	SummaryLanguage string `json:"summaryLanguage,omitempty"`
+}
+
+// ExportService writes starred entries out for use outside Gist, such as
+// archiving them in a notes app.
+type ExportService interface {
+	// ExportStarred writes all starred entries to w in format, newest first,
+	// with their content and cached AI summary. Entries are written as they
+	// are read, so an error can come after part of the export was written.
+	// Returns the number of entries written.
+	ExportStarred(ctx context.Context, w io.Writer, format string) (int, error)
+}
+
+type exportService struct {
+	entries   repository.EntryRepository
+	feeds     repository.FeedRepository
+	summaries repository.AISummaryRepository
+}
+
+func NewExportService(entries repository.EntryRepository, feeds repository.FeedRepository, summaries repository.AISummaryRepository) ExportService {
+	return &exportService{entries: entries, feeds: feeds, summaries: summaries}
+}
+
+func (s *exportService) ExportStarred(ctx context.Context, w io.Writer, format string) (int, error) {
+	bw := bufio.NewWriter(w)
+	var write func(io.Writer, ExportedEntry) error
+	switch format {
+	case ExportJSON:
+		enc := json.NewEncoder(bw)
+		enc.SetEscapeHTML(false)
+		write = func(_ io.Writer, e ExportedEntry) error { return enc.Encode(e) }
+	case ExportMarkdown:
+		write = writeMarkdownEntry
+	default:
+		return 0, ErrInvalid
+	}
+
+	feedTitles, err := s.feedTitles(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if format == ExportMarkdown {
+		if _, err := io.WriteString(bw, "# Starred entries\n"); err != nil {
+			return 0, err
+		}
+	}
+
+	written := 0
+	filter := repository.EntryListFilter{StarredOnly: true, Limit: exportPageSize}
+	for {
+		page, err := s.entries.List(ctx, filter)
+		if err != nil {
+			return written, fmt.Errorf("list starred entries: %w", err)
+		}
+		for _, summary := range page {
+			exported, err := s.exportEntry(ctx, summary.ID, feedTitles)
+			if err != nil {
+				return written, err
+			}
+			if err := write(bw, exported); err != nil {
+				return written, err
+			}
+			written++
+		}
+		// Hand each page on, so a large export streams instead of piling up
+		if err := bw.Flush(); err != nil {
+			return written, err
+		}
+		if len(page) < exportPageSize {
+			return written, nil
+		}
+		last := page[len(page)-1]
+		filter.After = &repository.EntryCursor{PublishedAt: last.PublishedAt, ID: last.ID}
+	}
+}
+
+func (s *exportService) exportEntry(ctx context.Context, id int64, feedTitles map[int64]string) (ExportedEntry, error) {
+	entry, err := s.entries.GetByID(ctx, id)
+	if err != nil {
+		return ExportedEntry{}, fmt.Errorf("get entry %d: %w", id, err)
+	}
+	exported := ExportedEntry{
+		ID:          entry.ID,
+		FeedTitle:   feedTitles[entry.FeedID],
+		Title:       trimmedValue(entry.Title),
+		URL:         trimmedValue(entry.URL),
+		Author:      trimmedValue(entry.Author),
+		PublishedAt: entry.PublishedAt,
+		Content:     trimmedValue(entry.ReadableContent),
+	}
+	if exported.Content == "" {
+		exported.Content = trimmedValue(entry.Content)
+	}
+
+	summary, err := s.summaries.GetLatest(ctx, entry.ID)
+	if err != nil {
+		return ExportedEntry{}, fmt.Errorf("get summary of entry %d: %w", id, err)
+	}
+	if summary != nil {
+		exported.Summary = strings.TrimSpace(summary.Summary)
+		exported.SummaryLanguage = summary.Language
+	}
+	return exported, nil
+}
+
+func (s *exportService) feedTitles(ctx context.Context) (map[int64]string, error) {
+	feeds, err := s.feeds.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list feeds: %w", err)
+	}
+	titles := make(map[int64]string, len(feeds))
+	for _, feed := range feeds {
+		titles[feed.ID] = feed.Title
+	}
+	return titles, nil
+}
+
+// markdownLinkText escapes what would end the text of a Markdown link early.
+var markdownLinkText = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// writeMarkdownEntry writes an entry as a section of the Markdown digest:
+// its linked title, where it came from, the summary as a quote and the
+// content as plain text.
+func writeMarkdownEntry(w io.Writer, e ExportedEntry) error {
+	var b strings.Builder
+	title := e.Title
+	if title == "" {
+		title = "Untitled"
+	}
+	title = markdownLinkText.Replace(title)
+	if e.URL != "" {
+		fmt.Fprintf(&b, "\n## [%s](<%s>)\n\n", title, e.URL)
+	} else {
+		fmt.Fprintf(&b, "\n## %s\n\n", title)
+	}
+
+	var meta []string
+	for _, part := range []string{e.FeedTitle, e.Author} {
+		if part = strings.TrimSpace(part); part != "" {
+			meta = append(meta, part)
+		}
+	}
+	if e.PublishedAt != nil {
+		meta = append(meta, e.PublishedAt.UTC().Format("2006-01-02"))
+	}
+	if len(meta) > 0 {
+		fmt.Fprintf(&b, "*%s*\n\n", strings.Join(meta, " · "))
+	}
+
+	if e.Summary != "" {
+		for _, line := range strings.Split(e.Summary, "\n") {
+			b.WriteString(strings.TrimRight("> "+line, " ") + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if e.Content != "" {
+		if text := FormatContent(e.Content, e.URL, ContentFormatText); text != "" {
+			b.WriteString(text + "\n\n")
+		}
+	}
+	b.WriteString("---\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+// fakeSummaries serves cached summaries from a map, by entry ID.
+type fakeSummaries struct {
+	repository.AISummaryRepository
+	latest map[int64]*model.AISummary
+}
+
+func (f *fakeSummaries) GetLatest(_ context.Context, entryID int64) (*model.AISummary, error) {
+	return f.latest[entryID], nil
+}
+
+func TestExportService_ExportStarred(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	summaries := &fakeSummaries{latest: map[int64]*model.AISummary{
+		1: {EntryID: 1, Language: "en", Summary: "A short summary.\nSecond line."},
+	}}
+	service := NewExportService(entries, feeds, summaries)
+	ctx := context.Background()
+
+	published := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+	title, url := "Saved [draft]", "https://example.com/post"
+	readable, content := "<p>Readable text</p>", "<p>Feed text</p>"
+	stored := map[int64]model.Entry{
+		1: {ID: 1, FeedID: 7, Title: &title, URL: &url, Content: &content, ReadableContent: &readable, PublishedAt: &published},
+		2: {ID: 2, FeedID: 7, Content: &content},
+	}
+
+	feeds.EXPECT().List(ctx, nil).Return([]model.Feed{{ID: 7, Title: "Blog"}}, nil).Times(2)
+	entries.EXPECT().List(ctx, repository.EntryListFilter{StarredOnly: true, Limit: exportPageSize}).
+		Return([]model.EntrySummary{{ID: 1, PublishedAt: &published}, {ID: 2}}, nil).Times(2)
+	entries.EXPECT().GetByID(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, id int64) (model.Entry, error) {
+		return stored[id], nil
+	}).Times(4)
+
+	var out bytes.Buffer
+	n, err := service.ExportStarred(ctx, &out, ExportJSON)
+	if err != nil {
+		t.Fatalf("export json: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if n != 2 || len(lines) != 2 {
+		t.Fatalf("expected 2 entries, got %d in %d lines", n, len(lines))
+	}
+	var first ExportedEntry
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("parse line: %v", err)
+	}
+	if first.Content != readable || first.FeedTitle != "Blog" || first.SummaryLanguage != "en" {
+		t.Errorf("unexpected exported entry %+v", first)
+	}
+
+	out.Reset()
+	if _, err := service.ExportStarred(ctx, &out, ExportMarkdown); err != nil {
+		t.Fatalf("export markdown: %v", err)
+	}
+	md := out.String()
+	for _, want := range []string{
+		"# Starred entries\n",
+		"## [Saved \\[draft\\]](<https://example.com/post>)",
+		"*Blog · 2025-03-04*",
+		"> A short summary.\n> Second line.",
+		"Readable text",
+		"## Untitled",
+		"Feed text",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in digest:\n%s", want, md)
+		}
+	}
+}
+
+func TestExportService_ExportStarredPages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewExportService(entries, feeds, &fakeSummaries{})
+	ctx := context.Background()
+
+	page := make([]model.EntrySummary, exportPageSize)
+	for i := range page {
+		page[i] = model.EntrySummary{ID: int64(1000 - i)}
+	}
+	feeds.EXPECT().List(ctx, nil).Return(nil, nil)
+	gomock.InOrder(
+		entries.EXPECT().List(ctx, repository.EntryListFilter{StarredOnly: true, Limit: exportPageSize}).Return(page, nil),
+		entries.EXPECT().List(ctx, repository.EntryListFilter{
+			StarredOnly: true,
+			Limit:       exportPageSize,
+			After:       &repository.EntryCursor{ID: page[exportPageSize-1].ID},
+		}).Return(nil, nil),
+	)
+	entries.EXPECT().GetByID(ctx, gomock.Any()).Return(model.Entry{}, nil).Times(exportPageSize)
+
+	n, err := service.ExportStarred(ctx, &bytes.Buffer{}, ExportJSON)
+	if err != nil || n != exportPageSize {
+		t.Fatalf("expected %d entries, got %d (%v)", exportPageSize, n, err)
+	}
+}
+
+func TestExportService_ExportStarredRejectsUnknownFormat(t *testing.T) {
+	service := NewExportService(nil, nil, nil)
+	if _, err := service.ExportStarred(context.Background(), &bytes.Buffer{}, "pdf"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid, got %v", err)
+	}
+}
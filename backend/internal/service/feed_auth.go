@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+	"gist/backend/internal/secret"
+)
+
+// maxFeedAuthHeaders caps the custom headers a feed is fetched with.
+const maxFeedAuthHeaders = 20
+
+// reservedFeedHeaders are set by the fetch itself and cannot be overridden.
+var reservedFeedHeaders = map[string]bool{
+	"Host":              true,
+	"Content-Length":    true,
+	"If-None-Match":     true,
+	"If-Modified-Since": true,
+}
+
+// FeedCredentials keeps the credentials feeds are fetched with, sealed with
+// the instance's secret key so that a copy of the database, such as a
+// backup, does not give them away.
+type FeedCredentials struct {
+	feeds repository.FeedRepository
+	box   *secret.Box
+}
+
+func NewFeedCredentials(feeds repository.FeedRepository, box *secret.Box) *FeedCredentials {
+	return &FeedCredentials{feeds: feeds, box: box}
+}
+
+// Load sets feed.Auth from the stored credentials of a feed that has any.
+// A nil FeedCredentials loads nothing.
+func (c *FeedCredentials) Load(ctx context.Context, feed *model.Feed) error {
+	if c == nil || !feed.HasAuth || feed.Auth != nil {
+		return nil
+	}
+	sealed, err := c.feeds.GetAuth(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("get feed credentials: %w", err)
+	}
+	if sealed == nil {
+		return nil
+	}
+	data, err := c.box.Open(*sealed)
+	if err != nil {
+		return fmt.Errorf("open feed credentials: %w", err)
+	}
+	var auth model.FeedAuth
+	if err := json.Unmarshal(data, &auth); err != nil {
+		return fmt.Errorf("decode feed credentials: %w", err)
+	}
+	feed.Auth = &auth
+	return nil
+}
+
+// seal encrypts auth for storage; empty credentials seal to nil.
+func (c *FeedCredentials) seal(auth *model.FeedAuth) (*string, error) {
+	if auth == nil || auth.IsEmpty() {
+		return nil, nil
+	}
+	if c == nil {
+		return nil, fmt.Errorf("%w: feed credentials cannot be stored without a secret key", ErrInvalid)
+	}
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := c.box.Seal(data)
+	if err != nil {
+		return nil, fmt.Errorf("seal feed credentials: %w", err)
+	}
+	return &sealed, nil
+}
+
+// NormalizeFeedAuth trims auth and checks that it can be sent: a username
+// without a colon and valid header names and values, none of them a header
+// the fetch sets itself. Header names are canonicalized.
+func NormalizeFeedAuth(auth model.FeedAuth) (model.FeedAuth, error) {
+	normalized := model.FeedAuth{
+		Username: strings.TrimSpace(auth.Username),
+		Password: auth.Password,
+	}
+	if strings.Contains(normalized.Username, ":") {
+		return model.FeedAuth{}, fmt.Errorf("%w: username must not contain a colon", ErrInvalid)
+	}
+	headers, err := normalizeFeedHeaders(auth.Headers)
+	if err != nil {
+		return model.FeedAuth{}, err
+	}
+	normalized.Headers = headers
+	return normalized, nil
+}
+
+// normalizeFeedHeaders trims and canonicalizes headers a feed is fetched
+// with, checking they are valid and not set by the fetch itself.
+func normalizeFeedHeaders(headers map[string]string) (map[string]string, error) {
+	if len(headers) > maxFeedAuthHeaders {
+		return nil, fmt.Errorf("%w: at most %d headers", ErrInvalid, maxFeedAuthHeaders)
+	}
+	var normalized map[string]string
+	for name, value := range headers {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) {
+			return nil, fmt.Errorf("%w: invalid header %q", ErrInvalid, name)
+		}
+		if reservedFeedHeaders[name] {
+			return nil, fmt.Errorf("%w: header %s cannot be set", ErrInvalid, name)
+		}
+		if normalized == nil {
+			normalized = make(map[string]string)
+		}
+		normalized[name] = value
+	}
+	return normalized, nil
+}
+
+// applyFeedAuth adds a feed's credentials to a request for it. Custom headers
+// come last, so they can replace the User-Agent or a cookie.
+func applyFeedAuth(req *http.Request, auth *model.FeedAuth) {
+	if auth == nil {
+		return
+	}
+	if auth.Username != "" || auth.Password != "" {
+		req.SetBasicAuth(auth.Username, auth.Password)
+	}
+	for name, value := range auth.Headers {
+		req.Header.Set(name, value)
+	}
+}
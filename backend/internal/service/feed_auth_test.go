@@ -0,0 +1,146 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/secret"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestNormalizeFeedAuth(t *testing.T) {
+	auth, err := NormalizeFeedAuth(model.FeedAuth{
+		Username: " ann ",
+		Password: " pass ",
+		Headers:  map[string]string{"x-api-token": " abc "},
+	})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if auth.Username != "ann" || auth.Password != " pass " || auth.Headers["X-Api-Token"] != "abc" {
+		t.Errorf("unexpected credentials %+v", auth)
+	}
+
+	for _, bad := range []model.FeedAuth{
+		{Username: "ann:x"},
+		{Headers: map[string]string{"Bad Header": "x"}},
+		{Headers: map[string]string{"X-Token": "line\nbreak"}},
+		{Headers: map[string]string{"host": "example.com"}},
+	} {
+		if _, err := NormalizeFeedAuth(bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %+v to be invalid, got %v", bad, err)
+		}
+	}
+}
+
+func TestFeedCredentials_SealAndLoad(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	box, err := secret.NewBox(bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatalf("new box: %v", err)
+	}
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	credentials := NewFeedCredentials(mockFeeds, box)
+	ctx := context.Background()
+
+	auth := &model.FeedAuth{Username: "ann", Password: "secret", Headers: map[string]string{"X-Token": "abc"}}
+	sealed, err := credentials.seal(auth)
+	if err != nil || sealed == nil {
+		t.Fatalf("seal: %v, %v", sealed, err)
+	}
+	if bytes.Contains([]byte(*sealed), []byte("secret")) {
+		t.Fatalf("expected the password to be encrypted, got %s", *sealed)
+	}
+	if empty, err := credentials.seal(&model.FeedAuth{}); err != nil || empty != nil {
+		t.Errorf("expected empty credentials to seal to nil, got %v, %v", empty, err)
+	}
+
+	mockFeeds.EXPECT().GetAuth(ctx, int64(1)).Return(sealed, nil)
+	feed := model.Feed{ID: 1, HasAuth: true}
+	if err := credentials.Load(ctx, &feed); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if feed.Auth == nil || feed.Auth.Password != "secret" || feed.Auth.Headers["X-Token"] != "abc" {
+		t.Errorf("unexpected credentials %+v", feed.Auth)
+	}
+
+	// Feeds without credentials are not looked up
+	if err := credentials.Load(ctx, &model.Feed{ID: 2}); err != nil {
+		t.Errorf("expected no lookup, got %v", err)
+	}
+	var none *FeedCredentials
+	if _, err := none.seal(auth); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid without a secret key, got %v", err)
+	}
+}
+
+// authOnlyServer serves a feed only to requests carrying ann's credentials
+// and token.
+func authOnlyServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "ann" || pass != "secret" || r.Header.Get("X-Token") != "abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Private</title></channel></rss>`)
+	}))
+}
+
+func TestFeedService_PreviewWithAuth(t *testing.T) {
+	server := authOnlyServer(t)
+	defer server.Close()
+
+	service := NewFeedService(nil, nil, nil, nil, nil, server.Client(), nil, nil, nil)
+	ctx := context.Background()
+
+	if _, err := service.Preview(ctx, server.URL, nil, nil); !errors.Is(err, ErrFeedFetch) {
+		t.Fatalf("expected ErrFeedFetch without credentials, got %v", err)
+	}
+	auth := &model.FeedAuth{Username: "ann", Password: "secret", Headers: map[string]string{"x-token": "abc"}}
+	preview, err := service.Preview(ctx, server.URL, nil, auth)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.Title != "Private" {
+		t.Errorf("unexpected title %q", preview.Title)
+	}
+}
+
+func TestRefreshService_SendsFeedAuth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := authOnlyServer(t)
+	defer server.Close()
+
+	box, _ := secret.NewBox(bytes.Repeat([]byte{7}, 32))
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	credentials := NewFeedCredentials(mockFeeds, box)
+	service := NewRefreshService(mockFeeds, nil, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, nil, credentials, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	sealed, err := credentials.seal(&model.FeedAuth{Username: "ann", Password: "secret", Headers: map[string]string{"X-Token": "abc"}})
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Private", URL: server.URL, HasAuth: true}, nil)
+	mockFeeds.EXPECT().GetAuth(gomock.Any(), int64(1)).Return(sealed, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"gist/backend/internal/model"
+)
+
+const (
+	// maxBulkAddURLs caps the URLs of one bulk add.
+	maxBulkAddURLs = 200
+	// maxConcurrentBulkAdd is how many URLs of a bulk add are fetched at once.
+	maxConcurrentBulkAdd = 4
+)
+
+// Bulk add outcomes
+const (
+	BulkAddAdded   = "added"   // subscribed, or resubscribed to an archived feed
+	BulkAddExists  = "exists"  // already subscribed
+	BulkAddInvalid = "invalid" // not an http(s) URL
+	BulkAddFailed  = "failed"  // could not be fetched as a feed
+)
+
+// BulkAddResult is the outcome of one URL of a bulk add. Feed is set when the
+// URL was added or already subscribed, Error when it failed.
+type BulkAddResult struct {
+	URL    string
+	Status string
+	Feed   *model.Feed
+	Error  string
+}
+
+func (s *feedService) BulkAdd(ctx context.Context, urlList string, folderID *int64, feedType string) ([]BulkAddResult, error) {
+	urls := splitURLList(urlList)
+	if len(urls) == 0 || len(urls) > maxBulkAddURLs {
+		return nil, ErrInvalid
+	}
+	if folderID != nil {
+		if _, err := s.folders.GetByID(ctx, *folderID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrNotFound
+			}
+			return nil, fmt.Errorf("check folder: %w", err)
+		}
+	}
+
+	results := make([]BulkAddResult, len(urls))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentBulkAdd)
+	for i, feedURL := range urls {
+		g.Go(func() error {
+			results[i] = s.bulkAddOne(gctx, feedURL, folderID, feedType)
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return results, nil
+}
+
+// bulkAddOne subscribes to one URL of a bulk add. Unlike Add, a URL that does
+// not fetch as a feed is reported rather than subscribed to: a pasted list
+// often holds site addresses too.
+func (s *feedService) bulkAddOne(ctx context.Context, feedURL string, folderID *int64, feedType string) BulkAddResult {
+	result := BulkAddResult{URL: feedURL}
+	if !isValidURL(feedURL) {
+		result.Status = BulkAddInvalid
+		return result
+	}
+	fail := func(err error) BulkAddResult {
+		result.Status = BulkAddFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	existing, err := s.feeds.FindByURL(ctx, feedURL)
+	if err != nil {
+		return fail(fmt.Errorf("check feed url: %w", err))
+	}
+	if existing != nil && existing.ArchivedAt == nil {
+		result.Status = BulkAddExists
+		result.Feed = existing
+		return result
+	}
+
+	var feed model.Feed
+	if existing != nil {
+		feed, err = s.restoreArchived(ctx, *existing, folderID, "", feedType, nil)
+	} else {
+		var fetched feedFetch
+		fetched, err = s.fetchFeed(ctx, feedURL, nil, nil)
+		if err != nil {
+			return fail(err)
+		}
+		target := folderID
+		if target == nil {
+			if target, err = s.categoryFolder(ctx, fetched.categories, feedType); err != nil {
+				return fail(err)
+			}
+		}
+		feed, err = s.createWithSideEffects(ctx, fetchedFeed(feedURL, target, "", feedType, nil, fetched), fetched)
+	}
+	if err != nil {
+		return fail(err)
+	}
+	result.Status = BulkAddAdded
+	result.Feed = &feed
+	return result
+}
+
+// splitURLList returns the URLs of a newline-separated list in order, without
+// blank lines and repeats.
+func splitURLList(list string) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+		urls = append(urls, line)
+	}
+	return urls
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+)
+
+func TestSplitURLList(t *testing.T) {
+	got := splitURLList(" https://a.example.com/feed \r\n\nhttps://b.example.com/rss\nhttps://a.example.com/feed\n")
+	want := []string{"https://a.example.com/feed", "https://b.example.com/rss"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFeedService_BulkAdd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><body>A blog, not a feed</body></html>"))
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewFeedService(nil, mockFeeds, nil, nil, nil, server.Client(), nil, nil, nil)
+	ctx := context.Background()
+
+	subscribed := model.Feed{ID: 1, Title: "Subscribed", URL: "https://example.com/feed"}
+	mockFeeds.EXPECT().FindByURL(gomock.Any(), subscribed.URL).Return(&subscribed, nil)
+	mockFeeds.EXPECT().FindByURL(gomock.Any(), server.URL).Return(nil, nil)
+
+	list := strings.Join([]string{"https://example.com/feed", "not a url", server.URL}, "\n")
+	results, err := service.BulkAdd(ctx, list, nil, "article")
+	if err != nil {
+		t.Fatalf("bulk add: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("expected a result per URL, got %+v", results)
+	}
+	if results[0].Status != BulkAddExists || results[0].Feed == nil || results[0].Feed.ID != 1 {
+		t.Errorf("expected the subscribed feed to be reported, got %+v", results[0])
+	}
+	if results[1].Status != BulkAddInvalid {
+		t.Errorf("expected an invalid URL, got %+v", results[1])
+	}
+	if results[2].Status != BulkAddFailed || results[2].Error == "" || results[2].Feed != nil {
+		t.Errorf("expected a page that is not a feed to fail, got %+v", results[2])
+	}
+}
+
+func TestFeedService_BulkAdd_Rejects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	service := NewFeedService(nil, nil, mockFolders, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	if _, err := service.BulkAdd(ctx, " \n\n", nil, "article"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for an empty list, got %v", err)
+	}
+	var many strings.Builder
+	for i := 0; i <= maxBulkAddURLs; i++ {
+		many.WriteString("https://example.com/" + strings.Repeat("a", i) + "\n")
+	}
+	if _, err := service.BulkAdd(ctx, many.String(), nil, "article"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for too many URLs, got %v", err)
+	}
+
+	folderID := int64(9)
+	mockFolders.EXPECT().GetByID(ctx, folderID).Return(model.Folder{}, sql.ErrNoRows)
+	if _, err := service.BulkAdd(ctx, "https://example.com/feed", &folderID, "article"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing folder, got %v", err)
+	}
+}
+
+func TestFeedService_CategoryFolder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	values := map[string]string{keyCategoryFolders: "true"}
+	settings := NewSettingsService(&memorySettings{values: values}, nil)
+	service := NewFeedService(nil, nil, mockFolders, nil, settings, nil, nil, nil, nil).(*feedService)
+	ctx := context.Background()
+
+	// A new folder is created for the first category
+	mockFolders.EXPECT().FindByName(ctx, "Tech", nil).Return(nil, nil)
+	mockFolders.EXPECT().Create(ctx, "Tech", nil, "article").Return(model.Folder{ID: 3, Name: "Tech", Type: "article"}, nil)
+	folderID, err := service.categoryFolder(ctx, []string{" ", "Tech", "News"}, "article")
+	if err != nil || folderID == nil || *folderID != 3 {
+		t.Fatalf("expected the new folder, got %v, %v", folderID, err)
+	}
+
+	// A folder of another content type is left alone
+	mockFolders.EXPECT().FindByName(ctx, "Tech", nil).Return(&model.Folder{ID: 3, Name: "Tech", Type: "article"}, nil)
+	if folderID, err := service.categoryFolder(ctx, []string{"Tech"}, "picture"); err != nil || folderID != nil {
+		t.Errorf("expected no folder for another content type, got %v, %v", folderID, err)
+	}
+
+	// Feeds without categories stay unfiled
+	if folderID, err := service.categoryFolder(ctx, nil, "article"); err != nil || folderID != nil {
+		t.Errorf("expected no folder without categories, got %v, %v", folderID, err)
+	}
+
+	// Nothing is filed while the setting is off
+	values[keyCategoryFolders] = "false"
+	if folderID, err := service.categoryFolder(ctx, []string{"Tech"}, "article"); err != nil || folderID != nil {
+		t.Errorf("expected no folder with the setting off, got %v, %v", folderID, err)
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"gist/backend/internal/model"
+)
+
+// zoneSuffix matches the end of a date that names its offset or zone, such
+// as Z, +08:00, -0500, GMT or EST. Dates without one are local to the
+// publisher and read as UTC unless the feed sets a time zone.
+var zoneSuffix = regexp.MustCompile(`(?:Z|[+-]\d{2}:?\d{2}|\bUT|\b[A-Z]{3,5})\)?$`)
+
+// numericDate matches dates written as three numbers, such as 03/04/2025,
+// which are ambiguous between month-first and day-first.
+var numericDate = regexp.MustCompile(`^\d{1,2}[/.-]\d{1,2}[/.-]\d{4}\b`)
+
+// dayFirstLayouts read numeric dates as day, month, year.
+var dayFirstLayouts = []string{
+	"2/1/2006 15:04:05 -0700",
+	"2/1/2006 15:04:05",
+	"2/1/2006 15:04",
+	"2/1/2006",
+	"2.1.2006 15:04:05 -0700",
+	"2.1.2006 15:04:05",
+	"2.1.2006 15:04",
+	"2.1.2006",
+	"2-1-2006 15:04:05 -0700",
+	"2-1-2006 15:04:05",
+	"2-1-2006 15:04",
+	"2-1-2006",
+}
+
+// NormalizeDateQuirks checks the date corrections of a feed and trims its
+// time zone. Returns ErrInvalid for a zone the system does not know.
+func NormalizeDateQuirks(quirks model.FeedDateQuirks) (model.FeedDateQuirks, error) {
+	quirks.Timezone = strings.TrimSpace(quirks.Timezone)
+	if quirks.Timezone != "" {
+		if _, err := time.LoadLocation(quirks.Timezone); err != nil {
+			return model.FeedDateQuirks{}, fmt.Errorf("%w: unknown time zone %q", ErrInvalid, quirks.Timezone)
+		}
+	}
+	return quirks, nil
+}
+
+// normalizeOptionalDateQuirks runs NormalizeDateQuirks on quirks unless it
+// is nil. Empty quirks are nil.
+func normalizeOptionalDateQuirks(quirks *model.FeedDateQuirks) (*model.FeedDateQuirks, error) {
+	if quirks == nil {
+		return nil, nil
+	}
+	normalized, err := NormalizeDateQuirks(*quirks)
+	if err != nil {
+		return nil, err
+	}
+	if normalized.IsEmpty() {
+		return nil, nil
+	}
+	return &normalized, nil
+}
+
+// itemDates reads the dates of a feed's items under its date corrections.
+type itemDates struct {
+	loc          *time.Location
+	dayFirst     bool
+	ignoreFuture bool
+	now          time.Time
+}
+
+// newItemDates applies quirks, nil for none, to the items of a fetch that
+// began at now. A time zone that no longer loads is read as UTC.
+func newItemDates(quirks *model.FeedDateQuirks, now time.Time) itemDates {
+	d := itemDates{loc: time.UTC, now: now}
+	if quirks == nil {
+		return d
+	}
+	if quirks.Timezone != "" {
+		if loc, err := time.LoadLocation(quirks.Timezone); err == nil {
+			d.loc = loc
+		}
+	}
+	d.dayFirst = quirks.DayFirst
+	d.ignoreFuture = quirks.IgnoreFuture
+	return d
+}
+
+// read returns the date raw stands for, given the date the feed parser
+// made of it (nil when it could not).
+func (d itemDates) read(raw string, parsed *time.Time) *time.Time {
+	raw = strings.TrimSpace(raw)
+	if d.dayFirst && numericDate.MatchString(raw) {
+		for _, layout := range dayFirstLayouts {
+			if t, err := time.ParseInLocation(layout, raw, d.loc); err == nil {
+				utc := t.UTC()
+				return &utc
+			}
+		}
+	}
+	if parsed == nil {
+		return nil
+	}
+	t := *parsed
+	if d.loc != nil && d.loc != time.UTC && raw != "" && !zoneSuffix.MatchString(raw) {
+		// The parser took the publisher's wall clock for UTC
+		t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), d.loc)
+	}
+	t = t.UTC()
+	return &t
+}
+
+// future reports whether t is too far ahead to be trusted.
+func (d itemDates) future(t *time.Time) bool {
+	return t != nil && t.After(d.now.Add(futureDateSkew))
+}
+
+// published picks the publish date of an item: its published date, else
+// its updated date unless the feed stamps every item with the fetch time.
+// When the feed ignores future dates, a future published date gives way to
+// an updated date that is not.
+func (d itemDates) published(item *gofeed.Item, ignoreDynamicTime bool) *time.Time {
+	published := d.read(item.Published, item.PublishedParsed)
+	if ignoreDynamicTime {
+		return published
+	}
+	if published != nil && !(d.ignoreFuture && d.future(published)) {
+		return published
+	}
+	if updated := d.read(item.Updated, item.UpdatedParsed); updated != nil && (published == nil || !d.future(updated)) {
+		return updated
+	}
+	return published
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"gist/backend/internal/model"
+)
+
+func parseDatedItem(t *testing.T, pubDate, updated string) *gofeed.Item {
+	t.Helper()
+	xml := `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>t</title><entry><title>a</title>`
+	if pubDate != "" {
+		xml += `<published>` + pubDate + `</published>`
+	}
+	if updated != "" {
+		xml += `<updated>` + updated + `</updated>`
+	}
+	xml += `</entry></feed>`
+	parsed, err := gofeed.NewParser().ParseString(xml)
+	if err != nil {
+		t.Fatalf("parse feed: %v", err)
+	}
+	return parsed.Items[0]
+}
+
+func TestItemDates_Published(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		quirks    *model.FeedDateQuirks
+		published string
+		updated   string
+		want      string // RFC3339 in UTC, "" for none
+	}{
+		{name: "as given", published: "2025-03-04T10:00:00", want: "2025-03-04T10:00:00Z"},
+		{name: "naive date in feed zone", quirks: &model.FeedDateQuirks{Timezone: "Asia/Shanghai"}, published: "2025-03-04T10:00:00", want: "2025-03-04T02:00:00Z"},
+		{name: "offset kept", quirks: &model.FeedDateQuirks{Timezone: "Asia/Shanghai"}, published: "2025-03-04T10:00:00+01:00", want: "2025-03-04T09:00:00Z"},
+		{name: "zone name kept", quirks: &model.FeedDateQuirks{Timezone: "Asia/Shanghai"}, published: "Tue, 04 Mar 2025 10:00:00 GMT", want: "2025-03-04T10:00:00Z"},
+		{name: "day first", quirks: &model.FeedDateQuirks{DayFirst: true}, published: "03/04/2025 10:30", want: "2025-04-03T10:30:00Z"},
+		{name: "day first in zone", quirks: &model.FeedDateQuirks{DayFirst: true, Timezone: "Europe/Berlin"}, published: "13.01.2025", want: "2025-01-12T23:00:00Z"},
+		{name: "future kept", published: "2025-09-01T00:00:00Z", updated: "2025-05-01T00:00:00Z", want: "2025-09-01T00:00:00Z"},
+		{name: "future ignored for updated", quirks: &model.FeedDateQuirks{IgnoreFuture: true}, published: "2025-09-01T00:00:00Z", updated: "2025-05-01T00:00:00Z", want: "2025-05-01T00:00:00Z"},
+		{name: "future left to clamp", quirks: &model.FeedDateQuirks{IgnoreFuture: true}, published: "2025-09-01T00:00:00Z", updated: "2025-10-01T00:00:00Z", want: "2025-09-01T00:00:00Z"},
+		{name: "updated when unpublished", updated: "2025-05-01T00:00:00Z", want: "2025-05-01T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := parseDatedItem(t, tt.published, tt.updated)
+			got := newItemDates(tt.quirks, now).published(item, false)
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("got %v, want none", got)
+			case tt.want != "" && (got == nil || got.Format(time.RFC3339) != tt.want):
+				t.Errorf("got %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeDateQuirks(t *testing.T) {
+	quirks, err := NormalizeDateQuirks(model.FeedDateQuirks{Timezone: " America/New_York ", DayFirst: true})
+	if err != nil || quirks.Timezone != "America/New_York" || !quirks.DayFirst {
+		t.Errorf("got %+v, %v", quirks, err)
+	}
+	if _, err := NormalizeDateQuirks(model.FeedDateQuirks{Timezone: "Mars/Olympus"}); !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), "Mars/Olympus") {
+		t.Errorf("expected unknown zone to be invalid, got %v", err)
+	}
+}
+
+func TestFutureDates_ForFeed(t *testing.T) {
+	dates := futureDates{mode: FutureDatesKeep}
+	if got := dates.forFeed(model.Feed{}).mode; got != FutureDatesKeep {
+		t.Errorf("got %q for a feed without quirks", got)
+	}
+	if got := dates.forFeed(model.Feed{DateQuirks: &model.FeedDateQuirks{IgnoreFuture: true}}).mode; got != FutureDatesClamp {
+		t.Errorf("got %q for a feed ignoring future dates, want clamp", got)
+	}
+}
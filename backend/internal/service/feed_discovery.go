@@ -0,0 +1,234 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/sync/errgroup"
+
+	"gist/backend/internal/config"
+)
+
+const (
+	// maxDiscoveryPageSize caps the page read when looking for its feeds.
+	maxDiscoveryPageSize = 2 << 20
+	// maxDiscoveredLinks bounds how many feed links of a page are checked.
+	maxDiscoveredLinks = 10
+	// maxConcurrentDiscovery is how many candidates are fetched at once.
+	maxConcurrentDiscovery = 4
+)
+
+// discoveryPaths are where sites commonly serve their feed, tried at the
+// site root when the page links none.
+var discoveryPaths = []string{"/feed", "/rss.xml", "/atom.xml", "/feed.xml", "/index.xml", "/rss"}
+
+// feedLinkTypes are the link types announcing a feed.
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/json":      true,
+	"application/rdf+xml":   true,
+}
+
+// Where a discovered feed was found
+const (
+	DiscoveredDirect = "direct" // the URL itself is a feed
+	DiscoveredLink   = "link"   // a <link rel="alternate"> of the page
+	DiscoveredPath   = "path"   // a common feed path of the site
+)
+
+// FeedCandidate is a feed found on a website, checked to fetch as one.
+type FeedCandidate struct {
+	URL       string
+	Title     string
+	Source    string
+	ItemCount *int
+}
+
+// feedLink is a feed announced by a page, before it was checked.
+type feedLink struct {
+	url   string
+	title string
+}
+
+func (s *feedService) Discover(ctx context.Context, pageURL string) ([]FeedCandidate, error) {
+	trimmedURL := strings.TrimSpace(pageURL)
+	if !isValidURL(trimmedURL) {
+		return nil, ErrInvalid
+	}
+
+	body, finalURL, err := s.fetchDiscoveryPage(ctx, trimmedURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed, err := newFeedParser().Parse(bytes.NewReader(body)); err == nil {
+		title := strings.TrimSpace(parsed.Title)
+		if title == "" {
+			title = trimmedURL
+		}
+		var itemCount *int
+		if parsed.Items != nil {
+			count := len(parsed.Items)
+			itemCount = &count
+		}
+		return []FeedCandidate{{URL: trimmedURL, Title: title, Source: DiscoveredDirect, ItemCount: itemCount}}, nil
+	}
+
+	links := pageFeedLinks(body, finalURL)
+	if len(links) > maxDiscoveredLinks {
+		links = links[:maxDiscoveredLinks]
+	}
+	candidates := s.checkCandidates(ctx, links, DiscoveredLink)
+	if len(candidates) > 0 {
+		return candidates, nil
+	}
+
+	// The page links no feed; look where sites usually put one
+	root, err := url.Parse(finalURL)
+	if err != nil {
+		return []FeedCandidate{}, nil
+	}
+	paths := make([]feedLink, len(discoveryPaths))
+	for i, path := range discoveryPaths {
+		paths[i] = feedLink{url: (&url.URL{Scheme: root.Scheme, Host: root.Host, Path: path}).String()}
+	}
+	return s.checkCandidates(ctx, paths, DiscoveredPath), nil
+}
+
+// fetchDiscoveryPage fetches the page feeds are looked for on, returning its
+// body and the URL it was served from after redirects.
+func (s *feedService) fetchDiscoveryPage(ctx context.Context, pageURL string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, "", ErrFeedFetch
+	}
+	req.Header.Set("User-Agent", config.DefaultUserAgent)
+	req.Header.Set("Accept", "text/html, application/xhtml+xml, "+feedAccept)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, "", ErrFeedFetch
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, "", ErrFeedFetch
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDiscoveryPageSize))
+	if err != nil {
+		return nil, "", ErrFeedFetch
+	}
+	return body, resp.Request.URL.String(), nil
+}
+
+// checkCandidates fetches each link as a feed and returns those that are
+// one, in the order of links. Comment feeds, which WordPress and others link
+// next to the posts feed, come last.
+func (s *feedService) checkCandidates(ctx context.Context, links []feedLink, source string) []FeedCandidate {
+	found := make([]*FeedCandidate, len(links))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentDiscovery)
+	for i, link := range links {
+		g.Go(func() error {
+			fetched, err := s.fetchFeed(gctx, link.url, nil, nil)
+			if err != nil {
+				return nil
+			}
+			title := strings.TrimSpace(fetched.title)
+			if title == "" {
+				title = link.title
+			}
+			if title == "" {
+				title = link.url
+			}
+			found[i] = &FeedCandidate{URL: link.url, Title: title, Source: source, ItemCount: fetched.itemCount}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	candidates := make([]FeedCandidate, 0, len(links))
+	var comments []FeedCandidate
+	seen := make(map[string]bool)
+	for i, candidate := range found {
+		if candidate == nil || seen[candidate.URL] {
+			continue
+		}
+		seen[candidate.URL] = true
+		if isCommentFeed(links[i].title, candidate.URL) {
+			comments = append(comments, *candidate)
+		} else {
+			candidates = append(candidates, *candidate)
+		}
+	}
+	return append(candidates, comments...)
+}
+
+func isCommentFeed(title, feedURL string) bool {
+	title = strings.ToLower(title)
+	return strings.Contains(title, "comment") || strings.Contains(strings.ToLower(feedURL), "/comments/")
+}
+
+// pageFeedLinks returns the feeds an HTML page announces with
+// <link rel="alternate"> in its head, resolved against base, in page order.
+func pageFeedLinks(body []byte, base string) []feedLink {
+	var links []feedLink
+	seen := make(map[string]bool)
+	z := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return links
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "body":
+				return links
+			case "link":
+				if !hasAttr {
+					continue
+				}
+				var rel, typ, href, title string
+				for {
+					key, val, more := z.TagAttr()
+					switch string(key) {
+					case "rel":
+						rel = strings.ToLower(string(val))
+					case "type":
+						typ = strings.ToLower(strings.TrimSpace(string(val)))
+					case "href":
+						href = strings.TrimSpace(string(val))
+					case "title":
+						title = strings.TrimSpace(string(val))
+					}
+					if !more {
+						break
+					}
+				}
+				if href == "" || !feedLinkTypes[typ] || !hasRel(rel, "alternate") {
+					continue
+				}
+				resolved := resolveLink(base, href)
+				if !isValidURL(resolved) || seen[resolved] {
+					continue
+				}
+				seen[resolved] = true
+				links = append(links, feedLink{url: resolved, title: title})
+			}
+		}
+	}
+}
+
+func hasRel(rels, rel string) bool {
+	for _, r := range strings.Fields(rels) {
+		if r == rel {
+			return true
+		}
+	}
+	return false
+}
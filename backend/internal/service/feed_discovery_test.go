@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newDiscoveryServer(t *testing.T, pages map[string]string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, ok := pages[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(page))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFeedService_DiscoverLinkedFeeds(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{
+		"/blog/": `<html><head><title>Blog</title>
+			<link rel="alternate" type="application/rss+xml" title="Comments Feed" href="/comments/feed">
+			<link rel="alternate" type="application/atom+xml" title="Posts" href="posts.xml">
+			<link rel="alternate" type="application/rss+xml" href="/missing.xml">
+			<link rel="stylesheet" type="text/css" href="/style.css">
+			</head><body><link rel="alternate" type="application/rss+xml" href="/body.xml"></body></html>`,
+		"/comments/feed":  `<?xml version="1.0"?><rss version="2.0"><channel><title>Comments</title></channel></rss>`,
+		"/blog/posts.xml": `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>Posts feed</title><entry><title>Post</title></entry></feed>`,
+		"/body.xml":       `<?xml version="1.0"?><rss version="2.0"><channel><title>Body</title></channel></rss>`,
+	})
+	service := NewFeedService(nil, nil, nil, nil, nil, server.Client(), nil, nil, nil)
+
+	candidates, err := service.Discover(context.Background(), server.URL+"/blog/")
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %+v", candidates)
+	}
+	if candidates[0].URL != server.URL+"/blog/posts.xml" || candidates[0].Title != "Posts feed" || candidates[0].Source != DiscoveredLink {
+		t.Errorf("expected the posts feed first, got %+v", candidates[0])
+	}
+	if candidates[0].ItemCount == nil || *candidates[0].ItemCount != 1 {
+		t.Errorf("expected the item count, got %v", candidates[0].ItemCount)
+	}
+	if candidates[1].URL != server.URL+"/comments/feed" {
+		t.Errorf("expected the comments feed last, got %+v", candidates[1])
+	}
+}
+
+func TestFeedService_DiscoverCommonPaths(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{
+		"/":         `<html><head><title>Site</title></head><body>No feed links</body></html>`,
+		"/atom.xml": `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>Site feed</title></feed>`,
+	})
+	service := NewFeedService(nil, nil, nil, nil, nil, server.Client(), nil, nil, nil)
+
+	candidates, err := service.Discover(context.Background(), server.URL+"/")
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].URL != server.URL+"/atom.xml" || candidates[0].Source != DiscoveredPath {
+		t.Fatalf("expected the feed at /atom.xml, got %+v", candidates)
+	}
+}
+
+func TestFeedService_DiscoverFeedURL(t *testing.T) {
+	server := newDiscoveryServer(t, map[string]string{
+		"/feed": `<?xml version="1.0"?><rss version="2.0"><channel><title>Direct</title></channel></rss>`,
+	})
+	service := NewFeedService(nil, nil, nil, nil, nil, server.Client(), nil, nil, nil)
+
+	candidates, err := service.Discover(context.Background(), server.URL+"/feed")
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(candidates) != 1 || candidates[0].Title != "Direct" || candidates[0].Source != DiscoveredDirect {
+		t.Fatalf("expected the URL itself, got %+v", candidates)
+	}
+
+	if _, err := service.Discover(context.Background(), "ftp://example.com"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid, got %v", err)
+	}
+	if _, err := service.Discover(context.Background(), server.URL+"/gone"); !errors.Is(err, ErrFeedFetch) {
+		t.Errorf("expected ErrFeedFetch, got %v", err)
+	}
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+// feedFetchLogSize is how many refresh attempts are kept per feed.
+const feedFetchLogSize = 50
+
+// feedHealthHistory is how many attempts the health of one feed lists.
+const feedHealthHistory = 20
+
+// Feed health states
+const (
+	FeedHealthy = "healthy"
+	FeedFailing = "failing" // the latest attempt failed
+	FeedUnknown = "unknown" // no attempt recorded yet
+)
+
+// FeedHealth sums up the recent refresh attempts of a feed.
+type FeedHealth struct {
+	FeedID int64  `json:"feedId,string"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	// ConsecutiveFailures counts the failed attempts since the last success.
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	Attempts            int        `json:"attempts"`
+	Failures            int        `json:"failures"`
+	AvgDurationMs       int64      `json:"avgDurationMs"`
+	LastAttemptAt       *time.Time `json:"lastAttemptAt,omitempty"`
+	LastSuccessAt       *time.Time `json:"lastSuccessAt,omitempty"`
+	// LastError is the error of the latest failed attempt.
+	LastError *string `json:"lastError,omitempty"`
+	// CooldownUntil is set while the feed's host is left alone after it
+	// answered 429 Too Many Requests; refreshes skip the feed until then.
+	CooldownUntil *time.Time `json:"cooldownUntil,omitempty"`
+	// History lists the latest attempts, newest first; only the health of a
+	// single feed has it.
+	History []FeedFetchAttempt `json:"history,omitempty"`
+}
+
+// FeedFetchAttempt is one refresh attempt of a feed.
+type FeedFetchAttempt struct {
+	FetchedAt  time.Time `json:"fetchedAt"`
+	Status     string    `json:"status"` // one of FetchOutcomes
+	HTTPStatus *int      `json:"httpStatus,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+	NewEntries int       `json:"newEntries"`
+	Error      *string   `json:"error,omitempty"`
+}
+
+type FeedHealthService interface {
+	// Record logs a refresh attempt. Failures are only logged: recording
+	// never fails a refresh.
+	Record(ctx context.Context, fetch model.FeedFetch)
+	// Feed returns the health of a feed with its latest attempts.
+	Feed(ctx context.Context, feedID int64) (FeedHealth, error)
+	// List returns the health of every fetched feed, those failing the
+	// longest first.
+	List(ctx context.Context) ([]FeedHealth, error)
+	// CoolDown leaves host alone until the given time, after it answered
+	// 429 Too Many Requests. Every feed on the host shares the cooldown; a
+	// shorter one does not cut a running one short.
+	CoolDown(host string, until time.Time)
+	// CooldownUntil returns when the cooldown of host ends, nil when it has
+	// none running.
+	CooldownUntil(host string) *time.Time
+}
+
+type feedHealthService struct {
+	fetches   repository.FeedFetchRepository
+	feeds     repository.FeedRepository
+	mu        sync.Mutex
+	cooldowns map[string]time.Time // by host
+}
+
+func NewFeedHealthService(fetches repository.FeedFetchRepository, feeds repository.FeedRepository) FeedHealthService {
+	return &feedHealthService{fetches: fetches, feeds: feeds, cooldowns: make(map[string]time.Time)}
+}
+
+func (s *feedHealthService) Record(ctx context.Context, fetch model.FeedFetch) {
+	// A refresh cut short by its context still was an attempt
+	if err := s.fetches.Add(context.WithoutCancel(ctx), fetch, feedFetchLogSize); err != nil {
+		log.Printf("record fetch of feed %d: %v", fetch.FeedID, err)
+	}
+}
+
+func (s *feedHealthService) Feed(ctx context.Context, feedID int64) (FeedHealth, error) {
+	feed, err := s.feeds.GetByID(ctx, feedID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return FeedHealth{}, ErrNotFound
+		}
+		return FeedHealth{}, err
+	}
+	fetches, err := s.fetches.ListByFeed(ctx, feedID, feedFetchLogSize)
+	if err != nil {
+		return FeedHealth{}, err
+	}
+
+	health := summarizeFetches(feed, fetches)
+	health.CooldownUntil = s.CooldownUntil(extractHost(feed.URL))
+	health.History = []FeedFetchAttempt{}
+	for i, fetch := range fetches {
+		if i == feedHealthHistory {
+			break
+		}
+		health.History = append(health.History, fetchAttempt(fetch))
+	}
+	return health, nil
+}
+
+func fetchAttempt(fetch model.FeedFetch) FeedFetchAttempt {
+	return FeedFetchAttempt{
+		FetchedAt:  fetch.FetchedAt,
+		Status:     fetch.Status,
+		HTTPStatus: fetch.HTTPStatus,
+		DurationMs: fetch.Duration.Milliseconds(),
+		NewEntries: fetch.NewEntries,
+		Error:      fetch.Error,
+	}
+}
+
+func (s *feedHealthService) List(ctx context.Context) ([]FeedHealth, error) {
+	feeds, err := s.feeds.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	fetches, err := s.fetches.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byFeed := make(map[int64][]model.FeedFetch)
+	for _, fetch := range fetches {
+		byFeed[fetch.FeedID] = append(byFeed[fetch.FeedID], fetch)
+	}
+
+	health := []FeedHealth{}
+	for _, feed := range feeds {
+		if feed.IsVirtual() {
+			continue
+		}
+		feedHealth := summarizeFetches(feed, byFeed[feed.ID])
+		feedHealth.CooldownUntil = s.CooldownUntil(extractHost(feed.URL))
+		health = append(health, feedHealth)
+	}
+	sort.SliceStable(health, func(i, j int) bool {
+		a, b := health[i], health[j]
+		if a.ConsecutiveFailures != b.ConsecutiveFailures {
+			return a.ConsecutiveFailures > b.ConsecutiveFailures
+		}
+		// Among equally failing feeds, the one without a success for longest
+		if a.LastSuccessAt == nil || b.LastSuccessAt == nil {
+			return a.LastSuccessAt == nil && b.LastSuccessAt != nil
+		}
+		return a.LastSuccessAt.Before(*b.LastSuccessAt)
+	})
+	return health, nil
+}
+
+// summarizeFetches sums up the attempts of a feed, given newest first.
+func summarizeFetches(feed model.Feed, fetches []model.FeedFetch) FeedHealth {
+	health := FeedHealth{FeedID: feed.ID, Title: feed.Title, Status: FeedUnknown, Attempts: len(fetches)}
+	if len(fetches) == 0 {
+		return health
+	}
+
+	var total time.Duration
+	succeeded := false
+	for i := range fetches {
+		fetch := &fetches[i]
+		total += fetch.Duration
+		failed := fetch.Status == FetchHTTPError || fetch.Status == FetchError
+		if failed {
+			health.Failures++
+			if health.LastError == nil {
+				health.LastError = fetch.Error
+			}
+		} else if !succeeded {
+			health.LastSuccessAt = &fetch.FetchedAt
+		}
+		if !succeeded && failed {
+			health.ConsecutiveFailures++
+		}
+		succeeded = succeeded || !failed
+	}
+	health.AvgDurationMs = (total / time.Duration(len(fetches))).Milliseconds()
+	health.LastAttemptAt = &fetches[0].FetchedAt
+	health.Status = FeedHealthy
+	if health.ConsecutiveFailures > 0 {
+		health.Status = FeedFailing
+	}
+	return health
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestSummarizeFetches(t *testing.T) {
+	start := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	timeout, notFound := "timeout", "HTTP 404"
+	fetches := []model.FeedFetch{ // newest first
+		{FetchedAt: start.Add(4 * time.Hour), Status: FetchError, Duration: 400 * time.Millisecond, Error: &timeout},
+		{FetchedAt: start.Add(3 * time.Hour), Status: FetchHTTPError, Duration: 200 * time.Millisecond, Error: &notFound},
+		{FetchedAt: start.Add(2 * time.Hour), Status: FetchNotModified, Duration: 100 * time.Millisecond},
+		{FetchedAt: start.Add(1 * time.Hour), Status: FetchHTTPError, Duration: 200 * time.Millisecond, Error: &notFound},
+		{FetchedAt: start, Status: FetchOK, Duration: 100 * time.Millisecond},
+	}
+
+	health := summarizeFetches(model.Feed{ID: 1, Title: "Blog"}, fetches)
+	if health.Status != FeedFailing || health.ConsecutiveFailures != 2 || health.Failures != 3 || health.Attempts != 5 {
+		t.Errorf("unexpected counts %+v", health)
+	}
+	if health.AvgDurationMs != 200 {
+		t.Errorf("expected an average of 200ms, got %d", health.AvgDurationMs)
+	}
+	if health.LastSuccessAt == nil || !health.LastSuccessAt.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("expected the not-modified attempt as last success, got %v", health.LastSuccessAt)
+	}
+	if health.LastAttemptAt == nil || !health.LastAttemptAt.Equal(start.Add(4*time.Hour)) || health.LastError == nil || *health.LastError != timeout {
+		t.Errorf("unexpected last attempt %v, error %v", health.LastAttemptAt, health.LastError)
+	}
+
+	if unknown := summarizeFetches(model.Feed{ID: 2}, nil); unknown.Status != FeedUnknown || unknown.LastAttemptAt != nil {
+		t.Errorf("expected an unknown feed without attempts, got %+v", unknown)
+	}
+}
+
+func TestFeedHealthService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFetches := testutil.NewMockFeedFetchRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewFeedHealthService(mockFetches, mockFeeds)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	mockFeeds.EXPECT().List(ctx, nil).Return([]model.Feed{
+		{ID: 1, Title: "Fine", URL: "https://a.example/feed"},
+		{ID: 2, Title: "Dying", URL: "https://b.example/feed"},
+		{ID: 3, Title: "Saved pages", URL: "gist:saved"},
+	}, nil)
+	mockFetches.EXPECT().ListAll(ctx).Return([]model.FeedFetch{
+		{FeedID: 1, FetchedAt: now, Status: FetchOK},
+		{FeedID: 2, FetchedAt: now, Status: FetchError},
+		{FeedID: 2, FetchedAt: now.Add(-time.Hour), Status: FetchError},
+	}, nil)
+
+	health, err := service.List(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(health) != 2 || health[0].FeedID != 2 || health[0].ConsecutiveFailures != 2 || health[1].Status != FeedHealthy {
+		t.Errorf("expected the failing feed first and no virtual feed, got %+v", health)
+	}
+}
+
+func TestRefreshService_RecordsFeedHealth(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFetches := testutil.NewMockFeedFetchRepository(ctrl)
+	service := NewRefreshService(mockFeeds, nil, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, nil, nil, NewFeedHealthService(mockFetches, mockFeeds), nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Gone", URL: server.URL}, nil)
+	mockFeeds.EXPECT().UpdateErrorMessage(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	mockFeeds.EXPECT().IncrementFailures(gomock.Any(), int64(1)).Return(1, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	mockFetches.EXPECT().Add(gomock.Any(), gomock.Any(), feedFetchLogSize).DoAndReturn(func(_ context.Context, fetch model.FeedFetch, _ int) error {
+		if fetch.FeedID != 1 || fetch.Status != FetchHTTPError || fetch.HTTPStatus == nil || *fetch.HTTPStatus != http.StatusNotFound ||
+			fetch.Error == nil || *fetch.Error != "HTTP 404" || fetch.FetchedAt.IsZero() {
+			t.Errorf("unexpected recorded fetch %+v", fetch)
+		}
+		return nil
+	})
+
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+}
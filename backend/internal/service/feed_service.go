@@ -24,15 +24,66 @@ import (
 const feedTimeout = 20 * time.Second
 
 type FeedService interface {
-	Add(ctx context.Context, feedURL string, folderID *int64, titleOverride string, feedType string) (model.Feed, error)
-	Preview(ctx context.Context, feedURL string) (FeedPreview, error)
+	// Add subscribes to a feed, fetched with overrides and auth when they
+	// are not nil.
+	Add(ctx context.Context, feedURL string, folderID *int64, titleOverride string, feedType string, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (model.Feed, error)
+	// BulkAdd subscribes to each URL of a newline-separated list concurrently,
+	// reporting the outcome per URL. See BulkAddResult.
+	BulkAdd(ctx context.Context, urlList string, folderID *int64, feedType string) ([]BulkAddResult, error)
+	Preview(ctx context.Context, feedURL string, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (FeedPreview, error)
+	// AddScraped subscribes to a web page without a feed, whose entries are
+	// the items scraper picks out of it, dated under dateQuirks and fetched
+	// with overrides and auth when they are not nil. Selectors that pick
+	// nothing are ErrInvalid.
+	AddScraped(ctx context.Context, pageURL string, folderID *int64, titleOverride string, feedType string, scraper model.FeedScraper, dateQuirks *model.FeedDateQuirks, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (model.Feed, error)
+	// PreviewScraped returns the entries scraper picks out of a page, dated
+	// as AddScraped with the same dateQuirks would store them, to try
+	// selectors before subscribing.
+	PreviewScraped(ctx context.Context, pageURL string, scraper model.FeedScraper, dateQuirks *model.FeedDateQuirks, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (ScrapePreview, error)
+	// Discover finds the feeds of a website: the URL itself when it is a
+	// feed, else those its page links, else those at common feed paths of
+	// the site. Only candidates that fetch as feeds are returned, best first.
+	Discover(ctx context.Context, pageURL string) ([]FeedCandidate, error)
 	List(ctx context.Context, folderID *int64) ([]model.Feed, error)
-	Update(ctx context.Context, id int64, title string, folderID *int64) (model.Feed, error)
+	// Update sets the title and folder of a feed. A non-nil refreshInterval
+	// also sets its minutes between refreshes, where 0 goes back to the
+	// scheduler's choice, and a non-nil fullTextURL its full-text service,
+	// where "" goes back to the built-in readability. A non-nil
+	// summaryInSourceLanguage sets whether AI summaries of its entries are
+	// written in the article's language, a non-nil prefetchReadability
+	// whether refresh fetches the readable content of its new entries, a
+	// non-nil dateQuirks how the dates of its entries are read, where empty
+	// quirks remove the corrections, a non-nil scraper the selectors its
+	// page is scraped with, where empty selectors make it an ordinary feed,
+	// a non-nil overrides the User-Agent and headers it is requested with,
+	// where empty overrides send the defaults, and a non-nil auth the
+	// credentials it is fetched with, where empty credentials remove them.
+	Update(ctx context.Context, id int64, title string, folderID *int64, refreshInterval *int, fullTextURL *string, summaryInSourceLanguage *bool, prefetchReadability *bool, dateQuirks *model.FeedDateQuirks, scraper *model.FeedScraper, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (model.Feed, error)
 	UpdateType(ctx context.Context, id int64, feedType string) error
-	Delete(ctx context.Context, id int64) error
+	// Enable resets the failed refreshes of a feed disabled for failing too
+	// often and makes it due for the next scheduled refresh.
+	Enable(ctx context.Context, id int64) (model.Feed, error)
+	// Delete unsubscribes from a feed; mode decides what happens to its entries.
+	Delete(ctx context.Context, id int64, mode string) (FeedDeleteResult, error)
 	DeleteBatch(ctx context.Context, ids []int64) error
 }
 
+// Feed delete modes
+const (
+	FeedDeleteAll         = "deleteAll"   // remove the feed and every entry
+	FeedDeleteKeepStarred = "keepStarred" // keep starred entries, drop the rest
+	FeedDeleteKeepAll     = "keepAll"     // keep every entry
+)
+
+// FeedDeleteResult reports the outcome of a feed delete. When entries are kept
+// the feed is archived: it disappears from listings and refresh, but its kept
+// entries stay readable (marked as read) and resubscribing restores it.
+type FeedDeleteResult struct {
+	EntriesDeleted int64
+	EntriesKept    int64
+	Archived       bool
+}
+
 type FeedPreview struct {
 	URL         string
 	Title       string
@@ -43,120 +94,336 @@ type FeedPreview struct {
 	LastUpdated *string
 }
 
+// ScrapePreview is what a scraped feed would hold: the page's title and the
+// entries its selectors pick out, in page order.
+type ScrapePreview struct {
+	URL   string
+	Title string
+	Items []ScrapedItem
+}
+
+type ScrapedItem struct {
+	Title       string
+	URL         string
+	PublishedAt *time.Time // nil when the page gives no date
+}
+
 type feedService struct {
-	feeds      repository.FeedRepository
-	folders    repository.FolderRepository
-	entries    repository.EntryRepository
-	icons      IconService
-	settings   SettingsService
-	httpClient *http.Client
-	anubis     *anubis.Solver
+	tx          repository.TxManager
+	feeds       repository.FeedRepository
+	folders     repository.FolderRepository
+	outbox      OutboxNotifier
+	settings    SettingsService
+	httpClient  *http.Client
+	anubis      *anubis.Solver
+	credentials *FeedCredentials
+	hooks       *IngestHooks
+	sanitizer   *ContentSanitizer
 }
 
-func NewFeedService(feeds repository.FeedRepository, folders repository.FolderRepository, entries repository.EntryRepository, icons IconService, settings SettingsService, httpClient *http.Client, anubisSolver *anubis.Solver) FeedService {
+func NewFeedService(tx repository.TxManager, feeds repository.FeedRepository, folders repository.FolderRepository, outbox OutboxNotifier, settings SettingsService, httpClient *http.Client, anubisSolver *anubis.Solver, credentials *FeedCredentials, hooks *IngestHooks) FeedService {
 	client := httpClient
 	if client == nil {
 		client = &http.Client{Timeout: feedTimeout}
 	}
-	return &feedService{feeds: feeds, folders: folders, entries: entries, icons: icons, settings: settings, httpClient: client, anubis: anubisSolver}
+	return &feedService{tx: tx, feeds: feeds, folders: folders, outbox: outbox, settings: settings, httpClient: client, anubis: anubisSolver, credentials: credentials, hooks: hooks, sanitizer: NewContentSanitizer(settings)}
 }
 
-func (s *feedService) Add(ctx context.Context, feedURL string, folderID *int64, titleOverride string, feedType string) (model.Feed, error) {
+func (s *feedService) Add(ctx context.Context, feedURL string, folderID *int64, titleOverride string, feedType string, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (model.Feed, error) {
 	trimmedURL := strings.TrimSpace(feedURL)
 	if !isValidURL(trimmedURL) {
 		return model.Feed{}, ErrInvalid
 	}
-	if existing, err := s.feeds.FindByURL(ctx, trimmedURL); err != nil {
-		return model.Feed{}, fmt.Errorf("check feed url: %w", err)
-	} else if existing != nil {
-		return model.Feed{}, &FeedConflictError{ExistingFeed: *existing}
+	overrides, err := normalizeOptionalRequestOverrides(overrides)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	if overrides != nil && overrides.IsEmpty() {
+		overrides = nil
+	}
+	auth, err = normalizeOptionalFeedAuth(auth)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	existing, err := s.checkNewFeed(ctx, trimmedURL, folderID)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	if existing != nil {
+		restored, err := s.restoreArchived(ctx, *existing, folderID, titleOverride, feedType, auth)
+		if err != nil || overrides == nil {
+			return restored, err
+		}
+		if err := s.feeds.UpdateRequestOverrides(ctx, restored.ID, overrides); err != nil {
+			return model.Feed{}, fmt.Errorf("update request overrides: %w", err)
+		}
+		restored.RequestOverrides = overrides
+		return restored, nil
+	}
+
+	fetched, fetchErr := s.fetchFeed(ctx, trimmedURL, overrides, auth)
+	if fetchErr != nil {
+		// Fetch failed, create feed with error message and retry the first refresh in the background
+		feed := failedFeed(trimmedURL, folderID, titleOverride, feedType, auth, fetchErr)
+		feed.RequestOverrides = overrides
+		return s.createWithSideEffects(ctx, feed, feedFetch{})
+	}
+
+	if folderID == nil {
+		if folderID, err = s.categoryFolder(ctx, fetched.categories, feedType); err != nil {
+			return model.Feed{}, err
+		}
+	}
+
+	feed := fetchedFeed(trimmedURL, folderID, titleOverride, feedType, auth, fetched)
+	feed.RequestOverrides = overrides
+	return s.createWithSideEffects(ctx, feed, fetched)
+}
+
+// checkNewFeed checks that a feed can be subscribed to at feedURL in
+// folderID, returning the archived feed of that URL to restore, if any.
+func (s *feedService) checkNewFeed(ctx context.Context, feedURL string, folderID *int64) (*model.Feed, error) {
+	existing, err := s.feeds.FindByURL(ctx, feedURL)
+	if err != nil {
+		return nil, fmt.Errorf("check feed url: %w", err)
+	}
+	if existing != nil && existing.ArchivedAt == nil {
+		return nil, &FeedConflictError{ExistingFeed: *existing}
 	}
 	if folderID != nil {
 		if _, err := s.folders.GetByID(ctx, *folderID); err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				return model.Feed{}, ErrNotFound
+				return nil, ErrNotFound
 			}
-			return model.Feed{}, fmt.Errorf("check folder: %w", err)
+			return nil, fmt.Errorf("check folder: %w", err)
 		}
 	}
+	return existing, nil
+}
 
-	fetched, fetchErr := s.fetchFeed(ctx, trimmedURL)
-	if fetchErr != nil {
-		// Fetch failed, create feed with error message
-		finalTitle := strings.TrimSpace(titleOverride)
-		if finalTitle == "" {
-			finalTitle = trimmedURL
+// failedFeed builds a new feed whose first fetch failed, to be refreshed
+// again in the background.
+func failedFeed(feedURL string, folderID *int64, titleOverride string, feedType string, auth *model.FeedAuth, fetchErr error) model.Feed {
+	finalTitle := strings.TrimSpace(titleOverride)
+	if finalTitle == "" {
+		finalTitle = feedURL
+	}
+	errMsg := fetchErr.Error()
+	return model.Feed{
+		FolderID:     folderID,
+		Title:        finalTitle,
+		URL:          feedURL,
+		Type:         feedType,
+		ErrorMessage: &errMsg,
+		Auth:         auth,
+	}
+}
+
+// maxCategoryFolderName caps the length of a folder named after a category.
+const maxCategoryFolderName = 100
+
+// categoryFolder returns the top-level folder named after the first category
+// a feed declares, creating it with feedType when missing. It returns nil
+// when filing by category is off, the feed declares no category, or the
+// folder of that name holds another content type.
+func (s *feedService) categoryFolder(ctx context.Context, categories []string, feedType string) (*int64, error) {
+	if s.settings == nil {
+		return nil, nil
+	}
+	var name string
+	for _, category := range categories {
+		if name = strings.TrimSpace(category); name != "" {
+			break
 		}
-		errMsg := fetchErr.Error()
-		feed := model.Feed{
-			FolderID:     folderID,
-			Title:        finalTitle,
-			URL:          trimmedURL,
-			Type:         feedType,
-			ErrorMessage: &errMsg,
+	}
+	if name == "" {
+		return nil, nil
+	}
+	if runes := []rune(name); len(runes) > maxCategoryFolderName {
+		name = string(runes[:maxCategoryFolderName])
+	}
+	general, err := s.settings.GetGeneralSettings(ctx)
+	if err != nil || !general.CategoryFolders {
+		return nil, nil
+	}
+
+	folder, err := s.folders.FindByName(ctx, name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("find category folder: %w", err)
+	}
+	if folder == nil {
+		created, err := s.folders.Create(ctx, name, nil, feedType)
+		if err != nil {
+			// Another add may have created it meanwhile
+			if folder, _ = s.folders.FindByName(ctx, name, nil); folder == nil {
+				return nil, fmt.Errorf("create category folder: %w", err)
+			}
+		} else {
+			folder = &created
 		}
-		return s.feeds.Create(ctx, feed)
 	}
+	if folder.Type != feedType {
+		return nil, nil
+	}
+	return &folder.ID, nil
+}
 
+// fetchedFeed builds a new feed from what fetching its URL returned.
+func fetchedFeed(feedURL string, folderID *int64, titleOverride string, feedType string, auth *model.FeedAuth, fetched feedFetch) model.Feed {
 	finalTitle := strings.TrimSpace(titleOverride)
 	if finalTitle == "" {
 		finalTitle = strings.TrimSpace(fetched.title)
 	}
 	if finalTitle == "" {
-		finalTitle = trimmedURL
+		finalTitle = feedURL
 	}
 
-	feed := model.Feed{
+	return model.Feed{
 		FolderID:     folderID,
 		Title:        finalTitle,
-		URL:          trimmedURL,
+		URL:          feedURL,
 		SiteURL:      optionalString(fetched.siteURL),
 		Description:  optionalString(fetched.description),
 		Type:         feedType,
 		ETag:         optionalString(fetched.etag),
 		LastModified: optionalString(fetched.lastModified),
+		Auth:         auth,
 	}
+}
 
-	created, err := s.feeds.Create(ctx, feed)
+// createWithSideEffects stores the feed, its first entries and outbox messages
+// for the icon fetch (and the first refresh when nothing was fetched) in one
+// transaction, so a crash can never leave a feed without its follow-up work.
+func (s *feedService) createWithSideEffects(ctx context.Context, feed model.Feed, fetched feedFetch) (model.Feed, error) {
+	keywords := nsfwKeywords(ctx, s.settings)
+	sealedAuth, err := s.credentials.seal(feed.Auth)
 	if err != nil {
 		return model.Feed{}, err
 	}
+	var created model.Feed
+	err = s.tx.WithTx(ctx, func(repos repository.TxRepositories) error {
+		var err error
+		created, err = repos.Feeds.Create(ctx, feed)
+		if err != nil {
+			return err
+		}
+		if sealedAuth != nil {
+			if err := repos.Feeds.UpdateAuth(ctx, created.ID, sealedAuth); err != nil {
+				return err
+			}
+			created.HasAuth = true
+		}
+		if feed.Scraper != nil {
+			if err := repos.Feeds.UpdateScraper(ctx, created.ID, feed.Scraper); err != nil {
+				return err
+			}
+		}
+		if feed.DateQuirks != nil {
+			if err := repos.Feeds.UpdateDateQuirks(ctx, created.ID, feed.DateQuirks); err != nil {
+				return err
+			}
+		}
+		if feed.RequestOverrides != nil {
+			if err := repos.Feeds.UpdateRequestOverrides(ctx, created.ID, feed.RequestOverrides); err != nil {
+				return err
+			}
+		}
 
-	// Download and save icon
-	if s.icons != nil {
-		siteURL := ""
-		if created.SiteURL != nil {
+		siteURL := feed.URL // Use feed URL as fallback for favicon
+		if created.SiteURL != nil && *created.SiteURL != "" {
 			siteURL = *created.SiteURL
 		}
-		if siteURL == "" {
-			siteURL = trimmedURL // Use feed URL as fallback for favicon
+		if err := enqueueOutbox(ctx, repos.Outbox, OutboxFeedIcon, FeedIconPayload{
+			FeedID:   created.ID,
+			ImageURL: fetched.imageURL,
+			SiteURL:  siteURL,
+		}); err != nil {
+			return err
 		}
-		if iconPath, err := s.icons.FetchAndSaveIcon(ctx, fetched.imageURL, siteURL); err == nil && iconPath != "" {
-			_ = s.feeds.UpdateIconPath(ctx, created.ID, iconPath)
-			created.IconPath = &iconPath
+
+		// A sitemap's pages are fetched by the refresh, as is a feed that failed
+		if feed.ErrorMessage != nil || fetched.sitemap {
+			return enqueueOutbox(ctx, repos.Outbox, OutboxFeedRefresh, FeedRefreshPayload{FeedID: created.ID})
 		}
-	}
 
-	// Save entries from the fetched feed
-	dynamicTime := hasDynamicTime(fetched.items)
-	for _, item := range fetched.items {
-		entry := itemToEntry(created.ID, item, dynamicTime)
-		if entry.URL == nil || *entry.URL == "" {
-			continue
+		// Save entries from the fetched feed
+		dynamicTime := hasDynamicTime(fetched.items)
+		dates := loadFutureDates(ctx, s.settings).forFeed(created)
+		itemDates := newItemDates(created.DateQuirks, dates.now)
+		for _, item := range fetched.items {
+			entry := itemToEntry(created.ID, item, dynamicTime, itemDates)
+			if entry.URL == nil || *entry.URL == "" {
+				continue
+			}
+			if created.Type == pictureFeedType {
+				addGallery(&entry, item)
+			}
+			markNSFW(&entry, item, fetched.nsfwReason, keywords)
+			if !s.hooks.run(ctx, created, &entry) || dates.hold(entry) {
+				continue
+			}
+			s.sanitizer.SanitizeEntry(ctx, &entry)
+			dates.clamp(&entry, nil)
+			setContentFormats(&entry)
+			if err := repos.Entries.CreateOrUpdate(ctx, entry); err != nil {
+				return fmt.Errorf("save entry: %w", err)
+			}
 		}
-		_ = s.entries.CreateOrUpdate(ctx, entry)
+		return nil
+	})
+	if err != nil {
+		return model.Feed{}, err
 	}
 
+	if s.outbox != nil {
+		s.outbox.Notify()
+	}
 	return created, nil
 }
 
-func (s *feedService) Preview(ctx context.Context, feedURL string) (FeedPreview, error) {
+// restoreArchived resubscribes to an archived feed, keeping the entries that survived its deletion.
+func (s *feedService) restoreArchived(ctx context.Context, feed model.Feed, folderID *int64, titleOverride string, feedType string, auth *model.FeedAuth) (model.Feed, error) {
+	if auth != nil {
+		if err := s.setAuth(ctx, &feed, auth); err != nil {
+			return model.Feed{}, err
+		}
+	}
+	feed.FolderID = folderID
+	if title := strings.TrimSpace(titleOverride); title != "" {
+		feed.Title = title
+	}
+	updated, err := s.feeds.Update(ctx, feed)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	if feedType != "" && feedType != updated.Type {
+		if err := s.feeds.UpdateType(ctx, updated.ID, feedType); err != nil {
+			return model.Feed{}, err
+		}
+		updated.Type = feedType
+	}
+	if err := s.feeds.SetArchived(ctx, updated.ID, false); err != nil {
+		return model.Feed{}, err
+	}
+	updated.ArchivedAt = nil
+	return updated, nil
+}
+
+func (s *feedService) Preview(ctx context.Context, feedURL string, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (FeedPreview, error) {
 	trimmedURL := strings.TrimSpace(feedURL)
 	if !isValidURL(trimmedURL) {
 		return FeedPreview{}, ErrInvalid
 	}
+	overrides, err := normalizeOptionalRequestOverrides(overrides)
+	if err != nil {
+		return FeedPreview{}, err
+	}
+	auth, err = normalizeOptionalFeedAuth(auth)
+	if err != nil {
+		return FeedPreview{}, err
+	}
 
-	fetched, err := s.fetchFeed(ctx, trimmedURL)
+	fetched, err := s.fetchFeed(ctx, trimmedURL, overrides, auth)
 	if err != nil {
 		return FeedPreview{}, err
 	}
@@ -182,11 +449,46 @@ func (s *feedService) List(ctx context.Context, folderID *int64) ([]model.Feed,
 	return s.feeds.List(ctx, folderID)
 }
 
-func (s *feedService) Update(ctx context.Context, id int64, title string, folderID *int64) (model.Feed, error) {
+func (s *feedService) Update(ctx context.Context, id int64, title string, folderID *int64, refreshInterval *int, fullTextURL *string, summaryInSourceLanguage *bool, prefetchReadability *bool, dateQuirks *model.FeedDateQuirks, scraper *model.FeedScraper, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (model.Feed, error) {
 	trimmedTitle := strings.TrimSpace(title)
 	if trimmedTitle == "" {
 		return model.Feed{}, ErrInvalid
 	}
+	auth, err := normalizeOptionalFeedAuth(auth)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	overrides, err = normalizeOptionalRequestOverrides(overrides)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	if dateQuirks != nil {
+		normalized, err := NormalizeDateQuirks(*dateQuirks)
+		if err != nil {
+			return model.Feed{}, err
+		}
+		dateQuirks = &normalized
+	}
+	if scraper != nil {
+		normalized, err := NormalizeScraper(*scraper)
+		if err != nil {
+			return model.Feed{}, err
+		}
+		scraper = &normalized
+	}
+	if refreshInterval != nil && *refreshInterval != 0 &&
+		(*refreshInterval < MinRefreshIntervalMinutes || *refreshInterval > MaxRefreshIntervalMinutes) {
+		return model.Feed{}, fmt.Errorf("%w: refresh interval must be between %d and %d minutes", ErrInvalid, MinRefreshIntervalMinutes, MaxRefreshIntervalMinutes)
+	}
+	var trimmedFullTextURL *string
+	if fullTextURL != nil {
+		if trimmed := strings.TrimSpace(*fullTextURL); trimmed != "" {
+			if err := ValidateFullTextURL(trimmed); err != nil {
+				return model.Feed{}, err
+			}
+			trimmedFullTextURL = &trimmed
+		}
+	}
 	if folderID != nil {
 		if _, err := s.folders.GetByID(ctx, *folderID); err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
@@ -206,17 +508,161 @@ func (s *feedService) Update(ctx context.Context, id int64, title string, folder
 	feed.Title = trimmedTitle
 	feed.FolderID = folderID
 
+	if refreshInterval != nil {
+		if err := s.setRefreshInterval(ctx, &feed, *refreshInterval); err != nil {
+			return model.Feed{}, err
+		}
+	}
+	if fullTextURL != nil {
+		if err := s.feeds.UpdateFullTextURL(ctx, feed.ID, trimmedFullTextURL); err != nil {
+			return model.Feed{}, fmt.Errorf("update full-text service: %w", err)
+		}
+		feed.FullTextURL = trimmedFullTextURL
+	}
+	if summaryInSourceLanguage != nil {
+		if err := s.feeds.UpdateSummaryInSourceLanguage(ctx, feed.ID, *summaryInSourceLanguage); err != nil {
+			return model.Feed{}, fmt.Errorf("update summary language: %w", err)
+		}
+		feed.SummaryInSourceLanguage = *summaryInSourceLanguage
+	}
+	if prefetchReadability != nil {
+		if err := s.feeds.UpdatePrefetchReadability(ctx, feed.ID, *prefetchReadability); err != nil {
+			return model.Feed{}, fmt.Errorf("update readability prefetch: %w", err)
+		}
+		feed.PrefetchReadability = *prefetchReadability
+	}
+	if dateQuirks != nil {
+		if dateQuirks.IsEmpty() {
+			dateQuirks = nil
+		}
+		if err := s.feeds.UpdateDateQuirks(ctx, feed.ID, dateQuirks); err != nil {
+			return model.Feed{}, fmt.Errorf("update date quirks: %w", err)
+		}
+		feed.DateQuirks = dateQuirks
+	}
+	if scraper != nil {
+		if scraper.IsEmpty() {
+			scraper = nil
+		}
+		if err := s.feeds.UpdateScraper(ctx, feed.ID, scraper); err != nil {
+			return model.Feed{}, fmt.Errorf("update scraper: %w", err)
+		}
+		feed.Scraper = scraper
+	}
+	if overrides != nil {
+		if overrides.IsEmpty() {
+			overrides = nil
+		}
+		if err := s.feeds.UpdateRequestOverrides(ctx, feed.ID, overrides); err != nil {
+			return model.Feed{}, fmt.Errorf("update request overrides: %w", err)
+		}
+		feed.RequestOverrides = overrides
+	}
+	if auth != nil {
+		if err := s.setAuth(ctx, &feed, auth); err != nil {
+			return model.Feed{}, err
+		}
+	}
 	return s.feeds.Update(ctx, feed)
 }
 
-func (s *feedService) Delete(ctx context.Context, id int64) error {
-	if _, err := s.feeds.GetByID(ctx, id); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return ErrNotFound
+// setAuth stores the credentials a feed is fetched with, removing them when
+// auth is empty.
+func (s *feedService) setAuth(ctx context.Context, feed *model.Feed, auth *model.FeedAuth) error {
+	sealed, err := s.credentials.seal(auth)
+	if err != nil {
+		return err
+	}
+	if err := s.feeds.UpdateAuth(ctx, feed.ID, sealed); err != nil {
+		return fmt.Errorf("update feed credentials: %w", err)
+	}
+	feed.HasAuth = sealed != nil
+	return nil
+}
+
+// normalizeOptionalFeedAuth runs NormalizeFeedAuth on auth unless it is nil.
+func normalizeOptionalFeedAuth(auth *model.FeedAuth) (*model.FeedAuth, error) {
+	if auth == nil {
+		return nil, nil
+	}
+	normalized, err := NormalizeFeedAuth(*auth)
+	if err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+// setRefreshInterval stores the feed's interval and, when a shorter one makes
+// the feed due sooner, brings its next refresh forward.
+func (s *feedService) setRefreshInterval(ctx context.Context, feed *model.Feed, minutes int) error {
+	feed.RefreshInterval = nil
+	if minutes > 0 {
+		feed.RefreshInterval = &minutes
+	}
+	if err := s.feeds.UpdateRefreshInterval(ctx, feed.ID, feed.RefreshInterval); err != nil {
+		return fmt.Errorf("update refresh interval: %w", err)
+	}
+	if minutes == 0 || feed.NextRefreshAt == nil {
+		return nil
+	}
+	next := time.Now().Add(time.Duration(minutes) * time.Minute)
+	if next.Before(*feed.NextRefreshAt) {
+		if err := s.feeds.ScheduleRefresh(ctx, feed.ID, next); err != nil {
+			return fmt.Errorf("schedule refresh: %w", err)
 		}
-		return fmt.Errorf("get feed: %w", err)
+		feed.NextRefreshAt = &next
+	}
+	return nil
+}
+
+func (s *feedService) Delete(ctx context.Context, id int64, mode string) (FeedDeleteResult, error) {
+	if mode == "" {
+		mode = FeedDeleteAll
+	}
+	if mode != FeedDeleteAll && mode != FeedDeleteKeepStarred && mode != FeedDeleteKeepAll {
+		return FeedDeleteResult{}, ErrInvalid
+	}
+
+	var result FeedDeleteResult
+	err := s.tx.WithTx(ctx, func(repos repository.TxRepositories) error {
+		if _, err := repos.Feeds.GetByID(ctx, id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return fmt.Errorf("get feed: %w", err)
+		}
+
+		if mode != FeedDeleteKeepAll {
+			deleted, err := repos.Entries.DeleteByFeed(ctx, id, mode == FeedDeleteKeepStarred)
+			if err != nil {
+				return fmt.Errorf("delete entries: %w", err)
+			}
+			result.EntriesDeleted = deleted
+		}
+
+		kept, err := repos.Entries.CountByFeed(ctx, id)
+		if err != nil {
+			return fmt.Errorf("count entries: %w", err)
+		}
+		result.EntriesKept = kept
+		if kept == 0 {
+			return repos.Feeds.Delete(ctx, id)
+		}
+
+		// Kept entries would otherwise linger as unread under a feed nobody can see
+		if err := repos.Entries.MarkAllAsRead(ctx, &id, nil, nil); err != nil {
+			return fmt.Errorf("mark kept entries read: %w", err)
+		}
+		if err := repos.Feeds.SetArchived(ctx, id, true); err != nil {
+			return err
+		}
+		result.Archived = true
+		return nil
+	})
+	if err != nil {
+		return FeedDeleteResult{}, err
 	}
-	return s.feeds.Delete(ctx, id)
+	return result, nil
 }
 
 func (s *feedService) UpdateType(ctx context.Context, id int64, feedType string) error {
@@ -229,6 +675,23 @@ func (s *feedService) UpdateType(ctx context.Context, id int64, feedType string)
 	return s.feeds.UpdateType(ctx, id, feedType)
 }
 
+func (s *feedService) Enable(ctx context.Context, id int64) (model.Feed, error) {
+	if err := s.feeds.ClearFailures(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Feed{}, ErrNotFound
+		}
+		return model.Feed{}, fmt.Errorf("clear feed failures: %w", err)
+	}
+	if err := s.feeds.ScheduleRefresh(ctx, id, time.Now()); err != nil {
+		return model.Feed{}, fmt.Errorf("schedule feed refresh: %w", err)
+	}
+	feed, err := s.feeds.GetByID(ctx, id)
+	if err != nil {
+		return model.Feed{}, fmt.Errorf("get feed: %w", err)
+	}
+	return feed, nil
+}
+
 func (s *feedService) DeleteBatch(ctx context.Context, ids []int64) error {
 	if len(ids) == 0 {
 		return nil
@@ -254,22 +717,29 @@ type feedFetch struct {
 	etag         string
 	lastModified string
 	items        []*gofeed.Item
+	// nsfwReason is set when the whole feed is marked not safe for work.
+	nsfwReason string
+	// sitemap is set when the URL is a sitemap rather than a feed.
+	sitemap bool
+	// categories are the categories the feed itself declares.
+	categories []string
 }
 
-func (s *feedService) fetchFeed(ctx context.Context, feedURL string) (feedFetch, error) {
-	return s.fetchFeedWithUA(ctx, feedURL, config.DefaultUserAgent, true)
+func (s *feedService) fetchFeed(ctx context.Context, feedURL string, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (feedFetch, error) {
+	return s.fetchFeedWithUA(ctx, feedURL, overrides, auth, config.DefaultUserAgent, !overridesUserAgent(overrides))
 }
 
-func (s *feedService) fetchFeedWithUA(ctx context.Context, feedURL string, userAgent string, allowFallback bool) (feedFetch, error) {
-	return s.fetchFeedWithCookie(ctx, feedURL, userAgent, "", allowFallback, 0)
+func (s *feedService) fetchFeedWithUA(ctx context.Context, feedURL string, overrides *model.FeedRequestOverrides, auth *model.FeedAuth, userAgent string, allowFallback bool) (feedFetch, error) {
+	return s.fetchFeedWithCookie(ctx, feedURL, overrides, auth, userAgent, "", allowFallback, 0)
 }
 
-func (s *feedService) fetchFeedWithCookie(ctx context.Context, feedURL string, userAgent string, cookie string, allowFallback bool, retryCount int) (feedFetch, error) {
+func (s *feedService) fetchFeedWithCookie(ctx context.Context, feedURL string, overrides *model.FeedRequestOverrides, auth *model.FeedAuth, userAgent string, cookie string, allowFallback bool, retryCount int) (feedFetch, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 	if err != nil {
 		return feedFetch{}, ErrFeedFetch
 	}
 	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", feedAccept)
 
 	// Add cached Anubis cookie if available
 	if cookie == "" && s.anubis != nil {
@@ -281,6 +751,8 @@ func (s *feedService) fetchFeedWithCookie(ctx context.Context, feedURL string, u
 	if cookie != "" {
 		req.Header.Set("Cookie", cookie)
 	}
+	applyRequestOverrides(req, overrides)
+	applyFeedAuth(req, auth)
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
@@ -292,7 +764,7 @@ func (s *feedService) fetchFeedWithCookie(ctx context.Context, feedURL string, u
 	if resp.StatusCode >= http.StatusBadRequest && allowFallback && s.settings != nil {
 		fallbackUA := s.settings.GetFallbackUserAgent(ctx)
 		if fallbackUA != "" {
-			return s.fetchFeedWithCookie(ctx, feedURL, fallbackUA, cookie, false, retryCount)
+			return s.fetchFeedWithCookie(ctx, feedURL, overrides, auth, fallbackUA, cookie, false, retryCount)
 		}
 	}
 
@@ -306,10 +778,13 @@ func (s *feedService) fetchFeedWithCookie(ctx context.Context, feedURL string, u
 		return feedFetch{}, ErrFeedFetch
 	}
 
-	// Try to parse as RSS/Atom
-	parser := gofeed.NewParser()
+	// Try to parse as RSS, Atom or JSON Feed
+	parser := newFeedParser()
 	parsed, parseErr := parser.Parse(bytes.NewReader(body))
 	if parseErr != nil {
+		if sm, ok := parseSitemap(body); ok {
+			return sitemapFetch(feedURL, sm), nil
+		}
 		// Parse failed, check if it's an Anubis challenge
 		if s.anubis != nil && anubis.IsAnubisChallenge(body) {
 			if retryCount >= 2 {
@@ -321,7 +796,7 @@ func (s *feedService) fetchFeedWithCookie(ctx context.Context, feedURL string, u
 				return feedFetch{}, ErrFeedFetch
 			}
 			// Retry with fresh client to avoid connection reuse
-			return s.fetchFeedWithFreshClient(ctx, feedURL, userAgent, newCookie, retryCount+1)
+			return s.fetchFeedWithFreshClient(ctx, feedURL, overrides, auth, userAgent, newCookie, retryCount+1)
 		}
 		return feedFetch{}, ErrFeedFetch
 	}
@@ -358,22 +833,27 @@ func (s *feedService) fetchFeedWithCookie(ctx context.Context, feedURL string, u
 		etag:         etag,
 		lastModified: lastModified,
 		items:        parsed.Items,
+		nsfwReason:   feedNSFWReason(parsed),
+		categories:   parsed.Categories,
 	}, nil
 }
 
 // fetchFeedWithFreshClient creates a new http.Client to avoid connection reuse after Anubis
-func (s *feedService) fetchFeedWithFreshClient(ctx context.Context, feedURL string, userAgent string, cookie string, retryCount int) (feedFetch, error) {
+func (s *feedService) fetchFeedWithFreshClient(ctx context.Context, feedURL string, overrides *model.FeedRequestOverrides, auth *model.FeedAuth, userAgent string, cookie string, retryCount int) (feedFetch, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
 	if err != nil {
 		return feedFetch{}, ErrFeedFetch
 	}
 	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", feedAccept)
 	if cookie != "" {
 		req.Header.Set("Cookie", cookie)
 	}
+	applyRequestOverrides(req, overrides)
+	applyFeedAuth(req, auth)
 
 	// Use fresh client to avoid connection reuse
-	freshClient := &http.Client{Timeout: feedTimeout}
+	freshClient := &http.Client{Timeout: feedTimeout, Jar: s.httpClient.Jar, Transport: s.httpClient.Transport}
 	resp, err := freshClient.Do(req)
 	if err != nil {
 		return feedFetch{}, ErrFeedFetch
@@ -394,9 +874,12 @@ func (s *feedService) fetchFeedWithFreshClient(ctx context.Context, feedURL stri
 		return feedFetch{}, fmt.Errorf("anubis challenge persists after %d retries", retryCount)
 	}
 
-	parser := gofeed.NewParser()
+	parser := newFeedParser()
 	parsed, parseErr := parser.Parse(bytes.NewReader(body))
 	if parseErr != nil {
+		if sm, ok := parseSitemap(body); ok {
+			return sitemapFetch(feedURL, sm), nil
+		}
 		return feedFetch{}, ErrFeedFetch
 	}
 
@@ -432,6 +915,8 @@ func (s *feedService) fetchFeedWithFreshClient(ctx context.Context, feedURL stri
 		etag:         etag,
 		lastModified: lastModified,
 		items:        parsed.Items,
+		nsfwReason:   feedNSFWReason(parsed),
+		categories:   parsed.Categories,
 	}, nil
 }
 
@@ -462,7 +947,7 @@ func hasDynamicTime(items []*gofeed.Item) bool {
 	return firstTime != nil
 }
 
-func itemToEntry(feedID int64, item *gofeed.Item, ignoreDynamicTime bool) model.Entry {
+func itemToEntry(feedID int64, item *gofeed.Item, ignoreDynamicTime bool, dates itemDates) model.Entry {
 	entry := model.Entry{
 		FeedID: feedID,
 	}
@@ -483,40 +968,33 @@ func itemToEntry(feedID int64, item *gofeed.Item, ignoreDynamicTime bool) model.
 	}
 	if content != "" {
 		entry.Content = &content
+		snippet := makeSnippet(content)
+		entry.Snippet = &snippet
 	}
 
 	// Extract thumbnail from media tags
 	entry.ThumbnailURL = extractThumbnail(item)
 
-	if item.Author != nil && item.Author.Name != "" {
-		author := strings.TrimSpace(item.Author.Name)
+	if author := itemAuthor(item); author != "" {
 		entry.Author = &author
 	}
+	entry.ExternalURL = optionalString(item.Custom["external_url"])
+	entry.Attachments = itemAttachments(item)
 
-	entry.PublishedAt = extractPublishedAt(item, ignoreDynamicTime)
+	entry.PublishedAt = extractPublishedAt(item, ignoreDynamicTime, dates)
 
 	return entry
 }
 
-func extractPublishedAt(item *gofeed.Item, ignoreDynamicTime bool) *time.Time {
-	now := time.Now()
-
+func extractPublishedAt(item *gofeed.Item, ignoreDynamicTime bool, dates itemDates) *time.Time {
 	// 1. Try to extract from summary (SEC RSS: "Filed: 2025-12-17")
 	if t := extractDateFromSummary(item.Description); t != nil {
 		return t
 	}
 
-	// 2. Try standard fields, reject future dates
-	if item.PublishedParsed != nil && !item.PublishedParsed.After(now) {
-		t := item.PublishedParsed.UTC()
-		return &t
-	}
-	if !ignoreDynamicTime && item.UpdatedParsed != nil && !item.UpdatedParsed.After(now) {
-		t := item.UpdatedParsed.UTC()
-		return &t
-	}
-
-	return nil
+	// 2. Try standard fields, read with the feed's date quirks. Future dates
+	// are kept here and handled at ingest per the future dates setting
+	return dates.published(item, ignoreDynamicTime)
 }
 
 var filedDateRegex = regexp.MustCompile(`Filed:.*?(\d{4}-\d{2}-\d{2})`)
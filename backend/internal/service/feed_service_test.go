@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+// countingNotifier counts the times the outbox is woken.
+type countingNotifier struct{ notified int }
+
+func (n *countingNotifier) Notify() { n.notified++ }
+
+// mockTx runs every transaction against repos, returning what the work
+// returned, as the real manager commits or rolls back on it.
+func mockTx(ctrl *gomock.Controller, repos repository.TxRepositories) *testutil.MockTxManager {
+	tx := testutil.NewMockTxManager(ctrl)
+	tx.EXPECT().WithTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func(repository.TxRepositories) error) error {
+		return fn(repos)
+	}).AnyTimes()
+	return tx
+}
+
+func feedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Blog</title><link>https://example.com/</link>
+<item><title>First</title><link>https://example.com/1</link></item>
+<item><title>Second</title><link>https://example.com/2</link></item>
+</channel></rss>`)
+	}))
+}
+
+func TestFeedService_AddStoresFeedEntriesAndOutbox(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server := feedServer(t)
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	txFeeds := testutil.NewMockFeedRepository(ctrl)
+	txEntries := testutil.NewMockEntryRepository(ctrl)
+	txOutbox := testutil.NewMockOutboxRepository(ctrl)
+	notifier := &countingNotifier{}
+	service := NewFeedService(mockTx(ctrl, repository.TxRepositories{Feeds: txFeeds, Entries: txEntries, Outbox: txOutbox}), mockFeeds, nil, notifier, nil, server.Client(), nil, nil, nil)
+	ctx := context.Background()
+
+	mockFeeds.EXPECT().FindByURL(ctx, server.URL).Return(nil, nil)
+	txFeeds.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, feed model.Feed) (model.Feed, error) {
+		feed.ID = 7
+		return feed, nil
+	})
+	txOutbox.EXPECT().Enqueue(ctx, OutboxFeedIcon, `{"feedId":7,"siteUrl":"https://example.com/"}`).Return(nil)
+	txEntries.EXPECT().CreateOrUpdate(ctx, gomock.Any()).Return(nil).Times(2)
+
+	feed, err := service.Add(ctx, server.URL, nil, "", "article", nil, nil)
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if feed.ID != 7 || feed.Title != "Blog" {
+		t.Errorf("unexpected feed %+v", feed)
+	}
+	if notifier.notified != 1 {
+		t.Errorf("expected the outbox to be woken once, got %d", notifier.notified)
+	}
+}
+
+func TestFeedService_AddRollsBackWhenAnEntryFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	server := feedServer(t)
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	txFeeds := testutil.NewMockFeedRepository(ctrl)
+	txEntries := testutil.NewMockEntryRepository(ctrl)
+	txOutbox := testutil.NewMockOutboxRepository(ctrl)
+	notifier := &countingNotifier{}
+	service := NewFeedService(mockTx(ctrl, repository.TxRepositories{Feeds: txFeeds, Entries: txEntries, Outbox: txOutbox}), mockFeeds, nil, notifier, nil, server.Client(), nil, nil, nil)
+	ctx := context.Background()
+
+	mockFeeds.EXPECT().FindByURL(ctx, server.URL).Return(nil, nil)
+	txFeeds.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, feed model.Feed) (model.Feed, error) {
+		feed.ID = 7
+		return feed, nil
+	})
+	txOutbox.EXPECT().Enqueue(ctx, OutboxFeedIcon, gomock.Any()).Return(nil)
+	// The first entry fails, so the second is never written
+	txEntries.EXPECT().CreateOrUpdate(ctx, gomock.Any()).Return(errors.New("disk full"))
+
+	if _, err := service.Add(ctx, server.URL, nil, "", "article", nil, nil); err == nil {
+		t.Fatal("expected the failed entry to fail the add")
+	}
+	if notifier.notified != 0 {
+		t.Errorf("expected the outbox not to be woken for a rolled back feed, got %d", notifier.notified)
+	}
+}
+
+func TestFeedService_Delete(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		deleted int64
+		kept    int64
+		want    FeedDeleteResult
+	}{
+		{"delete all", FeedDeleteAll, 5, 0, FeedDeleteResult{EntriesDeleted: 5}},
+		{"default mode", "", 5, 0, FeedDeleteResult{EntriesDeleted: 5}},
+		{"keep starred", FeedDeleteKeepStarred, 3, 2, FeedDeleteResult{EntriesDeleted: 3, EntriesKept: 2, Archived: true}},
+		{"keep starred without any", FeedDeleteKeepStarred, 5, 0, FeedDeleteResult{EntriesDeleted: 5}},
+		{"keep all", FeedDeleteKeepAll, 0, 5, FeedDeleteResult{EntriesKept: 5, Archived: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			txFeeds := testutil.NewMockFeedRepository(ctrl)
+			txEntries := testutil.NewMockEntryRepository(ctrl)
+			service := NewFeedService(mockTx(ctrl, repository.TxRepositories{Feeds: txFeeds, Entries: txEntries}), nil, nil, nil, nil, nil, nil, nil, nil)
+			ctx := context.Background()
+			feedID := int64(7)
+
+			txFeeds.EXPECT().GetByID(ctx, feedID).Return(model.Feed{ID: feedID}, nil)
+			if tt.mode != FeedDeleteKeepAll {
+				txEntries.EXPECT().DeleteByFeed(ctx, feedID, tt.mode == FeedDeleteKeepStarred).Return(tt.deleted, nil)
+			}
+			txEntries.EXPECT().CountByFeed(ctx, feedID).Return(tt.kept, nil)
+			if tt.kept == 0 {
+				txFeeds.EXPECT().Delete(ctx, feedID).Return(nil)
+			} else {
+				// Kept entries stay, read, under the archived feed
+				txEntries.EXPECT().MarkAllAsRead(ctx, &feedID, nil, nil).Return(nil)
+				txFeeds.EXPECT().SetArchived(ctx, feedID, true).Return(nil)
+			}
+
+			result, err := service.Delete(ctx, feedID, tt.mode)
+			if err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			if result != tt.want {
+				t.Errorf("got %+v, want %+v", result, tt.want)
+			}
+		})
+	}
+}
+
+func TestFeedService_DeleteRejectsUnknownMode(t *testing.T) {
+	service := NewFeedService(nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	if _, err := service.Delete(context.Background(), 7, "archive"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid, got %v", err)
+	}
+}
+
+func TestFeedService_DeleteNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	txFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewFeedService(mockTx(ctrl, repository.TxRepositories{Feeds: txFeeds}), nil, nil, nil, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	txFeeds.EXPECT().GetByID(ctx, int64(7)).Return(model.Feed{}, sql.ErrNoRows)
+	if _, err := service.Delete(ctx, 7, FeedDeleteAll); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFeedService_DeleteFailsWhenAStepFails(t *testing.T) {
+	failed := errors.New("disk full")
+	tests := []struct {
+		name   string
+		expect func(feeds *testutil.MockFeedRepository, entries *testutil.MockEntryRepository)
+	}{
+		{"deleting entries", func(feeds *testutil.MockFeedRepository, entries *testutil.MockEntryRepository) {
+			entries.EXPECT().DeleteByFeed(gomock.Any(), int64(7), true).Return(int64(0), failed)
+		}},
+		{"marking kept entries read", func(feeds *testutil.MockFeedRepository, entries *testutil.MockEntryRepository) {
+			entries.EXPECT().DeleteByFeed(gomock.Any(), int64(7), true).Return(int64(3), nil)
+			entries.EXPECT().CountByFeed(gomock.Any(), int64(7)).Return(int64(2), nil)
+			entries.EXPECT().MarkAllAsRead(gomock.Any(), gomock.Any(), nil, nil).Return(failed)
+		}},
+		{"archiving the feed", func(feeds *testutil.MockFeedRepository, entries *testutil.MockEntryRepository) {
+			entries.EXPECT().DeleteByFeed(gomock.Any(), int64(7), true).Return(int64(3), nil)
+			entries.EXPECT().CountByFeed(gomock.Any(), int64(7)).Return(int64(2), nil)
+			entries.EXPECT().MarkAllAsRead(gomock.Any(), gomock.Any(), nil, nil).Return(nil)
+			feeds.EXPECT().SetArchived(gomock.Any(), int64(7), true).Return(failed)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			txFeeds := testutil.NewMockFeedRepository(ctrl)
+			txEntries := testutil.NewMockEntryRepository(ctrl)
+			service := NewFeedService(mockTx(ctrl, repository.TxRepositories{Feeds: txFeeds, Entries: txEntries}), nil, nil, nil, nil, nil, nil, nil, nil)
+
+			txFeeds.EXPECT().GetByID(gomock.Any(), int64(7)).Return(model.Feed{ID: 7}, nil)
+			tt.expect(txFeeds, txEntries)
+			// The error leaves WithTx, which rolls back every step before it
+			if _, err := service.Delete(context.Background(), 7, FeedDeleteKeepStarred); !errors.Is(err, failed) {
+				t.Errorf("expected the step's error, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Feed fetch outcomes
+const (
+	FetchOK          = "ok"
+	FetchNotModified = "not_modified"
+	FetchHTTPError   = "http_error"
+	FetchError       = "error" // network, parse or challenge failure
+)
+
+// FetchOutcomes lists every outcome, in exposition order.
+var FetchOutcomes = []string{FetchOK, FetchNotModified, FetchHTTPError, FetchError}
+
+// FeedFetchStats accumulates the refresh fetches of one feed, or of all feeds
+// folded into the "other" bucket.
+type FeedFetchStats struct {
+	FeedID   int64 // 0 for the other bucket
+	Title    string
+	Outcomes map[string]int64
+	Seconds  float64 // total fetch duration
+	// Collapsed counts new entries dropped as repeats of a recent title.
+	Collapsed int64
+}
+
+// Fetches returns the number of fetches across all outcomes.
+func (s FeedFetchStats) Fetches() int64 {
+	var n int64
+	for _, count := range s.Outcomes {
+		n += count
+	}
+	return n
+}
+
+// failures counts the fetches that did not return the feed.
+func (s FeedFetchStats) failures() int64 {
+	return s.Outcomes[FetchHTTPError] + s.Outcomes[FetchError]
+}
+
+func (s *FeedFetchStats) add(other FeedFetchStats) {
+	for outcome, count := range other.Outcomes {
+		s.Outcomes[outcome] += count
+	}
+	s.Seconds += other.Seconds
+	s.Collapsed += other.Collapsed
+}
+
+// FetchMetricsSnapshot is a point-in-time view of fetch metrics with
+// per-feed detail capped to the most interesting feeds.
+type FetchMetricsSnapshot struct {
+	Feeds        []FeedFetchStats // highest ranked first
+	Other        FeedFetchStats   // everything beyond the cap
+	OtherFeeds   int              // how many feeds Other covers
+	TrackedFeeds int              // how many feeds have been fetched at all
+}
+
+// FetchMetrics records feed refresh fetches in memory since startup.
+type FetchMetrics struct {
+	mu    sync.Mutex
+	feeds map[int64]*FeedFetchStats
+}
+
+func NewFetchMetrics() *FetchMetrics {
+	return &FetchMetrics{feeds: make(map[int64]*FeedFetchStats)}
+}
+
+// Observe records one fetch of a feed. A nil receiver records nothing.
+func (m *FetchMetrics) Observe(feedID int64, title, outcome string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.feeds[feedID]
+	if !ok {
+		stats = &FeedFetchStats{FeedID: feedID, Outcomes: make(map[string]int64)}
+		m.feeds[feedID] = stats
+	}
+	stats.Title = title
+	stats.Outcomes[outcome]++
+	stats.Seconds += duration.Seconds()
+}
+
+// ObserveCollapsed records n new entries of a feed dropped as duplicate
+// titles. A nil receiver records nothing.
+func (m *FetchMetrics) ObserveCollapsed(feedID int64, title string, n int) {
+	if m == nil || n == 0 {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.feeds[feedID]
+	if !ok {
+		stats = &FeedFetchStats{FeedID: feedID, Outcomes: make(map[string]int64)}
+		m.feeds[feedID] = stats
+	}
+	stats.Title = title
+	stats.Collapsed += int64(n)
+}
+
+// Snapshot returns per-feed stats for at most limit feeds and folds the rest
+// into Other, so the number of exported series stays bounded however many
+// feeds there are. Feeds are ranked by failed fetches, then by total fetch
+// time: the feeds worth looking at are the broken and the slow ones.
+func (m *FetchMetrics) Snapshot(limit int) FetchMetricsSnapshot {
+	m.mu.Lock()
+	all := make([]FeedFetchStats, 0, len(m.feeds))
+	for _, stats := range m.feeds {
+		copied := *stats
+		copied.Outcomes = make(map[string]int64, len(stats.Outcomes))
+		for outcome, count := range stats.Outcomes {
+			copied.Outcomes[outcome] = count
+		}
+		all = append(all, copied)
+	}
+	m.mu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		if fi, fj := all[i].failures(), all[j].failures(); fi != fj {
+			return fi > fj
+		}
+		if all[i].Seconds != all[j].Seconds {
+			return all[i].Seconds > all[j].Seconds
+		}
+		return all[i].FeedID < all[j].FeedID
+	})
+
+	if limit < 0 {
+		limit = 0
+	}
+	snapshot := FetchMetricsSnapshot{
+		Other:        FeedFetchStats{Outcomes: make(map[string]int64)},
+		TrackedFeeds: len(all),
+	}
+	for i, stats := range all {
+		if i < limit {
+			snapshot.Feeds = append(snapshot.Feeds, stats)
+			continue
+		}
+		snapshot.Other.add(stats)
+		snapshot.OtherFeeds++
+	}
+	return snapshot
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestFetchMetrics_SnapshotCapsFeeds(t *testing.T) {
+	metrics := NewFetchMetrics()
+	metrics.Observe(1, "Fast", FetchOK, 100*time.Millisecond)
+	metrics.Observe(2, "Slow", FetchOK, 3*time.Second)
+	metrics.Observe(3, "Broken", FetchHTTPError, 50*time.Millisecond)
+	metrics.Observe(3, "Broken", FetchError, 50*time.Millisecond)
+	metrics.Observe(4, "Quiet", FetchNotModified, 10*time.Millisecond)
+	metrics.ObserveCollapsed(4, "Quiet", 3)
+
+	snapshot := metrics.Snapshot(2)
+	if len(snapshot.Feeds) != 2 || snapshot.Feeds[0].FeedID != 3 || snapshot.Feeds[1].FeedID != 2 {
+		t.Fatalf("expected broken then slow feed, got %+v", snapshot.Feeds)
+	}
+	if snapshot.OtherFeeds != 2 || snapshot.TrackedFeeds != 4 {
+		t.Errorf("expected 2 of 4 feeds in other, got %d of %d", snapshot.OtherFeeds, snapshot.TrackedFeeds)
+	}
+	if snapshot.Other.Outcomes[FetchOK] != 1 || snapshot.Other.Outcomes[FetchNotModified] != 1 || snapshot.Other.Fetches() != 2 {
+		t.Errorf("unexpected other outcomes: %+v", snapshot.Other.Outcomes)
+	}
+	if snapshot.Other.Collapsed != 3 {
+		t.Errorf("expected collapsed entries folded into other, got %d", snapshot.Other.Collapsed)
+	}
+
+	if none := metrics.Snapshot(0); len(none.Feeds) != 0 || none.Other.Fetches() != 5 {
+		t.Errorf("limit 0 should fold every fetch into other, got %+v", none)
+	}
+}
+
+func TestRefreshService_RecordsFetchOutcomes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/not-modified":
+			w.WriteHeader(http.StatusNotModified)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	metrics := NewFetchMetrics()
+	service := NewRefreshService(mockFeeds, nil, nil, nil, server.Client(), nil, metrics, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Same", URL: server.URL + "/not-modified"}, nil)
+	mockFeeds.EXPECT().GetByID(ctx, int64(2)).Return(model.Feed{ID: 2, Title: "Down", URL: server.URL + "/down"}, nil)
+	mockFeeds.EXPECT().UpdateErrorMessage(gomock.Any(), int64(2), gomock.Any()).Return(nil)
+	mockFeeds.EXPECT().IncrementFailures(gomock.Any(), int64(2)).Return(1, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	// Neither outcome is an error for callers
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Errorf("not modified: unexpected error: %v", err)
+	}
+	if err := service.RefreshFeed(ctx, 2); err != nil {
+		t.Errorf("http error: unexpected error: %v", err)
+	}
+
+	snapshot := metrics.Snapshot(10)
+	outcomes := map[int64]map[string]int64{}
+	for _, stats := range snapshot.Feeds {
+		outcomes[stats.FeedID] = stats.Outcomes
+	}
+	if outcomes[1][FetchNotModified] != 1 || outcomes[2][FetchHTTPError] != 1 {
+		t.Errorf("unexpected outcomes: %+v", outcomes)
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/expr-lang/expr"
+	"github.com/expr-lang/expr/vm"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/ai"
+)
+
+// Limits of an expression filter. Expressions have no access to anything but
+// the entry; these bound how much work one can make refresh do.
+const (
+	filterExprMaxNodes     = 500
+	filterExprMemoryBudget = 100_000
+	filterExprTimeout      = 100 * time.Millisecond
+)
+
+// errFilterExprTimeout is returned for an expression that runs too long.
+var errFilterExprTimeout = errors.New("expression timed out")
+
+// filterEnv is what an expression filter sees of an entry.
+type filterEnv struct {
+	Title   string `expr:"title"`
+	Content string `expr:"content"` // HTML
+	Text    string `expr:"text"`    // content as plain text
+	Author  string `expr:"author"`
+	URL     string `expr:"url"`
+}
+
+// filterRewriteKeys are the fields the result of a rewrite may replace.
+var filterRewriteKeys = []string{"title", "content", "author"}
+
+// compileFilterExpr compiles the expression of a filter. A rewrite returns a
+// map of the fields to replace, or nil; every other action a bool.
+func compileFilterExpr(pattern, action string) (*vm.Program, error) {
+	options := []expr.Option{expr.Env(filterEnv{}), expr.MaxNodes(filterExprMaxNodes)}
+	if action != model.FilterActionRewrite {
+		options = append(options, expr.AsBool())
+	}
+	program, err := expr.Compile(pattern, options...)
+	if err != nil {
+		return nil, err
+	}
+	if action == model.FilterActionRewrite {
+		switch program.Node().Type().Kind() {
+		case reflect.Map, reflect.Interface:
+		default:
+			return nil, fmt.Errorf("rewrite expression must return a map, got %s", program.Node().Type())
+		}
+	}
+	return program, nil
+}
+
+// ValidateFilterExpr reports why an expression cannot be the pattern of a
+// filter with action, or nil if it can.
+func ValidateFilterExpr(pattern, action string) error {
+	_, err := compileFilterExpr(pattern, action)
+	return err
+}
+
+// runFilterExpr evaluates a compiled expression against env. The VM cannot be
+// interrupted, so a run that times out is left to finish on its own; the
+// memory budget keeps it from going on for long.
+func runFilterExpr(program *vm.Program, env filterEnv) (any, error) {
+	type result struct {
+		value any
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		machine := vm.VM{MemoryBudget: filterExprMemoryBudget}
+		value, err := machine.Run(program, env)
+		done <- result{value, err}
+	}()
+
+	timer := time.NewTimer(filterExprTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		return nil, errFilterExprTimeout
+	}
+}
+
+// newFilterEnv is the view of an entry expressions are evaluated against.
+func newFilterEnv(entry *model.Entry) filterEnv {
+	return filterEnv{
+		Title:   trimmedValue(entry.Title),
+		Content: trimmedValue(entry.Content),
+		Text:    ai.HTMLToText(trimmedValue(entry.Content)),
+		Author:  trimmedValue(entry.Author),
+		URL:     trimmedValue(entry.URL),
+	}
+}
+
+// applyRewrite replaces the fields of entry named in the result of a rewrite
+// expression. A nil result leaves the entry as it is.
+func applyRewrite(entry *model.Entry, result any) (bool, error) {
+	if result == nil {
+		return false, nil
+	}
+	changes, ok := result.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf("rewrite returned %T, not a map", result)
+	}
+	if len(changes) == 0 {
+		return false, nil
+	}
+	// Check every field before changing any
+	values := make(map[string]*string, len(changes))
+	for key, value := range changes {
+		if !slices.Contains(filterRewriteKeys, key) {
+			return false, fmt.Errorf("rewrite cannot set %q", key)
+		}
+		switch v := value.(type) {
+		case nil:
+			values[key] = nil
+		case string:
+			values[key] = &v
+		default:
+			return false, fmt.Errorf("rewrite of %q is %T, not a string", key, value)
+		}
+	}
+	for key, value := range values {
+		switch key {
+		case "title":
+			entry.Title = value
+		case "content":
+			entry.Content = value
+		case "author":
+			entry.Author = value
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/expr-lang/expr/vm"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+	"gist/backend/internal/service/ai"
+)
+
+// FilterParams describes the rule of a filter to create or update.
+type FilterParams struct {
+	FeedID   *int64
+	Field    string
+	Match    string
+	Pattern  string
+	Action   string
+	FolderID *int64
+	Enabled  bool
+}
+
+// FilterTestResult is what a rule did to a sample entry.
+type FilterTestResult struct {
+	Matched bool
+	Skip    bool
+	// Entry is the sample after the rule: read, starred, filed or rewritten.
+	Entry model.Entry
+	// Error is why an expression failed to run; the rule is then ignored.
+	Error string
+}
+
+type FilterService interface {
+	List(ctx context.Context) ([]model.Filter, error)
+	Create(ctx context.Context, params FilterParams) (model.Filter, error)
+	Update(ctx context.Context, id int64, params FilterParams) (model.Filter, error)
+	Delete(ctx context.Context, id int64) error
+	// RulesFor returns the enabled filters that apply to new entries of a feed.
+	RulesFor(ctx context.Context, feedID int64) (FilterRules, error)
+	// Test runs a rule, without saving it, on a sample entry.
+	Test(ctx context.Context, params FilterParams, sample model.Entry) (FilterTestResult, error)
+}
+
+type filterService struct {
+	filters repository.FilterRepository
+	feeds   repository.FeedRepository
+	folders repository.FolderRepository
+}
+
+func NewFilterService(filters repository.FilterRepository, feeds repository.FeedRepository, folders repository.FolderRepository) FilterService {
+	return &filterService{filters: filters, feeds: feeds, folders: folders}
+}
+
+func (s *filterService) List(ctx context.Context) ([]model.Filter, error) {
+	return s.filters.List(ctx)
+}
+
+func (s *filterService) Create(ctx context.Context, params FilterParams) (model.Filter, error) {
+	filter, err := s.validate(ctx, params)
+	if err != nil {
+		return model.Filter{}, err
+	}
+	return s.filters.Create(ctx, filter)
+}
+
+func (s *filterService) Update(ctx context.Context, id int64, params FilterParams) (model.Filter, error) {
+	filter, err := s.validate(ctx, params)
+	if err != nil {
+		return model.Filter{}, err
+	}
+	filter.ID = id
+	updated, err := s.filters.Update(ctx, filter)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Filter{}, ErrNotFound
+	}
+	return updated, err
+}
+
+func (s *filterService) Delete(ctx context.Context, id int64) error {
+	if _, err := s.filters.GetByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get filter: %w", err)
+	}
+	return s.filters.Delete(ctx, id)
+}
+
+// validate checks params and turns them into the filter to store. The feed
+// and the folder of a move must exist; other actions drop the folder. An
+// expression reads the entry itself, so it has no field.
+func (s *filterService) validate(ctx context.Context, params FilterParams) (model.Filter, error) {
+	filter := model.Filter{
+		FeedID:  params.FeedID,
+		Field:   params.Field,
+		Match:   params.Match,
+		Pattern: strings.TrimSpace(params.Pattern),
+		Action:  params.Action,
+		Enabled: params.Enabled,
+	}
+	if filter.Match == model.FilterExpr {
+		filter.Field = ""
+	} else {
+		switch filter.Field {
+		case model.FilterFieldTitle, model.FilterFieldContent, model.FilterFieldAuthor, model.FilterFieldURL:
+		default:
+			return model.Filter{}, fmt.Errorf("%w: unknown field %q", ErrInvalid, filter.Field)
+		}
+	}
+	if filter.Pattern == "" {
+		return model.Filter{}, fmt.Errorf("%w: empty pattern", ErrInvalid)
+	}
+	switch filter.Match {
+	case model.FilterContains:
+	case model.FilterRegex:
+		if _, err := regexp.Compile(filter.Pattern); err != nil {
+			return model.Filter{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	case model.FilterExpr:
+		if _, err := compileFilterExpr(filter.Pattern, filter.Action); err != nil {
+			return model.Filter{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	default:
+		return model.Filter{}, fmt.Errorf("%w: unknown match %q", ErrInvalid, filter.Match)
+	}
+	switch filter.Action {
+	case model.FilterActionRead, model.FilterActionStar, model.FilterActionSkip:
+	case model.FilterActionRewrite:
+		if filter.Match != model.FilterExpr {
+			return model.Filter{}, fmt.Errorf("%w: only an expression can rewrite", ErrInvalid)
+		}
+	case model.FilterActionMove:
+		if params.FolderID == nil {
+			return model.Filter{}, fmt.Errorf("%w: move needs a folder", ErrInvalid)
+		}
+		if _, err := s.folders.GetByID(ctx, *params.FolderID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return model.Filter{}, ErrNotFound
+			}
+			return model.Filter{}, fmt.Errorf("get folder: %w", err)
+		}
+		filter.FolderID = params.FolderID
+	default:
+		return model.Filter{}, fmt.Errorf("%w: unknown action %q", ErrInvalid, filter.Action)
+	}
+	if filter.FeedID != nil {
+		if _, err := s.feeds.GetByID(ctx, *filter.FeedID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return model.Filter{}, ErrNotFound
+			}
+			return model.Filter{}, fmt.Errorf("get feed: %w", err)
+		}
+	}
+	return filter, nil
+}
+
+func (s *filterService) RulesFor(ctx context.Context, feedID int64) (FilterRules, error) {
+	filters, err := s.filters.ListForFeed(ctx, feedID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(FilterRules, 0, len(filters))
+	for _, filter := range filters {
+		// Patterns are checked when saved, so this only fails for rows
+		// written some other way
+		rule, err := compileFilter(filter)
+		if err != nil {
+			log.Printf("filter %d: %v", filter.ID, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *filterService) Test(ctx context.Context, params FilterParams, sample model.Entry) (FilterTestResult, error) {
+	filter, err := s.validate(ctx, params)
+	if err != nil {
+		return FilterTestResult{}, err
+	}
+	rule, err := compileFilter(filter)
+	if err != nil {
+		return FilterTestResult{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	result := FilterTestResult{Entry: sample}
+	result.Matched, result.Skip, err = rule.apply(&filterInput{entry: &result.Entry})
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, nil
+}
+
+// FilterRules are the compiled filters of one feed, in the order they were created.
+type FilterRules []filterRule
+
+type filterRule struct {
+	filter  model.Filter
+	pattern string // lower-cased, for contains
+	re      *regexp.Regexp
+	program *vm.Program
+}
+
+func compileFilter(filter model.Filter) (filterRule, error) {
+	rule := filterRule{filter: filter, pattern: strings.ToLower(filter.Pattern)}
+	var err error
+	switch filter.Match {
+	case model.FilterRegex:
+		rule.re, err = regexp.Compile(filter.Pattern)
+	case model.FilterExpr:
+		rule.program, err = compileFilterExpr(filter.Pattern, filter.Action)
+	}
+	return rule, err
+}
+
+// Apply runs the rules on an entry of the feed, marking it read or starred,
+// rewriting it or setting the folder it is filed under, which only a new
+// entry keeps when saved. It reports whether a skip rule matched, in which
+// case the entry should not be stored. The first matching move wins, and
+// later rules see what earlier ones rewrote. An expression that fails is
+// logged and ignored.
+func (rules FilterRules) Apply(entry *model.Entry) (skip bool) {
+	in := filterInput{entry: entry}
+	for _, rule := range rules {
+		_, skip, err := rule.apply(&in)
+		if err != nil {
+			log.Printf("filter %d: %v", rule.filter.ID, err)
+			continue
+		}
+		if skip {
+			return true
+		}
+	}
+	return false
+}
+
+// filterInput is an entry the rules run on, with the views of it they read
+// made once.
+type filterInput struct {
+	entry *model.Entry
+	text  *string // content as plain text
+	env   *filterEnv
+}
+
+func (in *filterInput) field(field string) string {
+	switch field {
+	case model.FilterFieldTitle:
+		return trimmedValue(in.entry.Title)
+	case model.FilterFieldAuthor:
+		return trimmedValue(in.entry.Author)
+	case model.FilterFieldURL:
+		return trimmedValue(in.entry.URL)
+	case model.FilterFieldContent:
+		if in.text == nil {
+			plain := ai.HTMLToText(trimmedValue(in.entry.Content))
+			in.text = &plain
+		}
+		return *in.text
+	}
+	return ""
+}
+
+func (in *filterInput) exprEnv() filterEnv {
+	if in.env == nil {
+		env := newFilterEnv(in.entry)
+		in.env = &env
+		in.text = &env.Text
+	}
+	return *in.env
+}
+
+// apply runs one rule on the input, reporting whether it matched and whether
+// the entry is to be skipped.
+func (r filterRule) apply(in *filterInput) (matched, skip bool, err error) {
+	if r.program != nil {
+		value, err := runFilterExpr(r.program, in.exprEnv())
+		if err != nil {
+			return false, false, err
+		}
+		if r.filter.Action == model.FilterActionRewrite {
+			matched, err := applyRewrite(in.entry, value)
+			if matched {
+				in.text, in.env = nil, nil
+			}
+			return matched, false, err
+		}
+		matched, _ = value.(bool)
+	} else {
+		matched = r.matches(in.field(r.filter.Field))
+	}
+	if !matched {
+		return false, false, nil
+	}
+
+	switch r.filter.Action {
+	case model.FilterActionSkip:
+		return true, true, nil
+	case model.FilterActionRead:
+		in.entry.Read = true
+	case model.FilterActionStar:
+		in.entry.Starred = true
+	case model.FilterActionMove:
+		if in.entry.FolderID == nil {
+			in.entry.FolderID = r.filter.FolderID
+		}
+	}
+	return true, false, nil
+}
+
+func (r filterRule) matches(text string) bool {
+	if text == "" {
+		return false
+	}
+	if r.re != nil {
+		return r.re.MatchString(text)
+	}
+	return strings.Contains(strings.ToLower(text), r.pattern)
+}
+
+// filterRules loads the filters of a feed for a refresh. Failures are logged
+// and leave the feed unfiltered, like an unreadable NSFW keyword setting.
+func filterRules(ctx context.Context, filters FilterService, feedID int64) FilterRules {
+	if filters == nil {
+		return nil
+	}
+	rules, err := filters.RulesFor(ctx, feedID)
+	if err != nil {
+		log.Printf("load filters of feed %d: %v", feedID, err)
+		return nil
+	}
+	return rules
+}
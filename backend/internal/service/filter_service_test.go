@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestFilterRules_Apply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	muted, later := int64(10), int64(11)
+	mockFilters := testutil.NewMockFilterRepository(ctrl)
+	mockFilters.EXPECT().ListForFeed(gomock.Any(), int64(1)).Return([]model.Filter{
+		{ID: 1, Field: model.FilterFieldTitle, Match: model.FilterContains, Pattern: "Sponsored", Action: model.FilterActionSkip},
+		{ID: 2, Field: model.FilterFieldAuthor, Match: model.FilterRegex, Pattern: `^(bot|robot)$`, Action: model.FilterActionRead},
+		{ID: 3, Field: model.FilterFieldContent, Match: model.FilterContains, Pattern: "release notes", Action: model.FilterActionStar},
+		{ID: 4, Field: model.FilterFieldURL, Match: model.FilterContains, Pattern: "/podcast/", Action: model.FilterActionMove, FolderID: &muted},
+		{ID: 5, Field: model.FilterFieldURL, Match: model.FilterContains, Pattern: "example.com", Action: model.FilterActionMove, FolderID: &later},
+	}, nil)
+	service := NewFilterService(mockFilters, nil, nil)
+
+	rules, err := service.RulesFor(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("load rules: %v", err)
+	}
+
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name     string
+		entry    model.Entry
+		skip     bool
+		read     bool
+		starred  bool
+		folderID int64
+	}{
+		{"contains ignores case", model.Entry{Title: str("[sponsored] A deal"), URL: str("https://other.org/1")}, true, false, false, 0},
+		{"regex", model.Entry{Author: str("bot"), URL: str("https://other.org/2")}, false, true, false, 0},
+		{"regex does not match part", model.Entry{Author: str("botanist"), URL: str("https://other.org/3")}, false, false, false, 0},
+		{"content is matched as text", model.Entry{Content: str("<p>Our <b>release</b> notes</p>"), URL: str("https://other.org/4")}, false, false, true, 0},
+		{"first move wins", model.Entry{URL: str("https://example.com/podcast/7")}, false, false, false, muted},
+		{"later move", model.Entry{URL: str("https://example.com/post/8")}, false, false, false, later},
+		{"no match", model.Entry{Title: str("Hello"), URL: str("https://other.org/9")}, false, false, false, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := tt.entry
+			skip := rules.Apply(&entry)
+			var folderID int64
+			if entry.FolderID != nil {
+				folderID = *entry.FolderID
+			}
+			if skip != tt.skip || entry.Read != tt.read || entry.Starred != tt.starred || folderID != tt.folderID {
+				t.Errorf("expected skip=%v read=%v starred=%v folder=%d, got skip=%v read=%v starred=%v folder=%d",
+					tt.skip, tt.read, tt.starred, tt.folderID, skip, entry.Read, entry.Starred, folderID)
+			}
+		})
+	}
+}
+
+func TestFilterRules_ApplyExpr(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFilters := testutil.NewMockFilterRepository(ctrl)
+	mockFilters.EXPECT().ListForFeed(gomock.Any(), int64(1)).Return([]model.Filter{
+		{ID: 1, Match: model.FilterExpr, Pattern: `title startsWith "[AD]" ? {title: trim(title[4:])} : nil`, Action: model.FilterActionRewrite},
+		{ID: 2, Match: model.FilterExpr, Pattern: `url contains "/ads/" && author == ""`, Action: model.FilterActionSkip},
+		{ID: 3, Field: model.FilterFieldTitle, Match: model.FilterContains, Pattern: "[ad]", Action: model.FilterActionRead},
+		{ID: 4, Match: model.FilterExpr, Pattern: `len(text) > 10 and text matches "(?i)release"`, Action: model.FilterActionStar},
+		{ID: 5, Match: model.FilterExpr, Pattern: `{summary: title}`, Action: model.FilterActionRewrite},
+	}, nil)
+	service := NewFilterService(mockFilters, nil, nil)
+
+	rules, err := service.RulesFor(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("load rules: %v", err)
+	}
+
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name    string
+		entry   model.Entry
+		skip    bool
+		read    bool
+		starred bool
+		title   string
+	}{
+		{"rewrite is seen by later rules", model.Entry{Title: str("[AD] Sale"), URL: str("https://example.com/1")}, false, false, false, "Sale"},
+		{"skip", model.Entry{Title: str("Hi"), URL: str("https://example.com/ads/2")}, true, false, false, "Hi"},
+		{"skip needs both", model.Entry{Title: str("Hi"), Author: str("Ann"), URL: str("https://example.com/ads/3")}, false, false, false, "Hi"},
+		{"content as text", model.Entry{Title: str("v2"), Content: str("<p>New <b>Release</b> out</p>"), URL: str("https://example.com/4")}, false, false, true, "v2"},
+		{"no match", model.Entry{Title: str("Hello"), URL: str("https://example.com/5")}, false, false, false, "Hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := tt.entry
+			skip := rules.Apply(&entry)
+			if skip != tt.skip || entry.Read != tt.read || entry.Starred != tt.starred || *entry.Title != tt.title {
+				t.Errorf("expected skip=%v read=%v starred=%v title=%q, got skip=%v read=%v starred=%v title=%q",
+					tt.skip, tt.read, tt.starred, tt.title, skip, entry.Read, entry.Starred, *entry.Title)
+			}
+		})
+	}
+}
+
+func TestFilterService_Test(t *testing.T) {
+	service := NewFilterService(nil, nil, nil)
+	ctx := context.Background()
+	title := "Weekly digest"
+	sample := model.Entry{Title: &title}
+
+	result, err := service.Test(ctx, FilterParams{
+		Match: model.FilterExpr, Pattern: `{title: upper(title), author: "Gist"}`, Action: model.FilterActionRewrite,
+	}, sample)
+	if err != nil {
+		t.Fatalf("test rewrite: %v", err)
+	}
+	if !result.Matched || *result.Entry.Title != "WEEKLY DIGEST" || *result.Entry.Author != "Gist" || title != "Weekly digest" {
+		t.Errorf("unexpected rewrite result %+v", result)
+	}
+
+	result, err = service.Test(ctx, FilterParams{
+		Field: model.FilterFieldTitle, Match: model.FilterContains, Pattern: "news", Action: model.FilterActionSkip,
+	}, sample)
+	if err != nil || result.Matched || result.Skip {
+		t.Errorf("expected no match, got %+v, %v", result, err)
+	}
+
+	// A failure on the entry is reported, not returned
+	result, err = service.Test(ctx, FilterParams{
+		Match: model.FilterExpr, Pattern: `{title: 1}`, Action: model.FilterActionRewrite,
+	}, sample)
+	if err != nil || result.Error == "" || *result.Entry.Title != title {
+		t.Errorf("expected a rewrite error, got %+v, %v", result, err)
+	}
+
+	if _, err := service.Test(ctx, FilterParams{Match: model.FilterExpr, Pattern: `title ==`, Action: model.FilterActionSkip}, sample); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid, got %v", err)
+	}
+}
+
+func TestFilterService_Create_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFilters := testutil.NewMockFilterRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	service := NewFilterService(mockFilters, mockFeeds, mockFolders)
+	ctx := context.Background()
+
+	valid := FilterParams{Field: model.FilterFieldTitle, Match: model.FilterContains, Pattern: "ad", Action: model.FilterActionRead, Enabled: true}
+	folderID := int64(5)
+	feedID := int64(6)
+	tests := []struct {
+		name   string
+		modify func(*FilterParams)
+		want   error
+	}{
+		{"unknown field", func(p *FilterParams) { p.Field = "summary" }, ErrInvalid},
+		{"blank pattern", func(p *FilterParams) { p.Pattern = "  " }, ErrInvalid},
+		{"bad regex", func(p *FilterParams) { p.Match, p.Pattern = model.FilterRegex, "(" }, ErrInvalid},
+		{"unknown action", func(p *FilterParams) { p.Action = "delete" }, ErrInvalid},
+		{"move without folder", func(p *FilterParams) { p.Action = model.FilterActionMove }, ErrInvalid},
+		{"bad expression", func(p *FilterParams) { p.Match, p.Pattern = model.FilterExpr, `title contains` }, ErrInvalid},
+		{"expression not bool", func(p *FilterParams) { p.Match, p.Pattern = model.FilterExpr, `len(title)` }, ErrInvalid},
+		{"unknown variable", func(p *FilterParams) { p.Match, p.Pattern = model.FilterExpr, `summary != ""` }, ErrInvalid},
+		{"rewrite without expression", func(p *FilterParams) { p.Action = model.FilterActionRewrite }, ErrInvalid},
+		{"rewrite to string", func(p *FilterParams) {
+			p.Match, p.Pattern, p.Action = model.FilterExpr, `upper(title)`, model.FilterActionRewrite
+		}, ErrInvalid},
+		{"missing folder", func(p *FilterParams) { p.Action, p.FolderID = model.FilterActionMove, &folderID }, ErrNotFound},
+		{"missing feed", func(p *FilterParams) { p.FeedID = &feedID }, ErrNotFound},
+	}
+	mockFolders.EXPECT().GetByID(ctx, folderID).Return(model.Folder{}, sql.ErrNoRows)
+	mockFeeds.EXPECT().GetByID(ctx, feedID).Return(model.Feed{}, sql.ErrNoRows)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := valid
+			tt.modify(&params)
+			if _, err := service.Create(ctx, params); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Only a move keeps its folder
+	mockFilters.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, filter model.Filter) (model.Filter, error) {
+		if filter.FolderID != nil || filter.Pattern != "ad" {
+			t.Errorf("unexpected filter to store %+v", filter)
+		}
+		return filter, nil
+	})
+	params := valid
+	params.Pattern = " ad "
+	params.FolderID = &folderID
+	if _, err := service.Create(ctx, params); err != nil {
+		t.Fatalf("create filter: %v", err)
+	}
+}
+
+func TestRefreshService_AppliesFilters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Blog</title>
+<item><title>Sponsored: a deal</title><link>https://example.com/1</link></item>
+<item><title>Weekly digest</title><link>https://example.com/2</link></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFilters := testutil.NewMockFilterRepository(ctrl)
+	filters := NewFilterService(mockFilters, mockFeeds, nil)
+	service := NewRefreshService(mockFeeds, mockEntries, nil, filters, server.Client(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Blog", URL: server.URL}, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	mockFilters.EXPECT().ListForFeed(gomock.Any(), int64(1)).Return([]model.Filter{
+		{ID: 1, Field: model.FilterFieldTitle, Match: model.FilterContains, Pattern: "sponsored", Action: model.FilterActionSkip},
+		{ID: 2, Field: model.FilterFieldTitle, Match: model.FilterRegex, Pattern: `(?i)digest`, Action: model.FilterActionRead},
+	}, nil)
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), "https://example.com/2").Return(model.Entry{}, sql.ErrNoRows)
+	mockEntries.EXPECT().GetByTitlePublished(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(model.Entry{}, sql.ErrNoRows).AnyTimes()
+	mockEntries.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		if *entry.URL != "https://example.com/2" || !entry.Read {
+			t.Errorf("expected only the digest to be stored, read, got %s read=%v", *entry.URL, entry.Read)
+		}
+		return nil
+	})
+
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Noooste/azuretls-client"
+
+	"gist/backend/internal/repository"
+)
+
+// Browser fingerprints a host can be fetched with
+const (
+	FingerprintChrome  = azuretls.Chrome
+	FingerprintFirefox = azuretls.Firefox
+	FingerprintSafari  = azuretls.Safari
+)
+
+// FingerprintTransport sends requests to the hosts given a browser in
+// GeneralSettings.TLSFingerprints through azuretls, with that browser's TLS
+// and HTTP/2 fingerprint, since some anti-bot CDNs reject Go's own. Other
+// hosts go through the default transport. Redirects and cookies are left to
+// the http.Client, as with any transport.
+type FingerprintTransport struct {
+	settings repository.SettingsRepository
+	base     http.RoundTripper
+	mu       sync.Mutex
+	// sessions holds one azuretls session per browser, created on first use
+	sessions map[string]*azuretls.Session
+}
+
+func NewFingerprintTransport(settings repository.SettingsRepository) *FingerprintTransport {
+	return &FingerprintTransport{
+		settings: settings,
+		base:     http.DefaultTransport,
+		sessions: make(map[string]*azuretls.Session),
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *FingerprintTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	browser := t.browserFor(req.Context(), req.URL.Hostname())
+	if browser == "" {
+		return t.base.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Map order is random; sorted keys keep the header order stable
+	keys := make([]string, 0, len(req.Header))
+	for key := range req.Header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	headers := make(azuretls.OrderedHeaders, 0, len(keys))
+	for _, key := range keys {
+		headers = append(headers, append([]string{strings.ToLower(key)}, req.Header[key]...))
+	}
+
+	fetch := &azuretls.Request{
+		Method:           req.Method,
+		Url:              req.URL.String(),
+		OrderedHeaders:   headers,
+		DisableRedirects: true,
+		NoCookie:         true,
+	}
+	if len(body) > 0 {
+		fetch.Body = body
+	}
+	fetch.SetContext(req.Context())
+	resp, err := t.session(browser).Do(fetch)
+	if err != nil {
+		return nil, fmt.Errorf("%s fingerprint: %w", browser, err)
+	}
+
+	header := make(http.Header, len(resp.Header))
+	for key, values := range resp.Header {
+		header[key] = values
+	}
+	// azuretls has already decoded and read the body
+	header.Del("Content-Encoding")
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        resp.Status,
+		StatusCode:    resp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(resp.Body)),
+		ContentLength: int64(len(resp.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Close closes the azuretls sessions.
+func (t *FingerprintTransport) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for browser, session := range t.sessions {
+		session.Close()
+		delete(t.sessions, browser)
+	}
+}
+
+func (t *FingerprintTransport) session(browser string) *azuretls.Session {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	session, ok := t.sessions[browser]
+	if !ok {
+		session = azuretls.NewSession()
+		session.Browser = browser
+		session.SetTimeout(refreshTimeout)
+		t.sessions[browser] = session
+	}
+	return session
+}
+
+// browserFor returns the browser to fetch host as, or "" for Go's own
+// fingerprint.
+func (t *FingerprintTransport) browserFor(ctx context.Context, host string) string {
+	if t.settings == nil {
+		return ""
+	}
+	setting, err := t.settings.Get(ctx, keyTLSFingerprints)
+	if err != nil || setting == nil {
+		return ""
+	}
+	fingerprints, err := ParseTLSFingerprints(setting.Value)
+	if err != nil {
+		return ""
+	}
+	return fingerprintFor(fingerprints, host)
+}
+
+// ParseTLSFingerprints parses comma- or line-separated "host=browser" pairs,
+// where browser is FingerprintChrome, FingerprintFirefox or FingerprintSafari.
+func ParseTLSFingerprints(raw string) (map[string]string, error) {
+	fingerprints := make(map[string]string)
+	for _, field := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '，' || r == '\n' }) {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		host, browser, ok := strings.Cut(field, "=")
+		host = strings.Trim(strings.ToLower(strings.TrimSpace(host)), ".")
+		browser = strings.ToLower(strings.TrimSpace(browser))
+		if !ok || host == "" {
+			return nil, fmt.Errorf("%w: TLS fingerprint %q must be host=browser", ErrInvalid, field)
+		}
+		switch browser {
+		case FingerprintChrome, FingerprintFirefox, FingerprintSafari:
+			fingerprints[host] = browser
+		default:
+			return nil, fmt.Errorf("%w: unknown TLS fingerprint %q", ErrInvalid, browser)
+		}
+	}
+	return fingerprints, nil
+}
+
+// fingerprintFor returns the browser of host, or of the closest parent
+// domain listed.
+func fingerprintFor(fingerprints map[string]string, host string) string {
+	host = strings.ToLower(host)
+	for {
+		if browser, ok := fingerprints[host]; ok {
+			return browser
+		}
+		_, parent, ok := strings.Cut(host, ".")
+		if !ok {
+			return ""
+		}
+		host = parent
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFingerprintTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/feed", http.StatusMovedPermanently)
+			return
+		}
+		if r.Header.Get("If-None-Match") != `"v1"` {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte("<rss></rss>"))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	settings := &memorySettings{values: map[string]string{keyTLSFingerprints: "127.0.0.1=firefox"}}
+	transport := NewFingerprintTransport(settings)
+	defer transport.Close()
+	client := &http.Client{Transport: transport}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/old", nil)
+	req.Header.Set("If-None-Match", `"v1"`)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "<rss></rss>" {
+		t.Fatalf("expected the decoded feed after the redirect, got HTTP %d %q", resp.StatusCode, body)
+	}
+	if resp.Header.Get("Content-Encoding") != "" || resp.Request.URL.Path != "/feed" {
+		t.Errorf("unexpected response %v for %s", resp.Header, resp.Request.URL)
+	}
+	if len(transport.sessions) != 1 || transport.sessions[FingerprintFirefox] == nil {
+		t.Errorf("expected the host to be fetched as firefox, sessions %v", transport.sessions)
+	}
+}
+
+func TestParseTLSFingerprints(t *testing.T) {
+	got, err := ParseTLSFingerprints(" Example.com = Firefox, cdn.example.net=chrome\n.news.org=safari ")
+	want := map[string]string{"example.com": "firefox", "cdn.example.net": "chrome", "news.org": "safari"}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v %v, want %v", got, err, want)
+	}
+	if browser := fingerprintFor(got, "feeds.EXAMPLE.com"); browser != FingerprintFirefox {
+		t.Errorf("expected a subdomain to use its parent's browser, got %q", browser)
+	}
+	if browser := fingerprintFor(got, "example.org"); browser != "" {
+		t.Errorf("expected no browser for an unlisted host, got %q", browser)
+	}
+
+	for _, raw := range []string{"example.com", "example.com=opera", "=chrome"} {
+		if _, err := ParseTLSFingerprints(raw); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: expected ErrInvalid, got %v", raw, err)
+		}
+	}
+}
@@ -16,6 +16,9 @@ type FolderService interface {
 	List(ctx context.Context) ([]model.Folder, error)
 	Update(ctx context.Context, id int64, name string, parentID *int64) (model.Folder, error)
 	UpdateType(ctx context.Context, id int64, folderType string) error
+	// SetPregenerateThumbnails turns pre-generating grid thumbnails after each
+	// refresh on or off. Only picture folders can turn it on.
+	SetPregenerateThumbnails(ctx context.Context, id int64, enabled bool) error
 	Delete(ctx context.Context, id int64) error
 }
 
@@ -149,6 +152,20 @@ func (s *folderService) UpdateType(ctx context.Context, id int64, folderType str
 	return nil
 }
 
+func (s *folderService) SetPregenerateThumbnails(ctx context.Context, id int64, enabled bool) error {
+	folder, err := s.folders.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get folder: %w", err)
+	}
+	if enabled && folder.Type != "picture" {
+		return ErrInvalid
+	}
+	return s.folders.UpdatePregenerateThumbnails(ctx, id, enabled)
+}
+
 func (s *folderService) Delete(ctx context.Context, id int64) error {
 	if _, err := s.folders.GetByID(ctx, id); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -284,6 +284,45 @@ func TestFolderService_UpdateType_CascadeToFeeds(t *testing.T) {
 	}
 }
 
+func TestFolderService_SetPregenerateThumbnails_PictureOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewFolderService(mockFolders, mockFeeds)
+	ctx := context.Background()
+
+	mockFolders.EXPECT().
+		GetByID(ctx, int64(1)).
+		Return(model.Folder{ID: 1, Name: "Photos", Type: "picture"}, nil)
+	mockFolders.EXPECT().
+		UpdatePregenerateThumbnails(ctx, int64(1), true).
+		Return(nil)
+	if err := service.SetPregenerateThumbnails(ctx, 1, true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Article folders have no picture grid to pre-generate for
+	mockFolders.EXPECT().
+		GetByID(ctx, int64(2)).
+		Return(model.Folder{ID: 2, Name: "News", Type: "article"}, nil)
+	if err := service.SetPregenerateThumbnails(ctx, 2, true); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid, got %v", err)
+	}
+
+	// Turning it off is always allowed
+	mockFolders.EXPECT().
+		GetByID(ctx, int64(2)).
+		Return(model.Folder{ID: 2, Name: "News", Type: "article"}, nil)
+	mockFolders.EXPECT().
+		UpdatePregenerateThumbnails(ctx, int64(2), false).
+		Return(nil)
+	if err := service.SetPregenerateThumbnails(ctx, 2, false); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
+
 func TestFolderService_Delete_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
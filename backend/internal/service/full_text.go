@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gist/backend/internal/config"
+)
+
+// fullTextPlaceholder stands for the escaped entry URL in a full-text service URL.
+const fullTextPlaceholder = "{url}"
+
+// maxFullTextSize caps a full-text service response.
+const maxFullTextSize = 10 << 20
+
+// ValidateFullTextURL checks that raw is an http(s) URL a full-text service
+// can be called at, with or without the {url} placeholder.
+func ValidateFullTextURL(raw string) error {
+	parsed, err := url.Parse(strings.ReplaceAll(raw, fullTextPlaceholder, "x"))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: full-text service must be an http(s) URL", ErrInvalid)
+	}
+	return nil
+}
+
+// fullTextRequestURL is where the service at serviceURL extracts pageURL:
+// the {url} placeholder is replaced by the escaped page URL, as FiveFilters'
+// ?url={url} expects, and without one the page URL is appended as is, as
+// Morss expects (https://morss.it/:proxy/).
+func fullTextRequestURL(serviceURL, pageURL string) string {
+	if strings.Contains(serviceURL, fullTextPlaceholder) {
+		return strings.ReplaceAll(serviceURL, fullTextPlaceholder, url.QueryEscape(pageURL))
+	}
+	return serviceURL + pageURL
+}
+
+// extractEntry extracts the page of an entry of feedID, through the feed's
+// full-text service when it has one. A full-text service only returns the
+// content, so the language and direction are then those the content declares.
+func (s *readabilityService) extractEntry(ctx context.Context, feedID int64, pageURL string) (*ReadablePage, error) {
+	if s.feeds != nil {
+		feed, err := s.feeds.GetByID(ctx, feedID)
+		if err != nil {
+			return nil, fmt.Errorf("get feed: %w", err)
+		}
+		ctx = withRequestOverrides(ctx, feed.RequestOverrides)
+		if feed.FullTextURL != nil {
+			content, err := s.extractWithService(ctx, *feed.FullTextURL, pageURL)
+			if err != nil {
+				return nil, err
+			}
+			lang, dir := declaredLanguage([]byte(content))
+			return &ReadablePage{Content: content, Language: lang, Direction: textDirection(dir, lang)}, nil
+		}
+	}
+	return s.ExtractPage(ctx, pageURL)
+}
+
+// extractWithService asks a full-text service for the content of pageURL.
+// The service may answer with JSON carrying a content (FiveFilters
+// extract.php) or html field, with a feed whose first item holds the full
+// text (Morss, FiveFilters makefulltextfeed.php), or with the page itself,
+// which is then run through readability.
+func (s *readabilityService) extractWithService(ctx context.Context, serviceURL, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullTextRequestURL(serviceURL, pageURL), nil)
+	if err != nil {
+		return "", fmt.Errorf("%w: full-text service: %v", ErrFeedFetch, err)
+	}
+	req.Header.Set("User-Agent", config.GistUserAgent)
+	resp, err := s.fullText.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: full-text service: %v", ErrFeedFetch, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: full-text service returned HTTP %d", ErrFeedFetch, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFullTextSize))
+	if err != nil {
+		return "", fmt.Errorf("%w: full-text service: %v", ErrFeedFetch, err)
+	}
+
+	content, err := s.fullTextContent(pageURL, body)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("%w: full-text service returned no content", ErrFeedFetch)
+	}
+	return s.content.Sanitize(ctx, content), nil
+}
+
+func (s *readabilityService) fullTextContent(pageURL string, body []byte) (string, error) {
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		var doc struct {
+			Content string `json:"content"`
+			HTML    string `json:"html"`
+		}
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return "", fmt.Errorf("%w: full-text service returned invalid JSON: %v", ErrFeedFetch, err)
+		}
+		if doc.Content != "" {
+			return doc.Content, nil
+		}
+		return doc.HTML, nil
+	}
+
+	if feed, err := newFeedParser().Parse(bytes.NewReader(body)); err == nil {
+		if len(feed.Items) == 0 {
+			return "", fmt.Errorf("%w: full-text service returned an empty feed", ErrFeedFetch)
+		}
+		if item := feed.Items[0]; item.Content != "" {
+			return item.Content, nil
+		}
+		return feed.Items[0].Description, nil
+	}
+
+	page, err := s.extract(pageURL, body)
+	if err != nil {
+		return "", err
+	}
+	return page.Content, nil
+}
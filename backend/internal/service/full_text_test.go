@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestFullTextRequestURL(t *testing.T) {
+	page := "https://example.com/post?id=1&ref=rss"
+	if got := fullTextRequestURL("https://ftr.example.com/extract.php?url={url}", page); got != "https://ftr.example.com/extract.php?url=https%3A%2F%2Fexample.com%2Fpost%3Fid%3D1%26ref%3Drss" {
+		t.Errorf("unexpected FiveFilters URL %s", got)
+	}
+	if got := fullTextRequestURL("https://morss.it/:proxy/", page); got != "https://morss.it/:proxy/"+page {
+		t.Errorf("unexpected Morss URL %s", got)
+	}
+	for _, bad := range []string{"ftp://example.com/{url}", "/extract?url={url}", "https://"} {
+		if err := ValidateFullTextURL(bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %q to be invalid, got %v", bad, err)
+		}
+	}
+}
+
+func TestReadabilityService_FullTextService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"title":"Post","content":"<p>From JSON for %s</p><script>alert(1)</script>"}`, r.URL.Query().Get("url"))
+		case "/feed":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprint(w, `<rss version="2.0"><channel><title>Full text</title>
+<item><title>Post</title><link>https://example.com/post</link><description><![CDATA[<p>From the feed</p>]]></description></item>
+</channel></rss>`)
+		case "/empty":
+			fmt.Fprint(w, `{"content":""}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	service := NewReadabilityService(nil, nil, nil, nil, nil).(*readabilityService)
+	defer service.Close()
+	ctx := context.Background()
+	page := "https://example.com/post"
+
+	tests := []struct {
+		name       string
+		serviceURL string
+		want       string
+		wantErr    bool
+	}{
+		{"json content, sanitized", server.URL + "/json?url={url}", "<p>From JSON for " + page + "</p>", false},
+		{"feed item", server.URL + "/feed?url={url}", "<p>From the feed</p>", false},
+		{"no content", server.URL + "/empty?url={url}", "", true},
+		{"error status", server.URL + "/missing/", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.extractWithService(ctx, tt.serviceURL, page)
+			if tt.wantErr {
+				if !errors.Is(err, ErrFeedFetch) {
+					t.Errorf("expected ErrFeedFetch, got %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil || strings.TrimSpace(got) != tt.want {
+				t.Errorf("expected %q, got %q, %v", tt.want, got, err)
+			}
+		})
+	}
+}
+
+func TestReadabilityService_FetchReadableContent_FeedService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"content":"<p lang=\"he\">Full text</p>"}`)
+	}))
+	defer server.Close()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewReadabilityService(mockEntries, mockFeeds, nil, nil, nil)
+	defer service.Close()
+	ctx := context.Background()
+
+	url := "https://example.com/post"
+	fullTextURL := server.URL + "/extract?url={url}"
+	want := model.ReadableMeta{Language: "he", Direction: model.DirectionRTL}
+	mockEntries.EXPECT().GetByID(ctx, int64(7)).Return(model.Entry{ID: 7, FeedID: 1, URL: &url}, nil)
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, FullTextURL: &fullTextURL}, nil)
+	mockEntries.EXPECT().UpdateReadableContent(ctx, int64(7), `<p lang="he">Full text</p>`, want).Return(nil)
+
+	content, meta, err := service.FetchReadableContent(ctx, 7, false)
+	if err != nil {
+		t.Fatalf("fetch readable content: %v", err)
+	}
+	if content != `<p lang="he">Full text</p>` || meta != want {
+		t.Errorf("unexpected content %q, %+v", content, meta)
+	}
+}
+
+func TestRefreshService_FetchesFullTextOfNewEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/extract" {
+			fmt.Fprint(w, `{"content":"<p>Full text</p>"}`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Blog</title>
+<item><title>New</title><link>https://example.com/new</link></item>
+<item><title>Known</title><link>https://example.com/known</link></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	readability := NewReadabilityService(mockEntries, mockFeeds, nil, nil, nil)
+	defer readability.Close()
+	service := NewRefreshService(mockFeeds, mockEntries, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, readability, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	fullTextURL := server.URL + "/extract?url={url}"
+	feed := model.Feed{ID: 1, Title: "Blog", URL: server.URL, FullTextURL: &fullTextURL}
+	mockFeeds.EXPECT().GetByID(gomock.Any(), int64(1)).Return(feed, nil).Times(2)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	newURL, knownURL := "https://example.com/new", "https://example.com/known"
+	gomock.InOrder(
+		mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), newURL).Return(model.Entry{}, sql.ErrNoRows),
+		mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), newURL).Return(model.Entry{ID: 5, FeedID: 1, URL: &newURL}, nil),
+	)
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), knownURL).Return(model.Entry{ID: 6, FeedID: 1, URL: &knownURL}, nil)
+	mockEntries.EXPECT().GetByTitlePublished(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(model.Entry{}, sql.ErrNoRows).AnyTimes()
+	mockEntries.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockEntries.EXPECT().SaveRevision(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockEntries.EXPECT().GetByID(gomock.Any(), int64(5)).Return(model.Entry{ID: 5, FeedID: 1, URL: &newURL}, nil)
+	// Only the new entry is extracted
+	mockEntries.EXPECT().UpdateReadableContent(gomock.Any(), int64(5), "<p>Full text</p>", model.ReadableMeta{}).Return(nil)
+
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	// Extraction runs in the background, after the refresh returns
+	service.(*refreshService).prefetching.Wait()
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+// Future date modes of the general settings, for entries whose feed dates
+// them later than they were fetched
+const (
+	FutureDatesKeep  = "keep"  // store the date as the feed gives it
+	FutureDatesClamp = "clamp" // date the entry when it was first seen
+	FutureDatesHide  = "hide"  // store the entry once its date arrives
+)
+
+// futureDateSkew is how far ahead of the local clock a date may be before it
+// counts as in the future, for publishers whose clocks run a little fast.
+const futureDateSkew = 5 * time.Minute
+
+// futureDates is how a refresh treats entries dated in the future.
+type futureDates struct {
+	mode string
+	now  time.Time
+}
+
+// loadFutureDates reads the future date mode for a refresh. Without settings,
+// or when they cannot be read, dates are clamped.
+func loadFutureDates(ctx context.Context, settings SettingsService) futureDates {
+	d := futureDates{mode: FutureDatesClamp, now: time.Now()}
+	if settings == nil {
+		return d
+	}
+	if general, err := settings.GetGeneralSettings(ctx); err == nil {
+		d.mode = general.FutureDates
+	}
+	return d
+}
+
+// forFeed clamps the future dates of a feed whose date quirks ignore them,
+// whatever the setting.
+func (d futureDates) forFeed(feed model.Feed) futureDates {
+	if feed.DateQuirks != nil && feed.DateQuirks.IgnoreFuture {
+		d.mode = FutureDatesClamp
+	}
+	return d
+}
+
+// future reports whether entry is dated later than the refresh began.
+func (d futureDates) future(entry model.Entry) bool {
+	return entry.PublishedAt != nil && entry.PublishedAt.After(d.now.Add(futureDateSkew))
+}
+
+// hold reports whether a new entry must wait for its date to be stored.
+func (d futureDates) hold(entry model.Entry) bool {
+	return d.mode == FutureDatesHide && d.future(entry)
+}
+
+// clamp dates an entry the feed dates in the future when it was first seen,
+// keeping the feed's date as its original. existing is the stored copy of a
+// known entry, nil for a new one; a clamped entry keeps the date it was
+// clamped to, so it does not move up the list on every refresh.
+func (d futureDates) clamp(entry *model.Entry, existing *model.Entry) {
+	if d.mode != FutureDatesClamp {
+		return
+	}
+	clamped := existing != nil && existing.OriginalPublishedAt != nil
+	if !d.future(*entry) && !clamped {
+		return
+	}
+	entry.OriginalPublishedAt = entry.PublishedAt
+	switch {
+	case existing != nil && existing.PublishedAt != nil && (clamped || !existing.PublishedAt.After(d.now)):
+		entry.PublishedAt = existing.PublishedAt
+	default:
+		now := d.now
+		entry.PublishedAt = &now
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+func TestFutureDates_Clamp(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	soon := now.Add(time.Minute)
+	future := now.Add(72 * time.Hour)
+	earlier := now.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name         string
+		mode         string
+		published    *time.Time
+		existing     *model.Entry
+		wantDate     *time.Time
+		wantOriginal *time.Time
+	}{
+		{"past date kept", FutureDatesClamp, &past, nil, &past, nil},
+		{"clock skew kept", FutureDatesClamp, &soon, nil, &soon, nil},
+		{"no date", FutureDatesClamp, nil, nil, nil, nil},
+		{"new entry clamped to now", FutureDatesClamp, &future, nil, &now, &future},
+		{"known entry keeps its date", FutureDatesClamp, &future, &model.Entry{PublishedAt: &earlier}, &earlier, &future},
+		{"clamped entry keeps its date after the date arrives", FutureDatesClamp, &past,
+			&model.Entry{PublishedAt: &earlier, OriginalPublishedAt: &past}, &earlier, &past},
+		{"keep mode", FutureDatesKeep, &future, nil, &future, nil},
+		{"hide mode", FutureDatesHide, &future, nil, &future, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := model.Entry{PublishedAt: tt.published}
+			futureDates{mode: tt.mode, now: now}.clamp(&entry, tt.existing)
+			if !sameTime(entry.PublishedAt, tt.wantDate) {
+				t.Errorf("PublishedAt = %v, want %v", entry.PublishedAt, tt.wantDate)
+			}
+			if !sameTime(entry.OriginalPublishedAt, tt.wantOriginal) {
+				t.Errorf("OriginalPublishedAt = %v, want %v", entry.OriginalPublishedAt, tt.wantOriginal)
+			}
+		})
+	}
+}
+
+func TestFutureDates_Hold(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+	hide := futureDates{mode: FutureDatesHide, now: now}
+	if !hide.hold(model.Entry{PublishedAt: &future}) {
+		t.Error("expected a future entry held while hiding")
+	}
+	if hide.hold(model.Entry{PublishedAt: &past}) || hide.hold(model.Entry{}) {
+		t.Error("expected entries without a future date stored")
+	}
+	if (futureDates{mode: FutureDatesClamp, now: now}).hold(model.Entry{PublishedAt: &future}) {
+		t.Error("expected clamping not to hold entries")
+	}
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultHostCooldown is how long a host that answered 429 without a
+	// usable Retry-After is left alone.
+	defaultHostCooldown = 10 * time.Minute
+	// minHostCooldown and maxHostCooldown bound the cooldown a Retry-After
+	// asks for.
+	minHostCooldown = time.Minute
+	maxHostCooldown = 24 * time.Hour
+)
+
+// HostCooldownError is returned when a feed is not fetched because its host
+// answered 429 Too Many Requests and its cooldown has not ended.
+type HostCooldownError struct {
+	Host  string
+	Until time.Time
+}
+
+func (e *HostCooldownError) Error() string {
+	return fmt.Sprintf("%s is cooling down until %s", e.Host, e.Until.UTC().Format(time.RFC3339))
+}
+
+// hostCooldown is how long to leave a host alone after a 429 response with
+// the given header: its Retry-After, in seconds or as an HTTP date, within
+// minHostCooldown and maxHostCooldown, or defaultHostCooldown.
+func hostCooldown(header http.Header, now time.Time) time.Duration {
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return defaultHostCooldown
+	}
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		wait = at.Sub(now)
+	} else {
+		return defaultHostCooldown
+	}
+	return min(max(wait, minHostCooldown), maxHostCooldown)
+}
+
+func (s *feedHealthService) CoolDown(host string, until time.Time) {
+	if host == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.cooldowns[host]; !ok || until.After(current) {
+		s.cooldowns[host] = until
+	}
+}
+
+func (s *feedHealthService) CooldownUntil(host string) *time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until, ok := s.cooldowns[host]
+	if !ok {
+		return nil
+	}
+	if !until.After(time.Now()) {
+		delete(s.cooldowns, host)
+		return nil
+	}
+	return &until
+}
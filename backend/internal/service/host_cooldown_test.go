@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestHostCooldown(t *testing.T) {
+	now := time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		retryAfter string
+		want       time.Duration
+	}{
+		{"", defaultHostCooldown},
+		{"120", 2 * time.Minute},
+		{"5", minHostCooldown},
+		{"604800", maxHostCooldown},
+		{now.Add(30 * time.Minute).Format(http.TimeFormat), 30 * time.Minute},
+		{"soon", defaultHostCooldown},
+	}
+	for _, tt := range tests {
+		header := http.Header{}
+		if tt.retryAfter != "" {
+			header.Set("Retry-After", tt.retryAfter)
+		}
+		if got := hostCooldown(header, now); got != tt.want {
+			t.Errorf("Retry-After %q: got %s, want %s", tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestRefreshService_CoolsDownHostOn429(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockFetches := testutil.NewMockFeedFetchRepository(ctrl)
+	health := NewFeedHealthService(mockFetches, mockFeeds)
+	service := NewRefreshService(mockFeeds, nil, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, nil, nil, health, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	// Two feeds on the same host: the first is throttled, the second skipped
+	first := model.Feed{ID: 1, Title: "Posts", URL: server.URL + "/posts"}
+	second := model.Feed{ID: 2, Title: "Comments", URL: server.URL + "/comments"}
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(first, nil)
+	mockFeeds.EXPECT().UpdateErrorMessage(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	mockFeeds.EXPECT().IncrementFailures(gomock.Any(), int64(1)).Return(1, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	mockFetches.EXPECT().Add(gomock.Any(), gomock.Any(), feedFetchLogSize).Return(nil)
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	until := health.CooldownUntil(extractHost(server.URL))
+	if until == nil || time.Until(*until) < 9*time.Minute || time.Until(*until) > 10*time.Minute {
+		t.Fatalf("expected the host cooling down for 10 minutes, got %v", until)
+	}
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(2)).Return(second, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(2), *until).Return(nil)
+	_, err := service.RefreshNow(ctx, 2)
+	var cooling *HostCooldownError
+	if !errors.As(err, &cooling) || !cooling.Until.Equal(*until) {
+		t.Errorf("expected a cooldown error, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("expected the host asked once, got %d requests", requests)
+	}
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(2)).Return(second, nil)
+	mockFetches.EXPECT().ListByFeed(ctx, int64(2), feedFetchLogSize).Return(nil, nil)
+	feedHealth, err := health.Feed(ctx, 2)
+	if err != nil {
+		t.Fatalf("health: %v", err)
+	}
+	if feedHealth.CooldownUntil == nil || !feedHealth.CooldownUntil.Equal(*until) {
+		t.Errorf("expected the cooldown in the feed's health, got %v", feedHealth.CooldownUntil)
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // decode GIF icons
+	_ "image/jpeg" // decode JPEG icons
+	"image/png"
+)
+
+const (
+	// iconColorMaxSide guards against decoding huge uploaded images.
+	iconColorMaxSide = 1024
+	// iconColorSamples is about how many pixels per side are looked at.
+	iconColorSamples = 64
+	// iconNeutralChroma is the spread between channels below which a pixel
+	// counts as white, grey or black.
+	iconNeutralChroma = 24
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// colorBin adds up the pixels that fall into one 4-bit-per-channel bucket.
+type colorBin struct {
+	count   int
+	r, g, b int
+}
+
+// dominantColor returns the most common color of an icon as #rrggbb.
+// Transparent pixels are skipped, and white, grey and black only win when
+// the icon has little else, since most icons sit on a plain background. It
+// reports false for images it cannot decode.
+func dominantColor(data []byte) (string, bool) {
+	img, err := decodeIconImage(data)
+	if err != nil {
+		return "", false
+	}
+
+	bounds := img.Bounds()
+	step := bounds.Dx() / iconColorSamples
+	if h := bounds.Dy() / iconColorSamples; h > step {
+		step = h
+	}
+	if step < 1 {
+		step = 1
+	}
+
+	var colored, neutral [4096]colorBin
+	coloredCount, neutralCount := 0, 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 128 {
+				continue
+			}
+			bins := &colored
+			if chroma(c) < iconNeutralChroma {
+				bins = &neutral
+				neutralCount++
+			} else {
+				coloredCount++
+			}
+			bin := &bins[int(c.R>>4)<<8|int(c.G>>4)<<4|int(c.B>>4)]
+			bin.count++
+			bin.r += int(c.R)
+			bin.g += int(c.G)
+			bin.b += int(c.B)
+		}
+	}
+	if coloredCount+neutralCount == 0 {
+		return "", false
+	}
+
+	bins := &neutral
+	if coloredCount*20 >= coloredCount+neutralCount {
+		bins = &colored
+	}
+	best := &bins[0]
+	for i := range bins {
+		if bins[i].count > best.count {
+			best = &bins[i]
+		}
+	}
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.count, best.g/best.count, best.b/best.count), true
+}
+
+// chroma is the spread between the strongest and weakest channel.
+func chroma(c color.NRGBA) int {
+	hi, lo := c.R, c.R
+	for _, v := range []uint8{c.G, c.B} {
+		if v > hi {
+			hi = v
+		}
+		if v < lo {
+			lo = v
+		}
+	}
+	return int(hi) - int(lo)
+}
+
+// decodeIconImage decodes a PNG, JPEG or GIF icon, or the largest image of
+// an ICO file.
+func decodeIconImage(data []byte) (image.Image, error) {
+	if bytes.HasPrefix(data, []byte{0, 0, 1, 0}) {
+		return decodeICO(data)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width > iconColorMaxSide || cfg.Height > iconColorMaxSide {
+		return nil, fmt.Errorf("icon too large: %dx%d", cfg.Width, cfg.Height)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+// decodeICO decodes the largest image of an ICO file. Images stored as PNG
+// and 32-bit bitmaps are supported; older palette bitmaps are not.
+func decodeICO(data []byte) (image.Image, error) {
+	if len(data) < 6 {
+		return nil, fmt.Errorf("truncated ico header")
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:6]))
+	var best []byte
+	bestWidth := -1
+	for i := 0; i < count; i++ {
+		if len(data) < 6+16*(i+1) {
+			return nil, fmt.Errorf("truncated ico directory")
+		}
+		entry := data[6+16*i:]
+		width := int(entry[0])
+		if width == 0 {
+			width = 256
+		}
+		size := int(binary.LittleEndian.Uint32(entry[8:12]))
+		offset := int(binary.LittleEndian.Uint32(entry[12:16]))
+		if offset < 0 || size <= 0 || offset+size > len(data) || width <= bestWidth {
+			continue
+		}
+		best, bestWidth = data[offset:offset+size], width
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no image in ico")
+	}
+	if bytes.HasPrefix(best, pngSignature) {
+		return png.Decode(bytes.NewReader(best))
+	}
+	return decodeICOBitmap(best)
+}
+
+// decodeICOBitmap decodes a 32-bit BGRA bitmap of an ICO file, stored bottom
+// up with twice its height to make room for the (ignored) AND mask.
+func decodeICOBitmap(dib []byte) (image.Image, error) {
+	if len(dib) < 40 {
+		return nil, fmt.Errorf("truncated ico bitmap")
+	}
+	headerSize := int(binary.LittleEndian.Uint32(dib[0:4]))
+	width := int(int32(binary.LittleEndian.Uint32(dib[4:8])))
+	height := int(int32(binary.LittleEndian.Uint32(dib[8:12]))) / 2
+	bitCount := binary.LittleEndian.Uint16(dib[14:16])
+	if bitCount != 32 {
+		return nil, fmt.Errorf("unsupported ico bitmap depth %d", bitCount)
+	}
+	if width <= 0 || height <= 0 || width > iconColorMaxSide || height > iconColorMaxSide || headerSize < 40 || headerSize+width*height*4 > len(dib) {
+		return nil, fmt.Errorf("malformed ico bitmap")
+	}
+
+	pixels := dib[headerSize:]
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	hasAlpha := false
+	for y := 0; y < height; y++ {
+		row := pixels[(height-1-y)*width*4:]
+		for x := 0; x < width; x++ {
+			b, g, r, a := row[x*4], row[x*4+1], row[x*4+2], row[x*4+3]
+			hasAlpha = hasAlpha || a != 0
+			img.SetNRGBA(x, y, color.NRGBA{R: r, G: g, B: b, A: a})
+		}
+	}
+	// Bitmaps without an alpha channel leave it all zero
+	if !hasAlpha {
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = 0xff
+		}
+	}
+	return img, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+// icoWithBitmap wraps a 32-bit BGRA bitmap of a single color in an ICO file.
+func icoWithBitmap(size int, c color.NRGBA) []byte {
+	var dib bytes.Buffer
+	header := make([]byte, 40)
+	binary.LittleEndian.PutUint32(header[0:], 40)
+	binary.LittleEndian.PutUint32(header[4:], uint32(size))
+	binary.LittleEndian.PutUint32(header[8:], uint32(2*size))
+	binary.LittleEndian.PutUint16(header[12:], 1)
+	binary.LittleEndian.PutUint16(header[14:], 32)
+	dib.Write(header)
+	for i := 0; i < size*size; i++ {
+		dib.Write([]byte{c.B, c.G, c.R, c.A})
+	}
+	dib.Write(make([]byte, size*size/8)) // AND mask
+
+	ico := []byte{0, 0, 1, 0, 1, 0}
+	entry := make([]byte, 16)
+	entry[0], entry[1] = byte(size), byte(size)
+	binary.LittleEndian.PutUint16(entry[6:], 32)
+	binary.LittleEndian.PutUint32(entry[8:], uint32(dib.Len()))
+	binary.LittleEndian.PutUint32(entry[12:], 22)
+	return append(append(ico, entry...), dib.Bytes()...)
+}
+
+func TestDominantColor(t *testing.T) {
+	// A blue mark on a mostly transparent, partly white icon
+	mark := image.NewNRGBA(image.Rect(0, 0, 32, 32))
+	for y := 0; y < 32; y++ {
+		for x := 0; x < 32; x++ {
+			switch {
+			case x < 8:
+				mark.SetNRGBA(x, y, color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff})
+			case x < 12:
+				mark.SetNRGBA(x, y, color.NRGBA{R: 0x20, G: 0x40, B: 0xd0, A: 0xff})
+			}
+		}
+	}
+	var markPNG bytes.Buffer
+	if err := png.Encode(&markPNG, mark); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"color over white", markPNG.Bytes(), "#2040d0"},
+		{"plain white", encodePNG(t, 16, 16, color.White), "#ffffff"},
+		{"ico bitmap", icoWithBitmap(16, color.NRGBA{R: 0x10, G: 0x90, B: 0x30, A: 0xff}), "#109030"},
+		{"ico bitmap without alpha", icoWithBitmap(16, color.NRGBA{R: 0x10, G: 0x90, B: 0x30}), "#109030"},
+	}
+	for _, tc := range cases {
+		if got, ok := dominantColor(tc.data); !ok || got != tc.want {
+			t.Errorf("%s: got %q, %v, want %q", tc.name, got, ok, tc.want)
+		}
+	}
+
+	for name, data := range map[string][]byte{
+		"transparent": encodePNG(t, 16, 16, color.Transparent),
+		"truncated":   icoWithBitmap(16, color.NRGBA{A: 0xff})[:30],
+		"html":        []byte("<!doctype html><html></html>"),
+	} {
+		if got, ok := dominantColor(data); ok {
+			t.Errorf("%s: expected no color, got %q", name, got)
+		}
+	}
+}
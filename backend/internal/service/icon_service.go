@@ -10,10 +10,13 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/mmcdole/gofeed"
+	"golang.org/x/sync/errgroup"
 
 	"gist/backend/internal/config"
 	"gist/backend/internal/model"
@@ -23,6 +26,10 @@ import (
 
 const iconTimeout = 15 * time.Second
 
+// maxConcurrentIconFetches limits parallel icon fetches in a backfill; each
+// site is still visited one feed at a time.
+const maxConcurrentIconFetches = 4
+
 type IconService interface {
 	// FetchAndSaveIcon downloads and saves the icon locally
 	// Returns relative path like "example.com.png" based on domain
@@ -31,23 +38,42 @@ type IconService interface {
 	EnsureIcon(ctx context.Context, iconPath, siteURL string) error
 	// EnsureIconByFeedID checks if icon exists, fetches feed's siteURL and re-downloads if missing
 	EnsureIconByFeedID(ctx context.Context, feedID int64, iconPath string) error
-	// BackfillIcons fetches icons for all feeds that don't have one
-	BackfillIcons(ctx context.Context) error
+	// BackfillIcons fetches icons for the feeds without one and restores
+	// missing or stale icon files, a few feeds at a time, reporting how many
+	// of the feeds to check are done.
+	BackfillIcons(ctx context.Context, progress func(done, total int)) (IconBackfillResult, error)
 	// GetIconPath returns the full path for an icon file
 	GetIconPath(filename string) string
+	// SetFeedIcon makes a saved icon file the feed's icon, along with its
+	// dominant color
+	SetFeedIcon(ctx context.Context, feedID int64, iconPath string) error
 }
 
+// Icon sources, tried in the order set in GeneralSettings.IconSources
+const (
+	IconSourceFeed       = "feed"       // the image the feed declares
+	IconSourceSite       = "site"       // the site's own /favicon.ico
+	IconSourceGoogle     = "google"     // Google's S2 favicon service
+	IconSourceDuckDuckGo = "duckduckgo" // DuckDuckGo's favicon service
+)
+
+// DefaultIconSources is the order used when none is set.
+var DefaultIconSources = []string{IconSourceFeed, IconSourceSite, IconSourceGoogle, IconSourceDuckDuckGo}
+
 type iconService struct {
-	dataDir    string
+	dir        string
 	feeds      repository.FeedRepository
+	settings   repository.SettingsRepository
 	httpClient *http.Client
 	anubis     *anubis.Solver
 }
 
-func NewIconService(dataDir string, feeds repository.FeedRepository, anubisSolver *anubis.Solver) IconService {
+// NewIconService creates an icon service keeping icon files in dir.
+func NewIconService(dir string, feeds repository.FeedRepository, settings repository.SettingsRepository, anubisSolver *anubis.Solver) IconService {
 	return &iconService{
-		dataDir: dataDir,
-		feeds:   feeds,
+		dir:      dir,
+		feeds:    feeds,
+		settings: settings,
 		httpClient: &http.Client{
 			Timeout: iconTimeout,
 		},
@@ -58,70 +84,40 @@ func NewIconService(dataDir string, feeds repository.FeedRepository, anubisSolve
 func (s *iconService) FetchAndSaveIcon(ctx context.Context, feedImageURL, siteURL string) (string, error) {
 	feedImageURL = strings.TrimSpace(feedImageURL)
 
-	// Determine icon filename:
-	// - If feed has its own image (e.g., user avatar), use URL hash for unique filename
-	// - Otherwise, use domain-based filename (shared favicon)
-	var iconPath string
-	var iconURL string
-
-	if feedImageURL != "" {
-		// Feed has its own image, use hash-based filename
-		hash := sha256.Sum256([]byte(feedImageURL))
-		iconPath = hex.EncodeToString(hash[:8]) + ".png" // Use first 8 bytes (16 chars)
-		iconURL = feedImageURL
-	} else {
-		// Use domain-based filename for shared favicon
-		iconPath = iconFilename(siteURL)
-		if iconPath == "" {
-			return "", nil
-		}
-		iconURL = s.buildFaviconURL(siteURL)
-		if iconURL == "" {
-			return "", nil
-		}
-	}
-
-	fullPath := filepath.Join(s.dataDir, "icons", iconPath)
-
-	// Check if icon already exists
-	if _, err := os.Stat(fullPath); err == nil {
-		return iconPath, nil
-	}
-
-	// Download icon with fallback:
-	// 1. Feed's own image URL (if provided)
-	// 2. Google Favicon API (which already tries /favicon.ico internally)
-	iconData, err := s.downloadIcon(ctx, iconURL)
-	if err != nil {
-		// Try Google Favicon API as fallback
-		googleURL := s.buildFaviconURL(siteURL)
-		if googleURL != "" && googleURL != iconURL {
-			iconData, err = s.downloadIcon(ctx, googleURL)
-			if err == nil {
-				// Switch to domain-based filename since we're using favicon
-				iconPath = iconFilename(siteURL)
-				if iconPath == "" {
-					return "", nil
-				}
-				fullPath = filepath.Join(s.dataDir, "icons", iconPath)
-			} else {
-				return "", nil // All attempts failed, icon is optional
+	// Try the sources in the configured order. The feed's own image (e.g. a
+	// user avatar) is saved under a hash of its URL; favicons under the
+	// domain, shared by the feeds of a site.
+	for _, source := range s.iconSources(ctx) {
+		var iconPath, iconURL string
+		if source == IconSourceFeed {
+			if feedImageURL == "" {
+				continue
 			}
+			hash := sha256.Sum256([]byte(feedImageURL))
+			iconPath = hex.EncodeToString(hash[:8]) + ".png" // Use first 8 bytes (16 chars)
+			iconURL = feedImageURL
 		} else {
-			return "", nil // No valid Google Favicon URL available
+			iconPath = iconFilename(siteURL)
+			iconURL = faviconURL(source, siteURL)
+			if iconPath == "" || iconURL == "" {
+				continue
+			}
 		}
-	}
-
-	// Save to file
-	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
-		return "", fmt.Errorf("create icons dir: %w", err)
-	}
 
-	if err := os.WriteFile(fullPath, iconData, 0644); err != nil {
-		return "", fmt.Errorf("write icon file: %w", err)
+		fullPath := filepath.Join(s.dir, iconPath)
+		if _, err := os.Stat(fullPath); err == nil {
+			return iconPath, nil
+		}
+		iconData, err := s.downloadIcon(ctx, iconURL)
+		if err != nil {
+			continue
+		}
+		if err := writeIcon(fullPath, iconData); err != nil {
+			return "", err
+		}
+		return iconPath, nil
 	}
-
-	return iconPath, nil
+	return "", nil // All attempts failed, icon is optional
 }
 
 func (s *iconService) EnsureIcon(ctx context.Context, iconPath, siteURL string) error {
@@ -131,7 +127,7 @@ func (s *iconService) EnsureIcon(ctx context.Context, iconPath, siteURL string)
 
 	// Clean to prevent path traversal
 	iconPath = filepath.Clean(iconPath)
-	fullPath := filepath.Join(s.dataDir, "icons", iconPath)
+	fullPath := filepath.Join(s.dir, iconPath)
 
 	// Check if file exists
 	if _, err := os.Stat(fullPath); err == nil {
@@ -144,25 +140,29 @@ func (s *iconService) EnsureIcon(ctx context.Context, iconPath, siteURL string)
 		return nil // Cannot recover, skip
 	}
 
-	// File missing, re-download using Google Favicon API
-	iconURL := s.buildFaviconURL(siteURL)
-	if iconURL == "" {
-		return nil
-	}
-
-	iconData, err := s.downloadIcon(ctx, iconURL)
-	if err != nil {
-		return nil // Silently fail
+	// File missing, re-download from the favicon sources in order
+	for _, source := range s.iconSources(ctx) {
+		iconURL := faviconURL(source, siteURL)
+		if iconURL == "" {
+			continue
+		}
+		iconData, err := s.downloadIcon(ctx, iconURL)
+		if err != nil {
+			continue
+		}
+		return writeIcon(fullPath, iconData)
 	}
+	return nil // Silently fail
+}
 
+// writeIcon saves a downloaded icon, creating the icons directory if needed.
+func writeIcon(fullPath string, data []byte) error {
 	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
 		return fmt.Errorf("create icons dir: %w", err)
 	}
-
-	if err := os.WriteFile(fullPath, iconData, 0644); err != nil {
+	if err := os.WriteFile(fullPath, data, 0644); err != nil {
 		return fmt.Errorf("write icon file: %w", err)
 	}
-
 	return nil
 }
 
@@ -199,98 +199,244 @@ func (s *iconService) EnsureIconByFeedID(ctx context.Context, feedID int64, icon
 		siteURL = *feed.SiteURL
 	}
 
-	return s.EnsureIcon(ctx, iconPath, siteURL)
+	return s.EnsureIcon(withRequestOverrides(ctx, feed.RequestOverrides), iconPath, siteURL)
 }
 
 func (s *iconService) GetIconPath(filename string) string {
 	// Clean to prevent path traversal
-	return filepath.Join(s.dataDir, "icons", filepath.Clean(filename))
+	return filepath.Join(s.dir, filepath.Clean(filename))
 }
 
-func (s *iconService) BackfillIcons(ctx context.Context) error {
-	parser := gofeed.NewParser()
+func (s *iconService) SetFeedIcon(ctx context.Context, feedID int64, iconPath string) error {
+	return s.feeds.UpdateIcon(ctx, feedID, iconPath, s.iconColor(iconPath))
+}
 
-	// 1. Fetch icons for feeds without icon_path in DB
-	feeds, err := s.feeds.ListWithoutIcon(ctx)
+// iconColor returns the dominant color of a saved icon, or nil if it cannot
+// be told.
+func (s *iconService) iconColor(iconPath string) *string {
+	if iconPath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.Clean(iconPath)))
 	if err != nil {
-		return fmt.Errorf("list feeds without icon: %w", err)
+		return nil
 	}
-	s.fetchIconsForFeeds(ctx, parser, feeds)
+	color, ok := dominantColor(data)
+	if !ok {
+		return nil
+	}
+	return &color
+}
 
-	// 2. Re-download missing or stale icon files
-	allFeeds, err := s.feeds.List(ctx, nil)
+// IconBackfillResult summarizes an icon backfill.
+type IconBackfillResult struct {
+	// Checked is how many feeds needed an icon fetched or its file restored.
+	Checked int `json:"checked"`
+	// Fetched is how many of them have an icon file now.
+	Fetched int `json:"fetched"`
+	// Missing lists the feeds whose icon could not be found.
+	Missing []MissingIcon `json:"missing"`
+}
+
+// MissingIcon is a feed left without an icon by a backfill.
+type MissingIcon struct {
+	FeedID int64  `json:"feedId,string"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+// iconJob is a feed whose icon the backfill fetches or restores.
+type iconJob struct {
+	feed    model.Feed
+	siteURL string
+	// parse re-reads the feed for its own image; otherwise only a missing
+	// domain icon file is downloaded again
+	parse bool
+}
+
+func (s *iconService) BackfillIcons(ctx context.Context, progress func(done, total int)) (IconBackfillResult, error) {
+	result := IconBackfillResult{Missing: []MissingIcon{}}
+	jobs, err := s.iconJobs(ctx)
 	if err != nil {
-		return fmt.Errorf("list all feeds: %w", err)
+		return result, err
+	}
+	result.Checked = len(jobs)
+	if progress != nil {
+		progress(0, len(jobs))
+	}
+
+	var mu sync.Mutex
+	done := 0
+	hl := newHostLimiter()
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentIconFetches)
+	for _, job := range jobs {
+		job := job
+		g.Go(func() error {
+			// Feeds of one site share its favicon file, so they go one at a time
+			if host := extractHost(job.siteURL); host != "" {
+				if err := hl.acquire(gctx, host); err != nil {
+					return nil // context cancelled
+				}
+				defer hl.release(host)
+			}
+			found := s.backfillIcon(gctx, job)
+
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if found {
+				result.Fetched++
+			} else if gctx.Err() == nil {
+				result.Missing = append(result.Missing, MissingIcon{FeedID: job.feed.ID, Title: job.feed.Title, URL: job.feed.URL})
+			}
+			if progress != nil {
+				progress(done, len(jobs))
+			}
+			return nil
+		})
 	}
+	if err := g.Wait(); err != nil {
+		return result, err
+	}
+	sort.Slice(result.Missing, func(i, j int) bool { return result.Missing[i].Title < result.Missing[j].Title })
+	return result, ctx.Err()
+}
 
+// iconJobs lists the feeds without an icon, and those whose icon file is
+// missing or more than iconMaxAge old.
+func (s *iconService) iconJobs(ctx context.Context) ([]iconJob, error) {
 	const iconMaxAge = 30 * 24 * time.Hour // 30 days
-	now := time.Now()
 
-	var feedsNeedRefetch []int64
-	for _, feed := range allFeeds {
+	feeds, err := s.feeds.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list all feeds: %w", err)
+	}
+	now := time.Now()
+	var jobs []iconJob
+	for _, feed := range feeds {
+		if feed.IsVirtual() {
+			continue
+		}
+		job := iconJob{feed: feed, siteURL: feed.URL, parse: true}
+		if feed.SiteURL != nil && *feed.SiteURL != "" {
+			job.siteURL = *feed.SiteURL
+		}
 		if feed.IconPath == nil || *feed.IconPath == "" {
+			jobs = append(jobs, job)
 			continue
 		}
 
 		// Clean to prevent path traversal
 		cleanPath := filepath.Clean(*feed.IconPath)
-		fullPath := filepath.Join(s.dataDir, "icons", cleanPath)
+		fullPath := filepath.Join(s.dir, cleanPath)
 		info, statErr := os.Stat(fullPath)
 		needRefresh := statErr != nil || now.Sub(info.ModTime()) > iconMaxAge
 		if !needRefresh {
+			// Icons saved before their colors were kept get one without a download
+			if statErr == nil && feed.IconColor == nil {
+				if err := s.SetFeedIcon(ctx, feed.ID, *feed.IconPath); err != nil {
+					return nil, fmt.Errorf("set icon color: %w", err)
+				}
+			}
 			continue
 		}
+		// Hash-based icons need re-fetch via RSS parsing; domain-based icons
+		// can be re-downloaded directly
+		job.parse = isHashFilename(*feed.IconPath)
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
 
-		// Hash-based icons need re-fetch via RSS parsing
-		if isHashFilename(*feed.IconPath) {
-			feedsNeedRefetch = append(feedsNeedRefetch, feed.ID)
-			continue
+// backfillIcon fetches or restores the icon of a feed and reports whether it
+// has an icon file afterwards.
+func (s *iconService) backfillIcon(ctx context.Context, job iconJob) bool {
+	ctx = withRequestOverrides(ctx, job.feed.RequestOverrides)
+	if !job.parse {
+		_ = s.EnsureIcon(ctx, *job.feed.IconPath, job.siteURL)
+		if _, err := os.Stat(filepath.Join(s.dir, filepath.Clean(*job.feed.IconPath))); err != nil {
+			return false
 		}
+		// The file may have been downloaded again, so its color is too
+		return s.SetFeedIcon(ctx, job.feed.ID, *job.feed.IconPath) == nil
+	}
 
-		// Domain-based icons can be re-downloaded directly
-		siteURL := feed.URL
-		if feed.SiteURL != nil && *feed.SiteURL != "" {
-			siteURL = *feed.SiteURL
-		}
-		_ = s.EnsureIcon(ctx, *feed.IconPath, siteURL)
+	// A stale feed image is fetched again from the URL the feed gives now
+	if job.feed.IconPath != nil && *job.feed.IconPath != "" {
+		_ = s.feeds.UpdateIcon(ctx, job.feed.ID, "", nil)
 	}
 
-	// 3. Re-fetch hash-based icons by clearing DB and re-parsing RSS
-	if len(feedsNeedRefetch) > 0 {
-		for _, feedID := range feedsNeedRefetch {
-			_ = s.feeds.UpdateIconPath(ctx, feedID, "")
-		}
-		if feedsToRefetch, err := s.feeds.ListWithoutIcon(ctx); err == nil {
-			s.fetchIconsForFeeds(ctx, parser, feedsToRefetch)
-		}
+	// Try to parse feed to get imageURL from RSS
+	imageURL := ""
+	if parsed, err := gofeed.NewParser().ParseURLWithContext(job.feed.URL, ctx); err == nil && parsed.Image != nil {
+		imageURL = strings.TrimSpace(parsed.Image.URL)
 	}
 
-	return nil
+	iconPath, err := s.FetchAndSaveIcon(ctx, imageURL, job.siteURL)
+	if err != nil || iconPath == "" {
+		return false
+	}
+	return s.SetFeedIcon(ctx, job.feed.ID, iconPath) == nil
 }
 
-// fetchIconsForFeeds parses RSS feeds to get imageURL and fetches icons
-func (s *iconService) fetchIconsForFeeds(ctx context.Context, parser *gofeed.Parser, feeds []model.Feed) {
-	for _, feed := range feeds {
-		siteURL := feed.URL
-		if feed.SiteURL != nil && *feed.SiteURL != "" {
-			siteURL = *feed.SiteURL
-		}
+// BackfillIconsTask runs BackfillIcons as a task.
+func BackfillIconsTask(icons IconService) TaskFunc {
+	return func(ctx context.Context, h TaskHandle) (interface{}, error) {
+		return icons.BackfillIcons(ctx, func(done, total int) {
+			h.Progress(done, total, "")
+		})
+	}
+}
 
-		// Try to parse feed to get imageURL from RSS
-		imageURL := ""
-		if parsed, err := parser.ParseURLWithContext(feed.URL, ctx); err == nil && parsed.Image != nil {
-			imageURL = strings.TrimSpace(parsed.Image.URL)
-		}
+// iconSources returns the configured source order, or the default one.
+func (s *iconService) iconSources(ctx context.Context) []string {
+	if s.settings == nil {
+		return DefaultIconSources
+	}
+	setting, err := s.settings.Get(ctx, keyIconSources)
+	if err != nil || setting == nil {
+		return DefaultIconSources
+	}
+	sources, err := ParseIconSources(setting.Value)
+	if err != nil {
+		return DefaultIconSources
+	}
+	return sources
+}
 
-		iconPath, err := s.FetchAndSaveIcon(ctx, imageURL, siteURL)
-		if err != nil || iconPath == "" {
+// ParseIconSources parses a comma- or line-separated list of icon sources in
+// the order to try them. An empty list means DefaultIconSources; leaving out
+// IconSourceGoogle and IconSourceDuckDuckGo keeps site domains from being
+// sent to third parties.
+func ParseIconSources(raw string) ([]string, error) {
+	var sources []string
+	seen := make(map[string]bool)
+	for _, field := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '，' || r == '\n' }) {
+		source := strings.ToLower(strings.TrimSpace(field))
+		if source == "" {
 			continue
 		}
-		_ = s.feeds.UpdateIconPath(ctx, feed.ID, iconPath)
+		switch source {
+		case IconSourceFeed, IconSourceSite, IconSourceGoogle, IconSourceDuckDuckGo:
+		default:
+			return nil, fmt.Errorf("%w: unknown icon source %q", ErrInvalid, source)
+		}
+		if seen[source] {
+			return nil, fmt.Errorf("%w: icon source %q listed twice", ErrInvalid, source)
+		}
+		seen[source] = true
+		sources = append(sources, source)
+	}
+	if len(sources) == 0 {
+		return DefaultIconSources, nil
 	}
+	return sources, nil
 }
 
-func (s *iconService) buildFaviconURL(siteURL string) string {
+// faviconURL returns where source serves the favicon of siteURL's domain, or
+// "" if it has none.
+func faviconURL(source, siteURL string) string {
 	if siteURL == "" {
 		return ""
 	}
@@ -305,7 +451,19 @@ func (s *iconService) buildFaviconURL(siteURL string) string {
 		return ""
 	}
 
-	return fmt.Sprintf("https://www.google.com/s2/favicons?domain=%s&sz=128", url.QueryEscape(domain))
+	switch source {
+	case IconSourceSite:
+		scheme := parsed.Scheme
+		if scheme != "http" {
+			scheme = "https"
+		}
+		return (&url.URL{Scheme: scheme, Host: parsed.Host, Path: "/favicon.ico"}).String()
+	case IconSourceGoogle:
+		return fmt.Sprintf("https://www.google.com/s2/favicons?domain=%s&sz=128", url.QueryEscape(domain))
+	case IconSourceDuckDuckGo:
+		return fmt.Sprintf("https://icons.duckduckgo.com/ip3/%s.ico", url.PathEscape(domain))
+	}
+	return ""
 }
 
 // iconFilename generates a filename based on the domain
@@ -344,6 +502,7 @@ func (s *iconService) downloadIconWithRetry(ctx context.Context, iconURL string,
 			}
 		}
 	}
+	applyRequestOverrides(req, requestOverridesFrom(ctx))
 
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
@@ -378,6 +537,10 @@ func (s *iconService) downloadIconWithRetry(ctx context.Context, iconURL string,
 	if len(data) < 100 {
 		return nil, fmt.Errorf("icon too small")
 	}
+	// Sites without a favicon may answer with an HTML page
+	if strings.HasPrefix(http.DetectContentType(data), "text/html") {
+		return nil, fmt.Errorf("icon is an HTML page")
+	}
 
 	return data, nil
 }
@@ -392,6 +555,7 @@ func (s *iconService) downloadIconWithFreshClient(ctx context.Context, iconURL s
 	if cookie != "" {
 		req.Header.Set("Cookie", cookie)
 	}
+	applyRequestOverrides(req, requestOverridesFrom(ctx))
 
 	// Use fresh client to avoid connection reuse
 	freshClient := &http.Client{Timeout: iconTimeout}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+)
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// redIcon is a red square PNG with a white border, big enough not to be
+// taken for a broken image.
+var redIcon = func() []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			c := color.NRGBA{R: 0xe0, G: 0x10, B: 0x10, A: 0xff}
+			if x < 2 || y < 2 || x > 13 || y > 13 {
+				c = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return append(buf.Bytes(), make([]byte, 200)...)
+}()
+
+func TestIconService_BackfillIcons(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/found.xml", "/lost.xml":
+			image := "/icon.png"
+			if r.URL.Path == "/lost.xml" {
+				image = "/gone.png"
+			}
+			w.Header().Set("Content-Type", "application/rss+xml")
+			fmt.Fprintf(w, `<rss version="2.0"><channel><title>T</title><link>http://%s/</link><image><url>http://%s%s</url></image></channel></rss>`, r.Host, r.Host, image)
+		case "/icon.png":
+			w.Write(redIcon)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	icons := NewIconService(dir, mockFeeds, nil, nil).(*iconService)
+	// The favicon services find nothing either
+	icons.httpClient = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "www.google.com" || req.URL.Host == "icons.duckduckgo.com" {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	kept := "kept.example.com.png"
+	if err := os.WriteFile(filepath.Join(dir, kept), []byte("icon"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mockFeeds.EXPECT().List(gomock.Any(), nil).Return([]model.Feed{
+		{ID: 1, Title: "Found", URL: server.URL + "/found.xml"},
+		{ID: 2, Title: "Lost", URL: server.URL + "/lost.xml"},
+		{ID: 3, Title: "Kept", URL: "https://kept.example.com/feed", IconPath: &kept},
+		{ID: 4, Title: "Saved", URL: model.SavedPagesURL},
+	}, nil)
+	// The kept icon is not fetched again, only given its color
+	mockFeeds.EXPECT().UpdateIcon(gomock.Any(), int64(3), kept, gomock.Any()).Return(nil)
+	mockFeeds.EXPECT().UpdateIcon(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, _ string, color *string) error {
+			if color == nil || *color != "#e01010" {
+				t.Errorf("expected the icon's red, got %v", color)
+			}
+			return nil
+		})
+
+	var mu sync.Mutex
+	var reports [][2]int
+	result, err := icons.BackfillIcons(context.Background(), func(done, total int) {
+		mu.Lock()
+		reports = append(reports, [2]int{done, total})
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("backfill: %v", err)
+	}
+	if result.Checked != 2 || result.Fetched != 1 || len(result.Missing) != 1 || result.Missing[0].FeedID != 2 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if len(reports) != 3 || reports[0] != [2]int{0, 2} || reports[2] != [2]int{2, 2} {
+		t.Errorf("unexpected progress %v", reports)
+	}
+}
+
+func TestParseIconSources(t *testing.T) {
+	sources, err := ParseIconSources(" Site, feed\ngoogle ")
+	if err != nil || len(sources) != 3 || sources[0] != IconSourceSite || sources[1] != IconSourceFeed || sources[2] != IconSourceGoogle {
+		t.Errorf("unexpected sources %v, %v", sources, err)
+	}
+	if sources, err := ParseIconSources(""); err != nil || len(sources) != len(DefaultIconSources) {
+		t.Errorf("expected the default order, got %v, %v", sources, err)
+	}
+	for _, raw := range []string{"feed,bing", "site,site"} {
+		if _, err := ParseIconSources(raw); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: expected ErrInvalid, got %v", raw, err)
+		}
+	}
+}
+
+func TestIconService_FetchAndSaveIcon_SourceOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/favicon.ico":
+			w.Write(append([]byte("\x00\x00\x01\x00"), make([]byte, 200)...))
+		case "/spa/favicon.ico":
+			fmt.Fprint(w, "<!doctype html><html><body>"+string(make([]byte, 200))+"</body></html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	mockSettings := testutil.NewMockSettingsRepository(ctrl)
+	icons := NewIconService(t.TempDir(), nil, mockSettings, nil).(*iconService)
+	var mu sync.Mutex
+	var thirdParty []string
+	icons.httpClient = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "www.google.com" || req.URL.Host == "icons.duckduckgo.com" {
+			mu.Lock()
+			thirdParty = append(thirdParty, req.URL.Host)
+			mu.Unlock()
+			return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+		}
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	ctx := context.Background()
+
+	// Google comes first but has nothing, so the site's favicon is used
+	mockSettings.EXPECT().Get(ctx, keyIconSources).Return(&model.Setting{Key: keyIconSources, Value: "google,site"}, nil)
+	iconPath, err := icons.FetchAndSaveIcon(ctx, server.URL+"/missing.png", server.URL)
+	if err != nil || iconPath != iconFilename(server.URL) {
+		t.Errorf("expected the site favicon, got %q, %v", iconPath, err)
+	}
+	if len(thirdParty) != 1 || thirdParty[0] != "www.google.com" {
+		t.Errorf("expected only Google asked, got %v", thirdParty)
+	}
+
+	// Without third-party sources a missing feed image leaves no icon
+	thirdParty = nil
+	mockSettings.EXPECT().Get(ctx, keyIconSources).Return(&model.Setting{Key: keyIconSources, Value: "feed"}, nil)
+	if iconPath, err := icons.FetchAndSaveIcon(ctx, server.URL+"/missing.png", "https://other.example.com/"); err != nil || iconPath != "" {
+		t.Errorf("expected no icon, got %q, %v", iconPath, err)
+	}
+	if len(thirdParty) != 0 {
+		t.Errorf("expected no third-party requests, got %v", thirdParty)
+	}
+
+	// An HTML page served as the favicon is no icon
+	if _, err := icons.downloadIcon(ctx, server.URL+"/spa/favicon.ico"); err == nil {
+		t.Error("expected an HTML favicon to be rejected")
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"container/list"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// imageCache keeps proxied images on disk, named like thumbnails by the
+// SHA-256 of their URL. It holds at most maxBytes and drops the least
+// recently used images first. A file's modification time records its last
+// use, so the order survives restarts.
+type imageCache struct {
+	dir      string
+	maxBytes int64
+	now      func() time.Time
+
+	mu     sync.Mutex
+	loaded bool
+	size   int64
+	order  *list.List // of *cachedImage, most recently used first
+	files  map[string]*list.Element
+}
+
+type cachedImage struct {
+	key  string
+	name string
+	size int64
+}
+
+// newImageCache creates a cache of at most maxBytes in dir. A cache without
+// room keeps nothing.
+func newImageCache(dir string, maxBytes int64) *imageCache {
+	return &imageCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		now:      time.Now,
+		order:    list.New(),
+		files:    make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached image of imageURL and marks it used.
+func (c *imageCache) get(imageURL string) (*ProxyResult, bool) {
+	if c.maxBytes <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	el, ok := c.files[thumbnailKey(imageURL)]
+	if !ok {
+		return nil, false
+	}
+	image := el.Value.(*cachedImage)
+	path := filepath.Join(c.dir, image.name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		// Removed behind our back, as by a wipe
+		c.remove(el)
+		return nil, false
+	}
+	now := c.now()
+	_ = os.Chtimes(path, now, now)
+	c.order.MoveToFront(el)
+
+	contentType := mime.TypeByExtension(filepath.Ext(image.name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return &ProxyResult{Data: data, ContentType: contentType}, true
+}
+
+// put caches the image fetched from imageURL. Responses that are not images
+// of a known type, or larger than the whole cache, are not kept.
+func (c *imageCache) put(imageURL string, result *ProxyResult) {
+	size := int64(len(result.Data))
+	if c.maxBytes <= 0 || size > c.maxBytes {
+		return
+	}
+	mediaType, _, _ := mime.ParseMediaType(result.ContentType)
+	ext := thumbnailExtensions[mediaType]
+	if ext == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	key := thumbnailKey(imageURL)
+	if el, ok := c.files[key]; ok {
+		c.remove(el)
+	}
+	if err := c.write(key+ext, result.Data); err != nil {
+		log.Printf("cache image %s: %v", imageURL, err)
+		return
+	}
+	c.files[key] = c.order.PushFront(&cachedImage{key: key, name: key + ext, size: size})
+	c.size += size
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *imageCache) write(name string, data []byte) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	// Write then rename so readers never see a partial file
+	tmp, err := os.CreateTemp(c.dir, ".image-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	path := filepath.Join(c.dir, name)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	now := c.now()
+	return os.Chtimes(path, now, now)
+}
+
+// load indexes the images cached by earlier runs, once, ordered by their
+// last use. It evicts right away when the limit was lowered since.
+func (c *imageCache) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+
+	type found struct {
+		image *cachedImage
+		used  time.Time
+	}
+	var images []found
+	for _, entry := range dirEntries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		key := strings.TrimSuffix(name, filepath.Ext(name))
+		images = append(images, found{&cachedImage{key: key, name: name, size: info.Size()}, info.ModTime()})
+	}
+	sort.Slice(images, func(i, j int) bool { return images[i].used.After(images[j].used) })
+	for _, f := range images {
+		if _, ok := c.files[f.image.key]; ok {
+			continue
+		}
+		c.files[f.image.key] = c.order.PushBack(f.image)
+		c.size += f.image.size
+	}
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove drops an image from the index and the disk.
+func (c *imageCache) remove(el *list.Element) {
+	image := c.order.Remove(el).(*cachedImage)
+	delete(c.files, image.key)
+	c.size -= image.size
+	if err := os.Remove(filepath.Join(c.dir, image.name)); err != nil && !os.IsNotExist(err) {
+		log.Printf("evict cached image %s: %v", image.name, err)
+	}
+}
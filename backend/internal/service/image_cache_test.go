@@ -0,0 +1,57 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestImageCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := newImageCache(dir, 10)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	png := func(data string) *ProxyResult { return &ProxyResult{Data: []byte(data), ContentType: "image/png"} }
+
+	cache.put("https://a.example/a.png", png("aaaa"))
+	cache.put("https://a.example/b.png", png("bbbb"))
+	now = now.Add(time.Minute)
+	if got, ok := cache.get("https://a.example/a.png"); !ok || string(got.Data) != "aaaa" || got.ContentType != "image/png" {
+		t.Fatalf("expected a to be cached, got %+v, %v", got, ok)
+	}
+	// b is now the least recently used and makes room for c
+	cache.put("https://a.example/c.png", png("cccc"))
+	if _, ok := cache.get("https://a.example/b.png"); ok {
+		t.Error("expected b to be evicted")
+	}
+	cache.put("https://a.example/page", &ProxyResult{Data: []byte("<html>"), ContentType: "text/html"})
+	if _, ok := cache.get("https://a.example/page"); ok {
+		t.Error("expected a response that is not an image not to be kept")
+	}
+	cache.put("https://a.example/huge.png", png("more than ten bytes"))
+	if _, ok := cache.get("https://a.example/huge.png"); ok {
+		t.Error("expected an image larger than the cache not to be kept")
+	}
+
+	// A new run finds the images on disk, a used last, and evicts down to a
+	// lowered limit from the least recently used
+	now = now.Add(time.Minute)
+	cache.get("https://a.example/a.png")
+	reloaded := newImageCache(dir, 4)
+	if reloaded.get("https://a.example/c.png"); reloaded.size != 4 {
+		t.Errorf("expected 4 bytes after reload, got %d", reloaded.size)
+	}
+	if _, ok := reloaded.get("https://a.example/a.png"); !ok {
+		t.Error("expected the most recently used image to survive the reload")
+	}
+	if _, ok := reloaded.get("https://a.example/c.png"); ok {
+		t.Error("expected the least recently used image to be evicted on reload")
+	}
+}
+
+func TestImageCache_Disabled(t *testing.T) {
+	cache := newImageCache(t.TempDir(), 0)
+	cache.put("https://a.example/a.png", &ProxyResult{Data: []byte("a"), ContentType: "image/png"})
+	if _, ok := cache.get("https://a.example/a.png"); ok {
+		t.Error("expected a cache without room to keep nothing")
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/uuid"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/opml"
+	"gist/backend/internal/repository"
+)
+
+// ImportTaskService runs OPML imports as tasks whose progress is saved after
+// every feed, so they can be looked up and cancelled by ID after the task
+// runner has moved on, and an import the server stopped in the middle of
+// resumes from its last feed on the next start.
+type ImportTaskService interface {
+	// Start imports doc in the background. Returns ErrTaskRunning while
+	// another import runs.
+	Start(ctx context.Context, doc opml.Document) (Task, error)
+	// Get returns an import task, live while it runs and as saved after.
+	// Returns ErrNotFound for an unknown ID.
+	Get(ctx context.Context, id string) (Task, error)
+	// Cancel stops a running import; what it created so far stays and can
+	// be undone. Returns false if the task was not running, and ErrNotFound
+	// for an unknown ID.
+	Cancel(ctx context.Context, id string) (bool, error)
+	// Resume restarts the import the server last stopped in the middle of.
+	// Older interrupted imports are marked failed, as only one runs at a time.
+	Resume(ctx context.Context) error
+}
+
+type importTaskService struct {
+	opml  OPMLService
+	tasks TaskRunner
+	repo  repository.ImportTaskRepository
+}
+
+func NewImportTaskService(opmlService OPMLService, tasks TaskRunner, repo repository.ImportTaskRepository) ImportTaskService {
+	return &importTaskService{opml: opmlService, tasks: tasks, repo: repo}
+}
+
+func (s *importTaskService) Start(ctx context.Context, doc opml.Document) (Task, error) {
+	document, err := json.Marshal(doc)
+	if err != nil {
+		return Task{}, fmt.Errorf("encode import document: %w", err)
+	}
+	id := uuid.New().String()
+	total := doc.CountFeeds()
+	if err := s.repo.Create(ctx, model.ImportTask{ID: id, Status: TaskRunning, Document: string(document), Total: total}); err != nil {
+		return Task{}, err
+	}
+
+	task, err := s.tasks.StartAs(id, TaskImport, total, s.run(doc, ImportResume{}))
+	if err != nil {
+		if deleteErr := s.repo.Delete(ctx, id); deleteErr != nil {
+			log.Printf("delete unstarted import task %s: %v", id, deleteErr)
+		}
+		return Task{}, err
+	}
+	return task, nil
+}
+
+// run imports doc from resume on, saving each feed's progress. An import
+// cut short by shutdown is left running in the database to be resumed; a
+// cancelled one was marked by Cancel.
+func (s *importTaskService) run(doc opml.Document, resume ImportResume) TaskFunc {
+	return func(ctx context.Context, task TaskHandle) (interface{}, error) {
+		onProgress := func(p ImportProgress) {
+			task.Progress(p.Current, p.Total, p.Feed)
+			if p.Status != "importing" {
+				return
+			}
+			// The feed being imported is not finished yet
+			if err := s.repo.UpdateProgress(ctx, task.ID, p.Current-1, encodeImportResult(p.Result)); err != nil && ctx.Err() == nil {
+				log.Printf("save import %s progress: %v", task.ID, err)
+			}
+		}
+		result, err := s.opml.Import(ctx, task.ID, doc, resume, onProgress)
+		if ctx.Err() != nil {
+			return result, err
+		}
+
+		status, errMsg := TaskDone, ""
+		if err != nil {
+			status, errMsg = TaskError, err.Error()
+		}
+		if _, finishErr := s.repo.Finish(ctx, task.ID, status, doc.CountFeeds(), encodeImportResult(result), errMsg); finishErr != nil {
+			log.Printf("save import %s result: %v", task.ID, finishErr)
+		}
+		return result, err
+	}
+}
+
+func (s *importTaskService) Get(ctx context.Context, id string) (Task, error) {
+	if task, ok := s.tasks.Get(id); ok && task.Kind == TaskImport {
+		return task, nil
+	}
+	saved, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return Task{}, err
+	}
+	if saved == nil {
+		return Task{}, ErrNotFound
+	}
+	return savedImportTask(*saved), nil
+}
+
+func (s *importTaskService) Cancel(ctx context.Context, id string) (bool, error) {
+	saved, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if saved == nil {
+		if task, ok := s.tasks.Get(id); !ok || task.Kind != TaskImport {
+			return false, ErrNotFound
+		}
+		return s.tasks.Cancel(id), nil
+	}
+
+	// Saved first, so the task stopping does not look like a shutdown
+	cancelled, err := s.repo.Finish(ctx, id, TaskCancelled, saved.Processed, saved.Result, "")
+	if err != nil {
+		return false, err
+	}
+	return s.tasks.Cancel(id) || cancelled, nil
+}
+
+func (s *importTaskService) Resume(ctx context.Context) error {
+	running, err := s.repo.ListRunning(ctx)
+	if err != nil {
+		return err
+	}
+	for i, saved := range running {
+		if _, ok := s.tasks.Get(saved.ID); ok {
+			continue
+		}
+		if i < len(running)-1 {
+			s.fail(ctx, saved, "interrupted by a restart")
+			continue
+		}
+
+		var doc opml.Document
+		if err := json.Unmarshal([]byte(saved.Document), &doc); err != nil {
+			s.fail(ctx, saved, "saved document is unreadable")
+			continue
+		}
+		resume := ImportResume{Processed: saved.Processed, Result: decodeImportResult(saved.Result)}
+		if _, err := s.tasks.StartAs(saved.ID, TaskImport, saved.Total, s.run(doc, resume)); err != nil {
+			if errors.Is(err, ErrTaskRunning) {
+				// Left for a later start
+				log.Printf("resume import %s: %v", saved.ID, err)
+				continue
+			}
+			return fmt.Errorf("resume import %s: %w", saved.ID, err)
+		}
+		log.Printf("resuming import %s after %d of %d feeds", saved.ID, saved.Processed, saved.Total)
+	}
+	return nil
+}
+
+func (s *importTaskService) fail(ctx context.Context, saved model.ImportTask, reason string) {
+	if _, err := s.repo.Finish(ctx, saved.ID, TaskError, saved.Processed, saved.Result, reason); err != nil {
+		log.Printf("end import %s: %v", saved.ID, err)
+	}
+}
+
+// savedImportTask describes an import task the runner no longer tracks.
+func savedImportTask(saved model.ImportTask) Task {
+	task := Task{
+		ID:         saved.ID,
+		Kind:       TaskImport,
+		Status:     saved.Status,
+		Total:      saved.Total,
+		Current:    saved.Processed,
+		Error:      saved.Error,
+		CreatedAt:  saved.CreatedAt,
+		FinishedAt: saved.FinishedAt,
+	}
+	if saved.Status == TaskDone {
+		task.Result = decodeImportResult(saved.Result)
+	}
+	return task
+}
+
+func encodeImportResult(result ImportResult) string {
+	data, _ := json.Marshal(result)
+	return string(data)
+}
+
+func decodeImportResult(raw string) ImportResult {
+	var result ImportResult
+	if raw != "" {
+		_ = json.Unmarshal([]byte(raw), &result)
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"go.uber.org/mock/gomock"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/opml"
+	"gist/backend/internal/service/testutil"
+)
+
+// fakeImporter imports by reporting each feed after the resume point.
+type fakeImporter struct {
+	OPMLService
+	resumed []ImportResume
+}
+
+func (f *fakeImporter) Import(_ context.Context, _ string, doc opml.Document, resume ImportResume, onProgress func(ImportProgress)) (ImportResult, error) {
+	f.resumed = append(f.resumed, resume)
+	result := resume.Result
+	total := doc.CountFeeds()
+	for i := resume.Processed + 1; i <= total; i++ {
+		onProgress(ImportProgress{Total: total, Current: i, Status: "importing", Result: result})
+		result.FeedsCreated++
+	}
+	return result, nil
+}
+
+func importDocument(urls ...string) opml.Document {
+	var doc opml.Document
+	for _, u := range urls {
+		doc.Body.Outlines = append(doc.Body.Outlines, opml.Outline{Text: u, XMLURL: u})
+	}
+	return doc
+}
+
+func TestImportTaskService_Resume(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := testutil.NewMockImportTaskRepository(ctrl)
+	importer := &fakeImporter{}
+	runner := NewTaskRunner(nil)
+	service := NewImportTaskService(importer, runner, repo)
+	ctx := context.Background()
+
+	doc := importDocument("https://a.example/feed", "https://b.example/feed", "https://c.example/feed")
+	document, _ := json.Marshal(doc)
+	partial := encodeImportResult(ImportResult{FeedsCreated: 1, FeedsSkipped: 1})
+	repo.EXPECT().ListRunning(ctx).Return([]model.ImportTask{
+		{ID: "stale", Status: TaskRunning, Total: 5, Processed: 1},
+		{ID: "latest", Status: TaskRunning, Document: string(document), Total: 3, Processed: 2, Result: partial},
+	}, nil)
+	repo.EXPECT().Finish(ctx, "stale", TaskError, 1, "", "interrupted by a restart").Return(true, nil)
+	repo.EXPECT().UpdateProgress(gomock.Any(), "latest", 2, partial).Return(nil)
+	repo.EXPECT().Finish(gomock.Any(), "latest", TaskDone, 3, encodeImportResult(ImportResult{FeedsCreated: 2, FeedsSkipped: 1}), "").Return(true, nil)
+
+	if err := service.Resume(ctx); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	done := waitForStatus(t, runner, "latest")
+	if done.Status != TaskDone {
+		t.Fatalf("expected the resumed import to finish, got %+v", done)
+	}
+	if len(importer.resumed) != 1 || importer.resumed[0].Processed != 2 || importer.resumed[0].Result.FeedsSkipped != 1 {
+		t.Errorf("expected the import to resume after 2 feeds, got %+v", importer.resumed)
+	}
+}
+
+func TestImportTaskService_GetAndCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := testutil.NewMockImportTaskRepository(ctrl)
+	service := NewImportTaskService(&fakeImporter{}, NewTaskRunner(nil), repo)
+	ctx := context.Background()
+
+	saved := model.ImportTask{ID: "saved", Status: TaskDone, Total: 2, Processed: 2, Result: encodeImportResult(ImportResult{FeedsCreated: 2})}
+	repo.EXPECT().Get(ctx, "saved").Return(&saved, nil)
+	task, err := service.Get(ctx, "saved")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if result, ok := task.Result.(ImportResult); task.Status != TaskDone || task.Current != 2 || !ok || result.FeedsCreated != 2 {
+		t.Errorf("expected the saved task, got %+v", task)
+	}
+
+	// A task interrupted by a restart and not resumed yet is still marked
+	interrupted := model.ImportTask{ID: "interrupted", Status: TaskRunning, Total: 4, Processed: 3, Result: "{}"}
+	repo.EXPECT().Get(ctx, "interrupted").Return(&interrupted, nil)
+	repo.EXPECT().Finish(ctx, "interrupted", TaskCancelled, 3, "{}", "").Return(true, nil)
+	if cancelled, err := service.Cancel(ctx, "interrupted"); err != nil || !cancelled {
+		t.Errorf("expected the task to be cancelled, got %v, %v", cancelled, err)
+	}
+
+	repo.EXPECT().Get(ctx, "unknown").Return(nil, nil).Times(2)
+	if _, err := service.Get(ctx, "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := service.Cancel(ctx, "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
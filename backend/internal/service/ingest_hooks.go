@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"gist/backend/internal/model"
+)
+
+// IngestHook transforms entries on their way in, to add custom filters,
+// enrichment or routing without changing the refresh code. Hooks see every
+// entry a refresh, a WebSub push, a sitemap or a new subscription stores,
+// after the filter rules and before sanitizing and collapsing duplicates.
+type IngestHook interface {
+	// Name identifies the hook in logs; names are unique.
+	Name() string
+	// Ingest may change any field of the entry but its ID, and set its
+	// FeedID to route it to another existing feed. It returns false to
+	// drop the entry. On an error the hook's changes are discarded and the
+	// entry goes on as it was, so set pointer fields to new values rather
+	// than writing through them. An entry left without a URL is an error.
+	Ingest(ctx context.Context, feed model.Feed, entry *model.Entry) (bool, error)
+}
+
+// NewIngestHook makes an IngestHook of a function.
+func NewIngestHook(name string, ingest func(ctx context.Context, feed model.Feed, entry *model.Entry) (bool, error)) IngestHook {
+	return funcHook{name: name, ingest: ingest}
+}
+
+type funcHook struct {
+	name   string
+	ingest func(ctx context.Context, feed model.Feed, entry *model.Entry) (bool, error)
+}
+
+func (h funcHook) Name() string { return h.name }
+
+func (h funcHook) Ingest(ctx context.Context, feed model.Feed, entry *model.Entry) (bool, error) {
+	return h.ingest(ctx, feed, entry)
+}
+
+// IngestHooks are the hooks every ingested entry passes through, in order.
+// A nil *IngestHooks runs none.
+type IngestHooks struct {
+	hooks []IngestHook
+}
+
+// NewIngestHooks returns the hooks to run, in the given order. It fails if a
+// hook is nil or its name is taken.
+func NewIngestHooks(hooks ...IngestHook) (*IngestHooks, error) {
+	names := make(map[string]bool, len(hooks))
+	for _, hook := range hooks {
+		if hook == nil {
+			return nil, fmt.Errorf("ingest hook is nil")
+		}
+		if names[hook.Name()] {
+			return nil, fmt.Errorf("ingest hook %q is registered twice", hook.Name())
+		}
+		names[hook.Name()] = true
+	}
+	return &IngestHooks{hooks: hooks}, nil
+}
+
+// Names lists the hooks in the order they run.
+func (r *IngestHooks) Names() []string {
+	if r == nil {
+		return nil
+	}
+	names := make([]string, 0, len(r.hooks))
+	for _, hook := range r.hooks {
+		names = append(names, hook.Name())
+	}
+	return names
+}
+
+// run passes an entry through the hooks in order and reports whether it is
+// kept. A hook that fails or panics is logged and skipped.
+func (r *IngestHooks) run(ctx context.Context, feed model.Feed, entry *model.Entry) bool {
+	if r == nil {
+		return true
+	}
+	for _, hook := range r.hooks {
+		changed := *entry
+		keep, err := runIngestHook(ctx, hook, feed, &changed)
+		if err == nil && keep && (changed.URL == nil || *changed.URL == "") {
+			err = fmt.Errorf("entry left without a URL")
+		}
+		if err != nil {
+			log.Printf("feed %d (%s): ingest hook %s: %v", feed.ID, feed.Title, hook.Name(), err)
+			continue
+		}
+		if !keep {
+			return false
+		}
+		changed.ID = entry.ID
+		if changed.FeedID == 0 {
+			changed.FeedID = entry.FeedID
+		}
+		*entry = changed
+	}
+	return true
+}
+
+// runIngestHook runs one hook, turning a panic into an error so a broken
+// hook cannot take a refresh down.
+func runIngestHook(ctx context.Context, hook IngestHook, feed model.Feed, entry *model.Entry) (keep bool, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			keep, err = false, fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return hook.Ingest(ctx, feed, entry)
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"gist/backend/internal/model"
+)
+
+func TestIngestHooks(t *testing.T) {
+	ctx := context.Background()
+	feed := model.Feed{ID: 1, Title: "Blog"}
+
+	upper := NewIngestHook("upper", func(_ context.Context, _ model.Feed, entry *model.Entry) (bool, error) {
+		title := strings.ToUpper(*entry.Title)
+		entry.Title = &title
+		return true, nil
+	})
+	failing := NewIngestHook("failing", func(_ context.Context, _ model.Feed, entry *model.Entry) (bool, error) {
+		entry.Title = nil
+		return true, errors.New("enrichment service down")
+	})
+	panicking := NewIngestHook("panicking", func(context.Context, model.Feed, *model.Entry) (bool, error) {
+		panic("boom")
+	})
+	noURL := NewIngestHook("no-url", func(_ context.Context, _ model.Feed, entry *model.Entry) (bool, error) {
+		entry.URL = nil
+		return true, nil
+	})
+	route := NewIngestHook("route", func(_ context.Context, _ model.Feed, entry *model.Entry) (bool, error) {
+		entry.ID, entry.FeedID = 99, 2
+		return !strings.Contains(*entry.Title, "SPONSORED"), nil
+	})
+	hooks, err := NewIngestHooks(upper, failing, panicking, noURL, route)
+	if err != nil {
+		t.Fatalf("new ingest hooks: %v", err)
+	}
+	if _, err := NewIngestHooks(upper, NewIngestHook("upper", nil)); err == nil {
+		t.Error("expected a taken name to be refused")
+	}
+	if _, err := NewIngestHooks(upper, nil); err == nil {
+		t.Error("expected a nil hook to be refused")
+	}
+	if names := hooks.Names(); !slices.Equal(names, []string{"upper", "failing", "panicking", "no-url", "route"}) {
+		t.Errorf("unexpected hooks %v", names)
+	}
+
+	// Failing hooks leave the entry as it was and the rest still run
+	title, url := "Hello", "https://example.com/hello"
+	entry := model.Entry{ID: 5, FeedID: 1, Title: &title, URL: &url}
+	if !hooks.run(ctx, feed, &entry) {
+		t.Fatal("expected the entry kept")
+	}
+	if *entry.Title != "HELLO" || entry.URL == nil || entry.ID != 5 || entry.FeedID != 2 {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	sponsored := "Sponsored post"
+	dropped := model.Entry{FeedID: 1, Title: &sponsored, URL: &url}
+	if hooks.run(ctx, feed, &dropped) {
+		t.Error("expected the sponsored entry dropped")
+	}
+}
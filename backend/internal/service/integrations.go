@@ -0,0 +1,391 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gist/backend/internal/repository"
+)
+
+// Read-later services entries can be pushed to
+const (
+	IntegrationWallabag = "wallabag"
+	IntegrationPocket   = "pocket"
+	IntegrationLinkding = "linkding"
+)
+
+// IntegrationProviders are the read-later services entries can be pushed to.
+var IntegrationProviders = []string{IntegrationWallabag, IntegrationPocket, IntegrationLinkding}
+
+const (
+	// integrationTimeout bounds a push to a read-later service, sign-in
+	// included.
+	integrationTimeout = 30 * time.Second
+	// pocketAddURL is the Pocket API endpoint pages are added with.
+	pocketAddURL = "https://getpocket.com/v3/add"
+	// keyIntegrationsPrefix prefixes the setting keys of every integration.
+	keyIntegrationsPrefix = "integrations."
+)
+
+// WallabagIntegration signs in to a Wallabag instance with an API client
+// and the password of its user.
+type WallabagIntegration struct {
+	URL          string `json:"url"`
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+}
+
+// PocketIntegration adds pages with the consumer key of a Pocket app and an
+// access token it was granted.
+type PocketIntegration struct {
+	ConsumerKey string `json:"consumerKey"`
+	AccessToken string `json:"accessToken"`
+}
+
+// LinkdingIntegration adds bookmarks to a Linkding instance with an API token.
+type LinkdingIntegration struct {
+	URL   string `json:"url"`
+	Token string `json:"token"`
+}
+
+// IntegrationSettings configures the read-later services. Secrets (client
+// secret, password, access token, token) are returned masked; saving a
+// masked or empty secret keeps the stored one. Emptying the URL, or for
+// Pocket the consumer key, removes a service with its secrets.
+type IntegrationSettings struct {
+	Wallabag WallabagIntegration `json:"wallabag"`
+	Pocket   PocketIntegration   `json:"pocket"`
+	Linkding LinkdingIntegration `json:"linkding"`
+}
+
+// IntegrationSaveResult reports an entry pushed to a read-later service.
+type IntegrationSaveResult struct {
+	Provider string `json:"provider"`
+	// RemoteID is the ID the service gave the saved page, when it returns one.
+	RemoteID string `json:"remoteId,omitempty"`
+}
+
+// integrationPage is what a read-later service is sent.
+type integrationPage struct {
+	URL   string
+	Title string
+	// Content is the readable content as HTML, when it was asked for and
+	// the service stores content.
+	Content string
+}
+
+// integrationAdapter pushes pages to one read-later service.
+type integrationAdapter interface {
+	configured() bool
+	save(ctx context.Context, client *http.Client, page integrationPage) (string, error)
+}
+
+// IntegrationsService pushes entries to third-party read-later services.
+type IntegrationsService interface {
+	// GetSettings returns the configuration of every service, with masked
+	// secrets.
+	GetSettings(ctx context.Context) (*IntegrationSettings, error)
+	// SetSettings saves the configuration of every service; see
+	// IntegrationSettings for how secrets are kept.
+	SetSettings(ctx context.Context, settings *IntegrationSettings) error
+	// SaveTo pushes the URL and title of an entry to provider, with its
+	// readable content when withContent is set and the service keeps
+	// content (only Wallabag does). Returns ErrIntegrationNotConfigured when
+	// the service is not set up and ErrIntegrationFailed when it refused.
+	SaveTo(ctx context.Context, entryID int64, provider string, withContent bool) (IntegrationSaveResult, error)
+}
+
+type integrationsService struct {
+	settings   repository.SettingsRepository
+	entries    repository.EntryRepository
+	httpClient *http.Client
+	pocketURL  string
+}
+
+func NewIntegrationsService(settings repository.SettingsRepository, entries repository.EntryRepository, httpClient *http.Client) IntegrationsService {
+	client := httpClient
+	if client == nil {
+		client = &http.Client{Timeout: integrationTimeout}
+	}
+	return &integrationsService{settings: settings, entries: entries, httpClient: client, pocketURL: pocketAddURL}
+}
+
+// integrationField is a setting of an integration: its key under
+// keyIntegrationsPrefix, the field it is kept in and whether it is a secret.
+type integrationField struct {
+	key    string
+	value  *string
+	secret bool
+}
+
+// integrationFields lists the settings of every integration, bound to s.
+func integrationFields(s *IntegrationSettings) []integrationField {
+	return []integrationField{
+		{"wallabag.url", &s.Wallabag.URL, false},
+		{"wallabag.client_id", &s.Wallabag.ClientID, false},
+		{"wallabag.client_secret", &s.Wallabag.ClientSecret, true},
+		{"wallabag.username", &s.Wallabag.Username, false},
+		{"wallabag.password", &s.Wallabag.Password, true},
+		{"pocket.consumer_key", &s.Pocket.ConsumerKey, false},
+		{"pocket.access_token", &s.Pocket.AccessToken, true},
+		{"linkding.url", &s.Linkding.URL, false},
+		{"linkding.token", &s.Linkding.Token, true},
+	}
+}
+
+func (s *integrationsService) GetSettings(ctx context.Context) (*IntegrationSettings, error) {
+	settings, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range integrationFields(settings) {
+		if field.secret {
+			*field.value = maskAPIKey(*field.value)
+		}
+	}
+	return settings, nil
+}
+
+func (s *integrationsService) SetSettings(ctx context.Context, settings *IntegrationSettings) error {
+	removed := map[string]bool{
+		"wallabag.": strings.TrimSpace(settings.Wallabag.URL) == "",
+		"pocket.":   strings.TrimSpace(settings.Pocket.ConsumerKey) == "",
+		"linkding.": strings.TrimSpace(settings.Linkding.URL) == "",
+	}
+	for _, field := range integrationFields(settings) {
+		key := keyIntegrationsPrefix + field.key
+		provider := field.key[:strings.Index(field.key, ".")+1]
+		value := strings.TrimSpace(*field.value)
+		if strings.HasSuffix(field.key, ".url") {
+			value = strings.TrimRight(value, "/")
+		}
+		switch {
+		case removed[provider]:
+			if err := s.settings.Delete(ctx, key); err != nil {
+				return fmt.Errorf("delete %s: %w", key, err)
+			}
+		case field.secret && (value == "" || isMaskedKey(value)):
+			// Keep the stored secret
+		default:
+			if err := s.settings.Set(ctx, key, value); err != nil {
+				return fmt.Errorf("set %s: %w", key, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *integrationsService) SaveTo(ctx context.Context, entryID int64, provider string, withContent bool) (IntegrationSaveResult, error) {
+	settings, err := s.load(ctx)
+	if err != nil {
+		return IntegrationSaveResult{}, err
+	}
+	var adapter integrationAdapter
+	switch provider {
+	case IntegrationWallabag:
+		adapter = settings.Wallabag
+	case IntegrationPocket:
+		adapter = pocketAdapter{PocketIntegration: settings.Pocket, endpoint: s.pocketURL}
+	case IntegrationLinkding:
+		adapter = settings.Linkding
+	default:
+		return IntegrationSaveResult{}, ErrInvalid
+	}
+	if !adapter.configured() {
+		return IntegrationSaveResult{}, ErrIntegrationNotConfigured
+	}
+
+	entry, err := s.entries.GetByID(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return IntegrationSaveResult{}, ErrNotFound
+		}
+		return IntegrationSaveResult{}, fmt.Errorf("get entry: %w", err)
+	}
+	page := integrationPage{URL: trimmedValue(entry.URL), Title: trimmedValue(entry.Title)}
+	if page.URL == "" {
+		return IntegrationSaveResult{}, fmt.Errorf("%w: entry has no URL", ErrInvalid)
+	}
+	if withContent {
+		page.Content = trimmedValue(entry.ReadableContent)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, integrationTimeout)
+	defer cancel()
+	remoteID, err := adapter.save(ctx, s.httpClient, page)
+	if err != nil {
+		return IntegrationSaveResult{}, err
+	}
+	return IntegrationSaveResult{Provider: provider, RemoteID: remoteID}, nil
+}
+
+// load reads the configuration of every service, secrets unmasked.
+func (s *integrationsService) load(ctx context.Context) (*IntegrationSettings, error) {
+	stored, err := s.settings.GetByPrefix(ctx, keyIntegrationsPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("get integration settings: %w", err)
+	}
+	values := make(map[string]string, len(stored))
+	for _, setting := range stored {
+		values[strings.TrimPrefix(setting.Key, keyIntegrationsPrefix)] = setting.Value
+	}
+	settings := &IntegrationSettings{}
+	for _, field := range integrationFields(settings) {
+		*field.value = values[field.key]
+	}
+	return settings, nil
+}
+
+func (w WallabagIntegration) configured() bool {
+	return w.URL != "" && w.ClientID != "" && w.ClientSecret != "" && w.Username != "" && w.Password != ""
+}
+
+// save signs in with the password grant, then creates the entry. Wallabag
+// fetches the page itself unless content is sent.
+func (w WallabagIntegration) save(ctx context.Context, client *http.Client, page integrationPage) (string, error) {
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	err := postIntegration(ctx, client, IntegrationWallabag, w.URL+"/oauth/v2/token", url.Values{
+		"grant_type":    {"password"},
+		"client_id":     {w.ClientID},
+		"client_secret": {w.ClientSecret},
+		"username":      {w.Username},
+		"password":      {w.Password},
+	}, nil, &token)
+	if err != nil {
+		return "", err
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("%w: wallabag returned no access token", ErrIntegrationFailed)
+	}
+
+	form := url.Values{"url": {page.URL}}
+	if page.Title != "" {
+		form.Set("title", page.Title)
+	}
+	if page.Content != "" {
+		form.Set("content", page.Content)
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	header := http.Header{"Authorization": {"Bearer " + token.AccessToken}}
+	if err := postIntegration(ctx, client, IntegrationWallabag, w.URL+"/api/entries.json", form, header, &created); err != nil {
+		return "", err
+	}
+	return formatRemoteID(created.ID), nil
+}
+
+// pocketAdapter is a Pocket configuration with the endpoint it adds to.
+type pocketAdapter struct {
+	PocketIntegration
+	endpoint string
+}
+
+func (p pocketAdapter) configured() bool {
+	return p.ConsumerKey != "" && p.AccessToken != ""
+}
+
+func (p pocketAdapter) save(ctx context.Context, client *http.Client, page integrationPage) (string, error) {
+	body := map[string]string{
+		"url":          page.URL,
+		"consumer_key": p.ConsumerKey,
+		"access_token": p.AccessToken,
+	}
+	if page.Title != "" {
+		body["title"] = page.Title
+	}
+	var added struct {
+		Item struct {
+			ItemID string `json:"item_id"`
+		} `json:"item"`
+	}
+	header := http.Header{"X-Accept": {"application/json"}}
+	if err := postIntegration(ctx, client, IntegrationPocket, p.endpoint, body, header, &added); err != nil {
+		return "", err
+	}
+	return added.Item.ItemID, nil
+}
+
+func (l LinkdingIntegration) configured() bool {
+	return l.URL != "" && l.Token != ""
+}
+
+func (l LinkdingIntegration) save(ctx context.Context, client *http.Client, page integrationPage) (string, error) {
+	body := map[string]any{"url": page.URL, "unread": true}
+	if page.Title != "" {
+		body["title"] = page.Title
+	}
+	var created struct {
+		ID int64 `json:"id"`
+	}
+	header := http.Header{"Authorization": {"Token " + l.Token}}
+	if err := postIntegration(ctx, client, IntegrationLinkding, l.URL+"/api/bookmarks/", body, header, &created); err != nil {
+		return "", err
+	}
+	return formatRemoteID(created.ID), nil
+}
+
+// postIntegration posts a form (url.Values) or JSON body to a read-later
+// service and decodes its JSON answer into out. Network errors and error
+// statuses are returned as ErrIntegrationFailed.
+func postIntegration(ctx context.Context, client *http.Client, provider, endpoint string, payload any, header http.Header, out any) error {
+	var body io.Reader
+	contentType := "application/json"
+	if form, ok := payload.(url.Values); ok {
+		body = strings.NewReader(form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	} else {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encode %s request: %w", provider, err)
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, body)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrIntegrationFailed, provider, err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%w: %s: %v", ErrIntegrationFailed, provider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%w: %s answered %d: %s", ErrIntegrationFailed, provider, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("%w: %s answered with invalid JSON: %v", ErrIntegrationFailed, provider, err)
+	}
+	return nil
+}
+
+func formatRemoteID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestIntegrationsService_Settings(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	settings := testutil.NewMockSettingsRepository(ctrl)
+	service := NewIntegrationsService(settings, nil, nil)
+	ctx := context.Background()
+
+	settings.EXPECT().GetByPrefix(ctx, "integrations.").Return([]model.Setting{
+		{Key: "integrations.linkding.url", Value: "https://links.example.com"},
+		{Key: "integrations.linkding.token", Value: "0123456789abcdef"},
+	}, nil)
+	got, err := service.GetSettings(ctx)
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	if got.Linkding.URL != "https://links.example.com" || got.Linkding.Token == "0123456789abcdef" || !isMaskedKey(got.Linkding.Token) {
+		t.Errorf("expected a masked token, got %+v", got.Linkding)
+	}
+
+	// The masked token is kept, Pocket is set and Wallabag, without a URL, removed
+	settings.EXPECT().Set(ctx, "integrations.linkding.url", "https://links.example.com").Return(nil)
+	settings.EXPECT().Set(ctx, "integrations.pocket.consumer_key", "key").Return(nil)
+	settings.EXPECT().Set(ctx, "integrations.pocket.access_token", "token").Return(nil)
+	for _, key := range []string{"url", "client_id", "client_secret", "username", "password"} {
+		settings.EXPECT().Delete(ctx, "integrations.wallabag."+key).Return(nil)
+	}
+	err = service.SetSettings(ctx, &IntegrationSettings{
+		Pocket:   PocketIntegration{ConsumerKey: "key", AccessToken: "token"},
+		Linkding: LinkdingIntegration{URL: "https://links.example.com/", Token: got.Linkding.Token},
+	})
+	if err != nil {
+		t.Fatalf("set settings: %v", err)
+	}
+}
+
+func TestIntegrationsService_SaveTo(t *testing.T) {
+	var wallabagForm, linkdingBody, pocketBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/v2/token":
+			if r.FormValue("grant_type") != "password" || r.FormValue("password") != "secret" {
+				http.Error(w, "bad credentials", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token":"wb-token"}`))
+		case "/api/entries.json":
+			if r.Header.Get("Authorization") != "Bearer wb-token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			wallabagForm = map[string]any{"url": r.FormValue("url"), "content": r.FormValue("content")}
+			w.Write([]byte(`{"id":42}`))
+		case "/api/bookmarks/":
+			if r.Header.Get("Authorization") != "Token ld-token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			json.NewDecoder(r.Body).Decode(&linkdingBody)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":7}`))
+		case "/v3/add":
+			json.NewDecoder(r.Body).Decode(&pocketBody)
+			w.Write([]byte(`{"item":{"item_id":"123"},"status":1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	settings := testutil.NewMockSettingsRepository(ctrl)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	service := NewIntegrationsService(settings, entries, server.Client()).(*integrationsService)
+	service.pocketURL = server.URL + "/v3/add"
+	ctx := context.Background()
+
+	settings.EXPECT().GetByPrefix(ctx, "integrations.").Return([]model.Setting{
+		{Key: "integrations.wallabag.url", Value: server.URL},
+		{Key: "integrations.wallabag.client_id", Value: "client"},
+		{Key: "integrations.wallabag.client_secret", Value: "client-secret"},
+		{Key: "integrations.wallabag.username", Value: "ann"},
+		{Key: "integrations.wallabag.password", Value: "secret"},
+		{Key: "integrations.pocket.consumer_key", Value: "consumer"},
+		{Key: "integrations.pocket.access_token", Value: "access"},
+		{Key: "integrations.linkding.url", Value: server.URL},
+		{Key: "integrations.linkding.token", Value: "ld-token"},
+	}, nil).AnyTimes()
+	title, url, readable := "Post", "https://example.com/post", "<p>Readable</p>"
+	entries.EXPECT().GetByID(gomock.Any(), int64(1)).
+		Return(model.Entry{ID: 1, Title: &title, URL: &url, ReadableContent: &readable}, nil).AnyTimes()
+
+	result, err := service.SaveTo(ctx, 1, IntegrationWallabag, true)
+	if err != nil {
+		t.Fatalf("save to wallabag: %v", err)
+	}
+	if result.RemoteID != "42" || wallabagForm["url"] != url || wallabagForm["content"] != readable {
+		t.Errorf("unexpected wallabag push %+v, form %v", result, wallabagForm)
+	}
+
+	result, err = service.SaveTo(ctx, 1, IntegrationLinkding, true)
+	if err != nil {
+		t.Fatalf("save to linkding: %v", err)
+	}
+	if result.RemoteID != "7" || linkdingBody["url"] != url || linkdingBody["title"] != title {
+		t.Errorf("unexpected linkding push %+v, body %v", result, linkdingBody)
+	}
+
+	result, err = service.SaveTo(ctx, 1, IntegrationPocket, false)
+	if err != nil {
+		t.Fatalf("save to pocket: %v", err)
+	}
+	if result.RemoteID != "123" || pocketBody["access_token"] != "access" || pocketBody["url"] != url {
+		t.Errorf("unexpected pocket push %+v, body %v", result, pocketBody)
+	}
+}
+
+func TestIntegrationsService_SaveToErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	ctrl := gomock.NewController(t)
+	settings := testutil.NewMockSettingsRepository(ctrl)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	service := NewIntegrationsService(settings, entries, server.Client())
+	ctx := context.Background()
+
+	settings.EXPECT().GetByPrefix(ctx, "integrations.").Return([]model.Setting{
+		{Key: "integrations.linkding.url", Value: server.URL},
+		{Key: "integrations.linkding.token", Value: "wrong"},
+	}, nil).AnyTimes()
+	url := "https://example.com/post"
+	entries.EXPECT().GetByID(ctx, int64(1)).Return(model.Entry{ID: 1, URL: &url}, nil)
+
+	if _, err := service.SaveTo(ctx, 1, IntegrationPocket, false); !errors.Is(err, ErrIntegrationNotConfigured) {
+		t.Errorf("expected ErrIntegrationNotConfigured, got %v", err)
+	}
+	if _, err := service.SaveTo(ctx, 1, IntegrationLinkding, false); !errors.Is(err, ErrIntegrationFailed) {
+		t.Errorf("expected ErrIntegrationFailed, got %v", err)
+	}
+	if _, err := service.SaveTo(ctx, 1, "instapaper", false); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid, got %v", err)
+	}
+}
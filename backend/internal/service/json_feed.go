@@ -0,0 +1,207 @@
+package service
+
+import (
+	"errors"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+	jsonfeed "github.com/mmcdole/gofeed/json"
+
+	"gist/backend/internal/model"
+)
+
+// feedAccept is the Accept header of feed fetches. It names JSON Feed next
+// to RSS and Atom for servers that pick the format by it.
+const feedAccept = "application/rss+xml, application/atom+xml, application/feed+json, application/json;q=0.9, application/xml;q=0.9, text/xml;q=0.9, */*;q=0.8"
+
+// jsonFeedVersion starts the version URL of every JSON Feed.
+const jsonFeedVersion = "jsonfeed.org/version/"
+
+// jsonFeedExtension is the extension namespace the attachments of a JSON
+// Feed item are kept under, as gofeed enclosures have no title or duration.
+const jsonFeedExtension = "jsonfeed"
+
+// maxEntryAttachments caps the attachments stored for a single entry.
+const maxEntryAttachments = 20
+
+var errNotJSONFeed = errors.New("json document is not a JSON Feed")
+
+// newFeedParser returns a feed parser that reads JSON Feed with
+// jsonFeedTranslator.
+func newFeedParser() *gofeed.Parser {
+	parser := gofeed.NewParser()
+	parser.JSONTranslator = &jsonFeedTranslator{}
+	return parser
+}
+
+// jsonFeedTranslator maps JSON Feed 1.0 and 1.1 onto gofeed items where
+// gofeed's own translator falls short. It also rejects JSON that is not a
+// JSON Feed, which gofeed would otherwise read as an empty feed.
+type jsonFeedTranslator struct {
+	gofeed.DefaultJSONTranslator
+}
+
+func (t *jsonFeedTranslator) Translate(feed interface{}) (*gofeed.Feed, error) {
+	doc, ok := feed.(*jsonfeed.Feed)
+	if !ok || !strings.Contains(doc.Version, jsonFeedVersion) {
+		return nil, errNotJSONFeed
+	}
+	parsed, err := t.DefaultJSONTranslator.Translate(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range parsed.Items {
+		translateJSONItem(item, doc.Items[i], parsed)
+	}
+	return parsed, nil
+}
+
+// translateJSONItem completes an item gofeed translated from a JSON Feed:
+//   - the link falls back to external_url, then to an id that is a URL, and
+//     external_url is kept when the item links elsewhere than its permalink;
+//   - plain-text content becomes paragraphs of HTML;
+//   - an item without authors has the feed's, as the spec says;
+//   - attachments keep their size, title and duration.
+func translateJSONItem(item *gofeed.Item, doc *jsonfeed.Item, feed *gofeed.Feed) {
+	external := strings.TrimSpace(doc.ExternalURL)
+	switch {
+	case item.Link != "":
+	case external != "":
+		item.Link = external
+	case isValidURL(strings.TrimSpace(doc.ID)):
+		item.Link = strings.TrimSpace(doc.ID)
+	}
+	if external != "" && external != item.Link {
+		if item.Custom == nil {
+			item.Custom = make(map[string]string)
+		}
+		item.Custom["external_url"] = external
+	}
+
+	if doc.ContentHTML == "" && doc.ContentText != "" {
+		item.Content = textToHTML(doc.ContentText)
+	}
+
+	if len(item.Authors) == 0 && len(feed.Authors) > 0 {
+		item.Authors = feed.Authors
+		item.Author = feed.Authors[0]
+	}
+
+	item.Enclosures = nil
+	if doc.Attachments == nil {
+		return
+	}
+	var attachments []ext.Extension
+	for _, a := range *doc.Attachments {
+		size := ""
+		if a.SizeInBytes > 0 {
+			size = strconv.FormatInt(a.SizeInBytes, 10)
+		}
+		item.Enclosures = append(item.Enclosures, &gofeed.Enclosure{URL: a.URL, Type: a.MimeType, Length: size})
+		attachments = append(attachments, ext.Extension{Name: "attachment", Attrs: map[string]string{
+			"url":                 a.URL,
+			"mime_type":           a.MimeType,
+			"title":               a.Title,
+			"size_in_bytes":       size,
+			"duration_in_seconds": strconv.FormatInt(a.DurationInSeconds, 10),
+		}})
+	}
+	if item.Extensions == nil {
+		item.Extensions = make(ext.Extensions)
+	}
+	item.Extensions[jsonFeedExtension] = map[string][]ext.Extension{"attachment": attachments}
+}
+
+// textToHTML turns plain text into escaped HTML paragraphs, one per block
+// of text between blank lines, keeping single line breaks.
+func textToHTML(text string) string {
+	var b strings.Builder
+	for _, block := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		block = strings.TrimSpace(block)
+		if block == "" {
+			continue
+		}
+		lines := strings.Split(block, "\n")
+		for i, line := range lines {
+			lines[i] = html.EscapeString(strings.TrimSpace(line))
+		}
+		b.WriteString("<p>" + strings.Join(lines, "<br>") + "</p>")
+	}
+	return b.String()
+}
+
+// itemAuthor is the author line of an item: the names of all its authors.
+func itemAuthor(item *gofeed.Item) string {
+	var names []string
+	for _, person := range item.Authors {
+		if person != nil && strings.TrimSpace(person.Name) != "" {
+			names = append(names, strings.TrimSpace(person.Name))
+		}
+	}
+	if len(names) == 0 && item.Author != nil {
+		return strings.TrimSpace(item.Author.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// itemAttachments collects the files attached to a feed item, in feed order:
+// JSON Feed attachments, otherwise RSS and Atom enclosures, with the iTunes
+// duration when there is a single one. Anything but http(s) is dropped.
+func itemAttachments(item *gofeed.Item) []model.EntryAttachment {
+	attachments := make([]model.EntryAttachment, 0)
+	seen := make(map[string]bool)
+	add := func(a model.EntryAttachment) {
+		a.URL = strings.TrimSpace(a.URL)
+		if !isValidURL(a.URL) || seen[a.URL] || len(attachments) >= maxEntryAttachments {
+			return
+		}
+		seen[a.URL] = true
+		attachments = append(attachments, a)
+	}
+
+	if exts, ok := item.Extensions[jsonFeedExtension]; ok {
+		for _, a := range exts["attachment"] {
+			add(model.EntryAttachment{
+				URL:             a.Attrs["url"],
+				MimeType:        strings.TrimSpace(a.Attrs["mime_type"]),
+				Title:           strings.TrimSpace(a.Attrs["title"]),
+				SizeBytes:       parseCount(a.Attrs["size_in_bytes"]),
+				DurationSeconds: parseCount(a.Attrs["duration_in_seconds"]),
+			})
+		}
+		return attachments
+	}
+
+	for _, enc := range item.Enclosures {
+		add(model.EntryAttachment{URL: enc.URL, MimeType: strings.TrimSpace(enc.Type), SizeBytes: parseCount(enc.Length)})
+	}
+	if len(attachments) == 1 && item.ITunesExt != nil {
+		attachments[0].DurationSeconds = parseDuration(item.ITunesExt.Duration)
+	}
+	return attachments
+}
+
+// parseCount reads a non-negative whole number, 0 when it is anything else.
+func parseCount(raw string) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// parseDuration reads an iTunes duration, seconds or [HH:]MM:SS, in seconds.
+func parseDuration(raw string) int64 {
+	var seconds int64
+	for _, part := range strings.Split(strings.TrimSpace(raw), ":") {
+		n, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || n < 0 {
+			return 0
+		}
+		seconds = seconds*60 + n
+	}
+	return seconds
+}
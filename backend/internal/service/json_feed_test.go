@@ -0,0 +1,120 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+	ext "github.com/mmcdole/gofeed/extensions"
+
+	"gist/backend/internal/model"
+)
+
+const jsonFeedFixture = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Linkblog",
+  "home_page_url": "https://example.com/",
+  "authors": [{"name": "Ann"}],
+  "items": [
+    {
+      "id": "1",
+      "url": "https://example.com/posts/1",
+      "external_url": "https://news.example.org/story",
+      "title": "A story worth reading",
+      "content_text": "First line\nsecond line\n\n<b>not bold</b> & more",
+      "date_published": "2025-03-01T08:00:00Z"
+    },
+    {
+      "id": "https://example.com/episodes/2",
+      "title": "Episode 2",
+      "content_html": "<p>Show notes</p>",
+      "authors": [{"name": "Bo"}, {"name": "Cy"}],
+      "attachments": [
+        {"url": "https://cdn.example.com/2.mp3", "mime_type": "audio/mpeg", "title": "Episode 2", "size_in_bytes": 1234, "duration_in_seconds": 61},
+        {"url": "ftp://cdn.example.com/2.ogg", "mime_type": "audio/ogg"}
+      ]
+    },
+    {
+      "id": "tag:example.com,2025:3",
+      "external_url": "https://elsewhere.example.net/page",
+      "content_text": "Link only"
+    }
+  ]
+}`
+
+func TestJSONFeedTranslator(t *testing.T) {
+	parsed, err := newFeedParser().Parse(strings.NewReader(jsonFeedFixture))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if parsed.Title != "Linkblog" || len(parsed.Items) != 3 {
+		t.Fatalf("unexpected feed %q with %d items", parsed.Title, len(parsed.Items))
+	}
+
+	linked := itemToEntry(1, parsed.Items[0], false, newItemDates(nil, time.Now()))
+	if *linked.URL != "https://example.com/posts/1" || linked.ExternalURL == nil || *linked.ExternalURL != "https://news.example.org/story" {
+		t.Errorf("expected permalink and external url, got %v and %v", *linked.URL, linked.ExternalURL)
+	}
+	if want := "<p>First line<br>second line</p><p>&lt;b&gt;not bold&lt;/b&gt; &amp; more</p>"; *linked.Content != want {
+		t.Errorf("expected text content as HTML %q, got %q", want, *linked.Content)
+	}
+	if linked.Author == nil || *linked.Author != "Ann" {
+		t.Errorf("expected the feed author, got %v", linked.Author)
+	}
+	if linked.PublishedAt == nil || linked.PublishedAt.Format("2006-01-02") != "2025-03-01" {
+		t.Errorf("unexpected publish date %v", linked.PublishedAt)
+	}
+	if len(linked.Attachments) != 0 {
+		t.Errorf("expected no attachments, got %+v", linked.Attachments)
+	}
+
+	episode := itemToEntry(1, parsed.Items[1], false, newItemDates(nil, time.Now()))
+	if *episode.URL != "https://example.com/episodes/2" || episode.ExternalURL != nil {
+		t.Errorf("expected the id as url, got %v and %v", *episode.URL, episode.ExternalURL)
+	}
+	if episode.Author == nil || *episode.Author != "Bo, Cy" {
+		t.Errorf("expected both authors, got %v", episode.Author)
+	}
+	want := []model.EntryAttachment{{URL: "https://cdn.example.com/2.mp3", MimeType: "audio/mpeg", Title: "Episode 2", SizeBytes: 1234, DurationSeconds: 61}}
+	if !reflect.DeepEqual(episode.Attachments, want) {
+		t.Errorf("expected attachments %+v, got %+v", want, episode.Attachments)
+	}
+
+	linkOnly := itemToEntry(1, parsed.Items[2], false, newItemDates(nil, time.Now()))
+	if linkOnly.URL == nil || *linkOnly.URL != "https://elsewhere.example.net/page" || linkOnly.ExternalURL != nil {
+		t.Errorf("expected the external url as url, got %v and %v", linkOnly.URL, linkOnly.ExternalURL)
+	}
+}
+
+func TestJSONFeedTranslator_RejectsOtherJSON(t *testing.T) {
+	if _, err := newFeedParser().Parse(strings.NewReader(`{"error": "not found", "items": []}`)); err == nil {
+		t.Error("expected JSON without a JSON Feed version to be rejected")
+	}
+}
+
+func TestItemAttachments_Enclosures(t *testing.T) {
+	item := &gofeed.Item{
+		Enclosures: []*gofeed.Enclosure{
+			{URL: "https://cdn.example.com/ep.mp3", Type: "audio/mpeg", Length: "5000"},
+			{URL: "https://cdn.example.com/ep.mp3", Type: "audio/mpeg"},
+		},
+		ITunesExt: &ext.ITunesItemExtension{Duration: "1:02:03"},
+	}
+	want := []model.EntryAttachment{{URL: "https://cdn.example.com/ep.mp3", MimeType: "audio/mpeg", SizeBytes: 5000, DurationSeconds: 3723}}
+	if got := itemAttachments(item); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if got := itemAttachments(&gofeed.Item{}); got == nil || len(got) != 0 {
+		t.Errorf("expected an empty list for an item without enclosures, got %#v", got)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	for raw, want := range map[string]int64{"90": 90, "02:03": 123, "1:02:03": 3723, "": 0, "1:xx": 0, "-5": 0} {
+		if got := parseDuration(raw); got != want {
+			t.Errorf("parseDuration(%q) = %d, want %d", raw, got, want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gist/backend/internal/repository"
+)
+
+const (
+	// LeaseTTL is how long a lease lasts unless renewed. The lease of an
+	// instance that stopped without releasing it frees up after this long.
+	LeaseTTL = 2 * time.Minute
+	// leaseTimeout bounds the database calls of taking or renewing a lease.
+	leaseTimeout = 5 * time.Second
+)
+
+// Leases are database-held locks that keep instances sharing a database
+// from doing the same work at once. Each instance holds leases under its
+// own name, renewing them while it works.
+type Leases struct {
+	repo   repository.LeaseRepository
+	holder string
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+// NewLeases creates the leases of this instance, held under its host name
+// and a random suffix, so a restarted instance does not take over the
+// leases of the previous run before they expire.
+func NewLeases(repo repository.LeaseRepository) *Leases {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "gist"
+	}
+	return &Leases{
+		repo:   repo,
+		holder: fmt.Sprintf("%s-%s", host, uuid.New().String()[:8]),
+		ttl:    LeaseTTL,
+		now:    time.Now,
+	}
+}
+
+// Acquire takes the lease of name, or extends it when this instance holds
+// it already. Returns false while another instance holds it.
+func (l *Leases) Acquire(ctx context.Context, name string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, leaseTimeout)
+	defer cancel()
+	now := l.now()
+	return l.repo.Acquire(ctx, name, l.holder, now, now.Add(l.ttl))
+}
+
+// Release gives up the lease of name.
+func (l *Leases) Release(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), leaseTimeout)
+	defer cancel()
+	if err := l.repo.Release(ctx, name, l.holder); err != nil {
+		log.Printf("release lease %s: %v", name, err)
+	}
+}
+
+// Hold renews the lease of name until ctx is done, calling lost once if the
+// lease could not be renewed before it expired, as when another instance
+// took it over after this one stalled.
+func (l *Leases) Hold(ctx context.Context, name string, lost func()) {
+	ticker := time.NewTicker(l.ttl / 3)
+	defer ticker.Stop()
+	renewed := l.now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		ok, err := l.Acquire(ctx, name)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			// Retried on the next tick, while the lease still lasts
+			log.Printf("renew lease %s: %v", name, err)
+			if l.now().Sub(renewed) < l.ttl {
+				continue
+			}
+		}
+		if !ok {
+			log.Printf("lost lease %s", name)
+			lost()
+			return
+		}
+		renewed = l.now()
+	}
+}
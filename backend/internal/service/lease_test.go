@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gist/backend/internal/repository"
+)
+
+// memoryLeases is a lease table shared by the runners of a test.
+type memoryLeases struct {
+	repository.LeaseRepository
+	mu      sync.Mutex
+	holders map[string]string
+	expires map[string]time.Time
+}
+
+func newMemoryLeases() *memoryLeases {
+	return &memoryLeases{holders: map[string]string{}, expires: map[string]time.Time{}}
+}
+
+func (m *memoryLeases) Acquire(_ context.Context, name, holder string, now, expires time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, ok := m.holders[name]; ok && current != holder && now.Before(m.expires[name]) {
+		return false, nil
+	}
+	m.holders[name] = holder
+	m.expires[name] = expires
+	return true, nil
+}
+
+func (m *memoryLeases) Release(_ context.Context, name, holder string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.holders[name] == holder {
+		delete(m.holders, name)
+	}
+	return nil
+}
+
+func (m *memoryLeases) steal(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.holders[name] = "intruder"
+	m.expires[name] = time.Now().Add(time.Hour)
+}
+
+func TestLeasedTaskRunner_OneInstanceAtATime(t *testing.T) {
+	shared := newMemoryLeases()
+	first := NewLeasedTaskRunner(nil, NewLeases(shared), TaskRefresh)
+	second := NewLeasedTaskRunner(nil, NewLeases(shared), TaskRefresh)
+
+	release := make(chan struct{})
+	task, err := first.Start(TaskRefresh, 0, func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	if _, err := second.Start(TaskRefresh, 0, func(context.Context, TaskHandle) (interface{}, error) {
+		return nil, nil
+	}); !errors.Is(err, ErrTaskRunning) {
+		t.Fatalf("expected ErrTaskRunning while the other instance runs it, got %v", err)
+	}
+	// Kinds without a lease run on both
+	if _, err := second.Start(TaskImport, 0, func(context.Context, TaskHandle) (interface{}, error) {
+		return nil, nil
+	}); err != nil {
+		t.Fatalf("expected an unleased kind to start, got %v", err)
+	}
+
+	close(release)
+	waitForStatus(t, first, task.ID)
+	other, err := second.Start(TaskRefresh, 0, func(context.Context, TaskHandle) (interface{}, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the released lease to be taken, got %v", err)
+	}
+	waitForStatus(t, second, other.ID)
+}
+
+func TestLeasedTaskRunner_CancelsOnLostLease(t *testing.T) {
+	shared := newMemoryLeases()
+	leases := NewLeases(shared)
+	leases.ttl = 30 * time.Millisecond
+	runner := NewLeasedTaskRunner(nil, leases, TaskRefresh)
+
+	task, err := runner.Start(TaskRefresh, 0, func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	shared.steal(taskLease(TaskRefresh))
+
+	if got := waitForStatus(t, runner, task.ID); got.Status != TaskCancelled {
+		t.Errorf("expected the task to be cancelled once its lease was lost, got %s", got.Status)
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+const (
+	// linkRecheckAfter is how long a link check stays fresh.
+	linkRecheckAfter = 7 * 24 * time.Hour
+	// linkCheckBatch is how many due links are loaded at a time.
+	linkCheckBatch = 100
+	// maxConcurrentLinkChecks limits parallel checks; hosts are still visited one at a time.
+	maxConcurrentLinkChecks = 4
+	linkCheckTimeout        = 20 * time.Second
+	// linkCheckBodyLimit caps how much of a GET response is read before hanging up.
+	linkCheckBodyLimit = 64 << 10
+)
+
+// LinkCheckResult summarizes a run of link checks.
+type LinkCheckResult struct {
+	Checked int `json:"checked"`
+	Dead    int `json:"dead"`
+	Errors  int `json:"errors"`
+}
+
+// LinkCheckService verifies that the URLs of starred entries still resolve,
+// so saved articles whose original is gone can be found and read from the
+// copy stored here.
+type LinkCheckService interface {
+	// CheckStarred checks every starred entry not checked within the last week.
+	CheckStarred(ctx context.Context, progress func(checked int)) (LinkCheckResult, error)
+	// Broken returns the starred entries whose last check failed.
+	Broken(ctx context.Context) ([]model.BrokenLink, error)
+}
+
+type linkCheckService struct {
+	checks     repository.LinkCheckRepository
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+func NewLinkCheckService(checks repository.LinkCheckRepository, httpClient *http.Client) LinkCheckService {
+	client := httpClient
+	if client == nil {
+		client = &http.Client{Timeout: linkCheckTimeout}
+	}
+	return &linkCheckService{checks: checks, httpClient: client, now: time.Now}
+}
+
+func (s *linkCheckService) CheckStarred(ctx context.Context, progress func(checked int)) (LinkCheckResult, error) {
+	var result LinkCheckResult
+	cutoff := s.now().Add(-linkRecheckAfter)
+	hl := newHostLimiter()
+
+	for {
+		due, err := s.checks.ListDue(ctx, cutoff, linkCheckBatch)
+		if err != nil {
+			return result, err
+		}
+		if len(due) == 0 {
+			return result, nil
+		}
+
+		checks := make([]model.LinkCheck, len(due))
+		g, gctx := errgroup.WithContext(ctx)
+		g.SetLimit(maxConcurrentLinkChecks)
+		for i, link := range due {
+			i, link := i, link
+			g.Go(func() error {
+				if host := extractHost(link.URL); host != "" {
+					if err := hl.acquire(gctx, host); err != nil {
+						return err
+					}
+					defer hl.release(host)
+				}
+				checks[i] = s.check(gctx, link)
+				return nil
+			})
+		}
+		if err := g.Wait(); err != nil {
+			return result, err
+		}
+		if err := ctx.Err(); err != nil {
+			return result, err // checks cut short by cancellation are not results
+		}
+
+		for _, check := range checks {
+			if err := s.checks.Save(ctx, check); err != nil {
+				return result, err
+			}
+			result.Checked++
+			switch check.Status {
+			case model.LinkDead:
+				result.Dead++
+			case model.LinkError:
+				result.Errors++
+			}
+		}
+		if progress != nil {
+			progress(result.Checked)
+		}
+	}
+}
+
+// check requests the link, falling back from HEAD to GET for servers that
+// reject or mishandle HEAD.
+func (s *linkCheckService) check(ctx context.Context, link model.LinkToCheck) model.LinkCheck {
+	status, err := s.request(ctx, http.MethodHead, link.URL)
+	if err == nil && status >= http.StatusBadRequest && !isGoneStatus(status) {
+		status, err = s.request(ctx, http.MethodGet, link.URL)
+	}
+
+	check := model.LinkCheck{EntryID: link.EntryID, CheckedAt: s.now()}
+	switch {
+	case err != nil:
+		check.Status = model.LinkError
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			check.Status = model.LinkDead
+		}
+		msg := err.Error()
+		check.Error = &msg
+	case isGoneStatus(status):
+		check.Status = model.LinkDead
+		check.HTTPStatus = &status
+	case status >= http.StatusBadRequest:
+		check.Status = model.LinkError
+		check.HTTPStatus = &status
+	default:
+		check.Status = model.LinkOK
+		check.HTTPStatus = &status
+	}
+
+	if check.Status != model.LinkOK {
+		check.FailingSince = link.FailingSince
+		if check.FailingSince == nil {
+			check.FailingSince = &check.CheckedAt
+		}
+	}
+	return check
+}
+
+func (s *linkCheckService) request(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", config.DefaultUserAgent)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, linkCheckBodyLimit))
+	return resp.StatusCode, nil
+}
+
+// isGoneStatus reports whether status says the page no longer exists.
+func isGoneStatus(status int) bool {
+	return status == http.StatusNotFound || status == http.StatusGone
+}
+
+func (s *linkCheckService) Broken(ctx context.Context) ([]model.BrokenLink, error) {
+	links, err := s.checks.ListBroken(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list broken links: %w", err)
+	}
+	return links, nil
+}
+
+// LinkCheckTask runs CheckStarred as a task.
+func LinkCheckTask(checks LinkCheckService) TaskFunc {
+	return func(ctx context.Context, h TaskHandle) (interface{}, error) {
+		return checks.CheckStarred(ctx, func(checked int) {
+			h.Progress(checked, 0, "")
+		})
+	}
+}
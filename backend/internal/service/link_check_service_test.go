@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestLinkCheckService_CheckStarred(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+		case "/gone":
+			w.WriteHeader(http.StatusGone)
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	mockChecks := testutil.NewMockLinkCheckRepository(ctrl)
+	service := NewLinkCheckService(mockChecks, server.Client()).(*linkCheckService)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	failingSince := now.Add(-48 * time.Hour)
+	cutoff := now.Add(-linkRecheckAfter)
+	gomock.InOrder(
+		mockChecks.EXPECT().ListDue(ctx, cutoff, linkCheckBatch).Return([]model.LinkToCheck{
+			{EntryID: 1, URL: server.URL + "/ok"},
+			{EntryID: 2, URL: server.URL + "/gone"},
+			{EntryID: 3, URL: server.URL + "/no-head"},
+			{EntryID: 4, URL: server.URL + "/down", FailingSince: &failingSince},
+		}, nil),
+		mockChecks.EXPECT().Save(ctx, gomock.Any()).Times(4).Return(nil),
+		mockChecks.EXPECT().ListDue(ctx, cutoff, linkCheckBatch).Return(nil, nil),
+	)
+
+	result, err := service.CheckStarred(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != (LinkCheckResult{Checked: 4, Dead: 1, Errors: 1}) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestLinkCheckService_Check(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	service := NewLinkCheckService(nil, server.Client()).(*linkCheckService)
+	ctx := context.Background()
+
+	gone := service.check(ctx, model.LinkToCheck{EntryID: 1, URL: server.URL + "/gone"})
+	if gone.Status != model.LinkDead || gone.HTTPStatus == nil || *gone.HTTPStatus != http.StatusNotFound {
+		t.Errorf("expected dead link with 404, got %+v", gone)
+	}
+	if gone.FailingSince == nil || !gone.FailingSince.Equal(gone.CheckedAt) {
+		t.Errorf("a first failure should start failingSince at the check, got %v", gone.FailingSince)
+	}
+
+	earlier := time.Now().Add(-time.Hour)
+	down := service.check(ctx, model.LinkToCheck{EntryID: 2, URL: server.URL + "/down", FailingSince: &earlier})
+	if down.Status != model.LinkError || down.FailingSince == nil || !down.FailingSince.Equal(earlier) {
+		t.Errorf("expected error keeping failingSince, got %+v", down)
+	}
+}
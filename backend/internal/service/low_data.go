@@ -0,0 +1,47 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// lowDataClock formats the ends of a low-data schedule.
+const lowDataClock = "15:04"
+
+// ParseLowDataSchedule parses a daily window "HH:MM-HH:MM" in server local
+// time into minutes since midnight. The window may wrap past midnight
+// ("22:00-07:00"); equal ends mean all day.
+func ParseLowDataSchedule(schedule string) (from, until int, err error) {
+	start, end, ok := strings.Cut(strings.TrimSpace(schedule), "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: low-data schedule must be HH:MM-HH:MM", ErrInvalid)
+	}
+	startAt, err1 := time.Parse(lowDataClock, strings.TrimSpace(start))
+	endAt, err2 := time.Parse(lowDataClock, strings.TrimSpace(end))
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("%w: low-data schedule must be HH:MM-HH:MM", ErrInvalid)
+	}
+	return startAt.Hour()*60 + startAt.Minute(), endAt.Hour()*60 + endAt.Minute(), nil
+}
+
+// inLowDataWindow reports whether now falls in schedule. An empty or
+// malformed schedule never matches.
+func inLowDataWindow(schedule string, now time.Time) bool {
+	if schedule == "" {
+		return false
+	}
+	from, until, err := ParseLowDataSchedule(schedule)
+	if err != nil {
+		return false
+	}
+	minute := now.Hour()*60 + now.Minute()
+	switch {
+	case from == until:
+		return true
+	case from < until:
+		return minute >= from && minute < until
+	default: // wraps past midnight
+		return minute >= from || minute < until
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestInLowDataWindow(t *testing.T) {
+	at := func(clock string) time.Time {
+		parsed, err := time.Parse("15:04", clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return time.Date(2025, 3, 1, parsed.Hour(), parsed.Minute(), 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		schedule string
+		now      string
+		want     bool
+	}{
+		{"", "12:00", false},
+		{"09:00-17:00", "09:00", true},
+		{"09:00-17:00", "16:59", true},
+		{"09:00-17:00", "17:00", false},
+		{"22:00-07:00", "23:30", true},
+		{"22:00-07:00", "06:59", true},
+		{"22:00-07:00", "12:00", false},
+		{"00:00-00:00", "12:00", true},
+		{"bogus", "12:00", false},
+	}
+	for _, tt := range tests {
+		if got := inLowDataWindow(tt.schedule, at(tt.now)); got != tt.want {
+			t.Errorf("inLowDataWindow(%q, %s) = %v, want %v", tt.schedule, tt.now, got, tt.want)
+		}
+	}
+
+	if _, _, err := ParseLowDataSchedule("9-17"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for a malformed schedule, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gist/backend/internal/repository"
+)
+
+// DatabaseSnapshot is a consistent copy of the database taken for download.
+// Closing it deletes it.
+type DatabaseSnapshot struct {
+	*os.File
+	// Name is the file name to offer the download under.
+	Name string
+	Size int64
+}
+
+// Close closes and deletes the snapshot with the directory it was taken in.
+func (s *DatabaseSnapshot) Close() error {
+	err := s.File.Close()
+	if rmErr := os.RemoveAll(filepath.Dir(s.File.Name())); rmErr != nil && err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// RestoreResult summarizes a restore.
+type RestoreResult struct {
+	RowsRestored int64 `json:"rowsRestored"`
+}
+
+// MaintenanceService backs the whole database up and restores it, as when
+// moving an instance to another host. The secret key sealing stored
+// credentials is kept outside the database and is not part of a backup.
+type MaintenanceService interface {
+	// Backup takes a snapshot of the database into the data directory. The
+	// caller streams it out and closes it.
+	Backup(ctx context.Context) (*DatabaseSnapshot, error)
+	// Restore replaces all data with that of the database file read from r.
+	// It returns ErrInvalid when the file is not an intact Gist database, in
+	// which case the data is left as it was.
+	Restore(ctx context.Context, r io.Reader) (RestoreResult, error)
+}
+
+type maintenanceService struct {
+	repo    repository.MaintenanceRepository
+	dataDir string
+	now     func() time.Time
+}
+
+// NewMaintenanceService creates a maintenance service keeping its temporary
+// files in dataDir, on the same disk as the database.
+func NewMaintenanceService(repo repository.MaintenanceRepository, dataDir string) MaintenanceService {
+	return &maintenanceService{repo: repo, dataDir: dataDir, now: time.Now}
+}
+
+func (s *maintenanceService) Backup(ctx context.Context) (*DatabaseSnapshot, error) {
+	// VACUUM INTO needs a path that does not exist yet
+	dir, err := os.MkdirTemp(s.dataDir, ".snapshot-*")
+	if err != nil {
+		return nil, fmt.Errorf("create snapshot directory: %w", err)
+	}
+
+	path := filepath.Join(dir, "gist.db")
+	if err := s.repo.Snapshot(ctx, path); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("open snapshot: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("stat snapshot: %w", err)
+	}
+	snapshot := &DatabaseSnapshot{
+		File: file,
+		Name: "gist-" + s.now().UTC().Format(backupTimeLayout) + ".db",
+		Size: info.Size(),
+	}
+	log.Printf("maintenance: database snapshot taken (%d bytes)", snapshot.Size)
+	return snapshot, nil
+}
+
+func (s *maintenanceService) Restore(ctx context.Context, r io.Reader) (RestoreResult, error) {
+	dir, err := os.MkdirTemp(s.dataDir, ".restore-*")
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("create restore directory: %w", err)
+	}
+	// Also removes the journal files of checking the upload
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "gist.db")
+	file, err := os.Create(path)
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("store upload: %w", err)
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		return RestoreResult{}, err
+	}
+	if err := file.Close(); err != nil {
+		return RestoreResult{}, fmt.Errorf("store upload: %w", err)
+	}
+
+	restored, err := s.repo.Restore(ctx, path)
+	if errors.Is(err, repository.ErrInvalidDatabase) {
+		return RestoreResult{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	log.Printf("maintenance: database restored (%d rows)", restored)
+	return RestoreResult{RowsRestored: restored}, nil
+}
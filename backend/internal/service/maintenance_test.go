@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"gist/backend/internal/repository"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestMaintenanceService_Backup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := testutil.NewMockMaintenanceRepository(ctrl)
+	dataDir := t.TempDir()
+	svc := NewMaintenanceService(repo, dataDir)
+	ctx := context.Background()
+
+	repo.EXPECT().Snapshot(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, path string) error {
+		return os.WriteFile(path, []byte("SQLite format 3\x00"), 0o600)
+	})
+	snapshot, err := svc.Backup(ctx)
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	data, err := io.ReadAll(snapshot)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+	if string(data) != "SQLite format 3\x00" || snapshot.Size != int64(len(data)) || !strings.HasSuffix(snapshot.Name, ".db") {
+		t.Errorf("unexpected snapshot %q (%d bytes) named %q", data, snapshot.Size, snapshot.Name)
+	}
+	if err := snapshot.Close(); err != nil {
+		t.Fatalf("close snapshot: %v", err)
+	}
+	if files, _ := os.ReadDir(dataDir); len(files) != 0 {
+		t.Errorf("expected the snapshot to be deleted on close, found %d files", len(files))
+	}
+}
+
+func TestMaintenanceService_Restore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := testutil.NewMockMaintenanceRepository(ctrl)
+	dataDir := t.TempDir()
+	svc := NewMaintenanceService(repo, dataDir)
+	ctx := context.Background()
+
+	repo.EXPECT().Restore(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, path string) (int64, error) {
+		data, err := os.ReadFile(path)
+		if err != nil || string(data) != "uploaded" {
+			t.Errorf("expected the upload to be stored, got %q, %v", data, err)
+		}
+		return 42, nil
+	})
+	result, err := svc.Restore(ctx, strings.NewReader("uploaded"))
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if result.RowsRestored != 42 {
+		t.Errorf("expected 42 rows restored, got %d", result.RowsRestored)
+	}
+
+	repo.EXPECT().Restore(ctx, gomock.Any()).Return(int64(0), repository.ErrInvalidDatabase)
+	if _, err := svc.Restore(ctx, strings.NewReader("not a database")); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for an invalid database, got %v", err)
+	}
+
+	if files, _ := os.ReadDir(dataDir); len(files) != 0 {
+		t.Errorf("expected uploads to be deleted, found %d files", len(files))
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"gist/backend/internal/events"
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+// maxNotifications is how many notifications the inbox keeps; older ones are
+// dropped as new ones arrive.
+const maxNotifications = 100
+
+// taskLabels name task kinds in notification titles.
+var taskLabels = map[string]string{
+	TaskImport:          "OPML import",
+	TaskRefresh:         "Feed refresh",
+	TaskIconBackfill:    "Icon backfill",
+	TaskSnippetBackfill: "Snippet backfill",
+	TaskSync:            "Sync",
+	TaskLinkCheck:       "Link check",
+	TaskPrune:           "Pruning old entries",
+	TaskFetchReadable:   "Readable content extraction",
+}
+
+type NotificationService interface {
+	// List returns up to limit notifications, newest first.
+	List(ctx context.Context, unreadOnly bool, limit int) ([]model.Notification, error)
+	UnreadCount(ctx context.Context) (int, error)
+	MarkRead(ctx context.Context, id int64) error
+	MarkAllRead(ctx context.Context) error
+	Dismiss(ctx context.Context, id int64) error
+	// Notify adds a notification to the inbox and announces it to event
+	// stream clients.
+	Notify(ctx context.Context, notification model.Notification) (model.Notification, error)
+}
+
+type notificationService struct {
+	notifications repository.NotificationRepository
+	hub           *events.Hub
+}
+
+func NewNotificationService(notifications repository.NotificationRepository, hub *events.Hub) NotificationService {
+	return &notificationService{notifications: notifications, hub: hub}
+}
+
+func (s *notificationService) List(ctx context.Context, unreadOnly bool, limit int) ([]model.Notification, error) {
+	return s.notifications.List(ctx, unreadOnly, limit)
+}
+
+func (s *notificationService) UnreadCount(ctx context.Context) (int, error) {
+	return s.notifications.CountUnread(ctx)
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, id int64) error {
+	if err := s.notifications.MarkRead(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context) error {
+	return s.notifications.MarkAllRead(ctx)
+}
+
+func (s *notificationService) Dismiss(ctx context.Context, id int64) error {
+	if err := s.notifications.Delete(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *notificationService) Notify(ctx context.Context, notification model.Notification) (model.Notification, error) {
+	created, err := s.notifications.Create(ctx, notification)
+	if err != nil {
+		return model.Notification{}, err
+	}
+	if err := s.notifications.Trim(ctx, maxNotifications); err != nil {
+		log.Printf("trim notifications: %v", err)
+	}
+	s.hub.Publish(events.TypeNotification, events.Notification{
+		ID:    created.ID,
+		Kind:  created.Kind,
+		Title: created.Title,
+		Body:  created.Body,
+	})
+	return created, nil
+}
+
+// EventNotifier adds a notification to the inbox when an OPML import finishes,
+// any task fails or a failing feed is disabled, following the events of a
+// hub. Cancelled tasks were stopped on purpose and are not reported.
+type EventNotifier struct {
+	notifications NotificationService
+	hub           *events.Hub
+	stopCh        chan struct{}
+	wg            sync.WaitGroup
+}
+
+func NewEventNotifier(notifications NotificationService, hub *events.Hub) *EventNotifier {
+	return &EventNotifier{notifications: notifications, hub: hub, stopCh: make(chan struct{})}
+}
+
+func (n *EventNotifier) Start() {
+	n.wg.Add(1)
+	go n.run()
+}
+
+func (n *EventNotifier) Stop() {
+	close(n.stopCh)
+	n.wg.Wait()
+}
+
+func (n *EventNotifier) run() {
+	defer n.wg.Done()
+
+	// The hub drops a subscriber that falls behind; subscribe again, as a
+	// missed event only loses its notification
+	for n.follow() {
+	}
+}
+
+// follow handles events until the notifier is stopped, returning false,
+// or the hub drops the subscription, returning true.
+func (n *EventNotifier) follow() bool {
+	ch, unsubscribe := n.hub.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-n.stopCh:
+			return false
+		case ev, ok := <-ch:
+			if !ok {
+				return true
+			}
+			switch data := ev.Data.(type) {
+			case Task:
+				n.notifyTask(context.Background(), data)
+			case events.FeedDisabled:
+				n.notifyFeedDisabled(context.Background(), data)
+			}
+		}
+	}
+}
+
+// notifyTask adds the notification for a task event, if it calls for one.
+func (n *EventNotifier) notifyTask(ctx context.Context, task Task) {
+	notification, ok := taskNotification(task)
+	if !ok {
+		return
+	}
+	if _, err := n.notifications.Notify(ctx, notification); err != nil {
+		log.Printf("notify %s task %s: %v", task.Kind, task.ID, err)
+	}
+}
+
+// notifyFeedDisabled adds the notification for a disabled feed.
+func (n *EventNotifier) notifyFeedDisabled(ctx context.Context, disabled events.FeedDisabled) {
+	if _, err := n.notifications.Notify(ctx, feedDisabledNotification(disabled)); err != nil {
+		log.Printf("notify disabled feed %d: %v", disabled.FeedID, err)
+	}
+}
+
+func feedDisabledNotification(disabled events.FeedDisabled) model.Notification {
+	feedID := disabled.FeedID
+	body := fmt.Sprintf("Disabled after %d failed refreshes in a row", disabled.Failures)
+	if disabled.Error != "" {
+		body += ": " + disabled.Error
+	}
+	return model.Notification{
+		Kind:   model.NotificationFeedDisabled,
+		FeedID: &feedID,
+		Title:  disabled.Title + " disabled",
+		Body:   body,
+	}
+}
+
+func taskNotification(task Task) (model.Notification, bool) {
+	label := taskLabels[task.Kind]
+	if label == "" {
+		label = task.Kind
+	}
+	kind := task.Kind
+	switch {
+	case task.Status == TaskError:
+		return model.Notification{
+			Kind:     model.NotificationTaskFailed,
+			TaskKind: &kind,
+			Title:    label + " failed",
+			Body:     task.Error,
+		}, true
+	case task.Status == TaskDone && task.Kind == TaskImport:
+		notification := model.Notification{
+			Kind:     model.NotificationImportDone,
+			TaskKind: &kind,
+			Title:    label + " finished",
+		}
+		if result, ok := task.Result.(ImportResult); ok {
+			notification.Body = fmt.Sprintf("%d feeds added, %d skipped; %d folders created",
+				result.FeedsCreated, result.FeedsSkipped, result.FoldersCreated)
+		}
+		return notification, true
+	}
+	return model.Notification{}, false
+}
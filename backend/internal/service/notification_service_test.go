@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gist/backend/internal/events"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestEventNotifier_NotifyTask(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNotifications := testutil.NewMockNotificationRepository(ctrl)
+	hub := events.NewHub()
+	received, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+	notifier := NewEventNotifier(NewNotificationService(mockNotifications, hub), hub)
+	ctx := context.Background()
+
+	var stored []model.Notification
+	mockNotifications.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, n model.Notification) (model.Notification, error) {
+		n.ID = int64(len(stored) + 1)
+		stored = append(stored, n)
+		return n, nil
+	}).Times(2)
+	mockNotifications.EXPECT().Trim(ctx, maxNotifications).Return(nil).Times(2)
+
+	notifier.notifyTask(ctx, Task{ID: "a", Kind: TaskImport, Status: TaskDone, Result: ImportResult{FeedsCreated: 3, FeedsSkipped: 1, FoldersCreated: 2}})
+	notifier.notifyTask(ctx, Task{ID: "b", Kind: TaskSync, Status: TaskError, Error: "primary unreachable"})
+	// Neither running nor cancelled tasks, nor other finished ones, are reported
+	notifier.notifyTask(ctx, Task{ID: "c", Kind: TaskImport, Status: TaskRunning})
+	notifier.notifyTask(ctx, Task{ID: "d", Kind: TaskImport, Status: TaskCancelled})
+	notifier.notifyTask(ctx, Task{ID: "e", Kind: TaskRefresh, Status: TaskDone})
+
+	if len(stored) != 2 {
+		t.Fatalf("expected 2 notifications, got %+v", stored)
+	}
+	if n := stored[0]; n.Kind != model.NotificationImportDone || n.Title != "OPML import finished" || n.Body != "3 feeds added, 1 skipped; 2 folders created" {
+		t.Errorf("unexpected import notification %+v", n)
+	}
+	if n := stored[1]; n.Kind != model.NotificationTaskFailed || n.TaskKind == nil || *n.TaskKind != TaskSync || n.Title != "Sync failed" || n.Body != "primary unreachable" {
+		t.Errorf("unexpected failure notification %+v", n)
+	}
+
+	ev := <-received
+	if data, ok := ev.Data.(events.Notification); ev.Type != events.TypeNotification || !ok || data.ID != 1 {
+		t.Errorf("unexpected event %+v", ev)
+	}
+}
+
+func TestFeedDisabledNotification(t *testing.T) {
+	n := feedDisabledNotification(events.FeedDisabled{FeedID: 7, Title: "Blog", Failures: 10, Error: "HTTP 503"})
+	if n.Kind != model.NotificationFeedDisabled || n.FeedID == nil || *n.FeedID != 7 || n.TaskKind != nil ||
+		n.Title != "Blog disabled" || n.Body != "Disabled after 10 failed refreshes in a row: HTTP 503" {
+		t.Errorf("unexpected notification %+v", n)
+	}
+}
+
+func TestNotificationService_MissingNotification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockNotifications := testutil.NewMockNotificationRepository(ctrl)
+	service := NewNotificationService(mockNotifications, nil)
+	ctx := context.Background()
+
+	mockNotifications.EXPECT().MarkRead(ctx, int64(9)).Return(sql.ErrNoRows)
+	mockNotifications.EXPECT().Delete(ctx, int64(9)).Return(sql.ErrNoRows)
+
+	if err := service.MarkRead(ctx, 9); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := service.Dismiss(ctx, 9); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mmcdole/gofeed"
+
+	"gist/backend/internal/model"
+)
+
+// NSFW display modes of the general settings
+const (
+	NSFWShow = "show"
+	NSFWBlur = "blur"
+	NSFWHide = "hide"
+)
+
+// adultCategories are category names that mark an item or a whole feed as
+// not safe for work, compared case-insensitively.
+var adultCategories = map[string]bool{
+	"nsfw":     true,
+	"adult":    true,
+	"explicit": true,
+	"18+":      true,
+	"r18":      true,
+	"r-18":     true,
+	"porn":     true,
+	"xxx":      true,
+	"hentai":   true,
+}
+
+// feedNSFWReason returns why a whole feed is not safe for work, or "" if it
+// does not say so. Its items inherit the flag.
+func feedNSFWReason(feed *gofeed.Feed) string {
+	if feed == nil {
+		return ""
+	}
+	if feed.ITunesExt != nil && isExplicit(feed.ITunesExt.Explicit) {
+		return model.NSFWExplicit
+	}
+	if hasAdultCategory(feed.Categories) {
+		return model.NSFWCategory
+	}
+	return ""
+}
+
+// markNSFW flags an entry whose item carries an NSFW marker, whose feed does
+// (feedReason), or whose title or categories match one of keywords.
+func markNSFW(entry *model.Entry, item *gofeed.Item, feedReason string, keywords []string) {
+	reason := itemNSFWReason(item)
+	if reason == "" {
+		reason = feedReason
+	}
+	if reason == "" && matchesKeyword(item, keywords) {
+		reason = model.NSFWKeyword
+	}
+	if reason != "" {
+		entry.NSFWReason = &reason
+	}
+}
+
+func itemNSFWReason(item *gofeed.Item) string {
+	if item.ITunesExt != nil && isExplicit(item.ITunesExt.Explicit) {
+		return model.NSFWExplicit
+	}
+	// <media:rating scheme="urn:simple">adult</media:rating>
+	for _, rating := range item.Extensions["media"]["rating"] {
+		if strings.EqualFold(strings.TrimSpace(rating.Value), "adult") {
+			return model.NSFWExplicit
+		}
+	}
+	if hasAdultCategory(item.Categories) {
+		return model.NSFWCategory
+	}
+	return ""
+}
+
+func isExplicit(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "true", "explicit":
+		return true
+	}
+	return false
+}
+
+func hasAdultCategory(categories []string) bool {
+	for _, category := range categories {
+		if adultCategories[strings.ToLower(strings.TrimSpace(category))] {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesKeyword(item *gofeed.Item, keywords []string) bool {
+	if len(keywords) == 0 {
+		return false
+	}
+	texts := append([]string{item.Title}, item.Categories...)
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		for _, keyword := range keywords {
+			if containsKeyword(text, keyword) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsKeyword reports whether lowercase text contains keyword as a whole
+// word, so "ass" does not match "class". CJK text has no word breaks, so a
+// match next to a CJK character always counts.
+func containsKeyword(text, keyword string) bool {
+	for start := 0; start <= len(text); {
+		i := strings.Index(text[start:], keyword)
+		if i < 0 {
+			return false
+		}
+		i += start
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		first, _ := utf8.DecodeRuneInString(keyword)
+		last, _ := utf8.DecodeLastRuneInString(keyword)
+		after, _ := utf8.DecodeRuneInString(text[i+len(keyword):])
+		if !continuesWord(before, first) && !continuesWord(last, after) {
+			return true
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		start = i + size
+	}
+	return false
+}
+
+// continuesWord reports whether two adjacent runes belong to the same word.
+func continuesWord(a, b rune) bool {
+	return isWordRune(a) && isWordRune(b)
+}
+
+func isWordRune(r rune) bool {
+	return (unicode.IsLetter(r) || unicode.IsDigit(r)) && !isCJK(r)
+}
+
+// parseNSFWKeywords splits the keyword setting, one rule per comma or line,
+// into lowercase keywords.
+func parseNSFWKeywords(raw string) []string {
+	var keywords []string
+	for _, field := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == '，' || r == '\n' }) {
+		if keyword := strings.ToLower(strings.TrimSpace(field)); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// nsfwKeywords loads the keyword rules; there are none without settings.
+func nsfwKeywords(ctx context.Context, settings SettingsService) []string {
+	if settings == nil {
+		return nil
+	}
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil {
+		return nil
+	}
+	return parseNSFWKeywords(general.NSFWKeywords)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"time"
+
+	"gist/backend/internal/repository"
+)
+
+// nsfwCheckBatch is how many unchecked images each run sends to the AI provider.
+const nsfwCheckBatch = 50
+
+// visionImageTypes are the image types AI providers accept.
+var visionImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// NSFWCheckService flags entries of picture feeds whose thumbnail the AI
+// provider judges not safe for work, catching what feed markers and keyword
+// rules miss.
+type NSFWCheckService interface {
+	// CheckImages checks the thumbnails of the newest unchecked picture
+	// entries if the check is turned on. Returns how many were flagged.
+	CheckImages(ctx context.Context) (int, error)
+}
+
+type nsfwCheckService struct {
+	entries    repository.EntryRepository
+	settings   SettingsService
+	thumbnails ThumbnailService
+	ai         AIService
+	now        func() time.Time
+}
+
+func NewNSFWCheckService(entries repository.EntryRepository, settings SettingsService, thumbnails ThumbnailService, aiService AIService) NSFWCheckService {
+	return &nsfwCheckService{
+		entries:    entries,
+		settings:   settings,
+		thumbnails: thumbnails,
+		ai:         aiService,
+		now:        time.Now,
+	}
+}
+
+func (s *nsfwCheckService) CheckImages(ctx context.Context) (int, error) {
+	general, err := s.settings.GetGeneralSettings(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("get settings: %w", err)
+	}
+	if !general.NSFWVisionCheck {
+		return 0, nil
+	}
+
+	unchecked, err := s.entries.ListUncheckedImages(ctx, nsfwCheckBatch)
+	if err != nil {
+		return 0, fmt.Errorf("list unchecked images: %w", err)
+	}
+
+	flagged := 0
+	for _, entry := range unchecked {
+		referer := ""
+		if entry.URL != nil {
+			referer = *entry.URL
+		}
+		// The grid thumbnail is small enough to send and usually cached already
+		thumb, err := s.thumbnails.Get(ctx, *entry.ThumbnailURL, referer)
+		if err != nil {
+			if ctx.Err() != nil {
+				return flagged, ctx.Err()
+			}
+			// Nothing to judge; the grid cannot show it either
+			log.Printf("nsfw check entry %d: %v", entry.ID, err)
+			if err := s.entries.SaveNSFWCheck(ctx, entry.ID, false, s.now()); err != nil {
+				return flagged, err
+			}
+			continue
+		}
+
+		isNSFW := false
+		mediaType, _, _ := mime.ParseMediaType(thumb.ContentType)
+		if visionImageTypes[mediaType] {
+			isNSFW, err = s.ai.IsImageNSFW(ctx, thumb.Data, mediaType)
+			if err != nil {
+				// Left unchecked; the provider may not support images or be unavailable
+				return flagged, fmt.Errorf("check entry %d: %w", entry.ID, err)
+			}
+		}
+		if err := s.entries.SaveNSFWCheck(ctx, entry.ID, isNSFW, s.now()); err != nil {
+			return flagged, err
+		}
+		if isNSFW {
+			flagged++
+		}
+	}
+	return flagged, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+type fakeNSFWSettings struct {
+	SettingsService
+	visionCheck bool
+}
+
+func (s *fakeNSFWSettings) GetGeneralSettings(context.Context) (*GeneralSettings, error) {
+	return &GeneralSettings{NSFWVisionCheck: s.visionCheck}, nil
+}
+
+type fakeThumbnails struct {
+	ThumbnailService
+	images map[string]*ProxyResult
+}
+
+func (s *fakeThumbnails) Get(_ context.Context, imageURL, _ string) (*ProxyResult, error) {
+	result, ok := s.images[imageURL]
+	if !ok {
+		return nil, ErrInvalidURL
+	}
+	return result, nil
+}
+
+// fakeVision judges an image NSFW if its data is "nsfw".
+type fakeVision struct {
+	AIService
+	err   error
+	calls int
+}
+
+func (a *fakeVision) IsImageNSFW(_ context.Context, image []byte, _ string) (bool, error) {
+	a.calls++
+	if a.err != nil {
+		return false, a.err
+	}
+	return string(image) == "nsfw", nil
+}
+
+func pictureEntry(id int64, thumbnail string) model.Entry {
+	return model.Entry{ID: id, ThumbnailURL: &thumbnail}
+}
+
+func TestNSFWCheckService_CheckImages(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	thumbnails := &fakeThumbnails{images: map[string]*ProxyResult{
+		"https://cdn.example.com/1.jpg": {Data: []byte("nsfw"), ContentType: "image/jpeg"},
+		"https://cdn.example.com/2.jpg": {Data: []byte("safe"), ContentType: "image/jpeg"},
+		"https://cdn.example.com/3.svg": {Data: []byte("nsfw"), ContentType: "image/svg+xml"},
+	}}
+	vision := &fakeVision{}
+	service := NewNSFWCheckService(entries, &fakeNSFWSettings{visionCheck: true}, thumbnails, vision)
+	ctx := context.Background()
+
+	entries.EXPECT().ListUncheckedImages(ctx, nsfwCheckBatch).Return([]model.Entry{
+		pictureEntry(1, "https://cdn.example.com/1.jpg"),
+		pictureEntry(2, "https://cdn.example.com/2.jpg"),
+		pictureEntry(3, "https://cdn.example.com/3.svg"),
+		pictureEntry(4, "https://cdn.example.com/missing.jpg"),
+	}, nil)
+	entries.EXPECT().SaveNSFWCheck(ctx, int64(1), true, gomock.Any()).Return(nil)
+	entries.EXPECT().SaveNSFWCheck(ctx, int64(2), false, gomock.Any()).Return(nil)
+	// Unsupported and unavailable images are marked checked so they are not retried
+	entries.EXPECT().SaveNSFWCheck(ctx, int64(3), false, gomock.Any()).Return(nil)
+	entries.EXPECT().SaveNSFWCheck(ctx, int64(4), false, gomock.Any()).Return(nil)
+
+	flagged, err := service.CheckImages(ctx)
+	if err != nil {
+		t.Fatalf("check images: %v", err)
+	}
+	if flagged != 1 {
+		t.Errorf("expected 1 flagged, got %d", flagged)
+	}
+	if vision.calls != 2 {
+		t.Errorf("expected 2 vision calls, got %d", vision.calls)
+	}
+}
+
+func TestNSFWCheckService_CheckImages_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	service := NewNSFWCheckService(entries, &fakeNSFWSettings{}, &fakeThumbnails{}, &fakeVision{})
+
+	flagged, err := service.CheckImages(context.Background())
+	if err != nil || flagged != 0 {
+		t.Errorf("expected nothing to happen, got %d, %v", flagged, err)
+	}
+}
+
+func TestNSFWCheckService_CheckImages_ProviderError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	thumbnails := &fakeThumbnails{images: map[string]*ProxyResult{
+		"https://cdn.example.com/1.jpg": {Data: []byte("safe"), ContentType: "image/jpeg"},
+	}}
+	providerErr := errors.New("images not supported")
+	service := NewNSFWCheckService(entries, &fakeNSFWSettings{visionCheck: true}, thumbnails, &fakeVision{err: providerErr})
+	ctx := context.Background()
+
+	entries.EXPECT().ListUncheckedImages(ctx, nsfwCheckBatch).Return([]model.Entry{
+		pictureEntry(1, "https://cdn.example.com/1.jpg"),
+		pictureEntry(2, "https://cdn.example.com/1.jpg"),
+	}, nil)
+
+	// The entry stays unchecked so a later run retries it
+	if _, err := service.CheckImages(ctx); !errors.Is(err, providerErr) {
+		t.Errorf("expected provider error, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+	"github.com/mmcdole/gofeed/extensions"
+
+	"gist/backend/internal/model"
+)
+
+func TestMarkNSFW(t *testing.T) {
+	keywords := parseNSFWKeywords("Ass, 成人\n  ,lewd")
+	if want := []string{"ass", "成人", "lewd"}; !reflect.DeepEqual(keywords, want) {
+		t.Fatalf("expected keywords %v, got %v", want, keywords)
+	}
+
+	tests := []struct {
+		name       string
+		item       *gofeed.Item
+		feedReason string
+		want       string
+	}{
+		{"plain", &gofeed.Item{Title: "Sunset", Categories: []string{"Landscape"}}, "", ""},
+		{"category", &gofeed.Item{Title: "Set 12", Categories: []string{" NSFW "}}, "", model.NSFWCategory},
+		{"itunes explicit", &gofeed.Item{Title: "Episode 3", ITunesExt: &ext.ITunesItemExtension{Explicit: "Yes"}}, "", model.NSFWExplicit},
+		{"itunes clean", &gofeed.Item{Title: "Episode 4", ITunesExt: &ext.ITunesItemExtension{Explicit: "no"}}, "", ""},
+		{"media rating", &gofeed.Item{Title: "Set 13", Extensions: ext.Extensions{
+			"media": {"rating": {{Value: "adult", Attrs: map[string]string{"scheme": "urn:simple"}}}},
+		}}, "", model.NSFWExplicit},
+		{"feed marker", &gofeed.Item{Title: "Anything"}, model.NSFWCategory, model.NSFWCategory},
+		{"item marker wins over feed", &gofeed.Item{Title: "Episode", ITunesExt: &ext.ITunesItemExtension{Explicit: "true"}}, model.NSFWCategory, model.NSFWExplicit},
+		{"keyword in title", &gofeed.Item{Title: "A lewd joke"}, "", model.NSFWKeyword},
+		{"keyword in category", &gofeed.Item{Title: "Set 14", Categories: []string{"Lewd"}}, "", model.NSFWKeyword},
+		{"keyword inside a word", &gofeed.Item{Title: "First class passengers"}, "", ""},
+		{"CJK keyword", &gofeed.Item{Title: "成人向け写真集"}, "", model.NSFWKeyword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entry model.Entry
+			markNSFW(&entry, tt.item, tt.feedReason, keywords)
+			got := ""
+			if entry.NSFWReason != nil {
+				got = *entry.NSFWReason
+			}
+			if got != tt.want {
+				t.Errorf("expected reason %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFeedNSFWReason(t *testing.T) {
+	if got := feedNSFWReason(&gofeed.Feed{ITunesExt: &ext.ITunesFeedExtension{Explicit: "explicit"}}); got != model.NSFWExplicit {
+		t.Errorf("expected explicit, got %q", got)
+	}
+	if got := feedNSFWReason(&gofeed.Feed{Categories: []string{"Photography", "R18"}}); got != model.NSFWCategory {
+		t.Errorf("expected category, got %q", got)
+	}
+	if got := feedNSFWReason(&gofeed.Feed{Categories: []string{"Photography"}}); got != "" {
+		t.Errorf("expected no reason, got %q", got)
+	}
+}
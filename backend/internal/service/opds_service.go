@@ -0,0 +1,199 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gist/backend/internal/epub"
+	"gist/backend/internal/model"
+	"gist/backend/internal/opds"
+	"gist/backend/internal/repository"
+)
+
+const (
+	// opdsPageSize is how many entries each catalog page lists.
+	opdsPageSize = 50
+	// OPDSPath is where the catalog is served; its links are absolute paths.
+	OPDSPath = "/api/opds"
+)
+
+// OPDSService exposes starred entries as an OPDS catalog, so e-reader apps
+// such as KOReader can browse them and download each one as an EPUB book.
+type OPDSService interface {
+	// Starred returns page (from 1) of the starred-entries catalog feed.
+	Starred(ctx context.Context, page int) ([]byte, error)
+	// EPUB converts an entry to an EPUB book, preferring its readable content.
+	// Returns the book and a file name for it.
+	EPUB(ctx context.Context, id int64) ([]byte, string, error)
+}
+
+type opdsService struct {
+	entries repository.EntryRepository
+	feeds   repository.FeedRepository
+	now     func() time.Time
+}
+
+func NewOPDSService(entries repository.EntryRepository, feeds repository.FeedRepository) OPDSService {
+	return &opdsService{entries: entries, feeds: feeds, now: time.Now}
+}
+
+func (s *opdsService) Starred(ctx context.Context, page int) ([]byte, error) {
+	if page < 1 {
+		return nil, ErrInvalid
+	}
+	// Fetch one more than a page to know whether another follows
+	entries, err := s.entries.List(ctx, repository.EntryListFilter{
+		StarredOnly: true,
+		Limit:       opdsPageSize + 1,
+		Offset:      (page - 1) * opdsPageSize,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list starred entries: %w", err)
+	}
+	hasMore := len(entries) > opdsPageSize
+	if hasMore {
+		entries = entries[:opdsPageSize]
+	}
+	feedTitles, err := s.feedTitles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := opds.Feed{
+		ID:      "urn:gist:starred",
+		Title:   "Gist · Starred",
+		Updated: s.now().UTC().Format(time.RFC3339),
+		Links: []opds.Link{
+			{Rel: "self", Type: opds.AcquisitionType, Href: opdsPageHref(page)},
+			{Rel: "start", Type: opds.AcquisitionType, Href: OPDSPath},
+		},
+	}
+	if page > 1 {
+		feed.Links = append(feed.Links, opds.Link{Rel: "previous", Type: opds.AcquisitionType, Href: opdsPageHref(page - 1)})
+	}
+	if hasMore {
+		feed.Links = append(feed.Links, opds.Link{Rel: "next", Type: opds.AcquisitionType, Href: opdsPageHref(page + 1)})
+	}
+	for _, entry := range entries {
+		feed.Entries = append(feed.Entries, opdsEntry(entry, feedTitles[entry.FeedID]))
+	}
+
+	payload, err := opds.Encode(feed)
+	if err != nil {
+		return nil, fmt.Errorf("encode opds: %w", err)
+	}
+	return payload, nil
+}
+
+func (s *opdsService) EPUB(ctx context.Context, id int64) ([]byte, string, error) {
+	entry, err := s.entries.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", ErrNotFound
+		}
+		return nil, "", err
+	}
+	feedTitle := ""
+	if feed, err := s.feeds.GetByID(ctx, entry.FeedID); err == nil {
+		feedTitle = feed.Title
+	}
+
+	content := ""
+	if entry.ReadableContent != nil && *entry.ReadableContent != "" {
+		content = *entry.ReadableContent
+	} else if entry.Content != nil {
+		content = *entry.Content
+	}
+	book := epub.Book{
+		ID:        opdsEntryID(entry.ID),
+		Title:     trimmedValue(entry.Title),
+		Author:    trimmedValue(entry.Author),
+		Publisher: feedTitle,
+		SourceURL: trimmedValue(entry.URL),
+		Published: entry.PublishedAt,
+		Modified:  entry.UpdatedAt,
+		Content:   content,
+	}
+
+	var buf bytes.Buffer
+	if err := epub.Write(&buf, book); err != nil {
+		return nil, "", fmt.Errorf("write epub: %w", err)
+	}
+	return buf.Bytes(), epubFileName(book.Title, entry.ID), nil
+}
+
+func (s *opdsService) feedTitles(ctx context.Context) (map[int64]string, error) {
+	feeds, err := s.feeds.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list feeds: %w", err)
+	}
+	titles := make(map[int64]string, len(feeds))
+	for _, feed := range feeds {
+		titles[feed.ID] = feed.Title
+	}
+	return titles, nil
+}
+
+func opdsEntry(entry model.EntrySummary, feedTitle string) opds.Entry {
+	title := trimmedValue(entry.Title)
+	if title == "" {
+		title = "Untitled"
+	}
+	e := opds.Entry{
+		ID:      opdsEntryID(entry.ID),
+		Title:   title,
+		Updated: entry.UpdatedAt.UTC().Format(time.RFC3339),
+		Links: []opds.Link{
+			{Rel: opds.RelAcquisition, Type: opds.EPUBType, Href: fmt.Sprintf("%s/entries/%d.epub", OPDSPath, entry.ID)},
+		},
+	}
+	// Readers list the author under the title; fall back to the feed
+	if author := trimmedValue(entry.Author); author != "" {
+		e.Authors = []opds.Name{{Name: author}}
+	} else if feedTitle != "" {
+		e.Authors = []opds.Name{{Name: feedTitle}}
+	}
+	if entry.PublishedAt != nil {
+		e.Issued = entry.PublishedAt.UTC().Format("2006-01-02")
+	}
+	if snippet := trimmedValue(entry.Snippet); snippet != "" {
+		e.Summary = &opds.Text{Type: "text", Value: snippet}
+	}
+	if url := trimmedValue(entry.URL); url != "" {
+		e.Links = append(e.Links, opds.Link{Rel: "alternate", Type: "text/html", Href: url})
+	}
+	return e
+}
+
+func opdsEntryID(id int64) string {
+	return "urn:gist:entry:" + strconv.FormatInt(id, 10)
+}
+
+func opdsPageHref(page int) string {
+	if page == 1 {
+		return OPDSPath
+	}
+	return OPDSPath + "?page=" + strconv.Itoa(page)
+}
+
+// unsafeFileChars are the characters replaced in EPUB file names.
+var unsafeFileChars = regexp.MustCompile(`[\x00-\x1f/\\:*?"<>|]+`)
+
+// epubFileName names the book after its title, falling back to the entry ID.
+func epubFileName(title string, id int64) string {
+	name := strings.Join(strings.Fields(unsafeFileChars.ReplaceAllString(title, " ")), " ")
+	if runes := []rune(name); len(runes) > 80 {
+		name = strings.TrimSpace(string(runes[:80]))
+	}
+	if name == "" {
+		name = "entry-" + strconv.FormatInt(id, 10)
+	}
+	return name + ".epub"
+}
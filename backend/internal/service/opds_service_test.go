@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/opds"
+	"gist/backend/internal/repository"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestOPDSService_Starred(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewOPDSService(entries, feeds)
+	ctx := context.Background()
+
+	page := make([]model.EntrySummary, opdsPageSize+1)
+	for i := range page {
+		page[i] = model.EntrySummary{ID: int64(i + 1), FeedID: 7, UpdatedAt: time.Now()}
+	}
+	title, author := "Saved article", "Ann"
+	page[0].Title = &title
+	page[1].Author = &author
+
+	entries.EXPECT().List(ctx, repository.EntryListFilter{
+		StarredOnly: true,
+		Limit:       opdsPageSize + 1,
+		Offset:      opdsPageSize,
+	}).Return(page, nil)
+	feeds.EXPECT().List(ctx, nil).Return([]model.Feed{{ID: 7, Title: "Blog"}}, nil)
+
+	payload, err := service.Starred(ctx, 2)
+	if err != nil {
+		t.Fatalf("starred: %v", err)
+	}
+	var feed opds.Feed
+	if err := xml.Unmarshal(payload, &feed); err != nil {
+		t.Fatalf("parse feed: %v\n%s", err, payload)
+	}
+
+	if len(feed.Entries) != opdsPageSize {
+		t.Fatalf("expected %d entries, got %d", opdsPageSize, len(feed.Entries))
+	}
+	rels := make(map[string]string)
+	for _, link := range feed.Links {
+		rels[link.Rel] = link.Href
+	}
+	if rels["self"] != OPDSPath+"?page=2" || rels["previous"] != OPDSPath || rels["next"] != OPDSPath+"?page=3" {
+		t.Errorf("unexpected paging links %v", rels)
+	}
+
+	first, second := feed.Entries[0], feed.Entries[1]
+	if first.Title != title || first.Links[0].Rel != opds.RelAcquisition || first.Links[0].Href != OPDSPath+"/entries/1.epub" {
+		t.Errorf("unexpected first entry %+v", first)
+	}
+	// Without an author the feed stands in
+	if first.Authors[0].Name != "Blog" || second.Authors[0].Name != author {
+		t.Errorf("unexpected authors %+v and %+v", first.Authors, second.Authors)
+	}
+}
+
+func TestOPDSService_EPUB(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewOPDSService(entries, feeds)
+	ctx := context.Background()
+
+	title, content := "Fish/Chips: a review", "<p>Tasty</p>"
+	entries.EXPECT().GetByID(ctx, int64(1)).Return(model.Entry{ID: 1, FeedID: 7, Title: &title, Content: &content}, nil)
+	feeds.EXPECT().GetByID(ctx, int64(7)).Return(model.Feed{ID: 7, Title: "Blog"}, nil)
+
+	book, name, err := service.EPUB(ctx, 1)
+	if err != nil {
+		t.Fatalf("epub: %v", err)
+	}
+	if name != "Fish Chips a review.epub" {
+		t.Errorf("unexpected file name %q", name)
+	}
+	if len(book) == 0 {
+		t.Error("expected a book")
+	}
+
+	entries.EXPECT().GetByID(ctx, int64(2)).Return(model.Entry{}, sql.ErrNoRows)
+	if _, _, err := service.EPUB(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -15,7 +15,15 @@ import (
 )
 
 type OPMLService interface {
-	Import(ctx context.Context, reader io.Reader, onProgress func(ImportProgress)) (ImportResult, error)
+	// Parse decodes an OPML document from a stream without buffering it whole.
+	Parse(reader io.Reader) (opml.Document, error)
+	// Import creates the document's folders and feeds, recording them under taskID for Undo.
+	// An import resumed after an interruption passes where it stopped.
+	Import(ctx context.Context, taskID string, doc opml.Document, resume ImportResume, onProgress func(ImportProgress)) (ImportResult, error)
+	// Undo removes the folders and feeds created by the import task.
+	Undo(ctx context.Context, taskID string) (ImportUndoResult, error)
+	// Preview reports what Import would do with doc without writing anything.
+	Preview(ctx context.Context, doc opml.Document) (ImportPreview, error)
 	Export(ctx context.Context) ([]byte, error)
 }
 
@@ -31,6 +39,51 @@ type ImportProgress struct {
 	Current int    `json:"current"`
 	Feed    string `json:"feed,omitempty"`
 	Status  string `json:"status"` // "started", "importing", "done", "error"
+	// Result counts the feeds before Current and the folders so far
+	Result ImportResult `json:"result"`
+}
+
+// ImportResume is where an interrupted import left off: the feeds it had
+// finished, in document order, and what they came to.
+type ImportResume struct {
+	Processed int
+	Result    ImportResult
+}
+
+// ImportUndoResult reports what an undo removed. Folders that have since
+// received feeds or subfolders from elsewhere are kept.
+type ImportUndoResult struct {
+	FeedsDeleted   int `json:"feedsDeleted"`
+	FoldersDeleted int `json:"foldersDeleted"`
+	FoldersKept    int `json:"foldersKept"`
+}
+
+// ImportPreview describes the outcome of an import without performing it.
+type ImportPreview struct {
+	Summary       ImportResult          `json:"summary"`
+	Folders       []ImportPreviewFolder `json:"folders"`       // folders that would be created
+	Feeds         []ImportPreviewFeed   `json:"feeds"`         // feeds that would be created
+	Duplicates    []ImportPreviewFeed   `json:"duplicates"`    // already subscribed or repeated in the file
+	TypeConflicts []ImportTypeConflict  `json:"typeConflicts"` // duplicates whose existing type differs from the target folder
+	Invalid       []ImportPreviewFeed   `json:"invalid"`       // missing or malformed feed URLs
+}
+
+type ImportPreviewFolder struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+type ImportPreviewFeed struct {
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Folder string `json:"folder,omitempty"`
+	Type   string `json:"type"`
+}
+
+type ImportTypeConflict struct {
+	ImportPreviewFeed
+	ExistingType   string `json:"existingType"`
+	ExistingFolder string `json:"existingFolder,omitempty"`
 }
 
 type opmlService struct {
@@ -38,6 +91,7 @@ type opmlService struct {
 	feedService   FeedService
 	folders       repository.FolderRepository
 	feeds         repository.FeedRepository
+	importItems   repository.ImportItemRepository
 }
 
 func NewOPMLService(
@@ -45,33 +99,49 @@ func NewOPMLService(
 	feedService FeedService,
 	folders repository.FolderRepository,
 	feeds repository.FeedRepository,
+	importItems repository.ImportItemRepository,
 ) OPMLService {
 	return &opmlService{
 		folderService: folderService,
 		feedService:   feedService,
 		folders:       folders,
 		feeds:         feeds,
+		importItems:   importItems,
 	}
 }
 
-func (s *opmlService) Import(ctx context.Context, reader io.Reader, onProgress func(ImportProgress)) (ImportResult, error) {
+func (s *opmlService) Parse(reader io.Reader) (opml.Document, error) {
 	doc, err := opml.Parse(reader)
 	if err != nil {
-		return ImportResult{}, ErrInvalid
+		// Keep the cause so callers can tell size-limit errors from malformed XML
+		return opml.Document{}, fmt.Errorf("%w: %w", ErrInvalid, err)
 	}
+	return doc, nil
+}
 
+func (s *opmlService) Import(ctx context.Context, taskID string, doc opml.Document, resume ImportResume, onProgress func(ImportProgress)) (ImportResult, error) {
 	// Count total feeds
-	total := countFeeds(doc.Body.Outlines)
+	total := doc.CountFeeds()
 
 	// Send started progress
 	if onProgress != nil {
-		onProgress(ImportProgress{Total: total, Current: 0, Status: "started"})
+		onProgress(ImportProgress{Total: total, Current: resume.Processed, Status: "started", Result: resume.Result})
 	}
 
-	result := ImportResult{}
+	folders, err := s.folders.List(ctx)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("list folders: %w", err)
+	}
+	// knownFolders holds the folders that existed before the import or that
+	// it created, to tell the category folders Add creates from them
+	knownFolders := make(map[int64]bool, len(folders))
+	for _, folder := range folders {
+		knownFolders[folder.ID] = true
+	}
+	result := resume.Result
 	current := 0
 	for _, outline := range doc.Body.Outlines {
-		if err := s.importOutline(ctx, outline, nil, "article", &result, &current, total, onProgress); err != nil {
+		if err := s.importOutline(ctx, taskID, outline, nil, "article", knownFolders, &result, &current, resume.Processed, total, onProgress); err != nil {
 			return result, err
 		}
 	}
@@ -79,16 +149,263 @@ func (s *opmlService) Import(ctx context.Context, reader io.Reader, onProgress f
 	return result, nil
 }
 
-func countFeeds(outlines []opml.Outline) int {
-	count := 0
-	for _, outline := range outlines {
-		if isFeedOutline(outline) {
-			count++
+func (s *opmlService) Undo(ctx context.Context, taskID string) (ImportUndoResult, error) {
+	items, err := s.importItems.ListByTask(ctx, taskID)
+	if err != nil {
+		return ImportUndoResult{}, err
+	}
+	if len(items) == 0 {
+		return ImportUndoResult{}, ErrNotFound
+	}
+
+	var feedIDs, folderIDs []int64
+	for _, item := range items {
+		switch item.ItemType {
+		case model.ImportItemFeed:
+			feedIDs = append(feedIDs, item.ItemID)
+		case model.ImportItemFolder:
+			folderIDs = append(folderIDs, item.ItemID)
+		}
+	}
+
+	result := ImportUndoResult{}
+	if len(feedIDs) > 0 {
+		// Feeds the user already deleted are simply not counted
+		deleted, err := s.feeds.DeleteBatch(ctx, feedIDs)
+		if err != nil {
+			return result, fmt.Errorf("delete imported feeds: %w", err)
+		}
+		result.FeedsDeleted = int(deleted)
+	}
+
+	if len(folderIDs) > 0 {
+		folders, err := s.folders.List(ctx)
+		if err != nil {
+			return result, fmt.Errorf("list folders: %w", err)
+		}
+		parentByID := make(map[int64]*int64, len(folders))
+		childCount := make(map[int64]int)
+		for _, folder := range folders {
+			parentByID[folder.ID] = folder.ParentID
+			if folder.ParentID != nil {
+				childCount[*folder.ParentID]++
+			}
+		}
+
+		// Snowflake IDs grow with creation time, so walking backwards removes
+		// subfolders before their parents
+		for i := len(folderIDs) - 1; i >= 0; i-- {
+			id := folderIDs[i]
+			parentID, exists := parentByID[id]
+			if !exists {
+				continue
+			}
+			remaining, err := s.feeds.List(ctx, &id)
+			if err != nil {
+				return result, fmt.Errorf("list feeds in folder: %w", err)
+			}
+			if len(remaining) > 0 || childCount[id] > 0 {
+				result.FoldersKept++
+				continue
+			}
+			if err := s.folders.Delete(ctx, id); err != nil {
+				return result, err
+			}
+			if parentID != nil {
+				childCount[*parentID]--
+			}
+			result.FoldersDeleted++
+		}
+	}
+
+	if err := s.importItems.DeleteByTask(ctx, taskID); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// previewScope is the folder an outline would be imported into during a preview.
+type previewScope struct {
+	folderID   *int64 // existing folder; nil at the root or inside a folder that would be created
+	isNew      bool
+	path       string
+	folderType string
+}
+
+func (s *opmlService) Preview(ctx context.Context, doc opml.Document) (ImportPreview, error) {
+	folders, err := s.folders.List(ctx)
+	if err != nil {
+		return ImportPreview{}, fmt.Errorf("list folders: %w", err)
+	}
+	folderPaths := buildFolderPaths(folders)
+
+	preview := ImportPreview{
+		Folders:       []ImportPreviewFolder{},
+		Feeds:         []ImportPreviewFeed{},
+		Duplicates:    []ImportPreviewFeed{},
+		TypeConflicts: []ImportTypeConflict{},
+		Invalid:       []ImportPreviewFeed{},
+	}
+	seen := make(map[string]bool)
+	root := previewScope{folderType: "article"}
+	for _, outline := range doc.Body.Outlines {
+		if err := s.previewOutline(ctx, outline, root, folderPaths, seen, &preview); err != nil {
+			return ImportPreview{}, err
+		}
+	}
+	return preview, nil
+}
+
+func (s *opmlService) previewOutline(
+	ctx context.Context,
+	outline opml.Outline,
+	scope previewScope,
+	folderPaths map[int64]string,
+	seen map[string]bool,
+	preview *ImportPreview,
+) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if outline.IsFeed() {
+		return s.previewFeed(ctx, outline, scope, folderPaths, seen, preview)
+	}
+
+	name := pickOutlineTitle(outline)
+	if strings.TrimSpace(name) == "" {
+		name = "Untitled"
+	}
+	newType := outlineTypeHint(outline)
+	if newType == "" {
+		newType = "article"
+	}
+	child := previewScope{isNew: true, path: joinFolderPath(scope.path, name), folderType: newType}
+	if !scope.isNew {
+		existing, err := s.folders.FindByName(ctx, name, scope.folderID)
+		if err != nil {
+			return fmt.Errorf("find folder: %w", err)
+		}
+		if existing != nil {
+			child = previewScope{folderID: &existing.ID, path: child.path, folderType: existing.Type}
+		}
+	}
+
+	if child.isNew {
+		// Sibling outlines with the same name merge into one folder, as in Import
+		duplicate := false
+		for _, folder := range preview.Folders {
+			if folder.Path == child.path {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			preview.Folders = append(preview.Folders, ImportPreviewFolder{Path: child.path, Type: child.folderType})
+			preview.Summary.FoldersCreated++
 		} else {
-			count += countFeeds(outline.Outlines)
+			preview.Summary.FoldersSkipped++
+		}
+	} else {
+		preview.Summary.FoldersSkipped++
+	}
+
+	for _, sub := range outline.Outlines {
+		if err := s.previewOutline(ctx, sub, child, folderPaths, seen, preview); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *opmlService) previewFeed(
+	ctx context.Context,
+	outline opml.Outline,
+	scope previewScope,
+	folderPaths map[int64]string,
+	seen map[string]bool,
+	preview *ImportPreview,
+) error {
+	feedURL := strings.TrimSpace(outline.XMLURL)
+	title := strings.TrimSpace(outline.Title)
+	if title == "" {
+		title = strings.TrimSpace(outline.Text)
+	}
+	item := ImportPreviewFeed{Title: title, URL: feedURL, Folder: scope.path, Type: scope.folderType}
+	if hint := outlineTypeHint(outline); hint != "" {
+		item.Type = hint
+	}
+
+	if feedURL == "" || !isValidURL(feedURL) {
+		preview.Invalid = append(preview.Invalid, item)
+		preview.Summary.FeedsSkipped++
+		return nil
+	}
+	if seen[feedURL] {
+		preview.Duplicates = append(preview.Duplicates, item)
+		preview.Summary.FeedsSkipped++
+		return nil
+	}
+	seen[feedURL] = true
+
+	existing, err := s.feeds.FindByURL(ctx, feedURL)
+	if err != nil {
+		return fmt.Errorf("check feed url: %w", err)
+	}
+	if existing == nil {
+		preview.Feeds = append(preview.Feeds, item)
+		preview.Summary.FeedsCreated++
+		return nil
+	}
+
+	preview.Duplicates = append(preview.Duplicates, item)
+	preview.Summary.FeedsSkipped++
+	if existing.Type != item.Type {
+		conflict := ImportTypeConflict{ImportPreviewFeed: item, ExistingType: existing.Type}
+		if existing.FolderID != nil {
+			conflict.ExistingFolder = folderPaths[*existing.FolderID]
+		}
+		preview.TypeConflicts = append(preview.TypeConflicts, conflict)
+	}
+	return nil
+}
+
+// buildFolderPaths maps folder IDs to slash-separated paths from the root.
+func buildFolderPaths(folders []model.Folder) map[int64]string {
+	byID := make(map[int64]model.Folder, len(folders))
+	for _, folder := range folders {
+		byID[folder.ID] = folder
+	}
+
+	paths := make(map[int64]string, len(folders))
+	var resolve func(id int64, depth int) string
+	resolve = func(id int64, depth int) string {
+		if path, ok := paths[id]; ok {
+			return path
+		}
+		folder, ok := byID[id]
+		if !ok {
+			return ""
 		}
+		path := folder.Name
+		// Depth guard protects against cycles in corrupted data
+		if folder.ParentID != nil && depth < len(folders) {
+			path = joinFolderPath(resolve(*folder.ParentID, depth+1), folder.Name)
+		}
+		paths[id] = path
+		return path
+	}
+	for _, folder := range folders {
+		resolve(folder.ID, 0)
+	}
+	return paths
+}
+
+func joinFolderPath(parent, name string) string {
+	if parent == "" {
+		return name
 	}
-	return count
+	return parent + "/" + name
 }
 
 func (s *opmlService) Export(ctx context.Context) ([]byte, error) {
@@ -105,6 +422,7 @@ func (s *opmlService) Export(ctx context.Context) ([]byte, error) {
 	date := time.Now().UTC().Format(time.RFC1123Z)
 	doc := opml.Document{
 		Version: "2.0",
+		GistNS:  opml.Namespace,
 		Head: opml.Head{
 			Title:        "Gist Subscriptions",
 			DateCreated:  date,
@@ -122,11 +440,14 @@ func (s *opmlService) Export(ctx context.Context) ([]byte, error) {
 
 func (s *opmlService) importOutline(
 	ctx context.Context,
+	taskID string,
 	outline opml.Outline,
 	parentID *int64,
 	folderType string,
+	knownFolders map[int64]bool,
 	result *ImportResult,
 	current *int,
+	skip int,
 	total int,
 	onProgress func(ImportProgress),
 ) error {
@@ -135,24 +456,33 @@ func (s *opmlService) importOutline(
 		return ctx.Err()
 	}
 
-	if isFeedOutline(outline) {
-		return s.importFeed(ctx, outline, parentID, folderType, result, current, total, onProgress)
+	if outline.IsFeed() {
+		return s.importFeed(ctx, taskID, outline, parentID, folderType, knownFolders, result, current, skip, total, onProgress)
 	}
 
 	folderName := pickOutlineTitle(outline)
-	folder, created, err := s.ensureFolder(ctx, folderName, parentID)
+	newType := outlineTypeHint(outline)
+	if newType == "" {
+		newType = "article"
+	}
+	folder, created, err := s.ensureFolder(ctx, folderName, parentID, newType)
 	if err != nil {
 		return err
 	}
 	if created {
+		if err := s.importItems.Add(ctx, taskID, model.ImportItemFolder, folder.ID); err != nil {
+			return err
+		}
+		knownFolders[folder.ID] = true
 		result.FoldersCreated++
-	} else {
+	} else if *current >= skip {
+		// Folders before the resume point were counted by the first run
 		result.FoldersSkipped++
 	}
 
 	for _, child := range outline.Outlines {
 		// Use the folder's actual type (may differ from parent if folder already existed)
-		if err := s.importOutline(ctx, child, &folder.ID, folder.Type, result, current, total, onProgress); err != nil {
+		if err := s.importOutline(ctx, taskID, child, &folder.ID, folder.Type, knownFolders, result, current, skip, total, onProgress); err != nil {
 			return err
 		}
 	}
@@ -160,7 +490,8 @@ func (s *opmlService) importOutline(
 	return nil
 }
 
-func (s *opmlService) ensureFolder(ctx context.Context, name string, parentID *int64) (model.Folder, bool, error) {
+// ensureFolder finds the named folder or creates it with folderType.
+func (s *opmlService) ensureFolder(ctx context.Context, name string, parentID *int64, folderType string) (model.Folder, bool, error) {
 	if strings.TrimSpace(name) == "" {
 		name = "Untitled"
 	}
@@ -173,7 +504,7 @@ func (s *opmlService) ensureFolder(ctx context.Context, name string, parentID *i
 	}
 
 	// Create new folder using FolderService
-	folder, err := s.folderService.Create(ctx, name, parentID, "article")
+	folder, err := s.folderService.Create(ctx, name, parentID, folderType)
 	if err != nil {
 		if errors.Is(err, ErrConflict) {
 			// Race condition: folder was created between check and create
@@ -188,11 +519,14 @@ func (s *opmlService) ensureFolder(ctx context.Context, name string, parentID *i
 
 func (s *opmlService) importFeed(
 	ctx context.Context,
+	taskID string,
 	outline opml.Outline,
 	folderID *int64,
 	folderType string,
+	knownFolders map[int64]bool,
 	result *ImportResult,
 	current *int,
+	skip int,
 	total int,
 	onProgress func(ImportProgress),
 ) error {
@@ -202,14 +536,20 @@ func (s *opmlService) importFeed(
 		title = strings.TrimSpace(outline.Text)
 	}
 
-	// Send progress before importing
+	// Feeds up to the resume point were imported before the interruption
 	*current++
+	if *current <= skip {
+		return nil
+	}
+
+	// Send progress before importing
 	if onProgress != nil {
 		onProgress(ImportProgress{
 			Total:   total,
 			Current: *current,
 			Feed:    title,
 			Status:  "importing",
+			Result:  *result,
 		})
 	}
 
@@ -219,8 +559,12 @@ func (s *opmlService) importFeed(
 	}
 
 	// Use FeedService.Add to create feed (will fetch and refresh automatically)
-	// Feed inherits type from its parent folder
-	_, err := s.feedService.Add(ctx, feedURL, folderID, title, folderType)
+	// Feed inherits type from its parent folder unless the outline names one
+	feedType := outlineTypeHint(outline)
+	if feedType == "" {
+		feedType = folderType
+	}
+	feed, err := s.feedService.Add(ctx, feedURL, folderID, title, feedType, nil, nil)
 	if err != nil {
 		if errors.Is(err, ErrConflict) {
 			// Feed already exists
@@ -229,17 +573,61 @@ func (s *opmlService) importFeed(
 		}
 		return fmt.Errorf("add feed %s: %w", feedURL, err)
 	}
+	// A feed outside any folder may have been filed into a new folder named
+	// after its category, which Undo should remove too
+	if folderID == nil && feed.FolderID != nil && !knownFolders[*feed.FolderID] {
+		if err := s.importItems.Add(ctx, taskID, model.ImportItemFolder, *feed.FolderID); err != nil {
+			return err
+		}
+		knownFolders[*feed.FolderID] = true
+		result.FoldersCreated++
+	}
+	if err := s.importItems.Add(ctx, taskID, model.ImportItemFeed, feed.ID); err != nil {
+		return err
+	}
 
 	result.FeedsCreated++
 	return nil
 }
 
-func isFeedOutline(outline opml.Outline) bool {
-	if strings.TrimSpace(outline.XMLURL) != "" {
-		return true
+// categoryTypeHints maps OPML category keywords to the content type they
+// suggest, for files from readers that don't write gist:type.
+var categoryTypeHints = map[string]string{
+	"picture":       "picture",
+	"pictures":      "picture",
+	"photo":         "picture",
+	"photos":        "picture",
+	"image":         "picture",
+	"images":        "picture",
+	"gallery":       "picture",
+	"notification":  "notification",
+	"notifications": "notification",
+	"alert":         "notification",
+	"alerts":        "notification",
+	"podcast":       "podcast",
+	"podcasts":      "podcast",
+	"audio":         "podcast",
+}
+
+// outlineTypeHint returns the content type an outline asks for, or "" if it
+// names none. gist:type wins over a type attribute holding a content type,
+// which wins over category keywords.
+func outlineTypeHint(outline opml.Outline) string {
+	for _, value := range []string{outline.GistType, outline.Type} {
+		switch t := strings.ToLower(strings.TrimSpace(value)); t {
+		case "article", "picture", "notification", "podcast":
+			return t
+		}
 	}
-	feedType := strings.ToLower(strings.TrimSpace(outline.Type))
-	return feedType == "rss" || feedType == "atom" || feedType == "feed"
+
+	for _, category := range strings.Split(outline.Category, ",") {
+		for _, segment := range strings.Split(category, "/") {
+			if hint, ok := categoryTypeHints[strings.ToLower(strings.TrimSpace(segment))]; ok {
+				return hint
+			}
+		}
+	}
+	return ""
 }
 
 func pickOutlineTitle(outline opml.Outline) string {
@@ -277,6 +665,9 @@ func buildExportOutlines(folders []model.Folder, feeds []model.Feed) []opml.Outl
 
 	var rootFeeds []model.Feed
 	for _, feed := range feeds {
+		if feed.IsVirtual() {
+			continue
+		}
 		if feed.FolderID == nil {
 			rootFeeds = append(rootFeeds, feed)
 			continue
@@ -315,8 +706,9 @@ func buildFolderOutline(node *folderNode) opml.Outline {
 	})
 
 	outline := opml.Outline{
-		Text:  node.folder.Name,
-		Title: node.folder.Name,
+		Text:     node.folder.Name,
+		Title:    node.folder.Name,
+		GistType: node.folder.Type,
 	}
 	for _, child := range node.child {
 		outline.Outlines = append(outline.Outlines, buildFolderOutline(child))
@@ -329,10 +721,11 @@ func buildFolderOutline(node *folderNode) opml.Outline {
 
 func buildFeedOutline(feed model.Feed) opml.Outline {
 	outline := opml.Outline{
-		Text:   feed.Title,
-		Title:  feed.Title,
-		Type:   "rss",
-		XMLURL: feed.URL,
+		Text:     feed.Title,
+		Title:    feed.Title,
+		Type:     "rss",
+		XMLURL:   feed.URL,
+		GistType: feed.Type,
 	}
 	if feed.SiteURL != nil {
 		outline.HTMLURL = *feed.SiteURL
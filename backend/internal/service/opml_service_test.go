@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/opml"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestOPMLService_Preview(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewOPMLService(nil, nil, mockFolders, mockFeeds, nil)
+	ctx := context.Background()
+
+	photosID := int64(10)
+	mockFolders.EXPECT().List(ctx).Return([]model.Folder{
+		{ID: photosID, Name: "Photos", Type: "picture"},
+	}, nil)
+	mockFolders.EXPECT().FindByName(ctx, "Photos", (*int64)(nil)).
+		Return(&model.Folder{ID: photosID, Name: "Photos", Type: "picture"}, nil)
+	mockFolders.EXPECT().FindByName(ctx, "Tech", (*int64)(nil)).Return(nil, nil)
+
+	mockFeeds.EXPECT().FindByURL(ctx, "https://photos.example.com/feed").Return(nil, nil)
+	mockFeeds.EXPECT().FindByURL(ctx, "https://blog.example.com/feed").
+		Return(&model.Feed{ID: 1, URL: "https://blog.example.com/feed", Type: "article", FolderID: &photosID}, nil)
+	mockFeeds.EXPECT().FindByURL(ctx, "https://news.example.com/feed").Return(nil, nil)
+
+	doc := opml.Document{Body: opml.Body{Outlines: []opml.Outline{
+		{Text: "Photos", Outlines: []opml.Outline{
+			{Text: "Photo Feed", XMLURL: "https://photos.example.com/feed"},
+			{Text: "Blog", XMLURL: "https://blog.example.com/feed"},
+		}},
+		{Text: "Tech", Outlines: []opml.Outline{
+			{Text: "News", XMLURL: "https://news.example.com/feed"},
+			{Text: "News again", XMLURL: "https://news.example.com/feed"},
+			{Text: "Broken", Type: "rss"},
+		}},
+	}}}
+
+	preview, err := service.Preview(ctx, doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := ImportResult{FoldersCreated: 1, FoldersSkipped: 1, FeedsCreated: 2, FeedsSkipped: 3}
+	if preview.Summary != want {
+		t.Errorf("expected summary %+v, got %+v", want, preview.Summary)
+	}
+	if len(preview.Folders) != 1 || preview.Folders[0].Path != "Tech" {
+		t.Errorf("expected folder Tech to be created, got %+v", preview.Folders)
+	}
+	if len(preview.Feeds) != 2 || preview.Feeds[0].Type != "picture" {
+		t.Errorf("expected feed to inherit picture type, got %+v", preview.Feeds)
+	}
+	if len(preview.Duplicates) != 2 {
+		t.Errorf("expected 2 duplicates, got %+v", preview.Duplicates)
+	}
+	if len(preview.TypeConflicts) != 1 || preview.TypeConflicts[0].ExistingType != "article" || preview.TypeConflicts[0].ExistingFolder != "Photos" {
+		t.Errorf("unexpected type conflicts: %+v", preview.TypeConflicts)
+	}
+	if len(preview.Invalid) != 1 {
+		t.Errorf("expected 1 invalid feed, got %+v", preview.Invalid)
+	}
+}
+
+func TestOPMLService_Preview_TypeHints(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewOPMLService(nil, nil, mockFolders, mockFeeds, nil)
+	ctx := context.Background()
+
+	mockFolders.EXPECT().List(ctx).Return(nil, nil)
+	mockFolders.EXPECT().FindByName(ctx, gomock.Any(), (*int64)(nil)).Return(nil, nil).Times(2)
+	mockFeeds.EXPECT().FindByURL(ctx, gomock.Any()).Return(nil, nil).Times(3)
+
+	doc := opml.Document{Body: opml.Body{Outlines: []opml.Outline{
+		{Text: "Art", GistType: "picture", Outlines: []opml.Outline{
+			{Text: "Gallery", XMLURL: "https://art.example.com/feed"},
+			{Text: "Status", XMLURL: "https://status.example.com/feed", GistType: "notification"},
+		}},
+		{Text: "Shots", Category: "/Tags/Photos", Outlines: []opml.Outline{
+			{Text: "Shots feed", XMLURL: "https://shots.example.com/feed"},
+		}},
+	}}}
+
+	preview, err := service.Preview(ctx, doc)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(preview.Folders) != 2 || preview.Folders[0].Type != "picture" || preview.Folders[1].Type != "picture" {
+		t.Errorf("expected picture folders, got %+v", preview.Folders)
+	}
+	wantTypes := []string{"picture", "notification", "picture"}
+	for i, feed := range preview.Feeds {
+		if feed.Type != wantTypes[i] {
+			t.Errorf("feed %s: expected type %s, got %s", feed.URL, wantTypes[i], feed.Type)
+		}
+	}
+}
+
+func TestOPMLService_Export_RoundTripsTypes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewOPMLService(nil, nil, mockFolders, mockFeeds, nil)
+	ctx := context.Background()
+
+	folderID := int64(1)
+	mockFolders.EXPECT().List(ctx).Return([]model.Folder{{ID: folderID, Name: "Photos", Type: "picture"}}, nil)
+	mockFeeds.EXPECT().List(ctx, (*int64)(nil)).Return([]model.Feed{
+		{ID: 2, Title: "Gallery", URL: "https://art.example.com/feed", FolderID: &folderID, Type: "picture"},
+		{ID: 3, Title: "Alerts", URL: "https://status.example.com/feed", Type: "notification"},
+	}, nil)
+
+	payload, err := service.Export(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(payload), `xmlns:gist="`+opml.Namespace+`"`) {
+		t.Errorf("expected gist namespace declaration, got:\n%s", payload)
+	}
+
+	doc, err := service.Parse(bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("parse exported opml: %v", err)
+	}
+	outlines := doc.Body.Outlines
+	if len(outlines) != 2 || outlineTypeHint(outlines[0]) != "picture" || outlineTypeHint(outlines[1]) != "notification" {
+		t.Fatalf("types did not round-trip: %+v", outlines)
+	}
+	if feeds := outlines[0].Outlines; len(feeds) != 1 || outlineTypeHint(feeds[0]) != "picture" {
+		t.Errorf("feed type did not round-trip: %+v", feeds)
+	}
+}
+
+func TestOutlineTypeHint(t *testing.T) {
+	tests := []struct {
+		name    string
+		outline opml.Outline
+		want    string
+	}{
+		{"none", opml.Outline{Text: "Tech"}, ""},
+		{"rss type is not a hint", opml.Outline{Type: "rss", XMLURL: "https://example.com/feed"}, ""},
+		{"gist type", opml.Outline{GistType: "Notification"}, "notification"},
+		{"type attribute", opml.Outline{Type: "picture"}, "picture"},
+		{"gist type wins", opml.Outline{GistType: "article", Category: "/photos"}, "article"},
+		{"category path", opml.Outline{Category: "/Tags/Images,/Boston"}, "picture"},
+		{"category list", opml.Outline{Category: "news, alerts"}, "notification"},
+	}
+	for _, tt := range tests {
+		if got := outlineTypeHint(tt.outline); got != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+// Outbox message kinds
+const (
+	OutboxFeedIcon    = "feed.icon"
+	OutboxFeedRefresh = "feed.refresh"
+)
+
+const (
+	outboxPollInterval   = 30 * time.Second
+	outboxBatchSize      = 20
+	outboxHandlerTimeout = 2 * time.Minute
+	outboxMaxAttempts    = 10
+	outboxBaseBackoff    = 30 * time.Second
+	outboxMaxBackoff     = time.Hour
+)
+
+// FeedIconPayload asks for a feed's icon to be fetched.
+type FeedIconPayload struct {
+	FeedID   int64  `json:"feedId"`
+	ImageURL string `json:"imageUrl,omitempty"`
+	SiteURL  string `json:"siteUrl"`
+}
+
+// FeedRefreshPayload asks for a feed to be refreshed.
+type FeedRefreshPayload struct {
+	FeedID int64 `json:"feedId"`
+}
+
+// OutboxHandler performs one side effect. Returning an error schedules a retry.
+type OutboxHandler func(ctx context.Context, payload string) error
+
+// OutboxNotifier wakes the dispatcher after new messages are committed.
+type OutboxNotifier interface {
+	Notify()
+}
+
+// OutboxDispatcher runs outbox messages until they succeed, retrying with
+// exponential backoff. Messages survive restarts because they live in the database.
+type OutboxDispatcher struct {
+	outbox   repository.OutboxRepository
+	handlers map[string]OutboxHandler
+	notifyCh chan struct{}
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+func NewOutboxDispatcher(outbox repository.OutboxRepository) *OutboxDispatcher {
+	return &OutboxDispatcher{
+		outbox:   outbox,
+		handlers: make(map[string]OutboxHandler),
+		notifyCh: make(chan struct{}, 1),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Register sets the handler for a message kind. Call before Start.
+func (d *OutboxDispatcher) Register(kind string, handler OutboxHandler) {
+	d.handlers[kind] = handler
+}
+
+func (d *OutboxDispatcher) Notify() {
+	select {
+	case d.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+func (d *OutboxDispatcher) Start() {
+	d.wg.Add(1)
+	go d.run()
+}
+
+func (d *OutboxDispatcher) Stop() {
+	close(d.stopCh)
+	d.wg.Wait()
+}
+
+func (d *OutboxDispatcher) run() {
+	defer d.wg.Done()
+
+	// Pick up anything left over from before a restart
+	d.dispatchDue()
+
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.dispatchDue()
+		case <-d.notifyCh:
+			d.dispatchDue()
+		case <-d.stopCh:
+			return
+		}
+	}
+}
+
+func (d *OutboxDispatcher) dispatchDue() {
+	for {
+		select {
+		case <-d.stopCh:
+			return
+		default:
+		}
+
+		messages, err := d.outbox.ListDue(context.Background(), time.Now(), outboxBatchSize)
+		if err != nil {
+			log.Printf("outbox: list due messages: %v", err)
+			return
+		}
+		if len(messages) == 0 {
+			return
+		}
+		for _, msg := range messages {
+			d.dispatch(msg)
+		}
+	}
+}
+
+func (d *OutboxDispatcher) dispatch(msg model.OutboxMessage) {
+	ctx := context.Background()
+
+	handler, ok := d.handlers[msg.Kind]
+	if !ok {
+		log.Printf("outbox: dropping message %d with unknown kind %q", msg.ID, msg.Kind)
+		_ = d.outbox.Delete(ctx, msg.ID)
+		return
+	}
+
+	handlerCtx, cancel := context.WithTimeout(ctx, outboxHandlerTimeout)
+	err := handler(handlerCtx, msg.Payload)
+	cancel()
+
+	if err == nil {
+		if err := d.outbox.Delete(ctx, msg.ID); err != nil {
+			log.Printf("outbox: delete message %d: %v", msg.ID, err)
+		}
+		return
+	}
+
+	attempts := msg.Attempts + 1
+	if attempts >= outboxMaxAttempts {
+		log.Printf("outbox: giving up on %s message %d after %d attempts: %v", msg.Kind, msg.ID, attempts, err)
+		_ = d.outbox.Delete(ctx, msg.ID)
+		return
+	}
+	if err := d.outbox.Reschedule(ctx, msg.ID, err.Error(), time.Now().Add(outboxBackoff(attempts))); err != nil {
+		log.Printf("outbox: reschedule message %d: %v", msg.ID, err)
+	}
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	backoff := outboxBaseBackoff << (attempts - 1)
+	if backoff <= 0 || backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return backoff
+}
+
+// enqueueOutbox serializes payload and adds it to the outbox of the current transaction.
+func enqueueOutbox(ctx context.Context, outbox repository.OutboxRepository, kind string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode outbox payload: %w", err)
+	}
+	return outbox.Enqueue(ctx, kind, string(data))
+}
+
+// NewFeedIconHandler fetches a feed's icon and stores its path and color.
+func NewFeedIconHandler(icons IconService, feeds repository.FeedRepository) OutboxHandler {
+	return func(ctx context.Context, payload string) error {
+		var p FeedIconPayload
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return nil // malformed payloads can never succeed
+		}
+		feed, err := feeds.GetByID(ctx, p.FeedID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil // feed was deleted meanwhile
+			}
+			return err
+		}
+		iconPath, err := icons.FetchAndSaveIcon(withRequestOverrides(ctx, feed.RequestOverrides), p.ImageURL, p.SiteURL)
+		if err != nil {
+			return err
+		}
+		if iconPath == "" {
+			return nil
+		}
+		return icons.SetFeedIcon(ctx, p.FeedID, iconPath)
+	}
+}
+
+// NewFeedRefreshHandler refreshes a single feed.
+func NewFeedRefreshHandler(refresh RefreshService) OutboxHandler {
+	return func(ctx context.Context, payload string) error {
+		var p FeedRefreshPayload
+		if err := json.Unmarshal([]byte(payload), &p); err != nil {
+			return nil
+		}
+		if err := refresh.RefreshFeed(ctx, p.FeedID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return nil
+	}
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestOutboxDispatcher_DispatchDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	outbox := testutil.NewMockOutboxRepository(ctrl)
+	dispatcher := NewOutboxDispatcher(outbox)
+
+	var handled []string
+	dispatcher.Register("ok", func(_ context.Context, payload string) error {
+		handled = append(handled, payload)
+		return nil
+	})
+	dispatcher.Register("failing", func(context.Context, string) error {
+		return errors.New("host down")
+	})
+
+	messages := []model.OutboxMessage{
+		{ID: 1, Kind: "ok", Payload: "a"},
+		{ID: 2, Kind: "failing", Attempts: 2},
+		{ID: 3, Kind: "failing", Attempts: outboxMaxAttempts - 1},
+		{ID: 4, Kind: "unknown"},
+	}
+	before := time.Now()
+	gomock.InOrder(
+		outbox.EXPECT().ListDue(gomock.Any(), gomock.Any(), outboxBatchSize).Return(messages, nil),
+		outbox.EXPECT().ListDue(gomock.Any(), gomock.Any(), outboxBatchSize).Return(nil, nil),
+	)
+	outbox.EXPECT().Delete(gomock.Any(), int64(1)).Return(nil)
+	outbox.EXPECT().Reschedule(gomock.Any(), int64(2), "host down", gomock.Any()).DoAndReturn(func(_ context.Context, _ int64, _ string, next time.Time) error {
+		// Third attempt failed
+		if wait := next.Sub(before); wait < 4*outboxBaseBackoff || wait > 4*outboxBaseBackoff+time.Minute {
+			t.Errorf("expected a retry in about %s, got %s", 4*outboxBaseBackoff, wait)
+		}
+		return nil
+	})
+	// Given up on, and dropped as no handler can run it
+	outbox.EXPECT().Delete(gomock.Any(), int64(3)).Return(nil)
+	outbox.EXPECT().Delete(gomock.Any(), int64(4)).Return(nil)
+
+	dispatcher.dispatchDue()
+	if len(handled) != 1 || handled[0] != "a" {
+		t.Errorf("expected the ok message to be handled once, got %v", handled)
+	}
+}
+
+func TestOutboxDispatcher_StopsOnListError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	outbox := testutil.NewMockOutboxRepository(ctrl)
+	dispatcher := NewOutboxDispatcher(outbox)
+
+	outbox.EXPECT().ListDue(gomock.Any(), gomock.Any(), outboxBatchSize).Return(nil, errors.New("locked"))
+	dispatcher.dispatchDue()
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, outboxBaseBackoff},
+		{2, 2 * outboxBaseBackoff},
+		{5, 16 * outboxBaseBackoff},
+		{8, outboxMaxBackoff},
+		{64, outboxMaxBackoff},
+	}
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("attempt %d: got %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// PageCache keeps the HTML of recently fetched pages for a short while, so
+// extracting a page again, as with other rules or right after a refresh did,
+// does not download it again. It holds at most maxBytes of pages and drops
+// the least recently used first.
+type PageCache struct {
+	maxBytes int64
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu    sync.Mutex
+	size  int64
+	order *list.List // of *cachedPage, most recently used first
+	pages map[string]*list.Element
+}
+
+type cachedPage struct {
+	url     string
+	body    []byte
+	expires time.Time
+}
+
+// NewPageCache creates a cache of at most maxBytes whose pages are kept for
+// ttl. A cache without room or time keeps nothing.
+func NewPageCache(maxBytes int64, ttl time.Duration) *PageCache {
+	return &PageCache{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		now:      time.Now,
+		order:    list.New(),
+		pages:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns the page fetched from url, if it is cached and fresh.
+func (c *PageCache) Get(url string) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.pages[url]
+	if !ok {
+		return nil, false
+	}
+	page := el.Value.(*cachedPage)
+	if !c.now().Before(page.expires) {
+		c.remove(el)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return page.body, true
+}
+
+// Put caches the page fetched from url. Pages larger than the whole cache
+// are not kept.
+func (c *PageCache) Put(url string, body []byte) {
+	if c == nil || c.ttl <= 0 || int64(len(body)) > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.pages[url]; ok {
+		c.remove(el)
+	}
+	c.pages[url] = c.order.PushFront(&cachedPage{url: url, body: body, expires: c.now().Add(c.ttl)})
+	c.size += int64(len(body))
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *PageCache) remove(el *list.Element) {
+	page := c.order.Remove(el).(*cachedPage)
+	delete(c.pages, page.url)
+	c.size -= int64(len(page.body))
+}
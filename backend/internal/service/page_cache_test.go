@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPageCache(t *testing.T) {
+	cache := NewPageCache(10, time.Minute)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	cache.Put("a", []byte("aaaa"))
+	cache.Put("b", []byte("bbbb"))
+	if body, ok := cache.Get("a"); !ok || string(body) != "aaaa" {
+		t.Fatalf("expected a to be cached, got %q, %v", body, ok)
+	}
+	// b is now the least recently used and makes room for c
+	cache.Put("c", []byte("cccc"))
+	if _, ok := cache.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := cache.Get("a"); !ok {
+		t.Error("expected a to be kept")
+	}
+	cache.Put("huge", []byte("more than ten bytes"))
+	if _, ok := cache.Get("huge"); ok {
+		t.Error("expected a page larger than the cache not to be kept")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get("c"); ok {
+		t.Error("expected c to expire")
+	}
+	if cache.size != 4 {
+		t.Errorf("expected 4 bytes left after expiry, got %d", cache.size)
+	}
+
+	var disabled *PageCache
+	disabled.Put("a", []byte("a"))
+	if _, ok := disabled.Get("a"); ok {
+		t.Error("expected a nil cache to keep nothing")
+	}
+}
+
+func TestReadabilityService_ExtractPage_FromCache(t *testing.T) {
+	pages := NewPageCache(1<<20, time.Minute)
+	service := NewReadabilityService(nil, nil, nil, nil, pages)
+	defer service.Close()
+
+	// Nothing listens at .invalid, so the page can only come from the cache
+	pageURL := "https://example.invalid/post"
+	pages.Put(pageURL, []byte(`<html lang="en"><head><title>Cached post</title></head><body><article><h1>Cached post</h1><p>`+strings.Repeat("A paragraph of the cached page. ", 20)+`</p></article></body></html>`))
+
+	page, err := service.ExtractPage(context.Background(), pageURL)
+	if err != nil {
+		t.Fatalf("extract: %v", err)
+	}
+	if !strings.Contains(page.Content, "A paragraph of the cached page.") || page.Language != "en" {
+		t.Errorf("unexpected page %+v", page)
+	}
+}
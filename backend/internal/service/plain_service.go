@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+
+	"gist/backend/internal/plainview"
+	"gist/backend/internal/repository"
+)
+
+// PlainPath is where the plain-text listing is served.
+const PlainPath = "/api/plain/entries"
+
+// PlainService formats entries as plain text for terminals and shell
+// scripts, to read with curl or pipe into a pager.
+type PlainService interface {
+	// Entries formats a page of entries. query, the request's own, is kept
+	// in the path to the next page.
+	Entries(ctx context.Context, params EntryListParams, query url.Values) ([]byte, error)
+	// Entry formats an entry, preferring its readable content, with its
+	// text wrapped at width columns; 0 leaves it unwrapped.
+	Entry(ctx context.Context, id int64, width int) ([]byte, error)
+}
+
+type plainService struct {
+	entries EntryService
+	feeds   repository.FeedRepository
+}
+
+func NewPlainService(entries EntryService, feeds repository.FeedRepository) PlainService {
+	return &plainService{entries: entries, feeds: feeds}
+}
+
+func (s *plainService) Entries(ctx context.Context, params EntryListParams, query url.Values) ([]byte, error) {
+	// Fetch one more than a page to know whether another follows
+	limit := params.Limit
+	params.Limit++
+	entries, err := s.entries.List(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(entries) > limit
+	if hasMore {
+		entries = entries[:limit]
+	}
+	feedTitles, err := s.feedTitles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := plainview.List{Items: make([]plainview.Item, len(entries))}
+	for i, entry := range entries {
+		list.Items[i] = plainview.Item{
+			ID:        entry.ID,
+			Title:     trimmedValue(entry.Title),
+			FeedTitle: feedTitles[entry.FeedID],
+			Published: entry.PublishedAt,
+			URL:       trimmedValue(entry.URL),
+			Read:      entry.Read,
+			Starred:   entry.Starred,
+		}
+	}
+	if hasMore {
+		next := url.Values{}
+		for key, values := range query {
+			next[key] = values
+		}
+		next.Del("offset")
+		next.Set("after", EntryCursorOf(entries[len(entries)-1]))
+		list.Next = PlainPath + "?" + next.Encode()
+	}
+
+	var buf bytes.Buffer
+	if err := plainview.RenderList(&buf, list); err != nil {
+		return nil, fmt.Errorf("render plain list: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *plainService) Entry(ctx context.Context, id int64, width int) ([]byte, error) {
+	entry, err := s.entries.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	feedTitle := ""
+	if feed, err := s.feeds.GetByID(ctx, entry.FeedID); err == nil {
+		feedTitle = feed.Title
+	}
+
+	content := ""
+	if entry.ReadableContent != nil && *entry.ReadableContent != "" {
+		content = *entry.ReadableContent
+	} else if entry.Content != nil {
+		content = *entry.Content
+	}
+	var buf bytes.Buffer
+	if err := plainview.RenderArticle(&buf, plainview.Article{
+		ID:        entry.ID,
+		Title:     trimmedValue(entry.Title),
+		Author:    trimmedValue(entry.Author),
+		FeedTitle: feedTitle,
+		URL:       trimmedValue(entry.URL),
+		Published: entry.PublishedAt,
+		Content:   content,
+		Read:      entry.Read,
+		Starred:   entry.Starred,
+	}, width); err != nil {
+		return nil, fmt.Errorf("render plain entry: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *plainService) feedTitles(ctx context.Context) (map[int64]string, error) {
+	feeds, err := s.feeds.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list feeds: %w", err)
+	}
+	titles := make(map[int64]string, len(feeds))
+	for _, feed := range feeds {
+		titles[feed.ID] = feed.Title
+	}
+	return titles, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestPlainService_Entries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewPlainService(NewEntryService(nil, entries, feeds, testutil.NewMockFolderRepository(ctrl)), feeds)
+	ctx := context.Background()
+
+	published := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	title, link := "Fish & Chips", "https://example.com/fish"
+	page := []model.EntrySummary{
+		{ID: 100, FeedID: 7, Title: &title, URL: &link, PublishedAt: &published, Starred: true},
+		{ID: 99, FeedID: 7, PublishedAt: &published, Read: true},
+		{ID: 98, FeedID: 7, PublishedAt: &published},
+	}
+	entries.EXPECT().List(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, filter repository.EntryListFilter) ([]model.EntrySummary, error) {
+		if !filter.UnreadOnly || filter.Limit != 3 {
+			t.Errorf("unexpected filter %+v", filter)
+		}
+		return page, nil
+	})
+	feeds.EXPECT().List(ctx, nil).Return([]model.Feed{{ID: 7, Title: "Food Blog"}}, nil)
+
+	query := url.Values{"unreadOnly": {"true"}, "limit": {"2"}}
+	payload, err := service.Entries(ctx, EntryListParams{UnreadOnly: true, Limit: 2}, query)
+	if err != nil {
+		t.Fatalf("entries: %v", err)
+	}
+	want := "[100] Fish & Chips\n" +
+		"    Food Blog · 2025-03-01 08:00 · unread · starred\n" +
+		"    https://example.com/fish\n" +
+		"\n" +
+		"[99] Untitled\n" +
+		"    Food Blog · 2025-03-01 08:00\n" +
+		"\n" +
+		"Next: /api/plain/entries?after=2025-03-01T08%3A00%3A00Z%2C99&limit=2&unreadOnly=true\n"
+	if string(payload) != want {
+		t.Errorf("got\n%s\nwant\n%s", payload, want)
+	}
+}
+
+func TestPlainService_Entry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewPlainService(NewEntryService(nil, entries, feeds, testutil.NewMockFolderRepository(ctrl)), feeds)
+	ctx := context.Background()
+
+	title, link, author := "Saved article", "https://example.com/posts/1", "Ann"
+	content := "<p>Feed text</p>"
+	readable := "<h2>Intro</h2><p>The quick brown fox jumps over the lazy dog</p><script>alert(1)</script>"
+	entries.EXPECT().GetByID(ctx, int64(5)).Return(model.Entry{
+		ID: 5, FeedID: 7, Title: &title, URL: &link, Author: &author, Content: &content, ReadableContent: &readable, Read: true,
+	}, nil)
+	feeds.EXPECT().GetByID(ctx, int64(7)).Return(model.Feed{ID: 7, Title: "Blog"}, nil)
+
+	payload, err := service.Entry(ctx, 5, 20)
+	if err != nil {
+		t.Fatalf("entry: %v", err)
+	}
+	want := "Saved article\n" +
+		"Blog · Ann\n" +
+		"https://example.com/posts/1\n" +
+		"\n" +
+		"Intro\n" +
+		"\n" +
+		"The quick brown fox\njumps over the lazy\ndog\n"
+	if string(payload) != want {
+		t.Errorf("got\n%s\nwant\n%s", payload, want)
+	}
+	if strings.Contains(string(payload), "Feed text") {
+		t.Error("expected the readable content only")
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+// PlaybackService remembers where playback of an entry's attachment, such
+// as a podcast episode, stopped, so it resumes there on any device.
+type PlaybackService interface {
+	// Get returns the playback position of an entry, zero when it was never
+	// played, or ErrNotFound.
+	Get(ctx context.Context, entryID int64) (model.PlaybackPosition, error)
+	// Set stores the playback position of an entry in seconds from the
+	// start. Returns ErrNotFound or ErrInvalid for a negative position.
+	Set(ctx context.Context, entryID, seconds int64) (model.PlaybackPosition, error)
+}
+
+type playbackService struct {
+	repo repository.PlaybackRepository
+	now  func() time.Time
+}
+
+func NewPlaybackService(repo repository.PlaybackRepository) PlaybackService {
+	return &playbackService{repo: repo, now: time.Now}
+}
+
+func (s *playbackService) Get(ctx context.Context, entryID int64) (model.PlaybackPosition, error) {
+	position, err := s.repo.Get(ctx, entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.PlaybackPosition{}, ErrNotFound
+	}
+	if err != nil {
+		return model.PlaybackPosition{}, fmt.Errorf("get playback position: %w", err)
+	}
+	return position, nil
+}
+
+func (s *playbackService) Set(ctx context.Context, entryID, seconds int64) (model.PlaybackPosition, error) {
+	if seconds < 0 {
+		return model.PlaybackPosition{}, ErrInvalid
+	}
+	position := model.PlaybackPosition{
+		EntryID:         entryID,
+		PositionSeconds: seconds,
+		UpdatedAt:       s.now().UTC().Truncate(time.Second),
+	}
+	if err := s.repo.Set(ctx, position); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PlaybackPosition{}, ErrNotFound
+		}
+		return model.PlaybackPosition{}, err
+	}
+	return position, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+// memoryPlayback stores positions of the entries in it.
+type memoryPlayback struct {
+	repository.PlaybackRepository
+	positions map[int64]model.PlaybackPosition
+}
+
+func (m *memoryPlayback) Get(_ context.Context, entryID int64) (model.PlaybackPosition, error) {
+	position, ok := m.positions[entryID]
+	if !ok {
+		return model.PlaybackPosition{}, sql.ErrNoRows
+	}
+	return position, nil
+}
+
+func (m *memoryPlayback) Set(_ context.Context, position model.PlaybackPosition) error {
+	if _, ok := m.positions[position.EntryID]; !ok {
+		return sql.ErrNoRows
+	}
+	m.positions[position.EntryID] = position
+	return nil
+}
+
+func TestPlaybackService(t *testing.T) {
+	ctx := context.Background()
+	repo := &memoryPlayback{positions: map[int64]model.PlaybackPosition{1: {EntryID: 1}}}
+	now := time.Date(2026, 5, 1, 12, 0, 0, 500, time.UTC)
+	playback := &playbackService{repo: repo, now: func() time.Time { return now }}
+
+	position, err := playback.Set(ctx, 1, 754)
+	if err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if position.PositionSeconds != 754 || !position.UpdatedAt.Equal(now.Truncate(time.Second)) {
+		t.Errorf("unexpected position: %+v", position)
+	}
+	if got, err := playback.Get(ctx, 1); err != nil || got != position {
+		t.Errorf("get: got %+v, %v; want %+v", got, err, position)
+	}
+
+	if _, err := playback.Set(ctx, 1, -1); !errors.Is(err, ErrInvalid) {
+		t.Errorf("negative position: got %v, want ErrInvalid", err)
+	}
+	if _, err := playback.Set(ctx, 2, 10); !errors.Is(err, ErrNotFound) {
+		t.Errorf("set unknown entry: got %v, want ErrNotFound", err)
+	}
+	if _, err := playback.Get(ctx, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("get unknown entry: got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gist/backend/internal/repository"
+)
+
+const (
+	// MaxPreferenceSize bounds the JSON value of one preference, in bytes.
+	MaxPreferenceSize = 16 << 10
+	// MaxPreferencesSize bounds the JSON values of all preferences together.
+	MaxPreferencesSize = 256 << 10
+	// MaxPreferences bounds how many preferences are stored.
+	MaxPreferences = 200
+	// maxPreferenceKeyLength bounds the length of a preference key.
+	maxPreferenceKeyLength = 64
+	// maxBindingLength bounds a key binding or gesture action.
+	maxBindingLength = 64
+)
+
+// preferenceKeyPrefix namespaces preferences among the settings.
+const preferenceKeyPrefix = "pref."
+
+// ErrPreferencesFull is returned when an update would take the preferences
+// past MaxPreferences or MaxPreferencesSize.
+var ErrPreferencesFull = errors.New("preferences full")
+
+// preferenceKey matches preference keys: dot-separated lowercase segments
+// such as "shortcuts" or "reader.sidebar_width".
+var preferenceKey = regexp.MustCompile(`^[a-z][a-z0-9_-]*(\.[a-z0-9_-]+)*$`)
+
+// preferenceSchemas checks the values of the preferences the frontend gives
+// a meaning to. Other keys hold any JSON value.
+var preferenceSchemas = map[string]func(json.RawMessage) error{
+	// action -> key combination, such as "nextEntry": "j"
+	"shortcuts": checkBindings,
+	// gesture -> action, such as "swipeLeft": "toggleRead"
+	"gestures": checkBindings,
+}
+
+// PreferencesService stores user preferences of the frontend, such as
+// shortcut remaps and UI state, as JSON values by key so new ones need no
+// endpoint of their own.
+type PreferencesService interface {
+	// Get returns every stored preference.
+	Get(ctx context.Context) (map[string]json.RawMessage, error)
+	// Update stores the given preferences, deleting those set to null, and
+	// returns every stored preference. Nothing is stored when a key or value
+	// is invalid (ErrInvalid) or the result would be too large
+	// (ErrPreferencesFull).
+	Update(ctx context.Context, changes map[string]json.RawMessage) (map[string]json.RawMessage, error)
+}
+
+type preferencesService struct {
+	repo repository.SettingsRepository
+}
+
+func NewPreferencesService(repo repository.SettingsRepository) PreferencesService {
+	return &preferencesService{repo: repo}
+}
+
+func (s *preferencesService) Get(ctx context.Context) (map[string]json.RawMessage, error) {
+	stored, err := s.repo.GetByPrefix(ctx, preferenceKeyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("list preferences: %w", err)
+	}
+	prefs := make(map[string]json.RawMessage, len(stored))
+	for _, setting := range stored {
+		prefs[strings.TrimPrefix(setting.Key, preferenceKeyPrefix)] = json.RawMessage(setting.Value)
+	}
+	return prefs, nil
+}
+
+func (s *preferencesService) Update(ctx context.Context, changes map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	for key, value := range changes {
+		if err := CheckPreference(key, value); err != nil {
+			return nil, fmt.Errorf("%w: preference %s: %v", ErrInvalid, key, err)
+		}
+	}
+
+	prefs, err := s.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range changes {
+		if isJSONNull(value) {
+			delete(prefs, key)
+		} else {
+			prefs[key] = compactJSON(value)
+		}
+	}
+	size := 0
+	for _, value := range prefs {
+		size += len(value)
+	}
+	if len(prefs) > MaxPreferences {
+		return nil, fmt.Errorf("%w: more than %d preferences", ErrPreferencesFull, MaxPreferences)
+	}
+	if size > MaxPreferencesSize {
+		return nil, fmt.Errorf("%w: preferences over %d bytes", ErrPreferencesFull, MaxPreferencesSize)
+	}
+
+	for key, value := range changes {
+		if isJSONNull(value) {
+			if err := s.repo.Delete(ctx, preferenceKeyPrefix+key); err != nil {
+				return nil, fmt.Errorf("delete preference %s: %w", key, err)
+			}
+			continue
+		}
+		if err := s.repo.Set(ctx, preferenceKeyPrefix+key, string(prefs[key])); err != nil {
+			return nil, fmt.Errorf("set preference %s: %w", key, err)
+		}
+	}
+	return prefs, nil
+}
+
+// CheckPreference returns why a preference cannot be stored, checking its key,
+// the size of its value and, for known keys, the shape of the value. A null
+// value, which deletes the preference, is always valid for a valid key.
+func CheckPreference(key string, value json.RawMessage) error {
+	if len(key) > maxPreferenceKeyLength || !preferenceKey.MatchString(key) {
+		return errors.New("must be dot-separated lowercase segments of at most 64 characters")
+	}
+	if !json.Valid(value) {
+		return errors.New("must be JSON")
+	}
+	if isJSONNull(value) {
+		return nil
+	}
+	if len(compactJSON(value)) > MaxPreferenceSize {
+		return fmt.Errorf("must be at most %d bytes", MaxPreferenceSize)
+	}
+	if check, ok := preferenceSchemas[key]; ok {
+		return check(value)
+	}
+	return nil
+}
+
+// checkBindings checks an object of names to short non-empty strings, with
+// null clearing a binding.
+func checkBindings(value json.RawMessage) error {
+	var bindings map[string]*string
+	if err := json.Unmarshal(value, &bindings); err != nil {
+		return errors.New("must be an object of strings")
+	}
+	for name, binding := range bindings {
+		if name == "" || len(name) > maxPreferenceKeyLength {
+			return fmt.Errorf("names must be 1 to %d characters", maxPreferenceKeyLength)
+		}
+		if binding != nil && (*binding == "" || len(*binding) > maxBindingLength) {
+			return fmt.Errorf("%s must be 1 to %d characters", name, maxBindingLength)
+		}
+	}
+	return nil
+}
+
+func isJSONNull(value json.RawMessage) bool {
+	return strings.TrimSpace(string(value)) == "null"
+}
+
+// compactJSON returns value without insignificant whitespace, as it is
+// stored and counted against the size limits.
+func compactJSON(value json.RawMessage) json.RawMessage {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, value); err != nil {
+		return value
+	}
+	return buf.Bytes()
+}
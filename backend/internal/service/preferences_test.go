@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPreferences_Update(t *testing.T) {
+	ctx := context.Background()
+	repo := &memorySettings{values: map[string]string{"general.low_data": "true"}}
+	prefs := NewPreferencesService(repo)
+
+	got, err := prefs.Update(ctx, map[string]json.RawMessage{
+		"shortcuts":            json.RawMessage(`{ "nextEntry": "j", "markRead": null }`),
+		"reader.sidebar_width": json.RawMessage(`280`),
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if string(got["shortcuts"]) != `{"nextEntry":"j","markRead":null}` || string(got["reader.sidebar_width"]) != "280" {
+		t.Fatalf("unexpected preferences: %s", got)
+	}
+	if len(got) != 2 {
+		t.Errorf("other settings listed as preferences: %s", got)
+	}
+
+	// Keys not given are kept; null deletes
+	got, err = prefs.Update(ctx, map[string]json.RawMessage{
+		"reader.sidebar_width": json.RawMessage(`null`),
+		"gestures":             json.RawMessage(`{"swipeLeft":"toggleRead"}`),
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	stored, err := prefs.Get(ctx)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	for _, p := range []map[string]json.RawMessage{got, stored} {
+		if _, ok := p["reader.sidebar_width"]; ok || len(p) != 2 || string(p["gestures"]) != `{"swipeLeft":"toggleRead"}` {
+			t.Errorf("unexpected preferences: %s", p)
+		}
+	}
+	if repo.values["general.low_data"] != "true" {
+		t.Error("setting outside the preferences changed")
+	}
+}
+
+func TestPreferences_Invalid(t *testing.T) {
+	ctx := context.Background()
+	repo := &memorySettings{values: map[string]string{}}
+	prefs := NewPreferencesService(repo)
+
+	for name, changes := range map[string]map[string]json.RawMessage{
+		"uppercase key":       {"Shortcuts": json.RawMessage(`{}`)},
+		"empty segment":       {"reader..width": json.RawMessage(`1`)},
+		"long key":            {strings.Repeat("a", 65): json.RawMessage(`1`)},
+		"shortcuts not map":   {"shortcuts": json.RawMessage(`["j"]`)},
+		"empty binding":       {"shortcuts": json.RawMessage(`{"nextEntry":""}`)},
+		"gesture not string":  {"gestures": json.RawMessage(`{"swipeLeft":1}`)},
+		"value too large":     {"big": json.RawMessage(`"` + strings.Repeat("a", MaxPreferenceSize) + `"`)},
+		"one invalid of many": {"ok": json.RawMessage(`true`), "Bad": json.RawMessage(`true`)},
+	} {
+		if _, err := prefs.Update(ctx, changes); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: got %v, want ErrInvalid", name, err)
+		}
+	}
+	if len(repo.values) != 0 {
+		t.Errorf("invalid update stored preferences: %v", repo.values)
+	}
+}
+
+func TestPreferences_Full(t *testing.T) {
+	ctx := context.Background()
+	prefs := NewPreferencesService(&memorySettings{values: map[string]string{}})
+
+	value := json.RawMessage(`"` + strings.Repeat("a", MaxPreferenceSize-2) + `"`)
+	changes := make(map[string]json.RawMessage)
+	for i := 0; i*MaxPreferenceSize <= MaxPreferencesSize; i++ {
+		changes["p"+strings.Repeat("x", i)] = value
+	}
+	if _, err := prefs.Update(ctx, changes); !errors.Is(err, ErrPreferencesFull) {
+		t.Fatalf("got %v, want ErrPreferencesFull", err)
+	}
+	stored, err := prefs.Get(ctx)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if len(stored) != 0 {
+		t.Errorf("update past the limit stored %d preferences", len(stored))
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"gist/backend/internal/model"
+)
+
+// proxiedImagePath is the route that serves images through the proxy.
+const proxiedImagePath = "/api/proxy/image"
+
+// ProxiedImageURL is the URL that loads imageURL through the image proxy,
+// with refererURL, the page it appears on, as its Referer.
+func ProxiedImageURL(imageURL, refererURL string) string {
+	query := url.Values{"url": {imageURL}}
+	if refererURL != "" {
+		query.Set("ref", refererURL)
+	}
+	return proxiedImagePath + "?" + query.Encode()
+}
+
+// ProxyEntryImages points the images in the content and readable content of
+// an entry at the image proxy.
+func ProxyEntryImages(entry *model.Entry) {
+	base := trimmedValue(entry.URL)
+	if entry.Content != nil {
+		proxied := ProxyContentImages(*entry.Content, base)
+		entry.Content = &proxied
+	}
+	if entry.ReadableContent != nil {
+		proxied := ProxyContentImages(*entry.ReadableContent, base)
+		entry.ReadableContent = &proxied
+	}
+}
+
+// ProxyContentImages rewrites the src and srcset of the img elements in
+// HTML content to load through the image proxy, resolving relative URLs
+// against baseURL. Content without images, or that cannot be parsed, is
+// returned as it is.
+func ProxyContentImages(content, baseURL string) string {
+	if !strings.Contains(content, "<img") {
+		return content
+	}
+	nodes, err := html.ParseFragment(strings.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return content
+	}
+	base, _ := url.Parse(baseURL)
+
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		proxyImages(node, base, baseURL)
+		if err := html.Render(&buf, node); err != nil {
+			return content
+		}
+	}
+	return buf.String()
+}
+
+func proxyImages(n *html.Node, base *url.URL, referer string) {
+	if n.Type == html.ElementNode && n.DataAtom == atom.Img {
+		for i, attr := range n.Attr {
+			switch attr.Key {
+			case "src":
+				if proxied, ok := proxiedSource(base, attr.Val, referer); ok {
+					n.Attr[i].Val = proxied
+				}
+			case "srcset":
+				n.Attr[i].Val = proxiedSrcset(base, attr.Val, referer)
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		proxyImages(c, base, referer)
+	}
+}
+
+// proxiedSource returns the proxied URL of an image source. Sources that are
+// not http(s) once resolved, such as data: URIs, are left alone.
+func proxiedSource(base *url.URL, src, referer string) (string, bool) {
+	resolved, ok := resolveImageURL(base, src)
+	if !ok {
+		return "", false
+	}
+	return ProxiedImageURL(resolved, referer), true
+}
+
+// proxiedSrcset rewrites each candidate of a srcset, keeping its width or
+// density descriptor. A candidate's URL runs to the next space and may hold
+// commas itself, as CDN transformation URLs do.
+func proxiedSrcset(base *url.URL, srcset, referer string) string {
+	var candidates []string
+	rest := srcset
+	for {
+		rest = strings.TrimLeft(rest, " \t\n\r\f,")
+		if rest == "" {
+			break
+		}
+		end := strings.IndexAny(rest, " \t\n\r\f")
+		if end < 0 {
+			end = len(rest)
+		}
+		src, descriptor := rest[:end], ""
+		rest = rest[end:]
+		if trimmed := strings.TrimRight(src, ","); trimmed != src {
+			src = trimmed
+		} else if comma := strings.IndexByte(rest, ','); comma >= 0 {
+			descriptor, rest = strings.TrimSpace(rest[:comma]), rest[comma+1:]
+		} else {
+			descriptor, rest = strings.TrimSpace(rest), ""
+		}
+		if proxied, ok := proxiedSource(base, src, referer); ok {
+			src = proxied
+		}
+		if descriptor != "" {
+			src += " " + descriptor
+		}
+		candidates = append(candidates, src)
+	}
+	return strings.Join(candidates, ", ")
+}
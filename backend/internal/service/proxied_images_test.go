@@ -0,0 +1,42 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"gist/backend/internal/model"
+)
+
+func TestProxyContentImages(t *testing.T) {
+	content := `<p>Hi</p><img src="/img/a.png" alt="a"><img src="data:image/gif;base64,R0lGOD"><img src="https://cdn.example.com/b.jpg" srcset="https://cdn.example.com/w_100,h_50/b.jpg 1x, b@2x.jpg 2x">`
+	got := ProxyContentImages(content, "https://blog.example.com/posts/1")
+
+	for _, want := range []string{
+		`<img src="/api/proxy/image?ref=https%3A%2F%2Fblog.example.com%2Fposts%2F1&amp;url=https%3A%2F%2Fblog.example.com%2Fimg%2Fa.png" alt="a"/>`,
+		`<img src="data:image/gif;base64,R0lGOD"/>`,
+		`srcset="/api/proxy/image?ref=https%3A%2F%2Fblog.example.com%2Fposts%2F1&amp;url=https%3A%2F%2Fcdn.example.com%2Fw_100%2Ch_50%2Fb.jpg 1x, /api/proxy/image?ref=https%3A%2F%2Fblog.example.com%2Fposts%2F1&amp;url=https%3A%2F%2Fblog.example.com%2Fposts%2Fb%402x.jpg 2x"`,
+		`<p>Hi</p>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in\n%s", want, got)
+		}
+	}
+
+	if text := "<p>No images here</p>"; ProxyContentImages(text, "") != text {
+		t.Error("expected content without images to be returned as it is")
+	}
+}
+
+func TestProxyEntryImages(t *testing.T) {
+	url := "https://blog.example.com/post"
+	content := `<img src="https://cdn.example.com/a.png">`
+	entry := model.Entry{URL: &url, Content: &content}
+	ProxyEntryImages(&entry)
+	want := ProxiedImageURL("https://cdn.example.com/a.png", url)
+	if !strings.Contains(*entry.Content, strings.ReplaceAll(want, "&", "&amp;")) {
+		t.Errorf("expected the image to be proxied, got %s", *entry.Content)
+	}
+	if entry.ReadableContent != nil {
+		t.Error("expected missing readable content to stay missing")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/Noooste/azuretls-client"
@@ -28,50 +29,139 @@ type ProxyResult struct {
 }
 
 type ProxyService interface {
+	// FetchImage downloads an image, bypassing the cache.
 	FetchImage(ctx context.Context, imageURL, refererURL string) (*ProxyResult, error)
+	// CachedImage returns an image from the disk cache, downloading and
+	// caching it on a miss.
+	CachedImage(ctx context.Context, imageURL, refererURL string) (*ProxyResult, error)
 	Close()
 }
 
 type proxyService struct {
 	session *azuretls.Session
 	anubis  *anubis.Solver
+	cache   *imageCache
+	// networkProxy picks the proxy of a request, as for the feed clients:
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+	networkProxy func(*http.Request) (*url.URL, error)
+
+	mu      sync.Mutex
+	proxied map[string]*azuretls.Session // by proxy URL
+}
+
+// NewProxyService creates the image proxy, caching up to cacheSize bytes of
+// images in cacheDir.
+func NewProxyService(anubisSolver *anubis.Solver, cacheDir string, cacheSize int64) ProxyService {
+	return &proxyService{
+		session:      newProxySession(),
+		anubis:       anubisSolver,
+		cache:        newImageCache(cacheDir, cacheSize),
+		networkProxy: http.ProxyFromEnvironment,
+		proxied:      make(map[string]*azuretls.Session),
+	}
 }
 
-func NewProxyService(anubisSolver *anubis.Solver) ProxyService {
+func newProxySession() *azuretls.Session {
 	session := azuretls.NewSession()
 	session.Browser = azuretls.Chrome
 	session.SetTimeout(proxyTimeout)
-
-	return &proxyService{
-		session: session,
-		anubis:  anubisSolver,
-	}
+	return session
 }
 
 func (s *proxyService) Close() {
 	if s.session != nil {
 		s.session.Close()
 	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, session := range s.proxied {
+		session.Close()
+	}
 }
 
 func (s *proxyService) FetchImage(ctx context.Context, imageURL, refererURL string) (*ProxyResult, error) {
 	return s.fetchImageWithRetry(ctx, imageURL, refererURL, "", 0)
 }
 
+func (s *proxyService) CachedImage(ctx context.Context, imageURL, refererURL string) (*ProxyResult, error) {
+	if cached, ok := s.cache.get(imageURL); ok {
+		return cached, nil
+	}
+	result, err := s.FetchImage(ctx, imageURL, refererURL)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(imageURL, result)
+	return result, nil
+}
+
 func (s *proxyService) fetchImageWithRetry(ctx context.Context, imageURL, refererURL, cookie string, retryCount int) (*ProxyResult, error) {
-	return s.doFetch(ctx, s.session, imageURL, refererURL, cookie, retryCount, false)
+	proxyURL, err := s.proxyFor(imageURL)
+	if err != nil {
+		return nil, err
+	}
+	session := s.session
+	if proxyURL != "" {
+		if session, err = s.proxiedSession(proxyURL); err != nil {
+			return nil, err
+		}
+	}
+	return s.doFetch(ctx, session, imageURL, refererURL, cookie, retryCount, false)
 }
 
 // fetchWithFreshSession creates a new azuretls session to avoid connection reuse after Anubis
 func (s *proxyService) fetchWithFreshSession(ctx context.Context, imageURL, refererURL, cookie string, retryCount int) (*ProxyResult, error) {
-	tempSession := azuretls.NewSession()
-	tempSession.Browser = azuretls.Chrome
-	tempSession.SetTimeout(proxyTimeout)
+	tempSession := newProxySession()
 	defer tempSession.Close()
+	proxyURL, err := s.proxyFor(imageURL)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL != "" {
+		if err := tempSession.SetProxy(proxyURL); err != nil {
+			return nil, fmt.Errorf("%w: proxy: %v", ErrFetchFailed, err)
+		}
+	}
 
 	return s.doFetch(ctx, tempSession, imageURL, refererURL, cookie, retryCount, true)
 }
 
+// proxyFor returns the network proxy to fetch imageURL through, or "" to
+// fetch it directly.
+func (s *proxyService) proxyFor(imageURL string) (string, error) {
+	parsedURL, err := url.Parse(imageURL)
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+	if s.networkProxy == nil {
+		return "", nil
+	}
+	proxyURL, err := s.networkProxy(&http.Request{URL: parsedURL})
+	if err != nil {
+		return "", fmt.Errorf("%w: proxy: %v", ErrFetchFailed, err)
+	}
+	if proxyURL == nil {
+		return "", nil
+	}
+	return proxyURL.String(), nil
+}
+
+// proxiedSession returns the shared session that fetches through proxyURL.
+func (s *proxyService) proxiedSession(proxyURL string) (*azuretls.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session, ok := s.proxied[proxyURL]; ok {
+		return session, nil
+	}
+	session := newProxySession()
+	if err := session.SetProxy(proxyURL); err != nil {
+		session.Close()
+		return nil, fmt.Errorf("%w: proxy: %v", ErrFetchFailed, err)
+	}
+	s.proxied[proxyURL] = session
+	return session, nil
+}
+
 // doFetch performs the actual HTTP request with the given session
 func (s *proxyService) doFetch(ctx context.Context, session *azuretls.Session, imageURL, refererURL, cookie string, retryCount int, isFreshSession bool) (*ProxyResult, error) {
 	parsedURL, err := url.Parse(imageURL)
@@ -117,6 +207,7 @@ func (s *proxyService) doFetch(ctx context.Context, session *azuretls.Session, i
 	if err != nil {
 		return nil, fmt.Errorf("%w: %v", ErrFetchFailed, err)
 	}
+	recordBandwidth(ctx, int64(len(resp.Body)))
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("%w: %d", ErrFetchFailed, resp.StatusCode)
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestProxyService_ProxyFor(t *testing.T) {
+	proxy, _ := url.Parse("http://proxy.internal:3128")
+	s := &proxyService{networkProxy: func(req *http.Request) (*url.URL, error) {
+		if req.URL.Hostname() == "intranet.example" {
+			return nil, nil
+		}
+		return proxy, nil
+	}}
+
+	if got, err := s.proxyFor("https://cdn.example.com/a.png"); err != nil || got != "http://proxy.internal:3128" {
+		t.Errorf("got %q, %v; want the network proxy", got, err)
+	}
+	if got, err := s.proxyFor("https://intranet.example/a.png"); err != nil || got != "" {
+		t.Errorf("got %q, %v; want a direct fetch", got, err)
+	}
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gist/backend/internal/repository"
+)
+
+const (
+	// readBatchWindow is how long the first read-status change waits for
+	// others to join its batch.
+	readBatchWindow = 50 * time.Millisecond
+	// readBatchMaxSize flushes a batch early once it holds this many entries.
+	readBatchMaxSize = 200
+	// readBatchTimeout bounds a batch's writes; they outlive any one request.
+	readBatchTimeout = 10 * time.Second
+)
+
+// readBatch is a set of pending read-status changes written together.
+type readBatch struct {
+	updates map[int64]bool
+	done    chan struct{}
+	err     error
+	// found holds the entries the batch updated; the others do not exist.
+	found map[int64]bool
+}
+
+// readStatusBatcher coalesces read-status changes arriving within a short
+// window into at most two UPDATE statements in one transaction, so marking
+// entries read while scrolling does not cost a write transaction per entry.
+// A later change to the same entry within the window overrides an earlier
+// one.
+type readStatusBatcher struct {
+	tx     repository.TxManager
+	window time.Duration
+
+	mu      sync.Mutex
+	pending *readBatch
+}
+
+func newReadStatusBatcher(tx repository.TxManager, window time.Duration) *readStatusBatcher {
+	return &readStatusBatcher{tx: tx, window: window}
+}
+
+// Submit queues a change and waits until its batch is written. It returns
+// ErrNotFound when the entry does not exist. If ctx ends first the change
+// is still written, but the caller stops waiting.
+func (b *readStatusBatcher) Submit(ctx context.Context, id int64, read bool) error {
+	b.mu.Lock()
+	batch := b.pending
+	if batch == nil {
+		batch = &readBatch{updates: make(map[int64]bool), done: make(chan struct{})}
+		b.pending = batch
+		time.AfterFunc(b.window, func() { b.flush(batch) })
+	}
+	batch.updates[id] = read
+	full := len(batch.updates) >= readBatchMaxSize
+	b.mu.Unlock()
+
+	if full {
+		b.flush(batch)
+	}
+
+	select {
+	case <-batch.done:
+		if batch.err != nil {
+			return batch.err
+		}
+		if !batch.found[id] {
+			return ErrNotFound
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// flush writes batch unless it has already been written.
+func (b *readStatusBatcher) flush(batch *readBatch) {
+	b.mu.Lock()
+	if b.pending != batch {
+		b.mu.Unlock()
+		return
+	}
+	b.pending = nil
+	b.mu.Unlock()
+
+	var readIDs, unreadIDs []int64
+	for id, read := range batch.updates {
+		if read {
+			readIDs = append(readIDs, id)
+		} else {
+			unreadIDs = append(unreadIDs, id)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), readBatchTimeout)
+	defer cancel()
+	found := make(map[int64]bool, len(batch.updates))
+	batch.err = b.tx.WithTx(ctx, func(repos repository.TxRepositories) error {
+		if err := writeReadStatus(ctx, repos.Entries, readIDs, true, found); err != nil {
+			return err
+		}
+		return writeReadStatus(ctx, repos.Entries, unreadIDs, false, found)
+	})
+	batch.found = found
+	close(batch.done)
+}
+
+// writeReadStatus sets the read status of ids and adds those that exist to found.
+func writeReadStatus(ctx context.Context, entries repository.EntryRepository, ids []int64, read bool, found map[int64]bool) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	updated, err := entries.UpdateReadStatus(ctx, ids, read)
+	if err != nil {
+		return err
+	}
+	for _, id := range updated {
+		found[id] = true
+	}
+	return nil
+}
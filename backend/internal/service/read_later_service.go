@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+// savedPagesTitle is the title the Saved pages feed is created with.
+const savedPagesTitle = "Saved pages"
+
+// Read-later sort orders
+const (
+	ReadLaterSortCreated = "created"
+	ReadLaterSortUpdated = "updated"
+)
+
+// SavePageParams is a web page to save.
+type SavePageParams struct {
+	URL string
+	// Title overrides the page title if set.
+	Title string
+	// HTML is the page as the client captured it. When set the page is not
+	// fetched, so pages behind a login can be saved.
+	HTML string
+}
+
+// ReadLaterQuery selects read-later entries the way read-it-later clients
+// such as the Wallabag apps ask for them.
+type ReadLaterQuery struct {
+	Read    *bool
+	Starred *bool
+	// Since leaves out entries not updated since then; zero for all.
+	Since time.Time
+	// SortBy is ReadLaterSortCreated (the default) or ReadLaterSortUpdated.
+	SortBy    string
+	Ascending bool
+	Limit     int
+	Offset    int
+}
+
+// ReadLaterService keeps the read-later list: web pages saved into the Saved
+// pages feed plus every starred entry.
+type ReadLaterService interface {
+	// Save stores a web page in the Saved pages feed, extracting its readable
+	// content and site icon. A page saved before is returned as it is.
+	Save(ctx context.Context, params SavePageParams) (model.Entry, error)
+	// List returns a page of the read-later entries matching q and how many
+	// match in all.
+	List(ctx context.Context, q ReadLaterQuery) ([]model.EntrySummary, int, error)
+	// FindByURL returns the saved page with a URL, or ErrNotFound.
+	FindByURL(ctx context.Context, pageURL string) (model.Entry, error)
+}
+
+type readLaterService struct {
+	entries     repository.EntryRepository
+	feeds       repository.FeedRepository
+	readability ReadabilityService
+	icons       IconService
+
+	// feedMu keeps concurrent saves from creating the feed twice
+	feedMu sync.Mutex
+}
+
+func NewReadLaterService(entries repository.EntryRepository, feeds repository.FeedRepository, readability ReadabilityService, icons IconService) ReadLaterService {
+	return &readLaterService{entries: entries, feeds: feeds, readability: readability, icons: icons}
+}
+
+func (s *readLaterService) Save(ctx context.Context, params SavePageParams) (model.Entry, error) {
+	pageURL := strings.TrimSpace(params.URL)
+	parsed, err := url.Parse(pageURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return model.Entry{}, ErrInvalid
+	}
+
+	feed, err := s.savedPagesFeed(ctx)
+	if err != nil {
+		return model.Entry{}, err
+	}
+	if existing, err := s.entries.GetByURL(ctx, feed.ID, pageURL); err == nil {
+		return existing, nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return model.Entry{}, fmt.Errorf("find saved page: %w", err)
+	}
+
+	// A page that cannot be extracted is still saved, as a link
+	var page *ReadablePage
+	if params.HTML != "" {
+		page, err = s.readability.ExtractHTML(ctx, pageURL, params.HTML)
+	} else {
+		page, err = s.readability.ExtractPage(ctx, pageURL)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return model.Entry{}, ctx.Err()
+		}
+		log.Printf("extract saved page %s: %v", pageURL, err)
+		page = &ReadablePage{}
+	}
+
+	title := strings.TrimSpace(params.Title)
+	if title == "" {
+		title = page.Title
+	}
+	if title == "" {
+		title = pageURL
+	}
+	publishedAt := time.Now()
+	if page.PublishedAt != nil {
+		publishedAt = *page.PublishedAt
+	}
+	entry := model.Entry{
+		FeedID:      feed.ID,
+		Title:       &title,
+		URL:         &pageURL,
+		PublishedAt: &publishedAt,
+	}
+	if page.Content != "" {
+		snippet := makeSnippet(page.Content)
+		entry.Content = &page.Content
+		entry.Snippet = &snippet
+		setContentFormats(&entry)
+	}
+	if page.Author != "" {
+		entry.Author = &page.Author
+	}
+	if page.ImageURL != "" {
+		entry.ThumbnailURL = &page.ImageURL
+	}
+	// The icon is optional; FetchAndSaveIcon reports failed downloads as ""
+	if iconPath, err := s.icons.FetchAndSaveIcon(ctx, page.IconURL, pageURL); err != nil {
+		log.Printf("fetch icon of saved page %s: %v", pageURL, err)
+	} else if iconPath != "" {
+		entry.IconPath = &iconPath
+	}
+	if err := s.entries.CreateOrUpdate(ctx, entry); err != nil {
+		return model.Entry{}, fmt.Errorf("save page: %w", err)
+	}
+
+	saved, err := s.entries.GetByURL(ctx, feed.ID, pageURL)
+	if err != nil {
+		return model.Entry{}, fmt.Errorf("load saved page: %w", err)
+	}
+	// The extracted content is the readable version already
+	if page.Content != "" {
+		if err := s.entries.UpdateReadableContent(ctx, saved.ID, page.Content, page.meta()); err != nil {
+			return model.Entry{}, fmt.Errorf("save readable content: %w", err)
+		}
+		saved.ReadableContent = &page.Content
+		saved.ReadableMeta = page.meta()
+	}
+	return saved, nil
+}
+
+func (s *readLaterService) List(ctx context.Context, q ReadLaterQuery) ([]model.EntrySummary, int, error) {
+	filter := repository.EntryListFilter{StarredOnly: true}
+	feed, err := s.feeds.FindByURL(ctx, model.SavedPagesURL)
+	if err != nil {
+		return nil, 0, err
+	}
+	if feed != nil {
+		filter = repository.EntryListFilter{StarredOrFeedID: &feed.ID}
+	}
+	all, err := s.entries.List(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("list read-later entries: %w", err)
+	}
+
+	// Read-later lists are short, so the rest is filtered here
+	matched := all[:0]
+	for _, entry := range all {
+		if q.Read != nil && entry.Read != *q.Read {
+			continue
+		}
+		if q.Starred != nil && entry.Starred != *q.Starred {
+			continue
+		}
+		if !q.Since.IsZero() && entry.UpdatedAt.Before(q.Since) {
+			continue
+		}
+		matched = append(matched, entry)
+	}
+	sortKey := func(e model.EntrySummary) time.Time { return e.CreatedAt }
+	if q.SortBy == ReadLaterSortUpdated {
+		sortKey = func(e model.EntrySummary) time.Time { return e.UpdatedAt }
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		a, b := sortKey(matched[i]), sortKey(matched[j])
+		if q.Ascending {
+			return a.Before(b)
+		}
+		return a.After(b)
+	})
+
+	total := len(matched)
+	if q.Offset >= total {
+		return []model.EntrySummary{}, total, nil
+	}
+	matched = matched[q.Offset:]
+	if q.Limit > 0 && len(matched) > q.Limit {
+		matched = matched[:q.Limit]
+	}
+	return matched, total, nil
+}
+
+func (s *readLaterService) FindByURL(ctx context.Context, pageURL string) (model.Entry, error) {
+	feed, err := s.feeds.FindByURL(ctx, model.SavedPagesURL)
+	if err != nil {
+		return model.Entry{}, err
+	}
+	if feed == nil {
+		return model.Entry{}, ErrNotFound
+	}
+	entry, err := s.entries.GetByURL(ctx, feed.ID, strings.TrimSpace(pageURL))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Entry{}, ErrNotFound
+		}
+		return model.Entry{}, err
+	}
+	return entry, nil
+}
+
+// savedPagesFeed returns the Saved pages feed, creating it on first use.
+func (s *readLaterService) savedPagesFeed(ctx context.Context) (model.Feed, error) {
+	s.feedMu.Lock()
+	defer s.feedMu.Unlock()
+
+	feed, err := s.feeds.FindByURL(ctx, model.SavedPagesURL)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	if feed != nil {
+		return *feed, nil
+	}
+	created, err := s.feeds.Create(ctx, model.Feed{Title: savedPagesTitle, URL: model.SavedPagesURL, Type: "article"})
+	if err != nil {
+		return model.Feed{}, fmt.Errorf("create saved pages feed: %w", err)
+	}
+	return created, nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+type fakeExtractor struct {
+	ReadabilityService
+	page    *ReadablePage
+	err     error
+	fetched bool   // ExtractPage was called
+	html    string // what ExtractHTML was given
+}
+
+func (f *fakeExtractor) ExtractPage(ctx context.Context, pageURL string) (*ReadablePage, error) {
+	f.fetched = true
+	return f.page, f.err
+}
+
+func (f *fakeExtractor) ExtractHTML(ctx context.Context, pageURL, pageHTML string) (*ReadablePage, error) {
+	f.html = pageHTML
+	return f.page, f.err
+}
+
+type fakeIcons struct {
+	IconService
+	iconURL string // what FetchAndSaveIcon was given
+}
+
+func (f *fakeIcons) FetchAndSaveIcon(ctx context.Context, feedImageURL, siteURL string) (string, error) {
+	f.iconURL = feedImageURL
+	return "example.com.png", nil
+}
+
+func TestReadLaterService_Save(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	extractor := &fakeExtractor{page: &ReadablePage{
+		Title:   "A page",
+		Author:  "Ann",
+		Content: "<p>Hello there</p>",
+		IconURL: "https://example.com/icon.png",
+	}}
+	icons := &fakeIcons{}
+	service := NewReadLaterService(entries, feeds, extractor, icons)
+	ctx := context.Background()
+	pageURL := "https://example.com/page"
+
+	feeds.EXPECT().FindByURL(ctx, model.SavedPagesURL).Return(nil, nil)
+	feeds.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, feed model.Feed) (model.Feed, error) {
+		if feed.URL != model.SavedPagesURL || !feed.IsVirtual() {
+			t.Errorf("unexpected saved pages feed %+v", feed)
+		}
+		feed.ID = 9
+		return feed, nil
+	})
+	entries.EXPECT().GetByURL(ctx, int64(9), pageURL).Return(model.Entry{}, sql.ErrNoRows)
+	entries.EXPECT().CreateOrUpdate(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		if *entry.Title != "A page" || *entry.Author != "Ann" || *entry.Snippet != "Hello there" || *entry.IconPath != "example.com.png" {
+			t.Errorf("unexpected saved entry %+v", entry)
+		}
+		return nil
+	})
+	entries.EXPECT().GetByURL(ctx, int64(9), pageURL).Return(model.Entry{ID: 3, FeedID: 9}, nil)
+	entries.EXPECT().UpdateReadableContent(ctx, int64(3), "<p>Hello there</p>", gomock.Any()).Return(nil)
+
+	// Captured HTML is extracted instead of fetching the page
+	html := "<html><body><p>Hello there</p></body></html>"
+	saved, err := service.Save(ctx, SavePageParams{URL: " " + pageURL + " ", HTML: html})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if extractor.fetched || extractor.html != html {
+		t.Errorf("expected the captured HTML to be extracted, fetched %v", extractor.fetched)
+	}
+	if icons.iconURL != "https://example.com/icon.png" {
+		t.Errorf("expected the page's favicon to be fetched, got %q", icons.iconURL)
+	}
+	if saved.ID != 3 || saved.ReadableContent == nil {
+		t.Errorf("expected the stored entry with readable content, got %+v", saved)
+	}
+}
+
+func TestReadLaterService_SaveUnreadablePage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewReadLaterService(entries, feeds, &fakeExtractor{err: errors.New("blocked")}, &fakeIcons{})
+	ctx := context.Background()
+	pageURL := "https://example.com/page"
+
+	// The page is still saved, as a link under the given title
+	feeds.EXPECT().FindByURL(ctx, model.SavedPagesURL).Return(&model.Feed{ID: 9}, nil)
+	entries.EXPECT().GetByURL(ctx, int64(9), pageURL).Return(model.Entry{}, sql.ErrNoRows)
+	entries.EXPECT().CreateOrUpdate(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		if *entry.Title != "Mine" || entry.Content != nil {
+			t.Errorf("unexpected saved entry %+v", entry)
+		}
+		return nil
+	})
+	entries.EXPECT().GetByURL(ctx, int64(9), pageURL).Return(model.Entry{ID: 3}, nil)
+
+	if _, err := service.Save(ctx, SavePageParams{URL: pageURL, Title: "Mine"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := service.Save(ctx, SavePageParams{URL: "ftp://example.com/file"}); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for a non-web URL, got %v", err)
+	}
+}
+
+func TestReadLaterService_List(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewReadLaterService(entries, feeds, &fakeExtractor{}, &fakeIcons{})
+	ctx := context.Background()
+
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	list := func() []model.EntrySummary {
+		return []model.EntrySummary{
+			{ID: 1, CreatedAt: base, UpdatedAt: base.Add(3 * time.Hour)},
+			{ID: 2, CreatedAt: base.Add(time.Hour), UpdatedAt: base.Add(time.Hour), Read: true},
+			{ID: 3, CreatedAt: base.Add(2 * time.Hour), UpdatedAt: base.Add(2 * time.Hour), Starred: true},
+		}
+	}
+	feedID := int64(9)
+	feeds.EXPECT().FindByURL(ctx, model.SavedPagesURL).Return(&model.Feed{ID: feedID}, nil).AnyTimes()
+	entries.EXPECT().List(ctx, repository.EntryListFilter{StarredOrFeedID: &feedID}).DoAndReturn(
+		func(context.Context, repository.EntryListFilter) ([]model.EntrySummary, error) { return list(), nil }).AnyTimes()
+
+	unread := false
+	tests := []struct {
+		name  string
+		query ReadLaterQuery
+		ids   []int64
+		total int
+	}{
+		{"newest first", ReadLaterQuery{}, []int64{3, 2, 1}, 3},
+		{"unread", ReadLaterQuery{Read: &unread}, []int64{3, 1}, 2},
+		{"by update, oldest first", ReadLaterQuery{SortBy: ReadLaterSortUpdated, Ascending: true}, []int64{2, 3, 1}, 3},
+		{"since", ReadLaterQuery{Since: base.Add(2 * time.Hour)}, []int64{3, 1}, 2},
+		{"second page", ReadLaterQuery{Limit: 2, Offset: 2}, []int64{1}, 3},
+		{"past the end", ReadLaterQuery{Limit: 2, Offset: 4}, nil, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := service.List(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			var ids []int64
+			for _, entry := range got {
+				ids = append(ids, entry.ID)
+			}
+			if total != tt.total || len(ids) != len(tt.ids) {
+				t.Fatalf("expected %v of %d, got %v of %d", tt.ids, tt.total, ids, total)
+			}
+			for i := range ids {
+				if ids[i] != tt.ids[i] {
+					t.Fatalf("expected %v, got %v", tt.ids, ids)
+				}
+			}
+		})
+	}
+}
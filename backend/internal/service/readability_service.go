@@ -12,12 +12,13 @@ import (
 	"strings"
 	"time"
 
+	readability "codeberg.org/readeck/go-readability/v2"
 	"github.com/Noooste/azuretls-client"
 	"github.com/microcosm-cc/bluemonday"
 	"golang.org/x/net/html"
-	readability "codeberg.org/readeck/go-readability/v2"
 
 	"gist/backend/internal/config"
+	"gist/backend/internal/model"
 	"gist/backend/internal/repository"
 	"gist/backend/internal/service/anubis"
 )
@@ -25,18 +26,56 @@ import (
 const readabilityTimeout = 30 * time.Second
 
 type ReadabilityService interface {
-	FetchReadableContent(ctx context.Context, entryID int64) (string, error)
+	// FetchReadableContent extracts an entry's page, through its feed's
+	// full-text service if it has one, and stores the result with what
+	// extraction learned about the page. Content stored before is returned
+	// as it is unless force is set.
+	FetchReadableContent(ctx context.Context, entryID int64, force bool) (string, model.ReadableMeta, error)
+	// ExtractPage fetches a web page and extracts its main content, for pages
+	// saved outside of any feed. A page fetched shortly before is taken from
+	// the page cache.
+	ExtractPage(ctx context.Context, pageURL string) (*ReadablePage, error)
+	// ExtractHTML extracts the main content of a page already fetched, such
+	// as the DOM a browser extension captured behind a login.
+	ExtractHTML(ctx context.Context, pageURL, pageHTML string) (*ReadablePage, error)
+	// PendingReadable returns the IDs of a feed's entries without readable
+	// content, unread ones only when unreadOnly is set, for
+	// FetchReadableTask. It returns ErrNotFound when there is no such feed.
+	PendingReadable(ctx context.Context, feedID int64, unreadOnly bool) ([]int64, error)
 	Close()
 }
 
+// ReadablePage is a web page reduced to its main content.
+type ReadablePage struct {
+	Title       string
+	Author      string
+	Content     string // HTML
+	ImageURL    string
+	IconURL     string // the page's favicon, if it declares one
+	PublishedAt *time.Time
+	Language    string
+	Direction   string // "rtl" or "ltr", empty when unknown
+	SiteName    string
+}
+
+// meta is what is stored with the page's content as an entry's readable version.
+func (p *ReadablePage) meta() model.ReadableMeta {
+	return model.ReadableMeta{Language: p.Language, Direction: p.Direction, Byline: p.Author, SiteName: p.SiteName}
+}
+
 type readabilityService struct {
 	entries   repository.EntryRepository
+	feeds     repository.FeedRepository
 	session   *azuretls.Session
-	sanitizer *bluemonday.Policy
+	sanitizer *bluemonday.Policy // readies pages for readability
+	content   *ContentSanitizer  // cleans what is extracted for storage
 	anubis    *anubis.Solver
+	// fullText calls the feeds' full-text services
+	fullText *http.Client
+	pages    *PageCache // nil caches nothing
 }
 
-func NewReadabilityService(entries repository.EntryRepository, anubisSolver *anubis.Solver) ReadabilityService {
+func NewReadabilityService(entries repository.EntryRepository, feeds repository.FeedRepository, settings SettingsService, anubisSolver *anubis.Solver, pages *PageCache) ReadabilityService {
 	// Create a sanitizer policy similar to DOMPurify
 	// This removes scripts and other elements that interfere with readability parsing
 	p := bluemonday.UGCPolicy()
@@ -51,37 +90,79 @@ func NewReadabilityService(entries repository.EntryRepository, anubisSolver *anu
 
 	return &readabilityService{
 		entries:   entries,
+		feeds:     feeds,
 		session:   session,
 		sanitizer: p,
+		content:   NewContentSanitizer(settings),
 		anubis:    anubisSolver,
+		fullText:  &http.Client{Timeout: readabilityTimeout},
+		pages:     pages,
 	}
 }
 
-func (s *readabilityService) FetchReadableContent(ctx context.Context, entryID int64) (string, error) {
+func (s *readabilityService) FetchReadableContent(ctx context.Context, entryID int64, force bool) (string, model.ReadableMeta, error) {
 	entry, err := s.entries.GetByID(ctx, entryID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return "", ErrNotFound
+			return "", model.ReadableMeta{}, ErrNotFound
 		}
-		return "", err
+		return "", model.ReadableMeta{}, err
 	}
 
 	// Return cached content if available
-	if entry.ReadableContent != nil && *entry.ReadableContent != "" {
-		return *entry.ReadableContent, nil
+	if !force && entry.ReadableContent != nil && *entry.ReadableContent != "" {
+		return *entry.ReadableContent, entry.ReadableMeta, nil
 	}
 
 	// Validate URL
 	if entry.URL == nil || *entry.URL == "" {
-		return "", ErrInvalid
+		return "", model.ReadableMeta{}, ErrInvalid
+	}
+
+	page, err := s.extractEntry(ctx, entry.FeedID, *entry.URL)
+	if err != nil {
+		return "", model.ReadableMeta{}, err
+	}
+
+	// Save to database
+	meta := page.meta()
+	if err := s.entries.UpdateReadableContent(ctx, entryID, page.Content, meta); err != nil {
+		return "", model.ReadableMeta{}, err
+	}
+
+	return page.Content, meta, nil
+}
+
+func (s *readabilityService) ExtractPage(ctx context.Context, pageURL string) (*ReadablePage, error) {
+	body, ok := s.pages.Get(pageURL)
+	if !ok {
+		// Fetch with Chrome fingerprint and Anubis support
+		var err error
+		body, err = s.fetchWithChrome(ctx, pageURL, "", 0)
+		if err != nil {
+			return nil, err
+		}
+		s.pages.Put(pageURL, body)
 	}
+	return s.extractClean(ctx, pageURL, body)
+}
+
+func (s *readabilityService) ExtractHTML(ctx context.Context, pageURL, pageHTML string) (*ReadablePage, error) {
+	return s.extractClean(ctx, pageURL, []byte(pageHTML))
+}
 
-	// Fetch with Chrome fingerprint and Anubis support
-	body, err := s.fetchWithChrome(ctx, *entry.URL, "", 0)
+// extractClean extracts a page and sanitizes its content for storage.
+func (s *readabilityService) extractClean(ctx context.Context, pageURL string, body []byte) (*ReadablePage, error) {
+	page, err := s.extract(pageURL, body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	page.Content = s.content.Sanitize(ctx, page.Content)
+	return page, nil
+}
 
+// extract parses the main content and metadata out of a page body.
+func (s *readabilityService) extract(pageURL string, body []byte) (*ReadablePage, error) {
 	// Process lazy-loaded images before sanitization
 	// This converts data-src/data-lazy-src/data-original to src
 	// and removes placeholder SVG images
@@ -92,35 +173,49 @@ func (s *readabilityService) FetchReadableContent(ctx context.Context, entryID i
 	sanitized := s.sanitizer.Sanitize(string(body))
 
 	// Parse URL for readability
-	parsedURL, err := url.Parse(*entry.URL)
+	parsedURL, err := url.Parse(pageURL)
 	if err != nil {
-		return "", fmt.Errorf("parse URL failed: %w", err)
+		return nil, fmt.Errorf("parse URL failed: %w", err)
 	}
 
 	// Parse with readability
 	parser := readability.NewParser()
 	article, err := parser.Parse(strings.NewReader(sanitized), parsedURL)
 	if err != nil {
-		return "", fmt.Errorf("parse content failed: %w", err)
+		return nil, fmt.Errorf("parse content failed: %w", err)
 	}
 
 	// Render HTML content
 	var buf bytes.Buffer
 	if err := article.RenderHTML(&buf); err != nil {
-		return "", fmt.Errorf("render failed: %w", err)
+		return nil, fmt.Errorf("render failed: %w", err)
 	}
 
 	content := buf.String()
 	if content == "" {
-		return "", ErrInvalid
+		return nil, ErrInvalid
 	}
 
-	// Save to database
-	if err := s.entries.UpdateReadableContent(ctx, entryID, content); err != nil {
-		return "", err
+	page := &ReadablePage{Content: content}
+	// Sanitizing drops <head>, <html> and <body>, so metadata comes from the
+	// page as served
+	lang, dir := declaredLanguage(body)
+	if meta, err := parser.Parse(bytes.NewReader(body), parsedURL); err == nil {
+		page.Title = strings.TrimSpace(meta.Title())
+		page.Author = strings.TrimSpace(meta.Byline())
+		page.ImageURL = meta.ImageURL()
+		page.IconURL = meta.Favicon()
+		page.SiteName = strings.TrimSpace(meta.SiteName())
+		if published, err := meta.PublishedTime(); err == nil && !published.IsZero() {
+			page.PublishedAt = &published
+		}
+		if lang == "" {
+			lang = strings.TrimSpace(meta.Language())
+		}
 	}
-
-	return content, nil
+	page.Language = lang
+	page.Direction = textDirection(dir, lang)
+	return page, nil
 }
 
 // Close releases resources held by the service
@@ -182,6 +277,7 @@ func (s *readabilityService) doFetch(ctx context.Context, session *azuretls.Sess
 			headers = append(headers, []string{"cookie", cachedCookie})
 		}
 	}
+	headers = overrideOrderedHeaders(headers, requestOverridesFrom(ctx))
 
 	resp, err := session.Do(&azuretls.Request{
 		Method:         http.MethodGet,
@@ -191,6 +287,7 @@ func (s *readabilityService) doFetch(ctx context.Context, session *azuretls.Sess
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
+	recordBandwidth(ctx, int64(len(resp.Body)))
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// ReadableBatchResult summarizes a FetchReadableTask.
+type ReadableBatchResult struct {
+	Extracted int `json:"extracted"`
+	Failed    int `json:"failed"`
+}
+
+func (s *readabilityService) PendingReadable(ctx context.Context, feedID int64, unreadOnly bool) ([]int64, error) {
+	if _, err := s.feeds.GetByID(ctx, feedID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get feed: %w", err)
+	}
+	return s.entries.ListWithoutReadable(ctx, feedID, unreadOnly)
+}
+
+// FetchReadableTask extracts and stores the readable content of each entry,
+// one page at a time as they usually share a host. An entry that fails is
+// counted and skipped; it can be extracted again on demand.
+func FetchReadableTask(readability ReadabilityService, entryIDs []int64) TaskFunc {
+	return func(ctx context.Context, h TaskHandle) (interface{}, error) {
+		var result ReadableBatchResult
+		for i, id := range entryIDs {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			if _, _, err := readability.FetchReadableContent(ctx, id, false); err != nil {
+				if ctx.Err() != nil {
+					return result, ctx.Err()
+				}
+				log.Printf("readable content of entry %d: %v", id, err)
+				result.Failed++
+			} else {
+				result.Extracted++
+			}
+			h.Progress(i+1, len(entryIDs), "")
+		}
+		return result, nil
+	}
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+type fakeReadable struct {
+	ReadabilityService
+	mu      sync.Mutex
+	failing map[int64]bool
+	fetched []int64
+}
+
+func (f *fakeReadable) FetchReadableContent(ctx context.Context, entryID int64, force bool) (string, model.ReadableMeta, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.fetched = append(f.fetched, entryID)
+	if f.failing[entryID] {
+		return "", model.ReadableMeta{}, errors.New("HTTP 403")
+	}
+	return "<p>Readable</p>", model.ReadableMeta{}, nil
+}
+
+func TestFetchReadableTask(t *testing.T) {
+	readable := &fakeReadable{failing: map[int64]bool{2: true}}
+	var progress []int
+	handle := TaskHandle{progress: func(current, total int, _ string) {
+		if total != 3 {
+			t.Errorf("expected a total of 3, got %d", total)
+		}
+		progress = append(progress, current)
+	}}
+
+	result, err := FetchReadableTask(readable, []int64{1, 2, 3})(context.Background(), handle)
+	if err != nil {
+		t.Fatalf("task: %v", err)
+	}
+	if result != (ReadableBatchResult{Extracted: 2, Failed: 1}) {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !reflect.DeepEqual(readable.fetched, []int64{1, 2, 3}) || !reflect.DeepEqual(progress, []int{1, 2, 3}) {
+		t.Errorf("unexpected fetches %v or progress %v", readable.fetched, progress)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FetchReadableTask(readable, []int64{4})(ctx, handle); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected a cancelled task to stop, got %v", err)
+	}
+}
+
+func TestReadabilityService_PendingReadable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewReadabilityService(entries, feeds, nil, nil, nil)
+	defer service.Close()
+	ctx := context.Background()
+
+	feeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1}, nil)
+	entries.EXPECT().ListWithoutReadable(ctx, int64(1), true).Return([]int64{5, 4}, nil)
+	if ids, err := service.PendingReadable(ctx, 1, true); err != nil || !reflect.DeepEqual(ids, []int64{5, 4}) {
+		t.Errorf("unexpected pending entries %v, %v", ids, err)
+	}
+
+	feeds.EXPECT().GetByID(ctx, int64(2)).Return(model.Feed{}, sql.ErrNoRows)
+	if _, err := service.PendingReadable(ctx, 2, false); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
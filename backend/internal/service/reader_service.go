@@ -0,0 +1,136 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"gist/backend/internal/readerview"
+	"gist/backend/internal/repository"
+)
+
+const (
+	// readerPageSize is how many entries each reader list page shows.
+	readerPageSize = 30
+	// ReaderPath is where the reader is served; its links are absolute paths.
+	ReaderPath = "/api/reader"
+)
+
+// ReaderService renders unread entries as plain HTML pages that need no
+// scripts, for e-ink devices and text browsers that cannot run the app.
+type ReaderService interface {
+	// Unread renders a page of unread entries, newest first, continuing
+	// after cursor, an EntryCursorOf, when it is not empty.
+	Unread(ctx context.Context, cursor string) ([]byte, error)
+	// Article renders an entry, preferring its readable content.
+	Article(ctx context.Context, id int64) ([]byte, error)
+	// MarkAsRead marks an entry as read or unread.
+	MarkAsRead(ctx context.Context, id int64, read bool) error
+}
+
+type readerService struct {
+	entries EntryService
+	feeds   repository.FeedRepository
+}
+
+func NewReaderService(entries EntryService, feeds repository.FeedRepository) ReaderService {
+	return &readerService{entries: entries, feeds: feeds}
+}
+
+func (s *readerService) Unread(ctx context.Context, cursor string) ([]byte, error) {
+	// Fetch one more than a page to know whether another follows
+	entries, err := s.entries.List(ctx, EntryListParams{
+		UnreadOnly: true,
+		After:      cursor,
+		Limit:      readerPageSize + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(entries) > readerPageSize
+	if hasMore {
+		entries = entries[:readerPageSize]
+	}
+	feedTitles, err := s.feedTitles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := readerview.List{Title: "Gist · Unread", Items: make([]readerview.Item, len(entries))}
+	for i, entry := range entries {
+		list.Items[i] = readerview.Item{
+			Title:     trimmedValue(entry.Title),
+			FeedTitle: feedTitles[entry.FeedID],
+			Published: entry.PublishedAt,
+			Snippet:   ShortSnippet(trimmedValue(entry.Snippet)),
+			Href:      readerArticleHref(entry.ID),
+		}
+	}
+	if hasMore {
+		list.Next = ReaderPath + "?after=" + url.QueryEscape(EntryCursorOf(entries[len(entries)-1]))
+	}
+	if cursor != "" {
+		list.Start = ReaderPath
+	}
+
+	var buf bytes.Buffer
+	if err := readerview.RenderList(&buf, list); err != nil {
+		return nil, fmt.Errorf("render reader list: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *readerService) Article(ctx context.Context, id int64) ([]byte, error) {
+	entry, err := s.entries.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	feedTitle := ""
+	if feed, err := s.feeds.GetByID(ctx, entry.FeedID); err == nil {
+		feedTitle = feed.Title
+	}
+
+	content := ""
+	if entry.ReadableContent != nil && *entry.ReadableContent != "" {
+		content = *entry.ReadableContent
+	} else if entry.Content != nil {
+		content = *entry.Content
+	}
+	var buf bytes.Buffer
+	if err := readerview.RenderArticle(&buf, readerview.Article{
+		Title:     trimmedValue(entry.Title),
+		Author:    trimmedValue(entry.Author),
+		FeedTitle: feedTitle,
+		SourceURL: trimmedValue(entry.URL),
+		Published: entry.PublishedAt,
+		Content:   content,
+		Read:      entry.Read,
+		Back:      ReaderPath,
+		MarkRead:  readerArticleHref(entry.ID) + "/read",
+	}); err != nil {
+		return nil, fmt.Errorf("render reader article: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *readerService) MarkAsRead(ctx context.Context, id int64, read bool) error {
+	return s.entries.MarkAsRead(ctx, id, read)
+}
+
+func (s *readerService) feedTitles(ctx context.Context) (map[int64]string, error) {
+	feeds, err := s.feeds.List(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("list feeds: %w", err)
+	}
+	titles := make(map[int64]string, len(feeds))
+	for _, feed := range feeds {
+		titles[feed.ID] = feed.Title
+	}
+	return titles, nil
+}
+
+func readerArticleHref(id int64) string {
+	return ReaderPath + "/" + strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestReaderService_Unread(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewReaderService(NewEntryService(nil, entries, feeds, testutil.NewMockFolderRepository(ctrl)), feeds)
+	ctx := context.Background()
+
+	published := time.Date(2025, 3, 1, 8, 0, 0, 0, time.UTC)
+	page := make([]model.EntrySummary, readerPageSize+1)
+	for i := range page {
+		page[i] = model.EntrySummary{ID: int64(100 - i), FeedID: 7, PublishedAt: &published}
+	}
+	title, snippet := "Fish & Chips", strings.Repeat("word ", 60)
+	page[0].Title, page[0].Snippet = &title, &snippet
+
+	entries.EXPECT().List(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, filter repository.EntryListFilter) ([]model.EntrySummary, error) {
+		if !filter.UnreadOnly || filter.Limit != readerPageSize+1 || filter.After == nil || filter.After.ID != 200 {
+			t.Errorf("unexpected filter %+v", filter)
+		}
+		return page, nil
+	})
+	feeds.EXPECT().List(ctx, nil).Return([]model.Feed{{ID: 7, Title: "Food Blog"}}, nil)
+
+	payload, err := service.Unread(ctx, "2025-03-02T00:00:00Z,200")
+	if err != nil {
+		t.Fatalf("unread: %v", err)
+	}
+	doc := string(payload)
+
+	for _, want := range []string{
+		`<a href="/api/reader/100">Fish &amp; Chips</a>`,
+		"Food Blog · 2025-03-01 08:00",
+		`<a href="/api/reader">Newest</a>`,
+		`<a href="/api/reader?after=2025-03-01T08%3A00%3A00Z%2C71">Older</a>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("missing %q in\n%s", want, doc)
+		}
+	}
+	if strings.Count(doc, "<li>") != readerPageSize {
+		t.Errorf("expected %d entries, got %d", readerPageSize, strings.Count(doc, "<li>"))
+	}
+	if strings.Contains(doc, strings.Repeat("word ", 40)) {
+		t.Error("snippet should be shortened")
+	}
+	if strings.Contains(doc, "<script") {
+		t.Error("reader pages should not contain scripts")
+	}
+}
+
+func TestReaderService_Article(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	entries := testutil.NewMockEntryRepository(ctrl)
+	feeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewReaderService(NewEntryService(nil, entries, feeds, testutil.NewMockFolderRepository(ctrl)), feeds)
+	ctx := context.Background()
+
+	title, url := "Saved article", "https://example.com/posts/1"
+	content, readable := "<p>Feed text</p>", `<p>Readable <b>text</b><script>alert(1)</script><a href="/about">about</a></p>`
+	entries.EXPECT().GetByID(ctx, int64(5)).Return(model.Entry{
+		ID: 5, FeedID: 7, Title: &title, URL: &url, Content: &content, ReadableContent: &readable, Read: true,
+	}, nil)
+	feeds.EXPECT().GetByID(ctx, int64(7)).Return(model.Feed{ID: 7, Title: "Blog"}, nil)
+
+	payload, err := service.Article(ctx, 5)
+	if err != nil {
+		t.Fatalf("article: %v", err)
+	}
+	doc := string(payload)
+
+	for _, want := range []string{
+		"<h1>Saved article</h1>",
+		`<p>Readable <b>text</b><a href="https://example.com/about">about</a></p>`,
+		`<form method="post" action="/api/reader/5/read">`,
+		`<input type="hidden" name="read" value="false">`,
+		"Mark as unread",
+		`<a href="/api/reader">Back</a>`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("missing %q in\n%s", want, doc)
+		}
+	}
+	if strings.Contains(doc, "Feed text") || strings.Contains(doc, "alert(1)") {
+		t.Errorf("expected only the cleaned readable content in\n%s", doc)
+	}
+
+	entries.EXPECT().GetByID(ctx, int64(6)).Return(model.Entry{}, sql.ErrNoRows)
+	if _, err := service.Article(ctx, 6); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
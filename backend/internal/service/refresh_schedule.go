@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/events"
+	"gist/backend/internal/model"
+)
+
+const (
+	// RefreshCheckInterval is how often the scheduler looks for feeds due a
+	// refresh; it bounds how precisely a feed's interval is kept.
+	RefreshCheckInterval = 5 * time.Minute
+	// DefaultRefreshInterval applies to feeds without their own interval in
+	// the fixed refresh mode.
+	DefaultRefreshInterval = 15 * time.Minute
+
+	// Bounds of a feed's own interval, in minutes
+	MinRefreshIntervalMinutes = 5
+	MaxRefreshIntervalMinutes = 7 * 24 * 60
+
+	// adaptiveWindow is how far back the adaptive mode looks at a feed's
+	// entries; it aims for about two refreshes per entry in that time,
+	// between adaptiveMinInterval and adaptiveMaxInterval.
+	adaptiveWindow      = 30 * 24 * time.Hour
+	adaptiveMinInterval = 15 * time.Minute
+	adaptiveMaxInterval = 24 * time.Hour
+
+	// refreshSpread is how much of a check interval a scheduled refresh
+	// spreads its fetches over, so they do not all hit the network, and hosts
+	// many feeds share, at the same moment. The rest of the interval is left
+	// for the last fetches to finish before the next check.
+	refreshSpread = RefreshCheckInterval - time.Minute
+
+	// refreshDueSlack lets a feed due just after a check go in that check,
+	// rather than waiting for the next one.
+	refreshDueSlack = time.Minute
+
+	// maxFailureBackoff caps how far a failing feed's next refresh is pushed
+	// back.
+	maxFailureBackoff = 24 * time.Hour
+)
+
+// refreshInterval is the time until the feed is next due: its own interval
+// when set, otherwise the default or, in the adaptive mode, one derived from
+// how often it published lately.
+func (s *refreshService) refreshInterval(ctx context.Context, feed model.Feed) time.Duration {
+	if feed.RefreshInterval != nil {
+		return time.Duration(*feed.RefreshInterval) * time.Minute
+	}
+	if s.refreshMode != config.RefreshAdaptive {
+		return DefaultRefreshInterval
+	}
+	count, err := s.entries.CountPublishedSince(ctx, feed.ID, time.Now().Add(-adaptiveWindow))
+	if err != nil {
+		log.Printf("count recent entries of feed %d: %v", feed.ID, err)
+		return DefaultRefreshInterval
+	}
+	return adaptiveInterval(count)
+}
+
+// adaptiveInterval spreads two refreshes per entry published in the window.
+func adaptiveInterval(count int64) time.Duration {
+	if count == 0 {
+		return adaptiveMaxInterval
+	}
+	interval := adaptiveWindow / time.Duration(2*count)
+	if interval < adaptiveMinInterval {
+		return adaptiveMinInterval
+	}
+	if interval > adaptiveMaxInterval {
+		return adaptiveMaxInterval
+	}
+	return interval.Round(time.Minute)
+}
+
+// failureBackoff doubles the interval for each refresh in a row that failed,
+// up to maxFailureBackoff; an interval already longer is kept.
+func failureBackoff(interval time.Duration, failures int) time.Duration {
+	if interval >= maxFailureBackoff {
+		return interval
+	}
+	for ; failures > 0; failures-- {
+		interval *= 2
+		if interval >= maxFailureBackoff {
+			return maxFailureBackoff
+		}
+	}
+	return interval
+}
+
+// scheduleNext sets the feed's next refresh an interval after this one
+// started, backed off by the refreshes in a row that failed.
+func (s *refreshService) scheduleNext(ctx context.Context, feed model.Feed, started time.Time) {
+	if ctx.Err() != nil {
+		return
+	}
+	interval := failureBackoff(s.refreshInterval(ctx, feed), feed.ConsecutiveFailures)
+	if err := s.feeds.ScheduleRefresh(ctx, feed.ID, started.Add(interval)); err != nil {
+		log.Printf("schedule refresh of feed %d: %v", feed.ID, err)
+	}
+}
+
+// refreshDue reports whether the scheduler should refresh the feed now.
+// Feeds never refreshed are due straight away.
+func refreshDue(feed model.Feed, now time.Time) bool {
+	return feed.NextRefreshAt == nil || feed.NextRefreshAt.Before(now.Add(refreshDueSlack))
+}
+
+// refreshOffset is when into a spread of the given length a feed is fetched.
+// It is derived from a hash of the feed's ID, so it stays the same from one
+// run to the next while feeds added together land apart.
+func refreshOffset(feedID int64, spread time.Duration) time.Duration {
+	if spread <= 0 {
+		return 0
+	}
+	var id [8]byte
+	binary.LittleEndian.PutUint64(id[:], uint64(feedID))
+	h := fnv.New64a()
+	h.Write(id[:])
+	return time.Duration(h.Sum64() % uint64(spread))
+}
+
+// waitUntil waits for at, or less if hurry is closed first. It returns false
+// if ctx ends first.
+func waitUntil(ctx context.Context, at time.Time, hurry <-chan struct{}) bool {
+	wait := time.Until(at)
+	if wait <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-hurry:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// RefreshDueTask runs RefreshDue as a task.
+func RefreshDueTask(refresh RefreshService) TaskFunc {
+	return func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, refreshAllTimeout)
+		defer cancel()
+		return nil, refresh.RefreshDue(ctx)
+	}
+}
+
+// feedDisableThreshold loads the failed refreshes in a row after which a
+// feed is disabled; the default applies without settings.
+func feedDisableThreshold(ctx context.Context, settings SettingsService) int {
+	if settings == nil {
+		return DefaultFeedDisableThreshold
+	}
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil {
+		return DefaultFeedDisableThreshold
+	}
+	return general.FeedDisableThreshold
+}
+
+// trackFailures counts a failed refresh towards the feed's backoff and
+// disables the feed once the count reaches the threshold; a successful one
+// resets the count and enables the feed again.
+func (s *refreshService) trackFailures(ctx context.Context, feed *model.Feed, outcome string, errMsg *string) {
+	if ctx.Err() != nil {
+		return
+	}
+	if outcome == FetchOK || outcome == FetchNotModified {
+		if feed.ConsecutiveFailures == 0 && feed.DisabledAt == nil {
+			return
+		}
+		if err := s.feeds.ClearFailures(ctx, feed.ID); err != nil {
+			log.Printf("clear failures of feed %d: %v", feed.ID, err)
+			return
+		}
+		if feed.DisabledAt != nil {
+			log.Printf("feed %d (%s) enabled again after a successful refresh", feed.ID, feed.Title)
+		}
+		feed.ConsecutiveFailures, feed.DisabledAt = 0, nil
+		return
+	}
+
+	failures, err := s.feeds.IncrementFailures(ctx, feed.ID)
+	if err != nil {
+		log.Printf("count failure of feed %d: %v", feed.ID, err)
+		return
+	}
+	feed.ConsecutiveFailures = failures
+	threshold := feedDisableThreshold(ctx, s.settings)
+	if threshold == 0 || failures < threshold || feed.DisabledAt != nil {
+		return
+	}
+	now := time.Now()
+	if err := s.feeds.Disable(ctx, feed.ID, now); err != nil {
+		log.Printf("disable feed %d: %v", feed.ID, err)
+		return
+	}
+	feed.DisabledAt = &now
+	event := events.FeedDisabled{FeedID: feed.ID, Title: feed.Title, Failures: failures}
+	if errMsg != nil {
+		event.Error = *errMsg
+	}
+	log.Printf("disabled feed %d (%s) after %d failed refreshes in a row: %s", feed.ID, feed.Title, failures, event.Error)
+	s.events.Publish(events.TypeFeedDisabled, event)
+}
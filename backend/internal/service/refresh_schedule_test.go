@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/events"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+)
+
+func TestAdaptiveInterval(t *testing.T) {
+	cases := []struct {
+		count int64
+		want  time.Duration
+	}{
+		{0, 24 * time.Hour},
+		{1, 24 * time.Hour},
+		{30, 12 * time.Hour}, // daily
+		{300, 72 * time.Minute},
+		{5000, 15 * time.Minute},
+	}
+	for _, tc := range cases {
+		if got := adaptiveInterval(tc.count); got != tc.want {
+			t.Errorf("adaptiveInterval(%d) = %v, want %v", tc.count, got, tc.want)
+		}
+	}
+}
+
+func TestFailureBackoff(t *testing.T) {
+	cases := []struct {
+		interval time.Duration
+		failures int
+		want     time.Duration
+	}{
+		{15 * time.Minute, 0, 15 * time.Minute},
+		{15 * time.Minute, 1, 30 * time.Minute},
+		{15 * time.Minute, 3, 2 * time.Hour},
+		{15 * time.Minute, 10, 24 * time.Hour},
+		{15 * time.Minute, 1000, 24 * time.Hour},
+		{48 * time.Hour, 3, 48 * time.Hour},
+	}
+	for _, tc := range cases {
+		if got := failureBackoff(tc.interval, tc.failures); got != tc.want {
+			t.Errorf("failureBackoff(%v, %d) = %v, want %v", tc.interval, tc.failures, got, tc.want)
+		}
+	}
+}
+
+func TestRefreshService_RefreshDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var mu sync.Mutex
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched = append(fetched, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewRefreshService(mockFeeds, mockEntries, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshAdaptive)
+	service.(*refreshService).spread = 0
+	ctx := context.Background()
+
+	now := time.Now()
+	past, later := now.Add(-time.Minute), now.Add(time.Hour)
+	hourly := 60
+	mockFeeds.EXPECT().List(gomock.Any(), nil).Return([]model.Feed{
+		{ID: 1, Title: "Due", URL: server.URL + "/due", NextRefreshAt: &past, RefreshInterval: &hourly},
+		{ID: 2, Title: "New", URL: server.URL + "/new"},
+		{ID: 3, Title: "Waiting", URL: server.URL + "/waiting", NextRefreshAt: &later},
+	}, nil)
+	// Only the feed without an interval of its own looks at its history
+	mockEntries.EXPECT().CountPublishedSince(gomock.Any(), int64(2), gomock.Any()).Return(int64(30), nil)
+
+	scheduled := map[int64]time.Time{}
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, id int64, at time.Time) error {
+			mu.Lock()
+			scheduled[id] = at
+			mu.Unlock()
+			return nil
+		}).Times(2)
+
+	if err := service.RefreshDue(ctx); err != nil {
+		t.Fatalf("refresh due: %v", err)
+	}
+
+	sort.Strings(fetched)
+	if len(fetched) != 2 || fetched[0] != "/due" || fetched[1] != "/new" {
+		t.Errorf("expected only due feeds fetched, got %v", fetched)
+	}
+	if got := scheduled[1].Sub(now); got < time.Hour || got > time.Hour+time.Minute {
+		t.Errorf("expected the feed's own hourly interval, next refresh in %v", got)
+	}
+	if got := scheduled[2].Sub(now); got < 12*time.Hour || got > 12*time.Hour+time.Minute {
+		t.Errorf("expected an adaptive interval of 12h for a daily feed, next refresh in %v", got)
+	}
+}
+
+func TestRefreshOffset(t *testing.T) {
+	spread := refreshSpread
+	seen := map[time.Duration]bool{}
+	for id := int64(1); id <= 50; id++ {
+		offset := refreshOffset(id, spread)
+		if offset < 0 || offset >= spread {
+			t.Fatalf("offset %v of feed %d is outside the spread", offset, id)
+		}
+		if offset != refreshOffset(id, spread) {
+			t.Fatalf("offset of feed %d changed between calls", id)
+		}
+		seen[offset.Truncate(time.Minute)] = true
+	}
+	if len(seen) < int(spread/time.Minute) {
+		t.Errorf("expected offsets across the whole spread, got minutes %v", seen)
+	}
+	if got := refreshOffset(7, 0); got != 0 {
+		t.Errorf("expected no offset without a spread, got %v", got)
+	}
+}
+
+func TestRefreshService_RefreshDueSpread(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var mu sync.Mutex
+	fetchedAt := map[string]time.Time{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetchedAt[r.URL.Path] = time.Now()
+		mu.Unlock()
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewRefreshService(mockFeeds, nil, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed).(*refreshService)
+	spread := 200 * time.Millisecond
+	service.spread = spread
+	ctx := context.Background()
+
+	var feeds []model.Feed
+	for id := int64(1); id <= 4; id++ {
+		feeds = append(feeds, model.Feed{ID: id, Title: "Feed", URL: fmt.Sprintf("%s/%d", server.URL, id)})
+	}
+	mockFeeds.EXPECT().List(gomock.Any(), nil).Return(feeds, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(4)
+
+	started := time.Now()
+	if err := service.RefreshDue(ctx); err != nil {
+		t.Fatalf("refresh due: %v", err)
+	}
+	for _, feed := range feeds {
+		at, ok := fetchedAt[fmt.Sprintf("/%d", feed.ID)]
+		if !ok {
+			t.Errorf("expected feed %d to be fetched", feed.ID)
+			continue
+		}
+		if offset := refreshOffset(feed.ID, spread); at.Sub(started) < offset {
+			t.Errorf("expected feed %d fetched no sooner than %v into the run, got %v", feed.ID, offset, at.Sub(started))
+		}
+	}
+	if service.Hurry() {
+		t.Error("expected nothing to hurry once the refresh is done")
+	}
+}
+
+func TestRefreshService_Hurry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewRefreshService(mockFeeds, nil, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed).(*refreshService)
+	service.spread = time.Hour
+	listed := make(chan struct{})
+	mockFeeds.EXPECT().List(gomock.Any(), nil).DoAndReturn(func(context.Context, *int64) ([]model.Feed, error) {
+		close(listed)
+		return []model.Feed{{ID: 1, Title: "Feed", URL: server.URL}}, nil
+	})
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+
+	done := make(chan error, 1)
+	go func() { done <- service.RefreshDue(context.Background()) }()
+	<-listed
+	if !service.Hurry() {
+		t.Fatal("expected the spreading refresh to be hurried")
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("refresh due: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the hurried refresh to finish")
+	}
+}
+
+func TestRefreshService_DisablesFailingFeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var mu sync.Mutex
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched = append(fetched, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	hub := events.NewHub()
+	ch, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewRefreshService(mockFeeds, nil, nil, nil, server.Client(), nil, nil, nil, hub, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	now := time.Now()
+	disabledAt := now.Add(-time.Hour)
+	mockFeeds.EXPECT().List(gomock.Any(), nil).Return([]model.Feed{
+		{ID: 1, Title: "Failing", URL: server.URL + "/failing", ConsecutiveFailures: DefaultFeedDisableThreshold - 1},
+		{ID: 2, Title: "Disabled", URL: server.URL + "/disabled", ConsecutiveFailures: DefaultFeedDisableThreshold, DisabledAt: &disabledAt},
+	}, nil)
+	mockFeeds.EXPECT().UpdateErrorMessage(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	mockFeeds.EXPECT().IncrementFailures(gomock.Any(), int64(1)).Return(DefaultFeedDisableThreshold, nil)
+	mockFeeds.EXPECT().Disable(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	var scheduled time.Time
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, at time.Time) error {
+			scheduled = at
+			return nil
+		})
+
+	if err := service.RefreshAll(ctx); err != nil {
+		t.Fatalf("refresh all: %v", err)
+	}
+
+	if len(fetched) != 1 || fetched[0] != "/failing" {
+		t.Errorf("expected the disabled feed skipped, fetched %v", fetched)
+	}
+	if got := scheduled.Sub(now); got < maxFailureBackoff || got > maxFailureBackoff+time.Minute {
+		t.Errorf("expected the next refresh backed off by %v, got %v", maxFailureBackoff, got)
+	}
+	select {
+	case ev := <-ch:
+		disabled, ok := ev.Data.(events.FeedDisabled)
+		if ev.Type != events.TypeFeedDisabled || !ok || disabled.FeedID != 1 ||
+			disabled.Failures != DefaultFeedDisableThreshold || disabled.Error != "HTTP 500" {
+			t.Errorf("unexpected event %+v", ev)
+		}
+	default:
+		t.Error("expected a feed disabled event")
+	}
+}
+
+func TestRefreshService_ClearsFailuresOnSuccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewRefreshService(mockFeeds, nil, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	disabledAt := time.Now().Add(-time.Hour)
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Back", URL: server.URL, ConsecutiveFailures: 12, DisabledAt: &disabledAt}, nil)
+	mockFeeds.EXPECT().ClearFailures(gomock.Any(), int64(1)).Return(nil)
+	var scheduled time.Time
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, at time.Time) error {
+			scheduled = at
+			return nil
+		})
+
+	start := time.Now()
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if got := scheduled.Sub(start); got < DefaultRefreshInterval || got > DefaultRefreshInterval+time.Minute {
+		t.Errorf("expected the default interval without backoff, next refresh in %v", got)
+	}
+}
@@ -3,12 +3,14 @@ package service
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +20,7 @@ import (
 	"golang.org/x/sync/semaphore"
 
 	"gist/backend/internal/config"
+	"gist/backend/internal/events"
 	"gist/backend/internal/model"
 	"gist/backend/internal/repository"
 	"gist/backend/internal/service/anubis"
@@ -25,14 +28,24 @@ import (
 
 const refreshTimeout = 30 * time.Second
 
+// refreshAllTimeout bounds a full refresh run as a task.
+const refreshAllTimeout = 5 * time.Minute
+
 const (
 	// maxConcurrentRefresh limits parallel feed refreshes to avoid overwhelming
 	// the network and remote servers.
 	maxConcurrentRefresh = 8
 	// maxConcurrentPerHost limits parallel requests to the same host to be polite.
 	maxConcurrentPerHost = 1
+	// maxConcurrentPrefetch limits the readable content fetched at once for
+	// feeds that prefetch it, across all refreshes.
+	maxConcurrentPrefetch = 4
 )
 
+// prefetchTimeout bounds fetching the readable content of one refresh's new
+// entries, which goes on after the refresh returns.
+const prefetchTimeout = 10 * time.Minute
+
 // hostLimiter manages per-host concurrency limits.
 type hostLimiter struct {
 	mu       sync.Mutex
@@ -66,9 +79,34 @@ func (h *hostLimiter) release(host string) {
 
 var ErrAlreadyRefreshing = errors.New("refresh already in progress")
 
+// errNotModified and httpStatusError tell refreshFeedInternal how a fetch
+// ended; neither is a refresh failure for callers.
+var errNotModified = errors.New("feed not modified")
+
+type httpStatusError struct {
+	status int
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.status)
+}
+
 type RefreshService interface {
 	RefreshAll(ctx context.Context) error
+	// RefreshDue refreshes only the feeds whose next scheduled refresh has come.
+	RefreshDue(ctx context.Context) error
 	RefreshFeed(ctx context.Context, feedID int64) error
+	// RefreshNow refreshes a feed right away and reports how the fetch went.
+	// A feed whose host is cooling down after a 429 is not fetched; a
+	// *HostCooldownError says until when.
+	// A failed fetch is reported in the attempt, not returned as an error.
+	RefreshNow(ctx context.Context, feedID int64) (FeedFetchAttempt, error)
+	// Hurry makes a scheduled refresh that is spreading its fetches start
+	// the rest now. It reports whether one was running.
+	Hurry() bool
+	// Ingest stores the entries of feed content pushed by a WebSub hub, as a
+	// refresh would from fetched content.
+	Ingest(ctx context.Context, feedID int64, body []byte) error
 	IsRefreshing() bool
 }
 
@@ -76,38 +114,88 @@ type refreshService struct {
 	feeds        repository.FeedRepository
 	entries      repository.EntryRepository
 	settings     SettingsService
+	filters      FilterService
 	httpClient   *http.Client
 	anubis       *anubis.Solver
+	metrics      *FetchMetrics
+	bandwidth    *BandwidthMeter
+	events       *events.Hub
+	thumbnails   ThumbnailService
+	nsfw         NSFWCheckService
+	readability  ReadabilityService
+	credentials  *FeedCredentials
+	health       FeedHealthService
+	websub       WebSubService
+	hooks        *IngestHooks
+	sanitizer    *ContentSanitizer
+	refreshMode  string        // config.RefreshFixed or config.RefreshAdaptive
+	spread       time.Duration // how long a scheduled refresh spreads its fetches over
 	mu           sync.Mutex
 	isRefreshing bool
+	hurry        chan struct{} // closed to stop spreading the running refresh; nil if it does not spread
+	prefetch     *semaphore.Weighted
+	prefetching  sync.WaitGroup
 }
 
-func NewRefreshService(feeds repository.FeedRepository, entries repository.EntryRepository, settings SettingsService, httpClient *http.Client, anubisSolver *anubis.Solver) RefreshService {
+func NewRefreshService(feeds repository.FeedRepository, entries repository.EntryRepository, settings SettingsService, filters FilterService, httpClient *http.Client, anubisSolver *anubis.Solver, metrics *FetchMetrics, bandwidth *BandwidthMeter, hub *events.Hub, thumbnails ThumbnailService, nsfw NSFWCheckService, readability ReadabilityService, credentials *FeedCredentials, health FeedHealthService, websub WebSubService, hooks *IngestHooks, refreshMode string) RefreshService {
 	client := httpClient
 	if client == nil {
 		client = &http.Client{Timeout: refreshTimeout}
 	}
 	return &refreshService{
-		feeds:      feeds,
-		entries:    entries,
-		settings:   settings,
-		httpClient: client,
-		anubis:     anubisSolver,
+		feeds:       feeds,
+		entries:     entries,
+		settings:    settings,
+		filters:     filters,
+		httpClient:  client,
+		anubis:      anubisSolver,
+		metrics:     metrics,
+		bandwidth:   bandwidth,
+		events:      hub,
+		thumbnails:  thumbnails,
+		nsfw:        nsfw,
+		readability: readability,
+		credentials: credentials,
+		health:      health,
+		websub:      websub,
+		hooks:       hooks,
+		sanitizer:   NewContentSanitizer(settings),
+		refreshMode: refreshMode,
+		spread:      refreshSpread,
+		prefetch:    semaphore.NewWeighted(maxConcurrentPrefetch),
 	}
 }
 
 func (s *refreshService) RefreshAll(ctx context.Context) error {
+	return s.refreshFeeds(ctx, func(model.Feed) bool { return true }, 0)
+}
+
+func (s *refreshService) RefreshDue(ctx context.Context) error {
+	now := time.Now()
+	return s.refreshFeeds(ctx, func(feed model.Feed) bool { return refreshDue(feed, now) }, s.spread)
+}
+
+// refreshFeeds refreshes the feeds that match, a few at a time. With a
+// spread, each feed waits for its offset into it rather than all starting
+// at once.
+func (s *refreshService) refreshFeeds(ctx context.Context, match func(model.Feed) bool, spread time.Duration) error {
 	s.mu.Lock()
 	if s.isRefreshing {
 		s.mu.Unlock()
 		return ErrAlreadyRefreshing
 	}
 	s.isRefreshing = true
+	var hurry chan struct{}
+	if spread > 0 {
+		hurry = make(chan struct{})
+		s.hurry = hurry
+	}
 	s.mu.Unlock()
 
 	defer func() {
 		s.mu.Lock()
 		s.isRefreshing = false
+		s.hurry = nil
 		s.mu.Unlock()
 	}()
 
@@ -115,27 +203,41 @@ func (s *refreshService) RefreshAll(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	var matched []model.Feed
+	for _, feed := range feeds {
+		if feed.IsVirtual() || feed.DisabledAt != nil || !match(feed) {
+			continue
+		}
+		matched = append(matched, feed)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		return refreshOffset(matched[i].ID, spread) < refreshOffset(matched[j].ID, spread)
+	})
 
 	// Use errgroup for parallel refresh with concurrency limit
-	g, ctx := errgroup.WithContext(ctx)
+	g, gctx := errgroup.WithContext(ctx)
 	g.SetLimit(maxConcurrentRefresh)
 
 	// Per-host limiter to avoid overwhelming single servers
 	hl := newHostLimiter()
 
-	for _, feed := range feeds {
+	started := time.Now()
+	for _, feed := range matched {
 		feed := feed // capture loop variable
+		if !waitUntil(gctx, started.Add(refreshOffset(feed.ID, spread)), hurry) {
+			break
+		}
 		g.Go(func() error {
 			// Extract host for per-host limiting
 			host := extractHost(feed.URL)
 			if host != "" {
-				if err := hl.acquire(ctx, host); err != nil {
+				if err := hl.acquire(gctx, host); err != nil {
 					return nil // context cancelled
 				}
 				defer hl.release(host)
 			}
 
-			if err := s.refreshFeedInternal(ctx, feed); err != nil {
+			if err := s.refreshFeedInternal(gctx, feed); err != nil {
 				log.Printf("refresh feed %d (%s): %v", feed.ID, feed.Title, err)
 				// Don't return error to continue refreshing other feeds
 			}
@@ -144,7 +246,42 @@ func (s *refreshService) RefreshAll(ctx context.Context) error {
 	}
 
 	// Wait for all goroutines to complete
-	return g.Wait()
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	s.afterRefresh(ctx)
+	return nil
+}
+
+// afterRefresh caches grid thumbnails for picture folders that enable it, so
+// new images show without a fetch, then runs the AI image check on new
+// picture entries. Held back in low-data mode.
+func (s *refreshService) afterRefresh(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	ctx = withBandwidth(ctx, s.bandwidth, 0)
+	if s.settings != nil && s.settings.LowDataActive(ctx) {
+		return
+	}
+	if s.thumbnails != nil {
+		count, err := s.thumbnails.Pregenerate(ctx)
+		if err != nil {
+			log.Printf("pre-generate thumbnails: %v", err)
+		}
+		if count > 0 {
+			log.Printf("pre-generated %d thumbnails", count)
+		}
+	}
+	if s.nsfw != nil {
+		count, err := s.nsfw.CheckImages(ctx)
+		if err != nil {
+			log.Printf("nsfw image check: %v", err)
+		}
+		if count > 0 {
+			log.Printf("nsfw image check flagged %d entries", count)
+		}
+	}
 }
 
 // extractHost returns the host from a URL string.
@@ -156,22 +293,220 @@ func extractHost(rawURL string) string {
 	return u.Host
 }
 
+func (s *refreshService) Hurry() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.hurry == nil {
+		return false
+	}
+	select {
+	case <-s.hurry:
+	default:
+		close(s.hurry)
+	}
+	return true
+}
+
 func (s *refreshService) IsRefreshing() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	return s.isRefreshing
 }
 
+// RefreshAllTask runs RefreshAll as a task.
+func RefreshAllTask(refresh RefreshService) TaskFunc {
+	return func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, refreshAllTimeout)
+		defer cancel()
+		return nil, refresh.RefreshAll(ctx)
+	}
+}
+
 func (s *refreshService) RefreshFeed(ctx context.Context, feedID int64) error {
 	feed, err := s.feeds.GetByID(ctx, feedID)
 	if err != nil {
 		return err
 	}
+	if feed.IsVirtual() {
+		return nil
+	}
 	return s.refreshFeedInternal(ctx, feed)
 }
 
+func (s *refreshService) RefreshNow(ctx context.Context, feedID int64) (FeedFetchAttempt, error) {
+	feed, err := s.feeds.GetByID(ctx, feedID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return FeedFetchAttempt{}, ErrNotFound
+		}
+		return FeedFetchAttempt{}, fmt.Errorf("get feed: %w", err)
+	}
+	if feed.IsVirtual() {
+		return FeedFetchAttempt{}, fmt.Errorf("%w: feed %d is not fetched", ErrInvalid, feedID)
+	}
+
+	fetch, err := s.fetchFeed(ctx, feed)
+	var cooling *HostCooldownError
+	if errors.As(err, &cooling) {
+		return FeedFetchAttempt{}, err
+	}
+	// Out of time, the attempt says no more than that it did not finish
+	if err := ctx.Err(); err != nil {
+		return FeedFetchAttempt{}, err
+	}
+	return fetchAttempt(fetch), nil
+}
+
+func (s *refreshService) Ingest(ctx context.Context, feedID int64, body []byte) error {
+	feed, err := s.feeds.GetByID(ctx, feedID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get feed: %w", err)
+	}
+	parsed, err := newFeedParser().Parse(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%w: pushed content: %v", ErrInvalid, err)
+	}
+
+	newCount, updatedCount := s.saveItems(ctx, feed, parsed)
+	log.Printf("feed %d (%s): %d new, %d updated from push", feed.ID, feed.Title, newCount, updatedCount)
+	s.publishRefreshed(feed, newCount)
+	return nil
+}
+
+// followHub subscribes the feed to the WebSub hub its content names, so new
+// entries are pushed rather than waiting for the next refresh.
+func (s *refreshService) followHub(ctx context.Context, feed model.Feed, header http.Header, body []byte) {
+	if s.websub == nil {
+		return
+	}
+	if hub, topic := discoverHub(header, body, feed.URL); hub != "" {
+		s.websub.Discovered(ctx, feed, hub, topic)
+	}
+}
+
 func (s *refreshService) refreshFeedInternal(ctx context.Context, feed model.Feed) error {
-	return s.refreshFeedWithUA(ctx, feed, config.DefaultUserAgent, true)
+	_, err := s.fetchFeed(ctx, feed)
+	return err
+}
+
+// fetchFeed refreshes a feed and records the attempt, which it returns with
+// the error a failed fetch is for callers.
+func (s *refreshService) fetchFeed(ctx context.Context, feed model.Feed) (model.FeedFetch, error) {
+	// A host that answered 429 is left alone until its cooldown ends, when
+	// the scheduler picks the feed up again. Nothing was attempted.
+	if until := s.cooldownUntil(feed); until != nil {
+		if err := s.feeds.ScheduleRefresh(ctx, feed.ID, *until); err != nil {
+			log.Printf("schedule refresh of feed %d: %v", feed.ID, err)
+		}
+		return model.FeedFetch{}, &HostCooldownError{Host: extractHost(feed.URL), Until: *until}
+	}
+
+	ctx, added := withNewEntryCount(withBandwidth(ctx, s.bandwidth, feed.ID))
+	ctx, httpStatus := withFetchStatus(ctx)
+	start := time.Now()
+	err := s.credentials.Load(ctx, &feed)
+	if err != nil {
+		errMsg := err.Error()
+		_ = s.feeds.UpdateErrorMessage(ctx, feed.ID, &errMsg)
+	} else {
+		ctx = withRequestOverrides(ctx, feed.RequestOverrides)
+		err = s.refreshFeedWithUA(ctx, feed, config.DefaultUserAgent, !overridesUserAgent(feed.RequestOverrides))
+	}
+
+	outcome := FetchOK
+	var statusErr *httpStatusError
+	var errMsg *string
+	switch {
+	case errors.Is(err, errNotModified):
+		outcome, err = FetchNotModified, nil
+	case errors.As(err, &statusErr):
+		msg := statusErr.Error()
+		outcome, err, errMsg = FetchHTTPError, nil, &msg
+	case err != nil:
+		msg := err.Error()
+		outcome, errMsg = FetchError, &msg
+	}
+	duration := time.Since(start)
+	s.metrics.Observe(feed.ID, feed.Title, outcome, duration)
+	fetch := model.FeedFetch{FeedID: feed.ID, FetchedAt: start, Status: outcome, Duration: duration, NewEntries: *added, Error: errMsg}
+	if *httpStatus != 0 {
+		fetch.HTTPStatus = httpStatus
+	}
+	if s.health != nil {
+		s.health.Record(ctx, fetch)
+	}
+	s.trackFailures(ctx, &feed, outcome, errMsg)
+	s.scheduleNext(ctx, feed, start)
+	if outcome == FetchOK || outcome == FetchNotModified {
+		s.publishRefreshed(feed, *added)
+	}
+	return fetch, err
+}
+
+// cooldownUntil returns when the cooldown of the feed's host ends, nil when
+// it has none running.
+func (s *refreshService) cooldownUntil(feed model.Feed) *time.Time {
+	if s.health == nil {
+		return nil
+	}
+	return s.health.CooldownUntil(extractHost(feed.URL))
+}
+
+// coolDown leaves the feed's host, and so every feed on it, alone for as
+// long as its 429 response asks.
+func (s *refreshService) coolDown(feed model.Feed, header http.Header) {
+	if s.health == nil {
+		return
+	}
+	now := time.Now()
+	wait := hostCooldown(header, now)
+	host := extractHost(feed.URL)
+	log.Printf("feed %d (%s): HTTP 429, leaving %s alone for %s", feed.ID, feed.Title, host, wait)
+	s.health.CoolDown(host, now.Add(wait))
+}
+
+type newEntriesKey struct{}
+
+// withNewEntryCount returns a context the refresh paths count the entries
+// they create in, and the count.
+func withNewEntryCount(ctx context.Context) (context.Context, *int) {
+	count := new(int)
+	return context.WithValue(ctx, newEntriesKey{}, count), count
+}
+
+// countNewEntries adds to the count of new entries ctx carries, if any.
+func countNewEntries(ctx context.Context, n int) {
+	if count, ok := ctx.Value(newEntriesKey{}).(*int); ok {
+		*count += n
+	}
+}
+
+type fetchStatusKey struct{}
+
+// withFetchStatus returns a context the refresh paths note the HTTP status
+// of the feed's response in, and the status; 0 until a response came.
+func withFetchStatus(ctx context.Context) (context.Context, *int) {
+	status := new(int)
+	return context.WithValue(ctx, fetchStatusKey{}, status), status
+}
+
+// noteFetchStatus keeps the HTTP status of a response for the feed in ctx.
+func noteFetchStatus(ctx context.Context, status int) {
+	if p, ok := ctx.Value(fetchStatusKey{}).(*int); ok {
+		*p = status
+	}
+}
+
+// publishRefreshed tells clients a feed was fetched and, as new entries are
+// unread, how its unread count grew.
+func (s *refreshService) publishRefreshed(feed model.Feed, newEntries int) {
+	s.events.Publish(events.TypeFeedRefreshed, events.FeedRefreshed{FeedID: feed.ID, Title: feed.Title, NewEntries: newEntries})
+	if newEntries > 0 {
+		s.events.Publish(events.TypeUnreadDelta, events.UnreadDelta{FeedID: feed.ID, Delta: newEntries})
+	}
 }
 
 func (s *refreshService) refreshFeedWithUA(ctx context.Context, feed model.Feed, userAgent string, allowFallback bool) error {
@@ -186,6 +521,7 @@ func (s *refreshService) refreshFeedWithCookie(ctx context.Context, feed model.F
 		return err
 	}
 	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", acceptFor(feed))
 
 	// Add cached Anubis cookie if available
 	if cookie == "" && s.anubis != nil {
@@ -197,6 +533,8 @@ func (s *refreshService) refreshFeedWithCookie(ctx context.Context, feed model.F
 	if cookie != "" {
 		req.Header.Set("Cookie", cookie)
 	}
+	applyRequestOverrides(req, feed.RequestOverrides)
+	applyFeedAuth(req, feed.Auth)
 
 	// Conditional GET
 	if feed.ETag != nil && *feed.ETag != "" {
@@ -213,6 +551,10 @@ func (s *refreshService) refreshFeedWithCookie(ctx context.Context, feed model.F
 		return err
 	}
 	defer resp.Body.Close()
+	noteFetchStatus(ctx, resp.StatusCode)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		s.coolDown(feed, resp.Header)
+	}
 
 	// Not modified, skip parsing but clear error if any
 	if resp.StatusCode == http.StatusNotModified {
@@ -220,11 +562,12 @@ func (s *refreshService) refreshFeedWithCookie(ctx context.Context, feed model.F
 		if feed.ErrorMessage != nil {
 			_ = s.feeds.UpdateErrorMessage(ctx, feed.ID, nil)
 		}
-		return nil
+		return errNotModified
 	}
 
-	// On HTTP error, try fallback UA if available
-	if resp.StatusCode >= http.StatusBadRequest && allowFallback && s.settings != nil {
+	// On HTTP error, try fallback UA if available; a host asking to slow
+	// down is not asked again straight away
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusTooManyRequests && allowFallback && s.settings != nil {
 		fallbackUA := s.settings.GetFallbackUserAgent(ctx)
 		if fallbackUA != "" {
 			log.Printf("feed %d (%s): HTTP %d, retrying with fallback UA", feed.ID, feed.Title, resp.StatusCode)
@@ -234,9 +577,10 @@ func (s *refreshService) refreshFeedWithCookie(ctx context.Context, feed model.F
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		log.Printf("feed %d (%s): HTTP %d", feed.ID, feed.Title, resp.StatusCode)
-		errMsg := fmt.Sprintf("HTTP %d", resp.StatusCode)
+		statusErr := &httpStatusError{status: resp.StatusCode}
+		errMsg := statusErr.Error()
 		_ = s.feeds.UpdateErrorMessage(ctx, feed.ID, &errMsg)
-		return nil
+		return statusErr
 	}
 
 	// Read body into memory for Anubis detection and RSS parsing
@@ -247,9 +591,12 @@ func (s *refreshService) refreshFeedWithCookie(ctx context.Context, feed model.F
 		return err
 	}
 
-	parser := gofeed.NewParser()
-	parsed, parseErr := parser.Parse(bytes.NewReader(body))
+	parsed, parseErr := s.parseFeed(ctx, feed, body)
 	if parseErr != nil {
+		// Sites without a feed can be followed through their sitemap
+		if sm, ok := parseSitemap(body); ok && feed.Scraper == nil {
+			return s.refreshSitemap(ctx, feed, sm, resp.Header)
+		}
 		// Parse failed, check if it's an Anubis challenge
 		if s.anubis != nil && anubis.IsAnubisChallenge(body) {
 			if retryCount >= 2 {
@@ -272,14 +619,122 @@ func (s *refreshService) refreshFeedWithCookie(ctx context.Context, feed model.F
 		return parseErr
 	}
 
-	// Clear error message on successful refresh
+	s.recordFetch(ctx, &feed, resp.Header)
+	s.followHub(ctx, feed, resp.Header, body)
+
+	newCount, updatedCount := s.saveItems(ctx, feed, parsed)
+	countNewEntries(ctx, newCount)
+	if newCount > 0 || updatedCount > 0 {
+		log.Printf("feed %d (%s): %d new, %d updated", feed.ID, feed.Title, newCount, updatedCount)
+	}
+	return nil
+}
+
+// saveItems stores the items of a parsed feed as entries of the feed, after
+// the NSFW marks and filter rules, and counts the new and updated ones. Recent
+// entries still without content then have it fetched from their pages.
+// CreateOrUpdate handles duplicates via ON CONFLICT.
+func (s *refreshService) saveItems(ctx context.Context, feed model.Feed, parsed *gofeed.Feed) (int, int) {
+	newCount, updatedCount := 0, 0
+	dynamicTime := hasDynamicTime(parsed.Items)
+	feedNSFW := feedNSFWReason(parsed)
+	keywords := nsfwKeywords(ctx, s.settings)
+	rules := filterRules(ctx, s.filters, feed.ID)
+	dupes := duplicateTitles(ctx, s.settings, s.entries, feed.ID)
+	dates := loadFutureDates(ctx, s.settings).forFeed(feed)
+	itemDates := newItemDates(feed.DateQuirks, dates.now)
+	collapsed, held := 0, 0
+	var prefetch []string
+	for _, item := range parsed.Items {
+		entry := itemToEntry(feed.ID, item, dynamicTime, itemDates)
+		if entry.URL == nil || *entry.URL == "" {
+			continue
+		}
+		if feed.Type == pictureFeedType {
+			addGallery(&entry, item)
+		}
+		markNSFW(&entry, item, feedNSFW, keywords)
+		if rules.Apply(&entry) || !s.hooks.run(ctx, feed, &entry) {
+			continue
+		}
+		// Last, so content set by a rewrite or a hook is sanitized too
+		s.sanitizer.SanitizeEntry(ctx, &entry)
+		if s.collapseDuplicate(ctx, dupes, entry) {
+			collapsed++
+			continue
+		}
+		if s.holdFuture(ctx, dates, entry) {
+			held++
+			continue
+		}
+
+		created, err := s.saveEntry(ctx, entry, dates)
+		if err != nil {
+			log.Printf("save entry: %v", err)
+			continue
+		}
+
+		if created && entry.FeedID != feed.ID {
+			// Routed to another feed by an ingest hook
+			newCount++
+		} else if created {
+			newCount++
+			// A feed with a full-text service has its new entries
+			// extracted in full, like those flagged for prefetching
+			if feed.PrefetchReadability || feed.FullTextURL != nil {
+				prefetch = append(prefetch, *entry.URL)
+			}
+		} else {
+			updatedCount++
+		}
+	}
+
+	if collapsed > 0 {
+		log.Printf("feed %d (%s): %d collapsed as duplicate titles", feed.ID, feed.Title, collapsed)
+		s.metrics.ObserveCollapsed(feed.ID, feed.Title, collapsed)
+	}
+	if held > 0 {
+		log.Printf("feed %d (%s): %d held until their publish date", feed.ID, feed.Title, held)
+	}
+	s.refetchMissingContent(ctx, feed)
+	s.prefetchReadable(ctx, feed, prefetch)
+	return newCount, updatedCount
+}
+
+// collapseDuplicate reports whether an entry is new and repeats a recent
+// title of its feed, so it should not be stored. Known entries are always
+// updated.
+func (s *refreshService) collapseDuplicate(ctx context.Context, dupes *titleCollapser, entry model.Entry) bool {
+	if dupes == nil || entry.Title == nil {
+		return false
+	}
+	if _, found, err := s.findExisting(ctx, entry); err != nil || found {
+		return false
+	}
+	return dupes.collapse(*entry.Title)
+}
+
+// holdFuture reports whether an entry is new and dated in the future while
+// such entries are hidden, so it should not be stored before a refresh after
+// its date. Known entries are always updated.
+func (s *refreshService) holdFuture(ctx context.Context, dates futureDates, entry model.Entry) bool {
+	if !dates.hold(entry) {
+		return false
+	}
+	_, found, err := s.findExisting(ctx, entry)
+	return err == nil && !found
+}
+
+// recordFetch clears the feed's error after a successful fetch and keeps the
+// response's ETag and Last-Modified for the next conditional GET (only
+// non-empty values, to preserve existing ones).
+func (s *refreshService) recordFetch(ctx context.Context, feed *model.Feed, header http.Header) {
 	if feed.ErrorMessage != nil {
 		_ = s.feeds.UpdateErrorMessage(ctx, feed.ID, nil)
 	}
 
-	// Update feed ETag and LastModified (only update non-empty values to preserve existing ones)
-	newETag := strings.TrimSpace(resp.Header.Get("ETag"))
-	newLastModified := strings.TrimSpace(resp.Header.Get("Last-Modified"))
+	newETag := strings.TrimSpace(header.Get("ETag"))
+	newLastModified := strings.TrimSpace(header.Get("Last-Modified"))
 	needsUpdate := false
 	if newETag != "" {
 		feed.ETag = &newETag
@@ -290,44 +745,118 @@ func (s *refreshService) refreshFeedWithCookie(ctx context.Context, feed model.F
 		needsUpdate = true
 	}
 	if needsUpdate {
-		if _, err := s.feeds.Update(ctx, feed); err != nil {
+		if _, err := s.feeds.Update(ctx, *feed); err != nil {
 			log.Printf("update feed %d etag: %v", feed.ID, err)
 		}
 	}
+}
 
-	// Save entries (CreateOrUpdate handles duplicates via ON CONFLICT)
-	newCount := 0
-	updatedCount := 0
-	dynamicTime := hasDynamicTime(parsed.Items)
-	for _, item := range parsed.Items {
-		entry := itemToEntry(feed.ID, item, dynamicTime)
-		if entry.URL == nil || *entry.URL == "" {
-			continue
+// saveEntry stores an entry from the feed and reports whether it is new. When
+// the publisher changed the title or text of a known entry, a summary of the
+// change is recorded as its revision. Future dates are clamped per dates.
+func (s *refreshService) saveEntry(ctx context.Context, entry model.Entry, dates futureDates) (bool, error) {
+	existing, found, err := s.findExisting(ctx, entry)
+	if err != nil {
+		return false, err
+	}
+	if found {
+		// Keep the URL the entry was first seen with; state sync and the
+		// unique index are keyed by it
+		entry.URL = existing.URL
+		dates.clamp(&entry, &existing)
+		// An item still without content keeps what was fetched for it
+		if entry.Content == nil && trimmedValue(existing.Content) != "" {
+			snippet := makeSnippet(*existing.Content)
+			entry.Content, entry.Snippet = existing.Content, &snippet
+			if entry.ThumbnailURL == nil {
+				entry.ThumbnailURL = existing.ThumbnailURL
+			}
 		}
+	} else {
+		dates.clamp(&entry, nil)
+	}
+	setContentFormats(&entry)
 
-		// Check if entry already exists
-		exists, err := s.entries.ExistsByURL(ctx, feed.ID, *entry.URL)
-		if err != nil {
-			log.Printf("check entry exists: %v", err)
-			continue
+	if err := s.entries.CreateOrUpdate(ctx, entry); err != nil {
+		return false, err
+	}
+	if !found {
+		return true, nil
+	}
+
+	if rev, changed := diffEntry(existing, entry, time.Now()); changed {
+		if err := s.entries.SaveRevision(ctx, existing.ID, rev); err != nil {
+			log.Printf("save revision of entry %d: %v", existing.ID, err)
 		}
+	}
+	return false, nil
+}
 
-		if err := s.entries.CreateOrUpdate(ctx, entry); err != nil {
-			log.Printf("save entry: %v", err)
+// prefetchReadable fetches the readable content of a feed's new entries in
+// the background, a few at a time across all feeds, so they are complete
+// when opened. Entries that fail are skipped; the reader can still fetch
+// them on demand.
+func (s *refreshService) prefetchReadable(ctx context.Context, feed model.Feed, entryURLs []string) {
+	if len(entryURLs) == 0 || s.readability == nil {
+		return
+	}
+	entryIDs := make([]int64, 0, len(entryURLs))
+	for _, entryURL := range entryURLs {
+		entry, err := s.entries.GetByURL(ctx, feed.ID, entryURL)
+		if err != nil {
+			log.Printf("prefetch readable content of %s: %v", entryURL, err)
 			continue
 		}
-
-		if exists {
-			updatedCount++
-		} else {
-			newCount++
+		entryIDs = append(entryIDs, entry.ID)
+	}
+
+	s.prefetching.Add(1)
+	go func() {
+		defer s.prefetching.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), prefetchTimeout)
+		defer cancel()
+		var wg sync.WaitGroup
+		for _, id := range entryIDs {
+			if err := s.prefetch.Acquire(ctx, 1); err != nil {
+				break
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer s.prefetch.Release(1)
+				if _, _, err := s.readability.FetchReadableContent(ctx, id, false); err != nil {
+					log.Printf("prefetch readable content of entry %d: %v", id, err)
+				}
+			}()
 		}
+		wg.Wait()
+	}()
+}
+
+// findExisting returns the stored copy of an entry: the one with its URL or,
+// since some feeds put a session ID in item links on every fetch, one with
+// the same title and publish time. A bare date (midnight UTC) is too coarse
+// to tell items apart, so it never matches.
+func (s *refreshService) findExisting(ctx context.Context, entry model.Entry) (model.Entry, bool, error) {
+	existing, err := s.entries.GetByURL(ctx, entry.FeedID, *entry.URL)
+	if err == nil {
+		return existing, true, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return model.Entry{}, false, err
 	}
 
-	if newCount > 0 || updatedCount > 0 {
-		log.Printf("feed %d (%s): %d new, %d updated", feed.ID, feed.Title, newCount, updatedCount)
+	if entry.Title == nil || *entry.Title == "" || entry.PublishedAt == nil || entry.PublishedAt.Equal(entry.PublishedAt.Truncate(24*time.Hour)) {
+		return model.Entry{}, false, nil
 	}
-	return nil
+	existing, err = s.entries.GetByTitlePublished(ctx, entry.FeedID, *entry.Title, *entry.PublishedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Entry{}, false, nil
+	}
+	if err != nil {
+		return model.Entry{}, false, err
+	}
+	return existing, true, nil
 }
 
 // refreshFeedWithFreshClient creates a new http.Client to avoid connection reuse after Anubis
@@ -339,12 +868,15 @@ func (s *refreshService) refreshFeedWithFreshClient(ctx context.Context, feed mo
 		return err
 	}
 	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set("Accept", acceptFor(feed))
 	if cookie != "" {
 		req.Header.Set("Cookie", cookie)
 	}
+	applyRequestOverrides(req, feed.RequestOverrides)
+	applyFeedAuth(req, feed.Auth)
 
 	// Use fresh client to avoid connection reuse
-	freshClient := &http.Client{Timeout: refreshTimeout}
+	freshClient := &http.Client{Timeout: refreshTimeout, Jar: s.httpClient.Jar, Transport: s.httpClient.Transport}
 	resp, err := freshClient.Do(req)
 	if err != nil {
 		errMsg := err.Error()
@@ -352,12 +884,17 @@ func (s *refreshService) refreshFeedWithFreshClient(ctx context.Context, feed mo
 		return err
 	}
 	defer resp.Body.Close()
+	noteFetchStatus(ctx, resp.StatusCode)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		s.coolDown(feed, resp.Header)
+	}
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		log.Printf("feed %d (%s): HTTP %d", feed.ID, feed.Title, resp.StatusCode)
-		errMsg := fmt.Sprintf("HTTP %d", resp.StatusCode)
+		statusErr := &httpStatusError{status: resp.StatusCode}
+		errMsg := statusErr.Error()
 		_ = s.feeds.UpdateErrorMessage(ctx, feed.ID, &errMsg)
-		return nil
+		return statusErr
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -374,65 +911,21 @@ func (s *refreshService) refreshFeedWithFreshClient(ctx context.Context, feed mo
 		return errors.New(errMsg)
 	}
 
-	parser := gofeed.NewParser()
-	parsed, parseErr := parser.Parse(bytes.NewReader(body))
+	parsed, parseErr := s.parseFeed(ctx, feed, body)
 	if parseErr != nil {
+		if sm, ok := parseSitemap(body); ok && feed.Scraper == nil {
+			return s.refreshSitemap(ctx, feed, sm, resp.Header)
+		}
 		errMsg := parseErr.Error()
 		_ = s.feeds.UpdateErrorMessage(ctx, feed.ID, &errMsg)
 		return parseErr
 	}
 
-	// Clear error message on successful refresh
-	if feed.ErrorMessage != nil {
-		_ = s.feeds.UpdateErrorMessage(ctx, feed.ID, nil)
-	}
-
-	// Update feed ETag and LastModified
-	newETag := strings.TrimSpace(resp.Header.Get("ETag"))
-	newLastModified := strings.TrimSpace(resp.Header.Get("Last-Modified"))
-	needsUpdate := false
-	if newETag != "" {
-		feed.ETag = &newETag
-		needsUpdate = true
-	}
-	if newLastModified != "" {
-		feed.LastModified = &newLastModified
-		needsUpdate = true
-	}
-	if needsUpdate {
-		if _, err := s.feeds.Update(ctx, feed); err != nil {
-			log.Printf("update feed %d etag: %v", feed.ID, err)
-		}
-	}
-
-	// Save entries
-	newCount := 0
-	updatedCount := 0
-	dynamicTime := hasDynamicTime(parsed.Items)
-	for _, item := range parsed.Items {
-		entry := itemToEntry(feed.ID, item, dynamicTime)
-		if entry.URL == nil || *entry.URL == "" {
-			continue
-		}
-
-		exists, err := s.entries.ExistsByURL(ctx, feed.ID, *entry.URL)
-		if err != nil {
-			log.Printf("check entry exists: %v", err)
-			continue
-		}
-
-		if err := s.entries.CreateOrUpdate(ctx, entry); err != nil {
-			log.Printf("save entry: %v", err)
-			continue
-		}
-
-		if exists {
-			updatedCount++
-		} else {
-			newCount++
-		}
-	}
+	s.recordFetch(ctx, &feed, resp.Header)
+	s.followHub(ctx, feed, resp.Header, body)
 
+	newCount, updatedCount := s.saveItems(ctx, feed, parsed)
+	countNewEntries(ctx, newCount)
 	if newCount > 0 || updatedCount > 0 {
 		log.Printf("feed %d (%s): %d new, %d updated", feed.ID, feed.Title, newCount, updatedCount)
 	}
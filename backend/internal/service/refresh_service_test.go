@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/events"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"github.com/mmcdole/gofeed"
+	"go.uber.org/mock/gomock"
+)
+
+func TestRefreshService_SaveEntry_SameItemNewURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewRefreshService(nil, mockEntries, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed).(*refreshService)
+	ctx := context.Background()
+
+	title := "Weekly notes"
+	content := "<p>Same text</p>"
+	published := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	firstURL := "https://example.com/post?sid=aaa"
+	nextURL := "https://example.com/post?sid=bbb"
+	stored := model.Entry{ID: 7, FeedID: 1, Title: &title, URL: &firstURL, Content: &content, PublishedAt: &published}
+
+	mockEntries.EXPECT().GetByURL(ctx, int64(1), nextURL).Return(model.Entry{}, sql.ErrNoRows)
+	mockEntries.EXPECT().GetByTitlePublished(ctx, int64(1), title, published).Return(stored, nil)
+	mockEntries.EXPECT().CreateOrUpdate(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		if entry.URL == nil || *entry.URL != firstURL {
+			t.Errorf("expected the stored URL to be kept, got %v", entry.URL)
+		}
+		return nil
+	})
+
+	created, err := service.saveEntry(ctx, model.Entry{FeedID: 1, Title: &title, URL: &nextURL, Content: &content, PublishedAt: &published}, futureDates{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created {
+		t.Error("expected the item to update the stored entry")
+	}
+}
+
+func TestRefreshService_SaveEntry_NewItem(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewRefreshService(nil, mockEntries, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed).(*refreshService)
+	ctx := context.Background()
+
+	title := "Daily filing"
+	url := "https://example.com/filing/2"
+	// A bare date cannot tell items apart, so it is not looked up
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mockEntries.EXPECT().GetByURL(ctx, int64(1), url).Return(model.Entry{}, sql.ErrNoRows)
+	mockEntries.EXPECT().CreateOrUpdate(ctx, gomock.Any()).Return(nil)
+
+	created, err := service.saveEntry(ctx, model.Entry{FeedID: 1, Title: &title, URL: &url, PublishedAt: &day}, futureDates{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !created {
+		t.Error("expected a new entry")
+	}
+}
+
+func TestRefreshService_PublishesRefreshEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Blog</title>
+<item><title>One</title><link>https://example.com/1</link></item>
+<item><title>Two</title><link>https://example.com/2</link></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	hub := events.NewHub()
+	received, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+	service := NewRefreshService(mockFeeds, mockEntries, nil, nil, server.Client(), nil, nil, nil, hub, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Blog", URL: server.URL}, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), "https://example.com/1").Return(model.Entry{}, sql.ErrNoRows)
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), "https://example.com/2").Return(model.Entry{ID: 2, FeedID: 1}, nil)
+	mockEntries.EXPECT().GetByTitlePublished(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(model.Entry{}, sql.ErrNoRows).AnyTimes()
+	mockEntries.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	mockEntries.EXPECT().SaveRevision(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	refreshed := <-received
+	if data, ok := refreshed.Data.(events.FeedRefreshed); refreshed.Type != events.TypeFeedRefreshed || !ok || data.FeedID != 1 || data.NewEntries != 1 {
+		t.Errorf("unexpected first event %+v", refreshed)
+	}
+	delta := <-received
+	if data, ok := delta.Data.(events.UnreadDelta); delta.Type != events.TypeUnreadDelta || !ok || data.Delta != 1 {
+		t.Errorf("unexpected second event %+v", delta)
+	}
+}
+
+func TestRefreshService_PrefetchesReadableContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Blog</title>
+<item><title>One</title><link>https://example.com/1</link></item>
+<item><title>Two</title><link>https://example.com/2</link></item>
+<item><title>Known</title><link>https://example.com/known</link></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	readable := &fakeReadable{failing: map[int64]bool{11: true}}
+	service := NewRefreshService(mockFeeds, mockEntries, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, readable, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Blog", URL: server.URL, PrefetchReadability: true}, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+	for id, url := range map[int64]string{11: "https://example.com/1", 12: "https://example.com/2"} {
+		gomock.InOrder(
+			mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), url).Return(model.Entry{}, sql.ErrNoRows),
+			mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), url).Return(model.Entry{ID: id, FeedID: 1}, nil),
+		)
+	}
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), "https://example.com/known").Return(model.Entry{ID: 13, FeedID: 1}, nil)
+	mockEntries.EXPECT().GetByTitlePublished(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(model.Entry{}, sql.ErrNoRows).AnyTimes()
+	mockEntries.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	mockEntries.EXPECT().SaveRevision(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	service.(*refreshService).prefetching.Wait()
+
+	// Only the new entries are fetched, and a failure does not stop the rest
+	slices.Sort(readable.fetched)
+	if !slices.Equal(readable.fetched, []int64{11, 12}) {
+		t.Errorf("expected entries 11 and 12 prefetched, got %v", readable.fetched)
+	}
+}
+
+func TestRefreshService_SanitizesRewrittenContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFilters := testutil.NewMockFilterRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	filters := NewFilterService(mockFilters, nil, nil)
+	service := NewRefreshService(nil, mockEntries, nil, filters, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed).(*refreshService)
+	ctx := context.Background()
+
+	mockFilters.EXPECT().ListForFeed(gomock.Any(), int64(1)).Return([]model.Filter{
+		{ID: 1, Match: model.FilterExpr, Pattern: `{content: content + "<script>alert(1)</script>"}`, Action: model.FilterActionRewrite},
+	}, nil)
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), "https://example.com/1").Return(model.Entry{}, sql.ErrNoRows)
+	mockEntries.EXPECT().GetByTitlePublished(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(model.Entry{}, sql.ErrNoRows).AnyTimes()
+	mockEntries.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		if entry.Content == nil || *entry.Content != "<p>Hello</p>" {
+			t.Errorf("expected the rewritten content sanitized, got %v", entry.Content)
+		}
+		return nil
+	})
+
+	parsed := &gofeed.Feed{Items: []*gofeed.Item{{Title: "One", Link: "https://example.com/1", Content: "<p>Hello</p>"}}}
+	if created, _ := service.saveItems(ctx, model.Feed{ID: 1, Title: "Blog"}, parsed); created != 1 {
+		t.Errorf("expected 1 new entry, got %d", created)
+	}
+}
+
+func TestRefreshService_SanitizesHookContent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	hooks, err := NewIngestHooks(NewIngestHook("embed", func(_ context.Context, _ model.Feed, entry *model.Entry) (bool, error) {
+		content := *entry.Content + `<img src="x" onerror="alert(1)">`
+		entry.Content = &content
+		return true, nil
+	}))
+	if err != nil {
+		t.Fatalf("new ingest hooks: %v", err)
+	}
+	service := NewRefreshService(nil, mockEntries, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, hooks, config.RefreshFixed).(*refreshService)
+	ctx := context.Background()
+
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), "https://example.com/1").Return(model.Entry{}, sql.ErrNoRows)
+	mockEntries.EXPECT().GetByTitlePublished(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(model.Entry{}, sql.ErrNoRows).AnyTimes()
+	mockEntries.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		if entry.Content == nil || strings.Contains(*entry.Content, "onerror") {
+			t.Errorf("expected the hook's content sanitized, got %v", entry.Content)
+		}
+		return nil
+	})
+
+	parsed := &gofeed.Feed{Items: []*gofeed.Item{{Title: "One", Link: "https://example.com/1", Content: "<p>Hello</p>"}}}
+	if created, _ := service.saveItems(ctx, model.Feed{ID: 1, Title: "Blog"}, parsed); created != 1 {
+		t.Errorf("expected 1 new entry, got %d", created)
+	}
+}
+
+func TestRefreshService_RefreshNow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.Header().Set("Content-Type", "text/html")
+			fmt.Fprint(w, `<html><body>Not a feed</body></html>`)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Blog</title>
+<item><title>One</title><link>https://example.com/1</link></item>
+</channel></rss>`)
+	}))
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewRefreshService(mockFeeds, mockEntries, nil, nil, server.Client(), nil, nil, nil, events.NewHub(), nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Blog", URL: server.URL}, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFeeds.EXPECT().UpdateErrorMessage(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockFeeds.EXPECT().IncrementFailures(gomock.Any(), int64(2)).Return(1, nil)
+	mockEntries.EXPECT().GetByURL(gomock.Any(), int64(1), "https://example.com/1").Return(model.Entry{}, sql.ErrNoRows)
+	mockEntries.EXPECT().GetByTitlePublished(gomock.Any(), int64(1), gomock.Any(), gomock.Any()).Return(model.Entry{}, sql.ErrNoRows).AnyTimes()
+	mockEntries.EXPECT().CreateOrUpdate(gomock.Any(), gomock.Any()).Return(nil)
+
+	attempt, err := service.RefreshNow(ctx, 1)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if attempt.Status != FetchOK || attempt.NewEntries != 1 || attempt.HTTPStatus == nil || *attempt.HTTPStatus != http.StatusOK || attempt.Error != nil {
+		t.Errorf("unexpected attempt %+v", attempt)
+	}
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(2)).Return(model.Feed{ID: 2, Title: "Broken", URL: server.URL + "/broken"}, nil)
+	attempt, err = service.RefreshNow(ctx, 2)
+	if err != nil {
+		t.Fatalf("a failed fetch should be reported, not returned: %v", err)
+	}
+	if attempt.Status != FetchError || attempt.Error == nil || attempt.NewEntries != 0 {
+		t.Errorf("expected a parse error, got %+v", attempt)
+	}
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(3)).Return(model.Feed{}, sql.ErrNoRows)
+	if _, err := service.RefreshNow(ctx, 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Noooste/azuretls-client"
+	"golang.org/x/net/http/httpguts"
+
+	"gist/backend/internal/model"
+)
+
+// maxUserAgentLength caps a feed's User-Agent override.
+const maxUserAgentLength = 512
+
+// NormalizeRequestOverrides trims overrides and checks that they can be
+// sent, as NormalizeFeedAuth does for credentials. The User-Agent is set
+// with UserAgent rather than as a header.
+func NormalizeRequestOverrides(overrides model.FeedRequestOverrides) (model.FeedRequestOverrides, error) {
+	userAgent := strings.TrimSpace(overrides.UserAgent)
+	if len(userAgent) > maxUserAgentLength || !httpguts.ValidHeaderFieldValue(userAgent) {
+		return model.FeedRequestOverrides{}, fmt.Errorf("%w: invalid user agent", ErrInvalid)
+	}
+	headers, err := normalizeFeedHeaders(overrides.Headers)
+	if err != nil {
+		return model.FeedRequestOverrides{}, err
+	}
+	if _, ok := headers["User-Agent"]; ok {
+		return model.FeedRequestOverrides{}, fmt.Errorf("%w: set the User-Agent with userAgent", ErrInvalid)
+	}
+	return model.FeedRequestOverrides{UserAgent: userAgent, Headers: headers}, nil
+}
+
+// normalizeOptionalRequestOverrides runs NormalizeRequestOverrides on
+// overrides unless it is nil.
+func normalizeOptionalRequestOverrides(overrides *model.FeedRequestOverrides) (*model.FeedRequestOverrides, error) {
+	if overrides == nil {
+		return nil, nil
+	}
+	normalized, err := NormalizeRequestOverrides(*overrides)
+	if err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+// overridesUserAgent reports whether overrides replace the User-Agent, in
+// which case the fallback User-Agent is not tried either.
+func overridesUserAgent(overrides *model.FeedRequestOverrides) bool {
+	return overrides != nil && overrides.UserAgent != ""
+}
+
+// applyRequestOverrides sets a feed's User-Agent and headers on a request
+// for it or one of its pages. Credentials are applied after, so they win.
+func applyRequestOverrides(req *http.Request, overrides *model.FeedRequestOverrides) {
+	if overrides == nil {
+		return
+	}
+	if overrides.UserAgent != "" {
+		req.Header.Set("User-Agent", overrides.UserAgent)
+	}
+	for name, value := range overrides.Headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// overrideOrderedHeaders applies a feed's overrides to the browser headers
+// readability fetches pages with, keeping their order.
+func overrideOrderedHeaders(headers azuretls.OrderedHeaders, overrides *model.FeedRequestOverrides) azuretls.OrderedHeaders {
+	if overrides == nil {
+		return headers
+	}
+	set := func(name, value string) {
+		for _, header := range headers {
+			if len(header) > 0 && strings.EqualFold(header[0], name) {
+				header[1] = value
+				return
+			}
+		}
+		headers = append(headers, []string{strings.ToLower(name), value})
+	}
+	if overrides.UserAgent != "" {
+		set("User-Agent", overrides.UserAgent)
+	}
+	for name, value := range overrides.Headers {
+		set(name, value)
+	}
+	return headers
+}
+
+type requestOverridesKey struct{}
+
+// withRequestOverrides returns a context whose page and icon fetches are
+// sent with a feed's overrides.
+func withRequestOverrides(ctx context.Context, overrides *model.FeedRequestOverrides) context.Context {
+	if overrides == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestOverridesKey{}, overrides)
+}
+
+// requestOverridesFrom returns the overrides ctx carries, if any.
+func requestOverridesFrom(ctx context.Context) *model.FeedRequestOverrides {
+	overrides, _ := ctx.Value(requestOverridesKey{}).(*model.FeedRequestOverrides)
+	return overrides
+}
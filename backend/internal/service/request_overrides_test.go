@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Noooste/azuretls-client"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestNormalizeRequestOverrides(t *testing.T) {
+	overrides, err := NormalizeRequestOverrides(model.FeedRequestOverrides{
+		UserAgent: " Feedly/1.0 ",
+		Headers:   map[string]string{"referer": " https://example.com/ "},
+	})
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if overrides.UserAgent != "Feedly/1.0" || overrides.Headers["Referer"] != "https://example.com/" {
+		t.Errorf("unexpected overrides %+v", overrides)
+	}
+	if empty, err := NormalizeRequestOverrides(model.FeedRequestOverrides{UserAgent: "  "}); err != nil || !empty.IsEmpty() {
+		t.Errorf("expected blank overrides to be empty, got %+v, %v", empty, err)
+	}
+
+	for _, bad := range []model.FeedRequestOverrides{
+		{UserAgent: "line\nbreak"},
+		{UserAgent: strings.Repeat("a", maxUserAgentLength+1)},
+		{Headers: map[string]string{"user-agent": "Feedly/1.0"}},
+		{Headers: map[string]string{"If-None-Match": "x"}},
+	} {
+		if _, err := NormalizeRequestOverrides(bad); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected %+v to be invalid, got %v", bad, err)
+		}
+	}
+}
+
+// pickyServer serves a feed only to the Feedly User-Agent with a referer.
+func pickyServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != "Feedly/1.0" || r.Header.Get("Referer") != "https://example.com/" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rss+xml")
+		fmt.Fprint(w, `<rss version="2.0"><channel><title>Picky</title></channel></rss>`)
+	}))
+}
+
+func TestFeedService_PreviewWithRequestOverrides(t *testing.T) {
+	server := pickyServer(t)
+	defer server.Close()
+
+	service := NewFeedService(nil, nil, nil, nil, nil, server.Client(), nil, nil, nil)
+	ctx := context.Background()
+
+	if _, err := service.Preview(ctx, server.URL, nil, nil); !errors.Is(err, ErrFeedFetch) {
+		t.Fatalf("expected ErrFeedFetch with the default User-Agent, got %v", err)
+	}
+	overrides := &model.FeedRequestOverrides{UserAgent: "Feedly/1.0", Headers: map[string]string{"referer": "https://example.com/"}}
+	preview, err := service.Preview(ctx, server.URL, overrides, nil)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if preview.Title != "Picky" {
+		t.Errorf("unexpected title %q", preview.Title)
+	}
+}
+
+func TestFeedService_PreviewScrapedWithRequestOverrides(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.UserAgent() != "Feedly/1.0" || r.Header.Get("Referer") != "https://example.com/" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `<html><body><article><a href="/posts/1">Post</a></article></body></html>`)
+	}))
+	defer server.Close()
+
+	service := NewFeedService(nil, nil, nil, nil, nil, server.Client(), nil, nil, nil)
+	ctx := context.Background()
+	scraper := model.FeedScraper{Items: "article"}
+
+	if _, err := service.PreviewScraped(ctx, server.URL, scraper, nil, nil, nil); !errors.Is(err, ErrFeedFetch) {
+		t.Fatalf("expected ErrFeedFetch with the default User-Agent, got %v", err)
+	}
+	overrides := &model.FeedRequestOverrides{UserAgent: "Feedly/1.0", Headers: map[string]string{"referer": "https://example.com/"}}
+	preview, err := service.PreviewScraped(ctx, server.URL, scraper, nil, overrides, nil)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if len(preview.Items) != 1 {
+		t.Errorf("expected the page's item, got %+v", preview.Items)
+	}
+}
+
+func TestRefreshService_SendsRequestOverrides(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := pickyServer(t)
+	defer server.Close()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	service := NewRefreshService(mockFeeds, nil, nil, nil, server.Client(), nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	overrides := &model.FeedRequestOverrides{UserAgent: "Feedly/1.0", Headers: map[string]string{"Referer": "https://example.com/"}}
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Picky", URL: server.URL, RequestOverrides: overrides}, nil)
+	mockFeeds.EXPECT().ScheduleRefresh(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+
+	if err := service.RefreshFeed(ctx, 1); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+}
+
+func TestOverrideOrderedHeaders(t *testing.T) {
+	headers := azuretls.OrderedHeaders{
+		{"accept", "text/html"},
+		{"user-agent", config.ChromeUserAgent},
+	}
+	got := overrideOrderedHeaders(headers, &model.FeedRequestOverrides{
+		UserAgent: "Feedly/1.0",
+		Headers:   map[string]string{"Referer": "https://example.com/"},
+	})
+	want := azuretls.OrderedHeaders{
+		{"accept", "text/html"},
+		{"user-agent", "Feedly/1.0"},
+		{"referer", "https://example.com/"},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := overrideOrderedHeaders(want, nil); len(got) != len(want) {
+		t.Errorf("expected no overrides to change nothing, got %v", got)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"gist/backend/internal/repository"
+)
+
+// pruneBatch is how many entries PruneEntries deletes per statement, so a
+// first run over a large database does not hold the write lock for long.
+const pruneBatch = 500
+
+// PruneResult summarizes a pruning run.
+type PruneResult struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// PruneEntries deletes the unstarred entries past the retention set in
+// GeneralSettings. Entries age from when they were fetched, not published,
+// so an old item still in its feed is not deleted and fetched again as new;
+// a per-feed limit below the number of items a feed carries would do that.
+func PruneEntries(ctx context.Context, settings SettingsService, entries repository.EntryRepository, now time.Time) (PruneResult, error) {
+	var result PruneResult
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil {
+		return result, fmt.Errorf("get retention settings: %w", err)
+	}
+	var before time.Time
+	if general.RetentionDays > 0 {
+		before = now.AddDate(0, 0, -general.RetentionDays)
+	}
+	if before.IsZero() && general.RetentionMaxPerFeed == 0 {
+		return result, nil
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		deleted, err := entries.PruneOld(ctx, before, general.RetentionMaxPerFeed, pruneBatch)
+		if err != nil {
+			return result, fmt.Errorf("prune entries: %w", err)
+		}
+		result.Deleted += deleted
+		if deleted < pruneBatch {
+			break
+		}
+	}
+	log.Printf("retention: pruned %d entries", result.Deleted)
+	return result, nil
+}
+
+// PruneTask runs PruneEntries as a task.
+func PruneTask(settings SettingsService, entries repository.EntryRepository) TaskFunc {
+	return func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		return PruneEntries(ctx, settings, entries, time.Now())
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestPruneEntries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	settings := NewSettingsService(&memorySettings{values: map[string]string{}}, nil)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	// Without retention nothing is pruned
+	if result, err := PruneEntries(ctx, settings, mockEntries, now); err != nil || result.Deleted != 0 {
+		t.Fatalf("expected nothing pruned, got %+v, %v", result, err)
+	}
+
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	general.RetentionDays = 30
+	general.RetentionMaxPerFeed = 200
+	if err := settings.SetGeneralSettings(ctx, general); err != nil {
+		t.Fatalf("set settings: %v", err)
+	}
+
+	// Batches run until one comes back short
+	before := now.AddDate(0, 0, -30)
+	gomock.InOrder(
+		mockEntries.EXPECT().PruneOld(ctx, before, 200, pruneBatch).Return(int64(pruneBatch), nil),
+		mockEntries.EXPECT().PruneOld(ctx, before, 200, pruneBatch).Return(int64(12), nil),
+	)
+	result, err := PruneEntries(ctx, settings, mockEntries, now)
+	if err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	if result.Deleted != pruneBatch+12 {
+		t.Errorf("expected %d pruned, got %d", pruneBatch+12, result.Deleted)
+	}
+
+	general.RetentionDays = -1
+	if err := settings.SetGeneralSettings(ctx, general); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for a negative retention, got %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+
+	"gist/backend/internal/model"
+)
+
+// DefaultEmbedHosts are the hosts whose iframes entry content keeps when no
+// list was set: the YouTube and Vimeo players.
+var DefaultEmbedHosts = []string{"youtube.com", "youtube-nocookie.com", "vimeo.com"}
+
+// embedHostPattern is a host name, or a domain whose subdomains are included.
+var embedHostPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+// ParseEmbedHosts parses the comma- or line-separated hosts whose iframes are
+// kept in entry content. An empty list keeps no iframes.
+func ParseEmbedHosts(raw string) ([]string, error) {
+	hosts := parseCookieHosts(raw)
+	for _, host := range hosts {
+		if !embedHostPattern.MatchString(host) {
+			return nil, fmt.Errorf("%w: invalid embed host %q", ErrInvalid, host)
+		}
+	}
+	return hosts, nil
+}
+
+// ContentSanitizer cleans the HTML of entries before it is stored: scripts,
+// event handlers, styles and iframes from hosts not in the embed hosts
+// setting are removed. A nil sanitizer uses the default embed hosts.
+type ContentSanitizer struct {
+	settings SettingsService
+
+	mu     sync.Mutex
+	hosts  string // the embed hosts policy was built for
+	policy *bluemonday.Policy
+}
+
+// defaultContentPolicy is the policy of a sanitizer without settings.
+var defaultContentPolicy = newContentPolicy(DefaultEmbedHosts)
+
+func NewContentSanitizer(settings SettingsService) *ContentSanitizer {
+	return &ContentSanitizer{settings: settings}
+}
+
+// Sanitize returns content safe to store and show.
+func (s *ContentSanitizer) Sanitize(ctx context.Context, content string) string {
+	if content == "" {
+		return content
+	}
+	return s.contentPolicy(ctx).Sanitize(content)
+}
+
+// SanitizeEntry sanitizes the content of an entry in place.
+func (s *ContentSanitizer) SanitizeEntry(ctx context.Context, entry *model.Entry) {
+	if entry.Content == nil {
+		return
+	}
+	content := s.Sanitize(ctx, *entry.Content)
+	entry.Content = &content
+}
+
+// contentPolicy returns the policy for the current embed hosts, built again
+// only when they changed.
+func (s *ContentSanitizer) contentPolicy(ctx context.Context) *bluemonday.Policy {
+	if s == nil || s.settings == nil {
+		return defaultContentPolicy
+	}
+	general, err := s.settings.GetGeneralSettings(ctx)
+	if err != nil {
+		return defaultContentPolicy
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.policy == nil || s.hosts != general.EmbedHosts {
+		// Hosts are checked when saved, so a bad list was written some
+		// other way and keeps no iframes
+		hosts, _ := ParseEmbedHosts(general.EmbedHosts)
+		s.policy = newContentPolicy(hosts)
+		s.hosts = general.EmbedHosts
+	}
+	return s.policy
+}
+
+// newContentPolicy allows the markup of user-generated content plus media
+// and the iframes of embedHosts and their subdomains.
+func newContentPolicy(embedHosts []string) *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowRelativeURLs(true)
+	p.AllowElements("article", "section", "header", "footer", "aside", "main", "figure", "figcaption", "picture", "mark", "time")
+	p.AllowAttrs("lang", "dir").Globally()
+	p.AllowAttrs("srcset", "sizes", "loading").OnElements("img")
+	p.AllowAttrs("src", "srcset", "sizes", "media", "type").OnElements("source")
+	p.AllowAttrs("src", "poster", "controls", "loop", "muted", "playsinline", "preload", "width", "height").OnElements("video")
+	p.AllowAttrs("src", "controls", "loop", "muted", "preload").OnElements("audio")
+
+	if len(embedHosts) > 0 {
+		quoted := make([]string, len(embedHosts))
+		for i, host := range embedHosts {
+			quoted[i] = regexp.QuoteMeta(host)
+		}
+		src := regexp.MustCompile(`(?i)^(https?:)?//([a-z0-9-]+\.)*(` + strings.Join(quoted, "|") + `)(:\d+)?(/|$)`)
+		p.AllowAttrs("src").Matching(src).OnElements("iframe")
+		p.AllowAttrs("width", "height", "title", "allow", "allowfullscreen", "frameborder").OnElements("iframe")
+	}
+	return p
+}
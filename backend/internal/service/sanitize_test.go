@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestContentSanitizer_Sanitize(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		keep    []string
+		drop    []string
+	}{
+		{"script", `<p>Hi</p><script>alert(1)</script>`, []string{"<p>Hi</p>"}, []string{"script", "alert"}},
+		{"event handler", `<img src="https://example.com/a.png" onerror="alert(1)">`, []string{`src="https://example.com/a.png"`}, []string{"onerror"}},
+		{"style", `<p style="position:fixed">Hi</p>`, []string{"<p>Hi</p>"}, []string{"style"}},
+		{"youtube iframe", `<iframe src="https://www.youtube.com/embed/abc" allowfullscreen></iframe>`, []string{`src="https://www.youtube.com/embed/abc"`}, nil},
+		{"other iframe", `<iframe src="https://evil.example/youtube.com/"></iframe>`, nil, []string{"iframe", "evil"}},
+		{"lookalike host", `<iframe src="https://notyoutube.com/embed/abc"></iframe>`, nil, []string{"notyoutube"}},
+		{"javascript link", `<a href="javascript:alert(1)">x</a>`, []string{"x"}, []string{"javascript"}},
+		{"relative image", `<img src="/a.png" srcset="/a-2x.png 2x">`, []string{`src="/a.png"`, "srcset"}, nil},
+	}
+	var sanitizer *ContentSanitizer
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sanitizer.Sanitize(context.Background(), tt.content)
+			for _, s := range tt.keep {
+				if !strings.Contains(got, s) {
+					t.Errorf("Sanitize(%q) = %q, want it to keep %q", tt.content, got, s)
+				}
+			}
+			for _, s := range tt.drop {
+				if strings.Contains(got, s) {
+					t.Errorf("Sanitize(%q) = %q, want %q removed", tt.content, got, s)
+				}
+			}
+		})
+	}
+}
+
+func TestContentSanitizer_EmbedHostsSetting(t *testing.T) {
+	ctx := context.Background()
+	settings := NewSettingsService(&memorySettings{values: map[string]string{}}, nil)
+	sanitizer := NewContentSanitizer(settings)
+	vimeo := `<iframe src="https://player.vimeo.com/video/1"></iframe>`
+	if got := sanitizer.Sanitize(ctx, vimeo); !strings.Contains(got, "player.vimeo.com") {
+		t.Fatalf("expected the default hosts to keep vimeo, got %q", got)
+	}
+
+	general, err := settings.GetGeneralSettings(ctx)
+	if err != nil {
+		t.Fatalf("get settings: %v", err)
+	}
+	general.EmbedHosts = ""
+	if err := settings.SetGeneralSettings(ctx, general); err != nil {
+		t.Fatalf("set settings: %v", err)
+	}
+	if got := sanitizer.Sanitize(ctx, vimeo); strings.Contains(got, "iframe") {
+		t.Errorf("expected no iframes without embed hosts, got %q", got)
+	}
+
+	general.EmbedHosts = "example.org"
+	if err := settings.SetGeneralSettings(ctx, general); err != nil {
+		t.Fatalf("set settings: %v", err)
+	}
+	if got := sanitizer.Sanitize(ctx, `<iframe src="https://media.example.org/x"></iframe>`); !strings.Contains(got, "media.example.org") {
+		t.Errorf("expected subdomains of an embed host kept, got %q", got)
+	}
+}
+
+func TestParseEmbedHosts(t *testing.T) {
+	hosts, err := ParseEmbedHosts("YouTube.com,\n vimeo.com")
+	if err != nil || len(hosts) != 2 {
+		t.Fatalf("ParseEmbedHosts() = %v, %v", hosts, err)
+	}
+	for _, raw := range []string{"https://youtube.com", "localhost", "bad host.com", "*.example.com"} {
+		if _, err := ParseEmbedHosts(raw); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ParseEmbedHosts(%q) error = %v, want ErrInvalid", raw, err)
+		}
+	}
+}
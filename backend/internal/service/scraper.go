@@ -0,0 +1,373 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/andybalholm/cascadia"
+	"github.com/araddon/dateparse"
+	"github.com/mmcdole/gofeed"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+)
+
+const (
+	// maxScrapedItems bounds the entries taken from one page, in page order.
+	maxScrapedItems = 100
+	// maxScrapedPageSize caps the page read for scraping.
+	maxScrapedPageSize = 10 << 20
+	// pageAccept asks for the page itself rather than a feed.
+	pageAccept = "text/html, application/xhtml+xml;q=0.9, */*;q=0.8"
+)
+
+// errNothingScraped is returned when the selectors pick no entry out of a
+// page, which usually means the site changed its markup.
+var errNothingScraped = fmt.Errorf("%w: no items match the selectors", ErrInvalid)
+
+// NormalizeScraper trims the selectors of a scraped feed and checks they
+// are valid CSS. Items is required unless every selector is empty.
+func NormalizeScraper(scraper model.FeedScraper) (model.FeedScraper, error) {
+	scraper = model.FeedScraper{
+		Items: strings.TrimSpace(scraper.Items),
+		Title: strings.TrimSpace(scraper.Title),
+		Link:  strings.TrimSpace(scraper.Link),
+		Date:  strings.TrimSpace(scraper.Date),
+	}
+	if scraper.IsEmpty() {
+		return scraper, nil
+	}
+	if scraper.Items == "" {
+		return model.FeedScraper{}, fmt.Errorf("%w: an items selector is required", ErrInvalid)
+	}
+	for _, sel := range []string{scraper.Items, scraper.Title, scraper.Link, scraper.Date} {
+		if sel == "" {
+			continue
+		}
+		if _, err := cascadia.ParseGroup(sel); err != nil {
+			return model.FeedScraper{}, fmt.Errorf("%w: invalid selector %q: %v", ErrInvalid, sel, err)
+		}
+	}
+	return scraper, nil
+}
+
+// scrapePage turns the items a page's selectors pick out into feed items,
+// with links resolved against pageURL. Items without a web link are left
+// out, as are duplicates. Dates are read as a feed's are, from a datetime
+// attribute when the element has one; items without leave PublishedParsed
+// nil. Returns errNothingScraped when no item remains.
+func scrapePage(body []byte, pageURL string, scraper model.FeedScraper) (*gofeed.Feed, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("parse page: %w", err)
+	}
+	base, _ := url.Parse(pageURL)
+
+	parsed := &gofeed.Feed{
+		Title: collapseSpace(doc.Find("title").First().Text()),
+		Link:  pageURL,
+	}
+	if description, ok := doc.Find(`meta[name="description"]`).First().Attr("content"); ok {
+		parsed.Description = strings.TrimSpace(description)
+	}
+	seen := make(map[string]bool)
+	doc.Find(scraper.Items).EachWithBreak(func(_ int, item *goquery.Selection) bool {
+		link, ok := scrapedLink(item, scraper.Link, base)
+		if !ok || seen[link] {
+			return true
+		}
+		seen[link] = true
+
+		title := collapseSpace(pick(item, scraper.Title).Text())
+		if title == "" {
+			title = link
+		}
+		scraped := &gofeed.Item{Title: title, Link: link, GUID: link}
+		if scraper.Date != "" {
+			scraped.Published, scraped.PublishedParsed = scrapedDate(pick(item, scraper.Date))
+		}
+		parsed.Items = append(parsed.Items, scraped)
+		return len(parsed.Items) < maxScrapedItems
+	})
+	if len(parsed.Items) == 0 {
+		return nil, errNothingScraped
+	}
+	return parsed, nil
+}
+
+// pick returns the first match of sel within item, or item itself when sel
+// is empty.
+func pick(item *goquery.Selection, sel string) *goquery.Selection {
+	if sel == "" {
+		return item
+	}
+	return item.Find(sel).First()
+}
+
+// scrapedLink finds the link of an item: the href of what sel picks, or of
+// the first link inside it.
+func scrapedLink(item *goquery.Selection, sel string, base *url.URL) (string, bool) {
+	picked := pick(item, sel)
+	href, ok := picked.Attr("href")
+	if !ok {
+		href, ok = picked.Find("a[href]").First().Attr("href")
+	}
+	if !ok {
+		return "", false
+	}
+	return resolveImageURL(base, href)
+}
+
+// scrapedDate reads the date of an element, preferring the machine-readable
+// datetime attribute of a time element. raw is what was read, for the
+// feed's date corrections.
+func scrapedDate(el *goquery.Selection) (string, *time.Time) {
+	raw, ok := el.Attr("datetime")
+	if !ok {
+		raw, ok = el.Find("time[datetime]").First().Attr("datetime")
+	}
+	if !ok {
+		raw = collapseSpace(el.Text())
+	}
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil
+	}
+	t, err := dateparse.ParseIn(raw, time.UTC)
+	if err != nil {
+		return raw, nil
+	}
+	return raw, &t
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// dateUndated dates the scraped items a page gives no date when they are
+// first seen: known items keep the date they were stored with, found by
+// storedDate, and new ones get now.
+func dateUndated(items []*gofeed.Item, now time.Time, storedDate func(link string) *time.Time) {
+	for _, item := range items {
+		if item.PublishedParsed != nil {
+			continue
+		}
+		if storedDate != nil {
+			if stored := storedDate(item.Link); stored != nil {
+				item.PublishedParsed = stored
+				continue
+			}
+		}
+		first := now
+		item.PublishedParsed = &first
+	}
+}
+
+// acceptFor is the Accept header a feed is fetched with.
+func acceptFor(feed model.Feed) string {
+	if feed.Scraper != nil {
+		return pageAccept
+	}
+	return feedAccept
+}
+
+// parseFeed parses what fetching a feed returned: the feed itself, or the
+// page of a scraped feed, whose undated items keep the date they were
+// first seen with.
+func (s *refreshService) parseFeed(ctx context.Context, feed model.Feed, body []byte) (*gofeed.Feed, error) {
+	if feed.Scraper == nil {
+		return newFeedParser().Parse(bytes.NewReader(body))
+	}
+	parsed, err := scrapePage(body, feed.URL, *feed.Scraper)
+	if err != nil {
+		return nil, err
+	}
+	dateUndated(parsed.Items, time.Now(), func(link string) *time.Time {
+		if existing, err := s.entries.GetByURL(ctx, feed.ID, link); err == nil {
+			return existing.PublishedAt
+		}
+		return nil
+	})
+	return parsed, nil
+}
+
+// fetchPage fetches the page of a scraped feed, with the overrides ctx
+// carries.
+func fetchPage(ctx context.Context, client *http.Client, pageURL string, auth *model.FeedAuth) ([]byte, http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", config.DefaultUserAgent)
+	req.Header.Set("Accept", pageAccept)
+	applyRequestOverrides(req, requestOverridesFrom(ctx))
+	applyFeedAuth(req, auth)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, nil, &httpStatusError{status: resp.StatusCode}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxScrapedPageSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	return body, resp.Header, nil
+}
+
+// fetchScraped fetches and scrapes the page of a scraped feed. Its items
+// are all new, so the undated ones are dated now.
+func (s *feedService) fetchScraped(ctx context.Context, pageURL string, scraper model.FeedScraper, auth *model.FeedAuth) (feedFetch, error) {
+	body, header, err := fetchPage(ctx, s.httpClient, pageURL, auth)
+	if err != nil {
+		return feedFetch{}, err
+	}
+	parsed, err := scrapePage(body, pageURL, scraper)
+	if err != nil {
+		return feedFetch{}, err
+	}
+	dateUndated(parsed.Items, time.Now(), nil)
+	count := len(parsed.Items)
+	return feedFetch{
+		title:        parsed.Title,
+		description:  parsed.Description,
+		siteURL:      pageURL,
+		itemCount:    &count,
+		etag:         strings.TrimSpace(header.Get("ETag")),
+		lastModified: strings.TrimSpace(header.Get("Last-Modified")),
+		items:        parsed.Items,
+	}, nil
+}
+
+func (s *feedService) AddScraped(ctx context.Context, pageURL string, folderID *int64, titleOverride string, feedType string, scraper model.FeedScraper, dateQuirks *model.FeedDateQuirks, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (model.Feed, error) {
+	trimmedURL := strings.TrimSpace(pageURL)
+	if !isValidURL(trimmedURL) {
+		return model.Feed{}, ErrInvalid
+	}
+	scraper, err := NormalizeScraper(scraper)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	if scraper.IsEmpty() {
+		return model.Feed{}, fmt.Errorf("%w: an items selector is required", ErrInvalid)
+	}
+	dateQuirks, err = normalizeOptionalDateQuirks(dateQuirks)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	overrides, err = normalizeOptionalRequestOverrides(overrides)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	if overrides != nil && overrides.IsEmpty() {
+		overrides = nil
+	}
+	auth, err = normalizeOptionalFeedAuth(auth)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	existing, err := s.checkNewFeed(ctx, trimmedURL, folderID)
+	if err != nil {
+		return model.Feed{}, err
+	}
+	if existing != nil {
+		restored, err := s.restoreArchived(ctx, *existing, folderID, titleOverride, feedType, auth)
+		if err != nil {
+			return model.Feed{}, err
+		}
+		if err := s.feeds.UpdateScraper(ctx, restored.ID, &scraper); err != nil {
+			return model.Feed{}, fmt.Errorf("update scraper: %w", err)
+		}
+		restored.Scraper = &scraper
+		if dateQuirks != nil {
+			if err := s.feeds.UpdateDateQuirks(ctx, restored.ID, dateQuirks); err != nil {
+				return model.Feed{}, fmt.Errorf("update date quirks: %w", err)
+			}
+			restored.DateQuirks = dateQuirks
+		}
+		if overrides != nil {
+			if err := s.feeds.UpdateRequestOverrides(ctx, restored.ID, overrides); err != nil {
+				return model.Feed{}, fmt.Errorf("update request overrides: %w", err)
+			}
+			restored.RequestOverrides = overrides
+		}
+		return restored, nil
+	}
+
+	fetched, fetchErr := s.fetchScraped(withRequestOverrides(ctx, overrides), trimmedURL, scraper, auth)
+	if errors.Is(fetchErr, ErrInvalid) {
+		return model.Feed{}, fetchErr
+	}
+	if fetchErr != nil {
+		// The page may be back by the first refresh
+		feed := failedFeed(trimmedURL, folderID, titleOverride, feedType, auth, fetchErr)
+		feed.Scraper = &scraper
+		feed.DateQuirks = dateQuirks
+		feed.RequestOverrides = overrides
+		return s.createWithSideEffects(ctx, feed, feedFetch{})
+	}
+	feed := fetchedFeed(trimmedURL, folderID, titleOverride, feedType, auth, fetched)
+	feed.Scraper = &scraper
+	feed.DateQuirks = dateQuirks
+	feed.RequestOverrides = overrides
+	return s.createWithSideEffects(ctx, feed, fetched)
+}
+
+func (s *feedService) PreviewScraped(ctx context.Context, pageURL string, scraper model.FeedScraper, dateQuirks *model.FeedDateQuirks, overrides *model.FeedRequestOverrides, auth *model.FeedAuth) (ScrapePreview, error) {
+	trimmedURL := strings.TrimSpace(pageURL)
+	if !isValidURL(trimmedURL) {
+		return ScrapePreview{}, ErrInvalid
+	}
+	scraper, err := NormalizeScraper(scraper)
+	if err != nil {
+		return ScrapePreview{}, err
+	}
+	if scraper.IsEmpty() {
+		return ScrapePreview{}, fmt.Errorf("%w: an items selector is required", ErrInvalid)
+	}
+	dateQuirks, err = normalizeOptionalDateQuirks(dateQuirks)
+	if err != nil {
+		return ScrapePreview{}, err
+	}
+	overrides, err = normalizeOptionalRequestOverrides(overrides)
+	if err != nil {
+		return ScrapePreview{}, err
+	}
+	auth, err = normalizeOptionalFeedAuth(auth)
+	if err != nil {
+		return ScrapePreview{}, err
+	}
+
+	body, _, err := fetchPage(withRequestOverrides(ctx, overrides), s.httpClient, trimmedURL, auth)
+	if err != nil {
+		return ScrapePreview{}, ErrFeedFetch
+	}
+	parsed, err := scrapePage(body, trimmedURL, scraper)
+	if err != nil {
+		return ScrapePreview{}, err
+	}
+	preview := ScrapePreview{URL: trimmedURL, Title: parsed.Title, Items: make([]ScrapedItem, len(parsed.Items))}
+	if preview.Title == "" {
+		preview.Title = trimmedURL
+	}
+	// Dated as the first refresh will store them
+	dates := loadFutureDates(ctx, s.settings).forFeed(model.Feed{DateQuirks: dateQuirks})
+	itemDates := newItemDates(dateQuirks, dates.now)
+	for i, item := range parsed.Items {
+		entry := model.Entry{PublishedAt: itemDates.published(item, false)}
+		dates.clamp(&entry, nil)
+		preview.Items[i] = ScrapedItem{Title: item.Title, URL: item.Link, PublishedAt: entry.PublishedAt}
+	}
+	return preview, nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+)
+
+const scrapedPage = `<html><head><title> Example  News </title><meta name="description" content="Latest news"></head><body>
+<article class="post"><h2><a href="/posts/1">First <em>post</em></a></h2><time datetime="2024-03-01T10:00:00Z">March 1</time></article>
+<article class="post"><h2><a href="https://other.example.com/2">Second post</a></h2><span class="date">March 2, 2024</span></article>
+<article class="post"><h2><a href="/posts/1">First post again</a></h2></article>
+<article class="post"><h2>No link</h2></article>
+<article class="post"><a href="/posts/3"></a></article>
+</body></html>`
+
+func TestScrapePage(t *testing.T) {
+	parsed, err := scrapePage([]byte(scrapedPage), "https://example.com/news/", model.FeedScraper{Items: "article.post", Title: "h2", Link: "h2 a, a", Date: "time, .date"})
+	if err != nil {
+		t.Fatalf("scrape page: %v", err)
+	}
+	if parsed.Title != "Example News" || parsed.Description != "Latest news" {
+		t.Errorf("expected the page's title and description, got %q, %q", parsed.Title, parsed.Description)
+	}
+	if len(parsed.Items) != 3 {
+		t.Fatalf("expected 3 items without duplicates or unlinked ones, got %d", len(parsed.Items))
+	}
+
+	first := parsed.Items[0]
+	if first.Title != "First post" || first.Link != "https://example.com/posts/1" || first.GUID != first.Link {
+		t.Errorf("unexpected first item %q %q %q", first.Title, first.Link, first.GUID)
+	}
+	if want := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC); first.PublishedParsed == nil || !first.PublishedParsed.Equal(want) {
+		t.Errorf("expected the datetime attribute to be read, got %v", first.PublishedParsed)
+	}
+	if second := parsed.Items[1]; second.PublishedParsed == nil || second.PublishedParsed.Day() != 2 || second.Published != "March 2, 2024" {
+		t.Errorf("expected the date text to be read, got %v from %q", second.PublishedParsed, second.Published)
+	}
+	if third := parsed.Items[2]; third.Title != "https://example.com/posts/3" || third.PublishedParsed != nil {
+		t.Errorf("expected an untitled, undated item titled by its link, got %q, %v", third.Title, third.PublishedParsed)
+	}
+}
+
+func TestScrapePage_NothingMatched(t *testing.T) {
+	_, err := scrapePage([]byte(scrapedPage), "https://example.com/", model.FeedScraper{Items: "li.entry"})
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid when no item matches, got %v", err)
+	}
+}
+
+func TestNormalizeScraper(t *testing.T) {
+	scraper, err := NormalizeScraper(model.FeedScraper{Items: " article ", Date: " time "})
+	if err != nil || scraper != (model.FeedScraper{Items: "article", Date: "time"}) {
+		t.Errorf("expected trimmed selectors, got %+v, %v", scraper, err)
+	}
+	if scraper, err := NormalizeScraper(model.FeedScraper{Items: "  "}); err != nil || !scraper.IsEmpty() {
+		t.Errorf("expected blank selectors to be empty, got %+v, %v", scraper, err)
+	}
+	for _, invalid := range []model.FeedScraper{
+		{Title: "h2"},
+		{Items: "article["},
+		{Items: "article", Link: "a::"},
+	} {
+		if _, err := NormalizeScraper(invalid); !errors.Is(err, ErrInvalid) {
+			t.Errorf("expected ErrInvalid for %+v, got %v", invalid, err)
+		}
+	}
+}
+
+func TestDateUndated(t *testing.T) {
+	parsed, err := scrapePage([]byte(scrapedPage), "https://example.com/", model.FeedScraper{Items: "article.post", Date: "time"})
+	if err != nil {
+		t.Fatalf("scrape page: %v", err)
+	}
+	now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	stored := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	dateUndated(parsed.Items, now, func(link string) *time.Time {
+		if link == "https://other.example.com/2" {
+			return &stored
+		}
+		return nil
+	})
+
+	if got := parsed.Items[0].PublishedParsed; got.Day() != 1 || got.Month() != time.March {
+		t.Errorf("expected the scraped date to be kept, got %v", got)
+	}
+	if got := parsed.Items[1].PublishedParsed; !got.Equal(stored) {
+		t.Errorf("expected a known item to keep its stored date, got %v", got)
+	}
+	if got := parsed.Items[2].PublishedParsed; !got.Equal(now) {
+		t.Errorf("expected a new item to be dated now, got %v", got)
+	}
+}
+
+func TestFeedService_PreviewScrapedAppliesDateQuirks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body>
+<article><a href="/posts/1">Local</a><time>2024-03-01 10:00</time></article>
+<article><a href="/posts/2">Day first</a><time>03/04/2024</time></article>
+<article><a href="/posts/3">Future</a><time datetime="2999-01-01T00:00:00Z"></time></article>
+</body></html>`)
+	}))
+	defer server.Close()
+
+	service := NewFeedService(nil, nil, nil, nil, nil, server.Client(), nil, nil, nil)
+	quirks := &model.FeedDateQuirks{Timezone: "Asia/Shanghai", DayFirst: true, IgnoreFuture: true}
+	before := time.Now()
+	preview, err := service.PreviewScraped(context.Background(), server.URL, model.FeedScraper{Items: "article", Date: "time"}, quirks, nil, nil)
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if len(preview.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(preview.Items))
+	}
+	if got, want := preview.Items[0].PublishedAt, time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC); got == nil || !got.Equal(want) {
+		t.Errorf("expected the date read in the feed's zone, got %v", got)
+	}
+	if got, want := preview.Items[1].PublishedAt, time.Date(2024, 4, 2, 16, 0, 0, 0, time.UTC); got == nil || !got.Equal(want) {
+		t.Errorf("expected the date read day first in the feed's zone, got %v", got)
+	}
+	if got := preview.Items[2].PublishedAt; got == nil || got.Before(before) || got.After(time.Now()) {
+		t.Errorf("expected the future date clamped to now, got %v", got)
+	}
+}
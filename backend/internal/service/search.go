@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Search orders
+const (
+	SearchByRelevance = "relevance"
+	SearchByNewest    = "newest"
+)
+
+type EntrySearchParams struct {
+	EntryListParams
+	// Query is the search text, in the syntax of ParseSearchQuery.
+	Query string
+	// SortBy is SearchByRelevance (the default) or SearchByNewest.
+	SortBy string
+}
+
+// SearchQuery is parsed search text.
+type SearchQuery struct {
+	// Match is the FTS5 query for the entries_fts index.
+	Match       string
+	FeedID      *int64
+	FolderID    *int64
+	UnreadOnly  bool
+	StarredOnly bool
+}
+
+// searchTokenPattern splits search text into an optional "-", an optional
+// "field:" and a word or "quoted phrase" (an unclosed quote runs to the end).
+var searchTokenPattern = regexp.MustCompile(`(-)?(?:([a-z]+):)?("[^"]*"?|[^\s"]+)`)
+
+// searchColumns are the fields a word or phrase can be limited to.
+var searchColumns = map[string]bool{"title": true, "author": true}
+
+// ParseSearchQuery turns search text into an FTS5 query and the filters it
+// names. All words must match; "quoted words" match as a phrase, a trailing *
+// matches a prefix, -word leaves out entries containing it, and title: or
+// author: limits a word or phrase to that field. feed:ID, folder:ID, is:unread
+// and is:starred narrow the search. Words are always quoted for FTS5, so the
+// text cannot inject its own operators.
+func ParseSearchQuery(text string) (SearchQuery, error) {
+	var query SearchQuery
+	var include, exclude []string
+	for _, m := range searchTokenPattern.FindAllStringSubmatch(text, -1) {
+		negated, field, value := m[1] == "-", m[2], m[3]
+		switch field {
+		case "feed", "folder":
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || negated {
+				return SearchQuery{}, fmt.Errorf("%w: %s: needs an ID", ErrInvalid, field)
+			}
+			if field == "feed" {
+				query.FeedID = &id
+			} else {
+				query.FolderID = &id
+			}
+			continue
+		case "is":
+			switch {
+			case value == "unread" && !negated:
+				query.UnreadOnly = true
+			case value == "starred" && !negated:
+				query.StarredOnly = true
+			default:
+				return SearchQuery{}, fmt.Errorf("%w: unknown filter is:%s", ErrInvalid, value)
+			}
+			continue
+		}
+
+		column := ""
+		if searchColumns[field] {
+			column = field + " : "
+		} else if field != "" {
+			// Not a field, e.g. the scheme of a pasted link
+			value = field + ":" + value
+		}
+		term, ok := ftsTerm(value)
+		if !ok {
+			continue
+		}
+		if negated {
+			exclude = append(exclude, column+term)
+		} else {
+			include = append(include, column+term)
+		}
+	}
+
+	if len(include) == 0 {
+		return SearchQuery{}, fmt.Errorf("%w: nothing to search for", ErrInvalid)
+	}
+	query.Match = strings.Join(include, " ")
+	if len(exclude) > 0 {
+		query.Match = "(" + query.Match + ") NOT " + strings.Join(exclude, " NOT ")
+	}
+	return query, nil
+}
+
+// ftsTerm quotes a word or phrase as an FTS5 string, keeping a trailing * as a
+// prefix match. ok is false when it holds nothing the index could match.
+func ftsTerm(value string) (string, bool) {
+	phrase := strings.HasPrefix(value, `"`)
+	value = strings.Trim(value, `"`)
+	prefix := !phrase && strings.HasSuffix(value, "*")
+	value = strings.TrimRight(value, "*")
+	if strings.IndexFunc(value, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return "", false
+	}
+	term := `"` + strings.ReplaceAll(value, `"`, `""`) + `"`
+	if prefix {
+		term += "*"
+	}
+	return term, true
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"gist/backend/internal/repository"
 	"gist/backend/internal/service/ai"
@@ -27,8 +29,87 @@ type AISettings struct {
 type GeneralSettings struct {
 	FallbackUserAgent string `json:"fallbackUserAgent"`
 	AutoReadability   bool   `json:"autoReadability"`
+	// LowData turns low-data mode on until switched off.
+	LowData bool `json:"lowData"`
+	// LowDataSchedule also turns it on daily within "HH:MM-HH:MM" local time.
+	LowDataSchedule string `json:"lowDataSchedule"`
+	// LowDataActive reports whether low-data mode is in effect now. Read-only.
+	LowDataActive bool `json:"lowDataActive"`
+	// NSFWMode is how clients show entries flagged as not safe for work:
+	// NSFWShow, NSFWBlur or NSFWHide.
+	NSFWMode string `json:"nsfwMode"`
+	// NSFWKeywords flags entries whose title or categories contain one of
+	// its comma- or line-separated keywords.
+	NSFWKeywords string `json:"nsfwKeywords"`
+	// NSFWVisionCheck asks the AI provider to check the thumbnails of new
+	// picture entries after each refresh.
+	NSFWVisionCheck bool `json:"nsfwVisionCheck"`
+	// CookieHosts lists the hosts, comma- or line-separated, whose cookies
+	// are kept and sent back on later fetches. Subdomains are included.
+	CookieHosts string `json:"cookieHosts"`
+	// TLSFingerprints maps hosts to the browser whose TLS and HTTP/2
+	// fingerprint feeds are fetched with, as comma- or line-separated
+	// "host=browser" pairs. Subdomains are included.
+	TLSFingerprints string `json:"tlsFingerprints"`
+	// IconSources is the comma-separated order feed icons are looked up in,
+	// see ParseIconSources. The default order is returned when unset.
+	IconSources string `json:"iconSources"`
+	// RetentionDays prunes unstarred entries fetched more than this many
+	// days ago; 0 keeps them regardless of age.
+	RetentionDays int `json:"retentionDays"`
+	// RetentionMaxPerFeed prunes unstarred entries past the newest this many
+	// of each feed; 0 keeps them regardless of count.
+	RetentionMaxPerFeed int `json:"retentionMaxPerFeed"`
+	// FeedDisableThreshold disables a feed after this many refreshes in a
+	// row failed; 0 never disables feeds. DefaultFeedDisableThreshold is
+	// returned when unset.
+	FeedDisableThreshold int `json:"feedDisableThreshold"`
+	// CategoryFolders files feeds added without a folder, one by one or by
+	// OPML import, into a top-level folder named after the first category
+	// the feed declares, creating it when missing.
+	CategoryFolders bool `json:"categoryFolders"`
+	// DuplicateTitles drops new entries whose title repeats one of the same
+	// feed from the last DuplicateTitleWindow hours: DuplicateTitlesOff,
+	// DuplicateTitlesExact or DuplicateTitlesFuzzy.
+	DuplicateTitles      string `json:"duplicateTitles"`
+	DuplicateTitleWindow int    `json:"duplicateTitleWindow"`
+	// EmbedHosts lists the hosts, comma- or line-separated, whose iframes
+	// entry content keeps. Subdomains are included. DefaultEmbedHosts is
+	// returned when unset; an empty list keeps no iframes.
+	EmbedHosts string `json:"embedHosts"`
+	// FutureDates is how entries the feed dates in the future are stored:
+	// FutureDatesClamp (the default), FutureDatesHide or FutureDatesKeep.
+	FutureDates string `json:"futureDates"`
+	// ContentRefetchWindow is how many hours after an entry without content
+	// is found refreshes of its feed try to fetch the content from its page;
+	// 0 turns re-fetching off.
+	ContentRefetchWindow int `json:"contentRefetchWindow"`
+	// BackupInterval is how many hours apart scheduled backups are written;
+	// 0 turns them off.
+	BackupInterval int `json:"backupInterval"`
+	// BackupKeep is how many backups are kept, the oldest being deleted
+	// first. DefaultBackupKeep is returned when unset.
+	BackupKeep int `json:"backupKeep"`
 }
 
+// AppearanceSettings is how clients show Gist, kept on the server so it is
+// the same on every device.
+type AppearanceSettings struct {
+	// Theme is ThemeLight, ThemeDark or ThemeAuto (the default).
+	Theme string `json:"theme"`
+	// AccentColor is a #rrggbb color, or empty for the default.
+	AccentColor string `json:"accentColor"`
+	// FontSize is the size of entry content in CSS pixels.
+	FontSize int `json:"fontSize"`
+	// DefaultViews maps each content type to the view its entries are
+	// listed in, ViewList or ViewMasonry.
+	DefaultViews map[string]string `json:"defaultViews"`
+}
+
+// DefaultFeedDisableThreshold is the number of failed refreshes in a row
+// after which a feed is disabled when no threshold was set.
+const DefaultFeedDisableThreshold = 10
+
 // Setting keys
 const (
 	keyAIProvider        = "ai.provider"
@@ -43,8 +124,31 @@ const (
 	keyAIAutoSummary     = "ai.auto_summary"
 	keyAIRateLimit       = "ai.rate_limit"
 
-	keyFallbackUserAgent = "general.fallback_user_agent"
-	keyAutoReadability   = "general.auto_readability"
+	keyFallbackUserAgent    = "general.fallback_user_agent"
+	keyAutoReadability      = "general.auto_readability"
+	keyLowData              = "general.low_data"
+	keyLowDataSchedule      = "general.low_data_schedule"
+	keyNSFWMode             = "general.nsfw_mode"
+	keyNSFWKeywords         = "general.nsfw_keywords"
+	keyNSFWVisionCheck      = "general.nsfw_vision_check"
+	keyCookieHosts          = "general.cookie_hosts"
+	keyTLSFingerprints      = "general.tls_fingerprints"
+	keyIconSources          = "general.icon_sources"
+	keyRetentionDays        = "general.retention_days"
+	keyRetentionMaxPerFeed  = "general.retention_max_per_feed"
+	keyFeedDisableThreshold = "general.feed_disable_threshold"
+	keyCategoryFolders      = "general.category_folders"
+	keyDuplicateTitles      = "general.duplicate_titles"
+	keyDuplicateTitleWindow = "general.duplicate_title_window"
+	keyEmbedHosts           = "general.embed_hosts"
+	keyFutureDates          = "general.future_dates"
+	keyContentRefetchWindow = "general.content_refetch_window"
+	keyBackupInterval       = "general.backup_interval"
+	keyBackupKeep           = "general.backup_keep"
+	keyAppearanceTheme      = "appearance.theme"
+	keyAppearanceAccent     = "appearance.accent_color"
+	keyAppearanceFontSize   = "appearance.font_size"
+	keyAppearanceViewPrefix = "appearance.view."
 )
 
 // SettingsService provides settings management.
@@ -60,8 +164,19 @@ type SettingsService interface {
 	GetGeneralSettings(ctx context.Context) (*GeneralSettings, error)
 	// SetGeneralSettings updates the general settings.
 	SetGeneralSettings(ctx context.Context, settings *GeneralSettings) error
+	// GetAppearanceSettings returns the appearance settings, with the view
+	// of every content type.
+	GetAppearanceSettings(ctx context.Context) (*AppearanceSettings, error)
+	// SetAppearanceSettings updates the appearance settings. Content types
+	// missing from DefaultViews keep their view.
+	SetAppearanceSettings(ctx context.Context, settings *AppearanceSettings) error
 	// GetFallbackUserAgent returns the fallback user agent if set.
 	GetFallbackUserAgent(ctx context.Context) string
+	// SetLowData switches low-data mode on or off, leaving its schedule alone.
+	SetLowData(ctx context.Context, enabled bool) error
+	// LowDataActive reports whether low-data mode is on, by switch or by
+	// schedule. Background work that costs bandwidth should hold off while it is.
+	LowDataActive(ctx context.Context) bool
 }
 
 type settingsService struct {
@@ -283,7 +398,7 @@ func (s *settingsService) setAPIKey(ctx context.Context, key, value string) erro
 
 // GetGeneralSettings returns the general settings.
 func (s *settingsService) GetGeneralSettings(ctx context.Context) (*GeneralSettings, error) {
-	settings := &GeneralSettings{}
+	settings := &GeneralSettings{NSFWMode: NSFWShow}
 
 	if val, err := s.getString(ctx, keyFallbackUserAgent); err == nil {
 		settings.FallbackUserAgent = val
@@ -291,12 +406,122 @@ func (s *settingsService) GetGeneralSettings(ctx context.Context) (*GeneralSetti
 	if val, err := s.getString(ctx, keyAutoReadability); err == nil && val == "true" {
 		settings.AutoReadability = true
 	}
+	if val, err := s.getString(ctx, keyLowData); err == nil && val == "true" {
+		settings.LowData = true
+	}
+	if val, err := s.getString(ctx, keyLowDataSchedule); err == nil {
+		settings.LowDataSchedule = val
+	}
+	settings.LowDataActive = settings.LowData || inLowDataWindow(settings.LowDataSchedule, time.Now())
+	if val, err := s.getString(ctx, keyNSFWMode); err == nil && val != "" {
+		settings.NSFWMode = val
+	}
+	if val, err := s.getString(ctx, keyNSFWKeywords); err == nil {
+		settings.NSFWKeywords = val
+	}
+	if val, err := s.getString(ctx, keyNSFWVisionCheck); err == nil && val == "true" {
+		settings.NSFWVisionCheck = true
+	}
+	if val, err := s.getString(ctx, keyCookieHosts); err == nil {
+		settings.CookieHosts = val
+	}
+	if val, err := s.getString(ctx, keyTLSFingerprints); err == nil {
+		settings.TLSFingerprints = val
+	}
+	settings.IconSources = strings.Join(DefaultIconSources, ",")
+	if val, err := s.getString(ctx, keyIconSources); err == nil && val != "" {
+		settings.IconSources = val
+	}
+	if val, err := s.getInt(ctx, keyRetentionDays); err == nil && val > 0 {
+		settings.RetentionDays = val
+	}
+	if val, err := s.getInt(ctx, keyRetentionMaxPerFeed); err == nil && val > 0 {
+		settings.RetentionMaxPerFeed = val
+	}
+	settings.FeedDisableThreshold = DefaultFeedDisableThreshold
+	if val, err := s.getString(ctx, keyFeedDisableThreshold); err == nil && val != "" {
+		var threshold int
+		if _, err := fmt.Sscanf(val, "%d", &threshold); err == nil && threshold >= 0 {
+			settings.FeedDisableThreshold = threshold
+		}
+	}
+	if val, err := s.getString(ctx, keyCategoryFolders); err == nil && val == "true" {
+		settings.CategoryFolders = true
+	}
+	settings.DuplicateTitles = DuplicateTitlesOff
+	if val, err := s.getString(ctx, keyDuplicateTitles); err == nil && val != "" {
+		settings.DuplicateTitles = val
+	}
+	settings.DuplicateTitleWindow = DefaultDuplicateTitleWindow
+	if val, err := s.getInt(ctx, keyDuplicateTitleWindow); err == nil && val > 0 {
+		settings.DuplicateTitleWindow = val
+	}
+	settings.EmbedHosts = strings.Join(DefaultEmbedHosts, ",")
+	if setting, err := s.repo.Get(ctx, keyEmbedHosts); err == nil && setting != nil {
+		settings.EmbedHosts = setting.Value
+	}
+	settings.FutureDates = FutureDatesClamp
+	if val, err := s.getString(ctx, keyFutureDates); err == nil && val != "" {
+		settings.FutureDates = val
+	}
+	if val, err := s.getInt(ctx, keyContentRefetchWindow); err == nil && val > 0 {
+		settings.ContentRefetchWindow = val
+	}
+	if val, err := s.getInt(ctx, keyBackupInterval); err == nil && val > 0 {
+		settings.BackupInterval = val
+	}
+	settings.BackupKeep = DefaultBackupKeep
+	if val, err := s.getInt(ctx, keyBackupKeep); err == nil && val > 0 {
+		settings.BackupKeep = val
+	}
 
 	return settings, nil
 }
 
 // SetGeneralSettings updates the general settings.
 func (s *settingsService) SetGeneralSettings(ctx context.Context, settings *GeneralSettings) error {
+	if settings.LowDataSchedule != "" {
+		if _, _, err := ParseLowDataSchedule(settings.LowDataSchedule); err != nil {
+			return err
+		}
+	}
+	if _, err := ParseTLSFingerprints(settings.TLSFingerprints); err != nil {
+		return err
+	}
+	if _, err := ParseIconSources(settings.IconSources); err != nil {
+		return err
+	}
+	if _, err := ParseEmbedHosts(settings.EmbedHosts); err != nil {
+		return err
+	}
+	if settings.RetentionDays < 0 || settings.RetentionMaxPerFeed < 0 {
+		return fmt.Errorf("%w: retention must not be negative", ErrInvalid)
+	}
+	if settings.FeedDisableThreshold < 0 {
+		return fmt.Errorf("%w: feed disable threshold must not be negative", ErrInvalid)
+	}
+	switch settings.DuplicateTitles {
+	case "", DuplicateTitlesOff, DuplicateTitlesExact, DuplicateTitlesFuzzy:
+	default:
+		return fmt.Errorf("%w: unknown duplicate titles mode %q", ErrInvalid, settings.DuplicateTitles)
+	}
+	if settings.DuplicateTitleWindow < 0 || settings.DuplicateTitleWindow > MaxDuplicateTitleWindow {
+		return fmt.Errorf("%w: duplicate title window must be at most %d hours", ErrInvalid, MaxDuplicateTitleWindow)
+	}
+	switch settings.FutureDates {
+	case "", FutureDatesKeep, FutureDatesClamp, FutureDatesHide:
+	default:
+		return fmt.Errorf("%w: unknown future dates mode %q", ErrInvalid, settings.FutureDates)
+	}
+	if settings.ContentRefetchWindow < 0 || settings.ContentRefetchWindow > MaxContentRefetchWindow {
+		return fmt.Errorf("%w: content refetch window must be at most %d hours", ErrInvalid, MaxContentRefetchWindow)
+	}
+	if settings.BackupInterval < 0 || settings.BackupInterval > MaxBackupInterval {
+		return fmt.Errorf("%w: backup interval must be at most %d hours", ErrInvalid, MaxBackupInterval)
+	}
+	if settings.BackupKeep < 0 || settings.BackupKeep > MaxBackupKeep {
+		return fmt.Errorf("%w: at most %d backups can be kept", ErrInvalid, MaxBackupKeep)
+	}
 	if err := s.repo.Set(ctx, keyFallbackUserAgent, settings.FallbackUserAgent); err != nil {
 		return fmt.Errorf("set fallback user agent: %w", err)
 	}
@@ -307,9 +532,168 @@ func (s *settingsService) SetGeneralSettings(ctx context.Context, settings *Gene
 	if err := s.repo.Set(ctx, keyAutoReadability, autoReadabilityVal); err != nil {
 		return fmt.Errorf("set auto readability: %w", err)
 	}
+	if err := s.repo.Set(ctx, keyLowDataSchedule, settings.LowDataSchedule); err != nil {
+		return fmt.Errorf("set low-data schedule: %w", err)
+	}
+	if settings.NSFWMode != "" {
+		if err := s.repo.Set(ctx, keyNSFWMode, settings.NSFWMode); err != nil {
+			return fmt.Errorf("set nsfw mode: %w", err)
+		}
+	}
+	if err := s.repo.Set(ctx, keyNSFWKeywords, settings.NSFWKeywords); err != nil {
+		return fmt.Errorf("set nsfw keywords: %w", err)
+	}
+	visionCheckVal := "false"
+	if settings.NSFWVisionCheck {
+		visionCheckVal = "true"
+	}
+	if err := s.repo.Set(ctx, keyNSFWVisionCheck, visionCheckVal); err != nil {
+		return fmt.Errorf("set nsfw vision check: %w", err)
+	}
+	if err := s.setCookieHosts(ctx, settings.CookieHosts); err != nil {
+		return err
+	}
+	if err := s.repo.Set(ctx, keyTLSFingerprints, settings.TLSFingerprints); err != nil {
+		return fmt.Errorf("set tls fingerprints: %w", err)
+	}
+	if err := s.repo.Set(ctx, keyIconSources, settings.IconSources); err != nil {
+		return fmt.Errorf("set icon sources: %w", err)
+	}
+	if err := s.repo.Set(ctx, keyRetentionDays, fmt.Sprintf("%d", settings.RetentionDays)); err != nil {
+		return fmt.Errorf("set retention days: %w", err)
+	}
+	if err := s.repo.Set(ctx, keyRetentionMaxPerFeed, fmt.Sprintf("%d", settings.RetentionMaxPerFeed)); err != nil {
+		return fmt.Errorf("set retention max per feed: %w", err)
+	}
+	if err := s.repo.Set(ctx, keyFeedDisableThreshold, fmt.Sprintf("%d", settings.FeedDisableThreshold)); err != nil {
+		return fmt.Errorf("set feed disable threshold: %w", err)
+	}
+	categoryFoldersVal := "false"
+	if settings.CategoryFolders {
+		categoryFoldersVal = "true"
+	}
+	if err := s.repo.Set(ctx, keyCategoryFolders, categoryFoldersVal); err != nil {
+		return fmt.Errorf("set category folders: %w", err)
+	}
+	if settings.DuplicateTitles != "" {
+		if err := s.repo.Set(ctx, keyDuplicateTitles, settings.DuplicateTitles); err != nil {
+			return fmt.Errorf("set duplicate titles: %w", err)
+		}
+	}
+	if settings.DuplicateTitleWindow > 0 {
+		if err := s.repo.Set(ctx, keyDuplicateTitleWindow, fmt.Sprintf("%d", settings.DuplicateTitleWindow)); err != nil {
+			return fmt.Errorf("set duplicate title window: %w", err)
+		}
+	}
+	if err := s.repo.Set(ctx, keyEmbedHosts, settings.EmbedHosts); err != nil {
+		return fmt.Errorf("set embed hosts: %w", err)
+	}
+	if settings.FutureDates != "" {
+		if err := s.repo.Set(ctx, keyFutureDates, settings.FutureDates); err != nil {
+			return fmt.Errorf("set future dates: %w", err)
+		}
+	}
+	if err := s.repo.Set(ctx, keyContentRefetchWindow, fmt.Sprintf("%d", settings.ContentRefetchWindow)); err != nil {
+		return fmt.Errorf("set content refetch window: %w", err)
+	}
+	if err := s.repo.Set(ctx, keyBackupInterval, fmt.Sprintf("%d", settings.BackupInterval)); err != nil {
+		return fmt.Errorf("set backup interval: %w", err)
+	}
+	if settings.BackupKeep > 0 {
+		if err := s.repo.Set(ctx, keyBackupKeep, fmt.Sprintf("%d", settings.BackupKeep)); err != nil {
+			return fmt.Errorf("set backup keep: %w", err)
+		}
+	}
+	return s.SetLowData(ctx, settings.LowData)
+}
+
+// GetAppearanceSettings returns the appearance settings.
+func (s *settingsService) GetAppearanceSettings(ctx context.Context) (*AppearanceSettings, error) {
+	settings := &AppearanceSettings{
+		Theme:        ThemeAuto,
+		FontSize:     DefaultFontSize,
+		DefaultViews: make(map[string]string, len(defaultViews)),
+	}
+	if val, err := s.getString(ctx, keyAppearanceTheme); err == nil && val != "" {
+		settings.Theme = val
+	}
+	if val, err := s.getString(ctx, keyAppearanceAccent); err == nil {
+		settings.AccentColor = val
+	}
+	if val, err := s.getInt(ctx, keyAppearanceFontSize); err == nil && val > 0 {
+		settings.FontSize = val
+	}
+	for _, contentType := range ContentTypes {
+		settings.DefaultViews[contentType] = defaultViews[contentType]
+		if val, err := s.getString(ctx, keyAppearanceViewPrefix+contentType); err == nil && val != "" {
+			settings.DefaultViews[contentType] = val
+		}
+	}
+	return settings, nil
+}
+
+// SetAppearanceSettings updates the appearance settings.
+func (s *settingsService) SetAppearanceSettings(ctx context.Context, settings *AppearanceSettings) error {
+	if err := validateAppearance(settings); err != nil {
+		return err
+	}
+	if err := s.repo.Set(ctx, keyAppearanceTheme, settings.Theme); err != nil {
+		return fmt.Errorf("set theme: %w", err)
+	}
+	if err := s.repo.Set(ctx, keyAppearanceAccent, settings.AccentColor); err != nil {
+		return fmt.Errorf("set accent color: %w", err)
+	}
+	if err := s.repo.Set(ctx, keyAppearanceFontSize, fmt.Sprintf("%d", settings.FontSize)); err != nil {
+		return fmt.Errorf("set font size: %w", err)
+	}
+	for contentType, view := range settings.DefaultViews {
+		if err := s.repo.Set(ctx, keyAppearanceViewPrefix+contentType, view); err != nil {
+			return fmt.Errorf("set %s view: %w", contentType, err)
+		}
+	}
+	return nil
+}
+
+// setCookieHosts stores the cookie allowlist and forgets the stored cookies
+// of hosts no longer on it.
+func (s *settingsService) setCookieHosts(ctx context.Context, raw string) error {
+	if err := s.repo.Set(ctx, keyCookieHosts, raw); err != nil {
+		return fmt.Errorf("set cookie hosts: %w", err)
+	}
+	stored, err := s.repo.GetByPrefix(ctx, cookieKeyPrefix)
+	if err != nil {
+		return fmt.Errorf("list stored cookies: %w", err)
+	}
+	hosts := parseCookieHosts(raw)
+	for _, setting := range stored {
+		if !cookieHostAllowed(hosts, strings.TrimPrefix(setting.Key, cookieKeyPrefix)) {
+			if err := s.repo.Delete(ctx, setting.Key); err != nil {
+				return fmt.Errorf("delete stored cookies: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *settingsService) SetLowData(ctx context.Context, enabled bool) error {
+	val := "false"
+	if enabled {
+		val = "true"
+	}
+	if err := s.repo.Set(ctx, keyLowData, val); err != nil {
+		return fmt.Errorf("set low data: %w", err)
+	}
 	return nil
 }
 
+func (s *settingsService) LowDataActive(ctx context.Context) bool {
+	settings, err := s.GetGeneralSettings(ctx)
+	if err != nil {
+		return false
+	}
+	return settings.LowDataActive
+}
+
 // GetFallbackUserAgent returns the fallback user agent if set.
 // Returns empty string if disabled (user hasn't set one).
 func (s *settingsService) GetFallbackUserAgent(ctx context.Context) string {
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+)
+
+const (
+	// sitemapPagesPerRefresh bounds how many new pages of a sitemap feed are
+	// fetched and extracted per refresh; the rest follow on later refreshes.
+	sitemapPagesPerRefresh = 5
+	// sitemapMaxChildren bounds how many sitemaps of a sitemap index are read,
+	// newest first.
+	sitemapMaxChildren = 3
+	// sitemapScanDepth is how many of the newest pages are checked for new
+	// ones. Older pages are the site's backlog and are never fetched.
+	sitemapScanDepth = 100
+	// maxSitemapSize caps a decompressed sitemap; the protocol allows 50 MB.
+	maxSitemapSize = 50 << 20
+)
+
+// sitemap is a parsed sitemap.xml: either a list of pages or, for a sitemap
+// index, a list of further sitemaps.
+type sitemap struct {
+	Pages    []sitemapLink
+	Children []sitemapLink
+}
+
+type sitemapLink struct {
+	Loc     string
+	LastMod *time.Time
+}
+
+type sitemapXML struct {
+	XMLName xml.Name
+	URLs    []sitemapLinkXML `xml:"url"`
+	Maps    []sitemapLinkXML `xml:"sitemap"`
+}
+
+type sitemapLinkXML struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+// parseSitemap parses body as a sitemap or sitemap index, gzipped or not. ok
+// is false when body is not one, so callers can try it after a feed parser
+// gave up.
+func parseSitemap(body []byte) (sitemap, bool) {
+	var r io.Reader = bytes.NewReader(body)
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return sitemap{}, false
+		}
+		defer gz.Close()
+		r = io.LimitReader(gz, maxSitemapSize)
+	}
+	var doc sitemapXML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return sitemap{}, false
+	}
+	var links []sitemapLinkXML
+	var result sitemap
+	switch doc.XMLName.Local {
+	case "urlset":
+		links = doc.URLs
+	case "sitemapindex":
+		links = doc.Maps
+	default:
+		return sitemap{}, false
+	}
+
+	for _, link := range links {
+		loc := strings.TrimSpace(link.Loc)
+		if !isValidURL(loc) {
+			continue
+		}
+		parsed := sitemapLink{Loc: loc, LastMod: parseLastMod(link.LastMod)}
+		if doc.XMLName.Local == "urlset" {
+			result.Pages = append(result.Pages, parsed)
+		} else {
+			result.Children = append(result.Children, parsed)
+		}
+	}
+	return result, true
+}
+
+// lastModLayouts are the W3C datetime forms sitemaps use for lastmod.
+var lastModLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02",
+}
+
+func parseLastMod(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range lastModLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// newestFirst orders links by lastmod, newest first. Links without one keep
+// their order after the dated ones, latest listed first, since sitemaps
+// commonly append new pages.
+func newestFirst(links []sitemapLink) []sitemapLink {
+	sorted := make([]sitemapLink, len(links))
+	for i, link := range links {
+		sorted[len(links)-1-i] = link
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].LastMod, sorted[j].LastMod
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		return a.After(*b)
+	})
+	return sorted
+}
+
+// refreshSitemap adds the newest pages of a sitemap feed that are not stored
+// yet, extracting each through readability. Nested sitemap indexes are not
+// followed. header is the sitemap response's.
+func (s *refreshService) refreshSitemap(ctx context.Context, feed model.Feed, sm sitemap, header http.Header) error {
+	pages := sm.Pages
+	children := newestFirst(sm.Children)
+	if len(children) > sitemapMaxChildren {
+		children = children[:sitemapMaxChildren]
+	}
+	for _, child := range children {
+		childMap, err := s.fetchSitemap(ctx, child.Loc)
+		if err != nil {
+			log.Printf("feed %d (%s): sitemap %s: %v", feed.ID, feed.Title, child.Loc, err)
+			continue
+		}
+		pages = append(pages, childMap.Pages...)
+	}
+
+	pages = newestFirst(pages)
+	if len(pages) > sitemapScanDepth {
+		pages = pages[:sitemapScanDepth]
+	}
+	keywords := nsfwKeywords(ctx, s.settings)
+	rules := filterRules(ctx, s.filters, feed.ID)
+	dupes := duplicateTitles(ctx, s.settings, s.entries, feed.ID)
+	dates := loadFutureDates(ctx, s.settings).forFeed(feed)
+	newCount, collapsed := 0, 0
+	for _, page := range pages {
+		if newCount >= sitemapPagesPerRefresh || ctx.Err() != nil {
+			break
+		}
+		if _, err := s.entries.GetByURL(ctx, feed.ID, page.Loc); err == nil {
+			continue
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		entry := s.sitemapEntry(ctx, feed.ID, page)
+		markNSFW(&entry, &gofeed.Item{Title: *entry.Title}, "", keywords)
+		if rules.Apply(&entry) || !s.hooks.run(ctx, feed, &entry) {
+			continue
+		}
+		// Last, so content set by a rewrite or a hook is sanitized too
+		s.sanitizer.SanitizeEntry(ctx, &entry)
+		if dupes.collapse(*entry.Title) {
+			collapsed++
+			continue
+		}
+		if dates.hold(entry) {
+			continue
+		}
+		dates.clamp(&entry, nil)
+		setContentFormats(&entry)
+		if err := s.entries.CreateOrUpdate(ctx, entry); err != nil {
+			log.Printf("save entry: %v", err)
+			continue
+		}
+		newCount++
+	}
+
+	// Pages left for the next refresh must not be hidden behind a 304
+	if newCount >= sitemapPagesPerRefresh {
+		header = nil
+	}
+	s.recordFetch(ctx, &feed, header)
+
+	if collapsed > 0 {
+		log.Printf("feed %d (%s): %d sitemap pages collapsed as duplicate titles", feed.ID, feed.Title, collapsed)
+		s.metrics.ObserveCollapsed(feed.ID, feed.Title, collapsed)
+	}
+	countNewEntries(ctx, newCount)
+	if newCount > 0 {
+		log.Printf("feed %d (%s): %d new from sitemap", feed.ID, feed.Title, newCount)
+	}
+	return nil
+}
+
+// sitemapEntry builds the entry of a sitemap page from its readable content.
+// A page that cannot be extracted is kept as a link, so it is not retried on
+// every refresh.
+func (s *refreshService) sitemapEntry(ctx context.Context, feedID int64, link sitemapLink) model.Entry {
+	page := &ReadablePage{}
+	if s.readability != nil {
+		extracted, err := s.readability.ExtractPage(ctx, link.Loc)
+		if err != nil {
+			log.Printf("extract sitemap page %s: %v", link.Loc, err)
+		} else {
+			page = extracted
+		}
+	}
+
+	loc := link.Loc
+	title := page.Title
+	if title == "" {
+		title = loc
+	}
+	entry := model.Entry{FeedID: feedID, Title: &title, URL: &loc}
+	switch {
+	case page.PublishedAt != nil:
+		entry.PublishedAt = page.PublishedAt
+	case link.LastMod != nil:
+		entry.PublishedAt = link.LastMod
+	default:
+		now := time.Now()
+		entry.PublishedAt = &now
+	}
+	if page.Content != "" {
+		snippet := makeSnippet(page.Content)
+		entry.Content = &page.Content
+		entry.Snippet = &snippet
+	}
+	if page.Author != "" {
+		entry.Author = &page.Author
+	}
+	if page.ImageURL != "" {
+		entry.ThumbnailURL = &page.ImageURL
+	}
+	return entry
+}
+
+// fetchSitemap fetches and parses one sitemap of a sitemap index.
+func (s *refreshService) fetchSitemap(ctx context.Context, sitemapURL string) (sitemap, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sitemapURL, nil)
+	if err != nil {
+		return sitemap{}, err
+	}
+	req.Header.Set("User-Agent", config.DefaultUserAgent)
+	applyRequestOverrides(req, requestOverridesFrom(ctx))
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return sitemap{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return sitemap{}, &httpStatusError{status: resp.StatusCode}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSitemapSize))
+	if err != nil {
+		return sitemap{}, err
+	}
+	sm, ok := parseSitemap(body)
+	if !ok {
+		return sitemap{}, errors.New("not a sitemap")
+	}
+	return sm, nil
+}
+
+// sitemapFetch describes a sitemap subscribed to as a feed. It has no items
+// of its own: pages are extracted by the first refresh.
+func sitemapFetch(feedURL string, sm sitemap) feedFetch {
+	// No ETag or Last-Modified: the first refresh must not get a 304
+	fetched := feedFetch{sitemap: true}
+	if parsed, err := url.Parse(feedURL); err == nil {
+		fetched.title = parsed.Hostname()
+		fetched.siteURL = parsed.Scheme + "://" + parsed.Host
+	}
+	if len(sm.Pages) > 0 {
+		count := len(sm.Pages)
+		fetched.itemCount = &count
+	}
+	if newest := newestFirst(sm.Pages); len(newest) > 0 && newest[0].LastMod != nil {
+		fetched.lastUpdated = newest[0].LastMod.UTC().Format(time.RFC3339)
+	}
+	return fetched
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestParseSitemap(t *testing.T) {
+	urlset := `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc> https://example.com/a </loc><lastmod>2026-01-02</lastmod></url>
+  <url><loc>https://example.com/b</loc><lastmod>2026-01-03T10:00:00+02:00</lastmod></url>
+  <url><loc>mailto:me@example.com</loc></url>
+</urlset>`
+	sm, ok := parseSitemap([]byte(urlset))
+	if !ok || len(sm.Pages) != 2 || len(sm.Children) != 0 {
+		t.Fatalf("expected two pages, got %+v (ok %v)", sm, ok)
+	}
+	if sm.Pages[0].Loc != "https://example.com/a" || sm.Pages[0].LastMod == nil || sm.Pages[1].LastMod == nil {
+		t.Errorf("unexpected pages %+v", sm.Pages)
+	}
+
+	index := `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/posts.xml</loc></sitemap>
+</sitemapindex>`
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(index))
+	w.Close()
+	sm, ok = parseSitemap(gz.Bytes())
+	if !ok || len(sm.Children) != 1 || sm.Children[0].Loc != "https://example.com/posts.xml" {
+		t.Errorf("expected the gzipped index's sitemap, got %+v (ok %v)", sm, ok)
+	}
+
+	for _, body := range []string{`<rss version="2.0"><channel></channel></rss>`, `<html><body>hi</body></html>`, `not xml`} {
+		if _, ok := parseSitemap([]byte(body)); ok {
+			t.Errorf("expected %q not to parse as a sitemap", body)
+		}
+	}
+}
+
+func TestNewestFirst(t *testing.T) {
+	day := func(d int) *time.Time {
+		t := time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	links := []sitemapLink{
+		{Loc: "old", LastMod: day(1)},
+		{Loc: "undated-1"},
+		{Loc: "new", LastMod: day(5)},
+		{Loc: "undated-2"},
+	}
+	got := newestFirst(links)
+	want := []string{"new", "old", "undated-2", "undated-1"}
+	for i, link := range got {
+		if link.Loc != want[i] {
+			t.Fatalf("expected %v, got %+v", want, got)
+		}
+	}
+	if links[0].Loc != "old" {
+		t.Error("expected the input to be left as it is")
+	}
+}
+
+func TestRefreshService_RefreshSitemap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	extractor := &fakeExtractor{page: &ReadablePage{Title: "Read me", Content: "<p>Body</p>"}}
+	service := NewRefreshService(nil, mockEntries, nil, nil, nil, nil, nil, nil, nil, nil, nil, extractor, nil, nil, nil, nil, config.RefreshFixed).(*refreshService)
+	ctx := context.Background()
+
+	lastMod := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	var pages []sitemapLink
+	for i := 0; i < sitemapPagesPerRefresh+3; i++ {
+		pages = append(pages, sitemapLink{Loc: "https://example.com/p" + string(rune('a'+i)), LastMod: &lastMod})
+	}
+	// The newest listed page is stored already
+	stored := pages[len(pages)-1].Loc
+	mockEntries.EXPECT().GetByURL(ctx, int64(1), stored).Return(model.Entry{ID: 2}, nil)
+	mockEntries.EXPECT().GetByURL(ctx, int64(1), gomock.Any()).Return(model.Entry{}, sql.ErrNoRows).Times(sitemapPagesPerRefresh)
+	mockEntries.EXPECT().CreateOrUpdate(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		if *entry.URL == stored || *entry.Title != "Read me" || *entry.Snippet != "Body" || !entry.PublishedAt.Equal(lastMod) {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+		return nil
+	}).Times(sitemapPagesPerRefresh)
+
+	if err := service.refreshSitemap(ctx, model.Feed{ID: 1}, sitemap{Pages: pages}, nil); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+}
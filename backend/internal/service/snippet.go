@@ -0,0 +1,42 @@
+package service
+
+import (
+	"strings"
+
+	"gist/backend/internal/service/ai"
+)
+
+// snippetLength is the maximum length of an entry snippet, in characters.
+const snippetLength = 300
+
+// shortSnippetLength is the length ShortSnippet cuts snippets to, for compact
+// lists on small screens.
+const shortSnippetLength = 140
+
+// makeSnippet reduces HTML content to a single line of plain text for list previews.
+func makeSnippet(content string) string {
+	return clip(strings.Join(strings.Fields(ai.HTMLToText(content)), " "), snippetLength)
+}
+
+// ShortSnippet cuts a stored snippet down for compact lists.
+func ShortSnippet(snippet string) string {
+	return clip(snippet, shortSnippetLength)
+}
+
+// clip cuts text to at most n characters, marking the cut with an ellipsis.
+func clip(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
+
+// wordsPerMinute is the reading speed ReadingMinutes assumes.
+const wordsPerMinute = 200
+
+// ReadingMinutes estimates how long HTML content takes to read, rounded up.
+func ReadingMinutes(content string) int {
+	words := len(strings.Fields(ai.HTMLToText(content)))
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
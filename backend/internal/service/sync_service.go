@@ -0,0 +1,464 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/repository"
+)
+
+const (
+	// syncCursorKey stores the primary's change-log position a secondary has applied.
+	syncCursorKey = "sync.cursor"
+	// syncPendingKey stores the entry states a secondary had no entry for
+	// yet, retried on every pull.
+	syncPendingKey = "sync.pending"
+	// syncPendingMaxAge is how long a state waits for its entry before it is dropped.
+	syncPendingMaxAge = 7 * 24 * time.Hour
+	// syncPendingLimit caps the states waiting; the oldest are dropped first.
+	syncPendingLimit = 5000
+	// syncPageSize is how many entry changes a secondary requests at a time.
+	syncPageSize = 500
+	// MaxSyncPageSize caps the entry changes returned by one Changes call.
+	MaxSyncPageSize = 1000
+	syncTimeout     = 30 * time.Second
+)
+
+// SyncFolder is a folder on the primary, identified by its slash-separated path.
+type SyncFolder struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+}
+
+// SyncFeed is a subscription on the primary.
+type SyncFeed struct {
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Folder string `json:"folder,omitempty"`
+	Type   string `json:"type"`
+}
+
+// SyncEntryState is the current read and starred state of an entry.
+type SyncEntryState struct {
+	FeedURL string `json:"feedUrl"`
+	URL     string `json:"url"`
+	Read    bool   `json:"read"`
+	Starred bool   `json:"starred"`
+}
+
+// SyncChanges is one page of the primary's change feed: its full subscription
+// list plus the entry state changes after the requested cursor.
+type SyncChanges struct {
+	Cursor  int64            `json:"cursor"`
+	HasMore bool             `json:"hasMore"`
+	Folders []SyncFolder     `json:"folders"`
+	Feeds   []SyncFeed       `json:"feeds"`
+	Entries []SyncEntryState `json:"entries"`
+}
+
+// SyncResult reports what a pull changed on the secondary.
+type SyncResult struct {
+	FoldersCreated int   `json:"foldersCreated"`
+	FeedsCreated   int   `json:"feedsCreated"`
+	FeedsFailed    int   `json:"feedsFailed"`
+	EntriesUpdated int   `json:"entriesUpdated"`
+	EntriesMissing int   `json:"entriesMissing"` // not fetched on this instance yet; retried on later pulls
+	Cursor         int64 `json:"cursor"`
+}
+
+// pendingState is an entry state waiting for the secondary to fetch its entry.
+type pendingState struct {
+	SyncEntryState
+	Since time.Time `json:"since"`
+}
+
+// SyncPeer is the primary instance a secondary pulls from.
+type SyncPeer struct {
+	URL   string
+	Token string // the primary's API token
+}
+
+// SyncService mirrors one Gist instance onto another. The primary serves its
+// change feed; a secondary pulls it and applies it locally. Sync is one-way
+// and additive: the secondary never pushes its own changes back, and feeds
+// removed on the primary are kept.
+type SyncService interface {
+	// Changes returns the subscriptions and up to limit entry state changes after cursor.
+	Changes(ctx context.Context, cursor int64, limit int) (SyncChanges, error)
+	// Configured reports whether this instance has a primary to pull from.
+	Configured() bool
+	// Pull applies the primary's changes since the last pull.
+	Pull(ctx context.Context) (SyncResult, error)
+}
+
+type syncService struct {
+	folders       repository.FolderRepository
+	feeds         repository.FeedRepository
+	entries       repository.EntryRepository
+	settings      repository.SettingsRepository
+	folderService FolderService
+	feedService   FeedService
+	peer          SyncPeer
+	httpClient    *http.Client
+}
+
+func NewSyncService(
+	folders repository.FolderRepository,
+	feeds repository.FeedRepository,
+	entries repository.EntryRepository,
+	settings repository.SettingsRepository,
+	folderService FolderService,
+	feedService FeedService,
+	peer SyncPeer,
+) SyncService {
+	return &syncService{
+		folders:       folders,
+		feeds:         feeds,
+		entries:       entries,
+		settings:      settings,
+		folderService: folderService,
+		feedService:   feedService,
+		peer:          peer,
+		httpClient:    &http.Client{Timeout: syncTimeout},
+	}
+}
+
+func (s *syncService) Changes(ctx context.Context, cursor int64, limit int) (SyncChanges, error) {
+	if limit <= 0 || limit > MaxSyncPageSize {
+		limit = MaxSyncPageSize
+	}
+
+	folders, err := s.folders.List(ctx)
+	if err != nil {
+		return SyncChanges{}, fmt.Errorf("list folders: %w", err)
+	}
+	feeds, err := s.feeds.List(ctx, nil)
+	if err != nil {
+		return SyncChanges{}, fmt.Errorf("list feeds: %w", err)
+	}
+	// Fetch one extra change to tell whether there are more
+	changes, err := s.entries.ListStateChanges(ctx, cursor, limit+1)
+	if err != nil {
+		return SyncChanges{}, fmt.Errorf("list entry changes: %w", err)
+	}
+
+	result := SyncChanges{
+		Cursor:  cursor,
+		Folders: make([]SyncFolder, 0, len(folders)),
+		Feeds:   make([]SyncFeed, 0, len(feeds)),
+		Entries: make([]SyncEntryState, 0, len(changes)),
+	}
+
+	paths := buildFolderPaths(folders)
+	for _, folder := range folders {
+		result.Folders = append(result.Folders, SyncFolder{Path: paths[folder.ID], Type: folder.Type})
+	}
+	for _, feed := range feeds {
+		// Saved pages exist only here; a mirror has nothing to subscribe to
+		if feed.IsVirtual() {
+			continue
+		}
+		item := SyncFeed{URL: feed.URL, Title: feed.Title, Type: feed.Type}
+		if feed.FolderID != nil {
+			item.Folder = paths[*feed.FolderID]
+		}
+		result.Feeds = append(result.Feeds, item)
+	}
+
+	if len(changes) > limit {
+		changes = changes[:limit]
+		result.HasMore = true
+	}
+	for _, c := range changes {
+		result.Entries = append(result.Entries, SyncEntryState{FeedURL: c.FeedURL, URL: c.URL, Read: c.Read, Starred: c.Starred})
+		result.Cursor = c.Seq
+	}
+	return result, nil
+}
+
+func (s *syncService) Configured() bool {
+	return s.peer.URL != ""
+}
+
+func (s *syncService) Pull(ctx context.Context) (SyncResult, error) {
+	if !s.Configured() {
+		return SyncResult{}, fmt.Errorf("%w: no sync primary configured", ErrInvalid)
+	}
+
+	cursor, err := s.loadCursor(ctx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	pending, err := s.loadPending(ctx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	result := SyncResult{Cursor: cursor}
+
+	// States that found no entry before go first, so newer changes win
+	changed, err := s.retryPending(ctx, pending, &result)
+	if err != nil {
+		return result, err
+	}
+
+	for first := true; ; first = false {
+		page, err := s.fetchChanges(ctx, cursor)
+		if err != nil {
+			return result, err
+		}
+		// Subscriptions come with every page; mirror them once per pull
+		if first {
+			if err := s.mirrorSubscriptions(ctx, page, &result); err != nil {
+				return result, err
+			}
+		}
+
+		for _, state := range page.Entries {
+			found, err := s.entries.SetStateByURL(ctx, state.FeedURL, state.URL, state.Read, state.Starred)
+			if err != nil {
+				return result, fmt.Errorf("apply entry state: %w", err)
+			}
+			key := pendingKey(state)
+			if found {
+				result.EntriesUpdated++
+				if _, ok := pending[key]; ok {
+					delete(pending, key)
+					changed = true
+				}
+				continue
+			}
+			result.EntriesMissing++
+			since := time.Now()
+			if waiting, ok := pending[key]; ok {
+				since = waiting.Since
+			}
+			pending[key] = pendingState{SyncEntryState: state, Since: since}
+			changed = true
+		}
+
+		// Missing states are kept before the cursor moves past them
+		if changed {
+			if err := s.savePending(ctx, pending); err != nil {
+				return result, err
+			}
+			changed = false
+		}
+		cursor = page.Cursor
+		if err := s.settings.Set(ctx, syncCursorKey, strconv.FormatInt(cursor, 10)); err != nil {
+			return result, fmt.Errorf("save sync cursor: %w", err)
+		}
+		result.Cursor = cursor
+		if !page.HasMore {
+			return result, nil
+		}
+	}
+}
+
+// retryPending applies the waiting states whose entries have been fetched
+// since, and drops those that waited too long. It reports whether any state
+// left the queue.
+func (s *syncService) retryPending(ctx context.Context, pending map[string]pendingState, result *SyncResult) (bool, error) {
+	changed := false
+	cutoff := time.Now().Add(-syncPendingMaxAge)
+	for key, waiting := range pending {
+		if waiting.Since.Before(cutoff) {
+			delete(pending, key)
+			changed = true
+			continue
+		}
+		found, err := s.entries.SetStateByURL(ctx, waiting.FeedURL, waiting.URL, waiting.Read, waiting.Starred)
+		if err != nil {
+			return changed, fmt.Errorf("apply entry state: %w", err)
+		}
+		if found {
+			result.EntriesUpdated++
+			delete(pending, key)
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+func pendingKey(state SyncEntryState) string {
+	return state.FeedURL + "\n" + state.URL
+}
+
+func (s *syncService) loadPending(ctx context.Context) (map[string]pendingState, error) {
+	setting, err := s.settings.Get(ctx, syncPendingKey)
+	if err != nil {
+		return nil, fmt.Errorf("load pending sync states: %w", err)
+	}
+	pending := make(map[string]pendingState)
+	if setting == nil {
+		return pending, nil
+	}
+	var states []pendingState
+	if err := json.Unmarshal([]byte(setting.Value), &states); err != nil {
+		log.Printf("pending sync states: %v", err)
+		return pending, nil
+	}
+	for _, state := range states {
+		pending[pendingKey(state.SyncEntryState)] = state
+	}
+	return pending, nil
+}
+
+// savePending stores the waiting states, keeping the newest when there are
+// too many.
+func (s *syncService) savePending(ctx context.Context, pending map[string]pendingState) error {
+	if len(pending) == 0 {
+		if err := s.settings.Delete(ctx, syncPendingKey); err != nil {
+			return fmt.Errorf("save pending sync states: %w", err)
+		}
+		return nil
+	}
+	states := make([]pendingState, 0, len(pending))
+	for _, state := range pending {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool {
+		if !states[i].Since.Equal(states[j].Since) {
+			return states[i].Since.After(states[j].Since)
+		}
+		return pendingKey(states[i].SyncEntryState) < pendingKey(states[j].SyncEntryState)
+	})
+	if len(states) > syncPendingLimit {
+		for _, dropped := range states[syncPendingLimit:] {
+			delete(pending, pendingKey(dropped.SyncEntryState))
+		}
+		states = states[:syncPendingLimit]
+	}
+	data, err := json.Marshal(states)
+	if err != nil {
+		return fmt.Errorf("encode pending sync states: %w", err)
+	}
+	if err := s.settings.Set(ctx, syncPendingKey, string(data)); err != nil {
+		return fmt.Errorf("save pending sync states: %w", err)
+	}
+	return nil
+}
+
+func (s *syncService) loadCursor(ctx context.Context) (int64, error) {
+	setting, err := s.settings.Get(ctx, syncCursorKey)
+	if err != nil {
+		return 0, fmt.Errorf("load sync cursor: %w", err)
+	}
+	if setting == nil {
+		return 0, nil
+	}
+	cursor, err := strconv.ParseInt(setting.Value, 10, 64)
+	if err != nil {
+		return 0, nil // start over rather than stall on a corrupt cursor
+	}
+	return cursor, nil
+}
+
+func (s *syncService) fetchChanges(ctx context.Context, cursor int64) (SyncChanges, error) {
+	query := url.Values{}
+	query.Set("cursor", strconv.FormatInt(cursor, 10))
+	query.Set("limit", strconv.Itoa(syncPageSize))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.peer.URL+"/api/sync/changes?"+query.Encode(), nil)
+	if err != nil {
+		return SyncChanges{}, fmt.Errorf("build sync request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.peer.Token)
+	req.Header.Set("User-Agent", config.GistUserAgent)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return SyncChanges{}, fmt.Errorf("fetch changes from primary: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return SyncChanges{}, fmt.Errorf("fetch changes from primary: status %d", resp.StatusCode)
+	}
+	var page SyncChanges
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return SyncChanges{}, fmt.Errorf("decode changes from primary: %w", err)
+	}
+	return page, nil
+}
+
+// mirrorSubscriptions creates the primary's folders and feeds that are
+// missing here. New feeds are fetched right away so their entries exist
+// before entry states are applied.
+func (s *syncService) mirrorSubscriptions(ctx context.Context, page SyncChanges, result *SyncResult) error {
+	folders, err := s.folders.List(ctx)
+	if err != nil {
+		return fmt.Errorf("list folders: %w", err)
+	}
+	folderIDs := make(map[string]int64, len(folders))
+	for id, path := range buildFolderPaths(folders) {
+		folderIDs[path] = id
+	}
+
+	// Parents sort before their children
+	remote := append([]SyncFolder(nil), page.Folders...)
+	sort.Slice(remote, func(i, j int) bool { return remote[i].Path < remote[j].Path })
+	for _, folder := range remote {
+		if _, ok := folderIDs[folder.Path]; ok || folder.Path == "" {
+			continue
+		}
+		parentPath, name := "", folder.Path
+		if i := strings.LastIndex(folder.Path, "/"); i >= 0 {
+			parentPath, name = folder.Path[:i], folder.Path[i+1:]
+		}
+		var parentID *int64
+		if parentPath != "" {
+			id, ok := folderIDs[parentPath]
+			if !ok {
+				continue // parent could not be created
+			}
+			parentID = &id
+		}
+		created, err := s.folderService.Create(ctx, name, parentID, folder.Type)
+		if err != nil {
+			log.Printf("sync: create folder %s: %v", folder.Path, err)
+			continue
+		}
+		folderIDs[folder.Path] = created.ID
+		result.FoldersCreated++
+	}
+
+	for _, feed := range page.Feeds {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		existing, err := s.feeds.FindByURL(ctx, feed.URL)
+		if err != nil {
+			return fmt.Errorf("check feed url: %w", err)
+		}
+		if existing != nil {
+			continue
+		}
+		var folderID *int64
+		if id, ok := folderIDs[feed.Folder]; ok && feed.Folder != "" {
+			folderID = &id
+		}
+		if _, err := s.feedService.Add(ctx, feed.URL, folderID, feed.Title, feed.Type, nil, nil); err != nil {
+			log.Printf("sync: add feed %s: %v", feed.URL, err)
+			result.FeedsFailed++
+			continue
+		}
+		result.FeedsCreated++
+	}
+	return nil
+}
+
+// SyncTask runs Pull as a task.
+func SyncTask(sync SyncService) TaskFunc {
+	return func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		return sync.Pull(ctx)
+	}
+}
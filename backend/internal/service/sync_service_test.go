@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestSyncService_Changes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewSyncService(mockFolders, mockFeeds, mockEntries, nil, nil, nil, SyncPeer{})
+	ctx := context.Background()
+
+	parentID := int64(1)
+	mockFolders.EXPECT().List(ctx).Return([]model.Folder{
+		{ID: parentID, Name: "Tech", Type: "article"},
+		{ID: 2, Name: "Go", ParentID: &parentID, Type: "article"},
+	}, nil)
+	folderID := int64(2)
+	mockFeeds.EXPECT().List(ctx, (*int64)(nil)).Return([]model.Feed{
+		{ID: 10, Title: "Go Blog", URL: "https://go.dev/blog/feed.atom", FolderID: &folderID, Type: "article"},
+	}, nil)
+	mockEntries.EXPECT().ListStateChanges(ctx, int64(5), 3).Return([]model.EntryStateChange{
+		{Seq: 6, FeedURL: "https://go.dev/blog/feed.atom", URL: "https://go.dev/blog/a", Read: true},
+		{Seq: 9, FeedURL: "https://go.dev/blog/feed.atom", URL: "https://go.dev/blog/b", Starred: true},
+		{Seq: 12, FeedURL: "https://go.dev/blog/feed.atom", URL: "https://go.dev/blog/c", Read: true},
+	}, nil)
+
+	changes, err := service.Changes(ctx, 5, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !changes.HasMore || changes.Cursor != 9 || len(changes.Entries) != 2 {
+		t.Errorf("expected a full page ending at seq 9, got cursor=%d hasMore=%v entries=%d", changes.Cursor, changes.HasMore, len(changes.Entries))
+	}
+	if len(changes.Feeds) != 1 || changes.Feeds[0].Folder != "Tech/Go" {
+		t.Errorf("expected feed in Tech/Go, got %+v", changes.Feeds)
+	}
+}
+
+func TestSyncService_Pull(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	pages := map[string]SyncChanges{
+		"0": {Cursor: 2, HasMore: true, Entries: []SyncEntryState{
+			{FeedURL: "https://example.com/feed", URL: "https://example.com/a", Read: true},
+			{FeedURL: "https://example.com/feed", URL: "https://example.com/b", Starred: true},
+		}},
+		"2": {Cursor: 3, Entries: []SyncEntryState{
+			{FeedURL: "https://example.com/feed", URL: "https://example.com/c", Read: true},
+		}},
+	}
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/sync/changes" || r.Header.Get("Authorization") != "Bearer pairing-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		page, ok := pages[r.URL.Query().Get("cursor")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer primary.Close()
+
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockSettings := testutil.NewMockSettingsRepository(ctrl)
+	service := NewSyncService(mockFolders, mockFeeds, mockEntries, mockSettings, nil, nil, SyncPeer{URL: primary.URL, Token: "pairing-token"})
+	ctx := context.Background()
+
+	mockSettings.EXPECT().Get(ctx, syncCursorKey).Return(nil, nil)
+	mockSettings.EXPECT().Get(ctx, syncPendingKey).Return(nil, nil)
+	mockFolders.EXPECT().List(ctx).Return(nil, nil)
+	gomock.InOrder(
+		mockEntries.EXPECT().SetStateByURL(ctx, "https://example.com/feed", "https://example.com/a", true, false).Return(true, nil),
+		mockEntries.EXPECT().SetStateByURL(ctx, "https://example.com/feed", "https://example.com/b", false, true).Return(false, nil),
+		// The missing state is kept before the cursor passes it
+		mockSettings.EXPECT().Set(ctx, syncPendingKey, gomock.Any()).DoAndReturn(func(_ context.Context, _, value string) error {
+			var states []pendingState
+			if err := json.Unmarshal([]byte(value), &states); err != nil || len(states) != 1 || states[0].URL != "https://example.com/b" || !states[0].Starred {
+				t.Errorf("expected entry b pending, got %s", value)
+			}
+			return nil
+		}),
+		mockSettings.EXPECT().Set(ctx, syncCursorKey, "2").Return(nil),
+		mockEntries.EXPECT().SetStateByURL(ctx, "https://example.com/feed", "https://example.com/c", true, false).Return(true, nil),
+		mockSettings.EXPECT().Set(ctx, syncCursorKey, "3").Return(nil),
+	)
+
+	result, err := service.Pull(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := SyncResult{EntriesUpdated: 2, EntriesMissing: 1, Cursor: 3}
+	if result != want {
+		t.Errorf("expected %+v, got %+v", want, result)
+	}
+}
+
+func TestSyncService_PullRetriesMissing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(SyncChanges{Cursor: 7, Entries: []SyncEntryState{
+			{FeedURL: "https://example.com/feed", URL: "https://example.com/c", Read: true},
+		}})
+	}))
+	defer primary.Close()
+
+	mockFolders := testutil.NewMockFolderRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	mockSettings := testutil.NewMockSettingsRepository(ctrl)
+	service := NewSyncService(mockFolders, nil, mockEntries, mockSettings, nil, nil, SyncPeer{URL: primary.URL, Token: "pairing-token"})
+	ctx := context.Background()
+
+	waiting, _ := json.Marshal([]pendingState{
+		{SyncEntryState: SyncEntryState{FeedURL: "https://example.com/feed", URL: "https://example.com/b", Starred: true}, Since: time.Now().Add(-time.Hour)},
+		{SyncEntryState: SyncEntryState{FeedURL: "https://example.com/feed", URL: "https://example.com/gone", Read: true}, Since: time.Now().Add(-syncPendingMaxAge - time.Hour)},
+	})
+	mockSettings.EXPECT().Get(ctx, syncCursorKey).Return(&model.Setting{Key: syncCursorKey, Value: "5"}, nil)
+	mockSettings.EXPECT().Get(ctx, syncPendingKey).Return(&model.Setting{Key: syncPendingKey, Value: string(waiting)}, nil)
+	mockFolders.EXPECT().List(ctx).Return(nil, nil)
+	gomock.InOrder(
+		// Entry b has been fetched since; the expired state is dropped untried
+		mockEntries.EXPECT().SetStateByURL(ctx, "https://example.com/feed", "https://example.com/b", false, true).Return(true, nil),
+		mockEntries.EXPECT().SetStateByURL(ctx, "https://example.com/feed", "https://example.com/c", true, false).Return(true, nil),
+		mockSettings.EXPECT().Delete(ctx, syncPendingKey).Return(nil),
+		mockSettings.EXPECT().Set(ctx, syncCursorKey, "7").Return(nil),
+	)
+
+	result, err := service.Pull(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := SyncResult{EntriesUpdated: 2, Cursor: 7}
+	if result != want {
+		t.Errorf("expected %+v, got %+v", want, result)
+	}
+}
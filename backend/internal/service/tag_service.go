@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+// MaxTagNameLength bounds the length of a tag name, in characters.
+const MaxTagNameLength = 64
+
+// TagService manages user-defined tags and tags entries with them, to
+// organize saved entries more finely than starring.
+type TagService interface {
+	// List returns every tag with its entry count, by name.
+	List(ctx context.Context) ([]model.Tag, error)
+	// Create creates a tag. Returns ErrConflict when one of that name exists
+	// ignoring case.
+	Create(ctx context.Context, name string) (model.Tag, error)
+	Rename(ctx context.Context, id int64, name string) (model.Tag, error)
+	// Delete deletes a tag, untagging its entries.
+	Delete(ctx context.Context, id int64) error
+	// ListForEntry returns the tags of an entry, by name.
+	ListForEntry(ctx context.Context, entryID int64) ([]model.Tag, error)
+	// Assign tags an entry with the tag tagID or, when tagID is nil, with
+	// the tag named name, created if missing. Returns the entry's tags.
+	Assign(ctx context.Context, entryID int64, tagID *int64, name string) ([]model.Tag, error)
+	// Unassign removes a tag from an entry. Returns the entry's tags.
+	Unassign(ctx context.Context, entryID, tagID int64) ([]model.Tag, error)
+}
+
+type tagService struct {
+	tags    repository.TagRepository
+	entries repository.EntryRepository
+}
+
+func NewTagService(tags repository.TagRepository, entries repository.EntryRepository) TagService {
+	return &tagService{tags: tags, entries: entries}
+}
+
+func (s *tagService) List(ctx context.Context) ([]model.Tag, error) {
+	return s.tags.List(ctx)
+}
+
+func (s *tagService) Create(ctx context.Context, name string) (model.Tag, error) {
+	trimmed, err := normalizeTagName(name)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	if existing, err := s.tags.FindByName(ctx, trimmed); err != nil {
+		return model.Tag{}, fmt.Errorf("check tag name: %w", err)
+	} else if existing != nil {
+		return model.Tag{}, ErrConflict
+	}
+	return s.tags.Create(ctx, trimmed)
+}
+
+func (s *tagService) Rename(ctx context.Context, id int64, name string) (model.Tag, error) {
+	trimmed, err := normalizeTagName(name)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	if err := s.checkTag(ctx, id); err != nil {
+		return model.Tag{}, err
+	}
+	if existing, err := s.tags.FindByName(ctx, trimmed); err != nil {
+		return model.Tag{}, fmt.Errorf("check tag name: %w", err)
+	} else if existing != nil && existing.ID != id {
+		return model.Tag{}, ErrConflict
+	}
+	return s.tags.Rename(ctx, id, trimmed)
+}
+
+func (s *tagService) Delete(ctx context.Context, id int64) error {
+	if err := s.checkTag(ctx, id); err != nil {
+		return err
+	}
+	return s.tags.Delete(ctx, id)
+}
+
+func (s *tagService) ListForEntry(ctx context.Context, entryID int64) ([]model.Tag, error) {
+	if err := s.checkEntry(ctx, entryID); err != nil {
+		return nil, err
+	}
+	return s.tags.ListByEntry(ctx, entryID)
+}
+
+func (s *tagService) Assign(ctx context.Context, entryID int64, tagID *int64, name string) ([]model.Tag, error) {
+	if err := s.checkEntry(ctx, entryID); err != nil {
+		return nil, err
+	}
+
+	var id int64
+	if tagID != nil {
+		if err := s.checkTag(ctx, *tagID); err != nil {
+			return nil, err
+		}
+		id = *tagID
+	} else {
+		trimmed, err := normalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		existing, err := s.tags.FindByName(ctx, trimmed)
+		if err != nil {
+			return nil, fmt.Errorf("find tag: %w", err)
+		}
+		if existing == nil {
+			created, err := s.tags.Create(ctx, trimmed)
+			if err != nil {
+				return nil, err
+			}
+			existing = &created
+		}
+		id = existing.ID
+	}
+
+	if err := s.tags.Assign(ctx, entryID, id); err != nil {
+		return nil, err
+	}
+	return s.tags.ListByEntry(ctx, entryID)
+}
+
+func (s *tagService) Unassign(ctx context.Context, entryID, tagID int64) ([]model.Tag, error) {
+	if err := s.checkEntry(ctx, entryID); err != nil {
+		return nil, err
+	}
+	if err := s.tags.Unassign(ctx, entryID, tagID); err != nil {
+		return nil, err
+	}
+	return s.tags.ListByEntry(ctx, entryID)
+}
+
+func (s *tagService) checkEntry(ctx context.Context, id int64) error {
+	if _, err := s.entries.GetByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("check entry: %w", err)
+	}
+	return nil
+}
+
+func (s *tagService) checkTag(ctx context.Context, id int64) error {
+	if _, err := s.tags.GetByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("check tag: %w", err)
+	}
+	return nil
+}
+
+// normalizeTagName trims a tag name and checks it is 1 to MaxTagNameLength
+// characters.
+func normalizeTagName(name string) (string, error) {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" || utf8.RuneCountInString(trimmed) > MaxTagNameLength {
+		return "", ErrInvalid
+	}
+	return trimmed, nil
+}
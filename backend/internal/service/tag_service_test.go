@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestTagService_AssignByNameCreatesTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTags := testutil.NewMockTagRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewTagService(mockTags, mockEntries)
+	ctx := context.Background()
+
+	mockEntries.EXPECT().GetByID(ctx, int64(1)).Return(model.Entry{ID: 1}, nil)
+	mockTags.EXPECT().FindByName(ctx, "Research").Return(nil, nil)
+	mockTags.EXPECT().Create(ctx, "Research").Return(model.Tag{ID: 7, Name: "Research"}, nil)
+	mockTags.EXPECT().Assign(ctx, int64(1), int64(7)).Return(nil)
+	mockTags.EXPECT().ListByEntry(ctx, int64(1)).Return([]model.Tag{{ID: 7, Name: "Research"}}, nil)
+
+	tags, err := service.Assign(ctx, 1, nil, "  Research ")
+	if err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+	if len(tags) != 1 || tags[0].ID != 7 {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+}
+
+func TestTagService_AssignByNameReusesTag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTags := testutil.NewMockTagRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewTagService(mockTags, mockEntries)
+	ctx := context.Background()
+
+	mockEntries.EXPECT().GetByID(ctx, int64(1)).Return(model.Entry{ID: 1}, nil)
+	mockTags.EXPECT().FindByName(ctx, "research").Return(&model.Tag{ID: 7, Name: "Research"}, nil)
+	mockTags.EXPECT().Assign(ctx, int64(1), int64(7)).Return(nil)
+	mockTags.EXPECT().ListByEntry(ctx, int64(1)).Return([]model.Tag{{ID: 7, Name: "Research"}}, nil)
+
+	if _, err := service.Assign(ctx, 1, nil, "research"); err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+}
+
+func TestTagService_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTags := testutil.NewMockTagRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewTagService(mockTags, mockEntries)
+	ctx := context.Background()
+
+	mockEntries.EXPECT().GetByID(ctx, int64(1)).Return(model.Entry{}, sql.ErrNoRows)
+	if _, err := service.Assign(ctx, 1, nil, "Research"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown entry: got %v, want ErrNotFound", err)
+	}
+
+	tagID := int64(9)
+	mockEntries.EXPECT().GetByID(ctx, int64(2)).Return(model.Entry{ID: 2}, nil)
+	mockTags.EXPECT().GetByID(ctx, tagID).Return(model.Tag{}, sql.ErrNoRows)
+	if _, err := service.Assign(ctx, 2, &tagID, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown tag: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestTagService_CreateAndRename(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockTags := testutil.NewMockTagRepository(ctrl)
+	service := NewTagService(mockTags, testutil.NewMockEntryRepository(ctrl))
+	ctx := context.Background()
+
+	for _, name := range []string{"", "   ", strings.Repeat("标", MaxTagNameLength+1)} {
+		if _, err := service.Create(ctx, name); !errors.Is(err, ErrInvalid) {
+			t.Errorf("create %q: got %v, want ErrInvalid", name, err)
+		}
+	}
+
+	mockTags.EXPECT().FindByName(ctx, "Research").Return(&model.Tag{ID: 7, Name: "research"}, nil)
+	if _, err := service.Create(ctx, "Research"); !errors.Is(err, ErrConflict) {
+		t.Errorf("create existing: got %v, want ErrConflict", err)
+	}
+
+	// Renaming a tag to a different case of its own name is allowed
+	mockTags.EXPECT().GetByID(ctx, int64(7)).Return(model.Tag{ID: 7, Name: "research"}, nil)
+	mockTags.EXPECT().FindByName(ctx, "Research").Return(&model.Tag{ID: 7, Name: "research"}, nil)
+	mockTags.EXPECT().Rename(ctx, int64(7), "Research").Return(model.Tag{ID: 7, Name: "Research"}, nil)
+	if _, err := service.Rename(ctx, 7, "Research"); err != nil {
+		t.Errorf("rename: %v", err)
+	}
+
+	mockTags.EXPECT().GetByID(ctx, int64(8)).Return(model.Tag{ID: 8, Name: "later"}, nil)
+	mockTags.EXPECT().FindByName(ctx, "Research").Return(&model.Tag{ID: 7, Name: "Research"}, nil)
+	if _, err := service.Rename(ctx, 8, "Research"); !errors.Is(err, ErrConflict) {
+		t.Errorf("rename to existing: got %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gist/backend/internal/events"
+)
+
+// Task kinds
+const (
+	TaskImport          = "import"
+	TaskRefresh         = "refresh"
+	TaskIconBackfill    = "icon_backfill"
+	TaskSnippetBackfill = "snippet_backfill"
+	TaskSync            = "sync"
+	TaskLinkCheck       = "link_check"
+	TaskPrune           = "prune"
+	TaskFetchReadable   = "fetch_readable"
+	TaskBackup          = "backup"
+)
+
+// Task statuses
+const (
+	TaskRunning   = "running"
+	TaskDone      = "done"
+	TaskError     = "error"
+	TaskCancelled = "cancelled"
+)
+
+var ErrTaskRunning = errors.New("task already running")
+
+// Task is a snapshot of a long-running operation.
+type Task struct {
+	ID         string      `json:"id"`
+	Kind       string      `json:"kind"`
+	Status     string      `json:"status"`
+	Total      int         `json:"total"`
+	Current    int         `json:"current"`
+	Detail     string      `json:"detail,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	FinishedAt *time.Time  `json:"finishedAt,omitempty"`
+}
+
+// TaskHandle identifies a running task to its body and lets it report progress.
+type TaskHandle struct {
+	ID       string
+	progress func(current, total int, detail string)
+}
+
+// Progress publishes how far the task has got.
+func (h TaskHandle) Progress(current, total int, detail string) {
+	h.progress(current, total, detail)
+}
+
+// TaskFunc is the body of a task. It must stop promptly once ctx is done.
+type TaskFunc func(ctx context.Context, task TaskHandle) (interface{}, error)
+
+// TaskRunner runs long operations in the background on contexts it owns, so
+// they are neither tied to the request that started them nor left running
+// past shutdown. At most one task of each kind runs at a time, and the most
+// recent task of each kind is kept for status reporting; every change to a
+// task is also published as an events.TypeTask event.
+type TaskRunner interface {
+	// Start runs fn as a new task of kind. Returns ErrTaskRunning if one is already running.
+	Start(kind string, total int, fn TaskFunc) (Task, error)
+	// StartAs is Start under a given ID, to resume a task an earlier run of
+	// the server did not finish.
+	StartAs(id, kind string, total int, fn TaskFunc) (Task, error)
+	Get(id string) (Task, bool)
+	// Latest returns the most recent task of kind.
+	Latest(kind string) (Task, bool)
+	List() []Task
+	// Cancel stops a running task. Returns false if it is not running.
+	Cancel(id string) bool
+	// Shutdown cancels all running tasks and waits for them to return.
+	Shutdown(ctx context.Context) error
+}
+
+type trackedTask struct {
+	task   Task
+	cancel context.CancelFunc
+}
+
+type taskRunner struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	stop   context.CancelFunc
+	byKind map[string]*trackedTask
+	wg     sync.WaitGroup
+	hub    *events.Hub
+	leases *Leases
+	leased map[string]bool // kinds that take a lease to run
+}
+
+func NewTaskRunner(hub *events.Hub) TaskRunner {
+	ctx, stop := context.WithCancel(context.Background())
+	return &taskRunner{ctx: ctx, stop: stop, byKind: make(map[string]*trackedTask), hub: hub}
+}
+
+// NewLeasedTaskRunner creates a task runner whose tasks of kinds also take
+// a lease named "task:<kind>", so they run on one instance at a time when
+// several share the database. Start returns ErrTaskRunning while another
+// instance holds the lease, and a task that loses its lease is cancelled.
+func NewLeasedTaskRunner(hub *events.Hub, leases *Leases, kinds ...string) TaskRunner {
+	r := NewTaskRunner(hub).(*taskRunner)
+	r.leases = leases
+	r.leased = make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		r.leased[kind] = true
+	}
+	return r
+}
+
+func (r *taskRunner) Start(kind string, total int, fn TaskFunc) (Task, error) {
+	return r.StartAs(uuid.New().String(), kind, total, fn)
+}
+
+func (r *taskRunner) StartAs(id, kind string, total int, fn TaskFunc) (Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ctx.Err() != nil {
+		return Task{}, context.Canceled
+	}
+	if prev, ok := r.byKind[kind]; ok && prev.task.Status == TaskRunning {
+		return Task{}, ErrTaskRunning
+	}
+	if r.leased[kind] {
+		ok, err := r.leases.Acquire(r.ctx, taskLease(kind))
+		if err != nil {
+			return Task{}, err
+		}
+		if !ok {
+			return Task{}, fmt.Errorf("%w on another instance", ErrTaskRunning)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	t := &trackedTask{
+		task: Task{
+			ID:        id,
+			Kind:      kind,
+			Status:    TaskRunning,
+			Total:     total,
+			CreatedAt: time.Now(),
+		},
+		cancel: cancel,
+	}
+	r.byKind[kind] = t
+	r.hub.Publish(events.TypeTask, t.task)
+
+	r.wg.Add(1)
+	go r.run(ctx, t, fn)
+
+	return t.task, nil
+}
+
+func (r *taskRunner) run(ctx context.Context, t *trackedTask, fn TaskFunc) {
+	defer r.wg.Done()
+	defer t.cancel()
+
+	handle := TaskHandle{
+		ID: t.task.ID,
+		progress: func(current, total int, detail string) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if t.task.Status == TaskRunning {
+				t.task.Current = current
+				t.task.Total = total
+				t.task.Detail = detail
+				r.hub.Publish(events.TypeTask, t.task)
+			}
+		},
+	}
+
+	if r.leased[t.task.Kind] {
+		name := taskLease(t.task.Kind)
+		holdCtx, stopHold := context.WithCancel(ctx)
+		held := make(chan struct{})
+		go func() {
+			defer close(held)
+			r.leases.Hold(holdCtx, name, t.cancel)
+		}()
+		// Renewing has stopped before the lease is released
+		defer r.leases.Release(name)
+		defer func() { <-held }()
+		defer stopHold()
+	}
+
+	result, err := fn(ctx, handle)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t.task.Status != TaskRunning {
+		return // cancelled meanwhile
+	}
+	now := time.Now()
+	t.task.FinishedAt = &now
+	t.task.Detail = ""
+	switch {
+	case ctx.Err() != nil:
+		t.task.Status = TaskCancelled
+	case err != nil:
+		log.Printf("task %s (%s) failed: %v", t.task.ID, t.task.Kind, err)
+		t.task.Status = TaskError
+		t.task.Error = err.Error()
+	default:
+		t.task.Status = TaskDone
+		t.task.Result = result
+	}
+	r.hub.Publish(events.TypeTask, t.task)
+}
+
+func (r *taskRunner) Get(id string) (Task, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.byKind {
+		if t.task.ID == id {
+			return t.task, true
+		}
+	}
+	return Task{}, false
+}
+
+func (r *taskRunner) Latest(kind string) (Task, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.byKind[kind]
+	if !ok {
+		return Task{}, false
+	}
+	return t.task, true
+}
+
+func (r *taskRunner) List() []Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tasks := make([]Task, 0, len(r.byKind))
+	for _, t := range r.byKind {
+		tasks = append(tasks, t.task)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].CreatedAt.After(tasks[j].CreatedAt)
+	})
+	return tasks
+}
+
+func (r *taskRunner) Cancel(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.byKind {
+		if t.task.ID != id || t.task.Status != TaskRunning {
+			continue
+		}
+		t.cancel()
+		now := time.Now()
+		t.task.Status = TaskCancelled
+		t.task.Detail = ""
+		t.task.FinishedAt = &now
+		r.hub.Publish(events.TypeTask, t.task)
+		return true
+	}
+	return false
+}
+
+func (r *taskRunner) Shutdown(ctx context.Context) error {
+	r.stop()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func taskLease(kind string) string {
+	return "task:" + kind
+}
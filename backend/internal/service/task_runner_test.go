@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gist/backend/internal/events"
+)
+
+func waitForStatus(t *testing.T, runner TaskRunner, id string) Task {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if task, ok := runner.Get(id); ok && task.Status != TaskRunning {
+			return task
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("task %s did not finish", id)
+	return Task{}
+}
+
+func TestTaskRunner_CompletesWithResult(t *testing.T) {
+	runner := NewTaskRunner(nil)
+
+	task, err := runner.Start(TaskImport, 2, func(ctx context.Context, h TaskHandle) (interface{}, error) {
+		h.Progress(1, 2, "first")
+		return ImportResult{FeedsCreated: 2}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := waitForStatus(t, runner, task.ID)
+	if done.Status != TaskDone || done.FinishedAt == nil {
+		t.Fatalf("expected done task, got %+v", done)
+	}
+	if result, ok := done.Result.(ImportResult); !ok || result.FeedsCreated != 2 {
+		t.Errorf("unexpected result: %+v", done.Result)
+	}
+}
+
+func TestTaskRunner_PublishesTaskEvents(t *testing.T) {
+	hub := events.NewHub()
+	received, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+	runner := NewTaskRunner(hub)
+
+	task, err := runner.Start(TaskIconBackfill, 0, func(ctx context.Context, h TaskHandle) (interface{}, error) {
+		h.Progress(1, 3, "")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	waitForStatus(t, runner, task.ID)
+
+	var statuses []string
+	for i := 0; i < 3; i++ {
+		ev := <-received
+		snapshot, ok := ev.Data.(Task)
+		if ev.Type != events.TypeTask || !ok || snapshot.ID != task.ID {
+			t.Fatalf("unexpected event %+v", ev)
+		}
+		statuses = append(statuses, fmt.Sprintf("%s %d/%d", snapshot.Status, snapshot.Current, snapshot.Total))
+	}
+	if want := []string{"running 0/0", "running 1/3", "done 1/3"}; fmt.Sprint(statuses) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, statuses)
+	}
+}
+
+func TestTaskRunner_OneRunningTaskPerKind(t *testing.T) {
+	runner := NewTaskRunner(nil)
+	release := make(chan struct{})
+
+	first, err := runner.Start(TaskRefresh, 0, func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := runner.Start(TaskRefresh, 0, nil); !errors.Is(err, ErrTaskRunning) {
+		t.Errorf("expected ErrTaskRunning, got %v", err)
+	}
+	if _, err := runner.Start(TaskIconBackfill, 0, func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		return nil, nil
+	}); err != nil {
+		t.Errorf("other kinds should still start: %v", err)
+	}
+
+	close(release)
+	waitForStatus(t, runner, first.ID)
+}
+
+func TestTaskRunner_Cancel(t *testing.T) {
+	runner := NewTaskRunner(nil)
+
+	task, err := runner.Start(TaskImport, 0, func(ctx context.Context, _ TaskHandle) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !runner.Cancel(task.ID) {
+		t.Fatal("expected running task to be cancelled")
+	}
+	if runner.Cancel(task.ID) {
+		t.Error("cancelling twice should report false")
+	}
+	if got := waitForStatus(t, runner, task.ID); got.Status != TaskCancelled {
+		t.Errorf("expected cancelled, got %s", got.Status)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := runner.Shutdown(ctx); err != nil {
+		t.Errorf("shutdown: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/bandwidth_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/bandwidth_repository.go -destination=internal/service/testutil/mock_bandwidth_repo.go -package=testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	model "gist/backend/internal/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBandwidthRepository is a mock of BandwidthRepository interface.
+type MockBandwidthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockBandwidthRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockBandwidthRepositoryMockRecorder is the mock recorder for MockBandwidthRepository.
+type MockBandwidthRepositoryMockRecorder struct {
+	mock *MockBandwidthRepository
+}
+
+// NewMockBandwidthRepository creates a new mock instance.
+func NewMockBandwidthRepository(ctrl *gomock.Controller) *MockBandwidthRepository {
+	mock := &MockBandwidthRepository{ctrl: ctrl}
+	mock.recorder = &MockBandwidthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBandwidthRepository) EXPECT() *MockBandwidthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockBandwidthRepository) Add(ctx context.Context, feedID int64, day string, bytes int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, feedID, day, bytes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockBandwidthRepositoryMockRecorder) Add(ctx, feedID, day, bytes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockBandwidthRepository)(nil).Add), ctx, feedID, day, bytes)
+}
+
+// DeleteBefore mocks base method.
+func (m *MockBandwidthRepository) DeleteBefore(ctx context.Context, day string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteBefore", ctx, day)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteBefore indicates an expected call of DeleteBefore.
+func (mr *MockBandwidthRepositoryMockRecorder) DeleteBefore(ctx, day any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBefore", reflect.TypeOf((*MockBandwidthRepository)(nil).DeleteBefore), ctx, day)
+}
+
+// ListSince mocks base method.
+func (m *MockBandwidthRepository) ListSince(ctx context.Context, day string) ([]model.FeedBandwidth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSince", ctx, day)
+	ret0, _ := ret[0].([]model.FeedBandwidth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSince indicates an expected call of ListSince.
+func (mr *MockBandwidthRepositoryMockRecorder) ListSince(ctx, day any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSince", reflect.TypeOf((*MockBandwidthRepository)(nil).ListSince), ctx, day)
+}
@@ -14,6 +14,7 @@ import (
 	model "gist/backend/internal/model"
 	repository "gist/backend/internal/repository"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -42,6 +43,66 @@ func (m *MockEntryRepository) EXPECT() *MockEntryRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ApplyBatch mocks base method.
+func (m *MockEntryRepository) ApplyBatch(ctx context.Context, ids []int64, op string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApplyBatch", ctx, ids, op)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApplyBatch indicates an expected call of ApplyBatch.
+func (mr *MockEntryRepositoryMockRecorder) ApplyBatch(ctx, ids, op any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApplyBatch", reflect.TypeOf((*MockEntryRepository)(nil).ApplyBatch), ctx, ids, op)
+}
+
+// Archive mocks base method.
+func (m *MockEntryRepository) Archive(ctx context.Context, filter repository.EntryListFilter, bucketLen int) ([]model.ArchivePeriod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", ctx, filter, bucketLen)
+	ret0, _ := ret[0].([]model.ArchivePeriod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockEntryRepositoryMockRecorder) Archive(ctx, filter, bucketLen any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockEntryRepository)(nil).Archive), ctx, filter, bucketLen)
+}
+
+// CountByFeed mocks base method.
+func (m *MockEntryRepository) CountByFeed(ctx context.Context, feedID int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByFeed", ctx, feedID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByFeed indicates an expected call of CountByFeed.
+func (mr *MockEntryRepositoryMockRecorder) CountByFeed(ctx, feedID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByFeed", reflect.TypeOf((*MockEntryRepository)(nil).CountByFeed), ctx, feedID)
+}
+
+// CountPublishedSince mocks base method.
+func (m *MockEntryRepository) CountPublishedSince(ctx context.Context, feedID int64, since time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPublishedSince", ctx, feedID, since)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPublishedSince indicates an expected call of CountPublishedSince.
+func (mr *MockEntryRepositoryMockRecorder) CountPublishedSince(ctx, feedID, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPublishedSince", reflect.TypeOf((*MockEntryRepository)(nil).CountPublishedSince), ctx, feedID, since)
+}
+
 // CreateOrUpdate mocks base method.
 func (m *MockEntryRepository) CreateOrUpdate(ctx context.Context, entry model.Entry) error {
 	m.ctrl.T.Helper()
@@ -56,6 +117,21 @@ func (mr *MockEntryRepositoryMockRecorder) CreateOrUpdate(ctx, entry any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrUpdate", reflect.TypeOf((*MockEntryRepository)(nil).CreateOrUpdate), ctx, entry)
 }
 
+// DeleteByFeed mocks base method.
+func (m *MockEntryRepository) DeleteByFeed(ctx context.Context, feedID int64, keepStarred bool) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByFeed", ctx, feedID, keepStarred)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByFeed indicates an expected call of DeleteByFeed.
+func (mr *MockEntryRepositoryMockRecorder) DeleteByFeed(ctx, feedID, keepStarred any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByFeed", reflect.TypeOf((*MockEntryRepository)(nil).DeleteByFeed), ctx, feedID, keepStarred)
+}
+
 // ExistsByURL mocks base method.
 func (m *MockEntryRepository) ExistsByURL(ctx context.Context, feedID int64, url string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -71,19 +147,19 @@ func (mr *MockEntryRepositoryMockRecorder) ExistsByURL(ctx, feedID, url any) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistsByURL", reflect.TypeOf((*MockEntryRepository)(nil).ExistsByURL), ctx, feedID, url)
 }
 
-// GetAllUnreadCounts mocks base method.
-func (m *MockEntryRepository) GetAllUnreadCounts(ctx context.Context) ([]repository.UnreadCount, error) {
+// FillContent mocks base method.
+func (m *MockEntryRepository) FillContent(ctx context.Context, entry model.Entry) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllUnreadCounts", ctx)
-	ret0, _ := ret[0].([]repository.UnreadCount)
+	ret := m.ctrl.Call(m, "FillContent", ctx, entry)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllUnreadCounts indicates an expected call of GetAllUnreadCounts.
-func (mr *MockEntryRepositoryMockRecorder) GetAllUnreadCounts(ctx any) *gomock.Call {
+// FillContent indicates an expected call of FillContent.
+func (mr *MockEntryRepositoryMockRecorder) FillContent(ctx, entry any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUnreadCounts", reflect.TypeOf((*MockEntryRepository)(nil).GetAllUnreadCounts), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FillContent", reflect.TypeOf((*MockEntryRepository)(nil).FillContent), ctx, entry)
 }
 
 // GetByID mocks base method.
@@ -101,6 +177,36 @@ func (mr *MockEntryRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockEntryRepository)(nil).GetByID), ctx, id)
 }
 
+// GetByTitlePublished mocks base method.
+func (m *MockEntryRepository) GetByTitlePublished(ctx context.Context, feedID int64, title string, publishedAt time.Time) (model.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByTitlePublished", ctx, feedID, title, publishedAt)
+	ret0, _ := ret[0].(model.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByTitlePublished indicates an expected call of GetByTitlePublished.
+func (mr *MockEntryRepositoryMockRecorder) GetByTitlePublished(ctx, feedID, title, publishedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTitlePublished", reflect.TypeOf((*MockEntryRepository)(nil).GetByTitlePublished), ctx, feedID, title, publishedAt)
+}
+
+// GetByURL mocks base method.
+func (m *MockEntryRepository) GetByURL(ctx context.Context, feedID int64, url string) (model.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByURL", ctx, feedID, url)
+	ret0, _ := ret[0].(model.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByURL indicates an expected call of GetByURL.
+func (mr *MockEntryRepositoryMockRecorder) GetByURL(ctx, feedID, url any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByURL", reflect.TypeOf((*MockEntryRepository)(nil).GetByURL), ctx, feedID, url)
+}
+
 // GetStarredCount mocks base method.
 func (m *MockEntryRepository) GetStarredCount(ctx context.Context) (int, error) {
 	m.ctrl.T.Helper()
@@ -116,11 +222,41 @@ func (mr *MockEntryRepositoryMockRecorder) GetStarredCount(ctx any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStarredCount", reflect.TypeOf((*MockEntryRepository)(nil).GetStarredCount), ctx)
 }
 
+// GetStarredCounts mocks base method.
+func (m *MockEntryRepository) GetStarredCounts(ctx context.Context) ([]repository.StarredCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStarredCounts", ctx)
+	ret0, _ := ret[0].([]repository.StarredCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStarredCounts indicates an expected call of GetStarredCounts.
+func (mr *MockEntryRepositoryMockRecorder) GetStarredCounts(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStarredCounts", reflect.TypeOf((*MockEntryRepository)(nil).GetStarredCounts), ctx)
+}
+
+// GetUnreadCounts mocks base method.
+func (m *MockEntryRepository) GetUnreadCounts(ctx context.Context, filter repository.UnreadCountFilter) ([]repository.UnreadCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnreadCounts", ctx, filter)
+	ret0, _ := ret[0].([]repository.UnreadCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnreadCounts indicates an expected call of GetUnreadCounts.
+func (mr *MockEntryRepositoryMockRecorder) GetUnreadCounts(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnreadCounts", reflect.TypeOf((*MockEntryRepository)(nil).GetUnreadCounts), ctx, filter)
+}
+
 // List mocks base method.
-func (m *MockEntryRepository) List(ctx context.Context, filter repository.EntryListFilter) ([]model.Entry, error) {
+func (m *MockEntryRepository) List(ctx context.Context, filter repository.EntryListFilter) ([]model.EntrySummary, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "List", ctx, filter)
-	ret0, _ := ret[0].([]model.Entry)
+	ret0, _ := ret[0].([]model.EntrySummary)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -131,6 +267,111 @@ func (mr *MockEntryRepositoryMockRecorder) List(ctx, filter any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockEntryRepository)(nil).List), ctx, filter)
 }
 
+// ListMissingContent mocks base method.
+func (m *MockEntryRepository) ListMissingContent(ctx context.Context, feedID int64, since time.Time, limit int) ([]model.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMissingContent", ctx, feedID, since, limit)
+	ret0, _ := ret[0].([]model.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMissingContent indicates an expected call of ListMissingContent.
+func (mr *MockEntryRepositoryMockRecorder) ListMissingContent(ctx, feedID, since, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMissingContent", reflect.TypeOf((*MockEntryRepository)(nil).ListMissingContent), ctx, feedID, since, limit)
+}
+
+// ListStateChanges mocks base method.
+func (m *MockEntryRepository) ListStateChanges(ctx context.Context, afterSeq int64, limit int) ([]model.EntryStateChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListStateChanges", ctx, afterSeq, limit)
+	ret0, _ := ret[0].([]model.EntryStateChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListStateChanges indicates an expected call of ListStateChanges.
+func (mr *MockEntryRepositoryMockRecorder) ListStateChanges(ctx, afterSeq, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListStateChanges", reflect.TypeOf((*MockEntryRepository)(nil).ListStateChanges), ctx, afterSeq, limit)
+}
+
+// ListThumbnailSources mocks base method.
+func (m *MockEntryRepository) ListThumbnailSources(ctx context.Context, limit int) ([]model.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListThumbnailSources", ctx, limit)
+	ret0, _ := ret[0].([]model.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListThumbnailSources indicates an expected call of ListThumbnailSources.
+func (mr *MockEntryRepositoryMockRecorder) ListThumbnailSources(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListThumbnailSources", reflect.TypeOf((*MockEntryRepository)(nil).ListThumbnailSources), ctx, limit)
+}
+
+// ListTitlesSince mocks base method.
+func (m *MockEntryRepository) ListTitlesSince(ctx context.Context, feedID int64, since time.Time) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTitlesSince", ctx, feedID, since)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTitlesSince indicates an expected call of ListTitlesSince.
+func (mr *MockEntryRepositoryMockRecorder) ListTitlesSince(ctx, feedID, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTitlesSince", reflect.TypeOf((*MockEntryRepository)(nil).ListTitlesSince), ctx, feedID, since)
+}
+
+// ListUncheckedImages mocks base method.
+func (m *MockEntryRepository) ListUncheckedImages(ctx context.Context, limit int) ([]model.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUncheckedImages", ctx, limit)
+	ret0, _ := ret[0].([]model.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUncheckedImages indicates an expected call of ListUncheckedImages.
+func (mr *MockEntryRepositoryMockRecorder) ListUncheckedImages(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUncheckedImages", reflect.TypeOf((*MockEntryRepository)(nil).ListUncheckedImages), ctx, limit)
+}
+
+// ListWithoutReadable mocks base method.
+func (m *MockEntryRepository) ListWithoutReadable(ctx context.Context, feedID int64, unreadOnly bool) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWithoutReadable", ctx, feedID, unreadOnly)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWithoutReadable indicates an expected call of ListWithoutReadable.
+func (mr *MockEntryRepositoryMockRecorder) ListWithoutReadable(ctx, feedID, unreadOnly any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithoutReadable", reflect.TypeOf((*MockEntryRepository)(nil).ListWithoutReadable), ctx, feedID, unreadOnly)
+}
+
+// ListWithoutSnippet mocks base method.
+func (m *MockEntryRepository) ListWithoutSnippet(ctx context.Context, limit int) ([]model.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWithoutSnippet", ctx, limit)
+	ret0, _ := ret[0].([]model.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWithoutSnippet indicates an expected call of ListWithoutSnippet.
+func (mr *MockEntryRepositoryMockRecorder) ListWithoutSnippet(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithoutSnippet", reflect.TypeOf((*MockEntryRepository)(nil).ListWithoutSnippet), ctx, limit)
+}
+
 // MarkAllAsRead mocks base method.
 func (m *MockEntryRepository) MarkAllAsRead(ctx context.Context, feedID, folderID *int64, contentType *string) error {
 	m.ctrl.T.Helper()
@@ -145,32 +386,120 @@ func (mr *MockEntryRepositoryMockRecorder) MarkAllAsRead(ctx, feedID, folderID,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkAllAsRead", reflect.TypeOf((*MockEntryRepository)(nil).MarkAllAsRead), ctx, feedID, folderID, contentType)
 }
 
-// UpdateReadStatus mocks base method.
-func (m *MockEntryRepository) UpdateReadStatus(ctx context.Context, id int64, read bool) error {
+// PruneOld mocks base method.
+func (m *MockEntryRepository) PruneOld(ctx context.Context, before time.Time, keepPerFeed, limit int) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PruneOld", ctx, before, keepPerFeed, limit)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PruneOld indicates an expected call of PruneOld.
+func (mr *MockEntryRepositoryMockRecorder) PruneOld(ctx, before, keepPerFeed, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PruneOld", reflect.TypeOf((*MockEntryRepository)(nil).PruneOld), ctx, before, keepPerFeed, limit)
+}
+
+// SaveNSFWCheck mocks base method.
+func (m *MockEntryRepository) SaveNSFWCheck(ctx context.Context, entryID int64, flagged bool, checkedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveNSFWCheck", ctx, entryID, flagged, checkedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveNSFWCheck indicates an expected call of SaveNSFWCheck.
+func (mr *MockEntryRepositoryMockRecorder) SaveNSFWCheck(ctx, entryID, flagged, checkedAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveNSFWCheck", reflect.TypeOf((*MockEntryRepository)(nil).SaveNSFWCheck), ctx, entryID, flagged, checkedAt)
+}
+
+// SaveRevision mocks base method.
+func (m *MockEntryRepository) SaveRevision(ctx context.Context, entryID int64, rev model.EntryRevision) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateReadStatus", ctx, id, read)
+	ret := m.ctrl.Call(m, "SaveRevision", ctx, entryID, rev)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// SaveRevision indicates an expected call of SaveRevision.
+func (mr *MockEntryRepositoryMockRecorder) SaveRevision(ctx, entryID, rev any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRevision", reflect.TypeOf((*MockEntryRepository)(nil).SaveRevision), ctx, entryID, rev)
+}
+
+// Search mocks base method.
+func (m *MockEntryRepository) Search(ctx context.Context, filter repository.EntryListFilter) ([]model.EntrySummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, filter)
+	ret0, _ := ret[0].([]model.EntrySummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockEntryRepositoryMockRecorder) Search(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockEntryRepository)(nil).Search), ctx, filter)
+}
+
+// SetStateByURL mocks base method.
+func (m *MockEntryRepository) SetStateByURL(ctx context.Context, feedURL, url string, read, starred bool) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetStateByURL", ctx, feedURL, url, read, starred)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetStateByURL indicates an expected call of SetStateByURL.
+func (mr *MockEntryRepositoryMockRecorder) SetStateByURL(ctx, feedURL, url, read, starred any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetStateByURL", reflect.TypeOf((*MockEntryRepository)(nil).SetStateByURL), ctx, feedURL, url, read, starred)
+}
+
+// UpdateReadStatus mocks base method.
+func (m *MockEntryRepository) UpdateReadStatus(ctx context.Context, ids []int64, read bool) ([]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReadStatus", ctx, ids, read)
+	ret0, _ := ret[0].([]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // UpdateReadStatus indicates an expected call of UpdateReadStatus.
-func (mr *MockEntryRepositoryMockRecorder) UpdateReadStatus(ctx, id, read any) *gomock.Call {
+func (mr *MockEntryRepositoryMockRecorder) UpdateReadStatus(ctx, ids, read any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReadStatus", reflect.TypeOf((*MockEntryRepository)(nil).UpdateReadStatus), ctx, id, read)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReadStatus", reflect.TypeOf((*MockEntryRepository)(nil).UpdateReadStatus), ctx, ids, read)
 }
 
 // UpdateReadableContent mocks base method.
-func (m *MockEntryRepository) UpdateReadableContent(ctx context.Context, id int64, content string) error {
+func (m *MockEntryRepository) UpdateReadableContent(ctx context.Context, id int64, content string, meta model.ReadableMeta) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateReadableContent", ctx, id, content)
+	ret := m.ctrl.Call(m, "UpdateReadableContent", ctx, id, content, meta)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateReadableContent indicates an expected call of UpdateReadableContent.
-func (mr *MockEntryRepositoryMockRecorder) UpdateReadableContent(ctx, id, content any) *gomock.Call {
+func (mr *MockEntryRepositoryMockRecorder) UpdateReadableContent(ctx, id, content, meta any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReadableContent", reflect.TypeOf((*MockEntryRepository)(nil).UpdateReadableContent), ctx, id, content, meta)
+}
+
+// UpdateSnippet mocks base method.
+func (m *MockEntryRepository) UpdateSnippet(ctx context.Context, id int64, snippet string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSnippet", ctx, id, snippet)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSnippet indicates an expected call of UpdateSnippet.
+func (mr *MockEntryRepositoryMockRecorder) UpdateSnippet(ctx, id, snippet any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReadableContent", reflect.TypeOf((*MockEntryRepository)(nil).UpdateReadableContent), ctx, id, content)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSnippet", reflect.TypeOf((*MockEntryRepository)(nil).UpdateSnippet), ctx, id, snippet)
 }
 
 // UpdateStarredStatus mocks base method.
@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/feed_fetch_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/feed_fetch_repository.go -destination=internal/service/testutil/mock_feed_fetch_repo.go -package=testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	model "gist/backend/internal/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFeedFetchRepository is a mock of FeedFetchRepository interface.
+type MockFeedFetchRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFeedFetchRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockFeedFetchRepositoryMockRecorder is the mock recorder for MockFeedFetchRepository.
+type MockFeedFetchRepositoryMockRecorder struct {
+	mock *MockFeedFetchRepository
+}
+
+// NewMockFeedFetchRepository creates a new mock instance.
+func NewMockFeedFetchRepository(ctrl *gomock.Controller) *MockFeedFetchRepository {
+	mock := &MockFeedFetchRepository{ctrl: ctrl}
+	mock.recorder = &MockFeedFetchRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFeedFetchRepository) EXPECT() *MockFeedFetchRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockFeedFetchRepository) Add(ctx context.Context, fetch model.FeedFetch, keep int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, fetch, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockFeedFetchRepositoryMockRecorder) Add(ctx, fetch, keep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockFeedFetchRepository)(nil).Add), ctx, fetch, keep)
+}
+
+// ListAll mocks base method.
+func (m *MockFeedFetchRepository) ListAll(ctx context.Context) ([]model.FeedFetch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAll", ctx)
+	ret0, _ := ret[0].([]model.FeedFetch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAll indicates an expected call of ListAll.
+func (mr *MockFeedFetchRepositoryMockRecorder) ListAll(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAll", reflect.TypeOf((*MockFeedFetchRepository)(nil).ListAll), ctx)
+}
+
+// ListByFeed mocks base method.
+func (m *MockFeedFetchRepository) ListByFeed(ctx context.Context, feedID int64, limit int) ([]model.FeedFetch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByFeed", ctx, feedID, limit)
+	ret0, _ := ret[0].([]model.FeedFetch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByFeed indicates an expected call of ListByFeed.
+func (mr *MockFeedFetchRepositoryMockRecorder) ListByFeed(ctx, feedID, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByFeed", reflect.TypeOf((*MockFeedFetchRepository)(nil).ListByFeed), ctx, feedID, limit)
+}
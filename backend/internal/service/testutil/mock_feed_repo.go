@@ -13,6 +13,7 @@ import (
 	context "context"
 	model "gist/backend/internal/model"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -41,6 +42,20 @@ func (m *MockFeedRepository) EXPECT() *MockFeedRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ClearFailures mocks base method.
+func (m *MockFeedRepository) ClearFailures(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearFailures", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearFailures indicates an expected call of ClearFailures.
+func (mr *MockFeedRepositoryMockRecorder) ClearFailures(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearFailures", reflect.TypeOf((*MockFeedRepository)(nil).ClearFailures), ctx, id)
+}
+
 // Create mocks base method.
 func (m *MockFeedRepository) Create(ctx context.Context, feed model.Feed) (model.Feed, error) {
 	m.ctrl.T.Helper()
@@ -85,6 +100,20 @@ func (mr *MockFeedRepositoryMockRecorder) DeleteBatch(ctx, ids any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteBatch", reflect.TypeOf((*MockFeedRepository)(nil).DeleteBatch), ctx, ids)
 }
 
+// Disable mocks base method.
+func (m *MockFeedRepository) Disable(ctx context.Context, id int64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockFeedRepositoryMockRecorder) Disable(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockFeedRepository)(nil).Disable), ctx, id, at)
+}
+
 // FindByURL mocks base method.
 func (m *MockFeedRepository) FindByURL(ctx context.Context, url string) (*model.Feed, error) {
 	m.ctrl.T.Helper()
@@ -100,6 +129,21 @@ func (mr *MockFeedRepositoryMockRecorder) FindByURL(ctx, url any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByURL", reflect.TypeOf((*MockFeedRepository)(nil).FindByURL), ctx, url)
 }
 
+// GetAuth mocks base method.
+func (m *MockFeedRepository) GetAuth(ctx context.Context, id int64) (*string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuth", ctx, id)
+	ret0, _ := ret[0].(*string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuth indicates an expected call of GetAuth.
+func (mr *MockFeedRepositoryMockRecorder) GetAuth(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuth", reflect.TypeOf((*MockFeedRepository)(nil).GetAuth), ctx, id)
+}
+
 // GetByID mocks base method.
 func (m *MockFeedRepository) GetByID(ctx context.Context, id int64) (model.Feed, error) {
 	m.ctrl.T.Helper()
@@ -115,6 +159,21 @@ func (mr *MockFeedRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockFeedRepository)(nil).GetByID), ctx, id)
 }
 
+// IncrementFailures mocks base method.
+func (m *MockFeedRepository) IncrementFailures(ctx context.Context, id int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementFailures", ctx, id)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementFailures indicates an expected call of IncrementFailures.
+func (mr *MockFeedRepositoryMockRecorder) IncrementFailures(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementFailures", reflect.TypeOf((*MockFeedRepository)(nil).IncrementFailures), ctx, id)
+}
+
 // List mocks base method.
 func (m *MockFeedRepository) List(ctx context.Context, folderID *int64) ([]model.Feed, error) {
 	m.ctrl.T.Helper()
@@ -145,6 +204,34 @@ func (mr *MockFeedRepositoryMockRecorder) ListWithoutIcon(ctx any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWithoutIcon", reflect.TypeOf((*MockFeedRepository)(nil).ListWithoutIcon), ctx)
 }
 
+// ScheduleRefresh mocks base method.
+func (m *MockFeedRepository) ScheduleRefresh(ctx context.Context, id int64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleRefresh", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleRefresh indicates an expected call of ScheduleRefresh.
+func (mr *MockFeedRepositoryMockRecorder) ScheduleRefresh(ctx, id, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleRefresh", reflect.TypeOf((*MockFeedRepository)(nil).ScheduleRefresh), ctx, id, at)
+}
+
+// SetArchived mocks base method.
+func (m *MockFeedRepository) SetArchived(ctx context.Context, id int64, archived bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetArchived", ctx, id, archived)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetArchived indicates an expected call of SetArchived.
+func (mr *MockFeedRepositoryMockRecorder) SetArchived(ctx, id, archived any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetArchived", reflect.TypeOf((*MockFeedRepository)(nil).SetArchived), ctx, id, archived)
+}
+
 // Update mocks base method.
 func (m *MockFeedRepository) Update(ctx context.Context, feed model.Feed) (model.Feed, error) {
 	m.ctrl.T.Helper()
@@ -160,6 +247,34 @@ func (mr *MockFeedRepositoryMockRecorder) Update(ctx, feed any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFeedRepository)(nil).Update), ctx, feed)
 }
 
+// UpdateAuth mocks base method.
+func (m *MockFeedRepository) UpdateAuth(ctx context.Context, id int64, sealed *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAuth", ctx, id, sealed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAuth indicates an expected call of UpdateAuth.
+func (mr *MockFeedRepositoryMockRecorder) UpdateAuth(ctx, id, sealed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAuth", reflect.TypeOf((*MockFeedRepository)(nil).UpdateAuth), ctx, id, sealed)
+}
+
+// UpdateDateQuirks mocks base method.
+func (m *MockFeedRepository) UpdateDateQuirks(ctx context.Context, id int64, quirks *model.FeedDateQuirks) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDateQuirks", ctx, id, quirks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDateQuirks indicates an expected call of UpdateDateQuirks.
+func (mr *MockFeedRepositoryMockRecorder) UpdateDateQuirks(ctx, id, quirks any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDateQuirks", reflect.TypeOf((*MockFeedRepository)(nil).UpdateDateQuirks), ctx, id, quirks)
+}
+
 // UpdateErrorMessage mocks base method.
 func (m *MockFeedRepository) UpdateErrorMessage(ctx context.Context, id int64, errorMessage *string) error {
 	m.ctrl.T.Helper()
@@ -174,18 +289,102 @@ func (mr *MockFeedRepositoryMockRecorder) UpdateErrorMessage(ctx, id, errorMessa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateErrorMessage", reflect.TypeOf((*MockFeedRepository)(nil).UpdateErrorMessage), ctx, id, errorMessage)
 }
 
-// UpdateIconPath mocks base method.
-func (m *MockFeedRepository) UpdateIconPath(ctx context.Context, id int64, iconPath string) error {
+// UpdateFullTextURL mocks base method.
+func (m *MockFeedRepository) UpdateFullTextURL(ctx context.Context, id int64, fullTextURL *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFullTextURL", ctx, id, fullTextURL)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFullTextURL indicates an expected call of UpdateFullTextURL.
+func (mr *MockFeedRepositoryMockRecorder) UpdateFullTextURL(ctx, id, fullTextURL any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFullTextURL", reflect.TypeOf((*MockFeedRepository)(nil).UpdateFullTextURL), ctx, id, fullTextURL)
+}
+
+// UpdateIcon mocks base method.
+func (m *MockFeedRepository) UpdateIcon(ctx context.Context, id int64, iconPath string, iconColor *string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateIcon", ctx, id, iconPath, iconColor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateIcon indicates an expected call of UpdateIcon.
+func (mr *MockFeedRepositoryMockRecorder) UpdateIcon(ctx, id, iconPath, iconColor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIcon", reflect.TypeOf((*MockFeedRepository)(nil).UpdateIcon), ctx, id, iconPath, iconColor)
+}
+
+// UpdatePrefetchReadability mocks base method.
+func (m *MockFeedRepository) UpdatePrefetchReadability(ctx context.Context, id int64, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePrefetchReadability", ctx, id, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePrefetchReadability indicates an expected call of UpdatePrefetchReadability.
+func (mr *MockFeedRepositoryMockRecorder) UpdatePrefetchReadability(ctx, id, enabled any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrefetchReadability", reflect.TypeOf((*MockFeedRepository)(nil).UpdatePrefetchReadability), ctx, id, enabled)
+}
+
+// UpdateRefreshInterval mocks base method.
+func (m *MockFeedRepository) UpdateRefreshInterval(ctx context.Context, id int64, minutes *int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRefreshInterval", ctx, id, minutes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRefreshInterval indicates an expected call of UpdateRefreshInterval.
+func (mr *MockFeedRepositoryMockRecorder) UpdateRefreshInterval(ctx, id, minutes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRefreshInterval", reflect.TypeOf((*MockFeedRepository)(nil).UpdateRefreshInterval), ctx, id, minutes)
+}
+
+// UpdateRequestOverrides mocks base method.
+func (m *MockFeedRepository) UpdateRequestOverrides(ctx context.Context, id int64, overrides *model.FeedRequestOverrides) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRequestOverrides", ctx, id, overrides)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRequestOverrides indicates an expected call of UpdateRequestOverrides.
+func (mr *MockFeedRepositoryMockRecorder) UpdateRequestOverrides(ctx, id, overrides any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRequestOverrides", reflect.TypeOf((*MockFeedRepository)(nil).UpdateRequestOverrides), ctx, id, overrides)
+}
+
+// UpdateScraper mocks base method.
+func (m *MockFeedRepository) UpdateScraper(ctx context.Context, id int64, scraper *model.FeedScraper) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateScraper", ctx, id, scraper)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateScraper indicates an expected call of UpdateScraper.
+func (mr *MockFeedRepositoryMockRecorder) UpdateScraper(ctx, id, scraper any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateScraper", reflect.TypeOf((*MockFeedRepository)(nil).UpdateScraper), ctx, id, scraper)
+}
+
+// UpdateSummaryInSourceLanguage mocks base method.
+func (m *MockFeedRepository) UpdateSummaryInSourceLanguage(ctx context.Context, id int64, enabled bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateIconPath", ctx, id, iconPath)
+	ret := m.ctrl.Call(m, "UpdateSummaryInSourceLanguage", ctx, id, enabled)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateIconPath indicates an expected call of UpdateIconPath.
-func (mr *MockFeedRepositoryMockRecorder) UpdateIconPath(ctx, id, iconPath any) *gomock.Call {
+// UpdateSummaryInSourceLanguage indicates an expected call of UpdateSummaryInSourceLanguage.
+func (mr *MockFeedRepositoryMockRecorder) UpdateSummaryInSourceLanguage(ctx, id, enabled any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIconPath", reflect.TypeOf((*MockFeedRepository)(nil).UpdateIconPath), ctx, id, iconPath)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSummaryInSourceLanguage", reflect.TypeOf((*MockFeedRepository)(nil).UpdateSummaryInSourceLanguage), ctx, id, enabled)
 }
 
 // UpdateType mocks base method.
@@ -0,0 +1,131 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/filter_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/filter_repository.go -destination=internal/service/testutil/mock_filter_repo.go -package=testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	model "gist/backend/internal/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockFilterRepository is a mock of FilterRepository interface.
+type MockFilterRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFilterRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockFilterRepositoryMockRecorder is the mock recorder for MockFilterRepository.
+type MockFilterRepositoryMockRecorder struct {
+	mock *MockFilterRepository
+}
+
+// NewMockFilterRepository creates a new mock instance.
+func NewMockFilterRepository(ctrl *gomock.Controller) *MockFilterRepository {
+	mock := &MockFilterRepository{ctrl: ctrl}
+	mock.recorder = &MockFilterRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFilterRepository) EXPECT() *MockFilterRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockFilterRepository) Create(ctx context.Context, filter model.Filter) (model.Filter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, filter)
+	ret0, _ := ret[0].(model.Filter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFilterRepositoryMockRecorder) Create(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFilterRepository)(nil).Create), ctx, filter)
+}
+
+// Delete mocks base method.
+func (m *MockFilterRepository) Delete(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockFilterRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockFilterRepository)(nil).Delete), ctx, id)
+}
+
+// GetByID mocks base method.
+func (m *MockFilterRepository) GetByID(ctx context.Context, id int64) (model.Filter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByID", ctx, id)
+	ret0, _ := ret[0].(model.Filter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByID indicates an expected call of GetByID.
+func (mr *MockFilterRepositoryMockRecorder) GetByID(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockFilterRepository)(nil).GetByID), ctx, id)
+}
+
+// List mocks base method.
+func (m *MockFilterRepository) List(ctx context.Context) ([]model.Filter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]model.Filter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockFilterRepositoryMockRecorder) List(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFilterRepository)(nil).List), ctx)
+}
+
+// ListForFeed mocks base method.
+func (m *MockFilterRepository) ListForFeed(ctx context.Context, feedID int64) ([]model.Filter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListForFeed", ctx, feedID)
+	ret0, _ := ret[0].([]model.Filter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListForFeed indicates an expected call of ListForFeed.
+func (mr *MockFilterRepositoryMockRecorder) ListForFeed(ctx, feedID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListForFeed", reflect.TypeOf((*MockFilterRepository)(nil).ListForFeed), ctx, feedID)
+}
+
+// Update mocks base method.
+func (m *MockFilterRepository) Update(ctx context.Context, filter model.Filter) (model.Filter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, filter)
+	ret0, _ := ret[0].(model.Filter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockFilterRepositoryMockRecorder) Update(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFilterRepository)(nil).Update), ctx, filter)
+}
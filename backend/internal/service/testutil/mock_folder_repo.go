@@ -130,6 +130,20 @@ func (mr *MockFolderRepositoryMockRecorder) Update(ctx, id, name, parentID any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockFolderRepository)(nil).Update), ctx, id, name, parentID)
 }
 
+// UpdatePregenerateThumbnails mocks base method.
+func (m *MockFolderRepository) UpdatePregenerateThumbnails(ctx context.Context, id int64, enabled bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePregenerateThumbnails", ctx, id, enabled)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePregenerateThumbnails indicates an expected call of UpdatePregenerateThumbnails.
+func (mr *MockFolderRepositoryMockRecorder) UpdatePregenerateThumbnails(ctx, id, enabled any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePregenerateThumbnails", reflect.TypeOf((*MockFolderRepository)(nil).UpdatePregenerateThumbnails), ctx, id, enabled)
+}
+
 // UpdateType mocks base method.
 func (m *MockFolderRepository) UpdateType(ctx context.Context, id int64, folderType string) error {
 	m.ctrl.T.Helper()
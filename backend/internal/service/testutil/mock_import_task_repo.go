@@ -0,0 +1,129 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/import_task_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/import_task_repository.go -destination=internal/service/testutil/mock_import_task_repo.go -package=testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	model "gist/backend/internal/model"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockImportTaskRepository is a mock of ImportTaskRepository interface.
+type MockImportTaskRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockImportTaskRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockImportTaskRepositoryMockRecorder is the mock recorder for MockImportTaskRepository.
+type MockImportTaskRepositoryMockRecorder struct {
+	mock *MockImportTaskRepository
+}
+
+// NewMockImportTaskRepository creates a new mock instance.
+func NewMockImportTaskRepository(ctrl *gomock.Controller) *MockImportTaskRepository {
+	mock := &MockImportTaskRepository{ctrl: ctrl}
+	mock.recorder = &MockImportTaskRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImportTaskRepository) EXPECT() *MockImportTaskRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockImportTaskRepository) Create(ctx context.Context, task model.ImportTask) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, task)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockImportTaskRepositoryMockRecorder) Create(ctx, task any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockImportTaskRepository)(nil).Create), ctx, task)
+}
+
+// Delete mocks base method.
+func (m *MockImportTaskRepository) Delete(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockImportTaskRepositoryMockRecorder) Delete(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockImportTaskRepository)(nil).Delete), ctx, id)
+}
+
+// Finish mocks base method.
+func (m *MockImportTaskRepository) Finish(ctx context.Context, id, status string, processed int, result, errMsg string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish", ctx, id, status, processed, result, errMsg)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Finish indicates an expected call of Finish.
+func (mr *MockImportTaskRepositoryMockRecorder) Finish(ctx, id, status, processed, result, errMsg any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockImportTaskRepository)(nil).Finish), ctx, id, status, processed, result, errMsg)
+}
+
+// Get mocks base method.
+func (m *MockImportTaskRepository) Get(ctx context.Context, id string) (*model.ImportTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(*model.ImportTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockImportTaskRepositoryMockRecorder) Get(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockImportTaskRepository)(nil).Get), ctx, id)
+}
+
+// ListRunning mocks base method.
+func (m *MockImportTaskRepository) ListRunning(ctx context.Context) ([]model.ImportTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRunning", ctx)
+	ret0, _ := ret[0].([]model.ImportTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRunning indicates an expected call of ListRunning.
+func (mr *MockImportTaskRepositoryMockRecorder) ListRunning(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRunning", reflect.TypeOf((*MockImportTaskRepository)(nil).ListRunning), ctx)
+}
+
+// UpdateProgress mocks base method.
+func (m *MockImportTaskRepository) UpdateProgress(ctx context.Context, id string, processed int, result string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProgress", ctx, id, processed, result)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProgress indicates an expected call of UpdateProgress.
+func (mr *MockImportTaskRepositoryMockRecorder) UpdateProgress(ctx, id, processed, result any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProgress", reflect.TypeOf((*MockImportTaskRepository)(nil).UpdateProgress), ctx, id, processed, result)
+}
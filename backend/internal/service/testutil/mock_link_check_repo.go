@@ -0,0 +1,87 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/link_check_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/link_check_repository.go -destination=internal/service/testutil/mock_link_check_repo.go -package=testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	model "gist/backend/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockLinkCheckRepository is a mock of LinkCheckRepository interface.
+type MockLinkCheckRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinkCheckRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockLinkCheckRepositoryMockRecorder is the mock recorder for MockLinkCheckRepository.
+type MockLinkCheckRepositoryMockRecorder struct {
+	mock *MockLinkCheckRepository
+}
+
+// NewMockLinkCheckRepository creates a new mock instance.
+func NewMockLinkCheckRepository(ctrl *gomock.Controller) *MockLinkCheckRepository {
+	mock := &MockLinkCheckRepository{ctrl: ctrl}
+	mock.recorder = &MockLinkCheckRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinkCheckRepository) EXPECT() *MockLinkCheckRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListBroken mocks base method.
+func (m *MockLinkCheckRepository) ListBroken(ctx context.Context) ([]model.BrokenLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBroken", ctx)
+	ret0, _ := ret[0].([]model.BrokenLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBroken indicates an expected call of ListBroken.
+func (mr *MockLinkCheckRepositoryMockRecorder) ListBroken(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBroken", reflect.TypeOf((*MockLinkCheckRepository)(nil).ListBroken), ctx)
+}
+
+// ListDue mocks base method.
+func (m *MockLinkCheckRepository) ListDue(ctx context.Context, checkedBefore time.Time, limit int) ([]model.LinkToCheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDue", ctx, checkedBefore, limit)
+	ret0, _ := ret[0].([]model.LinkToCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDue indicates an expected call of ListDue.
+func (mr *MockLinkCheckRepositoryMockRecorder) ListDue(ctx, checkedBefore, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDue", reflect.TypeOf((*MockLinkCheckRepository)(nil).ListDue), ctx, checkedBefore, limit)
+}
+
+// Save mocks base method.
+func (m *MockLinkCheckRepository) Save(ctx context.Context, check model.LinkCheck) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, check)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockLinkCheckRepositoryMockRecorder) Save(ctx, check any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockLinkCheckRepository)(nil).Save), ctx, check)
+}
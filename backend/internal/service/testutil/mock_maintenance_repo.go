@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/maintenance_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/maintenance_repository.go -destination=internal/service/testutil/mock_maintenance_repo.go -package=testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockMaintenanceRepository is a mock of MaintenanceRepository interface.
+type MockMaintenanceRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockMaintenanceRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockMaintenanceRepositoryMockRecorder is the mock recorder for MockMaintenanceRepository.
+type MockMaintenanceRepositoryMockRecorder struct {
+	mock *MockMaintenanceRepository
+}
+
+// NewMockMaintenanceRepository creates a new mock instance.
+func NewMockMaintenanceRepository(ctrl *gomock.Controller) *MockMaintenanceRepository {
+	mock := &MockMaintenanceRepository{ctrl: ctrl}
+	mock.recorder = &MockMaintenanceRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMaintenanceRepository) EXPECT() *MockMaintenanceRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Restore mocks base method.
+func (m *MockMaintenanceRepository) Restore(ctx context.Context, path string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Restore", ctx, path)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Restore indicates an expected call of Restore.
+func (mr *MockMaintenanceRepositoryMockRecorder) Restore(ctx, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Restore", reflect.TypeOf((*MockMaintenanceRepository)(nil).Restore), ctx, path)
+}
+
+// Snapshot mocks base method.
+func (m *MockMaintenanceRepository) Snapshot(ctx context.Context, path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", ctx, path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockMaintenanceRepositoryMockRecorder) Snapshot(ctx, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockMaintenanceRepository)(nil).Snapshot), ctx, path)
+}
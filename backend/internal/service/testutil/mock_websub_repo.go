@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/websub_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/websub_repository.go -destination=internal/service/testutil/mock_websub_repo.go -package=testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	model "gist/backend/internal/model"
+	reflect "reflect"
+	time "time"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWebSubRepository is a mock of WebSubRepository interface.
+type MockWebSubRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebSubRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockWebSubRepositoryMockRecorder is the mock recorder for MockWebSubRepository.
+type MockWebSubRepositoryMockRecorder struct {
+	mock *MockWebSubRepository
+}
+
+// NewMockWebSubRepository creates a new mock instance.
+func NewMockWebSubRepository(ctrl *gomock.Controller) *MockWebSubRepository {
+	mock := &MockWebSubRepository{ctrl: ctrl}
+	mock.recorder = &MockWebSubRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebSubRepository) EXPECT() *MockWebSubRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockWebSubRepository) Get(ctx context.Context, feedID int64) (model.WebSubSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, feedID)
+	ret0, _ := ret[0].(model.WebSubSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWebSubRepositoryMockRecorder) Get(ctx, feedID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWebSubRepository)(nil).Get), ctx, feedID)
+}
+
+// Save mocks base method.
+func (m *MockWebSubRepository) Save(ctx context.Context, sub model.WebSubSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockWebSubRepositoryMockRecorder) Save(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockWebSubRepository)(nil).Save), ctx, sub)
+}
+
+// UpdateState mocks base method.
+func (m *MockWebSubRepository) UpdateState(ctx context.Context, feedID int64, state string, leaseExpiresAt *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateState", ctx, feedID, state, leaseExpiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateState indicates an expected call of UpdateState.
+func (mr *MockWebSubRepositoryMockRecorder) UpdateState(ctx, feedID, state, leaseExpiresAt any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateState", reflect.TypeOf((*MockWebSubRepository)(nil).UpdateState), ctx, feedID, state, leaseExpiresAt)
+}
@@ -0,0 +1,56 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/repository/wipe_repository.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/repository/wipe_repository.go -destination=internal/service/testutil/mock_wipe_repo.go -package=testutil
+//
+
+// Package testutil is a generated GoMock package.
+package testutil
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockWipeRepository is a mock of WipeRepository interface.
+type MockWipeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWipeRepositoryMockRecorder
+	isgomock struct{}
+}
+
+// MockWipeRepositoryMockRecorder is the mock recorder for MockWipeRepository.
+type MockWipeRepositoryMockRecorder struct {
+	mock *MockWipeRepository
+}
+
+// NewMockWipeRepository creates a new mock instance.
+func NewMockWipeRepository(ctrl *gomock.Controller) *MockWipeRepository {
+	mock := &MockWipeRepository{ctrl: ctrl}
+	mock.recorder = &MockWipeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWipeRepository) EXPECT() *MockWipeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// WipeAll mocks base method.
+func (m *MockWipeRepository) WipeAll(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WipeAll", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WipeAll indicates an expected call of WipeAll.
+func (mr *MockWipeRepositoryMockRecorder) WipeAll(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WipeAll", reflect.TypeOf((*MockWipeRepository)(nil).WipeAll), ctx)
+}
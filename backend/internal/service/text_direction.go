@@ -0,0 +1,80 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+
+	"golang.org/x/net/html"
+
+	"gist/backend/internal/model"
+)
+
+// rtlLanguages are the primary language subtags written right to left.
+var rtlLanguages = map[string]bool{
+	"ar": true, "ckb": true, "dv": true, "fa": true, "he": true, "iw": true,
+	"ks": true, "ps": true, "sd": true, "ug": true, "ur": true, "yi": true,
+}
+
+// rtlScripts are the script subtags written right to left, for tags such as
+// "az-Arab".
+var rtlScripts = map[string]bool{
+	"adlm": true, "arab": true, "hebr": true, "nkoo": true, "rohg": true,
+	"syrc": true, "thaa": true,
+}
+
+// textDirection is the direction of text a page declares with dir, or else
+// the one of its language. It is empty when neither tells.
+func textDirection(dir, lang string) string {
+	switch strings.ToLower(strings.TrimSpace(dir)) {
+	case model.DirectionRTL:
+		return model.DirectionRTL
+	case model.DirectionLTR:
+		return model.DirectionLTR
+	}
+	subtags := strings.FieldsFunc(strings.ToLower(lang), func(r rune) bool { return r == '-' || r == '_' })
+	if len(subtags) == 0 {
+		return ""
+	}
+	if rtlLanguages[subtags[0]] {
+		return model.DirectionRTL
+	}
+	for _, subtag := range subtags[1:] {
+		if rtlScripts[subtag] {
+			return model.DirectionRTL
+		}
+	}
+	return model.DirectionLTR
+}
+
+// declaredLanguage returns the lang and dir attributes a document declares:
+// those of <body>, falling back to <html>, or those of the first element of
+// a fragment such as a full-text service returns.
+func declaredLanguage(doc []byte) (lang, dir string) {
+	z := html.NewTokenizer(bytes.NewReader(doc))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return lang, dir
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch string(name) {
+			case "head", "meta", "title", "link", "base", "script", "style", "noscript":
+				continue
+			}
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				value := strings.TrimSpace(string(val))
+				switch {
+				case string(key) == "lang" && value != "":
+					lang = value
+				case string(key) == "dir" && value != "":
+					dir = value
+				}
+			}
+			if string(name) != "html" {
+				return lang, dir
+			}
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import "testing"
+
+func TestTextDirection(t *testing.T) {
+	cases := []struct{ dir, lang, want string }{
+		{"", "ar", "rtl"},
+		{"", "he-IL", "rtl"},
+		{"", "az-Arab", "rtl"},
+		{"", "fa_IR", "rtl"},
+		{"", "en-US", "ltr"},
+		{"ltr", "ar", "ltr"},
+		{"RTL", "", "rtl"},
+		{"auto", "", ""},
+		{"", "", ""},
+	}
+	for _, tc := range cases {
+		if got := textDirection(tc.dir, tc.lang); got != tc.want {
+			t.Errorf("textDirection(%q, %q) = %q, want %q", tc.dir, tc.lang, got, tc.want)
+		}
+	}
+}
+
+func TestDeclaredLanguage(t *testing.T) {
+	cases := []struct{ name, doc, lang, dir string }{
+		{"page", `<html lang="ar" dir="rtl"><head><meta lang="en"><title>t</title></head><body><p lang="en">x</p></body></html>`, "ar", "rtl"},
+		{"body overrides html", `<html lang="en"><body lang="he"><p>x</p></body></html>`, "he", ""},
+		{"fragment", `<div dir="rtl"><p lang="en">x</p></div>`, "", "rtl"},
+		{"undeclared", `<p>x</p><p lang="fa">y</p>`, "", ""},
+	}
+	for _, tc := range cases {
+		if lang, dir := declaredLanguage([]byte(tc.doc)); lang != tc.lang || dir != tc.dir {
+			t.Errorf("%s: got %q %q, want %q %q", tc.name, lang, dir, tc.lang, tc.dir)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"log"
+	"mime"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	_ "image/gif" // decode GIF dimensions; animated GIFs are cached as-is
+
+	"golang.org/x/sync/errgroup"
+
+	"gist/backend/internal/repository"
+)
+
+const (
+	// thumbnailWidth is the width /api/proxy/thumbnail scales images to.
+	thumbnailWidth = 600
+	// maxThumbnailPixels guards against decoding huge images into memory.
+	maxThumbnailPixels = 40_000_000
+	// thumbnailPregenerateLimit is how many recent images each run covers.
+	thumbnailPregenerateLimit = 200
+	// maxConcurrentThumbnails limits parallel image fetches while pre-generating.
+	maxConcurrentThumbnails = 4
+	thumbnailJPEGQuality    = 82
+)
+
+// ThumbnailWidths are the widths entry thumbnails come in, smallest first.
+var ThumbnailWidths = []int{320, 640}
+
+// DefaultThumbnailWidth is the entry thumbnail width when none is asked for.
+const DefaultThumbnailWidth = 320
+
+// ThumbnailService serves images scaled down for the picture grid, cached on
+// disk so repeat views and pre-generated images load without a fetch.
+type ThumbnailService interface {
+	// Get returns the thumbnail of an image, fetching it through the proxy
+	// and caching it on first use.
+	Get(ctx context.Context, imageURL, refererURL string) (*ProxyResult, error)
+	// ForEntry returns the thumbnail of an entry at one of ThumbnailWidths,
+	// generating it on first use. Returns ErrNotFound for a missing entry or
+	// one without a thumbnail.
+	ForEntry(ctx context.Context, entryID int64, width int) (*ProxyResult, error)
+	// Pregenerate caches the entry thumbnails, in every width, of recent
+	// entries in picture folders that enable it. Returns how many images
+	// were generated.
+	Pregenerate(ctx context.Context) (int, error)
+}
+
+type thumbnailService struct {
+	dir     string
+	proxy   ProxyService
+	entries repository.EntryRepository
+}
+
+// NewThumbnailService creates a thumbnail service caching under the media
+// directory.
+func NewThumbnailService(mediaDir string, proxy ProxyService, entries repository.EntryRepository) ThumbnailService {
+	return &thumbnailService{
+		dir:     filepath.Join(mediaDir, "thumbnails"),
+		proxy:   proxy,
+		entries: entries,
+	}
+}
+
+func (s *thumbnailService) Get(ctx context.Context, imageURL, refererURL string) (*ProxyResult, error) {
+	if cached, ok := s.cached(imageURL, thumbnailWidth); ok {
+		return cached, nil
+	}
+	return s.generate(ctx, imageURL, refererURL, thumbnailWidth)
+}
+
+func (s *thumbnailService) ForEntry(ctx context.Context, entryID int64, width int) (*ProxyResult, error) {
+	if !slices.Contains(ThumbnailWidths, width) {
+		return nil, fmt.Errorf("%w: thumbnail width must be one of %v", ErrInvalid, ThumbnailWidths)
+	}
+	entry, err := s.entries.GetByID(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if trimmedValue(entry.ThumbnailURL) == "" {
+		return nil, fmt.Errorf("%w: entry has no thumbnail", ErrNotFound)
+	}
+	imageURL := *entry.ThumbnailURL
+	if cached, ok := s.cached(imageURL, width); ok {
+		return cached, nil
+	}
+	return s.generate(ctx, imageURL, trimmedValue(entry.URL), width)
+}
+
+func (s *thumbnailService) Pregenerate(ctx context.Context) (int, error) {
+	sources, err := s.entries.ListThumbnailSources(ctx, thumbnailPregenerateLimit)
+	if err != nil {
+		return 0, fmt.Errorf("list thumbnail sources: %w", err)
+	}
+
+	generated := make([]bool, len(sources))
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentThumbnails)
+	for i, source := range sources {
+		i, imageURL, feedID := i, *source.ThumbnailURL, source.FeedID
+		widths := s.missingWidths(imageURL)
+		if len(widths) == 0 {
+			continue
+		}
+		referer := ""
+		if source.URL != nil {
+			referer = *source.URL
+		}
+		g.Go(func() error {
+			if _, err := s.generate(withBandwidthFeed(gctx, feedID), imageURL, referer, widths...); err != nil {
+				log.Printf("pre-generate thumbnail %s: %v", imageURL, err)
+				return nil
+			}
+			generated[i] = true
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, ok := range generated {
+		if ok {
+			count++
+		}
+	}
+	return count, ctx.Err()
+}
+
+// thumbnailKey names the cache files of an image.
+func thumbnailKey(imageURL string) string {
+	sum := sha256.Sum256([]byte(imageURL))
+	return hex.EncodeToString(sum[:])
+}
+
+// variantKey names the cache file of an image scaled to width. Images at
+// thumbnailWidth keep the name they had before there were other widths.
+func variantKey(imageURL string, width int) string {
+	if width == thumbnailWidth {
+		return thumbnailKey(imageURL)
+	}
+	return thumbnailKey(imageURL) + "-" + strconv.Itoa(width)
+}
+
+// missingWidths returns the entry thumbnail widths of an image not cached yet.
+func (s *thumbnailService) missingWidths(imageURL string) []int {
+	var missing []int
+	for _, width := range ThumbnailWidths {
+		if _, ok := s.cached(imageURL, width); !ok {
+			missing = append(missing, width)
+		}
+	}
+	return missing
+}
+
+func (s *thumbnailService) cached(imageURL string, width int) (*ProxyResult, bool) {
+	matches, _ := filepath.Glob(filepath.Join(s.dir, variantKey(imageURL, width)+".*"))
+	for _, path := range matches {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		contentType := mime.TypeByExtension(filepath.Ext(path))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		return &ProxyResult{Data: data, ContentType: contentType}, true
+	}
+	return nil, false
+}
+
+// generate fetches an image once, scales it down to each width if it is a
+// large JPEG or PNG and caches the results, returning the first. Other
+// formats, which the standard library cannot encode, are cached as fetched;
+// so is WebP, which it can neither decode nor encode.
+func (s *thumbnailService) generate(ctx context.Context, imageURL, refererURL string, widths ...int) (*ProxyResult, error) {
+	original, err := s.proxy.FetchImage(ctx, imageURL, refererURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var first *ProxyResult
+	for _, width := range widths {
+		result := original
+		if thumb, ok := scaleDown(original.Data, width); ok {
+			result = thumb
+		}
+		if err := s.store(imageURL, width, result); err != nil {
+			log.Printf("cache thumbnail %s: %v", imageURL, err)
+		}
+		if first == nil {
+			first = result
+		}
+	}
+	return first, nil
+}
+
+func (s *thumbnailService) store(imageURL string, width int, result *ProxyResult) error {
+	mediaType, _, _ := mime.ParseMediaType(result.ContentType)
+	if !strings.HasPrefix(mediaType, "image/") {
+		return fmt.Errorf("not an image: %q", result.ContentType)
+	}
+	ext := thumbnailExtensions[mediaType]
+	if ext == "" {
+		return fmt.Errorf("unsupported image type %q", mediaType)
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return err
+	}
+
+	// Write then rename so readers never see a partial file
+	tmp, err := os.CreateTemp(s.dir, ".thumb-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(result.Data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(s.dir, variantKey(imageURL, width)+ext))
+}
+
+// thumbnailExtensions maps cacheable image types to file extensions that
+// mime.TypeByExtension maps back.
+var thumbnailExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"image/avif": ".avif",
+}
+
+// scaleDown returns a JPEG or PNG image scaled to width, or false if it is
+// narrower already, too large to decode or in another format.
+func scaleDown(data []byte, width int) (*ProxyResult, bool) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, false
+	}
+	if cfg.Width <= width || cfg.Width*cfg.Height > maxThumbnailPixels {
+		return nil, false
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, false
+	}
+
+	dst := resizeToWidth(src, width)
+	var buf bytes.Buffer
+	contentType := "image/jpeg"
+	if opaque, ok := src.(interface{ Opaque() bool }); ok && !opaque.Opaque() {
+		// Keep transparency, which JPEG cannot hold
+		contentType = "image/png"
+		err = png.Encode(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailJPEGQuality})
+	}
+	if err != nil {
+		return nil, false
+	}
+	return &ProxyResult{Data: buf.Bytes(), ContentType: contentType}, true
+}
+
+// resizeToWidth scales src down to width, averaging the source pixels that
+// fall in each destination pixel.
+func resizeToWidth(src image.Image, width int) *image.NRGBA {
+	b := src.Bounds()
+	height := max(b.Dy()*width/b.Dx(), 1)
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		sy0 := b.Min.Y + y*b.Dy()/height
+		sy1 := max(b.Min.Y+(y+1)*b.Dy()/height, sy0+1)
+		for x := 0; x < width; x++ {
+			sx0 := b.Min.X + x*b.Dx()/width
+			sx1 := max(b.Min.X+(x+1)*b.Dx()/width, sx0+1)
+
+			var r, g, bl, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					bl += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			// Average premultiplied values, then convert back to straight alpha
+			c := color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n)}
+			dst.Set(x, y, c)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"sync"
+	"testing"
+
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+// fakeImageProxy serves fixed images by URL and counts fetches.
+type fakeImageProxy struct {
+	mu      sync.Mutex
+	images  map[string]*ProxyResult
+	fetches map[string]int
+}
+
+func (p *fakeImageProxy) FetchImage(_ context.Context, imageURL, _ string) (*ProxyResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fetches[imageURL]++
+	result, ok := p.images[imageURL]
+	if !ok {
+		return nil, ErrInvalidURL
+	}
+	return result, nil
+}
+
+func (p *fakeImageProxy) CachedImage(ctx context.Context, imageURL, refererURL string) (*ProxyResult, error) {
+	return p.FetchImage(ctx, imageURL, refererURL)
+}
+
+func (p *fakeImageProxy) Close() {}
+
+func encodePNG(t *testing.T, width, height int, fill color.Color) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestThumbnailService_Get(t *testing.T) {
+	large := "https://cdn.example.com/large.png"
+	small := "https://cdn.example.com/small.png"
+	smallData := encodePNG(t, 100, 50, color.NRGBA{R: 10, G: 20, B: 30, A: 255})
+	proxy := &fakeImageProxy{
+		images: map[string]*ProxyResult{
+			large: {Data: encodePNG(t, 1200, 800, color.NRGBA{R: 200, G: 100, B: 50, A: 255}), ContentType: "image/png"},
+			small: {Data: smallData, ContentType: "image/png"},
+		},
+		fetches: make(map[string]int),
+	}
+	service := NewThumbnailService(t.TempDir(), proxy, nil)
+	ctx := context.Background()
+
+	// A large opaque image is scaled down to a JPEG
+	thumb, err := service.Get(ctx, large, "")
+	if err != nil {
+		t.Fatalf("get thumbnail: %v", err)
+	}
+	if thumb.ContentType != "image/jpeg" {
+		t.Errorf("expected image/jpeg, got %s", thumb.ContentType)
+	}
+	img, err := jpeg.Decode(bytes.NewReader(thumb.Data))
+	if err != nil {
+		t.Fatalf("decode thumbnail: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != thumbnailWidth || b.Dy() != 400 {
+		t.Errorf("expected %dx400, got %dx%d", thumbnailWidth, b.Dx(), b.Dy())
+	}
+	r, g, bl, _ := img.At(10, 10).RGBA()
+	if r>>8 < 190 || g>>8 < 90 || g>>8 > 110 || bl>>8 > 60 {
+		t.Errorf("expected the fill color to survive scaling, got %d,%d,%d", r>>8, g>>8, bl>>8)
+	}
+
+	// The second request is served from the cache
+	cached, err := service.Get(ctx, large, "")
+	if err != nil {
+		t.Fatalf("get cached thumbnail: %v", err)
+	}
+	if proxy.fetches[large] != 1 {
+		t.Errorf("expected one fetch, got %d", proxy.fetches[large])
+	}
+	if cached.ContentType != "image/jpeg" || !bytes.Equal(cached.Data, thumb.Data) {
+		t.Errorf("expected the cached thumbnail, got %s of %d bytes", cached.ContentType, len(cached.Data))
+	}
+
+	// A narrow image is kept as it is
+	kept, err := service.Get(ctx, small, "")
+	if err != nil {
+		t.Fatalf("get small thumbnail: %v", err)
+	}
+	if kept.ContentType != "image/png" || !bytes.Equal(kept.Data, smallData) {
+		t.Errorf("expected the original image, got %s of %d bytes", kept.ContentType, len(kept.Data))
+	}
+
+	if _, err := service.Get(ctx, "https://cdn.example.com/missing.png", ""); err == nil {
+		t.Error("expected an error for an image that cannot be fetched")
+	}
+}
+
+func TestThumbnailService_Pregenerate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cached := "https://cdn.example.com/cached.png"
+	fresh := "https://cdn.example.com/fresh.png"
+	missing := "https://cdn.example.com/missing.png"
+	data := encodePNG(t, 800, 800, color.NRGBA{A: 255})
+	proxy := &fakeImageProxy{
+		images: map[string]*ProxyResult{
+			cached: {Data: data, ContentType: "image/png"},
+			fresh:  {Data: data, ContentType: "image/png"},
+		},
+		fetches: make(map[string]int),
+	}
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewThumbnailService(t.TempDir(), proxy, mockEntries)
+	ctx := context.Background()
+
+	if _, err := service.(*thumbnailService).generate(ctx, cached, "", ThumbnailWidths...); err != nil {
+		t.Fatalf("generate thumbnails: %v", err)
+	}
+
+	article := "https://example.com/post"
+	mockEntries.EXPECT().ListThumbnailSources(ctx, thumbnailPregenerateLimit).Return([]model.Entry{
+		{ID: 1, ThumbnailURL: &cached},
+		{ID: 2, URL: &article, ThumbnailURL: &fresh},
+		{ID: 3, ThumbnailURL: &missing},
+	}, nil)
+
+	count, err := service.Pregenerate(ctx)
+	if err != nil {
+		t.Fatalf("pregenerate: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 generated thumbnail, got %d", count)
+	}
+	if proxy.fetches[cached] != 1 || proxy.fetches[fresh] != 1 || proxy.fetches[missing] != 1 {
+		t.Errorf("expected cached images to be skipped, got fetches %v", proxy.fetches)
+	}
+	for _, width := range ThumbnailWidths {
+		if _, ok := service.(*thumbnailService).cached(fresh, width); !ok {
+			t.Errorf("expected the pre-generated %dpx thumbnail to be cached", width)
+		}
+	}
+}
+
+func TestThumbnailService_ForEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	photo := "https://cdn.example.com/photo.png"
+	proxy := &fakeImageProxy{
+		images:  map[string]*ProxyResult{photo: {Data: encodePNG(t, 1000, 500, color.NRGBA{A: 255}), ContentType: "image/png"}},
+		fetches: make(map[string]int),
+	}
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewThumbnailService(t.TempDir(), proxy, mockEntries)
+	ctx := context.Background()
+
+	mockEntries.EXPECT().GetByID(ctx, int64(1)).Return(model.Entry{ID: 1, ThumbnailURL: &photo}, nil).Times(3)
+	for _, width := range []int{320, 640, 320} {
+		thumb, err := service.ForEntry(ctx, 1, width)
+		if err != nil {
+			t.Fatalf("get %dpx thumbnail: %v", width, err)
+		}
+		img, err := jpeg.Decode(bytes.NewReader(thumb.Data))
+		if err != nil {
+			t.Fatalf("decode %dpx thumbnail: %v", width, err)
+		}
+		if b := img.Bounds(); b.Dx() != width || b.Dy() != width/2 {
+			t.Errorf("expected %dx%d, got %dx%d", width, width/2, b.Dx(), b.Dy())
+		}
+	}
+	if proxy.fetches[photo] != 2 {
+		t.Errorf("expected a fetch per uncached width, got %d", proxy.fetches[photo])
+	}
+
+	if _, err := service.ForEntry(ctx, 1, 500); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected an unknown width to be invalid, got %v", err)
+	}
+	mockEntries.EXPECT().GetByID(ctx, int64(2)).Return(model.Entry{ID: 2}, nil)
+	if _, err := service.ForEntry(ctx, 2, 320); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an entry without a thumbnail to be not found, got %v", err)
+	}
+	mockEntries.EXPECT().GetByID(ctx, int64(3)).Return(model.Entry{}, sql.ErrNoRows)
+	if _, err := service.ForEntry(ctx, 3, 320); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected a missing entry to be not found, got %v", err)
+	}
+}
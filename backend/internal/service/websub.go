@@ -0,0 +1,338 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"html"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/repository"
+)
+
+const (
+	// webSubLease is the subscription lease asked of hubs; they may grant
+	// a different one.
+	webSubLease = 10 * 24 * time.Hour
+	// webSubRenewBefore is how long before its lease runs out an active
+	// subscription is renewed, and webSubRenewRetry how often a renewal is
+	// tried again until the hub verifies it.
+	webSubRenewBefore = 24 * time.Hour
+	webSubRenewRetry  = time.Hour
+	// webSubRetryAfter is how long a request still unverified, or denied,
+	// waits before it is made again.
+	webSubRetryAfter = 24 * time.Hour
+	webSubTimeout    = 20 * time.Second
+)
+
+// WebSub verification modes, the hub.mode of a hub's request
+const (
+	WebSubModeSubscribe   = "subscribe"
+	WebSubModeUnsubscribe = "unsubscribe"
+	WebSubModeDenied      = "denied"
+)
+
+// webSubSignatures are the X-Hub-Signature methods pushed content may be
+// signed with.
+var webSubSignatures = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha384": sha512.New384,
+	"sha512": sha512.New,
+}
+
+// WebSubService keeps feeds subscribed to the WebSub hubs their content is
+// pushed from, and checks what the hubs send to the callback.
+type WebSubService interface {
+	// Discovered subscribes a feed to the hub found in its content, under
+	// topic, unless it is already. Subscriptions about to run out are
+	// renewed, and requests the hub did not verify are made again a day
+	// later. Failures are only logged; the feed is still refreshed.
+	Discovered(ctx context.Context, feed model.Feed, hub, topic string)
+	// Verify answers a hub checking the subscription of a feed. For a
+	// subscription it returns the challenge to echo and activates it for
+	// leaseSeconds; a denial is recorded and returns an empty challenge.
+	// Requests this instance did not make return ErrNotFound.
+	Verify(ctx context.Context, feedID int64, mode, topic, challenge string, leaseSeconds int) (string, error)
+	// Authenticate checks that content pushed for a feed is signed with the
+	// secret of its active subscription. It returns ErrNotFound without one
+	// and ErrInvalid for a missing or wrong signature.
+	Authenticate(ctx context.Context, feedID int64, body []byte, signature string) error
+}
+
+type webSubService struct {
+	subs       repository.WebSubRepository
+	httpClient *http.Client
+	publicURL  string
+}
+
+// NewWebSubService returns a service that subscribes feeds with callbacks
+// under publicURL; nothing is subscribed when it is empty.
+func NewWebSubService(subs repository.WebSubRepository, httpClient *http.Client, publicURL string) WebSubService {
+	client := httpClient
+	if client == nil {
+		client = &http.Client{Timeout: webSubTimeout}
+	}
+	return &webSubService{subs: subs, httpClient: client, publicURL: strings.TrimRight(publicURL, "/")}
+}
+
+func (s *webSubService) Discovered(ctx context.Context, feed model.Feed, hub, topic string) {
+	if s.publicURL == "" || hub == "" {
+		return
+	}
+	if topic == "" {
+		topic = feed.URL
+	}
+	now := time.Now()
+	sub, err := s.subs.Get(ctx, feed.ID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		sub = model.WebSubSubscription{FeedID: feed.ID}
+	case err != nil:
+		log.Printf("websub: get subscription of feed %d: %v", feed.ID, err)
+		return
+	case sub.Hub == hub && sub.Topic == topic && !webSubDue(sub, now):
+		return
+	}
+
+	// A renewal keeps the subscription active, and its secret, until the
+	// hub verifies it again; another hub or topic starts over
+	if sub.Hub != hub || sub.Topic != topic || sub.Secret == "" {
+		secret, err := newWebSubSecret()
+		if err != nil {
+			log.Printf("websub: secret for feed %d: %v", feed.ID, err)
+			return
+		}
+		sub = model.WebSubSubscription{FeedID: feed.ID, Hub: hub, Topic: topic, Secret: secret, State: model.WebSubPending}
+	}
+	if sub.State != model.WebSubActive {
+		sub.State, sub.LeaseExpiresAt = model.WebSubPending, nil
+	}
+	// Saved first: the hub may verify before it answers the request
+	if err := s.subs.Save(ctx, sub); err != nil {
+		log.Printf("websub: save subscription of feed %d: %v", feed.ID, err)
+		return
+	}
+	if err := s.subscribe(ctx, sub); err != nil {
+		log.Printf("websub: subscribe feed %d (%s) to %s: %v", feed.ID, feed.Title, hub, err)
+		return
+	}
+	log.Printf("websub: subscribing feed %d (%s) to %s", feed.ID, feed.Title, hub)
+}
+
+// webSubDue reports whether a subscription should be requested again.
+func webSubDue(sub model.WebSubSubscription, now time.Time) bool {
+	if sub.State != model.WebSubActive {
+		return now.Sub(sub.UpdatedAt) >= webSubRetryAfter
+	}
+	if sub.LeaseExpiresAt != nil && sub.LeaseExpiresAt.After(now.Add(webSubRenewBefore)) {
+		return false
+	}
+	return now.Sub(sub.UpdatedAt) >= webSubRenewRetry
+}
+
+// subscribe asks the hub to push the topic to the feed's callback.
+func (s *webSubService) subscribe(ctx context.Context, sub model.WebSubSubscription) error {
+	form := url.Values{
+		"hub.mode":          {WebSubModeSubscribe},
+		"hub.topic":         {sub.Topic},
+		"hub.callback":      {s.publicURL + "/api/websub/callback/" + strconv.FormatInt(sub.FeedID, 10)},
+		"hub.secret":        {sub.Secret},
+		"hub.lease_seconds": {strconv.Itoa(int(webSubLease / time.Second))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Hub, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("User-Agent", config.DefaultUserAgent)
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func (s *webSubService) Verify(ctx context.Context, feedID int64, mode, topic, challenge string, leaseSeconds int) (string, error) {
+	sub, err := s.subs.Get(ctx, feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get websub subscription: %w", err)
+	}
+	if sub.Topic != topic {
+		return "", ErrNotFound
+	}
+
+	switch mode {
+	case WebSubModeSubscribe:
+		if challenge == "" {
+			return "", ErrInvalid
+		}
+		if leaseSeconds <= 0 {
+			leaseSeconds = int(webSubLease / time.Second)
+		}
+		expires := time.Now().Add(time.Duration(leaseSeconds) * time.Second)
+		if err := s.subs.UpdateState(ctx, feedID, model.WebSubActive, &expires); err != nil {
+			return "", fmt.Errorf("activate websub subscription: %w", err)
+		}
+		log.Printf("websub: feed %d subscribed to %s until %s", feedID, sub.Hub, expires.Format(time.RFC3339))
+		return challenge, nil
+	case WebSubModeDenied:
+		if err := s.subs.UpdateState(ctx, feedID, model.WebSubDenied, nil); err != nil {
+			return "", fmt.Errorf("deny websub subscription: %w", err)
+		}
+		log.Printf("websub: %s denied the subscription of feed %d", sub.Hub, feedID)
+		return "", nil
+	case WebSubModeUnsubscribe:
+		// Feeds are never unsubscribed; their subscriptions lapse
+		return "", ErrNotFound
+	default:
+		return "", ErrInvalid
+	}
+}
+
+func (s *webSubService) Authenticate(ctx context.Context, feedID int64, body []byte, signature string) error {
+	sub, err := s.subs.Get(ctx, feedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("get websub subscription: %w", err)
+	}
+	if sub.State != model.WebSubActive {
+		return ErrNotFound
+	}
+	if !validWebSubSignature(sub.Secret, body, signature) {
+		return fmt.Errorf("%w: bad signature", ErrInvalid)
+	}
+	return nil
+}
+
+// validWebSubSignature checks an X-Hub-Signature header, method=hex.
+func validWebSubSignature(secret string, body []byte, signature string) bool {
+	method, sum, ok := strings.Cut(strings.TrimSpace(signature), "=")
+	newHash := webSubSignatures[strings.ToLower(method)]
+	if !ok || newHash == nil {
+		return false
+	}
+	want, err := hex.DecodeString(sum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), want)
+}
+
+func newWebSubSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+var (
+	linkHeaderPattern = regexp.MustCompile(`<([^>]*)>([^<]*)`)
+	linkRelPattern    = regexp.MustCompile(`(?i)\brel\s*=\s*"?([^";,]*)"?`)
+	linkTagPattern    = regexp.MustCompile(`(?i)<(?:[a-z0-9]+:)?link\b[^>]*>`)
+	linkAttrPattern   = regexp.MustCompile(`(?i)\b(rel|href)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	feedItemPattern   = regexp.MustCompile(`(?i)<(?:[a-z0-9]+:)?(?:item|entry)\b`)
+)
+
+// discoverHub finds the WebSub hub of a feed and its self URL, the topic to
+// subscribe to, in the Link headers of its response or else in the link
+// elements of its channel. Relative URLs are resolved against base.
+func discoverHub(header http.Header, body []byte, base string) (string, string) {
+	var hub, self string
+	for _, value := range header.Values("Link") {
+		for _, link := range linkHeaderPattern.FindAllStringSubmatch(value, -1) {
+			if rel := linkRelPattern.FindStringSubmatch(link[2]); rel != nil {
+				hub, self = pickLink(hub, self, rel[1], link[1])
+			}
+		}
+	}
+	if hub == "" {
+		// Entries have links of their own, self ones included
+		if loc := feedItemPattern.FindIndex(body); loc != nil {
+			body = body[:loc[0]]
+		}
+		self = ""
+		for _, tag := range linkTagPattern.FindAll(body, -1) {
+			var rel, href string
+			for _, attr := range linkAttrPattern.FindAllSubmatch(tag, -1) {
+				quoted := attr[2]
+				if quoted == nil {
+					quoted = attr[3]
+				}
+				value := string(bytes.TrimSpace(quoted))
+				if strings.EqualFold(string(attr[1]), "rel") {
+					rel = value
+				} else {
+					href = html.UnescapeString(value)
+				}
+			}
+			hub, self = pickLink(hub, self, rel, href)
+		}
+	}
+	if hub == "" {
+		return "", ""
+	}
+	return resolveLink(base, hub), resolveLink(base, self)
+}
+
+// pickLink keeps the first hub and self link among space-separated rels.
+func pickLink(hub, self, rels, href string) (string, string) {
+	if href == "" {
+		return hub, self
+	}
+	for _, rel := range strings.Fields(strings.ToLower(rels)) {
+		switch {
+		case rel == "hub" && hub == "":
+			hub = href
+		case rel == "self" && self == "":
+			self = href
+		}
+	}
+	return hub, self
+}
+
+func resolveLink(base, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
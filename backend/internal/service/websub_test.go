@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/mock/gomock"
+
+	"gist/backend/internal/config"
+	"gist/backend/internal/model"
+	"gist/backend/internal/service/testutil"
+)
+
+func TestDiscoverHub(t *testing.T) {
+	header := http.Header{}
+	header.Add("Link", `<https://hub.example.com/>; rel="hub", <https://example.com/feed.xml>; rel="self"`)
+
+	cases := []struct {
+		name      string
+		header    http.Header
+		body      string
+		wantHub   string
+		wantTopic string
+	}{
+		{
+			name:      "link headers",
+			header:    header,
+			body:      `<rss><channel><atom:link rel="hub" href="https://other.example.com/"/></channel></rss>`,
+			wantHub:   "https://hub.example.com/",
+			wantTopic: "https://example.com/feed.xml",
+		},
+		{
+			name: "atom",
+			body: `<feed xmlns="http://www.w3.org/2005/Atom">
+				<link rel="hub" href="https://pubsubhubbub.appspot.com"/>
+				<link rel="self" href="https://www.youtube.com/feeds/videos.xml?channel_id=UC1&amp;x=1"/>
+				<entry><link rel="self" href="https://www.youtube.com/watch?v=1"/></entry>
+			</feed>`,
+			wantHub:   "https://pubsubhubbub.appspot.com",
+			wantTopic: "https://www.youtube.com/feeds/videos.xml?channel_id=UC1&x=1",
+		},
+		{
+			name:      "rss with single quotes and a relative hub",
+			body:      `<rss><channel><atom:link href='/hub' rel='hub'/><item><title>A</title></item></channel></rss>`,
+			wantHub:   "https://example.com/hub",
+			wantTopic: "",
+		},
+		{
+			name: "hub only in an entry",
+			body: `<feed><entry><link rel="hub" href="https://hub.example.com/"/></entry></feed>`,
+		},
+	}
+	for _, tc := range cases {
+		h := tc.header
+		if h == nil {
+			h = http.Header{}
+		}
+		hub, topic := discoverHub(h, []byte(tc.body), "https://example.com/feed")
+		if hub != tc.wantHub || topic != tc.wantTopic {
+			t.Errorf("%s: got hub %q topic %q, want %q %q", tc.name, hub, topic, tc.wantHub, tc.wantTopic)
+		}
+	}
+}
+
+func TestValidWebSubSignature(t *testing.T) {
+	body := []byte("<feed/>")
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !validWebSubSignature("secret", body, signature) {
+		t.Error("expected the signature to be valid")
+	}
+	for _, bad := range []string{"", "sha256", "md5=00", "sha256=zz", signature + "00"} {
+		if validWebSubSignature("secret", body, bad) {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+	if validWebSubSignature("other", body, signature) {
+		t.Error("expected a signature with another secret to be rejected")
+	}
+}
+
+func TestWebSubService_Discovered(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var form map[string]string
+	hub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		form = map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer hub.Close()
+
+	mockSubs := testutil.NewMockWebSubRepository(ctrl)
+	service := NewWebSubService(mockSubs, hub.Client(), "https://gist.example.com/")
+	ctx := context.Background()
+	feed := model.Feed{ID: 7, Title: "Channel", URL: "https://example.com/feed"}
+
+	mockSubs.EXPECT().Get(ctx, int64(7)).Return(model.WebSubSubscription{}, sql.ErrNoRows)
+	var saved model.WebSubSubscription
+	mockSubs.EXPECT().Save(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, sub model.WebSubSubscription) error {
+		saved = sub
+		return nil
+	})
+	service.Discovered(ctx, feed, hub.URL, "https://example.com/self")
+
+	if saved.Hub != hub.URL || saved.Topic != "https://example.com/self" || saved.State != model.WebSubPending || len(saved.Secret) != 64 {
+		t.Errorf("unexpected saved subscription %+v", saved)
+	}
+	if form["hub.mode"] != "subscribe" || form["hub.topic"] != "https://example.com/self" || form["hub.secret"] != saved.Secret ||
+		form["hub.callback"] != "https://gist.example.com/api/websub/callback/7" || form["hub.lease_seconds"] != "864000" {
+		t.Errorf("unexpected subscription request %v", form)
+	}
+
+	// An active subscription far from running out is left alone
+	expires := time.Now().Add(5 * 24 * time.Hour)
+	saved.State, saved.LeaseExpiresAt, saved.UpdatedAt = model.WebSubActive, &expires, time.Now()
+	mockSubs.EXPECT().Get(ctx, int64(7)).Return(saved, nil)
+	form = nil
+	service.Discovered(ctx, feed, hub.URL, "https://example.com/self")
+	if form != nil {
+		t.Errorf("expected no request for an active subscription, got %v", form)
+	}
+}
+
+func TestWebSubService_DiscoveredWithoutPublicURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// No repository call is expected
+	service := NewWebSubService(testutil.NewMockWebSubRepository(ctrl), nil, "")
+	service.Discovered(context.Background(), model.Feed{ID: 1}, "https://hub.example.com/", "")
+}
+
+func TestWebSubDue(t *testing.T) {
+	now := time.Now()
+	soon, later := now.Add(time.Hour), now.Add(3*24*time.Hour)
+	cases := []struct {
+		name string
+		sub  model.WebSubSubscription
+		want bool
+	}{
+		{"active", model.WebSubSubscription{State: model.WebSubActive, LeaseExpiresAt: &later, UpdatedAt: now.Add(-48 * time.Hour)}, false},
+		{"expiring", model.WebSubSubscription{State: model.WebSubActive, LeaseExpiresAt: &soon, UpdatedAt: now.Add(-48 * time.Hour)}, true},
+		{"expiring, renewal just asked", model.WebSubSubscription{State: model.WebSubActive, LeaseExpiresAt: &soon, UpdatedAt: now.Add(-time.Minute)}, false},
+		{"pending", model.WebSubSubscription{State: model.WebSubPending, UpdatedAt: now.Add(-time.Hour)}, false},
+		{"pending for a day", model.WebSubSubscription{State: model.WebSubPending, UpdatedAt: now.Add(-25 * time.Hour)}, true},
+		{"denied for a day", model.WebSubSubscription{State: model.WebSubDenied, UpdatedAt: now.Add(-25 * time.Hour)}, true},
+	}
+	for _, tc := range cases {
+		if got := webSubDue(tc.sub, now); got != tc.want {
+			t.Errorf("%s: webSubDue = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWebSubService_Verify(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSubs := testutil.NewMockWebSubRepository(ctrl)
+	service := NewWebSubService(mockSubs, nil, "https://gist.example.com")
+	ctx := context.Background()
+	sub := model.WebSubSubscription{FeedID: 7, Hub: "https://hub.example.com/", Topic: "https://example.com/feed", Secret: "secret", State: model.WebSubPending}
+
+	mockSubs.EXPECT().Get(ctx, int64(7)).Return(sub, nil).Times(4)
+	mockSubs.EXPECT().Get(ctx, int64(8)).Return(model.WebSubSubscription{}, sql.ErrNoRows)
+	mockSubs.EXPECT().UpdateState(ctx, int64(7), model.WebSubActive, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, _ string, expires *time.Time) error {
+			if expires == nil || time.Until(*expires) < 59*time.Minute || time.Until(*expires) > time.Hour {
+				t.Errorf("expected the lease granted by the hub, got %v", expires)
+			}
+			return nil
+		})
+	mockSubs.EXPECT().UpdateState(ctx, int64(7), model.WebSubDenied, nil).Return(nil)
+
+	if challenge, err := service.Verify(ctx, 7, WebSubModeSubscribe, sub.Topic, "abc", 3600); err != nil || challenge != "abc" {
+		t.Errorf("subscribe: got %q, %v", challenge, err)
+	}
+	if _, err := service.Verify(ctx, 7, WebSubModeSubscribe, "https://example.com/other", "abc", 3600); !errors.Is(err, ErrNotFound) {
+		t.Errorf("other topic: expected ErrNotFound, got %v", err)
+	}
+	if _, err := service.Verify(ctx, 7, WebSubModeUnsubscribe, sub.Topic, "abc", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unsubscribe: expected ErrNotFound, got %v", err)
+	}
+	if _, err := service.Verify(ctx, 7, WebSubModeDenied, sub.Topic, "", 0); err != nil {
+		t.Errorf("denied: %v", err)
+	}
+	if _, err := service.Verify(ctx, 8, WebSubModeSubscribe, sub.Topic, "abc", 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("unknown feed: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRefreshService_Ingest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFeeds := testutil.NewMockFeedRepository(ctrl)
+	mockEntries := testutil.NewMockEntryRepository(ctrl)
+	service := NewRefreshService(mockFeeds, mockEntries, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, config.RefreshFixed)
+	ctx := context.Background()
+
+	mockFeeds.EXPECT().GetByID(ctx, int64(1)).Return(model.Feed{ID: 1, Title: "Channel", URL: "https://example.com/feed", Type: "article"}, nil).Times(2)
+	mockEntries.EXPECT().GetByURL(ctx, int64(1), "https://example.com/video").Return(model.Entry{}, sql.ErrNoRows)
+	mockEntries.EXPECT().GetByTitlePublished(ctx, int64(1), "Video", gomock.Any()).Return(model.Entry{}, sql.ErrNoRows)
+	mockEntries.EXPECT().CreateOrUpdate(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, entry model.Entry) error {
+		if entry.FeedID != 1 || entry.URL == nil || *entry.URL != "https://example.com/video" {
+			t.Errorf("unexpected entry %+v", entry)
+		}
+		return nil
+	})
+
+	pushed := `<feed xmlns="http://www.w3.org/2005/Atom"><title>Channel</title>
+		<entry><title>Video</title><link href="https://example.com/video"/><id>1</id><updated>2025-03-01T12:00:00Z</updated></entry>
+	</feed>`
+	if err := service.Ingest(ctx, 1, []byte(pushed)); err != nil {
+		t.Fatalf("ingest: %v", err)
+	}
+	if err := service.Ingest(ctx, 1, []byte("not a feed")); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected ErrInvalid for content that is not a feed, got %v", err)
+	}
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gist/backend/internal/repository"
+	"gist/backend/internal/secret"
+)
+
+// wipeTokenTTL is how long a wipe confirmation token can be used.
+const wipeTokenTTL = 5 * time.Minute
+
+// WipeConfirmation is the token a wipe must carry.
+type WipeConfirmation struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// WipeResult summarizes what a wipe removed.
+type WipeResult struct {
+	RowsDeleted  int64 `json:"rowsDeleted"`
+	FilesDeleted int   `json:"filesDeleted"`
+}
+
+// WipeService erases all data of the instance, returning it to its state on
+// first run. The config file and its API token are kept so the instance
+// stays reachable.
+type WipeService interface {
+	// Confirm issues a single-use token for Wipe, replacing any issued
+	// before.
+	Confirm() (WipeConfirmation, error)
+	// Wipe deletes every database row and every cached file and replaces the
+	// secret key. It returns ErrInvalid unless token is the current
+	// confirmation token.
+	Wipe(ctx context.Context, token string) (WipeResult, error)
+}
+
+type wipeService struct {
+	data    repository.WipeRepository
+	box     *secret.Box
+	keyPath string
+	dirs    []string
+	// kept are the files a wipe must never remove: the database, the
+	// secret key and the config file.
+	kept []string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewWipeService creates a wipe service. dirs are the cache directories
+// emptied by a wipe, such as those of icons, media and backups. It returns
+// an error if any of them holds the database at dbPath, the secret key or
+// the config file, which a wipe would otherwise delete.
+func NewWipeService(data repository.WipeRepository, box *secret.Box, keyPath, dbPath, configPath string, dirs ...string) (WipeService, error) {
+	paths := []string{keyPath, configPath}
+	if dbPath != "" {
+		// SQLite keeps uncommitted pages next to the database
+		paths = append(paths, dbPath, dbPath+"-wal", dbPath+"-shm")
+	}
+	var kept []string
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", path, err)
+		}
+		kept = append(kept, abs)
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", dir, err)
+		}
+		for _, path := range kept {
+			if within(path, abs) {
+				return nil, fmt.Errorf("%s cannot be wiped: it holds %s", dir, path)
+			}
+		}
+	}
+	return &wipeService{data: data, box: box, keyPath: keyPath, dirs: dirs, kept: kept, now: time.Now}, nil
+}
+
+func (s *wipeService) Confirm() (WipeConfirmation, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return WipeConfirmation{}, fmt.Errorf("generate wipe token: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = hex.EncodeToString(b)
+	s.expires = s.now().Add(wipeTokenTTL)
+	return WipeConfirmation{Token: s.token, ExpiresAt: s.expires}, nil
+}
+
+func (s *wipeService) Wipe(ctx context.Context, token string) (WipeResult, error) {
+	if !s.consume(token) {
+		return WipeResult{}, ErrInvalid
+	}
+
+	var result WipeResult
+	rows, err := s.data.WipeAll(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.RowsDeleted = rows
+
+	for _, dir := range s.dirs {
+		n, err := emptyDir(dir, s.kept)
+		result.FilesDeleted += n
+		if err != nil {
+			return result, err
+		}
+	}
+
+	// Credentials sealed with the old key are gone with the feeds; a new key
+	// keeps any copy of them, as in a backup, from being opened with this one
+	key, err := secret.CreateKey(s.keyPath)
+	if err != nil {
+		return result, err
+	}
+	if err := s.box.Rekey(key); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// consume reports whether token is the current confirmation token, which
+// can then not be used again.
+func (s *wipeService) consume(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token == "" || s.now().After(s.expires) {
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		return false
+	}
+	s.token = ""
+	return true
+}
+
+// emptyDir removes everything inside dir, keeping dir itself and any item
+// that is or holds one of kept, and returns the number of files removed.
+func emptyDir(dir string, kept []string) (int, error) {
+	if dir == "" {
+		return 0, nil
+	}
+	items, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("read %s: %w", dir, err)
+	}
+
+	removed := 0
+	for _, item := range items {
+		path := filepath.Join(dir, item.Name())
+		if holdsAny(path, kept) {
+			continue
+		}
+		files := 0
+		_ = filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				files++
+			}
+			return nil
+		})
+		if err := os.RemoveAll(path); err != nil {
+			return removed, fmt.Errorf("remove %s: %w", path, err)
+		}
+		removed += files
+	}
+	return removed, nil
+}
+
+// holdsAny reports whether path is or contains one of kept.
+func holdsAny(path string, kept []string) bool {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return true
+	}
+	for _, k := range kept {
+		if within(k, abs) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is dir or inside it.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gist/backend/internal/secret"
+	"gist/backend/internal/service/testutil"
+
+	"go.uber.org/mock/gomock"
+)
+
+func TestWipeService_Wipe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	data := testutil.NewMockWipeRepository(ctrl)
+	ctx := context.Background()
+
+	dataDir := t.TempDir()
+	keyPath := filepath.Join(dataDir, secret.KeyFileName)
+	key, _ := secret.LoadOrCreateKey(keyPath)
+	box, _ := secret.NewBox(key)
+	sealed, _ := box.Seal([]byte("hunter2"))
+
+	icons, media := filepath.Join(dataDir, "icons"), filepath.Join(dataDir, "media")
+	for _, file := range []string{filepath.Join(icons, "a.png"), filepath.Join(media, "ab", "c.webp"), filepath.Join(media, "d.webp")} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	created, err := NewWipeService(data, box, keyPath, filepath.Join(dataDir, "gist.db"), filepath.Join(dataDir, "gist.conf"), icons, media, filepath.Join(dataDir, "missing"))
+	if err != nil {
+		t.Fatalf("new wipe service: %v", err)
+	}
+	service := created.(*wipeService)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	if _, err := service.Wipe(ctx, ""); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid before a token is issued, got %v", err)
+	}
+	confirmation, err := service.Confirm()
+	if err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if !confirmation.ExpiresAt.Equal(now.Add(wipeTokenTTL)) {
+		t.Errorf("unexpected expiry %v", confirmation.ExpiresAt)
+	}
+	if _, err := service.Wipe(ctx, "wrong"); !errors.Is(err, ErrInvalid) {
+		t.Fatalf("expected ErrInvalid for a wrong token, got %v", err)
+	}
+
+	data.EXPECT().WipeAll(ctx).Return(int64(42), nil)
+	result, err := service.Wipe(ctx, confirmation.Token)
+	if err != nil {
+		t.Fatalf("wipe: %v", err)
+	}
+	if result != (WipeResult{RowsDeleted: 42, FilesDeleted: 3}) {
+		t.Errorf("unexpected result %+v", result)
+	}
+	for _, dir := range []string{icons, media} {
+		if items, err := os.ReadDir(dir); err != nil || len(items) != 0 {
+			t.Errorf("expected %s to be kept and empty, got %v, %v", dir, items, err)
+		}
+	}
+	if _, err := box.Open(sealed); !errors.Is(err, secret.ErrInvalid) {
+		t.Errorf("expected the secret key to be replaced, got %v", err)
+	}
+	if stored, _ := secret.LoadOrCreateKey(keyPath); string(stored) == string(key) {
+		t.Error("expected a new key file")
+	}
+
+	if _, err := service.Wipe(ctx, confirmation.Token); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected a used token to be rejected, got %v", err)
+	}
+}
+
+func TestWipeService_ExpiredToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	key, _ := secret.LoadOrCreateKey(filepath.Join(t.TempDir(), secret.KeyFileName))
+	box, _ := secret.NewBox(key)
+	created, _ := NewWipeService(testutil.NewMockWipeRepository(ctrl), box, "", "", "")
+	service := created.(*wipeService)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	confirmation, _ := service.Confirm()
+	now = now.Add(wipeTokenTTL + time.Second)
+	if _, err := service.Wipe(context.Background(), confirmation.Token); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected an expired token to be rejected, got %v", err)
+	}
+}
+
+func TestNewWipeService_RejectsDirsHoldingData(t *testing.T) {
+	dataDir := t.TempDir()
+	keyPath := filepath.Join(dataDir, secret.KeyFileName)
+	dbPath := filepath.Join(dataDir, "db", "gist.db")
+	configPath := filepath.Join(dataDir, "gist.conf")
+
+	tests := []struct {
+		name string
+		dir  string
+		ok   bool
+	}{
+		{"cache dir", filepath.Join(dataDir, "media"), true},
+		{"database dir", filepath.Join(dataDir, "db"), false},
+		{"data dir", dataDir, false},
+		{"parent of data dir", filepath.Dir(dataDir), false},
+		{"relative database dir", mustRel(t, filepath.Join(dataDir, "db")), false},
+	}
+	for _, tt := range tests {
+		_, err := NewWipeService(nil, nil, keyPath, dbPath, configPath, tt.dir)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: got error %v, want ok=%v", tt.name, err, tt.ok)
+		}
+	}
+}
+
+func TestEmptyDir_KeepsProtectedFiles(t *testing.T) {
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "db", "gist.db")
+	keyPath := filepath.Join(dir, secret.KeyFileName)
+	for _, file := range []string{dbPath, dbPath + "-wal", filepath.Join(dir, "db", "other"), keyPath, filepath.Join(dir, "a.png")} {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte("x"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removed, err := emptyDir(dir, []string{dbPath, dbPath + "-wal", keyPath})
+	if err != nil {
+		t.Fatalf("empty dir: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("expected only a.png to be removed, removed %d files", removed)
+	}
+	for _, file := range []string{dbPath, dbPath + "-wal", keyPath} {
+		if _, err := os.Stat(file); err != nil {
+			t.Errorf("expected %s to be kept, got %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "a.png")); !os.IsNotExist(err) {
+		t.Errorf("expected a.png to be removed, got %v", err)
+	}
+}
+
+func mustRel(t *testing.T, path string) string {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(wd, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rel
+}
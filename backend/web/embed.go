@@ -0,0 +1,25 @@
+// Package web embeds the built frontend so the server can ship as a single binary.
+//
+// Copy frontend/dist into backend/web/dist before building to embed it.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// Dist returns the embedded frontend build, or nil if the binary was built
+// without one (only the placeholder file is present).
+func Dist() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		return nil
+	}
+	if info, err := fs.Stat(sub, "index.html"); err != nil || info.IsDir() {
+		return nil
+	}
+	return sub
+}
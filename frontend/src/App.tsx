@@ -11,6 +11,8 @@ import { PictureMasonry, Lightbox } from '@/components/picture-masonry'
 import { useSelection, selectionToParams } from '@/hooks/useSelection'
 import { useMarkAllAsRead } from '@/hooks/useEntries'
 import { useMobileLayout } from '@/hooks/useMobileLayout'
+import { useServerEvents } from '@/hooks/useServerEvents'
+import { useTheme } from '@/hooks/useTheme'
 import { isAddFeedPath } from '@/lib/router'
 import type { ContentType } from '@/types/api'
 
@@ -41,6 +43,9 @@ function AppContent() {
   } = useSelection()
 
   const { mutate: markAllAsRead } = useMarkAllAsRead()
+  useServerEvents()
+  // Picks up the theme chosen on other devices
+  useTheme()
   const [addFeedContentType, setAddFeedContentType] = useState<ContentType>('article')
 
   // Mobile-aware selection handlers (all hooks must be before any conditional returns)
@@ -1,34 +1,96 @@
 import type {
+  ApiErrorCode,
   ApiErrorResponse,
+  BrokenLink,
   ContentType,
   Entry,
+  EntryArchiveParams,
+  EntryArchiveResponse,
   EntryListParams,
   EntryListResponse,
   Feed,
+  FeedAuth,
+  FeedCandidate,
+  FeedDateQuirks,
+  FeedDeleteMode,
+  FeedDeleteResult,
+  FeedFetchAttempt,
+  FeedHealth,
   FeedPreview,
+  FeedRequestOverrides,
+  FeedScraper,
+  FieldError,
+  Filter,
+  FilterInput,
+  FilterTestRequest,
+  FilterTestResult,
   Folder,
+  ImportPreview,
   ImportTask,
+  ImportUndoResult,
+  LinkCheckResult,
   MarkAllReadParams,
+  NotificationListResponse,
+  PlaybackPosition,
+  ReadableBatchResult,
+  ReadableContentResponse,
+  ScheduledJob,
+  ScrapePreview,
+  ServerEvent,
   StarredCountResponse,
+  StarredCountsResponse,
+  SyncResult,
+  Tag,
+  Task,
+  TaskKind,
+  UnreadCountsParams,
+  BulkAddFeedsResponse,
   UnreadCountsResponse,
 } from '@/types/api'
-import type { AISettings, AITestRequest, AITestResponse, GeneralSettings } from '@/types/settings'
+import type {
+  AISettings,
+  AITestRequest,
+  AITestResponse,
+  AppearanceSettings,
+  AppearanceSettingsUpdate,
+  BackupFile,
+  GeneralSettings,
+  GeneralSettingsUpdate,
+  IntegrationProvider,
+  IntegrationSaveResult,
+  IntegrationSettings,
+  Preferences,
+} from '@/types/settings'
 
 const API_BASE_URL = import.meta.env.VITE_API_URL ?? ''
 
 export class ApiError extends Error {
   status: number
+  code?: ApiErrorCode
+  details?: unknown
+  fieldErrors?: FieldError[]
 
-  constructor(message: string, status: number) {
+  constructor(message: string, status: number, body?: ApiErrorResponse) {
     super(message)
     this.status = status
+    this.code = body?.code
+    this.details = body?.details
+    this.fieldErrors = body?.fieldErrors
   }
 }
 
 function isErrorResponse(value: unknown): value is ApiErrorResponse {
   if (typeof value !== 'object' || value === null) return false
-  if (!('error' in value)) return false
-  return typeof (value as { error: unknown }).error === 'string'
+  if (!('code' in value) || !('message' in value)) return false
+  return typeof (value as { message: unknown }).message === 'string'
+}
+
+function toApiError(data: unknown, response: Response): ApiError {
+  if (isErrorResponse(data)) {
+    return new ApiError(data.message || 'Request failed', response.status, data)
+  }
+  const message = typeof data === 'string' ? data : response.statusText
+  return new ApiError(message || 'Request failed', response.status)
 }
 
 async function parseResponse(response: Response): Promise<unknown> {
@@ -63,12 +125,7 @@ async function request<T>(path: string, options: RequestInit = {}): Promise<T> {
 
   const data = await parseResponse(response)
   if (!response.ok) {
-    const message = isErrorResponse(data)
-      ? data.error
-      : typeof data === 'string'
-        ? data
-        : response.statusText
-    throw new ApiError(message || 'Request failed', response.status)
+    throw toApiError(data, response)
   }
 
   if (response.status === 204) {
@@ -116,6 +173,13 @@ export async function updateFolderType(id: string, type: ContentType): Promise<v
   })
 }
 
+export async function setFolderThumbnails(id: string, enabled: boolean): Promise<void> {
+  return request<void>(`/api/folders/${id}/thumbnails`, {
+    method: 'PATCH',
+    body: JSON.stringify({ enabled }),
+  })
+}
+
 export async function deleteFolders(ids: string[]): Promise<void> {
   return request<void>('/api/folders', {
     method: 'DELETE',
@@ -123,6 +187,37 @@ export async function deleteFolders(ids: string[]): Promise<void> {
   })
 }
 
+export async function listFilters(): Promise<Filter[]> {
+  return request<Filter[]>('/api/filters')
+}
+
+export async function createFilter(payload: FilterInput): Promise<Filter> {
+  return request<Filter>('/api/filters', {
+    method: 'POST',
+    body: JSON.stringify(payload),
+  })
+}
+
+export async function updateFilter(id: string, payload: FilterInput): Promise<Filter> {
+  return request<Filter>(`/api/filters/${id}`, {
+    method: 'PUT',
+    body: JSON.stringify(payload),
+  })
+}
+
+export async function testFilter(payload: FilterTestRequest): Promise<FilterTestResult> {
+  return request<FilterTestResult>('/api/filters/test', {
+    method: 'POST',
+    body: JSON.stringify(payload),
+  })
+}
+
+export async function deleteFilter(id: string): Promise<void> {
+  return request<void>(`/api/filters/${id}`, {
+    method: 'DELETE',
+  })
+}
+
 export async function listFeeds(folderId?: string): Promise<Feed[]> {
   const params = folderId === undefined ? '' : `?folderId=${encodeURIComponent(folderId)}`
   return request<Feed[]>(`/api/feeds${params}`)
@@ -133,6 +228,8 @@ export async function createFeed(payload: {
   folderId?: string
   title?: string
   type?: ContentType
+  requestOverrides?: FeedRequestOverrides
+  auth?: FeedAuth
 }): Promise<Feed> {
   return request<Feed>('/api/feeds', {
     method: 'POST',
@@ -140,9 +237,53 @@ export async function createFeed(payload: {
   })
 }
 
+export async function createScrapedFeed(payload: {
+  url: string
+  folderId?: string
+  title?: string
+  type?: ContentType
+  scraper: FeedScraper
+  dateQuirks?: FeedDateQuirks
+  requestOverrides?: FeedRequestOverrides
+  auth?: FeedAuth
+}): Promise<Feed> {
+  return request<Feed>('/api/feeds/scraped', {
+    method: 'POST',
+    body: JSON.stringify(payload),
+  })
+}
+
+export async function previewScrapedFeed(payload: {
+  url: string
+  scraper: FeedScraper
+  dateQuirks?: FeedDateQuirks
+  requestOverrides?: FeedRequestOverrides
+  auth?: FeedAuth
+}): Promise<ScrapePreview> {
+  return request<ScrapePreview>('/api/feeds/scraped/preview', {
+    method: 'POST',
+    body: JSON.stringify(payload),
+  })
+}
+
 export async function updateFeed(
   id: string,
-  payload: { title: string; folderId?: string }
+  payload: {
+    title: string
+    folderId?: string
+    refreshInterval?: number
+    fullTextUrl?: string
+    summaryInSourceLanguage?: boolean
+    prefetchReadability?: boolean
+    /** Replaces the date corrections; an empty object removes them. */
+    dateQuirks?: FeedDateQuirks
+    /** Replaces the scraper selectors; an empty object makes it an ordinary feed. */
+    scraper?: FeedScraper | Record<string, never>
+    /** Replaces the User-Agent and headers; an empty object sends the defaults. */
+    requestOverrides?: FeedRequestOverrides
+    /** Replaces the stored credentials; an empty object removes them. */
+    auth?: FeedAuth
+  }
 ): Promise<Feed> {
   return request<Feed>(`/api/feeds/${id}`, {
     method: 'PUT',
@@ -150,8 +291,11 @@ export async function updateFeed(
   })
 }
 
-export async function deleteFeed(id: string): Promise<void> {
-  return request<void>(`/api/feeds/${id}`, {
+export async function deleteFeed(
+  id: string,
+  mode: FeedDeleteMode = 'deleteAll'
+): Promise<FeedDeleteResult> {
+  return request<FeedDeleteResult>(`/api/feeds/${id}?mode=${mode}`, {
     method: 'DELETE',
   })
 }
@@ -163,6 +307,10 @@ export async function updateFeedType(id: string, type: ContentType): Promise<voi
   })
 }
 
+export async function enableFeed(id: string): Promise<Feed> {
+  return request<Feed>(`/api/feeds/${id}/enable`, { method: 'POST' })
+}
+
 export async function deleteFeeds(ids: string[]): Promise<void> {
   return request<void>('/api/feeds', {
     method: 'DELETE',
@@ -170,17 +318,76 @@ export async function deleteFeeds(ids: string[]): Promise<void> {
   })
 }
 
+const TASK_POLL_INTERVAL_MS = 1000
+
+// refreshAllFeeds starts a refresh task and resolves once it has finished.
 export async function refreshAllFeeds(): Promise<void> {
-  return request<void>('/api/feeds/refresh', {
+  let task = await request<Task>('/api/feeds/refresh', {
     method: 'POST',
   })
+  while (task.status === 'running') {
+    await new Promise((resolve) => setTimeout(resolve, TASK_POLL_INTERVAL_MS))
+    task = await getTask(task.id)
+  }
+  if (task.status === 'error') {
+    throw new ApiError(task.error || 'Refresh failed', 500)
+  }
 }
 
-export async function previewFeed(url: string): Promise<FeedPreview> {
+/** Subscribes to each URL of a newline-separated list */
+export async function bulkAddFeeds(payload: {
+  urls: string
+  folderId?: string
+  type?: ContentType
+}): Promise<BulkAddFeedsResponse> {
+  return request<BulkAddFeedsResponse>('/api/feeds/bulk-add', {
+    method: 'POST',
+    body: JSON.stringify(payload),
+  })
+}
+
+export async function previewFeed(
+  url: string,
+  auth?: FeedAuth,
+  requestOverrides?: FeedRequestOverrides
+): Promise<FeedPreview> {
+  if (auth || requestOverrides) {
+    // Sent in the body to keep credentials out of URLs and logs
+    return request<FeedPreview>('/api/feeds/preview', {
+      method: 'POST',
+      body: JSON.stringify({ url, requestOverrides, auth }),
+    })
+  }
   const params = new URLSearchParams({ url })
   return request<FeedPreview>(`/api/feeds/preview?${params.toString()}`)
 }
 
+export async function discoverFeeds(url: string): Promise<FeedCandidate[]> {
+  const params = new URLSearchParams({ url })
+  return request<FeedCandidate[]>(`/api/feeds/discover?${params.toString()}`)
+}
+
+export async function listFeedHealth(): Promise<FeedHealth[]> {
+  return request<FeedHealth[]>('/api/feeds/health')
+}
+
+export async function getFeedHealth(id: string): Promise<FeedHealth> {
+  return request<FeedHealth>(`/api/feeds/${id}/health`)
+}
+
+// fetchFeedReadable starts extracting the readable content of a feed's
+// entries that have none.
+export async function fetchFeedReadable(id: string, unreadOnly = false): Promise<Task<ReadableBatchResult>> {
+  const params = unreadOnly ? '?unreadOnly=true' : ''
+  return request<Task<ReadableBatchResult>>(`/api/feeds/${id}/fetch-readable${params}`, { method: 'POST' })
+}
+
+// refreshFeed fetches a feed now and resolves with how the fetch went; a
+// failed fetch resolves too, with its error.
+export async function refreshFeed(id: string): Promise<FeedFetchAttempt> {
+  return request<FeedFetchAttempt>(`/api/feeds/${id}/refresh`, { method: 'POST' })
+}
+
 export async function listEntries(params: EntryListParams = {}): Promise<EntryListResponse> {
   const searchParams = new URLSearchParams()
 
@@ -202,20 +409,65 @@ export async function listEntries(params: EntryListParams = {}): Promise<EntryLi
   if (params.hasThumbnail) {
     searchParams.set('hasThumbnail', 'true')
   }
+  if (params.excludeNsfw) {
+    searchParams.set('excludeNsfw', 'true')
+  }
+  if (params.tagId !== undefined) {
+    searchParams.set('tagId', params.tagId)
+  }
+  if (params.period !== undefined) {
+    searchParams.set('period', params.period)
+  }
   if (params.limit !== undefined) {
     searchParams.set('limit', String(params.limit))
   }
   if (params.offset !== undefined) {
     searchParams.set('offset', String(params.offset))
   }
+  if (params.after !== undefined) {
+    searchParams.set('after', params.after)
+  }
 
   const queryString = searchParams.toString()
   const path = queryString ? `/api/entries?${queryString}` : '/api/entries'
   return request<EntryListResponse>(path)
 }
 
+export async function getEntryArchive(params: EntryArchiveParams = {}): Promise<EntryArchiveResponse> {
+  const searchParams = new URLSearchParams()
+
+  if (params.groupBy !== undefined) {
+    searchParams.set('groupBy', params.groupBy)
+  }
+  if (params.feedId !== undefined) {
+    searchParams.set('feedId', String(params.feedId))
+  }
+  if (params.folderId !== undefined) {
+    searchParams.set('folderId', String(params.folderId))
+  }
+  if (params.contentType !== undefined) {
+    searchParams.set('contentType', params.contentType)
+  }
+  if (params.unreadOnly) {
+    searchParams.set('unreadOnly', 'true')
+  }
+  if (params.starredOnly) {
+    searchParams.set('starredOnly', 'true')
+  }
+  if (params.excludeNsfw) {
+    searchParams.set('excludeNsfw', 'true')
+  }
+  if (params.period !== undefined) {
+    searchParams.set('period', params.period)
+  }
+
+  const queryString = searchParams.toString()
+  const path = queryString ? `/api/entries/archive?${queryString}` : '/api/entries/archive'
+  return request<EntryArchiveResponse>(path)
+}
+
 export async function getEntry(id: string): Promise<Entry> {
-  return request<Entry>(`/api/entries/${id}`)
+  return request<Entry>(`/api/entries/${id}?proxyImages=true`)
 }
 
 export async function updateEntryReadStatus(id: string, read: boolean): Promise<void> {
@@ -225,11 +477,14 @@ export async function updateEntryReadStatus(id: string, read: boolean): Promise<
   })
 }
 
-export async function fetchReadableContent(id: string): Promise<string> {
-  const response = await request<{ readableContent: string }>(`/api/entries/${id}/fetch-readable`, {
+export async function fetchReadableContent(id: string): Promise<ReadableContentResponse> {
+  return request<ReadableContentResponse>(`/api/entries/${id}/fetch-readable?proxyImages=true`, {
     method: 'POST',
   })
-  return response.readableContent
+}
+
+export function entryPrintUrl(id: string): string {
+  return `${API_BASE_URL}/api/entries/${id}/print`
 }
 
 export async function markAllAsRead(params: MarkAllReadParams): Promise<void> {
@@ -239,8 +494,19 @@ export async function markAllAsRead(params: MarkAllReadParams): Promise<void> {
   })
 }
 
-export async function getUnreadCounts(): Promise<UnreadCountsResponse> {
-  return request<UnreadCountsResponse>('/api/unread-counts')
+export async function getUnreadCounts(params: UnreadCountsParams = {}): Promise<UnreadCountsResponse> {
+  const searchParams = new URLSearchParams()
+
+  if (params.contentType !== undefined) {
+    searchParams.set('contentType', params.contentType)
+  }
+  if (params.folderId !== undefined) {
+    searchParams.set('folderId', params.folderId)
+  }
+
+  const queryString = searchParams.toString()
+  const path = queryString ? `/api/unread-counts?${queryString}` : '/api/unread-counts'
+  return request<UnreadCountsResponse>(path)
 }
 
 export async function updateEntryStarred(id: string, starred: boolean): Promise<void> {
@@ -250,11 +516,64 @@ export async function updateEntryStarred(id: string, starred: boolean): Promise<
   })
 }
 
+export async function listTags(): Promise<Tag[]> {
+  return request<Tag[]>('/api/tags')
+}
+
+export async function createTag(name: string): Promise<Tag> {
+  return request<Tag>('/api/tags', {
+    method: 'POST',
+    body: JSON.stringify({ name }),
+  })
+}
+
+export async function renameTag(id: string, name: string): Promise<Tag> {
+  return request<Tag>(`/api/tags/${id}`, {
+    method: 'PUT',
+    body: JSON.stringify({ name }),
+  })
+}
+
+export async function deleteTag(id: string): Promise<void> {
+  return request<void>(`/api/tags/${id}`, { method: 'DELETE' })
+}
+
+export async function listEntryTags(entryId: string): Promise<Tag[]> {
+  return request<Tag[]>(`/api/entries/${entryId}/tags`)
+}
+
+/** Tags an entry with an existing tag, or with the tag named name, created when missing. */
+export async function addEntryTag(entryId: string, tag: { tagId: string } | { name: string }): Promise<Tag[]> {
+  return request<Tag[]>(`/api/entries/${entryId}/tags`, {
+    method: 'POST',
+    body: JSON.stringify(tag),
+  })
+}
+
+export async function removeEntryTag(entryId: string, tagId: string): Promise<Tag[]> {
+  return request<Tag[]>(`/api/entries/${entryId}/tags/${tagId}`, { method: 'DELETE' })
+}
+
+export async function getPlaybackPosition(id: string): Promise<PlaybackPosition> {
+  return request<PlaybackPosition>(`/api/entries/${id}/playback`)
+}
+
+export async function updatePlaybackPosition(id: string, positionSeconds: number): Promise<PlaybackPosition> {
+  return request<PlaybackPosition>(`/api/entries/${id}/playback`, {
+    method: 'PUT',
+    body: JSON.stringify({ positionSeconds: Math.max(0, Math.floor(positionSeconds)) }),
+  })
+}
+
 export async function getStarredCount(): Promise<StarredCountResponse> {
   return request<StarredCountResponse>('/api/starred-count')
 }
 
-export async function startImportOPML(file: File): Promise<void> {
+export async function getStarredCounts(): Promise<StarredCountsResponse> {
+  return request<StarredCountsResponse>('/api/starred-counts')
+}
+
+export async function startImportOPML(file: File): Promise<{ status: string; taskId: string }> {
   const formData = new FormData()
   formData.append('file', file)
 
@@ -264,14 +583,94 @@ export async function startImportOPML(file: File): Promise<void> {
     body: formData,
   })
 
+  const data = await parseResponse(response)
   if (!response.ok) {
-    const text = await response.text()
-    throw new ApiError(text || 'Import failed', response.status)
+    throw toApiError(data, response)
   }
+  return data as { status: string; taskId: string }
+}
+
+// runSync starts pulling from the configured sync primary.
+export async function runSync(): Promise<Task<SyncResult>> {
+  return request<Task<SyncResult>>('/api/sync/run', { method: 'POST' })
+}
+
+export async function listBrokenLinks(): Promise<BrokenLink[]> {
+  return request<BrokenLink[]>('/api/link-checks/broken')
+}
+
+// runLinkCheck starts checking the links of starred entries.
+export async function runLinkCheck(): Promise<Task<LinkCheckResult>> {
+  return request<Task<LinkCheckResult>>('/api/link-checks/run', { method: 'POST' })
+}
+
+export async function listNotifications(unreadOnly = false): Promise<NotificationListResponse> {
+  return request<NotificationListResponse>(`/api/notifications${unreadOnly ? '?unreadOnly=true' : ''}`)
+}
+
+export async function markNotificationRead(id: string): Promise<void> {
+  return request<void>(`/api/notifications/${id}/read`, { method: 'POST' })
+}
+
+export async function markAllNotificationsRead(): Promise<void> {
+  return request<void>('/api/notifications/mark-read', { method: 'POST' })
+}
+
+export async function dismissNotification(id: string): Promise<void> {
+  return request<void>(`/api/notifications/${id}`, { method: 'DELETE' })
 }
 
-export async function cancelImportOPML(): Promise<boolean> {
-  const result = await request<{ cancelled: boolean }>('/api/opml/import', {
+export async function listScheduledJobs(): Promise<ScheduledJob[]> {
+  return request<ScheduledJob[]>('/api/scheduler')
+}
+
+export async function pauseScheduledJob(kind: TaskKind): Promise<ScheduledJob> {
+  return request<ScheduledJob>(`/api/scheduler/${kind}/pause`, { method: 'POST' })
+}
+
+export async function resumeScheduledJob(kind: TaskKind): Promise<ScheduledJob> {
+  return request<ScheduledJob>(`/api/scheduler/${kind}/resume`, { method: 'POST' })
+}
+
+export async function listTasks(): Promise<Task[]> {
+  return request<Task[]>('/api/tasks')
+}
+
+export async function getTask(id: string): Promise<Task> {
+  return request<Task>(`/api/tasks/${id}`)
+}
+
+export async function cancelTask(id: string): Promise<boolean> {
+  const result = await request<{ cancelled: boolean }>(`/api/tasks/${id}`, {
+    method: 'DELETE',
+  })
+  return result.cancelled
+}
+
+export async function previewImportOPML(file: File): Promise<ImportPreview> {
+  const formData = new FormData()
+  formData.append('file', file)
+
+  return request<ImportPreview>('/api/opml/import?dryRun=true', {
+    method: 'POST',
+    body: formData,
+  })
+}
+
+export async function undoImportOPML(taskId: string): Promise<ImportUndoResult> {
+  return request<ImportUndoResult>(`/api/opml/imports/${taskId}/undo`, {
+    method: 'POST',
+  })
+}
+
+export async function getImportOPML(taskId: string): Promise<ImportTask> {
+  return request<ImportTask>(`/api/opml/import/${taskId}`)
+}
+
+// cancelImportOPML cancels the import with taskId, or the latest import.
+export async function cancelImportOPML(taskId?: string): Promise<boolean> {
+  const path = taskId ? `/api/opml/import/${taskId}` : '/api/opml/import'
+  const result = await request<{ cancelled: boolean }>(path, {
     method: 'DELETE',
   })
   return result.cancelled
@@ -328,11 +727,99 @@ export function watchImportStatus(onUpdate: (task: ImportTask) => void): () => v
   }
 }
 
+/** Streams background work notifications, reconnecting after a dropped connection. */
+export function watchEvents(onEvent: (event: ServerEvent) => void, onConnect?: () => void): () => void {
+  const url = `${API_BASE_URL}/api/events`
+  const controller = new AbortController()
+
+  const connect = async () => {
+    while (!controller.signal.aborted) {
+      try {
+        const response = await fetch(url, { signal: controller.signal })
+        if (response.ok && response.body) {
+          onConnect?.()
+          const reader = response.body.getReader()
+          const decoder = new TextDecoder()
+          let buffer = ''
+          let type = ''
+
+          while (true) {
+            const { done, value } = await reader.read()
+            if (done) break
+
+            buffer += decoder.decode(value, { stream: true })
+            const lines = buffer.split('\n')
+            buffer = lines.pop() || ''
+
+            for (const line of lines) {
+              if (line.startsWith('event: ')) {
+                type = line.slice(7)
+              } else if (line.startsWith('data: ') && type) {
+                try {
+                  onEvent({ type, data: JSON.parse(line.slice(6)) } as ServerEvent)
+                } catch {
+                  // ignore parse errors
+                }
+                type = ''
+              }
+            }
+          }
+        }
+      } catch {
+        // connection error, retry below
+      }
+      // Wait before reconnecting
+      await new Promise((resolve) => setTimeout(resolve, 5000))
+    }
+  }
+
+  connect()
+
+  return () => controller.abort()
+}
+
+export function exportStarredEntries(format: 'json' | 'md' = 'json'): void {
+  const url = `${API_BASE_URL}/api/entries/export?starred=true&format=${format}`
+  window.location.href = url
+}
+
 export function exportOPML(): void {
   const url = `${API_BASE_URL}/api/opml/export`
   window.location.href = url
 }
 
+export async function getAppearanceSettings(): Promise<AppearanceSettings> {
+  return request<AppearanceSettings>('/api/settings/appearance')
+}
+
+export async function updateAppearanceSettings(settings: AppearanceSettingsUpdate): Promise<AppearanceSettings> {
+  return request<AppearanceSettings>('/api/settings/appearance', {
+    method: 'PUT',
+    body: JSON.stringify(settings),
+  })
+}
+
+export async function getPreferences(): Promise<Preferences> {
+  return request<Preferences>('/api/preferences')
+}
+
+/** Stores the given preferences, keeping those not listed; null deletes one. */
+export async function updatePreferences(changes: Preferences): Promise<Preferences> {
+  return request<Preferences>('/api/preferences', {
+    method: 'PUT',
+    body: JSON.stringify(changes),
+  })
+}
+
+export async function listBackups(): Promise<BackupFile[]> {
+  return request<BackupFile[]>('/api/backups')
+}
+
+export function downloadBackup(name: string): void {
+  const url = `${API_BASE_URL}/api/backups/${encodeURIComponent(name)}`
+  window.location.href = url
+}
+
 export async function getAISettings(): Promise<AISettings> {
   return request<AISettings>('/api/settings/ai')
 }
@@ -355,13 +842,40 @@ export async function getGeneralSettings(): Promise<GeneralSettings> {
   return request<GeneralSettings>('/api/settings/general')
 }
 
-export async function updateGeneralSettings(settings: GeneralSettings): Promise<GeneralSettings> {
+export async function updateGeneralSettings(settings: GeneralSettingsUpdate): Promise<GeneralSettings> {
   return request<GeneralSettings>('/api/settings/general', {
     method: 'PUT',
     body: JSON.stringify(settings),
   })
 }
 
+export async function getIntegrationSettings(): Promise<IntegrationSettings> {
+  return request<IntegrationSettings>('/api/settings/integrations')
+}
+
+export async function updateIntegrationSettings(settings: IntegrationSettings): Promise<IntegrationSettings> {
+  return request<IntegrationSettings>('/api/settings/integrations', {
+    method: 'PUT',
+    body: JSON.stringify(settings),
+  })
+}
+
+export async function saveEntryTo(
+  id: string,
+  provider: IntegrationProvider,
+  withContent = false
+): Promise<IntegrationSaveResult> {
+  const params = withContent ? '?withContent=true' : ''
+  return request<IntegrationSaveResult>(`/api/entries/${id}/save-to/${provider}${params}`, { method: 'POST' })
+}
+
+export async function setLowData(enabled: boolean): Promise<GeneralSettings> {
+  return request<GeneralSettings>('/api/settings/low-data', {
+    method: 'PUT',
+    body: JSON.stringify({ enabled }),
+  })
+}
+
 export interface SummarizeRequest {
   entryId: string
   content: string
@@ -374,10 +888,20 @@ export interface SummarizeResponse {
   cached: boolean
 }
 
+/** The place of an AI request held back by the rate limit. */
+export interface AIQueueStatus {
+  /** Requests going before this one, itself included. */
+  position: number
+  /** Estimated wait in milliseconds. */
+  waitMs: number
+}
+
+const summaryQueueEvent = 'event: queue\n'
+
 export async function* streamSummary(
   req: SummarizeRequest,
   signal?: AbortSignal
-): AsyncGenerator<string | { cached: true; summary: string }> {
+): AsyncGenerator<string | { cached: true; summary: string } | { queued: AIQueueStatus }> {
   const url = `${API_BASE_URL}/api/ai/summarize`
   const response = await fetch(url, {
     method: 'POST',
@@ -388,12 +912,7 @@ export async function* streamSummary(
 
   if (!response.ok) {
     const data = await parseResponse(response)
-    const message = isErrorResponse(data)
-      ? data.error
-      : typeof data === 'string'
-        ? data
-        : response.statusText
-    throw new ApiError(message || 'Request failed', response.status)
+    throw toApiError(data, response)
   }
 
   const contentType = response.headers.get('Content-Type') ?? ''
@@ -412,6 +931,10 @@ export async function* streamSummary(
 
   const reader = response.body.getReader()
   const decoder = new TextDecoder()
+  // Queue events come before the text; until it starts, hold back what
+  // could still be one
+  let pending = ''
+  let started = false
 
   try {
     while (true) {
@@ -419,10 +942,29 @@ export async function* streamSummary(
       if (done) break
 
       const text = decoder.decode(value, { stream: true })
-      if (text) {
-        yield text
+      if (started) {
+        if (text) yield text
+        continue
+      }
+      pending += text
+      while (pending.startsWith(summaryQueueEvent)) {
+        const end = pending.indexOf('\n\n')
+        if (end < 0) break
+        const data = pending.slice(summaryQueueEvent.length, end).replace(/^data: /, '')
+        pending = pending.slice(end + 2)
+        try {
+          yield { queued: JSON.parse(data) as AIQueueStatus }
+        } catch {
+          // Ignore malformed events
+        }
+      }
+      if (pending && !summaryQueueEvent.startsWith(pending) && !pending.startsWith(summaryQueueEvent)) {
+        started = true
+        yield pending
+        pending = ''
       }
     }
+    if (pending) yield pending
   } finally {
     reader.releaseLock()
   }
@@ -463,7 +1005,12 @@ export interface TranslateError {
   error: string
 }
 
-export type TranslateEvent = TranslateInit | TranslateBlockResult | TranslateDone | TranslateError
+/** Blocks are held back by the AI rate limit. */
+export interface TranslateQueued {
+  queued: AIQueueStatus
+}
+
+export type TranslateEvent = TranslateInit | TranslateBlockResult | TranslateDone | TranslateError | TranslateQueued
 
 function isTranslateInit(event: TranslateEvent): event is TranslateInit {
   return 'blocks' in event && Array.isArray(event.blocks)
@@ -481,6 +1028,10 @@ function isTranslateError(event: TranslateEvent): event is TranslateError {
   return 'error' in event
 }
 
+function isTranslateQueued(event: TranslateEvent): event is TranslateQueued {
+  return 'queued' in event
+}
+
 export async function* streamTranslateBlocks(
   req: TranslateRequest,
   signal?: AbortSignal
@@ -495,12 +1046,7 @@ export async function* streamTranslateBlocks(
 
   if (!response.ok) {
     const data = await parseResponse(response)
-    const message = isErrorResponse(data)
-      ? data.error
-      : typeof data === 'string'
-        ? data
-        : response.statusText
-    throw new ApiError(message || 'Request failed', response.status)
+    throw toApiError(data, response)
   }
 
   const contentType = response.headers.get('Content-Type') ?? ''
@@ -547,7 +1093,7 @@ export async function* streamTranslateBlocks(
 }
 
 // Re-export type guards for use in components
-export { isTranslateInit, isTranslateBlockResult, isTranslateDone, isTranslateError }
+export { isTranslateInit, isTranslateBlockResult, isTranslateDone, isTranslateError, isTranslateQueued }
 
 // Keep the old function for backwards compatibility (returns full content)
 export async function translateContent(
@@ -593,12 +1139,7 @@ export async function* streamBatchTranslate(
 
   if (!response.ok) {
     const data = await parseResponse(response)
-    const message = isErrorResponse(data)
-      ? data.error
-      : typeof data === 'string'
-        ? data
-        : response.statusText
-    throw new ApiError(message || 'Request failed', response.status)
+    throw toApiError(data, response)
   }
 
   if (!response.body) {
@@ -1,10 +1,11 @@
-import { useCallback } from 'react'
+import { useCallback, useState, type DragEvent } from 'react'
 import { useTranslation } from 'react-i18next'
 import { cn } from '@/lib/utils'
 import { useAddFeed } from '@/hooks/useAddFeed'
 import { useFolders } from '@/hooks/useFolders'
 import { FeedUrlForm } from './FeedUrlForm'
 import { FeedPreviewCard } from './FeedPreviewCard'
+import { BulkAddResults } from './BulkAddResults'
 import type { ContentType } from '@/types/api'
 
 interface AddFeedPageProps {
@@ -24,8 +25,11 @@ export function AddFeedPage({ onClose, onFeedAdded, contentType = 'article' }: A
     error,
     discoverFeed,
     subscribeFeed,
+    bulkResults,
+    bulkAdd,
   } = useAddFeed(contentType)
   const { data: folders = [] } = useFolders()
+  const [isDragging, setIsDragging] = useState(false)
 
   const handleSubscribe = useCallback(async (feedUrl: string, options: { folderName?: string; title?: string }) => {
     const success = await subscribeFeed(feedUrl, options)
@@ -35,8 +39,46 @@ export function AddFeedPage({ onClose, onFeedAdded, contentType = 'article' }: A
     }
   }, [subscribeFeed, onFeedAdded, onClose])
 
+  // Dropped links or a dropped text file of URLs are added as a list
+  const handleDragOver = useCallback((e: DragEvent<HTMLDivElement>) => {
+    if (isLoading) return
+    e.preventDefault()
+    setIsDragging(true)
+  }, [isLoading])
+
+  const handleDragLeave = useCallback((e: DragEvent<HTMLDivElement>) => {
+    if (e.currentTarget.contains(e.relatedTarget as Node | null)) return
+    setIsDragging(false)
+  }, [])
+
+  const handleDrop = useCallback(async (e: DragEvent<HTMLDivElement>) => {
+    e.preventDefault()
+    setIsDragging(false)
+    if (isLoading) return
+    const file = e.dataTransfer.files[0]
+    const text = file
+      ? await file.text()
+      : e.dataTransfer.getData('text/uri-list') || e.dataTransfer.getData('text/plain')
+    // text/uri-list marks comments with #
+    const urls = text
+      .split(/\r?\n/)
+      .filter((line) => !line.startsWith('#'))
+      .join('\n')
+    if (urls.trim()) {
+      bulkAdd(urls)
+    }
+  }, [isLoading, bulkAdd])
+
   return (
-    <div className="relative flex h-full flex-col bg-background">
+    <div
+      className={cn(
+        'relative flex h-full flex-col bg-background',
+        isDragging && 'ring-2 ring-inset ring-primary/40'
+      )}
+      onDragOver={handleDragOver}
+      onDragLeave={handleDragLeave}
+      onDrop={handleDrop}
+    >
       {/* Back button - top left */}
       <button
         type="button"
@@ -70,11 +112,15 @@ export function AddFeedPage({ onClose, onFeedAdded, contentType = 'article' }: A
             <p className="mt-2 text-sm text-muted-foreground">
               {t('add_feed.feed_description')}
             </p>
+            <p className="mt-1 text-xs text-muted-foreground/80">
+              {t('add_feed.bulk_hint')}
+            </p>
           </div>
 
           {/* URL Form */}
           <FeedUrlForm
             onSubmit={discoverFeed}
+            onSubmitList={bulkAdd}
             isLoading={isLoading}
           />
 
@@ -85,6 +131,13 @@ export function AddFeedPage({ onClose, onFeedAdded, contentType = 'article' }: A
             </div>
           )}
 
+          {/* Bulk add results */}
+          {bulkResults && (
+            <div className="mt-6">
+              <BulkAddResults results={bulkResults} />
+            </div>
+          )}
+
           {/* Feed Preview */}
           {feedPreview && (
             <div className="mt-6">
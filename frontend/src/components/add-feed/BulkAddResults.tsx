@@ -0,0 +1,52 @@
+import { useTranslation } from 'react-i18next'
+import { cn } from '@/lib/utils'
+import type { BulkAddResult } from '@/types/api'
+
+interface BulkAddResultsProps {
+  results: BulkAddResult[]
+}
+
+const statusStyles: Record<BulkAddResult['status'], string> = {
+  added: 'bg-primary/10 text-primary',
+  exists: 'bg-muted text-muted-foreground',
+  invalid: 'bg-destructive/10 text-destructive',
+  failed: 'bg-destructive/10 text-destructive',
+}
+
+export function BulkAddResults({ results }: BulkAddResultsProps) {
+  const { t } = useTranslation()
+  const added = results.filter((result) => result.status === 'added').length
+
+  return (
+    <div className="rounded-xl border border-border">
+      <div className="border-b border-border px-4 py-3 text-sm font-medium">
+        {t('add_feed.bulk_summary', { added, total: results.length })}
+      </div>
+      <ul className="divide-y divide-border">
+        {results.map((result) => (
+          <li key={result.url} className="flex items-start gap-3 px-4 py-2.5 text-sm">
+            <span
+              className={cn(
+                'mt-0.5 shrink-0 rounded-md px-1.5 py-0.5 text-xs font-medium',
+                statusStyles[result.status]
+              )}
+            >
+              {t(`add_feed.bulk_status_${result.status}`)}
+            </span>
+            <div className="min-w-0 flex-1">
+              <div className="truncate">{result.feed?.title ?? result.url}</div>
+              {result.feed && (
+                <div className="truncate text-xs text-muted-foreground">{result.url}</div>
+              )}
+              {result.error && (
+                <div className="truncate text-xs text-destructive" title={result.error}>
+                  {result.error}
+                </div>
+              )}
+            </div>
+          </li>
+        ))}
+      </ul>
+    </div>
+  )
+}
@@ -1,14 +1,16 @@
-import { useState, useCallback, useRef, type FormEvent, type KeyboardEvent } from 'react'
+import { useState, useCallback, useRef, type ClipboardEvent, type FormEvent, type KeyboardEvent } from 'react'
 import { useTranslation } from 'react-i18next'
 import { cn } from '@/lib/utils'
 import { normalizeUrl } from '@/lib/url'
 
 interface FeedUrlFormProps {
   onSubmit: (url: string) => void
+  /** Called instead of onSubmit when several lines are pasted */
+  onSubmitList?: (urls: string) => void
   isLoading?: boolean
 }
 
-export function FeedUrlForm({ onSubmit, isLoading = false }: FeedUrlFormProps) {
+export function FeedUrlForm({ onSubmit, onSubmitList, isLoading = false }: FeedUrlFormProps) {
   const { t } = useTranslation()
   const [inputValue, setInputValue] = useState('')
   const inputRef = useRef<HTMLInputElement>(null)
@@ -39,6 +41,14 @@ export function FeedUrlForm({ onSubmit, isLoading = false }: FeedUrlFormProps) {
     }
   }, [inputValue, isLoading, onSubmit])
 
+  // A pasted URL list (e.g. from a blogroll) is added as a whole
+  const handlePaste = useCallback((e: ClipboardEvent<HTMLInputElement>) => {
+    const text = e.clipboardData.getData('text/plain')
+    if (!onSubmitList || isLoading || !text.trim().includes('\n')) return
+    e.preventDefault()
+    onSubmitList(text)
+  }, [onSubmitList, isLoading])
+
   const handleClear = useCallback(() => {
     setInputValue('')
     inputRef.current?.focus()
@@ -66,6 +76,7 @@ export function FeedUrlForm({ onSubmit, isLoading = false }: FeedUrlFormProps) {
           value={inputValue}
           onChange={handleInputChange}
           onKeyDown={handleKeyDown}
+          onPaste={handlePaste}
           placeholder="https://example.com/feed.xml"
           disabled={isLoading}
           className={cn(
@@ -1,13 +1,16 @@
 import { useTranslation } from 'react-i18next'
 import { cn } from '@/lib/utils'
+import type { AIQueueStatus } from '@/api'
 
 interface AiSummaryBoxProps {
   content: string | null
   isLoading?: boolean
   error?: string | null
+  /** Set while the AI rate limit holds the request back */
+  queue?: AIQueueStatus | null
 }
 
-export function AiSummaryBox({ content, isLoading, error }: AiSummaryBoxProps) {
+export function AiSummaryBox({ content, isLoading, error, queue }: AiSummaryBoxProps) {
   const { t } = useTranslation()
 
   if (!content && !isLoading && !error) return null
@@ -53,6 +56,11 @@ export function AiSummaryBox({ content, isLoading, error }: AiSummaryBoxProps) {
                 {content?.split('\n').filter(line => line.trim()).map((point, i) => (
                   <p key={i}>{point.trim()}</p>
                 ))}
+                {isLoading && !content && queue && (
+                  <p className="text-xs text-muted-foreground">
+                    {t('entry.ai_queued', { position: queue.position, seconds: Math.ceil(queue.waitMs / 1000) })}
+                  </p>
+                )}
                 {isLoading && !content && (
                   <div className="space-y-2">
                     <div className="h-4 w-full bg-primary/10 rounded animate-pulse" />
@@ -12,6 +12,8 @@ import {
   isTranslateBlockResult,
   isTranslateDone,
   isTranslateError,
+  isTranslateQueued,
+  type AIQueueStatus,
   type TranslateBlockData,
 } from '@/api'
 import { needsTranslation } from '@/lib/language-detect'
@@ -20,6 +22,7 @@ import { useTranslationStore, translationActions } from '@/stores/translation-st
 import { translateArticlesBatch } from '@/services/translation-service'
 import { EntryContentHeader } from './EntryContentHeader'
 import { EntryContentBody } from './EntryContentBody'
+import type { ReadableContentResponse } from '@/types/api'
 
 interface EntryContentProps {
   entryId: string | null
@@ -35,12 +38,14 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
   const { mutate: markAsStarred } = useMarkAsStarred()
   const { scrollRef, isAtTop } = useEntryContentScroll(entryId)
 
-  const autoTranslate = aiSettings?.autoTranslate ?? false
+  // Low-data mode holds back automatic AI requests; manual ones still work
+  const lowData = generalSettings?.lowDataActive ?? false
+  const autoTranslate = (aiSettings?.autoTranslate ?? false) && !lowData
   const targetLanguage = aiSettings?.summaryLanguage ?? 'zh-CN'
   const autoReadability = generalSettings?.autoReadability ?? false
 
   const [isReadableLoading, setIsReadableLoading] = useState(false)
-  const [localReadableContent, setLocalReadableContent] = useState<string | null>(null)
+  const [localReadable, setLocalReadable] = useState<ReadableContentResponse | null>(null)
   const [showReadable, setShowReadable] = useState(false)
   const [readableError, setReadableError] = useState<string | null>(null)
 
@@ -48,6 +53,7 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
   const [aiSummary, setAiSummary] = useState<string | null>(null)
   const [isLoadingSummary, setIsLoadingSummary] = useState(false)
   const [summaryError, setSummaryError] = useState<string | null>(null)
+  const [summaryQueue, setSummaryQueue] = useState<AIQueueStatus | null>(null)
   const summaryAbortRef = useRef<AbortController | null>(null)
   const summaryRequestedRef = useRef(false)
   const prevReadableActiveRef = useRef(false)
@@ -100,12 +106,14 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
     manuallyDisabledRef.current = false
 
     // Reset readability state
-    setLocalReadableContent(null)
+    setLocalReadable(null)
     setShowReadable(false)
     setReadableError(null)
   }, [entryId])
 
-  const readableContent = localReadableContent || entry?.readableContent
+  const readableContent = localReadable?.readableContent || entry?.readableContent
+  // What extraction learned about the page: its language, direction and attribution
+  const readableMeta = localReadable ?? entry
   const hasReadableContent = !!readableContent
 
   const handleToggleReadable = useCallback(async () => {
@@ -120,8 +128,8 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
     setIsReadableLoading(true)
     setReadableError(null)
     try {
-      const content = await fetchReadableContent(entry.id)
-      setLocalReadableContent(content)
+      const readable = await fetchReadableContent(entry.id)
+      setLocalReadable(readable)
       setShowReadable(true)
     } catch (err) {
       const message = err instanceof Error ? err.message : 'Failed to fetch readable content'
@@ -151,8 +159,8 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
       setIsReadableLoading(true)
       setReadableError(null)
       fetchReadableContent(entry.id)
-        .then((content) => {
-          setLocalReadableContent(content)
+        .then((readable) => {
+          setLocalReadable(readable)
           setShowReadable(true)
         })
         .catch((err) => {
@@ -190,6 +198,7 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
     setIsLoadingSummary(true)
     setSummaryError(null)
     setAiSummary(null)
+    setSummaryQueue(null)
     summaryRequestedRef.current = true
 
     const abortController = new AbortController()
@@ -210,8 +219,12 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
         if (typeof chunk === 'object' && 'cached' in chunk) {
           // Cached response
           setAiSummary(chunk.summary)
+        } else if (typeof chunk === 'object') {
+          // Held back by the AI rate limit
+          setSummaryQueue(chunk.queued)
         } else {
           // Streaming response
+          setSummaryQueue(null)
           setAiSummary(prev => (prev ?? '') + chunk)
         }
       }
@@ -221,6 +234,7 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
         return
       }
       const message = err instanceof Error ? err.message : 'Failed to generate summary'
+      setSummaryQueue(null)
       setSummaryError(message)
       setIsLoadingSummary(false)
       summaryAbortRef.current = null
@@ -228,6 +242,7 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
     }
 
     // Only update state if this request wasn't aborted
+    setSummaryQueue(null)
     setIsLoadingSummary(false)
     summaryAbortRef.current = null
   }, [entry, readableContent])
@@ -270,7 +285,7 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
   }, [isReadableActive, aiSummary, isLoadingSummary, generateSummary])
 
   // Auto-generate summary when entry is selected
-  const autoSummary = aiSettings?.autoSummary ?? false
+  const autoSummary = (aiSettings?.autoSummary ?? false) && !lowData
   useEffect(() => {
     if (!autoSummary || !entry || isLoadingSummary) return
     // Skip if user manually disabled summary for this entry
@@ -328,6 +343,11 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
         // SSE events
         const sseEvent = event
 
+        // Held back by the AI rate limit; blocks follow once it lets through
+        if (isTranslateQueued(sseEvent)) {
+          continue
+        }
+
         // Init event with all original blocks
         if (isTranslateInit(sseEvent)) {
           setOriginalBlocks(sseEvent.blocks)
@@ -550,9 +570,11 @@ export function EntryContent({ entryId, isMobile, onBack }: EntryContentProps) {
         displayTitle={displayTitle}
         scrollRef={scrollRef}
         displayContent={combinedTranslatedContent ?? baseContent}
+        readableMeta={isReadableActive && !combinedTranslatedContent ? readableMeta : undefined}
         aiSummary={aiSummary}
         isLoadingSummary={isLoadingSummary}
         summaryError={summaryError}
+        summaryQueue={summaryQueue}
       />
     </div>
   )
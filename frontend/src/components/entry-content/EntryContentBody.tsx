@@ -1,21 +1,32 @@
 import type { RefCallback } from 'react'
 import { useRef } from 'react'
+import { useTranslation } from 'react-i18next'
 import { useCodeHighlight } from '@/hooks/useCodeHighlight'
 import { useEntryMeta } from '@/hooks/useEntryMeta'
 import { ScrollArea } from '@/components/ui/scroll-area'
 import { isSafeUrl } from '@/lib/url'
 import { ArticleContent } from '@/components/ui/article-content'
 import { AiSummaryBox } from './AiSummaryBox'
-import type { Entry } from '@/types/api'
+import type { AIQueueStatus } from '@/api'
+import type { Entry, EntryAttachment, ReadableContentResponse } from '@/types/api'
+
+type ReadableMeta = Pick<
+  ReadableContentResponse,
+  'readableLang' | 'readableDir' | 'readableByline' | 'readableSiteName'
+>
 
 interface EntryContentBodyProps {
   entry: Entry
   displayTitle?: string | null
   scrollRef: RefCallback<HTMLDivElement>
   displayContent: string | null | undefined
+  /** Set while the readable version is shown */
+  readableMeta?: ReadableMeta
   aiSummary?: string | null
   isLoadingSummary?: boolean
   summaryError?: string | null
+  /** Set while the AI rate limit holds the summary back */
+  summaryQueue?: AIQueueStatus | null
 }
 
 export function EntryContentBody({
@@ -23,11 +34,14 @@ export function EntryContentBody({
   displayTitle,
   scrollRef,
   displayContent,
+  readableMeta,
   aiSummary,
   isLoadingSummary,
   summaryError,
+  summaryQueue,
 }: EntryContentBodyProps) {
-  const { publishedLong, readingTime } = useEntryMeta(entry)
+  const { t } = useTranslation()
+  const { publishedLong, originalPublishedLong, revisedLong, readingTime } = useEntryMeta(entry)
   const title = displayTitle ?? entry.title ?? 'Untitled'
   const contentRef = useRef<HTMLDivElement>(null)
 
@@ -35,6 +49,8 @@ export function EntryContentBody({
   useCodeHighlight(contentRef, displayContent ?? '')
 
   const hasContent = displayContent && displayContent.trim().length > 0
+  const author = entry.author ?? readableMeta?.readableByline
+  const siteName = readableMeta?.readableSiteName
 
   return (
     <ScrollArea
@@ -60,7 +76,7 @@ export function EntryContentBody({
           </h1>
 
           <div className="flex flex-wrap items-center gap-x-6 gap-y-3 text-sm text-muted-foreground">
-            {entry.author && (
+            {author && (
               <div className="flex items-center gap-1.5">
                 <svg
                   className="size-4 opacity-70"
@@ -75,7 +91,26 @@ export function EntryContentBody({
                     d="M16 7a4 4 0 11-8 0 4 4 0 018 0zM12 14a7 7 0 00-7 7h14a7 7 0 00-7-7z"
                   />
                 </svg>
-                <span>{entry.author}</span>
+                <span>{author}</span>
+              </div>
+            )}
+
+            {siteName && (
+              <div className="flex items-center gap-1.5">
+                <svg
+                  className="size-4 opacity-70"
+                  fill="none"
+                  stroke="currentColor"
+                  viewBox="0 0 24 24"
+                >
+                  <path
+                    strokeLinecap="round"
+                    strokeLinejoin="round"
+                    strokeWidth={2}
+                    d="M21 12a9 9 0 01-9 9m9-9a9 9 0 00-9-9m9 9H3m9 9a9 9 0 01-9-9m9 9c1.657 0 3-4.03 3-9s-1.343-9-3-9m0 18c-1.657 0-3-4.03-3-9s1.343-9 3-9m-9 9a9 9 0 019-9"
+                  />
+                </svg>
+                <span>{siteName}</span>
               </div>
             )}
 
@@ -94,7 +129,12 @@ export function EntryContentBody({
                     d="M8 7V3m8 4V3m-9 8h10M5 21h14a2 2 0 002-2V7a2 2 0 00-2-2H5a2 2 0 00-2 2v12a2 2 0 002 2z"
                   />
                 </svg>
-                <span className="tabular-nums">{publishedLong}</span>
+                <span
+                  className="tabular-nums"
+                  title={originalPublishedLong ? t('entry.original_published', { date: originalPublishedLong }) : undefined}
+                >
+                  {publishedLong}
+                </span>
               </div>
             )}
 
@@ -117,6 +157,47 @@ export function EntryContentBody({
               </div>
             )}
           </div>
+          {entry.externalUrl && isSafeUrl(entry.externalUrl) && (
+            <p className="text-sm text-muted-foreground">
+              {t('entry.external_link')}{' '}
+              <a
+                href={entry.externalUrl}
+                target="_blank"
+                rel="noopener noreferrer"
+                className="break-all text-foreground underline underline-offset-2 hover:opacity-80"
+              >
+                {entry.externalUrl}
+              </a>
+            </p>
+          )}
+          {entry.linkDead && (
+            <p className="rounded-md border border-border bg-muted/50 px-3 py-2 text-sm text-muted-foreground">
+              {t('entry.link_dead')}
+            </p>
+          )}
+          {entry.revision && (
+            <details className="rounded-md border border-border bg-muted/50 px-3 py-2 text-sm text-muted-foreground">
+              <summary className="cursor-pointer">
+                {t('entry.revised', {
+                  date: revisedLong ?? '',
+                  added: entry.revision.wordsAdded,
+                  removed: entry.revision.wordsRemoved,
+                })}
+              </summary>
+              <div className="mt-2 space-y-1.5">
+                {entry.revision.titleBefore && (
+                  <p>{t('entry.revised_title', { title: entry.revision.titleBefore })}</p>
+                )}
+                {entry.revision.changes.map((change, index) => (
+                  <p key={index}>
+                    {change.removed && <del className="text-destructive">{change.removed}</del>}
+                    {change.removed && change.added && ' → '}
+                    {change.added && <ins className="text-foreground no-underline">{change.added}</ins>}
+                  </p>
+                ))}
+              </div>
+            </details>
+          )}
           <hr className="border-border/60" />
         </header>
 
@@ -124,9 +205,15 @@ export function EntryContentBody({
           content={aiSummary ?? null}
           isLoading={isLoadingSummary}
           error={summaryError}
+          queue={summaryQueue}
         />
 
-        <div ref={contentRef} className="entry-content-body">
+        <div
+          ref={contentRef}
+          className="entry-content-body"
+          lang={readableMeta?.readableLang}
+          dir={readableMeta?.readableDir}
+        >
           {hasContent ? (
             <ArticleContent content={displayContent} articleUrl={entry.url} />
           ) : (
@@ -135,7 +222,52 @@ export function EntryContentBody({
             </div>
           )}
         </div>
+
+        {entry.attachments && entry.attachments.length > 0 && (
+          <section className="mt-10 space-y-3">
+            <h2 className="text-sm font-medium text-muted-foreground">{t('entry.attachments')}</h2>
+            {entry.attachments.filter((attachment) => isSafeUrl(attachment.url)).map((attachment) => (
+              <div key={attachment.url} className="space-y-2 rounded-md border border-border px-3 py-2 text-sm">
+                {attachment.mimeType?.startsWith('audio/') && (
+                  <audio controls preload="none" src={attachment.url} className="w-full" />
+                )}
+                {attachment.mimeType?.startsWith('video/') && (
+                  <video controls preload="none" src={attachment.url} className="w-full" />
+                )}
+                <div className="flex flex-wrap items-baseline gap-x-3">
+                  <a
+                    href={attachment.url}
+                    target="_blank"
+                    rel="noopener noreferrer"
+                    className="break-all text-foreground underline underline-offset-2 hover:opacity-80"
+                  >
+                    {attachment.title || attachment.url.split('/').pop() || attachment.url}
+                  </a>
+                  <span className="tabular-nums text-muted-foreground">{attachmentDetails(attachment)}</span>
+                </div>
+              </div>
+            ))}
+          </section>
+        )}
       </article>
     </ScrollArea>
   )
 }
+
+// attachmentDetails describes an attachment's type, size and duration.
+function attachmentDetails(attachment: EntryAttachment): string {
+  const parts: string[] = []
+  if (attachment.mimeType) parts.push(attachment.mimeType)
+  if (attachment.sizeBytes) {
+    const mb = attachment.sizeBytes / (1024 * 1024)
+    parts.push(mb >= 1 ? `${mb.toFixed(1)} MB` : `${Math.max(1, Math.round(attachment.sizeBytes / 1024))} KB`)
+  }
+  if (attachment.durationSeconds) {
+    const h = Math.floor(attachment.durationSeconds / 3600)
+    const m = Math.floor((attachment.durationSeconds % 3600) / 60)
+    const s = attachment.durationSeconds % 60
+    const mmss = `${String(m).padStart(h > 0 ? 2 : 1, '0')}:${String(s).padStart(2, '0')}`
+    parts.push(h > 0 ? `${h}:${mmss}` : mmss)
+  }
+  return parts.join(' · ')
+}
@@ -1,4 +1,5 @@
 import { useTranslation } from 'react-i18next'
+import { entryPrintUrl } from '@/api'
 import { isSafeUrl } from '@/lib/url'
 import { cn } from '@/lib/utils'
 import type { Entry } from '@/types/api'
@@ -226,6 +227,29 @@ export function EntryContentHeader({
             </button>
           )}
 
+          <a
+            href={entryPrintUrl(entry.id)}
+            target="_blank"
+            rel="noopener noreferrer"
+            className="no-drag-region flex size-9 items-center justify-center rounded-lg text-muted-foreground transition-colors hover:bg-accent hover:text-foreground"
+            aria-label={t('entry.print_view')}
+            title={t('entry.print_view')}
+          >
+            <svg
+              className="size-5"
+              fill="none"
+              stroke="currentColor"
+              strokeWidth={2}
+              viewBox="0 0 24 24"
+            >
+              <path
+                strokeLinecap="round"
+                strokeLinejoin="round"
+                d="M17 17h2a2 2 0 002-2v-4a2 2 0 00-2-2H5a2 2 0 00-2 2v4a2 2 0 002 2h2m2 4h6a2 2 0 002-2v-4a2 2 0 00-2-2H9a2 2 0 00-2 2v4a2 2 0 002 2zm8-12V5a2 2 0 00-2-2H9a2 2 0 00-2 2v4h10z"
+              />
+            </svg>
+          </a>
+
           {safeUrl && (
             <a
               href={safeUrl}
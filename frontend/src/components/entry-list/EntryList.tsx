@@ -5,15 +5,15 @@ import { useEntriesInfinite, useUnreadCounts } from '@/hooks/useEntries'
 import { useFeeds } from '@/hooks/useFeeds'
 import { useFolders } from '@/hooks/useFolders'
 import { useAISettings } from '@/hooks/useAISettings'
-import { selectionToParams, type SelectionType } from '@/hooks/useSelection'
-import { stripHtml } from '@/lib/html-utils'
+import { useGeneralSettings } from '@/hooks/useGeneralSettings'
+import { selectionToParams, unreadCountsScope, type SelectionType } from '@/hooks/useSelection'
 import { ScrollArea } from '@/components/ui/scroll-area'
 import { EntryListItem } from './EntryListItem'
 import { EntryListHeader } from './EntryListHeader'
 import { needsTranslation } from '@/lib/language-detect'
 import { translateArticlesBatch, cancelAllBatchTranslations } from '@/services/translation-service'
 import { translationActions } from '@/stores/translation-store'
-import type { Entry, Feed, Folder, ContentType } from '@/types/api'
+import type { EntrySummary, Feed, Folder, ContentType } from '@/types/api'
 
 interface EntryListProps {
   selection: SelectionType
@@ -47,16 +47,18 @@ export function EntryList({
   const { data: feeds = [] } = useFeeds()
   const { data: folders = [] } = useFolders()
   const { data: aiSettings } = useAISettings()
-  const { data: unreadCounts } = useUnreadCounts()
+  const { data: generalSettings } = useGeneralSettings()
+  const { data: unreadCounts } = useUnreadCounts(unreadCountsScope(selection, contentType))
+  const nsfwMode = generalSettings?.nsfwMode ?? 'show'
   const { data, fetchNextPage, hasNextPage, isFetchingNextPage, isLoading } =
-    useEntriesInfinite({ ...params, unreadOnly })
+    useEntriesInfinite({ ...params, unreadOnly, excludeNsfw: nsfwMode === 'hide' })
 
   // Track translated entries to avoid re-translating
   const translatedEntries = useRef(new Set<string>())
-  const pendingTranslation = useRef(new Map<string, Entry>())
+  const pendingTranslation = useRef(new Map<string, EntrySummary>())
   const debounceTimer = useRef<ReturnType<typeof setTimeout> | null>(null)
 
-  const autoTranslate = aiSettings?.autoTranslate ?? false
+  const autoTranslate = (aiSettings?.autoTranslate ?? false) && !(generalSettings?.lowDataActive ?? false)
   const targetLanguage = aiSettings?.summaryLanguage ?? 'zh-CN'
 
   // Cancel pending translations and reset state when list changes
@@ -117,7 +119,7 @@ export function EntryList({
       .map((entry) => ({
         id: entry.id,
         title: entry.title || '',
-        summary: entry.content ? stripHtml(entry.content).slice(0, 200) : null,
+        summary: entry.snippet ?? null,
       }))
 
     // Mark as translated to prevent re-translating
@@ -139,14 +141,14 @@ export function EntryList({
 
   // Schedule entry for translation when visible
   const scheduleTranslation = useCallback(
-    (entry: Entry) => {
+    (entry: EntrySummary) => {
       if (!autoTranslate) return
       if (translatedEntries.current.has(entry.id)) return
       // Skip if user manually disabled translation for this article
       if (translationActions.isDisabled(entry.id)) return
 
       // Check if needs translation
-      const summary = entry.content ? stripHtml(entry.content).slice(0, 200) : null
+      const summary = entry.snippet ?? null
       if (!needsTranslation(entry.title || '', summary, targetLanguage)) {
         translatedEntries.current.add(entry.id)
         return
@@ -184,6 +186,8 @@ export function EntryList({
             return t('entry_list.all_pictures')
           case 'notification':
             return t('entry_list.all_notifications')
+          case 'podcast':
+            return t('entry_list.all_podcasts')
           default:
             return t('entry_list.all_articles')
         }
@@ -202,21 +206,15 @@ export function EntryList({
     const counts = unreadCounts.counts
     switch (selection.type) {
       case 'all':
-        // Sum all feeds' unread counts, filtered by contentType
-        return feeds
-          .filter((f) => f.type === contentType)
-          .reduce((sum, f) => sum + (counts[f.id] ?? 0), 0)
+      case 'folder':
+        // Counts are already scoped to the content type or folder subtree
+        return Object.values(counts).reduce((sum, count) => sum + count, 0)
       case 'feed':
         return counts[selection.feedId] ?? 0
-      case 'folder':
-        // Sum unread counts for feeds in this folder with matching contentType
-        return feeds
-          .filter((f) => f.folderId === selection.folderId && f.type === contentType)
-          .reduce((sum, f) => sum + (counts[f.id] ?? 0), 0)
       case 'starred':
         return 0 // Starred view doesn't show unread count
     }
-  }, [unreadCounts, selection, feeds, contentType])
+  }, [unreadCounts, selection])
 
   return (
     <div className="flex h-full flex-col">
@@ -253,6 +251,7 @@ export function EntryList({
                   onClick={() => onSelectEntry(entry.id)}
                   autoTranslate={autoTranslate}
                   targetLanguage={targetLanguage}
+                  blurNsfw={nsfwMode === 'blur'}
                   style={{
                     position: 'absolute',
                     top: 0,
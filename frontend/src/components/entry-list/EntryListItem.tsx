@@ -1,19 +1,20 @@
-import { forwardRef, useState, useMemo } from 'react'
+import { forwardRef, useState } from 'react'
 import { useTranslation } from 'react-i18next'
 import { cn } from '@/lib/utils'
 import { formatRelativeTime } from '@/lib/date-utils'
-import { stripHtml } from '@/lib/html-utils'
 import { useTranslationStore } from '@/stores/translation-store'
 import { FeedIcon } from '@/components/ui/feed-icon'
-import type { Entry, Feed } from '@/types/api'
+import type { EntrySummary, Feed } from '@/types/api'
 
 interface EntryListItemProps {
-  entry: Entry
+  entry: EntrySummary
   feed?: Feed
   isSelected: boolean
   onClick: () => void
   autoTranslate?: boolean
   targetLanguage?: string
+  /** Blur the snippet of entries flagged not safe for work */
+  blurNsfw?: boolean
   style?: React.CSSProperties
   'data-index'?: number
 }
@@ -27,6 +28,7 @@ export const EntryListItem = forwardRef<HTMLDivElement, EntryListItemProps>(
       onClick,
       autoTranslate,
       targetLanguage,
+      blurNsfw,
       style,
       'data-index': dataIndex,
     },
@@ -35,7 +37,11 @@ export const EntryListItem = forwardRef<HTMLDivElement, EntryListItemProps>(
     const { t } = useTranslation()
     const publishedAt = entry.publishedAt ? formatRelativeTime(entry.publishedAt, t) : null
     const [iconError, setIconError] = useState(false)
-    const showIcon = feed?.iconPath && !iconError
+    const [iconLoaded, setIconLoaded] = useState(false)
+    // Saved pages carry their own site icon
+    const iconPath = entry.iconPath ?? feed?.iconPath
+    const iconColor = entry.iconPath ? undefined : feed?.iconColor
+    const showIcon = iconPath && !iconError
 
     // Get translation from store
     const translation = useTranslationStore((state) =>
@@ -44,15 +50,10 @@ export const EntryListItem = forwardRef<HTMLDivElement, EntryListItemProps>(
         : undefined
     )
 
-    // Cache stripped HTML to avoid DOMParser on every render
-    const strippedContent = useMemo(
-      () => (entry.content ? stripHtml(entry.content).slice(0, 150) : null),
-      [entry.content]
-    )
-
     // Use translated content if available
     const displayTitle = translation?.title ?? entry.title
-    const displaySummary = translation?.summary ?? strippedContent
+    const displaySummary = translation?.summary ?? entry.snippet ?? null
+    const blurred = blurNsfw && !!entry.nsfwReason
 
     return (
       <div
@@ -71,9 +72,11 @@ export const EntryListItem = forwardRef<HTMLDivElement, EntryListItemProps>(
         <div className="flex items-center gap-1.5 text-xs text-muted-foreground">
           {showIcon ? (
             <img
-              src={`/icons/${feed.iconPath}`}
+              src={`/icons/${iconPath}`}
               alt=""
               className="size-4 shrink-0 rounded object-contain"
+              style={iconLoaded ? undefined : { backgroundColor: iconColor }}
+              onLoad={() => setIconLoaded(true)}
               onError={() => setIconError(true)}
             />
           ) : (
@@ -95,12 +98,22 @@ export const EntryListItem = forwardRef<HTMLDivElement, EntryListItemProps>(
             !entry.read ? 'font-semibold' : 'font-medium text-muted-foreground'
           )}
         >
+          {blurred && (
+            <span className="mr-1.5 rounded bg-destructive/10 px-1 py-0.5 align-middle text-[10px] font-medium text-destructive">
+              {t('entry.nsfw')}
+            </span>
+          )}
           {displayTitle || 'Untitled'}
         </div>
 
         {/* Line 3: summary */}
         {displaySummary && (
-          <div className="mt-1 text-xs text-muted-foreground line-clamp-2">
+          <div
+            className={cn(
+              'mt-1 text-xs text-muted-foreground line-clamp-2',
+              blurred && 'blur-sm select-none'
+            )}
+          >
             {displaySummary}
           </div>
         )}
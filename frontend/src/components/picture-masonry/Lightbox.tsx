@@ -6,7 +6,6 @@ import { Play } from 'lucide-react'
 import { cn } from '@/lib/utils'
 import { isVideoThumbnail } from '@/lib/media-utils'
 import { formatRelativeTime } from '@/lib/date-utils'
-import { stripHtml } from '@/lib/html-utils'
 import { useLightboxStore } from '@/stores/lightbox-store'
 import { FeedIcon } from '@/components/ui/feed-icon'
 
@@ -85,8 +84,7 @@ export function Lightbox() {
 
   const publishedAt = entry?.publishedAt ? formatRelativeTime(entry.publishedAt, t) : null
 
-  // Strip HTML for content preview
-  const contentPreview = entry?.content ? stripHtml(entry.content).slice(0, 200) : null
+  const contentPreview = entry?.snippet ?? null
 
   return (
     <AnimatePresence>
@@ -1,11 +1,13 @@
 import { memo, useState, useCallback } from 'react'
 import { useTranslation } from 'react-i18next'
-import { Play } from 'lucide-react'
+import { EyeOff, Play } from 'lucide-react'
+import { useQueryClient } from '@tanstack/react-query'
 import { cn } from '@/lib/utils'
 import { getEntryImages } from '@/lib/extract-images'
-import { getProxiedImageUrl } from '@/lib/image-proxy'
+import { getProxiedImageUrl, getProxiedThumbnailUrl } from '@/lib/image-proxy'
 import { isVideoThumbnail } from '@/lib/media-utils'
 import { formatRelativeTime } from '@/lib/date-utils'
+import { getEntry } from '@/api'
 import { useMarkAsRead } from '@/hooks/useEntries'
 import { useLightboxStore } from '@/stores/lightbox-store'
 import {
@@ -13,11 +15,13 @@ import {
   useImageDimensionsStore,
 } from '@/stores/image-dimensions-store'
 import { FeedIcon } from '@/components/ui/feed-icon'
-import type { Entry, Feed } from '@/types/api'
+import type { Entry, EntrySummary, Feed } from '@/types/api'
 
 interface PictureItemProps {
-  entry: Entry
+  entry: EntrySummary
   feed?: Feed
+  /** Blur the image until clicked; the entry is flagged not safe for work */
+  blurNsfw?: boolean
 }
 
 // Default 3:4 vertical aspect ratio for uncached images
@@ -27,11 +31,13 @@ const FOOTER_HEIGHT = 40
 export const PictureItem = memo(function PictureItem({
   entry,
   feed,
+  blurNsfw = false,
 }: PictureItemProps) {
   const { t } = useTranslation()
   const openLightbox = useLightboxStore((state) => state.open)
   const setDimension = useImageDimensionsStore((state) => state.setDimension)
   const { mutate: markAsRead } = useMarkAsRead()
+  const queryClient = useQueryClient()
 
   // Get cached dimension from store
   const thumbnailUrl = entry.thumbnailUrl
@@ -42,6 +48,8 @@ export const PictureItem = memo(function PictureItem({
   const [imageLoaded, setImageLoaded] = useState(false)
   const [imageError, setImageError] = useState(false)
   const [iconError, setIconError] = useState(false)
+  const [revealed, setRevealed] = useState(false)
+  const blurred = blurNsfw && !revealed
 
   const showIcon = feed?.iconPath && !iconError
 
@@ -57,18 +65,39 @@ export const PictureItem = memo(function PictureItem({
     [thumbnailUrl, setDimension]
   )
 
-  const handleClick = useCallback(() => {
+  const handleClick = useCallback(async () => {
+    // The first click only reveals a blurred image
+    if (blurred) {
+      setRevealed(true)
+      return
+    }
+
     // Mark as read
     if (!entry.read) {
       markAsRead({ id: entry.id, read: true })
     }
 
-    // Open lightbox (for both image and video)
-    const images = getEntryImages(entry.thumbnailUrl, entry.content, entry.url ?? undefined)
+    // List entries carry no gallery; load the entry to find the rest of the images
+    let full: Entry | undefined
+    try {
+      full = await queryClient.fetchQuery({
+        queryKey: ['entry', entry.id],
+        queryFn: () => getEntry(entry.id),
+      })
+    } catch {
+      // Fall back to the thumbnail alone
+    }
+
+    // Open lightbox (for both image and video). Entries saved before the
+    // feed became a picture feed have no gallery; take images from content.
+    const articleUrl = entry.url ?? undefined
+    const images = full?.images?.length
+      ? full.images.map((url) => getProxiedImageUrl(url, articleUrl))
+      : getEntryImages(entry.thumbnailUrl, full?.content, articleUrl)
     if (images.length > 0) {
       openLightbox(entry, feed, images, 0)
     }
-  }, [entry, feed, openLightbox, markAsRead])
+  }, [blurred, entry, feed, openLightbox, markAsRead, queryClient])
 
   const publishedAt = entry.publishedAt ? formatRelativeTime(entry.publishedAt, t) : null
 
@@ -88,11 +117,22 @@ export const PictureItem = memo(function PictureItem({
           style={{ aspectRatio }}
         >
           <img
-            src={getProxiedImageUrl(thumbnailUrl, entry.url ?? undefined)}
+            src={
+              entry.thumbnailProxyUrl
+                ? `${entry.thumbnailProxyUrl}?w=320`
+                : getProxiedThumbnailUrl(thumbnailUrl, entry.url ?? undefined)
+            }
+            srcSet={
+              entry.thumbnailProxyUrl
+                ? `${entry.thumbnailProxyUrl}?w=320 320w, ${entry.thumbnailProxyUrl}?w=640 640w`
+                : undefined
+            }
+            sizes="(max-width: 768px) 50vw, 256px"
             alt={entry.title || ''}
             className={cn(
               'size-full object-cover transition-opacity duration-300',
-              imageLoaded ? 'opacity-100' : 'opacity-0'
+              imageLoaded ? 'opacity-100' : 'opacity-0',
+              blurred && 'scale-110 blur-2xl'
             )}
             loading="lazy"
             onLoad={handleImageLoad}
@@ -103,8 +143,14 @@ export const PictureItem = memo(function PictureItem({
               <div className="size-6 animate-spin rounded-full border-2 border-muted-foreground/20 border-t-muted-foreground/60" />
             </div>
           )}
+          {blurred && imageLoaded && (
+            <div className="absolute inset-0 flex flex-col items-center justify-center gap-1.5 text-xs font-medium text-white drop-shadow">
+              <EyeOff className="size-6" />
+              <span>{t('entry.nsfw_reveal')}</span>
+            </div>
+          )}
           {/* Video play icon overlay */}
-          {isVideo && imageLoaded && (
+          {isVideo && imageLoaded && !blurred && (
             <div className="absolute inset-0 flex items-center justify-center">
               <Play className="size-12 fill-white text-white drop-shadow-lg" />
             </div>
@@ -4,12 +4,13 @@ import { VirtuosoMasonry } from '@virtuoso.dev/masonry'
 import { useEntriesInfinite, useUnreadCounts } from '@/hooks/useEntries'
 import { useFeeds } from '@/hooks/useFeeds'
 import { useFolders } from '@/hooks/useFolders'
+import { useGeneralSettings } from '@/hooks/useGeneralSettings'
 import { useMasonryColumn } from '@/hooks/useMasonryColumn'
-import { selectionToParams, type SelectionType } from '@/hooks/useSelection'
+import { selectionToParams, unreadCountsScope, type SelectionType } from '@/hooks/useSelection'
 import { useImageDimensionsStore } from '@/stores/image-dimensions-store'
 import { PictureItem } from './PictureItem'
 import { EntryListHeader } from '@/components/entry-list/EntryListHeader'
-import type { ContentType, Entry, Feed } from '@/types/api'
+import type { ContentType, EntrySummary, Feed } from '@/types/api'
 
 interface PictureMasonryProps {
   selection: SelectionType
@@ -22,8 +23,9 @@ interface PictureMasonryProps {
 }
 
 interface MasonryItem {
-  entry: Entry
+  entry: EntrySummary
   feed?: Feed
+  blurNsfw: boolean
 }
 
 interface MasonryContext {
@@ -48,11 +50,13 @@ export function PictureMasonry({
 
   const { data: feeds = [] } = useFeeds()
   const { data: folders = [] } = useFolders()
-  const { data: unreadCounts } = useUnreadCounts()
+  const { data: unreadCounts } = useUnreadCounts(unreadCountsScope(selection, contentType))
+  const { data: generalSettings } = useGeneralSettings()
   const { data, fetchNextPage, hasNextPage, isFetchingNextPage, isLoading } = useEntriesInfinite({
     ...params,
     unreadOnly,
     hasThumbnail: true,
+    excludeNsfw: generalSettings?.nsfwMode === 'hide',
   })
 
   const feedsMap = useMemo(() => {
@@ -85,12 +89,14 @@ export function PictureMasonry({
     }
   }, [entries, loadFromDB])
 
+  const blurNsfw = generalSettings?.nsfwMode === 'blur'
   const items: MasonryItem[] = useMemo(() => {
     return entries.map((entry) => ({
       entry,
       feed: feedsMap.get(entry.feedId),
+      blurNsfw: blurNsfw && !!entry.nsfwReason,
     }))
-  }, [entries, feedsMap])
+  }, [entries, feedsMap, blurNsfw])
 
   const context: MasonryContext = useMemo(
     () => ({ feedsMap }),
@@ -171,23 +177,18 @@ export function PictureMasonry({
     const counts = unreadCounts.counts
     switch (selection.type) {
       case 'all':
-        return feeds
-          .filter((f) => f.type === contentType)
-          .reduce((sum, f) => sum + (counts[f.id] ?? 0), 0)
+      case 'folder':
+        return Object.values(counts).reduce((sum, count) => sum + count, 0)
       case 'feed':
         return counts[selection.feedId] ?? 0
-      case 'folder':
-        return feeds
-          .filter((f) => f.folderId === selection.folderId && f.type === contentType)
-          .reduce((sum, f) => sum + (counts[f.id] ?? 0), 0)
       case 'starred':
         return 0
     }
-  }, [unreadCounts, selection, feeds, contentType])
+  }, [unreadCounts, selection])
 
   const ItemContent = useCallback(
     ({ data: item }: { data: MasonryItem; context: MasonryContext }) => {
-      return <PictureItem entry={item.entry} feed={item.feed} />
+      return <PictureItem entry={item.entry} feed={item.feed} blurNsfw={item.blurNsfw} />
     },
     []
   )
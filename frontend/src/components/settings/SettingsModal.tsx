@@ -11,10 +11,11 @@ import { AppearanceSettings } from './tabs/AppearanceSettings'
 import { DataControl } from './tabs/DataControl'
 import { FeedsSettings } from './tabs/FeedsSettings'
 import { FoldersSettings } from './tabs/FoldersSettings'
+import { FiltersSettings } from './tabs/FiltersSettings'
 import { AISettings } from './tabs/AISettings'
 import { cn } from '@/lib/utils'
 
-export type SettingsTab = 'general' | 'appearance' | 'ai' | 'data' | 'feeds' | 'folders'
+export type SettingsTab = 'general' | 'appearance' | 'ai' | 'data' | 'feeds' | 'folders' | 'filters'
 
 interface SettingsModalProps {
   open: boolean
@@ -56,6 +57,8 @@ export function SettingsModal({ open, onOpenChange }: SettingsModalProps) {
         return <FeedsSettings />
       case 'folders':
         return <FoldersSettings />
+      case 'filters':
+        return <FiltersSettings />
       default:
         return null
     }
@@ -75,6 +78,8 @@ export function SettingsModal({ open, onOpenChange }: SettingsModalProps) {
         return t('settings.subscriptions')
       case 'folders':
         return t('settings.folders')
+      case 'filters':
+        return t('settings.filters')
       default:
         return t('settings.title')
     }
@@ -87,6 +92,7 @@ export function SettingsModal({ open, onOpenChange }: SettingsModalProps) {
     { id: 'data', label: t('settings.data') },
     { id: 'feeds', label: t('settings.subscriptions') },
     { id: 'folders', label: t('settings.folders') },
+    { id: 'filters', label: t('settings.filters') },
   ]
 
   // Mobile layout
@@ -107,6 +107,20 @@ export function SettingsSidebar({ activeTab, onTabChange }: SettingsSidebarProps
         </svg>
       ),
     },
+    {
+      id: 'filters',
+      label: t('settings.filters'),
+      icon: (
+        <svg className="size-[18px]" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+          <path
+            strokeLinecap="round"
+            strokeLinejoin="round"
+            strokeWidth={1.5}
+            d="M3 4.5h18l-7 8.25V19.5l-4-2v-4.75L3 4.5z"
+          />
+        </svg>
+      ),
+    },
   ]
 
   return (
@@ -68,7 +68,7 @@ export function DataControl() {
   }
 
   const handleCancel = async () => {
-    await cancelImportOPML()
+    await cancelImportOPML(task?.id)
   }
 
   const handleExport = () => {
@@ -175,8 +175,8 @@ export function DataControl() {
                 </button>
               </div>
               <div className="text-xs text-muted-foreground">
-                {task.feed
-                  ? `${task.feed} (${task.current}/${task.total})`
+                {task.detail
+                  ? `${task.detail} (${task.current}/${task.total})`
                   : `${task.current}/${task.total}`}
               </div>
             </div>
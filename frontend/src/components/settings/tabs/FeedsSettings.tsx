@@ -345,7 +345,14 @@ export function FeedsSettings() {
                     <td className="px-3 py-2 text-muted-foreground">
                       {formatDate(feed.createdAt)}
                     </td>
-                    <td className="px-3 py-2 text-muted-foreground">
+                    <td
+                      className="px-3 py-2 text-muted-foreground"
+                      title={
+                        feed.nextRefreshAt
+                          ? t('feeds.next_refresh', { time: formatDateTime(feed.nextRefreshAt) })
+                          : undefined
+                      }
+                    >
                       {formatDateTime(feed.updatedAt)}
                     </td>
                   </tr>
@@ -0,0 +1,323 @@
+import { useState } from 'react'
+import { useTranslation } from 'react-i18next'
+import { useQuery, useQueryClient } from '@tanstack/react-query'
+import { listFilters, createFilter, updateFilter, deleteFilter, testFilter } from '@/api'
+import { useFeeds } from '@/hooks/useFeeds'
+import { useFolders } from '@/hooks/useFolders'
+import { cn } from '@/lib/utils'
+import { Switch } from '@/components/ui/switch'
+import type { Filter, FilterAction, FilterField, FilterInput, FilterMatch, FilterTestResult } from '@/types/api'
+
+const FIELDS: FilterField[] = ['title', 'content', 'author', 'url']
+const MATCHES: FilterMatch[] = ['contains', 'regex', 'expr']
+const ACTIONS: FilterAction[] = ['mark_read', 'star', 'skip', 'move', 'rewrite']
+
+const selectClass = 'h-8 rounded-md border border-border bg-background px-2 text-sm focus:border-primary focus:outline-none'
+
+function toInput(filter: Filter): FilterInput {
+  return {
+    feedId: filter.feedId,
+    field: filter.field,
+    match: filter.match,
+    pattern: filter.pattern,
+    action: filter.action,
+    folderId: filter.folderId,
+    enabled: filter.enabled,
+  }
+}
+
+export function FiltersSettings() {
+  const { t } = useTranslation()
+  const queryClient = useQueryClient()
+  const { data: filters = [], isLoading } = useQuery({
+    queryKey: ['filters'],
+    queryFn: listFilters,
+  })
+  const { data: feeds = [] } = useFeeds()
+  const { data: folders = [] } = useFolders()
+  const [feedId, setFeedId] = useState('')
+  const [field, setField] = useState<FilterField>('title')
+  const [match, setMatch] = useState<FilterMatch>('contains')
+  const [pattern, setPattern] = useState('')
+  const [action, setAction] = useState<FilterAction>('mark_read')
+  const [folderId, setFolderId] = useState('')
+  const [isSaving, setIsSaving] = useState(false)
+  const [error, setError] = useState<string | null>(null)
+  const [sampleTitle, setSampleTitle] = useState('')
+  const [sampleUrl, setSampleUrl] = useState('')
+  const [testResult, setTestResult] = useState<FilterTestResult | null>(null)
+  const [testError, setTestError] = useState<string | null>(null)
+
+  const feedTitle = (id?: string) =>
+    id ? (feeds.find((f) => f.id === id)?.title ?? id) : t('filters.all_feeds')
+  const folderName = (id?: string) => folders.find((f) => f.id === id)?.name ?? ''
+
+  const isExpr = match === 'expr'
+  // Only an expression can rewrite an entry
+  const actions = isExpr ? ACTIONS : ACTIONS.filter((a) => a !== 'rewrite')
+  const canAdd = pattern.trim() !== '' && (action !== 'move' || folderId !== '') && (isExpr || action !== 'rewrite')
+
+  const ruleInput = (): FilterInput => ({
+    feedId: feedId || undefined,
+    field: isExpr ? '' : field,
+    match,
+    pattern: pattern.trim(),
+    action,
+    folderId: action === 'move' ? folderId : undefined,
+    enabled: true,
+  })
+
+  const handleMatchChange = (value: FilterMatch) => {
+    setMatch(value)
+    if (value !== 'expr' && action === 'rewrite') setAction('mark_read')
+    setTestResult(null)
+  }
+
+  const handleAdd = async () => {
+    if (!canAdd) return
+    setError(null)
+    setIsSaving(true)
+    try {
+      await createFilter(ruleInput())
+      setPattern('')
+      setTestResult(null)
+      await queryClient.invalidateQueries({ queryKey: ['filters'] })
+    } catch {
+      setError(t('filters.save_failed'))
+    } finally {
+      setIsSaving(false)
+    }
+  }
+
+  const handleTest = async () => {
+    if (!canAdd) return
+    setTestError(null)
+    setTestResult(null)
+    try {
+      const result = await testFilter({
+        ...ruleInput(),
+        entry: { title: sampleTitle, url: sampleUrl },
+      })
+      setTestResult(result)
+    } catch (err) {
+      setTestError(err instanceof Error ? err.message : t('filters.test_failed'))
+    }
+  }
+
+  const testSummary = (result: FilterTestResult) => {
+    if (result.error) return result.error
+    if (!result.matched) return t('filters.test_no_match')
+    if (result.skip) return t('filters.test_skipped')
+    if (action === 'rewrite' && result.title !== sampleTitle) {
+      return t('filters.test_rewritten', { title: result.title })
+    }
+    return t('filters.test_matched')
+  }
+
+  const handleToggle = async (filter: Filter, enabled: boolean) => {
+    setError(null)
+    try {
+      await updateFilter(filter.id, { ...toInput(filter), enabled })
+      await queryClient.invalidateQueries({ queryKey: ['filters'] })
+    } catch {
+      setError(t('filters.save_failed'))
+    }
+  }
+
+  const handleDelete = async (id: string) => {
+    setError(null)
+    try {
+      await deleteFilter(id)
+      await queryClient.invalidateQueries({ queryKey: ['filters'] })
+    } catch {
+      setError(t('filters.delete_failed'))
+    }
+  }
+
+  if (isLoading) {
+    return (
+      <div className="flex h-40 items-center justify-center">
+        <div className="size-6 animate-spin rounded-full border-2 border-primary border-t-transparent" />
+      </div>
+    )
+  }
+
+  return (
+    <div className="space-y-4">
+      <div>
+        <h3 className="text-sm font-semibold text-muted-foreground">
+          {t('filters.title', { count: filters.length })}
+        </h3>
+        <p className="text-xs text-muted-foreground">{t('filters.description')}</p>
+      </div>
+
+      {/* New rule */}
+      <div className="flex flex-wrap items-center gap-2 rounded-lg border border-border p-3">
+        <select value={feedId} onChange={(e) => setFeedId(e.target.value)} className={cn(selectClass, 'w-40')}>
+          <option value="">{t('filters.all_feeds')}</option>
+          {feeds.map((feed) => (
+            <option key={feed.id} value={feed.id}>{feed.title}</option>
+          ))}
+        </select>
+        {!isExpr && (
+          <select value={field} onChange={(e) => setField(e.target.value as FilterField)} className={selectClass}>
+            {FIELDS.map((f) => (
+              <option key={f} value={f}>{t(`filters.field_${f}`)}</option>
+            ))}
+          </select>
+        )}
+        <select value={match} onChange={(e) => handleMatchChange(e.target.value as FilterMatch)} className={selectClass}>
+          {MATCHES.map((m) => (
+            <option key={m} value={m}>{t(`filters.match_${m}`)}</option>
+          ))}
+        </select>
+        <input
+          type="text"
+          value={pattern}
+          onChange={(e) => setPattern(e.target.value)}
+          placeholder={
+            isExpr
+              ? t('filters.expr_placeholder')
+              : match === 'regex'
+                ? t('filters.regex_placeholder')
+                : t('filters.pattern_placeholder')
+          }
+          className={cn(
+            'h-8 rounded-md border border-border bg-background px-2 text-sm',
+            isExpr ? 'w-full font-mono' : 'w-44',
+            'placeholder:text-muted-foreground/50',
+            'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+          )}
+        />
+        <select value={action} onChange={(e) => setAction(e.target.value as FilterAction)} className={selectClass}>
+          {actions.map((a) => (
+            <option key={a} value={a}>{t(`filters.action_${a}`)}</option>
+          ))}
+        </select>
+        {action === 'move' && (
+          <select value={folderId} onChange={(e) => setFolderId(e.target.value)} className={cn(selectClass, 'w-36')}>
+            <option value="">{t('filters.choose_folder')}</option>
+            {folders.map((folder) => (
+              <option key={folder.id} value={folder.id}>{folder.name}</option>
+            ))}
+          </select>
+        )}
+        <button
+          type="button"
+          onClick={handleAdd}
+          disabled={!canAdd || isSaving}
+          className={cn(
+            'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+            'bg-primary text-primary-foreground hover:bg-primary/90',
+            'disabled:cursor-not-allowed disabled:opacity-50'
+          )}
+        >
+          {t('filters.add')}
+        </button>
+        <div className="flex w-full flex-wrap items-center gap-2">
+          <input
+            type="text"
+            value={sampleTitle}
+            onChange={(e) => setSampleTitle(e.target.value)}
+            placeholder={t('filters.sample_title')}
+            className={cn(
+              'h-8 w-44 rounded-md border border-border bg-background px-2 text-sm',
+              'placeholder:text-muted-foreground/50',
+              'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+            )}
+          />
+          <input
+            type="text"
+            value={sampleUrl}
+            onChange={(e) => setSampleUrl(e.target.value)}
+            placeholder={t('filters.sample_url')}
+            className={cn(
+              'h-8 w-44 rounded-md border border-border bg-background px-2 text-sm',
+              'placeholder:text-muted-foreground/50',
+              'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+            )}
+          />
+          <button
+            type="button"
+            onClick={handleTest}
+            disabled={!canAdd}
+            className={cn(
+              'h-8 rounded-md border border-border px-3 text-sm transition-colors',
+              'hover:bg-muted disabled:cursor-not-allowed disabled:opacity-50'
+            )}
+          >
+            {t('filters.test')}
+          </button>
+          {(testResult || testError) && (
+            <span className={cn('text-xs', testError || testResult?.error ? 'text-destructive' : 'text-muted-foreground')}>
+              {testError ?? (testResult && testSummary(testResult))}
+            </span>
+          )}
+        </div>
+      </div>
+
+      {error && (
+        <div className="rounded-md bg-destructive/10 px-3 py-2 text-sm text-destructive">
+          {error}
+        </div>
+      )}
+
+      {filters.length === 0 ? (
+        <div className="rounded-lg border border-dashed border-border bg-muted/20 p-8 text-center">
+          <p className="text-sm text-muted-foreground">{t('filters.no_filters')}</p>
+        </div>
+      ) : (
+        <div className="overflow-hidden rounded-lg border border-border">
+          <table className="w-full text-sm">
+            <thead className="bg-muted/50">
+              <tr>
+                <th className="px-3 py-2 text-left font-medium text-muted-foreground">{t('filters.scope')}</th>
+                <th className="px-3 py-2 text-left font-medium text-muted-foreground">{t('filters.rule')}</th>
+                <th className="px-3 py-2 text-left font-medium text-muted-foreground">{t('filters.action')}</th>
+                <th className="w-16 px-3 py-2" />
+                <th className="w-10 px-3 py-2" />
+              </tr>
+            </thead>
+            <tbody className="divide-y divide-border">
+              {filters.map((filter) => (
+                <tr key={filter.id} className={cn('transition-colors hover:bg-muted/30', !filter.enabled && 'opacity-60')}>
+                  <td className="max-w-[160px] truncate px-3 py-2" title={feedTitle(filter.feedId)}>
+                    {feedTitle(filter.feedId)}
+                  </td>
+                  <td className="max-w-[240px] px-3 py-2">
+                    <span className="text-muted-foreground">
+                      {filter.field && `${t(`filters.field_${filter.field}`)} `}{t(`filters.match_${filter.match}`)}
+                    </span>{' '}
+                    <code className="break-all rounded bg-muted px-1 text-xs">{filter.pattern}</code>
+                  </td>
+                  <td className="px-3 py-2">
+                    {t(`filters.action_${filter.action}`)}
+                    {filter.action === 'move' && ` → ${folderName(filter.folderId)}`}
+                  </td>
+                  <td className="px-3 py-2">
+                    <Switch
+                      checked={filter.enabled}
+                      onCheckedChange={(enabled) => handleToggle(filter, enabled)}
+                    />
+                  </td>
+                  <td className="px-3 py-2">
+                    <button
+                      type="button"
+                      onClick={() => handleDelete(filter.id)}
+                      className="rounded p-1 text-muted-foreground transition-colors hover:bg-destructive/10 hover:text-destructive"
+                      aria-label={t('filters.delete')}
+                    >
+                      <svg className="size-4" fill="none" stroke="currentColor" viewBox="0 0 24 24">
+                        <path strokeLinecap="round" strokeLinejoin="round" strokeWidth={2} d="M6 18L18 6M6 6l12 12" />
+                      </svg>
+                    </button>
+                  </td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        </div>
+      )}
+    </div>
+  )
+}
@@ -1,10 +1,11 @@
 import { useState, useEffect, useCallback, useMemo } from 'react'
 import { useTranslation } from 'react-i18next'
 import { useQueryClient } from '@tanstack/react-query'
-import { getGeneralSettings, updateGeneralSettings } from '@/api'
+import { getGeneralSettings, setLowData, updateGeneralSettings } from '@/api'
 import { cn } from '@/lib/utils'
 import { Switch } from '@/components/ui/switch'
 import { SegmentedControl } from '@/components/ui/segmented-control'
+import type { DuplicateTitlesMode, FutureDatesMode, NSFWMode } from '@/types/settings'
 
 type Language = 'zh' | 'en'
 
@@ -13,13 +14,59 @@ export function GeneralSettings() {
   const queryClient = useQueryClient()
   const [fallbackUA, setFallbackUA] = useState('')
   const [autoReadability, setAutoReadability] = useState(false)
+  const [lowData, setLowDataState] = useState(false)
+  const [lowDataActive, setLowDataActive] = useState(false)
+  const [lowDataSchedule, setLowDataSchedule] = useState('')
   const [isSaving, setIsSaving] = useState(false)
   const [saveStatus, setSaveStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [scheduleStatus, setScheduleStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [nsfwMode, setNSFWMode] = useState<NSFWMode>('show')
+  const [nsfwKeywords, setNSFWKeywords] = useState('')
+  const [nsfwVisionCheck, setNSFWVisionCheck] = useState(false)
+  const [categoryFolders, setCategoryFolders] = useState(false)
+  const [keywordsStatus, setKeywordsStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [cookieHosts, setCookieHosts] = useState('')
+  const [cookieHostsStatus, setCookieHostsStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [embedHosts, setEmbedHosts] = useState('')
+  const [embedHostsStatus, setEmbedHostsStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [tlsFingerprints, setTLSFingerprints] = useState('')
+  const [fingerprintsStatus, setFingerprintsStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [iconSources, setIconSources] = useState('')
+  const [iconSourcesStatus, setIconSourcesStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [retentionDays, setRetentionDays] = useState('')
+  const [retentionMaxPerFeed, setRetentionMaxPerFeed] = useState('')
+  const [retentionStatus, setRetentionStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [feedDisableThreshold, setFeedDisableThreshold] = useState('')
+  const [thresholdStatus, setThresholdStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [duplicateTitles, setDuplicateTitles] = useState<DuplicateTitlesMode>('off')
+  const [futureDates, setFutureDates] = useState<FutureDatesMode>('clamp')
+  const [refetchWindow, setRefetchWindow] = useState('')
+  const [refetchStatus, setRefetchStatus] = useState<'idle' | 'success' | 'error'>('idle')
+  const [duplicateTitleWindow, setDuplicateTitleWindow] = useState('')
+  const [windowStatus, setWindowStatus] = useState<'idle' | 'success' | 'error'>('idle')
 
   useEffect(() => {
     getGeneralSettings().then((settings) => {
       setFallbackUA(settings.fallbackUserAgent || '')
       setAutoReadability(settings.autoReadability || false)
+      setLowDataState(settings.lowData || false)
+      setLowDataActive(settings.lowDataActive || false)
+      setLowDataSchedule(settings.lowDataSchedule || '')
+      setNSFWMode(settings.nsfwMode || 'show')
+      setNSFWKeywords(settings.nsfwKeywords || '')
+      setNSFWVisionCheck(settings.nsfwVisionCheck || false)
+      setCategoryFolders(settings.categoryFolders || false)
+      setCookieHosts(settings.cookieHosts || '')
+      setEmbedHosts(settings.embedHosts || '')
+      setTLSFingerprints(settings.tlsFingerprints || '')
+      setIconSources(settings.iconSources || '')
+      setRetentionDays(settings.retentionDays ? String(settings.retentionDays) : '')
+      setRetentionMaxPerFeed(settings.retentionMaxPerFeed ? String(settings.retentionMaxPerFeed) : '')
+      setFeedDisableThreshold(String(settings.feedDisableThreshold ?? ''))
+      setDuplicateTitles(settings.duplicateTitles || 'off')
+      setFutureDates(settings.futureDates || 'clamp')
+      setRefetchWindow(String(settings.contentRefetchWindow ?? 0))
+      setDuplicateTitleWindow(String(settings.duplicateTitleWindow ?? ''))
     }).catch(() => {
       // ignore
     })
@@ -50,6 +97,257 @@ export function GeneralSettings() {
     }
   }, [fallbackUA, queryClient])
 
+  const handleLowDataChange = useCallback(async (checked: boolean) => {
+    setLowDataState(checked)
+    try {
+      const settings = await setLowData(checked)
+      setLowDataActive(settings.lowDataActive)
+      queryClient.invalidateQueries({ queryKey: ['generalSettings'] })
+    } catch {
+      // Revert on error
+      setLowDataState(!checked)
+    }
+  }, [queryClient])
+
+  const handleSaveLowDataSchedule = async () => {
+    setScheduleStatus('idle')
+    try {
+      const settings = await updateGeneralSettings({
+        fallbackUserAgent: fallbackUA,
+        autoReadability,
+        lowDataSchedule: lowDataSchedule.trim(),
+      })
+      setLowDataActive(settings.lowDataActive)
+      queryClient.invalidateQueries({ queryKey: ['generalSettings'] })
+      setScheduleStatus('success')
+      setTimeout(() => setScheduleStatus('idle'), 2000)
+    } catch {
+      setScheduleStatus('error')
+    }
+  }
+
+  const handleNSFWModeChange = useCallback(async (mode: NSFWMode) => {
+    const previous = nsfwMode
+    setNSFWMode(mode)
+    try {
+      await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, nsfwMode: mode })
+      queryClient.invalidateQueries({ queryKey: ['generalSettings'] })
+    } catch {
+      // Revert on error
+      setNSFWMode(previous)
+    }
+  }, [nsfwMode, fallbackUA, autoReadability, queryClient])
+
+  const handleNSFWVisionCheckChange = useCallback(async (checked: boolean) => {
+    setNSFWVisionCheck(checked)
+    try {
+      await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, nsfwVisionCheck: checked })
+      queryClient.invalidateQueries({ queryKey: ['generalSettings'] })
+    } catch {
+      // Revert on error
+      setNSFWVisionCheck(!checked)
+    }
+  }, [fallbackUA, autoReadability, queryClient])
+
+  const handleCategoryFoldersChange = useCallback(async (checked: boolean) => {
+    setCategoryFolders(checked)
+    try {
+      await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, categoryFolders: checked })
+      queryClient.invalidateQueries({ queryKey: ['generalSettings'] })
+    } catch {
+      // Revert on error
+      setCategoryFolders(!checked)
+    }
+  }, [fallbackUA, autoReadability, queryClient])
+
+  const handleSaveNSFWKeywords = async () => {
+    setKeywordsStatus('idle')
+    try {
+      await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, nsfwKeywords: nsfwKeywords.trim() })
+      queryClient.invalidateQueries({ queryKey: ['generalSettings'] })
+      setKeywordsStatus('success')
+      setTimeout(() => setKeywordsStatus('idle'), 2000)
+    } catch {
+      setKeywordsStatus('error')
+    }
+  }
+
+  const handleSaveCookieHosts = async () => {
+    setCookieHostsStatus('idle')
+    try {
+      await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, cookieHosts: cookieHosts.trim() })
+      setCookieHostsStatus('success')
+      setTimeout(() => setCookieHostsStatus('idle'), 2000)
+    } catch {
+      setCookieHostsStatus('error')
+    }
+  }
+
+  const handleSaveEmbedHosts = async () => {
+    setEmbedHostsStatus('idle')
+    try {
+      await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, embedHosts: embedHosts.trim() })
+      setEmbedHostsStatus('success')
+      setTimeout(() => setEmbedHostsStatus('idle'), 2000)
+    } catch {
+      setEmbedHostsStatus('error')
+    }
+  }
+
+  const handleSaveTLSFingerprints = async () => {
+    setFingerprintsStatus('idle')
+    try {
+      await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, tlsFingerprints: tlsFingerprints.trim() })
+      setFingerprintsStatus('success')
+      setTimeout(() => setFingerprintsStatus('idle'), 2000)
+    } catch {
+      setFingerprintsStatus('error')
+    }
+  }
+
+  const handleSaveIconSources = async () => {
+    setIconSourcesStatus('idle')
+    try {
+      const settings = await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, iconSources: iconSources.trim() })
+      setIconSources(settings.iconSources || '')
+      setIconSourcesStatus('success')
+      setTimeout(() => setIconSourcesStatus('idle'), 2000)
+    } catch {
+      setIconSourcesStatus('error')
+    }
+  }
+
+  const handleSaveRetention = async () => {
+    setRetentionStatus('idle')
+    // Empty turns a rule off
+    const days = Number(retentionDays.trim() || 0)
+    const maxPerFeed = Number(retentionMaxPerFeed.trim() || 0)
+    if (!Number.isInteger(days) || days < 0 || !Number.isInteger(maxPerFeed) || maxPerFeed < 0) {
+      setRetentionStatus('error')
+      return
+    }
+    try {
+      const settings = await updateGeneralSettings({
+        fallbackUserAgent: fallbackUA,
+        autoReadability,
+        retentionDays: days,
+        retentionMaxPerFeed: maxPerFeed,
+      })
+      setRetentionDays(settings.retentionDays ? String(settings.retentionDays) : '')
+      setRetentionMaxPerFeed(settings.retentionMaxPerFeed ? String(settings.retentionMaxPerFeed) : '')
+      setRetentionStatus('success')
+      setTimeout(() => setRetentionStatus('idle'), 2000)
+    } catch {
+      setRetentionStatus('error')
+    }
+  }
+
+  const handleDuplicateTitlesChange = useCallback(async (mode: DuplicateTitlesMode) => {
+    const previous = duplicateTitles
+    setDuplicateTitles(mode)
+    try {
+      await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, duplicateTitles: mode })
+      queryClient.invalidateQueries({ queryKey: ['generalSettings'] })
+    } catch {
+      // Revert on error
+      setDuplicateTitles(previous)
+    }
+  }, [duplicateTitles, fallbackUA, autoReadability, queryClient])
+
+  const handleFutureDatesChange = useCallback(async (mode: FutureDatesMode) => {
+    const previous = futureDates
+    setFutureDates(mode)
+    try {
+      await updateGeneralSettings({ fallbackUserAgent: fallbackUA, autoReadability, futureDates: mode })
+      queryClient.invalidateQueries({ queryKey: ['generalSettings'] })
+    } catch {
+      // Revert on error
+      setFutureDates(previous)
+    }
+  }, [futureDates, fallbackUA, autoReadability, queryClient])
+
+  const handleSaveRefetchWindow = async () => {
+    setRefetchStatus('idle')
+    const hours = Number(refetchWindow.trim())
+    if (!Number.isInteger(hours) || hours < 0 || hours > 168) {
+      setRefetchStatus('error')
+      return
+    }
+    try {
+      const settings = await updateGeneralSettings({
+        fallbackUserAgent: fallbackUA,
+        autoReadability,
+        contentRefetchWindow: hours,
+      })
+      setRefetchWindow(String(settings.contentRefetchWindow))
+      setRefetchStatus('success')
+      setTimeout(() => setRefetchStatus('idle'), 2000)
+    } catch {
+      setRefetchStatus('error')
+    }
+  }
+
+  const handleSaveDuplicateTitleWindow = async () => {
+    setWindowStatus('idle')
+    const hours = Number(duplicateTitleWindow.trim())
+    if (!Number.isInteger(hours) || hours < 1 || hours > 720) {
+      setWindowStatus('error')
+      return
+    }
+    try {
+      const settings = await updateGeneralSettings({
+        fallbackUserAgent: fallbackUA,
+        autoReadability,
+        duplicateTitleWindow: hours,
+      })
+      setDuplicateTitleWindow(String(settings.duplicateTitleWindow))
+      setWindowStatus('success')
+      setTimeout(() => setWindowStatus('idle'), 2000)
+    } catch {
+      setWindowStatus('error')
+    }
+  }
+
+  const handleSaveThreshold = async () => {
+    setThresholdStatus('idle')
+    // 0 never disables feeds
+    const threshold = Number(feedDisableThreshold.trim() || 0)
+    if (!Number.isInteger(threshold) || threshold < 0) {
+      setThresholdStatus('error')
+      return
+    }
+    try {
+      const settings = await updateGeneralSettings({
+        fallbackUserAgent: fallbackUA,
+        autoReadability,
+        feedDisableThreshold: threshold,
+      })
+      setFeedDisableThreshold(String(settings.feedDisableThreshold))
+      setThresholdStatus('success')
+      setTimeout(() => setThresholdStatus('idle'), 2000)
+    } catch {
+      setThresholdStatus('error')
+    }
+  }
+
+  const nsfwModeOptions = useMemo(() => [
+    { value: 'show' as NSFWMode, label: t('settings.nsfw_show') },
+    { value: 'blur' as NSFWMode, label: t('settings.nsfw_blur') },
+    { value: 'hide' as NSFWMode, label: t('settings.nsfw_hide') },
+  ], [t])
+
+  const duplicateTitlesOptions = useMemo(() => [
+    { value: 'off' as DuplicateTitlesMode, label: t('settings.duplicate_titles_off') },
+    { value: 'exact' as DuplicateTitlesMode, label: t('settings.duplicate_titles_exact') },
+    { value: 'fuzzy' as DuplicateTitlesMode, label: t('settings.duplicate_titles_fuzzy') },
+  ], [t])
+
+  const futureDatesOptions = useMemo(() => [
+    { value: 'clamp' as FutureDatesMode, label: t('settings.future_dates_clamp') },
+    { value: 'hide' as FutureDatesMode, label: t('settings.future_dates_hide') },
+    { value: 'keep' as FutureDatesMode, label: t('settings.future_dates_keep') },
+  ], [t])
+
   const languageOptions = useMemo(() => [
     { value: 'zh' as Language, label: t('language.zh') },
     { value: 'en' as Language, label: t('language.en') },
@@ -91,6 +389,304 @@ export function GeneralSettings() {
         </div>
       </section>
 
+      {/* Category Folders Section */}
+      <section>
+        <div className="flex items-center justify-between">
+          <div>
+            <div className="text-sm font-medium">{t('settings.category_folders')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.category_folders_description')}</div>
+          </div>
+          <Switch
+            checked={categoryFolders}
+            onCheckedChange={handleCategoryFoldersChange}
+          />
+        </div>
+      </section>
+
+      {/* Low-Data Section */}
+      <section className="space-y-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <div className="text-sm font-medium">{t('settings.low_data')}</div>
+            <div className="text-xs text-muted-foreground">
+              {t('settings.low_data_description')}
+              {!lowData && lowDataActive && ` · ${t('settings.low_data_active_by_schedule')}`}
+            </div>
+          </div>
+          <Switch
+            checked={lowData}
+            onCheckedChange={handleLowDataChange}
+          />
+        </div>
+        <div className="flex items-start justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.low_data_schedule')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.low_data_schedule_description')}</div>
+          </div>
+          <div className="flex shrink-0 gap-2">
+            <input
+              type="text"
+              value={lowDataSchedule}
+              onChange={(e) => setLowDataSchedule(e.target.value)}
+              placeholder={t('settings.low_data_schedule_placeholder')}
+              className={cn(
+                'h-8 w-32 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <button
+              type="button"
+              onClick={handleSaveLowDataSchedule}
+              className={cn(
+                'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                'bg-primary text-primary-foreground hover:bg-primary/90',
+                scheduleStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                scheduleStatus === 'error' && 'bg-destructive hover:bg-destructive'
+              )}
+            >
+              {scheduleStatus === 'success' ? t('settings.saved') : t('settings.save')}
+            </button>
+          </div>
+        </div>
+      </section>
+
+      {/* NSFW Section */}
+      <section className="space-y-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <div className="text-sm font-medium">{t('settings.nsfw')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.nsfw_description')}</div>
+          </div>
+          <SegmentedControl
+            value={nsfwMode}
+            onValueChange={handleNSFWModeChange}
+            options={nsfwModeOptions}
+          />
+        </div>
+        <div className="flex items-start justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.nsfw_keywords')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.nsfw_keywords_description')}</div>
+          </div>
+          <div className="flex shrink-0 gap-2">
+            <input
+              type="text"
+              value={nsfwKeywords}
+              onChange={(e) => setNSFWKeywords(e.target.value)}
+              placeholder={t('settings.nsfw_keywords_placeholder')}
+              className={cn(
+                'h-8 w-48 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <button
+              type="button"
+              onClick={handleSaveNSFWKeywords}
+              className={cn(
+                'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                'bg-primary text-primary-foreground hover:bg-primary/90',
+                keywordsStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                keywordsStatus === 'error' && 'bg-destructive hover:bg-destructive'
+              )}
+            >
+              {keywordsStatus === 'success' ? t('settings.saved') : t('settings.save')}
+            </button>
+          </div>
+        </div>
+        <div className="flex items-center justify-between">
+          <div>
+            <div className="text-sm font-medium">{t('settings.nsfw_vision_check')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.nsfw_vision_check_description')}</div>
+          </div>
+          <Switch
+            checked={nsfwVisionCheck}
+            onCheckedChange={handleNSFWVisionCheckChange}
+          />
+        </div>
+      </section>
+
+      {/* Retention Section */}
+      <section>
+        <div className="flex items-start justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.retention')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.retention_description')}</div>
+          </div>
+          <div className="flex shrink-0 items-center gap-2">
+            <input
+              type="number"
+              min={0}
+              value={retentionDays}
+              onChange={(e) => setRetentionDays(e.target.value)}
+              placeholder={t('settings.retention_days_placeholder')}
+              aria-label={t('settings.retention_days_placeholder')}
+              className={cn(
+                'h-8 w-24 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <input
+              type="number"
+              min={0}
+              value={retentionMaxPerFeed}
+              onChange={(e) => setRetentionMaxPerFeed(e.target.value)}
+              placeholder={t('settings.retention_max_per_feed_placeholder')}
+              aria-label={t('settings.retention_max_per_feed_placeholder')}
+              className={cn(
+                'h-8 w-28 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <button
+              type="button"
+              onClick={handleSaveRetention}
+              className={cn(
+                'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                'bg-primary text-primary-foreground hover:bg-primary/90',
+                retentionStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                retentionStatus === 'error' && 'bg-destructive hover:bg-destructive'
+              )}
+            >
+              {retentionStatus === 'success' ? t('settings.saved') : t('settings.save')}
+            </button>
+          </div>
+        </div>
+      </section>
+
+      {/* Feed Disable Section */}
+      <section>
+        <div className="flex items-start justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.feed_disable_threshold')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.feed_disable_threshold_description')}</div>
+          </div>
+          <div className="flex shrink-0 items-center gap-2">
+            <input
+              type="number"
+              min={0}
+              value={feedDisableThreshold}
+              onChange={(e) => setFeedDisableThreshold(e.target.value)}
+              aria-label={t('settings.feed_disable_threshold')}
+              className={cn(
+                'h-8 w-24 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <button
+              type="button"
+              onClick={handleSaveThreshold}
+              className={cn(
+                'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                'bg-primary text-primary-foreground hover:bg-primary/90',
+                thresholdStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                thresholdStatus === 'error' && 'bg-destructive hover:bg-destructive'
+              )}
+            >
+              {thresholdStatus === 'success' ? t('settings.saved') : t('settings.save')}
+            </button>
+          </div>
+        </div>
+      </section>
+
+      {/* Duplicate Titles Section */}
+      <section className="space-y-4">
+        <div className="flex items-center justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.duplicate_titles')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.duplicate_titles_description')}</div>
+          </div>
+          <SegmentedControl
+            value={duplicateTitles}
+            onValueChange={handleDuplicateTitlesChange}
+            options={duplicateTitlesOptions}
+          />
+        </div>
+        {duplicateTitles !== 'off' && (
+          <div className="flex items-start justify-between gap-4">
+            <div>
+              <div className="text-sm font-medium">{t('settings.duplicate_title_window')}</div>
+              <div className="text-xs text-muted-foreground">{t('settings.duplicate_title_window_description')}</div>
+            </div>
+            <div className="flex shrink-0 items-center gap-2">
+              <input
+                type="number"
+                min={1}
+                max={720}
+                value={duplicateTitleWindow}
+                onChange={(e) => setDuplicateTitleWindow(e.target.value)}
+                aria-label={t('settings.duplicate_title_window')}
+                className={cn(
+                  'h-8 w-24 rounded-md border border-border bg-background px-2 text-sm',
+                  'placeholder:text-muted-foreground/50',
+                  'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+                )}
+              />
+              <button
+                type="button"
+                onClick={handleSaveDuplicateTitleWindow}
+                className={cn(
+                  'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                  'bg-primary text-primary-foreground hover:bg-primary/90',
+                  windowStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                  windowStatus === 'error' && 'bg-destructive hover:bg-destructive'
+                )}
+              >
+                {windowStatus === 'success' ? t('settings.saved') : t('settings.save')}
+              </button>
+            </div>
+          </div>
+        )}
+        <div className="flex items-center justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.future_dates')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.future_dates_description')}</div>
+          </div>
+          <SegmentedControl
+            value={futureDates}
+            onValueChange={handleFutureDatesChange}
+            options={futureDatesOptions}
+          />
+        </div>
+        <div className="flex items-start justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.content_refetch_window')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.content_refetch_window_description')}</div>
+          </div>
+          <div className="flex shrink-0 items-center gap-2">
+            <input
+              type="number"
+              min={0}
+              max={168}
+              value={refetchWindow}
+              onChange={(e) => setRefetchWindow(e.target.value)}
+              aria-label={t('settings.content_refetch_window')}
+              className={cn(
+                'h-8 w-24 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <button
+              type="button"
+              onClick={handleSaveRefetchWindow}
+              className={cn(
+                'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                'bg-primary text-primary-foreground hover:bg-primary/90',
+                refetchStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                refetchStatus === 'error' && 'bg-destructive hover:bg-destructive'
+              )}
+            >
+              {refetchStatus === 'success' ? t('settings.saved') : t('settings.save')}
+            </button>
+          </div>
+        </div>
+      </section>
+
       {/* Advanced Section */}
       <section>
         <div className="mb-3 text-xs font-medium uppercase tracking-wider text-muted-foreground">
@@ -129,6 +725,130 @@ export function GeneralSettings() {
             </button>
           </div>
         </div>
+        <div className="mt-4 flex items-start justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.cookie_hosts')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.cookie_hosts_description')}</div>
+          </div>
+          <div className="flex shrink-0 gap-2">
+            <input
+              type="text"
+              value={cookieHosts}
+              onChange={(e) => setCookieHosts(e.target.value)}
+              placeholder={t('settings.cookie_hosts_placeholder')}
+              className={cn(
+                'h-8 w-64 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <button
+              type="button"
+              onClick={handleSaveCookieHosts}
+              className={cn(
+                'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                'bg-primary text-primary-foreground hover:bg-primary/90',
+                cookieHostsStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                cookieHostsStatus === 'error' && 'bg-destructive hover:bg-destructive'
+              )}
+            >
+              {cookieHostsStatus === 'success' ? t('settings.saved') : t('settings.save')}
+            </button>
+          </div>
+        </div>
+        <div className="mt-4 flex items-start justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.embed_hosts')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.embed_hosts_description')}</div>
+          </div>
+          <div className="flex shrink-0 gap-2">
+            <input
+              type="text"
+              value={embedHosts}
+              onChange={(e) => setEmbedHosts(e.target.value)}
+              placeholder={t('settings.embed_hosts_placeholder')}
+              className={cn(
+                'h-8 w-64 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <button
+              type="button"
+              onClick={handleSaveEmbedHosts}
+              className={cn(
+                'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                'bg-primary text-primary-foreground hover:bg-primary/90',
+                embedHostsStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                embedHostsStatus === 'error' && 'bg-destructive hover:bg-destructive'
+              )}
+            >
+              {embedHostsStatus === 'success' ? t('settings.saved') : t('settings.save')}
+            </button>
+          </div>
+        </div>
+        <div className="mt-4 flex items-start justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.tls_fingerprints')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.tls_fingerprints_description')}</div>
+          </div>
+          <div className="flex shrink-0 gap-2">
+            <input
+              type="text"
+              value={tlsFingerprints}
+              onChange={(e) => setTLSFingerprints(e.target.value)}
+              placeholder={t('settings.tls_fingerprints_placeholder')}
+              className={cn(
+                'h-8 w-64 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <button
+              type="button"
+              onClick={handleSaveTLSFingerprints}
+              className={cn(
+                'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                'bg-primary text-primary-foreground hover:bg-primary/90',
+                fingerprintsStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                fingerprintsStatus === 'error' && 'bg-destructive hover:bg-destructive'
+              )}
+            >
+              {fingerprintsStatus === 'success' ? t('settings.saved') : t('settings.save')}
+            </button>
+          </div>
+        </div>
+        <div className="mt-4 flex items-start justify-between gap-4">
+          <div>
+            <div className="text-sm font-medium">{t('settings.icon_sources')}</div>
+            <div className="text-xs text-muted-foreground">{t('settings.icon_sources_description')}</div>
+          </div>
+          <div className="flex shrink-0 gap-2">
+            <input
+              type="text"
+              value={iconSources}
+              onChange={(e) => setIconSources(e.target.value)}
+              placeholder={t('settings.icon_sources_placeholder')}
+              className={cn(
+                'h-8 w-64 rounded-md border border-border bg-background px-2 text-sm',
+                'placeholder:text-muted-foreground/50',
+                'focus:outline-none focus:ring-2 focus:ring-primary/20 focus:border-primary'
+              )}
+            />
+            <button
+              type="button"
+              onClick={handleSaveIconSources}
+              className={cn(
+                'h-8 rounded-md px-3 text-sm font-medium transition-colors',
+                'bg-primary text-primary-foreground hover:bg-primary/90',
+                iconSourcesStatus === 'success' && 'bg-green-600 hover:bg-green-600',
+                iconSourcesStatus === 'error' && 'bg-destructive hover:bg-destructive'
+              )}
+            >
+              {iconSourcesStatus === 'success' ? t('settings.saved') : t('settings.save')}
+            </button>
+          </div>
+        </div>
       </section>
 
     </div>
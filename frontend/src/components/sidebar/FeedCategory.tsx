@@ -26,6 +26,9 @@ interface FeedCategoryProps {
   onSelect?: () => void
   onDelete?: (folderId: string) => void
   onChangeType?: (folderId: string, type: ContentType) => void
+  /** Set for picture folders; whether grid thumbnails are cached after each refresh */
+  pregenerateThumbnails?: boolean
+  onToggleThumbnails?: (folderId: string, enabled: boolean) => void
 }
 
 function ChevronIcon({ className }: { className?: string }) {
@@ -54,6 +57,8 @@ export function FeedCategory({
   onSelect,
   onDelete,
   onChangeType,
+  pregenerateThumbnails,
+  onToggleThumbnails,
 }: FeedCategoryProps) {
   const { t } = useTranslation()
   const [open, , toggle] = useCategoryState(name, defaultOpen)
@@ -127,9 +132,19 @@ export function FeedCategory({
                 <ContextMenuItem onClick={() => onChangeType(folderId, 'notification')}>
                   {t('content_type.notification')}
                 </ContextMenuItem>
+                <ContextMenuItem onClick={() => onChangeType(folderId, 'podcast')}>
+                  {t('content_type.podcast')}
+                </ContextMenuItem>
               </ContextMenuSubContent>
             </ContextMenuSub>
           )}
+          {onToggleThumbnails && pregenerateThumbnails !== undefined && (
+            <ContextMenuItem onClick={() => onToggleThumbnails(folderId, !pregenerateThumbnails)}>
+              {pregenerateThumbnails
+                ? t('actions.stop_pregenerating_thumbnails')
+                : t('actions.pregenerate_thumbnails')}
+            </ContextMenuItem>
+          )}
           {onDelete && (
             <ContextMenuItem
               className="text-destructive focus:text-destructive"
@@ -23,6 +23,7 @@ interface FeedItemProps {
   name: string
   feedId: string
   iconPath?: string
+  iconColor?: string
   unreadCount?: number
   isActive?: boolean
   errorMessage?: string
@@ -65,6 +66,7 @@ export function FeedItem({
   name,
   feedId,
   iconPath,
+  iconColor,
   unreadCount,
   isActive = false,
   errorMessage,
@@ -78,6 +80,7 @@ export function FeedItem({
 }: FeedItemProps) {
   const { t } = useTranslation()
   const [iconError, setIconError] = useState(false)
+  const [iconLoaded, setIconLoaded] = useState(false)
   const hasError = !!errorMessage
   const triggerRef = useRef<HTMLSpanElement>(null)
 
@@ -117,6 +120,8 @@ export function FeedItem({
                   src={`/icons/${iconPath}`}
                   alt=""
                   className="size-4 rounded-sm object-cover"
+                  style={iconLoaded ? undefined : { backgroundColor: iconColor }}
+                  onLoad={() => setIconLoaded(true)}
                   onError={() => setIconError(true)}
                 />
               ) : (
@@ -176,6 +181,9 @@ export function FeedItem({
               <ContextMenuItem onClick={() => onChangeType(feedId, 'notification')}>
                 {t('content_type.notification')}
               </ContextMenuItem>
+              <ContextMenuItem onClick={() => onChangeType(feedId, 'podcast')}>
+                {t('content_type.podcast')}
+              </ContextMenuItem>
             </ContextMenuSubContent>
           </ContextMenuSub>
         )}
@@ -12,9 +12,9 @@ import { SidebarHeader } from './SidebarHeader'
 import { FeedCategory } from './FeedCategory'
 import { FeedItem } from './FeedItem'
 import { SettingsModal } from '@/components/settings'
-import { useFolders, useDeleteFolder, useUpdateFolderType } from '@/hooks/useFolders'
+import { useFolders, useDeleteFolder, useSetFolderThumbnails, useUpdateFolderType } from '@/hooks/useFolders'
 import { useFeeds, useDeleteFeed, useUpdateFeed, useUpdateFeedType } from '@/hooks/useFeeds'
-import { useUnreadCounts, useStarredCount } from '@/hooks/useEntries'
+import { useUnreadCounts, useStarredCount, useStarredCounts } from '@/hooks/useEntries'
 import type { SelectionType } from '@/hooks/useSelection'
 import type { Folder, Feed, ContentType } from '@/types/api'
 
@@ -99,6 +99,14 @@ function BellIcon({ className }: { className?: string }) {
   )
 }
 
+function HeadphonesIcon({ className }: { className?: string }) {
+  return (
+    <svg className={className} viewBox="0 0 24 24" fill="none" stroke="currentColor" strokeWidth={2} strokeLinecap="round" strokeLinejoin="round">
+      <path d="M3 14h3a2 2 0 0 1 2 2v3a2 2 0 0 1-2 2H5a2 2 0 0 1-2-2v-7a9 9 0 0 1 18 0v7a2 2 0 0 1-2 2h-1a2 2 0 0 1-2-2v-3a2 2 0 0 1 2-2h3" />
+    </svg>
+  )
+}
+
 export function Sidebar({
   onAddClick,
   selection,
@@ -114,7 +122,7 @@ export function Sidebar({
   const [sortBy, setSortBy] = useState<SortBy>('name')
 
   // Animation direction tracking
-  const contentTypeList: ContentType[] = ['article', 'picture', 'notification']
+  const contentTypeList: ContentType[] = ['article', 'picture', 'notification', 'podcast']
   const orderIndex = contentTypeList.indexOf(contentType)
   const prevOrderIndexRef = useRef(-1)
   const [isReady, setIsReady] = useState(false)
@@ -139,6 +147,7 @@ export function Sidebar({
   const { mutate: updateFeed } = useUpdateFeed()
   const { mutate: updateFeedType } = useUpdateFeedType()
   const { mutate: updateFolderType } = useUpdateFolderType()
+  const { mutate: setFolderThumbnails } = useSetFolderThumbnails()
 
   // Filter by content type (use currentAnimatedType for animation consistency)
   const folders = useMemo(
@@ -152,6 +161,7 @@ export function Sidebar({
 
   const { data: unreadCountsData } = useUnreadCounts()
   const { data: starredCountData } = useStarredCount()
+  const { data: starredCountsData } = useStarredCounts()
 
   // Handlers for menu actions
   const handleDeleteFeed = useCallback((feedId: string) => {
@@ -177,6 +187,10 @@ export function Sidebar({
     updateFolderType({ id: folderId, type })
   }, [updateFolderType])
 
+  const handleToggleThumbnails = useCallback((folderId: string, enabled: boolean) => {
+    setFolderThumbnails({ id: folderId, enabled })
+  }, [setFolderThumbnails])
+
   const unreadCounts = useMemo(() => {
     if (!unreadCountsData) return new Map<string, number>()
     const map = new Map<string, number>()
@@ -188,24 +202,29 @@ export function Sidebar({
 
   // Calculate unread count for each content type
   const contentTypeCounts = useMemo(() => {
-    const counts = { article: 0, picture: 0, notification: 0 }
+    const counts = { article: 0, picture: 0, notification: 0, podcast: 0 }
     for (const feed of allFeeds) {
       counts[feed.type] += unreadCounts.get(feed.id) || 0
     }
     return counts
   }, [allFeeds, unreadCounts])
 
-  const folderUnreadCounts = useMemo(() => {
-    const map = new Map<string, number>()
-    for (const feed of feeds) {
-      if (feed.folderId) {
-        const current = map.get(feed.folderId) || 0
-        const feedUnread = unreadCounts.get(feed.id) || 0
-        map.set(feed.folderId, current + feedUnread)
-      }
-    }
-    return map
-  }, [feeds, unreadCounts])
+  // Entries a filter moved count under their own folder, as the server files them
+  const folderUnreadCounts = useMemo(
+    () => new Map(Object.entries(unreadCountsData?.folders ?? {})),
+    [unreadCountsData]
+  )
+
+  // The starred view shows starred counts in place of unread counts
+  const isStarredSelected = selection.type === 'starred'
+  const feedCounts = useMemo(() => {
+    if (!isStarredSelected) return unreadCounts
+    return new Map(Object.entries(starredCountsData?.counts ?? {}))
+  }, [isStarredSelected, unreadCounts, starredCountsData])
+  const folderCounts = useMemo(() => {
+    if (!isStarredSelected) return folderUnreadCounts
+    return new Map(Object.entries(starredCountsData?.folders ?? {}))
+  }, [isStarredSelected, folderUnreadCounts, starredCountsData])
 
   const { foldersWithFeeds, uncategorizedFeeds } = groupFeedsByFolder(folders, feeds)
 
@@ -240,7 +259,6 @@ export function Sidebar({
   // Sorted uncategorized feeds
   const sortedUncategorizedFeeds = useMemo(() => sortFeeds(uncategorizedFeeds), [uncategorizedFeeds, sortFeeds])
 
-  const isStarredSelected = selection.type === 'starred'
   const isFeedSelected = (feedId: string) =>
     selection.type === 'feed' && selection.feedId === feedId
   const isFolderSelected = (folderId: string) =>
@@ -313,6 +331,24 @@ export function Sidebar({
               {contentTypeCounts.notification}
             </div>
           </button>
+          <button
+            onClick={() => {
+              onContentTypeChange('podcast')
+              onSelectAll?.('podcast')
+            }}
+            className={cn(
+              'flex h-11 w-8 shrink-0 grow flex-col items-center justify-center gap-1 rounded-md transition-colors',
+              contentType === 'podcast'
+                ? 'text-lime-600 dark:text-lime-500'
+                : 'text-muted-foreground hover:text-foreground'
+            )}
+            title={t('content_type.podcast')}
+          >
+            <HeadphonesIcon className="size-[1.375rem]" />
+            <div className="text-[0.625rem] font-medium leading-none">
+              {contentTypeCounts.podcast}
+            </div>
+          </button>
         </div>
       </div>
 
@@ -358,11 +394,13 @@ export function Sidebar({
                   key={folder.id}
                   folderId={folder.id}
                   name={folder.name}
-                  unreadCount={folderUnreadCounts.get(folder.id) || 0}
+                  unreadCount={folderCounts.get(folder.id) || 0}
                   isSelected={isFolderSelected(folder.id)}
                   onSelect={() => onSelectFolder(folder.id)}
                   onDelete={handleDeleteFolder}
                   onChangeType={handleChangeFolderType}
+                  pregenerateThumbnails={folder.type === 'picture' ? !!folder.pregenerateThumbnails : undefined}
+                  onToggleThumbnails={handleToggleThumbnails}
                 >
                   {folderFeeds.map((feed) => (
                     <FeedItem
@@ -370,7 +408,8 @@ export function Sidebar({
                       feedId={feed.id}
                       name={feed.title}
                       iconPath={feed.iconPath}
-                      unreadCount={unreadCounts.get(feed.id) || 0}
+                      iconColor={feed.iconColor}
+                      unreadCount={feedCounts.get(feed.id) || 0}
                       isActive={isFeedSelected(feed.id)}
                       errorMessage={feed.errorMessage}
                       onClick={() => onSelectFeed(feed.id)}
@@ -390,7 +429,8 @@ export function Sidebar({
                   feedId={feed.id}
                   name={feed.title}
                   iconPath={feed.iconPath}
-                  unreadCount={unreadCounts.get(feed.id) || 0}
+                  iconColor={feed.iconColor}
+                  unreadCount={feedCounts.get(feed.id) || 0}
                   isActive={isFeedSelected(feed.id)}
                   errorMessage={feed.errorMessage}
                   onClick={() => onSelectFeed(feed.id)}
@@ -1,8 +1,8 @@
 import { useState, useCallback } from 'react'
 import { useQueryClient } from '@tanstack/react-query'
-import { createFeed, createFolder, listFolders, previewFeed } from '@/api'
+import { bulkAddFeeds, createFeed, createFolder, listFolders, previewFeed } from '@/api'
 import { getErrorMessage } from '@/lib/errors'
-import type { ContentType, FeedPreview, Folder } from '@/types/api'
+import type { BulkAddResult, ContentType, FeedPreview, Folder } from '@/types/api'
 
 export interface SubscribeOptions {
   folderName?: string
@@ -15,6 +15,9 @@ interface UseAddFeedReturn {
   error: string | null
   discoverFeed: (url: string) => Promise<void>
   subscribeFeed: (feedUrl: string, options: SubscribeOptions) => Promise<boolean>
+  bulkResults: BulkAddResult[] | null
+  /** Subscribes to each URL of a newline-separated list */
+  bulkAdd: (urls: string) => Promise<void>
   clearPreview: () => void
   clearError: () => void
 }
@@ -39,6 +42,7 @@ export function useAddFeed(contentType: ContentType = 'article'): UseAddFeedRetu
   const [feedPreview, setFeedPreview] = useState<FeedPreview | null>(null)
   const [isLoading, setIsLoading] = useState(false)
   const [error, setError] = useState<string | null>(null)
+  const [bulkResults, setBulkResults] = useState<BulkAddResult[] | null>(null)
   const queryClient = useQueryClient()
 
   const clearPreview = useCallback(() => {
@@ -53,6 +57,7 @@ export function useAddFeed(contentType: ContentType = 'article'): UseAddFeedRetu
     setIsLoading(true)
     setError(null)
     setFeedPreview(null)
+    setBulkResults(null)
 
     try {
       const data = await previewFeed(url)
@@ -93,12 +98,33 @@ export function useAddFeed(contentType: ContentType = 'article'): UseAddFeedRetu
     }
   }, [queryClient, contentType])
 
+  const bulkAdd = useCallback(async (urls: string) => {
+    setIsLoading(true)
+    setError(null)
+    setFeedPreview(null)
+    setBulkResults(null)
+
+    try {
+      const data = await bulkAddFeeds({ urls, type: contentType })
+      setBulkResults(data.results)
+      if (data.results.some((result) => result.status === 'added')) {
+        await queryClient.invalidateQueries({ queryKey: ['feeds'] })
+      }
+    } catch (err) {
+      setError(getErrorMessage(err, 'Failed to add feeds.'))
+    } finally {
+      setIsLoading(false)
+    }
+  }, [queryClient, contentType])
+
   return {
     feedPreview,
     isLoading,
     error,
     discoverFeed,
     subscribeFeed,
+    bulkResults,
+    bulkAdd,
     clearPreview,
     clearError,
   }
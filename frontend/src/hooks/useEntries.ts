@@ -7,25 +7,26 @@ import {
   markAllAsRead,
   getUnreadCounts,
   getStarredCount,
+  getStarredCounts,
 } from '@/api'
-import type { Entry, EntryListParams, MarkAllReadParams } from '@/types/api'
+import type { Entry, EntryListParams, MarkAllReadParams, UnreadCountsParams } from '@/types/api'
 
 function entriesQueryKey(params: EntryListParams) {
   return ['entries', params] as const
 }
 
-export function useEntriesInfinite(params: Omit<EntryListParams, 'offset'>) {
+export function useEntriesInfinite(params: Omit<EntryListParams, 'offset' | 'after'>) {
   const pageSize = params.limit ?? 50
 
   return useInfiniteQuery({
     queryKey: entriesQueryKey({ ...params, limit: pageSize }),
-    queryFn: ({ pageParam = 0 }) =>
-      listEntries({ ...params, limit: pageSize, offset: pageParam }),
-    getNextPageParam: (lastPage, allPages) => {
+    queryFn: ({ pageParam }) =>
+      listEntries({ ...params, limit: pageSize, after: pageParam }),
+    getNextPageParam: (lastPage) => {
       if (!lastPage.hasMore) return undefined
-      return allPages.length * pageSize
+      return lastPage.nextCursor
     },
-    initialPageParam: 0,
+    initialPageParam: undefined as string | undefined,
   })
 }
 
@@ -37,10 +38,10 @@ export function useEntry(id: string | null) {
   })
 }
 
-export function useUnreadCounts() {
+export function useUnreadCounts(params: UnreadCountsParams = {}) {
   return useQuery({
-    queryKey: ['unreadCounts'],
-    queryFn: getUnreadCounts,
+    queryKey: ['unreadCounts', params],
+    queryFn: () => getUnreadCounts(params),
     staleTime: 30_000,
     refetchInterval: 60_000,
   })
@@ -84,6 +85,15 @@ export function useStarredCount() {
   })
 }
 
+export function useStarredCounts() {
+  return useQuery({
+    queryKey: ['starredCounts'],
+    queryFn: getStarredCounts,
+    staleTime: 30_000,
+    refetchInterval: 60_000,
+  })
+}
+
 export function useMarkAsStarred() {
   const queryClient = useQueryClient()
 
@@ -96,6 +106,7 @@ export function useMarkAsStarred() {
         return { ...old, starred }
       })
       queryClient.invalidateQueries({ queryKey: ['starredCount'] })
+      queryClient.invalidateQueries({ queryKey: ['starredCounts'] })
       queryClient.invalidateQueries({ queryKey: ['entries'] })
     },
   })
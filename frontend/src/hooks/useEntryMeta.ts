@@ -49,6 +49,20 @@ export function useEntryMeta(entry: Entry | null | undefined) {
     return shortDateFormatter.format(publishedAt)
   }, [publishedAt, shortDateFormatter])
 
+  const originalPublishedLong = useMemo(() => {
+    if (!entry?.originalPublishedAt) return null
+    const date = new Date(entry.originalPublishedAt)
+    if (Number.isNaN(date.getTime())) return null
+    return longDateFormatter.format(date)
+  }, [entry, longDateFormatter])
+
+  const revisedLong = useMemo(() => {
+    if (!entry?.revision) return null
+    const date = new Date(entry.revision.changedAt)
+    if (Number.isNaN(date.getTime())) return null
+    return longDateFormatter.format(date)
+  }, [entry, longDateFormatter])
+
   const readingTime = useMemo(() => {
     if (!entry?.content) return null
     const text = entry.content.replace(/<[^>]*>/g, '')
@@ -57,5 +71,5 @@ export function useEntryMeta(entry: Entry | null | undefined) {
     return mins > 0 ? t('entry.min_read', { mins }) : null
   }, [entry, t])
 
-  return { feedTitle, publishedLong, publishedShort, readingTime }
+  return { feedTitle, publishedLong, publishedShort, originalPublishedLong, revisedLong, readingTime }
 }
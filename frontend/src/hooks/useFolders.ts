@@ -1,5 +1,5 @@
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query'
-import { listFolders, deleteFolder, updateFolderType } from '@/api'
+import { listFolders, deleteFolder, setFolderThumbnails, updateFolderType } from '@/api'
 import type { ContentType } from '@/types/api'
 
 export function useFolders() {
@@ -32,3 +32,14 @@ export function useUpdateFolderType() {
     },
   })
 }
+
+export function useSetFolderThumbnails() {
+  const queryClient = useQueryClient()
+  return useMutation({
+    mutationFn: (payload: { id: string; enabled: boolean }) =>
+      setFolderThumbnails(payload.id, payload.enabled),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['folders'] })
+    },
+  })
+}
@@ -1,7 +1,7 @@
 import { useCallback, useMemo } from 'react'
 import { useLocation, useSearch } from 'wouter'
 import { parseRoute, buildPath } from '@/lib/router'
-import type { ContentType } from '@/types/api'
+import type { ContentType, UnreadCountsParams } from '@/types/api'
 
 export type SelectionType =
   | { type: 'all' }
@@ -112,3 +112,18 @@ export function selectionToParams(
       return { ...base, starredOnly: true }
   }
 }
+
+/** Unread counts to fetch for a selection's header count */
+export function unreadCountsScope(
+  selection: SelectionType,
+  contentType?: ContentType
+): UnreadCountsParams {
+  switch (selection.type) {
+    case 'all':
+      return { contentType }
+    case 'folder':
+      return { folderId: selection.folderId, contentType }
+    default:
+      return {}
+  }
+}
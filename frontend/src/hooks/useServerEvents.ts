@@ -0,0 +1,45 @@
+import { useEffect } from 'react'
+import { useQueryClient } from '@tanstack/react-query'
+import { watchEvents } from '@/api'
+
+/** Reloads feeds, entries and unread counts when the server reports changes. */
+export function useServerEvents() {
+  const queryClient = useQueryClient()
+
+  useEffect(() => {
+    let connected = false
+    return watchEvents(
+      (event) => {
+        switch (event.type) {
+          case 'feed_refreshed':
+            if (event.data.newEntries > 0) {
+              queryClient.invalidateQueries({ queryKey: ['entries'] })
+            }
+            queryClient.invalidateQueries({ queryKey: ['feeds'] })
+            break
+          case 'unread_delta':
+            queryClient.invalidateQueries({ queryKey: ['unreadCounts'] })
+            break
+          case 'task':
+            if (event.data.status === 'done' && (event.data.kind === 'import' || event.data.kind === 'icon_backfill')) {
+              queryClient.invalidateQueries({ queryKey: ['folders'] })
+              queryClient.invalidateQueries({ queryKey: ['feeds'] })
+              queryClient.invalidateQueries({ queryKey: ['unreadCounts'] })
+            }
+            break
+          case 'notification':
+            queryClient.invalidateQueries({ queryKey: ['notifications'] })
+            break
+        }
+      },
+      // Events missed while disconnected are not replayed
+      () => {
+        if (connected) {
+          queryClient.invalidateQueries({ queryKey: ['feeds'] })
+          queryClient.invalidateQueries({ queryKey: ['unreadCounts'] })
+        }
+        connected = true
+      },
+    )
+  }, [queryClient])
+}
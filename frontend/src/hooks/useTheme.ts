@@ -1,7 +1,19 @@
 import { useCallback, useEffect, useSyncExternalStore } from 'react'
+import { useQuery } from '@tanstack/react-query'
+import { getAppearanceSettings, updateAppearanceSettings } from '@/api'
+import type { AppearanceTheme } from '@/types/settings'
 
 export type Theme = 'light' | 'dark' | 'system'
 
+// The server calls following the device "auto"
+function fromServerTheme(theme: AppearanceTheme): Theme {
+  return theme === 'auto' ? 'system' : theme
+}
+
+function toServerTheme(theme: Theme): AppearanceTheme {
+  return theme === 'system' ? 'auto' : theme
+}
+
 const STORAGE_KEY = 'gist-theme'
 
 let cachedTheme: Theme = getStoredTheme()
@@ -30,7 +42,13 @@ function applyTheme(theme: Theme) {
   root.classList.toggle('dark', isDark)
 }
 
-export function setTheme(theme: Theme): void {
+/** Applies and remembers the theme; sync also saves it on the server, so other devices follow. */
+export function setTheme(theme: Theme, sync = true): void {
+  if (sync) {
+    updateAppearanceSettings({ theme: toServerTheme(theme) }).catch(() => {
+      // the local choice still applies
+    })
+  }
   cachedTheme = theme
   try {
     localStorage.setItem(STORAGE_KEY, theme)
@@ -56,6 +74,18 @@ function getServerSnapshot(): Theme {
 
 export function useTheme() {
   const theme = useSyncExternalStore(subscribe, getSnapshot, getServerSnapshot)
+  const { data: appearance } = useQuery({
+    queryKey: ['appearanceSettings'],
+    queryFn: getAppearanceSettings,
+    staleTime: 5 * 60 * 1000, // 5 minutes
+  })
+
+  // The theme chosen on another device wins over the one stored here
+  useEffect(() => {
+    if (appearance && fromServerTheme(appearance.theme) !== cachedTheme) {
+      setTheme(fromServerTheme(appearance.theme), false)
+    }
+  }, [appearance])
 
   useEffect(() => {
     // Apply theme on mount
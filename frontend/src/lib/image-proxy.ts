@@ -56,6 +56,17 @@ function toBase64Url(str: string): string {
  * Get proxied image URL
  */
 export function getProxiedImageUrl(src: string, articleUrl?: string): string {
+  return proxiedUrl('image', src, articleUrl)
+}
+
+/**
+ * Get proxied URL of an image scaled down for the picture grid
+ */
+export function getProxiedThumbnailUrl(src: string, articleUrl?: string): string {
+  return proxiedUrl('thumbnail', src, articleUrl)
+}
+
+function proxiedUrl(route: 'image' | 'thumbnail', src: string, articleUrl?: string): string {
   const absoluteUrl = toAbsoluteUrl(src, articleUrl)
   if (!absoluteUrl) return src
 
@@ -64,7 +75,7 @@ export function getProxiedImageUrl(src: string, articleUrl?: string): string {
     return absoluteUrl
   }
 
-  let url = `/api/proxy/image/${toBase64Url(absoluteUrl)}`
+  let url = `/api/proxy/${route}/${toBase64Url(absoluteUrl)}`
   // Pass article URL as referer for CDN anti-hotlinking
   if (articleUrl) {
     url += `?ref=${toBase64Url(articleUrl)}`
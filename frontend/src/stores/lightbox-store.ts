@@ -1,14 +1,14 @@
 import { create } from 'zustand'
-import type { Entry, Feed } from '@/types/api'
+import type { EntrySummary, Feed } from '@/types/api'
 
 interface LightboxState {
   isOpen: boolean
-  entry: Entry | null
+  entry: EntrySummary | null
   feed: Feed | null
   images: string[]
   currentIndex: number
 
-  open: (entry: Entry, feed: Feed | undefined, images: string[], startIndex?: number) => void
+  open: (entry: EntrySummary, feed: Feed | undefined, images: string[], startIndex?: number) => void
   close: () => void
   setIndex: (index: number) => void
   next: () => void
@@ -1,14 +1,63 @@
-export type ContentType = 'article' | 'picture' | 'notification'
+export type ContentType = 'article' | 'picture' | 'notification' | 'podcast'
 
 export interface Folder {
   id: string
   name: string
   parentId?: string
   type: ContentType
+  pregenerateThumbnails?: boolean
   createdAt: string
   updatedAt: string
 }
 
+/** Credentials a feed is fetched with: HTTP Basic auth and extra headers. */
+export interface FeedAuth {
+  username?: string
+  password?: string
+  headers?: Record<string, string>
+}
+
+/** Corrections for feeds whose entry dates are written wrong. */
+export interface FeedDateQuirks {
+  /** IANA zone of dates written without an offset, which are otherwise read as UTC. */
+  timezone?: string
+  /** Numeric dates such as 03/04/2025 are day, month, year. */
+  dayFirst?: boolean
+  /** Future dates fall back to the updated date or the time the entry was first seen. */
+  ignoreFuture?: boolean
+}
+
+/** User-Agent and headers a feed, its pages and its icon are requested with. */
+export interface FeedRequestOverrides {
+  /** Replaces the User-Agent; the fallback User-Agent is not tried. */
+  userAgent?: string
+  headers?: Record<string, string>
+}
+
+/** CSS selectors that turn a web page without a feed into entries. */
+export interface FeedScraper {
+  /** Selects each entry on the page. */
+  items: string
+  /** Selects the title within an item; the item's text when unset. */
+  title?: string
+  /** Selects the link within an item; its first link when unset. */
+  link?: string
+  /** Selects the date within an item; entries are dated when first seen when unset. */
+  date?: string
+}
+
+export interface ScrapedItem {
+  title: string
+  url: string
+  publishedAt?: string
+}
+
+export interface ScrapePreview {
+  url: string
+  title: string
+  items: ScrapedItem[]
+}
+
 export interface Feed {
   id: string
   folderId?: string
@@ -17,14 +66,58 @@ export interface Feed {
   siteUrl?: string
   description?: string
   iconPath?: string
+  /** Dominant color of the icon (#rrggbb), shown while it loads. */
+  iconColor?: string
   type: ContentType
   etag?: string
   lastModified?: string
   errorMessage?: string
+  /** Minutes between refreshes; unset when the scheduler decides. */
+  refreshInterval?: number
+  /** Unset until the feed is first refreshed. */
+  nextRefreshAt?: string
+  /** External full-text service (Morss, FiveFilters) new entries are extracted with; unset for the built-in readability. */
+  fullTextUrl?: string
+  /** AI summaries of the feed's entries are written in the article's language instead of the global one. */
+  summaryInSourceLanguage: boolean
+  /** Refresh fetches the readable content of new entries, so truncated articles are complete offline. */
+  prefetchReadability: boolean
+  dateQuirks?: FeedDateQuirks
+  /** Set when the feed is a web page scraped for entries. */
+  scraper?: FeedScraper
+  requestOverrides?: FeedRequestOverrides
+  /** Credentials are stored for the feed; they are never sent back. */
+  hasAuth: boolean
+  /** Refreshes in a row that failed; each doubles the wait until the next one, up to a day. */
+  consecutiveFailures: number
+  /** Set while the feed is disabled for failing too often; scheduled refreshes skip it. */
+  disabledAt?: string
   createdAt: string
   updatedAt: string
 }
 
+export type FeedDeleteMode = 'deleteAll' | 'keepStarred' | 'keepAll'
+
+export interface FeedDeleteResult {
+  entriesDeleted: number
+  entriesKept: number
+  archived: boolean
+}
+
+export type BulkAddStatus = 'added' | 'exists' | 'invalid' | 'failed'
+
+export interface BulkAddResult {
+  url: string
+  status: BulkAddStatus
+  /** The new feed (added) or the subscribed one (exists) */
+  feed?: Feed
+  error?: string
+}
+
+export interface BulkAddFeedsResponse {
+  results: BulkAddResult[]
+}
+
 export interface FeedPreview {
   url: string
   title: string
@@ -35,6 +128,45 @@ export interface FeedPreview {
   lastUpdated?: string
 }
 
+/** Where a discovered feed was found: the URL itself, a link of its page or a common feed path of the site. */
+export type FeedCandidateSource = 'direct' | 'link' | 'path'
+
+export interface FeedCandidate {
+  url: string
+  title: string
+  source: FeedCandidateSource
+  itemCount?: number
+}
+
+export type FetchOutcome = 'ok' | 'not_modified' | 'http_error' | 'error'
+
+/** One refresh attempt of a feed. */
+export interface FeedFetchAttempt {
+  fetchedAt: string
+  status: FetchOutcome
+  httpStatus?: number
+  durationMs: number
+  newEntries: number
+  error?: string
+}
+
+/** How the recent refreshes of a feed went; history only comes with a single feed. */
+export interface FeedHealth {
+  feedId: string
+  title: string
+  status: 'healthy' | 'failing' | 'unknown'
+  consecutiveFailures: number
+  attempts: number
+  failures: number
+  avgDurationMs: number
+  lastAttemptAt?: string
+  lastSuccessAt?: string
+  lastError?: string
+  /** Set while the feed's host is left alone after answering 429; refreshes skip the feed until then. */
+  cooldownUntil?: string
+  history?: FeedFetchAttempt[]
+}
+
 export interface Entry {
   id: string
   feedId: string
@@ -42,18 +174,92 @@ export interface Entry {
   url?: string
   content?: string
   readableContent?: string
+  readableLang?: string
+  /** Text direction of the readable content */
+  readableDir?: TextDirection
+  readableByline?: string
+  readableSiteName?: string
   thumbnailUrl?: string
   author?: string
   publishedAt?: string
+  /** The feed's publish date when it was in the future; publishedAt is then when the entry was first seen */
+  originalPublishedAt?: string
   read: boolean
   starred: boolean
+  linkDead?: boolean
+  /** Why the entry is flagged not safe for work: category, explicit, keyword or ai */
+  nsfwReason?: string
+  /** Site icon of a saved page, under /icons; feed entries use the feed's icon */
+  iconPath?: string
+  revision?: EntryRevision
+  images?: string[]
+  /** The page a linkblog entry is about, apart from url */
+  externalUrl?: string
+  /** Files attached to the entry, like podcast audio */
+  attachments?: EntryAttachment[]
+  createdAt: string
+  updatedAt: string
+}
+
+// EntryAttachment is a file attached to an entry. Size and duration are
+// absent when the feed does not give them.
+export interface EntryAttachment {
+  url: string
+  mimeType?: string
+  title?: string
+  sizeBytes?: number
+  durationSeconds?: number
+}
+
+// PlaybackPosition is where playback of an entry's attachment stopped, in
+// seconds from the start. updatedAt is absent when it was never played.
+export interface PlaybackPosition {
+  entryId: string
+  positionSeconds: number
+  updatedAt?: string
+}
+
+export type TextDirection = 'ltr' | 'rtl'
+
+// ReadableContentResponse is an entry's readable version with what extraction
+// learned about the page.
+export type ReadableContentResponse = Required<Pick<Entry, 'readableContent'>> &
+  Pick<Entry, 'readableLang' | 'readableDir' | 'readableByline' | 'readableSiteName'>
+
+// EntryRevision summarizes the publisher's latest change to an entry.
+export interface EntryRevision {
+  titleBefore?: string
+  wordsAdded: number
+  wordsRemoved: number
+  changes: { removed?: string; added?: string }[]
+  changedAt: string
+}
+
+// EntrySummary is an entry as listed: no content, a plain-text snippet instead.
+export interface EntrySummary {
+  id: string
+  feedId: string
+  title?: string
+  url?: string
+  snippet?: string
+  thumbnailUrl?: string
+  /** The thumbnail scaled down by the server; add ?w=320 or ?w=640 */
+  thumbnailProxyUrl?: string
+  author?: string
+  publishedAt?: string
+  read: boolean
+  starred: boolean
+  nsfwReason?: string
+  iconPath?: string
   createdAt: string
   updatedAt: string
 }
 
 export interface EntryListResponse {
-  entries: Entry[]
+  entries: EntrySummary[]
   hasMore: boolean
+  /** Cursor to pass as `after` for the next page, present when hasMore */
+  nextCursor?: string
 }
 
 export interface EntryListParams {
@@ -63,26 +269,124 @@ export interface EntryListParams {
   unreadOnly?: boolean
   starredOnly?: boolean
   hasThumbnail?: boolean
+  excludeNsfw?: boolean
+  tagId?: string
+  /** UTC year (YYYY) or month (YYYY-MM) of publication */
+  period?: string
   limit?: number
   offset?: number
+  /** Cursor from a previous page's nextCursor; not combinable with offset */
+  after?: string
+}
+
+// Tag is a user-defined label for organizing entries. entryCount is only
+// set in the tag list.
+export interface Tag {
+  id: string
+  name: string
+  entryCount?: number
+  createdAt: string
+  updatedAt: string
+}
+
+export type ArchiveGroupBy = 'month' | 'year'
+
+export type EntryArchiveParams = Omit<EntryListParams, 'limit' | 'offset' | 'after' | 'hasThumbnail'> & {
+  groupBy?: ArchiveGroupBy
+}
+
+export interface ArchivePeriod {
+  period: string
+  count: number
+  unreadCount: number
+}
+
+export interface EntryArchiveResponse {
+  groupBy: ArchiveGroupBy
+  periods: ArchivePeriod[]
+}
+
+export interface UnreadCountsParams {
+  contentType?: ContentType
+  /** Counts the folder and its subfolders */
+  folderId?: string
 }
 
 export interface UnreadCountsResponse {
+  /** Unread entries per feed ID */
   counts: Record<string, number>
+  /** Unread entries per folder ID, not including subfolders */
+  folders: Record<string, number>
+  total: number
+  /** Every starred entry, whatever the scope of the unread counts */
+  starredTotal: number
 }
 
 export interface StarredCountResponse {
   count: number
 }
 
+export interface StarredCountsResponse {
+  /** Starred entries per feed ID */
+  counts: Record<string, number>
+  /** Starred entries per folder ID, not including subfolders */
+  folders: Record<string, number>
+}
+
 export interface MarkAllReadParams {
   feedId?: string
   folderId?: string
   contentType?: ContentType
 }
 
+export type ApiErrorCode =
+  | 'invalid_request'
+  | 'validation_failed'
+  | 'invalid_id'
+  | 'missing_field'
+  | 'invalid_url'
+  | 'batch_too_large'
+  | 'missing_file'
+  | 'file_too_large'
+  | 'preferences_too_large'
+  | 'unsupported_media_type'
+  | 'not_found'
+  | 'method_not_allowed'
+  | 'conflict'
+  | 'feed_exists'
+  | 'refresh_in_progress'
+  | 'import_in_progress'
+  | 'sync_in_progress'
+  | 'sync_not_configured'
+  | 'link_check_in_progress'
+  | 'icon_backfill_in_progress'
+  | 'fetch_readable_in_progress'
+  | 'idempotency_key_in_use'
+  | 'unauthorized'
+  | 'demo_mode'
+  | 'read_only'
+  | 'feed_fetch_failed'
+  | 'host_cooling_down'
+  | 'content_fetch_failed'
+  | 'upstream_timeout'
+  | 'request_timeout'
+  | 'image_fetch_failed'
+  | 'ai_request_failed'
+  | 'integration_not_configured'
+  | 'integration_failed'
+  | 'internal_error'
+
+export interface FieldError {
+  field: string
+  code: string
+  message: string
+}
+
 export interface ApiErrorResponse {
-  error: string
+  code: ApiErrorCode
+  message: string
+  details?: unknown
+  fieldErrors?: FieldError[]
 }
 
 export interface ImportResult {
@@ -92,13 +396,197 @@ export interface ImportResult {
   feedsSkipped: number
 }
 
+export interface ImportPreviewFolder {
+  path: string
+  type: ContentType
+}
+
+export interface ImportPreviewFeed {
+  title: string
+  url: string
+  folder?: string
+  type: ContentType
+}
+
+export interface ImportTypeConflict extends ImportPreviewFeed {
+  existingType: ContentType
+  existingFolder?: string
+}
+
+export interface ImportPreview {
+  summary: ImportResult
+  folders: ImportPreviewFolder[]
+  feeds: ImportPreviewFeed[]
+  duplicates: ImportPreviewFeed[]
+  typeConflicts: ImportTypeConflict[]
+  invalid: ImportPreviewFeed[]
+}
+
+export interface ImportUndoResult {
+  feedsDeleted: number
+  foldersDeleted: number
+  foldersKept: number
+}
+
+export type TaskKind =
+  | 'import'
+  | 'refresh'
+  | 'icon_backfill'
+  | 'snippet_backfill'
+  | 'sync'
+  | 'link_check'
+  | 'prune'
+  | 'fetch_readable'
+
+export interface SyncResult {
+  foldersCreated: number
+  feedsCreated: number
+  feedsFailed: number
+  entriesUpdated: number
+  entriesMissing: number
+  cursor: number
+}
+
+export interface ReadableBatchResult {
+  extracted: number
+  failed: number
+}
+
+export interface LinkCheckResult {
+  checked: number
+  dead: number
+  errors: number
+}
+
+// BrokenLink is a starred entry whose URL failed its last check.
+export interface BrokenLink {
+  entryId: string
+  feedId: string
+  feedTitle: string
+  title?: string
+  url: string
+  status: 'dead' | 'error'
+  httpStatus?: number
+  error?: string
+  checkedAt: string
+  failingSince?: string
+}
+
+export type TaskStatus = 'running' | 'done' | 'error' | 'cancelled'
+
+export interface Task<R = unknown> {
+  id: string
+  kind: TaskKind
+  status: TaskStatus
+  total: number
+  current: number
+  detail?: string
+  result?: R
+  error?: string
+  createdAt: string
+  finishedAt?: string
+}
+
+export type FilterField = 'title' | 'content' | 'author' | 'url'
+/** expr makes pattern an expression over the entry, with no field */
+export type FilterMatch = 'contains' | 'regex' | 'expr'
+/** rewrite is only for expr rules, whose expression returns the fields to replace */
+export type FilterAction = 'mark_read' | 'star' | 'skip' | 'move' | 'rewrite'
+
+/** A rule refresh applies to new entries; without feedId it applies to every feed. */
+export interface Filter {
+  id: string
+  feedId?: string
+  /** Empty for expr rules */
+  field: FilterField | ''
+  match: FilterMatch
+  pattern: string
+  action: FilterAction
+  folderId?: string
+  enabled: boolean
+  createdAt: string
+  updatedAt: string
+}
+
+export type FilterInput = Omit<Filter, 'id' | 'createdAt' | 'updatedAt'>
+
+export interface FilterSampleEntry {
+  title?: string
+  content?: string
+  author?: string
+  url?: string
+}
+
+export interface FilterTestRequest extends FilterInput {
+  entry: FilterSampleEntry
+}
+
+/** What a rule did to the sample entry */
+export interface FilterTestResult {
+  matched: boolean
+  skip: boolean
+  read: boolean
+  starred: boolean
+  folderId?: string
+  title: string
+  content: string
+  author: string
+  /** Why the expression failed on the entry; refresh ignores the rule then */
+  error?: string
+}
+
+/** An inbox entry about background work: a finished import, a failed task or a disabled feed. */
+export interface Notification {
+  id: string
+  kind: 'import_done' | 'task_failed' | 'feed_disabled'
+  taskKind?: TaskKind
+  feedId?: string
+  title: string
+  body?: string
+  read: boolean
+  createdAt: string
+}
+
+export interface NotificationListResponse {
+  notifications: Notification[]
+  unread: number
+}
+
+/** Notifications streamed by GET /api/events. */
+export type ServerEvent =
+  | { type: 'feed_refreshed'; data: { feedId: string; title: string; newEntries: number } }
+  | { type: 'unread_delta'; data: { feedId: string; delta: number } }
+  | { type: 'task'; data: Task }
+  | { type: 'notification'; data: Pick<Notification, 'id' | 'kind' | 'title' | 'body'> }
+  | { type: 'feed_disabled'; data: { feedId: string; title: string; failures: number; error?: string } }
+
+export interface ScheduledJobRun {
+  taskId: string
+  status: TaskStatus
+  startedAt: string
+  finishedAt?: string
+  durationMs?: number
+  error?: string
+}
+
+export interface ScheduledJob {
+  kind: TaskKind
+  intervalSeconds: number
+  paused: boolean
+  skipReason?: string
+  nextRunAt?: string
+  lastRun?: ScheduledJobRun
+}
+
 export interface ImportTask {
   id?: string
-  status: 'idle' | 'running' | 'done' | 'error' | 'cancelled'
+  kind?: TaskKind
+  status: 'idle' | TaskStatus
   total: number
   current: number
-  feed?: string
+  detail?: string
   result?: ImportResult
   error?: string
   createdAt?: string
+  finishedAt?: string
 }
@@ -1,3 +1,5 @@
+import type { ContentType } from './api';
+
 export type AIProvider = 'openai' | 'anthropic' | 'compatible';
 
 export type ReasoningEffort = 'low' | 'medium' | 'high' | 'xhigh' | 'minimal' | 'none' | '';
@@ -35,4 +37,123 @@ export interface AITestResponse {
 export interface GeneralSettings {
   fallbackUserAgent: string;
   autoReadability: boolean;
+  lowData: boolean;
+  lowDataSchedule: string;
+  /** Whether low-data mode is in effect now, by switch or by schedule. */
+  lowDataActive: boolean;
+  nsfwMode: NSFWMode;
+  /** Keyword rules, one per comma or line. */
+  nsfwKeywords: string;
+  nsfwVisionCheck: boolean;
+  /** Hosts whose cookies are kept across fetches, one per comma or line. */
+  cookieHosts: string;
+  /** Hosts whose iframes entry content keeps, one per comma or line. */
+  embedHosts: string;
+  /** "host=browser" pairs (chrome, firefox, safari), one per comma or line. */
+  tlsFingerprints: string;
+  /** Order icons are looked up in: feed, site, google, duckduckgo. Left-out sources are never used. */
+  iconSources: string;
+  /** Unstarred entries fetched more than this many days ago are pruned daily; 0 keeps them. */
+  retentionDays: number;
+  /** Unstarred entries past the newest this many of each feed are pruned daily; 0 keeps them. */
+  retentionMaxPerFeed: number;
+  /** Hours between scheduled backups, 0 to 720; 0 is off. */
+  backupInterval: number;
+  /** Newest backups kept, 1 to 100. */
+  backupKeep: number;
+  feedDisableThreshold: number;
+  /** Feeds added without a folder go into a top-level folder named after their first category. */
+  categoryFolders: boolean;
+  /** New entries repeating a title of the same feed within the window are not stored. */
+  duplicateTitles: DuplicateTitlesMode;
+  /** Hours back duplicate titles are looked for, 1 to 720. */
+  duplicateTitleWindow: number;
+  /** How new entries dated in the future are stored. */
+  futureDates: FutureDatesMode;
+  /** Hours after an entry without content is found that refreshes fetch it from its page, 0 to 168; 0 is off. */
+  contentRefetchWindow: number;
+}
+
+/** How entries flagged not safe for work are shown. */
+export type NSFWMode = 'show' | 'blur' | 'hide';
+
+/** exact ignores case and spacing; fuzzy also punctuation and small differences. */
+export type DuplicateTitlesMode = 'off' | 'exact' | 'fuzzy';
+
+/** clamp dates them when first seen, hide stores them once their date arrives, keep stores the date as given. */
+export type FutureDatesMode = 'clamp' | 'hide' | 'keep';
+
+/** Low-data, NSFW, cookie, fingerprint, icon source, retention, duplicate title, future date, content refetch and backup fields keep their current values when omitted. */
+export type GeneralSettingsUpdate = Pick<GeneralSettings, 'fallbackUserAgent' | 'autoReadability'> &
+  Partial<Pick<GeneralSettings, 'lowData' | 'lowDataSchedule' | 'nsfwMode' | 'nsfwKeywords' | 'nsfwVisionCheck' | 'cookieHosts' | 'embedHosts' | 'tlsFingerprints' | 'iconSources' | 'retentionDays' | 'retentionMaxPerFeed' | 'feedDisableThreshold' | 'categoryFolders' | 'duplicateTitles' | 'duplicateTitleWindow' | 'futureDates' | 'contentRefetchWindow' | 'backupInterval' | 'backupKeep'>>;
+
+/** Server-side theme; auto follows each device. */
+export type AppearanceTheme = 'light' | 'dark' | 'auto';
+
+/** How entries of a content type are listed. */
+export type EntryView = 'list' | 'masonry';
+
+/** Appearance kept on the server, so every device looks the same. */
+export interface AppearanceSettings {
+  theme: AppearanceTheme;
+  /** #rrggbb, or empty for the default. */
+  accentColor: string;
+  /** Entry content size in pixels, 12 to 24. */
+  fontSize: number;
+  defaultViews: Record<ContentType, EntryView>;
+}
+
+/** Omitted fields and content types keep their current values. */
+export type AppearanceSettingsUpdate = Partial<Omit<AppearanceSettings, 'defaultViews'>> & {
+  defaultViews?: Partial<Record<ContentType, EntryView>>;
+};
+
+/** A backup written by the backup job: a zip of subscriptions.opml and starred.jsonl. */
+export interface BackupFile {
+  name: string;
+  size: number;
+  createdAt: string;
+}
+
+/** Read-later services entries can be pushed to. */
+export type IntegrationProvider = 'wallabag' | 'pocket' | 'linkding';
+
+/**
+ * Read-later service configuration. Secrets come back masked; saving a masked or empty secret keeps the stored one,
+ * and an empty url (for Pocket, consumerKey) removes the service.
+ */
+export interface IntegrationSettings {
+  wallabag: {
+    url: string;
+    clientId: string;
+    clientSecret: string;
+    username: string;
+    password: string;
+  };
+  pocket: {
+    consumerKey: string;
+    accessToken: string;
+  };
+  linkding: {
+    url: string;
+    token: string;
+  };
+}
+
+export interface IntegrationSaveResult {
+  provider: IntegrationProvider;
+  remoteId?: string;
+}
+
+/** Action or gesture name -> key combination or action; null clears a binding. */
+export type Bindings = Record<string, string | null>;
+
+/**
+ * Preferences stored on the server by key. Keys are dot-separated lowercase segments; shortcuts and gestures are
+ * checked as bindings, other keys hold any JSON value.
+ */
+export interface Preferences {
+  shortcuts?: Bindings;
+  gestures?: Bindings;
+  [key: string]: unknown;
 }
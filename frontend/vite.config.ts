@@ -19,6 +19,18 @@ export default defineConfig({
         theme_color: '#f26522',
         background_color: '#ffffff',
         display: 'standalone',
+        // Installed on Android, Gist appears in the share sheet; shared
+        // pages are saved by the backend, which redirects to the new entry
+        share_target: {
+          action: '/api/share',
+          method: 'POST',
+          enctype: 'application/x-www-form-urlencoded',
+          params: {
+            title: 'title',
+            text: 'text',
+            url: 'url',
+          },
+        },
         icons: [
           {
             src: 'pwa-64x64.png',